@@ -560,6 +560,8 @@ export interface Talkgroup {
     alertsEnabled?: boolean;
     // Custom transcription prompt; overrides system and global prompts when non-empty
     transcriptionPrompt?: string;
+    // 'off', 'standard' (empty) or 'priority' (transcribed before standard calls)
+    transcriptionTier?: string;
     autoLearnToneSets?: boolean;
     autoLearnUnitAliases?: boolean;
     alertingTalkgroup?: boolean;
@@ -1878,6 +1880,7 @@ export class RdioScannerAdminService implements OnDestroy {
             linkedVoiceMinDurationSeconds: this.ngFormBuilder.control(talkgroup?.linkedVoiceMinDurationSeconds || 0, Validators.min(0)),
             alertsEnabled: this.ngFormBuilder.control(talkgroup?.alertsEnabled !== false), // Default to true
             transcriptionPrompt: this.ngFormBuilder.control(talkgroup?.transcriptionPrompt || ''),
+            transcriptionTier: this.ngFormBuilder.control(talkgroup?.transcriptionTier || ''),
             autoLearnToneSets: this.ngFormBuilder.control(talkgroup?.autoLearnToneSets || false),
             autoLearnUnitAliases: this.ngFormBuilder.control(talkgroup?.autoLearnUnitAliases || false),
             alertingTalkgroup: this.ngFormBuilder.control(talkgroup?.alertingTalkgroup || false),
@@ -39,6 +39,8 @@ fi
 
 if [ -n "$DB_PASS" ]; then
     ARGS="$ARGS -db_pass $DB_PASS"
+elif [ "$TLR_SETUP" = "auto" ] && [ -n "$PG_SUPERUSER" ]; then
+    : # generated by the non-interactive setup below
 else
     echo "ERROR: DB_PASS environment variable is required"
     exit 1
@@ -69,6 +71,12 @@ if [ -n "$BASE_DIR" ]; then
     ARGS="$ARGS -base_dir $BASE_DIR"
 fi
 
+# Non-interactive bootstrap: create role/database/schema and admin account.
+# Generated credentials are printed once as JSON (see -setup_auto).
+if [ "$TLR_SETUP" = "auto" ]; then
+    /app/thinline-radio $ARGS -setup_auto
+fi
+
 # Print configuration (without sensitive data)
 echo "Starting ThinLine Radio..."
 echo "Database: $DB_HOST:${DB_PORT:-5432}/$DB_NAME"
@@ -383,7 +383,7 @@ func (accesses *Accesses) Write(db *Database) error {
 		count   uint
 		err     error
 		rows    *sql.Rows
-		rowIds  = []uint{}
+		rowIds  = []uint64{}
 		systems any
 	)
 
@@ -423,7 +423,7 @@ func (accesses *Accesses) Write(db *Database) error {
 			}
 		}
 		if remove {
-			rowIds = append(rowIds, id)
+			rowIds = append(rowIds, uint64(id))
 		}
 	}
 
@@ -434,18 +434,14 @@ func (accesses *Accesses) Write(db *Database) error {
 	}
 
 	if len(rowIds) > 0 {
-		if b, err := json.Marshal(rowIds); err == nil {
-			s := string(b)
-			s = strings.ReplaceAll(s, "[", "(")
-			s = strings.ReplaceAll(s, "]", ")")
-			if db.Config.DbType == DbTypePostgresql {
-				query = fmt.Sprintf(`DELETE FROM "public"."accesses" WHERE "accessId" IN %s`, s)
-			} else {
-				query = fmt.Sprintf("DELETE FROM `accesses` WHERE `accessId` IN %s", s)
-			}
-			if _, err = db.Sql.Exec(query); err != nil {
-				return formatError(err)
-			}
+		args := queryArgs{}
+		if db.Config.DbType == DbTypePostgresql {
+			query = `DELETE FROM "public"."accesses" WHERE "accessId" IN ` + args.in(rowIds)
+		} else {
+			query = "DELETE FROM `accesses` WHERE `accessId` IN " + args.in(rowIds)
+		}
+		if _, err = db.Sql.Exec(query, args...); err != nil {
+			return formatError(err)
 		}
 	}
 
@@ -0,0 +1,235 @@
+// Copyright (C) 2026 Thinline Dynamic Solutions
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>
+
+package main
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// activityAnomalyWindow is the rolling window whose call count is compared
+// with the same window on previous days.
+const activityAnomalyWindow = time.Hour
+
+const activityAnomalyCheckInterval = 15 * time.Minute
+
+// activityBaseline is the normal call volume of a talkgroup for one window,
+// learned from the same time of day on previous days.
+type activityBaseline struct {
+	Mean   float64
+	StdDev float64
+}
+
+// newActivityBaseline summarises the per-day counts. Days without calls must
+// be included as zeros.
+func newActivityBaseline(counts []int) activityBaseline {
+	if len(counts) == 0 {
+		return activityBaseline{}
+	}
+
+	var sum float64
+	for _, count := range counts {
+		sum += float64(count)
+	}
+	mean := sum / float64(len(counts))
+
+	var variance float64
+	for _, count := range counts {
+		variance += (float64(count) - mean) * (float64(count) - mean)
+	}
+	variance /= float64(len(counts))
+
+	return activityBaseline{Mean: mean, StdDev: math.Sqrt(variance)}
+}
+
+// threshold is the call count above which the window is unusually busy. The
+// deviation is floored at one call so a talkgroup with a perfectly steady or
+// empty history isn't flagged for a single extra call.
+func (baseline activityBaseline) threshold(sigma float64) float64 {
+	return baseline.Mean + sigma*math.Max(baseline.StdDev, 1)
+}
+
+// isAnomalous reports whether count is unusually high. Counts below minCalls
+// never are, so quiet talkgroups don't alert on a handful of calls.
+func (baseline activityBaseline) isAnomalous(count int, sigma float64, minCalls int) bool {
+	return count >= minCalls && float64(count) > baseline.threshold(sigma)
+}
+
+type activityCountKey struct {
+	systemId    uint64
+	talkgroupId uint64
+}
+
+// countActivityWindows returns, per talkgroup, the number of calls in the
+// last activityAnomalyWindow (index 0) and in the same window on each of the
+// previous days (index 1..days).
+func (controller *Controller) countActivityWindows(now time.Time, days int) (map[activityCountKey][]int, error) {
+	dayMs := int64(24 * time.Hour / time.Millisecond)
+	windowMs := int64(activityAnomalyWindow / time.Millisecond)
+	base := now.UnixMilli() - windowMs - int64(days)*dayMs
+
+	query := fmt.Sprintf(
+		`SELECT "systemId", "talkgroupId", ("timestamp" - %d) / %d AS "day", COUNT(*) FROM "calls" WHERE "timestamp" >= %d AND "timestamp" <= %d AND MOD("timestamp" - %d, %d) < %d GROUP BY "systemId", "talkgroupId", "day"`,
+		base, dayMs, base, now.UnixMilli(), base, dayMs, windowMs,
+	)
+
+	rows, err := controller.Database.Sql.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := map[activityCountKey][]int{}
+	for rows.Next() {
+		var (
+			key   activityCountKey
+			day   int64
+			count int
+		)
+		if err := rows.Scan(&key.systemId, &key.talkgroupId, &day, &count); err != nil {
+			continue
+		}
+		// The most recent day bucket is the current window
+		index := days - int(day)
+		if index < 0 || index > days {
+			continue
+		}
+		if counts[key] == nil {
+			counts[key] = make([]int, days+1)
+		}
+		counts[key][index] = count
+	}
+
+	return counts, rows.Err()
+}
+
+// activityHistoryDays returns how many full days of calls the database holds,
+// capped at max.
+func (controller *Controller) activityHistoryDays(now time.Time, max int) int {
+	var oldest int64
+	if err := controller.Database.Sql.QueryRow(`SELECT COALESCE(MIN("timestamp"), 0) FROM "calls"`).Scan(&oldest); err != nil || oldest == 0 {
+		return 0
+	}
+	days := int(now.Sub(time.UnixMilli(oldest)) / (24 * time.Hour))
+	if days > max {
+		days = max
+	}
+	return days
+}
+
+// MonitorActivityAnomalies raises an informational system alert for every
+// talkgroup with unusually high call volume in the last hour compared with the
+// same hour on previous days. When enabled, users with alerts on for the
+// talkgroup are notified too.
+func (controller *Controller) MonitorActivityAnomalies() {
+	options := controller.Options
+	if !options.ActivityAnomalyAlertsEnabled || !options.SystemHealthAlertsEnabled {
+		return
+	}
+
+	sigma := options.ActivityAnomalySigma
+	if sigma <= 0 {
+		sigma = 3
+	}
+	minCalls := int(options.ActivityAnomalyMinCalls)
+	if minCalls <= 0 {
+		minCalls = 10
+	}
+	historyDays := int(options.ActivityAnomalyHistoryDays)
+	if historyDays <= 0 {
+		historyDays = 14
+	}
+	repeat := time.Duration(options.ActivityAnomalyRepeatMinutes) * time.Minute
+	if repeat <= 0 {
+		repeat = time.Hour
+	}
+
+	now := time.Now()
+
+	// A few days are needed before "normal" means anything
+	days := controller.activityHistoryDays(now, historyDays)
+	if days < 3 {
+		return
+	}
+
+	counts, err := controller.countActivityWindows(now, days)
+	if err != nil {
+		controller.Logs.LogEvent(LogLevelError, fmt.Sprintf("failed to count talkgroup activity: %v", err))
+		return
+	}
+
+	if controller.activityAnomalyAlertedAt == nil {
+		controller.activityAnomalyAlertedAt = map[uint64]time.Time{}
+	}
+
+	for key, windows := range counts {
+		current := windows[0]
+		baseline := newActivityBaseline(windows[1:])
+		if !baseline.isAnomalous(current, sigma, minCalls) {
+			continue
+		}
+
+		if last, ok := controller.activityAnomalyAlertedAt[key.talkgroupId]; ok && now.Sub(last) < repeat {
+			continue
+		}
+		controller.activityAnomalyAlertedAt[key.talkgroupId] = now
+
+		system, ok := controller.Systems.GetSystemById(key.systemId)
+		if !ok {
+			continue
+		}
+		talkgroup, ok := system.Talkgroups.GetTalkgroupById(key.talkgroupId)
+		if !ok {
+			continue
+		}
+
+		controller.CreateSystemAlert(
+			"activity_anomaly",
+			"info",
+			fmt.Sprintf("Unusual Activity: %s", talkgroup.Label),
+			fmt.Sprintf("%s / %s had %d calls in the last hour, normally %.1f at this time of day (alert above %.1f).", system.Label, talkgroup.Label, current, baseline.Mean, baseline.threshold(sigma)),
+			&SystemAlertData{
+				SystemId:       system.Id,
+				SystemLabel:    system.Label,
+				TalkgroupId:    talkgroup.Id,
+				TalkgroupLabel: talkgroup.Label,
+				Count:          current,
+				Baseline:       baseline.Mean,
+			},
+			0, // System-generated
+		)
+
+		if options.ActivityAnomalyNotifyUsers && controller.PreferencesCache != nil {
+			var userIds []uint64
+			for _, userId := range controller.PreferencesCache.GetUsersForTalkgroup(system.Id, talkgroup.Id) {
+				if pref := controller.PreferencesCache.GetPreference(userId, system.Id, talkgroup.Id); pref != nil && pref.AlertEnabled {
+					userIds = append(userIds, userId)
+				}
+			}
+			if len(userIds) > 0 {
+				go controller.sendBatchedPushNotification(userIds, "activity", nil, system.Label, talkgroup.Label, "", nil)
+			}
+		}
+	}
+
+	// Forget talkgroups whose repeat interval has passed
+	for talkgroupId, last := range controller.activityAnomalyAlertedAt {
+		if now.Sub(last) >= repeat {
+			delete(controller.activityAnomalyAlertedAt, talkgroupId)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestActivityBaseline(t *testing.T) {
+	baseline := newActivityBaseline([]int{2, 4, 4, 4, 5, 5, 7, 9})
+	if baseline.Mean != 5 || baseline.StdDev != 2 {
+		t.Fatalf("expected mean 5 stddev 2, got %+v", baseline)
+	}
+	if baseline.threshold(3) != 11 {
+		t.Fatalf("expected threshold 11, got %v", baseline.threshold(3))
+	}
+	if baseline.isAnomalous(11, 3, 10) {
+		t.Fatal("count at the threshold should not be anomalous")
+	}
+	if !baseline.isAnomalous(12, 3, 10) {
+		t.Fatal("count above the threshold should be anomalous")
+	}
+}
+
+func TestActivityBaselineQuietTalkgroup(t *testing.T) {
+	baseline := newActivityBaseline([]int{0, 0, 0, 0, 0, 0, 0})
+	if baseline.StdDev != 0 || math.IsNaN(baseline.Mean) {
+		t.Fatalf("unexpected baseline %+v", baseline)
+	}
+	// The deviation floor keeps a few calls from alerting on a silent talkgroup
+	if baseline.isAnomalous(3, 3, 1) {
+		t.Fatal("3 calls should not exceed the floored threshold")
+	}
+	if baseline.isAnomalous(8, 3, 10) {
+		t.Fatal("counts below the minimum should never be anomalous")
+	}
+	if !baseline.isAnomalous(10, 3, 10) {
+		t.Fatal("10 calls on a silent talkgroup should be anomalous")
+	}
+}
+
+func TestActivityBaselineEmpty(t *testing.T) {
+	if baseline := newActivityBaseline(nil); baseline.Mean != 0 || baseline.StdDev != 0 {
+		t.Fatalf("expected zero baseline, got %+v", baseline)
+	}
+}
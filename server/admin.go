@@ -68,28 +68,6 @@ func IsLocalhostIP(ip string) bool {
 	return false
 }
 
-// GetClientIP extracts the client IP from the request
-func GetClientIP(r *http.Request) string {
-	forwarded := r.Header.Get("X-Forwarded-For")
-	if forwarded != "" {
-		ips := strings.Split(forwarded, ",")
-		if len(ips) > 0 {
-			return strings.TrimSpace(ips[0])
-		}
-	}
-
-	realIP := r.Header.Get("X-Real-IP")
-	if realIP != "" {
-		return realIP
-	}
-
-	ip := r.RemoteAddr
-	if idx := strings.LastIndex(ip, ":"); idx != -1 {
-		ip = ip[:idx]
-	}
-	return ip
-}
-
 type Admin struct {
 	Attempts         AdminLoginAttempts
 	AttemptsMax      uint
@@ -167,7 +145,7 @@ func (admin *Admin) requireAdminBasicAuth(next http.HandlerFunc) http.HandlerFun
 // behaviour now also respects AdminAllowedIPs).
 func (admin *Admin) requireLocalhost(next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		clientIP := GetClientIP(r)
+		clientIP := GetRemoteAddr(r)
 
 		if !admin.isAdminIPAllowed(clientIP) {
 			log.Printf("Admin access denied from IP: %s for route: %s", clientIP, r.URL.Path)
@@ -207,7 +185,7 @@ func (admin *Admin) AlertsHandler(w http.ResponseWriter, r *http.Request) {
 	switch r.Method {
 	case http.MethodGet:
 		t := admin.GetAuthorization(r)
-		if !admin.ValidateToken(t) {
+		if !admin.ValidatePermission(t, PermissionViewAlerts) {
 			w.WriteHeader(http.StatusUnauthorized)
 			return
 		}
@@ -227,7 +205,7 @@ func (admin *Admin) SystemHealthHandler(w http.ResponseWriter, r *http.Request)
 	switch r.Method {
 	case http.MethodGet:
 		t := admin.GetAuthorization(r)
-		if !admin.ValidateToken(t) {
+		if !admin.ValidatePermission(t, PermissionViewAlerts) {
 			w.WriteHeader(http.StatusUnauthorized)
 			return
 		}
@@ -253,6 +231,17 @@ func (admin *Admin) SystemHealthHandler(w http.ResponseWriter, r *http.Request)
 			return
 		}
 
+		// Tenant administrators only see their systems' alerts
+		if admin.tokenTenant(t) != nil {
+			scoped := []*SystemAlert{}
+			for _, alert := range alerts {
+				if admin.tenantAllowsAlert(t, alert) {
+					scoped = append(scoped, alert)
+				}
+			}
+			alerts = scoped
+		}
+
 		// Return JSON response
 		w.Header().Set("Content-Type", "application/json")
 		if b, err := json.Marshal(map[string]interface{}{
@@ -318,9 +307,9 @@ func (admin *Admin) TranscriptionFailuresHandler(w http.ResponseWriter, r *http.
 		// Get failed transcription calls with details
 		twentyFourHoursAgo := time.Now().Add(-24 * time.Hour).UnixMilli()
 
-		query := fmt.Sprintf(`SELECT c."callId", c."systemId", c."talkgroupId", c."timestamp", c."transcriptionFailureReason", s."label" as "systemLabel", t."label" as "talkgroupLabel", t."name" as "talkgroupName" FROM "calls" c LEFT JOIN "systems" s ON s."systemId" = c."systemId" LEFT JOIN "talkgroups" t ON t."talkgroupId" = c."talkgroupId" WHERE c."transcriptionStatus" = 'failed' AND c."timestamp" >= %d ORDER BY c."timestamp" DESC LIMIT 100`, twentyFourHoursAgo)
+		query := `SELECT c."callId", c."systemId", c."talkgroupId", c."timestamp", c."transcriptionFailureReason", s."label" as "systemLabel", t."label" as "talkgroupLabel", t."name" as "talkgroupName" FROM "calls" c LEFT JOIN "systems" s ON s."systemId" = c."systemId" LEFT JOIN "talkgroups" t ON t."talkgroupId" = c."talkgroupId" WHERE c."transcriptionStatus" = 'failed' AND c."timestamp" >= $1 ORDER BY c."timestamp" DESC LIMIT 100`
 
-		rows, err := admin.Controller.Database.Sql.Query(query)
+		rows, err := admin.Controller.Database.Sql.Query(query, twentyFourHoursAgo)
 		if err != nil {
 			w.WriteHeader(http.StatusInternalServerError)
 			json.NewEncoder(w).Encode(map[string]string{
@@ -391,24 +380,21 @@ func (admin *Admin) TranscriptionFailuresHandler(w http.ResponseWriter, r *http.
 		var rowsAffected int64
 		var err error
 
+		args := queryArgs{}
 		if len(request.CallIds) > 0 {
 			// Reset specific calls
-			callIdStrs := make([]string, len(request.CallIds))
-			for i, id := range request.CallIds {
-				callIdStrs[i] = fmt.Sprintf("%d", id)
-			}
-			query = fmt.Sprintf(`UPDATE "calls" SET "transcriptionStatus" = 'pending', "transcriptionFailureReason" = '' WHERE "callId" IN (%s)`, strings.Join(callIdStrs, ","))
+			query = `UPDATE "calls" SET "transcriptionStatus" = 'pending', "transcriptionFailureReason" = '' WHERE "callId" IN ` + args.in(request.CallIds)
 		} else {
 			// Reset all failed calls from last 24 hours
 			twentyFourHoursAgo := time.Now().Add(-24 * time.Hour).UnixMilli()
-			query = fmt.Sprintf(`UPDATE "calls" SET "transcriptionStatus" = 'pending', "transcriptionFailureReason" = '' WHERE "transcriptionStatus" = 'failed' AND "timestamp" >= %d`, twentyFourHoursAgo)
+			query = `UPDATE "calls" SET "transcriptionStatus" = 'pending', "transcriptionFailureReason" = '' WHERE "transcriptionStatus" = 'failed' AND "timestamp" >= ` + args.add(twentyFourHoursAgo)
 		}
 
 		// Log the query for debugging
 		admin.Controller.Logs.LogEvent(LogLevelInfo, fmt.Sprintf("Resetting transcription failures with query: %s", query))
 
 		var result sql.Result
-		result, err = admin.Controller.Database.Sql.Exec(query)
+		result, err = admin.Controller.Database.Sql.Exec(query, args...)
 		if err != nil {
 			errorMsg := fmt.Sprintf("failed to reset transcription failures: %v (query: %s)", err, query)
 			admin.Controller.Logs.LogEvent(LogLevelError, errorMsg)
@@ -895,6 +881,12 @@ func (admin *Admin) SystemHealthAlertSettingsHandler(w http.ResponseWriter, r *h
 			"toneDetectionRepeatMinutes":        admin.Controller.Options.ToneDetectionRepeatMinutes,
 			"noAudioRepeatMinutes":              admin.Controller.Options.NoAudioRepeatMinutes,
 			"alertRetentionDays":                admin.Controller.Options.AlertRetentionDays,
+			"activityAnomalyAlertsEnabled":      admin.Controller.Options.ActivityAnomalyAlertsEnabled,
+			"activityAnomalyNotifyUsers":        admin.Controller.Options.ActivityAnomalyNotifyUsers,
+			"activityAnomalySigma":              admin.Controller.Options.ActivityAnomalySigma,
+			"activityAnomalyMinCalls":           admin.Controller.Options.ActivityAnomalyMinCalls,
+			"activityAnomalyHistoryDays":        admin.Controller.Options.ActivityAnomalyHistoryDays,
+			"activityAnomalyRepeatMinutes":      admin.Controller.Options.ActivityAnomalyRepeatMinutes,
 		})
 
 	case http.MethodPost:
@@ -915,6 +907,12 @@ func (admin *Admin) SystemHealthAlertSettingsHandler(w http.ResponseWriter, r *h
 			ToneDetectionRepeatMinutes        *uint    `json:"toneDetectionRepeatMinutes"`
 			NoAudioRepeatMinutes              *uint    `json:"noAudioRepeatMinutes"`
 			AlertRetentionDays                *uint    `json:"alertRetentionDays"`
+			ActivityAnomalyAlertsEnabled      *bool    `json:"activityAnomalyAlertsEnabled"`
+			ActivityAnomalyNotifyUsers        *bool    `json:"activityAnomalyNotifyUsers"`
+			ActivityAnomalySigma              *float64 `json:"activityAnomalySigma"`
+			ActivityAnomalyMinCalls           *uint    `json:"activityAnomalyMinCalls"`
+			ActivityAnomalyHistoryDays        *uint    `json:"activityAnomalyHistoryDays"`
+			ActivityAnomalyRepeatMinutes      *uint    `json:"activityAnomalyRepeatMinutes"`
 		}
 
 		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
@@ -977,6 +975,35 @@ func (admin *Admin) SystemHealthAlertSettingsHandler(w http.ResponseWriter, r *h
 				admin.Controller.DismissAlertsByType("no_audio")
 			}
 		}
+		if request.ActivityAnomalyAlertsEnabled != nil {
+			v := *request.ActivityAnomalyAlertsEnabled
+			if err := admin.Controller.Options.WriteKey(
+				admin.Controller.Database,
+				"activityAnomalyAlertsEnabled",
+				v,
+				func() { admin.Controller.Options.ActivityAnomalyAlertsEnabled = v },
+			); err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				json.NewEncoder(w).Encode(map[string]string{"error": fmt.Sprintf("failed to save activityAnomalyAlertsEnabled: %v", err)})
+				return
+			}
+			if !v {
+				admin.Controller.DismissAlertsByType("activity_anomaly")
+			}
+		}
+		if request.ActivityAnomalyNotifyUsers != nil {
+			v := *request.ActivityAnomalyNotifyUsers
+			if err := admin.Controller.Options.WriteKey(
+				admin.Controller.Database,
+				"activityAnomalyNotifyUsers",
+				v,
+				func() { admin.Controller.Options.ActivityAnomalyNotifyUsers = v },
+			); err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				json.NewEncoder(w).Encode(map[string]string{"error": fmt.Sprintf("failed to save activityAnomalyNotifyUsers: %v", err)})
+				return
+			}
+		}
 		if request.TranscriptionFailureThreshold != nil && *request.TranscriptionFailureThreshold > 0 {
 			admin.Controller.Options.TranscriptionFailureThreshold = *request.TranscriptionFailureThreshold
 		}
@@ -1013,6 +1040,18 @@ func (admin *Admin) SystemHealthAlertSettingsHandler(w http.ResponseWriter, r *h
 		if request.AlertRetentionDays != nil && *request.AlertRetentionDays > 0 {
 			admin.Controller.Options.AlertRetentionDays = *request.AlertRetentionDays
 		}
+		if request.ActivityAnomalySigma != nil && *request.ActivityAnomalySigma > 0 {
+			admin.Controller.Options.ActivityAnomalySigma = *request.ActivityAnomalySigma
+		}
+		if request.ActivityAnomalyMinCalls != nil && *request.ActivityAnomalyMinCalls > 0 {
+			admin.Controller.Options.ActivityAnomalyMinCalls = *request.ActivityAnomalyMinCalls
+		}
+		if request.ActivityAnomalyHistoryDays != nil && *request.ActivityAnomalyHistoryDays > 0 {
+			admin.Controller.Options.ActivityAnomalyHistoryDays = *request.ActivityAnomalyHistoryDays
+		}
+		if request.ActivityAnomalyRepeatMinutes != nil && *request.ActivityAnomalyRepeatMinutes > 0 {
+			admin.Controller.Options.ActivityAnomalyRepeatMinutes = *request.ActivityAnomalyRepeatMinutes
+		}
 
 		if err := admin.Controller.Options.Write(admin.Controller.Database); err != nil {
 			w.WriteHeader(http.StatusInternalServerError)
@@ -1100,12 +1139,12 @@ func (admin *Admin) SystemNoAudioSettingsHandler(w http.ResponseWriter, r *http.
 // CallAudioHandler serves call audio for admin playback
 func (admin *Admin) CallAudioHandler(w http.ResponseWriter, r *http.Request) {
 	t := admin.GetAuthorization(r)
-	if !admin.ValidateToken(t) {
+	if !admin.ValidatePermission(t, PermissionExportCalls) {
 		w.WriteHeader(http.StatusUnauthorized)
 		return
 	}
 
-	if r.Method != http.MethodGet {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
 		w.WriteHeader(http.StatusMethodNotAllowed)
 		return
 	}
@@ -1133,6 +1172,11 @@ func (admin *Admin) CallAudioHandler(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(map[string]string{"error": fmt.Sprintf("call not found: %v", err)})
 		return
 	}
+	if call.System != nil && !admin.tenantAllowsSystem(t, call.System.Id) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"error": "call not found"})
+		return
+	}
 
 	// Check if call has audio
 	if len(call.Audio) == 0 {
@@ -1148,12 +1192,9 @@ func (admin *Admin) CallAudioHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	w.Header().Set("Content-Type", mimeType)
-	w.Header().Set("Content-Length", strconv.Itoa(len(call.Audio)))
-	w.Header().Set("Cache-Control", "private, max-age=3600")
 	w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=\"call-%d.%s\"", callId, getAudioExtension(mimeType)))
 
-	// Write audio data
-	w.Write(call.Audio)
+	serveCallAudio(w, r, callId, call.Audio)
 }
 
 // getAudioExtension returns file extension based on MIME type
@@ -1181,7 +1222,7 @@ func (admin *Admin) ToneImportHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	token := admin.GetAuthorization(r)
-	if !admin.ValidateToken(token) {
+	if !admin.ValidatePermission(token, PermissionManageTalkgroups) {
 		w.WriteHeader(http.StatusUnauthorized)
 		return
 	}
@@ -1197,7 +1238,7 @@ func (admin *Admin) ToneImportHandler(w http.ResponseWriter, r *http.Request) {
 		admin.Controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("tone import failed: %s", err.Error()))
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusBadRequest)
-		w.Write([]byte(fmt.Sprintf(`{"error":"%s"}`, escapeQuotes(err.Error()))))
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
 		return
 	}
 
@@ -1295,6 +1336,10 @@ func (admin *Admin) ChangePassword(currentPassword any, newPassword string) erro
 		return errors.New("newPassword is empty")
 	}
 
+	if newPassword == defaults.adminPassword {
+		return errors.New("the default password cannot be used")
+	}
+
 	switch v := currentPassword.(type) {
 	case string:
 		if err = bcrypt.CompareHashAndPassword([]byte(admin.Controller.Options.adminPassword), []byte(v)); err != nil {
@@ -1307,7 +1352,8 @@ func (admin *Admin) ChangePassword(currentPassword any, newPassword string) erro
 	}
 
 	admin.Controller.Options.adminPassword = string(hash)
-	admin.Controller.Options.adminPasswordNeedChange = newPassword == defaults.adminPassword
+	admin.Controller.Options.adminPasswordNeedChange = false
+	admin.Controller.FirstRun.clear()
 
 	if err := admin.Controller.Options.Write(admin.Controller.Database); err != nil {
 		return err
@@ -1357,10 +1403,6 @@ func (admin *Admin) ConfigHandler(w http.ResponseWriter, r *http.Request) {
 		}()
 
 	} else {
-		logError := func(err error) {
-			admin.Controller.Logs.LogEvent(LogLevelError, fmt.Sprintf("admin.confighandler.put: %s", err.Error()))
-		}
-
 		t := admin.GetAuthorization(r)
 		if !admin.ValidateToken(t) {
 			w.WriteHeader(http.StatusUnauthorized)
@@ -1387,824 +1429,870 @@ func (admin *Admin) ConfigHandler(w http.ResponseWriter, r *http.Request) {
 				return
 			}
 
-			admin.mutex.Lock()
-			defer admin.mutex.Unlock()
-
-			admin.Controller.Dirwatches.Stop()
-
-			switch v := m["apikeys"].(type) {
-			case []any:
-				admin.Controller.Apikeys.FromMap(v)
-				err = admin.Controller.Apikeys.Write(admin.Controller.Database)
-				if err != nil {
-					logError(err)
-				} else {
-					err = admin.Controller.Apikeys.Read(admin.Controller.Database)
-					if err != nil {
-						logError(err)
+			if options, ok := m["options"].(map[string]any); ok {
+				if webhooks, ok := options["webhooks"].([]any); ok {
+					if err := validateWebhookList(webhooks); err != nil {
+						w.WriteHeader(http.StatusBadRequest)
+						json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+						return
 					}
 				}
-			}
-
-			switch v := m["dirwatch"].(type) {
-			case []any:
-				admin.Controller.Dirwatches.FromMap(v)
-				err = admin.Controller.Dirwatches.Write(admin.Controller.Database)
-				if err != nil {
-					logError(err)
-				} else {
-					err = admin.Controller.Dirwatches.Read(admin.Controller.Database)
-					if err != nil {
-						logError(err)
+				if rules, ok := options["alertRules"].([]any); ok {
+					if err := validateAlertRuleList(rules); err != nil {
+						w.WriteHeader(http.StatusBadRequest)
+						json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+						return
 					}
 				}
 			}
 
-			switch v := m["downstreams"].(type) {
-			case []any:
-				admin.Controller.Downstreams.FromMap(v)
-				err = admin.Controller.Downstreams.Write(admin.Controller.Database)
-				if err != nil {
-					logError(err)
-				} else {
-					err = admin.Controller.Downstreams.Read(admin.Controller.Database)
-					if err != nil {
-						logError(err)
-					}
-				}
+			if err := admin.importConfig(m, isFullImport); err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+				return
 			}
 
-			switch v := m["groups"].(type) {
-			case []any:
-				admin.Controller.Groups.FromMap(v)
-				err = admin.Controller.Groups.Write(admin.Controller.Database)
-				if err != nil {
-					logError(err)
-				} else {
-					err = admin.Controller.Groups.Read(admin.Controller.Database)
-					if err != nil {
-						logError(err)
-					}
-				}
+			admin.SendConfig(w)
+
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	}
+}
+
+// importConfig applies an exported configuration. Every entity type present in
+// m replaces the existing data of that type; users, user groups, alert
+// preferences and device tokens are only replaced when isFullImport is set.
+func (admin *Admin) importConfig(m map[string]any, isFullImport bool) error {
+	var err error
+
+	logError := func(err error) {
+		admin.Controller.Logs.LogEvent(LogLevelError, fmt.Sprintf("admin.confighandler.put: %s", err.Error()))
+	}
+
+	admin.mutex.Lock()
+	defer admin.mutex.Unlock()
+
+	admin.Controller.Dirwatches.Stop()
+
+	switch v := m["apikeys"].(type) {
+	case []any:
+		admin.Controller.Apikeys.FromMap(v)
+		err = admin.Controller.Apikeys.Write(admin.Controller.Database)
+		if err != nil {
+			logError(err)
+		} else {
+			err = admin.Controller.Apikeys.Read(admin.Controller.Database)
+			if err != nil {
+				logError(err)
 			}
+		}
+	}
 
-			switch v := m["options"].(type) {
-			case map[string]any:
-				admin.Controller.Options.FromMap(v)
-				err = admin.Controller.Options.Write(admin.Controller.Database)
-				if err != nil {
-					logError(err)
-				} else {
-					// Reload options from database to update in-memory state
-					err = admin.Controller.Options.Read(admin.Controller.Database)
-					if err != nil {
-						logError(err)
-					} else {
-						// Restart transcription queue with updated settings
-						admin.Controller.RestartTranscriptionQueue()
-
-						// Restart no-audio monitoring in case health alert settings changed
-						go admin.Controller.StartNoAudioMonitoringForAllSystems()
-
-						// If audio encryption is enabled and we don't have a key yet
-						// (or it was just enabled), fetch the key + client token from
-						// the relay server without requiring a server restart.
-						if admin.Controller.Options.AudioEncryptionEnabled &&
-							admin.Controller.Options.RelayServerURL != "" &&
-							admin.Controller.Options.RelayServerAPIKey != "" &&
-							len(admin.Controller.AudioKey) == 0 {
-							go func() {
-								key, fetchErr := FetchAudioKeyFromRelay(
-									admin.Controller.Options.RelayServerURL,
-									admin.Controller.Options.RelayServerAPIKey,
-								)
-								if fetchErr != nil {
-									admin.Controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("audio encryption: failed to fetch key from relay after settings save: %v", fetchErr))
-									return
-								}
-								admin.Controller.AudioKey = key
-								admin.Controller.Logs.LogEvent(LogLevelInfo, "audio encryption: AES-256-GCM key loaded from relay server (triggered by settings save)")
-								admin.Controller.fetchAudioClientToken()
-							}()
-						} else if !admin.Controller.Options.AudioEncryptionEnabled {
-							// Encryption was turned off — clear keys from memory immediately.
-							admin.Controller.AudioKey = nil
-							admin.Controller.AudioClientToken = ""
+	switch v := m["dirwatch"].(type) {
+	case []any:
+		admin.Controller.Dirwatches.FromMap(v)
+		err = admin.Controller.Dirwatches.Write(admin.Controller.Database)
+		if err != nil {
+			logError(err)
+		} else {
+			err = admin.Controller.Dirwatches.Read(admin.Controller.Database)
+			if err != nil {
+				logError(err)
+			}
+		}
+	}
+
+	switch v := m["downstreams"].(type) {
+	case []any:
+		admin.Controller.Downstreams.FromMap(v)
+		err = admin.Controller.Downstreams.Write(admin.Controller.Database)
+		if err != nil {
+			logError(err)
+		} else {
+			err = admin.Controller.Downstreams.Read(admin.Controller.Database)
+			if err != nil {
+				logError(err)
+			}
+		}
+	}
+
+	switch v := m["groups"].(type) {
+	case []any:
+		admin.Controller.Groups.FromMap(v)
+		err = admin.Controller.Groups.Write(admin.Controller.Database)
+		if err != nil {
+			logError(err)
+		} else {
+			err = admin.Controller.Groups.Read(admin.Controller.Database)
+			if err != nil {
+				logError(err)
+			}
+		}
+	}
+
+	switch v := m["options"].(type) {
+	case map[string]any:
+		admin.Controller.Options.FromMap(v)
+		err = admin.Controller.Options.Write(admin.Controller.Database)
+		if err != nil {
+			logError(err)
+		} else {
+			// Reload options from database to update in-memory state
+			err = admin.Controller.Options.Read(admin.Controller.Database)
+			if err != nil {
+				logError(err)
+			} else {
+				// Restart transcription queue with updated settings
+				admin.Controller.RestartTranscriptionQueue()
+
+				// Restart no-audio monitoring in case health alert settings changed
+				go admin.Controller.StartNoAudioMonitoringForAllSystems()
+
+				// If audio encryption is enabled and we don't have a key yet
+				// (or it was just enabled), fetch the key + client token from
+				// the relay server without requiring a server restart.
+				if admin.Controller.Options.AudioEncryptionEnabled &&
+					admin.Controller.Options.RelayServerURL != "" &&
+					admin.Controller.Options.RelayServerAPIKey != "" &&
+					len(admin.Controller.AudioKey) == 0 {
+					go func() {
+						key, fetchErr := FetchAudioKeyFromRelay(
+							admin.Controller.Options.RelayServerURL,
+							admin.Controller.Options.RelayServerAPIKey,
+						)
+						if fetchErr != nil {
+							admin.Controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("audio encryption: failed to fetch key from relay after settings save: %v", fetchErr))
+							return
 						}
-					}
+						admin.Controller.AudioKey = key
+						admin.Controller.Logs.LogEvent(LogLevelInfo, "audio encryption: AES-256-GCM key loaded from relay server (triggered by settings save)")
+						admin.Controller.fetchAudioClientToken()
+					}()
+				} else if !admin.Controller.Options.AudioEncryptionEnabled {
+					// Encryption was turned off — clear keys from memory immediately.
+					admin.Controller.AudioKey = nil
+					admin.Controller.AudioClientToken = ""
+				}
+			}
+		}
+	}
+
+	// Handle Radio Reference configuration
+	switch v := m["radioReference"].(type) {
+	case map[string]any:
+		// Update the options with Radio Reference settings
+		if enabled, ok := v["enabled"].(bool); ok {
+			admin.Controller.Options.RadioReferenceEnabled = enabled
+		}
+		if username, ok := v["username"].(string); ok {
+			admin.Controller.Options.RadioReferenceUsername = username
+		}
+		if password, ok := v["password"].(string); ok {
+			admin.Controller.Options.RadioReferencePassword = password
+		}
+
+		// Save the updated options to database
+		err = admin.Controller.Options.Write(admin.Controller.Database)
+		if err != nil {
+			logError(err)
+		} else {
+			// Reload options from database to update in-memory state
+			err = admin.Controller.Options.Read(admin.Controller.Database)
+			if err != nil {
+				logError(err)
+			}
+		}
+	}
+
+	// Write tags BEFORE systems to ensure foreign key constraints are satisfied
+	// Talkgroups reference tags via foreign key, so tags must exist before talkgroups are inserted
+	switch v := m["tags"].(type) {
+	case []any:
+		admin.Controller.Tags.FromMap(v)
+		err = admin.Controller.Tags.Write(admin.Controller.Database)
+		if err != nil {
+			logError(err)
+		} else {
+			err = admin.Controller.Tags.Read(admin.Controller.Database)
+			if err != nil {
+				logError(err)
+			}
+		}
+	}
+
+	switch v := m["systems"].(type) {
+	case []any:
+		// Preserve per-system noAudioAlertsEnabled / noAudioThresholdMinutes values
+		// when the incoming config payload omits them (e.g. a normal talkgroup save from
+		// the admin UI that is unaware of the System Health tab settings).
+		// Without this, Systems.FromMap defaults noAudioAlertsEnabled to true, silently
+		// overwriting a user's "disabled" setting and causing it to reappear after restart.
+		for _, r := range v {
+			m, ok := r.(map[string]any)
+			if !ok {
+				continue
+			}
+			// Only patch fields that are completely absent from the payload
+			_, hasEnabled := m["noAudioAlertsEnabled"]
+			_, hasThreshold := m["noAudioThresholdMinutes"]
+			_, hasLifeSafety := m["lifeSafetyPhrases"]
+			_, hasGeocodeHint := m["geocodeHint"]
+			_, hasTimeZone := m["timeZone"]
+			if hasEnabled && hasThreshold && hasLifeSafety && hasGeocodeHint && hasTimeZone {
+				continue
+			}
+			// Try to find the matching existing system by id, then by systemRef
+			var existing *System
+			if idVal, ok := m["id"].(float64); ok {
+				existing, _ = admin.Controller.Systems.GetSystemById(uint64(idVal))
+			}
+			if existing == nil {
+				if refVal, ok := m["systemRef"].(float64); ok {
+					existing, _ = admin.Controller.Systems.GetSystemByRef(uint(refVal))
+				}
+			}
+			if existing != nil {
+				if !hasEnabled {
+					m["noAudioAlertsEnabled"] = existing.NoAudioAlertsEnabled
+				}
+				if !hasThreshold {
+					m["noAudioThresholdMinutes"] = existing.NoAudioThresholdMinutes
+				}
+				if !hasLifeSafety {
+					m["lifeSafetyPhrases"] = existing.LifeSafetyPhrases
+				}
+				if !hasGeocodeHint {
+					m["geocodeHint"] = existing.GeocodeHint
+				}
+				if !hasTimeZone {
+					m["timeZone"] = existing.TimeZone
+				}
+			}
+		}
+		admin.Controller.Systems.FromMap(v)
+		err = admin.Controller.Systems.Write(admin.Controller.Database)
+		if err != nil {
+			logError(err)
+			// The write transaction was rolled back, but any INSERT…RETURNING
+			// that ran before the failure may have left phantom talkgroup/site
+			// IDs in the in-memory structs.  Re-read from the DB to restore a
+			// clean, consistent in-memory state before further requests arrive.
+			if readErr := admin.Controller.Systems.Read(admin.Controller.Database); readErr != nil {
+				logError(readErr)
+			}
+			admin.Controller.Dirwatches.Start(admin.Controller)
+			return fmt.Errorf("failed to save systems: %v", err)
+		} else {
+			err = admin.Controller.Systems.Read(admin.Controller.Database)
+			if err != nil {
+				logError(err)
+			} else {
+				// Reload ID lookups cache after systems/talkgroups change
+				if err := admin.Controller.IdLookupsCache.Read(admin.Controller.Database); err != nil {
+					admin.Controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("failed to reload ID lookups cache: %v", err))
 				}
 			}
+		}
+	}
+
+	// Helper functions for imports
+	getStringFromMap := func(m map[string]any, key string) string {
+		if v, ok := m[key].(string); ok {
+			return v
+		}
+		return ""
+	}
+	getBoolFromMap := func(m map[string]any, key string, def bool) bool {
+		if v, ok := m[key].(bool); ok {
+			return v
+		}
+		return def
+	}
+	getUint64FromMap := func(m map[string]any, key string) uint64 {
+		if v, ok := m[key].(float64); ok {
+			return uint64(v)
+		}
+		return 0
+	}
+	getFloat64FromMap := func(m map[string]any, key string) float64 {
+		if v, ok := m[key].(float64); ok {
+			return v
+		}
+		return 0
+	}
+
+	// Handle user groups import
+	// Map from imported group ID to actual group ID (for user assignment)
+	groupIdMap := make(map[uint64]uint64)
+	switch v := m["userGroups"].(type) {
+	case []any:
+		// Track imported group IDs to determine which groups to delete
+		// Track actual IDs of successfully imported groups (updated or created)
+		importedGroupIds := make(map[uint64]bool)
+
+		for _, groupData := range v {
+			groupMap, ok := groupData.(map[string]any)
+			if !ok {
+				continue
+			}
 
-			// Handle Radio Reference configuration
-			switch v := m["radioReference"].(type) {
-			case map[string]any:
-				// Update the options with Radio Reference settings
-				if enabled, ok := v["enabled"].(bool); ok {
-					admin.Controller.Options.RadioReferenceEnabled = enabled
+			// Extract group data
+			id, _ := groupMap["id"].(float64)
+			name, _ := groupMap["name"].(string)
+			if name == "" {
+				continue
+			}
+
+			importedGroupId := uint64(id)
+
+			// Check if group exists by ID first, then by name
+			existingGroup := admin.Controller.UserGroups.Get(importedGroupId)
+			if existingGroup == nil {
+				// If not found by ID, try to find by name
+				existingGroup = admin.Controller.UserGroups.GetByName(name)
+			}
+			if existingGroup != nil {
+				// Fully overwrite existing group with imported data
+				existingGroup.Name = name
+				existingGroup.Description = getStringFromMap(groupMap, "description")
+				existingGroup.SystemAccess = getStringFromMap(groupMap, "systemAccess")
+				existingGroup.Delay = int(getFloat64FromMap(groupMap, "delay"))
+				existingGroup.SystemDelays = getStringFromMap(groupMap, "systemDelays")
+				existingGroup.TalkgroupDelays = getStringFromMap(groupMap, "talkgroupDelays")
+				existingGroup.ConnectionLimit = uint(getFloat64FromMap(groupMap, "connectionLimit"))
+				existingGroup.MaxUsers = uint(getFloat64FromMap(groupMap, "maxUsers"))
+				existingGroup.BillingEnabled = getBoolFromMap(groupMap, "billingEnabled", false)
+				existingGroup.StripePriceId = getStringFromMap(groupMap, "stripePriceId")
+				existingGroup.PricingOptions = getStringFromMap(groupMap, "pricingOptions")
+				existingGroup.BillingMode = getStringFromMap(groupMap, "billingMode")
+				existingGroup.CollectSalesTax = getBoolFromMap(groupMap, "collectSalesTax", false)
+				existingGroup.TaxMode = getStringFromMap(groupMap, "taxMode")
+				existingGroup.StripeTaxRateId = getStringFromMap(groupMap, "stripeTaxRateId")
+				existingGroup.IsPublicRegistration = getBoolFromMap(groupMap, "isPublicRegistration", false)
+				existingGroup.AllowAddExistingUsers = getBoolFromMap(groupMap, "allowAddExistingUsers", false)
+				if createdAt, ok := groupMap["createdAt"].(float64); ok {
+					existingGroup.CreatedAt = int64(createdAt)
 				}
-				if username, ok := v["username"].(string); ok {
-					admin.Controller.Options.RadioReferenceUsername = username
+
+				if err := admin.Controller.UserGroups.Update(existingGroup, admin.Controller.Database); err != nil {
+					logError(fmt.Errorf("failed to update imported user group %s: %v", name, err))
+				} else {
+					// Track the actual ID of the successfully updated group
+					importedGroupIds[existingGroup.Id] = true
+					// Map imported ID to actual ID (may be the same)
+					groupIdMap[importedGroupId] = existingGroup.Id
 				}
-				if password, ok := v["password"].(string); ok {
-					admin.Controller.Options.RadioReferencePassword = password
+			} else {
+				// Create new group
+				group := &UserGroup{
+					Name:                  name,
+					Description:           getStringFromMap(groupMap, "description"),
+					SystemAccess:          getStringFromMap(groupMap, "systemAccess"),
+					Delay:                 int(getFloat64FromMap(groupMap, "delay")),
+					SystemDelays:          getStringFromMap(groupMap, "systemDelays"),
+					TalkgroupDelays:       getStringFromMap(groupMap, "talkgroupDelays"),
+					ConnectionLimit:       uint(getFloat64FromMap(groupMap, "connectionLimit")),
+					MaxUsers:              uint(getFloat64FromMap(groupMap, "maxUsers")),
+					BillingEnabled:        getBoolFromMap(groupMap, "billingEnabled", false),
+					StripePriceId:         getStringFromMap(groupMap, "stripePriceId"),
+					PricingOptions:        getStringFromMap(groupMap, "pricingOptions"),
+					BillingMode:           getStringFromMap(groupMap, "billingMode"),
+					CollectSalesTax:       getBoolFromMap(groupMap, "collectSalesTax", false),
+					TaxMode:               getStringFromMap(groupMap, "taxMode"),
+					StripeTaxRateId:       getStringFromMap(groupMap, "stripeTaxRateId"),
+					IsPublicRegistration:  getBoolFromMap(groupMap, "isPublicRegistration", false),
+					AllowAddExistingUsers: getBoolFromMap(groupMap, "allowAddExistingUsers", false),
+				}
+				if createdAt, ok := groupMap["createdAt"].(float64); ok {
+					group.CreatedAt = int64(createdAt)
+				} else {
+					group.CreatedAt = time.Now().Unix()
 				}
 
-				// Save the updated options to database
-				err = admin.Controller.Options.Write(admin.Controller.Database)
-				if err != nil {
-					logError(err)
+				if err := admin.Controller.UserGroups.Add(group, admin.Controller.Database); err != nil {
+					logError(fmt.Errorf("failed to import user group %s: %v", name, err))
 				} else {
-					// Reload options from database to update in-memory state
-					err = admin.Controller.Options.Read(admin.Controller.Database)
-					if err != nil {
-						logError(err)
-					}
+					// Track the actual ID of the successfully created group (may differ from imported ID)
+					importedGroupIds[group.Id] = true
+					// Map imported ID to actual ID (will be different for new groups)
+					groupIdMap[importedGroupId] = group.Id
 				}
 			}
+		}
 
-			// Write tags BEFORE systems to ensure foreign key constraints are satisfied
-			// Talkgroups reference tags via foreign key, so tags must exist before talkgroups are inserted
-			switch v := m["tags"].(type) {
-			case []any:
-				admin.Controller.Tags.FromMap(v)
-				err = admin.Controller.Tags.Write(admin.Controller.Database)
-				if err != nil {
-					logError(err)
-				} else {
-					err = admin.Controller.Tags.Read(admin.Controller.Database)
-					if err != nil {
-						logError(err)
+		// Only delete groups not in import if this is a full import
+		// For regular saves, we preserve groups that aren't in the form data
+		if isFullImport {
+			allGroups := admin.Controller.UserGroups.GetAll()
+			for _, existingGroup := range allGroups {
+				if !importedGroupIds[existingGroup.Id] {
+					if err := admin.Controller.UserGroups.Delete(existingGroup.Id, admin.Controller.Database); err != nil {
+						logError(fmt.Errorf("failed to remove user group %d during import: %v", existingGroup.Id, err))
 					}
 				}
 			}
+		}
 
-			switch v := m["systems"].(type) {
-			case []any:
-				// Preserve per-system noAudioAlertsEnabled / noAudioThresholdMinutes values
-				// when the incoming config payload omits them (e.g. a normal talkgroup save from
-				// the admin UI that is unaware of the System Health tab settings).
-				// Without this, Systems.FromMap defaults noAudioAlertsEnabled to true, silently
-				// overwriting a user's "disabled" setting and causing it to reappear after restart.
-				for _, r := range v {
-					m, ok := r.(map[string]any)
-					if !ok {
-						continue
-					}
-					// Only patch fields that are completely absent from the payload
-					_, hasEnabled := m["noAudioAlertsEnabled"]
-					_, hasThreshold := m["noAudioThresholdMinutes"]
-					if hasEnabled && hasThreshold {
-						continue
-					}
-					// Try to find the matching existing system by id, then by systemRef
-					var existing *System
-					if idVal, ok := m["id"].(float64); ok {
-						existing, _ = admin.Controller.Systems.GetSystemById(uint64(idVal))
-					}
-					if existing == nil {
-						if refVal, ok := m["systemRef"].(float64); ok {
-							existing, _ = admin.Controller.Systems.GetSystemByRef(uint(refVal))
-						}
-					}
-					if existing != nil {
-						if !hasEnabled {
-							m["noAudioAlertsEnabled"] = existing.NoAudioAlertsEnabled
-						}
-						if !hasThreshold {
-							m["noAudioThresholdMinutes"] = existing.NoAudioThresholdMinutes
-						}
-					}
+		// Reload user groups after import
+		if err := admin.Controller.UserGroups.Load(admin.Controller.Database); err != nil {
+			logError(err)
+		}
+
+		// Rebuild groupIdMap after reload by matching imported names to actual groups
+		// This ensures the mapping is correct even if IDs don't match
+		if v, ok := m["userGroups"].([]any); ok {
+			for _, groupData := range v {
+				groupMap, ok := groupData.(map[string]any)
+				if !ok {
+					continue
 				}
-				admin.Controller.Systems.FromMap(v)
-				err = admin.Controller.Systems.Write(admin.Controller.Database)
-				if err != nil {
-					logError(err)
-					// The write transaction was rolled back, but any INSERT…RETURNING
-					// that ran before the failure may have left phantom talkgroup/site
-					// IDs in the in-memory structs.  Re-read from the DB to restore a
-					// clean, consistent in-memory state before further requests arrive.
-					if readErr := admin.Controller.Systems.Read(admin.Controller.Database); readErr != nil {
-						logError(readErr)
-					}
-					w.WriteHeader(http.StatusInternalServerError)
-					json.NewEncoder(w).Encode(map[string]string{"error": "failed to save systems: " + err.Error()})
-					admin.Controller.Dirwatches.Start(admin.Controller)
-					return
-				} else {
-					err = admin.Controller.Systems.Read(admin.Controller.Database)
-					if err != nil {
-						logError(err)
-					} else {
-						// Reload ID lookups cache after systems/talkgroups change
-						if err := admin.Controller.IdLookupsCache.Read(admin.Controller.Database); err != nil {
-							admin.Controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("failed to reload ID lookups cache: %v", err))
-						}
-					}
+
+				importedId, _ := groupMap["id"].(float64)
+				importedName, _ := groupMap["name"].(string)
+				if importedName == "" {
+					continue
 				}
-			}
 
-			// Helper functions for imports
-			getStringFromMap := func(m map[string]any, key string) string {
-				if v, ok := m[key].(string); ok {
-					return v
+				importedGroupId := uint64(importedId)
+				// Find the actual group by name (since IDs might not match)
+				if actualGroup := admin.Controller.UserGroups.GetByName(importedName); actualGroup != nil {
+					// Update the mapping with the actual ID
+					groupIdMap[importedGroupId] = actualGroup.Id
 				}
-				return ""
 			}
-			getBoolFromMap := func(m map[string]any, key string, def bool) bool {
-				if v, ok := m[key].(bool); ok {
-					return v
+		}
+	}
+
+	// Handle users import
+	// Map imported userId -> actual userId (based on email matching)
+	userIdMap := make(map[uint64]uint64)
+	switch v := m["users"].(type) {
+	case []any:
+		// Only delete ALL existing users for full imports, not regular saves
+		if isFullImport {
+			allUsers := admin.Controller.Users.GetAllUsers()
+			for _, existingUser := range allUsers {
+				// Delete dependent rows first (FK constraints have no CASCADE after migration)
+				admin.Controller.Database.Sql.Exec(`DELETE FROM "userAlertPreferences" WHERE "userId" = $1`, existingUser.Id)
+				admin.Controller.Database.Sql.Exec(`DELETE FROM "deviceTokens" WHERE "userId" = $1`, existingUser.Id)
+				// Now safe to delete the user
+				_, err := admin.Controller.Database.Sql.Exec(`DELETE FROM "users" WHERE "userId" = $1`, existingUser.Id)
+				if err != nil {
+					logError(fmt.Errorf("failed to delete user %s from database during import: %v", existingUser.Email, err))
+				} else {
+					// Remove from in-memory map
+					if err := admin.Controller.Users.Remove(existingUser.Id); err != nil {
+						logError(fmt.Errorf("failed to remove user %s from memory during import: %v", existingUser.Email, err))
+					}
 				}
-				return def
 			}
-			getUint64FromMap := func(m map[string]any, key string) uint64 {
-				if v, ok := m[key].(float64); ok {
-					return uint64(v)
-				}
-				return 0
+		}
+
+		// Now create/update users from import
+		for _, userData := range v {
+			userMap, ok := userData.(map[string]any)
+			if !ok {
+				continue
 			}
-			getFloat64FromMap := func(m map[string]any, key string) float64 {
-				if v, ok := m[key].(float64); ok {
-					return v
-				}
-				return 0
+
+			email, _ := userMap["email"].(string)
+			if email == "" {
+				continue
 			}
 
-			// Handle user groups import
-			// Map from imported group ID to actual group ID (for user assignment)
-			groupIdMap := make(map[uint64]uint64)
-			switch v := m["userGroups"].(type) {
-			case []any:
-				// Track imported group IDs to determine which groups to delete
-				// Track actual IDs of successfully imported groups (updated or created)
-				importedGroupIds := make(map[uint64]bool)
-
-				for _, groupData := range v {
-					groupMap, ok := groupData.(map[string]any)
-					if !ok {
-						continue
-					}
+			// Create new user with imported password hash
+			password, _ := userMap["password"].(string)
+			if password == "" {
+				logError(fmt.Errorf("cannot import user %s without password hash", email))
+				continue
+			}
 
-					// Extract group data
-					id, _ := groupMap["id"].(float64)
-					name, _ := groupMap["name"].(string)
-					if name == "" {
-						continue
+			// Map imported userGroupId to actual group ID
+			importedUserGroupId := getUint64FromMap(userMap, "userGroupId")
+			actualUserGroupId := uint64(0)
+			if importedUserGroupId > 0 {
+				if actualId, ok := groupIdMap[importedUserGroupId]; ok {
+					actualUserGroupId = actualId
+				} else {
+					// Group ID not found in mapping - try to find by ID in database
+					if existingGroup := admin.Controller.UserGroups.Get(importedUserGroupId); existingGroup != nil {
+						actualUserGroupId = importedUserGroupId
+					} else {
+						// Group doesn't exist - set to 0
+						actualUserGroupId = 0
+						logError(fmt.Errorf("user %s references non-existent group ID %d, setting to 0", email, importedUserGroupId))
 					}
+				}
+			}
 
-					importedGroupId := uint64(id)
+			// Track imported userId for ID mapping
+			importedUserId := getUint64FromMap(userMap, "id")
+			// Check if user already exists
+			existingUser := admin.Controller.Users.GetUserByEmail(email)
 
-					// Check if group exists by ID first, then by name
-					existingGroup := admin.Controller.UserGroups.Get(importedGroupId)
-					if existingGroup == nil {
-						// If not found by ID, try to find by name
-						existingGroup = admin.Controller.UserGroups.GetByName(name)
-					}
-					if existingGroup != nil {
-						// Fully overwrite existing group with imported data
-						existingGroup.Name = name
-						existingGroup.Description = getStringFromMap(groupMap, "description")
-						existingGroup.SystemAccess = getStringFromMap(groupMap, "systemAccess")
-						existingGroup.Delay = int(getFloat64FromMap(groupMap, "delay"))
-						existingGroup.SystemDelays = getStringFromMap(groupMap, "systemDelays")
-						existingGroup.TalkgroupDelays = getStringFromMap(groupMap, "talkgroupDelays")
-						existingGroup.ConnectionLimit = uint(getFloat64FromMap(groupMap, "connectionLimit"))
-						existingGroup.MaxUsers = uint(getFloat64FromMap(groupMap, "maxUsers"))
-						existingGroup.BillingEnabled = getBoolFromMap(groupMap, "billingEnabled", false)
-						existingGroup.StripePriceId = getStringFromMap(groupMap, "stripePriceId")
-						existingGroup.PricingOptions = getStringFromMap(groupMap, "pricingOptions")
-						existingGroup.BillingMode = getStringFromMap(groupMap, "billingMode")
-						existingGroup.CollectSalesTax = getBoolFromMap(groupMap, "collectSalesTax", false)
-						existingGroup.TaxMode = getStringFromMap(groupMap, "taxMode")
-						existingGroup.StripeTaxRateId = getStringFromMap(groupMap, "stripeTaxRateId")
-						existingGroup.IsPublicRegistration = getBoolFromMap(groupMap, "isPublicRegistration", false)
-						existingGroup.AllowAddExistingUsers = getBoolFromMap(groupMap, "allowAddExistingUsers", false)
-						if createdAt, ok := groupMap["createdAt"].(float64); ok {
-							existingGroup.CreatedAt = int64(createdAt)
-						}
+			if existingUser != nil {
+				if importedUserId != 0 {
+					userIdMap[importedUserId] = existingUser.Id
+				}
+				// Update existing user with imported data
+				existingUser.Password = password // Use imported password hash directly
+				existingUser.FirstName = getStringFromMap(userMap, "firstName")
+				existingUser.LastName = getStringFromMap(userMap, "lastName")
+				existingUser.ZipCode = getStringFromMap(userMap, "zipCode")
+				existingUser.Verified = getBoolFromMap(userMap, "verified", false)
+				existingUser.UserGroupId = actualUserGroupId
+				existingUser.IsGroupAdmin = getBoolFromMap(userMap, "isGroupAdmin", false)
+				existingUser.SystemAdmin = getBoolFromMap(userMap, "systemAdmin", false)
+				existingUser.ForcePasswordReset = getBoolFromMap(userMap, "forcePasswordReset", false)
+				existingUser.PinExpiresAt = getUint64FromMap(userMap, "pinExpiresAt")
+				existingUser.ConnectionLimit = uint(getFloat64FromMap(userMap, "connectionLimit"))
+				existingUser.Systems = getStringFromMap(userMap, "systems")
+				existingUser.Delay = int(getFloat64FromMap(userMap, "delay"))
+				existingUser.SystemDelays = getStringFromMap(userMap, "systemDelays")
+				existingUser.TalkgroupDelays = getStringFromMap(userMap, "talkgroupDelays")
+				existingUser.Settings = getStringFromMap(userMap, "settings")
+				existingUser.StripeCustomerId = getStringFromMap(userMap, "stripeCustomerId")
+				existingUser.StripeSubscriptionId = getStringFromMap(userMap, "stripeSubscriptionId")
+				existingUser.SubscriptionStatus = getStringFromMap(userMap, "subscriptionStatus")
+				existingUser.AccountExpiresAt = getUint64FromMap(userMap, "accountExpiresAt")
+
+				// Update PIN if provided in import (don't regenerate if already exists)
+				if importedPin := getStringFromMap(userMap, "pin"); importedPin != "" {
+					existingUser.Pin = importedPin
+				}
 
-						if err := admin.Controller.UserGroups.Update(existingGroup, admin.Controller.Database); err != nil {
-							logError(fmt.Errorf("failed to update imported user group %s: %v", name, err))
-						} else {
-							// Track the actual ID of the successfully updated group
-							importedGroupIds[existingGroup.Id] = true
-							// Map imported ID to actual ID (may be the same)
-							groupIdMap[importedGroupId] = existingGroup.Id
-						}
-					} else {
-						// Create new group
-						group := &UserGroup{
-							Name:                  name,
-							Description:           getStringFromMap(groupMap, "description"),
-							SystemAccess:          getStringFromMap(groupMap, "systemAccess"),
-							Delay:                 int(getFloat64FromMap(groupMap, "delay")),
-							SystemDelays:          getStringFromMap(groupMap, "systemDelays"),
-							TalkgroupDelays:       getStringFromMap(groupMap, "talkgroupDelays"),
-							ConnectionLimit:       uint(getFloat64FromMap(groupMap, "connectionLimit")),
-							MaxUsers:              uint(getFloat64FromMap(groupMap, "maxUsers")),
-							BillingEnabled:        getBoolFromMap(groupMap, "billingEnabled", false),
-							StripePriceId:         getStringFromMap(groupMap, "stripePriceId"),
-							PricingOptions:        getStringFromMap(groupMap, "pricingOptions"),
-							BillingMode:           getStringFromMap(groupMap, "billingMode"),
-							CollectSalesTax:       getBoolFromMap(groupMap, "collectSalesTax", false),
-							TaxMode:               getStringFromMap(groupMap, "taxMode"),
-							StripeTaxRateId:       getStringFromMap(groupMap, "stripeTaxRateId"),
-							IsPublicRegistration:  getBoolFromMap(groupMap, "isPublicRegistration", false),
-							AllowAddExistingUsers: getBoolFromMap(groupMap, "allowAddExistingUsers", false),
-						}
-						if createdAt, ok := groupMap["createdAt"].(float64); ok {
-							group.CreatedAt = int64(createdAt)
-						} else {
-							group.CreatedAt = time.Now().Unix()
-						}
+				// Update timestamps if provided
+				if createdAt := getStringFromMap(userMap, "createdAt"); createdAt != "" {
+					existingUser.CreatedAt = createdAt
+				}
+				if lastLogin := getStringFromMap(userMap, "lastLogin"); lastLogin != "" {
+					existingUser.LastLogin = lastLogin
+				}
 
-						if err := admin.Controller.UserGroups.Add(group, admin.Controller.Database); err != nil {
-							logError(fmt.Errorf("failed to import user group %s: %v", name, err))
-						} else {
-							// Track the actual ID of the successfully created group (may differ from imported ID)
-							importedGroupIds[group.Id] = true
-							// Map imported ID to actual ID (will be different for new groups)
-							groupIdMap[importedGroupId] = group.Id
-						}
-					}
+				// Update user in database
+				if err := admin.Controller.Users.Update(existingUser); err != nil {
+					logError(fmt.Errorf("failed to update existing user %s: %v", email, err))
+					continue
+				}
+				if err := admin.Controller.Users.Write(admin.Controller.Database); err != nil {
+					logError(fmt.Errorf("failed to write updated user %s to database: %v", email, err))
+				}
+			} else {
+				// Create new user
+				user := &User{
+					Email:                email,
+					Password:             password, // Use imported password hash directly
+					FirstName:            getStringFromMap(userMap, "firstName"),
+					LastName:             getStringFromMap(userMap, "lastName"),
+					ZipCode:              getStringFromMap(userMap, "zipCode"),
+					Verified:             getBoolFromMap(userMap, "verified", false),
+					UserGroupId:          actualUserGroupId,
+					IsGroupAdmin:         getBoolFromMap(userMap, "isGroupAdmin", false),
+					SystemAdmin:          getBoolFromMap(userMap, "systemAdmin", false),
+					ForcePasswordReset:   getBoolFromMap(userMap, "forcePasswordReset", false),
+					Pin:                  getStringFromMap(userMap, "pin"),
+					PinExpiresAt:         getUint64FromMap(userMap, "pinExpiresAt"),
+					ConnectionLimit:      uint(getFloat64FromMap(userMap, "connectionLimit")),
+					Systems:              getStringFromMap(userMap, "systems"),
+					Delay:                int(getFloat64FromMap(userMap, "delay")),
+					SystemDelays:         getStringFromMap(userMap, "systemDelays"),
+					TalkgroupDelays:      getStringFromMap(userMap, "talkgroupDelays"),
+					Settings:             getStringFromMap(userMap, "settings"),
+					StripeCustomerId:     getStringFromMap(userMap, "stripeCustomerId"),
+					StripeSubscriptionId: getStringFromMap(userMap, "stripeSubscriptionId"),
+					SubscriptionStatus:   getStringFromMap(userMap, "subscriptionStatus"),
+					AccountExpiresAt:     getUint64FromMap(userMap, "accountExpiresAt"),
+					CreatedAt:            getStringFromMap(userMap, "createdAt"),
+					LastLogin:            getStringFromMap(userMap, "lastLogin"),
 				}
 
-				// Only delete groups not in import if this is a full import
-				// For regular saves, we preserve groups that aren't in the form data
-				if isFullImport {
-					allGroups := admin.Controller.UserGroups.GetAll()
-					for _, existingGroup := range allGroups {
-						if !importedGroupIds[existingGroup.Id] {
-							if err := admin.Controller.UserGroups.Delete(existingGroup.Id, admin.Controller.Database); err != nil {
-								logError(fmt.Errorf("failed to remove user group %d during import: %v", existingGroup.Id, err))
-							}
-						}
+				// Generate PIN if not provided
+				if user.Pin == "" {
+					pin, err := admin.Controller.Users.GenerateUniquePin(0)
+					if err != nil {
+						logError(fmt.Errorf("failed to generate PIN for imported user %s: %v", email, err))
+						continue
 					}
+					user.Pin = pin
 				}
 
-				// Reload user groups after import
-				if err := admin.Controller.UserGroups.Load(admin.Controller.Database); err != nil {
-					logError(err)
+				// Set createdAt if not provided
+				if user.CreatedAt == "" {
+					user.CreatedAt = fmt.Sprintf("%d", time.Now().Unix())
 				}
 
-				// Rebuild groupIdMap after reload by matching imported names to actual groups
-				// This ensures the mapping is correct even if IDs don't match
-				if v, ok := m["userGroups"].([]any); ok {
-					for _, groupData := range v {
-						groupMap, ok := groupData.(map[string]any)
-						if !ok {
-							continue
-						}
+				if err := admin.Controller.Users.SaveNewUser(user, admin.Controller.Database); err != nil {
+					logError(fmt.Errorf("failed to import new user %s: %v", email, err))
+				} else if importedUserId != 0 {
+					if createdUser := admin.Controller.Users.GetUserByEmail(email); createdUser != nil {
+						userIdMap[importedUserId] = createdUser.Id
+					}
+				}
+			}
+		}
 
-						importedId, _ := groupMap["id"].(float64)
-						importedName, _ := groupMap["name"].(string)
-						if importedName == "" {
-							continue
-						}
+		// Reload users after import
+		if err := admin.Controller.Users.Read(admin.Controller.Database); err != nil {
+			logError(err)
+		}
+	}
 
-						importedGroupId := uint64(importedId)
-						// Find the actual group by name (since IDs might not match)
-						if actualGroup := admin.Controller.UserGroups.GetByName(importedName); actualGroup != nil {
-							// Update the mapping with the actual ID
-							groupIdMap[importedGroupId] = actualGroup.Id
-						}
-					}
+	// Handle keyword lists import
+	// NOTE: Keyword lists are user-defined and NOT part of Radio Reference data
+	// DO NOT delete/reimport keyword lists during Radio Reference imports - this preserves user's keyword lists and their IDs
+	switch v := m["keywordLists"].(type) {
+	case []any:
+		// Only import keyword lists during explicit full configuration backup/restore (not Radio Reference imports)
+		// Skip keyword list import entirely if this appears to be a Radio Reference import
+		if isFullImport && len(v) > 0 {
+			// Detect if this is a backup restore vs Radio Reference import
+			// Backup restores will have keywordListId field to preserve IDs
+			isBackupRestore := false
+			if firstList, ok := v[0].(map[string]any); ok {
+				if _, hasId := firstList["keywordListId"]; hasId {
+					isBackupRestore = true
 				}
 			}
 
-			// Handle users import
-			// Map imported userId -> actual userId (based on email matching)
-			userIdMap := make(map[uint64]uint64)
-			switch v := m["users"].(type) {
-			case []any:
-				// Only delete ALL existing users for full imports, not regular saves
-				if isFullImport {
-					allUsers := admin.Controller.Users.GetAllUsers()
-					for _, existingUser := range allUsers {
-						// Delete dependent rows first (FK constraints have no CASCADE after migration)
-						admin.Controller.Database.Sql.Exec(`DELETE FROM "userAlertPreferences" WHERE "userId" = $1`, existingUser.Id)
-						admin.Controller.Database.Sql.Exec(`DELETE FROM "deviceTokens" WHERE "userId" = $1`, existingUser.Id)
-						// Now safe to delete the user
-						_, err := admin.Controller.Database.Sql.Exec(`DELETE FROM "users" WHERE "userId" = $1`, existingUser.Id)
-						if err != nil {
-							logError(fmt.Errorf("failed to delete user %s from database during import: %v", existingUser.Email, err))
-						} else {
-							// Remove from in-memory map
-							if err := admin.Controller.Users.Remove(existingUser.Id); err != nil {
-								logError(fmt.Errorf("failed to remove user %s from memory during import: %v", existingUser.Email, err))
-							}
-						}
-					}
+			// Only process keyword lists for full backup restores, NOT Radio Reference imports
+			if isBackupRestore {
+				// Delete existing keyword lists for backup restore
+				_, err := admin.Controller.Database.Sql.Exec(`DELETE FROM "keywordLists"`)
+				if err != nil {
+					logError(fmt.Errorf("failed to delete existing keyword lists during backup restore: %v", err))
 				}
 
-				// Now create/update users from import
-				for _, userData := range v {
-					userMap, ok := userData.(map[string]any)
+				// Import keyword lists with preserved IDs
+				for _, listData := range v {
+					listMap, ok := listData.(map[string]any)
 					if !ok {
 						continue
 					}
 
-					email, _ := userMap["email"].(string)
-					if email == "" {
+					label, _ := listMap["label"].(string)
+					if label == "" {
 						continue
 					}
 
-					// Create new user with imported password hash
-					password, _ := userMap["password"].(string)
-					if password == "" {
-						logError(fmt.Errorf("cannot import user %s without password hash", email))
-						continue
+					keywordListId := uint64(getFloat64FromMap(listMap, "keywordListId"))
+					description := getStringFromMap(listMap, "description")
+					order := uint(getFloat64FromMap(listMap, "order"))
+					createdAt := int64(getFloat64FromMap(listMap, "createdAt"))
+					if createdAt == 0 {
+						createdAt = time.Now().UnixMilli()
 					}
 
-					// Map imported userGroupId to actual group ID
-					importedUserGroupId := getUint64FromMap(userMap, "userGroupId")
-					actualUserGroupId := uint64(0)
-					if importedUserGroupId > 0 {
-						if actualId, ok := groupIdMap[importedUserGroupId]; ok {
-							actualUserGroupId = actualId
-						} else {
-							// Group ID not found in mapping - try to find by ID in database
-							if existingGroup := admin.Controller.UserGroups.Get(importedUserGroupId); existingGroup != nil {
-								actualUserGroupId = importedUserGroupId
-							} else {
-								// Group doesn't exist - set to 0
-								actualUserGroupId = 0
-								logError(fmt.Errorf("user %s references non-existent group ID %d, setting to 0", email, importedUserGroupId))
+					// Get keywords array
+					var keywords []string
+					if keywordsData, ok := listMap["keywords"].([]any); ok {
+						for _, kw := range keywordsData {
+							if k, ok := kw.(string); ok {
+								keywords = append(keywords, k)
 							}
 						}
 					}
 
-					// Track imported userId for ID mapping
-					importedUserId := getUint64FromMap(userMap, "id")
-					// Check if user already exists
-					existingUser := admin.Controller.Users.GetUserByEmail(email)
-
-					if existingUser != nil {
-						if importedUserId != 0 {
-							userIdMap[importedUserId] = existingUser.Id
-						}
-						// Update existing user with imported data
-						existingUser.Password = password // Use imported password hash directly
-						existingUser.FirstName = getStringFromMap(userMap, "firstName")
-						existingUser.LastName = getStringFromMap(userMap, "lastName")
-						existingUser.ZipCode = getStringFromMap(userMap, "zipCode")
-						existingUser.Verified = getBoolFromMap(userMap, "verified", false)
-						existingUser.UserGroupId = actualUserGroupId
-						existingUser.IsGroupAdmin = getBoolFromMap(userMap, "isGroupAdmin", false)
-						existingUser.SystemAdmin = getBoolFromMap(userMap, "systemAdmin", false)
-						existingUser.ForcePasswordReset = getBoolFromMap(userMap, "forcePasswordReset", false)
-						existingUser.PinExpiresAt = getUint64FromMap(userMap, "pinExpiresAt")
-						existingUser.ConnectionLimit = uint(getFloat64FromMap(userMap, "connectionLimit"))
-						existingUser.Systems = getStringFromMap(userMap, "systems")
-						existingUser.Delay = int(getFloat64FromMap(userMap, "delay"))
-						existingUser.SystemDelays = getStringFromMap(userMap, "systemDelays")
-						existingUser.TalkgroupDelays = getStringFromMap(userMap, "talkgroupDelays")
-						existingUser.Settings = getStringFromMap(userMap, "settings")
-						existingUser.StripeCustomerId = getStringFromMap(userMap, "stripeCustomerId")
-						existingUser.StripeSubscriptionId = getStringFromMap(userMap, "stripeSubscriptionId")
-						existingUser.SubscriptionStatus = getStringFromMap(userMap, "subscriptionStatus")
-						existingUser.AccountExpiresAt = getUint64FromMap(userMap, "accountExpiresAt")
-
-						// Update PIN if provided in import (don't regenerate if already exists)
-						if importedPin := getStringFromMap(userMap, "pin"); importedPin != "" {
-							existingUser.Pin = importedPin
-						}
-
-						// Update timestamps if provided
-						if createdAt := getStringFromMap(userMap, "createdAt"); createdAt != "" {
-							existingUser.CreatedAt = createdAt
-						}
-						if lastLogin := getStringFromMap(userMap, "lastLogin"); lastLogin != "" {
-							existingUser.LastLogin = lastLogin
-						}
+					keywordsJson, _ := json.Marshal(keywords)
 
-						// Update user in database
-						if err := admin.Controller.Users.Update(existingUser); err != nil {
-							logError(fmt.Errorf("failed to update existing user %s: %v", email, err))
-							continue
-						}
-						if err := admin.Controller.Users.Write(admin.Controller.Database); err != nil {
-							logError(fmt.Errorf("failed to write updated user %s to database: %v", email, err))
+					// Insert keyword list with preserved ID
+					if admin.Controller.Database.Config.DbType == DbTypePostgresql {
+						query := `INSERT INTO "keywordLists" ("keywordListId", "label", "description", "keywords", "order", "createdAt") VALUES ($1, $2, $3, $4, $5, $6)`
+						if _, err := admin.Controller.Database.Sql.Exec(query, keywordListId, label, description, string(keywordsJson), order, createdAt); err != nil {
+							logError(fmt.Errorf("failed to import keyword list %s with ID %d: %v", label, keywordListId, err))
 						}
 					} else {
-						// Create new user
-						user := &User{
-							Email:                email,
-							Password:             password, // Use imported password hash directly
-							FirstName:            getStringFromMap(userMap, "firstName"),
-							LastName:             getStringFromMap(userMap, "lastName"),
-							ZipCode:              getStringFromMap(userMap, "zipCode"),
-							Verified:             getBoolFromMap(userMap, "verified", false),
-							UserGroupId:          actualUserGroupId,
-							IsGroupAdmin:         getBoolFromMap(userMap, "isGroupAdmin", false),
-							SystemAdmin:          getBoolFromMap(userMap, "systemAdmin", false),
-							ForcePasswordReset:   getBoolFromMap(userMap, "forcePasswordReset", false),
-							Pin:                  getStringFromMap(userMap, "pin"),
-							PinExpiresAt:         getUint64FromMap(userMap, "pinExpiresAt"),
-							ConnectionLimit:      uint(getFloat64FromMap(userMap, "connectionLimit")),
-							Systems:              getStringFromMap(userMap, "systems"),
-							Delay:                int(getFloat64FromMap(userMap, "delay")),
-							SystemDelays:         getStringFromMap(userMap, "systemDelays"),
-							TalkgroupDelays:      getStringFromMap(userMap, "talkgroupDelays"),
-							Settings:             getStringFromMap(userMap, "settings"),
-							StripeCustomerId:     getStringFromMap(userMap, "stripeCustomerId"),
-							StripeSubscriptionId: getStringFromMap(userMap, "stripeSubscriptionId"),
-							SubscriptionStatus:   getStringFromMap(userMap, "subscriptionStatus"),
-							AccountExpiresAt:     getUint64FromMap(userMap, "accountExpiresAt"),
-							CreatedAt:            getStringFromMap(userMap, "createdAt"),
-							LastLogin:            getStringFromMap(userMap, "lastLogin"),
-						}
-
-						// Generate PIN if not provided
-						if user.Pin == "" {
-							pin, err := admin.Controller.Users.GenerateUniquePin(0)
-							if err != nil {
-								logError(fmt.Errorf("failed to generate PIN for imported user %s: %v", email, err))
-								continue
-							}
-							user.Pin = pin
-						}
-
-						// Set createdAt if not provided
-						if user.CreatedAt == "" {
-							user.CreatedAt = fmt.Sprintf("%d", time.Now().Unix())
-						}
-
-						if err := admin.Controller.Users.SaveNewUser(user, admin.Controller.Database); err != nil {
-							logError(fmt.Errorf("failed to import new user %s: %v", email, err))
-						} else if importedUserId != 0 {
-							if createdUser := admin.Controller.Users.GetUserByEmail(email); createdUser != nil {
-								userIdMap[importedUserId] = createdUser.Id
-							}
+						query := `INSERT INTO "keywordLists" ("keywordListId", "label", "description", "keywords", "order", "createdAt") VALUES (?, ?, ?, ?, ?, ?)`
+						if _, err := admin.Controller.Database.Sql.Exec(query, keywordListId, label, description, string(keywordsJson), order, createdAt); err != nil {
+							logError(fmt.Errorf("failed to import keyword list %s with ID %d: %v", label, keywordListId, err))
 						}
 					}
 				}
+			}
+			// Radio Reference imports (without keywordListId) will skip keyword list processing entirely
+		}
+	}
 
-				// Reload users after import
-				if err := admin.Controller.Users.Read(admin.Controller.Database); err != nil {
-					logError(err)
-				}
+	// Handle user alert preferences import (map imported userId -> actual userId)
+	// ONLY delete and re-import if this is a full import, not a regular config save
+	if isFullImport {
+		switch v := m["userAlertPreferences"].(type) {
+		case []any:
+			// Delete ALL existing user alert preferences first (only during full import)
+			_, err := admin.Controller.Database.Sql.Exec(`DELETE FROM "userAlertPreferences"`)
+			if err != nil {
+				logError(fmt.Errorf("failed to delete existing user alert preferences during import: %v", err))
 			}
 
-			// Handle keyword lists import
-			// NOTE: Keyword lists are user-defined and NOT part of Radio Reference data
-			// DO NOT delete/reimport keyword lists during Radio Reference imports - this preserves user's keyword lists and their IDs
-			switch v := m["keywordLists"].(type) {
-			case []any:
-				// Only import keyword lists during explicit full configuration backup/restore (not Radio Reference imports)
-				// Skip keyword list import entirely if this appears to be a Radio Reference import
-				if isFullImport && len(v) > 0 {
-					// Detect if this is a backup restore vs Radio Reference import
-					// Backup restores will have keywordListId field to preserve IDs
-					isBackupRestore := false
-					if firstList, ok := v[0].(map[string]any); ok {
-						if _, hasId := firstList["keywordListId"]; hasId {
-							isBackupRestore = true
-						}
-					}
+			// Import all user alert preferences
+			for _, prefData := range v {
+				prefMap, ok := prefData.(map[string]any)
+				if !ok {
+					continue
+				}
 
-					// Only process keyword lists for full backup restores, NOT Radio Reference imports
-					if isBackupRestore {
-						// Delete existing keyword lists for backup restore
-						_, err := admin.Controller.Database.Sql.Exec(`DELETE FROM "keywordLists"`)
-						if err != nil {
-							logError(fmt.Errorf("failed to delete existing keyword lists during backup restore: %v", err))
-						}
+				importedUserId := uint64(getFloat64FromMap(prefMap, "userId"))
+				systemId := uint64(getFloat64FromMap(prefMap, "systemId"))
+				talkgroupId := uint64(getFloat64FromMap(prefMap, "talkgroupId"))
 
-						// Import keyword lists with preserved IDs
-						for _, listData := range v {
-							listMap, ok := listData.(map[string]any)
-							if !ok {
-								continue
-							}
+				// Skip if essential fields are missing
+				if importedUserId == 0 || systemId == 0 || talkgroupId == 0 {
+					continue
+				}
 
-							label, _ := listMap["label"].(string)
-							if label == "" {
-								continue
-							}
+				actualUserId := importedUserId
+				if mappedId, ok := userIdMap[importedUserId]; ok {
+					actualUserId = mappedId
+				}
 
-							keywordListId := uint64(getFloat64FromMap(listMap, "keywordListId"))
-							description := getStringFromMap(listMap, "description")
-							order := uint(getFloat64FromMap(listMap, "order"))
-							createdAt := int64(getFloat64FromMap(listMap, "createdAt"))
-							if createdAt == 0 {
-								createdAt = time.Now().UnixMilli()
-							}
+				if admin.Controller.Users.GetUserById(actualUserId) == nil {
+					continue
+				}
 
-							// Get keywords array
-							var keywords []string
-							if keywordsData, ok := listMap["keywords"].([]any); ok {
-								for _, kw := range keywordsData {
-									if k, ok := kw.(string); ok {
-										keywords = append(keywords, k)
-									}
-								}
-							}
+				alertEnabled := getBoolFromMap(prefMap, "alertEnabled", false)
+				toneAlerts := getBoolFromMap(prefMap, "toneAlerts", true)
+				keywordAlerts := getBoolFromMap(prefMap, "keywordAlerts", true)
 
-							keywordsJson, _ := json.Marshal(keywords)
-
-							// Insert keyword list with preserved ID
-							if admin.Controller.Database.Config.DbType == DbTypePostgresql {
-								query := `INSERT INTO "keywordLists" ("keywordListId", "label", "description", "keywords", "order", "createdAt") VALUES ($1, $2, $3, $4, $5, $6)`
-								if _, err := admin.Controller.Database.Sql.Exec(query, keywordListId, label, description, string(keywordsJson), order, createdAt); err != nil {
-									logError(fmt.Errorf("failed to import keyword list %s with ID %d: %v", label, keywordListId, err))
-								}
-							} else {
-								query := `INSERT INTO "keywordLists" ("keywordListId", "label", "description", "keywords", "order", "createdAt") VALUES (?, ?, ?, ?, ?, ?)`
-								if _, err := admin.Controller.Database.Sql.Exec(query, keywordListId, label, description, string(keywordsJson), order, createdAt); err != nil {
-									logError(fmt.Errorf("failed to import keyword list %s with ID %d: %v", label, keywordListId, err))
-								}
-							}
+				// Get keywords array
+				var keywords []string
+				if keywordsData, ok := prefMap["keywords"].([]any); ok {
+					for _, kw := range keywordsData {
+						if k, ok := kw.(string); ok {
+							keywords = append(keywords, k)
 						}
 					}
-					// Radio Reference imports (without keywordListId) will skip keyword list processing entirely
 				}
-			}
-
-			// Handle user alert preferences import (map imported userId -> actual userId)
-			// ONLY delete and re-import if this is a full import, not a regular config save
-			if isFullImport {
-				switch v := m["userAlertPreferences"].(type) {
-				case []any:
-					// Delete ALL existing user alert preferences first (only during full import)
-					_, err := admin.Controller.Database.Sql.Exec(`DELETE FROM "userAlertPreferences"`)
-					if err != nil {
-						logError(fmt.Errorf("failed to delete existing user alert preferences during import: %v", err))
-					}
-
-					// Import all user alert preferences
-					for _, prefData := range v {
-						prefMap, ok := prefData.(map[string]any)
-						if !ok {
-							continue
-						}
-
-						importedUserId := uint64(getFloat64FromMap(prefMap, "userId"))
-						systemId := uint64(getFloat64FromMap(prefMap, "systemId"))
-						talkgroupId := uint64(getFloat64FromMap(prefMap, "talkgroupId"))
-
-						// Skip if essential fields are missing
-						if importedUserId == 0 || systemId == 0 || talkgroupId == 0 {
-							continue
-						}
-
-						actualUserId := importedUserId
-						if mappedId, ok := userIdMap[importedUserId]; ok {
-							actualUserId = mappedId
-						}
-
-						if admin.Controller.Users.GetUserById(actualUserId) == nil {
-							continue
-						}
-
-						alertEnabled := getBoolFromMap(prefMap, "alertEnabled", false)
-						toneAlerts := getBoolFromMap(prefMap, "toneAlerts", true)
-						keywordAlerts := getBoolFromMap(prefMap, "keywordAlerts", true)
-
-						// Get keywords array
-						var keywords []string
-						if keywordsData, ok := prefMap["keywords"].([]any); ok {
-							for _, kw := range keywordsData {
-								if k, ok := kw.(string); ok {
-									keywords = append(keywords, k)
-								}
-							}
-						}
 
-						// Get keywordListIds array
-						var keywordListIds []int
-						if keywordListIdsData, ok := prefMap["keywordListIds"].([]any); ok {
-							for _, kid := range keywordListIdsData {
-								if k, ok := kid.(float64); ok {
-									keywordListIds = append(keywordListIds, int(k))
-								}
-							}
-						}
-
-						// Get toneSetIds array
-						var toneSetIds []string
-						if toneSetIdsData, ok := prefMap["toneSetIds"].([]any); ok {
-							for _, tid := range toneSetIdsData {
-								if t, ok := tid.(string); ok {
-									toneSetIds = append(toneSetIds, t)
-								}
-							}
+				// Get keywordListIds array
+				var keywordListIds []int
+				if keywordListIdsData, ok := prefMap["keywordListIds"].([]any); ok {
+					for _, kid := range keywordListIdsData {
+						if k, ok := kid.(float64); ok {
+							keywordListIds = append(keywordListIds, int(k))
 						}
+					}
+				}
 
-						// Use empty slices instead of nil so we always store "[]" not "null"
-						if keywords == nil {
-							keywords = []string{}
-						}
-						if keywordListIds == nil {
-							keywordListIds = []int{}
-						}
-						if toneSetIds == nil {
-							toneSetIds = []string{}
-						}
-						keywordsJson, _ := json.Marshal(keywords)
-						keywordListIdsJson, _ := json.Marshal(keywordListIds)
-						toneSetIdsJson, _ := json.Marshal(toneSetIds)
-
-						// Insert user alert preference using parameterized queries
-						if admin.Controller.Database.Config.DbType == DbTypePostgresql {
-							query := `INSERT INTO "userAlertPreferences" ("userId", "systemId", "talkgroupId", "alertEnabled", "toneAlerts", "keywordAlerts", "keywords", "keywordListIds", "toneSetIds") VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`
-							if _, err := admin.Controller.Database.Sql.Exec(query, actualUserId, systemId, talkgroupId, alertEnabled, toneAlerts, keywordAlerts, string(keywordsJson), string(keywordListIdsJson), string(toneSetIdsJson)); err != nil {
-								logError(fmt.Errorf("failed to import user alert preference for userId=%d (mapped from %d), systemId=%d, talkgroupId=%d: %v", actualUserId, importedUserId, systemId, talkgroupId, err))
-							}
-						} else {
-							query := `INSERT INTO "userAlertPreferences" ("userId", "systemId", "talkgroupId", "alertEnabled", "toneAlerts", "keywordAlerts", "keywords", "keywordListIds", "toneSetIds") VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
-							if _, err := admin.Controller.Database.Sql.Exec(query, actualUserId, systemId, talkgroupId, alertEnabled, toneAlerts, keywordAlerts, string(keywordsJson), string(keywordListIdsJson), string(toneSetIdsJson)); err != nil {
-								logError(fmt.Errorf("failed to import user alert preference for userId=%d (mapped from %d), systemId=%d, talkgroupId=%d: %v", actualUserId, importedUserId, systemId, talkgroupId, err))
-							}
+				// Get toneSetIds array
+				var toneSetIds []string
+				if toneSetIdsData, ok := prefMap["toneSetIds"].([]any); ok {
+					for _, tid := range toneSetIdsData {
+						if t, ok := tid.(string); ok {
+							toneSetIds = append(toneSetIds, t)
 						}
 					}
 				}
 
-				// Handle device tokens import (map imported userId -> actual userId)
-				switch v := m["deviceTokens"].(type) {
-				case []any:
-					// Delete ALL existing device tokens first (only during full import)
-					_, err := admin.Controller.Database.Sql.Exec(`DELETE FROM "deviceTokens"`)
-					if err != nil {
-						logError(fmt.Errorf("failed to delete existing device tokens during import: %v", err))
+				// Use empty slices instead of nil so we always store "[]" not "null"
+				if keywords == nil {
+					keywords = []string{}
+				}
+				if keywordListIds == nil {
+					keywordListIds = []int{}
+				}
+				if toneSetIds == nil {
+					toneSetIds = []string{}
+				}
+				keywordsJson, _ := json.Marshal(keywords)
+				keywordListIdsJson, _ := json.Marshal(keywordListIds)
+				toneSetIdsJson, _ := json.Marshal(toneSetIds)
+
+				// Insert user alert preference using parameterized queries
+				if admin.Controller.Database.Config.DbType == DbTypePostgresql {
+					query := `INSERT INTO "userAlertPreferences" ("userId", "systemId", "talkgroupId", "alertEnabled", "toneAlerts", "keywordAlerts", "keywords", "keywordListIds", "toneSetIds") VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`
+					if _, err := admin.Controller.Database.Sql.Exec(query, actualUserId, systemId, talkgroupId, alertEnabled, toneAlerts, keywordAlerts, string(keywordsJson), string(keywordListIdsJson), string(toneSetIdsJson)); err != nil {
+						logError(fmt.Errorf("failed to import user alert preference for userId=%d (mapped from %d), systemId=%d, talkgroupId=%d: %v", actualUserId, importedUserId, systemId, talkgroupId, err))
 					}
+				} else {
+					query := `INSERT INTO "userAlertPreferences" ("userId", "systemId", "talkgroupId", "alertEnabled", "toneAlerts", "keywordAlerts", "keywords", "keywordListIds", "toneSetIds") VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
+					if _, err := admin.Controller.Database.Sql.Exec(query, actualUserId, systemId, talkgroupId, alertEnabled, toneAlerts, keywordAlerts, string(keywordsJson), string(keywordListIdsJson), string(toneSetIdsJson)); err != nil {
+						logError(fmt.Errorf("failed to import user alert preference for userId=%d (mapped from %d), systemId=%d, talkgroupId=%d: %v", actualUserId, importedUserId, systemId, talkgroupId, err))
+					}
+				}
+			}
+		}
 
-					for _, tokenData := range v {
-						tokenMap, ok := tokenData.(map[string]any)
-						if !ok {
-							continue
-						}
+		// Handle device tokens import (map imported userId -> actual userId)
+		switch v := m["deviceTokens"].(type) {
+		case []any:
+			// Delete ALL existing device tokens first (only during full import)
+			_, err := admin.Controller.Database.Sql.Exec(`DELETE FROM "deviceTokens"`)
+			if err != nil {
+				logError(fmt.Errorf("failed to delete existing device tokens during import: %v", err))
+			}
 
-						importedUserId := uint64(getFloat64FromMap(tokenMap, "userId"))
-						token := getStringFromMap(tokenMap, "token")
-						if importedUserId == 0 || token == "" {
-							continue
-						}
+			for _, tokenData := range v {
+				tokenMap, ok := tokenData.(map[string]any)
+				if !ok {
+					continue
+				}
 
-						actualUserId := importedUserId
-						if mappedId, ok := userIdMap[importedUserId]; ok {
-							actualUserId = mappedId
-						}
-						if admin.Controller.Users.GetUserById(actualUserId) == nil {
-							continue
-						}
+				importedUserId := uint64(getFloat64FromMap(tokenMap, "userId"))
+				token := getStringFromMap(tokenMap, "token")
+				if importedUserId == 0 || token == "" {
+					continue
+				}
 
-						platform := getStringFromMap(tokenMap, "platform")
-						sound := getStringFromMap(tokenMap, "sound")
-						createdAt := int64(getFloat64FromMap(tokenMap, "createdAt"))
-						lastUsed := int64(getFloat64FromMap(tokenMap, "lastUsed"))
-						if createdAt == 0 {
-							createdAt = time.Now().Unix()
-						}
-						if lastUsed == 0 {
-							lastUsed = createdAt
-						}
+				actualUserId := importedUserId
+				if mappedId, ok := userIdMap[importedUserId]; ok {
+					actualUserId = mappedId
+				}
+				if admin.Controller.Users.GetUserById(actualUserId) == nil {
+					continue
+				}
 
-						if admin.Controller.Database.Config.DbType == DbTypePostgresql {
-							query := `INSERT INTO "deviceTokens" ("userId", "token", "platform", "sound", "createdAt", "lastUsed") VALUES ($1, $2, $3, $4, $5, $6)`
-							if _, err := admin.Controller.Database.Sql.Exec(query, actualUserId, token, platform, sound, createdAt, lastUsed); err != nil {
-								logError(fmt.Errorf("failed to import device token for userId=%d (mapped from %d): %v", actualUserId, importedUserId, err))
-							}
-						} else {
-							query := `INSERT INTO "deviceTokens" ("userId", "token", "platform", "sound", "createdAt", "lastUsed") VALUES (?, ?, ?, ?, ?, ?)`
-							if _, err := admin.Controller.Database.Sql.Exec(query, actualUserId, token, platform, sound, createdAt, lastUsed); err != nil {
-								logError(fmt.Errorf("failed to import device token for userId=%d (mapped from %d): %v", actualUserId, importedUserId, err))
-							}
-						}
-					}
+				platform := getStringFromMap(tokenMap, "platform")
+				sound := getStringFromMap(tokenMap, "sound")
+				createdAt := int64(getFloat64FromMap(tokenMap, "createdAt"))
+				lastUsed := int64(getFloat64FromMap(tokenMap, "lastUsed"))
+				if createdAt == 0 {
+					createdAt = time.Now().Unix()
+				}
+				if lastUsed == 0 {
+					lastUsed = createdAt
+				}
 
-					// Reload device tokens into memory
-					admin.Controller.DeviceTokens.mutex.Lock()
-					admin.Controller.DeviceTokens.tokens = make(map[uint64]*DeviceToken)
-					query := `SELECT "deviceTokenId", "userId", "token", "platform", "sound", "createdAt", "lastUsed" FROM "deviceTokens"`
-					rows, err := admin.Controller.Database.Sql.Query(query)
-					if err == nil {
-						defer rows.Close()
-						for rows.Next() {
-							var dt DeviceToken
-							if err := rows.Scan(&dt.Id, &dt.UserId, &dt.Token, &dt.Platform, &dt.Sound, &dt.CreatedAt, &dt.LastUsed); err == nil {
-								admin.Controller.DeviceTokens.tokens[dt.Id] = &dt
-							}
-						}
+				if admin.Controller.Database.Config.DbType == DbTypePostgresql {
+					query := `INSERT INTO "deviceTokens" ("userId", "token", "platform", "sound", "createdAt", "lastUsed") VALUES ($1, $2, $3, $4, $5, $6)`
+					if _, err := admin.Controller.Database.Sql.Exec(query, actualUserId, token, platform, sound, createdAt, lastUsed); err != nil {
+						logError(fmt.Errorf("failed to import device token for userId=%d (mapped from %d): %v", actualUserId, importedUserId, err))
+					}
+				} else {
+					query := `INSERT INTO "deviceTokens" ("userId", "token", "platform", "sound", "createdAt", "lastUsed") VALUES (?, ?, ?, ?, ?, ?)`
+					if _, err := admin.Controller.Database.Sql.Exec(query, actualUserId, token, platform, sound, createdAt, lastUsed); err != nil {
+						logError(fmt.Errorf("failed to import device token for userId=%d (mapped from %d): %v", actualUserId, importedUserId, err))
 					}
-					admin.Controller.DeviceTokens.mutex.Unlock()
 				}
-			} // End isFullImport check for userAlertPreferences and deviceTokens
+			}
 
-			// Emit config asynchronously to avoid blocking
-			go admin.Controller.EmitConfig()
-			admin.Controller.Dirwatches.Start(admin.Controller)
+			// Reload device tokens into memory
+			admin.Controller.DeviceTokens.mutex.Lock()
+			admin.Controller.DeviceTokens.tokens = make(map[uint64]*DeviceToken)
+			query := `SELECT "deviceTokenId", "userId", "token", "platform", "sound", "createdAt", "lastUsed" FROM "deviceTokens"`
+			rows, err := admin.Controller.Database.Sql.Query(query)
+			if err == nil {
+				defer rows.Close()
+				for rows.Next() {
+					var dt DeviceToken
+					if err := rows.Scan(&dt.Id, &dt.UserId, &dt.Token, &dt.Platform, &dt.Sound, &dt.CreatedAt, &dt.LastUsed); err == nil {
+						admin.Controller.DeviceTokens.tokens[dt.Id] = &dt
+					}
+				}
+			}
+			admin.Controller.DeviceTokens.mutex.Unlock()
+		}
+	} // End isFullImport check for userAlertPreferences and deviceTokens
 
-			// Sync config to file if enabled
-			admin.Controller.SyncConfigToFile()
+	// Emit config asynchronously to avoid blocking
+	go admin.Controller.EmitConfig()
+	admin.Controller.Dirwatches.Start(admin.Controller)
 
-			admin.SendConfig(w)
+	// Sync config to file if enabled
+	admin.Controller.SyncConfigToFile()
 
-			admin.Controller.Logs.LogEvent(LogLevelWarn, "configuration changed")
+	admin.Controller.Logs.LogEvent(LogLevelWarn, "configuration changed")
 
-		default:
-			w.WriteHeader(http.StatusMethodNotAllowed)
-		}
-	}
+	return nil
 }
 
 // OptionsPatchHandler is the API-driven entry point for the admin Options screens.
@@ -2329,7 +2417,7 @@ func (admin *Admin) ApikeysHandler(w http.ResponseWriter, r *http.Request) {
 //	PUT /api/admin/tags    body: [...]  (full list)
 func (admin *Admin) TagsHandler(w http.ResponseWriter, r *http.Request) {
 	t := admin.GetAuthorization(r)
-	if !admin.ValidateToken(t) {
+	if !admin.ValidatePermission(t, PermissionManageTalkgroups) {
 		w.WriteHeader(http.StatusUnauthorized)
 		return
 	}
@@ -2380,7 +2468,7 @@ func (admin *Admin) TagsHandler(w http.ResponseWriter, r *http.Request) {
 //	PUT /api/admin/talkgroup-groups    body: [...]  (full list)
 func (admin *Admin) GroupsConfigHandler(w http.ResponseWriter, r *http.Request) {
 	t := admin.GetAuthorization(r)
-	if !admin.ValidateToken(t) {
+	if !admin.ValidatePermission(t, PermissionManageTalkgroups) {
 		w.WriteHeader(http.StatusUnauthorized)
 		return
 	}
@@ -2545,7 +2633,7 @@ func (admin *Admin) SystemSaveHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	t := admin.GetAuthorization(r)
-	if !admin.ValidateToken(t) {
+	if !admin.ValidatePermission(t, PermissionManageSystems) {
 		w.WriteHeader(http.StatusUnauthorized)
 		return
 	}
@@ -2568,6 +2656,20 @@ func (admin *Admin) SystemSaveHandler(w http.ResponseWriter, r *http.Request) {
 			existing, _ = admin.Controller.Systems.GetSystemByRef(uint(refVal))
 		}
 	}
+	tenant := admin.tokenTenant(t)
+	if tenant != nil && existing != nil && !tenant.HasSystem(existing.Id) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"error": "system not found"})
+		return
+	}
+	if tenant != nil {
+		if idVal, ok := incoming["id"].(float64); ok && idVal > 0 && !tenant.HasSystem(uint64(idVal)) {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(map[string]string{"error": "system not found"})
+			return
+		}
+	}
+
 	if existing != nil {
 		if _, has := incoming["noAudioAlertsEnabled"]; !has {
 			incoming["noAudioAlertsEnabled"] = existing.NoAudioAlertsEnabled
@@ -2575,6 +2677,15 @@ func (admin *Admin) SystemSaveHandler(w http.ResponseWriter, r *http.Request) {
 		if _, has := incoming["noAudioThresholdMinutes"]; !has {
 			incoming["noAudioThresholdMinutes"] = existing.NoAudioThresholdMinutes
 		}
+		if _, has := incoming["lifeSafetyPhrases"]; !has {
+			incoming["lifeSafetyPhrases"] = existing.LifeSafetyPhrases
+		}
+		if _, has := incoming["geocodeHint"]; !has {
+			incoming["geocodeHint"] = existing.GeocodeHint
+		}
+		if _, has := incoming["timeZone"]; !has {
+			incoming["timeZone"] = existing.TimeZone
+		}
 	}
 
 	admin.mutex.Lock()
@@ -2627,11 +2738,22 @@ func (admin *Admin) SystemSaveHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Systems created by a tenant administrator belong to their tenant
+	if tenant != nil && existing == nil {
+		if refVal, ok := incoming["systemRef"].(float64); ok {
+			if system, ok := admin.Controller.Systems.GetSystemByRef(uint(refVal)); ok {
+				if err := admin.Controller.Tenants.adopt(tenant.Id, system.Id, 0, admin.Controller.Database); err != nil {
+					admin.Controller.Logs.LogEvent(LogLevelError, fmt.Sprintf("admin.systems.save: tenant %d: %s", tenant.Id, err.Error()))
+				}
+			}
+		}
+	}
+
 	go admin.Controller.EmitConfig()
 	admin.Controller.SyncConfigToFile()
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]any{"systems": admin.Controller.Systems.List})
+	json.NewEncoder(w).Encode(map[string]any{"systems": admin.tenantSystems(t)})
 }
 
 // SystemDeleteHandler deletes a SINGLE system by id.
@@ -2644,7 +2766,7 @@ func (admin *Admin) SystemDeleteHandler(w http.ResponseWriter, r *http.Request)
 	}
 
 	t := admin.GetAuthorization(r)
-	if !admin.ValidateToken(t) {
+	if !admin.ValidatePermission(t, PermissionManageSystems) {
 		w.WriteHeader(http.StatusUnauthorized)
 		return
 	}
@@ -2657,6 +2779,12 @@ func (admin *Admin) SystemDeleteHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	if !admin.tenantAllowsSystem(t, id) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"error": "system not found"})
+		return
+	}
+
 	admin.mutex.Lock()
 
 	currentJSON, mErr := json.Marshal(admin.Controller.Systems.List)
@@ -2703,7 +2831,7 @@ func (admin *Admin) SystemDeleteHandler(w http.ResponseWriter, r *http.Request)
 	admin.Controller.SyncConfigToFile()
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]any{"systems": admin.Controller.Systems.List})
+	json.NewEncoder(w).Encode(map[string]any{"systems": admin.tenantSystems(t)})
 }
 
 func (admin *Admin) StripeSyncHandler(w http.ResponseWriter, r *http.Request) {
@@ -3077,7 +3205,7 @@ func (admin *Admin) LogsHandler(w http.ResponseWriter, r *http.Request) {
 
 func (admin *Admin) CallsHandler(w http.ResponseWriter, r *http.Request) {
 	t := admin.GetAuthorization(r)
-	if !admin.ValidateToken(t) {
+	if !admin.ValidatePermission(t, PermissionExportCalls) {
 		w.WriteHeader(http.StatusUnauthorized)
 		return
 	}
@@ -3099,6 +3227,9 @@ func (admin *Admin) CallsHandler(w http.ResponseWriter, r *http.Request) {
 			User:                    nil,
 			BypassPlaybackSearchACL: true,
 		}
+		if tenant := admin.tokenTenant(t); tenant != nil {
+			adminClient.TenantSystemIds = tenant.SystemIds
+		}
 
 		results, err := admin.Controller.Calls.Search(callOptions, adminClient)
 		if err != nil {
@@ -3257,6 +3388,7 @@ func (admin *Admin) LoginConfigHandler(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]any{
 		"adminPasswordLoginDisabled": admin.Controller.Options.AdminPasswordLoginDisabled,
+		"firstRunRequired":           admin.Controller.FirstRun.Required(),
 		"version":                    Version,
 	})
 }
@@ -3273,7 +3405,7 @@ func (admin *Admin) SSOLoginHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Apply the same IP restrictions as the normal admin login
-	clientIP := GetClientIP(r)
+	clientIP := GetRemoteAddr(r)
 	if !admin.isAdminIPAllowed(clientIP) {
 		w.WriteHeader(http.StatusForbidden)
 		json.NewEncoder(w).Encode(map[string]string{"error": "Admin access denied: your IP address is not on the admin allow list"})
@@ -3289,29 +3421,36 @@ func (admin *Admin) SSOLoginHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if remaining := admin.Controller.PinAttemptTracker.GetRemainingBlockTime(clientIP); remaining > 0 {
+		writeLockout(w, r, remaining)
+		return
+	}
+
 	// Look up the user by PIN
 	user := admin.Controller.Users.GetUserByPin(body.Pin)
 	if user == nil {
+		admin.Controller.PinAttemptTracker.RecordFailedAttempt(clientIP)
 		admin.Controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("admin: SSO login failed — unknown PIN from %s", clientIP))
 		w.WriteHeader(http.StatusUnauthorized)
 		json.NewEncoder(w).Encode(map[string]string{"error": "invalid credentials"})
 		return
 	}
 
-	if !user.SystemAdmin {
-		admin.Controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("admin: SSO login denied for user %s — not a system admin", user.Email))
+	permissions := admin.Controller.Roles.Permissions(user)
+	if !user.SystemAdmin && len(permissions) == 0 {
+		admin.Controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("admin: SSO login denied for user %s — not a system admin and no role permissions", user.Email))
 		w.WriteHeader(http.StatusForbidden)
 		json.NewEncoder(w).Encode(map[string]string{"error": "user is not a system administrator"})
 		return
 	}
 
-	// Issue a standard admin JWT (same format as password login)
+	// Issue a standard admin JWT; the subject ties it to the user's permissions
 	id, err := uuid.NewRandom()
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{ID: id.String()})
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{ID: id.String(), Subject: strconv.FormatUint(user.Id, 10)})
 	sToken, err := token.SignedString([]byte(admin.Controller.Options.secret))
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
@@ -3326,14 +3465,23 @@ func (admin *Admin) SSOLoginHandler(w http.ResponseWriter, r *http.Request) {
 	}
 	admin.mutex.Unlock()
 
-	admin.Controller.Logs.LogEvent(LogLevelInfo, fmt.Sprintf("admin: SSO login granted for system admin %s from %s", user.Email, clientIP))
+	admin.Controller.Logs.LogEvent(LogLevelInfo, fmt.Sprintf("admin: SSO login granted for %s from %s (permissions: %s)", user.Email, clientIP, strings.Join(permissions, ", ")))
+
+	response := map[string]any{
+		"token":       sToken,
+		"systemAdmin": user.SystemAdmin,
+		"permissions": permissions,
+	}
+	if tenant := admin.Controller.Tenants.Of(user); tenant != nil && !user.SystemAdmin {
+		response["tenant"] = tenant.Branding()
+	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{"token": sToken})
+	json.NewEncoder(w).Encode(response)
 }
 
 func (admin *Admin) LoginHandler(w http.ResponseWriter, r *http.Request) {
-	clientIP := GetClientIP(r)
+	clientIP := GetRemoteAddr(r)
 
 	if !admin.isAdminIPAllowed(clientIP) {
 		userAgent := r.Header.Get("User-Agent")
@@ -3402,6 +3550,17 @@ func (admin *Admin) LoginHandler(w http.ResponseWriter, r *http.Request) {
 
 		switch v := m["password"].(type) {
 		case string:
+			// The default password never logs in; the administrator account is
+			// created with the first-run setup token instead
+			if v == defaults.adminPassword && admin.Controller.FirstRun.Required() {
+				admin.Controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("admin: default password login refused, first-run setup required (IP=%s)", remoteAddr))
+				w.WriteHeader(http.StatusForbidden)
+				json.NewEncoder(w).Encode(map[string]any{
+					"error":            "First-run setup required: use the setup token printed in the server console to create the administrator account.",
+					"firstRunRequired": true,
+				})
+				return
+			}
 			if len(v) > 0 {
 				if err := bcrypt.CompareHashAndPassword([]byte(admin.Controller.Options.adminPassword), []byte(v)); err == nil {
 					ok = true
@@ -3448,7 +3607,7 @@ func (admin *Admin) LoginHandler(w http.ResponseWriter, r *http.Request) {
 		}
 
 		b, err := json.Marshal(map[string]any{
-			"passwordNeedChange": true,
+			"passwordNeedChange": admin.Controller.Options.adminPasswordNeedChange,
 			"token":              sToken,
 		})
 		if err != nil {
@@ -3616,7 +3775,37 @@ func (admin *Admin) UserEditHandler(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNotFound)
 }
 
+// ValidateToken accepts the tokens of full administrators: the admin password
+// login and system admin users. Users signed in for their role permissions
+// are only accepted by ValidatePermission.
 func (admin *Admin) ValidateToken(sToken string) bool {
+	claims, ok := admin.tokenClaims(sToken)
+	if !ok {
+		return false
+	}
+	if claims.Subject == "" {
+		return true
+	}
+
+	user := admin.tokenUser(claims)
+	return user != nil && user.SystemAdmin
+}
+
+// ValidatePermission accepts full administrators and users holding permission
+// through a role.
+func (admin *Admin) ValidatePermission(sToken string, permission string) bool {
+	claims, ok := admin.tokenClaims(sToken)
+	if !ok {
+		return false
+	}
+	if claims.Subject == "" {
+		return true
+	}
+
+	return admin.Controller.Roles.HasPermission(admin.tokenUser(claims), permission)
+}
+
+func (admin *Admin) tokenClaims(sToken string) (*jwt.RegisteredClaims, bool) {
 	found := false
 	for _, t := range admin.Tokens {
 		if t == sToken {
@@ -3625,21 +3814,31 @@ func (admin *Admin) ValidateToken(sToken string) bool {
 		}
 	}
 	if !found {
-		return false
+		return nil, false
 	}
 
-	token, err := jwt.Parse(sToken, func(token *jwt.Token) (any, error) {
+	claims := &jwt.RegisteredClaims{}
+	token, err := jwt.ParseWithClaims(sToken, claims, func(token *jwt.Token) (any, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
 
 		return []byte(admin.Controller.Options.secret), nil
 	})
-	if err != nil {
-		return false
+	if err != nil || !token.Valid {
+		return nil, false
 	}
 
-	return token.Valid
+	return claims, true
+}
+
+// tokenUser returns the user an SSO token was issued to.
+func (admin *Admin) tokenUser(claims *jwt.RegisteredClaims) *User {
+	id, err := strconv.ParseUint(claims.Subject, 10, 64)
+	if err != nil {
+		return nil
+	}
+	return admin.Controller.Users.GetUserById(id)
 }
 
 func (admin *Admin) RadioReferenceTestHandler(w http.ResponseWriter, r *http.Request) {
@@ -4881,22 +5080,32 @@ func (v *rrSiteImportID) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
+type radioReferenceImportTalkgroup struct {
+	Id          float64 `json:"id"`
+	AlphaTag    string  `json:"alphaTag"`
+	Description string  `json:"description"`
+	Group       string  `json:"group"`
+	Tag         string  `json:"tag"`
+	Enc         float64 `json:"enc"`
+}
+
+type radioReferenceImportSite struct {
+	Id                       rrSiteImportID `json:"id"`
+	Name                     string         `json:"name"`
+	Rfss                     float64        `json:"rfss"`
+	Frequencies              []float64      `json:"frequencies"`
+	ControlChannels          []float64      `json:"controlChannels"`
+	AlternateControlChannels []float64      `json:"alternateControlChannels"`
+	Latitude                 float64        `json:"latitude"`
+	Longitude                float64        `json:"longitude"`
+	CountyName               string         `json:"countyName"`
+}
+
 type radioReferenceImportBody struct {
-	SystemId   float64 `json:"systemId"`
-	Talkgroups []struct {
-		Id          float64 `json:"id"`
-		AlphaTag    string  `json:"alphaTag"`
-		Description string  `json:"description"`
-		Group       string  `json:"group"`
-		Tag         string  `json:"tag"`
-		Enc         float64 `json:"enc"`
-	} `json:"talkgroups"`
-	Sites []struct {
-		Id          rrSiteImportID `json:"id"`
-		Name        string         `json:"name"`
-		Rfss        float64        `json:"rfss"`
-		Frequencies []float64      `json:"frequencies"`
-	} `json:"sites"`
+	SystemId   float64                         `json:"systemId"`
+	RRSystemId int                             `json:"rrSystemId"` // when set, the system is linked for re-sync
+	Talkgroups []radioReferenceImportTalkgroup `json:"talkgroups"`
+	Sites      []radioReferenceImportSite      `json:"sites"`
 }
 
 func (admin *Admin) radioReferenceImportToSystemCore(body radioReferenceImportBody) (created, updated int, err error) {
@@ -4961,6 +5170,7 @@ func (admin *Admin) radioReferenceImportToSystemCore(body radioReferenceImportBo
 			existing.Name = tg.Description
 			existing.GroupIds = []uint64{group.Id}
 			existing.TagId = tag.Id
+			existing.Encrypted = tg.Enc >= 2
 			updated++
 		} else {
 			maxOrder := uint(0)
@@ -4976,6 +5186,7 @@ func (admin *Admin) radioReferenceImportToSystemCore(body radioReferenceImportBo
 				GroupIds:     []uint64{group.Id},
 				TagId:        tag.Id,
 				Order:        maxOrder + 1,
+				Encrypted:    tg.Enc >= 2, // 1 is partially, 2 fully encrypted
 			})
 			created++
 		}
@@ -4988,12 +5199,24 @@ func (admin *Admin) radioReferenceImportToSystemCore(body radioReferenceImportBo
 			continue
 		}
 
+		controlChannels := append(append([]float64{}, s.ControlChannels...), s.AlternateControlChannels...)
+
 		if existing, ok := system.Sites.GetSiteByRef(siteRef); ok {
 			existing.Label = s.Name
 			existing.RFSS = uint(s.Rfss)
 			if len(s.Frequencies) > 0 {
 				existing.Frequencies = s.Frequencies
 			}
+			if len(controlChannels) > 0 {
+				existing.ControlChannels = controlChannels
+			}
+			if s.Latitude != 0 || s.Longitude != 0 {
+				existing.Latitude = s.Latitude
+				existing.Longitude = s.Longitude
+			}
+			if s.CountyName != "" {
+				existing.County = s.CountyName
+			}
 			updated++
 		} else {
 			maxOrder := uint(0)
@@ -5003,11 +5226,15 @@ func (admin *Admin) radioReferenceImportToSystemCore(body radioReferenceImportBo
 				}
 			}
 			system.Sites.List = append(system.Sites.List, &Site{
-				SiteRef:     siteRef,
-				Label:       s.Name,
-				RFSS:        uint(s.Rfss),
-				Frequencies: s.Frequencies,
-				Order:       maxOrder + 1,
+				SiteRef:         siteRef,
+				Label:           s.Name,
+				RFSS:            uint(s.Rfss),
+				Frequencies:     s.Frequencies,
+				ControlChannels: controlChannels,
+				Latitude:        s.Latitude,
+				Longitude:       s.Longitude,
+				County:          s.CountyName,
+				Order:           maxOrder + 1,
 			})
 			created++
 		}
@@ -5020,6 +5247,12 @@ func (admin *Admin) radioReferenceImportToSystemCore(body radioReferenceImportBo
 		return created, updated, fmt.Errorf("failed to read systems: %w", err)
 	}
 	ctrl.SyncConfigToFile()
+
+	if body.RRSystemId > 0 && ctrl.RadioReferenceSync != nil {
+		if err := ctrl.RadioReferenceSync.Link(systemId, body.RRSystemId); err != nil {
+			ctrl.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("radioreference sync: link system %d: %v", systemId, err))
+		}
+	}
 	return created, updated, nil
 }
 
@@ -5810,7 +6043,7 @@ func (admin *Admin) UsersListHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	t := admin.GetAuthorization(r)
-	if !admin.ValidateToken(t) {
+	if !admin.ValidatePermission(t, PermissionManageUsers) {
 		w.WriteHeader(http.StatusUnauthorized)
 		return
 	}
@@ -5823,8 +6056,17 @@ func (admin *Admin) UsersListHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Get all users from memory
+	// Get all users from memory, limited to the tenant of tenant administrators
 	users := admin.Controller.Users.GetAllUsers()
+	if tenant := admin.tokenTenant(t); tenant != nil {
+		scoped := users[:0:0]
+		for _, user := range users {
+			if tenant.HasUser(user) {
+				scoped = append(scoped, user)
+			}
+		}
+		users = scoped
+	}
 
 	// Convert users to JSON format
 	var userList []map[string]interface{}
@@ -5927,7 +6169,7 @@ func (admin *Admin) UserTestPushHandler(w http.ResponseWriter, r *http.Request)
 	}
 
 	t := admin.GetAuthorization(r)
-	if !admin.ValidateToken(t) {
+	if !admin.ValidatePermission(t, PermissionManageUsers) {
 		w.WriteHeader(http.StatusUnauthorized)
 		return
 	}
@@ -5950,7 +6192,7 @@ func (admin *Admin) UserTestPushHandler(w http.ResponseWriter, r *http.Request)
 
 	// Get user
 	user := admin.Controller.Users.GetUserById(userID)
-	if user == nil {
+	if user == nil || !admin.tenantAllowsUser(t, user) {
 		w.WriteHeader(http.StatusNotFound)
 		json.NewEncoder(w).Encode(map[string]string{"error": "User not found"})
 		return
@@ -5986,7 +6228,7 @@ func (admin *Admin) UserDeleteHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	t := admin.GetAuthorization(r)
-	if !admin.ValidateToken(t) {
+	if !admin.ValidatePermission(t, PermissionManageUsers) {
 		w.WriteHeader(http.StatusUnauthorized)
 		return
 	}
@@ -6009,12 +6251,19 @@ func (admin *Admin) UserDeleteHandler(w http.ResponseWriter, r *http.Request) {
 
 	// Get user to check if exists
 	user := admin.Controller.Users.GetUserById(userID)
-	if user == nil {
+	if user == nil || !admin.tenantAllowsUser(t, user) {
 		w.WriteHeader(http.StatusNotFound)
 		json.NewEncoder(w).Encode(map[string]string{"error": "User not found"})
 		return
 	}
 
+	// Role holders may not act on system administrators
+	if user.SystemAdmin && !admin.ValidateToken(t) {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]string{"error": "only administrators can change a system administrator"})
+		return
+	}
+
 	// Delete user and all related records in a transaction
 	tx, err := admin.Controller.Database.Sql.Begin()
 	if err != nil {
@@ -6078,7 +6327,7 @@ func (admin *Admin) UserUpdateHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	t := admin.GetAuthorization(r)
-	if !admin.ValidateToken(t) {
+	if !admin.ValidatePermission(t, PermissionManageUsers) {
 		w.WriteHeader(http.StatusUnauthorized)
 		return
 	}
@@ -6101,12 +6350,19 @@ func (admin *Admin) UserUpdateHandler(w http.ResponseWriter, r *http.Request) {
 
 	// Get user to check if exists
 	user := admin.Controller.Users.GetUserById(userID)
-	if user == nil {
+	if user == nil || !admin.tenantAllowsUser(t, user) {
 		w.WriteHeader(http.StatusNotFound)
 		json.NewEncoder(w).Encode(map[string]string{"error": "User not found"})
 		return
 	}
 
+	// Role holders may not act on system administrators
+	if user.SystemAdmin && !admin.ValidateToken(t) {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]string{"error": "only administrators can change a system administrator"})
+		return
+	}
+
 	// Parse request body
 	var request struct {
 		Email                string  `json:"email"`
@@ -6155,6 +6411,28 @@ func (admin *Admin) UserUpdateHandler(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	if request.SystemAdmin != nil && *request.SystemAdmin && !admin.ValidateToken(t) {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]string{"error": "only administrators can grant system administrator"})
+		return
+	}
+
+	// Tenant administrators keep users within their tenant
+	if tenant := admin.tokenTenant(t); tenant != nil && request.UserGroupId != nil {
+		if *request.UserGroupId > 0 && !containsId(tenant.UserGroupIds, *request.UserGroupId) {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(map[string]string{"error": "user group not found"})
+			return
+		}
+		if !containsId(tenant.UserIds, user.Id) {
+			if err := admin.Controller.Tenants.adopt(tenant.Id, 0, user.Id, admin.Controller.Database); err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+				return
+			}
+		}
+	}
+
 	// Update user fields
 	user.Email = NormalizeEmail(request.Email)
 	user.FirstName = strings.TrimSpace(request.FirstName)
@@ -6279,7 +6557,7 @@ func (admin *Admin) UserCreateHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	t := admin.GetAuthorization(r)
-	if !admin.ValidateToken(t) {
+	if !admin.ValidatePermission(t, PermissionManageUsers) {
 		w.WriteHeader(http.StatusUnauthorized)
 		return
 	}
@@ -6367,6 +6645,14 @@ func (admin *Admin) UserCreateHandler(w http.ResponseWriter, r *http.Request) {
 	user.Systems = "*" // Default to all systems
 	user.SubscriptionStatus = ""
 
+	// Tenant administrators only assign their tenant's user groups
+	tenant := admin.tokenTenant(t)
+	if tenant != nil && request.UserGroupId > 0 && !containsId(tenant.UserGroupIds, request.UserGroupId) {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Invalid user group ID"})
+		return
+	}
+
 	// If assigned to a user group, handle group-specific setup
 	if request.UserGroupId > 0 {
 		group := admin.Controller.UserGroups.Get(request.UserGroupId)
@@ -6425,6 +6711,13 @@ func (admin *Admin) UserCreateHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Users created by a tenant administrator belong to their tenant
+	if tenant != nil && !tenant.HasUser(user) {
+		if err := admin.Controller.Tenants.adopt(tenant.Id, 0, user.Id, admin.Controller.Database); err != nil {
+			log.Printf("Failed to add user %d to tenant %d: %v", user.Id, tenant.Id, err)
+		}
+	}
+
 	// Sync config to file if enabled
 	admin.Controller.SyncConfigToFile()
 
@@ -6446,7 +6739,7 @@ func (admin *Admin) UserResetPasswordHandler(w http.ResponseWriter, r *http.Requ
 	}
 
 	t := admin.GetAuthorization(r)
-	if !admin.ValidateToken(t) {
+	if !admin.ValidatePermission(t, PermissionManageUsers) {
 		w.WriteHeader(http.StatusUnauthorized)
 		return
 	}
@@ -6469,12 +6762,19 @@ func (admin *Admin) UserResetPasswordHandler(w http.ResponseWriter, r *http.Requ
 
 	// Get user to check if exists
 	user := admin.Controller.Users.GetUserById(userID)
-	if user == nil {
+	if user == nil || !admin.tenantAllowsUser(t, user) {
 		w.WriteHeader(http.StatusNotFound)
 		json.NewEncoder(w).Encode(map[string]string{"error": "User not found"})
 		return
 	}
 
+	// Role holders may not act on system administrators
+	if user.SystemAdmin && !admin.ValidateToken(t) {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]string{"error": "only administrators can change a system administrator"})
+		return
+	}
+
 	// Parse request body
 	var request struct {
 		NewPassword string `json:"newPassword"`
@@ -6606,7 +6906,7 @@ func (admin *Admin) DeviceTokenDeleteHandler(w http.ResponseWriter, r *http.Requ
 	}
 
 	t := admin.GetAuthorization(r)
-	if !admin.ValidateToken(t) {
+	if !admin.ValidatePermission(t, PermissionManageUsers) {
 		w.WriteHeader(http.StatusUnauthorized)
 		return
 	}
@@ -6639,7 +6939,7 @@ func (admin *Admin) DeviceTokenDeleteHandler(w http.ResponseWriter, r *http.Requ
 
 	// Verify the user exists
 	user := admin.Controller.Users.GetUserById(userID)
-	if user == nil {
+	if user == nil || !admin.tenantAllowsUser(t, user) {
 		w.WriteHeader(http.StatusNotFound)
 		json.NewEncoder(w).Encode(map[string]string{"error": "User not found"})
 		return
@@ -0,0 +1,651 @@
+// Copyright (C) 2026 Thinline Dynamic Solutions
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const (
+	adminV2Prefix       = "/api/v2"
+	adminV2DefaultLimit = 50
+	adminV2MaxLimit     = 500
+)
+
+// adminV2Route maps a /api/v2 operation onto the admin handler behind the
+// older /api/admin endpoint, which keeps serving the current client
+// unchanged. The v2 dispatcher adds the error envelope, pagination and the
+// OpenAPI description on top.
+type adminV2Route struct {
+	Method  string
+	Path    string // below /api/v2, with {name} path parameters
+	Id      string // OpenAPI operationId
+	Tag     string
+	Summary string
+	Params  []adminV2Param
+	Body    bool // takes a JSON request body
+
+	Handler http.HandlerFunc
+	V1      string // the v1 path; {name} takes the path parameter, others go to the query
+	V1Query string // fixed v1 query parameters
+	// SearchBody sends the query parameters to the handler as a POST JSON
+	// search body, as the v1 search endpoints expect.
+	SearchBody bool
+
+	// List routes return a page of the array at ListKey of the v1
+	// response, or the response itself when ListKey is empty. With TotalKey
+	// the handler pages itself and reports the total there; otherwise the
+	// dispatcher pages the full list.
+	List     bool
+	ListKey  string
+	TotalKey string
+
+	Raw bool // non-JSON response, passed through
+	// RawTypes are the media types of a Raw response, audio/* by default
+	RawTypes []string
+}
+
+// adminV2Param is a query parameter of a route.
+type adminV2Param struct {
+	Name        string
+	Type        string // "string", "integer" or "boolean"
+	Description string
+}
+
+// AdminV2Error is the body of every v2 error response.
+type AdminV2Error struct {
+	Error AdminV2ErrorDetail `json:"error"`
+}
+
+type AdminV2ErrorDetail struct {
+	Status  int    `json:"status"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// AdminV2Pagination describes the page of a list response.
+type AdminV2Pagination struct {
+	Limit  int `json:"limit"`
+	Offset int `json:"offset"`
+	Total  int `json:"total"`
+}
+
+// adminV2Routes lists the v2 operations.
+func (admin *Admin) adminV2Routes() []adminV2Route {
+	h := func(handler http.HandlerFunc) http.HandlerFunc {
+		return admin.requireLocalhost(handler)
+	}
+
+	return []adminV2Route{
+		{Method: http.MethodGet, Path: "/config", Id: "getConfig", Tag: "config", Summary: "Get the full configuration",
+			Handler: h(admin.ConfigHandler), V1: "/api/admin/config"},
+		{Method: http.MethodPut, Path: "/config", Id: "putConfig", Tag: "config", Summary: "Replace the full configuration", Body: true,
+			Handler: h(admin.ConfigHandler), V1: "/api/admin/config"},
+		{Method: http.MethodPatch, Path: "/options", Id: "patchOptions", Tag: "config", Summary: "Change some options", Body: true,
+			Handler: h(admin.OptionsPatchHandler), V1: "/api/admin/options"},
+
+		{Method: http.MethodGet, Path: "/apikeys", Id: "listApikeys", Tag: "apikeys", Summary: "List the upload API keys",
+			Handler: h(admin.ApikeysHandler), V1: "/api/admin/apikeys", List: true, ListKey: "apikeys"},
+		{Method: http.MethodPut, Path: "/apikeys", Id: "putApikeys", Tag: "apikeys", Summary: "Replace the upload API keys", Body: true,
+			Handler: h(admin.ApikeysHandler), V1: "/api/admin/apikeys"},
+		{Method: http.MethodGet, Path: "/apikeys/stats", Id: "listApikeyStats", Tag: "apikeys", Summary: "Upload statistics per API key",
+			Params:  []adminV2Param{{Name: "days", Type: "integer", Description: "Days of history, up to 90"}},
+			Handler: h(admin.ApikeyStatsHandler), V1: "/api/admin/apikey-stats", List: true, ListKey: "apikeys"},
+
+		{Method: http.MethodGet, Path: "/calls", Id: "searchCalls", Tag: "calls", Summary: "Search the calls",
+			Params: []adminV2Param{
+				{Name: "system", Type: "integer", Description: "System id"},
+				{Name: "talkgroup", Type: "integer", Description: "Talkgroup id"},
+				{Name: "group", Type: "string", Description: "Talkgroup group"},
+				{Name: "tag", Type: "string", Description: "Talkgroup tag"},
+				{Name: "date", Type: "string", Description: "RFC 3339 time to search from"},
+				{Name: "sort", Type: "integer", Description: "-1 for newest first"},
+			},
+			Handler: h(admin.CallsHandler), V1: "/api/admin/calls", SearchBody: true, List: true, ListKey: "results", TotalKey: "count"},
+		{Method: http.MethodGet, Path: "/calls/stats", Id: "getCallStats", Tag: "calls", Summary: "Call counts and airtime over time",
+			Params: []adminV2Param{
+				{Name: "from", Type: "string", Description: "Start date"},
+				{Name: "to", Type: "string", Description: "End date"},
+				{Name: "bucket", Type: "string", Description: "hour, day or week"},
+				{Name: "tz", Type: "string", Description: "IANA time zone of the buckets"},
+				{Name: "systemRef", Type: "integer", Description: "System reference"},
+				{Name: "talkgroupRef", Type: "integer", Description: "Talkgroup reference"},
+			},
+			Handler: h(admin.StatsHandler), V1: "/api/admin/stats"},
+		{Method: http.MethodGet, Path: "/listeners", Id: "getListenerStats", Tag: "calls", Summary: "Current listeners and talkgroup popularity",
+			Params:  []adminV2Param{{Name: "days", Type: "integer", Description: "Days of history"}},
+			Handler: h(admin.ListenerAnalyticsHandler), V1: "/api/admin/listeners"},
+		{Method: http.MethodGet, Path: "/calls/{id}/audio", Id: "getCallAudio", Tag: "calls", Summary: "Download the audio of a call",
+			Handler: h(admin.CallAudioHandler), V1: "/api/admin/call-audio/{id}", Raw: true},
+		{Method: http.MethodGet, Path: "/calls/export", Id: "exportCalls", Tag: "calls", Summary: "Export call metadata as CSV or Parquet",
+			Params: []adminV2Param{
+				{Name: "from", Type: "string", Description: "Start date"},
+				{Name: "to", Type: "string", Description: "End date"},
+				{Name: "format", Type: "string", Description: "csv or parquet"},
+				{Name: "tz", Type: "string", Description: "IANA time zone of the CSV times"},
+				{Name: "systemRef", Type: "integer", Description: "System reference"},
+				{Name: "talkgroupRef", Type: "integer", Description: "Talkgroup reference"},
+				{Name: "transcripts", Type: "boolean", Description: "Add a transcript column"},
+			},
+			Handler: h(admin.CallExportHandler), V1: "/api/admin/export/calls", Raw: true, RawTypes: []string{"text/csv", "application/vnd.apache.parquet"}},
+		{Method: http.MethodPost, Path: "/calls/share", Id: "shareCall", Tag: "calls", Summary: "Create a share link to a call", Body: true,
+			Handler: h(admin.CallShareHandler), V1: "/api/admin/calls/share"},
+
+		{Method: http.MethodGet, Path: "/logs", Id: "searchLogs", Tag: "logs", Summary: "Search the server logs",
+			Params: []adminV2Param{
+				{Name: "level", Type: "string", Description: "info, warn or error"},
+				{Name: "search", Type: "string", Description: "Text to look for"},
+				{Name: "date", Type: "string", Description: "RFC 3339 time to search from"},
+				{Name: "sort", Type: "integer", Description: "-1 for newest first"},
+			},
+			Handler: h(admin.LogsHandler), V1: "/api/admin/logs", SearchBody: true, List: true, ListKey: "logs", TotalKey: "count"},
+
+		{Method: http.MethodGet, Path: "/users", Id: "listUsers", Tag: "users", Summary: "List the users",
+			Handler: h(admin.UsersListHandler), V1: "/api/admin/users", List: true},
+		{Method: http.MethodGet, Path: "/guest-grants", Id: "listGuestGrants", Tag: "users", Summary: "List the guest access grants",
+			Handler: h(admin.GuestGrantsHandler), V1: "/api/admin/guest-grants", List: true, ListKey: "grants"},
+		{Method: http.MethodPost, Path: "/guest-grants", Id: "createGuestGrant", Tag: "users", Summary: "Grant guest access to some talkgroups", Body: true,
+			Handler: h(admin.GuestGrantsHandler), V1: "/api/admin/guest-grants"},
+		{Method: http.MethodDelete, Path: "/guest-grants", Id: "deleteGuestGrant", Tag: "users", Summary: "Revoke a guest access grant",
+			Params:  []adminV2Param{{Name: "id", Type: "integer", Description: "Grant id"}},
+			Handler: h(admin.GuestGrantsHandler), V1: "/api/admin/guest-grants"},
+		{Method: http.MethodGet, Path: "/embeds", Id: "listPlayerEmbeds", Tag: "users", Summary: "List the public player embeds",
+			Handler: h(admin.PlayerEmbedsHandler), V1: "/api/admin/embeds", List: true, ListKey: "embeds"},
+		{Method: http.MethodPost, Path: "/embeds", Id: "createPlayerEmbed", Tag: "users", Summary: "Create a public player embed", Body: true,
+			Handler: h(admin.PlayerEmbedsHandler), V1: "/api/admin/embeds"},
+		{Method: http.MethodPut, Path: "/embeds", Id: "putPlayerEmbed", Tag: "users", Summary: "Change a public player embed", Body: true,
+			Params:  []adminV2Param{{Name: "id", Type: "integer", Description: "Embed id"}},
+			Handler: h(admin.PlayerEmbedsHandler), V1: "/api/admin/embeds"},
+		{Method: http.MethodDelete, Path: "/embeds", Id: "deletePlayerEmbed", Tag: "users", Summary: "Delete a public player embed",
+			Params:  []adminV2Param{{Name: "id", Type: "integer", Description: "Embed id"}},
+			Handler: h(admin.PlayerEmbedsHandler), V1: "/api/admin/embeds"},
+
+		{Method: http.MethodGet, Path: "/system-alerts", Id: "listSystemAlerts", Tag: "alerts", Summary: "List the system alerts, newest first",
+			Params:  []adminV2Param{{Name: "includeDismissed", Type: "boolean", Description: "Include dismissed alerts"}},
+			Handler: h(admin.SystemHealthHandler), V1: "/api/admin/systemhealth", V1Query: "limit=1000", List: true, ListKey: "alerts"},
+
+		{Method: http.MethodGet, Path: "/jobs", Id: "listJobs", Tag: "jobs", Summary: "List the most recently updated background jobs",
+			Params: []adminV2Param{
+				{Name: "kind", Type: "string", Description: "transcription or webhook"},
+				{Name: "status", Type: "string", Description: "queued, running, done or dead"},
+			},
+			Handler: h(admin.JobsHandler), V1: "/api/admin/jobs", V1Query: "limit=500", List: true, ListKey: "jobs"},
+		{Method: http.MethodPost, Path: "/jobs/{id}/retry", Id: "retryJob", Tag: "jobs", Summary: "Retry a dead job",
+			Handler: h(admin.JobsHandler), V1: "/api/admin/jobs"},
+		{Method: http.MethodDelete, Path: "/jobs/{id}", Id: "deleteJob", Tag: "jobs", Summary: "Delete a job that isn't running",
+			Handler: h(admin.JobsHandler), V1: "/api/admin/jobs"},
+
+		{Method: http.MethodGet, Path: "/retention-policies", Id: "listRetentionPolicies", Tag: "storage", Summary: "List the retention policies",
+			Handler: h(admin.RetentionHandler), V1: "/api/admin/retention", List: true, ListKey: "policies"},
+		{Method: http.MethodPut, Path: "/retention-policies", Id: "putRetentionPolicy", Tag: "storage", Summary: "Create or change a retention policy", Body: true,
+			Handler: h(admin.RetentionHandler), V1: "/api/admin/retention"},
+		{Method: http.MethodDelete, Path: "/retention-policies", Id: "deleteRetentionPolicy", Tag: "storage", Summary: "Delete a retention policy",
+			Params: []adminV2Param{
+				{Name: "systemId", Type: "integer", Description: "System id"},
+				{Name: "talkgroupId", Type: "integer", Description: "Talkgroup id, omitted for the system policy"},
+			},
+			Handler: h(admin.RetentionHandler), V1: "/api/admin/retention"},
+		{Method: http.MethodGet, Path: "/storage/capacity", Id: "getStorageCapacity", Tag: "storage", Summary: "Storage use and forecast",
+			Params:  []adminV2Param{{Name: "days", Type: "integer", Description: "Days of samples, up to 30"}},
+			Handler: h(admin.StorageCapacityHandler), V1: "/api/admin/capacity"},
+		{Method: http.MethodGet, Path: "/storage/audio-migration", Id: "getAudioMigration", Tag: "storage", Summary: "Progress of the audio storage migration",
+			Handler: h(admin.AudioStorageMigrateHandler), V1: "/api/admin/audio-storage/migrate"},
+		{Method: http.MethodPost, Path: "/storage/audio-migration", Id: "startAudioMigration", Tag: "storage", Summary: "Start or resume the audio storage migration", Body: true,
+			Handler: h(admin.AudioStorageMigrateHandler), V1: "/api/admin/audio-storage/migrate"},
+		{Method: http.MethodDelete, Path: "/storage/audio-migration", Id: "pauseAudioMigration", Tag: "storage", Summary: "Pause the audio storage migration",
+			Handler: h(admin.AudioStorageMigrateHandler), V1: "/api/admin/audio-storage/migrate"},
+
+		{Method: http.MethodGet, Path: "/outbox-events", Id: "listOutboxEvents", Tag: "integrations", Summary: "Read the event outbox",
+			Params: []adminV2Param{
+				{Name: "after", Type: "integer", Description: "Return events after this sequence number"},
+				{Name: "limit", Type: "integer", Description: "Most events to return"},
+			},
+			Handler: h(admin.OutboxHandler), V1: "/api/admin/outbox"},
+
+		{Method: http.MethodGet, Path: "/onboarding", Id: "getOnboarding", Tag: "config", Summary: "Setup checklist",
+			Handler: h(admin.OnboardingHandler), V1: "/api/admin/onboarding"},
+	}
+}
+
+// AdminV2Handler serves /api/v2.
+func (admin *Admin) AdminV2Handler(w http.ResponseWriter, r *http.Request) {
+	serveAdminV2(admin.adminV2Routes(), w, r)
+}
+
+// matchAdminV2Path matches a path against a route template and returns its
+// path parameters.
+func matchAdminV2Path(template string, path string) (map[string]string, bool) {
+	want := strings.Split(strings.Trim(template, "/"), "/")
+	got := strings.Split(strings.Trim(path, "/"), "/")
+	if len(want) != len(got) {
+		return nil, false
+	}
+	params := map[string]string{}
+	for i, segment := range want {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			if got[i] == "" {
+				return nil, false
+			}
+			params[segment[1:len(segment)-1]] = got[i]
+		} else if segment != got[i] {
+			return nil, false
+		}
+	}
+	return params, true
+}
+
+func serveAdminV2(routes []adminV2Route, w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, adminV2Prefix)
+
+	if path == "/openapi.json" && r.Method == http.MethodGet {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(adminV2OpenAPI(routes))
+		return
+	}
+
+	var (
+		allowed []string
+		params  map[string]string
+		route   *adminV2Route
+	)
+	for i := range routes {
+		p, ok := matchAdminV2Path(routes[i].Path, path)
+		if !ok {
+			continue
+		}
+		allowed = append(allowed, routes[i].Method)
+		if routes[i].Method == r.Method {
+			route, params = &routes[i], p
+			break
+		}
+	}
+	switch {
+	case route == nil && len(allowed) > 0:
+		w.Header().Set("Allow", strings.Join(allowed, ", "))
+		writeAdminV2Error(w, http.StatusMethodNotAllowed, "")
+		return
+	case route == nil:
+		writeAdminV2Error(w, http.StatusNotFound, "no such endpoint")
+		return
+	}
+
+	page := AdminV2Pagination{Limit: adminV2DefaultLimit}
+	if route.List {
+		var err error
+		if page.Limit, page.Offset, err = adminV2Page(r.URL.Query()); err != nil {
+			writeAdminV2Error(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	v1, err := route.v1Request(r, params, page)
+	if err != nil {
+		writeAdminV2Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if route.Raw {
+		route.Handler(w, v1)
+		return
+	}
+
+	recorder := newAdminV2Recorder()
+	route.Handler(recorder, v1)
+
+	if recorder.status >= 400 {
+		writeAdminV2Error(w, recorder.status, adminV2ErrorMessage(recorder.body.Bytes()))
+		return
+	}
+
+	var data json.RawMessage
+	if body := bytes.TrimSpace(recorder.body.Bytes()); len(body) > 0 {
+		if !json.Valid(body) {
+			writeAdminV2Error(w, http.StatusInternalServerError, "the handler returned an invalid response")
+			return
+		}
+		data = body
+	} else {
+		data = json.RawMessage("null")
+	}
+
+	response := map[string]any{"data": data}
+	if route.List {
+		items, pagination, err := route.page(data, page)
+		if err != nil {
+			writeAdminV2Error(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		response = map[string]any{"data": items, "pagination": pagination}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(recorder.status)
+	json.NewEncoder(w).Encode(response)
+}
+
+// adminV2Page reads ?limit= and ?offset=.
+func adminV2Page(query url.Values) (limit int, offset int, err error) {
+	limit = adminV2DefaultLimit
+	if s := query.Get("limit"); s != "" {
+		if limit, err = strconv.Atoi(s); err != nil || limit < 1 {
+			return 0, 0, fmt.Errorf("limit must be a positive number")
+		}
+		if limit > adminV2MaxLimit {
+			limit = adminV2MaxLimit
+		}
+	}
+	if s := query.Get("offset"); s != "" {
+		if offset, err = strconv.Atoi(s); err != nil || offset < 0 {
+			return 0, 0, fmt.Errorf("offset must be zero or a positive number")
+		}
+	}
+	return limit, offset, nil
+}
+
+// v1Request turns the v2 request into the request the v1 handler expects.
+func (route *adminV2Route) v1Request(r *http.Request, params map[string]string, page AdminV2Pagination) (*http.Request, error) {
+	v1 := r.Clone(r.Context())
+
+	query := url.Values{}
+	if route.V1Query != "" {
+		query, _ = url.ParseQuery(route.V1Query)
+	}
+	for name, values := range r.URL.Query() {
+		if route.List && (name == "limit" || name == "offset") {
+			continue
+		}
+		query[name] = values
+	}
+
+	path := route.V1
+	for name, value := range params {
+		if placeholder := "{" + name + "}"; strings.Contains(path, placeholder) {
+			path = strings.ReplaceAll(path, placeholder, url.PathEscape(value))
+		} else {
+			query.Set(name, value)
+		}
+	}
+	v1.URL.Path = path
+	v1.RequestURI = ""
+
+	if route.SearchBody {
+		body := map[string]any{}
+		for _, param := range route.Params {
+			s := query.Get(param.Name)
+			if s == "" {
+				continue
+			}
+			switch param.Type {
+			case "integer":
+				v, err := strconv.ParseInt(s, 10, 64)
+				if err != nil {
+					return nil, fmt.Errorf("%s must be a number", param.Name)
+				}
+				body[param.Name] = float64(v)
+			case "boolean":
+				body[param.Name] = s == "true"
+			default:
+				body[param.Name] = s
+			}
+		}
+		body["limit"] = float64(page.Limit)
+		body["offset"] = float64(page.Offset)
+		b, _ := json.Marshal(body)
+		v1.Method = http.MethodPost
+		v1.Body = io.NopCloser(bytes.NewReader(b))
+		v1.ContentLength = int64(len(b))
+		v1.Header.Set("Content-Type", "application/json")
+		query = url.Values{}
+	}
+	v1.URL.RawQuery = query.Encode()
+
+	// v2 takes the admin token as a bearer token
+	if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		v1.Header.Set("Authorization", token)
+	}
+
+	return v1, nil
+}
+
+// page returns the requested page of the v1 list response.
+func (route *adminV2Route) page(data json.RawMessage, page AdminV2Pagination) ([]json.RawMessage, AdminV2Pagination, error) {
+	list := data
+	var total *int
+	if route.ListKey != "" {
+		fields := map[string]json.RawMessage{}
+		if err := json.Unmarshal(data, &fields); err != nil {
+			return nil, page, fmt.Errorf("unexpected list response: %v", err)
+		}
+		list = fields[route.ListKey]
+		if route.TotalKey != "" {
+			var n int
+			if err := json.Unmarshal(fields[route.TotalKey], &n); err == nil {
+				total = &n
+			}
+		}
+	}
+
+	items := []json.RawMessage{}
+	if len(list) > 0 && string(list) != "null" {
+		if err := json.Unmarshal(list, &items); err != nil {
+			return nil, page, fmt.Errorf("unexpected list response: %v", err)
+		}
+	}
+
+	if total != nil {
+		// The handler paged the list itself
+		page.Total = *total
+		return items, page, nil
+	}
+
+	page.Total = len(items)
+	start, end := page.Offset, page.Offset+page.Limit
+	if start > len(items) {
+		start = len(items)
+	}
+	if end > len(items) {
+		end = len(items)
+	}
+	return items[start:end], page, nil
+}
+
+// adminV2ErrorMessage extracts the message of a v1 error response, which is
+// {"error": "..."}, plain text or empty.
+func adminV2ErrorMessage(body []byte) string {
+	var v1 struct {
+		Error   string `json:"error"`
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(body, &v1); err == nil {
+		if v1.Error != "" {
+			return v1.Error
+		}
+		return v1.Message
+	}
+	return strings.TrimSpace(string(body))
+}
+
+// writeAdminV2Error writes the error envelope. Without a message the status
+// text is used.
+func writeAdminV2Error(w http.ResponseWriter, status int, message string) {
+	text := http.StatusText(status)
+	if message == "" {
+		message = text
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(AdminV2Error{Error: AdminV2ErrorDetail{
+		Status:  status,
+		Code:    strings.ReplaceAll(strings.ToLower(text), " ", "_"),
+		Message: message,
+	}})
+}
+
+// adminV2Recorder buffers the response of a v1 handler.
+type adminV2Recorder struct {
+	header      http.Header
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func newAdminV2Recorder() *adminV2Recorder {
+	return &adminV2Recorder{header: http.Header{}, status: http.StatusOK}
+}
+
+func (recorder *adminV2Recorder) Header() http.Header {
+	return recorder.header
+}
+
+func (recorder *adminV2Recorder) Write(b []byte) (int, error) {
+	recorder.wroteHeader = true
+	return recorder.body.Write(b)
+}
+
+func (recorder *adminV2Recorder) WriteHeader(status int) {
+	if !recorder.wroteHeader {
+		recorder.status = status
+		recorder.wroteHeader = true
+	}
+}
+
+// adminV2OpenAPI describes the routes as an OpenAPI 3 document.
+func adminV2OpenAPI(routes []adminV2Route) map[string]any {
+	ref := func(name string) map[string]any {
+		return map[string]any{"$ref": "#/components/schemas/" + name}
+	}
+	jsonContent := func(schema any) map[string]any {
+		return map[string]any{"application/json": map[string]any{"schema": schema}}
+	}
+
+	paths := map[string]map[string]any{}
+	for _, route := range routes {
+		parameters := []map[string]any{}
+		for _, segment := range strings.Split(route.Path, "/") {
+			if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+				parameters = append(parameters, map[string]any{
+					"name": segment[1 : len(segment)-1], "in": "path", "required": true,
+					"schema": map[string]any{"type": "string"},
+				})
+			}
+		}
+		params := route.Params
+		if route.List {
+			params = append(append([]adminV2Param{}, params...),
+				adminV2Param{Name: "limit", Type: "integer", Description: fmt.Sprintf("Page size, %d by default and up to %d", adminV2DefaultLimit, adminV2MaxLimit)},
+				adminV2Param{Name: "offset", Type: "integer", Description: "Items to skip"},
+			)
+		}
+		for _, param := range params {
+			parameters = append(parameters, map[string]any{
+				"name": param.Name, "in": "query", "description": param.Description,
+				"schema": map[string]any{"type": param.Type},
+			})
+		}
+
+		success := map[string]any{"description": "OK", "content": jsonContent(ref("Envelope"))}
+		switch {
+		case route.List:
+			success["content"] = jsonContent(ref("ListEnvelope"))
+		case route.Raw:
+			types := route.RawTypes
+			if len(types) == 0 {
+				types = []string{"audio/*"}
+			}
+			content := map[string]any{}
+			for _, t := range types {
+				content[t] = map[string]any{"schema": map[string]any{"type": "string", "format": "binary"}}
+			}
+			success["content"] = content
+		}
+
+		operation := map[string]any{
+			"operationId": route.Id,
+			"summary":     route.Summary,
+			"tags":        []string{route.Tag},
+			"parameters":  parameters,
+			"responses": map[string]any{
+				"200":     success,
+				"default": map[string]any{"description": "Error", "content": jsonContent(ref("Error"))},
+			},
+		}
+		if route.Body {
+			operation["requestBody"] = map[string]any{"required": true, "content": jsonContent(map[string]any{"type": "object"})}
+		}
+
+		if paths[route.Path] == nil {
+			paths[route.Path] = map[string]any{}
+		}
+		paths[route.Path][strings.ToLower(route.Method)] = operation
+	}
+
+	return map[string]any{
+		"openapi": "3.0.3",
+		"info": map[string]any{
+			"title":   "ThinLine Radio Admin API",
+			"version": Version,
+		},
+		"servers":  []map[string]any{{"url": adminV2Prefix}},
+		"security": []map[string]any{{"adminToken": []string{}}},
+		"paths":    paths,
+		"components": map[string]any{
+			"securitySchemes": map[string]any{
+				"adminToken": map[string]any{"type": "http", "scheme": "bearer", "description": "Token from POST /api/admin/login"},
+			},
+			"schemas": map[string]any{
+				"Envelope": map[string]any{
+					"type":       "object",
+					"properties": map[string]any{"data": map[string]any{}},
+				},
+				"ListEnvelope": map[string]any{
+					"type": "object",
+					"properties": map[string]any{
+						"data":       map[string]any{"type": "array", "items": map[string]any{}},
+						"pagination": ref("Pagination"),
+					},
+				},
+				"Pagination": map[string]any{
+					"type": "object",
+					"properties": map[string]any{
+						"limit":  map[string]any{"type": "integer"},
+						"offset": map[string]any{"type": "integer"},
+						"total":  map[string]any{"type": "integer"},
+					},
+				},
+				"Error": map[string]any{
+					"type": "object",
+					"properties": map[string]any{
+						"error": map[string]any{
+							"type": "object",
+							"properties": map[string]any{
+								"status":  map[string]any{"type": "integer"},
+								"code":    map[string]any{"type": "string"},
+								"message": map[string]any{"type": "string"},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
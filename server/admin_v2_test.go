@@ -0,0 +1,165 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMatchAdminV2Path(t *testing.T) {
+	params, ok := matchAdminV2Path("/jobs/{id}/retry", "/jobs/42/retry")
+	if !ok || params["id"] != "42" {
+		t.Fatalf("expected id 42, got %v %v", params, ok)
+	}
+	if _, ok := matchAdminV2Path("/jobs/{id}", "/jobs/42/retry"); ok {
+		t.Fatal("longer path should not match")
+	}
+	if _, ok := matchAdminV2Path("/jobs/{id}", "/jobs/"); ok {
+		t.Fatal("empty path parameter should not match")
+	}
+}
+
+func serveAdminV2Test(routes []adminV2Route, method string, target string, header string) (*httptest.ResponseRecorder, map[string]any) {
+	r := httptest.NewRequest(method, target, nil)
+	if header != "" {
+		r.Header.Set("Authorization", header)
+	}
+	w := httptest.NewRecorder()
+	serveAdminV2(routes, w, r)
+	body := map[string]any{}
+	json.Unmarshal(w.Body.Bytes(), &body)
+	return w, body
+}
+
+func TestAdminV2ListPagination(t *testing.T) {
+	routes := []adminV2Route{{
+		Method: http.MethodGet, Path: "/items", List: true, ListKey: "items", V1: "/api/admin/items",
+		Handler: func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") != "token" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			if r.URL.Path != "/api/admin/items" || r.URL.Query().Get("limit") != "" {
+				t.Errorf("unexpected v1 request %s", r.URL)
+			}
+			json.NewEncoder(w).Encode(map[string]any{"items": []int{1, 2, 3, 4, 5}})
+		},
+	}}
+
+	w, body := serveAdminV2Test(routes, http.MethodGet, "/api/v2/items?limit=2&offset=3", "Bearer token")
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	data, _ := json.Marshal(body["data"])
+	if string(data) != "[4,5]" {
+		t.Fatalf("expected [4,5], got %s", data)
+	}
+	pagination := body["pagination"].(map[string]any)
+	if pagination["total"] != float64(5) || pagination["limit"] != float64(2) || pagination["offset"] != float64(3) {
+		t.Fatalf("unexpected pagination %v", pagination)
+	}
+
+	w, body = serveAdminV2Test(routes, http.MethodGet, "/api/v2/items", "")
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401, got %d", w.Code)
+	}
+	detail := body["error"].(map[string]any)
+	if detail["code"] != "unauthorized" || detail["message"] != "Unauthorized" || detail["status"] != float64(401) {
+		t.Fatalf("unexpected error %v", detail)
+	}
+
+	w, _ = serveAdminV2Test(routes, http.MethodGet, "/api/v2/items?limit=0", "token")
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for a zero limit, got %d", w.Code)
+	}
+}
+
+func TestAdminV2SearchBody(t *testing.T) {
+	routes := []adminV2Route{{
+		Method: http.MethodGet, Path: "/calls", V1: "/api/admin/calls", SearchBody: true,
+		List: true, ListKey: "results", TotalKey: "count",
+		Params: []adminV2Param{{Name: "system", Type: "integer"}, {Name: "tag", Type: "string"}},
+		Handler: func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodPost {
+				t.Errorf("expected POST, got %s", r.Method)
+			}
+			b, _ := io.ReadAll(r.Body)
+			m := map[string]any{}
+			json.Unmarshal(b, &m)
+			if m["system"] != float64(3) || m["tag"] != "Fire" || m["limit"] != float64(10) || m["offset"] != float64(20) {
+				t.Errorf("unexpected search body %s", b)
+			}
+			w.Write([]byte(`{"count": 95, "results": [{"id": 1}]}`))
+		},
+	}}
+
+	w, body := serveAdminV2Test(routes, http.MethodGet, "/api/v2/calls?system=3&tag=Fire&limit=10&offset=20", "token")
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	if pagination := body["pagination"].(map[string]any); pagination["total"] != float64(95) {
+		t.Fatalf("expected the handler's total, got %v", pagination)
+	}
+
+	w, _ = serveAdminV2Test(routes, http.MethodGet, "/api/v2/calls?system=x", "token")
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for a bad number, got %d", w.Code)
+	}
+}
+
+func TestAdminV2Errors(t *testing.T) {
+	routes := []adminV2Route{{
+		Method: http.MethodDelete, Path: "/jobs/{id}", V1: "/api/admin/jobs",
+		Handler: func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Query().Get("id") != "7" {
+				t.Errorf("expected the id in the query, got %s", r.URL)
+			}
+			w.WriteHeader(http.StatusConflict)
+			json.NewEncoder(w).Encode(map[string]string{"error": "job is running"})
+		},
+	}}
+
+	w, body := serveAdminV2Test(routes, http.MethodDelete, "/api/v2/jobs/7", "token")
+	if w.Code != http.StatusConflict || body["error"].(map[string]any)["message"] != "job is running" {
+		t.Fatalf("unexpected response %d %v", w.Code, body)
+	}
+
+	w, _ = serveAdminV2Test(routes, http.MethodGet, "/api/v2/jobs/7", "token")
+	if w.Code != http.StatusMethodNotAllowed || w.Header().Get("Allow") != "DELETE" {
+		t.Fatalf("expected 405 allowing DELETE, got %d %q", w.Code, w.Header().Get("Allow"))
+	}
+
+	w, _ = serveAdminV2Test(routes, http.MethodGet, "/api/v2/nothing", "token")
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", w.Code)
+	}
+}
+
+func TestAdminV2OpenAPI(t *testing.T) {
+	admin := &Admin{}
+	routes := admin.adminV2Routes()
+
+	w, body := serveAdminV2Test(routes, http.MethodGet, "/api/v2/openapi.json", "")
+	if w.Code != http.StatusOK || body["openapi"] != "3.0.3" {
+		t.Fatalf("unexpected spec response %d", w.Code)
+	}
+
+	paths := body["paths"].(map[string]any)
+	ids := map[string]bool{}
+	for _, route := range routes {
+		operation, ok := paths[route.Path].(map[string]any)[strings.ToLower(route.Method)].(map[string]any)
+		if !ok {
+			t.Fatalf("%s %s missing from the spec", route.Method, route.Path)
+		}
+		if ids[route.Id] {
+			t.Fatalf("duplicate operationId %s", route.Id)
+		}
+		ids[route.Id] = true
+		if operation["operationId"] != route.Id {
+			t.Fatalf("unexpected operationId %v", operation["operationId"])
+		}
+	}
+}
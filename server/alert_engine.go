@@ -36,6 +36,11 @@ type AlertEngine struct {
 	lastToneAlertFiredAt map[uint64]time.Time
 	// toneAlertDispatched prevents duplicate TriggerToneAlerts push batches for the same callId.
 	toneAlertDispatched map[uint64]struct{}
+	// lifeSafetyDispatched holds calls already alerted from the life-safety phrase pack.
+	lifeSafetyDispatched map[uint64]struct{}
+
+	// escalations holds alerts waiting for an acknowledgement before escalating.
+	escalations alertEscalations
 
 	// lastCleanupUnix is the Unix timestamp (seconds) of the most recent
 	// cleanupOldAlerts run.  Compared atomically so that concurrent createAlert
@@ -50,6 +55,7 @@ func NewAlertEngine(controller *Controller) *AlertEngine {
 		lastPreAlertFiredAt:  make(map[uint64]time.Time),
 		lastToneAlertFiredAt: make(map[uint64]time.Time),
 		toneAlertDispatched:  make(map[uint64]struct{}),
+		lifeSafetyDispatched: make(map[uint64]struct{}),
 	}
 }
 
@@ -147,6 +153,11 @@ func (engine *AlertEngine) TriggerPreAlerts(call *Call) {
 		return
 	}
 
+	// Scheduled tone tests are recorded without notifying anyone
+	if isToneTest(call) {
+		return
+	}
+
 	// Get all matched tone sets from this call
 	matchedToneSets := call.ToneSequence.MatchedToneSets
 	if len(matchedToneSets) == 0 {
@@ -304,6 +315,11 @@ func (engine *AlertEngine) TriggerToneAlerts(call *Call) {
 		return
 	}
 
+	// Scheduled tone tests are recorded without notifying anyone
+	if isToneTest(call) {
+		return
+	}
+
 	// Get all matched tone sets from this call
 	matchedToneSets := call.ToneSequence.MatchedToneSets
 	if len(matchedToneSets) == 0 {
@@ -410,6 +426,9 @@ func (engine *AlertEngine) TriggerToneAlerts(call *Call) {
 		// Forward to TonesToActive downstream (per-tone-set and/or global)
 		dispatchToneDownstreams(engine.controller, call, matchedToneSet)
 
+		// Page through Active911 or IamResponding when the tone set forwards dispatches
+		engine.controller.Paging.Dispatch(call, matchedToneSet)
+
 		if toneCooldownBlocked {
 			continue
 		}
@@ -824,6 +843,11 @@ func (engine *AlertEngine) createAlert(alert *AlertRecord) {
 		alert.AlertId, alert.CallId, alert.SystemId, alert.TalkgroupId, 
 		alert.AlertType, alert.ToneSetId, alert.KeywordsMatched)
 
+	// Page the escalation tier if nobody acknowledges in time
+	engine.startEscalation(alert, -1)
+
+	engine.dispatchAlertWebhooks(alert)
+
 	// Debug log
 	if engine.controller.DebugLogger != nil {
 		details := fmt.Sprintf("AlertID=%d", alert.AlertId)
@@ -846,6 +870,44 @@ func (engine *AlertEngine) createAlert(alert *AlertRecord) {
 	}
 }
 
+// alertDetails returns the label of the alert's tone set and its matched keywords.
+func alertDetails(talkgroup *Talkgroup, alert *AlertRecord) (string, []string) {
+	toneSetName := ""
+	for _, toneSet := range talkgroup.ToneSets {
+		if alert.ToneSetId != "" && toneSet.Id == alert.ToneSetId {
+			toneSetName = toneSet.Label
+		}
+	}
+	var keywords []string
+	if alert.KeywordsMatched != "" {
+		json.Unmarshal([]byte(alert.KeywordsMatched), &keywords)
+	}
+	return toneSetName, keywords
+}
+
+// dispatchAlertWebhooks notifies the webhooks following tone and keyword alerts.
+func (engine *AlertEngine) dispatchAlertWebhooks(alert *AlertRecord) {
+	system, talkgroup, ok := engine.alertRefs(alert)
+	if !ok {
+		return
+	}
+
+	toneSetName, keywords := alertDetails(talkgroup, alert)
+	call := &Call{
+		Id:         alert.CallId,
+		System:     system,
+		Talkgroup:  talkgroup,
+		Timestamp:  time.UnixMilli(alert.CreatedAt),
+		Transcript: alert.TranscriptSnippet,
+	}
+	if toneSetName != "" {
+		call.ToneSequence = &ToneSequence{MatchedToneSet: &ToneSet{Id: alert.ToneSetId, Label: toneSetName}}
+	}
+
+	record := *alert
+	go engine.controller.dispatchWebhooks(&webhookEvent{Name: alert.AlertType, Call: call, Alert: &record, Keywords: keywords})
+}
+
 // sendAlertNotification sends a WebSocket notification to the user.
 // The clients map lock is held only long enough to snapshot matching clients;
 // the actual channel sends happen after the lock is released so that slow or
@@ -0,0 +1,466 @@
+// Copyright (C) 2026 Thinline Dynamic Solutions
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// EscalationPolicy pages a second tier when nobody acknowledges an alert in
+// time. The users who follow the talkgroup are tier 1 and are alerted as
+// usual; when none of them acknowledges within the window, the alert is sent
+// to the tier-2 users and, optionally, raised as a system alert for admins.
+type EscalationPolicy struct {
+	Id                uint     `json:"id"`
+	Label             string   `json:"label"`
+	SystemRef         uint     `json:"systemRef"`
+	TalkgroupRef      uint     `json:"talkgroupRef"`     // 0 = every talkgroup of the system
+	AlertTypes        []string `json:"alertTypes"`       // empty = escalationAlertTypes
+	AckWindowSeconds  uint     `json:"ackWindowSeconds"` // 0 = escalationAckWindow
+	Tier2UserIds      []uint64 `json:"tier2UserIds"`
+	Tier2SystemAdmins bool     `json:"tier2SystemAdmins"`
+}
+
+type EscalationPolicies []EscalationPolicy
+
+// escalationAckWindow is the default time tier 1 has to acknowledge.
+const escalationAckWindow = 5 * time.Minute
+
+// escalationAlertTypes are the alert types a policy covers by default.
+var escalationAlertTypes = []string{"tone", "keyword", "tone+keyword"}
+
+// escalationKnownAlertTypes are the alert types a policy may list.
+var escalationKnownAlertTypes = map[string]bool{
+	"tone":         true,
+	"keyword":      true,
+	"tone+keyword": true,
+	"life-safety":  true,
+}
+
+// escalationPoliciesFromList parses the admin representation. Policies need a
+// system and somewhere to escalate to; others are dropped.
+func escalationPoliciesFromList(list []any) EscalationPolicies {
+	policies := EscalationPolicies{}
+
+	for _, item := range list {
+		m, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+
+		policy := EscalationPolicy{}
+		if v, ok := m["id"].(float64); ok && v > 0 {
+			policy.Id = uint(v)
+		}
+		if v, ok := m["label"].(string); ok {
+			policy.Label = strings.TrimSpace(v)
+		}
+		if v, ok := m["systemRef"].(float64); ok && v > 0 {
+			policy.SystemRef = uint(v)
+		}
+		if v, ok := m["talkgroupRef"].(float64); ok && v > 0 {
+			policy.TalkgroupRef = uint(v)
+		}
+		if v, ok := m["ackWindowSeconds"].(float64); ok && v > 0 {
+			policy.AckWindowSeconds = uint(v)
+		}
+		if v, ok := m["tier2SystemAdmins"].(bool); ok {
+			policy.Tier2SystemAdmins = v
+		}
+		if types, ok := m["alertTypes"].([]any); ok {
+			for _, t := range types {
+				if s, ok := t.(string); ok && escalationKnownAlertTypes[strings.ToLower(strings.TrimSpace(s))] {
+					policy.AlertTypes = append(policy.AlertTypes, strings.ToLower(strings.TrimSpace(s)))
+				}
+			}
+		}
+		if userIds, ok := m["tier2UserIds"].([]any); ok {
+			seen := map[uint64]bool{}
+			for _, id := range userIds {
+				if v, ok := id.(float64); ok && v > 0 && !seen[uint64(v)] {
+					seen[uint64(v)] = true
+					policy.Tier2UserIds = append(policy.Tier2UserIds, uint64(v))
+				}
+			}
+		}
+
+		if policy.SystemRef == 0 || (len(policy.Tier2UserIds) == 0 && !policy.Tier2SystemAdmins) {
+			continue
+		}
+
+		policies = append(policies, policy)
+	}
+
+	// Number policies saved without an id after the highest one in use
+	var maxId uint
+	for _, policy := range policies {
+		if policy.Id > maxId {
+			maxId = policy.Id
+		}
+	}
+	for i := range policies {
+		if policies[i].Id == 0 {
+			maxId++
+			policies[i].Id = maxId
+		}
+	}
+
+	return policies
+}
+
+// ForAlert returns the policy for an alert of alertType on the talkgroup, or
+// nil. A policy for the talkgroup wins over one for the whole system.
+func (policies EscalationPolicies) ForAlert(systemRef uint, talkgroupRef uint, alertType string) *EscalationPolicy {
+	var systemWide *EscalationPolicy
+	for i := range policies {
+		policy := &policies[i]
+		if policy.SystemRef != systemRef || !policy.covers(alertType) {
+			continue
+		}
+		if policy.TalkgroupRef == talkgroupRef {
+			return policy
+		}
+		if policy.TalkgroupRef == 0 && systemWide == nil {
+			systemWide = policy
+		}
+	}
+	return systemWide
+}
+
+func (policy *EscalationPolicy) covers(alertType string) bool {
+	types := policy.AlertTypes
+	if len(types) == 0 {
+		types = escalationAlertTypes
+	}
+	for _, t := range types {
+		if t == alertType {
+			return true
+		}
+	}
+	return false
+}
+
+func (policy *EscalationPolicy) ackWindow() time.Duration {
+	if policy.AckWindowSeconds > 0 {
+		return time.Duration(policy.AckWindowSeconds) * time.Second
+	}
+	return escalationAckWindow
+}
+
+// alertEscalations holds the escalation jobs of alerts waiting for an
+// acknowledgement, and the timers of those that could not be stored.
+type alertEscalations struct {
+	mutex  sync.Mutex
+	timers map[uint64]*time.Timer
+	jobs   map[uint64]uint64
+}
+
+// track remembers the escalation job of an alert, so an acknowledgement can
+// remove it.
+func (escalations *alertEscalations) track(alertId uint64, jobId uint64) {
+	escalations.mutex.Lock()
+	defer escalations.mutex.Unlock()
+	if escalations.jobs == nil {
+		escalations.jobs = map[uint64]uint64{}
+	}
+	escalations.jobs[alertId] = jobId
+}
+
+// untrack forgets the escalation job of an alert and returns its id.
+func (escalations *alertEscalations) untrack(alertId uint64) (uint64, bool) {
+	escalations.mutex.Lock()
+	defer escalations.mutex.Unlock()
+	jobId, ok := escalations.jobs[alertId]
+	delete(escalations.jobs, alertId)
+	return jobId, ok
+}
+
+func (escalations *alertEscalations) arm(alertId uint64, delay time.Duration, fire func()) {
+	escalations.mutex.Lock()
+	defer escalations.mutex.Unlock()
+	if escalations.timers == nil {
+		escalations.timers = map[uint64]*time.Timer{}
+	}
+	if _, ok := escalations.timers[alertId]; ok {
+		return
+	}
+	escalations.timers[alertId] = time.AfterFunc(delay, fire)
+}
+
+// disarm stops the alert's timer and reports whether one was pending.
+func (escalations *alertEscalations) disarm(alertId uint64) bool {
+	escalations.mutex.Lock()
+	defer escalations.mutex.Unlock()
+	timer, ok := escalations.timers[alertId]
+	if ok {
+		timer.Stop()
+		delete(escalations.timers, alertId)
+	}
+	return ok
+}
+
+// alertRefs returns the system and talkgroup of an alert record.
+func (engine *AlertEngine) alertRefs(alert *AlertRecord) (*System, *Talkgroup, bool) {
+	system, ok := engine.controller.Systems.GetSystemById(alert.SystemId)
+	if !ok {
+		return nil, nil, false
+	}
+	talkgroup, ok := system.Talkgroups.GetTalkgroupById(alert.TalkgroupId)
+	if !ok {
+		return nil, nil, false
+	}
+	return system, talkgroup, true
+}
+
+// jobKindAlertEscalation is a persistent job escalating one alert once its
+// acknowledgement window is over.
+const jobKindAlertEscalation = "alert_escalation"
+
+// alertEscalationJobPayload carries the policy as it was when the alert was
+// raised.
+type alertEscalationJobPayload struct {
+	Alert  AlertRecord      `json:"alert"`
+	Policy EscalationPolicy `json:"policy"`
+}
+
+// startEscalation schedules the escalation of a new alert when a policy
+// covers it. delay overrides the policy window when resuming after a restart.
+func (engine *AlertEngine) startEscalation(alert *AlertRecord, delay time.Duration) {
+	if alert == nil || alert.AlertId == 0 || len(engine.controller.Options.EscalationPolicies) == 0 {
+		return
+	}
+
+	system, talkgroup, ok := engine.alertRefs(alert)
+	if !ok {
+		return
+	}
+	found := engine.controller.Options.EscalationPolicies.ForAlert(system.SystemRef, talkgroup.TalkgroupRef, alert.AlertType)
+	if found == nil {
+		return
+	}
+
+	// Options can be replaced while the timer runs
+	policy := *found
+	record := *alert
+	if delay < 0 {
+		delay = policy.ackWindow()
+	}
+
+	// Stored as a persistent job so the escalation survives a restart
+	if jobs := engine.controller.Jobs; jobs != nil {
+		id, err := jobs.Enqueue(jobKindAlertEscalation, 0, alertEscalationJobPayload{Alert: record, Policy: policy}, nil, delay)
+		if err == nil {
+			engine.escalations.track(alert.AlertId, id)
+			return
+		}
+		engine.controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("alert escalation: failed to store the escalation of alert %d, timing it in memory: %v", alert.AlertId, err))
+	}
+
+	engine.escalations.arm(alert.AlertId, delay, func() {
+		if err := engine.escalate(&record, &policy); err != nil {
+			engine.controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("alert escalation: %v", err))
+		}
+	})
+}
+
+// runAlertEscalationJob escalates an alert, retrying when the database fails.
+func (controller *Controller) runAlertEscalationJob(job *Job) error {
+	var payload alertEscalationJobPayload
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		return jobPermanent(fmt.Errorf("invalid payload: %v", err))
+	}
+	return controller.AlertEngine.escalate(&payload.Alert, &payload.Policy)
+}
+
+// escalate sends an alert nobody acknowledged to the policy's second tier.
+func (engine *AlertEngine) escalate(alert *AlertRecord, policy *EscalationPolicy) error {
+	engine.escalations.disarm(alert.AlertId)
+	engine.escalations.untrack(alert.AlertId)
+
+	db := engine.controller.Database.Sql
+
+	var acks int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM "alertAcks" WHERE "alertId" = $1`, alert.AlertId).Scan(&acks); err != nil {
+		return fmt.Errorf("failed to read acknowledgements of alert %d: %v", alert.AlertId, err)
+	}
+	if acks > 0 {
+		return nil
+	}
+
+	// Claim the escalation so it is only sent once, and not for a purged alert
+	res, err := db.Exec(`UPDATE "alerts" SET "escalatedAt" = $1 WHERE "alertId" = $2 AND "escalatedAt" = 0`, time.Now().UnixMilli(), alert.AlertId)
+	if err != nil {
+		return fmt.Errorf("failed to mark alert %d: %v", alert.AlertId, err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return nil
+	}
+
+	system, talkgroup, ok := engine.alertRefs(alert)
+	if !ok {
+		return nil
+	}
+
+	call := &Call{
+		Id:        alert.CallId,
+		System:    system,
+		Talkgroup: talkgroup,
+		Timestamp: time.UnixMilli(alert.CreatedAt),
+	}
+
+	toneSetName, keywords := alertDetails(talkgroup, alert)
+
+	var userIds []uint64
+	for _, userId := range policy.Tier2UserIds {
+		user := engine.controller.Users.GetUserById(userId)
+		if user == nil || !engine.controller.userHasAccess(user, call) {
+			continue
+		}
+		userIds = append(userIds, userId)
+		go engine.sendAlertNotification(userId, alert.CallId, "escalation")
+	}
+	if len(userIds) > 0 {
+		go engine.controller.sendBatchedPushNotification(userIds, "escalation", call, system.Label, talkgroup.Label, toneSetName, keywords)
+	}
+
+	if policy.Tier2SystemAdmins {
+		engine.controller.CreateSystemAlert(
+			"alert_escalation",
+			"warning",
+			fmt.Sprintf("Unacknowledged %s alert on %s", alert.AlertType, talkgroup.Label),
+			fmt.Sprintf("No one acknowledged the %s alert for call %d on %s / %s within %s.", alert.AlertType, alert.CallId, system.Label, talkgroup.Label, policy.ackWindow()),
+			&SystemAlertData{
+				CallId:         alert.CallId,
+				SystemId:       system.Id,
+				SystemLabel:    system.Label,
+				TalkgroupId:    talkgroup.Id,
+				TalkgroupLabel: talkgroup.Label,
+			},
+			0,
+		)
+	}
+
+	engine.controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf(
+		"alert %d (%s, call %d) not acknowledged within %s, escalated to %d tier-2 user(s) by policy %d",
+		alert.AlertId, alert.AlertType, alert.CallId, policy.ackWindow(), len(userIds), policy.Id,
+	))
+
+	return nil
+}
+
+// ResumeEscalations reschedules recent alerts that were neither acknowledged
+// nor escalated when the server stopped, and have no escalation job left.
+func (engine *AlertEngine) ResumeEscalations() {
+	if len(engine.controller.Options.EscalationPolicies) == 0 {
+		return
+	}
+
+	var longest time.Duration
+	for i := range engine.controller.Options.EscalationPolicies {
+		if window := engine.controller.Options.EscalationPolicies[i].ackWindow(); window > longest {
+			longest = window
+		}
+	}
+
+	// Alerts older than a day are not worth paging anyone for
+	since := time.Now().Add(-longest - 24*time.Hour).UnixMilli()
+
+	rows, err := engine.controller.Database.Sql.Query(`SELECT a."alertId", a."callId", a."systemId", a."talkgroupId", a."alertType", a."toneSetId", a."keywordsMatched", a."createdAt" FROM "alerts" a WHERE a."createdAt" >= $1 AND a."escalatedAt" = 0 AND NOT EXISTS (SELECT 1 FROM "alertAcks" k WHERE k."alertId" = a."alertId") AND NOT EXISTS (SELECT 1 FROM "jobs" j WHERE j."kind" = $2 AND j."status" IN ($3, $4) AND j."payload"::jsonb -> 'alert' ->> 'alertId' = a."alertId"::text)`, since, jobKindAlertEscalation, JobStatusQueued, JobStatusRunning)
+	if err != nil {
+		engine.controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("alert escalation: failed to resume: %v", err))
+		return
+	}
+	defer rows.Close()
+
+	resumed := 0
+	for rows.Next() {
+		alert := &AlertRecord{}
+		if err := rows.Scan(&alert.AlertId, &alert.CallId, &alert.SystemId, &alert.TalkgroupId, &alert.AlertType, &alert.ToneSetId, &alert.KeywordsMatched, &alert.CreatedAt); err != nil {
+			continue
+		}
+		system, talkgroup, ok := engine.alertRefs(alert)
+		if !ok {
+			continue
+		}
+		policy := engine.controller.Options.EscalationPolicies.ForAlert(system.SystemRef, talkgroup.TalkgroupRef, alert.AlertType)
+		if policy == nil {
+			continue
+		}
+		remaining := time.Until(time.UnixMilli(alert.CreatedAt).Add(policy.ackWindow()))
+		if remaining < 0 {
+			remaining = 0
+		}
+		engine.startEscalation(alert, remaining)
+		resumed++
+	}
+
+	if resumed > 0 {
+		engine.controller.Logs.LogEvent(LogLevelInfo, fmt.Sprintf("alert escalation: resumed %d pending alert(s)", resumed))
+	}
+}
+
+// AlertAck is one user's acknowledgement of an alert.
+type AlertAck struct {
+	AlertId uint64 `json:"alertId"`
+	UserId  uint64 `json:"userId"`
+	AckedAt int64  `json:"ackedAt"`
+}
+
+// AcknowledgeAlert records that userId has seen the alert and stops its
+// escalation. Acknowledging twice keeps the first time.
+func (engine *AlertEngine) AcknowledgeAlert(alertId uint64, userId uint64) error {
+	if _, err := engine.controller.Database.Sql.Exec(
+		`INSERT INTO "alertAcks" ("alertId", "userId", "ackedAt") VALUES ($1, $2, $3) ON CONFLICT ("alertId", "userId") DO NOTHING`,
+		alertId, userId, time.Now().UnixMilli(),
+	); err != nil {
+		return err
+	}
+
+	disarmed := engine.escalations.disarm(alertId)
+	if jobId, ok := engine.escalations.untrack(alertId); ok {
+		if deleted, err := engine.controller.Jobs.Delete(jobId); err == nil && deleted {
+			disarmed = true
+		}
+	}
+	if disarmed {
+		engine.controller.Logs.LogEvent(LogLevelInfo, fmt.Sprintf("alert %d acknowledged by user %d, escalation cancelled", alertId, userId))
+	}
+
+	return nil
+}
+
+// ListAlertAcks returns the acknowledgements of an alert, oldest first.
+func (engine *AlertEngine) ListAlertAcks(alertId uint64) ([]AlertAck, error) {
+	rows, err := engine.controller.Database.Sql.Query(`SELECT "alertId", "userId", "ackedAt" FROM "alertAcks" WHERE "alertId" = $1 ORDER BY "ackedAt"`, alertId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	acks := []AlertAck{}
+	for rows.Next() {
+		ack := AlertAck{}
+		if err := rows.Scan(&ack.AlertId, &ack.UserId, &ack.AckedAt); err != nil {
+			return nil, err
+		}
+		acks = append(acks, ack)
+	}
+	return acks, rows.Err()
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEscalationPoliciesFromList(t *testing.T) {
+	policies := escalationPoliciesFromList([]any{
+		map[string]any{
+			"label":            "Fire dispatch",
+			"systemRef":        float64(1),
+			"talkgroupRef":     float64(100),
+			"alertTypes":       []any{"TONE", "bogus"},
+			"ackWindowSeconds": float64(90),
+			"tier2UserIds":     []any{float64(7), float64(7), float64(8)},
+		},
+		map[string]any{"id": float64(4), "systemRef": float64(1), "tier2SystemAdmins": true},
+		map[string]any{"systemRef": float64(2)},                                // nowhere to escalate
+		map[string]any{"tier2UserIds": []any{float64(9)}, "talkgroupRef": 5.0}, // no system
+	})
+
+	if len(policies) != 2 {
+		t.Fatalf("expected 2 policies, got %d", len(policies))
+	}
+	if policies[0].Id != 5 || policies[1].Id != 4 {
+		t.Fatalf("unexpected ids %d, %d", policies[0].Id, policies[1].Id)
+	}
+	if len(policies[0].AlertTypes) != 1 || policies[0].AlertTypes[0] != "tone" {
+		t.Fatalf("unexpected alert types %v", policies[0].AlertTypes)
+	}
+	if len(policies[0].Tier2UserIds) != 2 {
+		t.Fatalf("expected duplicate user ids to be dropped, got %v", policies[0].Tier2UserIds)
+	}
+	if policies[0].ackWindow() != 90*time.Second || policies[1].ackWindow() != escalationAckWindow {
+		t.Fatalf("unexpected ack windows %s, %s", policies[0].ackWindow(), policies[1].ackWindow())
+	}
+}
+
+func TestEscalationPoliciesForAlert(t *testing.T) {
+	policies := EscalationPolicies{
+		{Id: 1, SystemRef: 1},
+		{Id: 2, SystemRef: 1, TalkgroupRef: 100, AlertTypes: []string{"tone"}},
+	}
+
+	tests := []struct {
+		talkgroupRef uint
+		alertType    string
+		want         uint
+	}{
+		{100, "tone", 2},
+		{100, "keyword", 1},
+		{200, "tone+keyword", 1},
+		{200, "life-safety", 0},
+		{200, "pre-alert", 0},
+	}
+	for _, test := range tests {
+		got := uint(0)
+		if policy := policies.ForAlert(1, test.talkgroupRef, test.alertType); policy != nil {
+			got = policy.Id
+		}
+		if got != test.want {
+			t.Fatalf("ForAlert(1, %d, %q) = policy %d, want %d", test.talkgroupRef, test.alertType, got, test.want)
+		}
+	}
+
+	if policies.ForAlert(2, 100, "tone") != nil {
+		t.Fatalf("expected no policy for another system")
+	}
+}
+
+func TestAlertEscalationsDisarm(t *testing.T) {
+	escalations := alertEscalations{}
+	fired := make(chan uint64, 2)
+
+	escalations.arm(1, 20*time.Millisecond, func() { fired <- 1 })
+	escalations.arm(2, 20*time.Millisecond, func() { fired <- 2 })
+	escalations.arm(2, time.Millisecond, func() { fired <- 0 }) // already armed
+
+	if !escalations.disarm(1) {
+		t.Fatalf("expected alert 1 to be pending")
+	}
+	if escalations.disarm(1) {
+		t.Fatalf("expected alert 1 to be disarmed once")
+	}
+
+	select {
+	case id := <-fired:
+		if id != 2 {
+			t.Fatalf("unexpected escalation of alert %d", id)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("alert 2 did not escalate")
+	}
+}
@@ -0,0 +1,303 @@
+// Copyright (C) 2026 Thinline Dynamic Solutions
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/shirou/gopsutil/v4/disk"
+)
+
+// AlertRule raises a system alert when a health metric crosses a threshold,
+// for conditions the built-in monitors don't cover. For example "fewer than
+// 5 calls on system 3 in the last 30 minutes" or "transcription queue deeper
+// than 200".
+type AlertRule struct {
+	Id            uint    `json:"id"`
+	Label         string  `json:"label"`
+	Enabled       bool    `json:"enabled"`
+	Metric        string  `json:"metric"`              // see alertRuleMetrics
+	SystemRef     uint    `json:"systemRef,omitempty"` // ingest_rate and transcription_failures only, 0 = every system
+	Comparison    string  `json:"comparison"`          // >, >=, < or <=
+	Threshold     float64 `json:"threshold"`
+	WindowMinutes uint    `json:"windowMinutes,omitempty"` // counted metrics, default 60
+	Severity      string  `json:"severity,omitempty"`      // default warning
+	RepeatMinutes uint    `json:"repeatMinutes,omitempty"` // default 60
+}
+
+const (
+	// alertRuleMetricIngestRate is calls received per minute over the window.
+	alertRuleMetricIngestRate = "ingest_rate"
+	// alertRuleMetricTranscriptionFailures is calls whose transcription failed
+	// in the window.
+	alertRuleMetricTranscriptionFailures = "transcription_failures"
+	// alertRuleMetricQueueDepth is the transcription queue depth right now.
+	alertRuleMetricQueueDepth = "transcription_queue_depth"
+	// alertRuleMetricDiskUsedPct is the used percentage of the data
+	// directory's disk.
+	alertRuleMetricDiskUsedPct = "disk_used_pct"
+	// alertRuleMetricDiskFreeGB is the free space on the data directory's
+	// disk in gigabytes.
+	alertRuleMetricDiskFreeGB = "disk_free_gb"
+
+	alertRuleAlertType     = "alert_rule"
+	alertRuleCheckInterval = time.Minute
+)
+
+var alertRuleMetrics = map[string]bool{
+	alertRuleMetricIngestRate:            true,
+	alertRuleMetricTranscriptionFailures: true,
+	alertRuleMetricQueueDepth:            true,
+	alertRuleMetricDiskUsedPct:           true,
+	alertRuleMetricDiskFreeGB:            true,
+}
+
+var alertRuleSeverities = map[string]bool{"info": true, "warning": true, "error": true, "critical": true}
+
+func validateAlertRule(rule *AlertRule) error {
+	if !alertRuleMetrics[rule.Metric] {
+		return fmt.Errorf("alert rule %q: unknown metric %q", rule.Label, rule.Metric)
+	}
+	switch rule.Comparison {
+	case ">", ">=", "<", "<=":
+	default:
+		return fmt.Errorf("alert rule %q: comparison must be >, >=, < or <=", rule.Label)
+	}
+	if rule.Severity == "" {
+		rule.Severity = "warning"
+	}
+	if !alertRuleSeverities[rule.Severity] {
+		return fmt.Errorf("alert rule %q: unknown severity %q", rule.Label, rule.Severity)
+	}
+	return nil
+}
+
+// alertRulesFromList parses the admin representation, dropping invalid rules
+// and numbering new ones. The config handler refuses invalid rules with
+// validateAlertRuleList before saving.
+func alertRulesFromList(list []any) []AlertRule {
+	rules := []AlertRule{}
+
+	for _, item := range list {
+		b, err := json.Marshal(item)
+		if err != nil {
+			continue
+		}
+		var rule AlertRule
+		if err := json.Unmarshal(b, &rule); err != nil || validateAlertRule(&rule) != nil {
+			continue
+		}
+		rules = append(rules, rule)
+	}
+
+	var maxId uint
+	for _, rule := range rules {
+		if rule.Id > maxId {
+			maxId = rule.Id
+		}
+	}
+	for i := range rules {
+		if rules[i].Id == 0 {
+			maxId++
+			rules[i].Id = maxId
+		}
+	}
+
+	return rules
+}
+
+// validateAlertRuleList returns the first error of the admin representation.
+func validateAlertRuleList(list []any) error {
+	for _, item := range list {
+		b, err := json.Marshal(item)
+		if err != nil {
+			return err
+		}
+		var rule AlertRule
+		if err := json.Unmarshal(b, &rule); err != nil {
+			return fmt.Errorf("alert rule: %v", err)
+		}
+		if err := validateAlertRule(&rule); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// matches compares the metric value with the threshold.
+func (rule AlertRule) matches(value float64) bool {
+	switch rule.Comparison {
+	case ">":
+		return value > rule.Threshold
+	case ">=":
+		return value >= rule.Threshold
+	case "<":
+		return value < rule.Threshold
+	case "<=":
+		return value <= rule.Threshold
+	}
+	return false
+}
+
+func (rule AlertRule) window() time.Duration {
+	if rule.WindowMinutes == 0 {
+		return time.Hour
+	}
+	return time.Duration(rule.WindowMinutes) * time.Minute
+}
+
+func (rule AlertRule) repeat() time.Duration {
+	if rule.RepeatMinutes == 0 {
+		return time.Hour
+	}
+	return time.Duration(rule.RepeatMinutes) * time.Minute
+}
+
+// describe renders the condition for alert messages, as "ingest_rate < 0.5
+// over 30 minutes on County".
+func (rule AlertRule) describe(systemLabel string) string {
+	s := fmt.Sprintf("%s %s %g", rule.Metric, rule.Comparison, rule.Threshold)
+	switch rule.Metric {
+	case alertRuleMetricIngestRate, alertRuleMetricTranscriptionFailures:
+		s += fmt.Sprintf(" over %d minutes", int(rule.window().Minutes()))
+		if systemLabel != "" {
+			s += " on " + systemLabel
+		}
+	}
+	return s
+}
+
+// alertRuleValue measures the rule's metric.
+func (controller *Controller) alertRuleValue(rule AlertRule, now time.Time) (float64, error) {
+	since := now.Add(-rule.window()).UnixMilli()
+
+	countCalls := func(condition string) (float64, error) {
+		query := `SELECT COUNT(*) FROM "calls" WHERE "timestamp" >= $1` + condition
+		args := []any{since}
+		if rule.SystemRef > 0 {
+			query += ` AND "systemRef" = $2`
+			args = append(args, rule.SystemRef)
+		}
+		var count int64
+		if err := controller.Database.Sql.QueryRow(query, args...).Scan(&count); err != nil {
+			return 0, err
+		}
+		return float64(count), nil
+	}
+
+	switch rule.Metric {
+	case alertRuleMetricIngestRate:
+		count, err := countCalls("")
+		if err != nil {
+			return 0, err
+		}
+		return count / rule.window().Minutes(), nil
+
+	case alertRuleMetricTranscriptionFailures:
+		return countCalls(` AND "transcriptionStatus" = 'failed'`)
+
+	case alertRuleMetricQueueDepth:
+		if controller.TranscriptionQueue == nil {
+			return 0, nil
+		}
+		return float64(controller.TranscriptionQueue.QueueDepth()), nil
+
+	case alertRuleMetricDiskUsedPct, alertRuleMetricDiskFreeGB:
+		usage, err := disk.Usage(controller.Config.BaseDir)
+		if err != nil {
+			return 0, err
+		}
+		if rule.Metric == alertRuleMetricDiskUsedPct {
+			return usage.UsedPercent, nil
+		}
+		return float64(usage.Free) / (1 << 30), nil
+	}
+
+	return 0, fmt.Errorf("unknown metric %q", rule.Metric)
+}
+
+// MonitorAlertRules evaluates every enabled alert rule and raises a system
+// alert for each one whose condition holds, at most once per repeat
+// interval. A rule whose condition has cleared resolves its paged incident.
+func (controller *Controller) MonitorAlertRules() {
+	if !controller.Options.SystemHealthAlertsEnabled {
+		return
+	}
+
+	now := time.Now()
+
+	if controller.alertRuleAlertedAt == nil {
+		controller.alertRuleAlertedAt = map[uint]time.Time{}
+	}
+
+	for _, rule := range controller.Options.AlertRules {
+		if !rule.Enabled {
+			continue
+		}
+
+		var (
+			systemId    uint64
+			systemLabel string
+		)
+		if rule.SystemRef > 0 {
+			system, ok := controller.Systems.GetSystemByRef(rule.SystemRef)
+			if !ok {
+				continue
+			}
+			systemId, systemLabel = system.Id, system.Label
+		}
+
+		value, err := controller.alertRuleValue(rule, now)
+		if err != nil {
+			controller.Logs.LogEvent(LogLevelError, fmt.Sprintf("alert rule %q: %v", rule.Label, err))
+			continue
+		}
+
+		incidentType := fmt.Sprintf("%s:%d", alertRuleAlertType, rule.Id)
+
+		if !rule.matches(value) {
+			delete(controller.alertRuleAlertedAt, rule.Id)
+			controller.IncidentRouting.Resolve(incidentType, systemId)
+			continue
+		}
+
+		if last, ok := controller.alertRuleAlertedAt[rule.Id]; ok && now.Sub(last) < rule.repeat() {
+			continue
+		}
+		controller.alertRuleAlertedAt[rule.Id] = now
+
+		title := rule.Label
+		if title == "" {
+			title = "Alert Rule Triggered"
+		}
+
+		controller.CreateSystemAlert(
+			alertRuleAlertType,
+			rule.Severity,
+			title,
+			fmt.Sprintf("%s (currently %.4g)", rule.describe(systemLabel), value),
+			&SystemAlertData{
+				SystemId:    systemId,
+				SystemLabel: systemLabel,
+				RuleId:      rule.Id,
+				Value:       value,
+			},
+			0, // System-generated
+		)
+	}
+}
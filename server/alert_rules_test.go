@@ -0,0 +1,69 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAlertRuleMatches(t *testing.T) {
+	for _, test := range []struct {
+		comparison string
+		value      float64
+		want       bool
+	}{
+		{">", 10, false},
+		{">", 11, true},
+		{">=", 10, true},
+		{"<", 10, false},
+		{"<", 9.5, true},
+		{"<=", 10, true},
+	} {
+		rule := AlertRule{Comparison: test.comparison, Threshold: 10}
+		if got := rule.matches(test.value); got != test.want {
+			t.Fatalf("%v %s 10 = %v, want %v", test.value, test.comparison, got, test.want)
+		}
+	}
+}
+
+func TestValidateAlertRule(t *testing.T) {
+	rule := AlertRule{Label: "queue", Metric: alertRuleMetricQueueDepth, Comparison: ">", Threshold: 200}
+	if err := validateAlertRule(&rule); err != nil {
+		t.Fatalf("valid rule refused: %v", err)
+	}
+	if rule.Severity != "warning" {
+		t.Fatalf("severity defaulted to %q, want warning", rule.Severity)
+	}
+
+	for _, bad := range []AlertRule{
+		{Label: "metric", Metric: "cpu", Comparison: ">"},
+		{Label: "comparison", Metric: alertRuleMetricDiskUsedPct, Comparison: "=="},
+		{Label: "severity", Metric: alertRuleMetricDiskUsedPct, Comparison: ">", Severity: "page"},
+	} {
+		if err := validateAlertRule(&bad); err == nil || !strings.Contains(err.Error(), bad.Label) {
+			t.Fatalf("rule %q: got %v", bad.Label, err)
+		}
+	}
+}
+
+func TestAlertRulesFromList(t *testing.T) {
+	rules := alertRulesFromList([]any{
+		map[string]any{"id": float64(4), "label": "disk", "enabled": true, "metric": "disk_used_pct", "comparison": ">", "threshold": float64(90)},
+		map[string]any{"label": "bad", "metric": "cpu", "comparison": ">"},
+		map[string]any{"label": "quiet", "metric": "ingest_rate", "comparison": "<", "threshold": 0.2, "systemRef": float64(3), "windowMinutes": float64(30)},
+	})
+	if len(rules) != 2 {
+		t.Fatalf("got %d rules, want 2", len(rules))
+	}
+	if rules[0].Id != 4 || rules[1].Id != 5 {
+		t.Fatalf("got ids %d and %d, want 4 and 5", rules[0].Id, rules[1].Id)
+	}
+	if got := rules[1].describe("County"); got != "ingest_rate < 0.2 over 30 minutes on County" {
+		t.Fatalf("got description %q", got)
+	}
+	if got := rules[0].describe(""); got != "disk_used_pct > 90" {
+		t.Fatalf("got description %q", got)
+	}
+	if err := validateAlertRuleList([]any{map[string]any{"label": "bad", "metric": "cpu", "comparison": ">"}}); err == nil {
+		t.Fatalf("invalid rule list accepted")
+	}
+}
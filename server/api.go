@@ -226,6 +226,7 @@ func (api *Api) CallUploadHandler(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
+		r.Body = http.MaxBytesReader(w, r.Body, api.Controller.Options.UploadLimitsConfig.bodyLimit())
 		mr := multipart.NewReader(r.Body, params["boundary"])
 
 		var rawParts strings.Builder
@@ -236,13 +237,13 @@ func (api *Api) CallUploadHandler(w http.ResponseWriter, r *http.Request) {
 			if err == io.EOF {
 				break
 			} else if err != nil {
-				api.exitWithError(w, http.StatusExpectationFailed, fmt.Sprintf("multipart: %s\n", err.Error()))
+				api.exitWithError(w, uploadReadStatus(err), fmt.Sprintf("multipart: %s\n", err.Error()))
 				return
 			}
 
 			b, err := io.ReadAll(p)
 			if err != nil {
-				api.exitWithError(w, http.StatusExpectationFailed, fmt.Sprintf("ioread: %s\n", err.Error()))
+				api.exitWithError(w, uploadReadStatus(err), fmt.Sprintf("ioread: %s\n", err.Error()))
 				return
 			}
 
@@ -282,7 +283,7 @@ func (api *Api) CallUploadHandler(w http.ResponseWriter, r *http.Request) {
 
 		if ok, err := call.IsValid(); ok {
 			log.Printf("api: [UPLOAD PARSED] -> Valid, passing to HandleCall")
-			api.HandleCall(key, call, w)
+			api.HandleCall(key, call, w, r)
 		} else {
 			log.Printf("api: [UPLOAD PARSED] -> INVALID: %s", err.Error())
 			// Also log to event system
@@ -297,7 +298,7 @@ func (api *Api) CallUploadHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-func (api *Api) HandleCall(key string, call *Call, w http.ResponseWriter) {
+func (api *Api) HandleCall(key string, call *Call, w http.ResponseWriter, r *http.Request) {
 	defer func() {
 		if r := recover(); r != nil {
 			// Enhanced panic logging with call details
@@ -343,11 +344,28 @@ func (api *Api) HandleCall(key string, call *Call, w http.ResponseWriter) {
 	msg := []byte(fmt.Sprintf("Invalid API key for system %v talkgroup %v.\n", systemRef, talkgroupRef))
 
 	if apikey, ok := api.Controller.Apikeys.GetApikey(key); ok {
+		if !api.Controller.IngestRateLimiter.Allow(fmt.Sprintf("apikey:%d", apikey.Id)) {
+			w.Header().Set("Retry-After", "60")
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte("Too many uploads for this API key, please slow down\n"))
+			return
+		}
+
+		if ok, retryAfter := api.Controller.ApikeyUsage.Admit(apikey, time.Now()); !ok {
+			writeApikeyLimited(w, retryAfter)
+			return
+		}
+
 		if apikey.HasAccess(call) {
 			// Store API key ID in call metadata for preferred API key logic
 			apikeyId := apikey.Id
 			call.ApiKeyId = &apikeyId
 
+			if rejection := api.Controller.validateUpload(call); rejection != nil {
+				api.exitWithError(w, rejection.status, fmt.Sprintf("%s (system %v talkgroup %v)\n", rejection.message, systemRef, talkgroupRef))
+				return
+			}
+
 			// Ensure site information is properly resolved before ingestion
 			if call != nil && call.SiteRef == "" && call.Meta.SiteRef != "" {
 				// Try to resolve by siteRef first
@@ -363,6 +381,7 @@ func (api *Api) HandleCall(key string, call *Call, w http.ResponseWriter) {
 			// Use a non-blocking send to avoid deadlocks
 			select {
 			case api.Controller.Ingest <- call:
+				api.Controller.ApikeyUsage.Record(apikey.Id, GetRemoteAddr(r), len(call.Audio), time.Now())
 			default:
 				w.WriteHeader(http.StatusServiceUnavailable)
 				w.Write([]byte("Server busy, please try again\n"))
@@ -403,6 +422,7 @@ func (api *Api) TrunkRecorderCallUploadHandler(w http.ResponseWriter, r *http.Re
 			return
 		}
 
+		r.Body = http.MaxBytesReader(w, r.Body, api.Controller.Options.UploadLimitsConfig.bodyLimit())
 		mr := multipart.NewReader(r.Body, params["boundary"])
 
 		var trRawParts strings.Builder
@@ -415,13 +435,13 @@ func (api *Api) TrunkRecorderCallUploadHandler(w http.ResponseWriter, r *http.Re
 			if err == io.EOF {
 				break
 			} else if err != nil {
-				api.exitWithError(w, http.StatusExpectationFailed, fmt.Sprintf("multipart: %s", err.Error()))
+				api.exitWithError(w, uploadReadStatus(err), fmt.Sprintf("multipart: %s", err.Error()))
 				return
 			}
 
 			b, err := io.ReadAll(p)
 			if err != nil {
-				api.exitWithError(w, http.StatusExpectationFailed, fmt.Sprintf("ioread: %s", err.Error()))
+				api.exitWithError(w, uploadReadStatus(err), fmt.Sprintf("ioread: %s", err.Error()))
 				return
 			}
 
@@ -461,7 +481,7 @@ func (api *Api) TrunkRecorderCallUploadHandler(w http.ResponseWriter, r *http.Re
 
 		if ok, err := call.IsValid(); ok {
 			log.Printf("api: [TR-UPLOAD PARSED] -> Valid, passing to HandleCall")
-			api.HandleCall(key, call, w)
+			api.HandleCall(key, call, w, r)
 
 		} else {
 			log.Printf("api: [TR-UPLOAD PARSED] -> INVALID: %s", err.Error())
@@ -479,23 +499,12 @@ func (api *Api) TrunkRecorderCallUploadHandler(w http.ResponseWriter, r *http.Re
 func (api *Api) exitWithError(w http.ResponseWriter, status int, message string) {
 	api.Controller.Logs.LogEvent(LogLevelError, fmt.Sprintf("api: %s", message))
 
-	w.WriteHeader(status)
-	w.Write([]byte(fmt.Sprintf("%s\n", message)))
+	writeProblem(w, nil, NewAPIError(status, "", message))
 }
 
 // exitWithErrorContext logs an error with additional context (IP, endpoint, user agent, etc.) and writes the error response
-func (api *Api) exitWithErrorContext(w http.ResponseWriter, r *http.Request, status int, message string) {
-	// Extract client IP (handle proxy headers)
-	clientIP := r.RemoteAddr
-	if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
-		// X-Forwarded-For can contain multiple IPs, take the first one
-		ips := strings.Split(forwarded, ",")
-		if len(ips) > 0 {
-			clientIP = strings.TrimSpace(ips[0])
-		}
-	} else if realIP := r.Header.Get("X-Real-IP"); realIP != "" {
-		clientIP = realIP
-	}
+func (api *Api) exitWithErrorContext(w http.ResponseWriter, r *http.Request, status int, code string, message string) {
+	clientIP := GetRemoteAddr(r)
 
 	// Build detailed error message with context
 	userAgent := r.Header.Get("User-Agent")
@@ -515,8 +524,7 @@ func (api *Api) exitWithErrorContext(w http.ResponseWriter, r *http.Request, sta
 	api.Controller.Logs.LogEvent(LogLevelError, contextMsg)
 
 	// Write response (just the message, not the context details)
-	w.WriteHeader(status)
-	w.Write([]byte(fmt.Sprintf("%s\n", message)))
+	writeProblem(w, r, NewAPIError(status, code, message))
 }
 
 // Helper function to generate support button HTML
@@ -622,7 +630,7 @@ func (api *Api) UserRegisterHandler(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 		if !valid {
-			api.exitWithError(w, http.StatusForbidden, "CAPTCHA verification failed. Please try again.")
+			api.exitWithAPIError(w, r, NewAPIError(http.StatusForbidden, ErrCodeCaptchaFailed, "CAPTCHA verification failed. Please try again."))
 			return
 		}
 	}
@@ -1078,6 +1086,12 @@ func (api *Api) UserLoginHandler(w http.ResponseWriter, r *http.Request) {
 	// Get client IP for login attempt tracking
 	clientIP := GetRemoteAddr(r)
 
+	// The IP is checked by LoginAttemptMiddleware; the account is checked here
+	if remaining := api.Controller.LoginAttemptTracker.RemainingLockout(clientIP, request.Email); remaining > 0 {
+		writeLockout(w, r, remaining)
+		return
+	}
+
 	// Turnstile verification (mobile apps are exempt)
 	if api.Controller.Options.TurnstileEnabled {
 		valid, err := api.verifyTurnstile(request.TurnstileToken, clientIP, r)
@@ -1086,7 +1100,7 @@ func (api *Api) UserLoginHandler(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 		if !valid {
-			api.exitWithError(w, http.StatusForbidden, "CAPTCHA verification failed. Please try again.")
+			api.exitWithAPIError(w, r, NewAPIError(http.StatusForbidden, ErrCodeCaptchaFailed, "CAPTCHA verification failed. Please try again."))
 			return
 		}
 	}
@@ -1095,25 +1109,28 @@ func (api *Api) UserLoginHandler(w http.ResponseWriter, r *http.Request) {
 	user := api.Controller.Users.GetUserByEmail(request.Email)
 	if user == nil {
 		// Record failed attempt
-		api.Controller.LoginAttemptTracker.RecordFailedAttempt(clientIP)
-		api.exitWithErrorContext(w, r, http.StatusUnauthorized, "Invalid credentials")
+		api.Controller.LoginAttemptTracker.RecordFailure(clientIP, request.Email)
+		api.exitWithErrorContext(w, r, http.StatusUnauthorized, ErrCodeInvalidCredential, "Invalid credentials")
 		return
 	}
 
 	// Verify password
 	if !user.VerifyPassword(request.Password) {
 		// Record failed attempt
-		api.Controller.LoginAttemptTracker.RecordFailedAttempt(clientIP)
-		api.exitWithErrorContext(w, r, http.StatusUnauthorized, "Invalid credentials")
+		api.Controller.LoginAttemptTracker.RecordFailure(clientIP, request.Email)
+		api.exitWithErrorContext(w, r, http.StatusUnauthorized, ErrCodeInvalidCredential, "Invalid credentials")
 		return
 	}
 
 	// Login successful - reset failed attempts
-	api.Controller.LoginAttemptTracker.RecordSuccess(clientIP)
+	api.Controller.LoginAttemptTracker.RecordAccountSuccess(clientIP, request.Email)
+
+	// A password login lets devices signed out from the session list back in
+	api.Controller.Sessions.ClearRevocations(user.Id)
 
 	// Check if email verification is required
 	if api.Controller.Options.EmailVerificationRequired && !user.Verified {
-		api.exitWithError(w, http.StatusForbidden, "Email verification required. Please check your email and verify your account before logging in.")
+		api.exitWithAPIError(w, r, NewAPIError(http.StatusForbidden, ErrCodeEmailNotVerified, "Email verification required. Please check your email and verify your account before logging in."))
 		return
 	}
 
@@ -1188,6 +1205,14 @@ func (api *Api) RequestPasswordResetHandler(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
+	// Every request counts so reset emails can't be used to flood a mailbox
+	clientIP := GetRemoteAddr(r)
+	if remaining := api.Controller.PasswordResetTracker.RemainingLockout(clientIP, request.Email); remaining > 0 {
+		writeLockout(w, r, remaining)
+		return
+	}
+	api.Controller.PasswordResetTracker.RecordFailure(clientIP, request.Email)
+
 	// Find user
 	user := api.Controller.Users.GetUserByEmail(request.Email)
 	if user == nil {
@@ -1256,18 +1281,27 @@ func (api *Api) ResetPasswordHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	clientIP := GetRemoteAddr(r)
+	if remaining := api.Controller.PasswordResetTracker.RemainingLockout(clientIP, request.Email); remaining > 0 {
+		writeLockout(w, r, remaining)
+		return
+	}
+
 	// Find user
 	user := api.Controller.Users.GetUserByEmail(request.Email)
 	if user == nil {
+		api.Controller.PasswordResetTracker.RecordFailure(clientIP, request.Email)
 		api.exitWithError(w, http.StatusUnauthorized, "Invalid email or code")
 		return
 	}
 
 	// Verify reset code
 	if !user.VerifyResetCode(request.Code) {
+		api.Controller.PasswordResetTracker.RecordFailure(clientIP, request.Email)
 		api.exitWithError(w, http.StatusUnauthorized, "Invalid or expired code")
 		return
 	}
+	api.Controller.PasswordResetTracker.RecordAccountSuccess(clientIP, request.Email)
 
 	// Set new password
 	user.SetPassword(request.NewPassword)
@@ -1967,6 +2001,7 @@ func (api *Api) handleSubscriptionEvent(rawData []byte, status string) {
 	// Update user subscription status
 	user.StripeSubscriptionId = subData.ID
 	user.SubscriptionStatus = status
+	trackTrial(user, &subData, status)
 
 	// Always update PIN expiration based on subscription period end
 	var periodEnd int64 = subData.CurrentPeriodEnd
@@ -2063,6 +2098,11 @@ func (api *Api) handleSubscriptionEvent(rawData []byte, status string) {
 		return
 	}
 
+	// Update the plan entitlements from the subscription prices
+	if err := api.Controller.Billing.SyncSubscription(user, sub, status); err != nil {
+		log.Printf("Failed to update billing entitlement for %s: %v", user.Email, err)
+	}
+
 	// Sync config to file if enabled
 	api.Controller.SyncConfigToFile()
 
@@ -2640,6 +2680,7 @@ func (api *Api) CreateCheckoutSessionHandler(w http.ResponseWriter, r *http.Requ
 		Email      string `json:"email"`
 		SuccessUrl string `json:"successUrl"`
 		CancelUrl  string `json:"cancelUrl"`
+		PromoCode  string `json:"promoCode"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
@@ -2750,6 +2791,23 @@ func (api *Api) CreateCheckoutSessionHandler(w http.ResponseWriter, r *http.Requ
 		}
 	}
 
+	// Apply the promo code the customer entered, or let them enter one on the
+	// checkout page. Stripe does not accept both.
+	if strings.TrimSpace(request.PromoCode) != "" {
+		promotionCodeId, err := resolvePromotionCode(request.PromoCode)
+		if err != nil {
+			log.Printf("Rejected promo code %q for %s: %v", request.PromoCode, request.Email, err)
+			api.exitWithError(w, http.StatusBadRequest, "Invalid or expired promo code")
+			return
+		}
+		params.Discounts = []*stripe.CheckoutSessionDiscountParams{
+			{PromotionCode: stripe.String(promotionCodeId)},
+		}
+		params.Metadata["rdio_promo_code"] = strings.TrimSpace(request.PromoCode)
+	} else {
+		params.AllowPromotionCodes = stripe.Bool(true)
+	}
+
 	// Use existing Stripe customer ID if available, otherwise use email
 	if user.StripeCustomerId != "" {
 		params.Customer = stripe.String(user.StripeCustomerId)
@@ -2815,7 +2873,7 @@ func (api *Api) SettingsGetHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Find user by PIN
-	user := api.Controller.Users.GetUserByPin(pin)
+	user := api.userByPin(r, pin)
 	if user == nil {
 		api.exitWithError(w, http.StatusUnauthorized, "Invalid PIN")
 		return
@@ -2891,14 +2949,15 @@ func (api *Api) AlertsHandler(w http.ResponseWriter, r *http.Request) {
 		// Query alerts with optional since filter
 		// If sinceTimestamp > 0, only fetch alerts created at or after that timestamp
 		// Use >= to ensure we don't miss alerts created at the exact timestamp
+		args := queryArgs{}
 		whereClause := ""
 		if sinceTimestamp > 0 {
-			whereClause = fmt.Sprintf(`WHERE a."createdAt" >= %d`, sinceTimestamp)
+			whereClause = `WHERE a."createdAt" >= ` + args.add(sinceTimestamp)
 		}
 
 		// Query all alerts (no userId filter) with system, talkgroup labels, call transcripts, and tone sequence
-		query := fmt.Sprintf(`SELECT a."alertId", a."callId", a."systemId", a."talkgroupId", a."alertType", a."toneDetected", a."toneSetId", a."keywordsMatched", a."transcriptSnippet", a."createdAt", s."label" as "systemLabel", s."systemRef" as "systemRef", t."label" as "talkgroupLabel", t."name" as "talkgroupName", c."transcript" as "callTranscript", c."transcriptionStatus" as "callTranscriptionStatus", c."toneSequence" as "callToneSequence", c."timestamp" as "callTimestamp", c."alertSummary" as "callAlertSummary" FROM "alerts" a LEFT JOIN "systems" s ON s."systemId" = a."systemId" LEFT JOIN "talkgroups" t ON t."talkgroupId" = a."talkgroupId" LEFT JOIN "calls" c ON c."callId" = a."callId" %s ORDER BY a."createdAt" DESC LIMIT %d`, whereClause, maxAlerts)
-		rows, err := api.Controller.Database.Sql.Query(query)
+		query := fmt.Sprintf(`SELECT a."alertId", a."callId", a."systemId", a."talkgroupId", a."alertType", a."toneDetected", a."toneSetId", a."keywordsMatched", a."transcriptSnippet", a."createdAt", s."label" as "systemLabel", s."systemRef" as "systemRef", t."label" as "talkgroupLabel", t."name" as "talkgroupName", c."transcript" as "callTranscript", c."transcriptionStatus" as "callTranscriptionStatus", c."toneSequence" as "callToneSequence", c."timestamp" as "callTimestamp", c."alertSummary" as "callAlertSummary" FROM "alerts" a LEFT JOIN "systems" s ON s."systemId" = a."systemId" LEFT JOIN "talkgroups" t ON t."talkgroupId" = a."talkgroupId" LEFT JOIN "calls" c ON c."callId" = a."callId" %s ORDER BY a."createdAt" DESC LIMIT %s`, whereClause, args.add(maxAlerts))
+		rows, err := api.Controller.Database.Sql.Query(query, args...)
 		if err != nil {
 			api.exitWithError(w, http.StatusInternalServerError, fmt.Sprintf("failed to query alerts: %v", err))
 			return
@@ -3369,11 +3428,158 @@ func (api *Api) AlertsHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// AlertAckHandler handles GET/POST /api/alerts/ack
+//
+// POST acknowledges an alert, stopping its escalation. The body names the
+// alert, or a call to acknowledge all of its alerts since push notifications
+// only carry the call id: {"alertId": 12} or {"callId": 345}.
+// GET ?alertId= returns the acknowledgements and when the alert escalated.
+func (api *Api) AlertAckHandler(w http.ResponseWriter, r *http.Request) {
+	client := api.getClient(r)
+	if client == nil || client.User == nil {
+		api.exitWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	type alertRow struct {
+		alertId     uint64
+		callId      uint64
+		escalatedAt int64
+	}
+
+	// visibleAlerts returns the alerts matching the condition the user has access to
+	visibleAlerts := func(column string, id uint64) ([]alertRow, error) {
+		rows, err := api.Controller.Database.Sql.Query(fmt.Sprintf(`SELECT "alertId", "callId", "systemId", "talkgroupId", "createdAt", "escalatedAt" FROM "alerts" WHERE "%s" = $1`, column), id)
+		if err != nil {
+			return nil, err
+		}
+		defer rows.Close()
+
+		alerts := []alertRow{}
+		for rows.Next() {
+			var (
+				row         alertRow
+				systemId    uint64
+				talkgroupId uint64
+				createdAt   int64
+			)
+			if err := rows.Scan(&row.alertId, &row.callId, &systemId, &talkgroupId, &createdAt, &row.escalatedAt); err != nil {
+				return nil, err
+			}
+			system, ok := api.Controller.Systems.GetSystemById(systemId)
+			if !ok {
+				continue
+			}
+			talkgroup, ok := system.Talkgroups.GetTalkgroupById(talkgroupId)
+			if !ok {
+				continue
+			}
+			minimalCall := &Call{
+				Id:        row.callId,
+				Timestamp: time.UnixMilli(createdAt),
+				System:    system,
+				Talkgroup: talkgroup,
+			}
+			if !api.Controller.userHasAccess(client.User, minimalCall) {
+				continue
+			}
+			alerts = append(alerts, row)
+		}
+		return alerts, rows.Err()
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		alertId, err := strconv.ParseUint(r.URL.Query().Get("alertId"), 10, 64)
+		if err != nil || alertId == 0 {
+			api.exitWithError(w, http.StatusBadRequest, "alertId is required")
+			return
+		}
+
+		alerts, err := visibleAlerts("alertId", alertId)
+		if err != nil {
+			api.exitWithError(w, http.StatusInternalServerError, fmt.Sprintf("failed to query alert: %v", err))
+			return
+		}
+		if len(alerts) == 0 {
+			api.exitWithError(w, http.StatusNotFound, "alert not found")
+			return
+		}
+
+		acks, err := api.Controller.AlertEngine.ListAlertAcks(alertId)
+		if err != nil {
+			api.exitWithError(w, http.StatusInternalServerError, fmt.Sprintf("failed to query acknowledgements: %v", err))
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{
+			"alertId":      alertId,
+			"acknowledged": len(acks) > 0,
+			"escalatedAt":  alerts[0].escalatedAt,
+			"acks":         acks,
+		})
+
+	case http.MethodPost:
+		var request struct {
+			AlertId uint64 `json:"alertId"`
+			CallId  uint64 `json:"callId"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			api.exitWithError(w, http.StatusBadRequest, fmt.Sprintf("invalid request body: %v", err))
+			return
+		}
+
+		var (
+			alerts []alertRow
+			err    error
+		)
+		switch {
+		case request.AlertId > 0:
+			alerts, err = visibleAlerts("alertId", request.AlertId)
+		case request.CallId > 0:
+			alerts, err = visibleAlerts("callId", request.CallId)
+		default:
+			api.exitWithError(w, http.StatusBadRequest, "alertId or callId is required")
+			return
+		}
+		if err != nil {
+			api.exitWithError(w, http.StatusInternalServerError, fmt.Sprintf("failed to query alert: %v", err))
+			return
+		}
+		if len(alerts) == 0 {
+			api.exitWithError(w, http.StatusNotFound, "alert not found")
+			return
+		}
+
+		acknowledged := []uint64{}
+		for _, alert := range alerts {
+			if err := api.Controller.AlertEngine.AcknowledgeAlert(alert.alertId, client.User.Id); err != nil {
+				api.exitWithError(w, http.StatusInternalServerError, fmt.Sprintf("failed to acknowledge alert: %v", err))
+				return
+			}
+			acknowledged = append(acknowledged, alert.alertId)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{"acknowledged": acknowledged})
+
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
 // transcriptReleasedForUser matches CAL / LCL playback: do not expose transcript text until the
 // call would be playable for this account (per-call effective delay). Rows still in the global
 // delayed queue are excluded in SQL (LEFT JOIN delayed … d."callId" IS NULL).
 func (api *Api) transcriptReleasedForUser(user *User, call *Call) bool {
-	if api.Controller == nil || user == nil || call == nil || call.Timestamp.IsZero() {
+	if api.Controller == nil || user == nil || call == nil {
+		return true
+	}
+	if !api.Controller.Billing.TranscriptsAllowed(user) {
+		return false
+	}
+	if call.Timestamp.IsZero() {
 		return true
 	}
 	def := api.Controller.Options.DefaultSystemDelay
@@ -3425,8 +3631,8 @@ func (api *Api) TranscriptsHandler(w http.ResponseWriter, r *http.Request) {
 		if v, err := strconv.ParseUint(s, 10, 64); err == nil {
 			// Try to resolve systemRef to systemId (client sends systemRef as "systemId")
 			var resolvedId uint64
-			resolveQuery := fmt.Sprintf(`SELECT "systemId" FROM "systems" WHERE "systemRef" = %d`, v)
-			if err := api.Controller.Database.Sql.QueryRow(resolveQuery).Scan(&resolvedId); err == nil {
+			resolveQuery := `SELECT "systemId" FROM "systems" WHERE "systemRef" = $1`
+			if err := api.Controller.Database.Sql.QueryRow(resolveQuery, v).Scan(&resolvedId); err == nil {
 				systemId = resolvedId
 			} else {
 				// Fallback: assume it's already a database systemId
@@ -3439,8 +3645,8 @@ func (api *Api) TranscriptsHandler(w http.ResponseWriter, r *http.Request) {
 			// Try to resolve talkgroupRef to talkgroupId (client sends talkgroupRef as "talkgroupId")
 			if systemId > 0 {
 				var resolvedId uint64
-				resolveQuery := fmt.Sprintf(`SELECT "talkgroupId" FROM "talkgroups" WHERE "systemId" = %d AND "talkgroupRef" = %d`, systemId, v)
-				if err := api.Controller.Database.Sql.QueryRow(resolveQuery).Scan(&resolvedId); err == nil {
+				resolveQuery := `SELECT "talkgroupId" FROM "talkgroups" WHERE "systemId" = $1 AND "talkgroupRef" = $2`
+				if err := api.Controller.Database.Sql.QueryRow(resolveQuery, systemId, v).Scan(&resolvedId); err == nil {
 					talkgroupId = resolvedId
 				} else {
 					// Fallback: assume it's already a database talkgroupId
@@ -3465,31 +3671,33 @@ func (api *Api) TranscriptsHandler(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	// Search query (searches in transcript text)
+	// Search query (searches in transcript text and its English translation)
 	search = strings.TrimSpace(r.URL.Query().Get("search"))
 
+	args := queryArgs{}
 	where := []string{
 		`(c."transcript" IS NOT NULL AND c."transcript" <> '')`,
 		`d."callId" IS NULL`,
 	}
 	if systemId > 0 {
-		where = append(where, fmt.Sprintf(`c."systemId" = %d`, systemId))
+		where = append(where, `c."systemId" = `+args.add(systemId))
 	}
 	if talkgroupId > 0 {
-		where = append(where, fmt.Sprintf(`c."talkgroupId" = %d`, talkgroupId))
+		where = append(where, `c."talkgroupId" = `+args.add(talkgroupId))
 	}
 	if status != "" {
-		where = append(where, fmt.Sprintf(`c."transcriptionStatus" = '%s'`, escapeQuotes(status)))
+		where = append(where, `c."transcriptionStatus" = `+args.add(status))
 	}
 	if dateFrom > 0 {
-		where = append(where, fmt.Sprintf(`c."timestamp" >= %d`, dateFrom))
+		where = append(where, `c."timestamp" >= `+args.add(dateFrom))
 	}
 	if dateTo > 0 {
-		where = append(where, fmt.Sprintf(`c."timestamp" <= %d`, dateTo))
+		where = append(where, `c."timestamp" <= `+args.add(dateTo))
 	}
 	if search != "" {
 		// Use ILIKE for case-insensitive search in PostgreSQL
-		where = append(where, fmt.Sprintf(`c."transcript" ILIKE '%%%s%%'`, escapeQuotes(search)))
+		pattern := args.add("%" + search + "%")
+		where = append(where, fmt.Sprintf(`(c."transcript" ILIKE %[1]s OR c."transcriptTranslation" ILIKE %[1]s)`, pattern))
 	}
 	whereClause := strings.Join(where, " AND ")
 
@@ -3501,7 +3709,7 @@ func (api *Api) TranscriptsHandler(w http.ResponseWriter, r *http.Request) {
 
 	for chunk := 0; uint(len(results)) < limit && chunk < maxChunks; chunk++ {
 		query := fmt.Sprintf(
-			`SELECT c."callId", c."systemId", c."talkgroupId", c."transcriptionStatus", c."transcript", COALESCE(c."reviewedTranscript", ''), COALESCE(c."trainingReviewStatus", ''), c."timestamp", c."alertSummary", s."label" as "systemLabel", t."label" as "talkgroupLabel", t."name" as "talkgroupName" `+
+			`SELECT c."callId", c."systemId", c."talkgroupId", c."transcriptionStatus", c."transcript", COALESCE(c."reviewedTranscript", ''), COALESCE(c."trainingReviewStatus", ''), c."timestamp", c."alertSummary", c."transcriptTranslation", c."summaryFields", s."label" as "systemLabel", t."label" as "talkgroupLabel", t."name" as "talkgroupName" `+
 				`FROM "calls" c `+
 				`LEFT JOIN "delayed" AS d ON d."callId" = c."callId" `+
 				`LEFT JOIN "systems" s ON s."systemId" = c."systemId" `+
@@ -3510,7 +3718,7 @@ func (api *Api) TranscriptsHandler(w http.ResponseWriter, r *http.Request) {
 			whereClause, chunkSize, dbScanOffset,
 		)
 
-		rows, err := api.Controller.Database.Sql.Query(query)
+		rows, err := api.Controller.Database.Sql.Query(query, args...)
 		if err != nil {
 			log.Printf("TranscriptsHandler: SQL query error: %v, query: %s", err, query)
 			api.exitWithError(w, http.StatusInternalServerError, fmt.Sprintf("failed to query transcripts: %v", err))
@@ -3530,12 +3738,14 @@ func (api *Api) TranscriptsHandler(w http.ResponseWriter, r *http.Request) {
 				trainingReviewStatus sql.NullString
 				callTimestamp       sql.NullInt64
 				alertSummary        sql.NullString
+				transcriptTranslation sql.NullString
+				summaryFields       sql.NullString
 				systemLabel         sql.NullString
 				talkgroupLabel      sql.NullString
 				talkgroupName       sql.NullString
 			)
 
-			if err := rows.Scan(&callId, &sysId, &tgId, &transcriptionStatus, &transcript, &reviewedTranscript, &trainingReviewStatus, &callTimestamp, &alertSummary, &systemLabel, &talkgroupLabel, &talkgroupName); err != nil {
+			if err := rows.Scan(&callId, &sysId, &tgId, &transcriptionStatus, &transcript, &reviewedTranscript, &trainingReviewStatus, &callTimestamp, &alertSummary, &transcriptTranslation, &summaryFields, &systemLabel, &talkgroupLabel, &talkgroupName); err != nil {
 				continue
 			}
 
@@ -3586,10 +3796,16 @@ func (api *Api) TranscriptsHandler(w http.ResponseWriter, r *http.Request) {
 				}
 				entry["transcript"] = t
 			}
+			if transcriptTranslation.Valid && transcriptTranslation.String != "" {
+				entry["transcriptTranslation"] = transcriptTranslation.String
+			}
 			entry["timestamp"] = callTimestamp.Int64
 			if alertSummary.Valid && alertSummary.String != "" {
 				entry["alertSummary"] = alertSummary.String
 			}
+			if fields := parseSummaryFields(summaryFields.String); fields != nil {
+				entry["summaryFields"] = fields
+			}
 			if systemLabel.Valid {
 				entry["systemLabel"] = systemLabel.String
 			}
@@ -3631,6 +3847,8 @@ func (api *Api) TranscriptsHandler(w http.ResponseWriter, r *http.Request) {
 		dbScanOffset += uint64(chunkSize)
 	}
 
+	api.Controller.attachCallUnits(results)
+
 	if b, err := json.Marshal(results); err == nil {
 		w.Header().Set("Content-Type", "application/json")
 		w.Write(b)
@@ -3709,6 +3927,11 @@ func (api *Api) AlertPreferencesHandler(w http.ResponseWriter, r *http.Request)
 				"toneSetSounds":      pref.ToneSetSounds,
 				"pagerAlert":         pref.PagerAlert,
 				"toneSetPagerAlerts": pref.ToneSetPagerAlerts,
+				"emailAlerts":        pref.EmailAlerts,
+				"emailDigest":        pref.EmailDigest,
+			}
+			if pref.QuietHours != nil {
+				prefMap["quietHours"] = pref.QuietHours
 			}
 
 			// Include systemRef and talkgroupRef for frontend matching
@@ -3759,6 +3982,8 @@ func (api *Api) AlertPreferencesHandler(w http.ResponseWriter, r *http.Request)
 				toneSetSounds      map[string]string
 				pagerAlert         bool
 				toneSetPagerAlerts map[string]bool
+				emailAlerts        *bool
+				emailDigest        *bool
 			)
 
 			// Accept either systemRef or systemId field names — prefer systemRef
@@ -3794,6 +4019,10 @@ func (api *Api) AlertPreferencesHandler(w http.ResponseWriter, r *http.Request)
 					}
 				}
 			}
+			if err := validateKeywordPatterns(keywords); err != nil {
+				api.exitWithError(w, http.StatusBadRequest, err.Error())
+				return
+			}
 			if v, ok := pref["keywordListIds"].([]any); ok {
 				for _, id := range v {
 					switch idVal := id.(type) {
@@ -3838,15 +4067,48 @@ func (api *Api) AlertPreferencesHandler(w http.ResponseWriter, r *http.Request)
 					}
 				}
 			}
+			// Apps without email settings leave them unchanged
+			if v, ok := pref["emailAlerts"].(bool); ok {
+				emailAlerts = &v
+			}
+			if v, ok := pref["emailDigest"].(bool); ok {
+				emailDigest = &v
+			}
+			// Quiet hours are left unchanged when absent and cleared by null
+			quietHoursJson := ""
+			_, hasQuietHours := pref["quietHours"]
+			if v, ok := pref["quietHours"].(map[string]any); ok {
+				quietHours := &QuietHours{}
+				b, _ := json.Marshal(v)
+				if err := json.Unmarshal(b, quietHours); err != nil {
+					api.exitWithError(w, http.StatusBadRequest, fmt.Sprintf("invalid quiet hours: %v", err))
+					return
+				}
+				if err := quietHours.Validate(); err != nil {
+					api.exitWithError(w, http.StatusBadRequest, fmt.Sprintf("invalid quiet hours: %v", err))
+					return
+				}
+				b, _ = json.Marshal(quietHours)
+				quietHoursJson = string(b)
+			}
+
+			// The user's own quiet hours go on the row without system and talkgroup
+			if hasQuietHours && requestSystem == 0 && requestTg == 0 {
+				if _, err := tx.Exec(`INSERT INTO "userAlertPreferences" ("userId", "systemId", "talkgroupId", "quietHours") VALUES ($1, 0, 0, $2) ON CONFLICT ("userId", "systemId", "talkgroupId") DO UPDATE SET "quietHours" = $2`, client.User.Id, quietHoursJson); err != nil {
+					api.exitWithError(w, http.StatusInternalServerError, fmt.Sprintf("failed to update quiet hours: %v", err))
+					return
+				}
+				continue
+			}
 
 			// Resolve systemId: prefer systemRef, fallback to systemId
 			systemId = 0
 			// Try systemRef first to avoid collision (e.g., OH Geauga systemRef=28 vs OH Statewide MA systemId=28)
-			resolveSystemQuery := fmt.Sprintf(`SELECT "systemId" FROM "systems" WHERE "systemRef" = %d`, requestSystem)
-			if err := api.Controller.Database.Sql.QueryRow(resolveSystemQuery).Scan(&systemId); err != nil {
+			resolveSystemQuery := `SELECT "systemId" FROM "systems" WHERE "systemRef" = $1`
+			if err := api.Controller.Database.Sql.QueryRow(resolveSystemQuery, requestSystem).Scan(&systemId); err != nil {
 				// Fallback: try as systemId
-				resolveSystemQuery = fmt.Sprintf(`SELECT "systemId" FROM "systems" WHERE "systemId" = %d`, requestSystem)
-				if err := api.Controller.Database.Sql.QueryRow(resolveSystemQuery).Scan(&systemId); err != nil {
+				resolveSystemQuery = `SELECT "systemId" FROM "systems" WHERE "systemId" = $1`
+				if err := api.Controller.Database.Sql.QueryRow(resolveSystemQuery, requestSystem).Scan(&systemId); err != nil {
 					api.Controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("skipping preference: could not resolve systemId from value=%d", requestSystem))
 					continue
 				}
@@ -3856,11 +4118,11 @@ func (api *Api) AlertPreferencesHandler(w http.ResponseWriter, r *http.Request)
 			var dbTalkgroupId uint64 = 0
 			var toneDetectionEnabled bool = false
 			// Try talkgroupRef first
-			verifyQuery := fmt.Sprintf(`SELECT "talkgroupId", "toneDetectionEnabled" FROM "talkgroups" WHERE "systemId" = %d AND "talkgroupRef" = %d`, systemId, requestTg)
-			if err := api.Controller.Database.Sql.QueryRow(verifyQuery).Scan(&dbTalkgroupId, &toneDetectionEnabled); err != nil {
+			verifyQuery := `SELECT "talkgroupId", "toneDetectionEnabled" FROM "talkgroups" WHERE "systemId" = $1 AND "talkgroupRef" = $2`
+			if err := api.Controller.Database.Sql.QueryRow(verifyQuery, systemId, requestTg).Scan(&dbTalkgroupId, &toneDetectionEnabled); err != nil {
 				// Fallback: try as talkgroupId
-				verifyQuery = fmt.Sprintf(`SELECT "talkgroupId", "toneDetectionEnabled" FROM "talkgroups" WHERE "systemId" = %d AND "talkgroupId" = %d`, systemId, requestTg)
-				if err := api.Controller.Database.Sql.QueryRow(verifyQuery).Scan(&dbTalkgroupId, &toneDetectionEnabled); err != nil {
+				verifyQuery = `SELECT "talkgroupId", "toneDetectionEnabled" FROM "talkgroups" WHERE "systemId" = $1 AND "talkgroupId" = $2`
+				if err := api.Controller.Database.Sql.QueryRow(verifyQuery, systemId, requestTg).Scan(&dbTalkgroupId, &toneDetectionEnabled); err != nil {
 					// Talkgroup doesn't exist, skip this preference
 					api.Controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("skipping preference for non-existent talkgroup: systemId=%d, providedTalkgroup=%d", systemId, requestTg))
 					continue
@@ -3908,9 +4170,18 @@ func (api *Api) AlertPreferencesHandler(w http.ResponseWriter, r *http.Request)
 			}
 
 			// Upsert preference using verified database talkgroupId
-			query := fmt.Sprintf(`INSERT INTO "userAlertPreferences" ("userId", "systemId", "talkgroupId", "alertEnabled", "toneAlerts", "keywordAlerts", "keywords", "keywordListIds", "toneSetIds", "notificationSound", "toneSetSounds", "pagerAlert", "toneSetPagerAlerts") VALUES (%d, %d, %d, %t, %t, %t, $1, $2, $3, $4, $5, %t, $6) ON CONFLICT ("userId", "systemId", "talkgroupId") DO UPDATE SET "alertEnabled" = %t, "toneAlerts" = %t, "keywordAlerts" = %t, "keywords" = $1, "keywordListIds" = $2, "toneSetIds" = $3, "notificationSound" = $4, "toneSetSounds" = $5, "pagerAlert" = %t, "toneSetPagerAlerts" = $6`, client.User.Id, systemId, dbTalkgroupId, alertEnabled, toneAlerts, keywordAlerts, pagerAlert, alertEnabled, toneAlerts, keywordAlerts, pagerAlert)
+			query := `INSERT INTO "userAlertPreferences" ("userId", "systemId", "talkgroupId", "alertEnabled", "toneAlerts", "keywordAlerts", "keywords", "keywordListIds", "toneSetIds", "notificationSound", "toneSetSounds", "pagerAlert", "toneSetPagerAlerts", "emailAlerts", "emailDigest", "quietHours") VALUES ($8, $9, $10, $11, $12, $13, $1, $2, $3, $4, $5, $14, $6, $15, $16, $7) ON CONFLICT ("userId", "systemId", "talkgroupId") DO UPDATE SET "alertEnabled" = $11, "toneAlerts" = $12, "keywordAlerts" = $13, "keywords" = $1, "keywordListIds" = $2, "toneSetIds" = $3, "notificationSound" = $4, "toneSetSounds" = $5, "pagerAlert" = $14, "toneSetPagerAlerts" = $6`
+			if emailAlerts != nil {
+				query += `, "emailAlerts" = $15`
+			}
+			if emailDigest != nil {
+				query += `, "emailDigest" = $16`
+			}
+			if hasQuietHours {
+				query += `, "quietHours" = $7`
+			}
 
-			if _, err := tx.Exec(query, string(keywordsJson), string(keywordListIdsJson), string(toneSetIdsJson), notificationSound, string(toneSetSoundsJson), string(toneSetPagerAlertsJson)); err != nil {
+			if _, err := tx.Exec(query, string(keywordsJson), string(keywordListIdsJson), string(toneSetIdsJson), notificationSound, string(toneSetSoundsJson), string(toneSetPagerAlertsJson), quietHoursJson, client.User.Id, systemId, dbTalkgroupId, alertEnabled, toneAlerts, keywordAlerts, pagerAlert, emailAlerts != nil && *emailAlerts, emailDigest != nil && *emailDigest); err != nil {
 				api.exitWithError(w, http.StatusInternalServerError, fmt.Sprintf("failed to update preference: %v", err))
 				return
 			}
@@ -4010,12 +4281,17 @@ func (api *Api) KeywordListsHandler(w http.ResponseWriter, r *http.Request) {
 			order = uint(v)
 		}
 
+		if err := validateKeywordPatterns(keywords); err != nil {
+			api.exitWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
 		keywordsJson, _ := json.Marshal(keywords)
 
-		query := fmt.Sprintf(`INSERT INTO "keywordLists" ("label", "description", "keywords", "order", "createdAt") VALUES ('%s', '%s', '%s', %d, %d) RETURNING "keywordListId"`, escapeQuotes(label), escapeQuotes(description), escapeQuotes(string(keywordsJson)), order, time.Now().UnixMilli())
+		query := `INSERT INTO "keywordLists" ("label", "description", "keywords", "order", "createdAt") VALUES ($1, $2, $3, $4, $5) RETURNING "keywordListId"`
 
 		var listId uint64
-		if err := api.Controller.Database.Sql.QueryRow(query).Scan(&listId); err != nil {
+		if err := api.Controller.Database.Sql.QueryRow(query, label, description, string(keywordsJson), order, time.Now().UnixMilli()).Scan(&listId); err != nil {
 			api.exitWithError(w, http.StatusInternalServerError, fmt.Sprintf("failed to create keyword list: %v", err))
 			return
 		}
@@ -4092,11 +4368,16 @@ func (api *Api) KeywordListHandler(w http.ResponseWriter, r *http.Request) {
 			order = uint(v)
 		}
 
+		if err := validateKeywordPatterns(keywords); err != nil {
+			api.exitWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
 		keywordsJson, _ := json.Marshal(keywords)
 
-		query := fmt.Sprintf(`UPDATE "keywordLists" SET "label" = '%s', "description" = '%s', "keywords" = '%s', "order" = %d WHERE "keywordListId" = %d`, escapeQuotes(label), escapeQuotes(description), escapeQuotes(string(keywordsJson)), order, listId)
+		query := `UPDATE "keywordLists" SET "label" = $1, "description" = $2, "keywords" = $3, "order" = $4 WHERE "keywordListId" = $5`
 
-		if _, err := api.Controller.Database.Sql.Exec(query); err != nil {
+		if _, err := api.Controller.Database.Sql.Exec(query, label, description, string(keywordsJson), order, listId); err != nil {
 			api.exitWithError(w, http.StatusInternalServerError, fmt.Sprintf("failed to update keyword list: %v", err))
 			return
 		}
@@ -4143,8 +4424,8 @@ func (api *Api) KeywordListHandler(w http.ResponseWriter, r *http.Request) {
 				// Update the preference if it referenced the deleted keyword list
 				if hasReference {
 					newIdsJson, _ := json.Marshal(newIds)
-					updateQuery := fmt.Sprintf(`UPDATE "userAlertPreferences" SET "keywordListIds" = '%s' WHERE "userAlertPreferenceId" = %d`, escapeQuotes(string(newIdsJson)), prefId)
-					if _, err := api.Controller.Database.Sql.Exec(updateQuery); err != nil {
+					updateQuery := `UPDATE "userAlertPreferences" SET "keywordListIds" = $1 WHERE "userAlertPreferenceId" = $2`
+					if _, err := api.Controller.Database.Sql.Exec(updateQuery, string(newIdsJson), prefId); err != nil {
 						log.Printf("Warning: failed to update user alert preference %d when deleting keyword list %d: %v", prefId, listId, err)
 					}
 				}
@@ -4152,8 +4433,8 @@ func (api *Api) KeywordListHandler(w http.ResponseWriter, r *http.Request) {
 		}
 
 		// Now delete the keyword list
-		query := fmt.Sprintf(`DELETE FROM "keywordLists" WHERE "keywordListId" = %d`, listId)
-		if _, err := api.Controller.Database.Sql.Exec(query); err != nil {
+		query := `DELETE FROM "keywordLists" WHERE "keywordListId" = $1`
+		if _, err := api.Controller.Database.Sql.Exec(query, listId); err != nil {
 			api.exitWithError(w, http.StatusInternalServerError, fmt.Sprintf("failed to delete keyword list: %v", err))
 			return
 		}
@@ -4204,8 +4485,13 @@ func (api *Api) getClient(r *http.Request) *Client {
 		}
 	}
 
-	// Then try to find user by PIN
-	user := api.Controller.Users.GetUserByPin(token)
+	// Then try to find user by PIN; an expired admin JWT is not a PIN guess
+	var user *User
+	if strings.Count(token, ".") == 2 {
+		user = api.Controller.Users.GetUserByPin(token)
+	} else {
+		user = api.userByPin(r, token)
+	}
 	if user != nil {
 		// Create a client for this request
 		return &Client{
@@ -4254,7 +4540,7 @@ func (api *Api) SettingsSaveHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Find user by PIN
-	user := api.Controller.Users.GetUserByPin(pin)
+	user := api.userByPin(r, pin)
 	if user == nil {
 		api.exitWithError(w, http.StatusUnauthorized, "Invalid PIN")
 		return
@@ -4323,7 +4609,7 @@ func (api *Api) AccountGetHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Find user by PIN
-	user := api.Controller.Users.GetUserByPin(pin)
+	user := api.userByPin(r, pin)
 	if user == nil {
 		api.exitWithError(w, http.StatusUnauthorized, "Invalid PIN")
 		return
@@ -4458,7 +4744,7 @@ func (api *Api) AccountRequestEmailChangeVerificationHandler(w http.ResponseWrit
 	}
 
 	// Find user by PIN
-	user := api.Controller.Users.GetUserByPin(pin)
+	user := api.userByPin(r, pin)
 	if user == nil {
 		api.exitWithError(w, http.StatusUnauthorized, "Invalid PIN")
 		return
@@ -4522,7 +4808,7 @@ func (api *Api) AccountVerifyEmailChangeCodeHandler(w http.ResponseWriter, r *ht
 	}
 
 	// Find user by PIN
-	user := api.Controller.Users.GetUserByPin(pin)
+	user := api.userByPin(r, pin)
 	if user == nil {
 		api.exitWithError(w, http.StatusUnauthorized, "Invalid PIN")
 		return
@@ -4583,7 +4869,7 @@ func (api *Api) AccountUpdateEmailHandler(w http.ResponseWriter, r *http.Request
 	}
 
 	// Find user by PIN
-	user := api.Controller.Users.GetUserByPin(pin)
+	user := api.userByPin(r, pin)
 	if user == nil {
 		api.exitWithError(w, http.StatusUnauthorized, "Invalid PIN")
 		return
@@ -4797,7 +5083,7 @@ func (api *Api) AccountRequestPasswordChangeVerificationHandler(w http.ResponseW
 	}
 
 	// Find user by PIN
-	user := api.Controller.Users.GetUserByPin(pin)
+	user := api.userByPin(r, pin)
 	if user == nil {
 		api.exitWithError(w, http.StatusUnauthorized, "Invalid PIN")
 		return
@@ -4861,7 +5147,7 @@ func (api *Api) AccountVerifyPasswordChangeCodeHandler(w http.ResponseWriter, r
 	}
 
 	// Find user by PIN
-	user := api.Controller.Users.GetUserByPin(pin)
+	user := api.userByPin(r, pin)
 	if user == nil {
 		api.exitWithError(w, http.StatusUnauthorized, "Invalid PIN")
 		return
@@ -4919,7 +5205,7 @@ func (api *Api) AccountUpdatePasswordHandler(w http.ResponseWriter, r *http.Requ
 	}
 
 	// Find user by PIN
-	user := api.Controller.Users.GetUserByPin(pin)
+	user := api.userByPin(r, pin)
 	if user == nil {
 		api.exitWithError(w, http.StatusUnauthorized, "Invalid PIN")
 		return
@@ -5002,7 +5288,7 @@ func (api *Api) BillingPortalSessionHandler(w http.ResponseWriter, r *http.Reque
 	}
 
 	// Find user by PIN
-	user := api.Controller.Users.GetUserByPin(pin)
+	user := api.userByPin(r, pin)
 	if user == nil {
 		api.exitWithError(w, http.StatusUnauthorized, "Invalid PIN")
 		return
@@ -5105,6 +5391,12 @@ func (api *Api) GroupAdminLoginHandler(w http.ResponseWriter, r *http.Request) {
 	// Get client IP for login attempt tracking
 	clientIP := GetRemoteAddr(r)
 
+	// The IP is checked by LoginAttemptMiddleware; the account is checked here
+	if remaining := api.Controller.LoginAttemptTracker.RemainingLockout(clientIP, request.Email); remaining > 0 {
+		writeLockout(w, r, remaining)
+		return
+	}
+
 	// Turnstile verification (mobile apps are exempt)
 	if api.Controller.Options.TurnstileEnabled {
 		valid, err := api.verifyTurnstile(request.TurnstileToken, clientIP, r)
@@ -5113,7 +5405,7 @@ func (api *Api) GroupAdminLoginHandler(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 		if !valid {
-			api.exitWithError(w, http.StatusForbidden, "CAPTCHA verification failed. Please try again.")
+			api.exitWithAPIError(w, r, NewAPIError(http.StatusForbidden, ErrCodeCaptchaFailed, "CAPTCHA verification failed. Please try again."))
 			return
 		}
 	}
@@ -5121,17 +5413,17 @@ func (api *Api) GroupAdminLoginHandler(w http.ResponseWriter, r *http.Request) {
 	user := api.Controller.Users.GetUserByEmail(request.Email)
 	if user == nil || !user.VerifyPassword(request.Password) {
 		// Record failed attempt
-		api.Controller.LoginAttemptTracker.RecordFailedAttempt(clientIP)
-		api.exitWithErrorContext(w, r, http.StatusUnauthorized, "Invalid credentials")
+		api.Controller.LoginAttemptTracker.RecordFailure(clientIP, request.Email)
+		api.exitWithErrorContext(w, r, http.StatusUnauthorized, ErrCodeInvalidCredential, "Invalid credentials")
 		return
 	}
 
 	// Login successful - reset failed attempts
-	api.Controller.LoginAttemptTracker.RecordSuccess(clientIP)
+	api.Controller.LoginAttemptTracker.RecordAccountSuccess(clientIP, request.Email)
 
 	// Check if email verification is required
 	if api.Controller.Options.EmailVerificationRequired && !user.Verified {
-		api.exitWithError(w, http.StatusForbidden, "Email verification required. Please check your email and verify your account before logging in.")
+		api.exitWithAPIError(w, r, NewAPIError(http.StatusForbidden, ErrCodeEmailNotVerified, "Email verification required. Please check your email and verify your account before logging in."))
 		return
 	}
 
@@ -5180,7 +5472,7 @@ func (api *Api) getGroupAdminUser(r *http.Request) (*User, *UserGroup, error) {
 		return nil, nil, fmt.Errorf("PIN required")
 	}
 
-	user := api.Controller.Users.GetUserByPin(pin)
+	user := api.userByPin(r, pin)
 	if user == nil {
 		return nil, nil, fmt.Errorf("Invalid PIN")
 	}
@@ -8530,7 +8822,7 @@ func (api *Api) UserTransferToPublicHandler(w http.ResponseWriter, r *http.Reque
 	}
 
 	// Find user by PIN
-	user := api.Controller.Users.GetUserByPin(request.Pin)
+	user := api.userByPin(r, request.Pin)
 	if user == nil {
 		api.exitWithError(w, http.StatusUnauthorized, "Invalid PIN")
 		return
@@ -8882,7 +9174,7 @@ func (api *Api) SystemAlertsHandler(w http.ResponseWriter, r *http.Request) {
 	case http.MethodPost:
 		// Only system admins can create alerts
 		if !client.User.SystemAdmin {
-			api.exitWithError(w, http.StatusForbidden, "system admin access required")
+			api.exitWithAPIError(w, r, NewAPIError(http.StatusForbidden, ErrCodeAdminRequired, "system admin access required"))
 			return
 		}
 		// Create a manual system alert
@@ -8968,7 +9260,7 @@ func (api *Api) SystemAlertDismissHandler(w http.ResponseWriter, r *http.Request
 
 	// Check if user is a system admin
 	if !client.User.SystemAdmin {
-		api.exitWithError(w, http.StatusForbidden, "system admin access required")
+		api.exitWithAPIError(w, r, NewAPIError(http.StatusForbidden, ErrCodeAdminRequired, "system admin access required"))
 		return
 	}
 
@@ -9027,10 +9319,10 @@ func (api *Api) StatsHandler(w http.ResponseWriter, r *http.Request) {
 	var systemFilterArgs []interface{}
 	if sid := r.URL.Query().Get("systemId"); sid != "" {
 		if id, err := strconv.Atoi(sid); err == nil {
-			systemFilter = fmt.Sprintf(` AND c."systemId" = %d`, id)
+			systemFilter = ` AND c."systemId" = $2`
+			systemFilterArgs = []interface{}{id}
 		}
 	}
-	_ = systemFilterArgs // unused but kept for clarity
 
 	// ── 0. Available systems (for frontend dropdown) ───────────────────────
 	type SystemItem struct {
@@ -9064,7 +9356,7 @@ func (api *Api) StatsHandler(w http.ResponseWriter, r *http.Request) {
 		FROM calls c
 		WHERE c.timestamp >= $1`+systemFilter+`
 		GROUP BY minute
-		ORDER BY minute ASC`, oneHourAgo)
+		ORDER BY minute ASC`, append([]interface{}{oneHourAgo}, systemFilterArgs...)...)
 	if err == nil {
 		defer cpmRows.Close()
 		for cpmRows.Next() {
@@ -9092,7 +9384,7 @@ func (api *Api) StatsHandler(w http.ResponseWriter, r *http.Request) {
 		WHERE c.timestamp >= $1`+systemFilter+`
 		GROUP BY t."talkgroupId", t.label, t."talkgroupRef"
 		ORDER BY count DESC
-		LIMIT 10`, twentyFourHoursAgo)
+		LIMIT 10`, append([]interface{}{twentyFourHoursAgo}, systemFilterArgs...)...)
 	if err == nil {
 		defer tgRows.Close()
 		for tgRows.Next() {
@@ -9121,7 +9413,7 @@ func (api *Api) StatsHandler(w http.ResponseWriter, r *http.Request) {
 		FROM calls c
 		WHERE c.timestamp >= $1`+systemFilter+`
 		GROUP BY hour
-		ORDER BY hour ASC`, sevenDaysAgo)
+		ORDER BY hour ASC`, append([]interface{}{sevenDaysAgo}, systemFilterArgs...)...)
 	if err == nil {
 		defer hourRows.Close()
 		for hourRows.Next() {
@@ -9144,10 +9436,8 @@ func (api *Api) StatsHandler(w http.ResponseWriter, r *http.Request) {
 
 	// Build system filter using systemId from alerts table
 	toneSystemFilter := ""
-	if sid := r.URL.Query().Get("systemId"); sid != "" {
-		if id, err2 := strconv.Atoi(sid); err2 == nil {
-			toneSystemFilter = fmt.Sprintf(` AND a."systemId" = %d`, id)
-		}
+	if systemFilterArgs != nil {
+		toneSystemFilter = ` AND a."systemId" = $2`
 	}
 
 	toneRows, err := db.Query(`
@@ -9176,7 +9466,7 @@ func (api *Api) StatsHandler(w http.ResponseWriter, r *http.Request) {
 		  AND a."toneDetected" = true`+toneSystemFilter+`
 		GROUP BY a."toneSetId", t."talkgroupId", t."toneSets", t.label, t."talkgroupRef"
 		ORDER BY count DESC
-		LIMIT 10`, twentyFourHoursAgo)
+		LIMIT 10`, append([]interface{}{twentyFourHoursAgo}, systemFilterArgs...)...)
 	if err == nil {
 		defer toneRows.Close()
 		for toneRows.Next() {
@@ -9196,13 +9486,13 @@ func (api *Api) StatsHandler(w http.ResponseWriter, r *http.Request) {
 	var totalCallsToday, callsLastMinute, callsLastHour int
 
 	todayQ := `SELECT COUNT(*) FROM calls c WHERE c.timestamp >= $1` + systemFilter
-	db.QueryRow(todayQ, midnightToday).Scan(&totalCallsToday)
+	db.QueryRow(todayQ, append([]interface{}{midnightToday}, systemFilterArgs...)...).Scan(&totalCallsToday)
 
 	minQ := `SELECT COUNT(*) FROM calls c WHERE c.timestamp >= $1` + systemFilter
-	db.QueryRow(minQ, now-60*1000).Scan(&callsLastMinute)
+	db.QueryRow(minQ, append([]interface{}{now - 60*1000}, systemFilterArgs...)...).Scan(&callsLastMinute)
 
 	hourQ := `SELECT COUNT(*) FROM calls c WHERE c.timestamp >= $1` + systemFilter
-	db.QueryRow(hourQ, now-60*60*1000).Scan(&callsLastHour)
+	db.QueryRow(hourQ, append([]interface{}{now - 60*60*1000}, systemFilterArgs...)...).Scan(&callsLastHour)
 
 	// ── 6. Incident summary (today, by sub-category derived from transcripts) ─
 	// Each transcript is matched to the FIRST keyword group that applies
@@ -9244,10 +9534,8 @@ func (api *Api) StatsHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	incidentSystemFilter := ""
-	if sid := r.URL.Query().Get("systemId"); sid != "" {
-		if id, err3 := strconv.Atoi(sid); err3 == nil {
-			incidentSystemFilter = fmt.Sprintf(` AND c."systemId" = %d`, id)
-		}
+	if systemFilterArgs != nil {
+		incidentSystemFilter = ` AND c."systemId" = $1`
 	}
 
 	incidentQuery := `
@@ -9318,7 +9606,7 @@ func (api *Api) StatsHandler(w http.ResponseWriter, r *http.Request) {
 		catMap[c] = &IncidentCat{Category: c, Count: 0, Subcategories: []IncidentSub{}}
 	}
 
-	incRows, err := db.Query(incidentQuery)
+	incRows, err := db.Query(incidentQuery, systemFilterArgs...)
 	if err != nil {
 		log.Printf("StatsHandler: incident query error: %v", err)
 	} else {
@@ -21,7 +21,6 @@ import (
 	"errors"
 	"fmt"
 	"sort"
-	"strings"
 	"sync"
 )
 
@@ -32,6 +31,12 @@ type Apikey struct {
 	Key      string
 	Order    uint
 	Systems  any
+	// RateLimit caps the uploads per minute, DailyQuota the uploads per
+	// day. SilentMinutes raises an alert when the key uploads nothing for
+	// that long. Zero disables each.
+	RateLimit     uint
+	DailyQuota    uint
+	SilentMinutes uint
 }
 
 func NewApikey() *Apikey {
@@ -68,6 +73,21 @@ func (apikey *Apikey) FromMap(m map[string]any) *Apikey {
 
 	apikey.Systems = m["systems"]
 
+	switch v := m["rateLimit"].(type) {
+	case float64:
+		apikey.RateLimit = uint(v)
+	}
+
+	switch v := m["dailyQuota"].(type) {
+	case float64:
+		apikey.DailyQuota = uint(v)
+	}
+
+	switch v := m["silentMinutes"].(type) {
+	case float64:
+		apikey.SilentMinutes = uint(v)
+	}
+
 	return apikey
 }
 
@@ -142,6 +162,18 @@ func (apikey *Apikey) MarshalJSON() ([]byte, error) {
 		m["order"] = apikey.Order
 	}
 
+	if apikey.RateLimit > 0 {
+		m["rateLimit"] = apikey.RateLimit
+	}
+
+	if apikey.DailyQuota > 0 {
+		m["dailyQuota"] = apikey.DailyQuota
+	}
+
+	if apikey.SilentMinutes > 0 {
+		m["silentMinutes"] = apikey.SilentMinutes
+	}
+
 	return json.Marshal(m)
 }
 
@@ -200,7 +232,7 @@ func (apikeys *Apikeys) Read(db *Database) error {
 
 	formatError := apikeys.errorFormatter("read")
 
-	query = `SELECT "apikeyId", "disabled", "ident", "key", "order", "systems" FROM "apikeys"`
+	query = `SELECT "apikeyId", "disabled", "ident", "key", "order", "systems", "rateLimit", "dailyQuota", "silentMinutes" FROM "apikeys"`
 	if rows, err = db.Sql.Query(query); err != nil {
 		return formatError(err, query)
 	}
@@ -211,7 +243,7 @@ func (apikeys *Apikeys) Read(db *Database) error {
 			systems string
 		)
 
-		if err = rows.Scan(&apikey.Id, &apikey.Disabled, &apikey.Ident, &apikey.Key, &apikey.Order, &systems); err != nil {
+		if err = rows.Scan(&apikey.Id, &apikey.Disabled, &apikey.Ident, &apikey.Key, &apikey.Order, &systems, &apikey.RateLimit, &apikey.DailyQuota, &apikey.SilentMinutes); err != nil {
 			break
 		}
 
@@ -300,18 +332,17 @@ func (apikeys *Apikeys) Write(db *Database) error {
 	}
 
 	if len(apikeyIds) > 0 {
-		if b, err := json.Marshal(apikeyIds); err == nil {
-			in := strings.ReplaceAll(strings.ReplaceAll(string(b), "[", "("), "]", ")")
-			query = fmt.Sprintf(`DELETE FROM "apikeys" WHERE "apikeyId" IN %s`, in)
-			if _, err = tx.Exec(query); err != nil {
-				tx.Rollback()
-				return formatError(err, query)
-			}
+		args := queryArgs{}
+		query = `DELETE FROM "apikeys" WHERE "apikeyId" IN ` + args.in(apikeyIds)
+		if _, err = tx.Exec(query, args...); err != nil {
+			tx.Rollback()
+			return formatError(err, query)
 		}
 	}
 
 	for _, apikey := range apikeys.List {
 		var (
+			args    []any
 			count   uint
 			systems string
 		)
@@ -323,29 +354,34 @@ func (apikeys *Apikeys) Write(db *Database) error {
 		}
 
 		if apikey.Id > 0 {
-			query = fmt.Sprintf(`SELECT COUNT(*) FROM "apikeys" WHERE "apikeyId" = %d`, apikey.Id)
-			if err = tx.QueryRow(query).Scan(&count); err != nil {
+			query = `SELECT COUNT(*) FROM "apikeys" WHERE "apikeyId" = $1`
+			if err = tx.QueryRow(query, apikey.Id).Scan(&count); err != nil {
 				break
 			}
 		}
 
+		columns := queryColumns{}
+		columns.set("disabled", apikey.Disabled)
+		columns.set("ident", apikey.Ident)
+		columns.set("key", apikey.Key)
+		columns.set("order", apikey.Order)
+		columns.set("systems", systems)
+		columns.set("rateLimit", apikey.RateLimit)
+		columns.set("dailyQuota", apikey.DailyQuota)
+		columns.set("silentMinutes", apikey.SilentMinutes)
+
 		if count == 0 {
 			if apikey.Id > 0 {
 				// Preserve the explicit ID when inserting
-				query = fmt.Sprintf(`INSERT INTO "apikeys" ("apikeyId", "disabled", "ident", "key", "order", "systems") VALUES (%d, %t, '%s', '%s', %d, '%s')`, apikey.Id, apikey.Disabled, apikey.Ident, apikey.Key, apikey.Order, systems)
-			} else {
-				// Let database assign auto-increment ID
-				query = fmt.Sprintf(`INSERT INTO "apikeys" ("disabled", "ident", "key", "order", "systems") VALUES (%t, '%s', '%s', %d, '%s')`, apikey.Disabled, apikey.Ident, apikey.Key, apikey.Order, systems)
-			}
-			if _, err = tx.Exec(query); err != nil {
-				break
+				columns.set("apikeyId", apikey.Id)
 			}
-
+			// Otherwise let database assign auto-increment ID
+			query, args = columns.insert("apikeys")
 		} else {
-			query = fmt.Sprintf(`UPDATE "apikeys" SET "disabled" = %t, "ident" = '%s', "key" = '%s', "order" = %d, "systems" = '%s' WHERE "apikeyId" = %d`, apikey.Disabled, apikey.Ident, apikey.Key, apikey.Order, systems, apikey.Id)
-			if _, err = tx.Exec(query); err != nil {
-				break
-			}
+			query, args = columns.update("apikeys", "apikeyId", apikey.Id)
+		}
+		if _, err = tx.Exec(query, args...); err != nil {
+			break
 		}
 	}
 
@@ -0,0 +1,655 @@
+// Copyright (C) 2026 Thinline Dynamic Solutions
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// apikeyUsageFlushInterval is how often counts are written to the
+	// database and the keys are checked for silence and spikes.
+	apikeyUsageFlushInterval = time.Minute
+	// apikeyUsageRetentionDays of hourly counts and source addresses are kept.
+	apikeyUsageRetentionDays = 90
+	// apikeySpikeHistoryDays of the same hour make the normal upload count.
+	apikeySpikeHistoryDays = 7
+	// A key spikes when an hour is apikeySpikeSigma deviations above normal
+	// with at least apikeySpikeMinCalls uploads.
+	apikeySpikeSigma    = 3
+	apikeySpikeMinCalls = 30
+	// apikeyStatsSources is the most source addresses the API lists per key.
+	apikeyStatsSources = 20
+)
+
+// ApikeyStats is the usage of one API key returned by the admin API.
+type ApikeyStats struct {
+	ApikeyId      uint64           `json:"apikeyId"`
+	Ident         string           `json:"ident"`
+	Disabled      bool             `json:"disabled"`
+	RateLimit     uint             `json:"rateLimit"`
+	DailyQuota    uint             `json:"dailyQuota"`
+	SilentMinutes uint             `json:"silentMinutes"`
+	CallsToday    int              `json:"callsToday"`
+	LastSeen      int64            `json:"lastSeen"` // 0 when never seen
+	Silent        bool             `json:"silent"`
+	Days          []ApikeyUsageDay `json:"days"` // oldest first
+	Sources       []ApikeySource   `json:"sources"`
+}
+
+// ApikeyUsageDay is the uploads of a key on one local day.
+type ApikeyUsageDay struct {
+	Date     string `json:"date"`
+	Calls    int    `json:"calls"`
+	Bytes    int64  `json:"bytes"`
+	Rejected int    `json:"rejected"`
+}
+
+// ApikeySource is an address a key uploaded from.
+type ApikeySource struct {
+	Ip        string `json:"ip"`
+	Calls     int64  `json:"calls"`
+	FirstSeen int64  `json:"firstSeen"`
+	LastSeen  int64  `json:"lastSeen"`
+}
+
+type apikeyUsageKey struct {
+	apikeyId uint64
+	hour     int64
+}
+
+type apikeyUsageCounts struct {
+	calls    int
+	bytes    int64
+	rejected int
+}
+
+type apikeySourceKey struct {
+	apikeyId uint64
+	ip       string
+}
+
+type apikeySourceCounts struct {
+	calls     int64
+	firstSeen int64
+	lastSeen  int64
+}
+
+// apikeyWindow counts the uploads of a key since start.
+type apikeyWindow struct {
+	start time.Time
+	count int
+}
+
+// ApikeyUsage counts the uploads of each API key, enforces the per-key rate
+// limits and daily quotas, and alerts when a key goes silent or spikes. Counts
+// are kept in memory and flushed to the hourly "apikeyUsage" table.
+type ApikeyUsage struct {
+	controller *Controller
+	mutex      sync.Mutex
+	started    time.Time
+	stopChan   chan struct{}
+
+	pending  map[apikeyUsageKey]*apikeyUsageCounts
+	sources  map[apikeySourceKey]*apikeySourceCounts
+	minutes  map[uint64]*apikeyWindow
+	days     map[uint64]*apikeyWindow
+	lastSeen map[uint64]time.Time
+
+	silent    map[uint64]bool
+	spikeHour int64 // owned by the monitor
+}
+
+func NewApikeyUsage(controller *Controller) *ApikeyUsage {
+	return &ApikeyUsage{
+		controller: controller,
+		started:    time.Now(),
+		stopChan:   make(chan struct{}),
+		pending:    map[apikeyUsageKey]*apikeyUsageCounts{},
+		sources:    map[apikeySourceKey]*apikeySourceCounts{},
+		minutes:    map[uint64]*apikeyWindow{},
+		days:       map[uint64]*apikeyWindow{},
+		lastSeen:   map[uint64]time.Time{},
+		silent:     map[uint64]bool{},
+	}
+}
+
+// apikeyDayStart is the local midnight the daily quota counts from.
+func apikeyDayStart(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+}
+
+// apikeyHour is the start of the hour of t in unix milliseconds.
+func apikeyHour(t time.Time) int64 {
+	return t.Truncate(time.Hour).UnixMilli()
+}
+
+// Admit reports whether the key may upload now. A refused upload is counted
+// as rejected and the wait before the next accepted one is returned.
+func (usage *ApikeyUsage) Admit(apikey *Apikey, now time.Time) (ok bool, retryAfter time.Duration) {
+	usage.mutex.Lock()
+	defer usage.mutex.Unlock()
+
+	minute := now.Truncate(time.Minute)
+	window := usage.minutes[apikey.Id]
+	if window == nil || !window.start.Equal(minute) {
+		window = &apikeyWindow{start: minute}
+		usage.minutes[apikey.Id] = window
+	}
+
+	today := apikeyDayStart(now)
+	day := usage.days[apikey.Id]
+	if day == nil || !day.start.Equal(today) {
+		day = &apikeyWindow{start: today}
+		usage.days[apikey.Id] = day
+	}
+
+	switch {
+	case apikey.RateLimit > 0 && window.count >= int(apikey.RateLimit):
+		retryAfter = minute.Add(time.Minute).Sub(now)
+	case apikey.DailyQuota > 0 && day.count >= int(apikey.DailyQuota):
+		retryAfter = today.AddDate(0, 0, 1).Sub(now)
+	default:
+		window.count++
+		day.count++
+		return true, 0
+	}
+
+	usage.counts(apikey.Id, now).rejected++
+	return false, retryAfter
+}
+
+// Record counts an accepted upload of the given size from the address.
+func (usage *ApikeyUsage) Record(apikeyId uint64, ip string, bytes int, now time.Time) {
+	usage.mutex.Lock()
+	defer usage.mutex.Unlock()
+
+	counts := usage.counts(apikeyId, now)
+	counts.calls++
+	counts.bytes += int64(bytes)
+
+	usage.lastSeen[apikeyId] = now
+
+	if ip == "" {
+		return
+	}
+	key := apikeySourceKey{apikeyId: apikeyId, ip: ip}
+	source := usage.sources[key]
+	if source == nil {
+		source = &apikeySourceCounts{firstSeen: now.UnixMilli()}
+		usage.sources[key] = source
+	}
+	source.calls++
+	source.lastSeen = now.UnixMilli()
+}
+
+func (usage *ApikeyUsage) counts(apikeyId uint64, now time.Time) *apikeyUsageCounts {
+	key := apikeyUsageKey{apikeyId: apikeyId, hour: apikeyHour(now)}
+	counts := usage.pending[key]
+	if counts == nil {
+		counts = &apikeyUsageCounts{}
+		usage.pending[key] = counts
+	}
+	return counts
+}
+
+// Start loads today's counts and last upload times, then flushes and checks
+// the keys every apikeyUsageFlushInterval.
+func (usage *ApikeyUsage) Start() {
+	if err := usage.load(time.Now()); err != nil {
+		usage.controller.Logs.LogEvent(LogLevelError, fmt.Sprintf("apikey usage: %v", err))
+	}
+
+	go func() {
+		ticker := time.NewTicker(apikeyUsageFlushInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := usage.Flush(); err != nil {
+					usage.controller.Logs.LogEvent(LogLevelError, fmt.Sprintf("apikey usage: %v", err))
+				}
+				usage.Monitor(time.Now())
+			case <-usage.stopChan:
+				return
+			}
+		}
+	}()
+}
+
+// Stop ends the background goroutine and flushes the pending counts.
+func (usage *ApikeyUsage) Stop() {
+	select {
+	case <-usage.stopChan:
+		return
+	default:
+		close(usage.stopChan)
+	}
+	if err := usage.Flush(); err != nil {
+		usage.controller.Logs.LogEvent(LogLevelError, fmt.Sprintf("apikey usage: %v", err))
+	}
+}
+
+// load restores the daily quota counts and last upload times a restart would
+// otherwise forget.
+func (usage *ApikeyUsage) load(now time.Time) error {
+	db := usage.controller.Database.Sql
+	today := apikeyDayStart(now)
+
+	days := map[uint64]*apikeyWindow{}
+	rows, err := db.Query(`SELECT "apikeyId", SUM("calls") FROM "apikeyUsage" WHERE "hour" >= $1 GROUP BY "apikeyId"`, today.UnixMilli())
+	if err != nil {
+		return fmt.Errorf("load daily counts: %v", err)
+	}
+	for rows.Next() {
+		var (
+			apikeyId uint64
+			calls    int
+		)
+		if err := rows.Scan(&apikeyId, &calls); err != nil {
+			rows.Close()
+			return fmt.Errorf("load daily counts: %v", err)
+		}
+		days[apikeyId] = &apikeyWindow{start: today, count: calls}
+	}
+	rows.Close()
+
+	lastSeen := map[uint64]time.Time{}
+	rows, err = db.Query(`SELECT "apikeyId", MAX("lastSeen") FROM "apikeySources" GROUP BY "apikeyId"`)
+	if err != nil {
+		return fmt.Errorf("load last seen: %v", err)
+	}
+	for rows.Next() {
+		var (
+			apikeyId uint64
+			seen     int64
+		)
+		if err := rows.Scan(&apikeyId, &seen); err != nil {
+			rows.Close()
+			return fmt.Errorf("load last seen: %v", err)
+		}
+		lastSeen[apikeyId] = time.UnixMilli(seen)
+	}
+	rows.Close()
+
+	usage.mutex.Lock()
+	defer usage.mutex.Unlock()
+	for apikeyId, day := range days {
+		if current := usage.days[apikeyId]; current != nil && current.start.Equal(today) {
+			day.count += current.count
+		}
+		usage.days[apikeyId] = day
+	}
+	for apikeyId, seen := range lastSeen {
+		if seen.After(usage.lastSeen[apikeyId]) {
+			usage.lastSeen[apikeyId] = seen
+		}
+	}
+	return nil
+}
+
+// Flush adds the pending counts to the database.
+func (usage *ApikeyUsage) Flush() error {
+	usage.mutex.Lock()
+	pending, sources := usage.pending, usage.sources
+	usage.pending = map[apikeyUsageKey]*apikeyUsageCounts{}
+	usage.sources = map[apikeySourceKey]*apikeySourceCounts{}
+	usage.mutex.Unlock()
+
+	if len(pending) == 0 && len(sources) == 0 {
+		return nil
+	}
+
+	tx, err := usage.controller.Database.Sql.Begin()
+	if err != nil {
+		return fmt.Errorf("flush: %v", err)
+	}
+	for key, counts := range pending {
+		if _, err = tx.Exec(`INSERT INTO "apikeyUsage" ("apikeyId", "hour", "calls", "bytes", "rejected") SELECT $1, $2, $3, $4, $5 WHERE EXISTS (SELECT 1 FROM "apikeys" WHERE "apikeyId" = $1) ON CONFLICT ("apikeyId", "hour") DO UPDATE SET "calls" = "apikeyUsage"."calls" + EXCLUDED."calls", "bytes" = "apikeyUsage"."bytes" + EXCLUDED."bytes", "rejected" = "apikeyUsage"."rejected" + EXCLUDED."rejected"`, key.apikeyId, key.hour, counts.calls, counts.bytes, counts.rejected); err != nil {
+			break
+		}
+	}
+	if err == nil {
+		for key, source := range sources {
+			if _, err = tx.Exec(`INSERT INTO "apikeySources" ("apikeyId", "ip", "calls", "firstSeen", "lastSeen") SELECT $1, $2, $3, $4, $5 WHERE EXISTS (SELECT 1 FROM "apikeys" WHERE "apikeyId" = $1) ON CONFLICT ("apikeyId", "ip") DO UPDATE SET "calls" = "apikeySources"."calls" + EXCLUDED."calls", "lastSeen" = GREATEST("apikeySources"."lastSeen", EXCLUDED."lastSeen")`, key.apikeyId, key.ip, source.calls, source.firstSeen, source.lastSeen); err != nil {
+				break
+			}
+		}
+	}
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("flush: %v", err)
+	}
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("flush: %v", err)
+	}
+	return nil
+}
+
+// Prune drops the counts and source addresses past apikeyUsageRetentionDays.
+func (usage *ApikeyUsage) Prune() error {
+	cutoff := time.Now().AddDate(0, 0, -apikeyUsageRetentionDays).UnixMilli()
+	db := usage.controller.Database.Sql
+	if _, err := db.Exec(`DELETE FROM "apikeyUsage" WHERE "hour" < $1`, cutoff); err != nil {
+		return err
+	}
+	if _, err := db.Exec(`DELETE FROM "apikeySources" WHERE "lastSeen" < $1`, cutoff); err != nil {
+		return err
+	}
+	return nil
+}
+
+// apikeys returns the configured keys.
+func (usage *ApikeyUsage) apikeys() []*Apikey {
+	apikeys := usage.controller.Apikeys
+	apikeys.mutex.Lock()
+	defer apikeys.mutex.Unlock()
+	return append([]*Apikey{}, apikeys.List...)
+}
+
+// Monitor raises an apikey_silent alert for keys quiet longer than their
+// SilentMinutes, resolved once they upload again, and after each hour an
+// apikey_spike alert for keys that uploaded far more than at that hour on the
+// previous days.
+func (usage *ApikeyUsage) Monitor(now time.Time) {
+	controller := usage.controller
+	if !controller.Options.SystemHealthAlertsEnabled {
+		return
+	}
+
+	apikeys := usage.apikeys()
+
+	for _, apikey := range apikeys {
+		usage.mutex.Lock()
+		seen, ok := usage.lastSeen[apikey.Id]
+		wasSilent := usage.silent[apikey.Id]
+		if !ok {
+			seen = usage.started
+		}
+		quiet := !apikey.Disabled && apikey.SilentMinutes > 0 && now.Sub(seen) >= time.Duration(apikey.SilentMinutes)*time.Minute
+		if quiet {
+			usage.silent[apikey.Id] = true
+		} else {
+			delete(usage.silent, apikey.Id)
+		}
+		usage.mutex.Unlock()
+
+		switch {
+		case quiet && !wasSilent:
+			message := fmt.Sprintf("API key %s has not uploaded a call for %d minutes.", apikey.Ident, int(now.Sub(seen).Minutes()))
+			if !ok {
+				message = fmt.Sprintf("API key %s has not uploaded a call since the server started %d minutes ago.", apikey.Ident, int(now.Sub(seen).Minutes()))
+			}
+			data := &SystemAlertData{ApikeyId: apikey.Id, Threshold: int(apikey.SilentMinutes), MinutesSinceLast: int(now.Sub(seen).Minutes())}
+			if ok {
+				data.LastCallTime = seen.UnixMilli()
+			}
+			controller.CreateSystemAlert("apikey_silent", "warning", fmt.Sprintf("Upload Source Silent: %s", apikey.Ident), message, data, 0)
+		case !quiet && wasSilent:
+			controller.IncidentRouting.Resolve("apikey_silent", apikey.Id)
+		}
+	}
+
+	// Spikes are judged on complete hours
+	hour := apikeyHour(now) - time.Hour.Milliseconds()
+	if hour <= usage.spikeHour {
+		return
+	}
+	usage.spikeHour = hour
+	if usage.started.UnixMilli() > hour {
+		return
+	}
+
+	history, err := usage.hourHistory(time.UnixMilli(hour))
+	if err != nil {
+		controller.Logs.LogEvent(LogLevelError, fmt.Sprintf("apikey usage: %v", err))
+		return
+	}
+
+	for _, apikey := range apikeys {
+		counts, ok := history[apikey.Id]
+		if apikey.Disabled || !ok {
+			continue
+		}
+		baseline, spiked := apikeySpike(counts)
+		if !spiked {
+			continue
+		}
+		controller.CreateSystemAlert(
+			"apikey_spike",
+			"warning",
+			fmt.Sprintf("Upload Spike: %s", apikey.Ident),
+			fmt.Sprintf("API key %s uploaded %d calls in the hour from %s, normally %.1f at this time of day (alert above %.1f).", apikey.Ident, counts[0], time.UnixMilli(hour).Format("15:04"), baseline.Mean, baseline.threshold(apikeySpikeSigma)),
+			&SystemAlertData{ApikeyId: apikey.Id, Count: counts[0], Baseline: baseline.Mean},
+			0, // System-generated
+		)
+	}
+}
+
+// hourHistory returns, per key, the uploads in the hour (index 0) and in the
+// same hour on each previous day since the key was first used, up to
+// apikeySpikeHistoryDays.
+func (usage *ApikeyUsage) hourHistory(hour time.Time) (map[uint64][]int, error) {
+	args := queryArgs{}
+	hours := make([]string, apikeySpikeHistoryDays+1)
+	for i := range hours {
+		hours[i] = args.add(hour.AddDate(0, 0, -i).UnixMilli())
+	}
+
+	query := fmt.Sprintf(`SELECT u."apikeyId", u."hour", u."calls", f."first" FROM "apikeyUsage" AS u JOIN (SELECT "apikeyId", MIN("hour") AS "first" FROM "apikeyUsage" GROUP BY "apikeyId") AS f ON f."apikeyId" = u."apikeyId" WHERE u."hour" IN (%s)`, strings.Join(hours, ", "))
+	rows, err := usage.controller.Database.Sql.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	history := map[uint64][]int{}
+	for rows.Next() {
+		var (
+			apikeyId  uint64
+			at, first int64
+			calls     int
+		)
+		if err := rows.Scan(&apikeyId, &at, &calls, &first); err != nil {
+			return nil, err
+		}
+		counts, ok := history[apikeyId]
+		if !ok {
+			// Days before the key was first used don't count as quiet ones
+			days := int(hour.Sub(time.UnixMilli(first)).Hours() / 24)
+			if days > apikeySpikeHistoryDays {
+				days = apikeySpikeHistoryDays
+			}
+			counts = make([]int, days+1)
+			history[apikeyId] = counts
+		}
+		for i := range counts {
+			if hour.AddDate(0, 0, -i).UnixMilli() == at {
+				counts[i] = calls
+			}
+		}
+	}
+	return history, rows.Err()
+}
+
+// apikeySpike reports whether the first count is a spike against the
+// others. A few days of history are needed before "normal" means anything,
+// and steady sources must at least double, so a busy hour doesn't alert.
+func apikeySpike(counts []int) (activityBaseline, bool) {
+	if len(counts) < 4 {
+		return activityBaseline{}, false
+	}
+	baseline := newActivityBaseline(counts[1:])
+	spiked := baseline.isAnomalous(counts[0], apikeySpikeSigma, apikeySpikeMinCalls) && float64(counts[0]) >= 2*baseline.Mean
+	return baseline, spiked
+}
+
+// Stats returns the usage of every key over the last days.
+func (usage *ApikeyUsage) Stats(days int, now time.Time) ([]*ApikeyStats, error) {
+	if err := usage.Flush(); err != nil {
+		return nil, err
+	}
+
+	db := usage.controller.Database.Sql
+	today := apikeyDayStart(now)
+	since := today.AddDate(0, 0, -(days - 1))
+
+	apikeys := usage.apikeys()
+	stats := []*ApikeyStats{}
+	byId := map[uint64]*ApikeyStats{}
+
+	usage.mutex.Lock()
+	for _, apikey := range apikeys {
+		stat := &ApikeyStats{
+			ApikeyId:      apikey.Id,
+			Ident:         apikey.Ident,
+			Disabled:      apikey.Disabled,
+			RateLimit:     apikey.RateLimit,
+			DailyQuota:    apikey.DailyQuota,
+			SilentMinutes: apikey.SilentMinutes,
+			Silent:        usage.silent[apikey.Id],
+			Days:          make([]ApikeyUsageDay, days),
+			Sources:       []ApikeySource{},
+		}
+		for i := range stat.Days {
+			stat.Days[i].Date = since.AddDate(0, 0, i).Format("2006-01-02")
+		}
+		if day := usage.days[apikey.Id]; day != nil && day.start.Equal(today) {
+			stat.CallsToday = day.count
+		}
+		if seen, ok := usage.lastSeen[apikey.Id]; ok {
+			stat.LastSeen = seen.UnixMilli()
+		}
+		stats = append(stats, stat)
+		byId[apikey.Id] = stat
+	}
+	usage.mutex.Unlock()
+
+	rows, err := db.Query(`SELECT "apikeyId", "hour", "calls", "bytes", "rejected" FROM "apikeyUsage" WHERE "hour" >= $1`, since.UnixMilli())
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var (
+			apikeyId uint64
+			hour     int64
+			counts   apikeyUsageCounts
+		)
+		if err := rows.Scan(&apikeyId, &hour, &counts.calls, &counts.bytes, &counts.rejected); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		stat, ok := byId[apikeyId]
+		if !ok {
+			continue
+		}
+		date := time.UnixMilli(hour).In(now.Location()).Format("2006-01-02")
+		for i := range stat.Days {
+			if stat.Days[i].Date == date {
+				stat.Days[i].Calls += counts.calls
+				stat.Days[i].Bytes += counts.bytes
+				stat.Days[i].Rejected += counts.rejected
+			}
+		}
+	}
+	rows.Close()
+
+	rows, err = db.Query(`SELECT "apikeyId", "ip", "calls", "firstSeen", "lastSeen" FROM "apikeySources" ORDER BY "lastSeen" DESC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			apikeyId uint64
+			source   ApikeySource
+		)
+		if err := rows.Scan(&apikeyId, &source.Ip, &source.Calls, &source.FirstSeen, &source.LastSeen); err != nil {
+			return nil, err
+		}
+		if stat, ok := byId[apikeyId]; ok && len(stat.Sources) < apikeyStatsSources {
+			stat.Sources = append(stat.Sources, source)
+		}
+	}
+
+	sort.SliceStable(stats, func(i, j int) bool { return stats[i].Ident < stats[j].Ident })
+
+	return stats, rows.Err()
+}
+
+// ApikeyStatsHandler returns the uploads per day, source addresses, last
+// upload and limits of every API key. ?days= (up to
+// apikeyUsageRetentionDays, default 7) sets the history length.
+func (admin *Admin) ApikeyStatsHandler(w http.ResponseWriter, r *http.Request) {
+	t := admin.GetAuthorization(r)
+	if !admin.ValidateToken(t) {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	writeError := func(status int, message string) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(map[string]string{"error": message})
+	}
+
+	days := 7
+	if s := r.URL.Query().Get("days"); s != "" {
+		v, err := strconv.Atoi(s)
+		if err != nil || v < 1 {
+			writeError(http.StatusBadRequest, "days must be a positive number")
+			return
+		}
+		if v > apikeyUsageRetentionDays {
+			v = apikeyUsageRetentionDays
+		}
+		days = v
+	}
+
+	stats, err := admin.Controller.ApikeyUsage.Stats(days, time.Now())
+	if err != nil {
+		writeError(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{"apikeys": stats})
+}
+
+// writeApikeyLimited answers an upload refused by the key's rate limit or
+// daily quota.
+func writeApikeyLimited(w http.ResponseWriter, retryAfter time.Duration) {
+	seconds := int(retryAfter.Seconds() + 0.999)
+	if seconds < 1 {
+		seconds = 1
+	}
+	w.Header().Set("Retry-After", strconv.Itoa(seconds))
+	w.WriteHeader(http.StatusTooManyRequests)
+	w.Write([]byte("Upload limit reached for this API key, please slow down\n"))
+}
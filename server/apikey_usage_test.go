@@ -0,0 +1,88 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestApikeyUsageRateLimit(t *testing.T) {
+	usage := NewApikeyUsage(&Controller{})
+	apikey := &Apikey{Id: 1, RateLimit: 2}
+	now := time.Date(2026, 10, 18, 12, 0, 10, 0, time.Local)
+
+	for i := 0; i < 2; i++ {
+		if ok, _ := usage.Admit(apikey, now); !ok {
+			t.Fatalf("upload %d should be admitted", i+1)
+		}
+	}
+	ok, retryAfter := usage.Admit(apikey, now)
+	if ok {
+		t.Fatal("third upload in the minute should be refused")
+	}
+	if retryAfter != 50*time.Second {
+		t.Fatalf("expected retry after 50s, got %v", retryAfter)
+	}
+	if counts := usage.pending[apikeyUsageKey{apikeyId: 1, hour: apikeyHour(now)}]; counts == nil || counts.rejected != 1 {
+		t.Fatalf("expected one rejected upload, got %+v", counts)
+	}
+
+	if ok, _ := usage.Admit(apikey, now.Add(time.Minute)); !ok {
+		t.Fatal("upload in the next minute should be admitted")
+	}
+}
+
+func TestApikeyUsageDailyQuota(t *testing.T) {
+	usage := NewApikeyUsage(&Controller{})
+	apikey := &Apikey{Id: 1, DailyQuota: 1}
+	now := time.Date(2026, 10, 18, 23, 0, 0, 0, time.Local)
+
+	if ok, _ := usage.Admit(apikey, now); !ok {
+		t.Fatal("first upload should be admitted")
+	}
+	ok, retryAfter := usage.Admit(apikey, now.Add(time.Minute))
+	if ok {
+		t.Fatal("upload over the daily quota should be refused")
+	}
+	if retryAfter != 59*time.Minute {
+		t.Fatalf("expected retry after midnight, got %v", retryAfter)
+	}
+	if ok, _ := usage.Admit(apikey, now.Add(time.Hour)); !ok {
+		t.Fatal("upload on the next day should be admitted")
+	}
+}
+
+func TestApikeyUsageRecord(t *testing.T) {
+	usage := NewApikeyUsage(&Controller{})
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.Local)
+
+	usage.Record(1, "10.0.0.1", 1000, now)
+	usage.Record(1, "10.0.0.1", 500, now.Add(time.Minute))
+	usage.Record(1, "10.0.0.2", 200, now.Add(2*time.Minute))
+
+	counts := usage.pending[apikeyUsageKey{apikeyId: 1, hour: apikeyHour(now)}]
+	if counts == nil || counts.calls != 3 || counts.bytes != 1700 {
+		t.Fatalf("unexpected counts %+v", counts)
+	}
+	source := usage.sources[apikeySourceKey{apikeyId: 1, ip: "10.0.0.1"}]
+	if source == nil || source.calls != 2 || source.firstSeen != now.UnixMilli() || source.lastSeen != now.Add(time.Minute).UnixMilli() {
+		t.Fatalf("unexpected source %+v", source)
+	}
+	if !usage.lastSeen[1].Equal(now.Add(2 * time.Minute)) {
+		t.Fatalf("unexpected last seen %v", usage.lastSeen[1])
+	}
+}
+
+func TestApikeySpike(t *testing.T) {
+	if _, spiked := apikeySpike([]int{500, 40, 42}); spiked {
+		t.Fatal("two days of history should not be enough")
+	}
+	if _, spiked := apikeySpike([]int{60, 40, 42, 38, 41, 39}); spiked {
+		t.Fatal("a modest rise should not spike")
+	}
+	if _, spiked := apikeySpike([]int{500, 40, 42, 38, 41, 39}); !spiked {
+		t.Fatal("a tenfold rise should spike")
+	}
+	if _, spiked := apikeySpike([]int{20, 0, 0, 0, 0}); spiked {
+		t.Fatal("counts below the minimum should never spike")
+	}
+}
@@ -0,0 +1,397 @@
+// Copyright (C) 2026 Thinline Dynamic Solutions
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>
+
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"time"
+)
+
+const (
+	// callAudioLocationBlob prefixes "audioLocation" values of the form
+	// blob://<checksum>, shared audio kept in the audioBlobs table.
+	callAudioLocationBlob = "blob://"
+
+	// audioBlobReleaseGrace keeps a blob referenced shortly before a release,
+	// since the call inserts taking that reference may not be committed yet.
+	audioBlobReleaseGrace = 10 * time.Minute
+)
+
+// Dedup reports whether new calls share identical audio.
+func (store *AudioStore) Dedup() bool {
+	return store.controller.Options.AudioStorageConfig.Dedup
+}
+
+// PutShared stores audio once per checksum and returns its location, taking
+// a reference on it. With the database backend the audio goes to the
+// audioBlobs table rather than the calls row.
+func (store *AudioStore) PutShared(backend string, timestamp time.Time, filename string, mime string, audio []byte) (string, string, error) {
+	sum := sha256.Sum256(audio)
+	checksum := hex.EncodeToString(sum[:])
+	now := time.Now().UnixMilli()
+
+	if location, ok := store.reference(checksum, now); ok {
+		return location, checksum, nil
+	}
+
+	var (
+		location string
+		blob     = []byte{}
+	)
+	if backend == AudioStorageDatabase {
+		location = callAudioLocationBlob + checksum
+		blob = audio
+	} else {
+		var err error
+		// A unique name so a concurrent upload of the same audio never writes the same file
+		name := fmt.Sprintf("%d-%s", time.Now().UnixNano(), checksum[:12])
+		if location, _, err = store.Put(backend, name, timestamp, filename, mime, audio); err != nil {
+			return "", "", err
+		}
+	}
+
+	query := `INSERT INTO "audioBlobs" ("checksum", "location", "audio", "mime", "size", "refs", "createdAt", "referencedAt") VALUES ($1, $2, $3, $4, $5, 1, $6, $6) ON CONFLICT ("checksum") DO NOTHING`
+	res, err := store.controller.Database.Sql.Exec(query, checksum, location, blob, mime, len(audio), now)
+	if err != nil {
+		store.Remove(location)
+		return "", "", err
+	}
+
+	if n, _ := res.RowsAffected(); n == 0 {
+		// Another upload stored the same audio in the meantime
+		store.Remove(location)
+		if location, ok := store.reference(checksum, now); ok {
+			return location, checksum, nil
+		}
+		return "", "", fmt.Errorf("audio blob %s was released while being shared", checksum[:12])
+	}
+
+	return location, checksum, nil
+}
+
+// reference takes a reference on the blob with checksum, if there is one.
+func (store *AudioStore) reference(checksum string, now int64) (string, bool) {
+	var location string
+	query := `UPDATE "audioBlobs" SET "refs" = "refs" + 1, "referencedAt" = $2 WHERE "checksum" = $1 RETURNING "location"`
+	if err := store.controller.Database.Sql.QueryRow(query, checksum, now).Scan(&location); err != nil {
+		return "", false
+	}
+	return location, true
+}
+
+// Release recounts the references of locations whose calls were deleted and
+// removes the audio no call points at anymore. Locations not shared through
+// audioBlobs belonged to a single call and are removed right away. It returns
+// the bytes freed.
+func (store *AudioStore) Release(locations []string) int64 {
+	db := store.controller.Database
+	cutoff := time.Now().Add(-audioBlobReleaseGrace).UnixMilli()
+
+	var freed int64
+	seen := map[string]bool{}
+	for _, location := range locations {
+		if location == "" || seen[location] {
+			continue
+		}
+		seen[location] = true
+
+		var refs int64
+		if err := db.Sql.QueryRow(`SELECT COUNT(*) FROM "calls" WHERE "audioLocation" = $1`, location).Scan(&refs); err != nil {
+			continue
+		}
+
+		res, err := db.Sql.Exec(`UPDATE "audioBlobs" SET "refs" = $2 WHERE "location" = $1`, location, refs)
+		if err != nil {
+			continue
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			if refs == 0 {
+				freed += store.locationSize(location)
+				store.Remove(location)
+			}
+			continue
+		}
+
+		if refs == 0 {
+			if size, ok := store.dropBlob(location, cutoff); ok {
+				freed += size
+			}
+		}
+	}
+
+	return freed
+}
+
+// dropBlob deletes an unreferenced blob not referenced since cutoff, and its
+// file or object, returning its size.
+func (store *AudioStore) dropBlob(location string, cutoff int64) (int64, bool) {
+	var size int64
+	query := `DELETE FROM "audioBlobs" WHERE "location" = $1 AND "referencedAt" < $2 AND NOT EXISTS (SELECT 1 FROM "calls" WHERE "audioLocation" = $1) RETURNING "size"`
+	if err := store.controller.Database.Sql.QueryRow(query, location, cutoff).Scan(&size); err != nil {
+		return 0, false
+	}
+	store.Remove(location)
+	return size, true
+}
+
+// openBlob reads shared audio kept in the audioBlobs table.
+func (store *AudioStore) openBlob(location string) ([]byte, error) {
+	var audio []byte
+	query := `SELECT "audio" FROM "audioBlobs" WHERE "location" = $1`
+	if err := store.controller.Database.Sql.QueryRow(query, location).Scan(&audio); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("audio blob %q not found", location)
+		}
+		return nil, err
+	}
+	return audio, nil
+}
+
+// PruneAudioBlobs drops the blobs left without calls by deletions that do not
+// release audio themselves, such as purges and duplicate cleanup.
+func (controller *Controller) PruneAudioBlobs() error {
+	store := controller.AudioStore
+	cutoff := time.Now().Add(-audioBlobReleaseGrace).UnixMilli()
+
+	query := `SELECT b."location" FROM "audioBlobs" AS b WHERE b."referencedAt" < $1 AND NOT EXISTS (SELECT 1 FROM "calls" AS c WHERE c."audioLocation" = b."location")`
+	rows, err := controller.Database.Sql.Query(query, cutoff)
+	if err != nil {
+		return fmt.Errorf("%v in %s", err, query)
+	}
+
+	locations := []string{}
+	for rows.Next() {
+		var location string
+		if rows.Scan(&location) == nil {
+			locations = append(locations, location)
+		}
+	}
+	rows.Close()
+
+	dropped := 0
+	for _, location := range locations {
+		if _, ok := store.dropBlob(location, cutoff); ok {
+			dropped++
+		}
+	}
+
+	if dropped > 0 {
+		controller.Logs.LogEvent(LogLevelInfo, fmt.Sprintf("audio storage: released %d unreferenced shared audio file(s)", dropped))
+	}
+
+	return nil
+}
+
+// DedupExisting shares the audio of calls stored before dedup was enabled,
+// so identical recordings already on disk or in the calls table are kept
+// once. It returns the calls processed and how many now reference audio
+// another call already had.
+func (store *AudioStore) DedupExisting(progress func(calls int64, shared int64)) (int64, int64, error) {
+	if !store.Dedup() {
+		return 0, 0, fmt.Errorf("enable dedup in audioStorageConfig first")
+	}
+
+	var (
+		afterId uint64
+		calls   int64
+		shared  int64
+	)
+
+	for {
+		n, lastId, m, err := store.dedupBatch(afterId, audioStorageMoveBatchSize)
+		calls += int64(n)
+		shared += int64(m)
+		if progress != nil {
+			progress(calls, shared)
+		}
+		if err != nil || n < audioStorageMoveBatchSize {
+			return calls, shared, err
+		}
+		afterId = lastId
+	}
+}
+
+// dedupBatch shares the audio of up to limit calls whose audio is not in
+// audioBlobs yet, after afterId.
+func (store *AudioStore) dedupBatch(afterId uint64, limit int) (int, uint64, int, error) {
+	db := store.controller.Database
+
+	// The checksum of audio still in the calls table is computed by PostgreSQL
+	// so only the audio to be moved is read
+	query := `SELECT c."callId", c."audioLocation", c."audioChecksum", c."audioFilename", c."audioMime", c."timestamp", CASE WHEN c."audioLocation" = '' THEN encode(sha256(c."audio"), 'hex') ELSE '' END FROM "calls" AS c WHERE c."callId" > $1 AND (c."audioLocation" <> '' OR octet_length(c."audio") > 0) AND NOT EXISTS (SELECT 1 FROM "audioBlobs" AS b WHERE b."location" = c."audioLocation") ORDER BY c."callId" LIMIT $2`
+	rows, err := db.Sql.Query(query, afterId, limit)
+	if err != nil {
+		return 0, afterId, 0, err
+	}
+
+	type pending struct {
+		id        uint64
+		location  string
+		checksum  string
+		filename  string
+		mime      string
+		timestamp int64
+		tableSum  string
+	}
+
+	batch := []pending{}
+	for rows.Next() {
+		var p pending
+		if err := rows.Scan(&p.id, &p.location, &p.checksum, &p.filename, &p.mime, &p.timestamp, &p.tableSum); err != nil {
+			rows.Close()
+			return 0, afterId, 0, err
+		}
+		batch = append(batch, p)
+	}
+	rows.Close()
+
+	shared := 0
+	for _, p := range batch {
+		afterId = p.id
+
+		ok, err := store.dedupCall(p.id, p.location, p.checksum, p.filename, p.mime, p.timestamp, p.tableSum)
+		if err != nil {
+			return len(batch), afterId, shared, fmt.Errorf("call %d: %v", p.id, err)
+		}
+		if ok {
+			shared++
+		}
+	}
+
+	return len(batch), afterId, shared, nil
+}
+
+// dedupCall points one call at shared audio. Audio a blob already holds is
+// referenced and the call's own copy released; otherwise the call's audio
+// becomes the blob, adopting its file or object in place.
+func (store *AudioStore) dedupCall(callId uint64, location string, checksum string, filename string, mime string, timestamp int64, tableSum string) (bool, error) {
+	db := store.controller.Database
+	now := time.Now().UnixMilli()
+
+	if location == "" {
+		checksum = tableSum
+	} else if checksum == "" {
+		audio, err := store.Fetch(location, "")
+		if err != nil {
+			return false, err
+		}
+		sum := sha256.Sum256(audio)
+		checksum = hex.EncodeToString(sum[:])
+	}
+
+	if blob, ok := store.reference(checksum, now); ok {
+		res, err := db.Sql.Exec(`UPDATE "calls" SET "audio" = $1, "audioLocation" = $2, "audioChecksum" = $3 WHERE "callId" = $4 AND "audioLocation" = $5`, []byte{}, blob, checksum, callId, location)
+		if err != nil {
+			store.Release([]string{blob})
+			return false, err
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			// The call changed or was deleted meanwhile
+			store.Release([]string{blob})
+			return false, nil
+		}
+		if location != "" {
+			store.Release([]string{location})
+		}
+		return true, nil
+	}
+
+	if location == "" {
+		var audio []byte
+		if err := db.Sql.QueryRow(`SELECT "audio" FROM "calls" WHERE "callId" = $1`, callId).Scan(&audio); err != nil {
+			return false, err
+		}
+		blob, _, err := store.PutShared(store.Backend(), time.UnixMilli(timestamp), filename, mime, audio)
+		if err != nil {
+			return false, err
+		}
+		res, err := db.Sql.Exec(`UPDATE "calls" SET "audio" = $1, "audioLocation" = $2, "audioChecksum" = $3 WHERE "callId" = $4 AND "audioLocation" = ''`, []byte{}, blob, checksum, callId)
+		if err != nil {
+			store.Release([]string{blob})
+			return false, err
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			store.Release([]string{blob})
+		}
+		return false, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), callArchiveTimeout)
+	defer cancel()
+	body, size, err := store.Open(ctx, location)
+	if err != nil {
+		return false, err
+	}
+	body.Close()
+
+	query := `INSERT INTO "audioBlobs" ("checksum", "location", "audio", "mime", "size", "refs", "createdAt", "referencedAt") VALUES ($1, $2, $3, $4, $5, 1, $6, $6) ON CONFLICT ("checksum") DO NOTHING`
+	if _, err := db.Sql.Exec(query, checksum, location, []byte{}, mime, size, now); err != nil {
+		return false, err
+	}
+	if _, err := db.Sql.Exec(`UPDATE "calls" SET "audioChecksum" = $1 WHERE "callId" = $2 AND "audioChecksum" = ''`, checksum, callId); err != nil {
+		return false, err
+	}
+
+	return false, nil
+}
+
+// runAudioDedupCommand is the -dedup_audio command line tool.
+func runAudioDedupCommand(controller *Controller) error {
+	if err := controller.Options.Read(controller.Database); err != nil {
+		return err
+	}
+
+	before, err := controller.AudioStore.BlobStats()
+	if err != nil {
+		return err
+	}
+
+	last := time.Now()
+	calls, shared, err := controller.AudioStore.DedupExisting(func(calls int64, shared int64) {
+		if time.Since(last) > 5*time.Second {
+			fmt.Printf("checked %d calls, %d share audio\n", calls, shared)
+			last = time.Now()
+		}
+	})
+
+	fmt.Printf("checked %d calls, %d share audio\n", calls, shared)
+	if after, statsErr := controller.AudioStore.BlobStats(); statsErr == nil {
+		fmt.Printf("sharing now saves %s (%s more)\n", formatBytes(int(after.SavedBytes)), formatBytes(int(after.SavedBytes-before.SavedBytes)))
+	}
+
+	return err
+}
+
+// AudioBlobStats reports how much storage sharing identical audio saves.
+type AudioBlobStats struct {
+	Blobs      int64 `json:"blobs"`
+	References int64 `json:"references"`
+	Bytes      int64 `json:"bytes"`
+	SavedBytes int64 `json:"savedBytes"`
+}
+
+func (store *AudioStore) BlobStats() (*AudioBlobStats, error) {
+	stats := &AudioBlobStats{}
+	query := `SELECT COUNT(*), COALESCE(SUM("refs"), 0), COALESCE(SUM("size"), 0), COALESCE(SUM(GREATEST("refs" - 1, 0) * "size"), 0) FROM "audioBlobs"`
+	if err := store.controller.Database.Sql.QueryRow(query).Scan(&stats.Blobs, &stats.References, &stats.Bytes, &stats.SavedBytes); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
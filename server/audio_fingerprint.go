@@ -42,42 +42,37 @@ const (
 // a normalised RMS energy profile (one value per 50ms frame). Works on any
 // clip length ≥ ~200ms. Only requires ffmpeg, which TLR already depends on.
 func ComputeEnergyFingerprint(audio []byte, mime string) ([]float64, error) {
-	ext := audioExtFromMime(mime)
-	tmp, err := os.CreateTemp("", "tlr-efp-*"+ext)
+	pcm, err := decodeMonoPCM(audio, mime)
 	if err != nil {
-		return nil, fmt.Errorf("energy fingerprint: create temp: %w", err)
+		return nil, fmt.Errorf("energy fingerprint: %w", err)
 	}
-	defer os.Remove(tmp.Name())
 
-	if _, err := tmp.Write(audio); err != nil {
-		tmp.Close()
-		return nil, fmt.Errorf("energy fingerprint: write temp: %w", err)
+	profile := frameRMS(pcm)
+	if len(profile) < energyMinFrames {
+		return nil, fmt.Errorf("energy fingerprint: audio too short (%d frames)", len(profile))
 	}
-	tmp.Close()
 
-	cmd := exec.Command("ffmpeg",
-		"-i", tmp.Name(),
-		"-f", "s16le",
-		"-ar", fmt.Sprintf("%d", energySampleHz),
-		"-ac", "1",
-		"-loglevel", "quiet",
-		"pipe:1",
-	)
-	pcm, err := cmd.Output()
+	return normalizeEnergyProfile(profile), nil
+}
+
+// decodeMonoPCM decodes audio to 8 kHz mono s16le samples with ffmpeg. The
+// decode is shared with the other ingest stages, see decodeAudioShared.
+func decodeMonoPCM(audio []byte, mime string) ([]byte, error) {
+	decoded, err := decodeAudioShared(audio, mime)
 	if err != nil {
-		return nil, fmt.Errorf("energy fingerprint: ffmpeg decode: %w", err)
+		return nil, err
 	}
+	return decoded.Analysis, nil
+}
 
+// frameRMS returns the RMS level of each 50ms frame of 8 kHz s16le samples.
+func frameRMS(pcm []byte) []float64 {
 	const (
 		samplesPerFrame = energySampleHz * energyFrameMs / 1000 // 400
 		bytesPerFrame   = samplesPerFrame * 2                   // s16le
 	)
 
 	numFrames := len(pcm) / bytesPerFrame
-	if numFrames < energyMinFrames {
-		return nil, fmt.Errorf("energy fingerprint: audio too short (%d frames)", numFrames)
-	}
-
 	profile := make([]float64, numFrames)
 	for i := 0; i < numFrames; i++ {
 		offset := i * bytesPerFrame
@@ -89,19 +84,24 @@ func ComputeEnergyFingerprint(audio []byte, mime string) ([]float64, error) {
 		profile[i] = math.Sqrt(sumSq / float64(samplesPerFrame))
 	}
 
+	return profile
+}
+
+// normalizeEnergyProfile scales a profile so its loudest frame is 1.
+func normalizeEnergyProfile(profile []float64) []float64 {
 	maxVal := 0.0
 	for _, v := range profile {
 		if v > maxVal {
 			maxVal = v
 		}
 	}
-	if maxVal > 0 {
-		for i := range profile {
-			profile[i] /= maxVal
+	normalized := make([]float64, len(profile))
+	for i, v := range profile {
+		if maxVal > 0 {
+			normalized[i] = v / maxVal
 		}
 	}
-
-	return profile, nil
+	return normalized
 }
 
 // energyAlignShift is the maximum number of 50ms frames to slide when looking
@@ -0,0 +1,252 @@
+// Copyright (C) 2026 Thinline Dynamic Solutions
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>
+
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+)
+
+const (
+	// toneDecodeHz is the rate tone detection analyzes.
+	toneDecodeHz = 16000
+
+	// pcmCacheMaxBytes bounds the decoded audio kept between ingest stages.
+	pcmCacheMaxBytes = 64 << 20
+
+	// pcmDecodeFilter decodes once to 16 kHz mono and outputs two channels:
+	// the signal filtered for tone detection, then the unfiltered signal.
+	pcmDecodeFilter = "[0:a]aresample=16000,aformat=sample_fmts=flt:channel_layouts=mono,asplit=2[raw][t];" +
+		"[t]highpass=f=200,lowpass=f=3000,dynaudnorm,aformat=sample_fmts=flt:channel_layouts=mono[tone];" +
+		"[tone][raw]amerge=inputs=2[out]"
+)
+
+// decodedAudio is call audio decoded for every stage that analyzes it at
+// ingest: tone detection, the speech gate, silence trimming, encrypted call
+// detection, the duplicate fingerprints and the transcription enhancement.
+type decodedAudio struct {
+	Analysis []byte    // 8 kHz mono s16le, see decodeMonoPCM
+	Tones    []float64 // toneDecodeHz mono, filtered for tone detection
+	Speech   []float32 // toneDecodeHz mono, unfiltered
+}
+
+func (decoded *decodedAudio) size() int {
+	return len(decoded.Analysis) + len(decoded.Tones)*8 + len(decoded.Speech)*4
+}
+
+type pcmCacheEntry struct {
+	key     [32]byte
+	ready   chan struct{}
+	decoded *decodedAudio
+	err     error
+}
+
+// pcmCache keeps the latest decodes by audio content, so the ingest stages
+// share one ffmpeg run per call. Concurrent requests for the same audio wait
+// for a single decode.
+type pcmCache struct {
+	mutex    sync.Mutex
+	entries  map[[32]byte]*pcmCacheEntry
+	order    []*pcmCacheEntry
+	size     int
+	maxBytes int
+	decode   func(audio []byte, mime string) (*decodedAudio, error)
+}
+
+func newPCMCache(maxBytes int, decode func(audio []byte, mime string) (*decodedAudio, error)) *pcmCache {
+	return &pcmCache{
+		entries:  map[[32]byte]*pcmCacheEntry{},
+		maxBytes: maxBytes,
+		decode:   decode,
+	}
+}
+
+var sharedPCMCache = newPCMCache(pcmCacheMaxBytes, decodeAudioPCM)
+
+// decodeAudioShared returns the decoded audio, decoding it only when no
+// recent stage already did.
+func decodeAudioShared(audio []byte, mime string) (*decodedAudio, error) {
+	return sharedPCMCache.get(audio, mime)
+}
+
+func (cache *pcmCache) get(audio []byte, mime string) (*decodedAudio, error) {
+	key := sha256.Sum256(audio)
+
+	cache.mutex.Lock()
+	if entry, ok := cache.entries[key]; ok {
+		cache.mutex.Unlock()
+		<-entry.ready
+		return entry.decoded, entry.err
+	}
+	entry := &pcmCacheEntry{key: key, ready: make(chan struct{})}
+	cache.entries[key] = entry
+	cache.mutex.Unlock()
+
+	entry.decoded, entry.err = cache.decode(audio, mime)
+	close(entry.ready)
+
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	if entry.err != nil {
+		if cache.entries[key] == entry {
+			delete(cache.entries, key)
+		}
+		return nil, entry.err
+	}
+	cache.order = append(cache.order, entry)
+	cache.size += entry.decoded.size()
+	for cache.size > cache.maxBytes && len(cache.order) > 0 {
+		oldest := cache.order[0]
+		cache.order = cache.order[1:]
+		cache.size -= oldest.decoded.size()
+		if cache.entries[oldest.key] == oldest {
+			delete(cache.entries, oldest.key)
+		}
+	}
+	return entry.decoded, nil
+}
+
+// decodeAudioPCM decodes audio with a single ffmpeg run, reading it from
+// stdin. Containers that need seeking, like m4a with the index at the end,
+// go through a temporary file instead.
+func decodeAudioPCM(audio []byte, mime string) (*decodedAudio, error) {
+	if len(audio) == 0 {
+		return nil, errors.New("no audio")
+	}
+
+	out, err := runPCMDecode("pipe:0", audio)
+	if err != nil {
+		tmp, tmpErr := os.CreateTemp("", "tlr-pcm-*"+audioExtFromMime(mime))
+		if tmpErr != nil {
+			return nil, err
+		}
+		defer os.Remove(tmp.Name())
+		if _, tmpErr := tmp.Write(audio); tmpErr != nil {
+			tmp.Close()
+			return nil, err
+		}
+		tmp.Close()
+		if out, err = runPCMDecode(tmp.Name(), nil); err != nil {
+			return nil, err
+		}
+	}
+
+	tones, raw := splitDecodedChannels(out)
+	if len(raw) == 0 {
+		return nil, errors.New("ffmpeg decode: no samples")
+	}
+	return &decodedAudio{
+		Analysis: decimateToAnalysisPCM(raw),
+		Tones:    tones,
+		Speech:   raw,
+	}, nil
+}
+
+func runPCMDecode(input string, stdin []byte) ([]byte, error) {
+	cmd := exec.Command("ffmpeg",
+		"-hide_banner", "-loglevel", "error",
+		"-i", input,
+		"-filter_complex", pcmDecodeFilter,
+		"-map", "[out]",
+		"-c:a", "pcm_f32le",
+		"-f", "f32le",
+		"pipe:1",
+	)
+	if stdin != nil {
+		cmd.Stdin = bytes.NewReader(stdin)
+	}
+	stdout := bytes.NewBuffer([]byte(nil))
+	cmd.Stdout = stdout
+	stderr := bytes.NewBuffer([]byte(nil))
+	cmd.Stderr = stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("ffmpeg decode: %v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	if stdout.Len() == 0 {
+		return nil, errors.New("ffmpeg decode: no output")
+	}
+	return stdout.Bytes(), nil
+}
+
+// splitDecodedChannels splits the interleaved f32le output of pcmDecodeFilter
+// into the tone and raw channels.
+func splitDecodedChannels(out []byte) ([]float64, []float32) {
+	frames := len(out) / 8
+	tones := make([]float64, frames)
+	raw := make([]float32, frames)
+	for i := 0; i < frames; i++ {
+		tones[i] = float64(math.Float32frombits(binary.LittleEndian.Uint32(out[i*8:])))
+		raw[i] = math.Float32frombits(binary.LittleEndian.Uint32(out[i*8+4:]))
+	}
+	return tones, raw
+}
+
+// analysisDecimationTaps low-passes 16 kHz audio under 3.6 kHz before it is
+// halved to energySampleHz.
+var analysisDecimationTaps = func() []float64 {
+	const (
+		length = 31
+		cutoff = 3600.0 / toneDecodeHz
+	)
+	taps := make([]float64, length)
+	var sum float64
+	for n := range taps {
+		x := float64(n - length/2)
+		sinc := 2 * cutoff
+		if x != 0 {
+			sinc = math.Sin(2*math.Pi*cutoff*x) / (math.Pi * x)
+		}
+		hamming := 0.54 - 0.46*math.Cos(2*math.Pi*float64(n)/float64(length-1))
+		taps[n] = sinc * hamming
+		sum += taps[n]
+	}
+	for n := range taps {
+		taps[n] /= sum
+	}
+	return taps
+}()
+
+// decimateToAnalysisPCM turns 16 kHz float samples into 8 kHz mono s16le.
+func decimateToAnalysisPCM(raw []float32) []byte {
+	half := len(analysisDecimationTaps) / 2
+	pcm := make([]byte, (len(raw)/2)*2)
+	for i := 0; i < len(raw)/2; i++ {
+		center := i * 2
+		var v float64
+		for n, tap := range analysisDecimationTaps {
+			if j := center + n - half; j >= 0 && j < len(raw) {
+				v += float64(raw[j]) * tap
+			}
+		}
+		sample := math.Round(v * 32767)
+		if sample > 32767 {
+			sample = 32767
+		} else if sample < -32768 {
+			sample = -32768
+		}
+		binary.LittleEndian.PutUint16(pcm[i*2:], uint16(int16(sample)))
+	}
+	return pcm
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"encoding/binary"
+	"math"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestPCMCacheSharesDecodes(t *testing.T) {
+	var decodes int32
+	cache := newPCMCache(100, func(audio []byte, mime string) (*decodedAudio, error) {
+		atomic.AddInt32(&decodes, 1)
+		return &decodedAudio{Analysis: make([]byte, 40)}, nil
+	})
+
+	var wg sync.WaitGroup
+	results := make([]*decodedAudio, 8)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], _ = cache.get([]byte("call one"), "audio/wav")
+		}(i)
+	}
+	wg.Wait()
+	if decodes != 1 {
+		t.Fatalf("expected a single decode, got %d", decodes)
+	}
+	for _, result := range results {
+		if result != results[0] {
+			t.Fatalf("expected every stage to get the same decode")
+		}
+	}
+
+	// 40 bytes each: the third entry pushes the first one out
+	cache.get([]byte("call two"), "audio/wav")
+	cache.get([]byte("call three"), "audio/wav")
+	cache.get([]byte("call one"), "audio/wav")
+	if decodes != 4 {
+		t.Fatalf("expected the oldest decode to be evicted, got %d decodes", decodes)
+	}
+}
+
+func TestSplitDecodedChannels(t *testing.T) {
+	out := make([]byte, 16)
+	binary.LittleEndian.PutUint32(out[0:], math.Float32bits(0.5))
+	binary.LittleEndian.PutUint32(out[4:], math.Float32bits(-0.25))
+	binary.LittleEndian.PutUint32(out[8:], math.Float32bits(0.125))
+	binary.LittleEndian.PutUint32(out[12:], math.Float32bits(1))
+
+	tones, raw := splitDecodedChannels(out)
+	if len(tones) != 2 || tones[0] != 0.5 || tones[1] != 0.125 {
+		t.Fatalf("unexpected tone channel %v", tones)
+	}
+	if len(raw) != 2 || raw[0] != -0.25 || raw[1] != 1 {
+		t.Fatalf("unexpected raw channel %v", raw)
+	}
+}
+
+func TestDecimateToAnalysisPCM(t *testing.T) {
+	sine := func(freq float64) []float32 {
+		samples := make([]float32, toneDecodeHz)
+		for i := range samples {
+			samples[i] = float32(0.5 * math.Sin(2*math.Pi*freq*float64(i)/toneDecodeHz))
+		}
+		return samples
+	}
+	rms := func(pcm []byte) float64 {
+		var sumSq float64
+		for i := 0; i < len(pcm); i += 2 {
+			v := float64(int16(binary.LittleEndian.Uint16(pcm[i:]))) / 32768
+			sumSq += v * v
+		}
+		return math.Sqrt(sumSq / float64(len(pcm)/2))
+	}
+
+	voice := decimateToAnalysisPCM(sine(1000))
+	if len(voice) != energySampleHz*2 {
+		t.Fatalf("expected one second at %d Hz, got %d bytes", energySampleHz, len(voice))
+	}
+	if level := rms(voice); math.Abs(level-0.5/math.Sqrt2) > 0.02 {
+		t.Fatalf("expected 1 kHz to pass, got rms %.3f", level)
+	}
+
+	// 6 kHz would alias to 2 kHz without the low-pass
+	if level := rms(decimateToAnalysisPCM(sine(6000))); level > 0.02 {
+		t.Fatalf("expected 6 kHz to be filtered, got rms %.3f", level)
+	}
+}
@@ -0,0 +1,109 @@
+// Copyright (C) 2026 Thinline Dynamic Solutions
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>
+
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	audioProcessingDefaultLoudness = -16.0 // LUFS
+	audioProcessingDefaultHighpass = 120   // Hz
+)
+
+// AudioProcessingConfig replaces the fixed filters of the audio conversion
+// with a chain set per system: a highpass filter, denoising and loudness
+// normalization. It only changes the stored audio; tone detection and
+// transcription read the audio as uploaded.
+type AudioProcessingConfig struct {
+	Enabled bool                      `json:"enabled"`
+	Default AudioProcessingSettings   `json:"default"`
+	Systems []AudioProcessingSettings `json:"systems,omitempty"`
+}
+
+// AudioProcessingSettings is the chain for one system. Zero values keep the
+// defaults; Disabled stores the system's audio unfiltered.
+type AudioProcessingSettings struct {
+	SystemRef    uint    `json:"systemRef,omitempty"`
+	Disabled     bool    `json:"disabled,omitempty"`
+	Loudness     float64 `json:"loudness,omitempty"`   // LUFS, default -16
+	HighpassHz   uint    `json:"highpassHz,omitempty"` // default 120
+	Denoise      bool    `json:"denoise,omitempty"`
+	DenoiseModel string  `json:"denoiseModel,omitempty"` // RNNoise model file, ffmpeg's own denoiser when empty
+}
+
+// ForSystem returns the chain for the system, false when the audio is not
+// processed.
+func (config AudioProcessingConfig) ForSystem(systemRef uint) (AudioProcessingSettings, bool) {
+	if !config.Enabled {
+		return AudioProcessingSettings{}, false
+	}
+	settings := config.Default
+	for _, override := range config.Systems {
+		if override.SystemRef == systemRef {
+			settings = override
+			break
+		}
+	}
+	if settings.Disabled {
+		return settings, false
+	}
+	if settings.Loudness == 0 {
+		settings.Loudness = audioProcessingDefaultLoudness
+	}
+	if settings.HighpassHz == 0 {
+		settings.HighpassHz = audioProcessingDefaultHighpass
+	}
+	settings.SystemRef = systemRef
+	return settings, true
+}
+
+// filters returns the ffmpeg filter chain. A negative loudnessTarget, from
+// the loudness targets of the source, replaces the chain's loudness.
+func (settings AudioProcessingSettings) filters(loudnessTarget float64) string {
+	loudness := settings.Loudness
+	if loudnessTarget < 0 {
+		loudness = loudnessTarget
+	}
+
+	filters := []string{"apad=whole_dur=3s", fmt.Sprintf("highpass=f=%d", settings.HighpassHz)}
+	if settings.Denoise {
+		if settings.DenoiseModel != "" {
+			filters = append(filters, fmt.Sprintf("arnndn=m='%s'", strings.ReplaceAll(settings.DenoiseModel, "'", `'\''`)))
+		} else {
+			filters = append(filters, "afftdn=nf=-20")
+		}
+	}
+	filters = append(filters,
+		fmt.Sprintf("loudnorm=I=%.1f:TP=-1.5:LRA=11", loudness),
+		"alimiter=limit=0.891:attack=5:release=50",
+	)
+	return strings.Join(filters, ",")
+}
+
+// audioFilters returns the filter chain for the call audio, empty for the
+// conversion's own filters.
+func (controller *Controller) audioFilters(call *Call, loudnessTarget float64) string {
+	if call.System == nil {
+		return ""
+	}
+	settings, ok := controller.Options.AudioProcessingConfig.ForSystem(call.System.SystemRef)
+	if !ok {
+		return ""
+	}
+	return settings.filters(loudnessTarget)
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAudioProcessingForSystem(t *testing.T) {
+	config := AudioProcessingConfig{
+		Enabled: true,
+		Default: AudioProcessingSettings{Denoise: true},
+		Systems: []AudioProcessingSettings{
+			{SystemRef: 2, Loudness: -20, HighpassHz: 200, Denoise: true, DenoiseModel: "/models/sh.rnnn"},
+			{SystemRef: 3, Disabled: true},
+		},
+	}
+
+	settings, ok := config.ForSystem(1)
+	if !ok {
+		t.Fatalf("expected system 1 to use the default chain")
+	}
+	if filters := settings.filters(0); filters != "apad=whole_dur=3s,highpass=f=120,afftdn=nf=-20,loudnorm=I=-16.0:TP=-1.5:LRA=11,alimiter=limit=0.891:attack=5:release=50" {
+		t.Fatalf("unexpected default chain %q", filters)
+	}
+
+	settings, ok = config.ForSystem(2)
+	if !ok {
+		t.Fatalf("expected system 2 to be processed")
+	}
+	filters := settings.filters(0)
+	if !strings.Contains(filters, "highpass=f=200") || !strings.Contains(filters, "arnndn=m='/models/sh.rnnn'") || !strings.Contains(filters, "loudnorm=I=-20.0") {
+		t.Fatalf("unexpected system 2 chain %q", filters)
+	}
+	if filters := settings.filters(-23); !strings.Contains(filters, "loudnorm=I=-23.0") {
+		t.Fatalf("expected the source loudness target to win, got %q", filters)
+	}
+
+	if _, ok := config.ForSystem(3); ok {
+		t.Fatalf("expected system 3 to be stored unfiltered")
+	}
+
+	config.Enabled = false
+	if _, ok := config.ForSystem(1); ok {
+		t.Fatalf("expected no processing when disabled")
+	}
+}
@@ -0,0 +1,665 @@
+// Copyright (C) 2026 Thinline Dynamic Solutions
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	AudioStorageDatabase   = "database"
+	AudioStorageFilesystem = "filesystem"
+	AudioStorageObject     = "object"
+
+	// callAudioLocationFile prefixes "audioLocation" values of the form
+	// file://2026/03/04/<name>, relative to the audio storage directory.
+	callAudioLocationFile = "file://"
+
+	audioStorageMoveBatchSize = 100
+
+	// audioMigrationStateKey is the options row holding the progress of the
+	// online audio migration, so it resumes after a restart.
+	audioMigrationStateKey = "audioMigrationState"
+	// audioMigrationIngestBacklog is the number of calls waiting for ingest
+	// above which the online migration yields to call processing.
+	audioMigrationIngestBacklog = 50
+	audioMigrationThrottleWait  = 10 * time.Second
+)
+
+// AudioStore writes and reads call audio kept outside the calls table.
+type AudioStore struct {
+	controller *Controller
+	mutex      sync.Mutex
+	migration  *AudioMigrationStatus
+	pause      chan struct{}
+	stopped    chan struct{} // closed when the migration goroutine exits
+}
+
+type AudioMigrationStatus struct {
+	Running        bool   `json:"running"`
+	Paused         bool   `json:"paused,omitempty"`
+	Throttled      bool   `json:"throttled,omitempty"` // yielding to a busy ingest
+	Backend        string `json:"backend"`
+	CallsPerMinute int    `json:"callsPerMinute,omitempty"` // 0 = as fast as possible
+	StartedAt      int64  `json:"startedAt"`
+	FinishedAt     int64  `json:"finishedAt,omitempty"`
+	Calls          int64  `json:"calls"`
+	Bytes          int64  `json:"bytes"`
+	AfterId        uint64 `json:"afterId,omitempty"` // last call moved
+	Error          string `json:"error,omitempty"`
+
+	Shared *AudioBlobStats `json:"shared,omitempty"`
+}
+
+func NewAudioStore(controller *Controller) *AudioStore {
+	return &AudioStore{controller: controller}
+}
+
+func (store *AudioStore) Backend() string {
+	switch backend := store.controller.Options.AudioStorageConfig.Backend; backend {
+	case AudioStorageFilesystem, AudioStorageObject:
+		return backend
+	default:
+		return AudioStorageDatabase
+	}
+}
+
+func (store *AudioStore) root() string {
+	p := store.controller.Options.AudioStorageConfig.Path
+	if p == "" {
+		p = "audio"
+	}
+	return store.controller.Config.GetPath(p)
+}
+
+// Put writes audio to backend and returns its location and checksum. The name
+// only needs to be unique, so it is derived from the timestamp and checksum
+// rather than the call id, which is not known before the insert.
+func (store *AudioStore) Put(backend string, name string, timestamp time.Time, filename string, mime string, audio []byte) (string, string, error) {
+	sum := sha256.Sum256(audio)
+	checksum := hex.EncodeToString(sum[:])
+
+	if name == "" {
+		name = fmt.Sprintf("%d-%s", timestamp.UnixMilli(), checksum[:12])
+	}
+	rel := audioStoragePath(timestamp, name, filename)
+
+	switch backend {
+	case AudioStorageFilesystem:
+		full := filepath.Join(store.root(), filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(full), 0750); err != nil {
+			return "", "", err
+		}
+		tmp := full + ".tmp"
+		if err := os.WriteFile(tmp, audio, 0640); err != nil {
+			return "", "", err
+		}
+		if err := os.Rename(tmp, full); err != nil {
+			os.Remove(tmp)
+			return "", "", err
+		}
+		return callAudioLocationFile + rel, checksum, nil
+
+	case AudioStorageObject:
+		client, err := store.controller.CallArchiver.s3()
+		if err != nil {
+			return "", "", err
+		}
+		key := rel
+		if prefix := store.controller.Options.CallArchiveConfig.Prefix; prefix != "" {
+			key = strings.TrimSuffix(prefix, "/") + "/" + rel
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), callArchiveTimeout)
+		defer cancel()
+		if err := client.PutObject(ctx, key, audio, mime); err != nil {
+			return "", "", err
+		}
+		return callAudioLocationS3 + client.Bucket + "/" + key, checksum, nil
+	}
+
+	return "", "", fmt.Errorf("audio storage backend %q does not store files", backend)
+}
+
+// Remove deletes a stored file, used when the call insert fails after Put.
+func (store *AudioStore) Remove(location string) {
+	switch {
+	case strings.HasPrefix(location, callAudioLocationFile):
+		if full, err := store.filePath(location); err == nil {
+			os.Remove(full)
+		}
+	case strings.HasPrefix(location, callAudioLocationS3):
+		if client, err := store.controller.CallArchiver.s3(); err == nil {
+			if _, key, ok := strings.Cut(strings.TrimPrefix(location, callAudioLocationS3), "/"); ok {
+				ctx, cancel := context.WithTimeout(context.Background(), callArchiveTimeout)
+				client.DeleteObject(ctx, key)
+				cancel()
+			}
+		}
+	}
+}
+
+// locationSize returns the size of the audio stored at a filesystem or shared
+// blob location, 0 when it cannot be read.
+func (store *AudioStore) locationSize(location string) int64 {
+	switch {
+	case strings.HasPrefix(location, callAudioLocationFile):
+		if full, err := store.filePath(location); err == nil {
+			if info, err := os.Stat(full); err == nil {
+				return info.Size()
+			}
+		}
+	case strings.HasPrefix(location, callAudioLocationBlob):
+		var size int64
+		if store.controller.Database.Sql.QueryRow(`SELECT "size" FROM "audioBlobs" WHERE "location" = $1`, location).Scan(&size) == nil {
+			return size
+		}
+	}
+	return 0
+}
+
+// fileLocations lists the filesystem and shared blob locations of the calls
+// matching where, so callers deleting those calls can release the audio
+// afterwards. Objects are left to bucket lifecycle rules.
+func (store *AudioStore) fileLocations(where string, args ...any) []string {
+	return audioFileLocations(store.controller.Database.Sql, where, args...)
+}
+
+// audioFileLocations is fileLocations for a transaction about to delete the
+// calls.
+func audioFileLocations(db interface {
+	Query(query string, args ...any) (*sql.Rows, error)
+}, where string, args ...any) []string {
+	locations := []string{}
+
+	query := fmt.Sprintf(`SELECT "audioLocation" FROM "calls" WHERE (%s) AND ("audioLocation" LIKE 'file://%%' OR "audioLocation" LIKE 'blob://%%')`, where)
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return locations
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var location string
+		if rows.Scan(&location) == nil {
+			locations = append(locations, location)
+		}
+	}
+
+	return locations
+}
+
+// Open streams stored audio from its location.
+func (store *AudioStore) Open(ctx context.Context, location string) (io.ReadCloser, int64, error) {
+	switch {
+	case strings.HasPrefix(location, callAudioLocationFile):
+		full, err := store.filePath(location)
+		if err != nil {
+			return nil, 0, err
+		}
+		f, err := os.Open(full)
+		if err != nil {
+			return nil, 0, err
+		}
+		var size int64
+		if info, err := f.Stat(); err == nil {
+			size = info.Size()
+		}
+		return f, size, nil
+
+	case strings.HasPrefix(location, callAudioLocationS3):
+		return store.controller.CallArchiver.Open(ctx, location)
+
+	case strings.HasPrefix(location, callAudioLocationBlob):
+		audio, err := store.openBlob(location)
+		if err != nil {
+			return nil, 0, err
+		}
+		return io.NopCloser(bytes.NewReader(audio)), int64(len(audio)), nil
+	}
+
+	return nil, 0, fmt.Errorf("unsupported audio location %q", location)
+}
+
+// Fetch reads stored audio fully, verifying the checksum when one is recorded.
+func (store *AudioStore) Fetch(location string, checksum string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), callArchiveTimeout)
+	defer cancel()
+
+	body, _, err := store.Open(ctx, location)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	audio, err := io.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+
+	if checksum != "" {
+		sum := sha256.Sum256(audio)
+		if hex.EncodeToString(sum[:]) != checksum {
+			return nil, fmt.Errorf("checksum mismatch for %s", location)
+		}
+	}
+
+	return audio, nil
+}
+
+func (store *AudioStore) filePath(location string) (string, error) {
+	rel := filepath.FromSlash(strings.TrimPrefix(location, callAudioLocationFile))
+	if rel == "" || filepath.IsAbs(rel) || strings.HasPrefix(filepath.Clean(rel), "..") {
+		return "", fmt.Errorf("invalid audio location %q", location)
+	}
+	return filepath.Join(store.root(), rel), nil
+}
+
+// audioStoragePath lays files out by day so directories and buckets stay
+// browsable and lifecycle rules can target date prefixes.
+func audioStoragePath(timestamp time.Time, name string, filename string) string {
+	ext := strings.ToLower(filepath.Ext(filename))
+	if ext == "" {
+		ext = ".m4a"
+	}
+	return timestamp.UTC().Format("2006/01/02") + "/" + name + ext
+}
+
+// moveBatch moves the audio of up to limit calls still held in the calls
+// table (and older than cutoff, when non-zero) to backend.
+func (store *AudioStore) moveBatch(backend string, cutoff int64, afterId uint64, limit int) (int, uint64, int64, error) {
+	db := store.controller.Database
+
+	query := `SELECT "callId", "audio", "audioFilename", "audioMime", "timestamp" FROM "calls" WHERE "callId" > $1 AND "audioLocation" = '' AND octet_length("audio") > 0`
+	args := []any{afterId, limit}
+	if cutoff > 0 {
+		query += ` AND "timestamp" < $3`
+		args = append(args, cutoff)
+	}
+	query += ` ORDER BY "callId" LIMIT $2`
+
+	rows, err := db.Sql.Query(query, args...)
+	if err != nil {
+		return 0, afterId, 0, err
+	}
+
+	type pending struct {
+		id        uint64
+		audio     []byte
+		filename  string
+		mime      string
+		timestamp int64
+	}
+
+	batch := []pending{}
+	for rows.Next() {
+		var p pending
+		if err := rows.Scan(&p.id, &p.audio, &p.filename, &p.mime, &p.timestamp); err != nil {
+			rows.Close()
+			return 0, afterId, 0, err
+		}
+		batch = append(batch, p)
+	}
+	rows.Close()
+
+	var moved int64
+	for _, p := range batch {
+		location, checksum, err := store.Put(backend, fmt.Sprint(p.id), time.UnixMilli(p.timestamp), p.filename, p.mime, p.audio)
+		if err != nil {
+			return 0, afterId, moved, fmt.Errorf("call %d: %v", p.id, err)
+		}
+
+		query := `UPDATE "calls" SET "audio" = $1, "audioLocation" = $2, "audioChecksum" = $3 WHERE "callId" = $4 AND "audioLocation" = ''`
+		if _, err := db.Sql.Exec(query, []byte{}, location, checksum, p.id); err != nil {
+			store.Remove(location)
+			return 0, afterId, moved, fmt.Errorf("call %d: %v", p.id, err)
+		}
+
+		afterId = p.id
+		moved += int64(len(p.audio))
+	}
+
+	return len(batch), afterId, moved, nil
+}
+
+// MigrateBlobs moves every audio BLOB still in the calls table to the configured
+// backend. PostgreSQL only returns the space to the OS after VACUUM FULL.
+func (store *AudioStore) MigrateBlobs(progress func(calls int64, bytes int64)) (int64, int64, error) {
+	backend := store.Backend()
+	if backend == AudioStorageDatabase {
+		return 0, 0, fmt.Errorf("audio storage backend is %q; choose filesystem or object first", backend)
+	}
+
+	var (
+		afterId uint64
+		calls   int64
+		total   int64
+	)
+
+	for {
+		n, lastId, moved, err := store.moveBatch(backend, 0, afterId, audioStorageMoveBatchSize)
+		calls += int64(n)
+		total += moved
+		if progress != nil {
+			progress(calls, total)
+		}
+		if err != nil {
+			return calls, total, err
+		}
+		if n < audioStorageMoveBatchSize {
+			return calls, total, nil
+		}
+		afterId = lastId
+	}
+}
+
+// StartMigration moves the BLOBs in the background while the server runs,
+// at most callsPerMinute calls a minute when non-zero. A paused or
+// interrupted migration to the same backend resumes where it stopped.
+func (store *AudioStore) StartMigration(callsPerMinute int) (*AudioMigrationStatus, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	if store.migration != nil && store.migration.Running {
+		return nil, fmt.Errorf("a migration is already running")
+	}
+
+	if store.stopped != nil {
+		select {
+		case <-store.stopped:
+		default:
+			return nil, fmt.Errorf("the paused migration is finishing its batch, try again shortly")
+		}
+	}
+
+	backend := store.Backend()
+	if backend == AudioStorageDatabase {
+		return nil, fmt.Errorf("audio storage backend is %q; choose filesystem or object first", backend)
+	}
+
+	if callsPerMinute < 0 {
+		callsPerMinute = 0
+	}
+
+	status := &AudioMigrationStatus{Backend: backend, StartedAt: time.Now().UnixMilli()}
+	if saved := store.savedMigration(); saved != nil && saved.Backend == backend && saved.FinishedAt == 0 {
+		status = saved
+	}
+	status.Running = true
+	status.Paused = false
+	status.CallsPerMinute = callsPerMinute
+	status.Error = ""
+
+	store.migration = status
+	store.pause = make(chan struct{})
+	store.stopped = make(chan struct{})
+	store.saveMigration(status)
+
+	go store.runMigration(status, store.pause, store.stopped)
+
+	snapshot := *status
+	return &snapshot, nil
+}
+
+// PauseMigration stops the running migration after its current batch and
+// keeps its progress for StartMigration to resume.
+func (store *AudioStore) PauseMigration() error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	if store.migration == nil || !store.migration.Running {
+		return fmt.Errorf("no migration is running")
+	}
+
+	close(store.pause)
+	store.migration.Running = false
+	store.migration.Paused = true
+	store.migration.Throttled = false
+
+	return nil
+}
+
+// ResumeMigration restarts a migration that was running when the server
+// stopped.
+func (store *AudioStore) ResumeMigration() {
+	saved := store.savedMigration()
+	if saved == nil || !saved.Running {
+		return
+	}
+
+	if _, err := store.StartMigration(saved.CallsPerMinute); err != nil {
+		store.controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("audio storage migration: not resumed, %v", err))
+	}
+}
+
+func (store *AudioStore) runMigration(status *AudioMigrationStatus, pause <-chan struct{}, stopped chan<- struct{}) {
+	defer close(stopped)
+
+	store.mutex.Lock()
+	backend, afterId, rate := status.Backend, status.AfterId, status.CallsPerMinute
+	store.mutex.Unlock()
+
+	wait := func(d time.Duration) bool {
+		select {
+		case <-pause:
+			return false
+		case <-time.After(d):
+			return true
+		}
+	}
+
+	budget, window := rate, time.Now()
+
+	for {
+		select {
+		case <-pause:
+			store.mutex.Lock()
+			store.saveMigration(status)
+			store.mutex.Unlock()
+			store.controller.Logs.LogEvent(LogLevelInfo, fmt.Sprintf("audio storage migration: paused after %d calls", status.Calls))
+			return
+		default:
+		}
+
+		throttled := len(store.controller.Ingest) > audioMigrationIngestBacklog
+		store.mutex.Lock()
+		status.Throttled = throttled
+		store.mutex.Unlock()
+		if throttled {
+			wait(audioMigrationThrottleWait)
+			continue
+		}
+
+		limit := audioStorageMoveBatchSize
+		if rate > 0 {
+			if budget == 0 {
+				wait(time.Until(window.Add(time.Minute)))
+				budget, window = rate, time.Now()
+				continue
+			}
+			if budget < limit {
+				limit = budget
+			}
+		}
+
+		n, lastId, moved, err := store.moveBatch(backend, 0, afterId, limit)
+		afterId = lastId
+		budget -= n
+
+		store.mutex.Lock()
+		status.Calls += int64(n)
+		status.Bytes += moved
+		status.AfterId = afterId
+		done := err != nil || n < limit
+		if done {
+			status.Running = false
+			status.Paused = false
+			status.FinishedAt = time.Now().UnixMilli()
+			if err != nil {
+				status.Error = err.Error()
+			}
+		}
+		store.saveMigration(status)
+		calls, total := status.Calls, status.Bytes
+		store.mutex.Unlock()
+
+		if err != nil {
+			store.controller.Logs.LogEvent(LogLevelError, fmt.Sprintf("audio storage migration: %v", err))
+			return
+		}
+		if done {
+			store.controller.Logs.LogEvent(LogLevelInfo, fmt.Sprintf("audio storage migration: moved %d calls (%s) to %s storage", calls, formatBytes(int(total)), backend))
+			return
+		}
+	}
+}
+
+// savedMigration returns the persisted migration progress, if any.
+func (store *AudioStore) savedMigration() *AudioMigrationStatus {
+	var value string
+	if err := store.controller.Database.Sql.QueryRow(`SELECT "value" FROM "options" WHERE "key" = $1`, audioMigrationStateKey).Scan(&value); err != nil {
+		return nil
+	}
+
+	status := &AudioMigrationStatus{}
+	if err := json.Unmarshal([]byte(value), status); err != nil {
+		return nil
+	}
+	return status
+}
+
+// saveMigration persists the migration progress. The caller holds the mutex.
+func (store *AudioStore) saveMigration(status *AudioMigrationStatus) {
+	b, err := json.Marshal(status)
+	if err != nil {
+		return
+	}
+
+	db := store.controller.Database.Sql
+	res, err := db.Exec(`UPDATE "options" SET "value" = $1 WHERE "key" = $2`, string(b), audioMigrationStateKey)
+	if err == nil {
+		if n, _ := res.RowsAffected(); n == 0 {
+			_, err = db.Exec(`INSERT INTO "options" ("key", "value") VALUES ($1, $2)`, audioMigrationStateKey, string(b))
+		}
+	}
+	if err != nil {
+		store.controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("audio storage migration: saving progress: %v", err))
+	}
+}
+
+func (store *AudioStore) MigrationStatus() *AudioMigrationStatus {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	if store.migration == nil {
+		if saved := store.savedMigration(); saved != nil {
+			saved.Running = false
+			return saved
+		}
+		return &AudioMigrationStatus{Backend: store.Backend()}
+	}
+
+	snapshot := *store.migration
+	return &snapshot
+}
+
+// AudioStorageMigrateHandler moves existing BLOBs out of the database.
+// GET returns progress, POST starts or resumes a migration, optionally with
+// {"callsPerMinute": n}, and DELETE pauses it.
+func (admin *Admin) AudioStorageMigrateHandler(w http.ResponseWriter, r *http.Request) {
+	t := admin.GetAuthorization(r)
+	if !admin.ValidateToken(t) {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	switch r.Method {
+	case http.MethodGet:
+		status := admin.Controller.AudioStore.MigrationStatus()
+		if stats, err := admin.Controller.AudioStore.BlobStats(); err == nil {
+			status.Shared = stats
+		}
+		json.NewEncoder(w).Encode(status)
+
+	case http.MethodPost:
+		var body struct {
+			CallsPerMinute int `json:"callsPerMinute"`
+		}
+		if r.ContentLength != 0 {
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+				return
+			}
+		}
+
+		status, err := admin.Controller.AudioStore.StartMigration(body.CallsPerMinute)
+		if err != nil {
+			w.WriteHeader(http.StatusConflict)
+			json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+		json.NewEncoder(w).Encode(status)
+
+	case http.MethodDelete:
+		if err := admin.Controller.AudioStore.PauseMigration(); err != nil {
+			w.WriteHeader(http.StatusConflict)
+			json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+			return
+		}
+		json.NewEncoder(w).Encode(admin.Controller.AudioStore.MigrationStatus())
+
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// runAudioMigrationCommand is the -migrate_audio command line tool.
+func runAudioMigrationCommand(controller *Controller) error {
+	if err := controller.Options.Read(controller.Database); err != nil {
+		return err
+	}
+
+	last := time.Now()
+	calls, total, err := controller.AudioStore.MigrateBlobs(func(calls int64, bytes int64) {
+		if time.Since(last) > 5*time.Second {
+			fmt.Printf("moved %d calls (%s)\n", calls, formatBytes(int(bytes)))
+			last = time.Now()
+		}
+	})
+
+	fmt.Printf("moved %d calls (%s) to %s storage\n", calls, formatBytes(int(total)), controller.AudioStore.Backend())
+	if err == nil && calls > 0 {
+		fmt.Println(`run VACUUM FULL "calls" to return the freed space to the operating system`)
+	}
+
+	return err
+}
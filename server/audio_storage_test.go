@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAudioStoreFilesystemRoundTrip(t *testing.T) {
+	options := NewOptions()
+	options.AudioStorageConfig = AudioStorageConfig{Backend: AudioStorageFilesystem, Path: t.TempDir()}
+	store := NewAudioStore(&Controller{Config: &Config{}, Options: options})
+
+	audio := []byte("not really aac")
+	ts := time.Date(2026, 3, 4, 12, 0, 0, 0, time.UTC)
+
+	location, checksum, err := store.Put(store.Backend(), "", ts, "call.m4a", "audio/mp4", audio)
+	if err != nil {
+		t.Fatalf("put: %v", err)
+	}
+	if !strings.HasPrefix(location, "file://2026/03/04/") || !strings.HasSuffix(location, ".m4a") {
+		t.Fatalf("location = %s", location)
+	}
+
+	got, err := store.Fetch(location, checksum)
+	if err != nil || !bytes.Equal(got, audio) {
+		t.Fatalf("fetch = %q, %v", got, err)
+	}
+
+	if _, err := store.Fetch(location, strings.Repeat("0", 64)); err == nil {
+		t.Fatalf("checksum mismatch not detected")
+	}
+
+	body, size, err := store.Open(context.Background(), location)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	b, _ := io.ReadAll(body)
+	body.Close()
+	if size != int64(len(audio)) || !bytes.Equal(b, audio) {
+		t.Fatalf("open = %q (%d)", b, size)
+	}
+
+	store.Remove(location)
+	if _, err := store.Fetch(location, ""); err == nil {
+		t.Fatalf("file not removed")
+	}
+}
+
+func TestAudioStoreRejectsTraversal(t *testing.T) {
+	options := NewOptions()
+	options.AudioStorageConfig = AudioStorageConfig{Backend: AudioStorageFilesystem, Path: t.TempDir()}
+	store := NewAudioStore(&Controller{Config: &Config{}, Options: options})
+
+	for _, location := range []string{"file://../etc/passwd", "file:///etc/passwd", "file://"} {
+		if _, err := store.filePath(location); err == nil {
+			t.Fatalf("%s accepted", location)
+		}
+	}
+}
+
+func TestAudioStorageConfigDedup(t *testing.T) {
+	options := NewOptions().FromMap(map[string]any{
+		"audioStorageConfig": map[string]any{"backend": "filesystem", "dedup": true},
+	})
+	store := NewAudioStore(&Controller{Config: &Config{}, Options: options})
+	if !store.Dedup() || store.Backend() != AudioStorageFilesystem {
+		t.Fatalf("config = %+v", options.AudioStorageConfig)
+	}
+
+	// Shared blobs live in the database; removing one only drops the row
+	store.Remove(callAudioLocationBlob + strings.Repeat("0", 64))
+}
+
+func TestAudioMigrationControls(t *testing.T) {
+	options := NewOptions()
+	options.AudioStorageConfig = AudioStorageConfig{Backend: AudioStorageDatabase}
+	store := NewAudioStore(&Controller{Config: &Config{}, Options: options})
+
+	if _, err := store.StartMigration(60); err == nil || !strings.Contains(err.Error(), "choose filesystem or object") {
+		t.Fatalf("migrating to the database backend should be refused, got %v", err)
+	}
+
+	if err := store.PauseMigration(); err == nil {
+		t.Fatal("pausing without a running migration should fail")
+	}
+}
+
+func TestAudioDedupExistingNeedsDedup(t *testing.T) {
+	options := NewOptions()
+	options.AudioStorageConfig = AudioStorageConfig{Backend: AudioStorageFilesystem, Path: t.TempDir()}
+	store := NewAudioStore(&Controller{Config: &Config{}, Options: options})
+
+	if _, _, err := store.DedupExisting(nil); err == nil || !strings.Contains(err.Error(), "enable dedup") {
+		t.Fatalf("backfill without dedup enabled should be refused, got %v", err)
+	}
+}
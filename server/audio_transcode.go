@@ -0,0 +1,221 @@
+// Copyright (C) 2026 Thinline Dynamic Solutions
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"strings"
+)
+
+// AudioTranscodeConfig converts the stored audio of new calls to AAC at one
+// bitrate in the background, so feeds uploading WAV or high-bitrate MP3
+// don't take more room than the others.
+type AudioTranscodeConfig struct {
+	Enabled bool `json:"enabled,omitempty"`
+	Bitrate uint `json:"bitrate,omitempty"` // kbit/s, 32 to 128, default 48
+}
+
+const (
+	audioTranscodeDefaultBitrate = 48
+	audioTranscodeMinBitrate     = 32
+	audioTranscodeMaxBitrate     = 128
+	// audioTranscodeBitrateMargin leaves AAC audio alone unless its bitrate
+	// is well above the configured one, as container overhead inflates the
+	// estimate of short calls.
+	audioTranscodeBitrateMargin = 1.25
+)
+
+func (config AudioTranscodeConfig) bitrate() uint {
+	switch {
+	case config.Bitrate == 0:
+		return audioTranscodeDefaultBitrate
+	case config.Bitrate < audioTranscodeMinBitrate:
+		return audioTranscodeMinBitrate
+	case config.Bitrate > audioTranscodeMaxBitrate:
+		return audioTranscodeMaxBitrate
+	}
+	return config.Bitrate
+}
+
+// needsTranscode reports whether audio of the mime type, size in bytes and
+// duration in seconds isn't AAC at the storage bitrate. AAC audio of unknown
+// duration is left as is.
+func (config AudioTranscodeConfig) needsTranscode(mime string, size int, duration float64) bool {
+	if audioExtFromMime(mime) != ".m4a" {
+		return true
+	}
+	if duration <= 0 {
+		return false
+	}
+	kbps := float64(size) * 8 / duration / 1000
+	return kbps > float64(config.bitrate())*audioTranscodeBitrateMargin
+}
+
+// Transcode encodes audio to AAC at bitrate kbit/s like Convert, without its
+// filters, keeping the metadata.
+func (ffmpeg *FFMpeg) Transcode(audio []byte, mime string, bitrate uint) ([]byte, error) {
+	if !ffmpeg.available {
+		return nil, errors.New("ffmpeg is not available")
+	}
+
+	// The input goes through a file so containers that seek, like m4a, work
+	ext := audioExtFromMime(mime)
+	in, err := os.CreateTemp("", "tlr-transcode-*"+ext)
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(in.Name())
+	if _, err := in.Write(audio); err != nil {
+		in.Close()
+		return nil, err
+	}
+	in.Close()
+
+	args := []string{
+		"-i", in.Name(),
+		"-map_metadata", "0",
+		"-vn",
+		"-c:a", "aac", "-b:a", fmt.Sprintf("%dk", bitrate), "-movflags", "frag_keyframe+empty_moov", "-f", "ipod", "-",
+	}
+
+	cmd := exec.Command("ffmpeg", args...)
+
+	stdout := bytes.NewBuffer([]byte(nil))
+	cmd.Stdout = stdout
+
+	stderr := bytes.NewBuffer([]byte(nil))
+	cmd.Stderr = stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("%v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	if stdout.Len() == 0 {
+		return nil, errors.New("ffmpeg produced no audio")
+	}
+	return stdout.Bytes(), nil
+}
+
+// jobKindTranscode is a persistent job converting the stored audio of one
+// call to AAC.
+const jobKindTranscode = "transcode"
+
+// transcodeJobConcurrency calls are converted at the same time.
+const transcodeJobConcurrency = 2
+
+type transcodeJobPayload struct {
+	CallId       uint64 `json:"callId"`
+	OriginalMime string `json:"originalMime"`
+}
+
+// queueTranscode queues the conversion of a newly written call whose audio
+// isn't AAC at the storage bitrate. originalMime is the type of the uploaded audio.
+func (controller *Controller) queueTranscode(call *Call, originalMime string) {
+	config := controller.Options.AudioTranscodeConfig
+	if !config.Enabled || call.Id == 0 || len(call.Audio) == 0 {
+		return
+	}
+	if !config.needsTranscode(call.AudioMime, len(call.Audio), call.Duration) {
+		return
+	}
+
+	payload := transcodeJobPayload{CallId: call.Id, OriginalMime: originalMime}
+	if _, err := controller.Jobs.Enqueue(jobKindTranscode, 0, payload, nil, 0); err != nil {
+		controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("transcode: call %d not queued: %v", call.Id, err))
+	}
+}
+
+// runTranscodeJob converts the stored audio of a call to AAC.
+// The call is left alone if its audio was replaced in the meantime, as when
+// a better duplicate arrived.
+func (controller *Controller) runTranscodeJob(job *Job) error {
+	var payload transcodeJobPayload
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		return jobPermanent(fmt.Errorf("invalid payload: %v", err))
+	}
+
+	config := controller.Options.AudioTranscodeConfig
+	if !config.Enabled {
+		return jobPermanent(errors.New("transcoding is disabled"))
+	}
+
+	call, err := controller.Calls.GetCall(payload.CallId)
+	if err != nil || call == nil {
+		return jobPermanent(fmt.Errorf("call %d not found", payload.CallId))
+	}
+	if len(call.Audio) == 0 {
+		return jobPermanent(fmt.Errorf("call %d has no audio", payload.CallId))
+	}
+
+	duration, _ := controller.getCallDuration(call)
+	if !config.needsTranscode(call.AudioMime, len(call.Audio), duration) {
+		return nil
+	}
+
+	audio, err := controller.FFMpeg.Transcode(call.Audio, call.AudioMime, config.bitrate())
+	if err != nil {
+		return jobPermanent(fmt.Errorf("call %d: %v", call.Id, err))
+	}
+
+	var (
+		previousFilename = call.AudioFilename
+		previousMime     = call.AudioMime
+		previousLocation = call.AudioLocation
+		previousSize     = len(call.Audio)
+	)
+
+	call.Audio = audio
+	call.AudioFilename = strings.TrimSuffix(call.AudioFilename, path.Ext(call.AudioFilename)) + ".m4a"
+	call.AudioMime = "audio/mp4"
+	call.AudioLocation = ""
+	call.AudioChecksum = ""
+
+	stored := controller.Calls.storeAudio(call)
+	if stored {
+		audio = []byte{}
+	}
+
+	originalMime := payload.OriginalMime
+	if originalMime == "" {
+		originalMime = previousMime
+	}
+
+	query := `UPDATE "calls" SET "audio" = $1, "audioFilename" = $2, "audioMime" = $3, "audioLocation" = $4, "audioChecksum" = $5, "audioOriginalMime" = CASE WHEN "audioOriginalMime" = '' THEN $6 ELSE "audioOriginalMime" END WHERE "callId" = $7 AND "audioFilename" = $8 AND "audioMime" = $9 AND "audioLocation" = $10 AND (octet_length("audio") = $11 OR "audioLocation" <> '')`
+	res, err := controller.Database.Sql.Exec(query, audio, call.AudioFilename, call.AudioMime, call.AudioLocation, call.AudioChecksum, originalMime, call.Id, previousFilename, previousMime, previousLocation, previousSize)
+	if err != nil {
+		if stored {
+			controller.AudioStore.Release([]string{call.AudioLocation})
+		}
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		if stored {
+			controller.AudioStore.Release([]string{call.AudioLocation})
+		}
+		return nil
+	}
+
+	if previousLocation != "" {
+		controller.AudioStore.Release([]string{previousLocation})
+	}
+
+	return nil
+}
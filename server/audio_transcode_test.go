@@ -0,0 +1,38 @@
+package main
+
+import "testing"
+
+func TestAudioTranscodeBitrate(t *testing.T) {
+	cases := map[uint]uint{0: 48, 16: 32, 64: 64, 320: 128}
+	for configured, want := range cases {
+		if got := (AudioTranscodeConfig{Bitrate: configured}).bitrate(); got != want {
+			t.Fatalf("bitrate(%d) = %d, want %d", configured, got, want)
+		}
+	}
+}
+
+func TestAudioTranscodeNeeded(t *testing.T) {
+	config := AudioTranscodeConfig{Enabled: true, Bitrate: 32}
+
+	cases := []struct {
+		mime     string
+		size     int
+		duration float64
+		want     bool
+	}{
+		{"audio/wav", 160000, 10, true},
+		{"audio/mpeg", 40000, 10, true},
+		// 32 kbit/s AAC
+		{"audio/mp4", 40000, 10, false},
+		{"audio/x-m4a", 40000, 10, false},
+		// 128 kbit/s AAC
+		{"audio/mp4", 160000, 10, true},
+		{"audio/mp4", 160000, 0, false},
+	}
+
+	for _, c := range cases {
+		if got := config.needsTranscode(c.mime, c.size, c.duration); got != c.want {
+			t.Fatalf("needsTranscode(%s, %d, %.0f) = %t, want %t", c.mime, c.size, c.duration, got, c.want)
+		}
+	}
+}
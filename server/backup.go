@@ -0,0 +1,727 @@
+// Copyright (C) 2026 Thinline Dynamic Solutions
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>
+
+package main
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	backupFormat     = "thinline-radio-backup"
+	backupVersion    = 1
+	backupFileFormat = "thinline-radio-file"
+
+	backupManifestEntry = "manifest.json"
+	backupConfigEntry   = "config.tlrcfg"
+	backupFilesDir      = "files/"
+	backupCallsDir      = "calls/"
+
+	backupCallBatch = 500
+	backupMaxEntry  = 512 << 20
+)
+
+// BackupManifest is the first entry of a backup archive. The archive is a
+// gzipped tar holding the encrypted configuration archive, the server files
+// sealed the same way under files/<name>, and the selected calls with their
+// audio under calls/<callId>.json.
+type BackupManifest struct {
+	Format        string       `json:"format"`
+	Version       int          `json:"version"`
+	CreatedAt     string       `json:"createdAt"`
+	ServerVersion string       `json:"serverVersion"`
+	Credentials   bool         `json:"credentials"`
+	Files         []BackupFile `json:"files"`
+	CallsFrom     int64        `json:"callsFrom,omitempty"`
+	CallsTo       int64        `json:"callsTo,omitempty"`
+	SystemRef     uint         `json:"systemRef,omitempty"`
+}
+
+// BackupFile is a server file of the archive. Path is as configured, relative
+// to the base directory unless absolute.
+type BackupFile struct {
+	Name string `json:"name"`
+	Path string `json:"path"`
+}
+
+// BackupOptions selects what a backup holds besides the configuration and
+// the server files: the calls from From to To, of one system when SystemRef
+// is not 0.
+type BackupOptions struct {
+	Passphrase  string
+	Credentials bool
+	Calls       bool
+	From        time.Time
+	To          time.Time
+	SystemRef   uint
+}
+
+// backupCallOptions reads the call range of a backup, given like the range
+// of the call statistics. Without from and to, calls are left out.
+func backupCallOptions(options *BackupOptions, from string, to string, systemRef string) error {
+	if from == "" && to == "" {
+		return nil
+	}
+	values := url.Values{"from": {from}, "to": {to}, "systemRef": {systemRef}}
+	for name, v := range values {
+		if v[0] == "" {
+			delete(values, name)
+		}
+	}
+	q, err := parseCallStatsQuery(values, time.Now())
+	if err != nil {
+		return err
+	}
+	options.Calls, options.From, options.To, options.SystemRef = true, q.From, q.To, q.SystemRef
+	return nil
+}
+
+const (
+	restoreReplace = "replace"
+	restoreSkip    = "skip"
+	restoreNone    = "none"
+)
+
+// RestoreOptions sets how each part of a backup is restored:
+//   - Config: replace (default) replaces the configuration, skip keeps it.
+//   - Calls: skip (default) keeps the existing call when one of the same
+//     talkgroup has the same timestamp, replace replaces it, none restores
+//     no calls.
+//   - Files: skip (default) keeps the existing files, replace overwrites them.
+type RestoreOptions struct {
+	Passphrase string
+	Config     string
+	Calls      string
+	Files      string
+}
+
+func (options *RestoreOptions) validate() error {
+	for _, part := range []struct {
+		name     string
+		mode     *string
+		fallback string
+		allowed  []string
+	}{
+		{"config", &options.Config, restoreReplace, []string{restoreReplace, restoreSkip}},
+		{"calls", &options.Calls, restoreSkip, []string{restoreSkip, restoreReplace, restoreNone}},
+		{"files", &options.Files, restoreSkip, []string{restoreSkip, restoreReplace}},
+	} {
+		if *part.mode == "" {
+			*part.mode = part.fallback
+		}
+		valid := false
+		for _, mode := range part.allowed {
+			valid = valid || *part.mode == mode
+		}
+		if !valid {
+			return fmt.Errorf("%s must be one of %s", part.name, strings.Join(part.allowed, ", "))
+		}
+	}
+	return nil
+}
+
+type RestoreFilesResult struct {
+	Restored []string `json:"restored"`
+	Skipped  []string `json:"skipped"`
+}
+
+type RestoreCallsResult struct {
+	Restored  int `json:"restored"`
+	Replaced  int `json:"replaced"`
+	Skipped   int `json:"skipped"`
+	Unmatched int `json:"unmatched"` // system or talkgroup unknown to this server
+}
+
+type RestoreResult struct {
+	Manifest *BackupManifest    `json:"manifest"`
+	Config   string             `json:"config"` // replaced or skipped
+	Files    RestoreFilesResult `json:"files"`
+	Calls    RestoreCallsResult `json:"calls"`
+}
+
+// backupCall is a call of the archive. Systems and talkgroups are referenced
+// by ref so the calls restore into another server.
+type backupCall struct {
+	SystemRef             uint             `json:"systemRef"`
+	TalkgroupRef          uint             `json:"talkgroupRef"`
+	Timestamp             int64            `json:"timestamp"`
+	SiteRef               string           `json:"siteRef,omitempty"`
+	Frequency             uint             `json:"frequency,omitempty"`
+	Patches               []uint           `json:"patches,omitempty"`
+	Units                 []backupCallUnit `json:"units,omitempty"`
+	ToneSequence          *ToneSequence    `json:"toneSequence,omitempty"`
+	HasTones              bool             `json:"hasTones,omitempty"`
+	Transcript            string           `json:"transcript,omitempty"`
+	TranscriptConfidence  float64          `json:"transcriptConfidence,omitempty"`
+	TranscriptionStatus   string           `json:"transcriptionStatus,omitempty"`
+	TranscriptTranslation string           `json:"transcriptTranslation,omitempty"`
+	AlertSummary          string           `json:"alertSummary,omitempty"`
+	AudioFilename         string           `json:"audioFilename,omitempty"`
+	AudioMime             string           `json:"audioMime,omitempty"`
+	Audio                 []byte           `json:"audio"`
+}
+
+type backupCallUnit struct {
+	Offset  float32 `json:"offset"`
+	UnitRef uint    `json:"unitRef"`
+	Label   string  `json:"label,omitempty"`
+}
+
+func newBackupCall(call *Call) *backupCall {
+	b := &backupCall{
+		SystemRef:             call.System.SystemRef,
+		TalkgroupRef:          call.Talkgroup.TalkgroupRef,
+		Timestamp:             call.Timestamp.UnixMilli(),
+		SiteRef:               call.SiteRef,
+		Frequency:             call.Frequency,
+		Patches:               call.Patches,
+		ToneSequence:          call.ToneSequence,
+		HasTones:              call.HasTones,
+		Transcript:            call.Transcript,
+		TranscriptConfidence:  call.TranscriptConfidence,
+		TranscriptionStatus:   call.TranscriptionStatus,
+		TranscriptTranslation: call.TranscriptTranslation,
+		AlertSummary:          call.AlertSummary,
+		AudioFilename:         call.AudioFilename,
+		AudioMime:             call.AudioMime,
+		Audio:                 call.Audio,
+	}
+	for _, unit := range call.Units {
+		b.Units = append(b.Units, backupCallUnit{Offset: unit.Offset, UnitRef: unit.UnitRef, Label: unit.Label})
+	}
+	return b
+}
+
+// call returns the call to write for the archived call.
+func (b *backupCall) call(system *System, talkgroup *Talkgroup) *Call {
+	call := NewCall()
+	call.System = system
+	call.Talkgroup = talkgroup
+	call.Timestamp = time.UnixMilli(b.Timestamp)
+	call.SiteRef = b.SiteRef
+	call.Frequency = b.Frequency
+	call.Patches = b.Patches
+	call.ToneSequence = b.ToneSequence
+	call.HasTones = b.HasTones
+	call.Transcript = b.Transcript
+	call.TranscriptConfidence = b.TranscriptConfidence
+	call.TranscriptionStatus = b.TranscriptionStatus
+	call.TranscriptTranslation = b.TranscriptTranslation
+	call.AlertSummary = b.AlertSummary
+	call.AudioFilename = b.AudioFilename
+	call.AudioMime = b.AudioMime
+	call.Audio = b.Audio
+	for _, unit := range b.Units {
+		call.Units = append(call.Units, CallUnit{Offset: unit.Offset, UnitRef: unit.UnitRef, Label: unit.Label})
+	}
+	return call
+}
+
+// backupFileCandidates are the server files a database dump misses: the INI
+// file, the TLS certificates and the uploaded email logo and favicon.
+func (controller *Controller) backupFileCandidates() []BackupFile {
+	config := controller.Config
+	return []BackupFile{
+		{Name: "ini", Path: config.ConfigFile},
+		{Name: "sslCert", Path: config.SslCertFile},
+		{Name: "sslKey", Path: config.SslKeyFile},
+		{Name: "sslCaCert", Path: config.SslCaCertFile},
+		{Name: "sslCaKey", Path: config.SslCaKeyFile},
+		{Name: "emailLogo", Path: controller.Options.EmailLogoFilename},
+		{Name: "favicon", Path: controller.Options.FaviconFilename},
+	}
+}
+
+// restorePath is where a file of the archive is restored: the path this
+// server has for it, or else the archived path when it stays inside the base
+// directory.
+func (controller *Controller) restorePath(file BackupFile) (string, bool) {
+	for _, candidate := range controller.backupFileCandidates() {
+		if candidate.Name == file.Name && candidate.Path != "" {
+			return controller.Config.GetPath(candidate.Path), true
+		}
+	}
+	if file.Path == "" || filepath.IsAbs(file.Path) || !filepath.IsLocal(file.Path) {
+		return "", false
+	}
+	return controller.Config.GetPath(file.Path), true
+}
+
+// WriteBackup writes a backup archive to w and returns the number of calls
+// it holds. Calls that cannot be read, like calls still delayed, are left
+// out.
+func (controller *Controller) WriteBackup(w io.Writer, options BackupOptions) (int, error) {
+	sealedConfig, err := sealConfigArchive(controller.Admin.GetConfig(), options.Passphrase, options.Credentials)
+	if err != nil {
+		return 0, err
+	}
+
+	var systemId uint64
+	if options.Calls && options.SystemRef > 0 {
+		system, ok := controller.Systems.GetSystemByRef(options.SystemRef)
+		if !ok {
+			return 0, fmt.Errorf("unknown systemRef %d", options.SystemRef)
+		}
+		systemId = system.Id
+	}
+
+	manifest := BackupManifest{
+		Format:        backupFormat,
+		Version:       backupVersion,
+		CreatedAt:     time.Now().UTC().Format(time.RFC3339),
+		ServerVersion: Version,
+		Credentials:   options.Credentials,
+		Files:         []BackupFile{},
+	}
+	if options.Calls {
+		manifest.CallsFrom, manifest.CallsTo, manifest.SystemRef = options.From.UnixMilli(), options.To.UnixMilli(), options.SystemRef
+	}
+
+	sealedFiles := map[string][]byte{}
+	for _, file := range controller.backupFileCandidates() {
+		if file.Path == "" {
+			continue
+		}
+		b, err := os.ReadFile(controller.Config.GetPath(file.Path))
+		if err != nil {
+			continue
+		}
+		if sealedFiles[file.Name], err = sealArchive(backupFileFormat, b, options.Passphrase); err != nil {
+			return 0, err
+		}
+		manifest.Files = append(manifest.Files, file)
+	}
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	now := time.Now()
+	entry := func(name string, b []byte) error {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0600, Size: int64(len(b)), ModTime: now, Typeflag: tar.TypeReg}); err != nil {
+			return err
+		}
+		_, err := tw.Write(b)
+		return err
+	}
+
+	b, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return 0, err
+	}
+	if err := entry(backupManifestEntry, b); err != nil {
+		return 0, err
+	}
+	if err := entry(backupConfigEntry, sealedConfig); err != nil {
+		return 0, err
+	}
+	for _, file := range manifest.Files {
+		if err := entry(backupFilesDir+file.Name, sealedFiles[file.Name]); err != nil {
+			return 0, err
+		}
+	}
+
+	count := 0
+	for lastId := uint64(0); options.Calls; {
+		rows, err := controller.Database.Sql.Query(`SELECT "callId" FROM "calls" WHERE "timestamp" >= $1 AND "timestamp" < $2 AND ($3 = 0 OR "systemId" = $3) AND "callId" > $4 ORDER BY "callId" LIMIT $5`, options.From.UnixMilli(), options.To.UnixMilli(), systemId, lastId, backupCallBatch)
+		if err != nil {
+			return count, err
+		}
+		var ids []uint64
+		for rows.Next() {
+			var id uint64
+			if err := rows.Scan(&id); err == nil {
+				ids = append(ids, id)
+			}
+		}
+		rows.Close()
+
+		for _, id := range ids {
+			call, err := controller.Calls.GetCall(id)
+			if err != nil {
+				continue
+			}
+			b, err := json.Marshal(newBackupCall(call))
+			if err != nil {
+				continue
+			}
+			if err := entry(fmt.Sprintf("%s%d.json", backupCallsDir, id), b); err != nil {
+				return count, err
+			}
+			count++
+		}
+
+		if len(ids) < backupCallBatch {
+			break
+		}
+		lastId = ids[len(ids)-1]
+	}
+
+	if err := tw.Close(); err != nil {
+		return count, err
+	}
+	return count, gz.Close()
+}
+
+// RestoreBackup restores an archive made by WriteBackup, reading it as a
+// stream. The configuration comes first in the archive, so the restored
+// calls find the restored systems and talkgroups. On error, the result holds
+// what was restored before it.
+func (admin *Admin) RestoreBackup(r io.Reader, options RestoreOptions) (*RestoreResult, error) {
+	if err := options.validate(); err != nil {
+		return nil, err
+	}
+
+	result := &RestoreResult{Files: RestoreFilesResult{Restored: []string{}, Skipped: []string{}}}
+
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, errors.New("not a backup archive")
+	}
+	defer zr.Close()
+	tr := tar.NewReader(zr)
+
+	header, err := tr.Next()
+	if err != nil || header.Name != backupManifestEntry {
+		return nil, errors.New("not a backup archive")
+	}
+	manifest := &BackupManifest{}
+	if err := json.NewDecoder(io.LimitReader(tr, backupMaxEntry)).Decode(manifest); err != nil || manifest.Format != backupFormat {
+		return nil, errors.New("not a backup archive")
+	}
+	if manifest.Version > backupVersion {
+		return nil, fmt.Errorf("unsupported backup version %d", manifest.Version)
+	}
+	result.Manifest = manifest
+
+	files := map[string]BackupFile{}
+	for _, file := range manifest.Files {
+		files[file.Name] = file
+	}
+
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return result, err
+		}
+
+		switch {
+		case header.Name == backupConfigEntry:
+			if options.Config == restoreSkip {
+				result.Config = "skipped"
+				continue
+			}
+			b, err := io.ReadAll(io.LimitReader(tr, configArchiveMaxSize))
+			if err != nil {
+				return result, err
+			}
+			if _, err := admin.ImportConfigArchive(b, options.Passphrase); err != nil {
+				return result, fmt.Errorf("configuration: %w", err)
+			}
+			result.Config = "replaced"
+
+		case strings.HasPrefix(header.Name, backupFilesDir):
+			file, ok := files[strings.TrimPrefix(header.Name, backupFilesDir)]
+			if !ok {
+				continue
+			}
+			b, err := io.ReadAll(io.LimitReader(tr, backupMaxEntry))
+			if err != nil {
+				return result, err
+			}
+			restored, err := admin.Controller.restoreBackupFile(file, b, options)
+			if err != nil {
+				return result, fmt.Errorf("file %s: %w", file.Name, err)
+			}
+			if restored {
+				result.Files.Restored = append(result.Files.Restored, file.Name)
+			} else {
+				result.Files.Skipped = append(result.Files.Skipped, file.Name)
+			}
+
+		case strings.HasPrefix(header.Name, backupCallsDir):
+			if options.Calls == restoreNone {
+				continue
+			}
+			call := &backupCall{}
+			if err := json.NewDecoder(io.LimitReader(tr, backupMaxEntry)).Decode(call); err != nil {
+				return result, fmt.Errorf("%s: %w", header.Name, err)
+			}
+			if err := admin.Controller.restoreBackupCall(call, options.Calls, &result.Calls); err != nil {
+				return result, fmt.Errorf("%s: %w", header.Name, err)
+			}
+		}
+	}
+
+	admin.Controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("backup created %s by version %s restored: configuration %s, %d files, %d calls restored, %d replaced, %d skipped, %d unmatched", manifest.CreatedAt, manifest.ServerVersion, result.Config, len(result.Files.Restored), result.Calls.Restored, result.Calls.Replaced, result.Calls.Skipped, result.Calls.Unmatched))
+
+	return result, nil
+}
+
+// restoreBackupFile writes a file of the archive and reports whether it did;
+// existing files are kept unless the files mode is replace.
+func (controller *Controller) restoreBackupFile(file BackupFile, sealed []byte, options RestoreOptions) (bool, error) {
+	path, ok := controller.restorePath(file)
+	if !ok {
+		return false, nil
+	}
+	if _, err := os.Stat(path); err == nil && options.Files != restoreReplace {
+		return false, nil
+	}
+
+	zr, err := openArchive(sealed, backupFileFormat, options.Passphrase)
+	if err == errArchiveFormat {
+		return false, errors.New("not a sealed file")
+	} else if err != nil {
+		return false, err
+	}
+	defer zr.Close()
+	b, err := io.ReadAll(io.LimitReader(zr, backupMaxEntry))
+	if err != nil {
+		return false, err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0770); err != nil {
+		return false, err
+	}
+	return true, os.WriteFile(path, b, 0600)
+}
+
+// restoreBackupCall writes an archived call. A call of the same talkgroup
+// with the same timestamp is a conflict, kept or replaced as mode says.
+func (controller *Controller) restoreBackupCall(b *backupCall, mode string, result *RestoreCallsResult) error {
+	system, ok := controller.Systems.GetSystemByRef(b.SystemRef)
+	if !ok {
+		result.Unmatched++
+		return nil
+	}
+	talkgroup, ok := system.Talkgroups.GetTalkgroupByRef(b.TalkgroupRef)
+	if !ok {
+		result.Unmatched++
+		return nil
+	}
+
+	db := controller.Database
+	var existing uint64
+	err := db.Sql.QueryRow(`SELECT "callId" FROM "calls" WHERE "systemId" = $1 AND "talkgroupId" = $2 AND "timestamp" = $3 LIMIT 1`, system.Id, talkgroup.Id, b.Timestamp).Scan(&existing)
+	switch {
+	case err == nil && mode != restoreReplace:
+		result.Skipped++
+		return nil
+	case err == nil:
+		if err := controller.Calls.DeleteByIDs(db, []uint64{existing}); err != nil {
+			return err
+		}
+	case err != sql.ErrNoRows:
+		return err
+	}
+
+	call := b.call(system, talkgroup)
+	id, err := controller.Calls.WriteCall(call, db)
+	if err != nil {
+		return err
+	}
+	if call.AlertSummary != "" || call.TranscriptTranslation != "" {
+		if _, err := db.Sql.Exec(`UPDATE "calls" SET "alertSummary" = $2, "transcriptTranslation" = $3 WHERE "callId" = $1`, id, call.AlertSummary, call.TranscriptTranslation); err != nil {
+			return err
+		}
+	}
+
+	if existing > 0 {
+		result.Replaced++
+	} else {
+		result.Restored++
+	}
+	return nil
+}
+
+// backupDeadlineWriter extends the write deadline of a streamed backup at
+// every write, so long backups outlive the server write timeout while
+// stalled clients are still dropped.
+type backupDeadlineWriter struct {
+	w          io.Writer
+	controller *http.ResponseController
+}
+
+func (writer *backupDeadlineWriter) Write(p []byte) (int, error) {
+	writer.controller.SetWriteDeadline(time.Now().Add(callExportWriteWait))
+	return writer.w.Write(p)
+}
+
+// BackupHandler downloads (POST {"passphrase","credentials","from","to",
+// "systemRef"}) and restores (PUT with the archive as body, the
+// X-Archive-Passphrase header and the config, calls and files modes as query
+// parameters) backup archives. Calls are included when from or to is given.
+func (admin *Admin) BackupHandler(w http.ResponseWriter, r *http.Request) {
+	t := admin.GetAuthorization(r)
+	if !admin.ValidateToken(t) {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	writeError := func(status int, message string) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(map[string]string{"error": message})
+	}
+
+	switch r.Method {
+	case http.MethodPost:
+		var body struct {
+			Passphrase  string `json:"passphrase"`
+			Credentials bool   `json:"credentials"`
+			From        string `json:"from"`
+			To          string `json:"to"`
+			SystemRef   uint   `json:"systemRef"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		// Errors can't be reported once the archive streams
+		if len(body.Passphrase) < configArchiveMinPassphrase {
+			writeError(http.StatusBadRequest, fmt.Sprintf("the passphrase must be at least %d characters", configArchiveMinPassphrase))
+			return
+		}
+		options := BackupOptions{Passphrase: body.Passphrase, Credentials: body.Credentials}
+		systemRef := ""
+		if body.SystemRef > 0 {
+			systemRef = fmt.Sprint(body.SystemRef)
+		}
+		if err := backupCallOptions(&options, body.From, body.To, systemRef); err != nil {
+			writeError(http.StatusBadRequest, err.Error())
+			return
+		}
+		if options.SystemRef > 0 {
+			if _, ok := admin.Controller.Systems.GetSystemByRef(options.SystemRef); !ok {
+				writeError(http.StatusBadRequest, "unknown systemRef")
+				return
+			}
+		}
+
+		filename := fmt.Sprintf("thinline-radio-backup-%s.tlrbak", time.Now().Format("20060102-150405"))
+		w.Header().Set("Content-Type", "application/gzip")
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+
+		count, err := admin.Controller.WriteBackup(&backupDeadlineWriter{w: w, controller: http.NewResponseController(w)}, options)
+		if err != nil {
+			admin.Controller.Logs.LogEvent(LogLevelError, fmt.Sprintf("backup failed after %d calls: %v", count, err))
+			return
+		}
+		admin.Controller.Logs.LogEvent(LogLevelInfo, fmt.Sprintf("backup downloaded with %d calls (credentials: %v)", count, body.Credentials))
+
+	case http.MethodPut:
+		// The archive can hold a lot of audio
+		http.NewResponseController(w).SetReadDeadline(time.Time{})
+
+		query := r.URL.Query()
+		result, err := admin.RestoreBackup(r.Body, RestoreOptions{
+			Passphrase: r.Header.Get("X-Archive-Passphrase"),
+			Config:     query.Get("config"),
+			Calls:      query.Get("calls"),
+			Files:      query.Get("files"),
+		})
+
+		w.Header().Set("Content-Type", "application/json")
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]any{"error": err.Error(), "result": result})
+			return
+		}
+		json.NewEncoder(w).Encode(result)
+
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// runBackupCommand handles -backup and -restore.
+func runBackupCommand(controller *Controller, config *Config) error {
+	if err := controller.readAllData(false); err != nil {
+		return err
+	}
+
+	if config.backupFile != "" {
+		options := BackupOptions{Credentials: config.configCredentials}
+		systemRef := ""
+		if config.backupSystem > 0 {
+			systemRef = fmt.Sprint(config.backupSystem)
+		}
+		if err := backupCallOptions(&options, config.backupFrom, config.backupTo, systemRef); err != nil {
+			return err
+		}
+		passphrase, err := configArchivePassphrase(true)
+		if err != nil {
+			return err
+		}
+		options.Passphrase = passphrase
+
+		f, err := os.OpenFile(config.backupFile, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+		if err != nil {
+			return err
+		}
+		count, err := controller.WriteBackup(f, options)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			os.Remove(config.backupFile)
+			return err
+		}
+		fmt.Printf("backup with %d calls written to %s\n", count, config.backupFile)
+		return nil
+	}
+
+	f, err := os.Open(config.restoreFile)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	passphrase, err := configArchivePassphrase(false)
+	if err != nil {
+		return err
+	}
+	result, err := controller.Admin.RestoreBackup(f, RestoreOptions{
+		Passphrase: passphrase,
+		Config:     config.restoreConfig,
+		Calls:      config.restoreCalls,
+		Files:      config.restoreFiles,
+	})
+	if result != nil {
+		fmt.Printf("configuration: %s\n", result.Config)
+		fmt.Printf("files restored: %s; kept: %s\n", strings.Join(result.Files.Restored, ", "), strings.Join(result.Files.Skipped, ", "))
+		fmt.Printf("calls restored: %d, replaced: %d, skipped: %d, unmatched: %d\n", result.Calls.Restored, result.Calls.Replaced, result.Calls.Skipped, result.Calls.Unmatched)
+	}
+	if err != nil {
+		return err
+	}
+	if len(result.Files.Restored) > 0 {
+		fmt.Println("restart the server to apply the restored files")
+	}
+	return nil
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRestoreOptionsValidate(t *testing.T) {
+	options := RestoreOptions{}
+	if err := options.validate(); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+	if options.Config != "replace" || options.Calls != "skip" || options.Files != "skip" {
+		t.Fatalf("defaults: %+v", options)
+	}
+
+	for _, options := range []RestoreOptions{{Config: "merge"}, {Calls: "overwrite"}, {Files: "none"}} {
+		if err := options.validate(); err == nil {
+			t.Fatalf("accepted %+v", options)
+		}
+	}
+}
+
+func TestBackupCallRoundTrip(t *testing.T) {
+	system := &System{Id: 3, SystemRef: 7}
+	talkgroup := &Talkgroup{Id: 12, TalkgroupRef: 1001}
+	call := &Call{
+		System:        system,
+		Talkgroup:     talkgroup,
+		Timestamp:     time.UnixMilli(1760000000123),
+		SiteRef:       "012",
+		Frequency:     851012500,
+		Patches:       []uint{1002},
+		Units:         []CallUnit{{Id: 9, Offset: 1.5, UnitRef: 4401, Label: "Engine 4"}},
+		Transcript:    "engine four responding",
+		AlertSummary:  "Engine 4 responding",
+		AudioFilename: "call.m4a",
+		AudioMime:     "audio/mp4",
+		Audio:         []byte{1, 2, 3},
+	}
+
+	b, err := json.Marshal(newBackupCall(call))
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	archived := &backupCall{}
+	if err := json.Unmarshal(b, archived); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if archived.SystemRef != 7 || archived.TalkgroupRef != 1001 {
+		t.Fatalf("refs: %+v", archived)
+	}
+
+	restored := archived.call(system, talkgroup)
+	if !restored.Timestamp.Equal(call.Timestamp) || restored.SiteRef != "012" || restored.Frequency != call.Frequency || restored.AlertSummary != call.AlertSummary || !bytes.Equal(restored.Audio, call.Audio) {
+		t.Fatalf("restored: %+v", restored)
+	}
+	if len(restored.Units) != 1 || restored.Units[0].UnitRef != 4401 || restored.Units[0].Label != "Engine 4" || len(restored.Patches) != 1 {
+		t.Fatalf("units or patches: %+v %+v", restored.Units, restored.Patches)
+	}
+}
+
+func TestRestoreBackupFiles(t *testing.T) {
+	const passphrase = "correct horse battery"
+
+	dir := t.TempDir()
+	controller := &Controller{
+		Config:  &Config{BaseDir: dir, ConfigFile: "thinline-radio.ini"},
+		Options: NewOptions(),
+		Systems: NewSystems(),
+		Logs:    NewLogs(),
+	}
+	admin := &Admin{Controller: controller}
+
+	archive := func(files map[string]string, manifestFiles []BackupFile) *bytes.Buffer {
+		var buf bytes.Buffer
+		gz := gzip.NewWriter(&buf)
+		tw := tar.NewWriter(gz)
+		entry := func(name string, b []byte) {
+			tw.WriteHeader(&tar.Header{Name: name, Mode: 0600, Size: int64(len(b))})
+			tw.Write(b)
+		}
+		manifest, _ := json.Marshal(BackupManifest{Format: backupFormat, Version: backupVersion, Files: manifestFiles})
+		entry(backupManifestEntry, manifest)
+		entry(backupConfigEntry, []byte("{}"))
+		for name, content := range files {
+			sealed, err := sealArchive(backupFileFormat, []byte(content), passphrase)
+			if err != nil {
+				t.Fatalf("seal: %v", err)
+			}
+			entry(backupFilesDir+name, sealed)
+		}
+		entry(backupCallsDir+"1.json", []byte(`{"systemRef":1,"talkgroupRef":1}`))
+		tw.Close()
+		gz.Close()
+		return &buf
+	}
+
+	manifestFiles := []BackupFile{
+		{Name: "ini", Path: "/elsewhere/thinline-radio.ini"},
+		{Name: "emailLogo", Path: "logo.png"},
+		{Name: "favicon", Path: "../favicon.ico"},
+	}
+	files := map[string]string{"ini": "db_name = radio", "emailLogo": "png", "favicon": "ico"}
+
+	result, err := admin.RestoreBackup(archive(files, manifestFiles), RestoreOptions{Passphrase: passphrase, Config: "skip", Calls: "none"})
+	if err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	if result.Config != "skipped" || len(result.Files.Restored) != 2 || result.Calls != (RestoreCallsResult{}) {
+		t.Fatalf("result: %+v", result)
+	}
+
+	// The INI file goes where this server reads it, never outside the base directory
+	if b, err := os.ReadFile(filepath.Join(dir, "thinline-radio.ini")); err != nil || string(b) != "db_name = radio" {
+		t.Fatalf("ini not restored: %q %v", b, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "logo.png")); err != nil {
+		t.Fatalf("logo not restored: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(dir), "favicon.ico")); err == nil {
+		t.Fatalf("file restored outside the base directory")
+	}
+
+	files["ini"] = "db_name = other"
+	result, err = admin.RestoreBackup(archive(files, manifestFiles), RestoreOptions{Passphrase: passphrase, Config: "skip", Calls: "none"})
+	if err != nil || len(result.Files.Skipped) != 3 {
+		t.Fatalf("expected the existing files kept: %+v %v", result, err)
+	}
+	if b, _ := os.ReadFile(filepath.Join(dir, "thinline-radio.ini")); string(b) != "db_name = radio" {
+		t.Fatalf("existing ini overwritten: %q", b)
+	}
+
+	if _, err := admin.RestoreBackup(archive(files, manifestFiles), RestoreOptions{Passphrase: passphrase, Config: "skip", Calls: "none", Files: "replace"}); err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	if b, _ := os.ReadFile(filepath.Join(dir, "thinline-radio.ini")); string(b) != "db_name = other" {
+		t.Fatalf("ini not replaced: %q", b)
+	}
+
+	if _, err := admin.RestoreBackup(archive(files, manifestFiles), RestoreOptions{Passphrase: "wrong passphrase!", Config: "skip", Calls: "none", Files: "replace"}); err == nil {
+		t.Fatalf("wrong passphrase accepted")
+	}
+	if _, err := admin.RestoreBackup(bytes.NewReader([]byte("not an archive")), RestoreOptions{}); err == nil {
+		t.Fatalf("foreign file accepted")
+	}
+}
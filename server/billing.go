@@ -0,0 +1,397 @@
+// Copyright (C) 2026 Thinline Dynamic Solutions
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>
+
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/stripe/stripe-go/v76"
+	"github.com/stripe/stripe-go/v76/usagerecord"
+)
+
+var billingLog = Logger(LogCategoryBilling)
+
+// BillingPlan is a subscription tier. Users subscribed to one of its Stripe
+// prices get its entitlements; zero values leave that limit to the user and
+// group settings.
+type BillingPlan struct {
+	Id              string   `json:"id"`
+	Name            string   `json:"name"`
+	PriceIds        []string `json:"priceIds"`       // Stripe prices that grant the plan
+	MeteredPriceId  string   `json:"meteredPriceId"` // optional metered price billed per listening minute
+	ConnectionLimit uint     `json:"connectionLimit"`
+	MinDelay        uint     `json:"minDelay"` // minutes
+	Transcripts     bool     `json:"transcripts"`
+	ArchiveDays     uint     `json:"archiveDays"` // playback depth, 0 = unlimited
+}
+
+// validateBillingPlans rejects plans without an id and prices granting more
+// than one plan.
+func validateBillingPlans(plans []BillingPlan) error {
+	ids := map[string]bool{}
+	prices := map[string]string{}
+	for _, plan := range plans {
+		if strings.TrimSpace(plan.Id) == "" {
+			return fmt.Errorf("billing plan id is required")
+		}
+		if ids[plan.Id] {
+			return fmt.Errorf("duplicate billing plan %q", plan.Id)
+		}
+		ids[plan.Id] = true
+		for _, price := range append(append([]string{}, plan.PriceIds...), plan.MeteredPriceId) {
+			if price == "" {
+				continue
+			}
+			if other, ok := prices[price]; ok && other != plan.Id {
+				return fmt.Errorf("price %s is used by plans %q and %q", price, other, plan.Id)
+			}
+			prices[price] = plan.Id
+		}
+	}
+	return nil
+}
+
+// billingPlanForPrices returns the plan granted by the subscription prices and
+// the subscription item of its metered price, if any.
+func billingPlanForPrices(plans []BillingPlan, items []*stripe.SubscriptionItem) (*BillingPlan, string) {
+	for i := range plans {
+		plan := &plans[i]
+		granted := false
+		meteredItem := ""
+		for _, item := range items {
+			if item == nil || item.Price == nil {
+				continue
+			}
+			for _, price := range plan.PriceIds {
+				if item.Price.ID == price {
+					granted = true
+				}
+			}
+			if plan.MeteredPriceId != "" && item.Price.ID == plan.MeteredPriceId {
+				granted = true
+				meteredItem = item.ID
+			}
+		}
+		if granted {
+			return plan, meteredItem
+		}
+	}
+	return nil, ""
+}
+
+// BillingEntitlement is the plan a user is subscribed to, as last reported by
+// Stripe.
+type BillingEntitlement struct {
+	UserId             uint64 `json:"userId"`
+	PlanId             string `json:"planId"`
+	SubscriptionItemId string `json:"subscriptionItemId,omitempty"` // metered item
+	UsageReportedAt    int64  `json:"usageReportedAt"`              // unix ms
+	UpdatedAt          int64  `json:"updatedAt"`
+}
+
+// Billing keeps the entitlements of subscribed users, synced from Stripe
+// subscription webhooks, and reports metered usage.
+type Billing struct {
+	controller   *Controller
+	mutex        sync.RWMutex
+	entitlements map[uint64]*BillingEntitlement
+}
+
+func NewBilling(controller *Controller) *Billing {
+	return &Billing{
+		controller:   controller,
+		entitlements: map[uint64]*BillingEntitlement{},
+	}
+}
+
+func (billing *Billing) Load(db *Database) error {
+	billing.mutex.Lock()
+	defer billing.mutex.Unlock()
+
+	rows, err := db.Sql.Query(`SELECT "userId", "planId", "subscriptionItemId", "usageReportedAt", "updatedAt" FROM "billingEntitlements"`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	billing.entitlements = map[uint64]*BillingEntitlement{}
+	for rows.Next() {
+		entitlement := &BillingEntitlement{}
+		if err := rows.Scan(&entitlement.UserId, &entitlement.PlanId, &entitlement.SubscriptionItemId, &entitlement.UsageReportedAt, &entitlement.UpdatedAt); err != nil {
+			continue
+		}
+		billing.entitlements[entitlement.UserId] = entitlement
+	}
+	return rows.Err()
+}
+
+// Entitlement returns a copy of the user's entitlement, or nil.
+func (billing *Billing) Entitlement(userId uint64) *BillingEntitlement {
+	billing.mutex.RLock()
+	defer billing.mutex.RUnlock()
+
+	if entitlement, ok := billing.entitlements[userId]; ok {
+		e := *entitlement
+		return &e
+	}
+	return nil
+}
+
+// Plan returns the plan the user is subscribed to, or nil.
+func (billing *Billing) Plan(user *User) *BillingPlan {
+	if billing == nil || user == nil {
+		return nil
+	}
+	entitlement := billing.Entitlement(user.Id)
+	if entitlement == nil {
+		return nil
+	}
+	for _, plan := range billing.controller.Options.BillingPlans {
+		if plan.Id == entitlement.PlanId {
+			p := plan
+			return &p
+		}
+	}
+	return nil
+}
+
+// SyncSubscription updates the user's entitlement from a Stripe subscription.
+// Subscriptions that no longer grant access remove it.
+func (billing *Billing) SyncSubscription(user *User, sub *stripe.Subscription, status string) error {
+	if user == nil || sub == nil {
+		return nil
+	}
+
+	var plan *BillingPlan
+	var meteredItem string
+	switch status {
+	case "active", "trialing", "past_due":
+		if sub.Items != nil {
+			plan, meteredItem = billingPlanForPrices(billing.controller.Options.BillingPlans, sub.Items.Data)
+		}
+	}
+
+	db := billing.controller.Database
+	now := time.Now().UnixMilli()
+
+	billing.mutex.Lock()
+	defer billing.mutex.Unlock()
+
+	if plan == nil {
+		if _, ok := billing.entitlements[user.Id]; !ok {
+			return nil
+		}
+		if _, err := db.Sql.Exec(`DELETE FROM "billingEntitlements" WHERE "userId" = $1`, user.Id); err != nil {
+			return err
+		}
+		delete(billing.entitlements, user.Id)
+		billing.controller.Logs.LogEvent(LogLevelInfo, fmt.Sprintf("billing: %s no longer has a plan (subscription %s)", user.Email, status))
+		return nil
+	}
+
+	entitlement := &BillingEntitlement{UserId: user.Id, PlanId: plan.Id, SubscriptionItemId: meteredItem, UsageReportedAt: now, UpdatedAt: now}
+	if current, ok := billing.entitlements[user.Id]; ok && current.SubscriptionItemId == meteredItem {
+		entitlement.UsageReportedAt = current.UsageReportedAt
+	}
+
+	if _, err := db.Sql.Exec(
+		`INSERT INTO "billingEntitlements" ("userId", "planId", "subscriptionItemId", "usageReportedAt", "updatedAt") VALUES ($1, $2, $3, $4, $5) `+
+			`ON CONFLICT ("userId") DO UPDATE SET "planId" = EXCLUDED."planId", "subscriptionItemId" = EXCLUDED."subscriptionItemId", "usageReportedAt" = EXCLUDED."usageReportedAt", "updatedAt" = EXCLUDED."updatedAt"`,
+		entitlement.UserId, entitlement.PlanId, entitlement.SubscriptionItemId, entitlement.UsageReportedAt, entitlement.UpdatedAt,
+	); err != nil {
+		return err
+	}
+
+	if current, ok := billing.entitlements[user.Id]; !ok || current.PlanId != plan.Id {
+		billing.controller.Logs.LogEvent(LogLevelInfo, fmt.Sprintf("billing: %s is now on the %s plan", user.Email, plan.Name))
+	}
+	billing.entitlements[user.Id] = entitlement
+	return nil
+}
+
+// ConnectionLimit caps the user's connection limit to their plan's.
+func (billing *Billing) ConnectionLimit(user *User, limit uint) uint {
+	if plan := billing.Plan(user); plan != nil && plan.ConnectionLimit > 0 && (limit == 0 || limit > plan.ConnectionLimit) {
+		return plan.ConnectionLimit
+	}
+	return limit
+}
+
+// MinDelay raises the user's delay to their plan's minimum.
+func (billing *Billing) MinDelay(user *User, delay uint) uint {
+	if plan := billing.Plan(user); plan != nil && delay < plan.MinDelay {
+		return plan.MinDelay
+	}
+	return delay
+}
+
+// TranscriptsAllowed reports whether the user's plan includes transcripts.
+// Users without a plan keep the server's behavior.
+func (billing *Billing) TranscriptsAllowed(user *User) bool {
+	plan := billing.Plan(user)
+	return plan == nil || plan.Transcripts
+}
+
+// ArchiveAllows reports whether a call recorded at timestamp is within the
+// playback depth of the user's plan.
+func (billing *Billing) ArchiveAllows(user *User, timestamp time.Time) bool {
+	plan := billing.Plan(user)
+	if plan == nil || plan.ArchiveDays == 0 || timestamp.IsZero() {
+		return true
+	}
+	return !timestamp.Before(time.Now().AddDate(0, 0, -int(plan.ArchiveDays)))
+}
+
+// stripTranscript removes the transcripts of a call sent to a user whose plan
+// does not include them.
+func stripTranscript(call *Call) {
+	call.Transcript = ""
+	call.ReviewedTranscript = ""
+	call.TranscriptTranslation = ""
+	call.TranscriptConfidence = 0
+}
+
+// listeningMinutes sums the time the user's sessions were connected between
+// from and to (unix ms), in whole minutes.
+func (billing *Billing) listeningMinutes(userId uint64, from int64, to int64) (int64, error) {
+	var ms sql.NullInt64
+	err := billing.controller.Database.Sql.QueryRow(
+		`SELECT SUM(LEAST(CASE WHEN "endedAt" > 0 THEN "endedAt" ELSE "lastSeen" END, $3) - GREATEST("createdAt", $2)) FROM "sessions" `+
+			`WHERE "userId" = $1 AND "createdAt" < $3 AND (CASE WHEN "endedAt" > 0 THEN "endedAt" ELSE "lastSeen" END) > $2`,
+		userId, from, to,
+	).Scan(&ms)
+	if err != nil || !ms.Valid || ms.Int64 <= 0 {
+		return 0, err
+	}
+	return ms.Int64 / 60000, nil
+}
+
+// ReportUsage reports the listening minutes of users on a metered plan to
+// Stripe. Minutes not reported yet carry over to the next run.
+func (billing *Billing) ReportUsage() {
+	if billing.controller.Options.StripeSecretKey == "" {
+		return
+	}
+
+	billing.mutex.RLock()
+	metered := []BillingEntitlement{}
+	for _, entitlement := range billing.entitlements {
+		if entitlement.SubscriptionItemId != "" {
+			metered = append(metered, *entitlement)
+		}
+	}
+	billing.mutex.RUnlock()
+
+	stripe.Key = billing.controller.Options.StripeSecretKey
+	now := time.Now()
+
+	for _, entitlement := range metered {
+		minutes, err := billing.listeningMinutes(entitlement.UserId, entitlement.UsageReportedAt, now.UnixMilli())
+		if err != nil {
+			billingLog.Error(fmt.Sprintf("billing: listening minutes of user %d: %v", entitlement.UserId, err))
+			continue
+		}
+		if minutes == 0 {
+			continue
+		}
+
+		if _, err := usagerecord.New(&stripe.UsageRecordParams{
+			SubscriptionItem: stripe.String(entitlement.SubscriptionItemId),
+			Quantity:         stripe.Int64(minutes),
+			Timestamp:        stripe.Int64(now.Unix()),
+			Action:           stripe.String(stripe.UsageRecordActionIncrement),
+		}); err != nil {
+			billing.controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("billing: failed to report %d minutes for user %d: %v", minutes, entitlement.UserId, err))
+			continue
+		}
+
+		reportedAt := entitlement.UsageReportedAt + minutes*60000
+		if _, err := billing.controller.Database.Sql.Exec(`UPDATE "billingEntitlements" SET "usageReportedAt" = $1 WHERE "userId" = $2`, reportedAt, entitlement.UserId); err != nil {
+			billingLog.Error(fmt.Sprintf("billing: usage report time of user %d: %v", entitlement.UserId, err))
+		}
+
+		billing.mutex.Lock()
+		if current, ok := billing.entitlements[entitlement.UserId]; ok && current.SubscriptionItemId == entitlement.SubscriptionItemId {
+			current.UsageReportedAt = reportedAt
+		}
+		billing.mutex.Unlock()
+	}
+}
+
+// BillingPlanHandler returns the listener's plan, the available plans and
+// their entitlements. The customer portal is at /api/billing/portal.
+//
+//	GET /api/billing/plan?pin=...
+func (api *Api) BillingPlanHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		api.exitWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	pin := r.URL.Query().Get("pin")
+	if pin == "" {
+		if authHeader := r.Header.Get("Authorization"); strings.HasPrefix(authHeader, "Bearer ") {
+			pin = strings.TrimPrefix(authHeader, "Bearer ")
+		}
+	}
+	if pin == "" {
+		api.exitWithError(w, http.StatusUnauthorized, "PIN required")
+		return
+	}
+
+	user := api.userByPin(r, pin)
+	if user == nil {
+		api.exitWithError(w, http.StatusUnauthorized, "Invalid PIN")
+		return
+	}
+
+	plans := []map[string]any{}
+	for _, plan := range api.Controller.Options.BillingPlans {
+		plans = append(plans, map[string]any{
+			"id":              plan.Id,
+			"name":            plan.Name,
+			"priceIds":        plan.PriceIds,
+			"metered":         plan.MeteredPriceId != "",
+			"connectionLimit": plan.ConnectionLimit,
+			"minDelay":        plan.MinDelay,
+			"transcripts":     plan.Transcripts,
+			"archiveDays":     plan.ArchiveDays,
+		})
+	}
+
+	response := map[string]any{
+		"plans":              plans,
+		"subscriptionStatus": user.SubscriptionStatus,
+		"portalAvailable":    user.StripeCustomerId != "" && api.Controller.Options.StripeSecretKey != "",
+	}
+	if plan := api.Controller.Billing.Plan(user); plan != nil {
+		response["plan"] = plan.Id
+	}
+	if entitlement := api.Controller.Billing.Entitlement(user.Id); entitlement != nil && entitlement.SubscriptionItemId != "" {
+		if minutes, err := api.Controller.Billing.listeningMinutes(user.Id, entitlement.UsageReportedAt, time.Now().UnixMilli()); err == nil {
+			response["unreportedMinutes"] = minutes
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stripe/stripe-go/v76"
+)
+
+func TestValidateBillingPlans(t *testing.T) {
+	plans := []BillingPlan{
+		{Id: "basic", PriceIds: []string{"price_basic_m", "price_basic_y"}},
+		{Id: "pro", PriceIds: []string{"price_pro"}, MeteredPriceId: "price_pro_minutes"},
+	}
+	if err := validateBillingPlans(plans); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+	if err := validateBillingPlans(append(plans, BillingPlan{Id: "basic"})); err == nil {
+		t.Fatalf("duplicate plan accepted")
+	}
+	if err := validateBillingPlans(append(plans, BillingPlan{Id: "other", PriceIds: []string{"price_pro"}})); err == nil {
+		t.Fatalf("shared price accepted")
+	}
+	if err := validateBillingPlans([]BillingPlan{{Name: "No id"}}); err == nil {
+		t.Fatalf("plan without id accepted")
+	}
+}
+
+func TestBillingPlanForPrices(t *testing.T) {
+	plans := []BillingPlan{
+		{Id: "basic", PriceIds: []string{"price_basic"}},
+		{Id: "pro", PriceIds: []string{"price_pro"}, MeteredPriceId: "price_pro_minutes"},
+	}
+	items := []*stripe.SubscriptionItem{
+		{ID: "si_1", Price: &stripe.Price{ID: "price_pro"}},
+		{ID: "si_2", Price: &stripe.Price{ID: "price_pro_minutes"}},
+	}
+	plan, metered := billingPlanForPrices(plans, items)
+	if plan == nil || plan.Id != "pro" || metered != "si_2" {
+		t.Fatalf("plan = %v, metered = %q", plan, metered)
+	}
+	if plan, _ := billingPlanForPrices(plans, []*stripe.SubscriptionItem{{ID: "si_3", Price: &stripe.Price{ID: "price_other"}}}); plan != nil {
+		t.Fatalf("unknown price granted %v", plan)
+	}
+}
+
+func TestBillingGates(t *testing.T) {
+	controller := &Controller{Options: &Options{BillingPlans: []BillingPlan{
+		{Id: "basic", ConnectionLimit: 1, MinDelay: 5, ArchiveDays: 7},
+		{Id: "pro", Transcripts: true},
+	}}}
+	billing := NewBilling(controller)
+	billing.entitlements[1] = &BillingEntitlement{UserId: 1, PlanId: "basic"}
+	billing.entitlements[2] = &BillingEntitlement{UserId: 2, PlanId: "pro"}
+
+	basic, pro, none := &User{Id: 1}, &User{Id: 2}, &User{Id: 3}
+
+	if billing.ConnectionLimit(basic, 0) != 1 || billing.ConnectionLimit(basic, 3) != 1 || billing.ConnectionLimit(pro, 3) != 3 || billing.ConnectionLimit(none, 0) != 0 {
+		t.Fatalf("connection limits not gated")
+	}
+	if billing.MinDelay(basic, 0) != 5 || billing.MinDelay(basic, 10) != 10 || billing.MinDelay(none, 0) != 0 {
+		t.Fatalf("delay minimum not applied")
+	}
+	if billing.TranscriptsAllowed(basic) || !billing.TranscriptsAllowed(pro) || !billing.TranscriptsAllowed(none) {
+		t.Fatalf("transcript access not gated")
+	}
+	old := time.Now().AddDate(0, 0, -30)
+	if billing.ArchiveAllows(basic, old) || !billing.ArchiveAllows(basic, time.Now()) || !billing.ArchiveAllows(pro, old) {
+		t.Fatalf("archive depth not gated")
+	}
+
+	var unconfigured *Billing
+	if unconfigured.ConnectionLimit(basic, 2) != 2 || !unconfigured.TranscriptsAllowed(basic) {
+		t.Fatalf("nil billing changed limits")
+	}
+}
@@ -0,0 +1,393 @@
+// Copyright (C) 2026 Thinline Dynamic Solutions
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/stripe/stripe-go/v76"
+	"github.com/stripe/stripe-go/v76/promotioncode"
+)
+
+// subscriptionStatusComp marks a complimentary account granted by an admin.
+// Its end, like a trial's, is kept in AccountExpiresAt.
+const subscriptionStatusComp = "comp"
+
+// trialLapseGrace leaves Stripe time to send the webhook ending a trial
+// before the scheduler downgrades the account itself.
+const trialLapseGrace = 24 * time.Hour
+
+// BillingAuditEntry records a change an admin or the scheduler made to a
+// user's billing.
+type BillingAuditEntry struct {
+	Id        uint64 `json:"id"`
+	UserId    uint64 `json:"userId"`
+	Action    string `json:"action"` // comp_granted, comp_revoked, comp_expired, trial_expired
+	Actor     string `json:"actor"`
+	Detail    string `json:"detail"`
+	CreatedAt int64  `json:"createdAt"`
+}
+
+// trackTrial keeps the end of a trialing subscription in AccountExpiresAt and
+// clears it once the subscription leaves the trial.
+func trackTrial(user *User, sub *stripe.Subscription, status string) {
+	if user == nil || sub == nil || sub.TrialEnd <= 0 {
+		return
+	}
+	if status == "trialing" {
+		user.AccountExpiresAt = uint64(sub.TrialEnd)
+	} else if user.AccountExpiresAt == uint64(sub.TrialEnd) {
+		user.AccountExpiresAt = 0
+	}
+}
+
+// lapsedAccount returns the audit action of a trial or comp account that
+// expired at now, or "" if the account is still current.
+func lapsedAccount(user *User, now time.Time) string {
+	if user == nil || user.AccountExpiresAt == 0 {
+		return ""
+	}
+	expiresAt := time.Unix(int64(user.AccountExpiresAt), 0)
+	switch user.SubscriptionStatus {
+	case subscriptionStatusComp:
+		if !now.Before(expiresAt) {
+			return "comp_expired"
+		}
+	case "trialing":
+		if !now.Before(expiresAt.Add(trialLapseGrace)) {
+			return "trial_expired"
+		}
+	}
+	return ""
+}
+
+// resolvePromotionCode returns the id of the active Stripe promotion code a
+// customer typed at checkout.
+func resolvePromotionCode(code string) (string, error) {
+	params := &stripe.PromotionCodeListParams{
+		Code:   stripe.String(strings.TrimSpace(code)),
+		Active: stripe.Bool(true),
+	}
+	params.Limit = stripe.Int64(1)
+	iter := promotioncode.List(params)
+	for iter.Next() {
+		return iter.PromotionCode().ID, nil
+	}
+	if err := iter.Err(); err != nil {
+		return "", err
+	}
+	return "", fmt.Errorf("promo code %q is not valid", code)
+}
+
+// Audit records a billing change and logs it.
+func (billing *Billing) Audit(user *User, action string, actor string, detail string) {
+	if billing == nil || user == nil {
+		return
+	}
+	if _, err := billing.controller.Database.Sql.Exec(
+		`INSERT INTO "billingAudit" ("userId", "action", "actor", "detail", "createdAt") VALUES ($1, $2, $3, $4, $5)`,
+		user.Id, action, actor, detail, time.Now().UnixMilli(),
+	); err != nil {
+		billing.controller.Logs.LogEvent(LogLevelError, fmt.Sprintf("billing: audit of %s for %s: %v", action, user.Email, err))
+	}
+	message := fmt.Sprintf("billing: %s for %s by %s", action, user.Email, actor)
+	if detail != "" {
+		message += " (" + detail + ")"
+	}
+	billing.controller.Logs.LogEvent(LogLevelInfo, message)
+}
+
+// AuditEntries returns the most recent audit entries, of one user if userId
+// is not zero.
+func (billing *Billing) AuditEntries(userId uint64, limit int) ([]BillingAuditEntry, error) {
+	query := `SELECT "auditId", "userId", "action", "actor", "detail", "createdAt" FROM "billingAudit"`
+	args := queryArgs{}
+	if userId > 0 {
+		query += ` WHERE "userId" = ` + args.add(userId)
+	}
+	query += ` ORDER BY "auditId" DESC LIMIT ` + args.add(limit)
+
+	rows, err := billing.controller.Database.Sql.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []BillingAuditEntry{}
+	for rows.Next() {
+		entry := BillingAuditEntry{}
+		if err := rows.Scan(&entry.Id, &entry.UserId, &entry.Action, &entry.Actor, &entry.Detail, &entry.CreatedAt); err != nil {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
+
+// setPlan gives the user a plan outside of Stripe, or removes their plan if
+// planId is empty.
+func (billing *Billing) setPlan(user *User, planId string) error {
+	db := billing.controller.Database
+
+	billing.mutex.Lock()
+	defer billing.mutex.Unlock()
+
+	if planId == "" {
+		if _, ok := billing.entitlements[user.Id]; !ok {
+			return nil
+		}
+		if _, err := db.Sql.Exec(`DELETE FROM "billingEntitlements" WHERE "userId" = $1`, user.Id); err != nil {
+			return err
+		}
+		delete(billing.entitlements, user.Id)
+		return nil
+	}
+
+	now := time.Now().UnixMilli()
+	if _, err := db.Sql.Exec(
+		`INSERT INTO "billingEntitlements" ("userId", "planId", "subscriptionItemId", "usageReportedAt", "updatedAt") VALUES ($1, $2, '', $3, $3) `+
+			`ON CONFLICT ("userId") DO UPDATE SET "planId" = EXCLUDED."planId", "subscriptionItemId" = '', "updatedAt" = EXCLUDED."updatedAt"`,
+		user.Id, planId, now,
+	); err != nil {
+		return err
+	}
+	billing.entitlements[user.Id] = &BillingEntitlement{UserId: user.Id, PlanId: planId, UsageReportedAt: now, UpdatedAt: now}
+	return nil
+}
+
+func (billing *Billing) hasPlan(planId string) bool {
+	for _, plan := range billing.controller.Options.BillingPlans {
+		if plan.Id == planId {
+			return true
+		}
+	}
+	return false
+}
+
+// GrantComp gives the user a complimentary account until expiresAt (unix
+// seconds, 0 = no end), on planId if set.
+func (billing *Billing) GrantComp(user *User, planId string, expiresAt uint64, actor string, note string) error {
+	if planId != "" && !billing.hasPlan(planId) {
+		return fmt.Errorf("unknown billing plan %q", planId)
+	}
+	if expiresAt > 0 && expiresAt <= uint64(time.Now().Unix()) {
+		return fmt.Errorf("expiresAt is in the past")
+	}
+
+	user.SubscriptionStatus = subscriptionStatusComp
+	user.AccountExpiresAt = expiresAt
+	user.PinExpiresAt = expiresAt
+	if err := billing.saveUser(user); err != nil {
+		return err
+	}
+	if err := billing.setPlan(user, planId); err != nil {
+		return err
+	}
+
+	detail := "no expiry"
+	if expiresAt > 0 {
+		detail = "until " + time.Unix(int64(expiresAt), 0).UTC().Format(time.RFC3339)
+	}
+	if planId != "" {
+		detail += ", plan " + planId
+	}
+	if note != "" {
+		detail += ", " + note
+	}
+	billing.Audit(user, "comp_granted", actor, detail)
+	return nil
+}
+
+// RevokeComp ends the user's complimentary account now.
+func (billing *Billing) RevokeComp(user *User, actor string, note string) error {
+	if user.SubscriptionStatus != subscriptionStatusComp {
+		return fmt.Errorf("%s does not have a comp account", user.Email)
+	}
+	if err := billing.downgrade(user); err != nil {
+		return err
+	}
+	billing.Audit(user, "comp_revoked", actor, note)
+	return nil
+}
+
+// downgrade removes the access a lapsed trial or comp account had: the plan
+// is removed, the PIN of billed users expires and their sessions end.
+func (billing *Billing) downgrade(user *User) error {
+	user.SubscriptionStatus = "expired"
+	user.AccountExpiresAt = 0
+	if group := billing.controller.UserGroups.Get(user.UserGroupId); group != nil && group.BillingEnabled {
+		user.PinExpiresAt = uint64(time.Now().Unix())
+	} else {
+		user.PinExpiresAt = 0
+	}
+	if err := billing.saveUser(user); err != nil {
+		return err
+	}
+	if err := billing.setPlan(user, ""); err != nil {
+		return err
+	}
+	if user.PinExpiresAt > 0 && billing.controller.Sessions != nil {
+		billing.controller.Sessions.RevokeAll(user.Id)
+	}
+	return nil
+}
+
+func (billing *Billing) saveUser(user *User) error {
+	billing.controller.Users.Update(user)
+	if err := billing.controller.Users.Write(billing.controller.Database); err != nil {
+		return err
+	}
+	billing.controller.SyncConfigToFile()
+	return nil
+}
+
+// ExpireLapsed downgrades trial and comp accounts whose end has passed.
+// Trials get trialLapseGrace for Stripe to report the subscription's new
+// status first.
+func (billing *Billing) ExpireLapsed() {
+	if billing == nil {
+		return
+	}
+	now := time.Now()
+	for _, user := range billing.controller.Users.GetAllUsers() {
+		action := lapsedAccount(user, now)
+		if action == "" {
+			continue
+		}
+		if err := billing.downgrade(user); err != nil {
+			billing.controller.Logs.LogEvent(LogLevelError, fmt.Sprintf("billing: downgrade of %s: %v", user.Email, err))
+			continue
+		}
+		billing.Audit(user, action, "scheduler", "")
+	}
+}
+
+// adminActor names the admin a token belongs to in the billing audit.
+func (admin *Admin) adminActor(sToken string) string {
+	if claims, ok := admin.tokenClaims(sToken); ok && claims.Subject != "" {
+		if user := admin.tokenUser(claims); user != nil {
+			return user.Email
+		}
+	}
+	return "admin"
+}
+
+// BillingCompHandler grants and revokes complimentary accounts and lists the
+// billing audit.
+//
+//	GET    /api/admin/billing/comp[?userId=]
+//	POST   /api/admin/billing/comp  {"userId":1,"planId":"pro","expiresAt":1767225600,"days":30,"note":"..."}
+//	DELETE /api/admin/billing/comp?userId=1[&note=]
+func (admin *Admin) BillingCompHandler(w http.ResponseWriter, r *http.Request) {
+	t := admin.GetAuthorization(r)
+	if !admin.ValidatePermission(t, PermissionManageUsers) {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	writeError := func(status int, err error) {
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+	}
+
+	billing := admin.Controller.Billing
+
+	targetUser := func(id uint64) *User {
+		user := admin.Controller.Users.GetUserById(id)
+		if user == nil || !admin.tenantAllowsUser(t, user) {
+			return nil
+		}
+		return user
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		var userId uint64
+		if s := r.URL.Query().Get("userId"); s != "" {
+			id, err := strconv.ParseUint(s, 10, 64)
+			if err != nil || targetUser(id) == nil {
+				writeError(http.StatusNotFound, fmt.Errorf("user not found"))
+				return
+			}
+			userId = id
+		}
+		entries, err := billing.AuditEntries(userId, 500)
+		if err != nil {
+			writeError(http.StatusInternalServerError, err)
+			return
+		}
+		if userId == 0 && admin.tokenTenant(t) != nil {
+			scoped := []BillingAuditEntry{}
+			for _, entry := range entries {
+				if targetUser(entry.UserId) != nil {
+					scoped = append(scoped, entry)
+				}
+			}
+			entries = scoped
+		}
+		json.NewEncoder(w).Encode(map[string]any{"entries": entries})
+
+	case http.MethodPost:
+		var request struct {
+			UserId    uint64 `json:"userId"`
+			PlanId    string `json:"planId"`
+			ExpiresAt uint64 `json:"expiresAt"`
+			Days      uint   `json:"days"`
+			Note      string `json:"note"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			writeError(http.StatusBadRequest, fmt.Errorf("invalid request: %v", err))
+			return
+		}
+		user := targetUser(request.UserId)
+		if user == nil {
+			writeError(http.StatusNotFound, fmt.Errorf("user not found"))
+			return
+		}
+		expiresAt := request.ExpiresAt
+		if expiresAt == 0 && request.Days > 0 {
+			expiresAt = uint64(time.Now().AddDate(0, 0, int(request.Days)).Unix())
+		}
+		if err := billing.GrantComp(user, request.PlanId, expiresAt, admin.adminActor(t), strings.TrimSpace(request.Note)); err != nil {
+			writeError(http.StatusBadRequest, err)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{"userId": user.Id, "subscriptionStatus": user.SubscriptionStatus, "accountExpiresAt": user.AccountExpiresAt, "planId": request.PlanId})
+
+	case http.MethodDelete:
+		id, err := strconv.ParseUint(r.URL.Query().Get("userId"), 10, 64)
+		user := targetUser(id)
+		if err != nil || user == nil {
+			writeError(http.StatusNotFound, fmt.Errorf("user not found"))
+			return
+		}
+		if err := billing.RevokeComp(user, admin.adminActor(t), strings.TrimSpace(r.URL.Query().Get("note"))); err != nil {
+			writeError(http.StatusBadRequest, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
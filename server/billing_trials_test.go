@@ -0,0 +1,54 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stripe/stripe-go/v76"
+)
+
+func TestTrackTrial(t *testing.T) {
+	user := &User{}
+	sub := &stripe.Subscription{TrialEnd: 1767225600}
+
+	trackTrial(user, sub, "trialing")
+	if user.AccountExpiresAt != 1767225600 {
+		t.Fatalf("trialing: accountExpiresAt = %d", user.AccountExpiresAt)
+	}
+
+	trackTrial(user, sub, "active")
+	if user.AccountExpiresAt != 0 {
+		t.Fatalf("active: accountExpiresAt = %d", user.AccountExpiresAt)
+	}
+
+	user.AccountExpiresAt = 42
+	trackTrial(user, sub, "active")
+	if user.AccountExpiresAt != 42 {
+		t.Fatalf("an expiry set by an admin was cleared")
+	}
+}
+
+func TestLapsedAccount(t *testing.T) {
+	now := time.Unix(1767225600, 0)
+	past := uint64(now.Add(-time.Hour).Unix())
+	future := uint64(now.Add(time.Hour).Unix())
+
+	cases := []struct {
+		status    string
+		expiresAt uint64
+		want      string
+	}{
+		{subscriptionStatusComp, past, "comp_expired"},
+		{subscriptionStatusComp, future, ""},
+		{subscriptionStatusComp, 0, ""},
+		{"trialing", past, ""}, // within the grace for the webhook
+		{"trialing", uint64(now.Add(-trialLapseGrace).Unix()), "trial_expired"},
+		{"active", past, ""},
+	}
+	for _, c := range cases {
+		user := &User{SubscriptionStatus: c.status, AccountExpiresAt: c.expiresAt}
+		if got := lapsedAccount(user, now); got != c.want {
+			t.Fatalf("%s expiring %d: got %q, want %q", c.status, c.expiresAt, got, c.want)
+		}
+	}
+}
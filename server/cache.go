@@ -40,6 +40,9 @@ type UserAlertPreference struct {
 	ToneSetSounds        map[string]string
 	PagerAlert           bool
 	ToneSetPagerAlerts   map[string]bool
+	EmailAlerts          bool // Instant email for pager alerts
+	EmailDigest          bool // Alerts listed in the daily email digest
+	QuietHours           *QuietHours // Overrides the user's own quiet hours for this talkgroup, nil = not set
 	ToneDetectionEnabled bool // From talkgroup config
 }
 
@@ -78,7 +81,7 @@ func (cache *PreferencesCache) Read(db *Database) error {
 	query := `SELECT p."userId", p."systemId", p."talkgroupId", p."alertEnabled", 
 	          p."toneAlerts", p."keywordAlerts", p."keywords", p."keywordListIds", 
 	          p."toneSetIds", p."notificationSound", p."toneSetSounds",
-	          p."pagerAlert", p."toneSetPagerAlerts",
+	          p."pagerAlert", p."toneSetPagerAlerts", p."emailAlerts", p."emailDigest", p."quietHours",
 	          COALESCE(t."toneDetectionEnabled", false) as "toneDetectionEnabled"
 	          FROM "userAlertPreferences" p
 	          LEFT JOIN "talkgroups" t ON t."talkgroupId" = p."talkgroupId"
@@ -95,7 +98,7 @@ func (cache *PreferencesCache) Read(db *Database) error {
 	for rows.Next() {
 		pref := &UserAlertPreference{}
 		var keywordsJson, keywordListIdsJson, toneSetIdsJson string
-		var notificationSound, toneSetSoundsJson, toneSetPagerAlertsJson string
+		var notificationSound, toneSetSoundsJson, toneSetPagerAlertsJson, quietHoursJson string
 
 		if err := rows.Scan(
 			&pref.UserId,
@@ -111,6 +114,9 @@ func (cache *PreferencesCache) Read(db *Database) error {
 			&toneSetSoundsJson,
 			&pref.PagerAlert,
 			&toneSetPagerAlertsJson,
+			&pref.EmailAlerts,
+			&pref.EmailDigest,
+			&quietHoursJson,
 			&pref.ToneDetectionEnabled,
 		); err != nil {
 			continue
@@ -142,6 +148,11 @@ func (cache *PreferencesCache) Read(db *Database) error {
 				pref.ToneSetPagerAlerts = nil
 			}
 		}
+		if quietHoursJson != "" {
+			if err := json.Unmarshal([]byte(quietHoursJson), &pref.QuietHours); err != nil {
+				pref.QuietHours = nil
+			}
+		}
 		pref.NotificationSound = notificationSound
 
 		// Store in byUser map using numeric key
@@ -151,8 +162,11 @@ func (cache *PreferencesCache) Read(db *Database) error {
 		}
 		cache.byUser[pref.UserId][key] = pref
 
-		// Store in byTalkgroup reverse index
-		cache.byTalkgroup[key] = append(cache.byTalkgroup[key], pref.UserId)
+		// Store in byTalkgroup reverse index. The user's own row (system and
+		// talkgroup 0) only holds settings such as quiet hours.
+		if key != 0 {
+			cache.byTalkgroup[key] = append(cache.byTalkgroup[key], pref.UserId)
+		}
 
 		count++
 	}
@@ -0,0 +1,412 @@
+// Copyright (C) 2026 Thinline Dynamic Solutions
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>
+
+package main
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const cadMaxBody = 1 << 20
+
+// CADConfig accepts incidents from a computer-aided dispatch system, such as
+// PulsePoint or Active911 webhooks, and links them to the radio traffic of
+// the talkgroups and tone sets they are dispatched on.
+type CADConfig struct {
+	Enabled bool        `json:"enabled"`
+	Fields  CADFieldMap `json:"fields"`
+	Links   []CADLink   `json:"links"`
+}
+
+// CADFieldMap names where each field is in the webhook JSON, as a dotted
+// path. Array elements are selected by index, or all of them by "*", e.g.
+// "incident.units.*.name".
+type CADFieldMap struct {
+	Id           string `json:"id"`           // default "id"
+	IncidentType string `json:"incidentType"` // default "type"
+	Address      string `json:"address"`      // default "address"
+	Units        string `json:"units"`        // default "units", a list or comma separated
+	Latitude     string `json:"latitude"`     // default "latitude"
+	Longitude    string `json:"longitude"`    // default "longitude"
+	Description  string `json:"description"`  // default "description"
+	Time         string `json:"time"`         // default "time", RFC 3339 or Unix seconds or milliseconds
+}
+
+// CADLink links the CAD incidents of a type to talkgroups and tone sets.
+type CADLink struct {
+	Match         string   `json:"match"` // text in the incident type, empty for every incident
+	SystemRef     uint     `json:"systemRef"`
+	TalkgroupRefs []uint   `json:"talkgroupRefs"`
+	ToneSetIds    []string `json:"toneSetIds"`
+}
+
+func (fields CADFieldMap) withDefaults() CADFieldMap {
+	defaults := map[*string]string{
+		&fields.Id:           "id",
+		&fields.IncidentType: "type",
+		&fields.Address:      "address",
+		&fields.Units:        "units",
+		&fields.Latitude:     "latitude",
+		&fields.Longitude:    "longitude",
+		&fields.Description:  "description",
+		&fields.Time:         "time",
+	}
+	for field, value := range defaults {
+		if strings.TrimSpace(*field) == "" {
+			*field = value
+		}
+	}
+	return fields
+}
+
+// CADIncident is an incident received from CAD, stored with the incident
+// it opened or was linked to.
+type CADIncident struct {
+	Id           string   `json:"id"`
+	IncidentType string   `json:"incidentType,omitempty"`
+	Address      string   `json:"address,omitempty"`
+	Units        []string `json:"units,omitempty"`
+	Latitude     float64  `json:"latitude,omitempty"`
+	Longitude    float64  `json:"longitude,omitempty"`
+	Description  string   `json:"description,omitempty"`
+	Time         int64    `json:"time"`
+	ReceivedAt   int64    `json:"receivedAt"`
+}
+
+// cadLookup returns the values at a dotted path.
+func cadLookup(value any, path string) []any {
+	values := []any{value}
+	for _, key := range strings.Split(path, ".") {
+		next := []any{}
+		for _, v := range values {
+			switch node := v.(type) {
+			case map[string]any:
+				if child, ok := node[key]; ok && child != nil {
+					next = append(next, child)
+				}
+			case []any:
+				if key == "*" {
+					next = append(next, node...)
+				} else if i, err := strconv.Atoi(key); err == nil && i >= 0 && i < len(node) {
+					next = append(next, node[i])
+				}
+			}
+		}
+		values = next
+	}
+	return values
+}
+
+// cadStrings returns the scalar values at a path as trimmed strings. Lists
+// are flattened.
+func cadStrings(value any, path string) []string {
+	strs := []string{}
+	var add func(v any)
+	add = func(v any) {
+		switch v := v.(type) {
+		case string:
+			if s := strings.TrimSpace(v); s != "" {
+				strs = append(strs, s)
+			}
+		case json.Number:
+			strs = append(strs, v.String())
+		case bool:
+			strs = append(strs, strconv.FormatBool(v))
+		case []any:
+			for _, item := range v {
+				add(item)
+			}
+		}
+	}
+	for _, v := range cadLookup(value, path) {
+		add(v)
+	}
+	return strs
+}
+
+func cadString(value any, path string) string {
+	if strs := cadStrings(value, path); len(strs) > 0 {
+		return strs[0]
+	}
+	return ""
+}
+
+// parseCADTime reads RFC 3339 times and Unix times in seconds or
+// milliseconds.
+func parseCADTime(s string) (time.Time, bool) {
+	if n, err := strconv.ParseFloat(s, 64); err == nil && n > 0 {
+		if n < 1e12 {
+			return time.UnixMilli(int64(n * 1000)), true
+		}
+		return time.UnixMilli(int64(n)), true
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02 15:04:05"} {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// parseCADIncident reads a webhook body with the field map.
+func parseCADIncident(body []byte, fields CADFieldMap, now time.Time) (*CADIncident, error) {
+	fields = fields.withDefaults()
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var payload any
+	if err := decoder.Decode(&payload); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %v", err)
+	}
+
+	cad := &CADIncident{
+		Id:           cadString(payload, fields.Id),
+		IncidentType: cadString(payload, fields.IncidentType),
+		Address:      strings.ToUpper(cadString(payload, fields.Address)),
+		Description:  cadString(payload, fields.Description),
+		Time:         now.UnixMilli(),
+		ReceivedAt:   now.UnixMilli(),
+	}
+	if cad.Id == "" {
+		return nil, fmt.Errorf("no incident id at %q", fields.Id)
+	}
+
+	seen := map[string]bool{}
+	for _, s := range cadStrings(payload, fields.Units) {
+		for _, unit := range strings.Split(s, ",") {
+			unit = strings.ToUpper(strings.TrimSpace(unit))
+			if unit != "" && !seen[unit] {
+				seen[unit] = true
+				cad.Units = append(cad.Units, unit)
+			}
+		}
+	}
+	if v, err := strconv.ParseFloat(cadString(payload, fields.Latitude), 64); err == nil {
+		cad.Latitude = v
+	}
+	if v, err := strconv.ParseFloat(cadString(payload, fields.Longitude), 64); err == nil {
+		cad.Longitude = v
+	}
+	if t, ok := parseCADTime(cadString(payload, fields.Time)); ok {
+		cad.Time = t.UnixMilli()
+	}
+	return cad, nil
+}
+
+// parseStoredCADIncident reads the CAD incident stored with an incident.
+func parseStoredCADIncident(data string) *CADIncident {
+	if data == "" {
+		return nil
+	}
+	cad := &CADIncident{}
+	if err := json.Unmarshal([]byte(data), cad); err != nil {
+		return nil
+	}
+	return cad
+}
+
+// cadLinks returns the talkgroups and tone sets a CAD incident is linked to.
+// The first talkgroup is where the incident is listed.
+func (controller *Controller) cadLinks(cad *CADIncident, links []CADLink) (talkgroups []*Talkgroup, systems []*System, toneSetIds []string) {
+	incidentType := strings.ToLower(cad.IncidentType)
+	for _, link := range links {
+		if link.Match != "" && !strings.Contains(incidentType, strings.ToLower(link.Match)) {
+			continue
+		}
+		if system, ok := controller.Systems.GetSystemByRef(link.SystemRef); ok {
+			for _, ref := range link.TalkgroupRefs {
+				if talkgroup, ok := system.Talkgroups.GetTalkgroupByRef(ref); ok {
+					talkgroups = append(talkgroups, talkgroup)
+					systems = append(systems, system)
+				}
+			}
+		}
+		toneSetIds = append(toneSetIds, link.ToneSetIds...)
+	}
+	return talkgroups, systems, toneSetIds
+}
+
+// link adds the talkgroups, tone sets and units of a CAD incident to an open
+// incident, so the radio traffic that follows joins it.
+func (incident *incidentState) link(cad *CADIncident, talkgroups []*Talkgroup, toneSetIds []string) {
+	incident.CadId = cad.Id
+	if incident.Address == "" {
+		incident.Address = cad.Address
+	}
+	for _, talkgroup := range talkgroups {
+		incident.Talkgroups[talkgroup.Id] = true
+	}
+	for _, id := range toneSetIds {
+		incident.ToneSetIds[id] = true
+	}
+	for _, unit := range cad.Units {
+		incident.Units[unit] = true
+	}
+	for token := range incidentTokens(cad.IncidentType + " " + cad.Description) {
+		if len(incident.Tokens) >= incidentMaxTokens {
+			break
+		}
+		incident.Tokens[token] = true
+	}
+}
+
+// ObserveCAD records a CAD incident. An update of a CAD incident already
+// received updates its incident. Otherwise it is linked to the open incident
+// of the radio traffic it matches, or opens a new incident that the radio
+// traffic will join. It returns the incident and whether it was opened.
+func (incidents *Incidents) ObserveCAD(cad *CADIncident, config CADConfig) (uint64, bool, error) {
+	talkgroups, systems, toneSetIds := incidents.controller.cadLinks(cad, config.Links)
+	data, err := json.Marshal(cad)
+	if err != nil {
+		return 0, false, err
+	}
+
+	incidents.mutex.Lock()
+	defer incidents.mutex.Unlock()
+
+	db := incidents.controller.Database.Sql
+	now := time.Now()
+
+	var id uint64
+	err = db.QueryRow(`SELECT "incidentId" FROM "incidents" WHERE "cadId" = $1`, cad.Id).Scan(&id)
+	if err == nil {
+		if _, err := db.Exec(
+			`UPDATE "incidents" SET "cadData" = $1, "incidentType" = COALESCE(NULLIF($2, ''), "incidentType"), "address" = COALESCE(NULLIF($3, ''), "address") WHERE "incidentId" = $4`,
+			string(data), cad.IncidentType, cad.Address, id,
+		); err != nil {
+			return 0, false, err
+		}
+		if incident, ok := incidents.open[id]; ok {
+			incident.link(cad, talkgroups, toneSetIds)
+		}
+		return id, false, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return 0, false, err
+	}
+
+	c := &incidentCall{
+		Timestamp: now,
+		Address:   cad.Address,
+		Units:     cad.Units,
+		Tokens:    incidentTokens(cad.IncidentType + " " + cad.Description),
+	}
+	if incident, _, _ := incidents.match(c, incidents.controller.Options.IncidentConfig); incident != nil && incident.CadId == "" {
+		if _, err := db.Exec(
+			`UPDATE "incidents" SET "cadId" = $1, "cadData" = $2, "incidentType" = COALESCE(NULLIF("incidentType", ''), $3), "address" = COALESCE(NULLIF("address", ''), $4) WHERE "incidentId" = $5`,
+			cad.Id, string(data), cad.IncidentType, cad.Address, incident.Id,
+		); err != nil {
+			return 0, false, err
+		}
+		incident.link(cad, talkgroups, toneSetIds)
+		return incident.Id, false, nil
+	}
+
+	var systemId, talkgroupId uint64
+	if len(talkgroups) > 0 {
+		systemId, talkgroupId = systems[0].Id, talkgroups[0].Id
+	}
+	title := cad.IncidentType
+	if title == "" {
+		title = cad.Description
+	}
+	if err := db.QueryRow(
+		`INSERT INTO "incidents" ("systemId", "talkgroupId", "title", "incidentType", "address", "startedAt", "updatedAt", "callCount", "cadId", "cadData") VALUES ($1, $2, $3, $4, $5, $6, $7, 0, $8, $9) RETURNING "incidentId"`,
+		systemId, talkgroupId, title, cad.IncidentType, cad.Address, cad.Time, now.UnixMilli(), cad.Id, string(data),
+	).Scan(&id); err != nil {
+		return 0, false, err
+	}
+
+	// The incident stays open for the window from when CAD sent it
+	incident := emptyIncidentState(id, time.UnixMilli(cad.Time))
+	incident.UpdatedAt = now
+	incident.link(cad, talkgroups, toneSetIds)
+	incidents.open[id] = incident
+	return id, true, nil
+}
+
+// CADHandler serves POST /api/cad, an incident webhook from a CAD system.
+// It is authenticated with an API key, in the X-API-Key header or the key
+// query parameter.
+func (api *Api) CADHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		api.exitWithError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	config := api.Controller.Options.CADConfig
+	if !config.Enabled || !api.Controller.Options.IncidentConfig.Enabled {
+		api.exitWithError(w, http.StatusNotFound, "CAD ingestion is disabled")
+		return
+	}
+
+	key := r.Header.Get("X-API-Key")
+	if key == "" {
+		key = r.URL.Query().Get("key")
+	}
+	apikey, ok := api.Controller.Apikeys.GetApikey(key)
+	if key == "" || !ok {
+		api.exitWithError(w, http.StatusUnauthorized, "invalid API key")
+		return
+	}
+	if !api.Controller.IngestRateLimiter.Allow(fmt.Sprintf("apikey:%d", apikey.Id)) {
+		w.Header().Set("Retry-After", "60")
+		api.exitWithError(w, http.StatusTooManyRequests, "too many requests for this API key")
+		return
+	}
+	if ok, retryAfter := api.Controller.ApikeyUsage.Admit(apikey, time.Now()); !ok {
+		writeApikeyLimited(w, retryAfter)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, cadMaxBody+1))
+	if err != nil {
+		api.exitWithError(w, http.StatusBadRequest, "failed to read the request")
+		return
+	}
+	if len(body) > cadMaxBody {
+		api.exitWithError(w, http.StatusRequestEntityTooLarge, "request too large")
+		return
+	}
+
+	cad, err := parseCADIncident(body, config.Fields, time.Now())
+	if err != nil {
+		api.exitWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	id, created, err := api.Controller.Incidents.ObserveCAD(cad, config)
+	if err != nil {
+		api.Controller.Logs.LogEvent(LogLevelError, fmt.Sprintf("cad: failed to record incident %s: %v", cad.Id, err))
+		api.exitWithError(w, http.StatusInternalServerError, "failed to record the incident")
+		return
+	}
+	api.Controller.ApikeyUsage.Record(apikey.Id, GetRemoteAddr(r), len(body), time.Now())
+
+	w.Header().Set("Content-Type", "application/json")
+	if created {
+		w.WriteHeader(http.StatusCreated)
+	}
+	json.NewEncoder(w).Encode(map[string]any{"incidentId": id, "created": created})
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCADIncident(t *testing.T) {
+	now := time.UnixMilli(1700000000000)
+	body := []byte(`{"incident":{"number":20231114001,"call_type":"Structure Fire","location":{"full":"123 Oak St","lat":"39.78","lng":-89.65},"units":[{"name":"E5"},{"name":"l2"}],"created":1699999990}}`)
+	fields := CADFieldMap{
+		Id:           "incident.number",
+		IncidentType: "incident.call_type",
+		Address:      "incident.location.full",
+		Units:        "incident.units.*.name",
+		Latitude:     "incident.location.lat",
+		Longitude:    "incident.location.lng",
+		Time:         "incident.created",
+	}
+
+	cad, err := parseCADIncident(body, fields, now)
+	if err != nil {
+		t.Fatalf("parseCADIncident: %v", err)
+	}
+	if cad.Id != "20231114001" || cad.IncidentType != "Structure Fire" || cad.Address != "123 OAK ST" {
+		t.Fatalf("cad = %+v", cad)
+	}
+	if len(cad.Units) != 2 || cad.Units[0] != "E5" || cad.Units[1] != "L2" {
+		t.Fatalf("units = %v", cad.Units)
+	}
+	if cad.Latitude != 39.78 || cad.Longitude != -89.65 {
+		t.Fatalf("location = %v, %v", cad.Latitude, cad.Longitude)
+	}
+	if cad.Time != 1699999990000 || cad.ReceivedAt != now.UnixMilli() {
+		t.Fatalf("time = %d, receivedAt = %d", cad.Time, cad.ReceivedAt)
+	}
+
+	// Default field names, units as a comma separated string
+	cad, err = parseCADIncident([]byte(`{"id":"A1","type":"Medical","units":"M1, E5","time":"2023-11-14T22:13:20Z"}`), CADFieldMap{}, now)
+	if err != nil {
+		t.Fatalf("parseCADIncident: %v", err)
+	}
+	if cad.Id != "A1" || len(cad.Units) != 2 || cad.Units[1] != "E5" || cad.Time != 1700000000000 {
+		t.Fatalf("cad = %+v", cad)
+	}
+
+	if _, err := parseCADIncident([]byte(`{"type":"Medical"}`), CADFieldMap{}, now); err == nil {
+		t.Fatalf("expected an error without an incident id")
+	}
+	if _, err := parseCADIncident([]byte(`not json`), CADFieldMap{}, now); err == nil {
+		t.Fatalf("expected an error for invalid JSON")
+	}
+}
+
+func TestCADLinkedToneSetJoins(t *testing.T) {
+	window := 30 * time.Minute
+	start := time.UnixMilli(1700000000000)
+
+	incident := emptyIncidentState(1, start)
+	incident.link(&CADIncident{Id: "A1", IncidentType: "Structure Fire", Address: "123 OAK ST", Units: []string{"E5"}}, []*Talkgroup{{Id: 10}}, []string{"station-5"})
+
+	// Tones of the dispatched station join the CAD incident
+	tones := &incidentCall{TalkgroupId: 30, Timestamp: start.Add(time.Minute), HasTones: true, ToneSetIds: []string{"station-5"}}
+	if score, reasons := incidentScore(incident, tones, window); score < incidentDefaultMinScore {
+		t.Fatalf("linked tones score %.2f %v", score, reasons)
+	}
+
+	// Tones of another station never do
+	other := &incidentCall{TalkgroupId: 30, Timestamp: start.Add(time.Minute), HasTones: true, ToneSetIds: []string{"station-9"}}
+	if score, _ := incidentScore(incident, other, window); score != 0 {
+		t.Fatalf("other tones score %.2f", score)
+	}
+
+	// Traffic on the linked talkgroup naming the dispatched unit joins
+	traffic := &incidentCall{TalkgroupId: 10, Timestamp: start.Add(3 * time.Minute), Units: []string{"E5"}}
+	if score, reasons := incidentScore(incident, traffic, window); score < incidentDefaultMinScore {
+		t.Fatalf("linked talkgroup score %.2f %v", score, reasons)
+	}
+}
@@ -68,6 +68,8 @@ type Call struct {
 	Audio                []byte
 	AudioFilename        string
 	AudioMime            string
+	AudioLocation        string // set when the audio is stored outside the calls table (see AudioStore)
+	AudioChecksum        string // SHA-256 of the audio stored at AudioLocation
 	OriginalAudio        []byte // Original audio before AAC conversion (used for transcription)
 	OriginalAudioMime    string // Original audio MIME type
 	Delayed              bool
@@ -87,7 +89,10 @@ type Call struct {
 	TrainingReviewStatus string // pending, submitted
 	TranscriptConfidence float64
 	TranscriptionStatus  string
+	TranscriptTranslation string // English translation for non-English talkgroups
 	AlertSummary         string  // Optional short LLM summary for alerts (when summarized alerts enabled)
+	SummaryFields        *CallSummaryFields // Address, incident type and units extracted by the summarizer
+	Location             *CallLocation      // Dispatch address geocoded from the transcript
 	ApiKeyId             *uint64 // API key used for upload (for preferred API key logic)
 
 	// Add back simple fields for compatibility with v6 uploads
@@ -107,6 +112,10 @@ type Call struct {
 	// Not persisted to DB or included in JSON output.
 	Duration float64
 
+	// OriginalDuration is the audio length in seconds before silence was
+	// trimmed at ingest, 0 when the audio was not trimmed.
+	OriginalDuration float64
+
 	IsDuplicate bool `json:"isDuplicate,omitempty"`
 	AudioHash   string `json:"audioHash,omitempty"`
 
@@ -115,6 +124,10 @@ type Call struct {
 	// the receiving server from re-forwarding the call, breaking circular loops.
 	IsForwarded bool `json:"-"`
 
+	// Muted is runtime-only. It is set when the call starts outside a talkgroup
+	// schedule with the mute action: the call is stored but not streamed live.
+	Muted bool `json:"-"`
+
 	// ToneSourceTalkgroupId is runtime-only. When pending tones from one talkgroup
 	// attach to a voice call on another (linked-voice), this is the DB id of the
 	// talkgroup where tones were detected — used for alertCooldownSeconds lookup.
@@ -179,6 +192,7 @@ func (call *Call) MarshalJSON() ([]byte, error) {
 		"patches":   call.Patches,
 		"hasTones":  call.HasTones,
 	}
+	addCallTimeHints(callMap, call)
 
 	if call.ToneSequence != nil {
 		callMap["toneSequence"] = call.ToneSequence
@@ -196,10 +210,19 @@ func (call *Call) MarshalJSON() ([]byte, error) {
 			}
 		}
 		callMap["transcript"] = transcript
+		if call.TranscriptTranslation != "" {
+			callMap["transcriptTranslation"] = call.TranscriptTranslation
+		}
 	}
 	if call.AlertSummary != "" {
 		callMap["alertSummary"] = call.AlertSummary
 	}
+	if call.SummaryFields != nil {
+		callMap["summaryFields"] = call.SummaryFields
+	}
+	if call.Location != nil {
+		callMap["location"] = call.Location
+	}
 
 	if len(call.Frequencies) > 0 {
 		freqs := []map[string]any{}
@@ -225,6 +248,11 @@ func (call *Call) MarshalJSON() ([]byte, error) {
 		callMap["site"] = call.SiteRef
 	}
 
+	if call.OriginalDuration > 0 {
+		callMap["originalDuration"] = call.OriginalDuration
+		callMap["trimmedDuration"] = call.Duration
+	}
+
 	if call.System != nil {
 		callMap["system"] = call.System.SystemRef
 	} else if call.SystemId > 0 {
@@ -261,6 +289,11 @@ func (call *Call) MarshalJSON() ([]byte, error) {
 			if unit.Label != "" {
 				entry["tag"] = unit.Label
 			}
+			if call.System != nil {
+				if alias := call.System.Units.Alias(unit.UnitRef); alias != "" {
+					entry["alias"] = alias
+				}
+			}
 			sources = append(sources, entry)
 		}
 		callMap["sources"] = sources
@@ -316,6 +349,7 @@ func (call *Call) MarshalJSONWithEncryption(key []byte) ([]byte, error) {
 		"patches":   call.Patches,
 		"hasTones":  call.HasTones,
 	}
+	addCallTimeHints(callMap, call)
 
 	if call.ToneSequence != nil {
 		callMap["toneSequence"] = call.ToneSequence
@@ -332,10 +366,19 @@ func (call *Call) MarshalJSONWithEncryption(key []byte) ([]byte, error) {
 			}
 		}
 		callMap["transcript"] = transcript
+		if call.TranscriptTranslation != "" {
+			callMap["transcriptTranslation"] = call.TranscriptTranslation
+		}
 	}
 	if call.AlertSummary != "" {
 		callMap["alertSummary"] = call.AlertSummary
 	}
+	if call.SummaryFields != nil {
+		callMap["summaryFields"] = call.SummaryFields
+	}
+	if call.Location != nil {
+		callMap["location"] = call.Location
+	}
 	if len(call.Frequencies) > 0 {
 		freqs := []map[string]any{}
 		for _, f := range call.Frequencies {
@@ -380,6 +423,11 @@ func (call *Call) MarshalJSONWithEncryption(key []byte) ([]byte, error) {
 			if unit.Label != "" {
 				entry["tag"] = unit.Label
 			}
+			if call.System != nil {
+				if alias := call.System.Units.Alias(unit.UnitRef); alias != "" {
+					entry["alias"] = alias
+				}
+			}
 			sources = append(sources, entry)
 		}
 		callMap["sources"] = sources
@@ -612,12 +660,7 @@ func (calls *Calls) GetCall(id uint64) (*Call, error) {
 
 	call := Call{Id: id}
 
-	if calls.controller.Database.Config.DbType == DbTypePostgresql {
-		query = fmt.Sprintf(`SELECT c."audio", c."audioFilename", c."audioMime", c."siteRef", c."timestamp", STRING_AGG(CAST(COALESCE(cpt."talkgroupRef", 0) AS text), ','), sy."systemId", t."talkgroupId", c."frequency", c."toneSequence", c."hasTones", c."transcript", c."reviewedTranscript", c."trainingReviewStatus", c."transcriptConfidence", c."transcriptionStatus", c."alertSummary" FROM "calls" AS c LEFT JOIN "callPatches" AS cp on cp."callId" = c."callId" LEFT JOIN "talkgroups" AS cpt ON cpt."talkgroupId" = cp."talkgroupId" LEFT JOIN "systems" AS sy ON sy."systemId" = c."systemId" LEFT JOIN "talkgroups" AS t ON t."talkgroupId" = c."talkgroupId" WHERE c."callId" = %d GROUP BY c."callId", c."audio", c."audioFilename", c."audioMime", c."siteRef", c."timestamp", sy."systemId", t."talkgroupId", c."frequency", c."toneSequence", c."hasTones", c."transcript", c."reviewedTranscript", c."trainingReviewStatus", c."transcriptConfidence", c."transcriptionStatus", c."alertSummary"`, id)
-
-	} else {
-		query = fmt.Sprintf(`SELECT c."audio", c."audioFilename", c."audioMime", c."siteRef", c."timestamp", GROUP_CONCAT(COALESCE(cpt."talkgroupRef", 0)), sy."systemId", t."talkgroupId", c."frequency", c."toneSequence", c."hasTones", c."transcript", c."reviewedTranscript", c."trainingReviewStatus", c."transcriptConfidence", c."transcriptionStatus", c."alertSummary" FROM "calls" AS c LEFT JOIN "callPatches" AS cp on cp."callId" = c."callId" LEFT JOIN "talkgroups" AS cpt ON cpt."talkgroupId" = cp."talkgroupId" LEFT JOIN "systems" AS sy ON sy."systemId" = c."systemId" LEFT JOIN "talkgroups" AS t ON t."talkgroupId" = c."talkgroupId" WHERE c."callId" = %d GROUP BY c."callId", c."audio", c."audioFilename", c."audioMime", c."siteRef", c."timestamp", sy."systemId", t."talkgroupId", c."frequency", c."toneSequence", c."hasTones", c."transcript", c."reviewedTranscript", c."trainingReviewStatus", c."transcriptConfidence", c."transcriptionStatus", c."alertSummary"`, id)
-	}
+	query = `SELECT c."audio", c."audioFilename", c."audioMime", c."audioLocation", c."audioChecksum", c."siteRef", c."timestamp", STRING_AGG(CAST(COALESCE(cpt."talkgroupRef", 0) AS text), ','), sy."systemId", t."talkgroupId", c."frequency", c."toneSequence", c."hasTones", c."transcript", c."reviewedTranscript", c."trainingReviewStatus", c."transcriptConfidence", c."transcriptionStatus", c."alertSummary", c."transcriptTranslation", c."summaryFields", c."locationAddress", c."latitude", c."longitude" FROM "calls" AS c LEFT JOIN "callPatches" AS cp on cp."callId" = c."callId" LEFT JOIN "talkgroups" AS cpt ON cpt."talkgroupId" = cp."talkgroupId" LEFT JOIN "systems" AS sy ON sy."systemId" = c."systemId" LEFT JOIN "talkgroups" AS t ON t."talkgroupId" = c."talkgroupId" WHERE c."callId" = $1 GROUP BY c."callId", c."audio", c."audioFilename", c."audioMime", c."audioLocation", c."audioChecksum", c."siteRef", c."timestamp", sy."systemId", t."talkgroupId", c."frequency", c."toneSequence", c."hasTones", c."transcript", c."reviewedTranscript", c."trainingReviewStatus", c."transcriptConfidence", c."transcriptionStatus", c."alertSummary", c."transcriptTranslation", c."summaryFields", c."locationAddress", c."latitude", c."longitude"`
 
 	var toneSequenceJson sql.NullString
 	var transcript sql.NullString
@@ -626,8 +669,12 @@ func (calls *Calls) GetCall(id uint64) (*Call, error) {
 	var transcriptConfidence sql.NullFloat64
 	var transcriptionStatus sql.NullString
 	var alertSummary sql.NullString
+	var transcriptTranslation sql.NullString
+	var summaryFields sql.NullString
+	var locationAddress sql.NullString
+	var latitude, longitude sql.NullFloat64
 
-	if err = tx.QueryRow(query).Scan(&call.Audio, &call.AudioFilename, &call.AudioMime, &call.SiteRef, &timestamp, &patch, &systemId, &talkgroupId, &frequency, &toneSequenceJson, &call.HasTones, &transcript, &reviewedTranscript, &trainingReviewStatus, &transcriptConfidence, &transcriptionStatus, &alertSummary); err != nil && err != sql.ErrNoRows {
+	if err = tx.QueryRow(query, id).Scan(&call.Audio, &call.AudioFilename, &call.AudioMime, &call.AudioLocation, &call.AudioChecksum, &call.SiteRef, &timestamp, &patch, &systemId, &talkgroupId, &frequency, &toneSequenceJson, &call.HasTones, &transcript, &reviewedTranscript, &trainingReviewStatus, &transcriptConfidence, &transcriptionStatus, &alertSummary, &transcriptTranslation, &summaryFields, &locationAddress, &latitude, &longitude); err != nil && err != sql.ErrNoRows {
 		tx.Rollback()
 		return nil, formatError(err, query)
 	}
@@ -659,6 +706,9 @@ func (calls *Calls) GetCall(id uint64) (*Call, error) {
 	if reviewedTranscript.Valid {
 		call.ReviewedTranscript = reviewedTranscript.String
 	}
+	if transcriptTranslation.Valid {
+		call.TranscriptTranslation = transcriptTranslation.String
+	}
 	if trainingReviewStatus.Valid {
 		call.TrainingReviewStatus = trainingReviewStatus.String
 	}
@@ -671,6 +721,12 @@ func (calls *Calls) GetCall(id uint64) (*Call, error) {
 	if alertSummary.Valid {
 		call.AlertSummary = alertSummary.String
 	}
+	if summaryFields.Valid {
+		call.SummaryFields = parseSummaryFields(summaryFields.String)
+	}
+	if latitude.Valid && longitude.Valid {
+		call.Location = &CallLocation{Address: locationAddress.String, Latitude: latitude.Float64, Longitude: longitude.Float64}
+	}
 
 	if len(patch) > 0 {
 		for _, s := range strings.Split(patch, ",") {
@@ -696,8 +752,8 @@ func (calls *Calls) GetCall(id uint64) (*Call, error) {
 		return nil, formatError(fmt.Errorf("cannot retrieve talkgroup id %d for call id %d", talkgroupId, call.Id), "")
 	}
 
-	query = fmt.Sprintf(`SELECT "offset", "unitRef", COALESCE("label", '') FROM "callUnits" WHERE "callId" = %d ORDER BY "offset" ASC`, id)
-	if rows, err = tx.Query(query); err != nil {
+	query = `SELECT "offset", "unitRef", COALESCE("label", '') FROM "callUnits" WHERE "callId" = $1 ORDER BY "offset" ASC`
+	if rows, err = tx.Query(query, id); err != nil {
 		tx.Rollback()
 		return nil, formatError(err, query)
 	}
@@ -724,6 +780,8 @@ func (calls *Calls) GetCall(id uint64) (*Call, error) {
 		return nil, formatError(err, "")
 	}
 
+	calls.loadStoredAudio(&call)
+
 	return &call, nil
 }
 
@@ -733,7 +791,7 @@ func (calls *Calls) GetCall(id uint64) (*Call, error) {
 //	          callId, timestamp, patches, systemId, talkgroupId, frequency,
 //	          toneSequence, hasTones, transcript, transcriptConfidence,
 //	          transcriptionStatus, alertSummary
-//	Query 2 — audio bytes + filenames: callId, audio, audioFilename, audioMime, siteRef
+//	Query 2 — audio bytes + filenames: callId, audio, audioFilename, audioMime, audioLocation, audioChecksum, siteRef
 //	Query 3 — units:                   callId, offset, unitRef, label
 //
 // Any IDs currently in the delay queue are silently skipped.
@@ -753,25 +811,16 @@ func (calls *Calls) GetCallsBulk(ids []uint64) []*Call {
 		return nil
 	}
 
-	// Build the IN clause (safe: IDs are uint64, no user input).
-	inParts := make([]string, len(active))
-	for i, id := range active {
-		inParts[i] = strconv.FormatUint(id, 10)
-	}
-	inClause := strings.Join(inParts, ",")
+	args := queryArgs{}
+	inClause := args.in(active)
 
 	// --- Query 1: metadata ---
 	// Audio blobs are intentionally excluded from this query so they don't
 	// participate in the GROUP BY (which would force a full blob comparison
 	// for every row in the aggregation).
-	var metaQuery string
-	if calls.controller.Database.Config.DbType == DbTypePostgresql {
-		metaQuery = `SELECT c."callId", c."timestamp", STRING_AGG(CAST(COALESCE(cpt."talkgroupRef", 0) AS text), ','), sy."systemId", t."talkgroupId", c."frequency", c."toneSequence", c."hasTones", c."transcript", c."transcriptConfidence", c."transcriptionStatus", c."alertSummary" FROM "calls" AS c LEFT JOIN "callPatches" AS cp ON cp."callId" = c."callId" LEFT JOIN "talkgroups" AS cpt ON cpt."talkgroupId" = cp."talkgroupId" LEFT JOIN "systems" AS sy ON sy."systemId" = c."systemId" LEFT JOIN "talkgroups" AS t ON t."talkgroupId" = c."talkgroupId" WHERE c."callId" IN (` + inClause + `) GROUP BY c."callId", c."timestamp", sy."systemId", t."talkgroupId", c."frequency", c."toneSequence", c."hasTones", c."transcript", c."transcriptConfidence", c."transcriptionStatus", c."alertSummary" ORDER BY c."timestamp" ASC`
-	} else {
-		metaQuery = `SELECT c."callId", c."timestamp", GROUP_CONCAT(COALESCE(cpt."talkgroupRef", 0)), sy."systemId", t."talkgroupId", c."frequency", c."toneSequence", c."hasTones", c."transcript", c."transcriptConfidence", c."transcriptionStatus", c."alertSummary" FROM "calls" AS c LEFT JOIN "callPatches" AS cp ON cp."callId" = c."callId" LEFT JOIN "talkgroups" AS cpt ON cpt."talkgroupId" = cp."talkgroupId" LEFT JOIN "systems" AS sy ON sy."systemId" = c."systemId" LEFT JOIN "talkgroups" AS t ON t."talkgroupId" = c."talkgroupId" WHERE c."callId" IN (` + inClause + `) GROUP BY c."callId" ORDER BY c."timestamp" ASC`
-	}
+	metaQuery := `SELECT c."callId", c."timestamp", STRING_AGG(CAST(COALESCE(cpt."talkgroupRef", 0) AS text), ','), sy."systemId", t."talkgroupId", c."frequency", c."toneSequence", c."hasTones", c."transcript", c."transcriptConfidence", c."transcriptionStatus", c."alertSummary", c."transcriptTranslation" FROM "calls" AS c LEFT JOIN "callPatches" AS cp ON cp."callId" = c."callId" LEFT JOIN "talkgroups" AS cpt ON cpt."talkgroupId" = cp."talkgroupId" LEFT JOIN "systems" AS sy ON sy."systemId" = c."systemId" LEFT JOIN "talkgroups" AS t ON t."talkgroupId" = c."talkgroupId" WHERE c."callId" IN ` + inClause + ` GROUP BY c."callId", c."timestamp", sy."systemId", t."talkgroupId", c."frequency", c."toneSequence", c."hasTones", c."transcript", c."transcriptConfidence", c."transcriptionStatus", c."alertSummary", c."transcriptTranslation" ORDER BY c."timestamp" ASC`
 
-	metaRows, err := calls.controller.Database.Sql.Query(metaQuery)
+	metaRows, err := calls.controller.Database.Sql.Query(metaQuery, args...)
 	if err != nil {
 		return nil
 	}
@@ -786,11 +835,11 @@ func (calls *Calls) GetCallsBulk(ids []uint64) []*Call {
 		var systemId, talkgroupId uint64
 		var timestamp int64
 		var frequency sql.NullInt64
-		var toneSeqJson, transcript, transcriptionStatus, alertSummary sql.NullString
+		var toneSeqJson, transcript, transcriptionStatus, alertSummary, transcriptTranslation sql.NullString
 		var transcriptConfidence sql.NullFloat64
 		var hasTones bool
 
-		if err = metaRows.Scan(&id, &timestamp, &patch, &systemId, &talkgroupId, &frequency, &toneSeqJson, &hasTones, &transcript, &transcriptConfidence, &transcriptionStatus, &alertSummary); err != nil {
+		if err = metaRows.Scan(&id, &timestamp, &patch, &systemId, &talkgroupId, &frequency, &toneSeqJson, &hasTones, &transcript, &transcriptConfidence, &transcriptionStatus, &alertSummary, &transcriptTranslation); err != nil {
 			continue
 		}
 
@@ -822,6 +871,9 @@ func (calls *Calls) GetCallsBulk(ids []uint64) []*Call {
 		if alertSummary.Valid {
 			call.AlertSummary = alertSummary.String
 		}
+		if transcriptTranslation.Valid {
+			call.TranscriptTranslation = transcriptTranslation.String
+		}
 		if len(patch) > 0 {
 			for _, s := range strings.Split(patch, ",") {
 				if i, err2 := strconv.Atoi(s); err2 == nil && i > 0 {
@@ -851,27 +903,33 @@ func (calls *Calls) GetCallsBulk(ids []uint64) []*Call {
 
 	// --- Query 2: audio blobs ---
 	audioRows, err := calls.controller.Database.Sql.Query(
-		`SELECT "callId", "audio", "audioFilename", "audioMime", "siteRef" FROM "calls" WHERE "callId" IN (` + inClause + `)`)
+		`SELECT "callId", "audio", "audioFilename", "audioMime", "audioLocation", "audioChecksum", "siteRef" FROM "calls" WHERE "callId" IN ` + inClause, args...)
 	if err == nil {
 		defer audioRows.Close()
 		for audioRows.Next() {
 			var cid uint64
 			var audio []byte
-			var filename, mime, siteRef string
-			if audioRows.Scan(&cid, &audio, &filename, &mime, &siteRef) == nil {
+			var filename, mime, location, checksum, siteRef string
+			if audioRows.Scan(&cid, &audio, &filename, &mime, &location, &checksum, &siteRef) == nil {
 				if c, ok := byId[cid]; ok {
 					c.Audio = audio
 					c.AudioFilename = filename
 					c.AudioMime = mime
+					c.AudioLocation = location
+					c.AudioChecksum = checksum
 					c.SiteRef = siteRef
 				}
 			}
 		}
 	}
 
+	for _, c := range ordered {
+		calls.loadStoredAudio(c)
+	}
+
 	// --- Query 3: units ---
 	unitRows, err := calls.controller.Database.Sql.Query(
-		`SELECT "callId", "offset", "unitRef", COALESCE("label", '') FROM "callUnits" WHERE "callId" IN (` + inClause + `) ORDER BY "callId", "offset" ASC`)
+		`SELECT "callId", "offset", "unitRef", COALESCE("label", '') FROM "callUnits" WHERE "callId" IN ` + inClause + ` ORDER BY "callId", "offset" ASC`, args...)
 	if err == nil {
 		defer unitRows.Close()
 		for unitRows.Next() {
@@ -888,25 +946,32 @@ func (calls *Calls) GetCallsBulk(ids []uint64) []*Call {
 	return ordered
 }
 
-func (calls *Calls) Prune(db *Database, pruneDays uint) error {
-	timestamp := time.Now().Add(-24 * time.Hour * time.Duration(pruneDays)).UnixMilli()
-	query := fmt.Sprintf(`DELETE FROM "calls" WHERE "timestamp" < %d`, timestamp)
+// loadStoredAudio fetches the audio of a call kept outside the calls table.
+func (calls *Calls) loadStoredAudio(call *Call) {
+	if call == nil || len(call.Audio) > 0 || call.AudioLocation == "" || calls.controller == nil {
+		return
+	}
 
-	if _, err := db.Sql.Exec(query); err != nil {
-		return fmt.Errorf("%s in %s", err, query)
+	audio, err := calls.controller.AudioStore.Fetch(call.AudioLocation, call.AudioChecksum)
+	if err != nil {
+		calls.controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("call %d: stored audio unavailable: %v", call.Id, err))
+		return
 	}
 
-	return nil
+	call.Audio = audio
 }
 
-func (calls *Calls) PurgeAll(db *Database) error {
-	query := `DELETE FROM "calls"`
+func (calls *Calls) Prune(db *Database, pruneDays uint) error {
+	timestamp := time.Now().Add(-24 * time.Hour * time.Duration(pruneDays)).UnixMilli()
 
-	if _, err := db.Sql.Exec(query); err != nil {
-		return fmt.Errorf("%s in %s", err, query)
-	}
+	// Calls covered by a retention policy are purged by Retention instead
+	where := `"timestamp" < $1 AND NOT EXISTS (SELECT 1 FROM "retentionPolicies" r WHERE r."systemId" = "calls"."systemId" AND (r."talkgroupId" = 0 OR r."talkgroupId" = "calls"."talkgroupId"))`
 
-	return nil
+	return calls.deleteWhere(db, where, timestamp)
+}
+
+func (calls *Calls) PurgeAll(db *Database) error {
+	return calls.deleteWhere(db, "TRUE")
 }
 
 func (calls *Calls) DeleteByIDs(db *Database, ids []uint64) error {
@@ -914,23 +979,29 @@ func (calls *Calls) DeleteByIDs(db *Database, ids []uint64) error {
 		return nil
 	}
 
-	var placeholders []string
-	var args []interface{}
-	for i, id := range ids {
-		if db.Config.DbType == DbTypePostgresql {
-			placeholders = append(placeholders, fmt.Sprintf("$%d", i+1))
-		} else {
-			placeholders = append(placeholders, "?")
-		}
-		args = append(args, id)
+	args := queryArgs{}
+
+	return calls.deleteWhere(db, `"callId" IN `+args.in(ids), args...)
+}
+
+// deleteWhere deletes the calls matching where and releases their audio kept
+// outside the calls table once the delete went through.
+func (calls *Calls) deleteWhere(db *Database, where string, args ...any) error {
+	var files []string
+	if calls.controller != nil && calls.controller.AudioStore != nil {
+		files = calls.controller.AudioStore.fileLocations(where, args...)
 	}
 
-	query := fmt.Sprintf(`DELETE FROM "calls" WHERE "callId" IN (%s)`, strings.Join(placeholders, ", "))
+	query := `DELETE FROM "calls" WHERE ` + where
 
 	if _, err := db.Sql.Exec(query, args...); err != nil {
 		return fmt.Errorf("%s in %s", err, query)
 	}
 
+	if len(files) > 0 {
+		calls.controller.AudioStore.Release(files)
+	}
+
 	return nil
 }
 
@@ -1013,6 +1084,7 @@ func (calls *Calls) Search(searchOptions *CallsSearchOptions, client *Client) (*
 	}
 
 	// Build WHERE conditions using slice-based approach (like v6)
+	args := queryArgs{}
 	where := []string{
 		`c."systemId" > 0`,
 		`c."talkgroupId" > 0`,
@@ -1025,23 +1097,30 @@ func (calls *Calls) Search(searchOptions *CallsSearchOptions, client *Client) (*
 	switch v := searchOptions.System.(type) {
 	case uint:
 		conditions := []string{
-			fmt.Sprintf(`c."systemRef" = %d`, v),
+			`c."systemRef" = ` + args.add(v),
 		}
 		switch v := searchOptions.Talkgroup.(type) {
 		case uint:
-			conditions = append(conditions, fmt.Sprintf(`c."talkgroupRef" = %d`, v))
+			conditions = append(conditions, `c."talkgroupRef" = `+args.add(v))
 		}
 		if len(conditions) > 0 {
 			where = append(where, fmt.Sprintf("(%s)", strings.Join(conditions, " AND ")))
 		}
 	}
 
+	if client.TenantSystemIds != nil {
+		if len(client.TenantSystemIds) == 0 {
+			where = append(where, `1=0`)
+		} else {
+			where = append(where, `c."systemId" IN `+args.in(client.TenantSystemIds))
+		}
+	}
+
 	switch v := searchOptions.Group.(type) {
 	case string:
 		groupConditions := []string{}
 		for id, m := range client.GroupsMap[v] {
-			in := strings.ReplaceAll(strings.ReplaceAll(strings.ReplaceAll(fmt.Sprintf("%v", m), " ", ", "), "[", "("), "]", ")")
-			groupConditions = append(groupConditions, fmt.Sprintf(`(c."systemRef" = %d AND c."talkgroupRef" IN %s)`, id, in))
+			groupConditions = append(groupConditions, fmt.Sprintf(`(c."systemRef" = %s AND c."talkgroupRef" IN %s)`, args.add(id), args.in(queryIds(m))))
 		}
 		if len(groupConditions) > 0 {
 			where = append(where, fmt.Sprintf("(%s)", strings.Join(groupConditions, " OR ")))
@@ -1052,8 +1131,7 @@ func (calls *Calls) Search(searchOptions *CallsSearchOptions, client *Client) (*
 	case string:
 		tagConditions := []string{}
 		for id, m := range client.TagsMap[v] {
-			in := strings.ReplaceAll(strings.ReplaceAll(strings.ReplaceAll(fmt.Sprintf("%v", m), " ", ", "), "[", "("), "]", ")")
-			tagConditions = append(tagConditions, fmt.Sprintf(`(c."systemRef" = %d AND c."talkgroupRef" IN %s)`, id, in))
+			tagConditions = append(tagConditions, fmt.Sprintf(`(c."systemRef" = %s AND c."talkgroupRef" IN %s)`, args.add(id), args.in(queryIds(m))))
 		}
 		if len(tagConditions) > 0 {
 			where = append(where, fmt.Sprintf("(%s)", strings.Join(tagConditions, " OR ")))
@@ -1097,7 +1175,7 @@ func (calls *Calls) Search(searchOptions *CallsSearchOptions, client *Client) (*
 		cutoffTimeMs := cutoffTime.UnixMilli()
 
 		// Add delay condition: only include calls older than the delay period
-		where = append(where, fmt.Sprintf(`c."timestamp" <= %d`, cutoffTimeMs))
+		where = append(where, `c."timestamp" <= `+args.add(cutoffTimeMs))
 	}
 
 	// Date filter - use simple comparisons instead of BETWEEN (like v6/Python)
@@ -1106,7 +1184,7 @@ func (calls *Calls) Search(searchOptions *CallsSearchOptions, client *Client) (*
 		selectedDateMs := v.UnixMilli()
 		// When a date is selected, always show calls from that date forward (>=)
 		// The sort order (ASC/DESC) controls whether oldest or newest are shown first
-		where = append(where, fmt.Sprintf(`c."timestamp" >= %d`, selectedDateMs))
+		where = append(where, `c."timestamp" >= `+args.add(selectedDateMs))
 	default:
 		// No date selected - for large databases, limit scan range when sorting DESC (newest first)
 		// This prevents full table scans on 50M+ record databases
@@ -1117,7 +1195,7 @@ func (calls *Calls) Search(searchOptions *CallsSearchOptions, client *Client) (*
 			// Default to 24 hours back for newest-first searches without a date
 			defaultLookback := now.Add(-24 * time.Hour)
 			defaultLookbackMs := defaultLookback.UnixMilli()
-			where = append(where, fmt.Sprintf(`c."timestamp" >= %d`, defaultLookbackMs))
+			where = append(where, `c."timestamp" >= `+args.add(defaultLookbackMs))
 			calls.controller.Logs.LogEvent(LogLevelInfo, fmt.Sprintf("Search: No date selected, applying default 24-hour lookback for DESC order (from %s)", defaultLookback.Format("2006-01-02 15:04:05")))
 		}
 	}
@@ -1166,10 +1244,11 @@ func (calls *Calls) Search(searchOptions *CallsSearchOptions, client *Client) (*
 	}
 
 	if !enforcePlaybackACL {
-		query = fmt.Sprintf(`%s LIMIT %d OFFSET %d`, baseSelect, queryLimit, offset)
+		pageArgs := append(queryArgs{}, args...)
+		query = fmt.Sprintf(`%s LIMIT %s OFFSET %s`, baseSelect, pageArgs.add(queryLimit), pageArgs.add(offset))
 		calls.controller.Logs.LogEvent(LogLevelInfo, fmt.Sprintf("Search RESULTS query: %s", query))
 
-		if rows, err = db.Sql.QueryContext(ctx, query); err != nil && err != sql.ErrNoRows {
+		if rows, err = db.Sql.QueryContext(ctx, query, pageArgs...); err != nil && err != sql.ErrNoRows {
 			return nil, formatError(err, query)
 		}
 
@@ -1224,10 +1303,11 @@ func (calls *Calls) Search(searchOptions *CallsSearchOptions, client *Client) (*
 	var dbScanOffset uint
 
 	for chunks := 0; uint(len(collected)) < queryLimit && chunks < 2000; chunks++ {
-		query = fmt.Sprintf(`%s LIMIT %d OFFSET %d`, baseSelect, chunkSize, dbScanOffset)
+		chunkArgs := append(queryArgs{}, args...)
+		query = fmt.Sprintf(`%s LIMIT %s OFFSET %s`, baseSelect, chunkArgs.add(chunkSize), chunkArgs.add(dbScanOffset))
 
 		var chunkRows *sql.Rows
-		if chunkRows, err = db.Sql.QueryContext(ctx, query); err != nil && err != sql.ErrNoRows {
+		if chunkRows, err = db.Sql.QueryContext(ctx, query, chunkArgs...); err != nil && err != sql.ErrNoRows {
 			return nil, formatError(err, query)
 		}
 
@@ -1299,15 +1379,55 @@ func (calls *Calls) Search(searchOptions *CallsSearchOptions, client *Client) (*
 }
 
 func (calls *Calls) WriteCall(call *Call, db *Database) (uint64, error) {
+	stored := calls.storeAudio(call)
+
 	var id uint64
 	err := withPostgresIndexHeal(db, func() error {
 		var writeErr error
 		id, writeErr = calls.writeCall(call, db)
 		return writeErr
 	})
+
+	if err != nil && stored {
+		calls.controller.AudioStore.Release([]string{call.AudioLocation})
+		call.AudioLocation, call.AudioChecksum = "", ""
+	}
+
 	return id, err
 }
 
+// storeAudio writes the audio of a new call to the configured audio storage
+// backend. On failure the audio stays in the calls table so no call is lost.
+func (calls *Calls) storeAudio(call *Call) bool {
+	if calls.controller == nil || calls.controller.AudioStore == nil || len(call.Audio) == 0 || call.AudioLocation != "" {
+		return false
+	}
+
+	backend := calls.controller.AudioStore.Backend()
+
+	var (
+		location string
+		checksum string
+		err      error
+	)
+	if calls.controller.AudioStore.Dedup() {
+		location, checksum, err = calls.controller.AudioStore.PutShared(backend, call.Timestamp, call.AudioFilename, call.AudioMime, call.Audio)
+	} else if backend == AudioStorageDatabase {
+		return false
+	} else {
+		location, checksum, err = calls.controller.AudioStore.Put(backend, "", call.Timestamp, call.AudioFilename, call.AudioMime, call.Audio)
+	}
+	if err != nil {
+		calls.controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("audio storage: %v; keeping audio in the database", err))
+		return false
+	}
+
+	call.AudioLocation = location
+	call.AudioChecksum = checksum
+
+	return true
+}
+
 func (calls *Calls) writeCall(call *Call, db *Database) (uint64, error) {
 	var (
 		err   error
@@ -1375,15 +1495,22 @@ func (calls *Calls) writeCall(call *Call, db *Database) (uint64, error) {
 		}
 	}
 
+	// Audio kept outside the table leaves an empty BLOB; call.Audio stays in
+	// memory for transcription and live listeners
+	audio := call.Audio
+	if call.AudioLocation != "" {
+		audio = []byte{}
+	}
+
 	if db.Config.DbType == DbTypePostgresql {
-		query = fmt.Sprintf(`INSERT INTO "calls" ("audio", "audioFilename", "audioMime", "siteRef", "systemId", "talkgroupId", "systemRef", "talkgroupRef", "timestamp", "frequency", "toneSequence", "hasTones", "transcript", "transcriptConfidence", "transcriptionStatus", "transmissionId", "requestId", "signalJobId", "receivedAt", "audioDuration", "isDuplicate", "audioHash") VALUES ($1, $2, $3, %d, %d, %d, %d, %d, %d, %d, $4, %t, $5, %.2f, $6, $7, $8, $9, NOW(), %.4f, %t, $10) RETURNING "callId"`, siteRefInt, call.System.Id, call.Talkgroup.Id, call.System.SystemRef, call.Talkgroup.TalkgroupRef, call.Timestamp.UnixMilli(), frequencyValue, call.HasTones, call.TranscriptConfidence, call.Duration, call.IsDuplicate)
+		query = `INSERT INTO "calls" ("audio", "audioFilename", "audioMime", "siteRef", "systemId", "talkgroupId", "systemRef", "talkgroupRef", "timestamp", "frequency", "toneSequence", "hasTones", "transcript", "transcriptConfidence", "transcriptionStatus", "transmissionId", "requestId", "signalJobId", "receivedAt", "audioDuration", "originalDuration", "isDuplicate", "audioHash", "audioLocation", "audioChecksum") VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, NOW(), $19, $20, $21, $22, $23, $24) RETURNING "callId"`
 
-		err = tx.QueryRow(query, call.Audio, call.AudioFilename, call.AudioMime, toneSequenceJson, call.Transcript, call.TranscriptionStatus, call.TransmissionId, call.RequestId, call.SignalJobId, call.AudioHash).Scan(&call.Id)
+		err = tx.QueryRow(query, audio, call.AudioFilename, call.AudioMime, siteRefInt, call.System.Id, call.Talkgroup.Id, call.System.SystemRef, call.Talkgroup.TalkgroupRef, call.Timestamp.UnixMilli(), frequencyValue, toneSequenceJson, call.HasTones, call.Transcript, call.TranscriptConfidence, call.TranscriptionStatus, call.TransmissionId, call.RequestId, call.SignalJobId, call.Duration, call.OriginalDuration, call.IsDuplicate, call.AudioHash, call.AudioLocation, call.AudioChecksum).Scan(&call.Id)
 
 	} else {
-		query = fmt.Sprintf(`INSERT INTO "calls" ("audio", "audioFilename", "audioMime", "siteRef", "systemId", "talkgroupId", "systemRef", "talkgroupRef", "timestamp", "frequency", "toneSequence", "hasTones", "transcript", "transcriptConfidence", "transcriptionStatus", "transmissionId", "requestId", "signalJobId", "receivedAt", "audioDuration", "isDuplicate", "audioHash") VALUES (?, ?, ?, %d, %d, %d, %d, %d, %d, %d, ?, %t, ?, %.2f, ?, ?, ?, ?, CURRENT_TIMESTAMP, %.4f, %t, ?)`, siteRefInt, call.System.Id, call.Talkgroup.Id, call.System.SystemRef, call.Talkgroup.TalkgroupRef, call.Timestamp.UnixMilli(), frequencyValue, call.HasTones, call.TranscriptConfidence, call.Duration, call.IsDuplicate)
+		query = `INSERT INTO "calls" ("audio", "audioFilename", "audioMime", "siteRef", "systemId", "talkgroupId", "systemRef", "talkgroupRef", "timestamp", "frequency", "toneSequence", "hasTones", "transcript", "transcriptConfidence", "transcriptionStatus", "transmissionId", "requestId", "signalJobId", "receivedAt", "audioDuration", "originalDuration", "isDuplicate", "audioHash", "audioLocation", "audioChecksum") VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP, ?, ?, ?, ?, ?, ?)`
 
-		if res, err = tx.Exec(query, call.Audio, call.AudioFilename, call.AudioMime, toneSequenceJson, call.Transcript, call.TranscriptionStatus, call.TransmissionId, call.RequestId, call.SignalJobId, call.AudioHash); err == nil {
+		if res, err = tx.Exec(query, audio, call.AudioFilename, call.AudioMime, siteRefInt, call.System.Id, call.Talkgroup.Id, call.System.SystemRef, call.Talkgroup.TalkgroupRef, call.Timestamp.UnixMilli(), frequencyValue, toneSequenceJson, call.HasTones, call.Transcript, call.TranscriptConfidence, call.TranscriptionStatus, call.TransmissionId, call.RequestId, call.SignalJobId, call.Duration, call.OriginalDuration, call.IsDuplicate, call.AudioHash, call.AudioLocation, call.AudioChecksum); err == nil {
 			if id, err := res.LastInsertId(); err == nil {
 				call.Id = uint64(id)
 			}
@@ -1397,16 +1524,16 @@ func (calls *Calls) writeCall(call *Call, db *Database) (uint64, error) {
 
 	for _, ref := range call.Patches {
 		var talkgroupId sql.NullInt64
-		query = fmt.Sprintf(`SELECT "talkgroupId" FROM "talkgroups" WHERE "systemId" = %d and "talkgroupRef" = %d`, call.System.Id, ref)
-		if err = tx.QueryRow(query).Scan(&talkgroupId); err != nil && err != sql.ErrNoRows {
+		query = `SELECT "talkgroupId" FROM "talkgroups" WHERE "systemId" = $1 and "talkgroupRef" = $2`
+		if err = tx.QueryRow(query, call.System.Id, ref).Scan(&talkgroupId); err != nil && err != sql.ErrNoRows {
 			tx.Rollback()
 			return 0, formatError(err, query)
 		}
 		if !talkgroupId.Valid {
 			continue
 		}
-		query = fmt.Sprintf(`INSERT INTO "callPatches" ("callId", "talkgroupId") VALUES (%d, %d)`, call.Id, talkgroupId.Int64)
-		if _, err = tx.Exec(query); err != nil {
+		query = `INSERT INTO "callPatches" ("callId", "talkgroupId") VALUES ($1, $2)`
+		if _, err = tx.Exec(query, call.Id, talkgroupId.Int64); err != nil {
 			tx.Rollback()
 			return 0, formatError(err, query)
 		}
@@ -1419,13 +1546,20 @@ func (calls *Calls) writeCall(call *Call, db *Database) (uint64, error) {
 		if unit.UnitRef > 9223372036854775807 {
 			continue
 		}
-		query = fmt.Sprintf(`INSERT INTO "callUnits" ("callId", "offset", "unitRef", "label") VALUES (%d, %f, %d, $1)`, call.Id, unit.Offset, unit.UnitRef)
-		if _, err = tx.Exec(query, unit.Label); err != nil {
+		query = `INSERT INTO "callUnits" ("callId", "offset", "unitRef", "label") VALUES ($1, $2, $3, $4)`
+		if _, err = tx.Exec(query, call.Id, unit.Offset, unit.UnitRef, unit.Label); err != nil {
 			tx.Rollback()
 			return 0, formatError(err, query)
 		}
 	}
 
+	if calls.controller.outboxEnabled() {
+		if err = writeOutboxEvents(tx, newCallOutboxEvent("call.created", call)); err != nil {
+			tx.Rollback()
+			return 0, formatError(err, "")
+		}
+	}
+
 	if err = tx.Commit(); err != nil {
 		tx.Rollback()
 		return 0, formatError(err, "")
@@ -0,0 +1,159 @@
+// Copyright (C) 2026 Thinline Dynamic Solutions
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	callArchiveMaxRun  = 45 * time.Minute
+	callArchiveTimeout = 30 * time.Second
+
+	// callAudioLocationS3 prefixes "audioLocation" values of the form s3://bucket/key.
+	callAudioLocationS3 = "s3://"
+)
+
+// CallArchiver moves call audio from the calls table to S3-compatible object
+// storage once calls are older than CallArchiveConfig.AfterDays.
+type CallArchiver struct {
+	controller *Controller
+	mutex      sync.Mutex
+	running    bool
+	client     *S3Client
+	clientCfg  CallArchiveConfig
+}
+
+type CallArchiveResult struct {
+	Calls int64 `json:"calls"`
+	Bytes int64 `json:"bytes"`
+}
+
+func NewCallArchiver(controller *Controller) *CallArchiver {
+	return &CallArchiver{controller: controller}
+}
+
+// s3 returns a client for the current settings, rebuilding it when they change.
+func (archiver *CallArchiver) s3() (*S3Client, error) {
+	cfg := archiver.controller.Options.CallArchiveConfig
+
+	archiver.mutex.Lock()
+	defer archiver.mutex.Unlock()
+
+	if archiver.client != nil && archiver.clientCfg == cfg {
+		return archiver.client, nil
+	}
+
+	client, err := NewS3Client(cfg.Endpoint, cfg.Region, cfg.Bucket, cfg.AccessKeyId, cfg.SecretAccessKey, cfg.PathStyle)
+	if err != nil {
+		return nil, err
+	}
+
+	archiver.client = client
+	archiver.clientCfg = cfg
+
+	return client, nil
+}
+
+// Run archives eligible calls in batches. It is started by the hourly scheduler
+// and returns early if a previous run is still in progress.
+func (archiver *CallArchiver) Run() (*CallArchiveResult, error) {
+	cfg := archiver.controller.Options.CallArchiveConfig
+	result := &CallArchiveResult{}
+
+	if !cfg.Enabled || cfg.AfterDays == 0 {
+		return result, nil
+	}
+
+	archiver.mutex.Lock()
+	if archiver.running {
+		archiver.mutex.Unlock()
+		return result, nil
+	}
+	archiver.running = true
+	archiver.mutex.Unlock()
+
+	defer func() {
+		archiver.mutex.Lock()
+		archiver.running = false
+		archiver.mutex.Unlock()
+	}()
+
+	if _, err := archiver.s3(); err != nil {
+		return result, err
+	}
+
+	started := time.Now()
+	cutoff := started.Add(-24 * time.Hour * time.Duration(cfg.AfterDays)).UnixMilli()
+
+	var afterId uint64
+	for time.Since(started) < callArchiveMaxRun {
+		n, lastId, moved, err := archiver.controller.AudioStore.moveBatch(AudioStorageObject, cutoff, afterId, audioStorageMoveBatchSize)
+		result.Calls += int64(n)
+		result.Bytes += moved
+		if err != nil {
+			return result, err
+		}
+		if n < audioStorageMoveBatchSize {
+			break
+		}
+		afterId = lastId
+	}
+
+	return result, nil
+}
+
+// Open streams archived audio from its location.
+func (archiver *CallArchiver) Open(ctx context.Context, location string) (io.ReadCloser, int64, error) {
+	if !strings.HasPrefix(location, callAudioLocationS3) {
+		return nil, 0, fmt.Errorf("unsupported audio location %q", location)
+	}
+
+	bucket, key, ok := strings.Cut(strings.TrimPrefix(location, callAudioLocationS3), "/")
+	if !ok {
+		return nil, 0, fmt.Errorf("invalid audio location %q", location)
+	}
+
+	client, err := archiver.s3()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Objects stay readable after the configured bucket changes
+	if bucket != client.Bucket {
+		other := *client
+		other.Bucket = bucket
+		client = &other
+	}
+
+	return client.GetObject(ctx, key)
+}
+
+// RunScheduled is invoked by the scheduler and logs the outcome.
+func (archiver *CallArchiver) RunScheduled() {
+	result, err := archiver.Run()
+	if err != nil {
+		archiver.controller.Logs.LogEvent(LogLevelError, fmt.Sprintf("call archive: %v", err))
+	}
+	if result != nil && result.Calls > 0 {
+		archiver.controller.Logs.LogEvent(LogLevelInfo, fmt.Sprintf("call archive: moved audio of %d calls (%s) to object storage", result.Calls, formatBytes(int(result.Bytes))))
+	}
+}
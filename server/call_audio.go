@@ -16,36 +16,43 @@
 package main
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 )
 
 // CallAudioDownloadHandler serves raw audio bytes for a call.
 //
-// GET /api/calls/{callId}/audio?pin=<user_pin>
+// GET /api/calls/{callId}/audio?pin=<user_pin>, with Range and If-None-Match
+// GET /api/calls/{callId}/hls/index.m3u8?pin=<user_pin> for long calls
 //
 // Authentication: the same user PIN the mobile app already stores when a
 // user adds a scanner (validated via getClient, which checks against the
 // bcrypt-hashed PIN in the users table).
 func (api *Api) CallAudioDownloadHandler(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
 		w.WriteHeader(http.StatusMethodNotAllowed)
 		return
 	}
 
 	w.Header().Set("Access-Control-Allow-Origin", "*")
 	w.Header().Set("Access-Control-Allow-Methods", "GET, OPTIONS")
-	w.Header().Set("Access-Control-Allow-Headers", "Authorization, Content-Type")
+	w.Header().Set("Access-Control-Allow-Headers", "Authorization, Content-Type, Range, If-None-Match")
+	w.Header().Set("Access-Control-Expose-Headers", "Accept-Ranges, Content-Range, Content-Length, ETag")
 	if r.Method == http.MethodOptions {
 		w.WriteHeader(http.StatusNoContent)
 		return
 	}
 
-	// Extract call ID from URL: /api/calls/{id}/audio
+	// Extract call ID from URL: /api/calls/{id}/audio or /api/calls/{id}/hls/{file}
 	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/"), "/")
-	if len(parts) < 4 || parts[0] != "api" || parts[1] != "calls" || parts[3] != "audio" {
+	hls := len(parts) == 5 && parts[3] == "hls"
+	if len(parts) < 4 || parts[0] != "api" || parts[1] != "calls" || (parts[3] != "audio" && !hls) {
 		api.exitWithError(w, http.StatusBadRequest, "Invalid path — expected /api/calls/{id}/audio")
 		return
 	}
@@ -75,6 +82,49 @@ func (api *Api) CallAudioDownloadHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	// Audio URLs are handed out by the live call stream, so the same access and
+	// delay rules apply here as for websocket playback.
+	if client.User != nil && api.Controller.requiresUserAuth() {
+		if !api.Controller.userHasAccess(client.User, call) {
+			api.exitWithError(w, http.StatusForbidden, "Access denied")
+			return
+		}
+		delay := api.Controller.Delayer.getEffectiveDelayForClient(call, client)
+		if delay > 0 && time.Now().Before(call.Timestamp.Add(time.Duration(delay)*time.Minute)) {
+			api.exitWithError(w, http.StatusForbidden, "Call is still delayed for your account")
+			return
+		}
+	}
+
+	// Long calls play as HLS segments of the recorded audio
+	if hls {
+		api.Controller.CallHLS.Serve(w, r, call, parts[4])
+		return
+	}
+
+	// Users with announcements get the talkgroup spoken before the audio;
+	// ?announce=true or false overrides their setting
+	announce := api.Controller.TTS.EnabledFor(client.User)
+	if v := r.URL.Query().Get("announce"); v != "" && api.Controller.Options.TTSConfig.Enabled {
+		announce = v == "true" || v == "1"
+	}
+	if announce {
+		call = api.Controller.TTS.Announced(call)
+	}
+
+	writeCallAudio(w, r, call)
+}
+
+// writeCallAudio writes the call audio inline. Range requests let players
+// seek within long calls, and replays revalidate with the ETag instead of
+// downloading the audio again.
+func writeCallAudio(w http.ResponseWriter, r *http.Request, call *Call) {
+	writeCallAudioAs(w, r, call, "inline")
+}
+
+// writeCallAudioAs writes the call audio with the given content disposition,
+// inline or attachment.
+func writeCallAudioAs(w http.ResponseWriter, r *http.Request, call *Call, disposition string) {
 	mimeType := call.AudioMime
 	if mimeType == "" {
 		mimeType = "audio/aac"
@@ -82,13 +132,31 @@ func (api *Api) CallAudioDownloadHandler(w http.ResponseWriter, r *http.Request)
 
 	filename := call.AudioFilename
 	if filename == "" {
-		filename = fmt.Sprintf("call_%d.m4a", callId)
+		filename = fmt.Sprintf("call_%d.m4a", call.Id)
 	}
 
 	w.Header().Set("Content-Type", mimeType)
-	w.Header().Set("Content-Disposition", fmt.Sprintf(`inline; filename="%s"`, filename))
-	w.Header().Set("Content-Length", strconv.Itoa(len(call.Audio)))
-	w.Header().Set("Cache-Control", "no-store")
-	w.WriteHeader(http.StatusOK)
-	w.Write(call.Audio) //nolint:errcheck
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`%s; filename="%s"`, disposition, filename))
+	serveCallAudio(w, r, call.Id, call.Audio)
+}
+
+// callAudioCacheControl lets the browser keep call audio for a day without
+// sharing it with other users through proxies. Audio rewritten later, by a
+// transcode for instance, gets a new ETag.
+const callAudioCacheControl = "private, max-age=86400"
+
+// callAudioETag identifies the audio served for a call by its id and hash.
+func callAudioETag(callId uint64, audio []byte) string {
+	sum := sha256.Sum256(audio)
+	return fmt.Sprintf(`"%d-%s"`, callId, hex.EncodeToString(sum[:12]))
+}
+
+// serveCallAudio writes audio with Accept-Ranges, ETag and Cache-Control,
+// answering conditional requests with 304 and range requests with 206. The
+// caller sets Content-Type. No Last-Modified is sent: the call timestamp says
+// nothing about when the audio was last rewritten, so only the ETag validates.
+func serveCallAudio(w http.ResponseWriter, r *http.Request, callId uint64, audio []byte) {
+	w.Header().Set("ETag", callAudioETag(callId, audio))
+	w.Header().Set("Cache-Control", callAudioCacheControl)
+	http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(audio))
 }
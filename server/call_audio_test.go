@@ -0,0 +1,55 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestServeCallAudio(t *testing.T) {
+	audio := []byte("0123456789")
+
+	serve := func(header map[string]string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/api/calls/7/audio", nil)
+		for k, v := range header {
+			r.Header.Set(k, v)
+		}
+		w := httptest.NewRecorder()
+		w.Header().Set("Content-Type", "audio/mp4")
+		serveCallAudio(w, r, 7, audio)
+		return w
+	}
+
+	w := serve(nil)
+	etag := w.Header().Get("ETag")
+	if w.Code != http.StatusOK || w.Body.String() != string(audio) {
+		t.Fatalf("full response = %d %q", w.Code, w.Body.String())
+	}
+	if w.Header().Get("Accept-Ranges") != "bytes" || etag == "" || w.Header().Get("Cache-Control") != callAudioCacheControl {
+		t.Fatalf("missing caching headers: %v", w.Header())
+	}
+
+	if w.Header().Get("Last-Modified") != "" {
+		t.Fatalf("unexpected Last-Modified: %s", w.Header().Get("Last-Modified"))
+	}
+
+	w = serve(map[string]string{"If-Modified-Since": time.Now().UTC().Format(http.TimeFormat)})
+	if w.Code != http.StatusOK {
+		t.Fatalf("If-Modified-Since alone should not validate, got %d", w.Code)
+	}
+
+	w = serve(map[string]string{"Range": "bytes=2-4"})
+	if w.Code != http.StatusPartialContent || w.Body.String() != "234" || w.Header().Get("Content-Range") != "bytes 2-4/10" {
+		t.Fatalf("range response = %d %q %s", w.Code, w.Body.String(), w.Header().Get("Content-Range"))
+	}
+
+	w = serve(map[string]string{"If-None-Match": etag})
+	if w.Code != http.StatusNotModified || w.Body.Len() != 0 {
+		t.Fatalf("conditional response = %d", w.Code)
+	}
+
+	if callAudioETag(8, audio) == etag || callAudioETag(7, []byte("other")) == etag {
+		t.Fatal("the etag should depend on the call id and the audio")
+	}
+}
@@ -0,0 +1,350 @@
+// Copyright (C) 2026 Thinline Dynamic Solutions
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>
+
+package main
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var callExportLog = Logger(LogCategoryCalls)
+
+const (
+	callExportFetchSize = 5000
+	// Each batch gets this long to be written, so long exports outlive the
+	// server write timeout while stalled clients are still dropped
+	callExportWriteWait = 2 * time.Minute
+)
+
+// callExportColumns are the call metadata columns of an export. Audio is
+// never exported.
+var callExportColumns = []ParquetColumn{
+	{Name: "callId", Type: ParquetInt64},
+	{Name: "timestamp", Type: ParquetTimestamp},
+	{Name: "receivedAt", Type: ParquetTimestamp, Optional: true},
+	{Name: "systemRef", Type: ParquetInt64},
+	{Name: "systemLabel", Type: ParquetString},
+	{Name: "talkgroupRef", Type: ParquetInt64},
+	{Name: "talkgroupLabel", Type: ParquetString},
+	{Name: "talkgroupName", Type: ParquetString},
+	{Name: "tag", Type: ParquetString, Optional: true},
+	{Name: "groups", Type: ParquetString, Optional: true},
+	{Name: "siteRef", Type: ParquetInt64, Optional: true},
+	{Name: "frequency", Type: ParquetInt64, Optional: true},
+	{Name: "duration", Type: ParquetDouble, Optional: true},
+	{Name: "units", Type: ParquetString, Optional: true},
+	{Name: "hasTones", Type: ParquetBoolean},
+	{Name: "transcript", Type: ParquetString, Optional: true},
+}
+
+// callExportRow is a call as read from the export cursor.
+type callExportRow struct {
+	CallId      uint64
+	Timestamp   int64
+	ReceivedAt  sql.NullTime
+	SystemId    uint64
+	TalkgroupId uint64
+	SiteRef     int64
+	Frequency   int64
+	Duration    float64
+	HasTones    bool
+	Units       string
+	Transcript  string
+}
+
+// callExportRowWriter writes rows in one of the export formats.
+type callExportRowWriter interface {
+	Write(row []any) error
+	Close() error
+}
+
+type callExportCSV struct {
+	w        *csv.Writer
+	location *time.Location
+}
+
+func newCallExportCSV(w io.Writer, columns []ParquetColumn, location *time.Location) *callExportCSV {
+	c := &callExportCSV{w: csv.NewWriter(w), location: location}
+	header := make([]string, len(columns))
+	for i, column := range columns {
+		header[i] = column.Name
+	}
+	c.w.Write(header)
+	return c
+}
+
+func (c *callExportCSV) Write(row []any) error {
+	record := make([]string, len(row))
+	for i, value := range row {
+		switch v := value.(type) {
+		case int64:
+			record[i] = strconv.FormatInt(v, 10)
+		case float64:
+			record[i] = strconv.FormatFloat(v, 'f', -1, 64)
+		case bool:
+			record[i] = strconv.FormatBool(v)
+		case string:
+			record[i] = v
+		case time.Time:
+			record[i] = v.In(c.location).Format("2006-01-02T15:04:05.000Z07:00")
+		}
+	}
+	return c.w.Write(record)
+}
+
+func (c *callExportCSV) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// values returns the row in the order of callExportColumns, with the labels
+// of its system and talkgroup. Calls of deleted talkgroups are skipped.
+func (row *callExportRow) values(controller *Controller, transcripts bool) ([]any, bool) {
+	system, ok := controller.Systems.GetSystemById(row.SystemId)
+	if !ok {
+		return nil, false
+	}
+	talkgroup, ok := system.Talkgroups.GetTalkgroupById(row.TalkgroupId)
+	if !ok {
+		return nil, false
+	}
+
+	optional := func(v any, set bool) any {
+		if !set {
+			return nil
+		}
+		return v
+	}
+
+	var tag any
+	if t, ok := controller.Tags.GetTagById(talkgroup.TagId); ok {
+		tag = t.Label
+	}
+	groups := []string{}
+	for _, id := range talkgroup.GroupIds {
+		if group, ok := controller.Groups.GetGroupById(id); ok {
+			groups = append(groups, group.Label)
+		}
+	}
+
+	values := []any{
+		int64(row.CallId),
+		time.UnixMilli(row.Timestamp),
+		optional(row.ReceivedAt.Time, row.ReceivedAt.Valid),
+		int64(system.SystemRef),
+		system.Label,
+		int64(talkgroup.TalkgroupRef),
+		talkgroup.Label,
+		talkgroup.Name,
+		tag,
+		optional(strings.Join(groups, ";"), len(groups) > 0),
+		optional(row.SiteRef, row.SiteRef > 0),
+		optional(row.Frequency, row.Frequency > 0),
+		optional(row.Duration, row.Duration > 0),
+		optional(row.Units, row.Units != ""),
+		row.HasTones,
+	}
+	if transcripts {
+		values = append(values, optional(row.Transcript, row.Transcript != ""))
+	}
+	return values, true
+}
+
+// buildCallExportSQL returns the export query, in time order, and its
+// arguments. Duplicate calls are left out.
+func buildCallExportSQL(from int64, to int64, systemIds []uint64, talkgroupId uint64, transcripts bool) (string, []any) {
+	transcript := `''`
+	if transcripts {
+		transcript = `c."transcript"`
+	}
+	args := queryArgs{}
+	where := []string{
+		`c."timestamp" >= ` + args.add(from),
+		`c."timestamp" < ` + args.add(to),
+		`NOT c."isDuplicate"`,
+	}
+	if systemIds != nil {
+		if len(systemIds) == 0 {
+			where = append(where, "FALSE")
+		} else {
+			where = append(where, `c."systemId" IN `+args.in(systemIds))
+		}
+	}
+	if talkgroupId > 0 {
+		where = append(where, `c."talkgroupId" = `+args.add(talkgroupId))
+	}
+
+	query := fmt.Sprintf(
+		`SELECT c."callId", c."timestamp", c."receivedAt", c."systemId", c."talkgroupId", c."siteRef", c."frequency", c."audioDuration"::double precision, c."hasTones", `+
+			`COALESCE((SELECT STRING_AGG(CAST(cu."unitRef" AS text), ';' ORDER BY cu."offset") FROM "callUnits" cu WHERE cu."callId" = c."callId"), ''), %s `+
+			`FROM "calls" c WHERE %s ORDER BY c."timestamp", c."callId"`,
+		transcript, strings.Join(where, " AND "),
+	)
+
+	return query, args
+}
+
+// CallExportHandler serves GET /api/admin/export/calls, the call metadata of
+// a time range as CSV or Parquet. Rows are read through a database cursor
+// and streamed, so exports of millions of calls use little memory.
+func (admin *Admin) CallExportHandler(w http.ResponseWriter, r *http.Request) {
+	t := admin.GetAuthorization(r)
+	if !admin.ValidatePermission(t, PermissionExportCalls) {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	writeError := func(status int, message string) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(map[string]string{"error": message})
+	}
+
+	values := r.URL.Query()
+	q, err := parseCallStatsQuery(values, time.Now())
+	if err != nil {
+		writeError(http.StatusBadRequest, err.Error())
+		return
+	}
+	format := values.Get("format")
+	if format == "" {
+		format = "csv"
+	}
+	if format != "csv" && format != "parquet" {
+		writeError(http.StatusBadRequest, "format must be csv or parquet")
+		return
+	}
+	transcripts := values.Get("transcripts") == "true"
+
+	// Tenant administrators only export their own systems
+	var systemIds []uint64
+	var talkgroupId uint64
+	if q.SystemRef > 0 {
+		system, ok := admin.Controller.Systems.GetSystemByRef(q.SystemRef)
+		if !ok || !admin.tenantAllowsSystem(t, system.Id) {
+			writeError(http.StatusBadRequest, "unknown systemRef")
+			return
+		}
+		systemIds = []uint64{system.Id}
+		if q.TalkgroupRef > 0 {
+			talkgroup, ok := system.Talkgroups.GetTalkgroupByRef(q.TalkgroupRef)
+			if !ok {
+				writeError(http.StatusBadRequest, "unknown talkgroupRef")
+				return
+			}
+			talkgroupId = talkgroup.Id
+		}
+	} else if admin.tokenTenant(t) != nil {
+		systemIds = []uint64{}
+		for _, system := range admin.tenantSystems(t) {
+			systemIds = append(systemIds, system.Id)
+		}
+	}
+
+	ctx := r.Context()
+	tx, err := admin.Controller.Database.Sql.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		writeError(http.StatusInternalServerError, err.Error())
+		return
+	}
+	defer tx.Rollback()
+
+	query, args := buildCallExportSQL(q.From.UnixMilli(), q.To.UnixMilli(), systemIds, talkgroupId, transcripts)
+	if _, err := tx.ExecContext(ctx, `DECLARE "callExport" NO SCROLL CURSOR FOR `+query, args...); err != nil {
+		writeError(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	columns := callExportColumns
+	if !transcripts {
+		columns = columns[:len(columns)-1]
+	}
+	filename := fmt.Sprintf("calls-%s-%s.%s", q.From.In(q.Location).Format("20060102"), q.To.In(q.Location).Format("20060102"), format)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", filename))
+
+	var out callExportRowWriter
+	if format == "parquet" {
+		w.Header().Set("Content-Type", "application/vnd.apache.parquet")
+		out = NewParquetWriter(w, columns)
+	} else {
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		out = newCallExportCSV(w, columns, q.Location)
+	}
+
+	// Once streaming has started errors can't be reported with a status;
+	// the export is cut short and a Parquet file is left without its footer
+	controller := http.NewResponseController(w)
+	count := 0
+	for {
+		controller.SetWriteDeadline(time.Now().Add(callExportWriteWait))
+
+		rows, err := tx.QueryContext(ctx, fmt.Sprintf(`FETCH %d FROM "callExport"`, callExportFetchSize))
+		if err != nil {
+			callExportLog.Error(fmt.Sprintf("call export: %v", err))
+			return
+		}
+		fetched := 0
+		for rows.Next() {
+			fetched++
+			var row callExportRow
+			if err := rows.Scan(&row.CallId, &row.Timestamp, &row.ReceivedAt, &row.SystemId, &row.TalkgroupId, &row.SiteRef, &row.Frequency, &row.Duration, &row.HasTones, &row.Units, &row.Transcript); err != nil {
+				rows.Close()
+				callExportLog.Error(fmt.Sprintf("call export: %v", err))
+				return
+			}
+			values, ok := row.values(admin.Controller, transcripts)
+			if !ok {
+				continue
+			}
+			if err := out.Write(values); err != nil {
+				rows.Close()
+				callExportLog.Error(fmt.Sprintf("call export: %v", err))
+				return
+			}
+			count++
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			callExportLog.Error(fmt.Sprintf("call export: %v", err))
+			return
+		}
+		if csv, ok := out.(*callExportCSV); ok {
+			csv.w.Flush()
+		}
+		if fetched < callExportFetchSize {
+			break
+		}
+	}
+
+	if err := out.Close(); err != nil {
+		callExportLog.Error(fmt.Sprintf("call export: %v", err))
+		return
+	}
+	admin.Controller.Logs.LogEvent(LogLevelInfo, fmt.Sprintf("exported %d calls as %s", count, format))
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBuildCallExportSQL(t *testing.T) {
+	query, args := buildCallExportSQL(1000, 2000, []uint64{3, 4}, 7, false)
+	for _, want := range []string{
+		`c."timestamp" >= $1`, `c."timestamp" < $2`, `NOT c."isDuplicate"`,
+		`c."systemId" IN ($3, $4)`, `c."talkgroupId" = $5`, `ORDER BY c."timestamp", c."callId"`, `'' FROM "calls"`,
+	} {
+		if !strings.Contains(query, want) {
+			t.Errorf("query has no %s: %s", want, query)
+		}
+	}
+	if !reflect.DeepEqual(args, []any{int64(1000), int64(2000), uint64(3), uint64(4), uint64(7)}) {
+		t.Errorf("unexpected args %v", args)
+	}
+
+	if query, _ := buildCallExportSQL(1000, 2000, []uint64{}, 0, true); !strings.Contains(query, "FALSE") || !strings.Contains(query, `c."transcript" FROM`) {
+		t.Errorf("unexpected query %s", query)
+	}
+	if query, _ := buildCallExportSQL(1000, 2000, nil, 0, false); strings.Contains(query, "systemId\" IN") || strings.Contains(query, "FALSE") {
+		t.Errorf("unexpected system filter %s", query)
+	}
+}
+
+func TestCallExportCSV(t *testing.T) {
+	var b bytes.Buffer
+	location := time.FixedZone("EST", -5*3600)
+	out := newCallExportCSV(&b, callExportColumns[:3], location)
+	out.Write([]any{int64(12), time.UnixMilli(1700000000123), nil})
+	out.Write([]any{int64(13), time.UnixMilli(1700000000000), "a, b"})
+	if err := out.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	want := "callId,timestamp,receivedAt\n12,2023-11-14T17:13:20.123-05:00,\n13,2023-11-14T17:13:20.000-05:00,\"a, b\"\n"
+	if b.String() != want {
+		t.Fatalf("got %q, want %q", b.String(), want)
+	}
+}
@@ -0,0 +1,275 @@
+// Copyright (C) 2026 Thinline Dynamic Solutions
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CallHLSConfig serves very long calls as HLS playlists of short segments,
+// so web players start right away and scrub without the whole file.
+type CallHLSConfig struct {
+	Disabled           bool `json:"disabled,omitempty"`
+	MinDurationSeconds uint `json:"minDurationSeconds,omitempty"` // default 300
+	SegmentSeconds     uint `json:"segmentSeconds,omitempty"`     // default 6
+}
+
+const (
+	callHLSDefaultMinDuration = 300
+	callHLSDefaultSegment     = 6
+	// callHLSCacheTTL keeps the segments of a call after their last use.
+	callHLSCacheTTL  = 24 * time.Hour
+	callHLSPlaylist  = "index.m3u8"
+	callHLSCacheDir  = "hls"
+	callHLSBuildTime = 2 * time.Minute
+)
+
+var callHLSSegmentName = regexp.MustCompile(`^[0-9]+\.ts$`)
+
+func (config CallHLSConfig) minDuration() float64 {
+	if config.MinDurationSeconds == 0 {
+		return callHLSDefaultMinDuration
+	}
+	return float64(config.MinDurationSeconds)
+}
+
+func (config CallHLSConfig) segment() uint {
+	if config.SegmentSeconds == 0 {
+		return callHLSDefaultSegment
+	}
+	return config.SegmentSeconds
+}
+
+// CallHLS segments call audio on demand and caches the segments on disk.
+type CallHLS struct {
+	controller *Controller
+	mutex      sync.Mutex
+	building   map[string]chan struct{}
+}
+
+func NewCallHLS(controller *Controller) *CallHLS {
+	return &CallHLS{controller: controller, building: map[string]chan struct{}{}}
+}
+
+func (hls *CallHLS) root() string {
+	return hls.controller.Config.GetPath(callHLSCacheDir)
+}
+
+// callHLSKey names the cache directory of a call's audio, so new audio for
+// the same call never serves stale segments.
+func callHLSKey(callId uint64, audio []byte) string {
+	sum := sha256.Sum256(audio)
+	return fmt.Sprintf("%d-%s", callId, hex.EncodeToString(sum[:12]))
+}
+
+// Segments returns the cache directory holding the playlist and segments of
+// the call, building them on first use. Concurrent requests for the same
+// call wait for a single build.
+func (hls *CallHLS) Segments(call *Call) (string, error) {
+	key := callHLSKey(call.Id, call.Audio)
+	dir := filepath.Join(hls.root(), key)
+
+	for {
+		if _, err := os.Stat(filepath.Join(dir, callHLSPlaylist)); err == nil {
+			now := time.Now()
+			os.Chtimes(dir, now, now)
+			return dir, nil
+		}
+
+		hls.mutex.Lock()
+		if done, ok := hls.building[key]; ok {
+			hls.mutex.Unlock()
+			<-done
+			if _, err := os.Stat(filepath.Join(dir, callHLSPlaylist)); err != nil {
+				return "", fmt.Errorf("segmenting call %d failed", call.Id)
+			}
+			continue
+		}
+		done := make(chan struct{})
+		hls.building[key] = done
+		hls.mutex.Unlock()
+
+		err := hls.build(call, dir)
+
+		hls.mutex.Lock()
+		delete(hls.building, key)
+		close(done)
+		hls.mutex.Unlock()
+
+		if err != nil {
+			return "", err
+		}
+		return dir, nil
+	}
+}
+
+// build runs ffmpeg into a temporary directory renamed into place once
+// complete, so a playlist is never served half written.
+func (hls *CallHLS) build(call *Call, dir string) error {
+	if hls.controller.FFMpeg == nil || !hls.controller.FFMpeg.available {
+		return fmt.Errorf("ffmpeg is not available")
+	}
+
+	tmp := fmt.Sprintf("%s.tmp-%d", dir, time.Now().UnixNano())
+	if err := os.MkdirAll(tmp, 0750); err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+
+	// A file rather than stdin, since non-fragmented MP4 cannot be read from a pipe
+	input := filepath.Join(tmp, "input")
+	if err := os.WriteFile(input, call.Audio, 0640); err != nil {
+		return err
+	}
+
+	args := []string{"-hide_banner", "-loglevel", "error", "-i", input, "-vn"}
+	switch call.AudioMime {
+	case "", "audio/mp4", "audio/m4a", "audio/aac", "audio/x-m4a":
+		args = append(args, "-c:a", "copy")
+	default:
+		args = append(args, "-c:a", "aac", "-b:a", "48k")
+	}
+	args = append(args,
+		"-f", "hls",
+		"-hls_time", fmt.Sprint(hls.controller.Options.CallHLSConfig.segment()),
+		"-hls_playlist_type", "vod",
+		"-hls_segment_filename", filepath.Join(tmp, "%d.ts"),
+		filepath.Join(tmp, callHLSPlaylist),
+	)
+
+	cmd := exec.Command("ffmpeg", args...)
+	stderr := bytes.NewBuffer(nil)
+	cmd.Stderr = stderr
+
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	timer := time.AfterFunc(callHLSBuildTime, func() { cmd.Process.Kill() })
+	err := cmd.Wait()
+	timer.Stop()
+	if err != nil {
+		return fmt.Errorf("ffmpeg: %v %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	os.Remove(input)
+
+	if err := os.Rename(tmp, dir); err != nil && !os.IsExist(err) {
+		if _, statErr := os.Stat(filepath.Join(dir, callHLSPlaylist)); statErr != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Serve writes the playlist or one segment of the call. Segment URIs in the
+// playlist carry the query of the playlist request, so the PIN it was
+// authenticated with applies to the segments too.
+func (hls *CallHLS) Serve(w http.ResponseWriter, r *http.Request, call *Call, name string) {
+	config := hls.controller.Options.CallHLSConfig
+	if config.Disabled {
+		http.Error(w, "HLS playback is disabled", http.StatusNotFound)
+		return
+	}
+
+	if name != callHLSPlaylist && !callHLSSegmentName.MatchString(name) {
+		http.Error(w, "unknown HLS file", http.StatusNotFound)
+		return
+	}
+
+	if duration, err := hls.controller.getCallDuration(call); err != nil || duration < config.minDuration() {
+		http.Error(w, fmt.Sprintf("only calls longer than %g seconds are segmented, use /api/calls/%d/audio", config.minDuration(), call.Id), http.StatusNotFound)
+		return
+	}
+
+	dir, err := hls.Segments(call)
+	if err != nil {
+		hls.controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("hls: call %d: %v", call.Id, err))
+		http.Error(w, "segmenting the call failed", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Cache-Control", callAudioCacheControl)
+
+	if name == callHLSPlaylist {
+		playlist, err := os.ReadFile(filepath.Join(dir, callHLSPlaylist))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/vnd.apple.mpegurl")
+		w.Write(callHLSPlaylistWithQuery(playlist, r.URL.RawQuery))
+		return
+	}
+
+	w.Header().Set("Content-Type", "video/mp2t")
+	http.ServeFile(w, r, filepath.Join(dir, name))
+}
+
+// callHLSPlaylistWithQuery appends the query to the segment URIs.
+func callHLSPlaylistWithQuery(playlist []byte, query string) []byte {
+	if query == "" {
+		return playlist
+	}
+
+	out := bytes.NewBuffer(nil)
+	scanner := bufio.NewScanner(bytes.NewReader(playlist))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line != "" && !strings.HasPrefix(line, "#") {
+			line += "?" + query
+		}
+		out.WriteString(line + "\n")
+	}
+	return out.Bytes()
+}
+
+// Prune removes the segments of calls not played for callHLSCacheTTL, and
+// builds an interrupted restart left behind.
+func (hls *CallHLS) Prune() error {
+	entries, err := os.ReadDir(hls.root())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	cutoff := time.Now().Add(-callHLSCacheTTL)
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || info.ModTime().After(cutoff) {
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(hls.root(), entry.Name())); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
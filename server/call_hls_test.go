@@ -0,0 +1,71 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCallHLSPlaylistWithQuery(t *testing.T) {
+	playlist := []byte("#EXTM3U\n#EXT-X-TARGETDURATION:6\n#EXTINF:6.0,\n0.ts\n#EXTINF:4.2,\n1.ts\n#EXT-X-ENDLIST\n")
+
+	got := string(callHLSPlaylistWithQuery(playlist, "pin=1234"))
+	want := "#EXTM3U\n#EXT-X-TARGETDURATION:6\n#EXTINF:6.0,\n0.ts?pin=1234\n#EXTINF:4.2,\n1.ts?pin=1234\n#EXT-X-ENDLIST\n"
+	if got != want {
+		t.Fatalf("playlist =\n%s\nwant\n%s", got, want)
+	}
+
+	if string(callHLSPlaylistWithQuery(playlist, "")) != string(playlist) {
+		t.Fatal("a playlist without query should be served as is")
+	}
+}
+
+func TestCallHLSConfigDefaults(t *testing.T) {
+	config := CallHLSConfig{}
+	if config.minDuration() != callHLSDefaultMinDuration || config.segment() != callHLSDefaultSegment {
+		t.Fatalf("defaults = %g %d", config.minDuration(), config.segment())
+	}
+
+	config = CallHLSConfig{MinDurationSeconds: 120, SegmentSeconds: 4}
+	if config.minDuration() != 120 || config.segment() != 4 {
+		t.Fatalf("configured = %g %d", config.minDuration(), config.segment())
+	}
+}
+
+func TestCallHLSSegmentNames(t *testing.T) {
+	for name, ok := range map[string]bool{"0.ts": true, "12.ts": true, "../0.ts": false, "input": false, "0.ts.tmp": false} {
+		if callHLSSegmentName.MatchString(name) != ok {
+			t.Fatalf("%q accepted = %t", name, !ok)
+		}
+	}
+
+	if callHLSKey(1, []byte("a")) == callHLSKey(1, []byte("b")) || callHLSKey(1, []byte("a")) == callHLSKey(2, []byte("a")) {
+		t.Fatal("the cache key should depend on the call and its audio")
+	}
+}
+
+func TestCallHLSPrune(t *testing.T) {
+	base := t.TempDir()
+	hls := NewCallHLS(&Controller{Config: &Config{BaseDir: base}})
+
+	stale := filepath.Join(hls.root(), "1-old")
+	fresh := filepath.Join(hls.root(), "2-new")
+	for _, dir := range []string{stale, fresh} {
+		if err := os.MkdirAll(dir, 0750); err != nil {
+			t.Fatal(err)
+		}
+	}
+	old := time.Now().Add(-2 * callHLSCacheTTL)
+	os.Chtimes(stale, old, old)
+
+	if err := hls.Prune(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(stale); !os.IsNotExist(err) {
+		t.Fatal("stale segments should be removed")
+	}
+	if _, err := os.Stat(fresh); err != nil {
+		t.Fatal("recent segments should be kept")
+	}
+}
@@ -0,0 +1,485 @@
+// Copyright (C) 2026 Thinline Dynamic Solutions
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>
+
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var callSearchLog = Logger(LogCategoryCalls)
+
+const (
+	callSearchDefaultLimit = 50
+	callSearchMaxLimit     = 200
+	callSearchChunkSize    = 250
+	callSearchMaxChunks    = 40
+)
+
+// callSearchVector is the text search document of a call. The calls_search_idx
+// index is built on this exact expression, so queries must use it verbatim.
+func callSearchVector(alias string) string {
+	return fmt.Sprintf(`to_tsvector('english', %[1]s"transcript" || ' ' || %[1]s"transcriptTranslation")`, alias)
+}
+
+// CallSearchQuery is a parsed /api/search request.
+type CallSearchQuery struct {
+	Text         string
+	SystemRef    uint
+	TalkgroupRef uint
+	UnitRef      uint
+	DateFrom     int64 // unix ms
+	DateTo       int64
+	TonesOnly    bool
+	ToneSet      string
+	// TalkgroupIds restricts the search to these talkgroups when set, for
+	// the tag and group filters. An empty non-nil list matches nothing.
+	TalkgroupIds []uint64
+	Limit        uint
+	Offset       uint
+}
+
+func parseCallSearchQuery(values url.Values) (CallSearchQuery, error) {
+	q := CallSearchQuery{
+		Text:    strings.TrimSpace(values.Get("q")),
+		ToneSet: strings.TrimSpace(values.Get("toneSet")),
+		Limit:   callSearchDefaultLimit,
+	}
+
+	parseUint := func(name string, dest *uint) error {
+		if v := values.Get(name); v != "" {
+			n, err := strconv.ParseUint(v, 10, 32)
+			if err != nil {
+				return fmt.Errorf("invalid %s", name)
+			}
+			*dest = uint(n)
+		}
+		return nil
+	}
+	parseTime := func(name string, dest *int64) error {
+		if v := values.Get(name); v != "" {
+			if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+				*dest = n
+			} else if t, err := time.Parse(time.RFC3339, v); err == nil {
+				*dest = t.UnixMilli()
+			} else if t, err := time.Parse("2006-01-02", v); err == nil {
+				*dest = t.UnixMilli()
+			} else {
+				return fmt.Errorf("invalid %s", name)
+			}
+		}
+		return nil
+	}
+
+	for _, err := range []error{
+		parseUint("systemRef", &q.SystemRef),
+		parseUint("talkgroupRef", &q.TalkgroupRef),
+		parseUint("unit", &q.UnitRef),
+		parseUint("limit", &q.Limit),
+		parseUint("offset", &q.Offset),
+		parseTime("dateFrom", &q.DateFrom),
+		parseTime("dateTo", &q.DateTo),
+	} {
+		if err != nil {
+			return q, err
+		}
+	}
+
+	if v := values.Get("tones"); v != "" {
+		tones, err := strconv.ParseBool(v)
+		if err != nil {
+			return q, fmt.Errorf("invalid tones")
+		}
+		q.TonesOnly = tones
+	}
+
+	if q.TalkgroupRef > 0 && q.SystemRef == 0 {
+		return q, fmt.Errorf("talkgroupRef requires systemRef")
+	}
+	if q.Limit == 0 {
+		q.Limit = callSearchDefaultLimit
+	}
+	if q.Limit > callSearchMaxLimit {
+		q.Limit = callSearchMaxLimit
+	}
+	if q.Text == "" && q.SystemRef == 0 && q.UnitRef == 0 && !q.TonesOnly && q.ToneSet == "" {
+		return q, fmt.Errorf("a search text or a filter is required")
+	}
+
+	return q, nil
+}
+
+// callSearchMatches are the talkgroups and units whose labels match the
+// search text, by system id.
+type callSearchMatches struct {
+	Talkgroups map[uint64][]uint64
+	Units      map[uint64][]uint
+	UnitRefs   []uint // numbers in the search text, in any system
+}
+
+// matchCallSearchLabels finds the talkgroups and units named by every word
+// of the search text. Numbers in the text also match unit ids.
+func matchCallSearchLabels(systems []*System, text string) callSearchMatches {
+	matches := callSearchMatches{Talkgroups: map[uint64][]uint64{}, Units: map[uint64][]uint{}}
+
+	words := []string{}
+	for _, word := range strings.Fields(strings.ToLower(text)) {
+		word = strings.Trim(word, `"'.,;:!?()`)
+		if word == "" || word == "or" || strings.HasPrefix(word, "-") {
+			continue
+		}
+		if n, err := strconv.ParseUint(word, 10, 32); err == nil && n > 0 {
+			matches.UnitRefs = append(matches.UnitRefs, uint(n))
+		}
+		words = append(words, word)
+	}
+	if len(words) == 0 {
+		return matches
+	}
+
+	containsAll := func(s string) bool {
+		s = strings.ToLower(s)
+		for _, word := range words {
+			if !strings.Contains(s, word) {
+				return false
+			}
+		}
+		return true
+	}
+
+	for _, system := range systems {
+		if system.Talkgroups != nil {
+			for _, talkgroup := range system.Talkgroups.List {
+				if containsAll(talkgroup.Label + " " + talkgroup.Name) {
+					matches.Talkgroups[system.Id] = append(matches.Talkgroups[system.Id], talkgroup.Id)
+				}
+			}
+		}
+		if system.Units != nil {
+			for _, unit := range system.Units.List {
+				if unit.Label != "" && containsAll(unit.Label) {
+					matches.Units[system.Id] = append(matches.Units[system.Id], unit.UnitRef)
+				}
+			}
+		}
+	}
+
+	return matches
+}
+
+// buildCallSearchSQL returns the search query for one chunk of results,
+// ordered by relevance when there is a search text, newest first otherwise.
+// Calls matched through a talkgroup or unit label rank above transcript
+// matches of the same strength.
+func buildCallSearchSQL(q CallSearchQuery, systemId uint64, talkgroupId uint64, matches callSearchMatches, chunkOffset uint) (string, []any) {
+	args := queryArgs{}
+
+	where := []string{`d."callId" IS NULL`}
+	if systemId > 0 {
+		where = append(where, fmt.Sprintf(`c."systemId" = %s`, args.add(systemId)))
+	}
+	if talkgroupId > 0 {
+		where = append(where, fmt.Sprintf(`c."talkgroupId" = %s`, args.add(talkgroupId)))
+	}
+	if q.TalkgroupIds != nil {
+		if len(q.TalkgroupIds) == 0 {
+			where = append(where, "FALSE")
+		} else {
+			where = append(where, `c."talkgroupId" IN `+args.in(q.TalkgroupIds))
+		}
+	}
+	if q.DateFrom > 0 {
+		where = append(where, fmt.Sprintf(`c."timestamp" >= %s`, args.add(q.DateFrom)))
+	}
+	if q.DateTo > 0 {
+		where = append(where, fmt.Sprintf(`c."timestamp" <= %s`, args.add(q.DateTo)))
+	}
+	if q.UnitRef > 0 {
+		where = append(where, fmt.Sprintf(`EXISTS (SELECT 1 FROM "callUnits" cu WHERE cu."callId" = c."callId" AND cu."unitRef" = %s)`, args.add(q.UnitRef)))
+	}
+	if q.TonesOnly {
+		where = append(where, `c."hasTones"`)
+	}
+	if q.ToneSet != "" {
+		label, _ := json.Marshal(q.ToneSet)
+		where = append(where, fmt.Sprintf(`c."toneSequence" LIKE '%%' || %s || '%%'`, args.add(`"label":`+string(label))))
+	}
+
+	rank := "0"
+	headline := "''"
+	if q.Text != "" {
+		tsquery := fmt.Sprintf(`websearch_to_tsquery('english', %s)`, args.add(q.Text))
+
+		matched := []string{fmt.Sprintf(`%s @@ %s`, callSearchVector("c."), tsquery)}
+		labelMatch := []string{}
+		for id, talkgroupIds := range matches.Talkgroups {
+			if systemId > 0 && id != systemId {
+				continue
+			}
+			labelMatch = append(labelMatch, `c."talkgroupId" IN `+args.in(talkgroupIds))
+		}
+		unitMatch := []string{}
+		for id, unitRefs := range matches.Units {
+			if systemId > 0 && id != systemId {
+				continue
+			}
+			unitMatch = append(unitMatch, fmt.Sprintf(`(c."systemId" = %s AND cu."unitRef" IN %s)`, args.add(id), args.in(queryIds(unitRefs))))
+		}
+		if len(matches.UnitRefs) > 0 {
+			unitMatch = append(unitMatch, `cu."unitRef" IN `+args.in(queryIds(matches.UnitRefs)))
+		}
+
+		rank = fmt.Sprintf(`ts_rank_cd(%s, %s)`, callSearchVector("c."), tsquery)
+		if len(labelMatch) > 0 {
+			condition := "(" + strings.Join(labelMatch, " OR ") + ")"
+			matched = append(matched, condition)
+			rank += fmt.Sprintf(` + CASE WHEN %s THEN 1 ELSE 0 END`, condition)
+		}
+		if len(unitMatch) > 0 {
+			condition := fmt.Sprintf(`EXISTS (SELECT 1 FROM "callUnits" cu WHERE cu."callId" = c."callId" AND (%s))`, strings.Join(unitMatch, " OR "))
+			matched = append(matched, condition)
+			rank += fmt.Sprintf(` + CASE WHEN %s THEN 1 ELSE 0 END`, condition)
+		}
+		where = append(where, "("+strings.Join(matched, " OR ")+")")
+
+		headline = fmt.Sprintf(`CASE WHEN c."transcript" <> '' THEN ts_headline('english', c."transcript", %s, 'MaxFragments=2, MaxWords=20, MinWords=8, StartSel=<mark>, StopSel=</mark>') ELSE '' END`, tsquery)
+	}
+
+	order := `c."timestamp" DESC`
+	if q.Text != "" {
+		order = `"rank" DESC, c."timestamp" DESC`
+	}
+
+	query := fmt.Sprintf(
+		`SELECT c."callId", c."systemId", c."talkgroupId", c."timestamp", c."transcript", c."transcriptTranslation", c."hasTones", %s AS "rank", %s AS "headline" `+
+			`FROM "calls" c `+
+			`LEFT JOIN "delayed" AS d ON d."callId" = c."callId" `+
+			`WHERE %s ORDER BY %s LIMIT %s OFFSET %s`,
+		rank, headline, strings.Join(where, " AND "), order, args.add(callSearchChunkSize), args.add(chunkOffset),
+	)
+
+	return query, args
+}
+
+// CallSearchHandler searches calls by transcript text, talkgroup and unit
+// labels with filters on date, system, talkgroup, unit and tones.
+//
+//	GET /api/search?q=main+street&dateFrom=2026-10-11&systemRef=1&tones=true
+func (api *Api) CallSearchHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		api.exitWithError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	client := api.getClient(r)
+	if client == nil || (!client.IsAdmin && client.User == nil) {
+		api.exitWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	q, err := parseCallSearchQuery(r.URL.Query())
+	if err != nil {
+		api.exitWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var systemId, talkgroupId uint64
+	if q.SystemRef > 0 {
+		system, ok := api.Controller.Systems.GetSystemByRef(q.SystemRef)
+		if !ok {
+			api.exitWithError(w, http.StatusBadRequest, "unknown systemRef")
+			return
+		}
+		systemId = system.Id
+		if q.TalkgroupRef > 0 {
+			talkgroup, ok := system.Talkgroups.GetTalkgroupByRef(q.TalkgroupRef)
+			if !ok {
+				api.exitWithError(w, http.StatusBadRequest, "unknown talkgroupRef")
+				return
+			}
+			talkgroupId = talkgroup.Id
+		}
+	}
+
+	hits, hasMore, err := api.searchCalls(client, q, systemId, talkgroupId)
+	if err != nil {
+		callSearchLog.Error(fmt.Sprintf("CallSearchHandler: %v", err))
+		api.exitWithError(w, http.StatusInternalServerError, "search failed")
+		return
+	}
+
+	results := make([]map[string]any, 0, len(hits))
+	for _, hit := range hits {
+		entry := map[string]any{
+			"callId":         hit.CallId,
+			"systemRef":      hit.System.SystemRef,
+			"systemLabel":    hit.System.Label,
+			"talkgroupRef":   hit.Talkgroup.TalkgroupRef,
+			"talkgroupLabel": hit.Talkgroup.Label,
+			"talkgroupName":  hit.Talkgroup.Name,
+			"timestamp":      hit.Timestamp,
+			"hasTones":       hit.HasTones,
+		}
+		if hit.Transcript != "" {
+			entry["transcript"] = hit.Transcript
+		}
+		if hit.Translation != "" {
+			entry["transcriptTranslation"] = hit.Translation
+		}
+		if q.Text != "" {
+			entry["rank"] = hit.Rank
+			if hit.Headline != "" {
+				entry["headline"] = hit.Headline
+			}
+		}
+		results = append(results, entry)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"results": results,
+		"hasMore": hasMore,
+	})
+}
+
+// ensureCallSearchIndexesBackground builds the full-text index on the call
+// transcripts and the unit index on callUnits. Both are built concurrently so
+// large calls tables stay writable; until then searches fall back to scans.
+func ensureCallSearchIndexesBackground(db *Database) {
+	indexes := []struct{ name, query string }{
+		{"calls_search_idx", fmt.Sprintf(`CREATE INDEX CONCURRENTLY "calls_search_idx" ON "calls" USING GIN (%s)`, callSearchVector(""))},
+		{"callUnits_unitRef_idx", `CREATE INDEX CONCURRENTLY "callUnits_unitRef_idx" ON "callUnits" ("unitRef")`},
+	}
+
+	for _, index := range indexes {
+		var exists bool
+		if err := db.Sql.QueryRow(`SELECT EXISTS (SELECT 1 FROM pg_indexes WHERE indexname = $1)`, index.name).Scan(&exists); err != nil {
+			writeLogStdout(fmt.Sprintf("migration note (%s check): %v", index.name, err))
+			continue
+		}
+		if exists {
+			continue
+		}
+
+		writeLogStdout(fmt.Sprintf("building %s concurrently in background...", index.name))
+		if _, err := db.Sql.Exec(index.query); err != nil {
+			writeLogStdout(fmt.Sprintf("migration note (%s): %v", index.name, err))
+			continue
+		}
+		writeLogStdout(fmt.Sprintf("%s build completed", index.name))
+	}
+}
+
+// callSearchHit is a call found by searchCalls.
+type callSearchHit struct {
+	CallId      uint64
+	System      *System
+	Talkgroup   *Talkgroup
+	Timestamp   int64
+	Transcript  string
+	Translation string
+	HasTones    bool
+	Rank        float64
+	Headline    string
+}
+
+// searchCalls runs the search in chunks, leaving out the calls the client
+// can't hear or whose transcript isn't released to it yet, and returns the
+// page of q.Limit calls after q.Offset.
+func (api *Api) searchCalls(client *Client, q CallSearchQuery, systemId uint64, talkgroupId uint64) ([]callSearchHit, bool, error) {
+	api.Controller.Systems.mutex.RLock()
+	systems := append([]*System{}, api.Controller.Systems.List...)
+	api.Controller.Systems.mutex.RUnlock()
+	matches := matchCallSearchLabels(systems, q.Text)
+
+	hits := make([]callSearchHit, 0, q.Limit)
+	skip := q.Offset
+	hasMore := false
+	var chunkOffset uint
+
+	for chunk := 0; chunk < callSearchMaxChunks && !hasMore; chunk++ {
+		query, args := buildCallSearchSQL(q, systemId, talkgroupId, matches, chunkOffset)
+
+		rows, err := api.Controller.Database.Sql.Query(query, args...)
+		if err != nil {
+			return nil, false, err
+		}
+
+		rowCount := 0
+		for rows.Next() {
+			rowCount++
+			var (
+				hit         callSearchHit
+				sysId       uint64
+				tgId        uint64
+				transcript  sql.NullString
+				translation sql.NullString
+				headline    sql.NullString
+			)
+			if err := rows.Scan(&hit.CallId, &sysId, &tgId, &hit.Timestamp, &transcript, &translation, &hit.HasTones, &hit.Rank, &headline); err != nil {
+				continue
+			}
+
+			system, ok := api.Controller.Systems.GetSystemById(sysId)
+			if !ok {
+				continue
+			}
+			talkgroup, ok := system.Talkgroups.GetTalkgroupById(tgId)
+			if !ok {
+				continue
+			}
+
+			if !client.IsAdmin {
+				call := &Call{Id: hit.CallId, Timestamp: time.UnixMilli(hit.Timestamp), System: system, Talkgroup: talkgroup}
+				if !api.Controller.userHasAccess(client.User, call) || !api.transcriptReleasedForUser(client.User, call) {
+					continue
+				}
+			}
+
+			if skip > 0 {
+				skip--
+				continue
+			}
+			if uint(len(hits)) >= q.Limit {
+				hasMore = true
+				break
+			}
+
+			hit.System, hit.Talkgroup = system, talkgroup
+			hit.Transcript, hit.Translation, hit.Headline = transcript.String, translation.String, headline.String
+			hits = append(hits, hit)
+		}
+
+		if err := rows.Err(); err != nil {
+			rows.Close()
+			return nil, false, err
+		}
+		rows.Close()
+
+		if rowCount < callSearchChunkSize {
+			break
+		}
+		chunkOffset += callSearchChunkSize
+	}
+
+	return hits, hasMore, nil
+}
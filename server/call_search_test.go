@@ -0,0 +1,92 @@
+package main
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestParseCallSearchQuery(t *testing.T) {
+	q, err := parseCallSearchQuery(url.Values{
+		"q":            {" main street "},
+		"systemRef":    {"3"},
+		"talkgroupRef": {"1200"},
+		"dateFrom":     {"2026-10-11"},
+		"dateTo":       {"1760000000000"},
+		"tones":        {"true"},
+		"limit":        {"1000"},
+	})
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if q.Text != "main street" || q.SystemRef != 3 || q.TalkgroupRef != 1200 || !q.TonesOnly {
+		t.Fatalf("unexpected query %+v", q)
+	}
+	if q.DateFrom != 1791676800000 || q.DateTo != 1760000000000 {
+		t.Fatalf("unexpected dates %d %d", q.DateFrom, q.DateTo)
+	}
+	if q.Limit != callSearchMaxLimit {
+		t.Fatalf("limit not capped: %d", q.Limit)
+	}
+
+	for _, values := range []url.Values{
+		{},
+		{"talkgroupRef": {"1"}, "q": {"fire"}},
+		{"q": {"fire"}, "dateFrom": {"last week"}},
+	} {
+		if _, err := parseCallSearchQuery(values); err == nil {
+			t.Fatalf("expected an error for %v", values)
+		}
+	}
+}
+
+func TestMatchCallSearchLabels(t *testing.T) {
+	system := NewSystem()
+	system.Id = 1
+	system.Talkgroups.List = []*Talkgroup{
+		{Id: 10, Label: "FD Dispatch", Name: "Fire Dispatch"},
+		{Id: 11, Label: "PD Main", Name: "Police Main"},
+	}
+	system.Units.List = []*Unit{{UnitRef: 4512, Label: "Engine 5"}}
+
+	matches := matchCallSearchLabels([]*System{system}, "fire dispatch")
+	if got := matches.Talkgroups[1]; len(got) != 1 || got[0] != 10 {
+		t.Fatalf("talkgroup matches = %v", got)
+	}
+
+	matches = matchCallSearchLabels([]*System{system}, "engine 5")
+	if got := matches.Units[1]; len(got) != 1 || got[0] != 4512 {
+		t.Fatalf("unit matches = %v", got)
+	}
+	if len(matches.UnitRefs) != 1 || matches.UnitRefs[0] != 5 {
+		t.Fatalf("unit refs = %v", matches.UnitRefs)
+	}
+}
+
+func TestBuildCallSearchSQL(t *testing.T) {
+	q := CallSearchQuery{Text: "main street", DateFrom: 1, UnitRef: 7, ToneSet: "Station 1", Limit: 10}
+	matches := callSearchMatches{Talkgroups: map[uint64][]uint64{2: {20, 21}}, Units: map[uint64][]uint{}}
+
+	query, args := buildCallSearchSQL(q, 2, 0, matches, 250)
+	if len(args) != 9 {
+		t.Fatalf("expected 9 args, got %d: %v", len(args), args)
+	}
+	for _, want := range []string{
+		callSearchVector("c.") + " @@ websearch_to_tsquery('english', $5)",
+		`c."talkgroupId" IN ($6, $7)`,
+		`ORDER BY "rank" DESC`,
+		`LIMIT $8 OFFSET $9`,
+	} {
+		if !strings.Contains(query, want) {
+			t.Fatalf("query misses %q:\n%s", want, query)
+		}
+	}
+	if args[4] != "main street" || args[3] != `"label":"Station 1"` || args[5] != uint64(20) || args[8] != uint(250) {
+		t.Fatalf("unexpected args %v", args)
+	}
+
+	query, _ = buildCallSearchSQL(CallSearchQuery{TonesOnly: true}, 0, 0, callSearchMatches{}, 0)
+	if strings.Contains(query, "tsquery") || !strings.Contains(query, `ORDER BY c."timestamp" DESC`) {
+		t.Fatalf("filter-only query:\n%s", query)
+	}
+}
@@ -0,0 +1,271 @@
+// Copyright (C) 2026 Thinline Dynamic Solutions
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>
+
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const (
+	callShareDefaultTTL = 24 * time.Hour
+	callShareMaxTTL     = 7 * 24 * time.Hour
+)
+
+// callShareRequest is the body of a share link request. Hours defaults to 24
+// and can't exceed a week. DisableDownload only hides the download button:
+// the audio still plays from the link and can be saved from there.
+type callShareRequest struct {
+	CallId          uint64 `json:"callId"`
+	Hours           uint   `json:"hours"`
+	DisableDownload bool   `json:"disableDownload"`
+}
+
+// callShareSignature signs a share link for a call. createdBy is the id of
+// the user who shared the call, or 0 for an administrator.
+func callShareSignature(secret string, callId uint64, createdBy uint64, expires int64, download bool) string {
+	dl := 0
+	if download {
+		dl = 1
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "call-share.%d.%d.%d.%d", callId, createdBy, expires, dl)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// callShareLink returns a signed link to a call that needs no login.
+func (controller *Controller) callShareLink(callId uint64, createdBy uint64, expires int64, download bool) string {
+	query := url.Values{}
+	query.Set("call", strconv.FormatUint(callId, 10))
+	query.Set("by", strconv.FormatUint(createdBy, 10))
+	query.Set("expires", strconv.FormatInt(expires, 10))
+	if download {
+		query.Set("dl", "1")
+	} else {
+		query.Set("dl", "0")
+	}
+	query.Set("sig", callShareSignature(controller.Options.secret, callId, createdBy, expires, download))
+
+	return normalizePublicBaseURL(controller.Options.BaseUrl) + "/api/share?" + query.Encode()
+}
+
+// createCallShare decodes a share link request and answers with the link.
+// allowed reports whether the requester may share the call.
+func (controller *Controller) createCallShare(w http.ResponseWriter, r *http.Request, createdBy uint64, allowed func(call *Call) (int, string)) {
+	w.Header().Set("Content-Type", "application/json")
+
+	writeError := func(status int, message string) {
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(map[string]string{"error": message})
+	}
+
+	req := callShareRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.CallId == 0 {
+		writeError(http.StatusBadRequest, "invalid share request")
+		return
+	}
+
+	ttl := callShareDefaultTTL
+	if req.Hours > 0 {
+		ttl = time.Duration(req.Hours) * time.Hour
+	}
+	if ttl > callShareMaxTTL {
+		writeError(http.StatusBadRequest, fmt.Sprintf("share links expire after %d hours at most", int(callShareMaxTTL.Hours())))
+		return
+	}
+
+	call, err := controller.Calls.GetCall(req.CallId)
+	if err != nil || call == nil {
+		writeError(http.StatusNotFound, "call not found")
+		return
+	}
+	if status, message := allowed(call); status != 0 {
+		writeError(status, message)
+		return
+	}
+
+	expires := time.Now().Add(ttl).Unix()
+	download := !req.DisableDownload
+	controller.Logs.LogEvent(LogLevelInfo, fmt.Sprintf("call %d shared by %s for %s", call.Id, callShareCreator(createdBy), ttl))
+
+	json.NewEncoder(w).Encode(map[string]any{
+		"url":       controller.callShareLink(call.Id, createdBy, expires, download),
+		"expiresAt": expires,
+		"download":  download,
+	})
+}
+
+func callShareCreator(createdBy uint64) string {
+	if createdBy == 0 {
+		return "an administrator"
+	}
+	return fmt.Sprintf("user %d", createdBy)
+}
+
+// CallShareHandler creates a share link to a call the user can hear.
+//
+// POST /api/calls/share {"callId": 123, "hours": 24, "disableDownload": false}
+func (api *Api) CallShareHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	client := api.getClient(r)
+	if client == nil || client.User == nil {
+		api.exitWithError(w, http.StatusUnauthorized, "Invalid PIN")
+		return
+	}
+
+	api.Controller.createCallShare(w, r, client.User.Id, func(call *Call) (int, string) {
+		if !api.Controller.userHasAccess(client.User, call) {
+			return http.StatusForbidden, "access denied"
+		}
+		delay := api.Controller.Delayer.getEffectiveDelayForClient(call, client)
+		if delay > 0 && time.Now().Before(call.Timestamp.Add(time.Duration(delay)*time.Minute)) {
+			return http.StatusForbidden, "call is still delayed for your account"
+		}
+		return 0, ""
+	})
+}
+
+// CallShareHandler creates a share link to any call of the administrator's
+// systems. It needs the export calls permission.
+//
+// POST /api/admin/calls/share {"callId": 123, "hours": 24, "disableDownload": false}
+func (admin *Admin) CallShareHandler(w http.ResponseWriter, r *http.Request) {
+	t := admin.GetAuthorization(r)
+	if !admin.ValidatePermission(t, PermissionExportCalls) {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	admin.Controller.createCallShare(w, r, 0, func(call *Call) (int, string) {
+		if call.System == nil || !admin.tenantAllowsSystem(t, call.System.Id) {
+			return http.StatusForbidden, "access denied"
+		}
+		return 0, ""
+	})
+}
+
+// SharedCallHandler serves the call behind a share link.
+//
+// GET /api/share?call=<id>&by=<id>&expires=<unix>&dl=<0|1>&sig=<hmac>
+//
+// The audio plays inline. Add download=1 for an attachment, refused when the
+// link was shared with downloads disabled, or info=1 for the call details.
+// The inline audio is served either way, since the link could not play
+// without it, so disabling downloads is no protection against saving it.
+// Links shared by a user stop working when the user no longer has access to
+// the call.
+func (api *Api) SharedCallHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	callId, err1 := strconv.ParseUint(query.Get("call"), 10, 64)
+	createdBy, err2 := strconv.ParseUint(query.Get("by"), 10, 64)
+	expires, err3 := strconv.ParseInt(query.Get("expires"), 10, 64)
+	if err1 != nil || err2 != nil || err3 != nil {
+		api.exitWithError(w, http.StatusBadRequest, "Invalid link")
+		return
+	}
+	download := query.Get("dl") == "1"
+
+	expected := callShareSignature(api.Controller.Options.secret, callId, createdBy, expires, download)
+	if !hmac.Equal([]byte(expected), []byte(query.Get("sig"))) {
+		api.exitWithError(w, http.StatusForbidden, "Invalid link")
+		return
+	}
+	if time.Now().Unix() > expires {
+		api.exitWithError(w, http.StatusGone, "This link has expired")
+		return
+	}
+
+	call, err := api.Controller.Calls.GetCall(callId)
+	if err != nil || call == nil || len(call.Audio) == 0 {
+		api.exitWithError(w, http.StatusNotFound, "Call not found")
+		return
+	}
+
+	if createdBy > 0 {
+		user := api.Controller.Users.GetUserById(createdBy)
+		if user == nil || (api.Controller.requiresUserAuth() && !api.Controller.userHasAccess(user, call)) {
+			api.exitWithError(w, http.StatusForbidden, "This link is no longer valid")
+			return
+		}
+	}
+
+	if query.Get("info") == "1" {
+		info := map[string]any{
+			"id":        call.Id,
+			"timestamp": call.Timestamp.UnixMilli(),
+			"audioUrl":  "/api/share?" + callShareQuery(query, ""),
+			"download":  download,
+		}
+		if call.System != nil {
+			info["system"] = call.System.Label
+		}
+		if call.Talkgroup != nil {
+			info["talkgroup"] = call.Talkgroup.Label
+			info["talkgroupName"] = call.Talkgroup.Name
+		}
+		if download {
+			info["downloadUrl"] = "/api/share?" + callShareQuery(query, "download")
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		json.NewEncoder(w).Encode(info)
+		return
+	}
+
+	if query.Get("download") == "1" {
+		if !download {
+			api.exitWithError(w, http.StatusForbidden, "Downloads are disabled for this link")
+			return
+		}
+		writeCallAudioAs(w, r, call, "attachment")
+		return
+	}
+
+	writeCallAudio(w, r, call)
+}
+
+// callShareQuery returns the signed part of a share link query, with flag set
+// to 1 when not empty.
+func callShareQuery(query url.Values, flag string) string {
+	signed := url.Values{}
+	for _, key := range []string{"call", "by", "expires", "dl", "sig"} {
+		signed.Set(key, query.Get(key))
+	}
+	if flag != "" {
+		signed.Set(flag, "1")
+	}
+	return signed.Encode()
+}
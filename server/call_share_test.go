@@ -0,0 +1,83 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestCallShareSignature(t *testing.T) {
+	sig := callShareSignature("secret", 42, 7, 1700000000, true)
+	for _, other := range []string{
+		callShareSignature("other", 42, 7, 1700000000, true),
+		callShareSignature("secret", 43, 7, 1700000000, true),
+		callShareSignature("secret", 42, 0, 1700000000, true),
+		callShareSignature("secret", 42, 7, 1700000001, true),
+		callShareSignature("secret", 42, 7, 1700000000, false),
+	} {
+		if other == sig {
+			t.Fatalf("signature does not cover every field")
+		}
+	}
+}
+
+func TestCallShareLink(t *testing.T) {
+	controller := &Controller{Options: &Options{}}
+	controller.Options.secret = "secret"
+	controller.Options.BaseUrl = "scanner.example.com"
+
+	link, err := url.Parse(controller.callShareLink(42, 7, 1700000000, false))
+	if err != nil {
+		t.Fatalf("invalid link: %v", err)
+	}
+	if link.Scheme != "https" || link.Host != "scanner.example.com" || link.Path != "/api/share" {
+		t.Fatalf("unexpected link %s", link)
+	}
+	query := link.Query()
+	if query.Get("call") != "42" || query.Get("by") != "7" || query.Get("dl") != "0" {
+		t.Fatalf("unexpected query %v", query)
+	}
+	if query.Get("sig") != callShareSignature("secret", 42, 7, 1700000000, false) {
+		t.Fatalf("link is not signed: %v", query)
+	}
+
+	signed := callShareQuery(query, "download")
+	if parsed, _ := url.ParseQuery(signed); parsed.Get("download") != "1" || parsed.Get("sig") != query.Get("sig") {
+		t.Fatalf("unexpected signed query %s", signed)
+	}
+}
+
+func TestSharedCallHandlerRejectsBadLinks(t *testing.T) {
+	controller := &Controller{Options: &Options{}, Logs: NewLogs()}
+	controller.Options.secret = "secret"
+	api := &Api{Controller: controller}
+
+	serve := func(query url.Values) int {
+		w := httptest.NewRecorder()
+		api.SharedCallHandler(w, httptest.NewRequest(http.MethodGet, "/api/share?"+query.Encode(), nil))
+		return w.Code
+	}
+	link := func(expires int64, download bool, dl string) url.Values {
+		query := url.Values{}
+		query.Set("call", "42")
+		query.Set("by", "0")
+		query.Set("expires", strconv.FormatInt(expires, 10))
+		query.Set("dl", dl)
+		query.Set("sig", callShareSignature("secret", 42, 0, expires, download))
+		return query
+	}
+
+	future := time.Now().Add(time.Hour).Unix()
+	if code := serve(link(future, false, "1")); code != http.StatusForbidden {
+		t.Fatalf("enabling downloads on a link = %d, want 403", code)
+	}
+	if code := serve(link(time.Now().Add(-time.Minute).Unix(), true, "1")); code != http.StatusGone {
+		t.Fatalf("expired link = %d, want 410", code)
+	}
+	if code := serve(url.Values{"call": {"x"}}); code != http.StatusBadRequest {
+		t.Fatalf("malformed link = %d, want 400", code)
+	}
+}
@@ -0,0 +1,526 @@
+// Copyright (C) 2026 Thinline Dynamic Solutions
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"time"
+)
+
+const (
+	callStatsDefaultRange  = 7 * 24 * time.Hour
+	callStatsMaxRange      = 366 * 24 * time.Hour
+	callStatsTopTalkgroups = 25
+)
+
+// CallStatsQuery is a parsed /api/admin/stats request.
+type CallStatsQuery struct {
+	From         time.Time
+	To           time.Time
+	Bucket       string // hour, day or week
+	Location     *time.Location
+	SystemRef    uint
+	TalkgroupRef uint
+}
+
+func parseCallStatsQuery(values url.Values, now time.Time) (CallStatsQuery, error) {
+	q := CallStatsQuery{To: now, Location: time.Local}
+
+	if tz := values.Get("tz"); tz != "" {
+		location, err := time.LoadLocation(tz)
+		if err != nil {
+			return q, fmt.Errorf("invalid tz")
+		}
+		q.Location = location
+	}
+
+	parseTime := func(name string) (time.Time, error) {
+		v := values.Get(name)
+		if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return time.UnixMilli(n), nil
+		} else if t, err := time.Parse(time.RFC3339, v); err == nil {
+			return t, nil
+		} else if t, err := time.ParseInLocation("2006-01-02", v, q.Location); err == nil {
+			return t, nil
+		}
+		return time.Time{}, fmt.Errorf("invalid %s", name)
+	}
+
+	var err error
+	if values.Get("to") != "" {
+		if q.To, err = parseTime("to"); err != nil {
+			return q, err
+		}
+	}
+	q.From = q.To.Add(-callStatsDefaultRange)
+	if values.Get("from") != "" {
+		if q.From, err = parseTime("from"); err != nil {
+			return q, err
+		}
+	}
+	if !q.From.Before(q.To) {
+		return q, fmt.Errorf("from must be before to")
+	}
+	if q.To.Sub(q.From) > callStatsMaxRange {
+		return q, fmt.Errorf("the range can't exceed 366 days")
+	}
+
+	for _, p := range []struct {
+		name string
+		dest *uint
+	}{{"systemRef", &q.SystemRef}, {"talkgroupRef", &q.TalkgroupRef}} {
+		if v := values.Get(p.name); v != "" {
+			n, err := strconv.ParseUint(v, 10, 32)
+			if err != nil {
+				return q, fmt.Errorf("invalid %s", p.name)
+			}
+			*p.dest = uint(n)
+		}
+	}
+	if q.TalkgroupRef > 0 && q.SystemRef == 0 {
+		return q, fmt.Errorf("talkgroupRef requires systemRef")
+	}
+
+	switch q.Bucket = values.Get("bucket"); q.Bucket {
+	case "hour", "day", "week":
+	case "":
+		switch span := q.To.Sub(q.From); {
+		case span <= 48*time.Hour:
+			q.Bucket = "hour"
+		case span <= 92*24*time.Hour:
+			q.Bucket = "day"
+		default:
+			q.Bucket = "week"
+		}
+	default:
+		return q, fmt.Errorf("bucket must be hour, day or week")
+	}
+	if q.Bucket == "hour" && q.To.Sub(q.From) > 31*24*time.Hour {
+		return q, fmt.Errorf("hourly buckets are limited to 31 days")
+	}
+
+	return q, nil
+}
+
+// callStatsRow is the activity of one talkgroup during one hour.
+type callStatsRow struct {
+	SystemId    uint64
+	TalkgroupId uint64
+	Hour        int64 // unix hour
+	Calls       int64
+	Timed       int64 // calls with a known duration
+	Airtime     float64
+	NoSpeech    int64 // calls not transcribed for lack of speech
+}
+
+// CallStatsTotals sums the calls of a period. The average only counts calls
+// with a known duration.
+type CallStatsTotals struct {
+	Calls           int64   `json:"calls"`
+	AirtimeSeconds  float64 `json:"airtimeSeconds"`
+	AverageDuration float64 `json:"averageDurationSeconds"`
+	NoSpeech        int64   `json:"noSpeechCalls"`
+	timed           int64
+}
+
+func (totals *CallStatsTotals) add(row callStatsRow) {
+	totals.Calls += row.Calls
+	totals.timed += row.Timed
+	totals.AirtimeSeconds += row.Airtime
+	totals.NoSpeech += row.NoSpeech
+}
+
+func (totals *CallStatsTotals) finish() {
+	totals.AirtimeSeconds = math.Round(totals.AirtimeSeconds*10) / 10
+	if totals.timed > 0 {
+		totals.AverageDuration = math.Round(totals.AirtimeSeconds/float64(totals.timed)*10) / 10
+	}
+}
+
+// callStatsChange is the relative change from previous to current, in
+// percent, or nil when there is nothing to compare to.
+func callStatsChange(current float64, previous float64) *float64 {
+	if previous == 0 {
+		return nil
+	}
+	change := math.Round((current-previous)/previous*1000) / 10
+	return &change
+}
+
+type CallStatsBucket struct {
+	Start int64 `json:"start"` // unix ms
+	CallStatsTotals
+}
+
+type CallStatsGroup struct {
+	SystemRef     uint     `json:"systemRef"`
+	TalkgroupRef  uint     `json:"talkgroupRef,omitempty"`
+	Label         string   `json:"label"`
+	Name          string   `json:"name,omitempty"`
+	PreviousCalls int64    `json:"previousCalls"`
+	Change        *float64 `json:"change"`
+	// EncryptedCalls counts the encrypted calls, stored or not
+	EncryptedCalls int64 `json:"encryptedCalls,omitempty"`
+	CallStatsTotals
+}
+
+// CallStats is the analytics response: totals with their trend against the
+// previous period of the same length, a time series, a weekday by hour
+// heatmap and the busiest systems and talkgroups.
+type CallStats struct {
+	From       int64               `json:"from"`
+	To         int64               `json:"to"`
+	Bucket     string              `json:"bucket"`
+	TimeZone   string              `json:"timeZone"`
+	Totals     CallStatsTotals     `json:"totals"`
+	Previous   CallStatsTotals     `json:"previous"`
+	Change     map[string]*float64 `json:"change"`
+	Series     []CallStatsBucket   `json:"series"`
+	Heatmap    [7][24]int64        `json:"heatmap"` // [weekday, Sunday first][hour]
+	Systems    []CallStatsGroup    `json:"systems"`
+	Talkgroups []CallStatsGroup    `json:"talkgroups"`
+	// Encrypted counts the encrypted calls by the policy applied to them
+	Encrypted map[string]int64 `json:"encrypted"`
+}
+
+// callStatsBucketStart returns the start of the bucket holding t, in the
+// query time zone. Weeks start on Monday.
+func callStatsBucketStart(t time.Time, bucket string, location *time.Location) time.Time {
+	t = t.In(location)
+	switch bucket {
+	case "day":
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, location)
+	case "week":
+		day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, location)
+		return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+	default:
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, location)
+	}
+}
+
+func callStatsNextBucket(t time.Time, bucket string) time.Time {
+	switch bucket {
+	case "day":
+		return t.AddDate(0, 0, 1)
+	case "week":
+		return t.AddDate(0, 0, 7)
+	default:
+		return t.Add(time.Hour)
+	}
+}
+
+// buildCallStats aggregates the hourly rows of the period and the previous
+// period. label resolves system and talkgroup ids to refs and labels; rows it
+// can't resolve count in the totals only.
+func buildCallStats(q CallStatsQuery, rows []callStatsRow, previous []callStatsRow, label func(systemId, talkgroupId uint64) (CallStatsGroup, bool)) *CallStats {
+	stats := &CallStats{
+		From:     q.From.UnixMilli(),
+		To:       q.To.UnixMilli(),
+		Bucket:   q.Bucket,
+		TimeZone: q.Location.String(),
+		Series:   []CallStatsBucket{},
+	}
+
+	buckets := map[int64]int{}
+	for start := callStatsBucketStart(q.From, q.Bucket, q.Location); start.Before(q.To); start = callStatsNextBucket(start, q.Bucket) {
+		buckets[start.UnixMilli()] = len(stats.Series)
+		stats.Series = append(stats.Series, CallStatsBucket{Start: start.UnixMilli()})
+	}
+
+	talkgroups := map[[2]uint64]*CallStatsGroup{}
+	systems := map[[2]uint64]*CallStatsGroup{}
+	group := func(groups map[[2]uint64]*CallStatsGroup, systemId, talkgroupId uint64) *CallStatsGroup {
+		key := [2]uint64{systemId, talkgroupId}
+		if g, ok := groups[key]; ok {
+			return g
+		}
+		g, ok := label(systemId, talkgroupId)
+		if !ok {
+			return nil
+		}
+		groups[key] = &g
+		return &g
+	}
+
+	for _, row := range rows {
+		stats.Totals.add(row)
+
+		hour := time.Unix(row.Hour*3600, 0).In(q.Location)
+		stats.Heatmap[hour.Weekday()][hour.Hour()] += row.Calls
+		if i, ok := buckets[callStatsBucketStart(hour, q.Bucket, q.Location).UnixMilli()]; ok {
+			stats.Series[i].add(row)
+		}
+
+		if g := group(talkgroups, row.SystemId, row.TalkgroupId); g != nil {
+			g.add(row)
+		}
+		if g := group(systems, row.SystemId, 0); g != nil {
+			g.add(row)
+		}
+	}
+	for _, row := range previous {
+		stats.Previous.add(row)
+		if g := group(talkgroups, row.SystemId, row.TalkgroupId); g != nil {
+			g.PreviousCalls += row.Calls
+		}
+		if g := group(systems, row.SystemId, 0); g != nil {
+			g.PreviousCalls += row.Calls
+		}
+	}
+
+	stats.Totals.finish()
+	stats.Previous.finish()
+	for i := range stats.Series {
+		stats.Series[i].finish()
+	}
+	stats.Change = map[string]*float64{
+		"calls":                  callStatsChange(float64(stats.Totals.Calls), float64(stats.Previous.Calls)),
+		"airtimeSeconds":         callStatsChange(stats.Totals.AirtimeSeconds, stats.Previous.AirtimeSeconds),
+		"averageDurationSeconds": callStatsChange(stats.Totals.AverageDuration, stats.Previous.AverageDuration),
+	}
+
+	ranked := func(groups []*CallStatsGroup, limit int) []CallStatsGroup {
+		sort.Slice(groups, func(i, j int) bool {
+			if groups[i].Calls != groups[j].Calls {
+				return groups[i].Calls > groups[j].Calls
+			}
+			return groups[i].PreviousCalls > groups[j].PreviousCalls
+		})
+		list := []CallStatsGroup{}
+		for _, g := range groups {
+			if limit > 0 && len(list) == limit {
+				break
+			}
+			g.finish()
+			g.Change = callStatsChange(float64(g.Calls), float64(g.PreviousCalls))
+			list = append(list, *g)
+		}
+		return list
+	}
+
+	systemList := []*CallStatsGroup{}
+	for _, g := range systems {
+		systemList = append(systemList, g)
+	}
+	stats.Systems = ranked(systemList, 0)
+
+	talkgroupList := []*CallStatsGroup{}
+	for _, g := range talkgroups {
+		talkgroupList = append(talkgroupList, g)
+	}
+	stats.Talkgroups = ranked(talkgroupList, callStatsTopTalkgroups)
+
+	return stats
+}
+
+// readCallStatsRows returns the calls per talkgroup and hour between from and to.
+func (controller *Controller) readCallStatsRows(from time.Time, to time.Time, systemId uint64, talkgroupId uint64) ([]callStatsRow, error) {
+	formatError := errorFormatter("stats", "read")
+
+	query := `SELECT "systemId", "talkgroupId", "timestamp" / 3600000 AS "hour", COUNT(*), COUNT(*) FILTER (WHERE "audioDuration" > 0), COALESCE(SUM("audioDuration"), 0), COUNT(*) FILTER (WHERE "transcriptionStatus" = 'no_speech') FROM "calls" WHERE "timestamp" >= $1 AND "timestamp" < $2`
+	args := []any{from.UnixMilli(), to.UnixMilli()}
+	if systemId > 0 {
+		args = append(args, systemId)
+		query += fmt.Sprintf(` AND "systemId" = $%d`, len(args))
+	}
+	if talkgroupId > 0 {
+		args = append(args, talkgroupId)
+		query += fmt.Sprintf(` AND "talkgroupId" = $%d`, len(args))
+	}
+	query += ` GROUP BY 1, 2, 3`
+
+	rows, err := controller.Database.Sql.Query(query, args...)
+	if err != nil {
+		return nil, formatError(err, query)
+	}
+	defer rows.Close()
+
+	list := []callStatsRow{}
+	for rows.Next() {
+		var row callStatsRow
+		if err := rows.Scan(&row.SystemId, &row.TalkgroupId, &row.Hour, &row.Calls, &row.Timed, &row.Airtime, &row.NoSpeech); err != nil {
+			return nil, formatError(err, query)
+		}
+		list = append(list, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, formatError(err, query)
+	}
+
+	return list, nil
+}
+
+// encryptedStatsRow is the number of encrypted calls of one talkgroup handled
+// by one policy.
+type encryptedStatsRow struct {
+	SystemId    uint64
+	TalkgroupId uint64
+	Policy      string
+	Calls       int64
+}
+
+// addEncrypted adds the encrypted call counts to the totals and to the listed
+// systems and talkgroups.
+func (stats *CallStats) addEncrypted(rows []encryptedStatsRow, label func(systemId, talkgroupId uint64) (CallStatsGroup, bool)) {
+	stats.Encrypted = map[string]int64{}
+	for _, row := range rows {
+		stats.Encrypted[row.Policy] += row.Calls
+		for i := range stats.Systems {
+			if g, ok := label(row.SystemId, 0); ok && stats.Systems[i].SystemRef == g.SystemRef {
+				stats.Systems[i].EncryptedCalls += row.Calls
+			}
+		}
+		for i := range stats.Talkgroups {
+			if g, ok := label(row.SystemId, row.TalkgroupId); ok && stats.Talkgroups[i].SystemRef == g.SystemRef && stats.Talkgroups[i].TalkgroupRef == g.TalkgroupRef {
+				stats.Talkgroups[i].EncryptedCalls += row.Calls
+			}
+		}
+	}
+}
+
+// readEncryptedStatsRows returns the encrypted calls per talkgroup and policy
+// between from and to.
+func (controller *Controller) readEncryptedStatsRows(from time.Time, to time.Time, systemId uint64, talkgroupId uint64) ([]encryptedStatsRow, error) {
+	formatError := errorFormatter("stats", "readEncrypted")
+
+	query := `SELECT "systemId", "talkgroupId", "policy", SUM("calls") FROM "encryptedCallCounts" WHERE "hour" >= $1 AND "hour" < $2`
+	args := []any{from.Unix() / 3600, (to.Unix() + 3599) / 3600}
+	if systemId > 0 {
+		args = append(args, systemId)
+		query += fmt.Sprintf(` AND "systemId" = $%d`, len(args))
+	}
+	if talkgroupId > 0 {
+		args = append(args, talkgroupId)
+		query += fmt.Sprintf(` AND "talkgroupId" = $%d`, len(args))
+	}
+	query += ` GROUP BY 1, 2, 3`
+
+	rows, err := controller.Database.Sql.Query(query, args...)
+	if err != nil {
+		return nil, formatError(err, query)
+	}
+	defer rows.Close()
+
+	list := []encryptedStatsRow{}
+	for rows.Next() {
+		var row encryptedStatsRow
+		if err := rows.Scan(&row.SystemId, &row.TalkgroupId, &row.Policy, &row.Calls); err != nil {
+			return nil, formatError(err, query)
+		}
+		list = append(list, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, formatError(err, query)
+	}
+
+	return list, nil
+}
+
+// StatsHandler returns call analytics for the admin dashboard.
+//
+//	GET /api/admin/stats?from=2026-10-01&to=2026-10-15&bucket=day&tz=America/Chicago&systemRef=1
+func (admin *Admin) StatsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	writeError := func(status int, message string) {
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(map[string]string{"error": message})
+	}
+
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	t := admin.GetAuthorization(r)
+	if !admin.ValidatePermission(t, PermissionViewStats) {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	q, err := parseCallStatsQuery(r.URL.Query(), time.Now())
+	if err != nil {
+		writeError(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// Tenant administrators only get statistics for one of their systems
+	if q.SystemRef == 0 && admin.tokenTenant(t) != nil {
+		writeError(http.StatusBadRequest, "systemRef is required")
+		return
+	}
+
+	var systemId, talkgroupId uint64
+	if q.SystemRef > 0 {
+		system, ok := admin.Controller.Systems.GetSystemByRef(q.SystemRef)
+		if !ok || !admin.tenantAllowsSystem(t, system.Id) {
+			writeError(http.StatusBadRequest, "unknown systemRef")
+			return
+		}
+		systemId = system.Id
+		if q.TalkgroupRef > 0 {
+			talkgroup, ok := system.Talkgroups.GetTalkgroupByRef(q.TalkgroupRef)
+			if !ok {
+				writeError(http.StatusBadRequest, "unknown talkgroupRef")
+				return
+			}
+			talkgroupId = talkgroup.Id
+		}
+	}
+
+	rows, err := admin.Controller.readCallStatsRows(q.From, q.To, systemId, talkgroupId)
+	if err != nil {
+		writeError(http.StatusInternalServerError, err.Error())
+		return
+	}
+	span := q.To.Sub(q.From)
+	previous, err := admin.Controller.readCallStatsRows(q.From.Add(-span), q.From, systemId, talkgroupId)
+	if err != nil {
+		writeError(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	label := func(systemId, talkgroupId uint64) (CallStatsGroup, bool) {
+		system, ok := admin.Controller.Systems.GetSystemById(systemId)
+		if !ok {
+			return CallStatsGroup{}, false
+		}
+		if talkgroupId == 0 {
+			return CallStatsGroup{SystemRef: system.SystemRef, Label: system.Label}, true
+		}
+		talkgroup, ok := system.Talkgroups.GetTalkgroupById(talkgroupId)
+		if !ok {
+			return CallStatsGroup{}, false
+		}
+		return CallStatsGroup{SystemRef: system.SystemRef, TalkgroupRef: talkgroup.TalkgroupRef, Label: talkgroup.Label, Name: talkgroup.Name}, true
+	}
+	stats := buildCallStats(q, rows, previous, label)
+
+	encrypted, err := admin.Controller.readEncryptedStatsRows(q.From, q.To, systemId, talkgroupId)
+	if err != nil {
+		writeError(http.StatusInternalServerError, err.Error())
+		return
+	}
+	stats.addEncrypted(encrypted, label)
+
+	json.NewEncoder(w).Encode(stats)
+}
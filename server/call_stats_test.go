@@ -0,0 +1,93 @@
+package main
+
+import (
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestParseCallStatsQuery(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+
+	q, err := parseCallStatsQuery(url.Values{}, now)
+	if err != nil {
+		t.Fatalf("defaults: %v", err)
+	}
+	if !q.To.Equal(now) || q.To.Sub(q.From) != callStatsDefaultRange || q.Bucket != "day" {
+		t.Fatalf("unexpected defaults %+v", q)
+	}
+
+	q, err = parseCallStatsQuery(url.Values{"from": {"2026-10-17"}, "to": {"2026-10-18"}, "tz": {"America/Chicago"}}, now)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if q.Bucket != "hour" || q.From.Hour() != 0 || q.From.Location().String() != "America/Chicago" {
+		t.Fatalf("unexpected query %+v", q)
+	}
+
+	for _, values := range []url.Values{
+		{"from": {"2026-10-18"}, "to": {"2026-10-01"}},
+		{"from": {"2024-01-01"}},
+		{"bucket": {"month"}},
+		{"bucket": {"hour"}, "from": {"2026-08-01"}},
+		{"talkgroupRef": {"3"}},
+		{"tz": {"Mars/Olympus"}},
+	} {
+		if _, err := parseCallStatsQuery(values, now); err == nil {
+			t.Fatalf("expected an error for %v", values)
+		}
+	}
+}
+
+func TestBuildCallStats(t *testing.T) {
+	from := time.Date(2026, 10, 12, 0, 0, 0, 0, time.UTC) // Monday
+	q := CallStatsQuery{From: from, To: from.AddDate(0, 0, 2), Bucket: "day", Location: time.UTC}
+	hour := from.Unix() / 3600
+
+	rows := []callStatsRow{
+		{SystemId: 1, TalkgroupId: 10, Hour: hour + 8, Calls: 4, Timed: 4, Airtime: 40},
+		{SystemId: 1, TalkgroupId: 11, Hour: hour + 8, Calls: 1, Timed: 0, Airtime: 0},
+		{SystemId: 1, TalkgroupId: 10, Hour: hour + 30, Calls: 5, Timed: 5, Airtime: 20},
+		{SystemId: 9, TalkgroupId: 90, Hour: hour + 30, Calls: 2, Timed: 2, Airtime: 2},
+	}
+	previous := []callStatsRow{
+		{SystemId: 1, TalkgroupId: 10, Hour: hour - 20, Calls: 6, Timed: 6, Airtime: 30},
+	}
+	label := func(systemId, talkgroupId uint64) (CallStatsGroup, bool) {
+		if systemId != 1 {
+			return CallStatsGroup{}, false
+		}
+		return CallStatsGroup{SystemRef: 1, TalkgroupRef: uint(talkgroupId), Label: "TG"}, true
+	}
+
+	stats := buildCallStats(q, rows, previous, label)
+
+	if stats.Totals.Calls != 12 || stats.Totals.AirtimeSeconds != 62 || stats.Totals.AverageDuration != 5.6 {
+		t.Fatalf("unexpected totals %+v", stats.Totals)
+	}
+	if c := stats.Change["calls"]; c == nil || *c != 100 {
+		t.Fatalf("unexpected calls change %v", c)
+	}
+	if len(stats.Series) != 2 || stats.Series[0].Calls != 5 || stats.Series[1].Calls != 7 {
+		t.Fatalf("unexpected series %+v", stats.Series)
+	}
+	if stats.Heatmap[time.Monday][8] != 5 || stats.Heatmap[time.Tuesday][6] != 7 {
+		t.Fatalf("unexpected heatmap")
+	}
+	if len(stats.Systems) != 1 || stats.Systems[0].Calls != 10 || stats.Systems[0].PreviousCalls != 6 {
+		t.Fatalf("unexpected systems %+v", stats.Systems)
+	}
+	if len(stats.Talkgroups) != 2 || stats.Talkgroups[0].TalkgroupRef != 10 || stats.Talkgroups[0].Calls != 9 {
+		t.Fatalf("unexpected talkgroups %+v", stats.Talkgroups)
+	}
+	if c := stats.Talkgroups[0].Change; c == nil || *c != 50 {
+		t.Fatalf("unexpected talkgroup change %v", c)
+	}
+}
+
+func TestCallStatsBucketStartWeek(t *testing.T) {
+	sunday := time.Date(2026, 10, 18, 15, 0, 0, 0, time.UTC)
+	if got := callStatsBucketStart(sunday, "week", time.UTC); !got.Equal(time.Date(2026, 10, 12, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("week start = %v", got)
+	}
+}
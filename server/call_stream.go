@@ -0,0 +1,329 @@
+// Copyright (C) 2026 Thinline Dynamic Solutions
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	callStreamPingPeriod = 30 * time.Second
+	callStreamPongWait   = 90 * time.Second
+	callStreamWriteWait  = 10 * time.Second
+	callStreamQueueSize  = 256
+)
+
+// CallStream pushes call metadata to third-party subscribers connected to
+// /api/live. Unlike the webapp websocket it never carries audio bytes; each
+// event is released only after the subscriber's effective delay has elapsed.
+type CallStream struct {
+	controller  *Controller
+	mutex       sync.Mutex
+	subscribers map[*callStreamSubscriber]bool
+}
+
+type callStreamSubscriber struct {
+	user       *User
+	admin      bool
+	audio      bool
+	systems    map[uint]bool
+	talkgroups map[uint]bool
+	send       chan []byte
+	done       chan struct{}
+	once       sync.Once
+}
+
+// CallStreamEvent is the JSON message sent for every call.
+type CallStreamEvent struct {
+	Type           string   `json:"type"`
+	Id             uint64   `json:"id"`
+	DateTime       string   `json:"dateTime"`
+	System         uint     `json:"system"`
+	SystemLabel    string   `json:"systemLabel"`
+	Talkgroup      uint     `json:"talkgroup"`
+	TalkgroupLabel string   `json:"talkgroupLabel"`
+	TalkgroupName  string   `json:"talkgroupName"`
+	TalkgroupTag   string   `json:"talkgroupTag,omitempty"`
+	Groups         []string `json:"groups,omitempty"`
+	Site           string   `json:"site,omitempty"`
+	Frequency      uint     `json:"frequency,omitempty"`
+	Units          []uint   `json:"units,omitempty"`
+	Patches        []uint   `json:"patches,omitempty"`
+	HasTones       bool     `json:"hasTones,omitempty"`
+	Transcript     string   `json:"transcript,omitempty"`
+	Delay          uint     `json:"delay"`
+	AudioUrl       string   `json:"audioUrl,omitempty"`
+}
+
+func NewCallStream(controller *Controller) *CallStream {
+	return &CallStream{
+		controller:  controller,
+		subscribers: make(map[*callStreamSubscriber]bool),
+	}
+}
+
+// Count returns the number of connected subscribers.
+func (stream *CallStream) Count() int {
+	stream.mutex.Lock()
+	defer stream.mutex.Unlock()
+
+	return len(stream.subscribers)
+}
+
+// Emit schedules call for every subscriber allowed to hear it.
+func (stream *CallStream) Emit(call *Call) {
+	if call == nil || call.System == nil || call.Talkgroup == nil {
+		return
+	}
+
+	stream.mutex.Lock()
+	subscribers := make([]*callStreamSubscriber, 0, len(stream.subscribers))
+	for subscriber := range stream.subscribers {
+		subscribers = append(subscribers, subscriber)
+	}
+	stream.mutex.Unlock()
+
+	for _, subscriber := range subscribers {
+		if !stream.allowed(subscriber, call) {
+			continue
+		}
+
+		delay := stream.controller.Delayer.getEffectiveDelayForClient(call, &Client{User: subscriber.user})
+		if subscriber.admin {
+			delay = 0
+		}
+
+		b, err := json.Marshal(newCallStreamEvent(call, delay, subscriber.audio))
+		if err != nil {
+			continue
+		}
+
+		release := call.Timestamp.Add(time.Duration(delay) * time.Minute)
+		if remaining := time.Until(release); delay > 0 && remaining > 0 {
+			time.AfterFunc(remaining, func() { subscriber.push(b) })
+		} else {
+			subscriber.push(b)
+		}
+	}
+}
+
+func (stream *CallStream) allowed(subscriber *callStreamSubscriber, call *Call) bool {
+	if len(subscriber.systems) > 0 && !subscriber.systems[call.System.SystemRef] {
+		return false
+	}
+	if len(subscriber.talkgroups) > 0 && !subscriber.talkgroups[call.Talkgroup.TalkgroupRef] {
+		return false
+	}
+	if subscriber.admin || !stream.controller.requiresUserAuth() {
+		return true
+	}
+
+	return subscriber.user != nil && stream.controller.userHasAccess(subscriber.user, call)
+}
+
+func newCallStreamEvent(call *Call, delay uint, audio bool) *CallStreamEvent {
+	event := &CallStreamEvent{
+		Type:           "call",
+		Id:             call.Id,
+		DateTime:       call.Timestamp.UTC().Format(time.RFC3339),
+		System:         call.System.SystemRef,
+		SystemLabel:    call.System.Label,
+		Talkgroup:      call.Talkgroup.TalkgroupRef,
+		TalkgroupLabel: call.Talkgroup.Label,
+		TalkgroupName:  call.Talkgroup.Name,
+		TalkgroupTag:   call.Meta.TalkgroupTag,
+		Groups:         call.Meta.TalkgroupGroups,
+		Site:           call.SiteRef,
+		Frequency:      call.Frequency,
+		Patches:        call.Patches,
+		HasTones:       call.HasTones,
+		Transcript:     call.Transcript,
+		Delay:          delay,
+	}
+
+	for _, unit := range call.Units {
+		event.Units = append(event.Units, unit.UnitRef)
+	}
+	if len(event.Units) == 0 {
+		event.Units = call.Meta.UnitRefs
+	}
+
+	if audio {
+		event.AudioUrl = fmt.Sprintf("/api/calls/%d/audio", call.Id)
+	}
+
+	return event
+}
+
+// push queues b without blocking; slow subscribers lose events rather than
+// holding up the call pipeline.
+func (subscriber *callStreamSubscriber) push(b []byte) {
+	select {
+	case <-subscriber.done:
+	case subscriber.send <- b:
+	default:
+	}
+}
+
+func (subscriber *callStreamSubscriber) close() {
+	subscriber.once.Do(func() { close(subscriber.done) })
+}
+
+// parseCallStreamRefs parses a comma separated list of system or talkgroup refs.
+func parseCallStreamRefs(s string) (map[uint]bool, error) {
+	refs := map[uint]bool{}
+	for _, f := range strings.Split(s, ",") {
+		f = strings.TrimSpace(f)
+		if f == "" {
+			continue
+		}
+		v, err := strconv.ParseUint(f, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid id %q", f)
+		}
+		refs[uint(v)] = true
+	}
+	return refs, nil
+}
+
+// CallStreamHandler upgrades to a WebSocket and streams CallStreamEvent
+// messages. Authentication uses the user PIN (?pin= or Authorization: Bearer)
+// or an admin token; anonymous subscribers are accepted only when the server
+// does not require user authentication.
+func (api *Api) CallStreamHandler(w http.ResponseWriter, r *http.Request) {
+	if !strings.EqualFold(r.Header.Get("upgrade"), "websocket") {
+		api.exitWithError(w, http.StatusBadRequest, "WebSocket upgrade required")
+		return
+	}
+
+	stream := api.Controller.CallStream
+	query := r.URL.Query()
+
+	subscriber := &callStreamSubscriber{
+		audio: query.Get("audio") == "true" || query.Get("audio") == "1",
+		send:  make(chan []byte, callStreamQueueSize),
+		done:  make(chan struct{}),
+	}
+
+	if client := api.getClient(r); client != nil {
+		subscriber.user = client.User
+		subscriber.admin = client.IsAdmin
+	} else if api.Controller.requiresUserAuth() {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="TLR live calls"`)
+		api.exitWithError(w, http.StatusUnauthorized, "Invalid PIN")
+		return
+	}
+
+	if subscriber.user != nil {
+		if subscriber.user.PinExpired() {
+			api.exitWithError(w, http.StatusForbidden, "PIN expired")
+			return
+		}
+		if !subscriber.user.Verified {
+			api.exitWithError(w, http.StatusForbidden, "Account not verified")
+			return
+		}
+	}
+
+	var err error
+	if subscriber.systems, err = parseCallStreamRefs(query.Get("systems")); err != nil {
+		api.exitWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if subscriber.talkgroups, err = parseCallStreamRefs(query.Get("talkgroups")); err != nil {
+		api.exitWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if max := api.Controller.Options.MaxClients; max > 0 && stream.Count() >= int(max) {
+		api.exitWithError(w, http.StatusServiceUnavailable, "Too many subscribers")
+		return
+	}
+
+	upgrader := websocket.Upgrader{
+		CheckOrigin: func(r *http.Request) bool {
+			return true
+		},
+		ReadBufferSize:  1024,
+		WriteBufferSize: 4096,
+	}
+
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+
+	stream.mutex.Lock()
+	stream.subscribers[subscriber] = true
+	stream.mutex.Unlock()
+
+	api.Controller.Logs.LogEvent(LogLevelInfo, fmt.Sprintf("call stream: subscriber connected from %s", GetRemoteAddr(r)))
+
+	defer func() {
+		stream.mutex.Lock()
+		delete(stream.subscribers, subscriber)
+		stream.mutex.Unlock()
+		subscriber.close()
+		conn.Close()
+	}()
+
+	// Subscribers only listen; reading keeps pongs flowing and detects closes.
+	go func() {
+		defer subscriber.close()
+		conn.SetReadLimit(512)
+		conn.SetReadDeadline(time.Now().Add(callStreamPongWait))
+		conn.SetPongHandler(func(string) error {
+			return conn.SetReadDeadline(time.Now().Add(callStreamPongWait))
+		})
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(callStreamPingPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-subscriber.done:
+			conn.SetWriteDeadline(time.Now().Add(callStreamWriteWait))
+			conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+			return
+
+		case b := <-subscriber.send:
+			conn.SetWriteDeadline(time.Now().Add(callStreamWriteWait))
+			if err := conn.WriteMessage(websocket.TextMessage, b); err != nil {
+				return
+			}
+
+		case <-ticker.C:
+			conn.SetWriteDeadline(time.Now().Add(callStreamWriteWait))
+			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestCallStreamEmitHonorsDelay(t *testing.T) {
+	controller := &Controller{Options: NewOptions()}
+	controller.Delayer = NewDelayer(controller)
+	stream := NewCallStream(controller)
+
+	subscriber := &callStreamSubscriber{
+		audio:      true,
+		talkgroups: map[uint]bool{100: true},
+		send:       make(chan []byte, 4),
+		done:       make(chan struct{}),
+	}
+	stream.subscribers[subscriber] = true
+
+	system := &System{SystemRef: 1, Label: "County"}
+	delayed := &Talkgroup{TalkgroupRef: 100, Label: "FD", Delay: 5}
+
+	// Still inside the talkgroup delay: nothing may be sent yet
+	stream.Emit(&Call{Id: 1, System: system, Talkgroup: delayed, Timestamp: time.Now()})
+	// Filtered out by the talkgroup subscription
+	stream.Emit(&Call{Id: 2, System: system, Talkgroup: &Talkgroup{TalkgroupRef: 200}, Timestamp: time.Now()})
+	// Delay already elapsed
+	stream.Emit(&Call{Id: 3, System: system, Talkgroup: delayed, Timestamp: time.Now().Add(-10 * time.Minute)})
+
+	select {
+	case b := <-subscriber.send:
+		var event CallStreamEvent
+		if err := json.Unmarshal(b, &event); err != nil {
+			t.Fatalf("unmarshal: %v", err)
+		}
+		if event.Id != 3 || event.Delay != 5 || event.AudioUrl != "/api/calls/3/audio" {
+			t.Fatalf("event = %+v", event)
+		}
+	default:
+		t.Fatalf("no event sent")
+	}
+
+	select {
+	case b := <-subscriber.send:
+		t.Fatalf("unexpected event %s", b)
+	default:
+	}
+}
+
+func TestParseCallStreamRefs(t *testing.T) {
+	refs, err := parseCallStreamRefs(" 1, 22,,3")
+	if err != nil || len(refs) != 3 || !refs[22] {
+		t.Fatalf("refs = %v, %v", refs, err)
+	}
+	if _, err := parseCallStreamRefs("1,x"); err == nil {
+		t.Fatalf("invalid ref accepted")
+	}
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeSqlHandler answers a statement with the rows of a query or the number
+// of rows affected by an exec.
+type fakeSqlHandler func(query string, args []driver.Value) (rows [][]driver.Value, affected int64, err error)
+
+var (
+	fakeSqlMutex    sync.Mutex
+	fakeSqlHandlers = map[string]fakeSqlHandler{}
+)
+
+func init() {
+	sql.Register("fakesql", fakeSqlDriver{})
+}
+
+// newFakeDatabase opens a database whose statements are answered by handler.
+func newFakeDatabase(t *testing.T, handler fakeSqlHandler) *Database {
+	t.Helper()
+
+	fakeSqlMutex.Lock()
+	fakeSqlHandlers[t.Name()] = handler
+	fakeSqlMutex.Unlock()
+
+	db, err := sql.Open("fakesql", t.Name())
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	return &Database{Config: &Config{DbType: DbTypePostgresql}, Sql: db}
+}
+
+type fakeSqlDriver struct{}
+
+func (fakeSqlDriver) Open(name string) (driver.Conn, error) {
+	fakeSqlMutex.Lock()
+	defer fakeSqlMutex.Unlock()
+	handler, ok := fakeSqlHandlers[name]
+	if !ok {
+		return nil, fmt.Errorf("no fake database %s", name)
+	}
+	return fakeSqlConn{handler}, nil
+}
+
+type fakeSqlConn struct{ handler fakeSqlHandler }
+
+func (c fakeSqlConn) Prepare(query string) (driver.Stmt, error) {
+	return fakeSqlStmt{c.handler, query}, nil
+}
+func (fakeSqlConn) Close() error              { return nil }
+func (fakeSqlConn) Begin() (driver.Tx, error) { return fakeSqlTx{}, nil }
+
+type fakeSqlTx struct{}
+
+func (fakeSqlTx) Commit() error   { return nil }
+func (fakeSqlTx) Rollback() error { return nil }
+
+type fakeSqlStmt struct {
+	handler fakeSqlHandler
+	query   string
+}
+
+func (fakeSqlStmt) Close() error  { return nil }
+func (fakeSqlStmt) NumInput() int { return -1 }
+
+func (s fakeSqlStmt) Exec(args []driver.Value) (driver.Result, error) {
+	_, affected, err := s.handler(s.query, args)
+	return driver.RowsAffected(affected), err
+}
+
+func (s fakeSqlStmt) Query(args []driver.Value) (driver.Rows, error) {
+	rows, _, err := s.handler(s.query, args)
+	if err != nil {
+		return nil, err
+	}
+	return &fakeSqlRows{rows: rows}, nil
+}
+
+type fakeSqlRows struct{ rows [][]driver.Value }
+
+func (r *fakeSqlRows) Columns() []string {
+	n := 1
+	if len(r.rows) > 0 {
+		n = len(r.rows[0])
+	}
+	columns := make([]string, n)
+	for i := range columns {
+		columns[i] = fmt.Sprintf("c%d", i)
+	}
+	return columns
+}
+func (r *fakeSqlRows) Close() error { return nil }
+func (r *fakeSqlRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+func TestCallsDeleteByIDsReleasesAudio(t *testing.T) {
+	options := NewOptions()
+	options.AudioStorageConfig = AudioStorageConfig{Backend: AudioStorageFilesystem, Path: t.TempDir()}
+	controller := &Controller{Config: &Config{}, Options: options}
+	controller.AudioStore = NewAudioStore(controller)
+	controller.Calls = NewCalls(controller)
+
+	store := controller.AudioStore
+	ts := time.Date(2026, 3, 4, 12, 0, 0, 0, time.UTC)
+	kept, _, err := store.Put(store.Backend(), "", ts, "kept.m4a", "audio/mp4", []byte("kept"))
+	if err != nil {
+		t.Fatalf("put: %v", err)
+	}
+	deleted, _, err := store.Put(store.Backend(), "", ts, "deleted.m4a", "audio/mp4", []byte("deleted"))
+	if err != nil {
+		t.Fatalf("put: %v", err)
+	}
+
+	calls := map[int64]string{1: kept, 2: deleted}
+	controller.Database = newFakeDatabase(t, func(query string, args []driver.Value) ([][]driver.Value, int64, error) {
+		switch {
+		case strings.HasPrefix(query, `SELECT "audioLocation" FROM "calls" WHERE ("callId" IN`):
+			rows := [][]driver.Value{}
+			for _, id := range args {
+				if location, ok := calls[id.(int64)]; ok {
+					rows = append(rows, []driver.Value{location})
+				}
+			}
+			return rows, 0, nil
+		case strings.HasPrefix(query, `DELETE FROM "calls" WHERE "callId" IN`):
+			for _, id := range args {
+				delete(calls, id.(int64))
+			}
+			return nil, int64(len(args)), nil
+		case strings.HasPrefix(query, `SELECT COUNT(*) FROM "calls" WHERE "audioLocation" = $1`):
+			var refs int64
+			for _, location := range calls {
+				if location == args[0] {
+					refs++
+				}
+			}
+			return [][]driver.Value{{refs}}, 0, nil
+		case strings.HasPrefix(query, `UPDATE "audioBlobs"`):
+			return nil, 0, nil
+		}
+		return nil, 0, fmt.Errorf("unexpected query %s", query)
+	})
+
+	if err := controller.Calls.DeleteByIDs(controller.Database, []uint64{2}); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+
+	path, _ := store.filePath(deleted)
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("audio of the deleted call is still on disk: %v", err)
+	}
+	path, _ = store.filePath(kept)
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("audio of the kept call: %v", err)
+	}
+}
@@ -38,6 +38,8 @@ type Client struct {
 	IsAdmin     bool // Set to true when authenticated with admin token
 	// BypassPlaybackSearchACL skips user/group filtering in Calls.Search (admin HTTP API only).
 	BypassPlaybackSearchACL bool
+	// TenantSystemIds limits Calls.Search to a tenant's systems (tenant administrators only).
+	TenantSystemIds []uint64
 	PinExpired              bool // Set to true when user's PIN is expired
 	BacklogSent bool // Set to true after initial backlog has been sent (prevents resending on channel toggle)
 	Systems     []System
@@ -49,6 +51,7 @@ type Client struct {
 	SystemsMap  SystemsMap
 	request     *http.Request
 	FCMToken    string // Set via the "FCM" WS command; links this session to a push token.
+	Session     *Session // Set once the client authenticated with a PIN
 
 	// DownloadTimestamps tracks when each audio download was requested by this
 	// client, used for sliding-window rate limiting.
@@ -159,6 +162,8 @@ func (client *Client) Init(controller *Controller, request *http.Request, conn *
 				return err
 			}
 
+			controller.Sessions.Touch(client)
+
 			return nil
 		})
 
@@ -172,6 +177,8 @@ func (client *Client) Init(controller *Controller, request *http.Request, conn *
 				return
 			}
 
+			controller.Sessions.Touch(client)
+
 			message := &Message{}
 			if err = message.FromJson(b); err != nil {
 				controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("client.message.fromjson error from ip %s: %v", client.GetRemoteAddr(), err))
@@ -322,13 +329,24 @@ func (client *Client) SendConfig(groups *Groups, options *Options, systems *Syst
 		}
 	}
 
+	// Listeners of a tenant get its branding
+	branding := options.Branding
+	var tenant *Tenant
+	if client.Controller != nil {
+		tenant = client.Controller.Tenants.Of(client.User)
+	}
+	if tenant != nil {
+		branding = tenant.Name
+	}
+
 	var payload = map[string]any{
-		"alerts":      Alerts,
-		"branding":    options.Branding,
-		"email":       options.Email,
-		"groups":      client.GroupsMap,
-		"groupsData":  client.GroupsData,
-		"keypadBeeps": GetKeypadBeeps(options),
+		"alerts":          Alerts,
+		"branding":        branding,
+		"email":           options.Email,
+		"groups":          client.GroupsMap,
+		"groupsData":      client.GroupsData,
+		"keypadBeeps":     GetKeypadBeeps(options),
+		"logicalChannels": options.LogicalChannels.GetScopedChannels(&client.SystemsMap),
 		"options": map[string]any{
 			"userRegistrationEnabled": options.UserRegistrationEnabled,
 			"stripePaywallEnabled":    options.StripePaywallEnabled,
@@ -347,12 +365,17 @@ func (client *Client) SendConfig(groups *Groups, options *Options, systems *Syst
 			"relayServerURL":         options.RelayServerURL,
 			"audioClientToken":       client.Controller.AudioClientToken,
 		},
-		"playbackGoesLive":   options.PlaybackGoesLive,
-		"showListenersCount": options.ShowListenersCount,
-		"systems":            client.SystemsMap,
-		"tags":               client.TagsMap,
-		"tagsData":           client.TagsData,
-		"time12hFormat":      options.Time12hFormat,
+		"playbackGoesLive":       options.PlaybackGoesLive,
+		"showListenersCount":     options.ShowListenersCount,
+		"showTalkgroupListeners": options.ListenerAnalyticsConfig.ShowTalkgroupListeners && !options.ListenerAnalyticsConfig.Disabled,
+		"systems":                client.SystemsMap,
+		"tags":                   client.TagsMap,
+		"tagsData":               client.TagsData,
+		"time12hFormat":          options.Time12hFormat,
+	}
+
+	if tenant != nil {
+		payload["tenant"] = tenant.Branding()
 	}
 
 	// Include user settings if user is authenticated
@@ -466,9 +489,10 @@ func (clients *Clients) EmitCall(controller *Controller, call *Call) {
 
 	restricted := controller.requiresUserAuth()
 	msg := &Message{Command: MessageCommandCall, Payload: call}
+	announced := []*Client{}
 
 	for c := range clients.Map {
-		if !c.Livefeed.IsEnabled(call) {
+		if !controller.livefeedAllows(c.Livefeed, call) || !controller.ScanControls.Allows(c, call) {
 			continue
 		}
 
@@ -479,6 +503,12 @@ func (clients *Clients) EmitCall(controller *Controller, call *Call) {
 			}
 		}
 
+		// Users with announcements get the call once it is announced
+		if controller.TTS.EnabledFor(c.User) {
+			announced = append(announced, c)
+			continue
+		}
+
 		if controller.Delayer.CanDelayForClient(call, c) {
 			controller.Delayer.DelayForClient(call, c)
 		} else {
@@ -493,6 +523,10 @@ func (clients *Clients) EmitCall(controller *Controller, call *Call) {
 		}
 	}
 
+	if len(announced) > 0 {
+		go controller.TTS.EmitAnnounced(call, announced)
+	}
+
 	// Buffer call for disconnected clients within reconnection grace period
 	if controller.ReconnectionMgr != nil {
 		controller.ReconnectionMgr.BufferCallForDisconnected(call)
@@ -548,52 +582,6 @@ func (clients *Clients) Remove(client *Client) {
 	delete(clients.Map, client)
 }
 
-func (clients *Clients) UserConnectionCount(user *User) uint {
-	if user == nil {
-		return 0
-	}
-
-	clients.mutex.Lock()
-	defer clients.mutex.Unlock()
-
-	var count uint
-	var toRemove []*Client
-
-	for c := range clients.Map {
-		if c.User == user {
-			// Check if connection is still alive
-			// If Send channel is nil, the client has disconnected
-			if c.Send == nil {
-				toRemove = append(toRemove, c)
-				continue
-			}
-
-			// Check if websocket connection is still open
-			if c.Conn == nil {
-				toRemove = append(toRemove, c)
-				continue
-			}
-
-			// Connection appears to be alive
-			count++
-		}
-	}
-
-	// Remove dead connections immediately
-	for _, c := range toRemove {
-		delete(clients.Map, c)
-		// Try to trigger unregister for proper cleanup, but don't block
-		if c.Controller != nil {
-			select {
-			case c.Controller.Unregister <- c:
-			default:
-			}
-		}
-	}
-
-	return count
-}
-
 // RefreshConfigForGroup refreshes configuration for all active clients belonging to users in the specified group
 func (clients *Clients) RefreshConfigForGroup(controller *Controller, groupId uint64) {
 	clients.mutex.Lock()
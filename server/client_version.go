@@ -0,0 +1,214 @@
+// Copyright (C) 2026 Thinline Dynamic Solutions
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	ClientPlatformIOS     = "ios"
+	ClientPlatformAndroid = "android"
+	ClientPlatformWeb     = "web"
+
+	clientUpgradeDefaultMessage = "This version of the app is no longer supported. Please update to continue."
+)
+
+// clientInfo is what a client announces about itself in the
+// X-Client-Platform, X-Client-Version and X-API-Version headers.
+type clientInfo struct {
+	Platform   string
+	Version    string
+	APIVersion int
+}
+
+func clientInfoFromRequest(r *http.Request) clientInfo {
+	header := func(name string, query string) string {
+		if v := r.Header.Get(name); v != "" {
+			return strings.TrimSpace(v)
+		}
+		return strings.TrimSpace(r.URL.Query().Get(query))
+	}
+
+	info := clientInfo{
+		Platform: strings.ToLower(header("X-Client-Platform", "platform")),
+		Version:  strings.TrimPrefix(header("X-Client-Version", "clientVersion"), "v"),
+	}
+	info.APIVersion, _ = strconv.Atoi(header("X-API-Version", "apiVersion"))
+	return info
+}
+
+// compareVersions compares dotted versions ("2.10.1" > "2.9") numerically,
+// ignoring pre-release and build suffixes.
+func compareVersions(a string, b string) int {
+	parse := func(v string) []int {
+		v, _, _ = strings.Cut(v, "-")
+		v, _, _ = strings.Cut(v, "+")
+		parts := []int{}
+		for _, s := range strings.Split(v, ".") {
+			n, _ := strconv.Atoi(strings.TrimSpace(s))
+			parts = append(parts, n)
+		}
+		return parts
+	}
+
+	pa, pb := parse(a), parse(b)
+	for i := 0; i < len(pa) || i < len(pb); i++ {
+		var x, y int
+		if i < len(pa) {
+			x = pa[i]
+		}
+		if i < len(pb) {
+			y = pb[i]
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// clientUpgrade describes why a client must be upgraded.
+type clientUpgrade struct {
+	MinVersion string
+	UpgradeURL string
+	Message    string
+}
+
+// minVersion returns the oldest app version allowed and its store URL.
+func (cfg ClientVersionConfig) minVersion(platform string) (string, string) {
+	switch platform {
+	case ClientPlatformIOS:
+		return cfg.MinIOS, cfg.IOSUpgradeURL
+	case ClientPlatformAndroid:
+		return cfg.MinAndroid, cfg.AndroidUpgradeURL
+	}
+	return "", ""
+}
+
+// check returns nil when the client may use the API. Clients that do not
+// announce a version, such as older web apps and scripts, are let through.
+func (cfg ClientVersionConfig) check(info clientInfo) *clientUpgrade {
+	minVersion, upgradeURL := cfg.minVersion(info.Platform)
+
+	message := cfg.Message
+	if message == "" {
+		message = clientUpgradeDefaultMessage
+	}
+
+	if info.APIVersion > 0 && info.APIVersion < MinAPIVersion {
+		return &clientUpgrade{MinVersion: minVersion, UpgradeURL: upgradeURL, Message: message}
+	}
+
+	if minVersion != "" && info.Version != "" && compareVersions(info.Version, minVersion) < 0 {
+		return &clientUpgrade{MinVersion: minVersion, UpgradeURL: upgradeURL, Message: message}
+	}
+
+	return nil
+}
+
+// clientVersionExempt lists the paths answered whatever the client version:
+// the version endpoint itself, the admin panel and recorder uploads.
+func clientVersionExempt(path string) bool {
+	if !strings.HasPrefix(path, "/api/") {
+		return true
+	}
+	for _, prefix := range []string{"/api/version", "/api/admin", "/api/health", "/api/call-upload", "/api/trunk-recorder-call-upload"} {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// upgradeProblem is the 426 response of clients older than the minimum.
+type upgradeProblem struct {
+	Problem
+	MinVersion    string `json:"minVersion,omitempty"`
+	UpgradeURL    string `json:"upgradeUrl,omitempty"`
+	APIVersion    int    `json:"apiVersion"`
+	MinAPIVersion int    `json:"minApiVersion"`
+}
+
+// clientVersionMiddleware announces the API version on every response and
+// answers 426 Upgrade Required to clients older than the configured minimum.
+func (controller *Controller) clientVersionMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-API-Version", strconv.Itoa(APIVersion))
+
+		if !clientVersionExempt(r.URL.Path) {
+			if upgrade := controller.Options.ClientVersionConfig.check(clientInfoFromRequest(r)); upgrade != nil {
+				problem := upgradeProblem{
+					Problem:       newProblem(NewAPIError(http.StatusUpgradeRequired, ErrCodeUpgradeRequired, upgrade.Message), r.URL.Path),
+					MinVersion:    upgrade.MinVersion,
+					UpgradeURL:    upgrade.UpgradeURL,
+					APIVersion:    APIVersion,
+					MinAPIVersion: MinAPIVersion,
+				}
+				w.Header().Set("Content-Type", "application/problem+json")
+				w.WriteHeader(http.StatusUpgradeRequired)
+				json.NewEncoder(w).Encode(problem)
+				return
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// VersionHandler tells clients which server and API versions they talk to and
+// whether they must be upgraded, before they call anything else.
+//
+//	GET /api/version?platform=ios&clientVersion=2.3.0&apiVersion=2
+func (api *Api) VersionHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		api.exitWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	cfg := api.Controller.Options.ClientVersionConfig
+	info := clientInfoFromRequest(r)
+
+	clients := map[string]any{}
+	for _, platform := range []string{ClientPlatformIOS, ClientPlatformAndroid} {
+		minVersion, upgradeURL := cfg.minVersion(platform)
+		clients[platform] = map[string]string{"minVersion": minVersion, "upgradeUrl": upgradeURL}
+	}
+
+	response := map[string]any{
+		"version":         Version,
+		"apiVersion":      APIVersion,
+		"minApiVersion":   MinAPIVersion,
+		"clients":         clients,
+		"upgradeRequired": false,
+	}
+	if upgrade := cfg.check(info); upgrade != nil {
+		response["upgradeRequired"] = true
+		response["message"] = upgrade.Message
+		response["minVersion"] = upgrade.MinVersion
+		response["upgradeUrl"] = upgrade.UpgradeURL
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCompareVersions(t *testing.T) {
+	for _, c := range []struct {
+		a, b string
+		want int
+	}{
+		{"2.10.0", "2.9", 1},
+		{"2.4", "2.4.0", 0},
+		{"2.4.0-beta", "2.4.0", 0},
+		{"1.9.9", "2.0", -1},
+	} {
+		if got := compareVersions(c.a, c.b); got != c.want {
+			t.Fatalf("compareVersions(%q, %q) = %d, want %d", c.a, c.b, got, c.want)
+		}
+	}
+}
+
+func TestClientVersionCheck(t *testing.T) {
+	cfg := ClientVersionConfig{MinIOS: "2.4.0", IOSUpgradeURL: "https://apps.apple.com/app/id1"}
+
+	if up := cfg.check(clientInfo{Platform: ClientPlatformIOS, Version: "2.3.9"}); up == nil || up.UpgradeURL != cfg.IOSUpgradeURL || up.Message == "" {
+		t.Fatalf("old iOS app allowed: %+v", up)
+	}
+	if up := cfg.check(clientInfo{Platform: ClientPlatformIOS, Version: "2.4.0"}); up != nil {
+		t.Fatalf("current iOS app refused")
+	}
+	if up := cfg.check(clientInfo{Platform: ClientPlatformAndroid, Version: "1.0"}); up != nil {
+		t.Fatalf("Android refused without a minimum")
+	}
+	if up := cfg.check(clientInfo{Platform: ClientPlatformIOS}); up != nil {
+		t.Fatalf("client without a version refused")
+	}
+	if up := cfg.check(clientInfo{APIVersion: MinAPIVersion - 1}); MinAPIVersion > 1 && up == nil {
+		t.Fatalf("client below the minimum API version allowed")
+	}
+}
+
+func TestClientVersionMiddleware(t *testing.T) {
+	controller := &Controller{Options: &Options{ClientVersionConfig: ClientVersionConfig{MinAndroid: "3.0"}}}
+	handler := controller.clientVersionMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	request := func(path string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, path, nil)
+		r.Header.Set("X-Client-Platform", "android")
+		r.Header.Set("X-Client-Version", "2.9.1")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w
+	}
+
+	w := request("/api/alerts")
+	if w.Code != http.StatusUpgradeRequired || w.Header().Get("X-API-Version") == "" {
+		t.Fatalf("status %d", w.Code)
+	}
+	var problem upgradeProblem
+	if err := json.Unmarshal(w.Body.Bytes(), &problem); err != nil || problem.Code != ErrCodeUpgradeRequired || problem.MinVersion != "3.0" {
+		t.Fatalf("problem = %+v, %v", problem, err)
+	}
+
+	if w := request("/api/version"); w.Code != http.StatusOK {
+		t.Fatalf("version endpoint refused an old client")
+	}
+}
@@ -51,8 +51,37 @@ type Config struct {
 	SslListen            string
 	EnableDebugLog       bool
 	AutoUpdate           bool   // Automatically check and apply updates from GitHub
+	StateSnapshot        bool   // Boot from a signed on-disk state snapshot when it matches the DB
+	StateSnapshotMinutes uint   // Minutes between periodic snapshot writes (0 = default)
+	LogLevel             string // Console log level: debug, info, warn, error
+	LogFormat            string // Console log format: plain, text, json
+	LogSubsystems        string // Per-subsystem console levels, e.g. "tones=debug,radioreference=warn"
+	Logging              LoggingConfig
 	daemon               *Daemon
 	newAdminPassword     string
+	setupAuto            bool
+	SetupSMTP            *SetupSMTPSettings   // [smtp] section written by the setup wizard
+	SetupTranscription   *TranscriptionConfig // [transcription] section written by the setup wizard
+	migrateAudio         bool
+	dedupAudio           bool
+	migrationsStatus     bool
+	migrateDown          string
+	seedDemo             bool
+	toneCheck            string
+	toneCheckSets        string
+	installService       bool
+	configExport         string
+	configImport         string
+	configCredentials    bool
+	backupFile           string
+	backupFrom           string
+	backupTo             string
+	backupSystem         uint
+	restoreFile          string
+	restoreConfig        string
+	restoreCalls         string
+	restoreFiles         string
+	serviceInstall       ServiceInstallOptions
 }
 
 func NewConfig() *Config {
@@ -103,10 +132,35 @@ func NewConfig() *Config {
 	flag.StringVar(&config.ConfigFile, "config", defaultConfigFile, "server config file")
 	flag.StringVar(&config.Listen, "listen", defaultListen, "listening address")
 	flag.StringVar(&config.newAdminPassword, "admin_password", "", "change admin password")
+	flag.BoolVar(&config.setupAuto, "setup_auto", false, "non-interactive setup from flags/environment, prints the result as JSON")
+	flag.BoolVar(&config.installService, "install-service", false, "install the server as a systemd unit or Windows service and exit")
+	flag.StringVar(&config.serviceInstall.User, "service_user", "", "account the installed service runs as")
+	flag.StringVar(&config.serviceInstall.EnvFile, "service_env_file", "", "environment file for the installed service")
+	flag.StringVar(&config.configExport, "config_export", "", "write the configuration to an encrypted archive and exit")
+	flag.StringVar(&config.configImport, "config_import", "", "replace the configuration with an encrypted archive and exit")
+	flag.BoolVar(&config.configCredentials, "config_credentials", false, "include user password hashes and PINs in -config_export and -backup")
+	flag.StringVar(&config.backupFile, "backup", "", "write a backup archive of the configuration, the server files and optionally calls, and exit")
+	flag.StringVar(&config.backupFrom, "backup_from", "", "include the calls from this date or time in -backup")
+	flag.StringVar(&config.backupTo, "backup_to", "", "include the calls until this date or time in -backup")
+	flag.UintVar(&config.backupSystem, "backup_system", 0, "limit the calls of -backup to this system ref")
+	flag.StringVar(&config.restoreFile, "restore", "", "restore a backup archive and exit")
+	flag.StringVar(&config.restoreConfig, "restore_config", "replace", "configuration restore mode for -restore: replace or skip")
+	flag.StringVar(&config.restoreCalls, "restore_calls", "skip", "call conflict mode for -restore: skip, replace or none")
+	flag.StringVar(&config.restoreFiles, "restore_files", "skip", "file conflict mode for -restore: skip or replace")
+	flag.BoolVar(&config.migrateAudio, "migrate_audio", false, "move call audio stored in the database to the configured audio storage and exit")
+	flag.BoolVar(&config.dedupAudio, "dedup_audio", false, "share identical audio of calls stored before dedup was enabled and exit")
+	flag.BoolVar(&config.migrationsStatus, "migrations", false, "list the versioned database migrations and whether they are applied, and exit")
+	flag.StringVar(&config.migrateDown, "migrate_down", "", "revert the newest applied versioned database migration, given by id, and exit")
+	flag.BoolVar(&config.seedDemo, "seed-demo", false, "fill a fresh install with synthetic systems, users, calls and alerts for demos and exit")
+	flag.StringVar(&config.toneCheck, "tone_check", "", "run an audio file through tone detection with verbose diagnostics and exit")
+	flag.StringVar(&config.toneCheckSets, "tone_sets", "", "JSON file of tone sets (or a tone corpus.json) to match in -tone_check")
 	flag.StringVar(&config.SslAutoCert, "ssl_auto_cert", "", "domain name for Let's Encrypt automatic certificate")
 	flag.StringVar(&config.SslCertFile, "ssl_cert_file", "", "ssl PEM formated certificate")
 	flag.StringVar(&config.SslKeyFile, "ssl_key_file", "", "ssl PEM formated key")
 	flag.StringVar(&config.SslListen, "ssl_listen", "", "listening address for ssl")
+	flag.StringVar(&config.LogLevel, "log_level", "", "console log level (debug, info, warn, error)")
+	flag.StringVar(&config.LogFormat, "log_format", "", "console log format (plain, text, json)")
+	flag.StringVar(&config.LogSubsystems, "log_subsystems", "", "per-subsystem console log levels, e.g. tones=debug,radioreference=warn")
 	flag.Parse()
 
 	if !config.isBaseDirWritable() {
@@ -182,6 +236,34 @@ func NewConfig() *Config {
 		if v, err := cfg.Section("").Key("auto_update").Bool(); err == nil {
 			config.AutoUpdate = v
 		}
+
+		// Read state_snapshot settings (defaults to false / 15 minutes)
+		if v, err := cfg.Section("").Key("state_snapshot").Bool(); err == nil {
+			config.StateSnapshot = v
+		}
+		if v, err := cfg.Section("").Key("state_snapshot_interval").Uint(); err == nil {
+			config.StateSnapshotMinutes = v
+		}
+
+		// Read log_* settings (flags take precedence)
+		if v := cfg.Section("").Key("log_level").String(); len(v) > 0 && config.LogLevel == "" {
+			config.LogLevel = v
+		}
+		if v := cfg.Section("").Key("log_format").String(); len(v) > 0 && config.LogFormat == "" {
+			config.LogFormat = v
+		}
+		if v := cfg.Section("").Key("log_subsystems").String(); len(v) > 0 && config.LogSubsystems == "" {
+			config.LogSubsystems = v
+		}
+
+		config.readSetupSections(cfg)
+	}
+
+	if logging, err := parseLoggingConfig(config.LogLevel, config.LogFormat, config.LogSubsystems); err == nil {
+		config.Logging = logging
+	} else {
+		fmt.Printf("invalid log settings, using the defaults: %v\n", err)
+		config.Logging = defaultLoggingConfig()
 	}
 
 		if config.DbType != DbTypePostgresql {
@@ -194,6 +276,14 @@ func NewConfig() *Config {
 		NewCommand(config.BaseDir).Do(*command)
 	}
 
+	if config.installService {
+		if err := runInstallServiceCommand(config, config.serviceInstall); err != nil {
+			fmt.Printf("error: %v\n", err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
 	if *serviceAction != "" {
 		daemon, err := NewDaemon()
 		if err != nil {
@@ -294,6 +384,18 @@ func (config *Config) saveConfig() error {
 		ini = append(ini, "enable_debug_log = true")
 	}
 
+	if config.LogLevel != "" {
+		ini = append(ini, fmt.Sprintf("log_level = %s", config.LogLevel))
+	}
+
+	if config.LogFormat != "" {
+		ini = append(ini, fmt.Sprintf("log_format = %s", config.LogFormat))
+	}
+
+	if config.LogSubsystems != "" {
+		ini = append(ini, fmt.Sprintf("log_subsystems = %s", config.LogSubsystems))
+	}
+
 	file, err := os.Create(config.GetConfigFilePath())
 	if err != nil {
 		return err
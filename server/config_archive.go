@@ -0,0 +1,370 @@
+// Copyright (C) 2026 Thinline Dynamic Solutions
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>
+
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	configArchiveFormat  = "thinline-radio-config"
+	configArchiveVersion = 1
+
+	// configArchivePassphraseEnv supplies the passphrase to -config_export and
+	// -config_import without a prompt.
+	configArchivePassphraseEnv = "THINLINE_ARCHIVE_PASSPHRASE"
+
+	configArchiveMinPassphrase = 12
+	configArchiveMaxSize       = 256 << 20
+)
+
+// ConfigArchive is the on-disk envelope. Data holds the gzipped
+// ConfigArchivePayload, sealed with AES-256-GCM under a scrypt-derived key.
+type ConfigArchive struct {
+	Format  string `json:"format"`
+	Version int    `json:"version"`
+	Kdf     string `json:"kdf"`
+	Salt    string `json:"salt"`
+	Data    string `json:"data"`
+}
+
+type ConfigArchivePayload struct {
+	ExportedAt    string         `json:"exportedAt"`
+	ServerVersion string         `json:"serverVersion"`
+	Credentials   bool           `json:"credentials"`
+	Config        map[string]any `json:"config"`
+}
+
+func configArchiveKey(passphrase string, salt []byte) ([]byte, error) {
+	return scrypt.Key([]byte(passphrase), salt, 1<<15, 8, 1, 32)
+}
+
+// sealConfigArchive encrypts config. Without credentials, user password
+// hashes and PINs are left out of the archive.
+func sealConfigArchive(config map[string]any, passphrase string, credentials bool) ([]byte, error) {
+	if !credentials {
+		config = stripConfigCredentials(config)
+	}
+
+	payload, err := json.Marshal(ConfigArchivePayload{
+		ExportedAt:    time.Now().UTC().Format(time.RFC3339),
+		ServerVersion: Version,
+		Credentials:   credentials,
+		Config:        config,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return sealArchive(configArchiveFormat, payload, passphrase)
+}
+
+// sealArchive gzips payload and encrypts it into an envelope of format.
+func sealArchive(format string, payload []byte, passphrase string) ([]byte, error) {
+	if len(passphrase) < configArchiveMinPassphrase {
+		return nil, fmt.Errorf("the passphrase must be at least %d characters", configArchiveMinPassphrase)
+	}
+
+	var compressed bytes.Buffer
+	zw := gzip.NewWriter(&compressed)
+	if _, err := zw.Write(payload); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	key, err := configArchiveKey(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+	data, err := EncryptAudio(key, compressed.Bytes())
+	if err != nil {
+		return nil, err
+	}
+
+	return json.MarshalIndent(ConfigArchive{
+		Format:  format,
+		Version: configArchiveVersion,
+		Kdf:     "scrypt",
+		Salt:    base64.StdEncoding.EncodeToString(salt),
+		Data:    data,
+	}, "", "  ")
+}
+
+// openConfigArchive decrypts an archive made by sealConfigArchive.
+func openConfigArchive(b []byte, passphrase string) (*ConfigArchivePayload, error) {
+	zr, err := openArchive(b, configArchiveFormat, passphrase)
+	if err == errArchiveFormat {
+		return nil, errors.New("not a configuration archive")
+	} else if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+
+	payload := &ConfigArchivePayload{}
+	if err := json.NewDecoder(io.LimitReader(zr, configArchiveMaxSize)).Decode(payload); err != nil {
+		return nil, err
+	}
+	if payload.Config == nil {
+		return nil, errors.New("the archive holds no configuration")
+	}
+
+	return payload, nil
+}
+
+var errArchiveFormat = errors.New("not an archive of the expected format")
+
+// openArchive decrypts an envelope of format made by sealArchive and returns
+// the decompressed payload.
+func openArchive(b []byte, format string, passphrase string) (io.ReadCloser, error) {
+	archive := ConfigArchive{}
+	if err := json.Unmarshal(b, &archive); err != nil || archive.Format != format {
+		return nil, errArchiveFormat
+	}
+	if archive.Version > configArchiveVersion || archive.Kdf != "scrypt" {
+		return nil, fmt.Errorf("unsupported archive version %d", archive.Version)
+	}
+
+	salt, err := base64.StdEncoding.DecodeString(archive.Salt)
+	if err != nil {
+		return nil, errors.New("invalid archive salt")
+	}
+	key, err := configArchiveKey(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+	compressed, err := DecryptAudio(key, archive.Data)
+	if err != nil {
+		return nil, errors.New("wrong passphrase or damaged archive")
+	}
+
+	return gzip.NewReader(bytes.NewReader(compressed))
+}
+
+// stripConfigCredentials returns a copy of config whose users have no
+// password hash or PIN.
+func stripConfigCredentials(config map[string]any) map[string]any {
+	b, err := json.Marshal(config)
+	if err != nil {
+		return config
+	}
+	stripped := map[string]any{}
+	if err := json.Unmarshal(b, &stripped); err != nil {
+		return config
+	}
+
+	if users, ok := stripped["users"].([]any); ok {
+		for _, u := range users {
+			if user, ok := u.(map[string]any); ok {
+				delete(user, "password")
+				delete(user, "pin")
+			}
+		}
+	}
+
+	return stripped
+}
+
+// prepareConfigArchiveUsers gives users exported without credentials an
+// unusable password and requires a reset, since the import needs a hash.
+func prepareConfigArchiveUsers(config map[string]any) error {
+	users, ok := config["users"].([]any)
+	if !ok {
+		return nil
+	}
+
+	for _, u := range users {
+		user, ok := u.(map[string]any)
+		if !ok {
+			continue
+		}
+		if password, _ := user["password"].(string); password != "" {
+			continue
+		}
+		hash, err := bcrypt.GenerateFromPassword([]byte(generateSetupSecret()), bcrypt.DefaultCost)
+		if err != nil {
+			return err
+		}
+		user["password"] = string(hash)
+		user["forcePasswordReset"] = true
+	}
+
+	return nil
+}
+
+// ExportConfigArchive seals the live configuration.
+func (admin *Admin) ExportConfigArchive(passphrase string, credentials bool) ([]byte, error) {
+	return sealConfigArchive(admin.GetConfig(), passphrase, credentials)
+}
+
+// ImportConfigArchive replaces the configuration with the archive content.
+func (admin *Admin) ImportConfigArchive(b []byte, passphrase string) (*ConfigArchivePayload, error) {
+	payload, err := openConfigArchive(b, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	if err := prepareConfigArchiveUsers(payload.Config); err != nil {
+		return nil, err
+	}
+	if err := admin.importConfig(payload.Config, true); err != nil {
+		return nil, err
+	}
+
+	admin.Controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("configuration restored from archive exported %s by version %s", payload.ExportedAt, payload.ServerVersion))
+
+	return payload, nil
+}
+
+// ConfigArchiveHandler exports (POST {"passphrase","credentials"}) and
+// imports (PUT with the archive as body and the X-Archive-Passphrase header)
+// encrypted configuration archives.
+func (admin *Admin) ConfigArchiveHandler(w http.ResponseWriter, r *http.Request) {
+	t := admin.GetAuthorization(r)
+	if !admin.ValidateToken(t) {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPost:
+		var body struct {
+			Passphrase  string `json:"passphrase"`
+			Credentials bool   `json:"credentials"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		b, err := admin.ExportConfigArchive(body.Passphrase, body.Credentials)
+		if err != nil {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+			return
+		}
+
+		filename := fmt.Sprintf("thinline-radio-config-%s.tlrcfg", time.Now().Format("20060102-150405"))
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+		w.Write(b)
+
+		admin.Controller.Logs.LogEvent(LogLevelInfo, fmt.Sprintf("configuration archive exported (credentials: %v)", body.Credentials))
+
+	case http.MethodPut:
+		b, err := io.ReadAll(io.LimitReader(r.Body, configArchiveMaxSize))
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		if _, err := admin.ImportConfigArchive(b, r.Header.Get("X-Archive-Passphrase")); err != nil {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+			return
+		}
+
+		admin.SendConfig(w)
+
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// configArchivePassphrase reads the passphrase from the environment or the terminal.
+func configArchivePassphrase(confirm bool) (string, error) {
+	if v := os.Getenv(configArchivePassphraseEnv); v != "" {
+		return v, nil
+	}
+
+	passphrase, err := readPassword("Archive passphrase: ")
+	if err != nil {
+		return "", err
+	}
+	if confirm {
+		again, err := readPassword("Confirm passphrase: ")
+		if err != nil {
+			return "", err
+		}
+		if again != passphrase {
+			return "", errors.New("the passphrases do not match")
+		}
+	}
+
+	return passphrase, nil
+}
+
+// runConfigArchiveCommand handles -config_export and -config_import.
+func runConfigArchiveCommand(controller *Controller, exportFile string, importFile string, credentials bool) error {
+	if err := controller.readAllData(false); err != nil {
+		return err
+	}
+
+	if exportFile != "" {
+		passphrase, err := configArchivePassphrase(true)
+		if err != nil {
+			return err
+		}
+		b, err := controller.Admin.ExportConfigArchive(passphrase, credentials)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(exportFile, b, 0600); err != nil {
+			return err
+		}
+		fmt.Printf("configuration exported to %s\n", exportFile)
+		return nil
+	}
+
+	b, err := os.ReadFile(importFile)
+	if err != nil {
+		return err
+	}
+	passphrase, err := configArchivePassphrase(false)
+	if err != nil {
+		return err
+	}
+	payload, err := controller.Admin.ImportConfigArchive(b, passphrase)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("configuration exported %s by version %s imported\n", payload.ExportedAt, payload.ServerVersion)
+	if !payload.Credentials {
+		fmt.Println("the archive has no user credentials: users must reset their passwords and have new PINs")
+	}
+
+	return nil
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestConfigArchiveRoundTrip(t *testing.T) {
+	config := map[string]any{
+		"systems": []any{map[string]any{"id": 1, "label": "County"}},
+		"users":   []any{map[string]any{"email": "a@example.com", "password": "$2a$hash", "pin": "1234"}},
+	}
+
+	b, err := sealConfigArchive(config, "correct horse battery", false)
+	if err != nil {
+		t.Fatalf("seal: %v", err)
+	}
+	if strings.Contains(string(b), "County") {
+		t.Fatalf("archive is not encrypted")
+	}
+
+	if _, err := openConfigArchive(b, "wrong passphrase!"); err == nil {
+		t.Fatalf("wrong passphrase accepted")
+	}
+
+	payload, err := openConfigArchive(b, "correct horse battery")
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	if payload.Credentials {
+		t.Fatalf("credentials flag set")
+	}
+	user := payload.Config["users"].([]any)[0].(map[string]any)
+	if _, ok := user["password"]; ok {
+		t.Fatalf("password hash exported: %v", user)
+	}
+	if _, ok := user["pin"]; ok {
+		t.Fatalf("pin exported: %v", user)
+	}
+	if config["users"].([]any)[0].(map[string]any)["password"] != "$2a$hash" {
+		t.Fatalf("live configuration was modified")
+	}
+
+	if err := prepareConfigArchiveUsers(payload.Config); err != nil {
+		t.Fatalf("prepare: %v", err)
+	}
+	if p, _ := user["password"].(string); !strings.HasPrefix(p, "$2") || user["forcePasswordReset"] != true {
+		t.Fatalf("user not prepared for import: %v", user)
+	}
+}
+
+func TestConfigArchiveRejectsShortPassphrase(t *testing.T) {
+	if _, err := sealConfigArchive(map[string]any{}, "short", true); err == nil {
+		t.Fatalf("short passphrase accepted")
+	}
+	if _, err := openConfigArchive([]byte(`{"format":"other"}`), "correct horse battery"); err == nil {
+		t.Fatalf("foreign file accepted")
+	}
+}
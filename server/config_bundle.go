@@ -0,0 +1,574 @@
+// Copyright (C) 2026 Thinline Dynamic Solutions
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	configBundleFormat  = "thinline-radio-systems"
+	configBundleVersion = 1
+	configBundleMaxSize = 64 << 20
+)
+
+// systemTagListKeys are the system settings holding lists of tag ids.
+var systemTagListKeys = []string{"autoLearnToneSetsTagIds", "bulkToneDetectionTagIds", "autoLearnUnitAliasesTagIds"}
+
+// ConfigBundle is a portable export of the systems, talkgroups (with their
+// tone sets), sites, units, groups, tags and API keys. Database ids are left
+// out: systems, talkgroups, sites and units are matched by ref, and groups
+// and tags are referenced by label, so a bundle imports into another server.
+type ConfigBundle struct {
+	Format        string           `json:"format"`
+	Version       int              `json:"version"`
+	ExportedAt    string           `json:"exportedAt"`
+	ServerVersion string           `json:"serverVersion"`
+	Secrets       bool             `json:"secrets"`
+	Groups        []map[string]any `json:"groups"`
+	Tags          []map[string]any `json:"tags"`
+	Systems       []map[string]any `json:"systems"`
+	Apikeys       []map[string]any `json:"apikeys"`
+}
+
+// ConfigBundleImportResult counts what an import created and updated.
+type ConfigBundleImportResult struct {
+	Groups            configBundleCount `json:"groups"`
+	Tags              configBundleCount `json:"tags"`
+	Systems           configBundleCount `json:"systems"`
+	Talkgroups        configBundleCount `json:"talkgroups"`
+	Apikeys           configBundleCount `json:"apikeys"`
+	GeneratedKeys     []string          `json:"generatedKeys,omitempty"` // idents of API keys imported without a key
+	UnknownLabels     []string          `json:"unknownLabels,omitempty"`
+	RemovedTalkgroups int               `json:"removedTalkgroups,omitempty"`
+}
+
+type configBundleCount struct {
+	Created int `json:"created"`
+	Updated int `json:"updated"`
+}
+
+// toMap round-trips v through its JSON encoding.
+func toMap(v any) map[string]any {
+	m := map[string]any{}
+	if b, err := json.Marshal(v); err == nil {
+		json.Unmarshal(b, &m)
+	}
+	return m
+}
+
+func mapList(v any) []map[string]any {
+	list := []map[string]any{}
+	if items, ok := v.([]any); ok {
+		for _, item := range items {
+			if m, ok := item.(map[string]any); ok {
+				list = append(list, m)
+			}
+		}
+	}
+	return list
+}
+
+func anyList(list []map[string]any) []any {
+	out := make([]any, len(list))
+	for i, m := range list {
+		out[i] = m
+	}
+	return out
+}
+
+func bundleUint(v any) uint64 {
+	if f, ok := v.(float64); ok && f > 0 {
+		return uint64(f)
+	}
+	return 0
+}
+
+// BuildConfigBundle exports the configuration. API key secrets, the
+// TonesToActive keys of talkgroups and the paging tokens of tone sets are only
+// included with secrets.
+func (controller *Controller) BuildConfigBundle(secrets bool) *ConfigBundle {
+	bundle := &ConfigBundle{
+		Format:        configBundleFormat,
+		Version:       configBundleVersion,
+		ExportedAt:    time.Now().UTC().Format(time.RFC3339),
+		ServerVersion: Version,
+		Secrets:       secrets,
+		Groups:        []map[string]any{},
+		Tags:          []map[string]any{},
+		Systems:       []map[string]any{},
+		Apikeys:       []map[string]any{},
+	}
+
+	groupLabels := map[uint64]string{}
+	controller.Groups.mutex.RLock()
+	for _, group := range controller.Groups.List {
+		groupLabels[group.Id] = group.Label
+		m := toMap(group)
+		delete(m, "id")
+		bundle.Groups = append(bundle.Groups, m)
+	}
+	controller.Groups.mutex.RUnlock()
+
+	tagLabels := map[uint64]string{}
+	controller.Tags.mutex.RLock()
+	for _, tag := range controller.Tags.List {
+		tagLabels[tag.Id] = tag.Label
+		m := toMap(tag)
+		delete(m, "id")
+		bundle.Tags = append(bundle.Tags, m)
+	}
+	controller.Tags.mutex.RUnlock()
+
+	labels := func(v any, known map[uint64]string) []any {
+		out := []any{}
+		if ids, ok := v.([]any); ok {
+			for _, id := range ids {
+				if label, ok := known[bundleUint(id)]; ok {
+					out = append(out, label)
+				}
+			}
+		}
+		return out
+	}
+
+	controller.Systems.mutex.RLock()
+	systems := append([]*System{}, controller.Systems.List...)
+	controller.Systems.mutex.RUnlock()
+
+	for _, system := range systems {
+		m := toMap(system)
+		delete(m, "id")
+		for _, key := range systemTagListKeys {
+			m[key] = labels(m[key], tagLabels)
+		}
+
+		talkgroups := mapList(m["talkgroups"])
+		for _, talkgroup := range talkgroups {
+			delete(talkgroup, "id")
+			talkgroup["groupIds"] = labels(talkgroup["groupIds"], groupLabels)
+			if label, ok := tagLabels[bundleUint(talkgroup["tagId"])]; ok {
+				talkgroup["tagId"] = label
+			} else {
+				delete(talkgroup, "tagId")
+			}
+			if !secrets {
+				delete(talkgroup, "toneDownstreamAPIKey")
+				for _, toneSet := range mapList(talkgroup["toneSets"]) {
+					delete(toneSet, "dispatchToken")
+				}
+			}
+		}
+		m["talkgroups"] = talkgroups
+
+		for _, key := range []string{"sites", "units"} {
+			items := mapList(m[key])
+			for _, item := range items {
+				delete(item, "id")
+				delete(item, "systemId")
+			}
+			m[key] = items
+		}
+
+		bundle.Systems = append(bundle.Systems, m)
+	}
+
+	for _, apikey := range controller.Apikeys.List {
+		m := toMap(apikey)
+		delete(m, "id")
+		if !secrets {
+			delete(m, "key")
+		}
+		bundle.Apikeys = append(bundle.Apikeys, m)
+	}
+
+	return bundle
+}
+
+// parseConfigBundle reads and checks a bundle.
+func parseConfigBundle(b []byte) (*ConfigBundle, error) {
+	bundle := &ConfigBundle{}
+	if err := json.Unmarshal(b, bundle); err != nil || bundle.Format != configBundleFormat {
+		return nil, errors.New("not a systems configuration bundle")
+	}
+	if bundle.Version < 1 || bundle.Version > configBundleVersion {
+		return nil, fmt.Errorf("unsupported bundle version %d", bundle.Version)
+	}
+	return bundle, nil
+}
+
+// unitBundleKey matches units by radio id, or by range.
+func unitBundleKey(m map[string]any) string {
+	if ref := bundleUint(m["unitRef"]); ref > 0 {
+		return strconv.FormatUint(ref, 10)
+	}
+	return fmt.Sprintf("%d-%d", bundleUint(m["unitFrom"]), bundleUint(m["unitTo"]))
+}
+
+// ImportConfigBundle merges a bundle into the configuration. Groups and tags
+// are matched by label, systems by systemRef, their talkgroups, sites and
+// units by ref, and API keys by ident. Existing ones are updated and missing
+// ones created. Talkgroups and sites absent from the bundle are kept unless
+// prune, which deletes them with their calls.
+func (admin *Admin) ImportConfigBundle(bundle *ConfigBundle, prune bool) (*ConfigBundleImportResult, error) {
+	admin.mutex.Lock()
+	defer admin.mutex.Unlock()
+
+	controller := admin.Controller
+	db := controller.Database
+	result := &ConfigBundleImportResult{}
+	unknown := map[string]bool{}
+
+	// Groups and tags first: talkgroups reference them
+	for _, m := range bundle.Groups {
+		label, _ := m["label"].(string)
+		if label == "" {
+			continue
+		}
+		if existing, ok := controller.Groups.GetGroupByLabel(label); ok {
+			m["id"] = float64(existing.Id)
+			*existing = *NewGroup().FromMap(m)
+			result.Groups.Updated++
+		} else {
+			delete(m, "id")
+			controller.Groups.mutex.Lock()
+			controller.Groups.List = append(controller.Groups.List, NewGroup().FromMap(m))
+			controller.Groups.mutex.Unlock()
+			result.Groups.Created++
+		}
+	}
+	if err := controller.Groups.Write(db); err != nil {
+		return nil, fmt.Errorf("failed to write groups: %w", err)
+	}
+	if err := controller.Groups.Read(db); err != nil {
+		return nil, fmt.Errorf("failed to read groups: %w", err)
+	}
+
+	for _, m := range bundle.Tags {
+		label, _ := m["label"].(string)
+		if label == "" {
+			continue
+		}
+		if existing, ok := controller.Tags.GetTagByLabel(label); ok {
+			m["id"] = float64(existing.Id)
+			*existing = *NewTag().FromMap(m)
+			result.Tags.Updated++
+		} else {
+			delete(m, "id")
+			controller.Tags.mutex.Lock()
+			controller.Tags.List = append(controller.Tags.List, NewTag().FromMap(m))
+			controller.Tags.mutex.Unlock()
+			result.Tags.Created++
+		}
+	}
+	if err := controller.Tags.Write(db); err != nil {
+		return nil, fmt.Errorf("failed to write tags: %w", err)
+	}
+	if err := controller.Tags.Read(db); err != nil {
+		return nil, fmt.Errorf("failed to read tags: %w", err)
+	}
+
+	groupId := func(label string) (float64, bool) {
+		if group, ok := controller.Groups.GetGroupByLabel(label); ok {
+			return float64(group.Id), true
+		}
+		unknown["group "+label] = true
+		return 0, false
+	}
+	tagId := func(label string) (float64, bool) {
+		if tag, ok := controller.Tags.GetTagByLabel(label); ok {
+			return float64(tag.Id), true
+		}
+		unknown["tag "+label] = true
+		return 0, false
+	}
+	tagIds := func(v any) []any {
+		out := []any{}
+		if labels, ok := v.([]any); ok {
+			for _, l := range labels {
+				if label, ok := l.(string); ok {
+					if id, ok := tagId(label); ok {
+						out = append(out, id)
+					}
+				}
+			}
+		}
+		return out
+	}
+
+	for _, m := range bundle.Systems {
+		systemRef := bundleUint(m["systemRef"])
+		if systemRef == 0 {
+			continue
+		}
+		existing, exists := controller.Systems.GetSystemByRef(uint(systemRef))
+
+		for _, key := range systemTagListKeys {
+			m[key] = tagIds(m[key])
+		}
+
+		talkgroups := mapList(m["talkgroups"])
+		seenTalkgroups := map[uint]bool{}
+		for _, talkgroup := range talkgroups {
+			ref := uint(bundleUint(talkgroup["talkgroupRef"]))
+			seenTalkgroups[ref] = true
+
+			ids := []any{}
+			if labels, ok := talkgroup["groupIds"].([]any); ok {
+				for _, l := range labels {
+					if label, ok := l.(string); ok {
+						if id, ok := groupId(label); ok {
+							ids = append(ids, id)
+						}
+					}
+				}
+			}
+			talkgroup["groupIds"] = ids
+			delete(talkgroup, "id")
+			if label, ok := talkgroup["tagId"].(string); ok {
+				if id, ok := tagId(label); ok {
+					talkgroup["tagId"] = id
+				} else {
+					delete(talkgroup, "tagId")
+				}
+			}
+
+			if exists {
+				if current, ok := existing.Talkgroups.GetTalkgroupByRef(ref); ok {
+					talkgroup["id"] = float64(current.Id)
+					if _, ok := talkgroup["toneDownstreamAPIKey"]; !ok && current.ToneDownstreamAPIKey != "" {
+						talkgroup["toneDownstreamAPIKey"] = current.ToneDownstreamAPIKey
+					}
+					for _, toneSet := range mapList(talkgroup["toneSets"]) {
+						if _, ok := toneSet["dispatchToken"]; ok {
+							continue
+						}
+						for _, ts := range current.ToneSets {
+							if ts.Id == toneSet["id"] && ts.DispatchToken != "" {
+								toneSet["dispatchToken"] = ts.DispatchToken
+							}
+						}
+					}
+					result.Talkgroups.Updated++
+					continue
+				}
+			}
+			result.Talkgroups.Created++
+		}
+
+		sites := mapList(m["sites"])
+		seenSites := map[string]bool{}
+		for _, site := range sites {
+			ref, _ := site["siteRef"].(string)
+			seenSites[ref] = true
+			delete(site, "id")
+			if exists {
+				if current, ok := existing.Sites.GetSiteByRef(ref); ok {
+					site["id"] = float64(current.Id)
+				}
+			}
+		}
+
+		units := mapList(m["units"])
+		seenUnits := map[string]bool{}
+		currentUnits := map[string]*Unit{}
+		if exists {
+			for _, unit := range existing.Units.List {
+				currentUnits[unitBundleKey(toMap(unit))] = unit
+			}
+		}
+		for _, unit := range units {
+			key := unitBundleKey(unit)
+			seenUnits[key] = true
+			delete(unit, "id")
+			if current, ok := currentUnits[key]; ok {
+				unit["id"] = float64(current.Id)
+			}
+		}
+
+		if exists && !prune {
+			for _, current := range existing.Talkgroups.List {
+				if !seenTalkgroups[current.TalkgroupRef] {
+					talkgroups = append(talkgroups, toMap(current))
+				}
+			}
+			for _, current := range existing.Sites.List {
+				if !seenSites[current.SiteRef] {
+					sites = append(sites, toMap(current))
+				}
+			}
+			for key, current := range currentUnits {
+				if !seenUnits[key] {
+					units = append(units, toMap(current))
+				}
+			}
+		} else if exists {
+			for _, current := range existing.Talkgroups.List {
+				if !seenTalkgroups[current.TalkgroupRef] {
+					result.RemovedTalkgroups++
+				}
+			}
+		}
+
+		m["talkgroups"] = anyList(talkgroups)
+		m["sites"] = anyList(sites)
+		m["units"] = anyList(units)
+
+		if exists {
+			m["id"] = float64(existing.Id)
+			system := NewSystem().FromMap(m)
+			controller.Systems.mutex.Lock()
+			for i, s := range controller.Systems.List {
+				if s.Id == existing.Id {
+					controller.Systems.List[i] = system
+				}
+			}
+			controller.Systems.mutex.Unlock()
+			result.Systems.Updated++
+		} else {
+			delete(m, "id")
+			controller.Systems.mutex.Lock()
+			controller.Systems.List = append(controller.Systems.List, NewSystem().FromMap(m))
+			controller.Systems.mutex.Unlock()
+			result.Systems.Created++
+		}
+	}
+
+	if err := controller.Systems.Write(db); err != nil {
+		controller.Systems.Read(db)
+		return nil, fmt.Errorf("failed to write systems: %w", err)
+	}
+	if err := controller.Systems.Read(db); err != nil {
+		return nil, fmt.Errorf("failed to read systems: %w", err)
+	}
+	if err := controller.IdLookupsCache.Read(db); err != nil {
+		controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("failed to reload ID lookups cache: %v", err))
+	}
+
+	if len(bundle.Apikeys) > 0 {
+		for _, m := range bundle.Apikeys {
+			ident, _ := m["ident"].(string)
+			if ident == "" {
+				continue
+			}
+			delete(m, "id")
+
+			var existing *Apikey
+			for _, apikey := range controller.Apikeys.List {
+				if apikey.Ident == ident {
+					existing = apikey
+					break
+				}
+			}
+
+			if key, _ := m["key"].(string); key == "" {
+				if existing != nil {
+					m["key"] = existing.Key
+				} else {
+					m["key"] = uuid.New().String()
+					result.GeneratedKeys = append(result.GeneratedKeys, ident)
+				}
+			}
+
+			if existing != nil {
+				m["id"] = float64(existing.Id)
+				*existing = *NewApikey().FromMap(m)
+				result.Apikeys.Updated++
+			} else {
+				controller.Apikeys.List = append(controller.Apikeys.List, NewApikey().FromMap(m))
+				result.Apikeys.Created++
+			}
+		}
+		if err := controller.Apikeys.Write(db); err != nil {
+			return nil, fmt.Errorf("failed to write api keys: %w", err)
+		}
+		if err := controller.Apikeys.Read(db); err != nil {
+			return nil, fmt.Errorf("failed to read api keys: %w", err)
+		}
+	}
+
+	for label := range unknown {
+		result.UnknownLabels = append(result.UnknownLabels, label)
+	}
+
+	controller.SyncConfigToFile()
+	go controller.EmitConfig()
+
+	controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("systems configuration imported from a bundle exported %s by version %s: %d systems, %d talkgroups", bundle.ExportedAt, bundle.ServerVersion, result.Systems.Created+result.Systems.Updated, result.Talkgroups.Created+result.Talkgroups.Updated))
+
+	return result, nil
+}
+
+// ConfigBundleHandler exports (GET ?secrets=true) and imports (PUT the
+// bundle, ?prune=true) the systems configuration as a JSON bundle.
+func (admin *Admin) ConfigBundleHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	writeError := func(status int, message string) {
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(map[string]string{"error": message})
+	}
+
+	t := admin.GetAuthorization(r)
+	if !admin.ValidatePermission(t, PermissionManageSystems) {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	// Bundles span every tenant and the shared groups, tags and API keys
+	if admin.tokenTenant(t) != nil {
+		writeError(http.StatusForbidden, "bundles are only available to full administrators")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		bundle := admin.Controller.BuildConfigBundle(r.URL.Query().Get("secrets") == "true")
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="thinline-systems-%s.json"`, time.Now().Format("20060102-150405")))
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		enc.Encode(bundle)
+
+	case http.MethodPut:
+		b, err := io.ReadAll(io.LimitReader(r.Body, configBundleMaxSize))
+		if err != nil {
+			writeError(http.StatusBadRequest, "failed to read the bundle")
+			return
+		}
+		bundle, err := parseConfigBundle(b)
+		if err != nil {
+			writeError(http.StatusBadRequest, err.Error())
+			return
+		}
+		result, err := admin.ImportConfigBundle(bundle, r.URL.Query().Get("prune") == "true")
+		if err != nil {
+			writeError(http.StatusInternalServerError, err.Error())
+			return
+		}
+		json.NewEncoder(w).Encode(result)
+
+	default:
+		writeError(http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
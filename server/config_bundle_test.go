@@ -0,0 +1,75 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestConfigBundleExportUsesLabels(t *testing.T) {
+	controller := &Controller{Groups: NewGroups(), Tags: NewTags(), Systems: NewSystems(), Apikeys: NewApikeys()}
+	controller.Groups.List = []*Group{{Id: 7, Label: "Fire"}}
+	controller.Tags.List = []*Tag{{Id: 3, Label: "Dispatch"}}
+
+	system := NewSystem()
+	system.Id = 11
+	system.SystemRef = 100
+	system.Label = "County"
+	talkgroup := NewTalkgroup()
+	talkgroup.Id = 42
+	talkgroup.TalkgroupRef = 1001
+	talkgroup.Label = "FD Dispatch"
+	talkgroup.GroupIds = []uint64{7}
+	talkgroup.TagId = 3
+	system.Talkgroups.List = []*Talkgroup{talkgroup}
+	controller.Systems.List = []*System{system}
+	controller.Apikeys.List = []*Apikey{{Id: 1, Ident: "recorder", Key: "secret"}}
+
+	bundle := controller.BuildConfigBundle(false)
+	b, err := json.Marshal(bundle)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	parsed, err := parseConfigBundle(b)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+
+	if _, ok := parsed.Systems[0]["id"]; ok {
+		t.Fatalf("system id exported: %v", parsed.Systems[0])
+	}
+	tg := mapList(parsed.Systems[0]["talkgroups"])[0]
+	if _, ok := tg["id"]; ok {
+		t.Fatalf("talkgroup id exported: %v", tg)
+	}
+	if groups, _ := tg["groupIds"].([]any); len(groups) != 1 || groups[0] != "Fire" {
+		t.Fatalf("group not exported by label: %v", tg["groupIds"])
+	}
+	if tg["tagId"] != "Dispatch" {
+		t.Fatalf("tag not exported by label: %v", tg["tagId"])
+	}
+	if _, ok := parsed.Apikeys[0]["key"]; ok {
+		t.Fatalf("api key secret exported: %v", parsed.Apikeys[0])
+	}
+
+	if key := controller.BuildConfigBundle(true).Apikeys[0]["key"]; key != "secret" {
+		t.Fatalf("api key secret missing with secrets: %v", key)
+	}
+}
+
+func TestConfigBundleRejectsForeignFiles(t *testing.T) {
+	if _, err := parseConfigBundle([]byte(`{"format":"thinline-radio-archive","version":1}`)); err == nil {
+		t.Fatalf("foreign format accepted")
+	}
+	if _, err := parseConfigBundle([]byte(`{"format":"thinline-radio-systems","version":99}`)); err == nil {
+		t.Fatalf("future version accepted")
+	}
+}
+
+func TestUnitBundleKey(t *testing.T) {
+	if k := unitBundleKey(map[string]any{"unitRef": float64(5012)}); k != "5012" {
+		t.Fatalf("unit key %q", k)
+	}
+	if k := unitBundleKey(map[string]any{"unitFrom": float64(100), "unitTo": float64(199)}); k != "100-199" {
+		t.Fatalf("range key %q", k)
+	}
+}
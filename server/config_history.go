@@ -0,0 +1,514 @@
+// Copyright (C) 2026 Thinline Dynamic Solutions
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>
+
+package main
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+var configHistoryLog = Logger(LogCategoryAdmin)
+
+const (
+	// configHistoryDebounce coalesces bursts of saves (bulk imports, auto-populate)
+	// into a single revision.
+	configHistoryDebounce  = 5 * time.Second
+	configHistoryRetention = 365 * 24 * time.Hour
+)
+
+// configHistoryUserFields are the user properties that govern what a listener can
+// hear and when. Logins, billing and credentials are left out of revisions.
+var configHistoryUserFields = []string{"id", "email", "systems", "delay", "systemDelays", "talkgroupDelays", "connectionLimit", "userGroupId", "isGroupAdmin", "systemAdmin"}
+
+// ConfigHistory records a revision of the configuration every time it changes so
+// that the state at any past moment can be reconstructed and compared.
+type ConfigHistory struct {
+	controller *Controller
+	mutex      sync.Mutex
+	timer      *time.Timer
+	lastHash   string
+}
+
+type ConfigRevision struct {
+	Id        uint64         `json:"id"`
+	CreatedAt int64          `json:"createdAt"`
+	State     map[string]any `json:"-"`
+}
+
+type ConfigFieldChange struct {
+	From any `json:"from"`
+	To   any `json:"to"`
+}
+
+type ConfigEntityChange struct {
+	Key     string                       `json:"key"`
+	Label   string                       `json:"label"`
+	Changes map[string]ConfigFieldChange `json:"changes,omitempty"`
+}
+
+type ConfigSectionDiff struct {
+	Added   []ConfigEntityChange `json:"added"`
+	Removed []ConfigEntityChange `json:"removed"`
+	Changed []ConfigEntityChange `json:"changed"`
+}
+
+type ConfigDiff struct {
+	Systems     ConfigSectionDiff            `json:"systems"`
+	Talkgroups  ConfigSectionDiff            `json:"talkgroups"`
+	Groups      ConfigSectionDiff            `json:"groups"`
+	Tags        ConfigSectionDiff            `json:"tags"`
+	UserGroups  ConfigSectionDiff            `json:"userGroups"`
+	Users       ConfigSectionDiff            `json:"users"`
+	Apikeys     ConfigSectionDiff            `json:"apikeys"`
+	Dirwatch    ConfigSectionDiff            `json:"dirwatch"`
+	Downstreams ConfigSectionDiff            `json:"downstreams"`
+	Options     map[string]ConfigFieldChange `json:"options"`
+}
+
+func NewConfigHistory(controller *Controller) *ConfigHistory {
+	return &ConfigHistory{controller: controller}
+}
+
+// Record schedules a revision capture. It is cheap to call after every save.
+func (history *ConfigHistory) Record() {
+	history.mutex.Lock()
+	defer history.mutex.Unlock()
+
+	if history.timer != nil {
+		history.timer.Stop()
+	}
+	history.timer = time.AfterFunc(configHistoryDebounce, func() {
+		if err := history.Capture(); err != nil {
+			history.controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("config history: %v", err))
+		}
+	})
+}
+
+// Capture stores the current configuration as a new revision unless it is
+// identical to the latest one.
+func (history *ConfigHistory) Capture() error {
+	b, err := json.Marshal(history.currentState())
+	if err != nil {
+		return err
+	}
+
+	sum := sha256.Sum256(b)
+	hash := hex.EncodeToString(sum[:])
+
+	history.mutex.Lock()
+	defer history.mutex.Unlock()
+
+	if history.lastHash == "" {
+		query := `SELECT "hash" FROM "configRevisions" ORDER BY "configRevisionId" DESC LIMIT 1`
+		if err := history.controller.Database.Sql.QueryRow(query).Scan(&history.lastHash); err != nil && err != sql.ErrNoRows {
+			return err
+		}
+	}
+
+	if hash == history.lastHash {
+		return nil
+	}
+
+	// Entity changes since the previous revision go to the outbox with it
+	var events []OutboxEvent
+	if history.controller.outboxEnabled() {
+		var previous, current map[string]any
+		var state string
+		query := `SELECT "state" FROM "configRevisions" ORDER BY "configRevisionId" DESC LIMIT 1`
+		if err := history.controller.Database.Sql.QueryRow(query).Scan(&state); err == nil {
+			json.Unmarshal([]byte(state), &previous)
+		} else if err != sql.ErrNoRows {
+			return err
+		}
+		if err := json.Unmarshal(b, &current); err != nil {
+			return err
+		}
+		events = outboxEventsForConfig(previous, current)
+	}
+
+	tx, err := history.controller.Database.Sql.Begin()
+	if err != nil {
+		return err
+	}
+
+	query := `INSERT INTO "configRevisions" ("createdAt", "hash", "state") VALUES ($1, $2, $3)`
+	if _, err := tx.Exec(query, time.Now().UnixMilli(), hash, string(b)); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := writeOutboxEvents(tx, events...); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	history.lastHash = hash
+
+	return nil
+}
+
+// Flush writes any pending revision immediately, used on shutdown.
+func (history *ConfigHistory) Flush() {
+	history.mutex.Lock()
+	pending := history.timer != nil && history.timer.Stop()
+	history.timer = nil
+	history.mutex.Unlock()
+
+	if pending {
+		if err := history.Capture(); err != nil {
+			configHistoryLog.Error(fmt.Sprintf("config history: %v", err))
+		}
+	}
+}
+
+// At returns the latest revision recorded at or before ts (unix milliseconds).
+func (history *ConfigHistory) At(ts int64) (*ConfigRevision, error) {
+	var (
+		revision = &ConfigRevision{}
+		state    string
+	)
+
+	query := `SELECT "configRevisionId", "createdAt", "state" FROM "configRevisions" WHERE "createdAt" <= $1 ORDER BY "createdAt" DESC, "configRevisionId" DESC LIMIT 1`
+	if err := history.controller.Database.Sql.QueryRow(query, ts).Scan(&revision.Id, &revision.CreatedAt, &state); err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(state), &revision.State); err != nil {
+		return nil, fmt.Errorf("revision %d: %v", revision.Id, err)
+	}
+
+	return revision, nil
+}
+
+// Between lists the revisions recorded in the (from, to] window.
+func (history *ConfigHistory) Between(from int64, to int64) ([]ConfigRevision, error) {
+	revisions := []ConfigRevision{}
+
+	query := `SELECT "configRevisionId", "createdAt" FROM "configRevisions" WHERE "createdAt" > $1 AND "createdAt" <= $2 ORDER BY "createdAt" ASC`
+	rows, err := history.controller.Database.Sql.Query(query, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var revision ConfigRevision
+		if err := rows.Scan(&revision.Id, &revision.CreatedAt); err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, revision)
+	}
+
+	return revisions, rows.Err()
+}
+
+func (history *ConfigHistory) Prune() error {
+	cutoff := time.Now().Add(-configHistoryRetention).UnixMilli()
+
+	// Always keep the newest revision so the current state stays reconstructible.
+	query := `DELETE FROM "configRevisions" WHERE "createdAt" < $1 AND "configRevisionId" <> (SELECT MAX("configRevisionId") FROM "configRevisions")`
+	_, err := history.controller.Database.Sql.Exec(query, cutoff)
+
+	return err
+}
+
+func (history *ConfigHistory) currentState() map[string]any {
+	config := history.controller.Admin.GetConfig()
+
+	delete(config, "deviceTokens")
+	delete(config, "userAlertPreferences")
+
+	if users, ok := config["users"].([]map[string]any); ok {
+		list := make([]map[string]any, 0, len(users))
+		for _, user := range users {
+			m := map[string]any{}
+			for _, field := range configHistoryUserFields {
+				m[field] = user[field]
+			}
+			list = append(list, m)
+		}
+		config["users"] = list
+	}
+
+	return config
+}
+
+// diffConfigStates compares two reconstructed configurations. Either side may be
+// nil when no revision exists yet at that point in time.
+func diffConfigStates(from map[string]any, to map[string]any) ConfigDiff {
+	diff := ConfigDiff{
+		Systems:     diffConfigSection(from["systems"], to["systems"], "systemRef", "label", "talkgroups", "units", "sites"),
+		Talkgroups:  diffConfigSection(flattenConfigTalkgroups(from["systems"]), flattenConfigTalkgroups(to["systems"]), "key", "label"),
+		Groups:      diffConfigSection(from["groups"], to["groups"], "id", "label"),
+		Tags:        diffConfigSection(from["tags"], to["tags"], "id", "label"),
+		UserGroups:  diffConfigSection(from["userGroups"], to["userGroups"], "id", "name"),
+		Users:       diffConfigSection(from["users"], to["users"], "id", "email"),
+		Apikeys:     diffConfigSection(from["apikeys"], to["apikeys"], "id", "ident", "key"),
+		Dirwatch:    diffConfigSection(from["dirwatch"], to["dirwatch"], "id", "directory"),
+		Downstreams: diffConfigSection(from["downstreams"], to["downstreams"], "id", "url", "apikey"),
+		Options:     map[string]ConfigFieldChange{},
+	}
+
+	fromOptions, _ := from["options"].(map[string]any)
+	toOptions, _ := to["options"].(map[string]any)
+	diff.Options = diffConfigFields(fromOptions, toOptions)
+
+	return diff
+}
+
+// flattenConfigTalkgroups lifts the talkgroups out of each system, keyed by
+// systemRef/talkgroupRef so they survive a config export and re-import.
+func flattenConfigTalkgroups(systems any) []any {
+	list := []any{}
+
+	items, _ := systems.([]any)
+	for _, item := range items {
+		system, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		talkgroups, _ := system["talkgroups"].([]any)
+		for _, t := range talkgroups {
+			talkgroup, ok := t.(map[string]any)
+			if !ok {
+				continue
+			}
+			m := map[string]any{}
+			for k, v := range talkgroup {
+				m[k] = v
+			}
+			m["key"] = fmt.Sprintf("%v/%v", configValueString(system["systemRef"]), configValueString(talkgroup["talkgroupRef"]))
+			m["label"] = fmt.Sprintf("%v / %v", system["label"], talkgroup["label"])
+			delete(m, "id")
+			list = append(list, m)
+		}
+	}
+
+	return list
+}
+
+func diffConfigSection(from any, to any, keyField string, labelField string, ignore ...string) ConfigSectionDiff {
+	diff := ConfigSectionDiff{
+		Added:   []ConfigEntityChange{},
+		Removed: []ConfigEntityChange{},
+		Changed: []ConfigEntityChange{},
+	}
+
+	index := func(v any) (map[string]map[string]any, []string) {
+		m := map[string]map[string]any{}
+		keys := []string{}
+		items, _ := v.([]any)
+		for _, item := range items {
+			entity, ok := item.(map[string]any)
+			if !ok {
+				continue
+			}
+			entity = copyConfigEntity(entity, ignore)
+			key := configValueString(entity[keyField])
+			if _, exists := m[key]; !exists {
+				keys = append(keys, key)
+			}
+			m[key] = entity
+		}
+		return m, keys
+	}
+
+	fromIndex, fromKeys := index(from)
+	toIndex, toKeys := index(to)
+
+	for _, key := range fromKeys {
+		if _, ok := toIndex[key]; !ok {
+			diff.Removed = append(diff.Removed, ConfigEntityChange{Key: key, Label: configValueString(fromIndex[key][labelField])})
+		}
+	}
+
+	for _, key := range toKeys {
+		entity := toIndex[key]
+		previous, ok := fromIndex[key]
+		if !ok {
+			diff.Added = append(diff.Added, ConfigEntityChange{Key: key, Label: configValueString(entity[labelField])})
+			continue
+		}
+		if changes := diffConfigFields(previous, entity); len(changes) > 0 {
+			diff.Changed = append(diff.Changed, ConfigEntityChange{Key: key, Label: configValueString(entity[labelField]), Changes: changes})
+		}
+	}
+
+	return diff
+}
+
+func diffConfigFields(from map[string]any, to map[string]any) map[string]ConfigFieldChange {
+	changes := map[string]ConfigFieldChange{}
+
+	fields := map[string]bool{}
+	for k := range from {
+		fields[k] = true
+	}
+	for k := range to {
+		fields[k] = true
+	}
+
+	for field := range fields {
+		if !reflect.DeepEqual(from[field], to[field]) {
+			changes[field] = ConfigFieldChange{From: from[field], To: to[field]}
+		}
+	}
+
+	return changes
+}
+
+func copyConfigEntity(entity map[string]any, ignore []string) map[string]any {
+	m := make(map[string]any, len(entity))
+	for k, v := range entity {
+		m[k] = v
+	}
+	for _, field := range ignore {
+		delete(m, field)
+	}
+	return m
+}
+
+// configValueString renders JSON scalars without float noise (1201 rather than 1201.000000).
+func configValueString(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// ConfigDiffHandler reconstructs the configuration at two timestamps and returns
+// a structured diff. GET /api/admin/config/diff?from=<ms>&to=<ms>; "to" defaults to now.
+func (admin *Admin) ConfigDiffHandler(w http.ResponseWriter, r *http.Request) {
+	t := admin.GetAuthorization(r)
+	if !admin.ValidateToken(t) {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	writeError := func(status int, message string) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(map[string]string{"error": message})
+	}
+
+	from, err := strconv.ParseInt(r.URL.Query().Get("from"), 10, 64)
+	if err != nil {
+		writeError(http.StatusBadRequest, "from must be a unix timestamp in milliseconds")
+		return
+	}
+
+	to := time.Now().UnixMilli()
+	if s := r.URL.Query().Get("to"); s != "" {
+		if to, err = strconv.ParseInt(s, 10, 64); err != nil {
+			writeError(http.StatusBadRequest, "to must be a unix timestamp in milliseconds")
+			return
+		}
+	}
+
+	if to < from {
+		writeError(http.StatusBadRequest, "to must not be before from")
+		return
+	}
+
+	history := admin.Controller.ConfigHistory
+
+	// Make sure the most recent edits are included when diffing against "now".
+	history.Flush()
+
+	describe := func(requested int64, revision *ConfigRevision) map[string]any {
+		m := map[string]any{"requested": requested}
+		if revision != nil {
+			m["revisionId"] = revision.Id
+			m["revisionAt"] = revision.CreatedAt
+		}
+		return m
+	}
+
+	load := func(ts int64) (*ConfigRevision, map[string]any, error) {
+		revision, err := history.At(ts)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil, nil
+		} else if err != nil {
+			return nil, nil, err
+		}
+		return revision, revision.State, nil
+	}
+
+	fromRevision, fromState, err := load(from)
+	if err != nil {
+		writeError(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	toRevision, toState, err := load(to)
+	if err != nil {
+		writeError(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if toRevision == nil {
+		writeError(http.StatusNotFound, "no configuration revision recorded at or before the requested time")
+		return
+	}
+
+	revisions, err := history.Between(from, to)
+	if err != nil {
+		writeError(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	diff := diffConfigStates(fromState, toState)
+
+	// Sort for stable output; the Changes maps are already ordered by encoding/json.
+	for _, section := range []*ConfigSectionDiff{&diff.Systems, &diff.Talkgroups, &diff.Groups, &diff.Tags, &diff.UserGroups, &diff.Users, &diff.Apikeys, &diff.Dirwatch, &diff.Downstreams} {
+		for _, list := range [][]ConfigEntityChange{section.Added, section.Removed, section.Changed} {
+			sort.SliceStable(list, func(i, j int) bool { return list[i].Label < list[j].Label })
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"from":      describe(from, fromRevision),
+		"to":        describe(to, toRevision),
+		"revisions": revisions,
+		"diff":      diff,
+	})
+}
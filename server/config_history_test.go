@@ -0,0 +1,67 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func testConfigState(t *testing.T, s string) map[string]any {
+	var m map[string]any
+	if err := json.Unmarshal([]byte(s), &m); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	return m
+}
+
+func TestDiffConfigStatesTalkgroupsAndScopes(t *testing.T) {
+	from := testConfigState(t, `{
+		"systems": [{"id": 1, "systemRef": 42, "label": "County", "talkgroups": [
+			{"id": 10, "talkgroupRef": 1201, "label": "Fire Dispatch", "delay": 0},
+			{"id": 11, "talkgroupRef": 1202, "label": "Fire Tac"}
+		]}],
+		"userGroups": [{"id": 3, "name": "Public", "systemAccess": "[]", "delay": 0}],
+		"options": {"defaultSystemDelay": 0, "branding": "x"}
+	}`)
+	to := testConfigState(t, `{
+		"systems": [{"id": 1, "systemRef": 42, "label": "County", "talkgroups": [
+			{"id": 10, "talkgroupRef": 1201, "label": "Fire Dispatch", "delay": 30},
+			{"id": 12, "talkgroupRef": 1300, "label": "EMS"}
+		]}],
+		"userGroups": [{"id": 3, "name": "Public", "systemAccess": "[{\"id\":1}]", "delay": 0}],
+		"options": {"defaultSystemDelay": 15, "branding": "x"}
+	}`)
+
+	diff := diffConfigStates(from, to)
+
+	if len(diff.Systems.Changed) != 0 {
+		t.Fatalf("talkgroup edits should not show as system changes: %+v", diff.Systems.Changed)
+	}
+	if len(diff.Talkgroups.Added) != 1 || diff.Talkgroups.Added[0].Key != "42/1300" {
+		t.Fatalf("added talkgroups: %+v", diff.Talkgroups.Added)
+	}
+	if len(diff.Talkgroups.Removed) != 1 || diff.Talkgroups.Removed[0].Key != "42/1202" {
+		t.Fatalf("removed talkgroups: %+v", diff.Talkgroups.Removed)
+	}
+	if len(diff.Talkgroups.Changed) != 1 || diff.Talkgroups.Changed[0].Label != "County / Fire Dispatch" {
+		t.Fatalf("changed talkgroups: %+v", diff.Talkgroups.Changed)
+	}
+	if c, ok := diff.Talkgroups.Changed[0].Changes["delay"]; !ok || c.From != float64(0) || c.To != float64(30) {
+		t.Fatalf("delay change: %+v", diff.Talkgroups.Changed[0].Changes)
+	}
+	if len(diff.UserGroups.Changed) != 1 || len(diff.UserGroups.Changed[0].Changes) != 1 {
+		t.Fatalf("user group scope change: %+v", diff.UserGroups.Changed)
+	}
+	if len(diff.Options) != 1 || diff.Options["defaultSystemDelay"].To != float64(15) {
+		t.Fatalf("options: %+v", diff.Options)
+	}
+}
+
+func TestDiffConfigStatesFromEmpty(t *testing.T) {
+	to := testConfigState(t, `{"tags": [{"id": 1, "label": "Fire"}]}`)
+
+	diff := diffConfigStates(nil, to)
+
+	if len(diff.Tags.Added) != 1 || diff.Tags.Added[0].Label != "Fire" {
+		t.Fatalf("tags: %+v", diff.Tags)
+	}
+}
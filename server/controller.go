@@ -62,7 +62,20 @@ type Controller struct {
 	RegistrationCodes                *RegistrationCodes
 	TransferRequests                 *TransferRequests
 	DeviceTokens                     *DeviceTokens
+	UserWebhooks                     *UserWebhooks
+	Roles                            *Roles
+	Tenants                          *Tenants
+	GuestGrants                      *GuestGrants
+	PlayerEmbeds                     *PlayerEmbeds
+	Sessions                         *Sessions
 	EmailService                     *EmailService
+	EmailAlerts                      *EmailAlerts
+	Recordings                       *Recordings
+	UnitActivity                     *UnitActivity
+	FeedDedup                        *FeedDedup
+	RadioReferenceSync               *RadioReferenceSync
+	Billing                          *Billing
+	Heartbeat                        *Heartbeat
 	ToneDetector                     *ToneDetector
 	TranscriptionQueue               *TranscriptionQueue
 	HydraTranscriptionRetrievalQueue *HydraTranscriptionRetrievalQueue
@@ -71,6 +84,28 @@ type Controller struct {
 	HallucinationDetector            *HallucinationDetector
 	CentralManagement                *CentralManagementService
 	Health                           *HealthService
+	StateSnapshot                    *StateSnapshotter
+	PendingTonesStore                *PendingTonesStore
+	ConfigHistory                    *ConfigHistory
+	Retention                        *Retention
+	CallArchiver                     *CallArchiver
+	AudioStore                       *AudioStore
+	TranscriptionBackfill            *TranscriptionBackfill
+	IncidentRouting                  *IncidentRouter
+	StorageCapacity                  *StorageCapacity
+	CallHLS                          *CallHLS
+	ApikeyUsage                      *ApikeyUsage
+	ListenerAnalytics                *ListenerAnalytics
+	Jobs                             *JobQueue
+	FirstRun                         *FirstRun
+	CallStream                       *CallStream
+	ScanControls                     *ScanControls
+	TTS                              *TTS
+	Incidents                        *Incidents
+	Geocoding                        *Geocoding
+	WeatherAlerts                    *WeatherAlerts
+	Paging                           *PagingDispatcher
+	DirectPush                       *DirectPush
 	// Performance caches
 	PreferencesCache  *PreferencesCache
 	KeywordListsCache *KeywordListsCache
@@ -111,14 +146,22 @@ type Controller struct {
 
 	// Stop channel for the system health monitoring ticker (StartSystemHealthMonitoring)
 	healthMonitorStop chan struct{}
+	// Last unusual-activity alert per talkgroup, owned by MonitorActivityAnomalies
+	activityAnomalyAlertedAt map[uint64]time.Time
+	// Last alert per alert rule id, owned by MonitorAlertRules
+	alertRuleAlertedAt map[uint]time.Time
 
 	// Stop channels for per-system no-audio monitoring goroutines
 	noAudioMonitorStops   map[uint64]chan struct{}
 	noAudioMonitorStopsMu sync.Mutex
 
 	// Rate limiting
-	RateLimiter         *RateLimiter
-	LoginAttemptTracker *LoginAttemptTracker
+	RateLimiter          *RateLimiter
+	LoginAttemptTracker  *LoginAttemptTracker
+	PinAttemptTracker    *LoginAttemptTracker
+	PasswordResetTracker *LoginAttemptTracker
+	IngestAttemptTracker *LoginAttemptTracker
+	IngestRateLimiter    *RateLimiter
 
 	// Auto-updater
 	Updater *Updater
@@ -194,12 +237,49 @@ func NewController(config *Config) *Controller {
 	controller.RegistrationCodes = NewRegistrationCodes()
 	controller.TransferRequests = NewTransferRequests()
 	controller.DeviceTokens = NewDeviceTokens()
+	controller.UserWebhooks = NewUserWebhooks()
+	controller.Tenants = NewTenants()
+	controller.GuestGrants = NewGuestGrants()
+	controller.PlayerEmbeds = NewPlayerEmbeds()
+	controller.Roles = NewRoles()
+	controller.Roles.tenants = controller.Tenants
+	controller.Sessions = NewSessions(controller)
 	controller.EmailService = NewEmailService(controller)
+	controller.EmailAlerts = NewEmailAlerts(controller)
+	controller.Recordings = NewRecordings(controller)
+	controller.UnitActivity = NewUnitActivity(controller)
+	controller.FeedDedup = NewFeedDedup(controller)
+	controller.RadioReferenceSync = NewRadioReferenceSync(controller)
+	controller.Billing = NewBilling(controller)
 	controller.CentralManagement = NewCentralManagementService(controller)
 	controller.Health = NewHealthService(controller)
+	controller.Heartbeat = NewHeartbeat(controller)
 	controller.Delayer = NewDelayer(controller)
 	controller.Downstreams = NewDownstreams(controller)
 	controller.Scheduler = NewScheduler(controller)
+	controller.StateSnapshot = NewStateSnapshotter(controller)
+	controller.PendingTonesStore = NewPendingTonesStore(controller)
+	controller.ConfigHistory = NewConfigHistory(controller)
+	controller.Retention = NewRetention(controller)
+	controller.CallArchiver = NewCallArchiver(controller)
+	controller.AudioStore = NewAudioStore(controller)
+	controller.Systems.audioStore = controller.AudioStore
+	controller.TranscriptionBackfill = NewTranscriptionBackfill(controller)
+	controller.IncidentRouting = NewIncidentRouter(controller)
+	controller.StorageCapacity = NewStorageCapacity(controller)
+	controller.CallHLS = NewCallHLS(controller)
+	controller.ApikeyUsage = NewApikeyUsage(controller)
+	controller.ListenerAnalytics = NewListenerAnalytics(controller)
+	controller.Jobs = NewJobQueue(controller)
+	controller.FirstRun = NewFirstRun(controller)
+	controller.CallStream = NewCallStream(controller)
+	controller.ScanControls = NewScanControls(controller)
+	controller.TTS = NewTTS(controller)
+	controller.Incidents = NewIncidents(controller)
+	controller.Geocoding = NewGeocoding(controller)
+	controller.WeatherAlerts = NewWeatherAlerts(controller)
+	controller.Paging = NewPagingDispatcher(controller)
+	controller.DirectPush = NewDirectPush()
 
 	// Initialize performance caches
 	controller.PreferencesCache = NewPreferencesCache(controller)
@@ -211,6 +291,7 @@ func NewController(config *Config) *Controller {
 
 	controller.Logs.setDaemon(config.daemon)
 	controller.Logs.setDatabase(controller.Database)
+	logging.Configure(config.Logging, controller.Logs)
 	controller.Logs.InstallLogCapture()
 
 	// Initialize debug logger for tones/keywords if enabled in config
@@ -242,8 +323,8 @@ func NewController(config *Config) *Controller {
 	// Initialize rate limiting
 	// General rate limiter: 1000 requests per minute per IP
 	controller.RateLimiter = NewRateLimiter(1000, 1*time.Minute)
-	// Login attempt tracker: 6 failed attempts = 15 minute block
-	controller.LoginAttemptTracker = NewLoginAttemptTracker(6, 15*time.Minute)
+	// Brute-force trackers for login, PIN, password reset and call uploads (see rateLimitConfig)
+	controller.initRateLimits()
 
 	// Initialize auto-updater (always created so admin API works;
 	// background checks only run when auto_update = true in the ini).
@@ -260,6 +341,11 @@ func NewController(config *Config) *Controller {
 }
 
 func (controller *Controller) EmitCall(call *Call) {
+	// Third-party live subscribers apply their own delays from the call timestamp
+	go controller.CallStream.Emit(call)
+
+	go controller.dispatchWebhooks(&webhookEvent{Name: webhookEventCall, Call: call})
+
 	// Forwarded calls (received from another TLR server via downstream) are never
 	// re-forwarded — only emitted to local clients — to prevent circular loops.
 	if call.IsForwarded {
@@ -743,6 +829,14 @@ func (controller *Controller) IngestCall(call *Call) {
 		return
 	}
 
+	switch controller.Options.TalkgroupSchedules.ActionFor(call) {
+	case talkgroupScheduleDrop:
+		logCall(call, LogLevelInfo, "dropped - outside talkgroup schedule")
+		return
+	case talkgroupScheduleMute:
+		call.Muted = true
+	}
+
 	// Determine site by frequency if not already set
 	if call.SiteRef == "" && system != nil && system.Sites != nil && call.Frequency > 0 {
 		if site, ok := system.Sites.GetSiteByFrequency(call.Frequency); ok {
@@ -776,6 +870,13 @@ func (controller *Controller) IngestCall(call *Call) {
 				call.IsDuplicate = true
 			}
 		}
+
+		// Pass 3: logical channel — the same transmission captured on a linked
+		// talkgroup of another system (simulcast + conventional backup).
+		if !call.IsDuplicate && controller.isLogicalChannelDuplicate(call) {
+			logCall(call, LogLevelWarn, "duplicate (logical channel)")
+			call.IsDuplicate = true
+		}
 	}
 
 	// Continue processing after duplicate detection
@@ -797,9 +898,10 @@ func (controller *Controller) processCallAfterDuplicateCheck(call *Call) {
 		logCall(call, "error", err.Error())
 	}
 
-	// Drop duplicates — no DB write, no downstream, no transcription.
+	// Duplicates are not written nor sent downstream; their audio may still
+	// replace the first copy's when better (see FeedDedup).
 	if call.IsDuplicate {
-		logCall(call, LogLevelInfo, fmt.Sprintf("duplicate dropped: %s", call.AudioFilename))
+		go controller.FeedDedup.HandleDuplicate(call)
 		return
 	}
 
@@ -807,11 +909,24 @@ func (controller *Controller) processCallAfterDuplicateCheck(call *Call) {
 		system = call.System
 	}
 
+	// Encrypted calls follow the talkgroup's policy; they are never
+	// transcribed nor checked for tones.
+	encryptedPolicy, encryptedPcm := controller.encryptedCallPolicy(call)
+	if encryptedPolicy != "" && !controller.applyEncryptedPolicy(call, encryptedPolicy, encryptedPcm) {
+		logCall(call, "info", "dropped - encrypted")
+		return
+	}
+
+	// Trim leading and trailing silence before anything reads the audio.
+	if encryptedPolicy == "" {
+		controller.trimSilence(call)
+	}
+
 	// Snapshot RAW audio for tone detection (must run on unprocessed signal before AAC conversion).
 	rawAudio := make([]byte, len(call.Audio))
 	copy(rawAudio, call.Audio)
 	rawAudioMime := call.AudioMime
-	shouldDetectTones := call.Talkgroup != nil && call.Talkgroup.ToneDetectionEnabled && len(call.Talkgroup.ToneSets) > 0
+	shouldDetectTones := encryptedPolicy == "" && call.Talkgroup != nil && call.Talkgroup.ToneDetectionEnabled && len(call.Talkgroup.ToneSets) > 0
 
 	// Stage 2: Snapshot audio for transcription (before AAC conversion).
 	call.OriginalAudio = make([]byte, len(call.Audio))
@@ -820,19 +935,31 @@ func (controller *Controller) processCallAfterDuplicateCheck(call *Call) {
 
 	// Stage 3.5: Optionally enhance transcription audio with denoising and compression.
 	if controller.Options.TranscriptionEnhancement {
-		if enhanced := controller.FFMpeg.ProcessForTranscription(call.OriginalAudio); len(enhanced) > 0 {
+		if enhanced := controller.FFMpeg.ProcessForTranscription(call.OriginalAudio, call.OriginalAudioMime); len(enhanced) > 0 {
 			call.OriginalAudio = enhanced
 			call.OriginalAudioMime = "audio/wav"
 		}
 	}
 
 	// Stage 4: Encode audio to AAC/M4A for storage and streaming.
-	if convertErr := controller.FFMpeg.Convert(call, controller.Systems, controller.Tags, controller.Options.AudioConversion); convertErr != nil {
-		controller.Logs.LogEvent(LogLevelWarn, convertErr.Error())
+	loudnessTarget, _ := controller.Options.LoudnessTargets.ForCall(call)
+	// Encrypted calls stored without audio have nothing to convert.
+	if len(call.Audio) > 0 {
+		if convertErr := controller.FFMpeg.Convert(call, controller.Systems, controller.Tags, controller.Options.AudioConversion, loudnessTarget, controller.audioFilters(call, loudnessTarget)); convertErr != nil {
+			controller.Logs.LogEvent(LogLevelWarn, convertErr.Error())
+		}
 	}
 
 	if id, err := controller.Calls.WriteCall(call, controller.Database); err == nil {
 		call.Id = id
+		controller.FeedDedup.Remember(call, rawAudio, rawAudioMime)
+
+		if controller.Options.LoudnessAnalysisEnabled && system != nil && encryptedPolicy == "" {
+			go controller.recordLoudness(system.Id, call.ApiKeyId, rawAudio)
+		}
+		if controller.Options.FeedQualityEnabled && system != nil && encryptedPolicy == "" {
+			go controller.recordFeedQuality(system.Id, call.ApiKeyId, rawAudio, rawAudioMime)
+		}
 		// After writing, query the database to get the talkgroup ID that was actually written
 		// This ensures we have the correct database ID for logging (like v6 did)
 		// First try to get from cache, fallback to database query if needed
@@ -843,8 +970,8 @@ func (controller *Controller) processCallAfterDuplicateCheck(call *Call) {
 				call.Talkgroup.Id = dbTalkgroupId
 			} else {
 				// Fallback to database query if not in cache
-				query := fmt.Sprintf(`SELECT "talkgroupId" FROM "calls" WHERE "callId" = %d`, call.Id)
-				if err := controller.Database.Sql.QueryRow(query).Scan(&dbTalkgroupId); err == nil && dbTalkgroupId > 0 {
+				query := `SELECT "talkgroupId" FROM "calls" WHERE "callId" = $1`
+				if err := controller.Database.Sql.QueryRow(query, call.Id).Scan(&dbTalkgroupId); err == nil && dbTalkgroupId > 0 {
 					call.Talkgroup.Id = dbTalkgroupId
 				}
 			}
@@ -863,6 +990,17 @@ func (controller *Controller) processCallAfterDuplicateCheck(call *Call) {
 		}
 
 
+		controller.UnitActivity.Record(call)
+
+		// Convert the stored audio to AAC at the storage bitrate in the background
+		controller.queueTranscode(call, rawAudioMime)
+
+		// Muted calls are kept for search and playback only
+		if call.Muted {
+			logCall(call, LogLevelInfo, "stored muted - outside talkgroup schedule")
+			return
+		}
+
 		// IMMEDIATE: Emit call to clients (users can play NOW - zero delay)
 		controller.EmitCall(call)
 
@@ -875,7 +1013,7 @@ func (controller *Controller) processCallAfterDuplicateCheck(call *Call) {
 		}
 
 		// Auto-learn tone sets from raw ingest audio (does not require configured tone sets).
-		if toneAutoLearnEnabled(call) {
+		if encryptedPolicy == "" && toneAutoLearnEnabled(call) {
 			learnCall := *call
 			learnCall.Audio = rawAudio
 			learnCall.AudioMime = rawAudioMime
@@ -883,7 +1021,9 @@ func (controller *Controller) processCallAfterDuplicateCheck(call *Call) {
 		}
 
 		// Queue transcription with tone-aware decision
-		go controller.queueTranscriptionIfNeeded(call)
+		if encryptedPolicy == "" {
+			go controller.queueTranscriptionIfNeeded(call)
+		}
 
 		// Note: Pending tones are checked and attached AFTER transcription completes
 		// This ensures we only attach pending tones to calls that actually have voice (not tone-only)
@@ -900,37 +1040,37 @@ func (controller *Controller) purgeLegacyDuplicates() {
 	const batchSize = 100
 	const pause = 250 * time.Millisecond
 
-	var isPostgres bool
-	if controller.Database != nil && controller.Database.Sql != nil {
-		var version string
-		_ = controller.Database.Sql.QueryRow("SELECT version()").Scan(&version)
-		isPostgres = len(version) > 0 && version[:1] == "P" // "PostgreSQL ..."
-	}
-
 	total := 0
 	for {
-		var (
-			res sql.Result
-			err error
+		rows, err := controller.Database.Sql.Query(
+			`SELECT "callId" FROM "calls" WHERE "isDuplicate" = true ORDER BY "callId" LIMIT $1`, batchSize,
 		)
-		if isPostgres {
-			res, err = controller.Database.Sql.Exec(
-				fmt.Sprintf(`DELETE FROM "calls" WHERE "callId" IN (SELECT "callId" FROM "calls" WHERE "isDuplicate" = true LIMIT %d)`, batchSize),
-			)
-		} else {
-			res, err = controller.Database.Sql.Exec(
-				fmt.Sprintf(`DELETE FROM "calls" WHERE "isDuplicate" = 1 LIMIT %d`, batchSize),
-			)
+		if err != nil {
+			controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("purgeLegacyDuplicates: %v", err))
+			return
 		}
+		ids := []uint64{}
+		for rows.Next() {
+			var id uint64
+			if err = rows.Scan(&id); err != nil {
+				break
+			}
+			ids = append(ids, id)
+		}
+		rows.Close()
 		if err != nil {
 			controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("purgeLegacyDuplicates: %v", err))
 			return
 		}
-		n, _ := res.RowsAffected()
-		if n == 0 {
+		if len(ids) == 0 {
 			break
 		}
-		total += int(n)
+		// DeleteByIDs releases the audio stored outside the calls table
+		if err = controller.Calls.DeleteByIDs(controller.Database, ids); err != nil {
+			controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("purgeLegacyDuplicates: %v", err))
+			return
+		}
+		total += len(ids)
 		time.Sleep(pause)
 	}
 	if total > 0 {
@@ -1092,6 +1232,10 @@ func (controller *Controller) processToneDetection(call *Call) {
 						if math.Abs(tone.Frequency-ts.LongTone.Frequency) <= actualTol {
 							matched = true
 						}
+					} else if tone.ToneType == "Warble" && ts.Warble != nil {
+						if math.Abs(tone.Frequency-ts.Warble.HighFrequency) <= actualTol && math.Abs(tone.LowFrequency-ts.Warble.LowFrequency) <= actualTol {
+							matched = true
+						}
 					}
 
 					if matched {
@@ -1124,9 +1268,12 @@ func (controller *Controller) processToneDetection(call *Call) {
 			toneSequence.MatchedToneSet = matchedToneSets[0] // Keep first for backward compatibility
 			toneSetLabels := make([]string, len(matchedToneSets))
 			for i, ts := range matchedToneSets {
-				toneSetLabels[i] = ts.Label
+				toneSetLabels[i] = fmt.Sprintf("%s (confidence %.2f)", ts.Label, ts.Confidence)
 			}
 			controller.Logs.LogEvent(LogLevelInfo, fmt.Sprintf("tone set(s) matched for call %d: %s", call.Id, strings.Join(toneSetLabels, ", ")))
+			if markToneTest(call, toneSequence) {
+				controller.Logs.LogEvent(LogLevelInfo, fmt.Sprintf("tone set(s) matched for call %d during their test window, tagged as a test without alerts", call.Id))
+			}
 		} else {
 			// Log why no match - show what was configured vs what was detected
 			controller.Logs.LogEvent(LogLevelInfo, fmt.Sprintf("tones detected for call %d but no tone set matched", call.Id))
@@ -1147,6 +1294,9 @@ func (controller *Controller) processToneDetection(call *Call) {
 					if ts.LongTone != nil {
 						expectedFreqs = append(expectedFreqs, fmt.Sprintf("Long:%.1f", ts.LongTone.Frequency))
 					}
+					if ts.Warble != nil {
+						expectedFreqs = append(expectedFreqs, fmt.Sprintf("Warble:%.1f/%.1f", ts.Warble.HighFrequency, ts.Warble.LowFrequency))
+					}
 					if len(expectedFreqs) > 0 {
 						controller.Logs.LogEvent(LogLevelInfo, fmt.Sprintf("  configured tone set '%s': %s (tolerance: %.1f Hz)", ts.Label, strings.Join(expectedFreqs, ", "), ts.Tolerance))
 					}
@@ -1349,8 +1499,9 @@ func (controller *Controller) isToneOnlyCall(call *Call) bool {
 		return false
 	}
 
-	// If transcription is already completed with no transcript, it's tone-only
-	if call.TranscriptionStatus == "completed" && call.Transcript == "" {
+	// If transcription is already completed with no transcript, or skipped
+	// for lack of speech, it's tone-only
+	if (call.TranscriptionStatus == "completed" || call.TranscriptionStatus == "no_speech") && call.Transcript == "" {
 		return true
 	}
 
@@ -1369,6 +1520,9 @@ func (controller *Controller) storePendingTones(call *Call, toneSequence *ToneSe
 	controller.pendingTonesMutex.Lock()
 	defer controller.pendingTonesMutex.Unlock()
 
+	// New tones start a new page, later voice calls no longer join the previous one
+	delete(controller.pendingTones, key+":stitch")
+
 	// Check if pending tones are "locked" (claimed by an ongoing transcription)
 	// If locked, store in "nextPending" slot to be promoted after lock clears
 	existing, exists := controller.pendingTones[key]
@@ -1396,7 +1550,7 @@ func (controller *Controller) storePendingTones(call *Call, toneSequence *ToneSe
 		} else {
 			// Check if existing next pending tones are too old (expired)
 			existingAge := time.Now().UnixMilli() - nextPending.Timestamp
-			maxAge := int64(pendingToneTimeoutMinutes) * 60 * 1000 // Convert minutes to milliseconds
+			maxAge := controller.Options.ToneStitchingConfig.pendingTimeout()
 
 			if existingAge > maxAge {
 				// Existing next pending tones are too old - replace instead of merge
@@ -1491,7 +1645,7 @@ func (controller *Controller) storePendingTones(call *Call, toneSequence *ToneSe
 	} else {
 		// Check if existing pending tones are too old (expired)
 		existingAge := time.Now().UnixMilli() - existing.Timestamp
-		maxAge := int64(pendingToneTimeoutMinutes) * 60 * 1000 // Convert minutes to milliseconds
+		maxAge := controller.Options.ToneStitchingConfig.pendingTimeout()
 
 		if existingAge > maxAge {
 			// Existing pending tones are too old - replace instead of merge
@@ -1613,7 +1767,7 @@ func (controller *Controller) refreshPendingStackAnchor(key string, pending *Pen
 	}
 	pending.CallId = callId
 	pending.Timestamp = anchorTimestamp
-	controller.Logs.LogEvent(LogLevelInfo, fmt.Sprintf("pending tone stack for %s: anchor moved to call %d (orphan timer reset to %ds)", key, callId, orphanedToneAlertSeconds))
+	controller.Logs.LogEvent(LogLevelInfo, fmt.Sprintf("pending tone stack for %s: anchor moved to call %d (orphan timer reset to %s)", key, callId, controller.Options.ToneStitchingConfig.maxWait()))
 	controller.scheduleOrphanedToneCheck(key, callId, anchorTimestamp)
 }
 
@@ -1697,7 +1851,14 @@ func (controller *Controller) mergePendingTones(existing *ToneSequence, new *Ton
 // checkOrphanedTones waits after the stack anchor timestamp and checks if pending tones are still unclaimed.
 // Stale invocations exit when refreshPendingStackAnchor advances pending.Timestamp.
 func (controller *Controller) checkOrphanedTones(key string, callId uint64, timestamp int64) {
-	time.Sleep(time.Duration(orphanedToneAlertSeconds) * time.Second)
+	controller.checkOrphanedTonesAfter(key, callId, timestamp, controller.Options.ToneStitchingConfig.maxWait())
+}
+
+// checkOrphanedTonesAfter is checkOrphanedTones after delay, as for tones restored after a restart.
+func (controller *Controller) checkOrphanedTonesAfter(key string, callId uint64, timestamp int64, delay time.Duration) {
+	if delay > 0 {
+		time.Sleep(delay)
+	}
 
 	controller.pendingTonesMutex.Lock()
 	pending, exists := controller.pendingTones[key]
@@ -1724,10 +1885,10 @@ func (controller *Controller) checkOrphanedTones(key string, callId uint64, time
 
 	// Tones have been sitting for 60 seconds without new tones or voice call
 	// Trigger an alert for the tone-only call
-	controller.Logs.LogEvent(LogLevelInfo, fmt.Sprintf("orphaned tones detected after %d seconds for call %d - triggering alert without voice", orphanedToneAlertSeconds, loadCallId))
+	controller.Logs.LogEvent(LogLevelInfo, fmt.Sprintf("orphaned tones detected after %s for call %d - triggering alert without voice", controller.Options.ToneStitchingConfig.maxWait(), loadCallId))
 
 	if controller.DebugLogger != nil {
-		controller.DebugLogger.LogPendingTones("ORPHANED", loadCallId, 0, "Tones pending for 60+ seconds without voice - triggering alert")
+		controller.DebugLogger.LogPendingTones("ORPHANED", loadCallId, 0, fmt.Sprintf("Tones pending for %s without voice - triggering alert", controller.Options.ToneStitchingConfig.maxWait()))
 	}
 
 	// Load the original call that had the tones (use pending.CallId — updated on stacked-tone merges)
@@ -1751,9 +1912,8 @@ func (controller *Controller) checkOrphanedTones(key string, callId uint64, time
 		call.Transcript = "TONES DETECTED - NO VOICE CALL AVAILABLE"
 
 		// Update the call in the database with this transcript
-		query := fmt.Sprintf(`UPDATE "calls" SET "transcript" = '%s', "transcriptionStatus" = 'completed' WHERE "callId" = %d`,
-			escapeQuotes(call.Transcript), loadCallId)
-		if _, err := controller.Database.Sql.Exec(query); err != nil {
+		query := `UPDATE "calls" SET "transcript" = $1, "transcriptionStatus" = 'completed' WHERE "callId" = $2`
+		if _, err := controller.Database.Sql.Exec(query, call.Transcript, loadCallId); err != nil {
 			controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("failed to update orphaned call transcript: %v", err))
 		}
 	}
@@ -1854,7 +2014,7 @@ func (controller *Controller) checkAndAttachPendingTones(call *Call) bool {
 		if ageSeconds > float64(pending.WindowSeconds) {
 			expired = true
 		}
-	} else if ageMinutes > float64(pendingToneTimeoutMinutes) {
+	} else if now-pending.Timestamp > controller.Options.ToneStitchingConfig.pendingTimeout() {
 		expired = true
 	}
 
@@ -1988,6 +2148,9 @@ func (controller *Controller) checkAndAttachPendingTones(call *Call) bool {
 	}
 	controller.Logs.LogEvent(LogLevelInfo, fmt.Sprintf("attached pending tones from call %d to voice call %d (talkgroup %d, age: %.2f minutes, audio: %d bytes, duration: %.2fs)", pending.CallId, call.Id, call.Talkgroup.Id, ageMinutes, len(call.Audio), audioDuration))
 
+	// Dispatch voice spanning several recordings joins this call's page
+	controller.startToneStitch(key, call)
+
 	// Note: Do NOT trigger alerts here - alerts will be triggered after transcription completes
 	// This function may be called before transcription completes, so we wait to ensure voice exists
 
@@ -2011,6 +2174,9 @@ func (controller *Controller) callHasVoice(call *Call) bool {
 	if call.TranscriptionStatus == "completed" && (call.Transcript == "" || len(call.Transcript) <= 10) {
 		return false
 	}
+	if call.TranscriptionStatus == "no_speech" {
+		return false
+	}
 
 	// Get audio duration
 	audioDuration, err := controller.getCallDuration(call)
@@ -2223,13 +2389,11 @@ func (controller *Controller) updateCallToneSequence(callId uint64, toneSequence
 
 	hasTones := toneSequence != nil && len(toneSequence.Tones) > 0
 
-	query := fmt.Sprintf(`UPDATE "calls" SET "toneSequence" = $1, "hasTones" = %t WHERE "callId" = %d`, hasTones, callId)
-	if controller.Database.Config.DbType == DbTypePostgresql {
-		_, err = controller.Database.Sql.Exec(query, toneSequenceJson)
-	} else {
-		query = fmt.Sprintf(`UPDATE "calls" SET "toneSequence" = ?, "hasTones" = %t WHERE "callId" = %d`, hasTones, callId)
-		_, err = controller.Database.Sql.Exec(query, toneSequenceJson)
+	query := `UPDATE "calls" SET "toneSequence" = $1, "hasTones" = $2 WHERE "callId" = $3`
+	if controller.Database.Config.DbType != DbTypePostgresql {
+		query = `UPDATE "calls" SET "toneSequence" = ?, "hasTones" = ? WHERE "callId" = ?`
 	}
+	_, err = controller.Database.Sql.Exec(query, toneSequenceJson, hasTones, callId)
 
 	if err != nil {
 		controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("failed to update tone sequence for call %d: %v", callId, err))
@@ -2423,6 +2587,11 @@ func (controller *Controller) queueTranscriptionIfNeeded(call *Call) {
 	if call.Talkgroup != nil && !call.Talkgroup.AlertsEnabled {
 		return
 	}
+	// Talkgroups whose transcription tier is off never use provider quota
+	if transcriptionTierOf(call.Talkgroup) == TranscriptionTierOff {
+		controller.Logs.LogEvent(LogLevelInfo, fmt.Sprintf("skipping transcription for call %d: talkgroup transcription is off", call.Id))
+		return
+	}
 
 	// Check if Hydra transcription is enabled and call has transmission_id
 	controller.Options.mutex.Lock()
@@ -2480,7 +2649,7 @@ func (controller *Controller) queueTranscriptionIfNeeded(call *Call) {
 
 	// Check if transcription is needed
 	needsTranscription := false
-	priority := 50
+	priority := transcriptionPriority(call.Talkgroup)
 	reasons := []string{}
 
 	// Check minimum call duration if configured
@@ -2519,8 +2688,8 @@ func (controller *Controller) queueTranscriptionIfNeeded(call *Call) {
 				if remainingDuration < minRemainingDuration {
 					// Tone-only call - don't queue for transcription (avoids locking pending tones)
 					controller.Logs.LogEvent(LogLevelInfo, fmt.Sprintf("skipping transcription for call %d on tone-enabled talkgroup: remaining audio after tone removal (%.1fs) is less than minimum (%.1fs) - tone-only", call.Id, remainingDuration, minRemainingDuration))
-					updateQuery := fmt.Sprintf(`UPDATE "calls" SET "transcriptionStatus" = 'completed' WHERE "callId" = %d`, call.Id)
-					controller.Database.Sql.Exec(updateQuery)
+					updateQuery := `UPDATE "calls" SET "transcriptionStatus" = 'completed' WHERE "callId" = $1`
+					controller.Database.Sql.Exec(updateQuery, call.Id)
 					return
 				}
 
@@ -2564,8 +2733,8 @@ func (controller *Controller) queueTranscriptionIfNeeded(call *Call) {
 				if remainingDuration < minRemainingDuration {
 					controller.Logs.LogEvent(LogLevelInfo, fmt.Sprintf("skipping transcription for call %d: remaining audio after tone removal (%.1fs) is less than minimum (%.1fs) - likely tone-only", call.Id, remainingDuration, minRemainingDuration))
 					// Mark as completed so pending tones don't wait forever
-					updateQuery := fmt.Sprintf(`UPDATE "calls" SET "transcriptionStatus" = 'completed' WHERE "callId" = %d`, call.Id)
-					controller.Database.Sql.Exec(updateQuery)
+					updateQuery := `UPDATE "calls" SET "transcriptionStatus" = 'completed' WHERE "callId" = $1`
+					controller.Database.Sql.Exec(updateQuery, call.Id)
 					return
 				}
 				controller.Logs.LogEvent(LogLevelInfo, fmt.Sprintf("call %d has sufficient remaining audio after tone removal (%.1fs of %.1fs total, %.1fs tones)", call.Id, remainingDuration, audioDuration, toneDuration))
@@ -2612,7 +2781,12 @@ func (controller *Controller) queueTranscriptionJobIfNeeded(call *Call, priority
 			mimeToUse = call.OriginalAudioMime
 		}
 
-		queue.QueueJob(TranscriptionJob{
+		// Static-only calls make transcription models hallucinate
+		if !controller.passesSpeechGate(call, audioToUse, mimeToUse) {
+			return
+		}
+
+		job := TranscriptionJob{
 			CallId:        call.Id,
 			Audio:         call.Audio, // Keep converted audio for backward compatibility
 			AudioMime:     call.AudioMime,
@@ -2622,7 +2796,15 @@ func (controller *Controller) queueTranscriptionJobIfNeeded(call *Call, priority
 			TalkgroupId:   call.Talkgroup.Id,
 			Priority:      priority,
 			Reasons:       reasons,
-		})
+		}
+
+		// Stored as a persistent job so a restart doesn't lose it; the audio
+		// above is handed over in memory
+		payload := transcriptionJobPayload{CallId: call.Id, Reasons: reasons}
+		if _, err := controller.Jobs.Enqueue(jobKindTranscription, priority, payload, job, 0); err != nil {
+			controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("failed to store transcription job for call %d, queueing in memory: %v", call.Id, err))
+			queue.QueueJob(job)
+		}
 	} else {
 		controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("transcription queue became unavailable while processing call %d", call.Id))
 	}
@@ -2736,6 +2918,15 @@ func (controller *Controller) ProcessMessage(client *Client, message *Message) e
 			return err
 		}
 
+	} else if message.Command == MessageCommandScanControl {
+		if err := controller.ScanControls.Apply(client, message.Payload); err != nil {
+			msg := &Message{Command: MessageCommandError, Payload: err.Error()}
+			select {
+			case client.Send <- msg:
+			default:
+			}
+		}
+
 	} else if message.Command == MessageCommandFCMToken {
 		log.Printf("FCM command received from %s, payload type=%T", client.GetRemoteAddr(), message.Payload)
 		if token, ok := message.Payload.(string); ok && token != "" {
@@ -2833,6 +3024,10 @@ func (controller *Controller) ProcessMessageCommandCall(client *Client, message
 		}
 	}
 
+	if client.User != nil && !controller.Billing.TranscriptsAllowed(client.User) {
+		stripTranscript(call)
+	}
+
 	msg := &Message{Command: MessageCommandCall, Payload: call, Flag: message.Flag}
 	select {
 	case client.Send <- msg:
@@ -2863,6 +3058,7 @@ func (controller *Controller) ProcessMessageCommandLivefeedMap(client *Client, m
 	wasAllOff := client.Livefeed.IsAllOff()
 
 	client.Livefeed.FromMap(message.Payload)
+	controller.ListenerAnalytics.Select(client)
 	msg := &Message{Command: MessageCommandLivefeedMap, Payload: !client.Livefeed.IsAllOff()}
 	select {
 	case client.Send <- msg:
@@ -2899,16 +3095,7 @@ func (controller *Controller) sendAvailableCallsToClient(client *Client) {
 	// If user has 5 min delay, and current time is 11:00 PM, get calls from 10:55 PM onwards
 	defaultDelay := controller.Options.DefaultSystemDelay
 	if client.User != nil {
-		// Get user's default delay (group or user level)
-		if client.User.UserGroupId > 0 {
-			group := controller.UserGroups.Get(client.User.UserGroupId)
-			if group != nil && group.Delay > 0 {
-				defaultDelay = uint(group.Delay)
-			}
-		}
-		if defaultDelay == controller.Options.DefaultSystemDelay && client.User.Delay > 0 {
-			defaultDelay = uint(client.User.Delay)
-		}
+		defaultDelay = controller.userScope(client.User).accountDelay(defaultDelay)
 	}
 
 	// Get backlog setting from user preferences (available to all users)
@@ -2986,7 +3173,7 @@ func (controller *Controller) sendAvailableCallsToClient(client *Client) {
 		// For delayed feed catchup: send all calls from the delayed window
 		// The cutoff time was already calculated based on user's delay
 		// So all calls in the query result should be sent (no additional delay checks)
-		if client.Livefeed.IsEnabled(call) {
+		if controller.livefeedAllows(client.Livefeed, call) && controller.ScanControls.Allows(client, call) {
 			msg := &Message{Command: MessageCommandCall, Payload: call}
 			// Use non-blocking send for safety, with small delay to preserve order
 			select {
@@ -3037,7 +3224,21 @@ func (controller *Controller) ProcessMessageCommandPin(client *Client, message *
 		}
 
 		code := string(b)
+		ip := client.GetRemoteAddr()
+		if controller.PinAttemptTracker.IsBlocked(ip) {
+			controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("pin rejected for locked out ip %s", ip))
+			msg := &Message{Command: MessageCommandPin}
+			select {
+			case client.Send <- msg:
+			default:
+			}
+			return nil
+		}
+
 		user := controller.Users.GetUserByPin(code)
+		if user == nil && code != "" {
+			controller.PinAttemptTracker.RecordFailedAttempt(ip)
+		}
 
 		// If user auth is required and no user found, reject
 		if controller.requiresUserAuth() && user == nil {
@@ -3090,19 +3291,25 @@ func (controller *Controller) ProcessMessageCommandPin(client *Client, message *
 			userMutex.Lock()
 			defer userMutex.Unlock()
 
+			// A client switching accounts gives up its previous session
+			controller.Sessions.End(client, SessionEndDisconnected)
+
 			effectiveLimit := controller.userEffectiveConnectionLimit(user)
-			if effectiveLimit > 0 {
-				currentCount := controller.Clients.UserConnectionCount(user)
-				if currentCount >= effectiveLimit {
+			if _, err := controller.Sessions.Start(client, user, effectiveLimit); err != nil {
+				var msg *Message
+				if errors.Is(err, errSessionLimit) {
 					controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("too many concurrent connections for user %s, limit is %d", user.Email, effectiveLimit))
 					// Send the connection limit to the client so it can display a helpful message
-					msg := &Message{Command: MessageCommandMax, Payload: effectiveLimit}
-					select {
-					case client.Send <- msg:
-					default:
-					}
-					return nil
+					msg = &Message{Command: MessageCommandMax, Payload: effectiveLimit}
+				} else {
+					controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("signed out device tried to reconnect for user %s from ip %s", user.Email, client.GetRemoteAddr()))
+					msg = &Message{Command: MessageCommandPin}
+				}
+				select {
+				case client.Send <- msg:
+				default:
 				}
+				return nil
 			}
 
 			// Set user and authenticate (still holding the lock)
@@ -3129,6 +3336,9 @@ func (controller *Controller) ProcessMessageCommandPin(client *Client, message *
 
 		client.SendConfig(controller.Groups, controller.Options, controller.Systems, controller.Tags)
 
+		// Pick up the avoids and hold set on the user's other devices
+		controller.ScanControls.Send(client)
+
 		// Attempt to restore buffered calls from a previous disconnection
 		if user != nil && !pinExpired && controller.ReconnectionMgr != nil {
 			controller.ReconnectionMgr.RestoreClientState(client)
@@ -3139,7 +3349,7 @@ func (controller *Controller) ProcessMessageCommandPin(client *Client, message *
 }
 
 func (controller *Controller) ProcessMessageCommandVersion(client *Client) {
-	p := map[string]string{"version": Version}
+	p := map[string]string{"version": Version, "apiVersion": strconv.Itoa(APIVersion)}
 
 	if len(controller.Options.Branding) > 0 {
 		p["branding"] = controller.Options.Branding
@@ -3174,6 +3384,7 @@ func (controller *Controller) Start() error {
 	startupStart := time.Now()
 
 	// Clear any pending tones and waiting short calls from previous session
+	// (the pending tones saved by the previous run are restored once the options are read)
 	controller.clearPendingState()
 
 	// Reset any calls stuck in "processing" status from previous session
@@ -3181,8 +3392,16 @@ func (controller *Controller) Start() error {
 
 	// Batch database reads for better performance
 	dbReadStart := time.Now()
+	fromSnapshot := false
+	if controller.Config.StateSnapshot {
+		if err := controller.StateSnapshot.Load(); err == nil {
+			fromSnapshot = true
+		} else {
+			log.Printf("startup: state snapshot not used: %v", err)
+		}
+	}
 	log.Printf("startup: loading configuration from database...")
-	if err = controller.readAllData(); err != nil {
+	if err = controller.readAllData(fromSnapshot); err != nil {
 		return err
 	}
 	log.Printf("startup: database load completed in %s", time.Since(dbReadStart).Round(time.Millisecond))
@@ -3247,6 +3466,14 @@ func (controller *Controller) Start() error {
 	// Start system health monitoring for system admins
 	controller.StartSystemHealthMonitoring()
 
+	// Bring back the pages that were waiting for their voice when the server stopped
+	if restored, err := controller.PendingTonesStore.Restore(); err != nil {
+		controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("pending tones: %v", err))
+	} else if restored > 0 {
+		controller.Logs.LogEvent(LogLevelInfo, fmt.Sprintf("restored %d pending tone sequences", restored))
+	}
+	controller.PendingTonesStore.Start()
+
 	// Start reconnection manager cleanup routine
 	if controller.ReconnectionMgr != nil {
 		controller.ReconnectionMgr.StartCleanup()
@@ -3262,6 +3489,72 @@ func (controller *Controller) Start() error {
 	// Start auto-updater (no-op if auto_update = false in ini)
 	controller.Updater.Start()
 
+	// Complete time-shift recordings once their window is over
+	controller.Recordings.Start()
+
+	// Write the unit activity counted at ingest
+	controller.UnitActivity.Start()
+
+	// Report health to an external monitor when configured
+	controller.Heartbeat.Start()
+
+	// Record National Weather Service alerts for the watched areas
+	controller.WeatherAlerts.Start()
+
+	// Fail the paging deliveries a restart interrupted
+	controller.Paging.Start()
+
+	// Run background jobs, resuming those the previous process left behind
+	controller.Jobs.Register(jobKindTranscription, JobHandler{
+		Run:         controller.runTranscriptionJob,
+		Concurrency: transcriptionJobConcurrency,
+		MaxAttempts: 3,
+	})
+	controller.Jobs.Register(jobKindWebhook, JobHandler{
+		Run:         controller.runWebhookJob,
+		Concurrency: webhookJobConcurrency,
+		MaxAttempts: webhookMaxAttempts,
+		RetryDelay:  webhookRetryDelay,
+	})
+	controller.Jobs.Register(jobKindTranscode, JobHandler{
+		Run:         controller.runTranscodeJob,
+		Concurrency: transcodeJobConcurrency,
+		MaxAttempts: 3,
+	})
+	controller.Jobs.Register(jobKindPaging, JobHandler{
+		Run:         controller.runPagingJob,
+		Concurrency: pagingJobConcurrency,
+		MaxAttempts: pagingMaxAttempts,
+		RetryDelay:  pagingRetryDelay,
+	})
+	controller.Jobs.Register(jobKindAlertEscalation, JobHandler{
+		Run:         controller.runAlertEscalationJob,
+		Concurrency: 2,
+		MaxAttempts: 3,
+	})
+	controller.Jobs.Register(jobKindEmailAlert, JobHandler{
+		Run:         controller.runEmailAlertJob,
+		Concurrency: emailAlertJobConcurrency,
+		RetryDelay:  time.Minute,
+	})
+	controller.Jobs.Register(jobKindPush, JobHandler{
+		Run:         controller.runPushJob,
+		Concurrency: pushJobConcurrency,
+		MaxAttempts: pushJobMaxAttempts,
+		RetryDelay:  pushJobRetryDelay,
+	})
+	controller.Jobs.Start()
+
+	// Reschedule escalations the previous process had timed in memory
+	controller.AlertEngine.ResumeEscalations()
+
+	// Resume an online audio storage migration the previous process left running
+	go controller.AudioStore.ResumeMigration()
+
+	// Count uploads per API key and watch for silent or spiking keys
+	controller.ApikeyUsage.Start()
+	controller.ListenerAnalytics.Start()
+
 	// Purge any duplicate rows saved before duplicates were dropped at ingest.
 	// Runs once in the background at startup; deletes in small batches to avoid locking.
 	go controller.purgeLegacyDuplicates()
@@ -3336,10 +3629,14 @@ func (controller *Controller) Start() error {
 			select {
 			case client := <-controller.Register:
 				controller.Clients.Add(client)
+				controller.ListenerAnalytics.Connect(client)
 				emitClientsCount()
 
 			case client := <-controller.Unregister:
+				controller.Sessions.End(client, SessionEndDisconnected)
 				controller.Clients.Remove(client)
+				go controller.ListenerAnalytics.Disconnect(client)
+				controller.ScanControls.Release(client)
 				emitClientsCount()
 
 			case <-ctx.Done():
@@ -3363,6 +3660,24 @@ func (controller *Controller) Start() error {
 		return err
 	}
 
+	// The snapshot is only a warm cache — re-read the covered collections from
+	// the database now that the server is accepting traffic.
+	if controller.Config.StateSnapshot {
+		if fromSnapshot {
+			go controller.StateSnapshot.Reconcile()
+		} else {
+			go func() {
+				if err := controller.StateSnapshot.Write(); err != nil {
+					controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("state snapshot: %v", err))
+				}
+			}()
+		}
+		controller.StateSnapshot.Start()
+	}
+
+	// Record a baseline revision (a no-op when nothing changed since the last run)
+	controller.ConfigHistory.Record()
+
 	readyIn := time.Since(startupStart).Round(time.Millisecond)
 	log.Printf("startup: server ready in %s", readyIn)
 	controller.Logs.LogEvent(LogLevelInfo, fmt.Sprintf("startup: server ready in %s", readyIn))
@@ -3395,8 +3710,10 @@ func (controller *Controller) RestartTranscriptionQueue() {
 	}
 }
 
-// readAllData reads all data from the database in a single function for better organization
-func (controller *Controller) readAllData() error {
+// readAllData reads all data from the database in a single function for better organization.
+// When fromSnapshot is true, systems, groups, tags and users were already installed from the
+// state snapshot and are re-read later by StateSnapshotter.Reconcile.
+func (controller *Controller) readAllData(fromSnapshot bool) error {
 	// Read all data in parallel for better performance
 	var wg sync.WaitGroup
 	errChan := make(chan error, 10)
@@ -3414,21 +3731,31 @@ func (controller *Controller) readAllData() error {
 		}
 	}
 
-	wg.Add(16)
+	wg.Add(15)
 	go readFunc(func() error { return controller.Apikeys.Read(controller.Database) }, "apikeys")
 	go readFunc(func() error { return controller.Dirwatches.Read(controller.Database) }, "dirwatches")
 	go readFunc(func() error { return controller.Downstreams.Read(controller.Database) }, "downstreams")
-	go readFunc(func() error { return controller.Groups.Read(controller.Database) }, "groups")
 	go readFunc(func() error { return controller.Options.Read(controller.Database) }, "options")
-	go readFunc(func() error {
-		return controller.Systems.Read(controller.Database)
-	}, "systems")
-	go readFunc(func() error { return controller.Tags.Read(controller.Database) }, "tags")
-	go readFunc(func() error { return controller.Users.Read(controller.Database) }, "users")
+	if !fromSnapshot {
+		wg.Add(4)
+		go readFunc(func() error { return controller.Groups.Read(controller.Database) }, "groups")
+		go readFunc(func() error {
+			return controller.Systems.Read(controller.Database)
+		}, "systems")
+		go readFunc(func() error { return controller.Tags.Read(controller.Database) }, "tags")
+		go readFunc(func() error { return controller.Users.Read(controller.Database) }, "users")
+	}
 	go readFunc(func() error { return controller.UserGroups.Load(controller.Database) }, "userGroups")
 	go readFunc(func() error { return controller.RegistrationCodes.Load(controller.Database) }, "registrationCodes")
 	go readFunc(func() error { return controller.TransferRequests.Load(controller.Database) }, "transferRequests")
 	go readFunc(func() error { return controller.DeviceTokens.Load(controller.Database) }, "deviceTokens")
+	go readFunc(func() error { return controller.UserWebhooks.Load(controller.Database) }, "userWebhooks")
+	go readFunc(func() error { return controller.Roles.Load(controller.Database) }, "roles")
+	go readFunc(func() error { return controller.Tenants.Load(controller.Database) }, "tenants")
+	go readFunc(func() error { return controller.GuestGrants.Load(controller.Database) }, "guestGrants")
+	go readFunc(func() error { return controller.PlayerEmbeds.Load(controller.Database) }, "playerEmbeds")
+	go readFunc(func() error { return controller.Billing.Load(controller.Database) }, "billing")
+	go readFunc(func() error { return controller.Sessions.Load(controller.Database) }, "sessions")
 
 	// Load performance caches
 	go readFunc(func() error { return controller.PreferencesCache.Read(controller.Database) }, "preferencesCache")
@@ -3447,6 +3774,12 @@ func (controller *Controller) readAllData() error {
 		}
 	}
 
+	// Copy email/transcription settings from the setup wizard into the options
+	controller.applySetupSections()
+
+	// Refuse the default admin password until the administrator account is created
+	controller.FirstRun.Check()
+
 	// Check for duplicate emails and log them
 	controller.checkDuplicateEmails()
 
@@ -3464,57 +3797,28 @@ func (controller *Controller) readAllData() error {
 	return nil
 }
 
-// Helper method to check if user has access to a call (uses group settings if available)
+// userHasAccess reports whether the user may hear the call, see userScope.
 func (controller *Controller) userHasAccess(user *User, call *Call) bool {
 	if user == nil || call == nil || call.System == nil {
 		return true
 	}
 
-	// Check group access first if user has a group
-	if user.UserGroupId > 0 {
-		group := controller.UserGroups.Get(user.UserGroupId)
-		if group != nil {
-			// Check if group has access to the system
-			if !group.HasSystemAccess(uint64(call.System.SystemRef)) {
-				return false
-			}
-
-			// CRITICAL: Also check talkgroup-level access if talkgroup exists
-			if call.Talkgroup != nil {
-				if !group.HasTalkgroupAccess(uint64(call.System.SystemRef), call.Talkgroup.TalkgroupRef) {
-					return false
-				}
-			}
-
-			// Group allows this system and talkgroup
-			// Still check user-level restrictions (user can be more restrictive than group)
-		}
+	// Playback is limited to the archive depth of the user's billing plan
+	if !controller.Billing.ArchiveAllows(user, call.Timestamp) {
+		return false
 	}
 
-	// Check user-level access (can further restrict access beyond group)
-	return user.HasAccess(call)
+	return controller.userScope(user).allowsTalkgroup(call.System, call.Talkgroup)
 }
 
-// Helper method to get effective delay for a user (uses group settings if available)
+// userEffectiveDelay returns the delay in minutes of the call for the user,
+// see userScope, raised to the billing plan's minimum.
 func (controller *Controller) userEffectiveDelay(user *User, call *Call, defaultDelay uint) uint {
 	if user == nil || call == nil || call.System == nil || call.Talkgroup == nil {
 		return defaultDelay
 	}
 
-	// Check group delays first if user has a group
-	if user.UserGroupId > 0 {
-		group := controller.UserGroups.Get(user.UserGroupId)
-		if group != nil {
-			groupDelay := group.EffectiveDelay(call, defaultDelay)
-			// If group has a delay, use it (group settings override user settings)
-			if groupDelay != defaultDelay || group.Delay > 0 || len(group.systemDelaysMap) > 0 || len(group.talkgroupDelaysMap) > 0 {
-				return groupDelay
-			}
-		}
-	}
-
-	// Fall back to user-level delays
-	return user.EffectiveDelay(call, defaultDelay)
+	return controller.Billing.MinDelay(user, controller.userScope(user).delay(call, defaultDelay))
 }
 
 // Helper method to get effective connection limit for a user (uses group settings if available)
@@ -3528,12 +3832,12 @@ func (controller *Controller) userEffectiveConnectionLimit(user *User) uint {
 		group := controller.UserGroups.Get(user.UserGroupId)
 		if group != nil && group.ConnectionLimit > 0 {
 			// Group connection limit overrides user limit
-			return group.ConnectionLimit
+			return controller.Billing.ConnectionLimit(user, group.ConnectionLimit)
 		}
 	}
 
-	// Fall back to user-level connection limit
-	return user.ConnectionLimit
+	// Fall back to user-level connection limit, capped by the billing plan
+	return controller.Billing.ConnectionLimit(user, user.ConnectionLimit)
 }
 
 func (controller *Controller) fetchRadioReferenceAPIKey() {
@@ -3643,6 +3947,9 @@ func (controller *Controller) fetchAudioClientToken() {
 // SyncConfigToFile syncs the current configuration to a file if config sync is enabled
 // This is used for Google Drive sync and other file-based sync solutions
 func (controller *Controller) SyncConfigToFile() {
+	// Every config save passes through here, so this is where revisions are taken
+	controller.ConfigHistory.Record()
+
 	if !controller.Options.ConfigSyncEnabled {
 		return
 	}
@@ -3714,6 +4021,21 @@ func (controller *Controller) Terminate() {
 		controller.Scheduler.Stop()
 	}
 
+	// Persist any pending config revision before the database closes
+	if controller.ConfigHistory != nil {
+		controller.ConfigHistory.Flush()
+	}
+
+	// Flush a final state snapshot so the next boot starts warm
+	if controller.StateSnapshot != nil {
+		controller.StateSnapshot.Stop()
+	}
+
+	// Keep the pages still waiting for their voice for the next run
+	if controller.PendingTonesStore != nil {
+		controller.PendingTonesStore.Stop()
+	}
+
 	// Stop system health monitoring ticker
 	if controller.healthMonitorStop != nil {
 		close(controller.healthMonitorStop)
@@ -3732,6 +4054,29 @@ func (controller *Controller) Terminate() {
 		controller.Updater.Stop()
 	}
 
+	if controller.Recordings != nil {
+		controller.Recordings.Stop()
+	}
+
+	if controller.UnitActivity != nil {
+		controller.UnitActivity.Stop()
+	}
+
+	if controller.Heartbeat != nil {
+		controller.Heartbeat.Stop()
+	}
+
+	if controller.WeatherAlerts != nil {
+		controller.WeatherAlerts.Stop()
+	}
+
+	if controller.ApikeyUsage != nil {
+		controller.ApikeyUsage.Stop()
+	}
+	if controller.ListenerAnalytics != nil {
+		controller.ListenerAnalytics.Stop()
+	}
+
 	controller.Dirwatches.Stop()
 
 	// Stop dedup cache eviction goroutine
@@ -3739,6 +4084,11 @@ func (controller *Controller) Terminate() {
 		controller.DedupCache.Stop()
 	}
 
+	// Stop leasing background jobs; running ones resume after the restart
+	if controller.Jobs != nil {
+		controller.Jobs.Stop()
+	}
+
 	// Stop transcription queue
 	if controller.TranscriptionQueue != nil {
 		log.Println("Stopping transcription queue...")
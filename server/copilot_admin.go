@@ -1186,11 +1186,13 @@ func (admin *Admin) copilotCreateKeywordList(payloadJSON []byte) (map[string]any
 	if v, ok := list["order"].(float64); ok {
 		order = uint(v)
 	}
+	if err := validateKeywordPatterns(keywords); err != nil {
+		return nil, err
+	}
 	keywordsJSON, _ := json.Marshal(keywords)
-	query := fmt.Sprintf(`INSERT INTO "keywordLists" ("label", "description", "keywords", "order", "createdAt") VALUES ('%s', '%s', '%s', %d, %d) RETURNING "keywordListId"`,
-		escapeQuotes(label), escapeQuotes(description), escapeQuotes(string(keywordsJSON)), order, time.Now().UnixMilli())
+	query := `INSERT INTO "keywordLists" ("label", "description", "keywords", "order", "createdAt") VALUES ($1, $2, $3, $4, $5) RETURNING "keywordListId"`
 	var listId uint64
-	if err := admin.Controller.Database.Sql.QueryRow(query).Scan(&listId); err != nil {
+	if err := admin.Controller.Database.Sql.QueryRow(query, label, description, string(keywordsJSON), order, time.Now().UnixMilli()).Scan(&listId); err != nil {
 		return nil, err
 	}
 	_ = admin.Controller.KeywordListsCache.Read(admin.Controller.Database)
@@ -1231,10 +1233,12 @@ func (admin *Admin) copilotUpdateKeywordList(payloadJSON []byte) (map[string]any
 	if v, ok := list["order"].(float64); ok {
 		order = uint(v)
 	}
+	if err := validateKeywordPatterns(keywords); err != nil {
+		return nil, err
+	}
 	keywordsJSON, _ := json.Marshal(keywords)
-	query := fmt.Sprintf(`UPDATE "keywordLists" SET "label" = '%s', "description" = '%s', "keywords" = '%s', "order" = %d WHERE "keywordListId" = %d`,
-		escapeQuotes(label), escapeQuotes(description), escapeQuotes(string(keywordsJSON)), order, req.ID)
-	if _, err := admin.Controller.Database.Sql.Exec(query); err != nil {
+	query := `UPDATE "keywordLists" SET "label" = $1, "description" = $2, "keywords" = $3, "order" = $4 WHERE "keywordListId" = $5`
+	if _, err := admin.Controller.Database.Sql.Exec(query, label, description, string(keywordsJSON), order, req.ID); err != nil {
 		return nil, err
 	}
 	_ = admin.Controller.KeywordListsCache.Read(admin.Controller.Database)
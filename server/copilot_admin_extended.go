@@ -11,8 +11,8 @@ import (
 
 func (admin *Admin) copilotGetTranscriptionFailures() (map[string]any, error) {
 	twentyFourHoursAgo := time.Now().Add(-24 * time.Hour).UnixMilli()
-	query := fmt.Sprintf(`SELECT c."callId", c."systemId", c."talkgroupId", c."timestamp", c."transcriptionFailureReason", s."label", t."label", t."name" FROM "calls" c LEFT JOIN "systems" s ON s."systemId" = c."systemId" LEFT JOIN "talkgroups" t ON t."talkgroupId" = c."talkgroupId" WHERE c."transcriptionStatus" = 'failed' AND c."timestamp" >= %d ORDER BY c."timestamp" DESC LIMIT 100`, twentyFourHoursAgo)
-	rows, err := admin.Controller.Database.Sql.Query(query)
+	query := `SELECT c."callId", c."systemId", c."talkgroupId", c."timestamp", c."transcriptionFailureReason", s."label", t."label", t."name" FROM "calls" c LEFT JOIN "systems" s ON s."systemId" = c."systemId" LEFT JOIN "talkgroups" t ON t."talkgroupId" = c."talkgroupId" WHERE c."transcriptionStatus" = 'failed' AND c."timestamp" >= $1 ORDER BY c."timestamp" DESC LIMIT 100`
+	rows, err := admin.Controller.Database.Sql.Query(query, twentyFourHoursAgo)
 	if err != nil {
 		return nil, err
 	}
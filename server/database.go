@@ -82,6 +82,13 @@ func NewDatabase(config *Config) *Database {
 func (db *Database) migrate() error {
 	formatError := errorFormatter("database", "migrate")
 
+	// Servers sharing the database wait for each other's migrations
+	unlock, err := db.lockSchemaMigrations()
+	if err != nil {
+		return formatError(err, "")
+	}
+	defer unlock()
+
 	// Prepare migration table first (v6 style)
 	if _, err := prepareMigration(db); err != nil {
 		return formatError(err, "")
@@ -254,26 +261,6 @@ func (db *Database) migrate() error {
 		return formatError(err, "")
 	}
 
-	// Remove alert tone columns
-	if err := migrateRemoveAlertTones(db); err != nil {
-		return formatError(err, "")
-	}
-
-	// Remove LED color columns
-	if err := migrateRemoveLedColors(db); err != nil {
-		return formatError(err, "")
-	}
-
-	// Fix invalid user timestamps (empty strings or 0 values)
-	if err := migrateFixUserTimestamps(db); err != nil {
-		return formatError(err, "")
-	}
-
-	// One-time: allow full relay listener-email sync again after upgrade
-	if err := migrateRelayListenerEmailsResync(db); err != nil {
-		return formatError(err, "")
-	}
-
 	// Add name column to downstreams table
 	if err := migrateDownstreamsName(db); err != nil {
 		return formatError(err, "")
@@ -289,11 +276,6 @@ func (db *Database) migrate() error {
 		return formatError(err, "")
 	}
 
-	// Fix orphaned keyword list IDs in user alert preferences
-	if err := migrateFixKeywordListIds(db); err != nil {
-		return formatError(err, "")
-	}
-
 	// Enhanced duplicate detection with site frequencies, preferred sites, and API key preferences
 	if err := migrateEnhancedDuplicateDetection(db); err != nil {
 		return formatError(err, "")
@@ -436,6 +418,155 @@ func (db *Database) migrate() error {
 		return formatError(err, "")
 	}
 
+	if err := migrateConfigRevisions(db); err != nil {
+		return formatError(err, "")
+	}
+
+	if err := migrateRetentionPolicies(db); err != nil {
+		return formatError(err, "")
+	}
+
+	if err := migrateCallAudioLocation(db); err != nil {
+		return formatError(err, "")
+	}
+
+	if err := migrateCallAudioChecksum(db); err != nil {
+		return formatError(err, "")
+	}
+
+	if err := migrateLifeSafetyPhrases(db); err != nil {
+		return formatError(err, "")
+	}
+
+	if err := migrateOutboxEvents(db); err != nil {
+		return formatError(err, "")
+	}
+
+	if err := migrateAlertAcks(db); err != nil {
+		return formatError(err, "")
+	}
+
+	if err := migrateUserWebhooks(db); err != nil {
+		return formatError(err, "")
+	}
+
+	if err := migrateTranscriptTranslation(db); err != nil {
+		return formatError(err, "")
+	}
+
+	if err := migrateLoudnessSamples(db); err != nil {
+		return formatError(err, "")
+	}
+
+	if err := migrateEmailAlerts(db); err != nil {
+		return formatError(err, "")
+	}
+
+	if err := migrateRecordings(db); err != nil {
+		return formatError(err, "")
+	}
+
+	if err := migrateAudioBlobs(db); err != nil {
+		return formatError(err, "")
+	}
+
+	if err := migrateRoles(db); err != nil {
+		return formatError(err, "")
+	}
+
+	if err := migrateSessions(db); err != nil {
+		return formatError(err, "")
+	}
+
+	if err := migrateUnitActivity(db); err != nil {
+		return formatError(err, "")
+	}
+
+	if err := migrateFeedDedup(db); err != nil {
+		return formatError(err, "")
+	}
+
+	if err := migrateSiteControlChannels(db); err != nil {
+		return formatError(err, "")
+	}
+
+	if err := migrateRadioReferenceSync(db); err != nil {
+		return formatError(err, "")
+	}
+
+	if err := migrateTenants(db); err != nil {
+		return formatError(err, "")
+	}
+
+	if err := migrateBilling(db); err != nil {
+		return formatError(err, "")
+	}
+
+	if err := migrateBillingAudit(db); err != nil {
+		return formatError(err, "")
+	}
+
+	if err := migrateCallSummaries(db); err != nil {
+		return formatError(err, "")
+	}
+
+	if err := migrateIncidents(db); err != nil {
+		return formatError(err, "")
+	}
+
+	if err := migrateGeocoding(db); err != nil {
+		return formatError(err, "")
+	}
+
+	if err := migrateSiteLocations(db); err != nil {
+		return formatError(err, "")
+	}
+
+	if err := migrateWeatherAlerts(db); err != nil {
+		return formatError(err, "")
+	}
+
+	if err := migrateCAD(db); err != nil {
+		return formatError(err, "")
+	}
+
+	if err := migrateDispatchForwarding(db); err != nil {
+		return formatError(err, "")
+	}
+
+	if err := migrateQuietHours(db); err != nil {
+		return formatError(err, "")
+	}
+
+	if err := migrateEncryptedCalls(db); err != nil {
+		return formatError(err, "")
+	}
+
+	if err := migrateVADTrim(db); err != nil {
+		return formatError(err, "")
+	}
+
+	if err := migrateTranscriptionTiers(db); err != nil {
+		return formatError(err, "")
+	}
+
+	if err := migrateTranscriptionProvider(db); err != nil {
+		return formatError(err, "")
+	}
+
+	if err := migrateStorageSamples(db); err != nil {
+		return formatError(err, "")
+	}
+
+	if err := migrateJobs(db); err != nil {
+		return formatError(err, "")
+	}
+
+	// Versioned migrations, see schema_migrations.go
+	if err := db.runSchemaMigrations(schemaMigrations); err != nil {
+		return formatError(err, "")
+	}
+
 	return nil
 }
 
@@ -453,7 +584,3 @@ func (db *Database) seed() error {
 
 	return nil
 }
-
-func escapeQuotes(s string) string {
-	return strings.ReplaceAll(s, "'", "''")
-}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"strconv"
 	"strings"
@@ -29,12 +30,13 @@ func (controller *Controller) CallsDebugHandler(w http.ResponseWriter, r *http.R
 	tgFilter := r.URL.Query().Get("tg")
 	dupFilter := r.URL.Query().Get("dup") // "only" or "hide"
 
+	args := queryArgs{}
 	where := []string{}
 	if systemFilter != "" {
-		where = append(where, fmt.Sprintf(`"systemRef" = %s`, systemFilter))
+		where = append(where, `"systemRef" = `+args.add(systemFilter))
 	}
 	if tgFilter != "" {
-		where = append(where, fmt.Sprintf(`"talkgroupRef" = %s`, tgFilter))
+		where = append(where, `"talkgroupRef" = `+args.add(tgFilter))
 	}
 	if dupFilter == "only" {
 		where = append(where, `"isDuplicate" = true`)
@@ -47,9 +49,9 @@ func (controller *Controller) CallsDebugHandler(w http.ResponseWriter, r *http.R
 		whereClause = "WHERE " + strings.Join(where, " AND ")
 	}
 
-	limitClause := fmt.Sprintf("LIMIT %d", limit)
-	if noLimit {
-		limitClause = ""
+	limitClause := ""
+	if !noLimit {
+		limitClause = "LIMIT " + args.add(limit)
 	}
 
 	query := fmt.Sprintf(`
@@ -60,7 +62,7 @@ func (controller *Controller) CallsDebugHandler(w http.ResponseWriter, r *http.R
 		ORDER BY "callId" DESC
 		%s`, whereClause, limitClause)
 
-	rows, err := controller.Database.Sql.QueryContext(ctx, query)
+	rows, err := controller.Database.Sql.QueryContext(ctx, query, args...)
 	if err != nil {
 		http.Error(w, "DB error: "+err.Error(), http.StatusInternalServerError)
 		return
@@ -289,9 +291,9 @@ func (controller *Controller) CallsAudioHandler(w http.ResponseWriter, r *http.R
 	defer cancel()
 
 	var audio []byte
-	var mime string
-	query := fmt.Sprintf(`SELECT "audio", "audioMime" FROM "calls" WHERE "callId" = %d`, id)
-	if err := controller.Database.Sql.QueryRowContext(ctx, query).Scan(&audio, &mime); err != nil {
+	var mime, location string
+	query := `SELECT "audio", "audioMime", "audioLocation" FROM "calls" WHERE "callId" = $1`
+	if err := controller.Database.Sql.QueryRowContext(ctx, query, id).Scan(&audio, &mime, &location); err != nil {
 		http.NotFound(w, r)
 		return
 	}
@@ -300,6 +302,22 @@ func (controller *Controller) CallsAudioHandler(w http.ResponseWriter, r *http.R
 		mime = "audio/mp4"
 	}
 	w.Header().Set("Content-Type", mime)
+
+	// Audio stored outside the calls table is streamed from its location
+	if len(audio) == 0 && location != "" {
+		body, size, err := controller.AudioStore.Open(r.Context(), location)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		defer body.Close()
+		if size > 0 {
+			w.Header().Set("Content-Length", strconv.FormatInt(size, 10))
+		}
+		io.Copy(w, body)
+		return
+	}
+
 	w.Header().Set("Content-Length", strconv.Itoa(len(audio)))
 	w.Header().Set("Accept-Ranges", "bytes")
 	w.Write(audio)
@@ -336,14 +354,14 @@ func (controller *Controller) CallsVerifyHandler(w http.ResponseWriter, r *http.
 		return
 	}
 
-	var val string
+	var val any
 	switch body.Status {
 	case "duplicate":
-		val = "true"
+		val = true
 	case "not_duplicate":
-		val = "false"
+		val = false
 	case "unreviewed":
-		val = "NULL"
+		val = nil
 	default:
 		http.Error(w, "invalid status", http.StatusBadRequest)
 		return
@@ -352,8 +370,8 @@ func (controller *Controller) CallsVerifyHandler(w http.ResponseWriter, r *http.
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	query := fmt.Sprintf(`UPDATE "calls" SET "verifiedDuplicate" = %s WHERE "callId" = %d`, val, body.CallID)
-	if _, err := controller.Database.Sql.ExecContext(ctx, query); err != nil {
+	query := `UPDATE "calls" SET "verifiedDuplicate" = $1 WHERE "callId" = $2`
+	if _, err := controller.Database.Sql.ExecContext(ctx, query, val, body.CallID); err != nil {
 		http.Error(w, "db error: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -27,6 +27,7 @@ type DedupEntry struct {
 	Duration      float64   // Audio duration in seconds (for ratio guard)
 	CallTimestamp int64     // P25 call timestamp in milliseconds
 	SeenAt        time.Time
+	Member        string // "systemRef:talkgroupRef" for logical channel entries
 }
 
 // DedupCache is a mutex-protected in-memory cache that closes the race window
@@ -37,6 +38,7 @@ type DedupEntry struct {
 //   "ep:systemId:talkgroupId" — energy profile entry
 //   "ah:systemId:talkgroupId:hash" — PCM content hash entry
 //   "ts:systemId:talkgroupId" — timestamp fallback entry
+//   "lc:channelId" — last call received on a logical channel
 //
 // A background goroutine evicts stale entries every 30 seconds.
 type DedupCache struct {
@@ -82,6 +84,26 @@ func (dc *DedupCache) CheckAndMarkReceivedAt(systemId, talkgroupId uint64) bool
 	return false
 }
 
+// CheckAndMarkLogicalChannel checks whether the last call on the logical
+// channel came from a different member within window and has a similar
+// duration. Returns true (duplicate) if so. Calls on the same member are left
+// to CheckAndMarkReceivedAt, since back-to-back transmissions on one talkgroup
+// are distinct calls. Non-duplicates become the channel's last call.
+func (dc *DedupCache) CheckAndMarkLogicalChannel(channelId uint, member string, duration float64, window time.Duration) bool {
+	key := fmt.Sprintf("lc:%d", channelId)
+	now := time.Now()
+	dc.mutex.Lock()
+	defer dc.mutex.Unlock()
+
+	if entry, ok := dc.entries[key]; ok && entry.Member != member {
+		if now.Sub(entry.SeenAt) <= window && audioDurationsSimilarForReceivedAtDup(duration, entry.Duration) {
+			return true
+		}
+	}
+	dc.entries[key] = &DedupEntry{Duration: duration, SeenAt: now, Member: member}
+	return false
+}
+
 func (dc *DedupCache) evictionLoop() {
 	ticker := time.NewTicker(30 * time.Second)
 	defer ticker.Stop()
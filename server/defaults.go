@@ -85,6 +85,7 @@ type DefaultOptions struct {
 	emailSmtpSkipVerify         bool
 	emailSmtpFromEmail          string
 	emailSmtpFromName           string
+	emailDigestHour             uint
 	emailLogoFilename           string
 	emailLogoBorderRadius       string
 	faviconFilename             string
@@ -111,6 +112,16 @@ type DefaultOptions struct {
 	transcriptionFailureRepeatMinutes uint
 	toneDetectionRepeatMinutes        uint
 	noAudioRepeatMinutes              uint
+	activityAnomalyAlertsEnabled      bool
+	activityAnomalyNotifyUsers        bool
+	activityAnomalySigma              float64
+	activityAnomalyMinCalls           uint
+	activityAnomalyHistoryDays        uint
+	activityAnomalyRepeatMinutes      uint
+	loudnessAnalysisEnabled           bool
+	feedQualityEnabled                bool
+	outboxEnabled                     bool
+	outboxRetentionDays               uint
 	adminLocalhostOnly          bool
 	configSyncEnabled           bool
 	configSyncPath              string
@@ -201,6 +212,7 @@ var defaults = Defaults{
 		emailSmtpSkipVerify:         false,
 		emailSmtpFromEmail:          "",
 		emailSmtpFromName:           "",
+		emailDigestHour:             emailDigestDefaultHour,
 		emailLogoFilename:           "",
 		emailLogoBorderRadius:       "0px",
 		faviconFilename:             "",
@@ -240,6 +252,16 @@ var defaults = Defaults{
 		transcriptionFailureRepeatMinutes: 60,
 		toneDetectionRepeatMinutes: 60,
 		noAudioRepeatMinutes: 30,
+		activityAnomalyAlertsEnabled: false,
+		activityAnomalyNotifyUsers: false,
+		activityAnomalySigma: 3,
+		activityAnomalyMinCalls: 10,
+		activityAnomalyHistoryDays: 14,
+		activityAnomalyRepeatMinutes: 60,
+		loudnessAnalysisEnabled: false,
+		feedQualityEnabled: false,
+		outboxEnabled: false,
+		outboxRetentionDays: 7,
 		adminLocalhostOnly: false, // Default to false for backwards compatibility
 		configSyncEnabled:  false,
 		configSyncPath:     "",
@@ -134,6 +134,10 @@ func (delayer *Delayer) DelayForClient(call *Call, client *Client) {
 				if client.Send == nil {
 					return
 				}
+				// The talkgroup may have been avoided, or another held, meanwhile
+				if !delayer.controller.ScanControls.Allows(client, call) {
+					return
+				}
 				// Non-blocking send to prevent deadlock
 				msg := &Message{Command: MessageCommandCall, Payload: call}
 				select {
@@ -320,8 +324,8 @@ func (delayer *Delayer) pop(call *Call) error {
 
 	formatError := errorFormatter("delayer", "pop")
 
-	query := fmt.Sprintf(`DELETE FROM "delayed" WHERE "callId" = %d`, call.Id)
-	if _, err := delayer.controller.Database.Sql.Exec(query); err != nil {
+	query := `DELETE FROM "delayed" WHERE "callId" = $1`
+	if _, err := delayer.controller.Database.Sql.Exec(query, call.Id); err != nil {
 		return formatError(err, query)
 	}
 
@@ -334,8 +338,8 @@ func (delayer *Delayer) push(call *Call, timestamp time.Time) error {
 
 	formatError := errorFormatter("delayer", "push")
 
-	query := fmt.Sprintf(`INSERT INTO "delayed" ("callId", "timestamp") VALUES (%d, %d)`, call.Id, timestamp.UnixMilli())
-	if _, err := delayer.controller.Database.Sql.Exec(query); err != nil {
+	query := `INSERT INTO "delayed" ("callId", "timestamp") VALUES ($1, $2)`
+	if _, err := delayer.controller.Database.Sql.Exec(query, call.Id, timestamp.UnixMilli()); err != nil {
 		return formatError(err, query)
 	}
 
@@ -349,9 +353,9 @@ func (delayer *Delayer) IsCallDelayed(callId uint64) bool {
 
 	// Check if the call exists in the delayed table
 	var timestamp int64
-	query := fmt.Sprintf(`SELECT "timestamp" FROM "delayed" WHERE "callId" = %d`, callId)
+	query := `SELECT "timestamp" FROM "delayed" WHERE "callId" = $1`
 
-	if err := delayer.controller.Database.Sql.QueryRow(query).Scan(&timestamp); err != nil {
+	if err := delayer.controller.Database.Sql.QueryRow(query, callId).Scan(&timestamp); err != nil {
 		// If there's an error or no rows, the call is not delayed
 		return false
 	}
@@ -1057,41 +1057,51 @@ func (dirwatches *Dirwatches) Write(db *Database) error {
 	}
 
 	if len(dirwatchIds) > 0 {
-		if b, err := json.Marshal(dirwatchIds); err == nil {
-			in := strings.ReplaceAll(strings.ReplaceAll(string(b), "[", "("), "]", ")")
-			query = fmt.Sprintf(`DELETE FROM "dirwatches" WHERE "dirwatchId" IN %s`, in)
-			if _, err = tx.Exec(query); err != nil {
-				tx.Rollback()
-				return formatError(err, query)
-			}
+		args := queryArgs{}
+		query = `DELETE FROM "dirwatches" WHERE "dirwatchId" IN ` + args.in(dirwatchIds)
+		if _, err = tx.Exec(query, args...); err != nil {
+			tx.Rollback()
+			return formatError(err, query)
 		}
 	}
 
 	for _, dirwatch := range dirwatches.List {
 		var count uint
 
-		query = fmt.Sprintf(`SELECT COUNT(*) FROM "dirwatches" WHERE "dirwatchId" = %d`, dirwatch.Id)
-		if err = tx.QueryRow(query).Scan(&count); err != nil {
+		query = `SELECT COUNT(*) FROM "dirwatches" WHERE "dirwatchId" = $1`
+		if err = tx.QueryRow(query, dirwatch.Id).Scan(&count); err != nil {
 			break
 		}
 
+		columns := queryColumns{}
+		columns.set("delay", dirwatch.Delay)
+		columns.set("deleteAfter", dirwatch.DeleteAfter)
+		columns.set("directory", dirwatch.Directory)
+		columns.set("disabled", dirwatch.Disabled)
+		columns.set("extension", dirwatch.Extension)
+		columns.set("frequency", dirwatch.Frequency)
+		columns.set("mask", dirwatch.Mask)
+		columns.set("order", dirwatch.Order)
+		columns.set("siteId", dirwatch.SiteId)
+		columns.set("systemId", dirwatch.SystemId)
+		columns.set("talkgroupId", dirwatch.TalkgroupId)
+		columns.set("type", dirwatch.Kind)
+
+		var args []any
+
 		if count == 0 {
 			if dirwatch.Id > 0 {
 				// Preserve the explicit ID when inserting
-				query = fmt.Sprintf(`INSERT INTO "dirwatches" ("dirwatchId", "delay", "deleteAfter", "directory", "disabled", "extension", "frequency", "mask", "order", "siteId", "systemId", "talkgroupId", "type") VALUES (%d, %d, %t, '%s', %t, '%s', %d, '%s', %d, %d, %d, %d, '%s')`, dirwatch.Id, dirwatch.Delay, dirwatch.DeleteAfter, dirwatch.Directory, dirwatch.Disabled, dirwatch.Extension, dirwatch.Frequency, dirwatch.Mask, dirwatch.Order, dirwatch.SiteId, dirwatch.SystemId, dirwatch.TalkgroupId, dirwatch.Kind)
-			} else {
-				// Let database assign auto-increment ID
-				query = fmt.Sprintf(`INSERT INTO "dirwatches" ("delay", "deleteAfter", "directory", "disabled", "extension", "frequency", "mask", "order", "siteId", "systemId", "talkgroupId", "type") VALUES (%d, %t, '%s', %t, '%s', %d, '%s', %d, %d, %d, %d, '%s')`, dirwatch.Delay, dirwatch.DeleteAfter, dirwatch.Directory, dirwatch.Disabled, dirwatch.Extension, dirwatch.Frequency, dirwatch.Mask, dirwatch.Order, dirwatch.SiteId, dirwatch.SystemId, dirwatch.TalkgroupId, dirwatch.Kind)
+				columns.set("dirwatchId", dirwatch.Id)
 			}
-			if _, err = tx.Exec(query); err != nil {
-				break
-			}
-
+			// Otherwise let database assign auto-increment ID
+			query, args = columns.insert("dirwatches")
 		} else {
-			query = fmt.Sprintf(`UPDATE "dirwatches" SET "delay" = %d, "deleteAfter" = %t, "directory" = '%s', "disabled" = %t, "extension" = '%s', "frequency" = %d, "mask" = '%s', "order" = %d, "siteId" = %d, "systemId" = %d, "talkgroupId" = %d, "type" = '%s' WHERE "dirwatchId" = %d`, dirwatch.Delay, dirwatch.DeleteAfter, dirwatch.Directory, dirwatch.Disabled, dirwatch.Extension, dirwatch.Frequency, dirwatch.Mask, dirwatch.Order, dirwatch.SiteId, dirwatch.SystemId, dirwatch.TalkgroupId, dirwatch.Kind, dirwatch.Id)
-			if _, err = tx.Exec(query); err != nil {
-				break
-			}
+			query, args = columns.update("dirwatches", "dirwatchId", dirwatch.Id)
+		}
+
+		if _, err = tx.Exec(query, args...); err != nil {
+			break
 		}
 	}
 
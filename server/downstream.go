@@ -616,13 +616,11 @@ func (downstreams *Downstreams) Write(db *Database) error {
 	}
 
 	if len(downstreamIds) > 0 {
-		if b, err := json.Marshal(downstreamIds); err == nil {
-			in := strings.ReplaceAll(strings.ReplaceAll(string(b), "[", "("), "]", ")")
-			query = fmt.Sprintf(`DELETE FROM "downstreams" WHERE "downstreamId" IN %s`, in)
-			if _, err = tx.Exec(query); err != nil {
-				tx.Rollback()
-				return formatError(err, query)
-			}
+		args := queryArgs{}
+		query = `DELETE FROM "downstreams" WHERE "downstreamId" IN ` + args.in(downstreamIds)
+		if _, err = tx.Exec(query, args...); err != nil {
+			tx.Rollback()
+			return formatError(err, query)
 		}
 	}
 
@@ -639,29 +637,35 @@ func (downstreams *Downstreams) Write(db *Database) error {
 		}
 
 		if downstream.Id > 0 {
-			query = fmt.Sprintf(`SELECT COUNT(*) FROM "downstreams" WHERE "downstreamId" = %d`, downstream.Id)
-			if err = tx.QueryRow(query).Scan(&count); err != nil {
+			query = `SELECT COUNT(*) FROM "downstreams" WHERE "downstreamId" = $1`
+			if err = tx.QueryRow(query, downstream.Id).Scan(&count); err != nil {
 				break
 			}
 		}
 
+		columns := queryColumns{}
+		columns.set("apikey", downstream.Apikey)
+		columns.set("disabled", downstream.Disabled)
+		columns.set("name", downstream.Name)
+		columns.set("order", downstream.Order)
+		columns.set("systems", systems)
+		columns.set("url", downstream.Url)
+
+		var args []any
+
 		if count == 0 {
 			if downstream.Id > 0 {
 				// Preserve the explicit ID when inserting
-				query = fmt.Sprintf(`INSERT INTO "downstreams" ("downstreamId", "apikey", "disabled", "name", "order", "systems", "url") VALUES (%d, '%s', %t, '%s', %d, '%s', '%s')`, downstream.Id, escapeQuotes(downstream.Apikey), downstream.Disabled, escapeQuotes(downstream.Name), downstream.Order, systems, escapeQuotes(downstream.Url))
-			} else {
-				// Let database assign auto-increment ID
-				query = fmt.Sprintf(`INSERT INTO "downstreams" ("apikey", "disabled", "name", "order", "systems", "url") VALUES ('%s', %t, '%s', %d, '%s', '%s')`, escapeQuotes(downstream.Apikey), downstream.Disabled, escapeQuotes(downstream.Name), downstream.Order, systems, escapeQuotes(downstream.Url))
-			}
-			if _, err = tx.Exec(query); err != nil {
-				break
+				columns.set("downstreamId", downstream.Id)
 			}
-
+			// Otherwise let database assign auto-increment ID
+			query, args = columns.insert("downstreams")
 		} else {
-			query = fmt.Sprintf(`UPDATE "downstreams" SET "apikey" = '%s', "disabled" = %t, "name" = '%s', "order" = %d, "systems" = '%s', "url" = '%s' WHERE "downstreamId" = %d`, escapeQuotes(downstream.Apikey), downstream.Disabled, escapeQuotes(downstream.Name), downstream.Order, systems, escapeQuotes(downstream.Url), downstream.Id)
-			if _, err = tx.Exec(query); err != nil {
-				break
-			}
+			query, args = columns.update("downstreams", "downstreamId", downstream.Id)
+		}
+
+		if _, err = tx.Exec(query, args...); err != nil {
+			break
 		}
 	}
 
@@ -0,0 +1,469 @@
+// Copyright (C) 2026 Thinline Dynamic Solutions
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>
+
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	emailAudioLinkTTL      = 7 * 24 * time.Hour
+	emailDigestMaxCalls    = 200
+	emailDigestKeepDays    = 7
+	emailAlertDedupWindow  = time.Hour
+	emailDigestDefaultHour = 7
+)
+
+// emailAlertTypes are the alerts sent by email. Pre-alerts and escalations
+// stay on push.
+var emailAlertTypes = map[string]bool{
+	"tone":         true,
+	"keyword":      true,
+	"tone+keyword": true,
+}
+
+// EmailAlerts emails tone and keyword alerts to users who turned email on in
+// their alert preferences. High-priority alerts, the ones the user set to
+// pager-style playback, are emailed right away when emailAlerts is on. Every
+// alert on a talkgroup with emailDigest on is kept for the daily digest.
+type EmailAlerts struct {
+	controller    *Controller
+	mutex         sync.Mutex
+	sent          map[string]time.Time // "userId:callId" of instant emails
+	lastDigestDay string
+}
+
+func NewEmailAlerts(controller *Controller) *EmailAlerts {
+	return &EmailAlerts{
+		controller: controller,
+		sent:       map[string]time.Time{},
+	}
+}
+
+// configError reports why alert emails cannot be sent, or nil.
+func (es *EmailService) configError() error {
+	options := es.Controller.Options
+	if !options.EmailServiceEnabled {
+		return fmt.Errorf("email service is disabled")
+	}
+	switch strings.ToLower(options.EmailProvider) {
+	case "":
+		return fmt.Errorf("email provider not configured")
+	case "sendgrid":
+		if options.EmailSendGridAPIKey == "" {
+			return fmt.Errorf("SendGrid API key not configured")
+		}
+	case "mailgun":
+		if options.EmailMailgunAPIKey == "" || options.EmailMailgunDomain == "" {
+			return fmt.Errorf("Mailgun not properly configured")
+		}
+	case "smtp":
+		if options.EmailSmtpHost == "" {
+			return fmt.Errorf("SMTP host not configured")
+		}
+	}
+	if options.EmailSmtpFromEmail == "" {
+		return fmt.Errorf("from email address not configured")
+	}
+	return nil
+}
+
+// claim returns false when the user was already emailed about the call, for
+// instance by the tone alert before the tone+keyword one.
+func (alerts *EmailAlerts) claim(userId uint64, callId uint64) bool {
+	alerts.mutex.Lock()
+	defer alerts.mutex.Unlock()
+
+	now := time.Now()
+	for key, sentAt := range alerts.sent {
+		if now.Sub(sentAt) > emailAlertDedupWindow {
+			delete(alerts.sent, key)
+		}
+	}
+
+	key := fmt.Sprintf("%d:%d", userId, callId)
+	if _, ok := alerts.sent[key]; ok {
+		return false
+	}
+	alerts.sent[key] = now
+	return true
+}
+
+// Notify is called with every alert pushed to users. title and message are
+// the ones of the push notification.
+func (alerts *EmailAlerts) Notify(userIds []uint64, alertType string, call *Call, title string, message string, toneSetId string) {
+	if !emailAlertTypes[alertType] || call == nil || call.Id == 0 || call.System == nil || call.Talkgroup == nil {
+		return
+	}
+	controller := alerts.controller
+	if controller.EmailService == nil || controller.EmailService.configError() != nil {
+		return
+	}
+
+	for _, userId := range userIds {
+		pref := controller.PreferencesCache.GetPreference(userId, call.System.Id, call.Talkgroup.Id)
+		if pref == nil || (!pref.EmailAlerts && !pref.EmailDigest) {
+			continue
+		}
+		user := controller.Users.GetUserById(userId)
+		if user == nil || user.Email == "" || !user.Verified {
+			continue
+		}
+
+		if pref.EmailDigest {
+			alerts.queueDigest(userId, call, alertType, title, message)
+		}
+
+		if !pref.EmailAlerts || !controller.resolveUserPagerAlert(userId, call.System.Id, call.Talkgroup.Id, toneSetId) || !alerts.claim(userId, call.Id) {
+			continue
+		}
+
+		// Delayed calls are emailed once the delay is over, like the websocket alert
+		var wait time.Duration
+		if delay := controller.userEffectiveDelay(user, call, controller.Options.DefaultSystemDelay); delay > 0 {
+			wait = time.Until(call.Timestamp.Add(time.Duration(delay) * time.Minute))
+		}
+		at := formatLocalTime(call.Timestamp, call.System.TimeZone)
+		alerts.schedule(user, call.Id, at, title, message, wait)
+	}
+}
+
+// jobKindEmailAlert is a persistent job emailing one alert to one user.
+const jobKindEmailAlert = "email_alert"
+
+// emailAlertJobConcurrency alert emails are sent at the same time.
+const emailAlertJobConcurrency = 4
+
+type emailAlertJobPayload struct {
+	UserId  uint64 `json:"userId"`
+	CallId  uint64 `json:"callId"`
+	At      string `json:"at"`
+	Title   string `json:"title"`
+	Message string `json:"message"`
+}
+
+// schedule stores the alert email as a persistent job to send after wait,
+// so delayed emails survive a restart, and falls back to a timer.
+func (alerts *EmailAlerts) schedule(user *User, callId uint64, at string, title string, message string, wait time.Duration) {
+	send := func() {
+		if err := alerts.sendInstant(user, callId, at, title, message); err != nil {
+			alerts.controller.Logs.LogEvent(LogLevelWarn, err.Error())
+		}
+	}
+
+	if jobs := alerts.controller.Jobs; jobs != nil {
+		payload := emailAlertJobPayload{UserId: user.Id, CallId: callId, At: at, Title: title, Message: message}
+		_, err := jobs.Enqueue(jobKindEmailAlert, 0, payload, nil, wait)
+		if err == nil {
+			return
+		}
+		alerts.controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("alert email for call %d to user %d: failed to store, sending from memory: %v", callId, user.Id, err))
+	}
+
+	if wait > 0 {
+		time.AfterFunc(wait, send)
+	} else {
+		go send()
+	}
+}
+
+// runEmailAlertJob sends one alert email. Users deleted or unverified since
+// the alert get nothing.
+func (controller *Controller) runEmailAlertJob(job *Job) error {
+	var payload emailAlertJobPayload
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		return jobPermanent(fmt.Errorf("invalid payload: %v", err))
+	}
+
+	user := controller.Users.GetUserById(payload.UserId)
+	if user == nil || user.Email == "" || !user.Verified {
+		return nil
+	}
+	if controller.EmailService == nil {
+		return jobPermanent(fmt.Errorf("email is not configured"))
+	}
+	if err := controller.EmailService.configError(); err != nil {
+		return jobPermanent(err)
+	}
+
+	return controller.EmailAlerts.sendInstant(user, payload.CallId, payload.At, payload.Title, payload.Message)
+}
+
+// queueDigest keeps the alert for the user's next digest, once per call.
+func (alerts *EmailAlerts) queueDigest(userId uint64, call *Call, alertType string, title string, message string) {
+	query := `INSERT INTO "emailDigestItems" ("userId", "callId", "alertType", "title", "message", "createdAt") VALUES ($1, $2, $3, $4, $5, $6) ON CONFLICT ("userId", "callId") DO NOTHING`
+	if _, err := alerts.controller.Database.Sql.Exec(query, userId, call.Id, alertType, title, message, time.Now().UnixMilli()); err != nil {
+		alerts.controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("failed to queue email digest item for user %d: %v", userId, err))
+	}
+}
+
+// sendInstant emails one alert; at is the call time in the zone of its system.
+func (alerts *EmailAlerts) sendInstant(user *User, callId uint64, at string, title string, message string) error {
+	es := alerts.controller.EmailService
+	branding := alerts.controller.Options.Branding
+	if branding == "" {
+		branding = "ThinLine Radio"
+	}
+	fromName := alerts.controller.Options.EmailSmtpFromName
+	if fromName == "" {
+		fromName = branding
+	}
+
+	link := alerts.audioLink(user.Id, callId)
+	htmlBody := fmt.Sprintf(`<!DOCTYPE html>
+<html><head><meta charset="UTF-8"><meta name="viewport" content="width=device-width,initial-scale=1"></head>
+<body style="font-family:-apple-system,BlinkMacSystemFont,'Segoe UI',Roboto,sans-serif;line-height:1.6;color:#333;max-width:600px;margin:0 auto;padding:24px">
+<h1 style="font-size:20px">%s</h1>
+<p>%s</p>
+<p style="font-size:14px;color:#666">%s</p>
+<p><a href="%s" style="display:inline-block;background:#424242;color:#fff;padding:12px 20px;text-decoration:none;border-radius:6px">Listen to the call</a></p>
+<p style="font-size:14px;color:#666">The link works for 7 days. You receive this email because email alerts are on for this channel in your alert settings.</p>
+</body></html>`, html.EscapeString(title), html.EscapeString(message), html.EscapeString(at), html.EscapeString(link))
+
+	if err := es.sendEmail(fromName, alerts.controller.Options.EmailSmtpFromEmail, user.Email, fmt.Sprintf("[%s] %s", branding, title), htmlBody); err != nil {
+		return fmt.Errorf("alert email for call %d to user %d failed: %v", callId, user.Id, err)
+	}
+	return nil
+}
+
+// RunDaily sends the digests once a day at the digest hour (server time).
+func (alerts *EmailAlerts) RunDaily() {
+	now := time.Now()
+	day := now.Format("2006-01-02")
+
+	alerts.mutex.Lock()
+	if now.Hour() != int(alerts.controller.Options.EmailDigestHour) || alerts.lastDigestDay == day {
+		alerts.mutex.Unlock()
+		return
+	}
+	alerts.lastDigestDay = day
+	alerts.mutex.Unlock()
+
+	if err := alerts.SendDigests(); err != nil {
+		alerts.controller.Logs.LogEvent(LogLevelError, fmt.Sprintf("email digest: %v", err))
+	}
+}
+
+// emailDigestItem is an alert waiting for the digest.
+type emailDigestItem struct {
+	Id        uint64
+	UserId    uint64
+	CallId    uint64
+	AlertType string
+	Title     string
+	Message   string
+	CreatedAt int64
+	TimeZone  string // of the call's system
+}
+
+// SendDigests emails every user with queued alerts. Items are removed once
+// sent; items that could not be sent are retried the next day until they are
+// a week old.
+func (alerts *EmailAlerts) SendDigests() error {
+	controller := alerts.controller
+
+	cutoff := time.Now().Add(-24 * time.Hour * emailDigestKeepDays).UnixMilli()
+	if _, err := controller.Database.Sql.Exec(`DELETE FROM "emailDigestItems" WHERE "createdAt" < $1`, cutoff); err != nil {
+		return err
+	}
+
+	if err := controller.EmailService.configError(); err != nil {
+		return nil
+	}
+
+	rows, err := controller.Database.Sql.Query(`SELECT i."emailDigestItemId", i."userId", i."callId", i."alertType", i."title", i."message", i."createdAt", COALESCE(s."timeZone", '') FROM "emailDigestItems" AS i LEFT JOIN "calls" AS c ON c."callId" = i."callId" LEFT JOIN "systems" AS s ON s."systemId" = c."systemId" ORDER BY i."userId", i."createdAt"`)
+	if err != nil {
+		return err
+	}
+
+	byUser := map[uint64][]emailDigestItem{}
+	var userIds []uint64
+	for rows.Next() {
+		item := emailDigestItem{}
+		if err := rows.Scan(&item.Id, &item.UserId, &item.CallId, &item.AlertType, &item.Title, &item.Message, &item.CreatedAt, &item.TimeZone); err != nil {
+			rows.Close()
+			return err
+		}
+		if byUser[item.UserId] == nil {
+			userIds = append(userIds, item.UserId)
+		}
+		byUser[item.UserId] = append(byUser[item.UserId], item)
+	}
+	rows.Close()
+
+	sent := 0
+	for _, userId := range userIds {
+		items := byUser[userId]
+		user := controller.Users.GetUserById(userId)
+		if user == nil || user.Email == "" {
+			controller.Database.Sql.Exec(`DELETE FROM "emailDigestItems" WHERE "userId" = $1`, userId)
+			continue
+		}
+
+		if err := alerts.sendDigest(user, items); err != nil {
+			controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("email digest to user %d failed: %v", userId, err))
+			continue
+		}
+		sent++
+
+		if _, err := controller.Database.Sql.Exec(`DELETE FROM "emailDigestItems" WHERE "userId" = $1 AND "emailDigestItemId" <= $2`, userId, items[len(items)-1].Id); err != nil {
+			controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("failed to clear email digest of user %d: %v", userId, err))
+		}
+	}
+
+	if sent > 0 {
+		controller.Logs.LogEvent(LogLevelInfo, fmt.Sprintf("email digest sent to %d user(s)", sent))
+	}
+	return nil
+}
+
+func (alerts *EmailAlerts) sendDigest(user *User, items []emailDigestItem) error {
+	branding := alerts.controller.Options.Branding
+	if branding == "" {
+		branding = "ThinLine Radio"
+	}
+	fromName := alerts.controller.Options.EmailSmtpFromName
+	if fromName == "" {
+		fromName = branding
+	}
+
+	subject := fmt.Sprintf("[%s] %d alert(s) since your last digest", branding, len(items))
+	return alerts.controller.EmailService.sendEmail(fromName, alerts.controller.Options.EmailSmtpFromEmail, user.Email, subject, alerts.digestHTML(user, items, branding))
+}
+
+// digestHTML lists the alerts oldest first, at most emailDigestMaxCalls.
+func (alerts *EmailAlerts) digestHTML(user *User, items []emailDigestItem, branding string) string {
+	var b strings.Builder
+	b.WriteString(`<!DOCTYPE html>
+<html><head><meta charset="UTF-8"><meta name="viewport" content="width=device-width,initial-scale=1"></head>
+<body style="font-family:-apple-system,BlinkMacSystemFont,'Segoe UI',Roboto,sans-serif;line-height:1.6;color:#333;max-width:600px;margin:0 auto;padding:24px">
+`)
+	fmt.Fprintf(&b, `<h1 style="font-size:20px">%s alert digest</h1>
+<p>%d alert(s) matched your alert settings.</p>
+<table style="width:100%%;border-collapse:collapse;font-size:14px">
+`, html.EscapeString(branding), len(items))
+
+	shown := items
+	if len(shown) > emailDigestMaxCalls {
+		shown = shown[len(shown)-emailDigestMaxCalls:]
+	}
+	for _, item := range shown {
+		fmt.Fprintf(&b, `<tr style="border-top:1px solid #eee"><td style="padding:8px 8px 8px 0;white-space:nowrap;vertical-align:top">%s</td><td style="padding:8px 0"><strong>%s</strong><br>%s<br><a href="%s">Listen</a></td></tr>
+`,
+			formatLocalTime(time.UnixMilli(item.CreatedAt), item.TimeZone),
+			html.EscapeString(item.Title),
+			html.EscapeString(item.Message),
+			html.EscapeString(alerts.audioLink(user.Id, item.CallId)),
+		)
+	}
+	b.WriteString("</table>\n")
+
+	if len(items) > len(shown) {
+		fmt.Fprintf(&b, "<p style=\"font-size:14px;color:#666\">%d older alert(s) are not listed.</p>\n", len(items)-len(shown))
+	}
+	b.WriteString(`<p style="font-size:14px;color:#666">Links work for 7 days. You receive this digest because the digest is on for these channels in your alert settings.</p>
+</body></html>`)
+
+	return b.String()
+}
+
+// emailAudioSignature signs an audio link for one user and call.
+func emailAudioSignature(secret string, userId uint64, callId uint64, expires int64) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "email-audio.%d.%d.%d", userId, callId, expires)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// audioLink returns a signed link to the call audio that needs no login.
+func (alerts *EmailAlerts) audioLink(userId uint64, callId uint64) string {
+	expires := time.Now().Add(emailAudioLinkTTL).Unix()
+
+	query := url.Values{}
+	query.Set("call", strconv.FormatUint(callId, 10))
+	query.Set("user", strconv.FormatUint(userId, 10))
+	query.Set("expires", strconv.FormatInt(expires, 10))
+	query.Set("sig", emailAudioSignature(alerts.controller.Options.secret, userId, callId, expires))
+
+	return normalizePublicBaseURL(alerts.controller.Options.BaseUrl) + "/api/email/audio?" + query.Encode()
+}
+
+// EmailAudioHandler serves the audio behind links in alert emails.
+//
+// GET /api/email/audio?call=<id>&user=<id>&expires=<unix>&sig=<hmac>
+//
+// The link is checked against the user it was sent to, so access and delay
+// changes made since the email apply.
+func (api *Api) EmailAudioHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	callId, err1 := strconv.ParseUint(query.Get("call"), 10, 64)
+	userId, err2 := strconv.ParseUint(query.Get("user"), 10, 64)
+	expires, err3 := strconv.ParseInt(query.Get("expires"), 10, 64)
+	if err1 != nil || err2 != nil || err3 != nil {
+		api.exitWithError(w, http.StatusBadRequest, "Invalid link")
+		return
+	}
+
+	expected := emailAudioSignature(api.Controller.Options.secret, userId, callId, expires)
+	if !hmac.Equal([]byte(expected), []byte(query.Get("sig"))) {
+		api.exitWithError(w, http.StatusForbidden, "Invalid link")
+		return
+	}
+	if time.Now().Unix() > expires {
+		api.exitWithError(w, http.StatusGone, "This link has expired")
+		return
+	}
+
+	user := api.Controller.Users.GetUserById(userId)
+	if user == nil {
+		api.exitWithError(w, http.StatusForbidden, "Invalid link")
+		return
+	}
+
+	call, err := api.Controller.Calls.GetCall(callId)
+	if err != nil || call == nil || len(call.Audio) == 0 {
+		api.exitWithError(w, http.StatusNotFound, "Call audio not found")
+		return
+	}
+
+	if api.Controller.requiresUserAuth() {
+		if !api.Controller.userHasAccess(user, call) {
+			api.exitWithError(w, http.StatusForbidden, "Access denied")
+			return
+		}
+		if delay := api.Controller.userEffectiveDelay(user, call, api.Controller.Options.DefaultSystemDelay); delay > 0 && time.Now().Before(call.Timestamp.Add(time.Duration(delay)*time.Minute)) {
+			api.exitWithError(w, http.StatusForbidden, "Call is still delayed for your account")
+			return
+		}
+	}
+
+	writeCallAudio(w, r, call)
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEmailAudioSignature(t *testing.T) {
+	sig := emailAudioSignature("secret", 7, 42, 1700000000)
+	if sig != emailAudioSignature("secret", 7, 42, 1700000000) {
+		t.Fatalf("signature is not stable")
+	}
+	for _, other := range []string{
+		emailAudioSignature("other", 7, 42, 1700000000),
+		emailAudioSignature("secret", 8, 42, 1700000000),
+		emailAudioSignature("secret", 7, 43, 1700000000),
+		emailAudioSignature("secret", 7, 42, 1700000001),
+	} {
+		if other == sig {
+			t.Fatalf("signature does not cover every field")
+		}
+	}
+}
+
+func TestEmailAlertsAudioLink(t *testing.T) {
+	controller := &Controller{Options: &Options{}}
+	controller.Options.secret = "secret"
+	controller.Options.BaseUrl = "https://scanner.example.com/"
+	alerts := NewEmailAlerts(controller)
+
+	link, err := url.Parse(alerts.audioLink(7, 42))
+	if err != nil {
+		t.Fatalf("invalid link: %v", err)
+	}
+	if link.Host != "scanner.example.com" || link.Path != "/api/email/audio" {
+		t.Fatalf("unexpected link %s", link)
+	}
+	query := link.Query()
+	if query.Get("call") != "42" || query.Get("user") != "7" {
+		t.Fatalf("unexpected query %v", query)
+	}
+	if query.Get("sig") == "" || query.Get("expires") == "" {
+		t.Fatalf("link is not signed: %v", query)
+	}
+}
+
+func TestEmailAlertsClaim(t *testing.T) {
+	alerts := NewEmailAlerts(&Controller{})
+
+	if !alerts.claim(1, 10) {
+		t.Fatalf("first claim refused")
+	}
+	if alerts.claim(1, 10) {
+		t.Fatalf("same call emailed twice")
+	}
+	if !alerts.claim(2, 10) || !alerts.claim(1, 11) {
+		t.Fatalf("claims of other users or calls refused")
+	}
+
+	alerts.sent["1:10"] = time.Now().Add(-2 * emailAlertDedupWindow)
+	if !alerts.claim(1, 10) {
+		t.Fatalf("expired claim not released")
+	}
+}
+
+func TestEmailAlertsDigestHTML(t *testing.T) {
+	controller := &Controller{Options: &Options{}}
+	controller.Options.secret = "secret"
+	alerts := NewEmailAlerts(controller)
+
+	items := []emailDigestItem{
+		{CallId: 1, Title: "COUNTY / FIRE", Message: "STATION 1 <TONES>", CreatedAt: time.Now().UnixMilli()},
+		{CallId: 2, Title: "COUNTY / EMS", Message: "KEYWORD MATCH: CARDIAC", CreatedAt: time.Now().UnixMilli()},
+	}
+	body := alerts.digestHTML(&User{Id: 3}, items, "Scanner")
+
+	if !strings.Contains(body, "STATION 1 &lt;TONES&gt;") {
+		t.Fatalf("message not escaped")
+	}
+	if strings.Count(body, "/api/email/audio?") != 2 {
+		t.Fatalf("expected one link per alert")
+	}
+	if strings.Contains(body, "older alert") {
+		t.Fatalf("unexpected truncation note")
+	}
+}
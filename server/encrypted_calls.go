@@ -0,0 +1,147 @@
+// Copyright (C) 2026 Thinline Dynamic Solutions
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>
+
+package main
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Policies for encrypted calls, set per talkgroup. Encrypted calls are not
+// transcribed nor checked for tones, whatever the policy.
+const (
+	EncryptedPolicyKeep     = "keep"     // stored as received, only counted
+	EncryptedPolicyDrop     = "drop"     // not stored
+	EncryptedPolicyMetadata = "metadata" // stored without audio
+	EncryptedPolicyMute     = "mute"     // stored with silence of the same length
+)
+
+const (
+	// encryptedMinFrames is the shortest audio checked for noise (1 second).
+	encryptedMinFrames = 20
+	// encryptedSilenceRMS is the level below which audio is silence, not noise.
+	encryptedSilenceRMS = 100
+	// encryptedMinZeroCrossings is the zero crossing rate above which audio is
+	// broadband noise; voice stays well below it.
+	encryptedMinZeroCrossings = 0.3
+	// encryptedMaxVariation is the frame level variation below which audio is
+	// steady; speech has syllables and pauses.
+	encryptedMaxVariation = 0.35
+)
+
+func validEncryptedPolicy(policy string) bool {
+	switch policy {
+	case "", EncryptedPolicyKeep, EncryptedPolicyDrop, EncryptedPolicyMetadata, EncryptedPolicyMute:
+		return true
+	}
+	return false
+}
+
+// looksEncrypted reports whether 8 kHz mono s16le audio is all noise, as
+// encrypted traffic sounds once decoded without the key: a steady level and a
+// zero crossing rate no voice reaches.
+func looksEncrypted(pcm []byte) bool {
+	profile := frameRMS(pcm)
+	if len(profile) < encryptedMinFrames {
+		return false
+	}
+
+	var sum, sumSq float64
+	for _, v := range profile {
+		sum += v
+		sumSq += v * v
+	}
+	mean := sum / float64(len(profile))
+	if mean < encryptedSilenceRMS {
+		return false
+	}
+	variation := math.Sqrt(math.Max(sumSq/float64(len(profile))-mean*mean, 0)) / mean
+
+	samples := len(pcm) / 2
+	crossings := 0
+	previous := int16(binary.LittleEndian.Uint16(pcm[0:2]))
+	for i := 1; i < samples; i++ {
+		sample := int16(binary.LittleEndian.Uint16(pcm[i*2 : i*2+2]))
+		if (sample >= 0) != (previous >= 0) {
+			crossings++
+		}
+		previous = sample
+	}
+	zeroCrossingRate := float64(crossings) / float64(samples)
+
+	return zeroCrossingRate >= encryptedMinZeroCrossings && variation <= encryptedMaxVariation
+}
+
+// encryptedCallPolicy returns the policy to apply to the call, or "" when the
+// call is not encrypted or its talkgroup has no policy. Calls of talkgroups
+// marked encrypted always are; the audio of the others is checked for noise.
+// The decoded audio is returned for muting.
+func (controller *Controller) encryptedCallPolicy(call *Call) (string, []byte) {
+	if call.Talkgroup == nil || call.Talkgroup.EncryptedPolicy == "" || len(call.Audio) == 0 {
+		return "", nil
+	}
+	policy := call.Talkgroup.EncryptedPolicy
+
+	var pcm []byte
+	if !call.Talkgroup.Encrypted || policy == EncryptedPolicyMute {
+		decoded, err := decodeMonoPCM(call.Audio, call.AudioMime)
+		if err != nil {
+			controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("encrypted call check: %v", err))
+			if !call.Talkgroup.Encrypted {
+				return "", nil
+			}
+		}
+		pcm = decoded
+	}
+	if !call.Talkgroup.Encrypted && !looksEncrypted(pcm) {
+		return "", nil
+	}
+	return policy, pcm
+}
+
+// applyEncryptedPolicy handles an encrypted call before it is stored and
+// reports whether it is stored at all.
+func (controller *Controller) applyEncryptedPolicy(call *Call, policy string, pcm []byte) bool {
+	go controller.countEncryptedCall(call.System.Id, call.Talkgroup.Id, call.Timestamp, policy)
+
+	switch policy {
+	case EncryptedPolicyDrop:
+		return false
+	case EncryptedPolicyMetadata:
+		call.Audio = []byte{}
+	case EncryptedPolicyMute:
+		samples := len(pcm) / 2
+		if samples == 0 {
+			samples = int(call.Duration * energySampleHz)
+		}
+		call.Audio = encodePCM16Wav(make([]int16, samples), energySampleHz)
+		call.AudioMime = "audio/wav"
+		call.AudioFilename = strings.TrimSuffix(call.AudioFilename, filepath.Ext(call.AudioFilename)) + ".wav"
+	}
+	return true
+}
+
+// countEncryptedCall adds the call to the hourly counts shown in the stats.
+func (controller *Controller) countEncryptedCall(systemId uint64, talkgroupId uint64, timestamp time.Time, policy string) {
+	query := `INSERT INTO "encryptedCallCounts" ("systemId", "talkgroupId", "hour", "policy", "calls") VALUES ($1, $2, $3, $4, 1) ON CONFLICT ("systemId", "talkgroupId", "hour", "policy") DO UPDATE SET "calls" = "encryptedCallCounts"."calls" + 1`
+	if _, err := controller.Database.Sql.Exec(query, systemId, talkgroupId, timestamp.Unix()/3600, policy); err != nil {
+		controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("encrypted call count: %v", err))
+	}
+}
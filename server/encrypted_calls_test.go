@@ -0,0 +1,78 @@
+package main
+
+import (
+	"encoding/binary"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func pcmFromSamples(samples []int16) []byte {
+	pcm := make([]byte, len(samples)*2)
+	for i, s := range samples {
+		binary.LittleEndian.PutUint16(pcm[i*2:], uint16(s))
+	}
+	return pcm
+}
+
+func TestLooksEncrypted(t *testing.T) {
+	const seconds = 3
+	random := rand.New(rand.NewSource(1))
+
+	noise := make([]int16, energySampleHz*seconds)
+	for i := range noise {
+		noise[i] = int16(random.NormFloat64() * 3000)
+	}
+	if !looksEncrypted(pcmFromSamples(noise)) {
+		t.Fatalf("expected steady noise to look encrypted")
+	}
+
+	// Voice-like: a 300 Hz tone in syllables with pauses
+	voice := make([]int16, energySampleHz*seconds)
+	for i := range voice {
+		envelope := math.Max(math.Sin(2*math.Pi*4*float64(i)/energySampleHz), 0)
+		voice[i] = int16(8000 * envelope * math.Sin(2*math.Pi*300*float64(i)/energySampleHz))
+	}
+	if looksEncrypted(pcmFromSamples(voice)) {
+		t.Fatalf("expected voice not to look encrypted")
+	}
+
+	if looksEncrypted(pcmFromSamples(make([]int16, energySampleHz*seconds))) {
+		t.Fatalf("expected silence not to look encrypted")
+	}
+	if looksEncrypted(pcmFromSamples(noise[:energySampleHz/2])) {
+		t.Fatalf("expected audio under a second not to be checked")
+	}
+}
+
+func TestTalkgroupEncryptedPolicy(t *testing.T) {
+	talkgroup := NewTalkgroup().FromMap(map[string]any{"encrypted": true, "encryptedPolicy": "mute"})
+	if !talkgroup.Encrypted || talkgroup.EncryptedPolicy != EncryptedPolicyMute {
+		t.Fatalf("talkgroup = %+v", talkgroup)
+	}
+	if talkgroup := NewTalkgroup().FromMap(map[string]any{"encryptedPolicy": "shred"}); talkgroup.EncryptedPolicy != "" {
+		t.Fatalf("expected an unknown policy to be ignored, got %q", talkgroup.EncryptedPolicy)
+	}
+}
+
+func TestCallStatsAddEncrypted(t *testing.T) {
+	stats := &CallStats{
+		Systems:    []CallStatsGroup{{SystemRef: 10}},
+		Talkgroups: []CallStatsGroup{{SystemRef: 10, TalkgroupRef: 100}, {SystemRef: 10, TalkgroupRef: 200}},
+	}
+	label := func(systemId, talkgroupId uint64) (CallStatsGroup, bool) {
+		return CallStatsGroup{SystemRef: uint(systemId * 10), TalkgroupRef: uint(talkgroupId * 100)}, true
+	}
+	stats.addEncrypted([]encryptedStatsRow{
+		{SystemId: 1, TalkgroupId: 1, Policy: EncryptedPolicyDrop, Calls: 5},
+		{SystemId: 1, TalkgroupId: 1, Policy: EncryptedPolicyMute, Calls: 2},
+		{SystemId: 1, TalkgroupId: 3, Policy: EncryptedPolicyDrop, Calls: 1},
+	}, label)
+
+	if stats.Encrypted[EncryptedPolicyDrop] != 6 || stats.Encrypted[EncryptedPolicyMute] != 2 {
+		t.Fatalf("encrypted = %v", stats.Encrypted)
+	}
+	if stats.Systems[0].EncryptedCalls != 8 || stats.Talkgroups[0].EncryptedCalls != 7 || stats.Talkgroups[1].EncryptedCalls != 0 {
+		t.Fatalf("systems = %+v, talkgroups = %+v", stats.Systems, stats.Talkgroups)
+	}
+}
@@ -0,0 +1,382 @@
+// Copyright (C) 2026 Thinline Dynamic Solutions
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	feedDedupRetention     = 30 * time.Second       // how long a kept copy can be compared against
+	feedDedupWait          = 10 * time.Second       // how long a duplicate waits for the first copy to be written
+	feedDedupPoll          = 250 * time.Millisecond // how often it looks for it meanwhile
+	feedDedupMinSimilarity = 0.80
+	feedDedupDurationSlack = 0.10 // durations within 10% are compared on SNR
+	feedDedupSnrMarginDb   = 1.0
+)
+
+// feedCopyQuality is what decides which copy of a call is kept.
+type feedCopyQuality struct {
+	Duration float64 // seconds
+	Snr      float64 // dB, loud frames vs the noise floor
+}
+
+// feedCopy is the copy of a call currently stored for a talkgroup.
+type feedCopy struct {
+	CallId     uint64
+	ApiKeyId   uint64
+	ReceivedAt int64
+	Audio      []byte // raw upload, before conversion
+	AudioMime  string
+	Location   string
+	recorded   bool // the copy has a callCopies row
+	mutex      sync.Mutex
+}
+
+// CallCopy is one upload of a call by a feed.
+type CallCopy struct {
+	Id         uint64  `json:"id"`
+	CallId     uint64  `json:"callId"`
+	ApiKeyId   uint64  `json:"apiKeyId"`
+	ReceivedAt int64   `json:"receivedAt"`
+	Duration   float64 `json:"duration"`
+	Snr        float64 `json:"snr"`
+	Similarity float64 `json:"similarity"`
+	Kept       bool    `json:"kept"`
+}
+
+// FeedDedup merges the copies of a call uploaded by several feeds. The first
+// copy is written as usual; later copies flagged as duplicates are compared
+// with it by audio fingerprint, and the better copy's audio is kept.
+type FeedDedup struct {
+	controller *Controller
+	recent     map[string]*feedCopy
+	mutex      sync.Mutex
+}
+
+func NewFeedDedup(controller *Controller) *FeedDedup {
+	return &FeedDedup{
+		controller: controller,
+		recent:     map[string]*feedCopy{},
+	}
+}
+
+func feedDedupKey(call *Call) string {
+	return fmt.Sprintf("%d:%d", call.System.Id, call.Talkgroup.Id)
+}
+
+func callApiKeyId(call *Call) uint64 {
+	if call.ApiKeyId == nil {
+		return 0
+	}
+	return *call.ApiKeyId
+}
+
+// Remember keeps the raw audio of a call just written so copies arriving from
+// other feeds can be compared with it.
+func (dedup *FeedDedup) Remember(call *Call, rawAudio []byte, rawMime string) {
+	if call.System == nil || call.Talkgroup == nil || call.Id == 0 {
+		return
+	}
+
+	now := time.Now().UnixMilli()
+
+	dedup.mutex.Lock()
+	defer dedup.mutex.Unlock()
+
+	for key, entry := range dedup.recent {
+		if now-entry.ReceivedAt > feedDedupRetention.Milliseconds() {
+			delete(dedup.recent, key)
+		}
+	}
+
+	dedup.recent[feedDedupKey(call)] = &feedCopy{
+		CallId:     call.Id,
+		ApiKeyId:   callApiKeyId(call),
+		ReceivedAt: now,
+		Audio:      rawAudio,
+		AudioMime:  rawMime,
+		Location:   call.AudioLocation,
+	}
+}
+
+// waitFor returns the copy stored for the talkgroup of call, waiting for the
+// first copy to be written when both arrived together.
+func (dedup *FeedDedup) waitFor(call *Call) *feedCopy {
+	key := feedDedupKey(call)
+	deadline := time.Now().Add(feedDedupWait)
+	for {
+		dedup.mutex.Lock()
+		entry := dedup.recent[key]
+		dedup.mutex.Unlock()
+
+		if entry != nil && time.Now().UnixMilli()-entry.ReceivedAt <= feedDedupRetention.Milliseconds() {
+			return entry
+		}
+		if time.Now().After(deadline) {
+			return nil
+		}
+		time.Sleep(feedDedupPoll)
+	}
+}
+
+// HandleDuplicate compares a call flagged as duplicate with the copy already
+// stored. Copies whose audio does not match are processed as new calls; the
+// others are recorded, and replace the stored audio when better.
+func (dedup *FeedDedup) HandleDuplicate(call *Call) {
+	controller := dedup.controller
+	logCall := func(level string, msg string) {
+		controller.Logs.LogEvent(level, fmt.Sprintf("[%s] [%s] %s", call.System.Label, call.Talkgroup.Label, msg))
+	}
+
+	if call.System == nil || call.Talkgroup == nil {
+		return
+	}
+
+	entry := dedup.waitFor(call)
+	if entry == nil {
+		logCall(LogLevelInfo, fmt.Sprintf("duplicate dropped: %s", call.AudioFilename))
+		return
+	}
+
+	entry.mutex.Lock()
+	defer entry.mutex.Unlock()
+
+	storedPrint, storedQuality, storedErr := measureFeedCopy(entry.Audio, entry.AudioMime)
+	newPrint, newQuality, newErr := measureFeedCopy(call.Audio, call.AudioMime)
+
+	// Without fingerprints the arrival-time decision stands
+	similarity := 0.0
+	if storedErr == nil && newErr == nil {
+		similarity = EnergyFingerprintSimilarity(storedPrint, newPrint)
+
+		minSimilarity := controller.Options.FeedDedupConfig.MinSimilarity
+		if minSimilarity <= 0 {
+			minSimilarity = feedDedupMinSimilarity
+		}
+		if similarity < minSimilarity {
+			logCall(LogLevelInfo, fmt.Sprintf("not a duplicate (audio similarity %.2f): %s", similarity, call.AudioFilename))
+			call.IsDuplicate = false
+			controller.processCallAfterDuplicateCheck(call)
+			return
+		}
+	}
+
+	if !entry.recorded {
+		if err := dedup.recordCopy(entry.CallId, entry.ApiKeyId, entry.ReceivedAt, storedQuality, 1, true); err != nil {
+			logCall(LogLevelWarn, fmt.Sprintf("feed dedup: %v", err))
+		}
+		entry.recorded = true
+	}
+
+	replace := storedErr == nil && newErr == nil && !controller.Options.FeedDedupConfig.KeepFirst && betterFeedCopy(storedQuality, newQuality)
+
+	if err := dedup.recordCopy(entry.CallId, callApiKeyId(call), time.Now().UnixMilli(), newQuality, similarity, false); err != nil {
+		logCall(LogLevelWarn, fmt.Sprintf("feed dedup: %v", err))
+	}
+
+	if !replace {
+		logCall(LogLevelInfo, fmt.Sprintf("duplicate dropped: %s", call.AudioFilename))
+		return
+	}
+
+	if err := dedup.replaceAudio(entry, call); err != nil {
+		logCall(LogLevelWarn, fmt.Sprintf("feed dedup: keeping first copy of call %d: %v", entry.CallId, err))
+		return
+	}
+
+	logCall(LogLevelInfo, fmt.Sprintf("duplicate replaced call %d audio (%.1fs, %.1f dB SNR vs %.1fs, %.1f dB): %s", entry.CallId, newQuality.Duration, newQuality.Snr, storedQuality.Duration, storedQuality.Snr, call.AudioFilename))
+}
+
+// replaceAudio stores the audio of call in place of the stored copy's.
+func (dedup *FeedDedup) replaceAudio(entry *feedCopy, call *Call) error {
+	controller := dedup.controller
+
+	rawAudio := call.Audio
+	rawMime := call.AudioMime
+
+	loudnessTarget, _ := controller.Options.LoudnessTargets.ForCall(call)
+	if err := controller.FFMpeg.Convert(call, controller.Systems, controller.Tags, controller.Options.AudioConversion, loudnessTarget, controller.audioFilters(call, loudnessTarget)); err != nil {
+		controller.Logs.LogEvent(LogLevelWarn, err.Error())
+	}
+
+	stored := controller.Calls.storeAudio(call)
+	audio := call.Audio
+	if call.AudioLocation != "" {
+		audio = []byte{}
+	}
+
+	tx, err := controller.Database.Sql.Begin()
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(`UPDATE "calls" SET "audio" = $1, "audioFilename" = $2, "audioMime" = $3, "audioDuration" = $4, "audioLocation" = $5, "audioChecksum" = $6 WHERE "callId" = $7`,
+		audio, call.AudioFilename, call.AudioMime, call.Duration, call.AudioLocation, call.AudioChecksum, entry.CallId)
+	if err == nil {
+		_, err = tx.Exec(`UPDATE "callCopies" SET "kept" = ("callCopyId" = (SELECT MAX("callCopyId") FROM "callCopies" WHERE "callId" = $1)) WHERE "callId" = $1`, entry.CallId)
+	}
+	if err == nil {
+		err = tx.Commit()
+	} else {
+		tx.Rollback()
+	}
+	if err != nil {
+		if stored {
+			controller.AudioStore.Release([]string{call.AudioLocation})
+		}
+		return err
+	}
+
+	if entry.Location != "" {
+		controller.AudioStore.Release([]string{entry.Location})
+	}
+
+	entry.ApiKeyId = callApiKeyId(call)
+	entry.Audio = rawAudio
+	entry.AudioMime = rawMime
+	entry.Location = call.AudioLocation
+
+	return nil
+}
+
+func (dedup *FeedDedup) recordCopy(callId uint64, apiKeyId uint64, receivedAt int64, quality feedCopyQuality, similarity float64, kept bool) error {
+	_, err := dedup.controller.Database.Sql.Exec(`INSERT INTO "callCopies" ("callId", "apiKeyId", "receivedAt", "audioDuration", "snr", "similarity", "kept") VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		callId, apiKeyId, receivedAt, quality.Duration, quality.Snr, similarity, kept)
+	return err
+}
+
+// Copies returns the recorded copies of a call, oldest first.
+func (dedup *FeedDedup) Copies(callId uint64) ([]CallCopy, error) {
+	rows, err := dedup.controller.Database.Sql.Query(`SELECT "callCopyId", "callId", "apiKeyId", "receivedAt", "audioDuration", "snr", "similarity", "kept" FROM "callCopies" WHERE "callId" = $1 ORDER BY "callCopyId"`, callId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	copies := []CallCopy{}
+	for rows.Next() {
+		var c CallCopy
+		if err := rows.Scan(&c.Id, &c.CallId, &c.ApiKeyId, &c.ReceivedAt, &c.Duration, &c.Snr, &c.Similarity, &c.Kept); err != nil {
+			return nil, err
+		}
+		copies = append(copies, c)
+	}
+	return copies, rows.Err()
+}
+
+// measureFeedCopy decodes audio once for both its fingerprint and its quality.
+func measureFeedCopy(audio []byte, mime string) ([]float64, feedCopyQuality, error) {
+	pcm, err := decodeMonoPCM(audio, mime)
+	if err != nil {
+		return nil, feedCopyQuality{}, err
+	}
+
+	profile := frameRMS(pcm)
+	quality := feedCopyQuality{
+		Duration: float64(len(pcm)/2) / energySampleHz,
+		Snr:      energyProfileSnr(profile),
+	}
+	if len(profile) < energyMinFrames {
+		return nil, quality, fmt.Errorf("audio too short (%d frames)", len(profile))
+	}
+
+	return normalizeEnergyProfile(profile), quality, nil
+}
+
+// energyProfileSnr estimates the signal to noise ratio as the level of the
+// loud frames (90th percentile) over the noise floor (10th percentile).
+func energyProfileSnr(profile []float64) float64 {
+	if len(profile) == 0 {
+		return 0
+	}
+
+	sorted := append([]float64(nil), profile...)
+	sort.Float64s(sorted)
+
+	signal := sorted[int(0.9*float64(len(sorted)-1))]
+	noise := sorted[int(0.1*float64(len(sorted)-1))]
+	if noise < 1 {
+		noise = 1
+	}
+	if signal <= noise {
+		return 0
+	}
+
+	return 20 * math.Log10(signal/noise)
+}
+
+// betterFeedCopy reports whether candidate should replace stored: a copy
+// clearly longer wins (the other was cut short), otherwise a clearly cleaner
+// one. Ties keep the stored copy.
+func betterFeedCopy(stored feedCopyQuality, candidate feedCopyQuality) bool {
+	longer := stored.Duration
+	if candidate.Duration > longer {
+		longer = candidate.Duration
+	}
+	if longer > 0 && math.Abs(candidate.Duration-stored.Duration) > longer*feedDedupDurationSlack {
+		return candidate.Duration > stored.Duration
+	}
+	return candidate.Snr-stored.Snr > feedDedupSnrMarginDb
+}
+
+// CallCopiesHandler lists the copies of a call uploaded by the different
+// feeds: GET /api/admin/call-copies?callId=N
+func (admin *Admin) CallCopiesHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	writeError := func(status int, message string) {
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(map[string]string{"error": message})
+	}
+
+	t := admin.GetAuthorization(r)
+	if !admin.ValidatePermission(t, PermissionExportCalls) {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	if r.Method != http.MethodGet {
+		writeError(http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	callId, err := strconv.ParseUint(r.URL.Query().Get("callId"), 10, 64)
+	if err != nil || callId == 0 {
+		writeError(http.StatusBadRequest, "callId is required")
+		return
+	}
+	if admin.tokenTenant(t) != nil {
+		if call, err := admin.Controller.Calls.GetCall(callId); err != nil || call.System == nil || !admin.tenantAllowsSystem(t, call.System.Id) {
+			writeError(http.StatusNotFound, "call not found")
+			return
+		}
+	}
+
+	copies, err := admin.Controller.FeedDedup.Copies(callId)
+	if err != nil {
+		writeError(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]any{"copies": copies})
+}
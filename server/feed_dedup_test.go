@@ -0,0 +1,62 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestBetterFeedCopy(t *testing.T) {
+	stored := feedCopyQuality{Duration: 10, Snr: 20}
+
+	cases := []struct {
+		name      string
+		candidate feedCopyQuality
+		want      bool
+	}{
+		{"same", feedCopyQuality{Duration: 10, Snr: 20}, false},
+		{"clearly longer", feedCopyQuality{Duration: 12, Snr: 10}, true},
+		{"clearly shorter", feedCopyQuality{Duration: 8, Snr: 40}, false},
+		{"similar length, cleaner", feedCopyQuality{Duration: 9.5, Snr: 25}, true},
+		{"similar length, barely cleaner", feedCopyQuality{Duration: 10.2, Snr: 20.5}, false},
+	}
+	for _, c := range cases {
+		if got := betterFeedCopy(stored, c.candidate); got != c.want {
+			t.Fatalf("%s: betterFeedCopy = %v, want %v", c.name, got, c.want)
+		}
+	}
+}
+
+func TestEnergyProfileSnr(t *testing.T) {
+	if snr := energyProfileSnr(nil); snr != 0 {
+		t.Fatalf("empty profile snr = %v", snr)
+	}
+
+	profile := make([]float64, 100)
+	for i := range profile {
+		profile[i] = 10
+		if i%2 == 0 {
+			profile[i] = 1000
+		}
+	}
+	if snr := energyProfileSnr(profile); math.Abs(snr-40) > 0.01 {
+		t.Fatalf("snr = %v, want 40", snr)
+	}
+
+	flat := []float64{50, 50, 50, 50}
+	if snr := energyProfileSnr(flat); snr != 0 {
+		t.Fatalf("flat profile snr = %v, want 0", snr)
+	}
+}
+
+func TestFeedDedupConfigFromMap(t *testing.T) {
+	options := &Options{}
+	options.FromMap(map[string]any{"feedDedupConfig": map[string]any{"keepFirst": true, "minSimilarity": 0.9}})
+	if !options.FeedDedupConfig.KeepFirst || options.FeedDedupConfig.MinSimilarity != 0.9 {
+		t.Fatalf("feedDedupConfig = %+v", options.FeedDedupConfig)
+	}
+
+	options.FromMap(map[string]any{"feedDedupConfig": map[string]any{"minSimilarity": 2.0}})
+	if options.FeedDedupConfig.MinSimilarity != 0.9 {
+		t.Fatalf("out of range minSimilarity accepted: %v", options.FeedDedupConfig.MinSimilarity)
+	}
+}
@@ -0,0 +1,425 @@
+// Copyright (C) 2026 Thinline Dynamic Solutions
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>
+
+package main
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"gonum.org/v1/gonum/dsp/fourier"
+)
+
+const (
+	feedQualityClipLevel     = 32440 // -0.09 dBFS, s16
+	feedQualitySilenceDBFS   = -50.0
+	feedQualityMinCalls      = 20
+	feedQualityRetentionDays = 30
+
+	// A source is flagged past these averages
+	feedQualityMaxClipping = 0.1 // percent of samples
+	feedQualityMaxSilence  = 0.5 // ratio of frames
+	feedQualityQuietDBFS   = -35.0
+	feedQualityHotDBFS     = -10.0
+)
+
+// feedQualityBands is the frequency response snapshot, the share of the
+// voice band power in each band.
+var feedQualityBands = [...]struct {
+	Label string
+	Low   float64
+	High  float64
+}{
+	{"100-300", 100, 300},
+	{"300-600", 300, 600},
+	{"600-1200", 600, 1200},
+	{"1200-2400", 1200, 2400},
+	{"2400-3600", 2400, 3600},
+}
+
+const feedQualityBandCount = len(feedQualityBands)
+
+// feedQualityMeasurement is the quality of the audio of one call.
+type feedQualityMeasurement struct {
+	Samples      uint64
+	Clipped      uint64
+	Frames       uint64
+	SilentFrames uint64
+	Active       bool                          // any frame above the silence level
+	Level        float64                       // mean level of the active frames, dBFS
+	Bands        [feedQualityBandCount]float64 // dB relative to the voice band
+}
+
+// measureFeedQuality analyzes 8 kHz mono s16le audio in speechFrameSamples
+// frames. Silent frames count toward the silence ratio only, so the level
+// and the frequency response describe the audio heard.
+func measureFeedQuality(pcm []byte) feedQualityMeasurement {
+	measurement := feedQualityMeasurement{Samples: uint64(len(pcm) / 2)}
+	for i := 0; i+1 < len(pcm); i += 2 {
+		s := int16(binary.LittleEndian.Uint16(pcm[i:]))
+		if s >= feedQualityClipLevel || s <= -feedQualityClipLevel {
+			measurement.Clipped++
+		}
+	}
+
+	frames := len(pcm) / 2 / speechFrameSamples
+	if frames == 0 {
+		return measurement
+	}
+	measurement.Frames = uint64(frames)
+
+	fft := fourier.NewFFT(speechFrameSamples)
+	window := make([]float64, speechFrameSamples)
+	for i := range window {
+		window[i] = 0.5 - 0.5*math.Cos(2*math.Pi*float64(i)/float64(speechFrameSamples-1))
+	}
+	binHz := float64(energySampleHz) / speechFrameSamples
+
+	samples := make([]float64, speechFrameSamples)
+	var coefficients []complex128
+	var power float64
+	var bands [feedQualityBandCount]float64
+	active := 0
+	for f := 0; f < frames; f++ {
+		offset := f * speechFrameSamples * 2
+		var sumSq float64
+		for i := range samples {
+			sample := float64(int16(binary.LittleEndian.Uint16(pcm[offset+i*2:]))) / 32768
+			sumSq += sample * sample
+			samples[i] = sample * window[i]
+		}
+		meanSq := sumSq / speechFrameSamples
+		if meanSq == 0 || 10*math.Log10(meanSq) < feedQualitySilenceDBFS {
+			measurement.SilentFrames++
+			continue
+		}
+		active++
+		power += meanSq
+
+		coefficients = fft.Coefficients(coefficients, samples)
+		for b, band := range feedQualityBands {
+			for k := int(math.Ceil(band.Low / binHz)); float64(k)*binHz < band.High && k < len(coefficients); k++ {
+				bands[b] += real(coefficients[k])*real(coefficients[k]) + imag(coefficients[k])*imag(coefficients[k])
+			}
+		}
+	}
+	if active == 0 {
+		return measurement
+	}
+
+	measurement.Active = true
+	measurement.Level = 10 * math.Log10(power/float64(active))
+	var total float64
+	for _, v := range bands {
+		total += v
+	}
+	for b, v := range bands {
+		// Floor empty bands at -60 dB so one call cannot sink the average
+		measurement.Bands[b] = math.Max(10*math.Log10((v+1e-12)/(total+1e-12)), -60)
+	}
+	return measurement
+}
+
+// recordFeedQuality measures the raw audio of a call and adds it to the
+// day of its source.
+func (controller *Controller) recordFeedQuality(systemId uint64, apiKeyId *uint64, audio []byte, mime string) {
+	pcm, err := decodeMonoPCM(audio, mime)
+	if err != nil {
+		return
+	}
+	measurement := measureFeedQuality(pcm)
+	if measurement.Frames == 0 {
+		return
+	}
+
+	var keyId uint64
+	if apiKeyId != nil {
+		keyId = *apiKeyId
+	}
+
+	var activeCalls uint64
+	if measurement.Active {
+		activeCalls = 1
+	}
+
+	if _, err := controller.Database.Sql.Exec(`INSERT INTO "feedQualityDays" ("apiKeyId", "systemId", "day", "calls", "activeCalls", "levelSum", "samples", "clipped", "frames", "silentFrames", "band100Sum", "band300Sum", "band600Sum", "band1200Sum", "band2400Sum") VALUES ($1, $2, $3, 1, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14) ON CONFLICT ("apiKeyId", "systemId", "day") DO UPDATE SET "calls" = "feedQualityDays"."calls" + 1, "activeCalls" = "feedQualityDays"."activeCalls" + EXCLUDED."activeCalls", "levelSum" = "feedQualityDays"."levelSum" + EXCLUDED."levelSum", "samples" = "feedQualityDays"."samples" + EXCLUDED."samples", "clipped" = "feedQualityDays"."clipped" + EXCLUDED."clipped", "frames" = "feedQualityDays"."frames" + EXCLUDED."frames", "silentFrames" = "feedQualityDays"."silentFrames" + EXCLUDED."silentFrames", "band100Sum" = "feedQualityDays"."band100Sum" + EXCLUDED."band100Sum", "band300Sum" = "feedQualityDays"."band300Sum" + EXCLUDED."band300Sum", "band600Sum" = "feedQualityDays"."band600Sum" + EXCLUDED."band600Sum", "band1200Sum" = "feedQualityDays"."band1200Sum" + EXCLUDED."band1200Sum", "band2400Sum" = "feedQualityDays"."band2400Sum" + EXCLUDED."band2400Sum"`,
+		keyId, systemId, time.Now().Unix()/(24*60*60), activeCalls, measurement.Level, measurement.Samples, measurement.Clipped, measurement.Frames, measurement.SilentFrames,
+		measurement.Bands[0], measurement.Bands[1], measurement.Bands[2], measurement.Bands[3], measurement.Bands[4]); err != nil {
+		controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("failed to store feed quality: %v", err))
+	}
+}
+
+// PruneFeedQuality drops the days older than the report can look back.
+func (controller *Controller) PruneFeedQuality() error {
+	cutoff := time.Now().Unix()/(24*60*60) - feedQualityRetentionDays
+
+	_, err := controller.Database.Sql.Exec(`DELETE FROM "feedQualityDays" WHERE "day" < $1`, cutoff)
+	return err
+}
+
+// feedQualityDayRow is the daily aggregate of one source.
+type feedQualityDayRow struct {
+	ApiKeyId     uint64
+	SystemId     uint64
+	Day          int64 // days since the epoch
+	Calls        uint64
+	ActiveCalls  uint64
+	LevelSum     float64
+	Samples      uint64
+	Clipped      uint64
+	Frames       uint64
+	SilentFrames uint64
+	BandSums     [feedQualityBandCount]float64
+}
+
+type FeedQualityDay struct {
+	Date            string  `json:"date"`
+	Calls           uint64  `json:"calls"`
+	Level           float64 `json:"level"`
+	ClippingPercent float64 `json:"clippingPercent"`
+	SilenceRatio    float64 `json:"silenceRatio"`
+}
+
+// FeedQualityBand is the level of one band relative to the loudest band.
+type FeedQualityBand struct {
+	Band  string  `json:"band"`
+	Level float64 `json:"level"`
+}
+
+// FeedQualitySourceReport is the audio quality of one upload source, an API
+// key uploading to a system.
+type FeedQualitySourceReport struct {
+	ApiKeyId          uint64            `json:"apiKeyId"`
+	ApiKeyIdent       string            `json:"apiKeyIdent,omitempty"`
+	SystemId          uint64            `json:"systemId"`
+	SystemRef         uint              `json:"systemRef"`
+	SystemLabel       string            `json:"systemLabel"`
+	Calls             uint64            `json:"calls"`
+	Level             float64           `json:"level"`
+	ClippingPercent   float64           `json:"clippingPercent"`
+	SilenceRatio      float64           `json:"silenceRatio"`
+	FrequencyResponse []FeedQualityBand `json:"frequencyResponse"`
+	Status            string            `json:"status"` // insufficient, ok, clipping, silence, quiet, hot
+	Days              []FeedQualityDay  `json:"days"`
+
+	totals feedQualityDayRow
+}
+
+// feedQualityStatusOrder lists the problems first, the worst first.
+var feedQualityStatusOrder = map[string]int{"clipping": 0, "silence": 1, "quiet": 2, "hot": 3, "ok": 4, "insufficient": 5}
+
+// feedQualityStatus returns the most serious problem of a source.
+func feedQualityStatus(totals feedQualityDayRow) string {
+	if totals.Calls < feedQualityMinCalls {
+		return "insufficient"
+	}
+	if feedQualityRatio(totals.Clipped, totals.Samples)*100 > feedQualityMaxClipping {
+		return "clipping"
+	}
+	if feedQualityRatio(totals.SilentFrames, totals.Frames) > feedQualityMaxSilence {
+		return "silence"
+	}
+	if totals.ActiveCalls == 0 || totals.LevelSum/float64(totals.ActiveCalls) < feedQualityQuietDBFS {
+		return "quiet"
+	}
+	if totals.LevelSum/float64(totals.ActiveCalls) > feedQualityHotDBFS {
+		return "hot"
+	}
+	return "ok"
+}
+
+func feedQualityRatio(part uint64, whole uint64) float64 {
+	if whole == 0 {
+		return 0
+	}
+	return float64(part) / float64(whole)
+}
+
+// feedQualityLevel is the mean level of the active calls, or the silence
+// level when none had audio above it.
+func feedQualityLevel(row feedQualityDayRow) float64 {
+	if row.ActiveCalls == 0 {
+		return feedQualitySilenceDBFS
+	}
+	return math.Round(row.LevelSum/float64(row.ActiveCalls)*10) / 10
+}
+
+// buildFeedQualityReport folds the daily rows into one report per source,
+// the sources with problems first.
+func (controller *Controller) buildFeedQualityReport(rows []feedQualityDayRow) []*FeedQualitySourceReport {
+	type sourceKey struct {
+		apiKeyId uint64
+		systemId uint64
+	}
+
+	sources := map[sourceKey]*FeedQualitySourceReport{}
+	var reports []*FeedQualitySourceReport
+
+	for _, row := range rows {
+		key := sourceKey{row.ApiKeyId, row.SystemId}
+		report := sources[key]
+		if report == nil {
+			report = &FeedQualitySourceReport{ApiKeyId: row.ApiKeyId, SystemId: row.SystemId, Days: []FeedQualityDay{}}
+			report.ApiKeyIdent, report.SystemRef, report.SystemLabel = controller.uploadSourceLabels(row.ApiKeyId, row.SystemId)
+			report.totals = feedQualityDayRow{ApiKeyId: row.ApiKeyId, SystemId: row.SystemId}
+			sources[key] = report
+			reports = append(reports, report)
+		}
+
+		totals := &report.totals
+		totals.Calls += row.Calls
+		totals.ActiveCalls += row.ActiveCalls
+		totals.LevelSum += row.LevelSum
+		totals.Samples += row.Samples
+		totals.Clipped += row.Clipped
+		totals.Frames += row.Frames
+		totals.SilentFrames += row.SilentFrames
+		for b := range totals.BandSums {
+			totals.BandSums[b] += row.BandSums[b]
+		}
+
+		report.Days = append(report.Days, FeedQualityDay{
+			Date:            time.Unix(row.Day*24*60*60, 0).UTC().Format("2006-01-02"),
+			Calls:           row.Calls,
+			Level:           feedQualityLevel(row),
+			ClippingPercent: math.Round(feedQualityRatio(row.Clipped, row.Samples)*100*1000) / 1000,
+			SilenceRatio:    math.Round(feedQualityRatio(row.SilentFrames, row.Frames)*100) / 100,
+		})
+	}
+
+	for _, report := range reports {
+		totals := report.totals
+		report.Calls = totals.Calls
+		report.Level = feedQualityLevel(totals)
+		report.ClippingPercent = math.Round(feedQualityRatio(totals.Clipped, totals.Samples)*100*1000) / 1000
+		report.SilenceRatio = math.Round(feedQualityRatio(totals.SilentFrames, totals.Frames)*100) / 100
+		report.Status = feedQualityStatus(totals)
+
+		report.FrequencyResponse = []FeedQualityBand{}
+		if totals.ActiveCalls > 0 {
+			loudest := math.Inf(-1)
+			for _, sum := range totals.BandSums {
+				loudest = math.Max(loudest, sum/float64(totals.ActiveCalls))
+			}
+			for b, band := range feedQualityBands {
+				level := totals.BandSums[b]/float64(totals.ActiveCalls) - loudest
+				report.FrequencyResponse = append(report.FrequencyResponse, FeedQualityBand{Band: band.Label, Level: math.Round(level*10) / 10})
+			}
+		}
+		sort.Slice(report.Days, func(i int, j int) bool { return report.Days[i].Date < report.Days[j].Date })
+	}
+
+	sort.SliceStable(reports, func(i int, j int) bool {
+		if a, b := feedQualityStatusOrder[reports[i].Status], feedQualityStatusOrder[reports[j].Status]; a != b {
+			return a < b
+		}
+		return reports[i].Calls > reports[j].Calls
+	})
+
+	return reports
+}
+
+// uploadSourceLabels names the API key and the system of an upload source.
+func (controller *Controller) uploadSourceLabels(apiKeyId uint64, systemId uint64) (string, uint, string) {
+	var ident, label string
+	var systemRef uint
+	if system, ok := controller.Systems.GetSystemById(systemId); ok {
+		systemRef = system.SystemRef
+		label = system.Label
+	}
+	if controller.Apikeys != nil {
+		controller.Apikeys.mutex.Lock()
+		for _, apikey := range controller.Apikeys.List {
+			if apikey.Id == apiKeyId {
+				ident = apikey.Ident
+			}
+		}
+		controller.Apikeys.mutex.Unlock()
+	}
+	return ident, systemRef, label
+}
+
+// FeedQualityReportHandler reports the audio quality of each upload source.
+// GET /api/admin/feed-quality?days=<n>; days defaults to 7 and is capped at
+// the 30 days kept.
+func (admin *Admin) FeedQualityReportHandler(w http.ResponseWriter, r *http.Request) {
+	t := admin.GetAuthorization(r)
+	if !admin.ValidateToken(t) {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	writeError := func(status int, message string) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(map[string]string{"error": message})
+	}
+
+	days := 7
+	if s := r.URL.Query().Get("days"); s != "" {
+		v, err := strconv.Atoi(s)
+		if err != nil || v < 1 {
+			writeError(http.StatusBadRequest, "days must be a positive number")
+			return
+		}
+		days = min(v, feedQualityRetentionDays)
+	}
+	since := time.Now().Unix()/(24*60*60) - int64(days) + 1
+
+	rows, err := admin.Controller.Database.Sql.Query(`SELECT "apiKeyId", "systemId", "day", "calls", "activeCalls", "levelSum", "samples", "clipped", "frames", "silentFrames", "band100Sum", "band300Sum", "band600Sum", "band1200Sum", "band2400Sum" FROM "feedQualityDays" WHERE "day" >= $1`, since)
+	if err != nil {
+		writeError(http.StatusInternalServerError, err.Error())
+		return
+	}
+	defer rows.Close()
+
+	var dayRows []feedQualityDayRow
+	for rows.Next() {
+		row := feedQualityDayRow{}
+		if err := rows.Scan(&row.ApiKeyId, &row.SystemId, &row.Day, &row.Calls, &row.ActiveCalls, &row.LevelSum, &row.Samples, &row.Clipped, &row.Frames, &row.SilentFrames,
+			&row.BandSums[0], &row.BandSums[1], &row.BandSums[2], &row.BandSums[3], &row.BandSums[4]); err != nil {
+			writeError(http.StatusInternalServerError, err.Error())
+			return
+		}
+		dayRows = append(dayRows, row)
+	}
+	if err := rows.Err(); err != nil {
+		writeError(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	reports := admin.Controller.buildFeedQualityReport(dayRows)
+	if reports == nil {
+		reports = []*FeedQualitySourceReport{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"enabled": admin.Controller.Options.FeedQualityEnabled,
+		"days":    days,
+		"sources": reports,
+	})
+}
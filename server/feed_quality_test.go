@@ -0,0 +1,98 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestMeasureFeedQuality(t *testing.T) {
+	// One second of a 1 kHz tone at half scale, then one second of silence
+	samples := make([]int16, energySampleHz*2)
+	for i := 0; i < energySampleHz; i++ {
+		samples[i] = int16(16384 * math.Sin(2*math.Pi*1000*float64(i)/energySampleHz))
+	}
+
+	measurement := measureFeedQuality(pcmFromSamples(samples))
+	if !measurement.Active || math.Abs(measurement.Level-(-9)) > 0.5 {
+		t.Fatalf("expected a -9 dBFS level, got %+v", measurement)
+	}
+	if silence := float64(measurement.SilentFrames) / float64(measurement.Frames); math.Abs(silence-0.5) > 0.02 {
+		t.Fatalf("expected half the frames silent, got %.2f", silence)
+	}
+	if measurement.Clipped != 0 {
+		t.Fatalf("expected no clipping, got %d", measurement.Clipped)
+	}
+	for b, level := range measurement.Bands {
+		if b == 2 && level < -0.5 {
+			t.Fatalf("expected the 1 kHz band to hold the power, got %.1f dB", level)
+		}
+		if b != 2 && level > -20 {
+			t.Fatalf("expected band %s far below, got %.1f dB", feedQualityBands[b].Label, level)
+		}
+	}
+
+	// A 300 Hz tone overdriven into a square wave
+	for i := range samples {
+		if math.Sin(2*math.Pi*300*float64(i)/energySampleHz) >= 0 {
+			samples[i] = 32767
+		} else {
+			samples[i] = -32768
+		}
+	}
+	if measurement = measureFeedQuality(pcmFromSamples(samples)); measurement.Clipped != measurement.Samples {
+		t.Fatalf("expected every sample clipped, got %d of %d", measurement.Clipped, measurement.Samples)
+	}
+
+	if measurement = measureFeedQuality(pcmFromSamples(make([]int16, energySampleHz))); measurement.Active || measurement.SilentFrames != measurement.Frames {
+		t.Fatalf("expected silence, got %+v", measurement)
+	}
+}
+
+func TestBuildFeedQualityReport(t *testing.T) {
+	controller := &Controller{Options: NewOptions(), Systems: NewSystems()}
+
+	reports := controller.buildFeedQualityReport([]feedQualityDayRow{
+		{ApiKeyId: 1, SystemId: 1, Day: 20000, Calls: 10, ActiveCalls: 10, LevelSum: -200, Samples: 1000, Frames: 100, SilentFrames: 10, BandSums: [feedQualityBandCount]float64{-100, -50, -30, -60, -150}},
+		{ApiKeyId: 1, SystemId: 1, Day: 19999, Calls: 30, ActiveCalls: 30, LevelSum: -600, Samples: 3000, Frames: 300, SilentFrames: 30, BandSums: [feedQualityBandCount]float64{-300, -150, -90, -180, -450}},
+		{ApiKeyId: 2, SystemId: 1, Day: 20000, Calls: 50, ActiveCalls: 50, LevelSum: -2000, Samples: 5000, Frames: 500, SilentFrames: 50},
+		{ApiKeyId: 3, SystemId: 1, Day: 20000, Calls: 25, ActiveCalls: 25, LevelSum: -400, Samples: 10000, Clipped: 50, Frames: 1000},
+		{ApiKeyId: 4, SystemId: 1, Day: 20000, Calls: 5, ActiveCalls: 5, LevelSum: -100, Samples: 500, Frames: 50},
+	})
+	if len(reports) != 4 {
+		t.Fatalf("expected 4 sources, got %d", len(reports))
+	}
+
+	statuses := []string{}
+	for _, report := range reports {
+		statuses = append(statuses, report.Status)
+	}
+	if statuses[0] != "clipping" || statuses[1] != "quiet" || statuses[2] != "ok" || statuses[3] != "insufficient" {
+		t.Fatalf("statuses: %v", statuses)
+	}
+	if reports[0].ClippingPercent != 0.5 {
+		t.Fatalf("clipping: %+v", reports[0])
+	}
+
+	mixed := reports[2]
+	if mixed.Calls != 40 || mixed.Level != -20 || mixed.SilenceRatio != 0.1 {
+		t.Fatalf("mixed source: %+v", mixed)
+	}
+	if len(mixed.Days) != 2 || mixed.Days[0].Date != "2024-10-03" {
+		t.Fatalf("days: %+v", mixed.Days)
+	}
+	if len(mixed.FrequencyResponse) != feedQualityBandCount || mixed.FrequencyResponse[2].Level != 0 || mixed.FrequencyResponse[1].Level != -2 {
+		t.Fatalf("frequency response: %+v", mixed.FrequencyResponse)
+	}
+}
+
+func TestFeedQualityStatus(t *testing.T) {
+	if status := feedQualityStatus(feedQualityDayRow{Calls: 30, Frames: 100, SilentFrames: 60, ActiveCalls: 30, LevelSum: -600}); status != "silence" {
+		t.Fatalf("expected silence, got %s", status)
+	}
+	if status := feedQualityStatus(feedQualityDayRow{Calls: 30, ActiveCalls: 30, LevelSum: -150}); status != "hot" {
+		t.Fatalf("expected hot, got %s", status)
+	}
+	if status := feedQualityStatus(feedQualityDayRow{Calls: 30}); status != "quiet" {
+		t.Fatalf("expected a source without audio to be quiet, got %s", status)
+	}
+}
@@ -17,8 +17,11 @@ package main
 
 import (
 	"bytes"
+	"encoding/binary"
 	"errors"
 	"fmt"
+	"math"
+	"os"
 	"os/exec"
 	"path"
 	"regexp"
@@ -61,23 +64,35 @@ func NewFFMpeg() *FFMpeg {
 	return ffmpeg
 }
 
-func (ffmpeg *FFMpeg) ProcessForTranscription(audio []byte) []byte {
+// ProcessForTranscription denoises and compresses the audio for the
+// transcription providers. It starts from the decode shared with the other
+// ingest stages, so the audio is only decoded again when that decode fails.
+func (ffmpeg *FFMpeg) ProcessForTranscription(audio []byte, mime string) []byte {
 	if !ffmpeg.available {
 		return audio
 	}
 
-	args := []string{
-		"-i", "-",
+	input := []string{"-i", "-"}
+	stdin := audio
+	if decoded, err := decodeAudioShared(audio, mime); err == nil && len(decoded.Speech) > 0 {
+		input = []string{"-f", "f32le", "-ar", fmt.Sprint(toneDecodeHz), "-ac", "1", "-i", "-"}
+		stdin = make([]byte, len(decoded.Speech)*4)
+		for i, sample := range decoded.Speech {
+			binary.LittleEndian.PutUint32(stdin[i*4:], math.Float32bits(sample))
+		}
+	}
+
+	args := append(input,
 		"-af", "highpass=f=120,acompressor=threshold=-20dB:ratio=4:attack=8:release=80:makeup=6dB,afftdn=nf=-20",
 		"-ar", "16000",
 		"-ac", "1",
 		"-c:a", "pcm_s16le",
 		"-f", "wav",
 		"-",
-	}
+	)
 
 	cmd := exec.Command("ffmpeg", args...)
-	cmd.Stdin = bytes.NewReader(audio)
+	cmd.Stdin = bytes.NewReader(stdin)
 
 	stdout := bytes.NewBuffer([]byte(nil))
 	cmd.Stdout = stdout
@@ -92,13 +107,16 @@ func (ffmpeg *FFMpeg) ProcessForTranscription(audio []byte) []byte {
 	return audio
 }
 
-func (ffmpeg *FFMpeg) Convert(call *Call, systems *Systems, tags *Tags, mode uint) error {
+// Convert encodes the call audio to AAC. A negative loudnessTarget (LUFS)
+// normalizes the audio to that target whatever the conversion mode. Non-empty
+// filters replace the filters of the conversion mode.
+func (ffmpeg *FFMpeg) Convert(call *Call, systems *Systems, tags *Tags, mode uint, loudnessTarget float64, filters string) error {
 	var (
 		args = []string{"-i", "-"}
 		err  error
 	)
 
-	if mode == AUDIO_CONVERSION_DISABLED {
+	if mode == AUDIO_CONVERSION_DISABLED && loudnessTarget >= 0 && filters == "" {
 		return nil
 	}
 
@@ -122,7 +140,11 @@ func (ffmpeg *FFMpeg) Convert(call *Call, systems *Systems, tags *Tags, mode uin
 	}
 
 	if ffmpeg.version43 {
-		if mode == AUDIO_CONVERSION_ENABLED_NORM {
+		if filters != "" {
+			args = append(args, "-af", filters)
+		} else if loudnessTarget < 0 {
+			args = append(args, "-af", fmt.Sprintf("apad=whole_dur=3s,highpass=f=120,acompressor=threshold=-20dB:ratio=4:attack=8:release=80:makeup=6dB,afftdn=nf=-20,equalizer=f=250:width_type=q:width=2:g=-3,equalizer=f=3000:width_type=q:width=2:g=5,lowpass=f=3200,loudnorm=I=%.1f:TP=-1.5:LRA=11,alimiter=limit=0.891:attack=5:release=50", loudnessTarget))
+		} else if mode == AUDIO_CONVERSION_ENABLED_NORM {
 			args = append(args, "-af", "apad=whole_dur=3s,highpass=f=120,acompressor=threshold=-20dB:ratio=4:attack=8:release=80:makeup=6dB,afftdn=nf=-20,equalizer=f=250:width_type=q:width=2:g=-3,equalizer=f=3000:width_type=q:width=2:g=5,lowpass=f=3200,loudnorm=I=-14:TP=-1.5:LRA=11,alimiter=limit=0.891:attack=5:release=50")
 		} else if mode == AUDIO_CONVERSION_ENABLED_LOUD_NORM {
 			args = append(args, "-af", "apad=whole_dur=3s,highpass=f=120,acompressor=threshold=-20dB:ratio=4:attack=8:release=80:makeup=6dB,afftdn=nf=-20,equalizer=f=250:width_type=q:width=2:g=-3,equalizer=f=3000:width_type=q:width=2:g=5,lowpass=f=3200,loudnorm=I=-14:TP=-1.5:LRA=3,alimiter=limit=0.891:attack=5:release=50")
@@ -150,3 +172,133 @@ func (ffmpeg *FFMpeg) Convert(call *Call, systems *Systems, tags *Tags, mode uin
 
 	return nil
 }
+
+// Prepend joins intro before the audio and encodes the result to AAC like
+// Convert. Both inputs can be in any format ffmpeg reads.
+func (ffmpeg *FFMpeg) Prepend(intro []byte, audio []byte) ([]byte, error) {
+	if !ffmpeg.available {
+		return nil, errors.New("ffmpeg is not available")
+	}
+
+	// ffmpeg reads only one input from stdin, the intro goes through a file
+	f, err := os.CreateTemp("", "tlr-intro-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(intro); err != nil {
+		f.Close()
+		return nil, err
+	}
+	f.Close()
+
+	args := []string{
+		"-i", f.Name(),
+		"-i", "-",
+		"-filter_complex", "[0:a]aresample=16000,aformat=channel_layouts=mono,apad=pad_dur=0.3[a0];[1:a]aresample=16000,aformat=channel_layouts=mono[a1];[a0][a1]concat=n=2:v=0:a=1",
+		"-c:a", "aac", "-b:a", "48k", "-movflags", "frag_keyframe+empty_moov", "-f", "ipod", "-",
+	}
+
+	cmd := exec.Command("ffmpeg", args...)
+	cmd.Stdin = bytes.NewReader(audio)
+
+	stdout := bytes.NewBuffer([]byte(nil))
+	cmd.Stdout = stdout
+
+	stderr := bytes.NewBuffer([]byte(nil))
+	cmd.Stderr = stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("%v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.Bytes(), nil
+}
+
+// Trim cuts the audio to duration seconds from start, re-encoding it in the
+// format given by the mime type.
+func (ffmpeg *FFMpeg) Trim(audio []byte, mime string, start float64, duration float64) ([]byte, error) {
+	if !ffmpeg.available {
+		return nil, errors.New("ffmpeg is not available")
+	}
+
+	// The output goes through a file so containers that seek, like m4a, work
+	ext := audioExtFromMime(mime)
+	in, err := os.CreateTemp("", "tlr-trim-*"+ext)
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(in.Name())
+	if _, err := in.Write(audio); err != nil {
+		in.Close()
+		return nil, err
+	}
+	in.Close()
+
+	out := strings.TrimSuffix(in.Name(), ext) + "-out" + ext
+	defer os.Remove(out)
+
+	args := []string{
+		"-y",
+		"-i", in.Name(),
+		"-ss", fmt.Sprintf("%.3f", start),
+		"-t", fmt.Sprintf("%.3f", duration),
+		"-map_metadata", "0",
+		out,
+	}
+
+	cmd := exec.Command("ffmpeg", args...)
+
+	stderr := bytes.NewBuffer([]byte(nil))
+	cmd.Stderr = stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("%v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return os.ReadFile(out)
+}
+
+var (
+	loudnessIntegratedRegexp = regexp.MustCompile(`I:\s+(-?[0-9.]+) LUFS`)
+	loudnessMaxVolumeRegexp  = regexp.MustCompile(`max_volume:\s+(-?[0-9.]+) dB`)
+)
+
+// MeasureLoudness returns the integrated loudness (LUFS) and peak level (dBFS)
+// of the audio.
+func (ffmpeg *FFMpeg) MeasureLoudness(audio []byte) (*LoudnessMeasurement, error) {
+	if !ffmpeg.available {
+		return nil, errors.New("ffmpeg is not available")
+	}
+
+	cmd := exec.Command("ffmpeg", "-hide_banner", "-nostats", "-i", "-", "-af", "volumedetect,ebur128", "-f", "null", "-")
+	cmd.Stdin = bytes.NewReader(audio)
+
+	stderr := bytes.NewBuffer([]byte(nil))
+	cmd.Stderr = stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("ffmpeg: %v", err)
+	}
+
+	return parseLoudnessOutput(stderr.String())
+}
+
+// parseLoudnessOutput reads the ebur128 summary and volumedetect output.
+func parseLoudnessOutput(output string) (*LoudnessMeasurement, error) {
+	integrated := loudnessIntegratedRegexp.FindAllStringSubmatch(output, -1)
+	peak := loudnessMaxVolumeRegexp.FindStringSubmatch(output)
+	if len(integrated) == 0 || peak == nil {
+		return nil, errors.New("no loudness in ffmpeg output")
+	}
+
+	// The summary comes last, after the per-frame lines
+	loudness, err := strconv.ParseFloat(integrated[len(integrated)-1][1], 64)
+	if err != nil {
+		return nil, err
+	}
+	maxVolume, err := strconv.ParseFloat(peak[1], 64)
+	if err != nil {
+		return nil, err
+	}
+
+	return &LoudnessMeasurement{Loudness: loudness, Peak: maxVolume}, nil
+}
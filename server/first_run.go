@@ -0,0 +1,235 @@
+// Copyright (C) 2026 Thinline Dynamic Solutions
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>
+
+package main
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// FirstRun guards a server whose admin password is still the built-in default.
+// Password login with the default is refused; instead a one-time setup token is
+// printed to the console and exchanged at /api/admin/setup for a real password
+// (and optionally a system administrator account).
+type FirstRun struct {
+	controller *Controller
+	mutex      sync.Mutex
+	token      string
+}
+
+func NewFirstRun(controller *Controller) *FirstRun {
+	return &FirstRun{controller: controller}
+}
+
+// isDefaultAdminPassword reports whether hash is the hash of the default password.
+func isDefaultAdminPassword(hash string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(defaults.adminPassword)) == nil
+}
+
+// Check runs after the options are loaded. It flags the default password for a
+// forced change and issues a setup token.
+func (firstRun *FirstRun) Check() {
+	options := firstRun.controller.Options
+
+	if !isDefaultAdminPassword(options.adminPassword) {
+		return
+	}
+
+	if !options.adminPasswordNeedChange {
+		options.adminPasswordNeedChange = true
+		if err := options.Write(firstRun.controller.Database); err != nil {
+			firstRun.controller.Logs.LogEvent(LogLevelError, fmt.Sprintf("first run: %v", err))
+		}
+	}
+
+	firstRun.mutex.Lock()
+	if firstRun.token == "" {
+		firstRun.token = generateSetupSecret()
+	}
+	token := firstRun.token
+	firstRun.mutex.Unlock()
+
+	log.Println("╔════════════════════════════════════════════════════════════════════╗")
+	log.Println("║ FIRST RUN: the admin password has not been set.                    ║")
+	log.Println("║ Open the admin page and enter this one-time setup token to create  ║")
+	log.Println("║ the administrator account, or run with -admin_password.            ║")
+	log.Println("╚════════════════════════════════════════════════════════════════════╝")
+	log.Printf("setup token: %s", token)
+
+	firstRun.controller.Logs.LogEvent(LogLevelWarn, "first run: admin password is the default; password login is disabled until the administrator account is created with the setup token printed to the console")
+}
+
+// Required reports whether the administrator account still has to be created.
+func (firstRun *FirstRun) Required() bool {
+	firstRun.mutex.Lock()
+	defer firstRun.mutex.Unlock()
+
+	return firstRun.token != ""
+}
+
+// clear drops the setup token once the password was changed another way.
+func (firstRun *FirstRun) clear() {
+	firstRun.mutex.Lock()
+	firstRun.token = ""
+	firstRun.mutex.Unlock()
+}
+
+// Complete validates the setup token and sets the administrator credentials.
+func (firstRun *FirstRun) Complete(token string, email string, password string) error {
+	firstRun.mutex.Lock()
+	defer firstRun.mutex.Unlock()
+
+	if firstRun.token == "" {
+		return errors.New("setup has already been completed")
+	}
+	if subtle.ConstantTimeCompare([]byte(token), []byte(firstRun.token)) != 1 {
+		return errors.New("invalid setup token")
+	}
+
+	controller := firstRun.controller
+	if err := setAdminCredentials(controller.Database, controller.Options, controller.Users, email, password); err != nil {
+		return err
+	}
+
+	firstRun.token = ""
+
+	controller.Logs.LogEvent(LogLevelInfo, "first run: administrator account created")
+
+	return nil
+}
+
+// setAdminCredentials sets the admin password and, when email is given, makes a
+// verified system administrator user with the same password. It is shared by the
+// setup wizards and the first-run endpoint.
+func setAdminCredentials(db *Database, options *Options, users *Users, email string, password string) error {
+	if password == defaults.adminPassword {
+		return errors.New("the default password cannot be used")
+	}
+	if err := ValidatePassword(password); err != nil {
+		return err
+	}
+
+	email = NormalizeEmail(email)
+	if email != "" {
+		if err := ValidateEmail(email); err != nil {
+			return err
+		}
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	options.adminPassword = string(hash)
+	options.adminPasswordNeedChange = false
+
+	if err := options.Write(db); err != nil {
+		return err
+	}
+
+	if email == "" {
+		return nil
+	}
+
+	if user := users.GetUserByEmail(email); user != nil {
+		user.SystemAdmin = true
+		user.Verified = true
+		if err := user.SetPassword(password); err != nil {
+			return err
+		}
+		if err := users.Update(user); err != nil {
+			return err
+		}
+		return users.Write(db)
+	}
+
+	user := NewUser(email, password)
+	user.Verified = true
+	user.VerificationToken = ""
+	user.SystemAdmin = true
+
+	return users.SaveNewUser(user, db)
+}
+
+// FirstRunHandler exchanges the setup token for the administrator credentials.
+// GET reports whether setup is required; POST {"token","password","email"}
+// completes it and returns an admin session token.
+func (admin *Admin) FirstRunHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	switch r.Method {
+	case http.MethodGet:
+		json.NewEncoder(w).Encode(map[string]any{"required": admin.Controller.FirstRun.Required()})
+
+	case http.MethodPost:
+		var body struct {
+			Token    string `json:"token"`
+			Email    string `json:"email"`
+			Password string `json:"password"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid request body"})
+			return
+		}
+
+		if err := admin.Controller.FirstRun.Complete(strings.TrimSpace(body.Token), body.Email, body.Password); err != nil {
+			admin.Controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("first run: setup rejected from %s: %v", GetRemoteAddr(r), err))
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+			return
+		}
+
+		id, err := uuid.NewRandom()
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{ID: id.String()})
+		sToken, err := token.SignedString([]byte(admin.Controller.Options.secret))
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		admin.mutex.Lock()
+		if len(admin.Tokens) < 5 {
+			admin.Tokens = append(admin.Tokens, sToken)
+		} else {
+			admin.Tokens = append(admin.Tokens[1:], sToken)
+		}
+		admin.mutex.Unlock()
+
+		json.NewEncoder(w).Encode(map[string]any{
+			"passwordNeedChange": false,
+			"token":              sToken,
+		})
+
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestFirstRunRejectsBadTokenAndWeakPasswords(t *testing.T) {
+	firstRun := NewFirstRun(&Controller{Options: NewOptions()})
+
+	if err := firstRun.Complete("anything", "", "Str0ngPassword"); err == nil {
+		t.Fatalf("completed without a pending setup")
+	}
+
+	firstRun.token = "token"
+	if err := firstRun.Complete("wrong", "", "Str0ngPassword"); err == nil || err.Error() != "invalid setup token" {
+		t.Fatalf("wrong token: %v", err)
+	}
+	for _, password := range []string{defaults.adminPassword, "short", "alllowercase1"} {
+		if err := firstRun.Complete("token", "", password); err == nil {
+			t.Fatalf("password %q accepted", password)
+		}
+	}
+	if !firstRun.Required() {
+		t.Fatalf("failed attempts must keep the setup token")
+	}
+}
+
+func TestIsDefaultAdminPassword(t *testing.T) {
+	hash, _ := bcrypt.GenerateFromPassword([]byte(defaults.adminPassword), bcrypt.MinCost)
+	if !isDefaultAdminPassword(string(hash)) {
+		t.Fatalf("default hash not detected")
+	}
+	hash, _ = bcrypt.GenerateFromPassword([]byte("Str0ngPassword"), bcrypt.MinCost)
+	if isDefaultAdminPassword(string(hash)) {
+		t.Fatalf("custom password flagged as default")
+	}
+}
@@ -16,32 +16,19 @@
 package main
 
 import (
+	"database/sql"
 	"encoding/json"
 	"fmt"
 	"log"
-	"time"
 )
 
-// migrateFixKeywordListIds is called during database migrations to repair orphaned keyword list IDs
-func migrateFixKeywordListIds(db *Database) error {
-	// Check if migration has already been applied
-	var migrationExists bool
-	checkQuery := `SELECT EXISTS(SELECT 1 FROM "migrations" WHERE "name" = 'fix_keyword_list_ids_v1')`
-	if err := db.Sql.QueryRow(checkQuery).Scan(&migrationExists); err != nil {
-		// migrations table might not exist in older versions, continue anyway
-		migrationExists = false
-	}
-
-	if migrationExists {
-		// Migration already applied, skip
-		return nil
-	}
-
+// fixKeywordListIds is the schema migration repairing orphaned keyword list IDs
+func fixKeywordListIds(tx *sql.Tx) error {
 	log.Printf("Running keyword list ID repair migration...")
 
 	// Get all current keyword lists (sorted by order, then createdAt)
 	currentListsQuery := `SELECT "keywordListId", "label", "order" FROM "keywordLists" ORDER BY "order" ASC, "createdAt" ASC`
-	currentRows, err := db.Sql.Query(currentListsQuery)
+	currentRows, err := tx.Query(currentListsQuery)
 	if err != nil {
 		return fmt.Errorf("failed to query current keyword lists: %v", err)
 	}
@@ -66,14 +53,12 @@ func migrateFixKeywordListIds(db *Database) error {
 
 	if len(currentLists) == 0 {
 		log.Printf("No keyword lists found, skipping migration")
-		// Mark migration as complete even if no lists exist
-		_, _ = db.Sql.Exec(`INSERT INTO "migrations" ("name", "appliedAt") VALUES ('fix_keyword_list_ids_v1', $1)`, time.Now().Unix())
 		return nil
 	}
 
 	// Find all unique orphaned IDs (referenced but don't exist)
 	prefsQuery := `SELECT DISTINCT "keywordListIds" FROM "userAlertPreferences" WHERE "keywordListIds" != '[]' AND "keywordListIds" != ''`
-	prefsRows, err := db.Sql.Query(prefsQuery)
+	prefsRows, err := tx.Query(prefsQuery)
 	if err != nil {
 		return fmt.Errorf("failed to query user preferences: %v", err)
 	}
@@ -116,8 +101,6 @@ func migrateFixKeywordListIds(db *Database) error {
 
 	if len(orphanedIds) == 0 {
 		log.Printf("✓ No orphaned keyword list IDs found")
-		// Mark migration as complete
-		_, _ = db.Sql.Exec(`INSERT INTO "migrations" ("name", "appliedAt") VALUES ('fix_keyword_list_ids_v1', $1)`, time.Now().Unix())
 		return nil
 	}
 
@@ -138,7 +121,7 @@ func migrateFixKeywordListIds(db *Database) error {
 
 	// Update all user preferences with orphaned IDs
 	query := `SELECT "userAlertPreferenceId", "userId", "systemId", "talkgroupId", "keywordListIds" FROM "userAlertPreferences" WHERE "keywordListIds" != '[]' AND "keywordListIds" != ''`
-	rows, err := db.Sql.Query(query)
+	rows, err := tx.Query(query)
 	if err != nil {
 		return fmt.Errorf("failed to query affected preferences: %v", err)
 	}
@@ -180,8 +163,6 @@ func migrateFixKeywordListIds(db *Database) error {
 
 	if len(preferencesToUpdate) == 0 {
 		log.Printf("No preferences need updating")
-		// Mark migration as complete
-		_, _ = db.Sql.Exec(`INSERT INTO "migrations" ("name", "appliedAt") VALUES ('fix_keyword_list_ids_v1', $1)`, time.Now().Unix())
 		return nil
 	}
 
@@ -211,10 +192,9 @@ func migrateFixKeywordListIds(db *Database) error {
 		if !equalSlices(pref.keywordListIds, newIds) {
 			newIdsJson, _ := json.Marshal(newIds)
 
-			updateQuery := fmt.Sprintf(`UPDATE "userAlertPreferences" SET "keywordListIds" = $1 WHERE "userAlertPreferenceId" = %d`, pref.id)
-			if _, err := db.Sql.Exec(updateQuery, string(newIdsJson)); err != nil {
-				log.Printf("Failed to update preference %d: %v", pref.id, err)
-				continue
+			updateQuery := `UPDATE "userAlertPreferences" SET "keywordListIds" = $1 WHERE "userAlertPreferenceId" = $2`
+			if _, err := tx.Exec(updateQuery, string(newIdsJson), pref.id); err != nil {
+				return fmt.Errorf("failed to update preference %d: %v", pref.id, err)
 			}
 			updatedCount++
 		}
@@ -222,9 +202,6 @@ func migrateFixKeywordListIds(db *Database) error {
 
 	log.Printf("✓ Updated %d user preferences with corrected keyword list IDs", updatedCount)
 
-	// Mark migration as complete
-	_, _ = db.Sql.Exec(`INSERT INTO "migrations" ("name", "appliedAt") VALUES ('fix_keyword_list_ids_v1', $1)`, time.Now().Unix())
-
 	return nil
 }
 
@@ -435,8 +412,8 @@ func (controller *Controller) FixKeywordListIds(dryRun bool) error {
 				pref.userId, pref.systemId, pref.talkgroupId, oldIds, newIds))
 
 			if !dryRun {
-				updateQuery := fmt.Sprintf(`UPDATE "userAlertPreferences" SET "keywordListIds" = $1 WHERE "userAlertPreferenceId" = %d`, pref.id)
-				if _, err := controller.Database.Sql.Exec(updateQuery, string(newIdsJson)); err != nil {
+				updateQuery := `UPDATE "userAlertPreferences" SET "keywordListIds" = $1 WHERE "userAlertPreferenceId" = $2`
+				if _, err := controller.Database.Sql.Exec(updateQuery, string(newIdsJson), pref.id); err != nil {
 					controller.Logs.LogEvent(LogLevelError, fmt.Sprintf("Failed to update preference %d: %v", pref.id, err))
 					continue
 				}
@@ -0,0 +1,357 @@
+// Copyright (C) 2026 Thinline Dynamic Solutions
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	nominatimDefaultURL   = "https://nominatim.openstreetmap.org"
+	googleGeocodeURL      = "https://maps.googleapis.com/maps/api/geocode/json"
+	geocodeCacheSize      = 2000
+	nominatimMinInterval  = time.Second // Nominatim usage policy: at most one request per second
+	geocodeUserAgent      = "ThinLineRadio (https://github.com/Thinline-Dynamic-Solutions/ThinLineRadio)"
+	geocodeStreetSuffixes = `ST|STREET|AVE|AVENUE|RD|ROAD|DR|DRIVE|LN|LANE|BLVD|BOULEVARD|CT|COURT|WAY|HWY|HIGHWAY|PL|PLACE|PKWY|PARKWAY|CIR|CIRCLE|TER|TERRACE|TRL|TRAIL|PIKE|ROUTE`
+)
+
+// GeocodingConfig locates the addresses and intersections dispatched in
+// transcripts. Each system can add a city or county hint, which is appended
+// to every address looked up for its calls.
+type GeocodingConfig struct {
+	Enabled  bool   `json:"enabled"`
+	Provider string `json:"provider"` // "nominatim" (default) or "google"
+	APIKey   string `json:"apiKey"`   // Google Geocoding API key
+	URL      string `json:"url"`      // Nominatim server (default the public OpenStreetMap server)
+	Email    string `json:"email"`    // contact sent to the public Nominatim server, as its usage policy asks
+}
+
+// CallLocation is where a call was dispatched to.
+type CallLocation struct {
+	Address   string  `json:"address"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+// Geocoder looks up the coordinates of an address.
+type Geocoder interface {
+	Geocode(query string) (*CallLocation, error)
+	GetName() string
+}
+
+var (
+	geocodeAddressPattern      = regexp.MustCompile(`\b\d{1,6}\s+(?:[A-Z0-9]+\s+){0,3}(?:` + geocodeStreetSuffixes + `)\b`)
+	geocodeIntersectionPattern = regexp.MustCompile(`\b((?:[A-Z0-9]+\s+){1,3}(?:` + geocodeStreetSuffixes + `))\s+(?:AND|&|AT)\s+((?:[A-Z0-9]+\s+){0,2}(?:` + geocodeStreetSuffixes + `)\b|[A-Z0-9]+\b)`)
+	geocodeDigitsPattern       = regexp.MustCompile(`^\d+$`)
+)
+
+// geocodeLeadingWords are words that come before a street name in dispatch
+// traffic and are not part of it.
+var geocodeLeadingWords = map[string]bool{
+	"RESPOND": true, "RESPONDING": true, "TO": true, "AT": true, "ON": true, "OF": true, "IN": true,
+	"FOR": true, "NEAR": true, "BY": true, "THE": true, "AND": true, "CORNER": true, "AREA": true,
+	"STRUCTURE": true, "FIRE": true, "MEDICAL": true, "ALARM": true, "ACCIDENT": true, "CRASH": true,
+}
+
+// extractDispatchLocation returns the first street address, or else the first
+// intersection, spoken in a transcript, in capitals.
+func extractDispatchLocation(transcript string) string {
+	text := strings.Join(strings.Fields(strings.ToUpper(transcript)), " ")
+
+	for _, match := range geocodeAddressPattern.FindAllString(text, -1) {
+		// "ENGINE 5 TO 12 MAIN ST" matches from the unit number: the house
+		// number is the last number before the street name
+		words := strings.Fields(match)
+		start := 0
+		for i := 1; i < len(words)-1; i++ {
+			if geocodeDigitsPattern.MatchString(words[i]) {
+				start = i
+			}
+		}
+		words = words[start:]
+		// "MEDIC 3 RESPOND TO OAK AVE" is a unit, not a house number
+		street := true
+		for _, word := range words[1 : len(words)-1] {
+			if geocodeLeadingWords[word] {
+				street = false
+				break
+			}
+		}
+		if street {
+			return strings.Join(words, " ")
+		}
+	}
+
+	if m := geocodeIntersectionPattern.FindStringSubmatch(text); m != nil {
+		first := strings.Fields(m[1])
+		for len(first) > 1 && geocodeLeadingWords[first[0]] {
+			first = first[1:]
+		}
+		second := strings.TrimSpace(m[2])
+		if geocodeLeadingWords[second] {
+			return ""
+		}
+		return strings.Join(first, " ") + " AND " + second
+	}
+
+	return ""
+}
+
+// geocodeQuery joins an address and the city or county hint of its system.
+func geocodeQuery(address string, hint string) string {
+	if hint = strings.TrimSpace(hint); hint == "" {
+		return address
+	}
+	return address + ", " + hint
+}
+
+// Geocoding locates calls and caches the result of each lookup, failures
+// included, so the same address is looked up once.
+type Geocoding struct {
+	controller *Controller
+	mutex      sync.Mutex
+	cache      map[string]*CallLocation
+	order      []string
+}
+
+func NewGeocoding(controller *Controller) *Geocoding {
+	return &Geocoding{controller: controller, cache: map[string]*CallLocation{}}
+}
+
+func (geocoding *Geocoding) cached(query string) (*CallLocation, bool) {
+	geocoding.mutex.Lock()
+	defer geocoding.mutex.Unlock()
+	location, ok := geocoding.cache[query]
+	return location, ok
+}
+
+func (geocoding *Geocoding) store(query string, location *CallLocation) {
+	geocoding.mutex.Lock()
+	defer geocoding.mutex.Unlock()
+	if _, ok := geocoding.cache[query]; !ok {
+		geocoding.order = append(geocoding.order, query)
+	}
+	geocoding.cache[query] = location
+	for len(geocoding.order) > geocodeCacheSize {
+		delete(geocoding.cache, geocoding.order[0])
+		geocoding.order = geocoding.order[1:]
+	}
+}
+
+// newGeocoder returns the geocoder selected in config.
+func newGeocoder(config GeocodingConfig) (Geocoder, error) {
+	switch strings.ToLower(config.Provider) {
+	case "", "nominatim":
+		base := strings.TrimRight(config.URL, "/")
+		if base == "" {
+			base = nominatimDefaultURL
+		}
+		return &nominatimGeocoder{url: base, email: config.Email}, nil
+	case "google":
+		if config.APIKey == "" {
+			return nil, fmt.Errorf("google geocoding api key not configured")
+		}
+		return &googleGeocoder{apiKey: config.APIKey}, nil
+	default:
+		return nil, fmt.Errorf("unknown geocoding provider %q", config.Provider)
+	}
+}
+
+// LocateCall geocodes the address of a transcribed call and stores it on the
+// call. address is the address extracted by the summarizer, if any; without
+// it the address is parsed from the transcript.
+func (geocoding *Geocoding) LocateCall(call *Call, address string) *CallLocation {
+	if geocoding == nil || call == nil || call.Id == 0 {
+		return nil
+	}
+	config := geocoding.controller.Options.GeocodingConfig
+	if !config.Enabled {
+		return nil
+	}
+
+	address = strings.ToUpper(strings.TrimSpace(address))
+	if address == "" {
+		address = extractDispatchLocation(call.Transcript)
+	}
+	if address == "" {
+		return nil
+	}
+
+	hint := ""
+	if call.System != nil {
+		hint = call.System.GeocodeHint
+	}
+	query := geocodeQuery(address, hint)
+
+	location, ok := geocoding.cached(query)
+	if !ok {
+		geocoder, err := newGeocoder(config)
+		if err != nil {
+			geocoding.controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("geocoding skipped for call %d: %v", call.Id, err))
+			return nil
+		}
+		location, err = geocoder.Geocode(query)
+		if err != nil {
+			// Not cached, so a transient error is retried on the next call
+			geocoding.controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("geocoding %q for call %d with %s failed: %v", query, call.Id, geocoder.GetName(), err))
+			return nil
+		}
+		geocoding.store(query, location)
+	}
+	if location == nil {
+		return nil
+	}
+
+	located := &CallLocation{Address: address, Latitude: location.Latitude, Longitude: location.Longitude}
+	if _, err := geocoding.controller.Database.Sql.Exec(
+		`UPDATE "calls" SET "locationAddress" = $1, "latitude" = $2, "longitude" = $3 WHERE "callId" = $4`,
+		located.Address, located.Latitude, located.Longitude, call.Id,
+	); err != nil {
+		geocoding.controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("failed to store the location of call %d: %v", call.Id, err))
+		return nil
+	}
+	call.Location = located
+	return located
+}
+
+var geocodeHTTPClient = &http.Client{Timeout: 15 * time.Second}
+
+// getGeocode sends a GET request and decodes the JSON of a 200 response.
+func getGeocode(requestURL string, response any) error {
+	req, err := http.NewRequest(http.MethodGet, requestURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %v", err)
+	}
+	req.Header.Set("User-Agent", geocodeUserAgent)
+
+	resp, err := geocodeHTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response body: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body))
+	}
+	if err := json.Unmarshal(body, response); err != nil {
+		return fmt.Errorf("failed to parse response: %v", err)
+	}
+	return nil
+}
+
+// nominatimGeocoder looks addresses up on a Nominatim server. Requests are
+// spaced a second apart to respect the public server's usage policy.
+type nominatimGeocoder struct {
+	url   string
+	email string
+}
+
+var (
+	nominatimMutex   sync.Mutex
+	nominatimLastGet time.Time
+)
+
+func (geocoder *nominatimGeocoder) Geocode(query string) (*CallLocation, error) {
+	nominatimMutex.Lock()
+	if wait := nominatimMinInterval - time.Since(nominatimLastGet); wait > 0 {
+		time.Sleep(wait)
+	}
+	nominatimLastGet = time.Now()
+	nominatimMutex.Unlock()
+
+	params := url.Values{"q": {query}, "format": {"jsonv2"}, "limit": {"1"}}
+	if geocoder.email != "" {
+		params.Set("email", geocoder.email)
+	}
+
+	var results []struct {
+		Lat string `json:"lat"`
+		Lon string `json:"lon"`
+	}
+	if err := getGeocode(geocoder.url+"/search?"+params.Encode(), &results); err != nil {
+		return nil, err
+	}
+	if len(results) == 0 {
+		return nil, nil
+	}
+	latitude, err := strconv.ParseFloat(results[0].Lat, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid latitude %q", results[0].Lat)
+	}
+	longitude, err := strconv.ParseFloat(results[0].Lon, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid longitude %q", results[0].Lon)
+	}
+	return &CallLocation{Address: query, Latitude: latitude, Longitude: longitude}, nil
+}
+
+func (geocoder *nominatimGeocoder) GetName() string {
+	return "Nominatim"
+}
+
+// googleGeocoder looks addresses up with the Google Geocoding API.
+type googleGeocoder struct {
+	apiKey string
+}
+
+func (geocoder *googleGeocoder) Geocode(query string) (*CallLocation, error) {
+	params := url.Values{"address": {query}, "key": {geocoder.apiKey}}
+
+	var response struct {
+		Status       string `json:"status"`
+		ErrorMessage string `json:"error_message"`
+		Results      []struct {
+			Geometry struct {
+				Location struct {
+					Lat float64 `json:"lat"`
+					Lng float64 `json:"lng"`
+				} `json:"location"`
+			} `json:"geometry"`
+		} `json:"results"`
+	}
+	if err := getGeocode(googleGeocodeURL+"?"+params.Encode(), &response); err != nil {
+		return nil, err
+	}
+	switch response.Status {
+	case "OK":
+	case "ZERO_RESULTS":
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("%s: %s", response.Status, response.ErrorMessage)
+	}
+	if len(response.Results) == 0 {
+		return nil, nil
+	}
+	location := response.Results[0].Geometry.Location
+	return &CallLocation{Address: query, Latitude: location.Lat, Longitude: location.Lng}, nil
+}
+
+func (geocoder *googleGeocoder) GetName() string {
+	return "Google"
+}
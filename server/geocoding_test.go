@@ -0,0 +1,75 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestExtractDispatchLocation(t *testing.T) {
+	cases := []struct {
+		transcript string
+		want       string
+	}{
+		{"Engine 5 respond to 123 North Main Street for a fire alarm", "123 NORTH MAIN STREET"},
+		{"engine 5 to 12 main st", "12 MAIN ST"},
+		{"medic 3 respond to Oak Avenue and 5th for a crash", "OAK AVENUE AND 5TH"},
+		{"units respond to the corner of Elm St & Pine Rd", "ELM ST AND PINE RD"},
+		{"motor vehicle accident at route 9", ""},
+		{"engine 5 on scene", ""},
+	}
+	for _, c := range cases {
+		if got := extractDispatchLocation(c.transcript); got != c.want {
+			t.Fatalf("extractDispatchLocation(%q) = %q, want %q", c.transcript, got, c.want)
+		}
+	}
+}
+
+func TestGeocodeQuery(t *testing.T) {
+	if got := geocodeQuery("12 MAIN ST", " Springfield, IL "); got != "12 MAIN ST, Springfield, IL" {
+		t.Fatalf("geocodeQuery = %q", got)
+	}
+	if got := geocodeQuery("12 MAIN ST", ""); got != "12 MAIN ST" {
+		t.Fatalf("geocodeQuery without hint = %q", got)
+	}
+}
+
+func TestNominatimGeocoder(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/search" || r.URL.Query().Get("q") != "12 MAIN ST, Springfield" || r.Header.Get("User-Agent") == "" {
+			t.Errorf("unexpected request %s", r.URL)
+		}
+		if r.URL.Query().Get("q") == "12 MAIN ST, Springfield" {
+			w.Write([]byte(`[{"lat":"39.7817","lon":"-89.6501"}]`))
+			return
+		}
+		w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+
+	geocoder, err := newGeocoder(GeocodingConfig{URL: server.URL})
+	if err != nil {
+		t.Fatalf("newGeocoder: %v", err)
+	}
+	location, err := geocoder.Geocode("12 MAIN ST, Springfield")
+	if err != nil || location == nil {
+		t.Fatalf("Geocode: %v %v", location, err)
+	}
+	if location.Latitude != 39.7817 || location.Longitude != -89.6501 {
+		t.Fatalf("location = %+v", location)
+	}
+}
+
+func TestGeocodingCache(t *testing.T) {
+	geocoding := NewGeocoding(nil)
+	geocoding.store("a", nil)
+	if location, ok := geocoding.cached("a"); !ok || location != nil {
+		t.Fatalf("expected a cached miss")
+	}
+	for i := 0; i < geocodeCacheSize+1; i++ {
+		geocoding.store(string(rune('b'+i)), &CallLocation{})
+	}
+	if _, ok := geocoding.cached("a"); ok || len(geocoding.cache) != geocodeCacheSize {
+		t.Fatalf("cache not bounded: %d entries", len(geocoding.cache))
+	}
+}
@@ -0,0 +1,993 @@
+// Copyright (C) 2026 Thinline Dynamic Solutions
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// A small read-only GraphQL implementation: queries with arguments,
+// variables, aliases and fragments. Mutations, subscriptions, directives and
+// introspection are not supported; the schema is served as SDL instead.
+
+const (
+	graphqlMaxDepth = 8
+	graphqlMaxCost  = 20000
+)
+
+type gqlError struct {
+	Message string `json:"message"`
+	Path    []any  `json:"path,omitempty"`
+}
+
+func (err *gqlError) Error() string {
+	return err.Message
+}
+
+func gqlErrorf(format string, a ...any) *gqlError {
+	return &gqlError{Message: fmt.Sprintf(format, a...)}
+}
+
+// Parsed documents
+
+type gqlDocument struct {
+	Operations []*gqlOperation
+	Fragments  map[string]*gqlFragment
+}
+
+type gqlOperation struct {
+	Kind       string // query, mutation or subscription
+	Name       string
+	Variables  []gqlVariableDef
+	Selections []gqlSelection
+}
+
+type gqlVariableDef struct {
+	Name       string
+	Default    any
+	HasDefault bool
+}
+
+type gqlFragment struct {
+	Name          string
+	TypeCondition string
+	Selections    []gqlSelection
+}
+
+// gqlSelection is a field, a fragment spread or an inline fragment.
+type gqlSelection struct {
+	Field  *gqlField
+	Spread string
+	Inline *gqlFragment
+}
+
+type gqlField struct {
+	Alias      string
+	Name       string
+	Args       map[string]any
+	Selections []gqlSelection
+}
+
+func (field *gqlField) key() string {
+	if field.Alias != "" {
+		return field.Alias
+	}
+	return field.Name
+}
+
+// Argument values hold variables and enum values as these types
+type gqlVariable string
+type gqlEnum string
+
+// Lexer
+
+type gqlTokenKind int
+
+const (
+	gqlEOF gqlTokenKind = iota
+	gqlPunct
+	gqlName
+	gqlInt
+	gqlFloat
+	gqlString
+)
+
+type gqlToken struct {
+	kind  gqlTokenKind
+	value string
+	pos   int
+}
+
+func gqlLex(source string) ([]gqlToken, error) {
+	tokens := []gqlToken{}
+	for i := 0; i < len(source); {
+		c := source[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == ',' || c == 0xEF || c == 0xBB || c == 0xBF:
+			i++
+		case c == '#':
+			for i < len(source) && source[i] != '\n' && source[i] != '\r' {
+				i++
+			}
+		case strings.HasPrefix(source[i:], "..."):
+			tokens = append(tokens, gqlToken{gqlPunct, "...", i})
+			i += 3
+		case strings.IndexByte("!$():=@[]{}|&", c) >= 0:
+			tokens = append(tokens, gqlToken{gqlPunct, string(c), i})
+			i++
+		case c == '_' || (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z'):
+			start := i
+			for i < len(source) && (source[i] == '_' || (source[i] >= 'A' && source[i] <= 'Z') || (source[i] >= 'a' && source[i] <= 'z') || (source[i] >= '0' && source[i] <= '9')) {
+				i++
+			}
+			tokens = append(tokens, gqlToken{gqlName, source[start:i], start})
+		case c == '-' || (c >= '0' && c <= '9'):
+			start := i
+			kind := gqlInt
+			i++
+			for i < len(source) {
+				d := source[i]
+				if d >= '0' && d <= '9' {
+					i++
+				} else if d == '.' || d == 'e' || d == 'E' || ((d == '+' || d == '-') && (source[i-1] == 'e' || source[i-1] == 'E')) {
+					kind = gqlFloat
+					i++
+				} else {
+					break
+				}
+			}
+			tokens = append(tokens, gqlToken{kind, source[start:i], start})
+		case c == '"':
+			start := i
+			if strings.HasPrefix(source[i:], `"""`) {
+				end := strings.Index(source[i+3:], `"""`)
+				if end < 0 {
+					return nil, gqlErrorf("unterminated string at %d", start)
+				}
+				tokens = append(tokens, gqlToken{gqlString, strings.TrimSpace(source[i+3 : i+3+end]), start})
+				i += end + 6
+				continue
+			}
+			i++
+			for i < len(source) && source[i] != '"' {
+				if source[i] == '\\' {
+					i++
+				}
+				if i < len(source) && (source[i] == '\n' || source[i] == '\r') {
+					return nil, gqlErrorf("unterminated string at %d", start)
+				}
+				i++
+			}
+			if i >= len(source) {
+				return nil, gqlErrorf("unterminated string at %d", start)
+			}
+			i++
+			var value string
+			if err := json.Unmarshal([]byte(source[start:i]), &value); err != nil {
+				return nil, gqlErrorf("invalid string at %d", start)
+			}
+			tokens = append(tokens, gqlToken{gqlString, value, start})
+		default:
+			return nil, gqlErrorf("unexpected character %q at %d", c, i)
+		}
+	}
+	return append(tokens, gqlToken{kind: gqlEOF, pos: len(source)}), nil
+}
+
+// Parser
+
+type gqlParser struct {
+	tokens []gqlToken
+	pos    int
+}
+
+func parseGraphQL(source string) (*gqlDocument, error) {
+	tokens, err := gqlLex(source)
+	if err != nil {
+		return nil, err
+	}
+	parser := &gqlParser{tokens: tokens}
+	document := &gqlDocument{Fragments: map[string]*gqlFragment{}}
+
+	for parser.peek().kind != gqlEOF {
+		token := parser.peek()
+		switch {
+		case token.kind == gqlPunct && token.value == "{":
+			selections, err := parser.selectionSet()
+			if err != nil {
+				return nil, err
+			}
+			document.Operations = append(document.Operations, &gqlOperation{Kind: "query", Selections: selections})
+		case token.kind == gqlName && (token.value == "query" || token.value == "mutation" || token.value == "subscription"):
+			operation, err := parser.operation()
+			if err != nil {
+				return nil, err
+			}
+			document.Operations = append(document.Operations, operation)
+		case token.kind == gqlName && token.value == "fragment":
+			fragment, err := parser.fragment()
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := document.Fragments[fragment.Name]; ok {
+				return nil, gqlErrorf("fragment %s is defined twice", fragment.Name)
+			}
+			document.Fragments[fragment.Name] = fragment
+		default:
+			return nil, parser.unexpected()
+		}
+	}
+
+	if len(document.Operations) == 0 {
+		return nil, gqlErrorf("the document has no operation")
+	}
+	return document, nil
+}
+
+func (parser *gqlParser) peek() gqlToken {
+	return parser.tokens[parser.pos]
+}
+
+func (parser *gqlParser) next() gqlToken {
+	token := parser.tokens[parser.pos]
+	if token.kind != gqlEOF {
+		parser.pos++
+	}
+	return token
+}
+
+func (parser *gqlParser) unexpected() error {
+	token := parser.peek()
+	if token.kind == gqlEOF {
+		return gqlErrorf("unexpected end of the document")
+	}
+	return gqlErrorf("unexpected %q at %d", token.value, token.pos)
+}
+
+func (parser *gqlParser) isPunct(value string) bool {
+	token := parser.peek()
+	return token.kind == gqlPunct && token.value == value
+}
+
+func (parser *gqlParser) expectPunct(value string) error {
+	if !parser.isPunct(value) {
+		return parser.unexpected()
+	}
+	parser.next()
+	return nil
+}
+
+func (parser *gqlParser) name() (string, error) {
+	if parser.peek().kind != gqlName {
+		return "", parser.unexpected()
+	}
+	return parser.next().value, nil
+}
+
+func (parser *gqlParser) noDirectives() error {
+	if parser.isPunct("@") {
+		return gqlErrorf("directives are not supported")
+	}
+	return nil
+}
+
+func (parser *gqlParser) operation() (*gqlOperation, error) {
+	operation := &gqlOperation{Kind: parser.next().value}
+	if parser.peek().kind == gqlName {
+		operation.Name = parser.next().value
+	}
+	if parser.isPunct("(") {
+		parser.next()
+		for !parser.isPunct(")") {
+			if err := parser.expectPunct("$"); err != nil {
+				return nil, err
+			}
+			name, err := parser.name()
+			if err != nil {
+				return nil, err
+			}
+			if err := parser.expectPunct(":"); err != nil {
+				return nil, err
+			}
+			if err := parser.skipType(); err != nil {
+				return nil, err
+			}
+			variable := gqlVariableDef{Name: name}
+			if parser.isPunct("=") {
+				parser.next()
+				if variable.Default, err = parser.value(true); err != nil {
+					return nil, err
+				}
+				variable.HasDefault = true
+			}
+			operation.Variables = append(operation.Variables, variable)
+		}
+		parser.next()
+	}
+	if err := parser.noDirectives(); err != nil {
+		return nil, err
+	}
+	selections, err := parser.selectionSet()
+	if err != nil {
+		return nil, err
+	}
+	operation.Selections = selections
+	return operation, nil
+}
+
+// skipType skips a variable type such as [Int!]!. Values are coerced to the
+// argument types instead.
+func (parser *gqlParser) skipType() error {
+	if parser.isPunct("[") {
+		parser.next()
+		if err := parser.skipType(); err != nil {
+			return err
+		}
+		if err := parser.expectPunct("]"); err != nil {
+			return err
+		}
+	} else if _, err := parser.name(); err != nil {
+		return err
+	}
+	if parser.isPunct("!") {
+		parser.next()
+	}
+	return nil
+}
+
+func (parser *gqlParser) fragment() (*gqlFragment, error) {
+	parser.next()
+	name, err := parser.name()
+	if err != nil {
+		return nil, err
+	}
+	if name == "on" {
+		return nil, gqlErrorf("a fragment can't be named on")
+	}
+	if on, err := parser.name(); err != nil || on != "on" {
+		return nil, gqlErrorf("fragment %s needs a type condition", name)
+	}
+	typeCondition, err := parser.name()
+	if err != nil {
+		return nil, err
+	}
+	if err := parser.noDirectives(); err != nil {
+		return nil, err
+	}
+	selections, err := parser.selectionSet()
+	if err != nil {
+		return nil, err
+	}
+	return &gqlFragment{Name: name, TypeCondition: typeCondition, Selections: selections}, nil
+}
+
+func (parser *gqlParser) selectionSet() ([]gqlSelection, error) {
+	if err := parser.expectPunct("{"); err != nil {
+		return nil, err
+	}
+	selections := []gqlSelection{}
+	for !parser.isPunct("}") {
+		if parser.peek().kind == gqlEOF {
+			return nil, parser.unexpected()
+		}
+		selection, err := parser.selection()
+		if err != nil {
+			return nil, err
+		}
+		selections = append(selections, selection)
+	}
+	parser.next()
+	if len(selections) == 0 {
+		return nil, gqlErrorf("empty selection set")
+	}
+	return selections, nil
+}
+
+func (parser *gqlParser) selection() (gqlSelection, error) {
+	if parser.isPunct("...") {
+		parser.next()
+		if token := parser.peek(); token.kind == gqlName && token.value != "on" {
+			parser.next()
+			return gqlSelection{Spread: token.value}, parser.noDirectives()
+		}
+		inline := &gqlFragment{}
+		if token := parser.peek(); token.kind == gqlName && token.value == "on" {
+			parser.next()
+			typeCondition, err := parser.name()
+			if err != nil {
+				return gqlSelection{}, err
+			}
+			inline.TypeCondition = typeCondition
+		}
+		if err := parser.noDirectives(); err != nil {
+			return gqlSelection{}, err
+		}
+		selections, err := parser.selectionSet()
+		if err != nil {
+			return gqlSelection{}, err
+		}
+		inline.Selections = selections
+		return gqlSelection{Inline: inline}, nil
+	}
+
+	name, err := parser.name()
+	if err != nil {
+		return gqlSelection{}, err
+	}
+	field := &gqlField{Name: name, Args: map[string]any{}}
+	if parser.isPunct(":") {
+		parser.next()
+		field.Alias = name
+		if field.Name, err = parser.name(); err != nil {
+			return gqlSelection{}, err
+		}
+	}
+	if parser.isPunct("(") {
+		parser.next()
+		for !parser.isPunct(")") {
+			arg, err := parser.name()
+			if err != nil {
+				return gqlSelection{}, err
+			}
+			if err := parser.expectPunct(":"); err != nil {
+				return gqlSelection{}, err
+			}
+			if field.Args[arg], err = parser.value(false); err != nil {
+				return gqlSelection{}, err
+			}
+		}
+		parser.next()
+	}
+	if err := parser.noDirectives(); err != nil {
+		return gqlSelection{}, err
+	}
+	if parser.isPunct("{") {
+		if field.Selections, err = parser.selectionSet(); err != nil {
+			return gqlSelection{}, err
+		}
+	}
+	return gqlSelection{Field: field}, nil
+}
+
+func (parser *gqlParser) value(constant bool) (any, error) {
+	token := parser.next()
+	switch token.kind {
+	case gqlInt:
+		n, err := strconv.ParseInt(token.value, 10, 64)
+		if err != nil {
+			return nil, gqlErrorf("invalid number %s", token.value)
+		}
+		return n, nil
+	case gqlFloat:
+		f, err := strconv.ParseFloat(token.value, 64)
+		if err != nil {
+			return nil, gqlErrorf("invalid number %s", token.value)
+		}
+		return f, nil
+	case gqlString:
+		return token.value, nil
+	case gqlName:
+		switch token.value {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		case "null":
+			return nil, nil
+		}
+		return gqlEnum(token.value), nil
+	case gqlPunct:
+		switch token.value {
+		case "$":
+			if constant {
+				return nil, gqlErrorf("variables are not allowed in default values")
+			}
+			name, err := parser.name()
+			if err != nil {
+				return nil, err
+			}
+			return gqlVariable(name), nil
+		case "[":
+			list := []any{}
+			for !parser.isPunct("]") {
+				if parser.peek().kind == gqlEOF {
+					return nil, parser.unexpected()
+				}
+				v, err := parser.value(constant)
+				if err != nil {
+					return nil, err
+				}
+				list = append(list, v)
+			}
+			parser.next()
+			return list, nil
+		case "{":
+			object := map[string]any{}
+			for !parser.isPunct("}") {
+				name, err := parser.name()
+				if err != nil {
+					return nil, err
+				}
+				if err := parser.expectPunct(":"); err != nil {
+					return nil, err
+				}
+				if object[name], err = parser.value(constant); err != nil {
+					return nil, err
+				}
+			}
+			parser.next()
+			return object, nil
+		}
+	}
+	parser.pos--
+	return nil, parser.unexpected()
+}
+
+// Schema
+
+type gqlSchema struct {
+	Query string
+	Types map[string]*gqlType
+}
+
+type gqlType struct {
+	Description string
+	Fields      map[string]*gqlFieldDef
+}
+
+type gqlFieldDef struct {
+	Type        string // a scalar or object type name
+	List        bool
+	Description string
+	Args        []gqlArgDef
+	// Size estimates the length of a list for the query cost. Lists
+	// without it count as 10.
+	Size    func(args map[string]any) int
+	Resolve func(ctx *graphqlContext, parent any, args map[string]any) (any, error)
+}
+
+type gqlArgDef struct {
+	Name        string
+	Type        string // Int, Float, String, Boolean, ID or Timestamp
+	Required    bool
+	Default     any
+	Description string
+}
+
+var gqlScalars = map[string]bool{"Int": true, "Float": true, "String": true, "Boolean": true, "ID": true, "Timestamp": true}
+
+// coerce converts an argument value to the Go type of the scalar: int64 for
+// Int and Timestamp, float64, string for String and ID, and bool.
+func gqlCoerce(scalar string, value any) (any, error) {
+	switch scalar {
+	case "Int", "Timestamp":
+		switch v := value.(type) {
+		case int64:
+			if scalar == "Int" && (v > math.MaxInt32 || v < math.MinInt32) {
+				return nil, fmt.Errorf("%d is out of range for Int", v)
+			}
+			return v, nil
+		case float64:
+			if v == math.Trunc(v) {
+				return gqlCoerce(scalar, int64(v))
+			}
+		case json.Number:
+			if n, err := v.Int64(); err == nil {
+				return gqlCoerce(scalar, n)
+			}
+		}
+	case "Float":
+		switch v := value.(type) {
+		case int64:
+			return float64(v), nil
+		case float64:
+			return v, nil
+		case json.Number:
+			return v.Float64()
+		}
+	case "String":
+		if v, ok := value.(string); ok {
+			return v, nil
+		}
+	case "ID":
+		switch v := value.(type) {
+		case string:
+			return v, nil
+		case int64:
+			return strconv.FormatInt(v, 10), nil
+		case float64:
+			if v == math.Trunc(v) {
+				return strconv.FormatInt(int64(v), 10), nil
+			}
+		case json.Number:
+			return v.String(), nil
+		}
+	case "Boolean":
+		if v, ok := value.(bool); ok {
+			return v, nil
+		}
+	}
+	return nil, fmt.Errorf("expected %s, got %v", scalar, value)
+}
+
+// gqlRequest is a query being validated or executed.
+type gqlRequest struct {
+	schema    *gqlSchema
+	document  *gqlDocument
+	operation *gqlOperation
+	variables map[string]any
+}
+
+func (schema *gqlSchema) prepare(document *gqlDocument, operationName string, variables map[string]any) (*gqlRequest, error) {
+	request := &gqlRequest{schema: schema, document: document, variables: map[string]any{}}
+
+	for _, operation := range document.Operations {
+		if operationName == "" || operation.Name == operationName {
+			if request.operation != nil {
+				return nil, gqlErrorf("operationName is required when the document has several operations")
+			}
+			request.operation = operation
+		}
+	}
+	if request.operation == nil {
+		return nil, gqlErrorf("unknown operation %s", operationName)
+	}
+	if request.operation.Kind != "query" {
+		return nil, gqlErrorf("only queries are supported, this endpoint is read-only")
+	}
+
+	for _, variable := range request.operation.Variables {
+		if v, ok := variables[variable.Name]; ok {
+			request.variables[variable.Name] = v
+		} else if variable.HasDefault {
+			request.variables[variable.Name] = variable.Default
+		}
+	}
+	return request, nil
+}
+
+// gqlCollected is the fields of one response key, merged from fragments.
+type gqlCollected struct {
+	key    string
+	fields []*gqlField
+}
+
+// collect flattens the fragments of a selection set into the fields of each
+// response key, in order.
+func (request *gqlRequest) collect(typeName string, selections []gqlSelection, visited map[string]bool) ([]*gqlCollected, error) {
+	collected := []*gqlCollected{}
+	byKey := map[string]*gqlCollected{}
+
+	var walk func(selections []gqlSelection) error
+	walk = func(selections []gqlSelection) error {
+		for _, selection := range selections {
+			switch {
+			case selection.Field != nil:
+				key := selection.Field.key()
+				entry, ok := byKey[key]
+				if !ok {
+					entry = &gqlCollected{key: key}
+					byKey[key] = entry
+					collected = append(collected, entry)
+				} else if entry.fields[0].Name != selection.Field.Name {
+					return gqlErrorf("%s selects both %s and %s", key, entry.fields[0].Name, selection.Field.Name)
+				}
+				entry.fields = append(entry.fields, selection.Field)
+			case selection.Spread != "":
+				fragment, ok := request.document.Fragments[selection.Spread]
+				if !ok {
+					return gqlErrorf("unknown fragment %s", selection.Spread)
+				}
+				if visited[fragment.Name] {
+					return gqlErrorf("fragment %s spreads itself", fragment.Name)
+				}
+				if fragment.TypeCondition != typeName {
+					if _, ok := request.schema.Types[fragment.TypeCondition]; !ok {
+						return gqlErrorf("unknown type %s", fragment.TypeCondition)
+					}
+					continue
+				}
+				visited[fragment.Name] = true
+				err := walk(fragment.Selections)
+				delete(visited, fragment.Name)
+				if err != nil {
+					return err
+				}
+			case selection.Inline != nil:
+				if condition := selection.Inline.TypeCondition; condition != "" && condition != typeName {
+					if _, ok := request.schema.Types[condition]; !ok {
+						return gqlErrorf("unknown type %s", condition)
+					}
+					continue
+				}
+				if err := walk(selection.Inline.Selections); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+
+	return collected, walk(selections)
+}
+
+// args coerces the arguments of a field, filling in variables and defaults.
+func (request *gqlRequest) args(typeName string, def *gqlFieldDef, field *gqlField) (map[string]any, error) {
+	for name := range field.Args {
+		known := false
+		for _, arg := range def.Args {
+			known = known || arg.Name == name
+		}
+		if !known {
+			return nil, gqlErrorf("unknown argument %s on %s.%s", name, typeName, field.Name)
+		}
+	}
+
+	args := map[string]any{}
+	for _, arg := range def.Args {
+		value, ok := field.Args[arg.Name]
+		if variable, isVariable := value.(gqlVariable); isVariable {
+			value, ok = request.variables[string(variable)]
+		}
+		if !ok || value == nil {
+			if arg.Required {
+				return nil, gqlErrorf("argument %s of %s.%s is required", arg.Name, typeName, field.Name)
+			}
+			if arg.Default != nil {
+				args[arg.Name] = arg.Default
+			}
+			continue
+		}
+		v, err := gqlCoerce(arg.Type, value)
+		if err != nil {
+			return nil, gqlErrorf("argument %s of %s.%s: %v", arg.Name, typeName, field.Name, err)
+		}
+		args[arg.Name] = v
+	}
+	return args, nil
+}
+
+// validate checks the operation against the schema and returns its cost:
+// one per field, with the fields below a list counted once per estimated
+// item.
+func (request *gqlRequest) validate() (int, error) {
+	var check func(typeName string, selections []gqlSelection, depth int) (int, error)
+	check = func(typeName string, selections []gqlSelection, depth int) (int, error) {
+		if depth > graphqlMaxDepth {
+			return 0, gqlErrorf("the query is nested deeper than %d levels", graphqlMaxDepth)
+		}
+		collected, err := request.collect(typeName, selections, map[string]bool{})
+		if err != nil {
+			return 0, err
+		}
+
+		cost := 0
+		for _, entry := range collected {
+			for _, field := range entry.fields {
+				if field.Name == "__typename" {
+					cost++
+					continue
+				}
+				def, ok := request.schema.Types[typeName].Fields[field.Name]
+				if !ok {
+					return 0, gqlErrorf("cannot query field %s on type %s", field.Name, typeName)
+				}
+				args, err := request.args(typeName, def, field)
+				if err != nil {
+					return 0, err
+				}
+
+				size := 1
+				if def.List {
+					size = 10
+					if def.Size != nil {
+						size = def.Size(args)
+					}
+				}
+
+				if gqlScalars[def.Type] {
+					if len(field.Selections) > 0 {
+						return 0, gqlErrorf("%s.%s is a %s and takes no selection", typeName, field.Name, def.Type)
+					}
+					cost += size
+					continue
+				}
+				if len(field.Selections) == 0 {
+					return 0, gqlErrorf("%s.%s needs a selection of %s fields", typeName, field.Name, def.Type)
+				}
+				children, err := check(def.Type, field.Selections, depth+1)
+				if err != nil {
+					return 0, err
+				}
+				cost += 1 + size*children
+				if cost > graphqlMaxCost {
+					return 0, gqlErrorf("the query is too complex, its cost is over %d", graphqlMaxCost)
+				}
+			}
+		}
+		return cost, nil
+	}
+
+	cost, err := check(request.schema.Query, request.operation.Selections, 1)
+	if err == nil && cost > graphqlMaxCost {
+		err = gqlErrorf("the query is too complex, its cost of %d is over %d", cost, graphqlMaxCost)
+	}
+	return cost, err
+}
+
+// gqlObject is a result object that keeps the field order of the query.
+type gqlObject struct {
+	keys   []string
+	values map[string]any
+}
+
+func (object *gqlObject) set(key string, value any) {
+	if object.values == nil {
+		object.values = map[string]any{}
+	}
+	if _, ok := object.values[key]; !ok {
+		object.keys = append(object.keys, key)
+	}
+	object.values[key] = value
+}
+
+func (object *gqlObject) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, key := range object.keys {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		k, _ := json.Marshal(key)
+		v, err := json.Marshal(object.values[key])
+		if err != nil {
+			return nil, err
+		}
+		b.Write(k)
+		b.WriteByte(':')
+		b.Write(v)
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
+
+// execute runs a validated request. Resolver errors null their field and
+// are reported with its path.
+func (request *gqlRequest) execute(ctx *graphqlContext) (*gqlObject, []*gqlError) {
+	errors := []*gqlError{}
+
+	var object func(typeName string, parent any, selections [][]gqlSelection, path []any) *gqlObject
+	var complete func(def *gqlFieldDef, value any, fields []*gqlField, path []any) any
+
+	complete = func(def *gqlFieldDef, value any, fields []*gqlField, path []any) any {
+		if value == nil {
+			return nil
+		}
+		if def.List {
+			items, ok := value.([]any)
+			if !ok {
+				errors = append(errors, &gqlError{Message: "internal error: expected a list", Path: path})
+				return nil
+			}
+			list := make([]any, len(items))
+			for i, item := range items {
+				list[i] = complete(&gqlFieldDef{Type: def.Type}, item, fields, append(append([]any{}, path...), i))
+			}
+			return list
+		}
+		if gqlScalars[def.Type] {
+			return value
+		}
+		selections := make([][]gqlSelection, len(fields))
+		for i, field := range fields {
+			selections[i] = field.Selections
+		}
+		return object(def.Type, value, selections, path)
+	}
+
+	object = func(typeName string, parent any, selections [][]gqlSelection, path []any) *gqlObject {
+		merged := []gqlSelection{}
+		for _, s := range selections {
+			merged = append(merged, s...)
+		}
+		collected, _ := request.collect(typeName, merged, map[string]bool{})
+
+		result := &gqlObject{}
+		for _, entry := range collected {
+			field := entry.fields[0]
+			fieldPath := append(append([]any{}, path...), entry.key)
+			if field.Name == "__typename" {
+				result.set(entry.key, typeName)
+				continue
+			}
+			def := request.schema.Types[typeName].Fields[field.Name]
+			args, _ := request.args(typeName, def, field)
+			value, err := def.Resolve(ctx, parent, args)
+			if err != nil {
+				errors = append(errors, &gqlError{Message: err.Error(), Path: fieldPath})
+				result.set(entry.key, nil)
+				continue
+			}
+			result.set(entry.key, complete(def, value, entry.fields, fieldPath))
+		}
+		return result
+	}
+
+	data := object(request.schema.Query, nil, [][]gqlSelection{request.operation.Selections}, nil)
+	return data, errors
+}
+
+// SDL returns the schema in the GraphQL schema definition language.
+func (schema *gqlSchema) SDL() string {
+	var b strings.Builder
+	b.WriteString("# Unix time in milliseconds\nscalar Timestamp\n\n")
+	b.WriteString(fmt.Sprintf("schema {\n  query: %s\n}\n", schema.Query))
+
+	typeNames := make([]string, 0, len(schema.Types))
+	for name := range schema.Types {
+		typeNames = append(typeNames, name)
+	}
+	sort.Strings(typeNames)
+
+	for _, typeName := range typeNames {
+		t := schema.Types[typeName]
+		b.WriteString("\n")
+		if t.Description != "" {
+			b.WriteString(fmt.Sprintf("%q\n", t.Description))
+		}
+		b.WriteString(fmt.Sprintf("type %s {\n", typeName))
+
+		fieldNames := make([]string, 0, len(t.Fields))
+		for name := range t.Fields {
+			fieldNames = append(fieldNames, name)
+		}
+		sort.Strings(fieldNames)
+
+		for _, fieldName := range fieldNames {
+			def := t.Fields[fieldName]
+			if def.Description != "" {
+				b.WriteString(fmt.Sprintf("  %q\n", def.Description))
+			}
+			b.WriteString("  " + fieldName)
+			if len(def.Args) > 0 {
+				args := make([]string, len(def.Args))
+				for i, arg := range def.Args {
+					args[i] = arg.Name + ": " + arg.Type
+					if arg.Required {
+						args[i] += "!"
+					}
+					if arg.Default != nil {
+						args[i] += fmt.Sprintf(" = %v", arg.Default)
+					}
+				}
+				b.WriteString("(" + strings.Join(args, ", ") + ")")
+			}
+			fieldType := def.Type
+			if def.List {
+				fieldType = "[" + fieldType + "!]"
+			}
+			b.WriteString(": " + fieldType + "\n")
+		}
+		b.WriteString("}\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,891 @@
+// Copyright (C) 2026 Thinline Dynamic Solutions
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>
+
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var graphqlLog = Logger(LogCategoryCalls)
+
+const graphqlMaxQueryBytes = 16 * 1024
+
+// graphqlContext is the client of a GraphQL request and the calls whose
+// details are read in one batch on first use.
+type graphqlContext struct {
+	api     *Api
+	client  *Client
+	now     time.Time
+	pending []*graphqlCall
+}
+
+type graphqlCall struct {
+	Id          uint64
+	System      *System
+	Talkgroup   *Talkgroup
+	Timestamp   int64
+	Transcript  string
+	Translation string
+	Headline    string
+	HasTones    bool
+	Rank        *float64
+
+	loaded    bool
+	frequency uint
+	tones     []any
+	toneSets  []any
+	units     []any
+}
+
+type graphqlTalkgroup struct {
+	System    *System
+	Talkgroup *Talkgroup
+}
+
+type graphqlUnit struct {
+	Ref   uint
+	Label string
+}
+
+type graphqlIncident struct {
+	*incidentSummary
+	transcripts bool
+}
+
+func (ctx *graphqlContext) newCall(call *graphqlCall) *graphqlCall {
+	ctx.pending = append(ctx.pending, call)
+	return call
+}
+
+// canHear tells if the client may hear calls of the talkgroup, with the same
+// rules as the REST API.
+func (ctx *graphqlContext) canHear(system *System, talkgroup *Talkgroup) bool {
+	if ctx.client.IsAdmin {
+		return true
+	}
+	return ctx.api.Controller.userHasAccess(ctx.client.User, &Call{System: system, Talkgroup: talkgroup, Timestamp: ctx.now})
+}
+
+func (ctx *graphqlContext) talkgroups(system *System) []*Talkgroup {
+	system.Talkgroups.mutex.Lock()
+	list := append([]*Talkgroup{}, system.Talkgroups.List...)
+	system.Talkgroups.mutex.Unlock()
+
+	talkgroups := []*Talkgroup{}
+	for _, talkgroup := range list {
+		if ctx.canHear(system, talkgroup) {
+			talkgroups = append(talkgroups, talkgroup)
+		}
+	}
+	return talkgroups
+}
+
+// systems returns the systems with at least one talkgroup the client can
+// hear.
+func (ctx *graphqlContext) systems() []*System {
+	ctx.api.Controller.Systems.mutex.RLock()
+	list := append([]*System{}, ctx.api.Controller.Systems.List...)
+	ctx.api.Controller.Systems.mutex.RUnlock()
+
+	systems := []*System{}
+	for _, system := range list {
+		if ctx.client.IsAdmin || len(ctx.talkgroups(system)) > 0 {
+			systems = append(systems, system)
+		}
+	}
+	return systems
+}
+
+// readCalls reads calls by id, in order, leaving out those the client can't
+// hear yet and the transcripts not released to it.
+func (ctx *graphqlContext) readCalls(ids []uint64) ([]*graphqlCall, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	args := queryArgs{}
+	rows, err := ctx.api.Controller.Database.Sql.Query(
+		`SELECT c."callId", c."systemId", c."talkgroupId", c."timestamp", c."transcript", c."transcriptTranslation", c."hasTones", d."callId" IS NOT NULL FROM "calls" c LEFT JOIN "delayed" AS d ON d."callId" = c."callId" WHERE c."callId" IN `+args.in(ids),
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	byId := map[uint64]*graphqlCall{}
+	for rows.Next() {
+		var (
+			call                    graphqlCall
+			systemId, talkgroupId   uint64
+			transcript, translation sql.NullString
+			delayed                 bool
+		)
+		if err := rows.Scan(&call.Id, &systemId, &talkgroupId, &call.Timestamp, &transcript, &translation, &call.HasTones, &delayed); err != nil {
+			return nil, err
+		}
+		system, ok := ctx.api.Controller.Systems.GetSystemById(systemId)
+		if !ok {
+			continue
+		}
+		talkgroup, ok := system.Talkgroups.GetTalkgroupById(talkgroupId)
+		if !ok {
+			continue
+		}
+		call.System, call.Talkgroup = system, talkgroup
+		call.Transcript, call.Translation = transcript.String, translation.String
+
+		if !ctx.client.IsAdmin {
+			c := &Call{Id: call.Id, Timestamp: time.UnixMilli(call.Timestamp), System: system, Talkgroup: talkgroup}
+			if delayed || !ctx.api.Controller.userHasAccess(ctx.client.User, c) {
+				continue
+			}
+			delay := ctx.api.Controller.Delayer.getEffectiveDelayForClient(c, ctx.client)
+			if c.Timestamp.Add(time.Duration(delay) * time.Minute).After(ctx.now) {
+				continue
+			}
+			if !ctx.api.transcriptReleasedForUser(ctx.client.User, c) {
+				call.Transcript, call.Translation = "", ""
+			}
+		}
+		byId[call.Id] = &call
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	calls := []*graphqlCall{}
+	for _, id := range ids {
+		if call, ok := byId[id]; ok {
+			calls = append(calls, ctx.newCall(call))
+		}
+	}
+	return calls, nil
+}
+
+// details reads the frequency, tones and units of all the calls resolved so
+// far on the first access to one of them.
+func (ctx *graphqlContext) details(call *graphqlCall) error {
+	if call.loaded {
+		return nil
+	}
+
+	byId := map[uint64]*graphqlCall{}
+	ids := []uint64{}
+	for _, c := range ctx.pending {
+		if !c.loaded {
+			c.loaded = true
+			c.tones, c.toneSets, c.units = []any{}, []any{}, []any{}
+			byId[c.Id] = c
+			ids = append(ids, c.Id)
+		}
+	}
+	ctx.pending = nil
+	if len(ids) == 0 {
+		return nil
+	}
+
+	db := ctx.api.Controller.Database.Sql
+	args := queryArgs{}
+	in := args.in(ids)
+	rows, err := db.Query(`SELECT "callId", "frequency", "toneSequence" FROM "calls" WHERE "callId" IN `+in, args...)
+	if err != nil {
+		return err
+	}
+	for rows.Next() {
+		var (
+			id           uint64
+			frequency    sql.NullInt64
+			toneSequence sql.NullString
+		)
+		if err := rows.Scan(&id, &frequency, &toneSequence); err != nil {
+			rows.Close()
+			return err
+		}
+		c := byId[id]
+		if c == nil {
+			continue
+		}
+		c.frequency = uint(frequency.Int64)
+		var sequence ToneSequence
+		if toneSequence.String != "" && json.Unmarshal([]byte(toneSequence.String), &sequence) == nil {
+			for _, tone := range sequence.Tones {
+				c.tones = append(c.tones, tone)
+			}
+			seen := map[string]bool{}
+			for _, toneSet := range append([]*ToneSet{sequence.MatchedToneSet}, sequence.MatchedToneSets...) {
+				if toneSet != nil && toneSet.Label != "" && !seen[toneSet.Label] {
+					seen[toneSet.Label] = true
+					c.toneSets = append(c.toneSets, toneSet.Label)
+				}
+			}
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	rows, err = db.Query(`SELECT "callId", "unitRef", COALESCE("label", '') FROM "callUnits" WHERE "callId" IN `+in+` ORDER BY "callId", "offset" ASC`, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			id   uint64
+			unit graphqlUnit
+		)
+		if err := rows.Scan(&id, &unit.Ref, &unit.Label); err != nil {
+			return err
+		}
+		c := byId[id]
+		if c == nil {
+			continue
+		}
+		if unit.Label == "" && c.System.Units != nil {
+			unit.Label = c.System.Units.Alias(unit.Ref)
+		}
+		c.units = append(c.units, unit)
+	}
+	return rows.Err()
+}
+
+// graphqlCallsFilter resolves the arguments of Query.calls to a search.
+func (ctx *graphqlContext) graphqlCallsFilter(args map[string]any) (CallSearchQuery, uint64, uint64, error) {
+	var systemId, talkgroupId uint64
+	q := CallSearchQuery{Limit: callSearchDefaultLimit}
+
+	if v, ok := args["first"].(int64); ok {
+		if v < 1 || v > callSearchMaxLimit {
+			return q, 0, 0, fmt.Errorf("first must be between 1 and %d", callSearchMaxLimit)
+		}
+		q.Limit = uint(v)
+	}
+	if v, ok := args["offset"].(int64); ok {
+		if v < 0 {
+			return q, 0, 0, fmt.Errorf("offset can't be negative")
+		}
+		q.Offset = uint(v)
+	}
+	if v, ok := args["text"].(string); ok {
+		q.Text = strings.TrimSpace(v)
+	}
+	if v, ok := args["toneSet"].(string); ok {
+		q.ToneSet = strings.TrimSpace(v)
+	}
+	if v, ok := args["tonesOnly"].(bool); ok {
+		q.TonesOnly = v
+	}
+	if v, ok := args["unit"].(int64); ok {
+		q.UnitRef = uint(v)
+	}
+	if v, ok := args["dateFrom"].(int64); ok {
+		q.DateFrom = v
+	}
+	if v, ok := args["dateTo"].(int64); ok {
+		q.DateTo = v
+	}
+
+	var system *System
+	if v, ok := args["systemRef"].(int64); ok {
+		if system, ok = ctx.api.Controller.Systems.GetSystemByRef(uint(v)); !ok {
+			return q, 0, 0, fmt.Errorf("unknown systemRef %d", v)
+		}
+		q.SystemRef, systemId = system.SystemRef, system.Id
+	}
+	if v, ok := args["talkgroupRef"].(int64); ok {
+		if system == nil {
+			return q, 0, 0, fmt.Errorf("talkgroupRef needs a systemRef")
+		}
+		talkgroup, ok := system.Talkgroups.GetTalkgroupByRef(uint(v))
+		if !ok {
+			return q, 0, 0, fmt.Errorf("unknown talkgroupRef %d", v)
+		}
+		q.TalkgroupRef, talkgroupId = talkgroup.TalkgroupRef, talkgroup.Id
+	}
+
+	tagLabel, _ := args["tag"].(string)
+	groupLabel, _ := args["group"].(string)
+	if tagLabel != "" || groupLabel != "" {
+		match, err := ctx.talkgroupMatcher(tagLabel, groupLabel)
+		if err != nil {
+			return q, 0, 0, err
+		}
+		q.TalkgroupIds = []uint64{}
+		for _, s := range ctx.systems() {
+			if system != nil && s != system {
+				continue
+			}
+			for _, talkgroup := range ctx.talkgroups(s) {
+				if match(talkgroup) {
+					q.TalkgroupIds = append(q.TalkgroupIds, talkgroup.Id)
+				}
+			}
+		}
+	}
+
+	return q, systemId, talkgroupId, nil
+}
+
+// talkgroupMatcher matches the talkgroups with a tag and in a group, given by
+// their labels. An empty label matches all.
+func (ctx *graphqlContext) talkgroupMatcher(tagLabel string, groupLabel string) (func(*Talkgroup) bool, error) {
+	var tag *Tag
+	var group *Group
+	if tagLabel != "" {
+		var ok bool
+		if tag, ok = ctx.api.Controller.Tags.GetTagByLabel(tagLabel); !ok {
+			return nil, fmt.Errorf("unknown tag %s", tagLabel)
+		}
+	}
+	if groupLabel != "" {
+		var ok bool
+		if group, ok = ctx.api.Controller.Groups.GetGroupByLabel(groupLabel); !ok {
+			return nil, fmt.Errorf("unknown group %s", groupLabel)
+		}
+	}
+
+	return func(talkgroup *Talkgroup) bool {
+		if tag != nil && talkgroup.TagId != tag.Id {
+			return false
+		}
+		if group != nil {
+			for _, id := range talkgroup.GroupIds {
+				if id == group.Id {
+					return true
+				}
+			}
+			return false
+		}
+		return true
+	}, nil
+}
+
+// readIncidents returns the incidents updated since a time that have calls
+// the client can hear, most recent first.
+func (ctx *graphqlContext) readIncidents(since int64, first int, incidentId uint64) ([]any, error) {
+	transcripts := ctx.client.IsAdmin || ctx.api.Controller.Billing.TranscriptsAllowed(ctx.client.User)
+	incidents := ctx.api.Controller.Incidents
+
+	query := `SELECT "incidentId", "title", "incidentType", "address", "startedAt", "updatedAt" FROM "incidents" WHERE "updatedAt" >= $1 ORDER BY "updatedAt" DESC LIMIT $2`
+	args := []any{since, incidentListMax}
+	if incidentId > 0 {
+		query = `SELECT "incidentId", "title", "incidentType", "address", "startedAt", "updatedAt" FROM "incidents" WHERE "incidentId" = $1`
+		args = []any{incidentId}
+	}
+	rows, err := ctx.api.Controller.Database.Sql.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	candidates := []*incidentSummary{}
+	for rows.Next() {
+		incident := &incidentSummary{}
+		if err := rows.Scan(&incident.Id, &incident.Title, &incident.IncidentType, &incident.Address, &incident.StartedAt, &incident.UpdatedAt); err != nil {
+			continue
+		}
+		candidates = append(candidates, incident)
+	}
+	rows.Close()
+
+	list := []any{}
+	for _, incident := range candidates {
+		if len(list) >= first {
+			break
+		}
+		calls, err := incidents.visibleCalls(incident.Id, ctx.client, transcripts)
+		if err != nil {
+			return nil, err
+		}
+		if len(calls) == 0 {
+			continue
+		}
+		incident.summarize(calls)
+		incident.Calls = calls
+		if !transcripts {
+			incident.Title, incident.Address, incident.IncidentType = "", "", ""
+		}
+		list = append(list, &graphqlIncident{incident, transcripts})
+	}
+	return list, nil
+}
+
+func gqlId(args map[string]any) (uint64, error) {
+	s, _ := args["id"].(string)
+	id, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid id %s", s)
+	}
+	return id, nil
+}
+
+func gqlFirst(fallback int) func(map[string]any) int {
+	return func(args map[string]any) int {
+		if v, ok := args["first"].(int64); ok && v > 0 {
+			return int(v)
+		}
+		return fallback
+	}
+}
+
+func gqlSize(n int) func(map[string]any) int {
+	return func(map[string]any) int { return n }
+}
+
+// gqlValue resolves a scalar field from its parent with get.
+func gqlValue[T any](get func(T) any) func(*graphqlContext, any, map[string]any) (any, error) {
+	return func(ctx *graphqlContext, parent any, args map[string]any) (any, error) {
+		return get(parent.(T)), nil
+	}
+}
+
+func gqlOptional(s string) any {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+// graphqlSchema is the read-only schema served at /api/graphql.
+var graphqlSchema = newGraphQLSchema()
+
+func newGraphQLSchema() *gqlSchema {
+	callDetail := func(get func(*graphqlCall) any) func(*graphqlContext, any, map[string]any) (any, error) {
+		return func(ctx *graphqlContext, parent any, args map[string]any) (any, error) {
+			call := parent.(*graphqlCall)
+			if err := ctx.details(call); err != nil {
+				graphqlLog.Error(fmt.Sprintf("graphql: %v", err))
+				return nil, fmt.Errorf("failed to read the call details")
+			}
+			return get(call), nil
+		}
+	}
+
+	return &gqlSchema{
+		Query: "Query",
+		Types: map[string]*gqlType{
+			"Query": {Fields: map[string]*gqlFieldDef{
+				"calls": {
+					Type: "Call", List: true, Size: gqlFirst(callSearchDefaultLimit),
+					Description: "Calls matching the filters, by relevance when there is a text, newest first otherwise",
+					Args: []gqlArgDef{
+						{Name: "text", Type: "String", Description: "Searched in transcripts, talkgroup and unit labels"},
+						{Name: "systemRef", Type: "Int"},
+						{Name: "talkgroupRef", Type: "Int"},
+						{Name: "tag", Type: "String"},
+						{Name: "group", Type: "String"},
+						{Name: "unit", Type: "Int"},
+						{Name: "dateFrom", Type: "Timestamp"},
+						{Name: "dateTo", Type: "Timestamp"},
+						{Name: "tonesOnly", Type: "Boolean"},
+						{Name: "toneSet", Type: "String"},
+						{Name: "first", Type: "Int", Default: int64(callSearchDefaultLimit)},
+						{Name: "offset", Type: "Int", Default: int64(0)},
+					},
+					Resolve: func(ctx *graphqlContext, parent any, args map[string]any) (any, error) {
+						q, systemId, talkgroupId, err := ctx.graphqlCallsFilter(args)
+						if err != nil {
+							return nil, err
+						}
+						hits, _, err := ctx.api.searchCalls(ctx.client, q, systemId, talkgroupId)
+						if err != nil {
+							graphqlLog.Error(fmt.Sprintf("graphql: %v", err))
+							return nil, fmt.Errorf("search failed")
+						}
+						calls := make([]any, len(hits))
+						for i, hit := range hits {
+							call := &graphqlCall{Id: hit.CallId, System: hit.System, Talkgroup: hit.Talkgroup, Timestamp: hit.Timestamp, Transcript: hit.Transcript, Translation: hit.Translation, HasTones: hit.HasTones}
+							if q.Text != "" {
+								rank := hit.Rank
+								call.Rank, call.Headline = &rank, hit.Headline
+							}
+							calls[i] = ctx.newCall(call)
+						}
+						return calls, nil
+					},
+				},
+				"call": {
+					Type: "Call", Args: []gqlArgDef{{Name: "id", Type: "ID", Required: true}},
+					Resolve: func(ctx *graphqlContext, parent any, args map[string]any) (any, error) {
+						id, err := gqlId(args)
+						if err != nil {
+							return nil, err
+						}
+						calls, err := ctx.readCalls([]uint64{id})
+						if err != nil {
+							graphqlLog.Error(fmt.Sprintf("graphql: %v", err))
+							return nil, fmt.Errorf("failed to read the call")
+						}
+						if len(calls) == 0 {
+							return nil, nil
+						}
+						return calls[0], nil
+					},
+				},
+				"systems": {
+					Type: "System", List: true, Size: gqlSize(10),
+					Resolve: func(ctx *graphqlContext, parent any, args map[string]any) (any, error) {
+						systems := []any{}
+						for _, system := range ctx.systems() {
+							systems = append(systems, system)
+						}
+						return systems, nil
+					},
+				},
+				"system": {
+					Type: "System", Args: []gqlArgDef{{Name: "ref", Type: "Int", Required: true}},
+					Resolve: func(ctx *graphqlContext, parent any, args map[string]any) (any, error) {
+						system, ok := ctx.api.Controller.Systems.GetSystemByRef(uint(args["ref"].(int64)))
+						if !ok || (!ctx.client.IsAdmin && len(ctx.talkgroups(system)) == 0) {
+							return nil, nil
+						}
+						return system, nil
+					},
+				},
+				"tags": {
+					Type: "Tag", List: true, Size: gqlSize(20),
+					Resolve: func(ctx *graphqlContext, parent any, args map[string]any) (any, error) {
+						tags := ctx.api.Controller.Tags
+						tags.mutex.RLock()
+						defer tags.mutex.RUnlock()
+						list := []any{}
+						for _, tag := range tags.List {
+							list = append(list, tag)
+						}
+						return list, nil
+					},
+				},
+				"groups": {
+					Type: "Group", List: true, Size: gqlSize(20),
+					Resolve: func(ctx *graphqlContext, parent any, args map[string]any) (any, error) {
+						groups := ctx.api.Controller.Groups
+						groups.mutex.RLock()
+						defer groups.mutex.RUnlock()
+						list := []any{}
+						for _, group := range groups.List {
+							list = append(list, group)
+						}
+						return list, nil
+					},
+				},
+				"incidents": {
+					Type: "Incident", List: true, Size: gqlFirst(incidentListDefault),
+					Description: "Incidents with calls the client can hear, most recently updated first",
+					Args: []gqlArgDef{
+						{Name: "since", Type: "Timestamp", Description: "Defaults to 24 hours ago"},
+						{Name: "first", Type: "Int", Default: int64(incidentListDefault)},
+					},
+					Resolve: func(ctx *graphqlContext, parent any, args map[string]any) (any, error) {
+						since := ctx.now.Add(-24 * time.Hour).UnixMilli()
+						if v, ok := args["since"].(int64); ok {
+							since = v
+						}
+						first := int(args["first"].(int64))
+						if first < 1 || first > incidentListMax {
+							return nil, fmt.Errorf("first must be between 1 and %d", incidentListMax)
+						}
+						incidents, err := ctx.readIncidents(since, first, 0)
+						if err != nil {
+							graphqlLog.Error(fmt.Sprintf("graphql: %v", err))
+							return nil, fmt.Errorf("failed to read incidents")
+						}
+						return incidents, nil
+					},
+				},
+				"incident": {
+					Type: "Incident", Args: []gqlArgDef{{Name: "id", Type: "ID", Required: true}},
+					Resolve: func(ctx *graphqlContext, parent any, args map[string]any) (any, error) {
+						id, err := gqlId(args)
+						if err != nil {
+							return nil, err
+						}
+						incidents, err := ctx.readIncidents(0, 1, id)
+						if err != nil {
+							graphqlLog.Error(fmt.Sprintf("graphql: %v", err))
+							return nil, fmt.Errorf("failed to read the incident")
+						}
+						if len(incidents) == 0 {
+							return nil, nil
+						}
+						return incidents[0], nil
+					},
+				},
+			}},
+
+			"Call": {Fields: map[string]*gqlFieldDef{
+				"id":        {Type: "ID", Resolve: gqlValue(func(c *graphqlCall) any { return strconv.FormatUint(c.Id, 10) })},
+				"timestamp": {Type: "Timestamp", Resolve: gqlValue(func(c *graphqlCall) any { return c.Timestamp })},
+				"dateTime": {Type: "String", Description: "RFC 3339 time of the call",
+					Resolve: gqlValue(func(c *graphqlCall) any { return time.UnixMilli(c.Timestamp).UTC().Format(time.RFC3339) })},
+				"system": {Type: "System", Resolve: gqlValue(func(c *graphqlCall) any { return c.System })},
+				"talkgroup": {Type: "Talkgroup", Resolve: gqlValue(func(c *graphqlCall) any {
+					return &graphqlTalkgroup{c.System, c.Talkgroup}
+				})},
+				"transcript":            {Type: "String", Resolve: gqlValue(func(c *graphqlCall) any { return gqlOptional(c.Transcript) })},
+				"transcriptTranslation": {Type: "String", Resolve: gqlValue(func(c *graphqlCall) any { return gqlOptional(c.Translation) })},
+				"headline": {Type: "String", Description: "Transcript excerpt around the searched text",
+					Resolve: gqlValue(func(c *graphqlCall) any { return gqlOptional(c.Headline) })},
+				"rank": {Type: "Float", Description: "Search relevance, when searching a text",
+					Resolve: gqlValue(func(c *graphqlCall) any {
+						if c.Rank == nil {
+							return nil
+						}
+						return *c.Rank
+					})},
+				"hasTones": {Type: "Boolean", Resolve: gqlValue(func(c *graphqlCall) any { return c.HasTones })},
+				"audioUrl": {Type: "String", Resolve: gqlValue(func(c *graphqlCall) any { return fmt.Sprintf("/api/calls/%d/audio", c.Id) })},
+				"frequency": {Type: "Int", Resolve: callDetail(func(c *graphqlCall) any {
+					if c.frequency == 0 {
+						return nil
+					}
+					return c.frequency
+				})},
+				"tones":    {Type: "Tone", List: true, Size: gqlSize(5), Resolve: callDetail(func(c *graphqlCall) any { return c.tones })},
+				"toneSets": {Type: "String", List: true, Size: gqlSize(3), Description: "Labels of the matched tone sets", Resolve: callDetail(func(c *graphqlCall) any { return c.toneSets })},
+				"units":    {Type: "Unit", List: true, Size: gqlSize(5), Resolve: callDetail(func(c *graphqlCall) any { return c.units })},
+			}},
+
+			"System": {Fields: map[string]*gqlFieldDef{
+				"ref":   {Type: "Int", Resolve: gqlValue(func(s *System) any { return s.SystemRef })},
+				"label": {Type: "String", Resolve: gqlValue(func(s *System) any { return s.Label })},
+				"talkgroups": {
+					Type: "Talkgroup", List: true, Size: gqlSize(100),
+					Description: "Talkgroups the client can hear",
+					Args:        []gqlArgDef{{Name: "tag", Type: "String"}, {Name: "group", Type: "String"}},
+					Resolve: func(ctx *graphqlContext, parent any, args map[string]any) (any, error) {
+						system := parent.(*System)
+						tag, _ := args["tag"].(string)
+						group, _ := args["group"].(string)
+						match, err := ctx.talkgroupMatcher(tag, group)
+						if err != nil {
+							return nil, err
+						}
+						list := []any{}
+						for _, talkgroup := range ctx.talkgroups(system) {
+							if match(talkgroup) {
+								list = append(list, &graphqlTalkgroup{system, talkgroup})
+							}
+						}
+						return list, nil
+					},
+				},
+				"units": {
+					Type: "Unit", List: true, Size: gqlSize(100),
+					Resolve: func(ctx *graphqlContext, parent any, args map[string]any) (any, error) {
+						system := parent.(*System)
+						list := []any{}
+						if system.Units == nil {
+							return list, nil
+						}
+						system.Units.mutex.Lock()
+						defer system.Units.mutex.Unlock()
+						for _, unit := range system.Units.List {
+							list = append(list, graphqlUnit{unit.UnitRef, unit.Label})
+						}
+						return list, nil
+					},
+				},
+			}},
+
+			"Talkgroup": {Fields: map[string]*gqlFieldDef{
+				"ref":   {Type: "Int", Resolve: gqlValue(func(t *graphqlTalkgroup) any { return t.Talkgroup.TalkgroupRef })},
+				"label": {Type: "String", Resolve: gqlValue(func(t *graphqlTalkgroup) any { return t.Talkgroup.Label })},
+				"name":  {Type: "String", Resolve: gqlValue(func(t *graphqlTalkgroup) any { return t.Talkgroup.Name })},
+				"frequency": {Type: "Int", Resolve: gqlValue(func(t *graphqlTalkgroup) any {
+					if t.Talkgroup.Frequency == 0 {
+						return nil
+					}
+					return t.Talkgroup.Frequency
+				})},
+				"system": {Type: "System", Resolve: gqlValue(func(t *graphqlTalkgroup) any { return t.System })},
+				"tag": {
+					Type: "Tag",
+					Resolve: func(ctx *graphqlContext, parent any, args map[string]any) (any, error) {
+						if tag, ok := ctx.api.Controller.Tags.GetTagById(parent.(*graphqlTalkgroup).Talkgroup.TagId); ok {
+							return tag, nil
+						}
+						return nil, nil
+					},
+				},
+				"groups": {
+					Type: "Group", List: true, Size: gqlSize(3),
+					Resolve: func(ctx *graphqlContext, parent any, args map[string]any) (any, error) {
+						list := []any{}
+						for _, id := range parent.(*graphqlTalkgroup).Talkgroup.GroupIds {
+							if group, ok := ctx.api.Controller.Groups.GetGroupById(id); ok {
+								list = append(list, group)
+							}
+						}
+						return list, nil
+					},
+				},
+			}},
+
+			"Unit": {Fields: map[string]*gqlFieldDef{
+				"ref":   {Type: "Int", Resolve: gqlValue(func(u graphqlUnit) any { return u.Ref })},
+				"label": {Type: "String", Resolve: gqlValue(func(u graphqlUnit) any { return gqlOptional(u.Label) })},
+			}},
+
+			"Tag": {Fields: map[string]*gqlFieldDef{
+				"id":    {Type: "ID", Resolve: gqlValue(func(t *Tag) any { return strconv.FormatUint(t.Id, 10) })},
+				"label": {Type: "String", Resolve: gqlValue(func(t *Tag) any { return t.Label })},
+				"color": {Type: "String", Resolve: gqlValue(func(t *Tag) any { return gqlOptional(t.Color) })},
+			}},
+
+			"Group": {Fields: map[string]*gqlFieldDef{
+				"id":    {Type: "ID", Resolve: gqlValue(func(g *Group) any { return strconv.FormatUint(g.Id, 10) })},
+				"label": {Type: "String", Resolve: gqlValue(func(g *Group) any { return g.Label })},
+			}},
+
+			"Tone": {Fields: map[string]*gqlFieldDef{
+				"frequency": {Type: "Float", Description: "Hz", Resolve: gqlValue(func(t Tone) any { return t.Frequency })},
+				"startTime": {Type: "Float", Description: "Seconds from the start of the call", Resolve: gqlValue(func(t Tone) any { return t.StartTime })},
+				"duration":  {Type: "Float", Description: "Seconds", Resolve: gqlValue(func(t Tone) any { return t.Duration })},
+				"type":      {Type: "String", Resolve: gqlValue(func(t Tone) any { return gqlOptional(t.ToneType) })},
+			}},
+
+			"Incident": {Fields: map[string]*gqlFieldDef{
+				"id":           {Type: "ID", Resolve: gqlValue(func(i *graphqlIncident) any { return strconv.FormatUint(i.Id, 10) })},
+				"title":        {Type: "String", Resolve: gqlValue(func(i *graphqlIncident) any { return gqlOptional(i.Title) })},
+				"incidentType": {Type: "String", Resolve: gqlValue(func(i *graphqlIncident) any { return gqlOptional(i.IncidentType) })},
+				"address":      {Type: "String", Resolve: gqlValue(func(i *graphqlIncident) any { return gqlOptional(i.Address) })},
+				"startedAt":    {Type: "Timestamp", Resolve: gqlValue(func(i *graphqlIncident) any { return i.StartedAt })},
+				"updatedAt":    {Type: "Timestamp", Resolve: gqlValue(func(i *graphqlIncident) any { return i.UpdatedAt })},
+				"callCount":    {Type: "Int", Resolve: gqlValue(func(i *graphqlIncident) any { return i.CallCount })},
+				"talkgroups": {Type: "String", List: true, Size: gqlSize(3), Description: "Labels of the talkgroups of the calls",
+					Resolve: gqlValue(func(i *graphqlIncident) any {
+						list := make([]any, len(i.Talkgroups))
+						for n, label := range i.Talkgroups {
+							list[n] = label
+						}
+						return list
+					})},
+				"calls": {
+					Type: "Call", List: true, Size: gqlSize(20),
+					Resolve: func(ctx *graphqlContext, parent any, args map[string]any) (any, error) {
+						incident := parent.(*graphqlIncident)
+						ids := make([]uint64, len(incident.Calls))
+						for n, call := range incident.Calls {
+							ids[n] = call.Id
+						}
+						calls, err := ctx.readCalls(ids)
+						if err != nil {
+							graphqlLog.Error(fmt.Sprintf("graphql: %v", err))
+							return nil, fmt.Errorf("failed to read the incident calls")
+						}
+						list := make([]any, len(calls))
+						for n, call := range calls {
+							list[n] = call
+						}
+						return list, nil
+					},
+				},
+			}},
+		},
+	}
+}
+
+type graphqlResponse struct {
+	Data   *gqlObject  `json:"data,omitempty"`
+	Errors []*gqlError `json:"errors,omitempty"`
+}
+
+func writeGraphQL(w http.ResponseWriter, status int, response graphqlResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(response)
+}
+
+// GraphQLHandler serves read-only GraphQL queries over calls, systems,
+// talkgroups, tags, units and incidents, with the access rules of the REST
+// API. GET without a query returns the schema.
+func (api *Api) GraphQLHandler(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Query         string         `json:"query"`
+		OperationName string         `json:"operationName"`
+		Variables     map[string]any `json:"variables"`
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		query := r.URL.Query()
+		if query.Get("query") == "" {
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+			w.Write([]byte(graphqlSchema.SDL()))
+			return
+		}
+		body.Query, body.OperationName = query.Get("query"), query.Get("operationName")
+		if v := query.Get("variables"); v != "" {
+			if err := json.Unmarshal([]byte(v), &body.Variables); err != nil {
+				writeGraphQL(w, http.StatusBadRequest, graphqlResponse{Errors: []*gqlError{{Message: "invalid variables"}}})
+				return
+			}
+		}
+	case http.MethodPost:
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 4*graphqlMaxQueryBytes)).Decode(&body); err != nil {
+			writeGraphQL(w, http.StatusBadRequest, graphqlResponse{Errors: []*gqlError{{Message: "invalid request body"}}})
+			return
+		}
+	default:
+		api.exitWithError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	client := api.getClient(r)
+	if client == nil || (!client.IsAdmin && client.User == nil) {
+		api.exitWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	if client.User != nil && client.User.PinExpired() {
+		api.exitWithError(w, http.StatusForbidden, "PIN expired")
+		return
+	}
+
+	if len(body.Query) > graphqlMaxQueryBytes {
+		writeGraphQL(w, http.StatusBadRequest, graphqlResponse{Errors: []*gqlError{gqlErrorf("the query is longer than %d bytes", graphqlMaxQueryBytes)}})
+		return
+	}
+
+	request, err := graphqlPrepare(body.Query, body.OperationName, body.Variables)
+	if err != nil {
+		writeGraphQL(w, http.StatusBadRequest, graphqlResponse{Errors: []*gqlError{{Message: err.Error()}}})
+		return
+	}
+
+	data, errors := request.execute(&graphqlContext{api: api, client: client, now: time.Now()})
+	writeGraphQL(w, http.StatusOK, graphqlResponse{Data: data, Errors: errors})
+}
+
+// graphqlPrepare parses and validates a query against the schema.
+func graphqlPrepare(query string, operationName string, variables map[string]any) (*gqlRequest, error) {
+	document, err := parseGraphQL(query)
+	if err != nil {
+		return nil, err
+	}
+	request, err := graphqlSchema.prepare(document, operationName, variables)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := request.validate(); err != nil {
+		return nil, err
+	}
+	return request, nil
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+type gqlTestItem struct {
+	Id    int64
+	Label string
+}
+
+func gqlTestSchema() *gqlSchema {
+	items := []any{}
+	for i := int64(1); i <= 3; i++ {
+		items = append(items, &gqlTestItem{i, fmt.Sprintf("item %d", i)})
+	}
+	return &gqlSchema{
+		Query: "Query",
+		Types: map[string]*gqlType{
+			"Query": {Fields: map[string]*gqlFieldDef{
+				"items": {
+					Type: "Item", List: true, Size: gqlFirst(10),
+					Args: []gqlArgDef{{Name: "first", Type: "Int", Default: int64(10)}},
+					Resolve: func(ctx *graphqlContext, parent any, args map[string]any) (any, error) {
+						first := int(args["first"].(int64))
+						if first > len(items) {
+							first = len(items)
+						}
+						return items[:first], nil
+					},
+				},
+				"item": {
+					Type: "Item", Args: []gqlArgDef{{Name: "id", Type: "ID", Required: true}},
+					Resolve: func(ctx *graphqlContext, parent any, args map[string]any) (any, error) {
+						if args["id"] == "404" {
+							return nil, fmt.Errorf("not found")
+						}
+						return items[0], nil
+					},
+				},
+			}},
+			"Item": {Fields: map[string]*gqlFieldDef{
+				"id":    {Type: "Int", Resolve: gqlValue(func(i *gqlTestItem) any { return i.Id })},
+				"label": {Type: "String", Resolve: gqlValue(func(i *gqlTestItem) any { return i.Label })},
+				"children": {
+					Type: "Item", List: true, Size: gqlSize(10),
+					Resolve: func(ctx *graphqlContext, parent any, args map[string]any) (any, error) {
+						return items, nil
+					},
+				},
+			}},
+		},
+	}
+}
+
+func gqlTestRun(t *testing.T, query string, variables map[string]any) (string, []*gqlError) {
+	t.Helper()
+	schema := gqlTestSchema()
+	document, err := parseGraphQL(query)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	request, err := schema.prepare(document, "", variables)
+	if err != nil {
+		t.Fatalf("prepare: %v", err)
+	}
+	if _, err := request.validate(); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+	data, errors := request.execute(&graphqlContext{})
+	b, err := json.Marshal(data)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	return string(b), errors
+}
+
+func TestGraphQLExecute(t *testing.T) {
+	got, errors := gqlTestRun(t, `
+		# aliases, arguments and __typename
+		{ first: items(first: 2) { id, label } one: item(id: 1) { __typename label } }`, nil)
+	want := `{"first":[{"id":1,"label":"item 1"},{"id":2,"label":"item 2"}],"one":{"__typename":"Item","label":"item 1"}}`
+	if got != want || len(errors) > 0 {
+		t.Fatalf("got %s %v, want %s", got, errors, want)
+	}
+}
+
+func TestGraphQLVariablesAndFragments(t *testing.T) {
+	got, _ := gqlTestRun(t, `
+		query Items($n: Int = 3, $id: ID!) {
+			items(first: $n) { ...fields }
+			item(id: $id) { ... on Item { id } label }
+		}
+		fragment fields on Item { id label }`, map[string]any{"n": float64(1), "id": "2"})
+	want := `{"items":[{"id":1,"label":"item 1"}],"item":{"id":1,"label":"item 1"}}`
+	if got != want {
+		t.Fatalf("got %s, want %s", got, want)
+	}
+}
+
+func TestGraphQLResolverErrors(t *testing.T) {
+	got, errors := gqlTestRun(t, `{ item(id: "404") { id } items(first: 1) { id } }`, nil)
+	if got != `{"item":null,"items":[{"id":1}]}` {
+		t.Fatalf("got %s", got)
+	}
+	if len(errors) != 1 || errors[0].Message != "not found" || len(errors[0].Path) != 1 || errors[0].Path[0] != "item" {
+		t.Fatalf("errors %+v", errors)
+	}
+}
+
+func TestGraphQLRejects(t *testing.T) {
+	schema := gqlTestSchema()
+	cases := map[string]string{
+		`mutation { items { id } }`:                               "read-only",
+		`{ items { id @skip(if: true) } }`:                        "directives",
+		`{ items { nope } }`:                                      "cannot query field nope",
+		`{ items }`:                                               "needs a selection",
+		`{ items { id { x } } }`:                                  "takes no selection",
+		`{ item { id } }`:                                         "is required",
+		`{ items(first: "a") { id } }`:                            "expected Int",
+		`{ items(last: 1) { id } }`:                               "unknown argument",
+		`{ items { ...missing } }`:                                "unknown fragment",
+		`{ items { ...a } } fragment a on Item { ...a }`:          "spreads itself",
+		`{ items(first: 1000) { children { children { id } } } }`: "too complex",
+		`{ items { children { children { children { children { children { children { children { id } } } } } } } } }`: "nested deeper",
+		`{ items { id }`: "unexpected end",
+	}
+	for query, want := range cases {
+		document, err := parseGraphQL(query)
+		if err == nil {
+			var request *gqlRequest
+			if request, err = schema.prepare(document, "", nil); err == nil {
+				_, err = request.validate()
+			}
+		}
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%s: got %v, want %q", query, err, want)
+		}
+	}
+}
+
+func TestGraphQLSchema(t *testing.T) {
+	sdl := graphqlSchema.SDL()
+	for _, want := range []string{"type Query {", "calls(text: String", "type Call {", "type Incident {", "scalar Timestamp"} {
+		if !strings.Contains(sdl, want) {
+			t.Errorf("schema has no %q", want)
+		}
+	}
+
+	// Every object field of the served schema refers to a known type
+	for typeName, t2 := range graphqlSchema.Types {
+		for fieldName, def := range t2.Fields {
+			if _, ok := graphqlSchema.Types[def.Type]; !ok && !gqlScalars[def.Type] {
+				t.Errorf("%s.%s has unknown type %s", typeName, fieldName, def.Type)
+			}
+		}
+	}
+
+	if _, err := graphqlPrepare(`{ calls(first: 200) { id units { ref } talkgroup { label tag { label } } } }`, "", nil); err != nil {
+		t.Fatalf("prepare: %v", err)
+	}
+	if _, err := graphqlPrepare(`{ systems { talkgroups { system { talkgroups { system { label } } } } } }`, "", nil); err == nil {
+		t.Fatalf("expected the cost limit to reject the query")
+	}
+}
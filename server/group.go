@@ -21,7 +21,6 @@ import (
 	"errors"
 	"fmt"
 	"sort"
-	"strings"
 	"sync"
 )
 
@@ -327,13 +326,11 @@ func (groups *Groups) Write(db *Database) error {
 	}
 
 	if len(groupIds) > 0 {
-		if b, err := json.Marshal(groupIds); err == nil {
-			in := strings.ReplaceAll(strings.ReplaceAll(string(b), "[", "("), "]", ")")
-			query = fmt.Sprintf(`DELETE FROM "groups" WHERE "groupId" IN %s`, in)
-			if _, err = tx.Exec(query); err != nil {
-				tx.Rollback()
-				return formatError(err, query)
-			}
+		args := queryArgs{}
+		query = `DELETE FROM "groups" WHERE "groupId" IN ` + args.in(groupIds)
+		if _, err = tx.Exec(query, args...); err != nil {
+			tx.Rollback()
+			return formatError(err, query)
 		}
 	}
 
@@ -342,14 +339,14 @@ func (groups *Groups) Write(db *Database) error {
 		var existingId uint64
 
 		if group.Id > 0 {
-			query = fmt.Sprintf(`SELECT COUNT(*) FROM "groups" WHERE "groupId" = %d`, group.Id)
-			if err = tx.QueryRow(query).Scan(&count); err != nil {
+			query = `SELECT COUNT(*) FROM "groups" WHERE "groupId" = $1`
+			if err = tx.QueryRow(query, group.Id).Scan(&count); err != nil {
 				break
 			}
 		} else {
 			// Check if a group with this label already exists (to prevent duplicates)
-			query = fmt.Sprintf(`SELECT "groupId" FROM "groups" WHERE "label" = '%s' LIMIT 1`, escapeQuotes(group.Label))
-			err = tx.QueryRow(query).Scan(&existingId)
+			query = `SELECT "groupId" FROM "groups" WHERE "label" = $1 LIMIT 1`
+			err = tx.QueryRow(query, group.Label).Scan(&existingId)
 			if err != nil && err != sql.ErrNoRows {
 				// Real error (not just "no rows")
 				break
@@ -364,21 +361,21 @@ func (groups *Groups) Write(db *Database) error {
 		if count == 0 {
 			if group.Id > 0 {
 				// Preserve the explicit ID when inserting.
-				query = fmt.Sprintf(`INSERT INTO "groups" ("groupId", "label", "order") VALUES (%d, '%s', %d)`, group.Id, escapeQuotes(group.Label), group.Order)
-				if _, err = tx.Exec(query); err != nil {
+				query = `INSERT INTO "groups" ("groupId", "label", "order") VALUES ($1, $2, $3)`
+				if _, err = tx.Exec(query, group.Id, group.Label, group.Order); err != nil {
 					break
 				}
 			} else {
 				// Let the database assign an auto-increment ID and immediately capture it
 				// so the in-memory group pointer gets the real Id before Write() returns.
 				// This closes the race window where another goroutine could read Id == 0.
-				query = fmt.Sprintf(`INSERT INTO "groups" ("label", "order") VALUES ('%s', %d)`, escapeQuotes(group.Label), group.Order)
+				query = `INSERT INTO "groups" ("label", "order") VALUES ($1, $2)`
 				if db.Config.DbType == DbTypePostgresql {
-					if err = tx.QueryRow(query + ` RETURNING "groupId"`).Scan(&group.Id); err != nil {
+					if err = tx.QueryRow(query+` RETURNING "groupId"`, group.Label, group.Order).Scan(&group.Id); err != nil {
 						break
 					}
 				} else {
-					if res, err = tx.Exec(query); err != nil {
+					if res, err = tx.Exec(query, group.Label, group.Order); err != nil {
 						break
 					}
 					if id, err2 := res.LastInsertId(); err2 == nil {
@@ -388,8 +385,8 @@ func (groups *Groups) Write(db *Database) error {
 			}
 
 		} else {
-			query = fmt.Sprintf(`UPDATE "groups" SET "label" = '%s', "order" = %d where "groupId" = %d`, escapeQuotes(group.Label), group.Order, group.Id)
-			if _, err = tx.Exec(query); err != nil {
+			query = `UPDATE "groups" SET "label" = $1, "order" = $2 where "groupId" = $3`
+			if _, err = tx.Exec(query, group.Label, group.Order, group.Id); err != nil {
 				break
 			}
 		}
@@ -0,0 +1,395 @@
+// Copyright (C) 2026 Thinline Dynamic Solutions
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>
+
+package main
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	guestGrantTokenPrefix = "guest_"
+	guestGrantMaxTTL      = 30 * 24 * time.Hour
+	guestGrantCallsLimit  = 100
+
+	// guestGrantHistory is how far before its creation a grant reaches, so
+	// the dispatch that prompted it can be heard.
+	guestGrantHistory = 24 * time.Hour
+)
+
+// GuestGrant gives someone without an account temporary access to the calls
+// of a few talkgroups. Only a hash of its token is stored; the token is
+// shown once, when the grant is created.
+type GuestGrant struct {
+	Id        uint64           `json:"id"`
+	Label     string           `json:"label"`
+	Scopes    []TalkgroupScope `json:"scopes"`
+	CreatedAt int64            `json:"createdAt"`
+	ExpiresAt int64            `json:"expiresAt"`
+
+	tokenHash string
+}
+
+// validate normalizes the grant and rejects grants without talkgroups.
+func (grant *GuestGrant) validate() error {
+	grant.Label = strings.TrimSpace(grant.Label)
+	if grant.Label == "" {
+		return fmt.Errorf("label is required")
+	}
+
+	return validateTalkgroupScopes(grant.Scopes)
+}
+
+// Allows reports whether the grant covers the talkgroup.
+func (grant *GuestGrant) Allows(system *System, talkgroup *Talkgroup) bool {
+	return grant != nil && talkgroupScopesAllow(grant.Scopes, system, talkgroup)
+}
+
+// Expired reports whether the grant has expired.
+func (grant *GuestGrant) Expired(now time.Time) bool {
+	return now.Unix() >= grant.ExpiresAt
+}
+
+func guestGrantTokenHash(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+type GuestGrants struct {
+	mutex  sync.RWMutex
+	grants map[uint64]*GuestGrant
+}
+
+func NewGuestGrants() *GuestGrants {
+	return &GuestGrants{
+		grants: map[uint64]*GuestGrant{},
+	}
+}
+
+func (grants *GuestGrants) Load(db *Database) error {
+	grants.mutex.Lock()
+	defer grants.mutex.Unlock()
+
+	rows, err := db.Sql.Query(`SELECT "guestGrantId", "tokenHash", "label", "scopes", "createdAt", "expiresAt" FROM "guestGrants"`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	grants.grants = map[uint64]*GuestGrant{}
+	for rows.Next() {
+		grant := &GuestGrant{}
+		var scopes string
+		if err := rows.Scan(&grant.Id, &grant.tokenHash, &grant.Label, &scopes, &grant.CreatedAt, &grant.ExpiresAt); err != nil {
+			continue
+		}
+		json.Unmarshal([]byte(scopes), &grant.Scopes)
+		grants.grants[grant.Id] = grant
+	}
+	return rows.Err()
+}
+
+// List returns the grants, newest first.
+func (grants *GuestGrants) List() []GuestGrant {
+	grants.mutex.RLock()
+	defer grants.mutex.RUnlock()
+
+	list := make([]GuestGrant, 0, len(grants.grants))
+	for _, grant := range grants.grants {
+		list = append(list, *grant)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Id > list[j].Id })
+	return list
+}
+
+// Get returns a copy of the grant, or nil.
+func (grants *GuestGrants) Get(id uint64) *GuestGrant {
+	grants.mutex.RLock()
+	defer grants.mutex.RUnlock()
+
+	if grant, ok := grants.grants[id]; ok {
+		copy := *grant
+		return &copy
+	}
+	return nil
+}
+
+// Find returns a copy of the unexpired grant of the token, or nil.
+func (grants *GuestGrants) Find(token string) *GuestGrant {
+	if grants == nil || !strings.HasPrefix(token, guestGrantTokenPrefix) {
+		return nil
+	}
+	hash := guestGrantTokenHash(token)
+
+	grants.mutex.RLock()
+	defer grants.mutex.RUnlock()
+
+	for _, grant := range grants.grants {
+		if grant.tokenHash == hash && !grant.Expired(time.Now()) {
+			copy := *grant
+			return &copy
+		}
+	}
+	return nil
+}
+
+// Create stores a new grant expiring after ttl and returns its token.
+func (grants *GuestGrants) Create(grant *GuestGrant, ttl time.Duration, db *Database) (string, error) {
+	if err := grant.validate(); err != nil {
+		return "", err
+	}
+	if ttl <= 0 || ttl > guestGrantMaxTTL {
+		return "", fmt.Errorf("guest access expires after %d hours at most", int(guestGrantMaxTTL.Hours()))
+	}
+
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	token := guestGrantTokenPrefix + hex.EncodeToString(buf)
+
+	now := time.Now()
+	grant.tokenHash = guestGrantTokenHash(token)
+	grant.CreatedAt = now.Unix()
+	grant.ExpiresAt = now.Add(ttl).Unix()
+	scopes, _ := json.Marshal(grant.Scopes)
+
+	if err := db.Sql.QueryRow(
+		`INSERT INTO "guestGrants" ("tokenHash", "label", "scopes", "createdAt", "expiresAt") VALUES ($1, $2, $3, $4, $5) RETURNING "guestGrantId"`,
+		grant.tokenHash, grant.Label, string(scopes), grant.CreatedAt, grant.ExpiresAt,
+	).Scan(&grant.Id); err != nil {
+		return "", err
+	}
+
+	grants.mutex.Lock()
+	stored := *grant
+	grants.grants[grant.Id] = &stored
+	grants.mutex.Unlock()
+
+	return token, nil
+}
+
+// Delete revokes the grant.
+func (grants *GuestGrants) Delete(id uint64, db *Database) error {
+	if _, err := db.Sql.Exec(`DELETE FROM "guestGrants" WHERE "guestGrantId" = $1`, id); err != nil {
+		return err
+	}
+
+	grants.mutex.Lock()
+	delete(grants.grants, id)
+	grants.mutex.Unlock()
+	return nil
+}
+
+// Prune drops the grants expired for more than a week, so they stay listed
+// for a while after they stop working.
+func (grants *GuestGrants) Prune(db *Database) error {
+	cutoff := time.Now().Add(-7 * 24 * time.Hour).Unix()
+	if _, err := db.Sql.Exec(`DELETE FROM "guestGrants" WHERE "expiresAt" < $1`, cutoff); err != nil {
+		return err
+	}
+
+	grants.mutex.Lock()
+	for id, grant := range grants.grants {
+		if grant.ExpiresAt < cutoff {
+			delete(grants.grants, id)
+		}
+	}
+	grants.mutex.Unlock()
+	return nil
+}
+
+// GuestGrantsHandler lists, creates and revokes guest access grants. It
+// needs the manage users permission.
+//
+// GET    /api/admin/guest-grants
+// POST   /api/admin/guest-grants {"label": "...", "hours": 48, "scopes": [{"systemRef": 1, "talkgroupRefs": [100]}]}
+// DELETE /api/admin/guest-grants?id=<id>
+func (admin *Admin) GuestGrantsHandler(w http.ResponseWriter, r *http.Request) {
+	t := admin.GetAuthorization(r)
+	if !admin.ValidatePermission(t, PermissionManageUsers) {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	writeError := func(status int, err error) {
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+	}
+
+	grants := admin.Controller.GuestGrants
+
+	switch r.Method {
+	case http.MethodGet:
+		list := []GuestGrant{}
+		for _, grant := range grants.List() {
+			if admin.tenantAllowsTalkgroupScopes(t, grant.Scopes) {
+				list = append(list, grant)
+			}
+		}
+		json.NewEncoder(w).Encode(map[string]any{"grants": list})
+
+	case http.MethodPost:
+		var req struct {
+			Label  string           `json:"label"`
+			Hours  uint             `json:"hours"`
+			Scopes []TalkgroupScope `json:"scopes"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(http.StatusBadRequest, fmt.Errorf("invalid grant: %v", err))
+			return
+		}
+		if req.Hours == 0 {
+			req.Hours = 24
+		}
+		grant := &GuestGrant{Label: req.Label, Scopes: req.Scopes}
+		if err := admin.checkTalkgroupScopes(t, grant.Scopes); err != nil {
+			writeError(http.StatusBadRequest, err)
+			return
+		}
+		token, err := grants.Create(grant, time.Duration(req.Hours)*time.Hour, admin.Controller.Database)
+		if err != nil {
+			writeError(http.StatusBadRequest, err)
+			return
+		}
+		admin.Controller.Logs.LogEvent(LogLevelInfo, fmt.Sprintf("admin: guest access %q granted until %s", grant.Label, time.Unix(grant.ExpiresAt, 0).Format(time.RFC3339)))
+		json.NewEncoder(w).Encode(map[string]any{"grant": grant, "token": token})
+
+	case http.MethodDelete:
+		id, err := strconv.ParseUint(r.URL.Query().Get("id"), 10, 64)
+		if err != nil || id == 0 {
+			writeError(http.StatusBadRequest, fmt.Errorf("invalid grant id"))
+			return
+		}
+		grant := grants.Get(id)
+		if grant == nil || !admin.tenantAllowsTalkgroupScopes(t, grant.Scopes) {
+			writeError(http.StatusNotFound, fmt.Errorf("grant not found"))
+			return
+		}
+		if err := grants.Delete(id, admin.Controller.Database); err != nil {
+			writeError(http.StatusInternalServerError, err)
+			return
+		}
+		admin.Controller.Logs.LogEvent(LogLevelInfo, fmt.Sprintf("admin: guest access %q revoked", grant.Label))
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// guestGrant returns the grant of the token in ?token= or the Authorization
+// header, or nil.
+func (api *Api) guestGrant(r *http.Request) *GuestGrant {
+	token := r.URL.Query().Get("token")
+	if token == "" {
+		token = strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	}
+	return api.Controller.GuestGrants.Find(token)
+}
+
+// guestCallReleased reports whether the call has passed the delay of its
+// talkgroup or system, which guests are held to.
+func (api *Api) guestCallReleased(call *Call, now time.Time) bool {
+	delay := api.Controller.Delayer.getSystemDelay(call)
+	return !call.Timestamp.Add(time.Duration(delay) * time.Minute).After(now)
+}
+
+// GuestCallsHandler lists and plays the calls of a guest grant.
+//
+// GET /api/guest/calls?token=<token>[&before=<call id>]
+// GET /api/guest/calls/{id}/audio?token=<token>
+func (api *Api) GuestCallsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	grant := api.guestGrant(r)
+	if grant == nil {
+		api.exitWithError(w, http.StatusUnauthorized, "Invalid or expired guest access")
+		return
+	}
+
+	path := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/guest/calls"), "/")
+	if path == "" {
+		api.guestCalls(w, r, grant)
+		return
+	}
+
+	parts := strings.Split(path, "/")
+	callId, err := strconv.ParseUint(parts[0], 10, 64)
+	if len(parts) != 2 || parts[1] != "audio" || err != nil {
+		api.exitWithError(w, http.StatusBadRequest, "Invalid path — expected /api/guest/calls/{id}/audio")
+		return
+	}
+
+	call, err := api.Controller.Calls.GetCall(callId)
+	if err != nil || call == nil || len(call.Audio) == 0 || !grant.Allows(call.System, call.Talkgroup) || call.Timestamp.Before(time.Unix(grant.CreatedAt, 0).Add(-guestGrantHistory)) {
+		api.exitWithError(w, http.StatusNotFound, "Call audio not found")
+		return
+	}
+	if api.Controller.callDelayed(callId) || !api.guestCallReleased(call, time.Now()) {
+		api.exitWithError(w, http.StatusForbidden, "Call is still delayed")
+		return
+	}
+
+	writeCallAudio(w, r, call)
+}
+
+// guestCalls answers with the latest calls of the grant, newest first.
+func (api *Api) guestCalls(w http.ResponseWriter, r *http.Request, grant *GuestGrant) {
+	before, _ := strconv.ParseUint(r.URL.Query().Get("before"), 10, 64)
+	now := time.Now()
+	since := time.Unix(grant.CreatedAt, 0).Add(-guestGrantHistory)
+
+	list, err := api.Controller.readScopedCalls(grant.Scopes, since, before, guestGrantCallsLimit, func(call *Call) bool {
+		return api.guestCallReleased(call, now)
+	})
+	if err != nil {
+		api.exitWithError(w, http.StatusInternalServerError, "Failed to read calls")
+		return
+	}
+
+	token := r.URL.Query().Get("token")
+	calls := []map[string]any{}
+	for _, call := range list {
+		audioUrl := fmt.Sprintf("/api/guest/calls/%d/audio", call.Id)
+		if token != "" {
+			audioUrl += "?token=" + token
+		}
+		calls = append(calls, scopedCallResponse(call, audioUrl))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(map[string]any{
+		"label":     grant.Label,
+		"expiresAt": grant.ExpiresAt,
+		"calls":     calls,
+	})
+}
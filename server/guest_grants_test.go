@@ -0,0 +1,61 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGuestGrantValidate(t *testing.T) {
+	for _, grant := range []GuestGrant{
+		{Label: " ", Scopes: []TalkgroupScope{{SystemRef: 1, TalkgroupRefs: []uint{100}}}},
+		{Label: "Reporter"},
+		{Label: "Reporter", Scopes: []TalkgroupScope{{SystemRef: 1}}},
+		{Label: "Reporter", Scopes: []TalkgroupScope{{TalkgroupRefs: []uint{100}}}},
+	} {
+		if err := grant.validate(); err == nil {
+			t.Fatalf("grant %+v should be invalid", grant)
+		}
+	}
+
+	grant := GuestGrant{Label: " Reporter ", Scopes: []TalkgroupScope{{SystemRef: 1, TalkgroupRefs: []uint{100}}}}
+	if err := grant.validate(); err != nil || grant.Label != "Reporter" {
+		t.Fatalf("validate = %v, label %q", err, grant.Label)
+	}
+}
+
+func TestGuestGrantAllows(t *testing.T) {
+	grant := &GuestGrant{Scopes: []TalkgroupScope{{SystemRef: 1, TalkgroupRefs: []uint{100, 101}}}}
+	system := &System{SystemRef: 1}
+	other := &System{SystemRef: 2}
+
+	if !grant.Allows(system, &Talkgroup{TalkgroupRef: 101}) {
+		t.Fatal("talkgroup of the grant should be allowed")
+	}
+	if grant.Allows(system, &Talkgroup{TalkgroupRef: 102}) || grant.Allows(other, &Talkgroup{TalkgroupRef: 100}) {
+		t.Fatal("talkgroups outside the grant should be denied")
+	}
+	if grant.Allows(system, nil) || (*GuestGrant)(nil).Allows(system, &Talkgroup{TalkgroupRef: 100}) {
+		t.Fatal("missing talkgroup or grant should be denied")
+	}
+}
+
+func TestGuestGrantsFind(t *testing.T) {
+	token := guestGrantTokenPrefix + "abc"
+	grants := NewGuestGrants()
+	grants.grants[1] = &GuestGrant{Id: 1, Label: "Active", tokenHash: guestGrantTokenHash(token), ExpiresAt: time.Now().Add(time.Hour).Unix()}
+	grants.grants[2] = &GuestGrant{Id: 2, Label: "Expired", tokenHash: guestGrantTokenHash(token + "2"), ExpiresAt: time.Now().Add(-time.Hour).Unix()}
+
+	if grant := grants.Find(token); grant == nil || grant.Id != 1 {
+		t.Fatalf("Find = %+v, want grant 1", grant)
+	}
+	if grants.Find(token+"2") != nil {
+		t.Fatal("expired grant should not be found")
+	}
+	if grants.Find(strings.TrimPrefix(token, guestGrantTokenPrefix)) != nil || grants.Find("") != nil {
+		t.Fatal("tokens without the guest prefix should not be found")
+	}
+	if list := grants.List(); len(list) != 2 || list[0].Id != 2 {
+		t.Fatalf("List = %+v, want newest first", list)
+	}
+}
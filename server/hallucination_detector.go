@@ -274,10 +274,9 @@ func (hd *HallucinationDetector) getOrCreatePhrase(phrase string, systemId uint6
 
 	// Insert into database
 	systemIdsJson, _ := json.Marshal(sh.SystemIds)
-	query := fmt.Sprintf(`INSERT INTO "suspectedHallucinations" ("phrase", "rejectedCount", "acceptedCount", "firstSeenAt", "lastSeenAt", "systemIds", "status", "autoAdded", "createdAt", "updatedAt") VALUES ($1, %d, %d, %d, %d, $2, '%s', %t, %d, %d) RETURNING "id"`,
-		sh.RejectedCount, sh.AcceptedCount, sh.FirstSeenAt, sh.LastSeenAt, sh.Status, sh.AutoAdded, sh.CreatedAt, sh.UpdatedAt)
+	query := `INSERT INTO "suspectedHallucinations" ("phrase", "rejectedCount", "acceptedCount", "firstSeenAt", "lastSeenAt", "systemIds", "status", "autoAdded", "createdAt", "updatedAt") VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING "id"`
 
-	err = hd.controller.Database.Sql.QueryRow(query, phrase, string(systemIdsJson)).Scan(&sh.Id)
+	err = hd.controller.Database.Sql.QueryRow(query, phrase, sh.RejectedCount, sh.AcceptedCount, sh.FirstSeenAt, sh.LastSeenAt, string(systemIdsJson), sh.Status, sh.AutoAdded, sh.CreatedAt, sh.UpdatedAt).Scan(&sh.Id)
 
 	if err != nil {
 		return nil, err
@@ -322,10 +321,9 @@ func (hd *HallucinationDetector) getPhrase(phrase string) (*SuspectedHallucinati
 func (hd *HallucinationDetector) savePhrase(sh *SuspectedHallucination) error {
 	systemIdsJson, _ := json.Marshal(sh.SystemIds)
 
-	query := fmt.Sprintf(`UPDATE "suspectedHallucinations" SET "rejectedCount" = %d, "acceptedCount" = %d, "lastSeenAt" = %d, "systemIds" = $1, "status" = '%s', "autoAdded" = %t, "updatedAt" = %d WHERE "id" = %d`,
-		sh.RejectedCount, sh.AcceptedCount, sh.LastSeenAt, escapeQuotes(sh.Status), sh.AutoAdded, sh.UpdatedAt, sh.Id)
+	query := `UPDATE "suspectedHallucinations" SET "rejectedCount" = $1, "acceptedCount" = $2, "lastSeenAt" = $3, "systemIds" = $4, "status" = $5, "autoAdded" = $6, "updatedAt" = $7 WHERE "id" = $8`
 
-	_, err := hd.controller.Database.Sql.Exec(query, string(systemIdsJson))
+	_, err := hd.controller.Database.Sql.Exec(query, sh.RejectedCount, sh.AcceptedCount, sh.LastSeenAt, string(systemIdsJson), sh.Status, sh.AutoAdded, sh.UpdatedAt, sh.Id)
 	return err
 }
 
@@ -408,8 +406,8 @@ func (hd *HallucinationDetector) ApproveHallucination(id uint64) error {
 	sh.UpdatedAt = time.Now().UnixMilli()
 
 	// Save to database
-	query = fmt.Sprintf(`UPDATE "suspectedHallucinations" SET "status" = 'approved', "updatedAt" = %d WHERE "id" = %d`, sh.UpdatedAt, sh.Id)
-	if _, err := hd.controller.Database.Sql.Exec(query); err != nil {
+	query = `UPDATE "suspectedHallucinations" SET "status" = 'approved', "updatedAt" = $1 WHERE "id" = $2`
+	if _, err := hd.controller.Database.Sql.Exec(query, sh.UpdatedAt, sh.Id); err != nil {
 		return err
 	}
 
@@ -428,8 +426,8 @@ func (hd *HallucinationDetector) RejectHallucination(id uint64) error {
 	hd.mutex.Lock()
 	defer hd.mutex.Unlock()
 
-	query := fmt.Sprintf(`UPDATE "suspectedHallucinations" SET "status" = 'rejected', "updatedAt" = %d WHERE "id" = %d`, time.Now().UnixMilli(), id)
-	_, err := hd.controller.Database.Sql.Exec(query)
+	query := `UPDATE "suspectedHallucinations" SET "status" = 'rejected', "updatedAt" = $1 WHERE "id" = $2`
+	_, err := hd.controller.Database.Sql.Exec(query, time.Now().UnixMilli(), id)
 	return err
 }
 
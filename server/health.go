@@ -238,6 +238,14 @@ func (hs *HealthService) gather() (map[string]interface{}, bool) {
 	payload["transcription_provider"] = opts.TranscriptionConfig.Provider
 	if ctrl.TranscriptionQueue != nil {
 		payload["transcription_queue_depth"] = ctrl.TranscriptionQueue.QueueDepth()
+		if provider := ctrl.TranscriptionQueue.provider; provider != nil {
+			payload["transcription_provider_available"] = provider.IsAvailable()
+			if local, ok := provider.(*WhisperLocalTranscription); ok {
+				if err := local.Check(); err != nil {
+					payload["transcription_provider_error"] = err.Error()
+				}
+			}
+		}
 	}
 
 	var memStats runtime.MemStats
@@ -298,6 +306,17 @@ func (hs *HealthService) gather() (map[string]interface{}, bool) {
 	payload["hydra_transcription_enabled"] = opts.HydraTranscriptionEnabled
 	payload["hydra_api_key_present"] = strings.TrimSpace(opts.HydraAPIKey) != ""
 
+	payload["heartbeat_enabled"] = opts.HeartbeatConfig.Enabled && opts.HeartbeatConfig.URL != ""
+	if ctrl.Heartbeat != nil {
+		lastSent, lastError := ctrl.Heartbeat.Status()
+		if !lastSent.IsZero() {
+			payload["heartbeat_last_sent_at"] = lastSent.UTC().Format(time.RFC3339)
+		}
+		if lastError != "" {
+			payload["heartbeat_error"] = lastError
+		}
+	}
+
 	payload["reasons"] = reasons
 	if ready {
 		payload["status"] = "ok"
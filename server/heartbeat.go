@@ -0,0 +1,166 @@
+// Copyright (C) 2026 Thinline Dynamic Solutions
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	heartbeatDefaultInterval = 60 * time.Second
+	heartbeatMinInterval     = 10 * time.Second
+	heartbeatTimeout         = 10 * time.Second
+)
+
+// heartbeatInterval returns the configured interval, clamped to the minimum.
+func heartbeatInterval(config HeartbeatConfig) time.Duration {
+	if config.IntervalSeconds == 0 {
+		return heartbeatDefaultInterval
+	}
+	if interval := time.Duration(config.IntervalSeconds) * time.Second; interval > heartbeatMinInterval {
+		return interval
+	}
+	return heartbeatMinInterval
+}
+
+// heartbeatTarget returns the URL to ping for the current health. Monitors
+// such as healthchecks.io take a separate failure URL; without one the
+// regular URL is pinged and the payload status tells the state.
+func heartbeatTarget(config HeartbeatConfig, ready bool) string {
+	if !ready && config.FailURL != "" {
+		return config.FailURL
+	}
+	return config.URL
+}
+
+// Heartbeat pings a monitoring URL on an interval with the /api/health
+// payload, so network monitoring (SolarWinds, PRTG, healthchecks.io, Uptime
+// Kuma...) notices both a degraded server and one that stopped reporting.
+type Heartbeat struct {
+	controller *Controller
+	client     *http.Client
+	stopChan   chan struct{}
+
+	mutex     sync.Mutex
+	lastSent  time.Time
+	lastError string
+}
+
+func NewHeartbeat(controller *Controller) *Heartbeat {
+	return &Heartbeat{
+		controller: controller,
+		client:     &http.Client{Timeout: heartbeatTimeout},
+		stopChan:   make(chan struct{}),
+	}
+}
+
+// Start runs the heartbeat loop. The configuration is read on every beat so
+// changes in the admin UI apply without a restart.
+func (heartbeat *Heartbeat) Start() {
+	go func() {
+		timer := time.NewTimer(heartbeatMinInterval)
+		defer timer.Stop()
+		for {
+			select {
+			case <-timer.C:
+				config := heartbeat.controller.Options.HeartbeatConfig
+				if config.Enabled && config.URL != "" {
+					heartbeat.beat(config)
+				}
+				timer.Reset(heartbeatInterval(config))
+			case <-heartbeat.stopChan:
+				return
+			}
+		}
+	}()
+}
+
+// Stop signals the background goroutine to exit.
+func (heartbeat *Heartbeat) Stop() {
+	select {
+	case <-heartbeat.stopChan:
+	default:
+		close(heartbeat.stopChan)
+	}
+}
+
+// beat sends one heartbeat and logs when delivery starts or stops failing.
+func (heartbeat *Heartbeat) beat(config HeartbeatConfig) {
+	body, code := heartbeat.controller.Health.renderCached()
+	err := heartbeat.send(config, body, code == http.StatusOK)
+
+	heartbeat.mutex.Lock()
+	previous := heartbeat.lastError
+	if err != nil {
+		heartbeat.lastError = err.Error()
+	} else {
+		heartbeat.lastError = ""
+		heartbeat.lastSent = time.Now()
+	}
+	heartbeat.mutex.Unlock()
+
+	switch {
+	case err != nil && previous == "":
+		heartbeat.controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("heartbeat: delivery failed: %v", err))
+	case err == nil && previous != "":
+		heartbeat.controller.Logs.LogEvent(LogLevelInfo, "heartbeat: delivery restored")
+	}
+}
+
+func (heartbeat *Heartbeat) send(config HeartbeatConfig, body []byte, ready bool) error {
+	method := http.MethodPost
+	if strings.EqualFold(config.Method, http.MethodGet) {
+		method = http.MethodGet
+		body = nil
+	}
+
+	req, err := http.NewRequest(method, heartbeatTarget(config, ready), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("User-Agent", "ThinLine-Radio/"+Version)
+	if method == http.MethodPost {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if config.AuthHeader != "" {
+		req.Header.Set("Authorization", config.AuthHeader)
+	}
+
+	resp, err := heartbeat.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("%s returned status %d", req.URL.Host, resp.StatusCode)
+	}
+	return nil
+}
+
+// Status reports the last successful beat and the current delivery error.
+func (heartbeat *Heartbeat) Status() (time.Time, string) {
+	heartbeat.mutex.Lock()
+	defer heartbeat.mutex.Unlock()
+	return heartbeat.lastSent, heartbeat.lastError
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHeartbeatInterval(t *testing.T) {
+	if interval := heartbeatInterval(HeartbeatConfig{}); interval != heartbeatDefaultInterval {
+		t.Fatalf("expected the default interval, got %s", interval)
+	}
+	if interval := heartbeatInterval(HeartbeatConfig{IntervalSeconds: 2}); interval != heartbeatMinInterval {
+		t.Fatalf("expected the minimum interval, got %s", interval)
+	}
+	if interval := heartbeatInterval(HeartbeatConfig{IntervalSeconds: 300}); interval != 5*time.Minute {
+		t.Fatalf("expected 5m, got %s", interval)
+	}
+}
+
+func TestHeartbeatTarget(t *testing.T) {
+	config := HeartbeatConfig{URL: "https://hc-ping.com/abc"}
+	if heartbeatTarget(config, false) != config.URL {
+		t.Fatalf("expected the regular URL without a failure URL")
+	}
+	config.FailURL = "https://hc-ping.com/abc/fail"
+	if heartbeatTarget(config, true) != config.URL || heartbeatTarget(config, false) != config.FailURL {
+		t.Fatalf("unexpected targets")
+	}
+}
+
+func TestHeartbeatSend(t *testing.T) {
+	var method, auth, body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		method, auth, body = r.Method, r.Header.Get("Authorization"), string(b)
+		if r.URL.Path == "/down" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	heartbeat := NewHeartbeat(&Controller{})
+	config := HeartbeatConfig{URL: server.URL + "/ping", AuthHeader: "Bearer token"}
+
+	if err := heartbeat.send(config, []byte(`{"status":"ok"}`), true); err != nil {
+		t.Fatalf("send failed: %v", err)
+	}
+	if method != http.MethodPost || auth != "Bearer token" || body != `{"status":"ok"}` {
+		t.Fatalf("unexpected request %s %q %q", method, auth, body)
+	}
+
+	config.Method = "get"
+	if err := heartbeat.send(config, []byte(`{"status":"ok"}`), true); err != nil {
+		t.Fatalf("send failed: %v", err)
+	}
+	if method != http.MethodGet || body != "" {
+		t.Fatalf("unexpected request %s %q", method, body)
+	}
+
+	config.URL = server.URL + "/down"
+	if err := heartbeat.send(config, nil, true); err == nil {
+		t.Fatalf("expected an error on a 404")
+	}
+}
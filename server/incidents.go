@@ -0,0 +1,632 @@
+// Copyright (C) 2026 Thinline Dynamic Solutions
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var incidentsLog = Logger(LogCategoryCalls)
+
+const (
+	incidentDefaultWindow   = 30 * time.Minute
+	incidentDefaultMinScore = 0.5
+	incidentRecentJoin      = 2 * time.Minute
+	incidentListDefault     = 50
+	incidentListMax         = 200
+	incidentMaxTokens       = 200
+)
+
+// IncidentConfig groups related transcribed calls into incidents, so a
+// structure fire can be followed across dispatch, fireground and EMS
+// talkgroups.
+type IncidentConfig struct {
+	Enabled       bool    `json:"enabled"`
+	WindowMinutes uint    `json:"windowMinutes"` // an incident closes after this long without calls (default 30)
+	MinScore      float64 `json:"minScore"`      // score a call needs to join an incident (default 0.5)
+}
+
+func (config IncidentConfig) window() time.Duration {
+	if config.WindowMinutes == 0 {
+		return incidentDefaultWindow
+	}
+	return time.Duration(config.WindowMinutes) * time.Minute
+}
+
+func (config IncidentConfig) minScore() float64 {
+	if config.MinScore <= 0 {
+		return incidentDefaultMinScore
+	}
+	return config.MinScore
+}
+
+// incidentCall is what correlation knows about a call.
+type incidentCall struct {
+	Id          uint64
+	SystemId    uint64
+	TalkgroupId uint64
+	GroupIds    []uint64
+	TagId       uint64
+	Timestamp   time.Time
+	HasTones    bool
+	ToneSetIds  []string
+	Address     string
+	Units       []string
+	Tokens      map[string]bool
+}
+
+// incidentState is an open incident. Its terms grow with every call joined.
+// Incidents from CAD also carry the tone sets of the talkgroups dispatched.
+type incidentState struct {
+	Id         uint64
+	CadId      string
+	Address    string
+	StartedAt  time.Time
+	UpdatedAt  time.Time
+	CallCount  int
+	Talkgroups map[uint64]bool
+	GroupIds   map[uint64]bool
+	TagIds     map[uint64]bool
+	Units      map[string]bool
+	Tokens     map[string]bool
+	ToneSetIds map[string]bool
+}
+
+var incidentTokenPattern = regexp.MustCompile(`[A-Z0-9]+`)
+
+// incidentStopwords are frequent radio words that say nothing about which
+// incident a call belongs to.
+var incidentStopwords = map[string]bool{
+	"THAT": true, "THIS": true, "WITH": true, "FROM": true, "HAVE": true, "WILL": true,
+	"COPY": true, "CLEAR": true, "RESPOND": true, "RESPONDING": true, "ENROUTE": true,
+	"ROUTE": true, "SCENE": true, "UNIT": true, "UNITS": true, "THANK": true, "THANKS": true,
+	"OKAY": true, "RECEIVED": true, "AFFIRMATIVE": true, "NEGATIVE": true, "STANDBY": true,
+	"GO": true, "AHEAD": true, "THEY": true, "THERE": true, "WHAT": true, "YOUR": true,
+	"JUST": true, "BACK": true, "BEEN": true, "ALSO": true, "ABOUT": true, "GOING": true,
+}
+
+// incidentTokens returns the distinctive terms of a transcript: numbers, and
+// words of four letters or more that are not common radio words.
+func incidentTokens(transcript string) map[string]bool {
+	tokens := map[string]bool{}
+	for _, token := range incidentTokenPattern.FindAllString(strings.ToUpper(transcript), -1) {
+		if incidentStopwords[token] {
+			continue
+		}
+		if len(token) >= 4 || strings.ContainsAny(token, "0123456789") {
+			tokens[token] = true
+		}
+	}
+	return tokens
+}
+
+// newIncidentCall reads the correlation inputs of a transcribed call.
+func newIncidentCall(call *Call) *incidentCall {
+	c := &incidentCall{
+		Id:        call.Id,
+		Timestamp: call.Timestamp,
+		HasTones:  call.HasTones,
+		Tokens:    incidentTokens(call.Transcript),
+	}
+	if call.System != nil {
+		c.SystemId = call.System.Id
+	}
+	if call.Talkgroup != nil {
+		c.TalkgroupId = call.Talkgroup.Id
+		c.GroupIds = call.Talkgroup.GroupIds
+		c.TagId = call.Talkgroup.TagId
+	}
+	if call.ToneSequence != nil {
+		seen := map[string]bool{}
+		toneSets := call.ToneSequence.MatchedToneSets
+		if call.ToneSequence.MatchedToneSet != nil {
+			toneSets = append([]*ToneSet{call.ToneSequence.MatchedToneSet}, toneSets...)
+		}
+		for _, toneSet := range toneSets {
+			if toneSet != nil && toneSet.Id != "" && !seen[toneSet.Id] {
+				seen[toneSet.Id] = true
+				c.ToneSetIds = append(c.ToneSetIds, toneSet.Id)
+			}
+		}
+	}
+	if call.SummaryFields != nil {
+		c.Address = call.SummaryFields.Address
+		c.Units = call.SummaryFields.Units
+	}
+	if c.Address == "" {
+		c.Address = extractDispatchLocation(call.Transcript)
+	}
+	return c
+}
+
+// startsIncident reports whether a call that matches no incident opens one:
+// dispatch tones or an address. Other traffic only joins incidents.
+func (c *incidentCall) startsIncident() bool {
+	return c.HasTones || c.Address != ""
+}
+
+// incidentScore rates how likely call belongs to incident and names the
+// signals that matched. A call after the window, or one with tones or an
+// address of its own that do not match the incident, never joins it. Tones
+// of a tone set the incident was dispatched to do match.
+func incidentScore(incident *incidentState, c *incidentCall, window time.Duration) (float64, []string) {
+	elapsed := c.Timestamp.Sub(incident.UpdatedAt)
+	if elapsed < 0 {
+		elapsed = 0
+	}
+	if elapsed > window {
+		return 0, nil
+	}
+
+	addressMatch := c.Address != "" && incident.Address != "" && c.Address == incident.Address
+	toneMatch := false
+	for _, id := range c.ToneSetIds {
+		if incident.ToneSetIds[id] {
+			toneMatch = true
+			break
+		}
+	}
+	if !addressMatch && !toneMatch && (c.HasTones || (c.Address != "" && incident.Address != "")) {
+		return 0, nil
+	}
+
+	score := 0.0
+	reasons := []string{}
+	if addressMatch {
+		score += 0.6
+		reasons = append(reasons, "address")
+	}
+	if toneMatch {
+		score += 0.6
+		reasons = append(reasons, "tones")
+	}
+	if incident.Talkgroups[c.TalkgroupId] {
+		score += 0.35
+		reasons = append(reasons, "talkgroup")
+		if elapsed <= incidentRecentJoin {
+			score += 0.2
+		}
+	} else if (c.TagId != 0 && incident.TagIds[c.TagId]) || sharesAny(incident.GroupIds, c.GroupIds) {
+		score += 0.15
+		reasons = append(reasons, "group")
+	}
+	for _, unit := range c.Units {
+		if incident.Units[unit] {
+			score += 0.3
+			reasons = append(reasons, "units")
+			break
+		}
+	}
+
+	shared := 0
+	for token := range c.Tokens {
+		if incident.Tokens[token] {
+			shared++
+		}
+	}
+	if smallest := len(c.Tokens); shared >= 2 && smallest > 0 {
+		if len(incident.Tokens) < smallest {
+			smallest = len(incident.Tokens)
+		}
+		score += 0.7 * float64(shared) / float64(smallest)
+		reasons = append(reasons, "transcript")
+	}
+
+	// Older incidents need stronger evidence
+	score *= 1 - 0.5*float64(elapsed)/float64(window)
+	return score, reasons
+}
+
+func sharesAny(set map[uint64]bool, ids []uint64) bool {
+	for _, id := range ids {
+		if set[id] {
+			return true
+		}
+	}
+	return false
+}
+
+// add records a call joined to incident.
+func (incident *incidentState) add(c *incidentCall) {
+	incident.CallCount++
+	if c.Timestamp.After(incident.UpdatedAt) {
+		incident.UpdatedAt = c.Timestamp
+	}
+	if incident.Address == "" {
+		incident.Address = c.Address
+	}
+	incident.Talkgroups[c.TalkgroupId] = true
+	for _, id := range c.GroupIds {
+		incident.GroupIds[id] = true
+	}
+	if c.TagId != 0 {
+		incident.TagIds[c.TagId] = true
+	}
+	for _, unit := range c.Units {
+		incident.Units[unit] = true
+	}
+	for token := range c.Tokens {
+		if len(incident.Tokens) >= incidentMaxTokens {
+			break
+		}
+		incident.Tokens[token] = true
+	}
+}
+
+func newIncidentState(id uint64, c *incidentCall) *incidentState {
+	incident := emptyIncidentState(id, c.Timestamp)
+	incident.add(c)
+	return incident
+}
+
+func emptyIncidentState(id uint64, startedAt time.Time) *incidentState {
+	return &incidentState{
+		Id:         id,
+		StartedAt:  startedAt,
+		UpdatedAt:  startedAt,
+		Talkgroups: map[uint64]bool{},
+		GroupIds:   map[uint64]bool{},
+		TagIds:     map[uint64]bool{},
+		Units:      map[string]bool{},
+		Tokens:     map[string]bool{},
+		ToneSetIds: map[string]bool{},
+	}
+}
+
+// Incidents correlates transcribed calls into incidents. Open incidents are
+// kept in memory; the incidents and their calls are stored in the database.
+type Incidents struct {
+	controller *Controller
+	mutex      sync.Mutex
+	open       map[uint64]*incidentState
+}
+
+func NewIncidents(controller *Controller) *Incidents {
+	return &Incidents{
+		controller: controller,
+		open:       map[uint64]*incidentState{},
+	}
+}
+
+// match returns the open incident call belongs to, if any, and closes the
+// incidents that have been quiet for longer than the window.
+func (incidents *Incidents) match(c *incidentCall, config IncidentConfig) (*incidentState, float64, []string) {
+	window := config.window()
+	var best *incidentState
+	var bestScore float64
+	var bestReasons []string
+	for id, incident := range incidents.open {
+		if c.Timestamp.Sub(incident.UpdatedAt) > window {
+			delete(incidents.open, id)
+			continue
+		}
+		score, reasons := incidentScore(incident, c, window)
+		if score >= config.minScore() && score > bestScore {
+			best, bestScore, bestReasons = incident, score, reasons
+		}
+	}
+	return best, bestScore, bestReasons
+}
+
+// Observe correlates a transcribed call, joining it to an open incident or
+// opening a new one.
+func (incidents *Incidents) Observe(call *Call) {
+	if incidents == nil || call == nil || call.Id == 0 {
+		return
+	}
+	config := incidents.controller.Options.IncidentConfig
+	if !config.Enabled {
+		return
+	}
+
+	c := newIncidentCall(call)
+
+	incidents.mutex.Lock()
+	defer incidents.mutex.Unlock()
+
+	db := incidents.controller.Database.Sql
+	incident, score, reasons := incidents.match(c, config)
+	if incident == nil {
+		if !c.startsIncident() {
+			return
+		}
+
+		title := strings.TrimSpace(call.AlertSummary)
+		if title == "" && call.Talkgroup != nil {
+			title = call.Talkgroup.Label
+		}
+		incidentType := ""
+		if call.SummaryFields != nil {
+			incidentType = call.SummaryFields.IncidentType
+		}
+
+		var id uint64
+		if err := db.QueryRow(
+			`INSERT INTO "incidents" ("systemId", "talkgroupId", "title", "incidentType", "address", "startedAt", "updatedAt", "callCount") VALUES ($1, $2, $3, $4, $5, $6, $6, 1) RETURNING "incidentId"`,
+			c.SystemId, c.TalkgroupId, title, incidentType, c.Address, c.Timestamp.UnixMilli(),
+		).Scan(&id); err != nil {
+			incidents.controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("failed to open an incident for call %d: %v", call.Id, err))
+			return
+		}
+		incident = newIncidentState(id, c)
+		incidents.open[id] = incident
+		score, reasons = 1, []string{"start"}
+	} else {
+		incident.add(c)
+		if _, err := db.Exec(
+			`UPDATE "incidents" SET "updatedAt" = $1, "callCount" = $2, "address" = $3 WHERE "incidentId" = $4`,
+			incident.UpdatedAt.UnixMilli(), incident.CallCount, incident.Address, incident.Id,
+		); err != nil {
+			incidents.controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("failed to update incident %d: %v", incident.Id, err))
+		}
+	}
+
+	if _, err := db.Exec(
+		`INSERT INTO "incidentCalls" ("incidentId", "callId", "score", "reason") VALUES ($1, $2, $3, $4) ON CONFLICT DO NOTHING`,
+		incident.Id, call.Id, score, strings.Join(reasons, ","),
+	); err != nil {
+		incidents.controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("failed to add call %d to incident %d: %v", call.Id, incident.Id, err))
+	}
+}
+
+// incidentTimelineCall is one call of an incident timeline.
+type incidentTimelineCall struct {
+	Id             uint64  `json:"id"`
+	System         uint    `json:"system"`
+	SystemLabel    string  `json:"systemLabel"`
+	Talkgroup      uint    `json:"talkgroup"`
+	TalkgroupLabel string  `json:"talkgroupLabel"`
+	TalkgroupName  string  `json:"talkgroupName"`
+	Timestamp      int64   `json:"timestamp"`
+	Transcript     string  `json:"transcript,omitempty"`
+	AlertSummary   string  `json:"alertSummary,omitempty"`
+	Score          float64 `json:"score"`
+	Reason         string  `json:"reason"`
+	AudioUrl       string  `json:"audioUrl"`
+}
+
+// incidentSummary is an incident as listed by the API.
+type incidentSummary struct {
+	Id           uint64                  `json:"id"`
+	Title        string                  `json:"title"`
+	IncidentType string                  `json:"incidentType,omitempty"`
+	Address      string                  `json:"address,omitempty"`
+	StartedAt    int64                   `json:"startedAt"`
+	UpdatedAt    int64                   `json:"updatedAt"`
+	CallCount    int                     `json:"callCount"`
+	Talkgroups   []string                `json:"talkgroups"`
+	CAD          *CADIncident            `json:"cad,omitempty"`
+	Calls        []*incidentTimelineCall `json:"calls,omitempty"`
+}
+
+// visibleCalls returns the calls of an incident the client can hear now, in
+// time order.
+func (incidents *Incidents) visibleCalls(incidentId uint64, client *Client, transcripts bool) ([]*incidentTimelineCall, error) {
+	rows, err := incidents.controller.Database.Sql.Query(
+		`SELECT c."callId", c."systemId", c."talkgroupId", c."timestamp", c."transcript", c."alertSummary", ic."score", ic."reason" FROM "incidentCalls" AS ic JOIN "calls" AS c ON c."callId" = ic."callId" WHERE ic."incidentId" = $1 ORDER BY c."timestamp", c."callId"`,
+		incidentId,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	now := time.Now()
+	calls := []*incidentTimelineCall{}
+	for rows.Next() {
+		var (
+			item                     incidentTimelineCall
+			systemId, talkgroupId    uint64
+			transcript, alertSummary *string
+		)
+		if err := rows.Scan(&item.Id, &systemId, &talkgroupId, &item.Timestamp, &transcript, &alertSummary, &item.Score, &item.Reason); err != nil {
+			return nil, err
+		}
+		system, ok := incidents.controller.Systems.GetSystemById(systemId)
+		if !ok {
+			continue
+		}
+		talkgroup, ok := system.Talkgroups.GetTalkgroupById(talkgroupId)
+		if !ok {
+			continue
+		}
+
+		call := &Call{Id: item.Id, Timestamp: time.UnixMilli(item.Timestamp), System: system, Talkgroup: talkgroup}
+		if !client.IsAdmin {
+			if !incidents.controller.userHasAccess(client.User, call) {
+				continue
+			}
+			// Calls still delayed for the client are left out until released
+			delay := incidents.controller.Delayer.getEffectiveDelayForClient(call, client)
+			if call.Timestamp.Add(time.Duration(delay) * time.Minute).After(now) {
+				continue
+			}
+		}
+
+		item.System = system.SystemRef
+		item.SystemLabel = system.Label
+		item.Talkgroup = talkgroup.TalkgroupRef
+		item.TalkgroupLabel = talkgroup.Label
+		item.TalkgroupName = talkgroup.Name
+		item.AudioUrl = fmt.Sprintf("/api/calls/%d/audio", item.Id)
+		if transcripts {
+			if transcript != nil {
+				item.Transcript = *transcript
+			}
+			if alertSummary != nil {
+				item.AlertSummary = *alertSummary
+			}
+		}
+		calls = append(calls, &item)
+	}
+	return calls, rows.Err()
+}
+
+// summarize fills the fields of incident that depend on the visible calls.
+func (incident *incidentSummary) summarize(calls []*incidentTimelineCall) {
+	incident.CallCount = len(calls)
+	incident.Talkgroups = []string{}
+	seen := map[string]bool{}
+	for _, call := range calls {
+		label := call.TalkgroupLabel
+		if !seen[label] {
+			seen[label] = true
+			incident.Talkgroups = append(incident.Talkgroups, label)
+		}
+	}
+	sort.Strings(incident.Talkgroups)
+}
+
+// IncidentsHandler serves GET /api/incidents, the recent incidents the user
+// can hear, and GET /api/incidents/{id}, the timeline of one incident.
+func (api *Api) IncidentsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		api.exitWithError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	client := api.getClient(r)
+	if client == nil || (!client.IsAdmin && client.User == nil) {
+		api.exitWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	if client.User != nil && client.User.PinExpired() {
+		api.exitWithError(w, http.StatusForbidden, "PIN expired")
+		return
+	}
+
+	transcripts := client.IsAdmin || api.Controller.Billing.TranscriptsAllowed(client.User)
+	incidents := api.Controller.Incidents
+	db := api.Controller.Database.Sql
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if s := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/incidents"), "/"); s != "" {
+		id, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			api.exitWithError(w, http.StatusBadRequest, "invalid incident id")
+			return
+		}
+
+		incident := &incidentSummary{Id: id}
+		var cadData string
+		if err := db.QueryRow(
+			`SELECT "title", "incidentType", "address", "startedAt", "updatedAt", "cadData" FROM "incidents" WHERE "incidentId" = $1`, id,
+		).Scan(&incident.Title, &incident.IncidentType, &incident.Address, &incident.StartedAt, &incident.UpdatedAt, &cadData); err != nil {
+			api.exitWithError(w, http.StatusNotFound, "incident not found")
+			return
+		}
+		if transcripts {
+			incident.CAD = parseStoredCADIncident(cadData)
+		}
+
+		calls, err := incidents.visibleCalls(id, client, transcripts)
+		if err != nil {
+			incidentsLog.Error(fmt.Sprintf("IncidentsHandler: %v", err))
+			api.exitWithError(w, http.StatusInternalServerError, "failed to read the incident")
+			return
+		}
+		if len(calls) == 0 {
+			api.exitWithError(w, http.StatusNotFound, "incident not found")
+			return
+		}
+		incident.summarize(calls)
+		incident.Calls = calls
+		if !transcripts {
+			incident.Title, incident.Address, incident.IncidentType = "", "", ""
+		}
+		json.NewEncoder(w).Encode(incident)
+		return
+	}
+
+	query := r.URL.Query()
+	limit := incidentListDefault
+	if s := query.Get("limit"); s != "" {
+		v, err := strconv.Atoi(s)
+		if err != nil || v < 1 {
+			api.exitWithError(w, http.StatusBadRequest, "invalid limit")
+			return
+		}
+		limit = v
+		if limit > incidentListMax {
+			limit = incidentListMax
+		}
+	}
+	since := time.Now().Add(-24 * time.Hour).UnixMilli()
+	if s := query.Get("since"); s != "" {
+		v, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			api.exitWithError(w, http.StatusBadRequest, "invalid since")
+			return
+		}
+		since = v
+	}
+
+	rows, err := db.Query(
+		`SELECT "incidentId", "title", "incidentType", "address", "startedAt", "updatedAt", "cadData" FROM "incidents" WHERE "updatedAt" >= $1 ORDER BY "updatedAt" DESC LIMIT $2`,
+		since, incidentListMax,
+	)
+	if err != nil {
+		incidentsLog.Error(fmt.Sprintf("IncidentsHandler: %v", err))
+		api.exitWithError(w, http.StatusInternalServerError, "failed to read incidents")
+		return
+	}
+	candidates := []*incidentSummary{}
+	for rows.Next() {
+		incident := &incidentSummary{}
+		var cadData string
+		if err := rows.Scan(&incident.Id, &incident.Title, &incident.IncidentType, &incident.Address, &incident.StartedAt, &incident.UpdatedAt, &cadData); err != nil {
+			continue
+		}
+		if transcripts {
+			incident.CAD = parseStoredCADIncident(cadData)
+		}
+		candidates = append(candidates, incident)
+	}
+	rows.Close()
+
+	// Incidents are listed with the calls the user can hear, and left out
+	// when there are none yet
+	list := []*incidentSummary{}
+	for _, incident := range candidates {
+		if len(list) >= limit {
+			break
+		}
+		calls, err := incidents.visibleCalls(incident.Id, client, transcripts)
+		if err != nil {
+			incidentsLog.Error(fmt.Sprintf("IncidentsHandler: %v", err))
+			continue
+		}
+		if len(calls) == 0 {
+			continue
+		}
+		incident.summarize(calls)
+		if !transcripts {
+			incident.Title, incident.Address, incident.IncidentType = "", "", ""
+		}
+		list = append(list, incident)
+	}
+
+	json.NewEncoder(w).Encode(map[string]any{"incidents": list})
+}
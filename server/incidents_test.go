@@ -0,0 +1,81 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestIncidentTokens(t *testing.T) {
+	tokens := incidentTokens("Engine 5 copy, responding to 123 Oak Street for smoke showing")
+	for _, want := range []string{"ENGINE", "5", "123", "STREET", "SMOKE", "SHOWING"} {
+		if !tokens[want] {
+			t.Fatalf("missing token %q in %v", want, tokens)
+		}
+	}
+	for _, unwanted := range []string{"COPY", "RESPONDING", "TO", "OAK", "FOR"} {
+		if tokens[unwanted] {
+			t.Fatalf("unexpected token %q", unwanted)
+		}
+	}
+}
+
+func TestNewIncidentCallAddress(t *testing.T) {
+	call := &Call{Id: 1, Transcript: "structure fire at 123 north oak street", System: &System{Id: 1}, Talkgroup: &Talkgroup{Id: 10}}
+	if c := newIncidentCall(call); c.Address != "123 NORTH OAK STREET" || !c.startsIncident() {
+		t.Fatalf("address = %q", c.Address)
+	}
+
+	call.SummaryFields = &CallSummaryFields{Address: "123 N OAK ST"}
+	if c := newIncidentCall(call); c.Address != "123 N OAK ST" {
+		t.Fatalf("summary address = %q", c.Address)
+	}
+
+	chatter := newIncidentCall(&Call{Id: 2, Transcript: "engine 5 on scene"})
+	if chatter.startsIncident() {
+		t.Fatalf("chatter should not start an incident")
+	}
+}
+
+func TestIncidentCorrelation(t *testing.T) {
+	window := 30 * time.Minute
+	config := IncidentConfig{Enabled: true}
+	start := time.UnixMilli(1700000000000)
+
+	dispatch := &incidentCall{Id: 1, TalkgroupId: 10, Timestamp: start, HasTones: true, Address: "123 OAK ST", Tokens: incidentTokens("structure fire 123 oak street engine 5 ladder 2")}
+	incidents := &Incidents{open: map[uint64]*incidentState{7: newIncidentState(7, dispatch)}}
+
+	// Fireground traffic mentioning the same units and address
+	fireground := &incidentCall{Id: 2, TalkgroupId: 20, Timestamp: start.Add(5 * time.Minute), Tokens: incidentTokens("engine 5 ladder 2 on scene 123 oak")}
+	incident, score, reasons := incidents.match(fireground, config)
+	if incident == nil || incident.Id != 7 {
+		t.Fatalf("fireground call not matched: score %.2f %v", score, reasons)
+	}
+	incident.add(fireground)
+	if !incident.Talkgroups[20] || incident.CallCount != 2 {
+		t.Fatalf("call not added: %+v", incident)
+	}
+
+	// Quick follow up on the fireground talkgroup
+	followUp := &incidentCall{Id: 3, TalkgroupId: 20, Timestamp: start.Add(6 * time.Minute), Tokens: incidentTokens("command copies")}
+	if incident, score, _ := incidents.match(followUp, config); incident == nil {
+		t.Fatalf("follow up not matched: score %.2f", score)
+	}
+
+	// New tones on dispatch for another address open another incident
+	other := &incidentCall{Id: 4, TalkgroupId: 10, Timestamp: start.Add(7 * time.Minute), HasTones: true, Address: "9 ELM ST", Tokens: incidentTokens("medical 9 elm street")}
+	if incident, _, _ := incidents.match(other, config); incident != nil {
+		t.Fatalf("new dispatch joined incident %d", incident.Id)
+	}
+
+	// Unrelated traffic on another talkgroup does not join
+	unrelated := &incidentCall{Id: 5, TalkgroupId: 30, Timestamp: start.Add(8 * time.Minute), Tokens: incidentTokens("traffic stop plate check")}
+	if score, _ := incidentScore(incident, unrelated, window); score >= config.minScore() {
+		t.Fatalf("unrelated call scored %.2f", score)
+	}
+
+	// The incident closes after the window
+	late := &incidentCall{Id: 6, TalkgroupId: 20, Timestamp: start.Add(time.Hour), Tokens: incidentTokens("engine 5 ladder 2 123 oak")}
+	if incident, _, _ := incidents.match(late, config); incident != nil || len(incidents.open) != 0 {
+		t.Fatalf("closed incident matched")
+	}
+}
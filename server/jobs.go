@@ -0,0 +1,567 @@
+// Copyright (C) 2026 Thinline Dynamic Solutions
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>
+
+package main
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Job is a unit of background work stored in the "jobs" table, so it
+// survives a crash or restart. A worker leases the job and keeps the lease
+// alive while it runs; a job whose lease runs out is picked up again. Failed
+// jobs are retried with exponential backoff and dead-lettered after their
+// last attempt, where an admin can inspect and retry them.
+type Job struct {
+	Id          uint64          `json:"id"`
+	Kind        string          `json:"kind"`
+	Payload     json.RawMessage `json:"payload"`
+	Priority    int             `json:"priority"`
+	Status      string          `json:"status"`
+	Attempts    uint            `json:"attempts"`
+	MaxAttempts uint            `json:"maxAttempts"`
+	RunAt       int64           `json:"runAt"`
+	LeaseUntil  int64           `json:"leaseUntil,omitempty"`
+	LeasedBy    string          `json:"leasedBy,omitempty"`
+	LastError   string          `json:"lastError,omitempty"`
+	CreatedAt   int64           `json:"createdAt"`
+	UpdatedAt   int64           `json:"updatedAt"`
+
+	// local is what the enqueuing process handed over in memory, such as
+	// audio not stored in the database. It is nil after a restart.
+	local any
+}
+
+// JobHandler runs the jobs of one kind.
+type JobHandler struct {
+	Run         func(job *Job) error
+	Concurrency int           // default 1
+	MaxAttempts uint          // default 5
+	RetryDelay  time.Duration // before the second attempt, doubling after, default 30s
+}
+
+const (
+	JobStatusQueued  = "queued"
+	JobStatusRunning = "running"
+	JobStatusDone    = "done"
+	JobStatusDead    = "dead"
+
+	jobLease            = 2 * time.Minute
+	jobPollInterval     = time.Second
+	jobMaxRetryDelay    = time.Hour
+	jobDoneRetention    = 3 * 24 * time.Hour
+	jobLastErrorMaxSize = 2000
+)
+
+// permanentJobError fails a job without retrying it.
+type permanentJobError struct{ err error }
+
+func (e permanentJobError) Error() string { return e.err.Error() }
+func (e permanentJobError) Unwrap() error { return e.err }
+
+// jobPermanent marks err as not worth retrying; the job is dead-lettered.
+func jobPermanent(err error) error {
+	return permanentJobError{err}
+}
+
+type JobQueue struct {
+	controller *Controller
+	owner      string
+	mutex      sync.Mutex
+	handlers   map[string]*JobHandler
+	wake       map[string]chan struct{}
+	locals     map[uint64]any
+	stop       chan struct{}
+	started    bool
+	wg         sync.WaitGroup
+}
+
+func NewJobQueue(controller *Controller) *JobQueue {
+	b := make([]byte, 4)
+	rand.Read(b)
+	host, _ := os.Hostname()
+
+	return &JobQueue{
+		controller: controller,
+		owner:      fmt.Sprintf("%s:%d:%s", host, os.Getpid(), hex.EncodeToString(b)),
+		handlers:   map[string]*JobHandler{},
+		wake:       map[string]chan struct{}{},
+		locals:     map[uint64]any{},
+		stop:       make(chan struct{}),
+	}
+}
+
+// Register sets the handler of a job kind. Handlers are registered before
+// Start.
+func (queue *JobQueue) Register(kind string, handler JobHandler) {
+	if handler.Concurrency <= 0 {
+		handler.Concurrency = 1
+	}
+	if handler.MaxAttempts == 0 {
+		handler.MaxAttempts = 5
+	}
+	if handler.RetryDelay <= 0 {
+		handler.RetryDelay = 30 * time.Second
+	}
+
+	queue.mutex.Lock()
+	defer queue.mutex.Unlock()
+	queue.handlers[kind] = &handler
+	queue.wake[kind] = make(chan struct{}, 1)
+}
+
+// Start runs a dispatcher per registered kind, which also resumes the jobs
+// left queued or running by the previous process.
+func (queue *JobQueue) Start() {
+	queue.mutex.Lock()
+	defer queue.mutex.Unlock()
+
+	if queue.started {
+		return
+	}
+	queue.started = true
+
+	for kind, handler := range queue.handlers {
+		queue.wg.Add(1)
+		go queue.dispatch(kind, handler, queue.wake[kind])
+	}
+}
+
+// Stop waits for the dispatchers to exit. Running jobs keep their lease
+// until it runs out and are resumed by the next process.
+func (queue *JobQueue) Stop() {
+	queue.mutex.Lock()
+	if !queue.started {
+		queue.mutex.Unlock()
+		return
+	}
+	queue.started = false
+	close(queue.stop)
+	queue.mutex.Unlock()
+
+	queue.wg.Wait()
+}
+
+// Enqueue stores a job to run after delay. local is handed to the handler
+// in memory when this process runs the job.
+func (queue *JobQueue) Enqueue(kind string, priority int, payload any, local any, delay time.Duration) (uint64, error) {
+	queue.mutex.Lock()
+	handler, ok := queue.handlers[kind]
+	queue.mutex.Unlock()
+	if !ok {
+		return 0, fmt.Errorf("unknown job kind %q", kind)
+	}
+
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	var id uint64
+	query := `INSERT INTO "jobs" ("kind", "payload", "priority", "status", "maxAttempts", "runAt", "createdAt", "updatedAt") VALUES ($1, $2, $3, $4, $5, $6, $7, $7) RETURNING "jobId"`
+	if err := queue.controller.Database.Sql.QueryRow(query, kind, string(b), priority, JobStatusQueued, handler.MaxAttempts, now.Add(delay).UnixMilli(), now.UnixMilli()).Scan(&id); err != nil {
+		return 0, err
+	}
+
+	queue.mutex.Lock()
+	if local != nil {
+		queue.locals[id] = local
+	}
+	wake := queue.wake[kind]
+	queue.mutex.Unlock()
+
+	if delay <= 0 {
+		select {
+		case wake <- struct{}{}:
+		default:
+		}
+	}
+
+	return id, nil
+}
+
+// SetPayload replaces the payload of a running job, so a retry only redoes
+// the part of the work that failed.
+func (queue *JobQueue) SetPayload(job *Job, payload any) error {
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	if _, err := queue.controller.Database.Sql.Exec(`UPDATE "jobs" SET "payload" = $1 WHERE "jobId" = $2`, string(b), job.Id); err != nil {
+		return err
+	}
+	job.Payload = json.RawMessage(b)
+	return nil
+}
+
+// dispatch leases jobs of the kind while a worker slot is free.
+func (queue *JobQueue) dispatch(kind string, handler *JobHandler, wake chan struct{}) {
+	defer queue.wg.Done()
+
+	slots := make(chan struct{}, handler.Concurrency)
+	ticker := time.NewTicker(jobPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case slots <- struct{}{}:
+		case <-queue.stop:
+			return
+		}
+
+		job, err := queue.lease(kind)
+		if err != nil {
+			queue.controller.Logs.LogEvent(LogLevelError, fmt.Sprintf("job queue: lease %s: %v", kind, err))
+		}
+		if job == nil {
+			<-slots
+			select {
+			case <-wake:
+			case <-ticker.C:
+			case <-queue.stop:
+				return
+			}
+			continue
+		}
+
+		go func() {
+			defer func() { <-slots }()
+			queue.run(job, handler)
+		}()
+	}
+}
+
+// lease claims the next due job of the kind, or one whose lease ran out.
+func (queue *JobQueue) lease(kind string) (*Job, error) {
+	now := time.Now().UnixMilli()
+
+	lock := ""
+	if queue.controller.Database.Config.DbType == DbTypePostgresql {
+		lock = " FOR UPDATE SKIP LOCKED"
+	}
+	query := `UPDATE "jobs" SET "status" = $1, "attempts" = "attempts" + 1, "leaseUntil" = $2, "leasedBy" = $3, "updatedAt" = $4
+		WHERE "jobId" = (SELECT "jobId" FROM "jobs" WHERE "kind" = $5 AND (("status" = $6 AND "runAt" <= $4) OR ("status" = $1 AND "leaseUntil" < $4)) ORDER BY "priority" DESC, "runAt" LIMIT 1` + lock + `)
+		RETURNING "jobId", "payload", "priority", "attempts", "maxAttempts"`
+
+	job := &Job{Kind: kind, Status: JobStatusRunning}
+	var payload string
+	err := queue.controller.Database.Sql.QueryRow(query, JobStatusRunning, now+jobLease.Milliseconds(), queue.owner, now, kind, JobStatusQueued).
+		Scan(&job.Id, &payload, &job.Priority, &job.Attempts, &job.MaxAttempts)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	job.Payload = json.RawMessage(payload)
+
+	queue.mutex.Lock()
+	job.local = queue.locals[job.Id]
+	delete(queue.locals, job.Id)
+	queue.mutex.Unlock()
+
+	return job, nil
+}
+
+// run executes the job, renewing its lease until the handler returns.
+func (queue *JobQueue) run(job *Job, handler *JobHandler) {
+	db := queue.controller.Database.Sql
+
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(jobLease / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				db.Exec(`UPDATE "jobs" SET "leaseUntil" = $1 WHERE "jobId" = $2 AND "leasedBy" = $3`, time.Now().Add(jobLease).UnixMilli(), job.Id, queue.owner)
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	err := func() (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("panic: %v", r)
+			}
+		}()
+		return handler.Run(job)
+	}()
+	close(done)
+
+	now := time.Now()
+	if err == nil {
+		if _, err := db.Exec(`UPDATE "jobs" SET "status" = $1, "lastError" = '', "leaseUntil" = 0, "updatedAt" = $2 WHERE "jobId" = $3`, JobStatusDone, now.UnixMilli(), job.Id); err != nil {
+			queue.controller.Logs.LogEvent(LogLevelError, fmt.Sprintf("job queue: complete job %d: %v", job.Id, err))
+		}
+		return
+	}
+
+	message := err.Error()
+	if len(message) > jobLastErrorMaxSize {
+		message = message[:jobLastErrorMaxSize]
+	}
+
+	status, runAt := JobStatusQueued, now.Add(jobRetryDelay(handler.RetryDelay, job.Attempts))
+	var permanent permanentJobError
+	if errors.As(err, &permanent) || job.Attempts >= job.MaxAttempts {
+		status = JobStatusDead
+		queue.controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("job queue: %s job %d dead after %d attempts: %s", job.Kind, job.Id, job.Attempts, message))
+	}
+
+	if _, err := db.Exec(`UPDATE "jobs" SET "status" = $1, "runAt" = $2, "lastError" = $3, "leaseUntil" = 0, "updatedAt" = $4 WHERE "jobId" = $5`, status, runAt.UnixMilli(), message, now.UnixMilli(), job.Id); err != nil {
+		queue.controller.Logs.LogEvent(LogLevelError, fmt.Sprintf("job queue: fail job %d: %v", job.Id, err))
+	}
+}
+
+// jobRetryDelay is the wait after the given failed attempt: base, doubling
+// after each failure up to jobMaxRetryDelay.
+func jobRetryDelay(base time.Duration, attempts uint) time.Duration {
+	delay := base
+	for i := uint(1); i < attempts; i++ {
+		delay *= 2
+		if delay >= jobMaxRetryDelay {
+			return jobMaxRetryDelay
+		}
+	}
+	return delay
+}
+
+// JobFilter selects jobs for the admin API.
+type JobFilter struct {
+	Kind   string
+	Status string
+	Limit  int
+}
+
+// Jobs lists the most recently updated jobs matching the filter.
+func (queue *JobQueue) Jobs(filter JobFilter) ([]Job, error) {
+	var (
+		conditions []string
+		args       []any
+	)
+	if filter.Kind != "" {
+		args = append(args, filter.Kind)
+		conditions = append(conditions, fmt.Sprintf(`"kind" = $%d`, len(args)))
+	}
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		conditions = append(conditions, fmt.Sprintf(`"status" = $%d`, len(args)))
+	}
+	where := "TRUE"
+	if len(conditions) > 0 {
+		where = strings.Join(conditions, " AND ")
+	}
+	limit := filter.Limit
+	if limit <= 0 || limit > 500 {
+		limit = 100
+	}
+
+	query := fmt.Sprintf(`SELECT "jobId", "kind", "payload", "priority", "status", "attempts", "maxAttempts", "runAt", "leaseUntil", "leasedBy", "lastError", "createdAt", "updatedAt" FROM "jobs" WHERE %s ORDER BY "updatedAt" DESC LIMIT $%d`, where, len(args)+1)
+	rows, err := queue.controller.Database.Sql.Query(query, append(args, limit)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	jobs := []Job{}
+	for rows.Next() {
+		var (
+			job     Job
+			payload string
+		)
+		if err := rows.Scan(&job.Id, &job.Kind, &payload, &job.Priority, &job.Status, &job.Attempts, &job.MaxAttempts, &job.RunAt, &job.LeaseUntil, &job.LeasedBy, &job.LastError, &job.CreatedAt, &job.UpdatedAt); err != nil {
+			return nil, err
+		}
+		job.Payload = json.RawMessage(payload)
+		jobs = append(jobs, job)
+	}
+	return jobs, rows.Err()
+}
+
+// Counts returns the number of jobs per kind and status.
+func (queue *JobQueue) Counts() (map[string]map[string]int64, error) {
+	rows, err := queue.controller.Database.Sql.Query(`SELECT "kind", "status", COUNT(*) FROM "jobs" GROUP BY "kind", "status"`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := map[string]map[string]int64{}
+	for rows.Next() {
+		var (
+			kind, status string
+			count        int64
+		)
+		if err := rows.Scan(&kind, &status, &count); err != nil {
+			return nil, err
+		}
+		if counts[kind] == nil {
+			counts[kind] = map[string]int64{}
+		}
+		counts[kind][status] = count
+	}
+	return counts, rows.Err()
+}
+
+// Retry requeues a dead job with a fresh set of attempts. With id 0 it
+// requeues every dead job, of the kind when one is given. It returns how many
+// jobs were requeued.
+func (queue *JobQueue) Retry(id uint64, kind string) (int64, error) {
+	query := `UPDATE "jobs" SET "status" = $1, "attempts" = 0, "runAt" = $2, "updatedAt" = $2 WHERE "status" = $3`
+	args := []any{JobStatusQueued, time.Now().UnixMilli(), JobStatusDead}
+	if id > 0 {
+		args = append(args, id)
+		query += fmt.Sprintf(` AND "jobId" = $%d`, len(args))
+	}
+	if kind != "" {
+		args = append(args, kind)
+		query += fmt.Sprintf(` AND "kind" = $%d`, len(args))
+	}
+
+	result, err := queue.controller.Database.Sql.Exec(query, args...)
+	if err != nil {
+		return 0, err
+	}
+	count, _ := result.RowsAffected()
+
+	if count > 0 {
+		queue.mutex.Lock()
+		for _, wake := range queue.wake {
+			select {
+			case wake <- struct{}{}:
+			default:
+			}
+		}
+		queue.mutex.Unlock()
+	}
+
+	return count, nil
+}
+
+// Delete removes a job that is not running.
+func (queue *JobQueue) Delete(id uint64) (bool, error) {
+	result, err := queue.controller.Database.Sql.Exec(`DELETE FROM "jobs" WHERE "jobId" = $1 AND "status" <> $2`, id, JobStatusRunning)
+	if err != nil {
+		return false, err
+	}
+	count, _ := result.RowsAffected()
+
+	queue.mutex.Lock()
+	delete(queue.locals, id)
+	queue.mutex.Unlock()
+
+	return count > 0, nil
+}
+
+// Prune drops completed jobs older than jobDoneRetention. Dead jobs are kept
+// until an admin retries or deletes them.
+func (queue *JobQueue) Prune() error {
+	cutoff := time.Now().Add(-jobDoneRetention).UnixMilli()
+	_, err := queue.controller.Database.Sql.Exec(`DELETE FROM "jobs" WHERE "status" = $1 AND "updatedAt" < $2`, JobStatusDone, cutoff)
+	return err
+}
+
+// JobsHandler inspects and retries background jobs.
+// GET lists jobs (?kind=&status=&limit=) with the counts per kind and status,
+// POST ?id= retries a dead job, or every dead job (of ?kind=) without an id,
+// DELETE ?id= removes a job.
+func (admin *Admin) JobsHandler(w http.ResponseWriter, r *http.Request) {
+	t := admin.GetAuthorization(r)
+	if !admin.ValidateToken(t) {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	queue := admin.Controller.Jobs
+
+	writeError := func(status int, err error) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+	}
+
+	var id uint64
+	if s := r.URL.Query().Get("id"); s != "" {
+		v, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			writeError(http.StatusBadRequest, fmt.Errorf("invalid id"))
+			return
+		}
+		id = v
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		jobs, err := queue.Jobs(JobFilter{Kind: r.URL.Query().Get("kind"), Status: r.URL.Query().Get("status"), Limit: limit})
+		if err != nil {
+			writeError(http.StatusInternalServerError, err)
+			return
+		}
+		counts, err := queue.Counts()
+		if err != nil {
+			writeError(http.StatusInternalServerError, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{"jobs": jobs, "counts": counts})
+
+	case http.MethodPost:
+		count, err := queue.Retry(id, r.URL.Query().Get("kind"))
+		if err != nil {
+			writeError(http.StatusInternalServerError, err)
+			return
+		}
+		if id > 0 && count == 0 {
+			writeError(http.StatusNotFound, fmt.Errorf("job %d is not dead", id))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]int64{"retried": count})
+
+	case http.MethodDelete:
+		if id == 0 {
+			writeError(http.StatusBadRequest, fmt.Errorf("id is required"))
+			return
+		}
+		deleted, err := queue.Delete(id)
+		if err != nil {
+			writeError(http.StatusInternalServerError, err)
+			return
+		}
+		if !deleted {
+			writeError(http.StatusNotFound, fmt.Errorf("job %d not found or running", id))
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestJobRetryDelay(t *testing.T) {
+	for _, test := range []struct {
+		attempts uint
+		want     time.Duration
+	}{
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{4, 4 * time.Minute},
+		{20, jobMaxRetryDelay},
+	} {
+		if got := jobRetryDelay(30*time.Second, test.attempts); got != test.want {
+			t.Fatalf("after attempt %d got %s, want %s", test.attempts, got, test.want)
+		}
+	}
+}
+
+func TestJobPermanent(t *testing.T) {
+	err := fmt.Errorf("deliver: %w", jobPermanent(errors.New("410 Gone")))
+	var permanent permanentJobError
+	if !errors.As(err, &permanent) {
+		t.Fatalf("wrapped permanent error not detected")
+	}
+	if errors.As(errors.New("timeout"), &permanent) {
+		t.Fatalf("plain error detected as permanent")
+	}
+}
+
+func TestJobQueueEnqueueUnknownKind(t *testing.T) {
+	queue := NewJobQueue(&Controller{})
+	if _, err := queue.Enqueue("nope", 0, nil, nil, 0); err == nil {
+		t.Fatalf("unregistered kind accepted")
+	}
+}
+
+func TestTranscriptionJobFinish(t *testing.T) {
+	TranscriptionJob{}.finish(nil)
+
+	done := make(chan error, 1)
+	TranscriptionJob{Done: done}.finish(errors.New("provider down"))
+	if err := <-done; err == nil || err.Error() != "provider down" {
+		t.Fatalf("got %v", err)
+	}
+}
+
+func TestRunWebhookJob(t *testing.T) {
+	status := http.StatusServiceUnavailable
+	var deliveryId string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		deliveryId = r.Header.Get("X-Webhook-Delivery")
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	payload, _ := json.Marshal(webhookJobPayload{
+		Webhook:    Webhook{URL: server.URL, ContentType: webhookDefaultContentType},
+		Event:      webhookEventCall,
+		DeliveryId: "abc123",
+		Body:       []byte(`{}`),
+	})
+	job := &Job{Kind: jobKindWebhook, Payload: payload}
+	controller := &Controller{}
+
+	var permanent permanentJobError
+	if err := controller.runWebhookJob(job); err == nil || errors.As(err, &permanent) {
+		t.Fatalf("503 should be retried, got %v", err)
+	}
+	if deliveryId != "abc123" {
+		t.Fatalf("got delivery id %q", deliveryId)
+	}
+
+	status = http.StatusBadRequest
+	if err := controller.runWebhookJob(job); !errors.As(err, &permanent) {
+		t.Fatalf("400 should not be retried, got %v", err)
+	}
+
+	status = http.StatusOK
+	if err := controller.runWebhookJob(job); err != nil {
+		t.Fatalf("delivery failed: %v", err)
+	}
+}
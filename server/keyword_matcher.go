@@ -38,6 +38,10 @@ type KeywordMatcher struct {
 	// pattern is only compiled once for the lifetime of the process.
 	mu      sync.RWMutex
 	compiled map[string]*regexp.Regexp
+
+	// Regex and proximity keywords (see keyword_pattern.go), keyed by the
+	// keyword as written. Invalid patterns are cached as nil.
+	patterns map[string]*keywordPattern
 }
 
 // NewKeywordMatcher creates a new keyword matcher
@@ -45,6 +49,7 @@ func NewKeywordMatcher() *KeywordMatcher {
 	return &KeywordMatcher{
 		contextChars: 50,
 		compiled:     make(map[string]*regexp.Regexp),
+		patterns:     make(map[string]*keywordPattern),
 	}
 }
 
@@ -71,7 +76,8 @@ func (matcher *KeywordMatcher) getCompiledPattern(keywordUpper string) (*regexp.
 }
 
 // MatchKeywords matches keywords against a transcript (case-insensitive, whole-word only)
-// Transcript should already be in ALL CAPS
+// Transcript should already be in ALL CAPS. Regex and proximity keywords are
+// matched as described in keyword_pattern.go.
 func (matcher *KeywordMatcher) MatchKeywords(transcript string, keywords []string) []KeywordMatch {
 	matches := []KeywordMatch{}
 	
@@ -86,6 +92,19 @@ func (matcher *KeywordMatcher) MatchKeywords(transcript string, keywords []strin
 		if keyword == "" {
 			continue
 		}
+
+		if isKeywordPattern(keyword) {
+			if pattern := matcher.getCompiledKeywordPattern(keyword); pattern != nil {
+				for _, loc := range pattern.find(transcript) {
+					matches = append(matches, KeywordMatch{
+						Keyword:  keyword,
+						Context:  matcher.extractContext(transcript, loc[0], loc[1]-loc[0]),
+						Position: loc[0],
+					})
+				}
+			}
+			continue
+		}
 		
 		// Convert keyword to uppercase for case-insensitive matching
 		keywordUpper := strings.ToUpper(strings.TrimSpace(keyword))
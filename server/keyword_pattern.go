@@ -0,0 +1,235 @@
+// Copyright (C) 2026 Thinline Dynamic Solutions
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>
+
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"regexp/syntax"
+	"strconv"
+	"strings"
+)
+
+// Besides plain keywords, keyword lists and user keywords accept two kinds of
+// pattern:
+//
+//	/MVA|MOTOR VEHICLE/       regular expression, case-sensitive
+//	/structure fire/i         regular expression, case-insensitive
+//	STRUCTURE NEAR/5 FIRE     both terms within 5 words of each other, either order
+//
+// Regular expressions use Go's RE2 syntax, which runs in linear time, so no
+// pattern can cause catastrophic backtracking. Patterns are further limited in
+// length and compiled size, and each pattern reports at most
+// keywordPatternMaxMatches matches per transcript.
+const (
+	keywordRegexMaxLength       = 256
+	keywordRegexMaxInstructions = 2000
+	keywordProximityMaxDistance = 50
+	keywordPatternMaxMatches    = 20
+)
+
+var (
+	keywordNearOperator = regexp.MustCompile(`(?i)(?:^|\s+)NEAR/(\d+)(?:\s+|$)`)
+	keywordWord         = regexp.MustCompile(`[\p{L}\p{N}']+`)
+)
+
+// keywordPattern is a compiled regex or proximity keyword.
+type keywordPattern struct {
+	regex    *regexp.Regexp
+	left     []string
+	right    []string
+	distance int
+}
+
+// isKeywordPattern tells patterns apart from plain keywords.
+func isKeywordPattern(keyword string) bool {
+	keyword = strings.TrimSpace(keyword)
+	if strings.HasPrefix(keyword, "/") && len(keyword) > 1 {
+		return true
+	}
+	return strings.Contains(strings.ToUpper(keyword), "NEAR/") && keywordNearOperator.MatchString(keyword)
+}
+
+// parseKeywordPattern compiles a pattern, rejecting anything over the limits.
+func parseKeywordPattern(keyword string) (*keywordPattern, error) {
+	keyword = strings.TrimSpace(keyword)
+
+	if strings.HasPrefix(keyword, "/") {
+		end := strings.LastIndex(keyword, "/")
+		if end == 0 {
+			return nil, fmt.Errorf("regex %q is missing its closing /", keyword)
+		}
+		expr, flags := keyword[1:end], keyword[end+1:]
+		if expr == "" {
+			return nil, fmt.Errorf("regex %q is empty", keyword)
+		}
+		if len(expr) > keywordRegexMaxLength {
+			return nil, fmt.Errorf("regex %q is longer than %d characters", keyword, keywordRegexMaxLength)
+		}
+		switch flags {
+		case "":
+		case "i":
+			expr = "(?i)" + expr
+		default:
+			return nil, fmt.Errorf("regex %q has unknown flags %q, only i is supported", keyword, flags)
+		}
+
+		parsed, err := syntax.Parse(expr, syntax.Perl)
+		if err != nil {
+			return nil, fmt.Errorf("regex %q: %v", keyword, err)
+		}
+		prog, err := syntax.Compile(parsed.Simplify())
+		if err != nil {
+			return nil, fmt.Errorf("regex %q: %v", keyword, err)
+		}
+		if len(prog.Inst) > keywordRegexMaxInstructions {
+			return nil, fmt.Errorf("regex %q is too complex", keyword)
+		}
+
+		regex, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("regex %q: %v", keyword, err)
+		}
+		if regex.MatchString("") {
+			return nil, fmt.Errorf("regex %q matches empty text", keyword)
+		}
+		return &keywordPattern{regex: regex}, nil
+	}
+
+	operators := keywordNearOperator.FindAllStringSubmatchIndex(keyword, -1)
+	if len(operators) != 1 {
+		return nil, fmt.Errorf("proximity %q must have exactly one NEAR/n", keyword)
+	}
+	operator := operators[0]
+
+	distance, err := strconv.Atoi(keyword[operator[2]:operator[3]])
+	if err != nil || distance > keywordProximityMaxDistance {
+		return nil, fmt.Errorf("proximity %q: distance must be between 0 and %d words", keyword, keywordProximityMaxDistance)
+	}
+
+	left := keywordWords(keyword[:operator[0]])
+	right := keywordWords(keyword[operator[1]:])
+	if len(left) == 0 || len(right) == 0 {
+		return nil, fmt.Errorf("proximity %q needs a term on each side of NEAR/%d", keyword, distance)
+	}
+
+	return &keywordPattern{left: left, right: right, distance: distance}, nil
+}
+
+// validateKeywordPatterns checks the patterns in a keyword list before it is
+// saved. Plain keywords are always valid.
+func validateKeywordPatterns(keywords []string) error {
+	for _, keyword := range keywords {
+		if !isKeywordPattern(keyword) {
+			continue
+		}
+		if _, err := parseKeywordPattern(keyword); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type keywordWordSpan struct {
+	word       string
+	start, end int // byte offsets in the transcript
+}
+
+// keywordWords splits text into upper-cased words.
+func keywordWords(text string) []string {
+	return keywordWord.FindAllString(strings.ToUpper(text), -1)
+}
+
+func keywordWordSpans(text string) []keywordWordSpan {
+	spans := []keywordWordSpan{}
+	for _, loc := range keywordWord.FindAllStringIndex(text, -1) {
+		spans = append(spans, keywordWordSpan{word: strings.ToUpper(text[loc[0]:loc[1]]), start: loc[0], end: loc[1]})
+	}
+	return spans
+}
+
+// phraseAt lists the word indexes where phrase starts.
+func phraseAt(spans []keywordWordSpan, phrase []string) []int {
+	found := []int{}
+	for i := 0; i+len(phrase) <= len(spans); i++ {
+		match := true
+		for j, word := range phrase {
+			if spans[i+j].word != word {
+				match = false
+				break
+			}
+		}
+		if match {
+			found = append(found, i)
+		}
+	}
+	return found
+}
+
+// find returns the byte ranges of the pattern in transcript.
+func (pattern *keywordPattern) find(transcript string) [][]int {
+	if pattern.regex != nil {
+		return pattern.regex.FindAllStringIndex(transcript, keywordPatternMaxMatches)
+	}
+
+	spans := keywordWordSpans(transcript)
+	lefts := phraseAt(spans, pattern.left)
+	rights := phraseAt(spans, pattern.right)
+
+	found := [][]int{}
+	for _, l := range lefts {
+		for _, r := range rights {
+			// Words strictly between the two terms, whichever comes first
+			first, firstLen, second := l, len(pattern.left), r
+			if r < l {
+				first, firstLen, second = r, len(pattern.right), l
+			}
+			between := second - (first + firstLen)
+			if between < 0 || between > pattern.distance {
+				continue
+			}
+			lastLen := len(pattern.right)
+			if r < l {
+				lastLen = len(pattern.left)
+			}
+			found = append(found, []int{spans[first].start, spans[second+lastLen-1].end})
+			if len(found) >= keywordPatternMaxMatches {
+				return found
+			}
+			break
+		}
+	}
+	return found
+}
+
+// getCompiledKeywordPattern returns the cached pattern, or nil when it does
+// not compile. Patterns saved before validation existed are skipped rather
+// than matched literally.
+func (matcher *KeywordMatcher) getCompiledKeywordPattern(keyword string) *keywordPattern {
+	matcher.mu.RLock()
+	pattern, ok := matcher.patterns[keyword]
+	matcher.mu.RUnlock()
+	if ok {
+		return pattern
+	}
+
+	pattern, _ = parseKeywordPattern(keyword)
+
+	matcher.mu.Lock()
+	matcher.patterns[keyword] = pattern
+	matcher.mu.Unlock()
+	return pattern
+}
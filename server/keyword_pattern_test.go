@@ -0,0 +1,91 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestKeywordPatternValidation(t *testing.T) {
+	valid := []string{
+		"STRUCTURE FIRE",
+		"/MVA|MOTOR VEHICLE/",
+		"/\\bstructure\\s+fire\\b/i",
+		"STRUCTURE NEAR/5 FIRE",
+		"working fire near/0 smoke showing",
+	}
+	if err := validateKeywordPatterns(valid); err != nil {
+		t.Fatalf("expected valid patterns, got %v", err)
+	}
+
+	invalid := []string{
+		"/unclosed",
+		"/(fire/",
+		"/fire/x",
+		"/a*/",
+		"/" + strings.Repeat("a", keywordRegexMaxLength+1) + "/",
+		"/((a{100}){100}){100}/",
+		"FIRE NEAR/500 SMOKE",
+		"NEAR/5 FIRE",
+		"A NEAR/2 B NEAR/3 C",
+	}
+	for _, keyword := range invalid {
+		if err := validateKeywordPatterns([]string{keyword}); err == nil {
+			t.Errorf("expected %q to be rejected", keyword)
+		}
+	}
+}
+
+func TestMatchKeywordsRegex(t *testing.T) {
+	matcher := NewKeywordMatcher()
+	transcript := "ENGINE 5 RESPOND TO A MOTOR VEHICLE ACCIDENT ON MAIN STREET"
+
+	if matches := matcher.MatchKeywords(transcript, []string{"/MOTOR VEHICLE|MVA/"}); len(matches) != 1 || matches[0].Keyword != "/MOTOR VEHICLE|MVA/" {
+		t.Fatalf("expected a regex match, got %+v", matches)
+	}
+	if matches := matcher.MatchKeywords(transcript, []string{"/motor vehicle/"}); len(matches) != 0 {
+		t.Fatalf("case-sensitive regex should not match, got %+v", matches)
+	}
+	if matches := matcher.MatchKeywords(transcript, []string{"/motor vehicle/i"}); len(matches) != 1 {
+		t.Fatalf("case-insensitive regex should match, got %+v", matches)
+	}
+	// Invalid patterns are skipped, not matched literally
+	if matches := matcher.MatchKeywords("(FIRE", []string{"/(fire/i"}); len(matches) != 0 {
+		t.Fatalf("invalid pattern should not match, got %+v", matches)
+	}
+}
+
+func TestMatchKeywordsProximity(t *testing.T) {
+	matcher := NewKeywordMatcher()
+
+	cases := []struct {
+		transcript string
+		keyword    string
+		want       bool
+	}{
+		{"REPORTED STRUCTURE WITH HEAVY FIRE SHOWING", "STRUCTURE NEAR/2 FIRE", true},
+		{"REPORTED STRUCTURE WITH HEAVY FIRE SHOWING", "STRUCTURE NEAR/1 FIRE", false},
+		{"FIRE IN A TWO STORY STRUCTURE", "structure near/4 fire", true},
+		{"SMOKE SHOWING FROM A WORKING FIRE", "WORKING FIRE NEAR/3 SMOKE SHOWING", true},
+		{"STRUCTURES ON FIRE", "STRUCTURE NEAR/3 FIRE", false},
+		{"FIRE", "FIRE NEAR/3 FIRE", false},
+	}
+	for _, c := range cases {
+		matches := matcher.MatchKeywords(c.transcript, []string{c.keyword})
+		if (len(matches) > 0) != c.want {
+			t.Errorf("%q in %q: expected match=%t, got %+v", c.keyword, c.transcript, c.want, matches)
+		}
+	}
+
+	matches := matcher.MatchKeywords("CALLER REPORTS FIRE IN THE STRUCTURE", []string{"STRUCTURE NEAR/5 FIRE"})
+	if len(matches) != 1 || matches[0].Position != strings.Index("CALLER REPORTS FIRE IN THE STRUCTURE", "FIRE") {
+		t.Fatalf("expected match to start at the first term, got %+v", matches)
+	}
+}
+
+func TestMatchKeywordsPatternLimit(t *testing.T) {
+	matcher := NewKeywordMatcher()
+	transcript := strings.Repeat("FIRE ", 100)
+	if matches := matcher.MatchKeywords(transcript, []string{"/FIRE/"}); len(matches) != keywordPatternMaxMatches {
+		t.Fatalf("expected %d matches, got %d", keywordPatternMaxMatches, len(matches))
+	}
+}
@@ -0,0 +1,183 @@
+// Copyright (C) 2026 Thinline Dynamic Solutions
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// lifeSafetyPhrases is the built-in pack of life-safety phrases, by language.
+// Phrases are upper case without accents; transcripts are folded the same way
+// before matching. English is always checked since radio procedure words such
+// as MAYDAY are used in every language.
+var lifeSafetyPhrases = map[string][]string{
+	"en": {
+		"MAYDAY",
+		"EMERGENCY TRAFFIC",
+		"EVACUATE",
+		"ABANDON THE BUILDING",
+		"FIREFIGHTER DOWN",
+		"FIREFIGHTER TRAPPED",
+		"LOST FIREFIGHTER",
+		"MISSING FIREFIGHTER",
+		"OFFICER DOWN",
+		"OFFICER NEEDS ASSISTANCE",
+		"OFFICER NEEDS HELP",
+		"OFFICER SHOT",
+		"SHOTS FIRED",
+		"ACTIVE SHOOTER",
+	},
+	"es": {
+		"EVACUAR",
+		"EVACUEN",
+		"EVACUACION",
+		"BOMBERO CAIDO",
+		"BOMBERO ATRAPADO",
+		"OFICIAL CAIDO",
+		"OFICIAL HERIDO",
+		"AGENTE CAIDO",
+		"AGENTE HERIDO",
+		"DISPAROS",
+		"TIRADOR ACTIVO",
+	},
+	"fr": {
+		"EVACUEZ",
+		"EVACUATION",
+		"POMPIER A TERRE",
+		"POMPIER EN DETRESSE",
+		"POLICIER A TERRE",
+		"AGENT A TERRE",
+		"COUPS DE FEU",
+		"TIREUR ACTIF",
+	},
+}
+
+var lifeSafetyFold = strings.NewReplacer(
+	"Á", "A", "À", "A", "Â", "A", "Ä", "A", "Ã", "A",
+	"É", "E", "È", "E", "Ê", "E", "Ë", "E",
+	"Í", "I", "Ì", "I", "Î", "I", "Ï", "I",
+	"Ó", "O", "Ò", "O", "Ô", "O", "Ö", "O", "Õ", "O",
+	"Ú", "U", "Ù", "U", "Û", "U", "Ü", "U",
+	"Ç", "C", "Ñ", "N",
+)
+
+// lifeSafetyPhrasesFor returns the phrases checked for a transcript in
+// language ("en", "es-MX", "auto"...).
+func lifeSafetyPhrasesFor(language string) []string {
+	phrases := append([]string{}, lifeSafetyPhrases["en"]...)
+
+	language = strings.ToLower(strings.TrimSpace(language))
+	if i := strings.IndexAny(language, "-_"); i > 0 {
+		language = language[:i]
+	}
+	if language != "en" {
+		phrases = append(phrases, lifeSafetyPhrases[language]...)
+	}
+
+	return phrases
+}
+
+// matchLifeSafetyPhrases returns the distinct pack phrases found in transcript.
+func matchLifeSafetyPhrases(matcher *KeywordMatcher, transcript string, language string) []string {
+	folded := lifeSafetyFold.Replace(strings.ToUpper(transcript))
+
+	var found []string
+	seen := map[string]bool{}
+	for _, match := range matcher.MatchKeywords(folded, lifeSafetyPhrasesFor(language)) {
+		if !seen[match.Keyword] {
+			seen[match.Keyword] = true
+			found = append(found, match.Keyword)
+		}
+	}
+	return found
+}
+
+// claimLifeSafetyDispatch returns false when the call was already alerted,
+// from a partial or the final transcript.
+func (engine *AlertEngine) claimLifeSafetyDispatch(callId uint64) bool {
+	engine.cooldownMu.Lock()
+	defer engine.cooldownMu.Unlock()
+	if engine.lifeSafetyDispatched == nil {
+		engine.lifeSafetyDispatched = make(map[uint64]struct{})
+	}
+	if _, ok := engine.lifeSafetyDispatched[callId]; ok {
+		return false
+	}
+	engine.lifeSafetyDispatched[callId] = struct{}{}
+	return true
+}
+
+// TriggerLifeSafetyAlerts checks transcript against the built-in phrase pack
+// when the call's system has it enabled, and alerts every user with alerts
+// enabled on the talkgroup. Unlike user keywords it ignores the alert
+// cooldown, alerting talkgroup handling and the minimum word count. Returns
+// true when an alert was sent.
+func (engine *AlertEngine) TriggerLifeSafetyAlerts(call *Call, transcript string, language string) bool {
+	if call == nil || call.System == nil || call.Talkgroup == nil || !call.System.LifeSafetyPhrases {
+		return false
+	}
+	if !call.System.AlertsEnabled || !call.Talkgroup.AlertsEnabled {
+		return false
+	}
+
+	phrases := matchLifeSafetyPhrases(engine.controller.KeywordMatcher, transcript, language)
+	if len(phrases) == 0 || !engine.claimLifeSafetyDispatch(call.Id) {
+		return false
+	}
+
+	phrasesJson, _ := json.Marshal(phrases)
+
+	transcriptSnippet := strings.ToUpper(transcript)
+	if len(transcriptSnippet) > 200 {
+		transcriptSnippet = transcriptSnippet[:200] + "..."
+	}
+
+	engine.createAlert(&AlertRecord{
+		CallId:            call.Id,
+		SystemId:          call.System.Id,
+		TalkgroupId:       call.Talkgroup.Id,
+		AlertType:         "life-safety",
+		KeywordsMatched:   string(phrasesJson),
+		TranscriptSnippet: transcriptSnippet,
+		CreatedAt:         time.Now().UnixMilli(),
+	})
+
+	var userIds []uint64
+	for _, userId := range engine.controller.PreferencesCache.GetUsersForTalkgroup(call.System.Id, call.Talkgroup.Id) {
+		pref := engine.controller.PreferencesCache.GetPreference(userId, call.System.Id, call.Talkgroup.Id)
+		if pref != nil && pref.AlertEnabled {
+			userIds = append(userIds, userId)
+			go engine.sendAlertNotification(userId, call.Id, "life-safety")
+		}
+	}
+
+	engine.controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf(
+		"life-safety phrase %s on call %d (%s / %s), alerting %d user(s)",
+		strings.Join(phrases, ", "), call.Id, call.System.Label, call.Talkgroup.Label, len(userIds),
+	))
+
+	if len(userIds) > 0 {
+		// Sent as a keyword push so existing apps display the matched phrase
+		alertCall := *call
+		alertCall.Transcript = strings.ToUpper(transcript)
+		go engine.controller.sendBatchedPushNotification(userIds, "keyword", &alertCall, call.System.Label, call.Talkgroup.Label, "", phrases)
+	}
+
+	return true
+}
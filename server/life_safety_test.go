@@ -0,0 +1,55 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMatchLifeSafetyPhrases(t *testing.T) {
+	matcher := NewKeywordMatcher()
+
+	cases := []struct {
+		transcript string
+		language   string
+		want       string
+	}{
+		{"Mayday mayday mayday, Engine 5 crew trapped", "en", "MAYDAY"},
+		{"command, we have an officer down on Main", "en-US", "OFFICER DOWN"},
+		{"central, oficial caído en la calle ocho", "es", "OFICIAL CAIDO"},
+		{"Évacuez le bâtiment immédiatement", "fr-CA", "EVACUEZ"},
+		{"MAYDAY, ataque al edificio", "es", "MAYDAY"},
+		{"units be advised shots fired", "auto", "SHOTS FIRED"},
+	}
+	for _, c := range cases {
+		if got := matchLifeSafetyPhrases(matcher, c.transcript, c.language); strings.Join(got, ",") != c.want {
+			t.Fatalf("matchLifeSafetyPhrases(%q, %q) = %q, want %q", c.transcript, c.language, got, c.want)
+		}
+	}
+
+	for _, transcript := range []string{
+		"engine 5 on scene, nothing showing",
+		"maydays are practiced on tuesdays",
+		"evacuar la zona",
+	} {
+		if got := matchLifeSafetyPhrases(matcher, transcript, "en"); len(got) != 0 {
+			t.Fatalf("matchLifeSafetyPhrases(%q) = %q, want none", transcript, got)
+		}
+	}
+}
+
+func TestLifeSafetyPackIsFolded(t *testing.T) {
+	for language, phrases := range lifeSafetyPhrases {
+		for _, phrase := range phrases {
+			if phrase != strings.ToUpper(phrase) || lifeSafetyFold.Replace(phrase) != phrase || strings.TrimSpace(phrase) != phrase {
+				t.Fatalf("%s phrase %q must be trimmed upper case without accents", language, phrase)
+			}
+		}
+	}
+}
+
+func TestLifeSafetyDispatchOncePerCall(t *testing.T) {
+	engine := &AlertEngine{}
+	if !engine.claimLifeSafetyDispatch(7) || engine.claimLifeSafetyDispatch(7) || !engine.claimLifeSafetyDispatch(8) {
+		t.Fatalf("claimLifeSafetyDispatch must succeed once per call")
+	}
+}
@@ -0,0 +1,506 @@
+// Copyright (C) 2026 Thinline Dynamic Solutions
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ListenerAnalyticsConfig controls the listener statistics. Listeners are
+// the websocket clients; the talkgroups they listen to are those turned on
+// in their live feed.
+type ListenerAnalyticsConfig struct {
+	Disabled bool `json:"disabled,omitempty"`
+	// ShowTalkgroupListeners sends clients the number of listeners of each
+	// talkgroup they can hear, for an "X listening" indicator.
+	ShowTalkgroupListeners bool `json:"showTalkgroupListeners,omitempty"`
+	RetentionDays          uint `json:"retentionDays,omitempty"` // default 90
+}
+
+const (
+	listenerAnalyticsDefaultRetention = 90
+	listenerAnalyticsTick             = 10 * time.Second
+	listenerAnalyticsSample           = time.Minute
+	listenerAnalyticsFlush            = 5 * time.Minute
+	listenerAnalyticsTopTalkgroups    = 100
+)
+
+func (config ListenerAnalyticsConfig) retentionDays() uint {
+	if config.RetentionDays == 0 {
+		return listenerAnalyticsDefaultRetention
+	}
+	return config.RetentionDays
+}
+
+// listenerKey is a talkgroup by system and talkgroup references. The zero
+// key stands for all listeners, whatever they listen to.
+type listenerKey struct {
+	systemRef    uint
+	talkgroupRef uint
+}
+
+type listenerSession struct {
+	userId     uint64
+	ip         string
+	startedAt  int64 // unix ms
+	talkgroups map[listenerKey]bool
+	selected   map[listenerKey]bool // every talkgroup turned on during the session
+}
+
+type listenerBucket struct {
+	peak    int
+	minutes int64
+}
+
+// ListenerAnalytics follows the listener sessions. Each minute it samples the
+// listeners of every talkgroup into hourly peaks and listener minutes, which
+// are flushed to the listenerStats table. Ended sessions are recorded in the
+// listenerSessions table.
+type ListenerAnalytics struct {
+	controller *Controller
+	mutex      sync.Mutex
+	active     map[*Client]*listenerSession
+	pending    map[int64]map[listenerKey]*listenerBucket // hour -> talkgroup
+	emitted    map[listenerKey]int
+	stopChan   chan struct{}
+}
+
+func NewListenerAnalytics(controller *Controller) *ListenerAnalytics {
+	return &ListenerAnalytics{
+		controller: controller,
+		active:     map[*Client]*listenerSession{},
+		pending:    map[int64]map[listenerKey]*listenerBucket{},
+		emitted:    map[listenerKey]int{},
+		stopChan:   make(chan struct{}),
+	}
+}
+
+func (analytics *ListenerAnalytics) enabled() bool {
+	return analytics != nil && !analytics.controller.Options.ListenerAnalyticsConfig.Disabled
+}
+
+// Connect starts the session of a client.
+func (analytics *ListenerAnalytics) Connect(client *Client) {
+	if !analytics.enabled() {
+		return
+	}
+
+	session := &listenerSession{
+		ip:         client.GetRemoteAddr(),
+		startedAt:  time.Now().UnixMilli(),
+		talkgroups: map[listenerKey]bool{},
+		selected:   map[listenerKey]bool{},
+	}
+	if client.User != nil {
+		session.userId = client.User.Id
+	}
+
+	analytics.mutex.Lock()
+	analytics.active[client] = session
+	analytics.mutex.Unlock()
+
+	analytics.Select(client)
+}
+
+// Select updates the talkgroups of a client from its live feed.
+func (analytics *ListenerAnalytics) Select(client *Client) {
+	if !analytics.enabled() || client.Livefeed == nil {
+		return
+	}
+	selected := client.Livefeed.Selected()
+
+	analytics.mutex.Lock()
+	defer analytics.mutex.Unlock()
+
+	session, ok := analytics.active[client]
+	if !ok {
+		return
+	}
+	session.talkgroups = map[listenerKey]bool{}
+	for _, refs := range selected {
+		key := listenerKey{systemRef: refs[0], talkgroupRef: refs[1]}
+		session.talkgroups[key] = true
+		session.selected[key] = true
+	}
+	if client.User != nil {
+		session.userId = client.User.Id
+	}
+}
+
+// Disconnect ends the session of a client and records it.
+func (analytics *ListenerAnalytics) Disconnect(client *Client) {
+	if analytics == nil {
+		return
+	}
+
+	analytics.mutex.Lock()
+	session, ok := analytics.active[client]
+	delete(analytics.active, client)
+	analytics.mutex.Unlock()
+
+	if !ok || analytics.controller.Database == nil {
+		return
+	}
+
+	talkgroups := []string{}
+	for key := range session.selected {
+		talkgroups = append(talkgroups, fmt.Sprintf("%d:%d", key.systemRef, key.talkgroupRef))
+	}
+	sort.Strings(talkgroups)
+	b, _ := json.Marshal(talkgroups)
+
+	if _, err := analytics.controller.Database.Sql.Exec(
+		`INSERT INTO "listenerSessions" ("userId", "ip", "startedAt", "endedAt", "talkgroups") VALUES ($1, $2, $3, $4, $5)`,
+		session.userId, session.ip, session.startedAt, time.Now().UnixMilli(), string(b),
+	); err != nil {
+		analytics.controller.Logs.LogEvent(LogLevelError, fmt.Sprintf("listener analytics: %v", err))
+	}
+}
+
+// Counts returns the current listeners of each talkgroup listened to, and
+// the number of listeners under the zero key.
+func (analytics *ListenerAnalytics) Counts() map[listenerKey]int {
+	analytics.mutex.Lock()
+	defer analytics.mutex.Unlock()
+
+	return analytics.counts()
+}
+
+func (analytics *ListenerAnalytics) counts() map[listenerKey]int {
+	counts := map[listenerKey]int{{}: len(analytics.active)}
+	for _, session := range analytics.active {
+		for key := range session.talkgroups {
+			counts[key]++
+		}
+	}
+	return counts
+}
+
+// sample adds the current listeners to the bucket of the hour.
+func (analytics *ListenerAnalytics) sample(now time.Time) {
+	hour := now.Truncate(time.Hour).UnixMilli()
+
+	analytics.mutex.Lock()
+	defer analytics.mutex.Unlock()
+
+	buckets := analytics.pending[hour]
+	if buckets == nil {
+		buckets = map[listenerKey]*listenerBucket{}
+		analytics.pending[hour] = buckets
+	}
+	for key, count := range analytics.counts() {
+		bucket := buckets[key]
+		if bucket == nil {
+			bucket = &listenerBucket{}
+			buckets[key] = bucket
+		}
+		if count > bucket.peak {
+			bucket.peak = count
+		}
+		bucket.minutes += int64(count)
+	}
+}
+
+func (analytics *ListenerAnalytics) Start() {
+	go func() {
+		ticker := time.NewTicker(listenerAnalyticsTick)
+		defer ticker.Stop()
+
+		lastSample, lastFlush := time.Now(), time.Now()
+		for {
+			select {
+			case now := <-ticker.C:
+				if !analytics.enabled() {
+					continue
+				}
+				if now.Sub(lastSample) >= listenerAnalyticsSample {
+					analytics.sample(now)
+					lastSample = now
+				}
+				if now.Sub(lastFlush) >= listenerAnalyticsFlush {
+					if err := analytics.Flush(); err != nil {
+						analytics.controller.Logs.LogEvent(LogLevelError, fmt.Sprintf("listener analytics: %v", err))
+					}
+					lastFlush = now
+				}
+				if analytics.controller.Options.ListenerAnalyticsConfig.ShowTalkgroupListeners {
+					analytics.emit()
+				}
+			case <-analytics.stopChan:
+				return
+			}
+		}
+	}()
+}
+
+// Stop ends the background goroutine and flushes the pending samples.
+func (analytics *ListenerAnalytics) Stop() {
+	select {
+	case <-analytics.stopChan:
+		return
+	default:
+		close(analytics.stopChan)
+	}
+	if err := analytics.Flush(); err != nil {
+		analytics.controller.Logs.LogEvent(LogLevelError, fmt.Sprintf("listener analytics: %v", err))
+	}
+}
+
+// Flush writes the pending samples to the listenerStats table.
+func (analytics *ListenerAnalytics) Flush() error {
+	analytics.mutex.Lock()
+	pending := analytics.pending
+	analytics.pending = map[int64]map[listenerKey]*listenerBucket{}
+	analytics.mutex.Unlock()
+
+	if len(pending) == 0 {
+		return nil
+	}
+
+	tx, err := analytics.controller.Database.Sql.Begin()
+	if err != nil {
+		return fmt.Errorf("flush: %v", err)
+	}
+	for hour, buckets := range pending {
+		for key, bucket := range buckets {
+			if _, err = tx.Exec(`INSERT INTO "listenerStats" ("hour", "systemRef", "talkgroupRef", "peak", "listenerMinutes") VALUES ($1, $2, $3, $4, $5) ON CONFLICT ("hour", "systemRef", "talkgroupRef") DO UPDATE SET "peak" = GREATEST("listenerStats"."peak", EXCLUDED."peak"), "listenerMinutes" = "listenerStats"."listenerMinutes" + EXCLUDED."listenerMinutes"`, hour, key.systemRef, key.talkgroupRef, bucket.peak, bucket.minutes); err != nil {
+				break
+			}
+		}
+		if err != nil {
+			break
+		}
+	}
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("flush: %v", err)
+	}
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("flush: %v", err)
+	}
+	return nil
+}
+
+// Prune drops the statistics and sessions past the retention.
+func (analytics *ListenerAnalytics) Prune() error {
+	days := analytics.controller.Options.ListenerAnalyticsConfig.retentionDays()
+	cutoff := time.Now().AddDate(0, 0, -int(days)).UnixMilli()
+	db := analytics.controller.Database.Sql
+	if _, err := db.Exec(`DELETE FROM "listenerStats" WHERE "hour" < $1`, cutoff); err != nil {
+		return err
+	}
+	if _, err := db.Exec(`DELETE FROM "listenerSessions" WHERE "endedAt" < $1`, cutoff); err != nil {
+		return err
+	}
+	return nil
+}
+
+// emit sends the listeners of each talkgroup to the clients when they
+// changed, as {"systemRef": {"talkgroupRef": count}}. Clients only get the
+// talkgroups they can hear.
+func (analytics *ListenerAnalytics) emit() {
+	analytics.mutex.Lock()
+	counts := analytics.counts()
+	delete(counts, listenerKey{})
+	changed := !reflect.DeepEqual(counts, analytics.emitted)
+	analytics.emitted = counts
+	clients := make([]*Client, 0, len(analytics.active))
+	for client := range analytics.active {
+		clients = append(clients, client)
+	}
+	analytics.mutex.Unlock()
+
+	if !changed {
+		return
+	}
+
+	controller := analytics.controller
+	restricted := controller.requiresUserAuth()
+	for _, client := range clients {
+		if restricted && client.User == nil {
+			continue
+		}
+		payload := map[string]map[string]int{}
+		for key, count := range counts {
+			system, ok := controller.Systems.GetSystemByRef(key.systemRef)
+			if !ok {
+				continue
+			}
+			talkgroup, ok := system.Talkgroups.GetTalkgroupByRef(key.talkgroupRef)
+			if !ok {
+				continue
+			}
+			if restricted && !controller.userHasAccess(client.User, &Call{System: system, Talkgroup: talkgroup}) {
+				continue
+			}
+			systemRef := strconv.FormatUint(uint64(key.systemRef), 10)
+			if payload[systemRef] == nil {
+				payload[systemRef] = map[string]int{}
+			}
+			payload[systemRef][strconv.FormatUint(uint64(key.talkgroupRef), 10)] = count
+		}
+		select {
+		case client.Send <- &Message{Command: MessageCommandTGListeners, Payload: payload}:
+		default:
+		}
+	}
+}
+
+// listenerTalkgroupStats is the popularity of a talkgroup over a period.
+type listenerTalkgroupStats struct {
+	SystemRef       uint   `json:"systemRef"`
+	System          string `json:"system"`
+	TalkgroupRef    uint   `json:"talkgroupRef"`
+	Talkgroup       string `json:"talkgroup"`
+	TalkgroupName   string `json:"talkgroupName"`
+	Listeners       int    `json:"listeners,omitempty"`
+	Peak            int    `json:"peak"`
+	ListenerMinutes int64  `json:"listenerMinutes"`
+}
+
+// describe fills in the labels of the talkgroup, returning false when it
+// isn't in an allowed system.
+func (stats *listenerTalkgroupStats) describe(controller *Controller, allowed func(system *System) bool) bool {
+	system, ok := controller.Systems.GetSystemByRef(stats.SystemRef)
+	if !ok || !allowed(system) {
+		return false
+	}
+	stats.System = system.Label
+	if talkgroup, ok := system.Talkgroups.GetTalkgroupByRef(stats.TalkgroupRef); ok {
+		stats.Talkgroup, stats.TalkgroupName = talkgroup.Label, talkgroup.Name
+	}
+	return true
+}
+
+// ListenerAnalyticsHandler serves the current listeners and the talkgroup
+// popularity of the last days. It needs the view stats permission.
+//
+// GET /api/admin/listeners?days=7
+func (admin *Admin) ListenerAnalyticsHandler(w http.ResponseWriter, r *http.Request) {
+	t := admin.GetAuthorization(r)
+	if !admin.ValidatePermission(t, PermissionViewStats) {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	writeError := func(status int, message string) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(map[string]string{"error": message})
+	}
+
+	analytics := admin.Controller.ListenerAnalytics
+	retention := int(admin.Controller.Options.ListenerAnalyticsConfig.retentionDays())
+	days := 7
+	if s := r.URL.Query().Get("days"); s != "" {
+		v, err := strconv.Atoi(s)
+		if err != nil || v < 1 {
+			writeError(http.StatusBadRequest, "days must be a positive number")
+			return
+		}
+		days = min(v, retention)
+	}
+
+	// Tenant administrators only see their own systems, and not the
+	// listener totals, which span every tenant
+	tenant := admin.tokenTenant(t)
+	allowed := func(system *System) bool { return admin.tenantAllowsSystem(t, system.Id) }
+
+	if err := analytics.Flush(); err != nil {
+		writeError(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	current := []*listenerTalkgroupStats{}
+	counts := analytics.Counts()
+	for key, count := range counts {
+		if key == (listenerKey{}) {
+			continue
+		}
+		stats := &listenerTalkgroupStats{SystemRef: key.systemRef, TalkgroupRef: key.talkgroupRef, Listeners: count, Peak: count}
+		if stats.describe(admin.Controller, allowed) {
+			current = append(current, stats)
+		}
+	}
+	sort.Slice(current, func(i, j int) bool { return current[i].Listeners > current[j].Listeners })
+
+	from := time.Now().AddDate(0, 0, -days).Truncate(time.Hour).UnixMilli()
+	db := admin.Controller.Database.Sql
+
+	rows, err := db.Query(`SELECT "systemRef", "talkgroupRef", MAX("peak"), SUM("listenerMinutes") FROM "listenerStats" WHERE "hour" >= $1 AND "talkgroupRef" > 0 GROUP BY "systemRef", "talkgroupRef" ORDER BY SUM("listenerMinutes") DESC`, from)
+	if err != nil {
+		writeError(http.StatusInternalServerError, err.Error())
+		return
+	}
+	popularity := []*listenerTalkgroupStats{}
+	for rows.Next() {
+		stats := &listenerTalkgroupStats{}
+		if err := rows.Scan(&stats.SystemRef, &stats.TalkgroupRef, &stats.Peak, &stats.ListenerMinutes); err != nil {
+			continue
+		}
+		if len(popularity) < listenerAnalyticsTopTalkgroups && stats.describe(admin.Controller, allowed) {
+			popularity = append(popularity, stats)
+		}
+	}
+	rows.Close()
+
+	response := map[string]any{
+		"days":       days,
+		"current":    current,
+		"popularity": popularity,
+	}
+
+	if tenant == nil {
+		response["listeners"] = counts[listenerKey{}]
+
+		hourly := []map[string]any{}
+		rows, err := db.Query(`SELECT "hour", "peak", "listenerMinutes" FROM "listenerStats" WHERE "hour" >= $1 AND "systemRef" = 0 AND "talkgroupRef" = 0 ORDER BY "hour"`, from)
+		if err != nil {
+			writeError(http.StatusInternalServerError, err.Error())
+			return
+		}
+		for rows.Next() {
+			var hour, minutes int64
+			var peak int
+			if err := rows.Scan(&hour, &peak, &minutes); err != nil {
+				continue
+			}
+			hourly = append(hourly, map[string]any{"hour": hour, "peak": peak, "listenerMinutes": minutes})
+		}
+		rows.Close()
+		response["hourly"] = hourly
+
+		var sessions int64
+		var averageMinutes float64
+		db.QueryRow(`SELECT COUNT(*), COALESCE(AVG("endedAt" - "startedAt"), 0) / 60000.0 FROM "listenerSessions" WHERE "startedAt" >= $1`, from).Scan(&sessions, &averageMinutes)
+		response["sessions"] = map[string]any{"count": sessions, "averageMinutes": averageMinutes}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
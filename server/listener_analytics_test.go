@@ -0,0 +1,95 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func newListenerTestClient(selected map[string]any) *Client {
+	client := &Client{Livefeed: NewLivefeed(), Send: make(chan *Message, 4), request: httptest.NewRequest(http.MethodGet, "/", nil)}
+	client.Livefeed.FromMap(selected)
+	return client
+}
+
+func TestListenerAnalyticsCounts(t *testing.T) {
+	analytics := NewListenerAnalytics(&Controller{Options: &Options{}})
+
+	first := newListenerTestClient(map[string]any{"1": map[string]any{"100": true, "101": true}})
+	second := newListenerTestClient(map[string]any{"1": map[string]any{"100": true, "101": false}})
+	analytics.Connect(first)
+	analytics.Connect(second)
+
+	counts := analytics.Counts()
+	if counts[listenerKey{}] != 2 || counts[listenerKey{1, 100}] != 2 || counts[listenerKey{1, 101}] != 1 {
+		t.Fatalf("unexpected counts %v", counts)
+	}
+
+	second.Livefeed.FromMap(map[string]any{})
+	analytics.Select(second)
+	analytics.Disconnect(first)
+	counts = analytics.Counts()
+	if counts[listenerKey{}] != 1 || counts[listenerKey{1, 100}] != 0 {
+		t.Fatalf("unexpected counts after changes %v", counts)
+	}
+	if !analytics.active[second].selected[listenerKey{1, 100}] {
+		t.Fatal("the session should remember the talkgroups turned on earlier")
+	}
+}
+
+func TestListenerAnalyticsDisabled(t *testing.T) {
+	controller := &Controller{Options: &Options{}}
+	controller.Options.ListenerAnalyticsConfig.Disabled = true
+	analytics := NewListenerAnalytics(controller)
+
+	analytics.Connect(newListenerTestClient(map[string]any{"1": map[string]any{"100": true}}))
+	if counts := analytics.Counts(); counts[listenerKey{}] != 0 {
+		t.Fatalf("disabled analytics should not track listeners: %v", counts)
+	}
+}
+
+func TestListenerAnalyticsSample(t *testing.T) {
+	analytics := NewListenerAnalytics(&Controller{Options: &Options{}})
+	hour := time.Date(2026, 10, 1, 14, 0, 0, 0, time.UTC)
+
+	first := newListenerTestClient(map[string]any{"1": map[string]any{"100": true}})
+	analytics.Connect(first)
+	analytics.sample(hour.Add(time.Minute))
+	analytics.Connect(newListenerTestClient(map[string]any{"1": map[string]any{"100": true}}))
+	analytics.sample(hour.Add(2 * time.Minute))
+	analytics.Disconnect(first)
+	analytics.sample(hour.Add(3 * time.Minute))
+	analytics.sample(hour.Add(61 * time.Minute))
+
+	bucket := analytics.pending[hour.UnixMilli()][listenerKey{1, 100}]
+	if bucket == nil || bucket.peak != 2 || bucket.minutes != 4 {
+		t.Fatalf("unexpected bucket %+v", bucket)
+	}
+	if next := analytics.pending[hour.Add(time.Hour).UnixMilli()][listenerKey{1, 100}]; next == nil || next.peak != 1 {
+		t.Fatalf("samples of the next hour should go to their own bucket: %+v", next)
+	}
+}
+
+func TestListenerAnalyticsEmit(t *testing.T) {
+	controller := &Controller{Options: &Options{}, Systems: NewSystems()}
+	system := &System{Id: 1, SystemRef: 1, Talkgroups: NewTalkgroups()}
+	system.Talkgroups.List = append(system.Talkgroups.List, &Talkgroup{Id: 10, TalkgroupRef: 100})
+	controller.Systems.List = append(controller.Systems.List, system)
+	analytics := NewListenerAnalytics(controller)
+
+	client := newListenerTestClient(map[string]any{"1": map[string]any{"100": true, "999": true}})
+	analytics.Connect(client)
+	analytics.emit()
+
+	message := <-client.Send
+	payload, ok := message.Payload.(map[string]map[string]int)
+	if message.Command != MessageCommandTGListeners || !ok || payload["1"]["100"] != 1 || len(payload["1"]) != 1 {
+		t.Fatalf("unexpected message %+v", message)
+	}
+
+	analytics.emit()
+	if len(client.Send) != 0 {
+		t.Fatal("unchanged counts should not be sent again")
+	}
+}
@@ -82,6 +82,22 @@ func (livefeed *Livefeed) IsAllOff() bool {
 	return true
 }
 
+// Selected returns the system and talkgroup references turned on.
+func (livefeed *Livefeed) Selected() [][2]uint {
+	livefeed.mutex.Lock()
+	defer livefeed.mutex.Unlock()
+
+	selected := [][2]uint{}
+	for systemRef, talkgroups := range livefeed.Matrix {
+		for talkgroupRef, on := range talkgroups {
+			if on {
+				selected = append(selected, [2]uint{systemRef, talkgroupRef})
+			}
+		}
+	}
+	return selected
+}
+
 // IsEnabledForRef returns true if the client has the given systemRef+talkgroupRef
 // pair active in their livefeed. Used for cross-talkgroup duplicate filtering.
 func (livefeed *Livefeed) IsEnabledForRef(systemRef, talkgroupRef uint) bool {
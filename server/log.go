@@ -61,18 +61,8 @@ func (logs *Logs) LogEvent(level string, message string) error {
 	logs.mutex.Lock()
 	defer logs.mutex.Unlock()
 
-	if logs.daemon != nil {
-		switch level {
-		case LogLevelError:
-			logs.daemon.Logger.Error(message)
-		case LogLevelWarn:
-			logs.daemon.Logger.Warning(message)
-		case LogLevelInfo:
-			logs.daemon.Logger.Info(message)
-		}
-
-	} else {
-		writeLogStdout(message)
+	if !logging.event(level, category, message) {
+		logs.console(level, message)
 	}
 
 	if logs.database != nil {
@@ -92,14 +82,31 @@ func (logs *Logs) LogEvent(level string, message string) error {
 	return nil
 }
 
+// console prints a message in the plain format, to the service logger when
+// running as a service.
+func (logs *Logs) console(level string, message string) {
+	if logs.daemon != nil {
+		switch level {
+		case LogLevelError:
+			logs.daemon.Logger.Error(message)
+		case LogLevelWarn:
+			logs.daemon.Logger.Warning(message)
+		default:
+			logs.daemon.Logger.Info(message)
+		}
+		return
+	}
+	writeLogStdout(message)
+}
+
 func (logs *Logs) Prune(db *Database, pruneDays uint) error {
 	logs.mutex.Lock()
 	defer logs.mutex.Unlock()
 
 	timestamp := time.Now().Add(-24 * time.Hour * time.Duration(pruneDays)).UnixMilli()
-	query := fmt.Sprintf(`DELETE FROM "logs" WHERE "timestamp" < %d`, timestamp)
+	query := `DELETE FROM "logs" WHERE "timestamp" < $1`
 
-	if _, err := db.Sql.Exec(query); err != nil {
+	if _, err := db.Sql.Exec(query, timestamp); err != nil {
 		return fmt.Errorf("%s in %s", err, query)
 	}
 
@@ -162,6 +169,7 @@ func (logs *Logs) Search(searchOptions *LogsSearchOptions, db *Database) (*LogsS
 		order  string
 		query  string
 
+		args            = queryArgs{}
 		whereConditions []string
 
 		level     sql.NullString
@@ -186,16 +194,16 @@ func (logs *Logs) Search(searchOptions *LogsSearchOptions, db *Database) (*LogsS
 	// Level filter
 	switch v := searchOptions.Level.(type) {
 	case string:
-		whereConditions = append(whereConditions, fmt.Sprintf(`"level" = '%s'`, escapeSQLString(v)))
+		whereConditions = append(whereConditions, `"level" = `+args.add(v))
 	}
 
 	// Category filter
 	if cats := FilterLogCategories(searchOptions.Categories); len(cats) > 0 {
-		quoted := make([]string, len(cats))
+		placeholders := make([]string, len(cats))
 		for i, c := range cats {
-			quoted[i] = "'" + escapeSQLString(c) + "'"
+			placeholders[i] = args.add(c)
 		}
-		whereConditions = append(whereConditions, `"category" IN (`+strings.Join(quoted, ",")+`)`)
+		whereConditions = append(whereConditions, `"category" IN (`+strings.Join(placeholders, ", ")+`)`)
 	}
 
 	// Keyword / text search filter — case-insensitive substring match on the message.
@@ -205,7 +213,7 @@ func (logs *Logs) Search(searchOptions *LogsSearchOptions, db *Database) (*LogsS
 			escaped := strings.ReplaceAll(v, `\`, `\\`)
 			escaped = strings.ReplaceAll(escaped, `%`, `\%`)
 			escaped = strings.ReplaceAll(escaped, `_`, `\_`)
-			whereConditions = append(whereConditions, fmt.Sprintf(`"message" ILIKE '%%%s%%' ESCAPE '\'`, escaped))
+			whereConditions = append(whereConditions, `"message" ILIKE `+args.add("%"+escaped+"%")+` ESCAPE '\'`)
 		}
 	}
 
@@ -222,16 +230,16 @@ func (logs *Logs) Search(searchOptions *LogsSearchOptions, db *Database) (*LogsS
 	}
 
 	const maxSafeTimestampMs = int64(253402300800000)
-	whereConditions = append(whereConditions, fmt.Sprintf(`"timestamp" > 0 AND "timestamp" < %d`, maxSafeTimestampMs))
+	whereConditions = append(whereConditions, `"timestamp" > 0 AND "timestamp" < `+args.add(maxSafeTimestampMs))
 
 	// Date filter
 	switch v := searchOptions.Date.(type) {
 	case time.Time:
-		whereConditions = append(whereConditions, fmt.Sprintf(`"timestamp" >= %d`, v.UnixMilli()))
+		whereConditions = append(whereConditions, `"timestamp" >= `+args.add(v.UnixMilli()))
 	default:
 		if order == descOrder {
 			defaultLookback := time.Now().Add(-24 * time.Hour)
-			whereConditions = append(whereConditions, fmt.Sprintf(`"timestamp" >= %d`, defaultLookback.UnixMilli()))
+			whereConditions = append(whereConditions, `"timestamp" >= `+args.add(defaultLookback.UnixMilli()))
 		}
 	}
 
@@ -254,12 +262,12 @@ func (logs *Logs) Search(searchOptions *LogsSearchOptions, db *Database) (*LogsS
 
 	queryLimit := limit + 1
 
-	query = fmt.Sprintf(`SELECT "logId", "level", "category", "message", "timestamp" FROM "logs" WHERE %s ORDER BY "timestamp" %s LIMIT %d OFFSET %d`, where, order, queryLimit, offset)
+	query = fmt.Sprintf(`SELECT "logId", "level", "category", "message", "timestamp" FROM "logs" WHERE %s ORDER BY "timestamp" %s LIMIT %s OFFSET %s`, where, order, args.add(queryLimit), args.add(offset))
 
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
-	if rows, err = db.Sql.QueryContext(ctx, query); err != nil && err != sql.ErrNoRows {
+	if rows, err = db.Sql.QueryContext(ctx, query, args...); err != nil && err != sql.ErrNoRows {
 		return nil, formatError(err, query)
 	}
 	defer rows.Close()
@@ -329,10 +337,6 @@ func (logs *Logs) Search(searchOptions *LogsSearchOptions, db *Database) (*LogsS
 	return logResults, nil
 }
 
-func escapeSQLString(s string) string {
-	return strings.ReplaceAll(s, `'`, `''`)
-}
-
 func (logs *Logs) setDaemon(d *Daemon) {
 	logs.daemon = d
 }
@@ -167,7 +167,8 @@ func categorizeAPIPrefix(body, full string) string {
 
 	// Call upload / ingest pipeline only
 	if strings.Contains(lower, "[upload") || strings.Contains(lower, "[tr-upload") ||
-		strings.Contains(lower, "incomplete call data") || strings.Contains(lower, "handlecall") ||
+		strings.Contains(lower, "incomplete call data") || strings.Contains(lower, "rejected audio") ||
+		strings.Contains(lower, "handlecall") ||
 		strings.Contains(lower, "passing to handlecall") ||
 		strings.Contains(fullLower, "/api/call-upload") || strings.Contains(fullLower, "/api/trunk-recorder") {
 		return LogCategoryCalls
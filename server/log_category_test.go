@@ -24,6 +24,7 @@ func TestCategorizeLogMessage(t *testing.T) {
 		{"api: Public registration group not found", LogCategoryUsers},
 		{"api: [UPLOAD PARSED] -> Valid, passing to HandleCall", LogCategoryCalls},
 		{"api: Incomplete call data: missing audio | SystemId=1 TalkgroupId=2 AudioLen=0", LogCategoryCalls},
+		{"api: Rejected audio: file contains a video stream (system 1 talkgroup 2)", LogCategoryCalls},
 		{"downstream: system=1 talkgroup=2 file=x to http://x success", LogCategoryDownstream},
 		{"no-audio check OK: system 'Fire' within threshold", LogCategoryHealth},
 		{"options changed", LogCategoryAdmin},
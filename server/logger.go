@@ -0,0 +1,302 @@
+// Copyright (C) 2026 Thinline Dynamic Solutions
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	LogFormatPlain = "plain" // the historical one-line messages
+	LogFormatText  = "text"  // slog key=value lines
+	LogFormatJSON  = "json"  // one JSON object per line
+)
+
+// LoggingConfig controls the console output of the logs: the minimum level,
+// the format, and per-subsystem levels that override the minimum. The event
+// log in the database is not affected: it keeps every info, warning and
+// error entry so the admin log viewer sees the same thing whatever the
+// console verbosity.
+type LoggingConfig struct {
+	Level      slog.Level
+	Format     string
+	Subsystems map[string]slog.Level
+}
+
+func defaultLoggingConfig() LoggingConfig {
+	return LoggingConfig{Level: slog.LevelInfo, Format: LogFormatPlain}
+}
+
+func parseLogLevel(value string) (slog.Level, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "", "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	}
+	return slog.LevelInfo, fmt.Errorf("unknown log level %q", value)
+}
+
+// parseLoggingConfig reads the log_level, log_format and log_subsystems
+// settings. Subsystems are given as "tones=debug,radioreference=warn".
+func parseLoggingConfig(level string, format string, subsystems string) (LoggingConfig, error) {
+	config := defaultLoggingConfig()
+
+	var err error
+	if config.Level, err = parseLogLevel(level); err != nil {
+		return defaultLoggingConfig(), err
+	}
+
+	switch format = strings.ToLower(strings.TrimSpace(format)); format {
+	case "":
+	case LogFormatPlain, LogFormatText, LogFormatJSON:
+		config.Format = format
+	default:
+		return defaultLoggingConfig(), fmt.Errorf("unknown log format %q", format)
+	}
+
+	for _, entry := range strings.Split(subsystems, ",") {
+		if entry = strings.TrimSpace(entry); entry == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(entry, "=")
+		if !ok {
+			return defaultLoggingConfig(), fmt.Errorf("invalid log subsystem %q, expected name=level", entry)
+		}
+		subsystemLevel, err := parseLogLevel(value)
+		if err != nil {
+			return defaultLoggingConfig(), err
+		}
+		if config.Subsystems == nil {
+			config.Subsystems = map[string]slog.Level{}
+		}
+		config.Subsystems[strings.ToLower(strings.TrimSpace(name))] = subsystemLevel
+	}
+
+	return config, nil
+}
+
+// levelFor returns the minimum console level of the subsystem.
+func (config LoggingConfig) levelFor(subsystem string) slog.Level {
+	if level, ok := config.Subsystems[subsystem]; ok {
+		return level
+	}
+	return config.Level
+}
+
+// logLevelName maps a slog level to the level names of the event log.
+func logLevelName(level slog.Level) string {
+	switch {
+	case level >= slog.LevelError:
+		return LogLevelError
+	case level >= slog.LevelWarn:
+		return LogLevelWarn
+	case level >= slog.LevelInfo:
+		return LogLevelInfo
+	}
+	return "debug"
+}
+
+// Logging is the process-wide logging setup shared by the subsystem loggers.
+type Logging struct {
+	mutex  sync.RWMutex
+	config LoggingConfig
+	out    io.Writer
+	logs   *Logs
+}
+
+var logging = &Logging{config: defaultLoggingConfig(), out: os.Stderr}
+
+// Configure applies the configuration and sets the event log sink. With the
+// text and JSON formats, output of the standard log package is formatted the
+// same way.
+func (logging *Logging) Configure(config LoggingConfig, logs *Logs) {
+	if config.Format == "" {
+		config.Format = LogFormatPlain
+	}
+
+	logging.mutex.Lock()
+	logging.config = config
+	logging.logs = logs
+	logging.mutex.Unlock()
+
+	if config.Format != LogFormatPlain {
+		slog.SetDefault(slog.New(&logHandler{logging: logging, console: true}))
+	}
+}
+
+func (logging *Logging) current() (LoggingConfig, *Logs) {
+	logging.mutex.RLock()
+	defer logging.mutex.RUnlock()
+	return logging.config, logging.logs
+}
+
+// consoleHandler returns the slog handler of the text and JSON formats.
+func (logging *Logging) consoleHandler(format string) slog.Handler {
+	options := &slog.HandlerOptions{Level: slog.LevelDebug}
+	if format == LogFormatJSON {
+		return slog.NewJSONHandler(logging.out, options)
+	}
+	return slog.NewTextHandler(logging.out, options)
+}
+
+// event writes an event log entry to the console in the text and JSON
+// formats, or drops it below the level of its category. Returns false when
+// LogEvent should print the plain message itself.
+func (logging *Logging) event(level string, category string, message string) bool {
+	config, _ := logging.current()
+
+	slogLevel, _ := parseLogLevel(level)
+	if slogLevel < config.levelFor(category) {
+		return true
+	}
+	if config.Format == LogFormatPlain {
+		return false
+	}
+	logging.consoleHandler(config.Format).Handle(context.Background(), newLogRecord(slogLevel, message, slog.String("category", category)))
+	return true
+}
+
+// Logger returns the logger of a subsystem. Subsystems named after a log
+// category ("tones", "transcription", "radioreference"...) are filed under
+// that category in the event log.
+func Logger(subsystem string) *slog.Logger {
+	return slog.New(&logHandler{logging: logging, subsystem: subsystem})
+}
+
+// logHandler writes records to the console in the configured format and,
+// from the info level, to the event log. console handlers only write to the
+// console; they back the standard log package whose lines the log capture
+// already stores.
+type logHandler struct {
+	logging   *Logging
+	subsystem string
+	attrs     []slog.Attr
+	group     string
+	console   bool
+}
+
+func (handler *logHandler) Enabled(_ context.Context, level slog.Level) bool {
+	config, logs := handler.logging.current()
+	if level >= config.levelFor(handler.subsystem) {
+		return true
+	}
+	return !handler.console && logs != nil && level >= slog.LevelInfo
+}
+
+func (handler *logHandler) Handle(ctx context.Context, record slog.Record) error {
+	config, logs := handler.logging.current()
+
+	attrs := append([]slog.Attr{}, handler.attrs...)
+	record.Attrs(func(attr slog.Attr) bool {
+		if handler.group != "" {
+			attr.Key = handler.group + "." + attr.Key
+		}
+		attrs = append(attrs, attr)
+		return true
+	})
+	message := plainLogMessage(handler.subsystem, record.Message, attrs)
+
+	if record.Level >= config.levelFor(handler.subsystem) {
+		if config.Format == LogFormatPlain {
+			if logs != nil {
+				logs.console(logLevelName(record.Level), message)
+			} else {
+				writeLogStdout(message)
+			}
+		} else {
+			out := newLogRecord(record.Level, record.Message)
+			out.Time = record.Time
+			if handler.subsystem != "" {
+				out.AddAttrs(slog.String("subsystem", handler.subsystem))
+			}
+			out.AddAttrs(attrs...)
+			if err := handler.logging.consoleHandler(config.Format).Handle(ctx, out); err != nil {
+				return err
+			}
+		}
+	}
+
+	if !handler.console && logs != nil && record.Level >= slog.LevelInfo {
+		category := handler.subsystem
+		if !logCategoryAllowSet[category] {
+			category = CategorizeLogMessage(message)
+		}
+		return logs.insertCaptured(logLevelName(record.Level), category, message)
+	}
+	return nil
+}
+
+func (handler *logHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	clone := *handler
+	clone.attrs = append([]slog.Attr{}, handler.attrs...)
+	for _, attr := range attrs {
+		if handler.group != "" {
+			attr.Key = handler.group + "." + attr.Key
+		}
+		clone.attrs = append(clone.attrs, attr)
+	}
+	return &clone
+}
+
+func (handler *logHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return handler
+	}
+	clone := *handler
+	if clone.group != "" {
+		clone.group += "." + name
+	} else {
+		clone.group = name
+	}
+	return &clone
+}
+
+func newLogRecord(level slog.Level, message string, attrs ...slog.Attr) slog.Record {
+	record := slog.NewRecord(time.Now(), level, message, 0)
+	record.AddAttrs(attrs...)
+	return record
+}
+
+// plainLogMessage renders a record the way the plain format and the event
+// log show it: "subsystem: message key=value ...".
+func plainLogMessage(subsystem string, message string, attrs []slog.Attr) string {
+	var b strings.Builder
+	if subsystem != "" {
+		b.WriteString(subsystem)
+		b.WriteString(": ")
+	}
+	b.WriteString(message)
+	for _, attr := range attrs {
+		value := attr.Value.Resolve().String()
+		if strings.ContainsAny(value, " \t\n\"=") {
+			value = fmt.Sprintf("%q", value)
+		}
+		fmt.Fprintf(&b, " %s=%s", attr.Key, value)
+	}
+	return b.String()
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestParseLoggingConfig(t *testing.T) {
+	config, err := parseLoggingConfig("warn", "JSON", "tones=debug, radioreference=error")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if config.Level != slog.LevelWarn || config.Format != LogFormatJSON {
+		t.Fatalf("got level %v format %q", config.Level, config.Format)
+	}
+	if config.levelFor("tones") != slog.LevelDebug || config.levelFor("radioreference") != slog.LevelError {
+		t.Fatalf("subsystem levels not applied: %v", config.Subsystems)
+	}
+	if config.levelFor("transcription") != slog.LevelWarn {
+		t.Fatalf("expected the default level for other subsystems")
+	}
+
+	config, err = parseLoggingConfig("", "", "")
+	if err != nil || config.Level != slog.LevelInfo || config.Format != LogFormatPlain {
+		t.Fatalf("expected defaults, got %+v, %v", config, err)
+	}
+
+	for _, args := range [][3]string{{"verbose", "", ""}, {"", "xml", ""}, {"", "", "tones"}, {"", "", "tones=loud"}} {
+		if _, err := parseLoggingConfig(args[0], args[1], args[2]); err == nil {
+			t.Fatalf("expected an error for %v", args)
+		}
+	}
+}
+
+func TestPlainLogMessage(t *testing.T) {
+	message := plainLogMessage("tones", "matched", []slog.Attr{slog.String("label", "Station 1"), slog.Int("call", 42)})
+	if message != `tones: matched label="Station 1" call=42` {
+		t.Fatalf("got %q", message)
+	}
+}
+
+func TestLoggerJSONSubsystemFilter(t *testing.T) {
+	var out bytes.Buffer
+	config, _ := parseLoggingConfig("info", "json", "tones=warn")
+	instance := &Logging{config: config, out: &out}
+
+	tones := slog.New(&logHandler{logging: instance, subsystem: LogCategoryTones})
+	tones.Info("suppressed")
+	tones.Warn("no decode", "call", 7)
+
+	transcription := slog.New(&logHandler{logging: instance, subsystem: LogCategoryTranscription})
+	transcription.Debug("suppressed")
+	transcription.Info("done")
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got %q", out.String())
+	}
+
+	var entry map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &entry); err != nil {
+		t.Fatalf("invalid json: %v", err)
+	}
+	if entry["msg"] != "no decode" || entry["subsystem"] != "tones" || entry["level"] != "WARN" || entry["call"] != float64(7) {
+		t.Fatalf("unexpected entry %v", entry)
+	}
+}
@@ -0,0 +1,217 @@
+// Copyright (C) 2026 Thinline Dynamic Solutions
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>
+
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// LogicalChannel links talkgroups that carry the same agency on different
+// systems, such as a simulcast talkgroup and its conventional backup.
+// Listeners following any member receive calls from all of them, and a
+// transmission captured on more than one member is only kept once.
+//
+// Members are referenced by system and talkgroup refs so the link survives
+// a system being re-imported.
+type LogicalChannel struct {
+	Id            uint                   `json:"id"`
+	Label         string                 `json:"label"`
+	Members       []LogicalChannelMember `json:"members"`
+	DedupWindowMs uint                   `json:"dedupWindowMs"` // 0 = logicalChannelDedupWindow
+}
+
+type LogicalChannelMember struct {
+	SystemRef    uint `json:"systemRef"`
+	TalkgroupRef uint `json:"talkgroupRef"`
+}
+
+type LogicalChannels []LogicalChannel
+
+// logicalChannelDedupWindow is the default gap allowed between the arrivals of
+// the same transmission on two members. It is wider than
+// receivedAtDuplicateWindow since members are usually fed by different recorders.
+const logicalChannelDedupWindow = 3 * time.Second
+
+// logicalChannelsFromList parses the admin representation. Channels need at
+// least two members, and a talkgroup can only belong to one channel; later
+// duplicates are ignored.
+func logicalChannelsFromList(list []any) LogicalChannels {
+	channels := LogicalChannels{}
+	claimed := map[LogicalChannelMember]bool{}
+
+	for _, item := range list {
+		m, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+
+		channel := LogicalChannel{}
+		if v, ok := m["id"].(float64); ok && v > 0 {
+			channel.Id = uint(v)
+		}
+		if v, ok := m["label"].(string); ok {
+			channel.Label = strings.TrimSpace(v)
+		}
+		if v, ok := m["dedupWindowMs"].(float64); ok && v > 0 {
+			channel.DedupWindowMs = uint(v)
+		}
+
+		if members, ok := m["members"].([]any); ok {
+			for _, item := range members {
+				m, ok := item.(map[string]any)
+				if !ok {
+					continue
+				}
+				systemRef, _ := m["systemRef"].(float64)
+				talkgroupRef, _ := m["talkgroupRef"].(float64)
+				if systemRef <= 0 || talkgroupRef <= 0 {
+					continue
+				}
+				member := LogicalChannelMember{SystemRef: uint(systemRef), TalkgroupRef: uint(talkgroupRef)}
+				if claimed[member] {
+					continue
+				}
+				claimed[member] = true
+				channel.Members = append(channel.Members, member)
+			}
+		}
+
+		if len(channel.Members) < 2 {
+			continue
+		}
+
+		channels = append(channels, channel)
+	}
+
+	// Number channels saved without an id after the highest one in use
+	var maxId uint
+	for _, channel := range channels {
+		if channel.Id > maxId {
+			maxId = channel.Id
+		}
+	}
+	for i := range channels {
+		if channels[i].Id == 0 {
+			maxId++
+			channels[i].Id = maxId
+		}
+	}
+
+	return channels
+}
+
+// ForRef returns the channel the talkgroup belongs to, or nil.
+func (channels LogicalChannels) ForRef(systemRef uint, talkgroupRef uint) *LogicalChannel {
+	for i := range channels {
+		for _, member := range channels[i].Members {
+			if member.SystemRef == systemRef && member.TalkgroupRef == talkgroupRef {
+				return &channels[i]
+			}
+		}
+	}
+	return nil
+}
+
+// ForCall returns the channel the call's talkgroup belongs to, or nil.
+func (channels LogicalChannels) ForCall(call *Call) *LogicalChannel {
+	if call == nil || call.System == nil || call.Talkgroup == nil {
+		return nil
+	}
+	return channels.ForRef(call.System.SystemRef, call.Talkgroup.TalkgroupRef)
+}
+
+// GetScopedChannels returns the channels for a client config, keeping only
+// the members present in systemsMap. Channels left with a single member are
+// dropped as there is nothing to link.
+func (channels LogicalChannels) GetScopedChannels(systemsMap *SystemsMap) []map[string]any {
+	visible := map[LogicalChannelMember]bool{}
+	for _, system := range *systemsMap {
+		systemRef, _ := system["id"].(uint)
+		talkgroups, _ := system["talkgroups"].(TalkgroupsMap)
+		for _, talkgroup := range talkgroups {
+			if talkgroupRef, ok := talkgroup["id"].(uint); ok {
+				visible[LogicalChannelMember{SystemRef: systemRef, TalkgroupRef: talkgroupRef}] = true
+			}
+		}
+	}
+
+	scoped := []map[string]any{}
+	for _, channel := range channels {
+		members := []LogicalChannelMember{}
+		for _, member := range channel.Members {
+			if visible[member] {
+				members = append(members, member)
+			}
+		}
+		if len(members) < 2 {
+			continue
+		}
+		scoped = append(scoped, map[string]any{
+			"id":      channel.Id,
+			"label":   channel.Label,
+			"members": members,
+		})
+	}
+	return scoped
+}
+
+func (channel *LogicalChannel) dedupWindow() time.Duration {
+	if channel.DedupWindowMs > 0 {
+		return time.Duration(channel.DedupWindowMs) * time.Millisecond
+	}
+	return logicalChannelDedupWindow
+}
+
+// livefeedAllows is Livefeed.IsEnabled extended to logical channels: a call is
+// delivered when the client follows its talkgroup or any other member of the
+// talkgroup's channel.
+func (controller *Controller) livefeedAllows(livefeed *Livefeed, call *Call) bool {
+	if livefeed.IsEnabled(call) {
+		return true
+	}
+
+	channel := controller.Options.LogicalChannels.ForCall(call)
+	if channel == nil {
+		return false
+	}
+	for _, member := range channel.Members {
+		if livefeed.IsEnabledForRef(member.SystemRef, member.TalkgroupRef) {
+			return true
+		}
+	}
+	return false
+}
+
+// isLogicalChannelDuplicate reports whether the same transmission was already
+// received on another member of the call's logical channel.
+func (controller *Controller) isLogicalChannelDuplicate(call *Call) bool {
+	channel := controller.Options.LogicalChannels.ForCall(call)
+	if channel == nil || controller.DedupCache == nil {
+		return false
+	}
+
+	// Same guard as the receivedAt database pass: without a duration two
+	// unrelated transmissions can't be told apart.
+	duration, err := controller.getCallDuration(call)
+	if err != nil {
+		duration = 0
+	}
+
+	member := fmt.Sprintf("%d:%d", call.System.SystemRef, call.Talkgroup.TalkgroupRef)
+	return controller.DedupCache.CheckAndMarkLogicalChannel(channel.Id, member, duration, channel.dedupWindow())
+}
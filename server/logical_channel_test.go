@@ -0,0 +1,87 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestLogicalChannelsFromList(t *testing.T) {
+	var list []any
+	if err := json.Unmarshal([]byte(`[
+		{"id": 4, "label": "County Fire", "members": [
+			{"systemRef": 1, "talkgroupRef": 100},
+			{"systemRef": 2, "talkgroupRef": 7}
+		]},
+		{"label": "County EMS", "dedupWindowMs": 5000, "members": [
+			{"systemRef": 1, "talkgroupRef": 200},
+			{"systemRef": 2, "talkgroupRef": 7},
+			{"systemRef": 3, "talkgroupRef": 9}
+		]},
+		{"label": "Lonely", "members": [{"systemRef": 1, "talkgroupRef": 300}]}
+	]`), &list); err != nil {
+		t.Fatal(err)
+	}
+
+	channels := logicalChannelsFromList(list)
+	if len(channels) != 2 {
+		t.Fatalf("expected 2 channels, got %d", len(channels))
+	}
+	if channels[1].Id != 5 {
+		t.Fatalf("expected new channel to get id 5, got %d", channels[1].Id)
+	}
+	if len(channels[1].Members) != 2 {
+		t.Fatalf("talkgroup already linked to another channel should be skipped, got %+v", channels[1].Members)
+	}
+	if channels[1].dedupWindow() != 5*time.Second || channels[0].dedupWindow() != logicalChannelDedupWindow {
+		t.Fatalf("unexpected dedup windows %v %v", channels[0].dedupWindow(), channels[1].dedupWindow())
+	}
+
+	if channel := channels.ForRef(2, 7); channel == nil || channel.Id != 4 {
+		t.Fatalf("expected 2/7 to belong to channel 4, got %+v", channel)
+	}
+	if channels.ForRef(1, 300) != nil {
+		t.Fatal("single-member channel should not be linked")
+	}
+}
+
+func TestLogicalChannelLivefeed(t *testing.T) {
+	controller := &Controller{Options: &Options{LogicalChannels: LogicalChannels{{
+		Id:      1,
+		Members: []LogicalChannelMember{{SystemRef: 1, TalkgroupRef: 100}, {SystemRef: 2, TalkgroupRef: 7}},
+	}}}}
+
+	livefeed := NewLivefeed()
+	livefeed.Matrix[1] = map[uint]bool{100: true}
+
+	backup := &Call{System: &System{SystemRef: 2}, Talkgroup: &Talkgroup{TalkgroupRef: 7}}
+	if !controller.livefeedAllows(livefeed, backup) {
+		t.Fatal("call on the backup talkgroup should reach listeners of the primary")
+	}
+
+	other := &Call{System: &System{SystemRef: 2}, Talkgroup: &Talkgroup{TalkgroupRef: 8}}
+	if controller.livefeedAllows(livefeed, other) {
+		t.Fatal("unlinked talkgroup should not be delivered")
+	}
+}
+
+func TestDedupCacheLogicalChannel(t *testing.T) {
+	dc := NewDedupCache(0)
+	defer dc.Stop()
+
+	if dc.CheckAndMarkLogicalChannel(1, "1:100", 4.2, time.Second) {
+		t.Fatal("first call should not be a duplicate")
+	}
+	if dc.CheckAndMarkLogicalChannel(1, "1:100", 4.2, time.Second) {
+		t.Fatal("back-to-back calls on the same member are distinct")
+	}
+	if dc.CheckAndMarkLogicalChannel(1, "2:7", 9.5, time.Second) {
+		t.Fatal("different duration should not be a duplicate")
+	}
+	if !dc.CheckAndMarkLogicalChannel(1, "1:100", 9.3, time.Second) {
+		t.Fatal("same transmission on another member should be a duplicate")
+	}
+	if dc.CheckAndMarkLogicalChannel(2, "3:1", 9.3, time.Second) {
+		t.Fatal("channels are independent")
+	}
+}
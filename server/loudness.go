@@ -0,0 +1,330 @@
+// Copyright (C) 2026 Thinline Dynamic Solutions
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+)
+
+const (
+	// Sources are compared against a common speech loudness reference
+	loudnessReferenceLUFS = -16.0
+	loudnessToleranceDB   = 2.0
+	loudnessPeakCeiling   = -1.0
+	loudnessClippingPeak  = -0.5
+	loudnessMinSamples    = 20
+	loudnessRetentionDays = 30
+)
+
+// LoudnessMeasurement is the loudness of one call, before conversion.
+type LoudnessMeasurement struct {
+	Loudness float64 // integrated loudness, LUFS
+	Peak     float64 // maximum level, dBFS
+}
+
+// LoudnessTarget normalizes the audio uploaded by one source to Target LUFS.
+// ApiKeyId and SystemRef of 0 match any key or system; the most specific
+// target applies.
+type LoudnessTarget struct {
+	ApiKeyId  uint64  `json:"apiKeyId"`
+	SystemRef uint    `json:"systemRef"`
+	Target    float64 `json:"target"`
+}
+
+type LoudnessTargets []LoudnessTarget
+
+// loudnessTargetsFromList drops targets outside -70..-5 LUFS.
+func loudnessTargetsFromList(list []any) LoudnessTargets {
+	targets := LoudnessTargets{}
+
+	for _, item := range list {
+		m, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+
+		target := LoudnessTarget{}
+		if v, ok := m["apiKeyId"].(float64); ok && v > 0 {
+			target.ApiKeyId = uint64(v)
+		}
+		if v, ok := m["systemRef"].(float64); ok && v > 0 {
+			target.SystemRef = uint(v)
+		}
+		if v, ok := m["target"].(float64); ok {
+			target.Target = v
+		}
+
+		if target.Target < -70 || target.Target > -5 {
+			continue
+		}
+		targets = append(targets, target)
+	}
+
+	return targets
+}
+
+// ForSource returns the target for uploads with apiKeyId to systemRef.
+func (targets LoudnessTargets) ForSource(apiKeyId uint64, systemRef uint) (float64, bool) {
+	best := -1
+	var found float64
+	for _, target := range targets {
+		if (target.ApiKeyId != 0 && target.ApiKeyId != apiKeyId) || (target.SystemRef != 0 && target.SystemRef != systemRef) {
+			continue
+		}
+		score := 0
+		if target.ApiKeyId != 0 {
+			score += 2
+		}
+		if target.SystemRef != 0 {
+			score++
+		}
+		if score > best {
+			best = score
+			found = target.Target
+		}
+	}
+	return found, best >= 0
+}
+
+// ForCall returns the target for the source of call.
+func (targets LoudnessTargets) ForCall(call *Call) (float64, bool) {
+	if call == nil || call.System == nil {
+		return 0, false
+	}
+	var apiKeyId uint64
+	if call.ApiKeyId != nil {
+		apiKeyId = *call.ApiKeyId
+	}
+	return targets.ForSource(apiKeyId, call.System.SystemRef)
+}
+
+// recordLoudness measures the raw audio of a call and stores it for the
+// loudness report.
+func (controller *Controller) recordLoudness(systemId uint64, apiKeyId *uint64, audio []byte) {
+	measurement, err := controller.FFMpeg.MeasureLoudness(audio)
+	if err != nil {
+		return
+	}
+	// Silence has no integrated loudness
+	if math.IsInf(measurement.Loudness, 0) || measurement.Loudness <= -70 {
+		return
+	}
+
+	var keyId uint64
+	if apiKeyId != nil {
+		keyId = *apiKeyId
+	}
+
+	if _, err := controller.Database.Sql.Exec(`INSERT INTO "loudnessSamples" ("systemId", "apiKeyId", "loudness", "peak", "createdAt") VALUES ($1, $2, $3, $4, $5)`, systemId, keyId, measurement.Loudness, measurement.Peak, time.Now().UnixMilli()); err != nil {
+		controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("failed to store loudness sample: %v", err))
+	}
+}
+
+// PruneLoudnessSamples drops samples older than the report can look back.
+func (controller *Controller) PruneLoudnessSamples() error {
+	cutoff := time.Now().Add(-24 * time.Hour * loudnessRetentionDays).UnixMilli()
+
+	_, err := controller.Database.Sql.Exec(`DELETE FROM "loudnessSamples" WHERE "createdAt" < $1`, cutoff)
+	return err
+}
+
+// loudnessDayRow is the daily aggregate of one source.
+type loudnessDayRow struct {
+	ApiKeyId uint64
+	SystemId uint64
+	Day      int64 // days since the epoch
+	Samples  uint64
+	Loudness float64
+	Peak     float64
+}
+
+type LoudnessDay struct {
+	Date     string  `json:"date"`
+	Samples  uint64  `json:"samples"`
+	Loudness float64 `json:"loudness"`
+}
+
+// LoudnessSourceReport is the loudness of one upload source, an API key
+// uploading to a system.
+type LoudnessSourceReport struct {
+	ApiKeyId        uint64        `json:"apiKeyId"`
+	ApiKeyIdent     string        `json:"apiKeyIdent,omitempty"`
+	SystemId        uint64        `json:"systemId"`
+	SystemRef       uint          `json:"systemRef"`
+	SystemLabel     string        `json:"systemLabel"`
+	Samples         uint64        `json:"samples"`
+	Loudness        float64       `json:"loudness"`
+	Peak            float64       `json:"peak"`
+	Status          string        `json:"status"` // insufficient, ok, raise, lower, clipping, normalize
+	RecommendedGain float64       `json:"recommendedGain"`
+	Target          *float64      `json:"target,omitempty"`
+	Days            []LoudnessDay `json:"days"`
+}
+
+// loudnessRecommendation returns the recorder gain change in dB bringing a
+// source to the reference, rounded to half a dB, and what it means.
+func loudnessRecommendation(loudness float64, peak float64, samples uint64) (float64, string) {
+	if samples < loudnessMinSamples {
+		return 0, "insufficient"
+	}
+
+	gain := loudnessReferenceLUFS - loudness
+
+	if peak > loudnessClippingPeak {
+		return math.Round(math.Min(gain, -3)*2) / 2, "clipping"
+	}
+	if math.Abs(gain) <= loudnessToleranceDB {
+		return 0, "ok"
+	}
+	if gain < 0 {
+		return math.Round(gain*2) / 2, "lower"
+	}
+
+	// Raising the gain must leave headroom below the peaks; past that only
+	// normalization can make the source louder
+	if peak+gain > loudnessPeakCeiling {
+		gain = loudnessPeakCeiling - peak
+		if gain <= loudnessToleranceDB {
+			return math.Round(math.Max(gain, 0)*2) / 2, "normalize"
+		}
+	}
+	return math.Round(gain*2) / 2, "raise"
+}
+
+// buildLoudnessReport folds the daily rows into one report per source, the
+// sources furthest from the reference first.
+func (controller *Controller) buildLoudnessReport(rows []loudnessDayRow) []*LoudnessSourceReport {
+	type sourceKey struct {
+		apiKeyId uint64
+		systemId uint64
+	}
+
+	sources := map[sourceKey]*LoudnessSourceReport{}
+	var reports []*LoudnessSourceReport
+
+	for _, row := range rows {
+		key := sourceKey{row.ApiKeyId, row.SystemId}
+		report := sources[key]
+		if report == nil {
+			report = &LoudnessSourceReport{ApiKeyId: row.ApiKeyId, SystemId: row.SystemId, Days: []LoudnessDay{}}
+			report.ApiKeyIdent, report.SystemRef, report.SystemLabel = controller.uploadSourceLabels(row.ApiKeyId, row.SystemId)
+			sources[key] = report
+			reports = append(reports, report)
+		}
+
+		// Weighted by samples so busy days count more
+		total := report.Samples + row.Samples
+		if total > 0 {
+			report.Loudness = (report.Loudness*float64(report.Samples) + row.Loudness*float64(row.Samples)) / float64(total)
+			report.Peak = (report.Peak*float64(report.Samples) + row.Peak*float64(row.Samples)) / float64(total)
+		}
+		report.Samples = total
+		report.Days = append(report.Days, LoudnessDay{
+			Date:     time.Unix(row.Day*24*60*60, 0).UTC().Format("2006-01-02"),
+			Samples:  row.Samples,
+			Loudness: math.Round(row.Loudness*10) / 10,
+		})
+	}
+
+	for _, report := range reports {
+		report.RecommendedGain, report.Status = loudnessRecommendation(report.Loudness, report.Peak, report.Samples)
+		report.Loudness = math.Round(report.Loudness*10) / 10
+		report.Peak = math.Round(report.Peak*10) / 10
+		if target, ok := controller.Options.LoudnessTargets.ForSource(report.ApiKeyId, report.SystemRef); ok {
+			report.Target = &target
+		}
+		sort.Slice(report.Days, func(i int, j int) bool { return report.Days[i].Date < report.Days[j].Date })
+	}
+
+	sort.SliceStable(reports, func(i int, j int) bool {
+		return math.Abs(loudnessReferenceLUFS-reports[i].Loudness) > math.Abs(loudnessReferenceLUFS-reports[j].Loudness)
+	})
+
+	return reports
+}
+
+// LoudnessReportHandler reports the loudness of each upload source with a
+// recommended recorder gain change. GET /api/admin/loudness?days=<n>; days
+// defaults to 7 and is capped at the 30 days of samples kept.
+func (admin *Admin) LoudnessReportHandler(w http.ResponseWriter, r *http.Request) {
+	t := admin.GetAuthorization(r)
+	if !admin.ValidateToken(t) {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	writeError := func(status int, message string) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(map[string]string{"error": message})
+	}
+
+	days := 7
+	if s := r.URL.Query().Get("days"); s != "" {
+		v, err := strconv.Atoi(s)
+		if err != nil || v < 1 {
+			writeError(http.StatusBadRequest, "days must be a positive number")
+			return
+		}
+		days = min(v, loudnessRetentionDays)
+	}
+	since := time.Now().Add(-24 * time.Hour * time.Duration(days)).UnixMilli()
+
+	rows, err := admin.Controller.Database.Sql.Query(`SELECT "apiKeyId", "systemId", "createdAt" / 86400000 AS "day", COUNT(*), AVG("loudness"), AVG("peak") FROM "loudnessSamples" WHERE "createdAt" >= $1 GROUP BY "apiKeyId", "systemId", "day"`, since)
+	if err != nil {
+		writeError(http.StatusInternalServerError, err.Error())
+		return
+	}
+	defer rows.Close()
+
+	var dayRows []loudnessDayRow
+	for rows.Next() {
+		row := loudnessDayRow{}
+		if err := rows.Scan(&row.ApiKeyId, &row.SystemId, &row.Day, &row.Samples, &row.Loudness, &row.Peak); err != nil {
+			writeError(http.StatusInternalServerError, err.Error())
+			return
+		}
+		dayRows = append(dayRows, row)
+	}
+	if err := rows.Err(); err != nil {
+		writeError(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	reports := admin.Controller.buildLoudnessReport(dayRows)
+	if reports == nil {
+		reports = []*LoudnessSourceReport{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"enabled":   admin.Controller.Options.LoudnessAnalysisEnabled,
+		"days":      days,
+		"reference": loudnessReferenceLUFS,
+		"sources":   reports,
+	})
+}
@@ -0,0 +1,111 @@
+package main
+
+import "testing"
+
+func TestParseLoudnessOutput(t *testing.T) {
+	output := `[Parsed_volumedetect_0 @ 0x1] mean_volume: -27.3 dB
+[Parsed_volumedetect_0 @ 0x1] max_volume: -6.2 dB
+[Parsed_ebur128_1 @ 0x2] t: 0.4  TARGET:-23 LUFS  M: -30.1 S:-120.7  I: -30.1 LUFS  LRA:   0.0 LU
+[Parsed_ebur128_1 @ 0x2] Summary:
+
+  Integrated loudness:
+    I:         -24.6 LUFS
+    Threshold: -35.0 LUFS
+`
+	measurement, err := parseLoudnessOutput(output)
+	if err != nil {
+		t.Fatalf("parseLoudnessOutput: %v", err)
+	}
+	if measurement.Loudness != -24.6 || measurement.Peak != -6.2 {
+		t.Fatalf("got %+v", measurement)
+	}
+
+	if _, err := parseLoudnessOutput("no audio"); err == nil {
+		t.Fatalf("expected an error without a summary")
+	}
+}
+
+func TestLoudnessRecommendation(t *testing.T) {
+	cases := []struct {
+		loudness float64
+		peak     float64
+		samples  uint64
+		gain     float64
+		status   string
+	}{
+		{-30, -12, 5, 0, "insufficient"},
+		{-17, -6, 100, 0, "ok"},
+		{-26, -15, 100, 10, "raise"},
+		{-26, -8, 100, 7, "raise"},
+		{-20, -2, 100, 1, "normalize"},
+		{-10, -3, 100, -6, "lower"},
+		{-12, -0.1, 100, -4, "clipping"},
+		{-20, 0, 100, -3, "clipping"},
+	}
+	for _, c := range cases {
+		gain, status := loudnessRecommendation(c.loudness, c.peak, c.samples)
+		if gain != c.gain || status != c.status {
+			t.Fatalf("loudnessRecommendation(%v, %v, %v) = %v %q, want %v %q", c.loudness, c.peak, c.samples, gain, status, c.gain, c.status)
+		}
+	}
+}
+
+func TestLoudnessTargetsForSource(t *testing.T) {
+	targets := loudnessTargetsFromList([]any{
+		map[string]any{"target": -16.0},
+		map[string]any{"systemRef": 5.0, "target": -18.0},
+		map[string]any{"apiKeyId": 2.0, "target": -14.0},
+		map[string]any{"apiKeyId": 2.0, "systemRef": 5.0, "target": -20.0},
+		map[string]any{"apiKeyId": 3.0, "target": 0.0},
+	})
+	if len(targets) != 4 {
+		t.Fatalf("expected the out of range target to be dropped, got %d", len(targets))
+	}
+
+	cases := []struct {
+		apiKeyId  uint64
+		systemRef uint
+		want      float64
+	}{
+		{1, 1, -16},
+		{1, 5, -18},
+		{2, 1, -14},
+		{2, 5, -20},
+	}
+	for _, c := range cases {
+		got, ok := targets.ForSource(c.apiKeyId, c.systemRef)
+		if !ok || got != c.want {
+			t.Fatalf("ForSource(%d, %d) = %v %v, want %v", c.apiKeyId, c.systemRef, got, ok, c.want)
+		}
+	}
+
+	if _, ok := (LoudnessTargets{}).ForSource(1, 1); ok {
+		t.Fatalf("expected no target")
+	}
+}
+
+func TestBuildLoudnessReport(t *testing.T) {
+	controller := &Controller{Options: NewOptions(), Systems: NewSystems()}
+
+	reports := controller.buildLoudnessReport([]loudnessDayRow{
+		{ApiKeyId: 1, SystemId: 1, Day: 20000, Samples: 10, Loudness: -20, Peak: -10},
+		{ApiKeyId: 1, SystemId: 1, Day: 19999, Samples: 30, Loudness: -16, Peak: -6},
+		{ApiKeyId: 2, SystemId: 1, Day: 20000, Samples: 50, Loudness: -30, Peak: -14},
+	})
+	if len(reports) != 2 {
+		t.Fatalf("expected 2 sources, got %d", len(reports))
+	}
+
+	quiet := reports[0]
+	if quiet.ApiKeyId != 2 || quiet.Status != "raise" || quiet.RecommendedGain != 13 {
+		t.Fatalf("quiet source: %+v", quiet)
+	}
+
+	mixed := reports[1]
+	if mixed.Samples != 40 || mixed.Loudness != -17 || mixed.Peak != -7 || mixed.Status != "ok" {
+		t.Fatalf("mixed source: %+v", mixed)
+	}
+	if len(mixed.Days) != 2 || mixed.Days[0].Date != "2024-10-03" {
+		t.Fatalf("days: %+v", mixed.Days)
+	}
+}
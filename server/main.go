@@ -23,12 +23,12 @@ import (
 	"fmt"
 	"log"
 	"mime"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
 	"path"
 	"path/filepath"
-	"regexp"
 	"runtime"
 	"strconv"
 	"strings"
@@ -44,38 +44,54 @@ import (
 // Supports lines in the format KEY=VALUE, optional quotes, ignores comments and blank lines.
 func loadDotEnv(candidates ...string) {
 	for _, filePath := range candidates {
-		f, err := os.Open(filePath)
+		vars, err := readEnvFile(filePath)
 		if err != nil {
 			continue
 		}
-		defer f.Close()
-
-		scanner := bufio.NewScanner(f)
-		for scanner.Scan() {
-			line := strings.TrimSpace(scanner.Text())
-			if line == "" || strings.HasPrefix(line, "#") {
-				continue
-			}
-			if strings.HasPrefix(line, "export ") {
-				line = strings.TrimSpace(strings.TrimPrefix(line, "export "))
-			}
-			idx := strings.Index(line, "=")
-			if idx <= 0 {
-				continue
-			}
-			key := strings.TrimSpace(line[:idx])
-			val := strings.TrimSpace(line[idx+1:])
-			if len(val) >= 2 {
-				if (val[0] == '"' && val[len(val)-1] == '"') || (val[0] == '\'' && val[len(val)-1] == '\'') {
-					val = val[1 : len(val)-1]
-				}
-			}
+		for key, val := range vars {
 			_ = os.Setenv(key, val)
 		}
 		return // only load the first found file
 	}
 }
 
+// readEnvFile parses KEY=value lines, skipping comments and an optional
+// "export " prefix and stripping matching quotes around values.
+func readEnvFile(filePath string) (map[string]string, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	vars := map[string]string{}
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, "export ") {
+			line = strings.TrimSpace(strings.TrimPrefix(line, "export "))
+		}
+		idx := strings.Index(line, "=")
+		if idx <= 0 {
+			continue
+		}
+		key := strings.TrimSpace(line[:idx])
+		val := strings.TrimSpace(line[idx+1:])
+		if len(val) >= 2 {
+			if (val[0] == '"' && val[len(val)-1] == '"') || (val[0] == '\'' && val[len(val)-1] == '\'') {
+				val = val[1 : len(val)-1]
+			}
+		}
+		vars[key] = val
+	}
+
+	return vars, scanner.Err()
+}
+
 // writeInjectedWebappIndexHTML serves the Angular SPA shell with the same transforms for every
 // entry path: absolute <base href> (uses X-Forwarded-* behind reverse proxies) and
 // window.initialConfig. Without this, deep links such as /admin receive raw index.html; the
@@ -174,6 +190,25 @@ func main() {
 
 	config := NewConfig()
 
+	if config.toneCheck != "" {
+		if err := runToneCheckCommand(config.toneCheck, config.toneCheckSets, os.Stdout); err != nil {
+			log.Printf("ERROR: Tone check failed: %v", err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
+	// Non-interactive setup for Docker/Ansible (no TTY required)
+	if config.setupAuto {
+		result, err := runAutomatedSetup(config, os.Getenv)
+		if err != nil {
+			json.NewEncoder(os.Stdout).Encode(map[string]string{"error": err.Error()})
+			os.Exit(1)
+		}
+		json.NewEncoder(os.Stdout).Encode(result)
+		os.Exit(0)
+	}
+
 	// Check if we should run interactive setup wizard
 	if shouldRunInteractiveSetup(config) {
 		if config.DbName == "" || config.DbUsername == "" {
@@ -221,6 +256,60 @@ func main() {
 		os.Exit(0)
 	}
 
+	if config.configExport != "" || config.configImport != "" {
+		if err := runConfigArchiveCommand(controller, config.configExport, config.configImport, config.configCredentials); err != nil {
+			log.Printf("ERROR: Configuration archive failed: %v", err)
+			os.Exit(1)
+		}
+
+		os.Exit(0)
+	}
+
+	if config.backupFile != "" || config.restoreFile != "" {
+		if err := runBackupCommand(controller, config); err != nil {
+			log.Printf("ERROR: Backup failed: %v", err)
+			os.Exit(1)
+		}
+
+		os.Exit(0)
+	}
+
+	if config.migrateAudio {
+		if err := runAudioMigrationCommand(controller); err != nil {
+			log.Printf("ERROR: Audio migration failed: %v", err)
+			os.Exit(1)
+		}
+
+		os.Exit(0)
+	}
+
+	if config.dedupAudio {
+		if err := runAudioDedupCommand(controller); err != nil {
+			log.Printf("ERROR: Audio dedup failed: %v", err)
+			os.Exit(1)
+		}
+
+		os.Exit(0)
+	}
+
+	if config.migrationsStatus || config.migrateDown != "" {
+		if err := runSchemaMigrationsCommand(controller.Database, config.migrateDown); err != nil {
+			log.Printf("ERROR: Database migration command failed: %v", err)
+			os.Exit(1)
+		}
+
+		os.Exit(0)
+	}
+
+	if config.seedDemo {
+		if err := runSeedDemoCommand(controller); err != nil {
+			log.Printf("ERROR: Demo data seeding failed: %v", err)
+			os.Exit(1)
+		}
+
+		os.Exit(0)
+	}
+
 	// Create a panic recovery middleware
 	recoveryMiddleware := func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -253,7 +342,7 @@ func main() {
 
 	// Helper to wrap handlers with recovery, rate limiting, and security headers
 	wrapHandler := func(handler http.Handler) http.Handler {
-		return securityHeadersWrapper(rateLimitWrapper(recoveryMiddleware(handler)))
+		return securityHeadersWrapper(rateLimitWrapper(recoveryMiddleware(controller.clientVersionMiddleware(handler))))
 	}
 
 	// corsMiddleware adds CORS headers so the Central Management frontend (a different
@@ -263,7 +352,8 @@ func main() {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			w.Header().Set("Access-Control-Allow-Origin", "*")
 			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
-			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Client-Platform, X-Client-Version, X-API-Version")
+			w.Header().Set("Access-Control-Expose-Headers", "X-API-Version")
 			if r.Method == http.MethodOptions {
 				w.WriteHeader(http.StatusNoContent)
 				return
@@ -302,11 +392,15 @@ func main() {
 
 	http.HandleFunc("/api/admin/alerts", wrapHandler(controller.Admin.requireLocalhost(controller.Admin.AlertsHandler)).ServeHTTP)
 
+	http.HandleFunc("/api/admin/units", wrapHandler(controller.Admin.requireLocalhost(controller.Admin.UnitsHandler)).ServeHTTP)
+	http.HandleFunc("/api/admin/call-copies", wrapHandler(controller.Admin.requireLocalhost(controller.Admin.CallCopiesHandler)).ServeHTTP)
+	http.HandleFunc("/api/admin/stats", wrapHandler(controller.Admin.requireLocalhost(controller.Admin.StatsHandler)).ServeHTTP)
 	http.HandleFunc("/api/admin/systemhealth", wrapHandler(controller.Admin.requireLocalhost(controller.Admin.SystemHealthHandler)).ServeHTTP)
 
 	http.HandleFunc("/api/admin/system-no-audio-settings", wrapHandler(controller.Admin.requireLocalhost(controller.Admin.SystemNoAudioSettingsHandler)).ServeHTTP)
 
 	http.HandleFunc("/api/admin/transcription-failures", wrapHandler(controller.Admin.requireLocalhost(controller.Admin.TranscriptionFailuresHandler)).ServeHTTP)
+	http.HandleFunc("/api/admin/transcription/backfill", wrapHandler(controller.Admin.requireLocalhost(controller.Admin.TranscriptionBackfillHandler)).ServeHTTP)
 	http.HandleFunc("/api/admin/transcription-failure-threshold", wrapHandler(controller.Admin.requireLocalhost(controller.Admin.TranscriptionFailureThresholdHandler)).ServeHTTP)
 	http.HandleFunc("/api/admin/transcript-parser", wrapHandler(controller.Admin.requireLocalhost(controller.Admin.TranscriptParserHandler)).ServeHTTP)
 	http.HandleFunc("/api/admin/relay-suspension", wrapHandler(controller.Admin.requireLocalhost(controller.Admin.RelaySuspensionStatusHandler)).ServeHTTP)
@@ -325,6 +419,7 @@ func main() {
 	http.HandleFunc("/api/admin/transcript-review/", wrapHandler(http.HandlerFunc(controller.Admin.TranscriptReviewCallHandler)).ServeHTTP)
 
 	http.HandleFunc("/api/admin/tone-import", wrapHandler(controller.Admin.requireLocalhost(controller.Admin.ToneImportHandler)).ServeHTTP)
+	http.HandleFunc("/api/admin/talkgroup-import", wrapHandler(controller.Admin.requireLocalhost(controller.Admin.TalkgroupImportHandler)).ServeHTTP)
 	http.HandleFunc("/api/admin/sync-tone-sets", wrapHandler(controller.Admin.requireLocalhost(controller.Admin.SyncToneSetsHandler)).ServeHTTP)
 	http.HandleFunc("/api/admin/tone-history-analyze", wrapHandler(controller.Admin.requireLocalhost(controller.Admin.ToneHistoryAnalyzeHandler)).ServeHTTP)
 
@@ -379,6 +474,9 @@ func main() {
 	)
 	http.HandleFunc("/api/admin/login", securityHeadersWrapper(rateLimitWrapper(adminLoginHandler)).ServeHTTP)
 
+	// First run: exchange the console setup token for the administrator credentials (no auth required)
+	http.HandleFunc("/api/admin/setup", securityHeadersWrapper(rateLimitWrapper(recoveryMiddleware(controller.Admin.requireLocalhost(controller.Admin.FirstRunHandler)))).ServeHTTP)
+
 	// Public: tells the admin login page whether password login is disabled (no auth required)
 	http.HandleFunc("/api/admin/login-config", wrapHandler(http.HandlerFunc(controller.Admin.LoginConfigHandler)).ServeHTTP)
 
@@ -410,6 +508,8 @@ func main() {
 			// Check if it's a reset-password endpoint
 		} else if strings.HasSuffix(r.URL.Path, "/reset-password") && r.Method == http.MethodPost {
 			controller.Admin.UserResetPasswordHandler(w, r)
+		} else if strings.HasSuffix(r.URL.Path, "/sessions") || strings.HasSuffix(r.URL.Path, "/logout") {
+			controller.Admin.UserSessionsHandler(w, r)
 		} else if r.Method == http.MethodDelete {
 			controller.Admin.UserDeleteHandler(w, r)
 		} else if r.Method == http.MethodPut {
@@ -436,8 +536,39 @@ func main() {
 	http.HandleFunc("/api/admin/radioreference/talkgroups-by-category", wrapHandler(controller.Admin.requireLocalhost(controller.Admin.RadioReferenceTalkgroupsByCategoryHandler)).ServeHTTP)
 	http.HandleFunc("/api/admin/radioreference/sites", wrapHandler(controller.Admin.requireLocalhost(controller.Admin.RadioReferenceSitesHandler)).ServeHTTP)
 	http.HandleFunc("/api/admin/radioreference/import-to-system", wrapHandler(controller.Admin.requireLocalhost(controller.Admin.RadioReferenceImportToSystemHandler)).ServeHTTP)
+	http.HandleFunc("/api/admin/radioreference/sync", wrapHandler(controller.Admin.requireLocalhost(controller.Admin.RadioReferenceSyncHandler)).ServeHTTP)
+	http.HandleFunc("/api/admin/radioreference/sync/", wrapHandler(controller.Admin.requireLocalhost(controller.Admin.RadioReferenceSyncHandler)).ServeHTTP)
+	http.HandleFunc("/api/admin/receiver-config", wrapHandler(controller.Admin.requireLocalhost(controller.Admin.ReceiverConfigHandler)).ServeHTTP)
 
 	http.HandleFunc("/api/admin/config/reload", wrapHandler(controller.Admin.requireLocalhost(controller.Admin.ConfigReloadHandler)).ServeHTTP)
+	http.HandleFunc("/api/admin/config/archive", wrapHandler(controller.Admin.requireLocalhost(controller.Admin.ConfigArchiveHandler)).ServeHTTP)
+	http.HandleFunc("/api/admin/config/diff", wrapHandler(controller.Admin.requireLocalhost(controller.Admin.ConfigDiffHandler)).ServeHTTP)
+	http.HandleFunc("/api/admin/config/bundle", wrapHandler(controller.Admin.requireLocalhost(controller.Admin.ConfigBundleHandler)).ServeHTTP)
+	http.HandleFunc("/api/admin/outbox", wrapHandler(controller.Admin.requireLocalhost(controller.Admin.OutboxHandler)).ServeHTTP)
+	http.HandleFunc("/api/admin/paging-deliveries", wrapHandler(controller.Admin.requireLocalhost(controller.Admin.PagingDeliveriesHandler)).ServeHTTP)
+	http.HandleFunc("/api/admin/paging-deliveries/", wrapHandler(controller.Admin.requireLocalhost(controller.Admin.PagingDeliveriesHandler)).ServeHTTP)
+	http.HandleFunc("/api/admin/loudness", wrapHandler(controller.Admin.requireLocalhost(controller.Admin.LoudnessReportHandler)).ServeHTTP)
+	http.HandleFunc("/api/admin/feed-quality", wrapHandler(controller.Admin.requireLocalhost(controller.Admin.FeedQualityReportHandler)).ServeHTTP)
+	http.HandleFunc("/api/admin/recording-gaps", wrapHandler(controller.Admin.requireLocalhost(controller.Admin.RecordingGapsHandler)).ServeHTTP)
+	http.HandleFunc("/api/admin/backup", wrapHandler(controller.Admin.requireLocalhost(controller.Admin.BackupHandler)).ServeHTTP)
+	http.HandleFunc("/api/admin/roles", wrapHandler(controller.Admin.requireLocalhost(controller.Admin.RolesHandler)).ServeHTTP)
+	http.HandleFunc("/api/admin/tenants", wrapHandler(controller.Admin.requireLocalhost(controller.Admin.TenantsHandler)).ServeHTTP)
+	http.HandleFunc("/api/admin/billing/comp", wrapHandler(controller.Admin.requireLocalhost(controller.Admin.BillingCompHandler)).ServeHTTP)
+	http.HandleFunc("/api/admin/onboarding", wrapHandler(controller.Admin.requireLocalhost(controller.Admin.OnboardingHandler)).ServeHTTP)
+	http.HandleFunc("/api/admin/retention", wrapHandler(controller.Admin.requireLocalhost(controller.Admin.RetentionHandler)).ServeHTTP)
+	http.HandleFunc("/api/admin/retention/purge", wrapHandler(controller.Admin.requireLocalhost(controller.Admin.RetentionPurgeHandler)).ServeHTTP)
+	http.HandleFunc("/api/admin/capacity", wrapHandler(controller.Admin.requireLocalhost(controller.Admin.StorageCapacityHandler)).ServeHTTP)
+	http.HandleFunc("/api/admin/apikey-stats", wrapHandler(controller.Admin.requireLocalhost(controller.Admin.ApikeyStatsHandler)).ServeHTTP)
+	http.HandleFunc("/api/admin/export/calls", wrapHandler(controller.Admin.requireLocalhost(controller.Admin.CallExportHandler)).ServeHTTP)
+	http.HandleFunc("/api/admin/calls/share", wrapHandler(controller.Admin.requireLocalhost(controller.Admin.CallShareHandler)).ServeHTTP)
+	http.HandleFunc("/api/admin/guest-grants", wrapHandler(controller.Admin.requireLocalhost(controller.Admin.GuestGrantsHandler)).ServeHTTP)
+	http.HandleFunc("/api/admin/embeds", wrapHandler(controller.Admin.requireLocalhost(controller.Admin.PlayerEmbedsHandler)).ServeHTTP)
+	http.HandleFunc("/api/admin/listeners", wrapHandler(controller.Admin.requireLocalhost(controller.Admin.ListenerAnalyticsHandler)).ServeHTTP)
+
+	// Versioned admin API over the handlers above, described at /api/v2/openapi.json
+	http.HandleFunc("/api/v2/", wrapHandler(http.HandlerFunc(controller.Admin.AdminV2Handler)).ServeHTTP)
+	http.HandleFunc("/api/admin/jobs", wrapHandler(controller.Admin.requireLocalhost(controller.Admin.JobsHandler)).ServeHTTP)
+	http.HandleFunc("/api/admin/audio-storage/migrate", wrapHandler(controller.Admin.requireLocalhost(controller.Admin.AudioStorageMigrateHandler)).ServeHTTP)
 
 	// Hallucination detection endpoints
 	http.HandleFunc("/api/admin/hallucinations/suggestions", wrapHandler(controller.Admin.requireLocalhost(controller.Admin.HallucinationSuggestionsHandler)).ServeHTTP)
@@ -453,6 +584,7 @@ func main() {
 		recoveryMiddleware(http.HandlerFunc(controller.Api.UserLoginHandler)),
 	)
 	http.HandleFunc("/api/user/login", securityHeadersWrapper(rateLimitWrapper(userLoginHandler)).ServeHTTP)
+	http.HandleFunc("/api/version", corsMiddleware(wrapHandler(http.HandlerFunc(controller.Api.VersionHandler))).ServeHTTP)
 	http.HandleFunc("/api/public-registration-info", corsMiddleware(wrapHandler(http.HandlerFunc(controller.Api.PublicRegistrationInfoHandler))).ServeHTTP)
 	http.HandleFunc("/api/public-registration-channels", corsMiddleware(wrapHandler(http.HandlerFunc(controller.Api.PublicRegistrationChannelsHandler))).ServeHTTP)
 	http.HandleFunc("/api/registration-settings", wrapHandler(http.HandlerFunc(controller.Api.RegistrationSettingsHandler)).ServeHTTP)
@@ -468,6 +600,8 @@ func main() {
 	http.HandleFunc("/api/user/reset-password", wrapHandler(http.HandlerFunc(controller.Api.ResetPasswordHandler)).ServeHTTP)
 	http.HandleFunc("/api/user/force-password-reset", wrapHandler(http.HandlerFunc(controller.Api.UserForcePasswordResetHandler)).ServeHTTP)
 	http.HandleFunc("/api/user/device-token", wrapHandler(http.HandlerFunc(controller.Api.UserDeviceTokenHandler)).ServeHTTP)
+	http.HandleFunc("/api/user/sessions", wrapHandler(corsMiddleware(http.HandlerFunc(controller.Api.SessionsHandler))).ServeHTTP)
+	http.HandleFunc("/api/user/sessions/", wrapHandler(corsMiddleware(http.HandlerFunc(controller.Api.SessionsHandler))).ServeHTTP)
 	http.HandleFunc("/api/admin/relay-server-auth-key", wrapHandler(http.HandlerFunc(controller.Api.RelayServerAuthKeyHandler)).ServeHTTP)
 
 	// Group admin routes
@@ -527,7 +661,19 @@ func main() {
 	// Alert routes
 	http.HandleFunc("/api/alerts", wrapHandler(corsMiddleware(http.HandlerFunc(controller.Api.AlertsHandler))).ServeHTTP)
 	http.HandleFunc("/api/alerts/preferences", wrapHandler(corsMiddleware(http.HandlerFunc(controller.Api.AlertPreferencesHandler))).ServeHTTP)
+	http.HandleFunc("/api/webhooks", wrapHandler(corsMiddleware(http.HandlerFunc(controller.Api.UserWebhooksHandler))).ServeHTTP)
+	http.HandleFunc("/api/webhooks/", wrapHandler(corsMiddleware(http.HandlerFunc(controller.Api.UserWebhooksHandler))).ServeHTTP)
+	http.HandleFunc("/api/recordings", wrapHandler(corsMiddleware(http.HandlerFunc(controller.Api.RecordingsHandler))).ServeHTTP)
+	http.HandleFunc("/api/recordings/", wrapHandler(corsMiddleware(http.HandlerFunc(controller.Api.RecordingsHandler))).ServeHTTP)
+	http.HandleFunc("/api/alerts/ack", wrapHandler(corsMiddleware(http.HandlerFunc(controller.Api.AlertAckHandler))).ServeHTTP)
 	http.HandleFunc("/api/stats", wrapHandler(corsMiddleware(http.HandlerFunc(controller.Api.StatsHandler))).ServeHTTP)
+	http.HandleFunc("/api/search", wrapHandler(corsMiddleware(http.HandlerFunc(controller.Api.CallSearchHandler))).ServeHTTP)
+	http.HandleFunc("/api/graphql", wrapHandler(corsMiddleware(http.HandlerFunc(controller.Api.GraphQLHandler))).ServeHTTP)
+	http.HandleFunc("/api/playlist", wrapHandler(corsMiddleware(http.HandlerFunc(controller.Api.PlaylistHandler))).ServeHTTP)
+	http.HandleFunc("/api/incidents", wrapHandler(corsMiddleware(http.HandlerFunc(controller.Api.IncidentsHandler))).ServeHTTP)
+	http.HandleFunc("/api/incidents/", wrapHandler(corsMiddleware(http.HandlerFunc(controller.Api.IncidentsHandler))).ServeHTTP)
+	http.HandleFunc("/api/map", wrapHandler(corsMiddleware(http.HandlerFunc(controller.Api.MapHandler))).ServeHTTP)
+	http.HandleFunc("/api/cad", wrapHandler(http.HandlerFunc(controller.Api.CADHandler)).ServeHTTP)
 	http.HandleFunc("/api/transcripts", wrapHandler(corsMiddleware(http.HandlerFunc(controller.Api.TranscriptsHandler))).ServeHTTP)
 	http.HandleFunc("/api/transcripts/training-progress", wrapHandler(corsMiddleware(http.HandlerFunc(controller.Api.TranscriptsTrainingProgressHandler))).ServeHTTP)
 	http.HandleFunc("/api/keyword-lists", wrapHandler(http.HandlerFunc(controller.Api.KeywordListsHandler)).ServeHTTP)
@@ -597,6 +743,7 @@ func main() {
 	http.HandleFunc("/api/account/password/verify-code", wrapHandler(http.HandlerFunc(controller.Api.AccountVerifyPasswordChangeCodeHandler)).ServeHTTP)
 	http.HandleFunc("/api/account/password", wrapHandler(http.HandlerFunc(controller.Api.AccountUpdatePasswordHandler)).ServeHTTP)
 	http.HandleFunc("/api/billing/portal", wrapHandler(http.HandlerFunc(controller.Api.BillingPortalSessionHandler)).ServeHTTP)
+	http.HandleFunc("/api/billing/plan", wrapHandler(http.HandlerFunc(controller.Api.BillingPlanHandler)).ServeHTTP)
 
 	// Log that routes have been registered
 	log.Printf("All HTTP routes registered successfully")
@@ -608,14 +755,30 @@ func main() {
 	// Call upload endpoints - exclude from security headers and rate limiting (machine-to-machine APIs)
 	// These endpoints handle their own validation and need to accept frequent uploads
 	// Match v6 registration pattern exactly - pass handler directly without wrapping
-	http.HandleFunc("/api/call-upload", controller.Api.CallUploadHandler)
+	// They are still limited per IP and per API key (see rateLimitConfig)
+	http.HandleFunc("/api/call-upload", controller.ingestLimitMiddleware(controller.Api.CallUploadHandler))
 
-	http.HandleFunc("/api/trunk-recorder-call-upload", controller.Api.TrunkRecorderCallUploadHandler)
+	http.HandleFunc("/api/trunk-recorder-call-upload", controller.ingestLimitMiddleware(controller.Api.TrunkRecorderCallUploadHandler))
 
 	// Pager-alert audio download — authenticated by admin PIN.
 	// Pattern /api/calls/ also covers /api/calls/{id}/audio.
 	http.HandleFunc("/api/calls/", controller.Api.CallAudioDownloadHandler)
 
+	// Signed audio links in alert emails; no login needed
+	http.HandleFunc("/api/email/audio", controller.Api.EmailAudioHandler)
+
+	// Share links to single calls and guest access to a few talkgroups; no login needed
+	http.HandleFunc("/api/calls/share", corsMiddleware(wrapHandler(http.HandlerFunc(controller.Api.CallShareHandler))).ServeHTTP)
+	http.HandleFunc("/api/share", controller.Api.SharedCallHandler)
+	http.HandleFunc("/api/guest/calls", controller.Api.GuestCallsHandler)
+	http.HandleFunc("/api/guest/calls/", controller.Api.GuestCallsHandler)
+
+	// Public player for department websites, framed from the origins of each embed
+	http.HandleFunc("/embed/", wrapHandler(http.HandlerFunc(controller.Api.PlayerEmbedHandler)).ServeHTTP)
+
+	// Live call metadata stream for third-party dashboards (WebSocket).
+	http.HandleFunc("/api/live", wrapHandler(http.HandlerFunc(controller.Api.CallStreamHandler)).ServeHTTP)
+
 	// Debug page — lists recent calls with audio playback and duplicate flags.
 	// Protected by HTTP Basic Auth using the admin password.
 	http.HandleFunc("/calls", controller.Admin.requireAdminBasicAuth(controller.CallsDebugHandler))
@@ -832,7 +995,7 @@ func main() {
 		// Redirect /admin to root if the client IP is not on the admin allow list
 		requestPath := r.URL.Path
 		if requestPath == "/admin" || strings.HasPrefix(requestPath, "/admin/") {
-			clientIP := GetClientIP(r)
+			clientIP := GetRemoteAddr(r)
 			if !controller.Admin.isAdminIPAllowed(clientIP) {
 				log.Printf("Redirecting %s to / for disallowed IP: %s", requestPath, clientIP)
 				http.Redirect(w, r, "/", http.StatusFound)
@@ -1100,18 +1263,61 @@ func main() {
 	controller.Terminate()
 }
 
+// trustedProxies returns the addresses or CIDRs of the reverse proxies whose
+// forwarding headers are trusted, see initRateLimits.
+var trustedProxies = func() []string { return nil }
+
+// isTrustedProxy tells if ip is a configured reverse proxy. With none
+// configured, only a proxy on the same host is trusted.
+func isTrustedProxy(ip net.IP) bool {
+	if ip == nil {
+		return false
+	}
+	proxies := trustedProxies()
+	if len(proxies) == 0 {
+		return ip.IsLoopback()
+	}
+	for _, proxy := range proxies {
+		if _, network, err := net.ParseCIDR(proxy); err == nil {
+			if network.Contains(ip) {
+				return true
+			}
+		} else if proxyIP := net.ParseIP(proxy); proxyIP != nil && proxyIP.Equal(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetRemoteAddr returns the client IP of the request. X-Forwarded-For and
+// X-Real-IP are only read when the request comes from a trusted proxy, and
+// X-Forwarded-For is walked from the right so a client cannot spoof its
+// address by sending the header itself.
 func GetRemoteAddr(r *http.Request) string {
-	re := regexp.MustCompile(`(.+):.*$`)
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	if !isTrustedProxy(net.ParseIP(host)) {
+		return host
+	}
 
-	for _, addr := range strings.Split(r.Header.Get("X-Forwarded-For"), ",") {
-		if ip := re.ReplaceAllString(addr, "$1"); len(ip) > 0 {
-			return ip
+	if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+		addrs := strings.Split(forwarded, ",")
+		for i := len(addrs) - 1; i >= 0; i-- {
+			ip := net.ParseIP(strings.TrimSpace(addrs[i]))
+			if ip == nil {
+				break
+			}
+			if i == 0 || !isTrustedProxy(ip) {
+				return ip.String()
+			}
 		}
 	}
 
-	if ip := re.ReplaceAllString(r.RemoteAddr, "$1"); len(ip) > 0 {
-		return ip
+	if ip := net.ParseIP(strings.TrimSpace(r.Header.Get("X-Real-IP"))); ip != nil {
+		return ip.String()
 	}
 
-	return r.RemoteAddr
+	return host
 }
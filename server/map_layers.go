@@ -0,0 +1,386 @@
+// Copyright (C) 2026 Thinline Dynamic Solutions
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var mapLog = Logger(LogCategoryCalls)
+
+const (
+	mapDefaultLimit    = 200
+	mapMaxLimit        = 1000
+	mapDefaultLookback = time.Hour
+	mapCoveragePadding = 0.1 // fraction of the site extent added around it
+	mapCoverageMinSpan = 0.05
+)
+
+// GeoJSON types, with coordinates in [longitude, latitude] order.
+type geoJSONFeatureCollection struct {
+	Type     string            `json:"type"`
+	Features []*geoJSONFeature `json:"features"`
+}
+
+type geoJSONFeature struct {
+	Type       string          `json:"type"`
+	Geometry   geoJSONGeometry `json:"geometry"`
+	Properties map[string]any  `json:"properties"`
+}
+
+type geoJSONGeometry struct {
+	Type        string `json:"type"`
+	Coordinates any    `json:"coordinates"`
+}
+
+func geoJSONPoint(latitude float64, longitude float64, properties map[string]any) *geoJSONFeature {
+	return &geoJSONFeature{
+		Type:       "Feature",
+		Geometry:   geoJSONGeometry{Type: "Point", Coordinates: []float64{longitude, latitude}},
+		Properties: properties,
+	}
+}
+
+// mapBBox is a bounding box filter.
+type mapBBox struct {
+	MinLon, MinLat, MaxLon, MaxLat float64
+}
+
+// parseMapBBox parses "minLon,minLat,maxLon,maxLat", the GeoJSON bbox order.
+func parseMapBBox(s string) (*mapBBox, error) {
+	parts := strings.Split(s, ",")
+	if len(parts) != 4 {
+		return nil, fmt.Errorf("invalid bbox %q", s)
+	}
+	values := [4]float64{}
+	for i, part := range parts {
+		v, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid bbox %q", s)
+		}
+		values[i] = v
+	}
+	bbox := &mapBBox{MinLon: values[0], MinLat: values[1], MaxLon: values[2], MaxLat: values[3]}
+	if bbox.MinLat > bbox.MaxLat || bbox.MinLat < -90 || bbox.MaxLat > 90 || bbox.MinLon < -180 || bbox.MaxLon > 180 {
+		return nil, fmt.Errorf("invalid bbox %q", s)
+	}
+	return bbox, nil
+}
+
+// contains reports whether a point is in the box. A box crossing the
+// antimeridian has MinLon greater than MaxLon.
+func (bbox *mapBBox) contains(latitude float64, longitude float64) bool {
+	if bbox == nil {
+		return true
+	}
+	if latitude < bbox.MinLat || latitude > bbox.MaxLat {
+		return false
+	}
+	if bbox.MinLon <= bbox.MaxLon {
+		return longitude >= bbox.MinLon && longitude <= bbox.MaxLon
+	}
+	return longitude >= bbox.MinLon || longitude <= bbox.MaxLon
+}
+
+// parseMapLayers parses layers=calls,sites,coverage. Empty means every layer.
+func parseMapLayers(s string) (map[string]bool, error) {
+	layers := map[string]bool{"calls": true, "sites": true, "coverage": true}
+	if strings.TrimSpace(s) == "" {
+		return layers, nil
+	}
+	selected := map[string]bool{}
+	for _, layer := range strings.Split(s, ",") {
+		layer = strings.ToLower(strings.TrimSpace(layer))
+		if !layers[layer] {
+			return nil, fmt.Errorf("unknown layer %q", layer)
+		}
+		selected[layer] = true
+	}
+	return selected, nil
+}
+
+// siteFeatures returns the located sites of a system.
+func siteFeatures(system *System, bbox *mapBBox) []*geoJSONFeature {
+	features := []*geoJSONFeature{}
+	system.Sites.mutex.Lock()
+	defer system.Sites.mutex.Unlock()
+	for _, site := range system.Sites.List {
+		if !site.HasLocation() || !bbox.contains(site.Latitude, site.Longitude) {
+			continue
+		}
+		features = append(features, geoJSONPoint(site.Latitude, site.Longitude, map[string]any{
+			"layer":       "sites",
+			"system":      system.SystemRef,
+			"systemLabel": system.Label,
+			"siteRef":     site.SiteRef,
+			"label":       site.Label,
+			"rfss":        site.RFSS,
+		}))
+	}
+	return features
+}
+
+// coverageFeature returns the area around the located sites of a system, a
+// rough hint of where its traffic comes from, or nil without located sites.
+func coverageFeature(system *System) *geoJSONFeature {
+	system.Sites.mutex.Lock()
+	var minLat, minLon, maxLat, maxLon float64
+	located := 0
+	for _, site := range system.Sites.List {
+		if !site.HasLocation() {
+			continue
+		}
+		if located == 0 || site.Latitude < minLat {
+			minLat = site.Latitude
+		}
+		if located == 0 || site.Latitude > maxLat {
+			maxLat = site.Latitude
+		}
+		if located == 0 || site.Longitude < minLon {
+			minLon = site.Longitude
+		}
+		if located == 0 || site.Longitude > maxLon {
+			maxLon = site.Longitude
+		}
+		located++
+	}
+	system.Sites.mutex.Unlock()
+	if located == 0 {
+		return nil
+	}
+
+	padLat := (maxLat - minLat) * mapCoveragePadding
+	if padLat < mapCoverageMinSpan {
+		padLat = mapCoverageMinSpan
+	}
+	padLon := (maxLon - minLon) * mapCoveragePadding
+	if padLon < mapCoverageMinSpan {
+		padLon = mapCoverageMinSpan
+	}
+	minLat, maxLat, minLon, maxLon = minLat-padLat, maxLat+padLat, minLon-padLon, maxLon+padLon
+
+	return &geoJSONFeature{
+		Type: "Feature",
+		Geometry: geoJSONGeometry{Type: "Polygon", Coordinates: [][][]float64{{
+			{minLon, minLat}, {maxLon, minLat}, {maxLon, maxLat}, {minLon, maxLat}, {minLon, minLat},
+		}}},
+		Properties: map[string]any{
+			"layer":       "coverage",
+			"system":      system.SystemRef,
+			"systemLabel": system.Label,
+			"sites":       located,
+			"geocodeHint": system.GeocodeHint,
+		},
+	}
+}
+
+// MapHandler serves GET /api/map, a GeoJSON feature collection of the
+// geocoded calls, the Radio Reference sites and the coverage of the systems
+// the user can access.
+func (api *Api) MapHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		api.exitWithError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	client := api.getClient(r)
+	if client == nil || (!client.IsAdmin && client.User == nil) {
+		api.exitWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	if client.User != nil && client.User.PinExpired() {
+		api.exitWithError(w, http.StatusForbidden, "PIN expired")
+		return
+	}
+
+	query := r.URL.Query()
+	now := time.Now()
+
+	layers, err := parseMapLayers(query.Get("layers"))
+	if err != nil {
+		api.exitWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var bbox *mapBBox
+	if s := query.Get("bbox"); s != "" {
+		if bbox, err = parseMapBBox(s); err != nil {
+			api.exitWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	since := now.Add(-mapDefaultLookback).UnixMilli()
+	until := now.UnixMilli()
+	for name, value := range map[string]*int64{"since": &since, "until": &until} {
+		if s := query.Get(name); s != "" {
+			v, err := strconv.ParseInt(s, 10, 64)
+			if err != nil {
+				api.exitWithError(w, http.StatusBadRequest, "invalid "+name)
+				return
+			}
+			*value = v
+		}
+	}
+
+	limit := mapDefaultLimit
+	if s := query.Get("limit"); s != "" {
+		v, err := strconv.Atoi(s)
+		if err != nil || v < 1 {
+			api.exitWithError(w, http.StatusBadRequest, "invalid limit")
+			return
+		}
+		limit = v
+		if limit > mapMaxLimit {
+			limit = mapMaxLimit
+		}
+	}
+
+	collection := &geoJSONFeatureCollection{Type: "FeatureCollection", Features: []*geoJSONFeature{}}
+
+	// Sites and coverage of the systems with a talkgroup the user can access
+	if layers["sites"] || layers["coverage"] {
+		systems := []*System{}
+		api.Controller.Systems.mutex.RLock()
+		for _, system := range api.Controller.Systems.List {
+			if client.IsAdmin {
+				systems = append(systems, system)
+				continue
+			}
+			for _, talkgroup := range system.Talkgroups.List {
+				if api.Controller.userHasAccess(client.User, &Call{System: system, Talkgroup: talkgroup}) {
+					systems = append(systems, system)
+					break
+				}
+			}
+		}
+		api.Controller.Systems.mutex.RUnlock()
+
+		for _, system := range systems {
+			if layers["coverage"] {
+				if feature := coverageFeature(system); feature != nil {
+					collection.Features = append(collection.Features, feature)
+				}
+			}
+			if layers["sites"] {
+				collection.Features = append(collection.Features, siteFeatures(system, bbox)...)
+			}
+		}
+	}
+
+	if layers["calls"] {
+		features, err := api.mapCallFeatures(client, bbox, since, until, limit)
+		if err != nil {
+			mapLog.Error(fmt.Sprintf("MapHandler: %v", err))
+			api.exitWithError(w, http.StatusInternalServerError, "failed to read the map")
+			return
+		}
+		collection.Features = append(collection.Features, features...)
+	}
+
+	w.Header().Set("Content-Type", "application/geo+json")
+	json.NewEncoder(w).Encode(collection)
+}
+
+// mapCallFeatures returns the newest geocoded calls in the window that the
+// client can hear now.
+func (api *Api) mapCallFeatures(client *Client, bbox *mapBBox, since int64, until int64, limit int) ([]*geoJSONFeature, error) {
+	where := `"latitude" IS NOT NULL AND "timestamp" >= $1 AND "timestamp" <= $2`
+	args := []any{since, until}
+	if bbox != nil {
+		where += ` AND "latitude" >= $3 AND "latitude" <= $4`
+		args = append(args, bbox.MinLat, bbox.MaxLat)
+		if bbox.MinLon <= bbox.MaxLon {
+			where += ` AND "longitude" >= $5 AND "longitude" <= $6`
+		} else {
+			where += ` AND ("longitude" >= $5 OR "longitude" <= $6)`
+		}
+		args = append(args, bbox.MinLon, bbox.MaxLon)
+	}
+
+	// Calls the client cannot hear are skipped, so read past the limit
+	rows, err := api.Controller.Database.Sql.Query(
+		fmt.Sprintf(`SELECT "callId", "systemId", "talkgroupId", "timestamp", "latitude", "longitude", "locationAddress", "transcript", "alertSummary" FROM "calls" WHERE %s ORDER BY "timestamp" DESC LIMIT $%d`, where, len(args)+1),
+		append(args, limit*5)...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	transcripts := client.IsAdmin || api.Controller.Billing.TranscriptsAllowed(client.User)
+	now := time.Now()
+	features := []*geoJSONFeature{}
+	for rows.Next() && len(features) < limit {
+		var (
+			callId, systemId, talkgroupId uint64
+			timestamp                     int64
+			latitude, longitude           float64
+			address                       string
+			transcript, alertSummary      *string
+		)
+		if err := rows.Scan(&callId, &systemId, &talkgroupId, &timestamp, &latitude, &longitude, &address, &transcript, &alertSummary); err != nil {
+			return nil, err
+		}
+		system, ok := api.Controller.Systems.GetSystemById(systemId)
+		if !ok {
+			continue
+		}
+		talkgroup, ok := system.Talkgroups.GetTalkgroupById(talkgroupId)
+		if !ok {
+			continue
+		}
+
+		call := &Call{Id: callId, Timestamp: time.UnixMilli(timestamp), System: system, Talkgroup: talkgroup}
+		if !client.IsAdmin {
+			if !api.Controller.userHasAccess(client.User, call) {
+				continue
+			}
+			delay := api.Controller.Delayer.getEffectiveDelayForClient(call, client)
+			if call.Timestamp.Add(time.Duration(delay) * time.Minute).After(now) {
+				continue
+			}
+		}
+
+		properties := map[string]any{
+			"layer":          "calls",
+			"id":             callId,
+			"system":         system.SystemRef,
+			"systemLabel":    system.Label,
+			"talkgroup":      talkgroup.TalkgroupRef,
+			"talkgroupLabel": talkgroup.Label,
+			"talkgroupName":  talkgroup.Name,
+			"timestamp":      timestamp,
+			"audioUrl":       fmt.Sprintf("/api/calls/%d/audio", callId),
+		}
+		if transcripts {
+			properties["address"] = address
+			if transcript != nil {
+				properties["transcript"] = *transcript
+			}
+			if alertSummary != nil && *alertSummary != "" {
+				properties["alertSummary"] = *alertSummary
+			}
+		}
+		features = append(features, geoJSONPoint(latitude, longitude, properties))
+	}
+	return features, rows.Err()
+}
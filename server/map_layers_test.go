@@ -0,0 +1,86 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseMapBBox(t *testing.T) {
+	bbox, err := parseMapBBox("-90.1, 39.5, -89.2, 40.1")
+	if err != nil {
+		t.Fatalf("parseMapBBox: %v", err)
+	}
+	if !bbox.contains(39.78, -89.65) || bbox.contains(41, -89.65) || bbox.contains(39.78, -88) {
+		t.Fatalf("contains is wrong for %+v", bbox)
+	}
+
+	for _, s := range []string{"1,2,3", "a,1,2,3", "0,50,10,40", "0,-91,10,40"} {
+		if _, err := parseMapBBox(s); err == nil {
+			t.Fatalf("parseMapBBox(%q) should fail", s)
+		}
+	}
+
+	// Crossing the antimeridian
+	pacific, err := parseMapBBox("170,-20,-170,0")
+	if err != nil {
+		t.Fatalf("parseMapBBox: %v", err)
+	}
+	if !pacific.contains(-10, 178) || !pacific.contains(-10, -175) || pacific.contains(-10, 0) {
+		t.Fatalf("antimeridian bbox is wrong")
+	}
+
+	var none *mapBBox
+	if !none.contains(10, 10) {
+		t.Fatalf("a nil bbox contains everything")
+	}
+}
+
+func TestParseMapLayers(t *testing.T) {
+	layers, err := parseMapLayers("")
+	if err != nil || !layers["calls"] || !layers["sites"] || !layers["coverage"] {
+		t.Fatalf("default layers = %v %v", layers, err)
+	}
+	layers, err = parseMapLayers("Sites, calls")
+	if err != nil || !layers["calls"] || !layers["sites"] || layers["coverage"] {
+		t.Fatalf("layers = %v %v", layers, err)
+	}
+	if _, err := parseMapLayers("weather"); err == nil {
+		t.Fatalf("expected an error for an unknown layer")
+	}
+}
+
+func TestSiteAndCoverageFeatures(t *testing.T) {
+	system := NewSystem()
+	system.SystemRef = 11
+	system.Label = "County P25"
+	system.Sites.List = []*Site{
+		{SiteRef: "001", Label: "North", Latitude: 40, Longitude: -89},
+		{SiteRef: "002", Label: "South", Latitude: 39, Longitude: -90},
+		{SiteRef: "003", Label: "Unknown"},
+	}
+
+	features := siteFeatures(system, nil)
+	if len(features) != 2 {
+		t.Fatalf("got %d site features, want 2", len(features))
+	}
+	b, _ := json.Marshal(features[0].Geometry)
+	if string(b) != `{"type":"Point","coordinates":[-89,40]}` {
+		t.Fatalf("geometry = %s", b)
+	}
+	if len(siteFeatures(system, &mapBBox{MinLon: -89.5, MinLat: 39.5, MaxLon: -88.5, MaxLat: 40.5})) != 1 {
+		t.Fatalf("bbox not applied to sites")
+	}
+
+	coverage := coverageFeature(system)
+	if coverage == nil || coverage.Properties["sites"] != 2 {
+		t.Fatalf("coverage = %+v", coverage)
+	}
+	ring := coverage.Geometry.Coordinates.([][][]float64)[0]
+	if ring[0][0] != -90.1 || ring[0][1] != 38.9 || ring[2][0] != -88.9 || ring[2][1] != 40.1 {
+		t.Fatalf("coverage ring = %v", ring)
+	}
+
+	if coverageFeature(NewSystem()) != nil {
+		t.Fatalf("a system without located sites has no coverage")
+	}
+}
@@ -35,7 +35,9 @@ const (
 	MessageCommandPin            = "PIN"
 	MessageCommandPinSet         = "PNS"
 	MessageCommandPushId         = "PID"
+	MessageCommandScanControl    = "SCN"
 	MessageCommandServer         = "SRV"
+	MessageCommandTGListeners    = "TGL"
 	MessageCommandVersion        = "VER"
 
 	// WebsocketCallFlagDownload matches the client-side WebsocketCallFlag.Download value.
@@ -85,11 +85,11 @@ func migrateApikeys(db *Database) error {
 		}
 
 		if ident.Valid {
-			apikey.Ident = escapeQuotes(ident.String)
+			apikey.Ident = ident.String
 		}
 
 		if key.Valid {
-			apikey.Key = escapeQuotes(key.String)
+			apikey.Key = key.String
 		}
 
 		if order.Valid {
@@ -100,8 +100,8 @@ func migrateApikeys(db *Database) error {
 			apikey.Systems = systems.String
 		}
 
-		query = fmt.Sprintf(`INSERT INTO "apikeys" ("apikeyId", "disabled", "ident", "key", "order", "systems") VALUES (%d, %t, '%s', '%s', %d, '%s')`, apikey.Id, apikey.Disabled, apikey.Ident, apikey.Key, apikey.Order, apikey.Systems)
-		if _, err = tx.Exec(query); err != nil {
+		query = `INSERT INTO "apikeys" ("apikeyId", "disabled", "ident", "key", "order", "systems") VALUES ($1, $2, $3, $4, $5, $6)`
+		if _, err = tx.Exec(query, apikey.Id, apikey.Disabled, apikey.Ident, apikey.Key, apikey.Order, apikey.Systems); err != nil {
 			log.Println(formatError(err, query))
 		}
 	}
@@ -209,7 +209,7 @@ func migrateCalls(db *Database) error {
 		}
 
 		if audioFilename.Valid {
-			call.AudioFilename = escapeQuotes(audioFilename.String)
+			call.AudioFilename = audioFilename.String
 		}
 
 		if audioMime.Valid {
@@ -257,9 +257,9 @@ func migrateCalls(db *Database) error {
 			frequencyValue = int64(frequency.Int32)
 		}
 
-		query = fmt.Sprintf(`INSERT INTO "calls" ("callId", "audio", "audioFilename", "audioMime", "siteRef", "systemId", "talkgroupId", "timestamp", "frequency") VALUES (%d, $1, '%s', '%s', 0, %d, %d, %d, %d)`, call.Id, call.AudioFilename, call.AudioMime, systems[systemRef.Int32], talkgroups[systemRef.Int32][talkgroupRef.Int32], timestamp, frequencyValue)
+		query = `INSERT INTO "calls" ("callId", "audio", "audioFilename", "audioMime", "siteRef", "systemId", "talkgroupId", "timestamp", "frequency") VALUES ($1, $2, $3, $4, 0, $5, $6, $7, $8)`
 
-		if _, err = tx.Exec(query, call.Audio); err == nil {
+		if _, err = tx.Exec(query, call.Id, call.Audio, call.AudioFilename, call.AudioMime, systems[systemRef.Int32], talkgroups[systemRef.Int32][talkgroupRef.Int32], timestamp, frequencyValue); err == nil {
 			if patches.Valid && len(patches.String) > 0 {
 				var f any
 				if err = json.Unmarshal([]byte(patches.String), &f); err == nil {
@@ -269,8 +269,8 @@ func migrateCalls(db *Database) error {
 							switch i := v.(type) {
 							case float64:
 								if i := talkgroups[systemRef.Int32][int32(i)]; i > 0 {
-									query = fmt.Sprintf(`INSERT INTO "callPatches" ("callId", "talkgroupId") VALUES (%d, %d)`, call.Id, i)
-									if _, err = tx.Exec(query); err != nil {
+									query = `INSERT INTO "callPatches" ("callId", "talkgroupId") VALUES ($1, $2)`
+									if _, err = tx.Exec(query, call.Id, i); err != nil {
 										log.Println(formatError(err, query))
 									}
 								}
@@ -291,8 +291,8 @@ func migrateCalls(db *Database) error {
 								switch src := (m["src"]).(type) {
 								case float64:
 									if src > 0 {
-										query = fmt.Sprintf(`INSERT INTO "callUnits" ("callId", "offset", "unitRef") VALUES (%d, %f, %f)`, call.Id, m["pos"], src)
-										if _, err = tx.Exec(query); err != nil {
+										query = `INSERT INTO "callUnits" ("callId", "offset", "unitRef") VALUES ($1, $2, $3)`
+										if _, err = tx.Exec(query, call.Id, m["pos"], src); err != nil {
 											log.Println(formatError(err, query))
 										}
 									}
@@ -304,14 +304,14 @@ func migrateCalls(db *Database) error {
 
 			} else if source.Valid && source.Int32 > 0 {
 				var c int
-				query = fmt.Sprintf(`SELECT COUNT(*) FROM "units" WHERE "systemId" = %d AND "unitRef" = %d`, systems[systemRef.Int32], source.Int32)
-				if err = tx.QueryRow(query).Scan(&c); err == nil && c == 0 {
-					query = fmt.Sprintf(`INSERT INTO "units" ("label", "systemId", "unitRef") VALUES(%d, %d, %d)`, source.Int32, systems[systemRef.Int32], source.Int32)
-					if _, err = tx.Exec(query); err != nil {
+				query = `SELECT COUNT(*) FROM "units" WHERE "systemId" = $1 AND "unitRef" = $2`
+				if err = tx.QueryRow(query, systems[systemRef.Int32], source.Int32).Scan(&c); err == nil && c == 0 {
+					query = `INSERT INTO "units" ("label", "systemId", "unitRef") VALUES($1, $2, $3)`
+					if _, err = tx.Exec(query, fmt.Sprint(source.Int32), systems[systemRef.Int32], source.Int32); err != nil {
 						log.Println(formatError(err, query))
 					} else {
-						query = fmt.Sprintf(`INSERT INTO "callUnits" ("callId", "offset", "unitRef") VALUES (%d, %d, %d)`, call.Id, 0, source.Int32)
-						if _, err = tx.Exec(query); err != nil {
+						query = `INSERT INTO "callUnits" ("callId", "offset", "unitRef") VALUES ($1, 0, $2)`
+						if _, err = tx.Exec(query, call.Id, source.Int32); err != nil {
 							log.Println(formatError(err, query))
 						}
 					}
@@ -478,7 +478,7 @@ func migrateDirwatches(db *Database) error {
 		}
 
 		if directory.Valid && len(directory.String) > 0 {
-			dirwatch.Directory = escapeQuotes(directory.String)
+			dirwatch.Directory = directory.String
 		} else {
 			continue
 		}
@@ -488,7 +488,7 @@ func migrateDirwatches(db *Database) error {
 		}
 
 		if extension.Valid {
-			dirwatch.Extension = escapeQuotes(extension.String)
+			dirwatch.Extension = extension.String
 		}
 
 		if frequency.Valid {
@@ -496,7 +496,7 @@ func migrateDirwatches(db *Database) error {
 		}
 
 		if mask.Valid && len(mask.String) > 0 {
-			dirwatch.Mask = escapeQuotes(mask.String)
+			dirwatch.Mask = mask.String
 		}
 
 		if kind.Valid && len(kind.String) > 0 {
@@ -519,8 +519,8 @@ func migrateDirwatches(db *Database) error {
 			refTalkgroup = nil
 		}
 
-		query = fmt.Sprintf(`INSERT INTO "dirwatches" ("dirwatchId", "delay", "deleteAfter", "directory", "disabled", "extension", "frequency", "mask", "order", "systemId", "talkgroupId", "type") VALUES (%d, %d, %t, '%s', %t, '%s', %d, '%s', %d, %d, %d, '%s')`, dirwatch.Id, dirwatch.Delay, dirwatch.DeleteAfter, dirwatch.Directory, dirwatch.Disabled, dirwatch.Extension, dirwatch.Frequency, dirwatch.Mask, dirwatch.Order, refSystem, refTalkgroup, dirwatch.Kind)
-		if _, err = tx.Exec(query); err != nil {
+		query = `INSERT INTO "dirwatches" ("dirwatchId", "delay", "deleteAfter", "directory", "disabled", "extension", "frequency", "mask", "order", "systemId", "talkgroupId", "type") VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)`
+		if _, err = tx.Exec(query, dirwatch.Id, dirwatch.Delay, dirwatch.DeleteAfter, dirwatch.Directory, dirwatch.Disabled, dirwatch.Extension, dirwatch.Frequency, dirwatch.Mask, dirwatch.Order, refSystem, refTalkgroup, dirwatch.Kind); err != nil {
 			log.Println(formatError(err, query))
 		}
 	}
@@ -587,7 +587,7 @@ func migrateDownstreams(db *Database) error {
 		}
 
 		if apikey.Valid && len(apikey.String) > 0 {
-			downstream.Apikey = escapeQuotes(apikey.String)
+			downstream.Apikey = apikey.String
 		} else {
 			continue
 		}
@@ -607,13 +607,13 @@ func migrateDownstreams(db *Database) error {
 		}
 
 		if url.Valid && len(url.String) > 0 {
-			downstream.Url = escapeQuotes(url.String)
+			downstream.Url = url.String
 		} else {
 			continue
 		}
 
-		query = fmt.Sprintf(`INSERT INTO "downstreams" ("downstreamId", "apikey", "disabled", "order", "systems", "url") VALUES (%d, '%s', %t, %d, '%s', '%s')`, downstream.Id, downstream.Apikey, downstream.Disabled, downstream.Order, downstream.Systems, downstream.Url)
-		if _, err = tx.Exec(query); err != nil {
+		query = `INSERT INTO "downstreams" ("downstreamId", "apikey", "disabled", "order", "systems", "url") VALUES ($1, $2, $3, $4, $5, $6)`
+		if _, err = tx.Exec(query, downstream.Id, downstream.Apikey, downstream.Disabled, downstream.Order, downstream.Systems, downstream.Url); err != nil {
 			log.Println(formatError(err, query))
 		}
 	}
@@ -677,7 +677,7 @@ func migrateGroups(db *Database) error {
 		}
 
 		if label.Valid {
-			group.Label = escapeQuotes(label.String)
+			group.Label = label.String
 		}
 
 		groups = append(groups, group)
@@ -692,8 +692,8 @@ func migrateGroups(db *Database) error {
 	for i, group := range groups {
 		group.Order = uint(i + 1)
 
-		query = fmt.Sprintf(`INSERT INTO "groups" ("groupId", "label", "order") VALUES (%d, '%s', %d)`, group.Id, group.Label, group.Order)
-		if _, err = tx.Exec(query); err != nil {
+		query = `INSERT INTO "groups" ("groupId", "label", "order") VALUES ($1, $2, $3)`
+		if _, err = tx.Exec(query, group.Id, group.Label, group.Order); err != nil {
 			log.Println(formatError(err, query))
 		}
 	}
@@ -770,7 +770,7 @@ func migrateLogs(db *Database) error {
 		}
 
 		if message.Valid && len(message.String) > 0 {
-			l.Message = escapeQuotes(message.String)
+			l.Message = message.String
 		} else {
 			continue
 		}
@@ -844,9 +844,9 @@ func (db *Database) migrateWithSchema(name string, schemas []string, verbose boo
 		return fmt.Errorf("%s while doing %s", err.Error(), query)
 	}
 
-	query = fmt.Sprintf(`SELECT COUNT(*) FROM "rdioScannerMeta" WHERE "name" = '%s'`, escapeQuotes(name))
+	query = `SELECT COUNT(*) FROM "rdioScannerMeta" WHERE "name" = $1`
 
-	if err = db.Sql.QueryRow(query).Scan(&count); err != nil {
+	if err = db.Sql.QueryRow(query, name).Scan(&count); err != nil {
 		return formatError(err, query)
 	}
 
@@ -863,9 +863,9 @@ func (db *Database) migrateWithSchema(name string, schemas []string, verbose boo
 				}
 			}
 
-			query = fmt.Sprintf(`INSERT INTO "rdioScannerMeta" ("name") VALUES ('%s')`, escapeQuotes(name))
+			query = `INSERT INTO "rdioScannerMeta" ("name") VALUES ($1)`
 
-			if _, err = tx.Exec(query); err != nil {
+			if _, err = tx.Exec(query, name); err != nil {
 				tx.Rollback()
 				return formatError(err, query)
 			}
@@ -925,8 +925,8 @@ func migrateOptions(db *Database) error {
 				switch v := m["audioConversion"].(type) {
 				case bool:
 					if b, err := json.Marshal(v); err == nil {
-						query = fmt.Sprintf(`INSERT INTO "options" ("key", "value") VALUES ('%s', '%s')`, "audioConversion", string(b))
-						if _, err = tx.Exec(query); err != nil {
+						query = `INSERT INTO "options" ("key", "value") VALUES ($1, $2)`
+						if _, err = tx.Exec(query, "audioConversion", string(b)); err != nil {
 							log.Println(formatError(err, query))
 						}
 					}
@@ -934,8 +934,8 @@ func migrateOptions(db *Database) error {
 				switch v := m["autoPopulate"].(type) {
 				case bool:
 					if b, err := json.Marshal(v); err == nil {
-						query = fmt.Sprintf(`INSERT INTO "options" ("key", "value") VALUES ('%s', '%s')`, "autoPopulate", string(b))
-						if _, err = tx.Exec(query); err != nil {
+						query = `INSERT INTO "options" ("key", "value") VALUES ($1, $2)`
+						if _, err = tx.Exec(query, "autoPopulate", string(b)); err != nil {
 							log.Println(formatError(err, query))
 						}
 					}
@@ -943,8 +943,8 @@ func migrateOptions(db *Database) error {
 				switch v := m["branding"].(type) {
 				case string:
 					if b, err := json.Marshal(v); err == nil {
-						query = fmt.Sprintf(`INSERT INTO "options" ("key", "value") VALUES ('%s', '%s')`, "branding", escapeQuotes(string(b)))
-						if _, err = tx.Exec(query); err != nil {
+						query = `INSERT INTO "options" ("key", "value") VALUES ($1, $2)`
+						if _, err = tx.Exec(query, "branding", string(b)); err != nil {
 							log.Println(formatError(err, query))
 						}
 					}
@@ -952,8 +952,8 @@ func migrateOptions(db *Database) error {
 				switch v := m["dimmerDelay"].(type) {
 				case float64:
 					if b, err := json.Marshal(v); err == nil {
-						query = fmt.Sprintf(`INSERT INTO "options" ("key", "value") VALUES ('%s', '%s')`, "dimmerDelay", string(b))
-						if _, err = tx.Exec(query); err != nil {
+						query = `INSERT INTO "options" ("key", "value") VALUES ($1, $2)`
+						if _, err = tx.Exec(query, "dimmerDelay", string(b)); err != nil {
 							log.Println(formatError(err, query))
 						}
 					}
@@ -961,8 +961,8 @@ func migrateOptions(db *Database) error {
 				switch v := m["disableDuplicateDetection"].(type) {
 				case bool:
 					if b, err := json.Marshal(v); err == nil {
-						query = fmt.Sprintf(`INSERT INTO "options" ("key", "value") VALUES ('%s', '%s')`, "disableDuplicateDetection", string(b))
-						if _, err = tx.Exec(query); err != nil {
+						query = `INSERT INTO "options" ("key", "value") VALUES ($1, $2)`
+						if _, err = tx.Exec(query, "disableDuplicateDetection", string(b)); err != nil {
 							log.Println(formatError(err, query))
 						}
 					}
@@ -970,8 +970,8 @@ func migrateOptions(db *Database) error {
 				switch v := m["duplicateDetectionTimeFrame"].(type) {
 				case float64:
 					if b, err := json.Marshal(v); err == nil {
-						query = fmt.Sprintf(`INSERT INTO "options" ("key", "value") VALUES ('%s', '%s')`, "duplicateDetectionTimeFrame", string(b))
-						if _, err = tx.Exec(query); err != nil {
+						query = `INSERT INTO "options" ("key", "value") VALUES ($1, $2)`
+						if _, err = tx.Exec(query, "duplicateDetectionTimeFrame", string(b)); err != nil {
 							log.Println(formatError(err, query))
 						}
 					}
@@ -979,8 +979,8 @@ func migrateOptions(db *Database) error {
 				switch v := m["email"].(type) {
 				case string:
 					if b, err := json.Marshal(v); err == nil {
-						query = fmt.Sprintf(`INSERT INTO "options" ("key", "value") VALUES ('%s', '%s')`, "email", escapeQuotes(string(b)))
-						if _, err = tx.Exec(query); err != nil {
+						query = `INSERT INTO "options" ("key", "value") VALUES ($1, $2)`
+						if _, err = tx.Exec(query, "email", string(b)); err != nil {
 							log.Println(formatError(err, query))
 						}
 					}
@@ -988,8 +988,8 @@ func migrateOptions(db *Database) error {
 				switch v := m["keypadBeeps"].(type) {
 				case string:
 					if b, err := json.Marshal(v); err == nil {
-						query = fmt.Sprintf(`INSERT INTO "options" ("key", "value") VALUES ('%s', '%s')`, "keypadBeeps", string(b))
-						if _, err = tx.Exec(query); err != nil {
+						query = `INSERT INTO "options" ("key", "value") VALUES ($1, $2)`
+						if _, err = tx.Exec(query, "keypadBeeps", string(b)); err != nil {
 							log.Println(formatError(err, query))
 						}
 					}
@@ -997,8 +997,8 @@ func migrateOptions(db *Database) error {
 				switch v := m["maxClients"].(type) {
 				case float64:
 					if b, err := json.Marshal(v); err == nil {
-						query = fmt.Sprintf(`INSERT INTO "options" ("key", "value") VALUES ('%s', '%s')`, "maxClients", string(b))
-						if _, err = tx.Exec(query); err != nil {
+						query = `INSERT INTO "options" ("key", "value") VALUES ($1, $2)`
+						if _, err = tx.Exec(query, "maxClients", string(b)); err != nil {
 							log.Println(formatError(err, query))
 						}
 					}
@@ -1006,8 +1006,8 @@ func migrateOptions(db *Database) error {
 				switch v := m["playbackGoesLive"].(type) {
 				case bool:
 					if b, err := json.Marshal(v); err == nil {
-						query = fmt.Sprintf(`INSERT INTO "options" ("key", "value") VALUES ('%s', '%s')`, "playbackGoesLive", string(b))
-						if _, err = tx.Exec(query); err != nil {
+						query = `INSERT INTO "options" ("key", "value") VALUES ($1, $2)`
+						if _, err = tx.Exec(query, "playbackGoesLive", string(b)); err != nil {
 							log.Println(formatError(err, query))
 						}
 					}
@@ -1015,8 +1015,8 @@ func migrateOptions(db *Database) error {
 				switch v := m["pruneDays"].(type) {
 				case float64:
 					if b, err := json.Marshal(v); err == nil {
-						query = fmt.Sprintf(`INSERT INTO "options" ("key", "value") VALUES ('%s', '%s')`, "pruneDays", string(b))
-						if _, err = tx.Exec(query); err != nil {
+						query = `INSERT INTO "options" ("key", "value") VALUES ($1, $2)`
+						if _, err = tx.Exec(query, "pruneDays", string(b)); err != nil {
 							log.Println(formatError(err, query))
 						}
 					}
@@ -1024,8 +1024,8 @@ func migrateOptions(db *Database) error {
 				switch v := m["showListenersCount"].(type) {
 				case bool:
 					if b, err := json.Marshal(v); err == nil {
-						query = fmt.Sprintf(`INSERT INTO "options" ("key", "value") VALUES ('%s', '%s')`, "showListenersCount", string(b))
-						if _, err = tx.Exec(query); err != nil {
+						query = `INSERT INTO "options" ("key", "value") VALUES ($1, $2)`
+						if _, err = tx.Exec(query, "showListenersCount", string(b)); err != nil {
 							log.Println(formatError(err, query))
 						}
 					}
@@ -1033,8 +1033,8 @@ func migrateOptions(db *Database) error {
 				switch v := m["sortTalkgroups"].(type) {
 				case bool:
 					if b, err := json.Marshal(v); err == nil {
-						query = fmt.Sprintf(`INSERT INTO "options" ("key", "value") VALUES ('%s', '%s')`, "sortTalkgroups", string(b))
-						if _, err = tx.Exec(query); err != nil {
+						query = `INSERT INTO "options" ("key", "value") VALUES ($1, $2)`
+						if _, err = tx.Exec(query, "sortTalkgroups", string(b)); err != nil {
 							log.Println(formatError(err, query))
 						}
 					}
@@ -1042,8 +1042,8 @@ func migrateOptions(db *Database) error {
 				switch v := m["time12hFormat"].(type) {
 				case bool:
 					if b, err := json.Marshal(v); err == nil {
-						query = fmt.Sprintf(`INSERT INTO "options" ("key", "value") VALUES ('%s', '%s')`, "time12hFormat", string(b))
-						if _, err = tx.Exec(query); err != nil {
+						query = `INSERT INTO "options" ("key", "value") VALUES ($1, $2)`
+						if _, err = tx.Exec(query, "time12hFormat", string(b)); err != nil {
 							log.Println(formatError(err, query))
 						}
 					}
@@ -1051,8 +1051,8 @@ func migrateOptions(db *Database) error {
 			}
 
 		} else {
-			query = fmt.Sprintf(`INSERT INTO "options" ("key", "value") VALUES ('%s', '%s')`, escapeQuotes(key.String), escapeQuotes(value.String))
-			if _, err = tx.Exec(query); err != nil {
+			query = `INSERT INTO "options" ("key", "value") VALUES ($1, $2)`
+			if _, err = tx.Exec(query, key.String, value.String); err != nil {
 				log.Println(formatError(err, query))
 			}
 		}
@@ -1128,7 +1128,7 @@ func migrateSystems(db *Database) error {
 		}
 
 		if label.Valid {
-			system.Label = escapeQuotes(label.String)
+			system.Label = label.String
 		}
 
 		if order.Valid {
@@ -1139,8 +1139,8 @@ func migrateSystems(db *Database) error {
 			system.SystemRef = uint(systemRef.Int32)
 		}
 
-		query = fmt.Sprintf(`INSERT INTO "systems" ("systemId", "autoPopulate", "blacklists", "label", "order", "systemRef") VALUES (%d, %t, '%s', '%s', %d, %d)`, system.Id, system.AutoPopulate, system.Blacklists, system.Label, system.Order, system.SystemRef)
-		if _, err = tx.Exec(query); err != nil {
+		query = `INSERT INTO "systems" ("systemId", "autoPopulate", "blacklists", "label", "order", "systemRef") VALUES ($1, $2, $3, $4, $5, $6)`
+		if _, err = tx.Exec(query, system.Id, system.AutoPopulate, system.Blacklists, system.Label, system.Order, system.SystemRef); err != nil {
 			log.Println(formatError(err, query))
 		}
 	}
@@ -1204,7 +1204,7 @@ func migrateTags(db *Database) error {
 		}
 
 		if label.Valid {
-			tag.Label = escapeQuotes(label.String)
+			tag.Label = label.String
 		}
 
 		tags = append(tags, tag)
@@ -1219,8 +1219,8 @@ func migrateTags(db *Database) error {
 	for i, tag := range tags {
 		tag.Order = uint(i + 1)
 
-		query = fmt.Sprintf(`INSERT INTO "tags" ("tagId", "label", "order") VALUES (%d, '%s', %d)`, tag.Id, tag.Label, tag.Order)
-		if _, err = tx.Exec(query); err != nil {
+		query = `INSERT INTO "tags" ("tagId", "label", "order") VALUES ($1, $2, $3)`
+		if _, err = tx.Exec(query, tag.Id, tag.Label, tag.Order); err != nil {
 			log.Println(formatError(err, query))
 		}
 	}
@@ -1315,11 +1315,11 @@ func migrateTalkgroups(db *Database) error {
 		}
 
 		if label.Valid {
-			talkgroup.Label = escapeQuotes(label.String)
+			talkgroup.Label = label.String
 		}
 
 		if name.Valid {
-			talkgroup.Name = escapeQuotes(name.String)
+			talkgroup.Name = name.String
 		}
 
 		if order.Valid {
@@ -1338,10 +1338,10 @@ func migrateTalkgroups(db *Database) error {
 			talkgroup.TagId = uint64(tagId.Int64)
 		}
 
-		query = fmt.Sprintf(`INSERT INTO "talkgroups" ("talkgroupId", "frequency", "label", "name", "order", "systemId", "tagId", "talkgroupRef") VALUES (%d, %d, '%s', '%s', %d, %d, %d, %d)`, talkgroup.Id, talkgroup.Frequency, talkgroup.Label, talkgroup.Name, talkgroup.Order, systems[systemId.Int64], talkgroup.TagId, talkgroup.TalkgroupRef)
-		if _, err = tx.Exec(query); err == nil {
-			query = fmt.Sprintf(`INSERT INTO "talkgroupGroups" ("groupId", "talkgroupId") VALUES (%d, %d)`, talkgroup.GroupIds[0], talkgroup.Id)
-			if _, err = tx.Exec(query); err != nil {
+		query = `INSERT INTO "talkgroups" ("talkgroupId", "frequency", "label", "name", "order", "systemId", "tagId", "talkgroupRef") VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+		if _, err = tx.Exec(query, talkgroup.Id, talkgroup.Frequency, talkgroup.Label, talkgroup.Name, talkgroup.Order, systems[systemId.Int64], talkgroup.TagId, talkgroup.TalkgroupRef); err == nil {
+			query = `INSERT INTO "talkgroupGroups" ("groupId", "talkgroupId") VALUES ($1, $2)`
+			if _, err = tx.Exec(query, talkgroup.GroupIds[0], talkgroup.Id); err != nil {
 				log.Println(formatError(err, query))
 			}
 
@@ -1432,7 +1432,7 @@ func migrateUnits(db *Database) error {
 		}
 
 		if label.Valid {
-			unit.Label = escapeQuotes(label.String)
+			unit.Label = label.String
 		}
 
 		if order.Valid {
@@ -1443,8 +1443,8 @@ func migrateUnits(db *Database) error {
 			unit.UnitRef = uint(unitRef.Int32)
 		}
 
-		query = fmt.Sprintf(`INSERT INTO "units" ("unitId", "label", "order", "systemId", "unitRef") VALUES (%d, '%s', %d, %d, %d)`, unitId.Int64, unit.Label, unit.Order, systems[systemId.Int32], unit.Id)
-		if _, err = tx.Exec(query); err != nil {
+		query = `INSERT INTO "units" ("unitId", "label", "order", "systemId", "unitRef") VALUES ($1, $2, $3, $4, $5)`
+		if _, err = tx.Exec(query, unitId.Int64, unit.Label, unit.Order, systems[systemId.Int32], unit.Id); err != nil {
 			log.Println(formatError(err, query))
 		}
 	}
@@ -1623,8 +1623,8 @@ func migrateUserPins(db *Database) error {
 		}
 
 		existingPins[newPin] = struct{}{}
-		updateQuery := fmt.Sprintf(`UPDATE "users" SET "pin" = '%s' WHERE "userId" = %d`, escapeQuotes(newPin), userId)
-		if _, err := db.Sql.Exec(updateQuery); err != nil {
+		updateQuery := `UPDATE "users" SET "pin" = $1 WHERE "userId" = $2`
+		if _, err := db.Sql.Exec(updateQuery, newPin, userId); err != nil {
 			log.Printf("DEBUG: Unable to update user %d with generated pin: %v", userId, err)
 		}
 	}
@@ -2019,146 +2019,6 @@ func migrateTagsGroupsUniqueLabels(db *Database, verbose bool) error {
 	return db.migrateWithSchema("20251215000000-tags-groups-unique-labels", queries, verbose)
 }
 
-// Migration to remove alert tone columns from systems, talkgroups, tags, and groups
-func migrateRemoveAlertTones(db *Database) error {
-	formatError := errorFormatter("migration", "migrateRemoveAlertTones")
-
-	// Check if migration already ran
-	var count int
-	if err := db.Sql.QueryRow(`SELECT COUNT(*) FROM "migrations" WHERE "id" = '20251219000000-remove-alert-tones'`).Scan(&count); err == nil && count > 0 {
-		return nil
-	}
-
-	verbose := false
-	if count == 0 {
-		verbose = true
-	}
-
-	// Drop alert columns from systems, talkgroups, tags, and groups
-	queries := []string{
-		`ALTER TABLE "systems" DROP COLUMN IF EXISTS "alert"`,
-		`ALTER TABLE "talkgroups" DROP COLUMN IF EXISTS "alert"`,
-		`ALTER TABLE "tags" DROP COLUMN IF EXISTS "alert"`,
-		`ALTER TABLE "groups" DROP COLUMN IF EXISTS "alert"`,
-	}
-
-	if len(queries) == 0 {
-		// All columns already removed, just record migration
-		if _, err := db.Sql.Exec(`INSERT INTO "migrations" ("id") VALUES ('20251219000000-remove-alert-tones')`); err != nil {
-			return formatError(err, "recording migration")
-		}
-		return nil
-	}
-
-	return db.migrateWithSchema("20251219000000-remove-alert-tones", queries, verbose)
-}
-
-// Migration to remove led columns from systems, talkgroups, tags, and groups
-func migrateRemoveLedColors(db *Database) error {
-	formatError := errorFormatter("migration", "migrateRemoveLedColors")
-
-	// Check if migration already ran
-	var count int
-	if err := db.Sql.QueryRow(`SELECT COUNT(*) FROM "migrations" WHERE "id" = '20251219000001-remove-led-colors'`).Scan(&count); err == nil && count > 0 {
-		return nil
-	}
-
-	verbose := false
-	if count == 0 {
-		verbose = true
-	}
-
-	// Drop led columns from systems, talkgroups, tags, and groups
-	queries := []string{
-		`ALTER TABLE "systems" DROP COLUMN IF EXISTS "led"`,
-		`ALTER TABLE "talkgroups" DROP COLUMN IF EXISTS "led"`,
-		`ALTER TABLE "tags" DROP COLUMN IF EXISTS "led"`,
-		`ALTER TABLE "groups" DROP COLUMN IF EXISTS "led"`,
-	}
-
-	if len(queries) == 0 {
-		if _, err := db.Sql.Exec(`INSERT INTO "migrations" ("id") VALUES ('20251219000001-remove-led-colors')`); err != nil {
-			return formatError(err, "recording migration")
-		}
-		return nil
-	}
-
-	return db.migrateWithSchema("20251219000001-remove-led-colors", queries, verbose)
-}
-
-// Migration to fix invalid user timestamps (empty strings or invalid values)
-func migrateFixUserTimestamps(db *Database) error {
-	formatError := errorFormatter("migration", "migrateFixUserTimestamps")
-
-	// Check if migration already ran
-	var count int
-	if err := db.Sql.QueryRow(`SELECT COUNT(*) FROM "rdioScannerMeta" WHERE "name" = '20251228000000-fix-user-timestamps'`).Scan(&count); err != nil {
-		return formatError(err, "checking migration status")
-	}
-
-	if count > 0 {
-		return nil // Already migrated
-	}
-
-	log.Printf("running database migration 20251228000000-fix-user-timestamps")
-
-	// Fix users with empty or invalid createdAt timestamps
-	// Set to current time if empty, invalid, or 0
-	currentTime := time.Now().Unix()
-
-	// Fix createdAt: Set to current time if empty or invalid
-	query := fmt.Sprintf(`UPDATE "users" SET "createdAt" = '%d' WHERE "createdAt" = '' OR "createdAt" = '0'`, currentTime)
-	if _, err := db.Sql.Exec(query); err != nil {
-		return formatError(err, "fixing createdAt timestamps")
-	}
-
-	// Fix lastLogin: Set to '0' if empty (0 means never logged in)
-	query = `UPDATE "users" SET "lastLogin" = '0' WHERE "lastLogin" = ''`
-	if _, err := db.Sql.Exec(query); err != nil {
-		return formatError(err, "fixing lastLogin timestamps")
-	}
-
-	// Record migration as completed
-	query = `INSERT INTO "rdioScannerMeta" ("name") VALUES ('20251228000000-fix-user-timestamps')`
-	if _, err := db.Sql.Exec(query); err != nil {
-		return formatError(err, "recording migration")
-	}
-
-	log.Printf("Fixed user timestamps - set createdAt to current time for %d users", currentTime)
-	return nil
-}
-
-// migrateRelayListenerEmailsResync clears relayListenerEmailsInitialSyncDone so the scanner
-// performs a full POST to /api/scanner-listener-emails on the next startup. One-time fix for
-// upgrades where the flag was saved before emails reached the relay, or relay was unreachable.
-func migrateRelayListenerEmailsResync(db *Database) error {
-	const migrationName = "20260504000000-relay-listener-emails-resync"
-	formatError := errorFormatter("migration", "migrateRelayListenerEmailsResync")
-
-	var count int
-	if err := db.Sql.QueryRow(`SELECT COUNT(*) FROM "rdioScannerMeta" WHERE "name" = '` + migrationName + `'`).Scan(&count); err != nil {
-		return formatError(err, "checking migration status")
-	}
-	if count > 0 {
-		return nil
-	}
-
-	log.Printf("running database migration %s", migrationName)
-
-	res, err := db.Sql.Exec(`UPDATE "options" SET "value" = 'false' WHERE "key" = 'relayListenerEmailsInitialSyncDone'`)
-	if err != nil {
-		return formatError(err, "clear relayListenerEmailsInitialSyncDone")
-	}
-	if n, _ := res.RowsAffected(); n > 0 {
-		log.Printf("relay listener emails: reset initial-sync flag for one-time full resync to relay (%d row(s))", n)
-	}
-
-	if _, err := db.Sql.Exec(`INSERT INTO "rdioScannerMeta" ("name") VALUES ('` + migrationName + `')`); err != nil {
-		return formatError(err, "recording migration")
-	}
-	return nil
-}
-
 // fixAutoIncrementSequences - Resets auto-increment sequences to prevent duplicate key errors
 // This ensures that all sequences are set to MAX(id) + 1 for their respective tables
 func fixAutoIncrementSequences(db *Database) error {
@@ -2190,11 +2050,9 @@ func fixAutoIncrementSequences(db *Database) error {
 		// Query to find the actual sequence name for this column
 		// Use double quotes to preserve case sensitivity
 		var seqName sql.NullString
-		query := fmt.Sprintf(`
-			SELECT pg_get_serial_sequence('"%s"', '%s')
-		`, seq.table, seq.idColumn)
+		query := `SELECT pg_get_serial_sequence($1, $2)`
 
-		if err := db.Sql.QueryRow(query).Scan(&seqName); err != nil {
+		if err := db.Sql.QueryRow(query, fmt.Sprintf("%q", seq.table), seq.idColumn).Scan(&seqName); err != nil {
 			// Silently skip if sequence not found
 			continue
 		}
@@ -2219,8 +2077,8 @@ func fixAutoIncrementSequences(db *Database) error {
 		}
 
 		// Use the actual sequence name returned by pg_get_serial_sequence
-		query = fmt.Sprintf(`SELECT setval('%s', %d, false)`, seqName.String, nextVal)
-		if _, err := db.Sql.Exec(query); err != nil {
+		query = `SELECT setval($1, $2, false)`
+		if _, err := db.Sql.Exec(query, seqName.String, nextVal); err != nil {
 			// Silently skip if error resetting sequence
 			continue
 		}
@@ -2410,12 +2268,11 @@ func migrateSystemHealthAlertOptions(db *Database) error {
 
 	// Insert each option if it doesn't already exist
 	for key, value := range options {
-		query := fmt.Sprintf(`INSERT INTO "options" ("key", "value") 
-		         SELECT '%s', '%s'
-		         WHERE NOT EXISTS (SELECT 1 FROM "options" WHERE "key" = '%s')`,
-			escapeQuotes(key), value, escapeQuotes(key))
+		query := `INSERT INTO "options" ("key", "value")
+		         SELECT $1::text, $2::text
+		         WHERE NOT EXISTS (SELECT 1 FROM "options" WHERE "key" = $1::text)`
 
-		if _, err := db.Sql.Exec(query); err != nil {
+		if _, err := db.Sql.Exec(query, key, value); err != nil {
 			// Log but don't fail migration if individual option fails
 			log.Printf("migration note for option %s: %v", key, err)
 		}
@@ -2469,8 +2326,8 @@ func migrateAudioConversionModes(db *Database) error {
 // that matches rdio-scanner's original behaviour.
 func migrateRemoveAudioCodecBitrate(db *Database) error {
 	for _, key := range []string{"audioCodec", "audioBitrate"} {
-		query := fmt.Sprintf(`DELETE FROM "options" WHERE "key" = '%s'`, key)
-		if _, err := db.Sql.Exec(query); err != nil {
+		query := `DELETE FROM "options" WHERE "key" = $1`
+		if _, err := db.Sql.Exec(query, key); err != nil {
 			log.Printf("migration note (removeAudioCodecBitrate %s): %v", key, err)
 		}
 	}
@@ -2565,6 +2422,7 @@ func ensureLogsTimestampIndexBackground(db *Database) {
 func deferPostStartupMaintenance(db *Database) {
 	go ensureBootstrapIndexesBackground(db)
 	go ensureLogsTimestampIndexBackground(db)
+	go ensureCallSearchIndexesBackground(db)
 	startLogsCategoryMaintenance(db)
 }
 
@@ -3112,3 +2970,726 @@ func backfillLogsCategory(db *Database) {
 	markLogsMigrationDone(db, logsCategoryMigrationID)
 	writeLogStdout(fmt.Sprintf("logs category backfill completed (%d rows categorized)", updated))
 }
+
+// migrateConfigRevisions adds the configuration revision history used by the
+// admin config diff endpoint.
+func migrateConfigRevisions(db *Database) error {
+	queries := []string{
+		`CREATE TABLE IF NOT EXISTS "configRevisions" (
+			"configRevisionId" bigserial NOT NULL PRIMARY KEY,
+			"createdAt" bigint NOT NULL DEFAULT 0,
+			"hash" text NOT NULL DEFAULT '',
+			"state" text NOT NULL DEFAULT '{}'
+		)`,
+		`CREATE INDEX IF NOT EXISTS "configRevisions_createdAt_idx" ON "configRevisions" ("createdAt")`,
+	}
+	for _, q := range queries {
+		if _, err := db.Sql.Exec(q); err != nil {
+			log.Printf("migrateConfigRevisions note: %v", err)
+		}
+	}
+	return nil
+}
+
+// migrateRetentionPolicies adds per-system and per-talkgroup call retention.
+func migrateRetentionPolicies(db *Database) error {
+	queries := []string{
+		`CREATE TABLE IF NOT EXISTS "retentionPolicies" (
+			"systemId" bigint NOT NULL,
+			"talkgroupId" bigint NOT NULL DEFAULT 0,
+			"days" integer NOT NULL DEFAULT 0,
+			PRIMARY KEY ("systemId", "talkgroupId"),
+			CONSTRAINT "retentionPolicies_systemId" FOREIGN KEY ("systemId") REFERENCES "systems" ("systemId") ON DELETE CASCADE ON UPDATE CASCADE
+		)`,
+	}
+	for _, q := range queries {
+		if _, err := db.Sql.Exec(q); err != nil {
+			log.Printf("migrateRetentionPolicies note: %v", err)
+		}
+	}
+	return nil
+}
+
+// migrateCallAudioLocation adds the pointer to call audio stored outside the
+// calls table (object storage archive).
+func migrateCallAudioLocation(db *Database) error {
+	queries := []string{
+		`ALTER TABLE "calls" ADD COLUMN IF NOT EXISTS "audioLocation" text NOT NULL DEFAULT ''`,
+	}
+	for _, q := range queries {
+		if _, err := db.Sql.Exec(q); err != nil {
+			log.Printf("migrateCallAudioLocation note: %v", err)
+		}
+	}
+	return nil
+}
+
+// migrateCallAudioChecksum adds the checksum of call audio stored outside the
+// calls table (filesystem or object storage at ingest).
+func migrateCallAudioChecksum(db *Database) error {
+	queries := []string{
+		`ALTER TABLE "calls" ADD COLUMN IF NOT EXISTS "audioChecksum" text NOT NULL DEFAULT ''`,
+	}
+	for _, q := range queries {
+		if _, err := db.Sql.Exec(q); err != nil {
+			log.Printf("migrateCallAudioChecksum note: %v", err)
+		}
+	}
+	return nil
+}
+
+// migrateOutboxEvents adds the change event outbox read by analytics
+// pipelines (see outbox.go).
+func migrateOutboxEvents(db *Database) error {
+	queries := []string{
+		`CREATE TABLE IF NOT EXISTS "outboxEvents" (
+			"outboxEventId" bigserial NOT NULL PRIMARY KEY,
+			"aggregateType" text NOT NULL DEFAULT '',
+			"aggregateId" text NOT NULL DEFAULT '',
+			"eventType" text NOT NULL DEFAULT '',
+			"payload" text NOT NULL DEFAULT '{}',
+			"createdAt" bigint NOT NULL DEFAULT 0
+		)`,
+		`CREATE INDEX IF NOT EXISTS "outboxEvents_createdAt_idx" ON "outboxEvents" ("createdAt")`,
+	}
+	for _, q := range queries {
+		if _, err := db.Sql.Exec(q); err != nil {
+			log.Printf("migrateOutboxEvents note: %v", err)
+		}
+	}
+	return nil
+}
+
+// migrateJobs adds the persistent background job queue.
+func migrateJobs(db *Database) error {
+	queries := []string{
+		`CREATE TABLE IF NOT EXISTS "jobs" (
+			"jobId" bigserial NOT NULL PRIMARY KEY,
+			"kind" text NOT NULL,
+			"payload" text NOT NULL DEFAULT '',
+			"priority" integer NOT NULL DEFAULT 0,
+			"status" text NOT NULL DEFAULT 'queued',
+			"attempts" integer NOT NULL DEFAULT 0,
+			"maxAttempts" integer NOT NULL DEFAULT 5,
+			"runAt" bigint NOT NULL DEFAULT 0,
+			"leaseUntil" bigint NOT NULL DEFAULT 0,
+			"leasedBy" text NOT NULL DEFAULT '',
+			"lastError" text NOT NULL DEFAULT '',
+			"createdAt" bigint NOT NULL DEFAULT 0,
+			"updatedAt" bigint NOT NULL DEFAULT 0
+		)`,
+		`CREATE INDEX IF NOT EXISTS "jobs_kind_status_runAt_idx" ON "jobs" ("kind", "status", "priority" DESC, "runAt")`,
+		`CREATE INDEX IF NOT EXISTS "jobs_status_updatedAt_idx" ON "jobs" ("status", "updatedAt")`,
+	}
+	for _, q := range queries {
+		if _, err := db.Sql.Exec(q); err != nil {
+			log.Printf("migrateJobs note: %v", err)
+		}
+	}
+	return nil
+}
+
+// migrateStorageSamples adds the hourly storage measurements behind the
+// capacity forecast.
+func migrateStorageSamples(db *Database) error {
+	queries := []string{
+		`CREATE TABLE IF NOT EXISTS "storageSamples" (
+			"timestamp" bigint NOT NULL PRIMARY KEY,
+			"databaseBytes" bigint NOT NULL DEFAULT 0,
+			"audioBytes" bigint NOT NULL DEFAULT 0,
+			"diskFreeBytes" bigint NOT NULL DEFAULT 0,
+			"diskTotalBytes" bigint NOT NULL DEFAULT 0,
+			"oldestCall" bigint NOT NULL DEFAULT 0
+		)`,
+	}
+	for _, q := range queries {
+		if _, err := db.Sql.Exec(q); err != nil {
+			log.Printf("migrateStorageSamples note: %v", err)
+		}
+	}
+	return nil
+}
+
+// migrateTranscriptionProvider records the provider of each call's transcript
+// so the re-transcription backfill can select them by provider.
+func migrateTranscriptionProvider(db *Database) error {
+	queries := []string{
+		`ALTER TABLE "calls" ADD COLUMN IF NOT EXISTS "transcriptionProvider" text NOT NULL DEFAULT ''`,
+	}
+	for _, q := range queries {
+		if _, err := db.Sql.Exec(q); err != nil {
+			log.Printf("migrateTranscriptionProvider note: %v", err)
+		}
+	}
+	return nil
+}
+
+// migrateTranscriptionTiers adds the talkgroup transcription tier.
+func migrateTranscriptionTiers(db *Database) error {
+	queries := []string{
+		`ALTER TABLE "talkgroups" ADD COLUMN IF NOT EXISTS "transcriptionTier" text NOT NULL DEFAULT ''`,
+	}
+	for _, q := range queries {
+		if _, err := db.Sql.Exec(q); err != nil {
+			log.Printf("migrateTranscriptionTiers note: %v", err)
+		}
+	}
+	return nil
+}
+
+// migrateVADTrim adds the untrimmed audio length of calls whose silence was
+// trimmed at ingest; 0 means the audio was not trimmed.
+func migrateVADTrim(db *Database) error {
+	queries := []string{
+		`ALTER TABLE "calls" ADD COLUMN IF NOT EXISTS "originalDuration" real NOT NULL DEFAULT 0`,
+	}
+	for _, q := range queries {
+		if _, err := db.Sql.Exec(q); err != nil {
+			log.Printf("migrateVADTrim note: %v", err)
+		}
+	}
+	return nil
+}
+
+// migrateEncryptedCalls adds the talkgroup encrypted call policy and the
+// hourly counts of the encrypted calls it handled.
+func migrateEncryptedCalls(db *Database) error {
+	queries := []string{
+		`ALTER TABLE "talkgroups" ADD COLUMN IF NOT EXISTS "encrypted" boolean NOT NULL DEFAULT false`,
+		`ALTER TABLE "talkgroups" ADD COLUMN IF NOT EXISTS "encryptedPolicy" text NOT NULL DEFAULT ''`,
+		`CREATE TABLE IF NOT EXISTS "encryptedCallCounts" (
+			"systemId" bigint NOT NULL,
+			"talkgroupId" bigint NOT NULL,
+			"hour" bigint NOT NULL,
+			"policy" text NOT NULL,
+			"calls" bigint NOT NULL DEFAULT 0,
+			PRIMARY KEY ("systemId", "talkgroupId", "hour", "policy")
+		)`,
+	}
+	for _, q := range queries {
+		if _, err := db.Sql.Exec(q); err != nil {
+			log.Printf("migrateEncryptedCalls note: %v", err)
+		}
+	}
+	return nil
+}
+
+// migrateQuietHours adds the quiet hours schedule to the alert preferences.
+// The row with system and talkgroup 0 holds the user's own schedule.
+func migrateQuietHours(db *Database) error {
+	queries := []string{
+		`ALTER TABLE "userAlertPreferences" ADD COLUMN IF NOT EXISTS "quietHours" text NOT NULL DEFAULT ''`,
+	}
+	for _, q := range queries {
+		if _, err := db.Sql.Exec(q); err != nil {
+			log.Printf("migrateQuietHours note: %v", err)
+		}
+	}
+	return nil
+}
+
+// migrateDispatchForwarding adds the dispatches forwarded to paging services
+// when a tone set matches, with their delivery status.
+func migrateDispatchForwarding(db *Database) error {
+	queries := []string{
+		`CREATE TABLE IF NOT EXISTS "pagingDeliveries" (
+			"pagingDeliveryId" bigserial NOT NULL PRIMARY KEY,
+			"callId" bigint NOT NULL DEFAULT 0,
+			"toneSetId" text NOT NULL DEFAULT '',
+			"provider" text NOT NULL DEFAULT '',
+			"status" text NOT NULL DEFAULT '',
+			"attempts" integer NOT NULL DEFAULT 0,
+			"lastError" text NOT NULL DEFAULT '',
+			"createdAt" bigint NOT NULL DEFAULT 0,
+			"updatedAt" bigint NOT NULL DEFAULT 0
+		)`,
+		`CREATE INDEX IF NOT EXISTS "pagingDeliveries_status_idx" ON "pagingDeliveries" ("status")`,
+	}
+	for _, q := range queries {
+		if _, err := db.Sql.Exec(q); err != nil {
+			log.Printf("migrateDispatchForwarding note: %v", err)
+		}
+	}
+	return nil
+}
+
+// migrateCAD adds the CAD incident an incident was opened from or linked to.
+func migrateCAD(db *Database) error {
+	queries := []string{
+		`ALTER TABLE "incidents" ADD COLUMN IF NOT EXISTS "cadId" text NOT NULL DEFAULT ''`,
+		`ALTER TABLE "incidents" ADD COLUMN IF NOT EXISTS "cadData" text NOT NULL DEFAULT ''`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS "incidents_cadId_idx" ON "incidents" ("cadId") WHERE "cadId" <> ''`,
+	}
+	for _, q := range queries {
+		if _, err := db.Sql.Exec(q); err != nil {
+			log.Printf("migrateCAD note: %v", err)
+		}
+	}
+	return nil
+}
+
+// migrateWeatherAlerts adds the county of sites imported from Radio Reference
+// and the National Weather Service alerts already recorded.
+func migrateWeatherAlerts(db *Database) error {
+	queries := []string{
+		`ALTER TABLE "sites" ADD COLUMN IF NOT EXISTS "county" text NOT NULL DEFAULT ''`,
+		`CREATE TABLE IF NOT EXISTS "weatherAlerts" (
+			"alertId" text NOT NULL PRIMARY KEY,
+			"event" text NOT NULL DEFAULT '',
+			"expiresAt" bigint NOT NULL DEFAULT 0,
+			"createdAt" bigint NOT NULL DEFAULT 0
+		)`,
+	}
+	for _, q := range queries {
+		if _, err := db.Sql.Exec(q); err != nil {
+			log.Printf("migrateWeatherAlerts note: %v", err)
+		}
+	}
+	return nil
+}
+
+// migrateSiteLocations adds the coordinates of sites imported from Radio
+// Reference, shown on the map.
+func migrateSiteLocations(db *Database) error {
+	queries := []string{
+		`ALTER TABLE "sites" ADD COLUMN IF NOT EXISTS "latitude" double precision NOT NULL DEFAULT 0`,
+		`ALTER TABLE "sites" ADD COLUMN IF NOT EXISTS "longitude" double precision NOT NULL DEFAULT 0`,
+	}
+	for _, q := range queries {
+		if _, err := db.Sql.Exec(q); err != nil {
+			log.Printf("migrateSiteLocations note: %v", err)
+		}
+	}
+	return nil
+}
+
+// migrateGeocoding adds the per-system geocoding hint and the location
+// geocoded from the transcript of calls.
+func migrateGeocoding(db *Database) error {
+	queries := []string{
+		`ALTER TABLE "systems" ADD COLUMN IF NOT EXISTS "geocodeHint" text NOT NULL DEFAULT ''`,
+		`ALTER TABLE "calls" ADD COLUMN IF NOT EXISTS "locationAddress" text NOT NULL DEFAULT ''`,
+		`ALTER TABLE "calls" ADD COLUMN IF NOT EXISTS "latitude" double precision`,
+		`ALTER TABLE "calls" ADD COLUMN IF NOT EXISTS "longitude" double precision`,
+		`CREATE INDEX IF NOT EXISTS "calls_located_idx" ON "calls" ("timestamp") WHERE "latitude" IS NOT NULL`,
+	}
+	for _, q := range queries {
+		if _, err := db.Sql.Exec(q); err != nil {
+			log.Printf("migrateGeocoding note: %v", err)
+		}
+	}
+	return nil
+}
+
+// migrateIncidents adds the incidents correlated from related calls and the
+// calls of each incident, with the score and signals that joined them.
+func migrateIncidents(db *Database) error {
+	queries := []string{
+		`CREATE TABLE IF NOT EXISTS "incidents" (
+			"incidentId" bigserial NOT NULL PRIMARY KEY,
+			"systemId" bigint NOT NULL DEFAULT 0,
+			"talkgroupId" bigint NOT NULL DEFAULT 0,
+			"title" text NOT NULL DEFAULT '',
+			"incidentType" text NOT NULL DEFAULT '',
+			"address" text NOT NULL DEFAULT '',
+			"startedAt" bigint NOT NULL DEFAULT 0,
+			"updatedAt" bigint NOT NULL DEFAULT 0,
+			"callCount" integer NOT NULL DEFAULT 0
+		)`,
+		`CREATE INDEX IF NOT EXISTS "incidents_updatedAt_idx" ON "incidents" ("updatedAt")`,
+		`CREATE TABLE IF NOT EXISTS "incidentCalls" (
+			"incidentId" bigint NOT NULL REFERENCES "incidents" ("incidentId") ON DELETE CASCADE,
+			"callId" bigint NOT NULL REFERENCES "calls" ("callId") ON DELETE CASCADE,
+			"score" real NOT NULL DEFAULT 0,
+			"reason" text NOT NULL DEFAULT '',
+			PRIMARY KEY ("incidentId", "callId")
+		)`,
+		`CREATE INDEX IF NOT EXISTS "incidentCalls_callId_idx" ON "incidentCalls" ("callId")`,
+	}
+	for _, q := range queries {
+		if _, err := db.Sql.Exec(q); err != nil {
+			log.Printf("migrateIncidents note: %v", err)
+		}
+	}
+	return nil
+}
+
+// migrateCallSummaries adds the fields the summarizer extracts from a call
+// transcript, stored as JSON next to its one-line "alertSummary".
+func migrateCallSummaries(db *Database) error {
+	queries := []string{
+		`ALTER TABLE "calls" ADD COLUMN IF NOT EXISTS "summaryFields" text NOT NULL DEFAULT ''`,
+	}
+	for _, q := range queries {
+		if _, err := db.Sql.Exec(q); err != nil {
+			log.Printf("migrateCallSummaries note: %v", err)
+		}
+	}
+	return nil
+}
+
+// migrateBillingAudit adds the audit log of comp grants and lapsed trials.
+func migrateBillingAudit(db *Database) error {
+	queries := []string{
+		`CREATE TABLE IF NOT EXISTS "billingAudit" (
+			"auditId" bigserial NOT NULL PRIMARY KEY,
+			"userId" bigint NOT NULL DEFAULT 0,
+			"action" text NOT NULL DEFAULT '',
+			"actor" text NOT NULL DEFAULT '',
+			"detail" text NOT NULL DEFAULT '',
+			"createdAt" bigint NOT NULL DEFAULT 0
+		)`,
+		`CREATE INDEX IF NOT EXISTS "billingAudit_userId_idx" ON "billingAudit" ("userId")`,
+	}
+	for _, q := range queries {
+		if _, err := db.Sql.Exec(q); err != nil {
+			log.Printf("migrateBillingAudit note: %v", err)
+		}
+	}
+	return nil
+}
+
+// migrateBilling adds the plan each subscribed user is entitled to and the
+// metered usage already reported to Stripe.
+func migrateBilling(db *Database) error {
+	queries := []string{
+		`CREATE TABLE IF NOT EXISTS "billingEntitlements" (
+			"userId" bigint NOT NULL PRIMARY KEY REFERENCES "users" ("userId") ON DELETE CASCADE ON UPDATE CASCADE,
+			"planId" text NOT NULL DEFAULT '',
+			"subscriptionItemId" text NOT NULL DEFAULT '',
+			"usageReportedAt" bigint NOT NULL DEFAULT 0,
+			"updatedAt" bigint NOT NULL DEFAULT 0
+		)`,
+	}
+	for _, q := range queries {
+		if _, err := db.Sql.Exec(q); err != nil {
+			log.Printf("migrateBilling note: %v", err)
+		}
+	}
+	return nil
+}
+
+// migrateTenants adds the tenants hosting departments on one server and the
+// tenant of roles limited to one tenant.
+func migrateTenants(db *Database) error {
+	queries := []string{
+		`CREATE TABLE IF NOT EXISTS "tenants" (
+			"tenantId" bigserial NOT NULL PRIMARY KEY,
+			"name" text NOT NULL DEFAULT '',
+			"logoUrl" text NOT NULL DEFAULT '',
+			"primaryColor" text NOT NULL DEFAULT '',
+			"accentColor" text NOT NULL DEFAULT '',
+			"systemIds" text NOT NULL DEFAULT '[]',
+			"userGroupIds" text NOT NULL DEFAULT '[]',
+			"userIds" text NOT NULL DEFAULT '[]'
+		)`,
+		`ALTER TABLE "roles" ADD COLUMN IF NOT EXISTS "tenantId" bigint NOT NULL DEFAULT 0`,
+	}
+	for _, q := range queries {
+		if _, err := db.Sql.Exec(q); err != nil {
+			log.Printf("migrateTenants note: %v", err)
+		}
+	}
+	return nil
+}
+
+// migrateRadioReferenceSync adds the table linking systems to the Radio
+// Reference system they were imported from, with the changes found by the
+// last re-sync check.
+func migrateRadioReferenceSync(db *Database) error {
+	queries := []string{
+		`CREATE TABLE IF NOT EXISTS "radioReferenceSyncs" (
+			"systemId" bigint NOT NULL PRIMARY KEY REFERENCES "systems" ("systemId") ON DELETE CASCADE ON UPDATE CASCADE,
+			"rrSystemId" integer NOT NULL,
+			"checkedAt" bigint NOT NULL DEFAULT 0,
+			"changes" text NOT NULL DEFAULT '[]',
+			"error" text NOT NULL DEFAULT ''
+		)`,
+	}
+	for _, q := range queries {
+		if _, err := db.Sql.Exec(q); err != nil {
+			log.Printf("migrateRadioReferenceSync note: %v", err)
+		}
+	}
+	return nil
+}
+
+// migrateSiteControlChannels adds the control channels of each site, imported
+// from Radio Reference for the receiver config export.
+func migrateSiteControlChannels(db *Database) error {
+	if _, err := db.Sql.Exec(`ALTER TABLE "sites" ADD COLUMN IF NOT EXISTS "controlChannels" text NOT NULL DEFAULT '[]'`); err != nil {
+		log.Printf("migrateSiteControlChannels note: %v", err)
+	}
+	return nil
+}
+
+// migrateFeedDedup adds the table recording every copy of a call uploaded by
+// the different feeds, which one was kept and which API key sent it.
+func migrateFeedDedup(db *Database) error {
+	queries := []string{
+		`CREATE TABLE IF NOT EXISTS "callCopies" (
+			"callCopyId" bigserial NOT NULL PRIMARY KEY,
+			"callId" bigint NOT NULL REFERENCES "calls" ("callId") ON DELETE CASCADE ON UPDATE CASCADE,
+			"apiKeyId" bigint NOT NULL DEFAULT 0,
+			"receivedAt" bigint NOT NULL DEFAULT 0,
+			"audioDuration" real NOT NULL DEFAULT 0,
+			"snr" real NOT NULL DEFAULT 0,
+			"similarity" real NOT NULL DEFAULT 0,
+			"kept" boolean NOT NULL DEFAULT false
+		)`,
+		`CREATE INDEX IF NOT EXISTS "callCopies_callId_idx" ON "callCopies" ("callId")`,
+	}
+	for _, q := range queries {
+		if _, err := db.Sql.Exec(q); err != nil {
+			log.Printf("migrateFeedDedup note: %v", err)
+		}
+	}
+	return nil
+}
+
+// migrateUnitActivity adds the tables tracking when each radio unit was heard
+// and on which talkgroups.
+func migrateUnitActivity(db *Database) error {
+	queries := []string{
+		`CREATE TABLE IF NOT EXISTS "unitActivity" (
+			"systemId" bigint NOT NULL REFERENCES "systems" ("systemId") ON DELETE CASCADE ON UPDATE CASCADE,
+			"unitRef" bigint NOT NULL,
+			"firstSeen" bigint NOT NULL DEFAULT 0,
+			"lastSeen" bigint NOT NULL DEFAULT 0,
+			"callCount" bigint NOT NULL DEFAULT 0,
+			"radioLabel" text NOT NULL DEFAULT '',
+			PRIMARY KEY ("systemId", "unitRef")
+		)`,
+		`CREATE INDEX IF NOT EXISTS "unitActivity_lastSeen_idx" ON "unitActivity" ("systemId", "lastSeen" DESC)`,
+		`CREATE TABLE IF NOT EXISTS "unitTalkgroupActivity" (
+			"systemId" bigint NOT NULL REFERENCES "systems" ("systemId") ON DELETE CASCADE ON UPDATE CASCADE,
+			"unitRef" bigint NOT NULL,
+			"talkgroupId" bigint NOT NULL REFERENCES "talkgroups" ("talkgroupId") ON DELETE CASCADE ON UPDATE CASCADE,
+			"callCount" bigint NOT NULL DEFAULT 0,
+			"lastSeen" bigint NOT NULL DEFAULT 0,
+			PRIMARY KEY ("systemId", "unitRef", "talkgroupId")
+		)`,
+	}
+	for _, q := range queries {
+		if _, err := db.Sql.Exec(q); err != nil {
+			log.Printf("migrateUnitActivity note: %v", err)
+		}
+	}
+	return nil
+}
+
+// migrateSessions adds the sessions table tracking the devices logged in to
+// each user account.
+func migrateSessions(db *Database) error {
+	queries := []string{
+		`CREATE TABLE IF NOT EXISTS "sessions" (
+			"sessionId" text NOT NULL PRIMARY KEY,
+			"userId" bigint NOT NULL REFERENCES "users" ("userId") ON DELETE CASCADE ON UPDATE CASCADE,
+			"platform" text NOT NULL DEFAULT '',
+			"device" text NOT NULL DEFAULT '',
+			"userAgent" text NOT NULL DEFAULT '',
+			"ip" text NOT NULL DEFAULT '',
+			"createdAt" bigint NOT NULL DEFAULT 0,
+			"lastSeen" bigint NOT NULL DEFAULT 0,
+			"endedAt" bigint NOT NULL DEFAULT 0,
+			"endReason" text NOT NULL DEFAULT ''
+		)`,
+		`CREATE INDEX IF NOT EXISTS "sessions_userId_idx" ON "sessions" ("userId", "lastSeen")`,
+	}
+	for _, q := range queries {
+		if _, err := db.Sql.Exec(q); err != nil {
+			log.Printf("migrateSessions note: %v", err)
+		}
+	}
+	return nil
+}
+
+// migrateRoles adds the roles granting admin permissions to users and user
+// groups.
+func migrateRoles(db *Database) error {
+	queries := []string{
+		`CREATE TABLE IF NOT EXISTS "roles" (
+			"roleId" bigserial NOT NULL PRIMARY KEY,
+			"name" text NOT NULL DEFAULT '',
+			"description" text NOT NULL DEFAULT '',
+			"permissions" text NOT NULL DEFAULT '[]',
+			"userIds" text NOT NULL DEFAULT '[]',
+			"userGroupIds" text NOT NULL DEFAULT '[]'
+		)`,
+	}
+	for _, q := range queries {
+		if _, err := db.Sql.Exec(q); err != nil {
+			log.Printf("migrateRoles note: %v", err)
+		}
+	}
+	return nil
+}
+
+// migrateAudioBlobs adds the shared audio of calls uploaded with identical
+// audio, referenced by "audioLocation" and counted in "refs".
+func migrateAudioBlobs(db *Database) error {
+	queries := []string{
+		`CREATE TABLE IF NOT EXISTS "audioBlobs" (
+			"checksum" text NOT NULL PRIMARY KEY,
+			"location" text NOT NULL DEFAULT '',
+			"audio" bytea NOT NULL DEFAULT ''::bytea,
+			"mime" text NOT NULL DEFAULT '',
+			"size" bigint NOT NULL DEFAULT 0,
+			"refs" integer NOT NULL DEFAULT 0,
+			"createdAt" bigint NOT NULL DEFAULT 0,
+			"referencedAt" bigint NOT NULL DEFAULT 0
+		)`,
+		`CREATE INDEX IF NOT EXISTS "audioBlobs_location_idx" ON "audioBlobs" ("location")`,
+		`CREATE INDEX IF NOT EXISTS "calls_audioLocation_idx" ON "calls" ("audioLocation") WHERE "audioLocation" != ''`,
+	}
+	for _, q := range queries {
+		if _, err := db.Sql.Exec(q); err != nil {
+			log.Printf("migrateAudioBlobs note: %v", err)
+		}
+	}
+	return nil
+}
+
+// migrateRecordings adds the time-shift recordings scheduled by users.
+func migrateRecordings(db *Database) error {
+	queries := []string{
+		`CREATE TABLE IF NOT EXISTS "recordings" (
+			"recordingId" bigserial NOT NULL PRIMARY KEY,
+			"userId" bigint NOT NULL REFERENCES "users" ("userId") ON DELETE CASCADE ON UPDATE CASCADE,
+			"label" text NOT NULL DEFAULT '',
+			"talkgroups" text NOT NULL DEFAULT '[]',
+			"startAt" bigint NOT NULL DEFAULT 0,
+			"endAt" bigint NOT NULL DEFAULT 0,
+			"status" text NOT NULL DEFAULT 'scheduled',
+			"callIds" text NOT NULL DEFAULT '[]',
+			"createdAt" bigint NOT NULL DEFAULT 0,
+			"completedAt" bigint NOT NULL DEFAULT 0
+		)`,
+		`CREATE INDEX IF NOT EXISTS "recordings_status_endAt_idx" ON "recordings" ("status", "endAt")`,
+		`CREATE INDEX IF NOT EXISTS "recordings_userId_idx" ON "recordings" ("userId")`,
+	}
+	for _, q := range queries {
+		if _, err := db.Sql.Exec(q); err != nil {
+			log.Printf("migrateRecordings note: %v", err)
+		}
+	}
+	return nil
+}
+
+// migrateEmailAlerts adds the email settings of alert preferences and the
+// alerts waiting for the daily digest.
+func migrateEmailAlerts(db *Database) error {
+	queries := []string{
+		`ALTER TABLE "userAlertPreferences" ADD COLUMN IF NOT EXISTS "emailAlerts" boolean NOT NULL DEFAULT false`,
+		`ALTER TABLE "userAlertPreferences" ADD COLUMN IF NOT EXISTS "emailDigest" boolean NOT NULL DEFAULT false`,
+		`CREATE TABLE IF NOT EXISTS "emailDigestItems" (
+			"emailDigestItemId" bigserial NOT NULL PRIMARY KEY,
+			"userId" bigint NOT NULL REFERENCES "users" ("userId") ON DELETE CASCADE ON UPDATE CASCADE,
+			"callId" bigint NOT NULL DEFAULT 0,
+			"alertType" text NOT NULL DEFAULT '',
+			"title" text NOT NULL DEFAULT '',
+			"message" text NOT NULL DEFAULT '',
+			"createdAt" bigint NOT NULL DEFAULT 0,
+			UNIQUE ("userId", "callId")
+		)`,
+	}
+	for _, q := range queries {
+		if _, err := db.Sql.Exec(q); err != nil {
+			log.Printf("migrateEmailAlerts note: %v", err)
+		}
+	}
+	return nil
+}
+
+// migrateLoudnessSamples adds the per-call loudness measurements behind the
+// loudness report.
+func migrateLoudnessSamples(db *Database) error {
+	queries := []string{
+		`CREATE TABLE IF NOT EXISTS "loudnessSamples" (
+			"loudnessSampleId" bigserial NOT NULL PRIMARY KEY,
+			"systemId" bigint NOT NULL DEFAULT 0,
+			"apiKeyId" bigint NOT NULL DEFAULT 0,
+			"loudness" double precision NOT NULL DEFAULT 0,
+			"peak" double precision NOT NULL DEFAULT 0,
+			"createdAt" bigint NOT NULL DEFAULT 0
+		)`,
+		`CREATE INDEX IF NOT EXISTS "loudnessSamples_createdAt_idx" ON "loudnessSamples" ("createdAt")`,
+	}
+	for _, q := range queries {
+		if _, err := db.Sql.Exec(q); err != nil {
+			log.Printf("migrateLoudnessSamples note: %v", err)
+		}
+	}
+	return nil
+}
+
+// migrateTranscriptTranslation adds the spoken language of talkgroups and the
+// English translation stored next to the transcript of their calls.
+func migrateTranscriptTranslation(db *Database) error {
+	queries := []string{
+		`ALTER TABLE "talkgroups" ADD COLUMN IF NOT EXISTS "transcriptLanguage" text NOT NULL DEFAULT ''`,
+		`ALTER TABLE "calls" ADD COLUMN IF NOT EXISTS "transcriptTranslation" text NOT NULL DEFAULT ''`,
+	}
+	for _, q := range queries {
+		if _, err := db.Sql.Exec(q); err != nil {
+			log.Printf("migrateTranscriptTranslation note: %v", err)
+		}
+	}
+	return nil
+}
+
+// migrateUserWebhooks adds the webhooks users register from the API. The
+// webhook settings are stored as JSON in "config".
+func migrateUserWebhooks(db *Database) error {
+	queries := []string{
+		`CREATE TABLE IF NOT EXISTS "userWebhooks" (
+			"userWebhookId" bigserial NOT NULL PRIMARY KEY,
+			"userId" bigint NOT NULL,
+			"config" text NOT NULL DEFAULT '{}',
+			"createdAt" bigint NOT NULL DEFAULT 0,
+			CONSTRAINT "userWebhooks_userId_fkey" FOREIGN KEY ("userId") REFERENCES "users" ("userId") ON DELETE CASCADE ON UPDATE CASCADE
+		)`,
+	}
+	for _, q := range queries {
+		if _, err := db.Sql.Exec(q); err != nil {
+			log.Printf("migrateUserWebhooks note: %v", err)
+		}
+	}
+	return nil
+}
+
+// migrateAlertAcks adds acknowledgement tracking for alert escalation.
+// Acknowledgements are removed with their alert.
+func migrateAlertAcks(db *Database) error {
+	queries := []string{
+		`ALTER TABLE "alerts" ADD COLUMN IF NOT EXISTS "escalatedAt" bigint NOT NULL DEFAULT 0`,
+		`CREATE TABLE IF NOT EXISTS "alertAcks" (
+			"alertAckId" bigserial NOT NULL PRIMARY KEY,
+			"alertId" bigint NOT NULL,
+			"userId" bigint NOT NULL,
+			"ackedAt" bigint NOT NULL DEFAULT 0,
+			CONSTRAINT "alertAcks_alertId_fkey" FOREIGN KEY ("alertId") REFERENCES "alerts" ("alertId") ON DELETE CASCADE ON UPDATE CASCADE,
+			CONSTRAINT "alertAcks_alertId_userId_key" UNIQUE ("alertId", "userId")
+		)`,
+	}
+	for _, q := range queries {
+		if _, err := db.Sql.Exec(q); err != nil {
+			log.Printf("migrateAlertAcks note: %v", err)
+		}
+	}
+	return nil
+}
+
+// migrateLifeSafetyPhrases adds the per-system switch for the built-in
+// life-safety phrase pack. Off by default.
+func migrateLifeSafetyPhrases(db *Database) error {
+	queries := []string{
+		`ALTER TABLE "systems" ADD COLUMN IF NOT EXISTS "lifeSafetyPhrases" boolean NOT NULL DEFAULT false`,
+	}
+	for _, q := range queries {
+		if _, err := db.Sql.Exec(q); err != nil {
+			log.Printf("migrateLifeSafetyPhrases note: %v", err)
+		}
+	}
+	return nil
+}
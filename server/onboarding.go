@@ -0,0 +1,266 @@
+// Copyright (C) 2026 Thinline Dynamic Solutions
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+const (
+	OnboardingStepDone    = "done"
+	OnboardingStepTodo    = "todo"
+	OnboardingStepSkipped = "skipped"
+)
+
+// onboardingSteps are the checklist steps, in order. The database step
+// cannot be skipped since nothing else works without it.
+var onboardingSteps = []struct {
+	Id          string
+	Title       string
+	Description string
+	Skippable   bool
+}{
+	{"database", "Database connected", "The server reaches its PostgreSQL database.", false},
+	{"system", "First system imported", "Add a system with its talkgroups, by hand, from a CSV or from RadioReference.", true},
+	{"upload", "First upload received", "Point a recorder (Trunk Recorder, SDRTrunk, RTLSDR-Airband...) at the server with an API key, or set up a directory watch.", true},
+	{"transcription", "Transcription configured", "Enable transcription with a provider that is reachable.", true},
+	{"user", "First user invited", "Invite a user or create a user account, so someone besides the admin can listen.", true},
+}
+
+func onboardingStepSkippable(id string) bool {
+	for _, step := range onboardingSteps {
+		if step.Id == id {
+			return step.Skippable
+		}
+	}
+	return false
+}
+
+// OnboardingChecks are the facts the checklist is derived from.
+type OnboardingChecks struct {
+	DatabaseError         error
+	Systems               int
+	Talkgroups            int
+	LastCallAt            int64 // unix ms, 0 when no call was received
+	UploadSources         int   // API keys and directory watches
+	TranscriptionEnabled  bool
+	TranscriptionProvider string
+	TranscriptionReady    bool
+	Users                 int // accounts that are not system admins
+	Invitations           int
+}
+
+// OnboardingStep is one step of the checklist as returned by the API.
+type OnboardingStep struct {
+	Id          string `json:"id"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Status      string `json:"status"`
+	Detail      string `json:"detail"`
+	Skippable   bool   `json:"skippable"`
+}
+
+// Onboarding is the checklist state: the steps and the one to work on next.
+type Onboarding struct {
+	Steps     []OnboardingStep `json:"steps"`
+	Current   string           `json:"current"` // first step still to do, "" when complete
+	Complete  bool             `json:"complete"`
+	Dismissed bool             `json:"dismissed"`
+}
+
+// buildOnboarding derives the checklist from the checks and the admin's
+// choices. A done step stays done even when the admin skipped it.
+func buildOnboarding(checks OnboardingChecks, config OnboardingConfig) *Onboarding {
+	skipped := map[string]bool{}
+	for _, id := range config.Skipped {
+		skipped[id] = true
+	}
+
+	onboarding := &Onboarding{Steps: []OnboardingStep{}, Dismissed: config.Dismissed}
+	for _, definition := range onboardingSteps {
+		var done bool
+		var detail string
+
+		switch definition.Id {
+		case "database":
+			done = checks.DatabaseError == nil
+			if done {
+				detail = "Connected"
+			} else {
+				detail = checks.DatabaseError.Error()
+			}
+		case "system":
+			done = checks.Systems > 0 && checks.Talkgroups > 0
+			switch {
+			case checks.Systems == 0:
+				detail = "No system yet"
+			case checks.Talkgroups == 0:
+				detail = fmt.Sprintf("%d system(s) without talkgroups", checks.Systems)
+			default:
+				detail = fmt.Sprintf("%d system(s), %d talkgroup(s)", checks.Systems, checks.Talkgroups)
+			}
+		case "upload":
+			done = checks.LastCallAt > 0
+			switch {
+			case done:
+				detail = fmt.Sprintf("Last call received %s", time.UnixMilli(checks.LastCallAt).Format(time.RFC3339))
+			case checks.UploadSources == 0:
+				detail = "No API key or directory watch configured"
+			default:
+				detail = "Waiting for the first call"
+			}
+		case "transcription":
+			done = checks.TranscriptionEnabled && checks.TranscriptionReady
+			switch {
+			case !checks.TranscriptionEnabled:
+				detail = "Transcription is disabled"
+			case !checks.TranscriptionReady:
+				detail = fmt.Sprintf("Provider %s is not available; check its settings", checks.TranscriptionProvider)
+			default:
+				detail = fmt.Sprintf("Using %s", checks.TranscriptionProvider)
+			}
+		case "user":
+			done = checks.Users > 0 || checks.Invitations > 0
+			if done {
+				detail = fmt.Sprintf("%d user(s), %d invitation(s)", checks.Users, checks.Invitations)
+			} else {
+				detail = "No user besides the admin"
+			}
+		}
+
+		status := OnboardingStepTodo
+		if done {
+			status = OnboardingStepDone
+		} else if definition.Skippable && skipped[definition.Id] {
+			status = OnboardingStepSkipped
+		}
+		if status == OnboardingStepTodo && onboarding.Current == "" {
+			onboarding.Current = definition.Id
+		}
+
+		onboarding.Steps = append(onboarding.Steps, OnboardingStep{
+			Id:          definition.Id,
+			Title:       definition.Title,
+			Description: definition.Description,
+			Status:      status,
+			Detail:      detail,
+			Skippable:   definition.Skippable,
+		})
+	}
+	onboarding.Complete = onboarding.Current == ""
+
+	return onboarding
+}
+
+// OnboardingChecks runs the checks against the live server.
+func (controller *Controller) OnboardingChecks() OnboardingChecks {
+	checks := OnboardingChecks{}
+
+	checks.DatabaseError = controller.Database.Sql.Ping()
+
+	controller.Systems.mutex.RLock()
+	for _, system := range controller.Systems.List {
+		checks.Systems++
+		if system.Talkgroups != nil {
+			checks.Talkgroups += len(system.Talkgroups.List)
+		}
+	}
+	controller.Systems.mutex.RUnlock()
+
+	if checks.DatabaseError == nil {
+		controller.Database.Sql.QueryRow(`SELECT "timestamp" FROM "calls" ORDER BY "callId" DESC LIMIT 1`).Scan(&checks.LastCallAt)
+		controller.Database.Sql.QueryRow(`SELECT COUNT(*) FROM "userInvitations"`).Scan(&checks.Invitations)
+	}
+
+	controller.Apikeys.mutex.Lock()
+	checks.UploadSources += len(controller.Apikeys.List)
+	controller.Apikeys.mutex.Unlock()
+	controller.Dirwatches.mutex.Lock()
+	checks.UploadSources += len(controller.Dirwatches.List)
+	controller.Dirwatches.mutex.Unlock()
+
+	config := controller.Options.TranscriptionConfig
+	checks.TranscriptionEnabled = config.Enabled
+	checks.TranscriptionProvider = config.Provider
+	if queue := controller.TranscriptionQueue; queue != nil && queue.provider != nil {
+		checks.TranscriptionProvider = queue.provider.GetName()
+		checks.TranscriptionReady = queue.provider.IsAvailable()
+	}
+
+	for _, user := range controller.Users.GetAllUsers() {
+		if !user.SystemAdmin {
+			checks.Users++
+		}
+	}
+
+	return checks
+}
+
+// OnboardingHandler returns the onboarding checklist, and lets the admin
+// skip steps or hide the checklist.
+//
+//	GET /api/admin/onboarding
+//	PUT /api/admin/onboarding {"skipped": ["transcription"], "dismissed": false}
+func (admin *Admin) OnboardingHandler(w http.ResponseWriter, r *http.Request) {
+	writeError := func(status int, message string) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(map[string]string{"error": message})
+	}
+
+	t := admin.GetAuthorization(r)
+	if !admin.ValidateToken(t) {
+		writeError(http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+
+	case http.MethodPut:
+		var m map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&m); err != nil {
+			writeError(http.StatusBadRequest, fmt.Sprintf("invalid request body: %v", err))
+			return
+		}
+		if skipped, ok := m["skipped"].([]any); ok {
+			for _, id := range skipped {
+				if id, ok := id.(string); !ok || !onboardingStepSkippable(id) {
+					writeError(http.StatusBadRequest, fmt.Sprintf("step %v cannot be skipped", id))
+					return
+				}
+			}
+		}
+
+		applyOnboardingConfigFromMap(&admin.Controller.Options.OnboardingConfig, m)
+		if err := admin.Controller.Options.Write(admin.Controller.Database); err != nil {
+			writeError(http.StatusInternalServerError, fmt.Sprintf("failed to save onboarding settings: %v", err))
+			return
+		}
+
+	default:
+		writeError(http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	onboarding := buildOnboarding(admin.Controller.OnboardingChecks(), admin.Controller.Options.OnboardingConfig)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(onboarding)
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+)
+
+func onboardingStatuses(onboarding *Onboarding) map[string]string {
+	statuses := map[string]string{}
+	for _, step := range onboarding.Steps {
+		statuses[step.Id] = step.Status
+	}
+	return statuses
+}
+
+func TestBuildOnboardingFreshServer(t *testing.T) {
+	onboarding := buildOnboarding(OnboardingChecks{}, OnboardingConfig{})
+
+	if len(onboarding.Steps) != len(onboardingSteps) {
+		t.Fatalf("expected %d steps, got %d", len(onboardingSteps), len(onboarding.Steps))
+	}
+	if onboarding.Current != "system" || onboarding.Complete {
+		t.Fatalf("expected to start at the system step, got %q", onboarding.Current)
+	}
+	statuses := onboardingStatuses(onboarding)
+	if statuses["database"] != OnboardingStepDone || statuses["upload"] != OnboardingStepTodo {
+		t.Fatalf("unexpected statuses %v", statuses)
+	}
+}
+
+func TestBuildOnboardingProgressAndSkips(t *testing.T) {
+	checks := OnboardingChecks{Systems: 1, Talkgroups: 12, LastCallAt: 1700000000000, Users: 2}
+
+	onboarding := buildOnboarding(checks, OnboardingConfig{})
+	if onboarding.Current != "transcription" {
+		t.Fatalf("expected transcription to be next, got %q", onboarding.Current)
+	}
+
+	onboarding = buildOnboarding(checks, OnboardingConfig{Skipped: []string{"transcription", "user"}})
+	if !onboarding.Complete {
+		t.Fatalf("expected a complete checklist, current is %q", onboarding.Current)
+	}
+	statuses := onboardingStatuses(onboarding)
+	if statuses["transcription"] != OnboardingStepSkipped || statuses["user"] != OnboardingStepDone {
+		t.Fatalf("unexpected statuses %v", statuses)
+	}
+}
+
+func TestBuildOnboardingDatabaseNotSkippable(t *testing.T) {
+	checks := OnboardingChecks{DatabaseError: fmt.Errorf("connection refused")}
+	onboarding := buildOnboarding(checks, OnboardingConfig{Skipped: []string{"database"}})
+
+	if onboarding.Current != "database" || onboarding.Steps[0].Detail != "connection refused" {
+		t.Fatalf("database step skipped: %+v", onboarding.Steps[0])
+	}
+	if onboardingStepSkippable("database") || !onboardingStepSkippable("upload") || onboardingStepSkippable("unknown") {
+		t.Fatalf("unexpected skippable steps")
+	}
+}
@@ -74,6 +74,7 @@ type Options struct {
 	// Email settings (common to all providers)
 	EmailSmtpFromEmail string `json:"emailSmtpFromEmail"`
 	EmailSmtpFromName  string `json:"emailSmtpFromName"`
+	EmailDigestHour    uint   `json:"emailDigestHour"` // Hour of the daily alert digest (server time)
 	// Email logo settings
 	EmailLogoFilename     string `json:"emailLogoFilename"`     // Filename of logo file (stored in base directory)
 	EmailLogoBorderRadius string `json:"emailLogoBorderRadius"` // Border radius for email logo (e.g., "0px", "8px", "50%")
@@ -94,6 +95,43 @@ type Options struct {
 	TranscriptionEnhancement      bool                `json:"transcriptionEnhancement"`
 	TranscriptionFailureThreshold uint                `json:"transcriptionFailureThreshold"`
 	TranscriptParserConfig        TranscriptConfig    `json:"transcriptParserConfig"`
+	TranslationConfig             TranslationConfig   `json:"translationConfig"`
+	OnboardingConfig              OnboardingConfig    `json:"onboardingConfig"`
+	HeartbeatConfig               HeartbeatConfig     `json:"heartbeatConfig"`
+	ClientVersionConfig           ClientVersionConfig `json:"clientVersionConfig"`
+	RateLimitConfig               RateLimitConfig     `json:"rateLimitConfig"`
+	FeedDedupConfig               FeedDedupConfig     `json:"feedDedupConfig"`
+	BillingPlans                  []BillingPlan       `json:"billingPlans"`
+	CallArchiveConfig             CallArchiveConfig   `json:"callArchiveConfig"`
+	AudioStorageConfig            AudioStorageConfig  `json:"audioStorageConfig"`
+	LoudnessAnalysisEnabled       bool                `json:"loudnessAnalysisEnabled"`
+	LoudnessTargets               LoudnessTargets     `json:"loudnessTargets"`
+	FeedQualityEnabled            bool                `json:"feedQualityEnabled"`
+	LogicalChannels               LogicalChannels     `json:"logicalChannels"`
+	EscalationPolicies            EscalationPolicies  `json:"escalationPolicies"`
+	TTSConfig                     TTSConfig           `json:"ttsConfig"`
+	SummarizationConfig           SummarizationConfig `json:"summarizationConfig"`
+	IncidentConfig                IncidentConfig      `json:"incidentConfig"`
+	GeocodingConfig               GeocodingConfig     `json:"geocodingConfig"`
+	WeatherAlertConfig            WeatherAlertConfig  `json:"weatherAlertConfig"`
+	CADConfig                     CADConfig           `json:"cadConfig"`
+	PushDeliveryConfig            PushDeliveryConfig  `json:"pushDeliveryConfig"`
+	SpeechGateConfig              SpeechGateConfig    `json:"speechGateConfig"`
+	VADTrimConfig                 VADTrimConfig       `json:"vadTrimConfig"`
+	AudioProcessingConfig         AudioProcessingConfig `json:"audioProcessingConfig"`
+	IncidentRoutingConfig         IncidentRoutingConfig `json:"incidentRoutingConfig"`
+	StorageCapacityConfig         StorageCapacityConfig `json:"storageCapacityConfig"`
+	CallHLSConfig                 CallHLSConfig         `json:"callHlsConfig"`
+	ListenerAnalyticsConfig       ListenerAnalyticsConfig `json:"listenerAnalyticsConfig"`
+	UploadLimitsConfig            UploadLimitsConfig    `json:"uploadLimitsConfig"`
+	AudioTranscodeConfig          AudioTranscodeConfig  `json:"audioTranscodeConfig"`
+	TalkgroupSchedules            TalkgroupSchedules    `json:"talkgroupSchedules"`
+	ToneStitchingConfig           ToneStitchingConfig   `json:"toneStitchingConfig"`
+	RecordingGapConfig            RecordingGapConfig    `json:"recordingGapConfig"`
+	Webhooks                      Webhooks            `json:"webhooks"`
+	AlertRules                    []AlertRule         `json:"alertRules"`
+	OutboxEnabled                 bool                `json:"outboxEnabled"`
+	OutboxRetentionDays           uint                `json:"outboxRetentionDays"`
 	ToneDetectionIssueThreshold   uint                `json:"toneDetectionIssueThreshold"`
 	AlertRetentionDays            uint                `json:"alertRetentionDays"`
 	NoAudioThresholdMinutes       uint                `json:"noAudioThresholdMinutes"`
@@ -113,6 +151,13 @@ type Options struct {
 	TranscriptionFailureRepeatMinutes uint   `json:"transcriptionFailureRepeatMinutes"`
 	ToneDetectionRepeatMinutes        uint   `json:"toneDetectionRepeatMinutes"`
 	NoAudioRepeatMinutes              uint   `json:"noAudioRepeatMinutes"`
+	// Unusually busy talkgroup alerts (see activity_anomaly.go)
+	ActivityAnomalyAlertsEnabled bool    `json:"activityAnomalyAlertsEnabled"`
+	ActivityAnomalyNotifyUsers   bool    `json:"activityAnomalyNotifyUsers"`
+	ActivityAnomalySigma         float64 `json:"activityAnomalySigma"`
+	ActivityAnomalyMinCalls      uint    `json:"activityAnomalyMinCalls"`
+	ActivityAnomalyHistoryDays   uint    `json:"activityAnomalyHistoryDays"`
+	ActivityAnomalyRepeatMinutes uint    `json:"activityAnomalyRepeatMinutes"`
 	RelayServerURL                    string `json:"relayServerURL"`
 	RelayServerAPIKey                 string `json:"relayServerAPIKey"`
 	// After a successful one-time POST of all listener emails to the relay, this stays true (persisted).
@@ -164,6 +209,7 @@ type Options struct {
 	HydraTranscriptionEnabled bool   `json:"hydraTranscriptionEnabled"` // Per-server toggle for Hydra transcription
 	adminPassword             string
 	adminPasswordNeedChange   bool
+	setupSectionsApplied      string // config file sections already copied into the options (see applySetupSections)
 	mutex                     sync.Mutex
 	secret                    string
 }
@@ -171,7 +217,7 @@ type Options struct {
 // TranscriptionConfig contains configuration for transcription
 type TranscriptionConfig struct {
 	Enabled                     bool     `json:"enabled"`
-	Provider                    string   `json:"provider"` // "whisper-api", "azure", "google", "assemblyai", "cloudflare"
+	Provider                    string   `json:"provider"` // "whisper-api", "whisper-local", "azure", "google", "assemblyai", "deepgram", "cloudflare"
 	Language                    string   `json:"language"` // "en", "auto"
 	Prompt                      string   `json:"prompt"`   // Custom prompt for Whisper to guide transcription (e.g., terminology, formatting)
 	WorkerPoolSize              int      `json:"workerPoolSize"`
@@ -189,6 +235,16 @@ type TranscriptionConfig struct {
 	CloudflareAccountID         string   `json:"cloudflareAccountID"`         // Cloudflare account ID for Workers AI
 	CloudflareAPIToken          string   `json:"cloudflareAPIToken"`          // Cloudflare API token for Workers AI
 	CloudflareModel             string   `json:"cloudflareModel"`             // Cloudflare Workers AI model (default: @cf/openai/whisper-large-v3-turbo)
+	DeepgramKey                 string   `json:"deepgramKey"`                 // Deepgram API key
+	DeepgramModel               string   `json:"deepgramModel"`               // Deepgram model (default: nova-3)
+	DeepgramKeywords            []string `json:"deepgramKeywords"`            // Terms boosted in recognition (keyterms on nova-3, keywords on older models)
+	WhisperLocalEngine          string   `json:"whisperLocalEngine"`          // "whisper.cpp" (default) or "faster-whisper"
+	WhisperLocalBinary          string   `json:"whisperLocalBinary"`          // Executable path (default: whisper-cli or whisper-ctranslate2 from PATH)
+	WhisperLocalModel           string   `json:"whisperLocalModel"`           // ggml model file for whisper.cpp, model name/directory for faster-whisper
+	WhisperLocalGPU             bool     `json:"whisperLocalGPU"`             // Use the GPU (CUDA/Metal builds)
+	WhisperLocalThreads         int      `json:"whisperLocalThreads"`         // CPU threads per process (0 = half the cores)
+	WhisperLocalConcurrency     int      `json:"whisperLocalConcurrency"`     // Processes run at once regardless of worker count (0 = 1)
+	RealtimeKeywords            []string `json:"realtimeKeywords"`            // Critical keywords alerted from partial transcripts, before the call finishes (streaming providers only)
 	HallucinationPatterns       []string `json:"hallucinationPatterns"`       // Patterns to remove from transcripts (Whisper hallucinations)
 	HallucinationDetectionMode  string   `json:"hallucinationDetectionMode"`  // "off", "manual", "auto"
 	HallucinationMinOccurrences int      `json:"hallucinationMinOccurrences"` // Minimum times a phrase must appear in rejected calls before flagging (default: 5)
@@ -198,6 +254,8 @@ type TranscriptionConfig struct {
 	// until transcription is complete).  Default: 300 seconds (5 minutes).  Set higher for very
 	// slow CPUs.  0 = use default.
 	TimeoutSeconds int `json:"timeoutSeconds"`
+	// Chunked transcription of long calls, keyed by provider ("whisper-api", "deepgram", ...)
+	Chunking map[string]TranscriptionChunkingSettings `json:"chunking,omitempty"`
 	// Whisper training export — reviewed transcripts sent to transcript-collector on approve.
 	CollectorURL    string `json:"collectorURL"`
 	CollectorAPIKey string `json:"collectorAPIKey"`
@@ -211,6 +269,101 @@ type OpenAIIntegration struct {
 	Model   string `json:"model"` // chat model for naming (default gpt-5.4-mini)
 }
 
+// TranslationConfig stores an English translation next to the transcript of
+// calls on talkgroups with a non-English transcriptLanguage. The "openai"
+// provider uses the OpenAI integration credentials.
+type TranslationConfig struct {
+	Enabled  bool   `json:"enabled"`
+	Provider string `json:"provider"` // "openai" (default), "deepl", "libretranslate"
+	APIKey   string `json:"apiKey"`   // DeepL auth key or LibreTranslate API key
+	URL      string `json:"url"`      // LibreTranslate server, or a DeepL endpoint override
+}
+
+// OnboardingConfig is what the admin chose about the onboarding checklist:
+// steps marked as not needed on this server, and whether the checklist is
+// hidden altogether.
+type OnboardingConfig struct {
+	Dismissed bool     `json:"dismissed"`
+	Skipped   []string `json:"skipped"` // step ids
+}
+
+// HeartbeatConfig pings a monitoring URL on an interval with the health
+// payload. FailURL, when set, is pinged instead while the server is degraded.
+type HeartbeatConfig struct {
+	Enabled         bool   `json:"enabled"`
+	URL             string `json:"url"`
+	FailURL         string `json:"failUrl"`
+	Method          string `json:"method"`          // "POST" (default, JSON payload) or "GET"
+	IntervalSeconds uint   `json:"intervalSeconds"` // default 60, minimum 10
+	AuthHeader      string `json:"authHeader"`      // sent as the Authorization header
+}
+
+// ClientVersionConfig sets the oldest app versions allowed on the API. Older
+// apps are answered 426 Upgrade Required with Message and the store URL.
+type ClientVersionConfig struct {
+	MinIOS            string `json:"minIos"`     // e.g. "2.4.0"; empty allows every version
+	MinAndroid        string `json:"minAndroid"`
+	IOSUpgradeURL     string `json:"iosUpgradeUrl"`
+	AndroidUpgradeURL string `json:"androidUpgradeUrl"`
+	Message           string `json:"message"`
+}
+
+// RateLimitConfig tunes the brute-force protection of the login, PIN,
+// password reset and call upload endpoints. Zero values use the defaults.
+type RateLimitConfig struct {
+	Login                   RateLimitRule `json:"login"`
+	Pin                     RateLimitRule `json:"pin"`
+	PasswordReset           RateLimitRule `json:"passwordReset"`
+	Ingest                  RateLimitRule `json:"ingest"`                  // failed API keys on call uploads
+	IngestRequestsPerMinute int           `json:"ingestRequestsPerMinute"` // per IP and per API key; -1 disables
+	DisableAlerts           bool          `json:"disableAlerts"`
+	TrustedProxies          []string      `json:"trustedProxies"` // addresses or CIDRs; none trusts loopback only
+}
+
+// FeedDedupConfig tunes how duplicate uploads of the same call from several
+// feeds are merged. Zero values use the defaults.
+type FeedDedupConfig struct {
+	KeepFirst     bool    `json:"keepFirst"`     // never replace the first copy's audio
+	MinSimilarity float64 `json:"minSimilarity"` // audio fingerprint match required, 0-1 (default 0.80)
+}
+
+// RateLimitRule locks an IP or account out for LockoutSeconds after
+// MaxAttempts failures within WindowSeconds. Repeat lockouts double up to
+// MaxLockoutSeconds. MaxAttempts -1 disables the rule.
+type RateLimitRule struct {
+	MaxAttempts       int `json:"maxAttempts"`
+	WindowSeconds     int `json:"windowSeconds"`
+	LockoutSeconds    int `json:"lockoutSeconds"`
+	MaxLockoutSeconds int `json:"maxLockoutSeconds"`
+}
+
+// CallArchiveConfig moves the audio of calls older than AfterDays to S3-compatible
+// object storage. The calls row keeps a pointer ("audioLocation") and playback
+// fetches the audio back transparently. Objects are not removed when calls are
+// pruned; use a bucket lifecycle rule to expire them.
+type CallArchiveConfig struct {
+	Enabled         bool   `json:"enabled"`
+	AfterDays       uint   `json:"afterDays"`
+	Endpoint        string `json:"endpoint"` // e.g. "https://s3.us-east-1.amazonaws.com" or "http://minio:9000"
+	Region          string `json:"region"`
+	Bucket          string `json:"bucket"`
+	Prefix          string `json:"prefix"` // optional key prefix, e.g. "calls/"
+	AccessKeyId     string `json:"accessKeyId"`
+	SecretAccessKey string `json:"secretAccessKey"`
+	PathStyle       bool   `json:"pathStyle"` // required by MinIO and most self-hosted servers
+}
+
+// AudioStorageConfig selects where the audio of new calls is written. With
+// "filesystem" or "object" the calls row keeps only the location and a SHA-256
+// checksum of the audio. The object backend uses the CallArchiveConfig bucket.
+// With Dedup, identical audio (one transmission uploaded for several patched
+// talkgroups) is stored once and shared by the calls.
+type AudioStorageConfig struct {
+	Backend string `json:"backend"` // "database" (default), "filesystem", "object"
+	Path    string `json:"path"`    // filesystem root; relative paths are resolved from the base directory
+	Dedup   bool   `json:"dedup"`
+}
+
 const (
 	AUDIO_CONVERSION_DISABLED          = 0
 	AUDIO_CONVERSION_ENABLED           = 1
@@ -532,6 +685,17 @@ func (options *Options) FromMap(m map[string]any) *Options {
 		options.EmailSmtpFromName = defaults.options.emailSmtpFromName
 	}
 
+	switch v := m["emailDigestHour"].(type) {
+	case float64:
+		if v >= 0 && v <= 23 {
+			options.EmailDigestHour = uint(v)
+		} else {
+			options.EmailDigestHour = defaults.options.emailDigestHour
+		}
+	default:
+		options.EmailDigestHour = defaults.options.emailDigestHour
+	}
+
 	switch v := m["emailLogoFilename"].(type) {
 	case string:
 		options.EmailLogoFilename = v
@@ -829,6 +993,25 @@ func (options *Options) FromMap(m map[string]any) *Options {
 		options.NoAudioHistoricalDataDays = defaults.options.noAudioHistoricalDataDays
 	}
 
+	if v, ok := m["activityAnomalyAlertsEnabled"].(bool); ok {
+		options.ActivityAnomalyAlertsEnabled = v
+	}
+	if v, ok := m["activityAnomalyNotifyUsers"].(bool); ok {
+		options.ActivityAnomalyNotifyUsers = v
+	}
+	if v, ok := m["activityAnomalySigma"].(float64); ok && v > 0 {
+		options.ActivityAnomalySigma = v
+	}
+	if v, ok := m["activityAnomalyMinCalls"].(float64); ok && v > 0 {
+		options.ActivityAnomalyMinCalls = uint(v)
+	}
+	if v, ok := m["activityAnomalyHistoryDays"].(float64); ok && v > 0 {
+		options.ActivityAnomalyHistoryDays = uint(v)
+	}
+	if v, ok := m["activityAnomalyRepeatMinutes"].(float64); ok && v > 0 {
+		options.ActivityAnomalyRepeatMinutes = uint(v)
+	}
+
 	switch v := m["configSyncEnabled"].(type) {
 	case bool:
 		options.ConfigSyncEnabled = v
@@ -952,6 +1135,39 @@ func (options *Options) FromMap(m map[string]any) *Options {
 		if v, ok := tc["cloudflareModel"].(string); ok {
 			options.TranscriptionConfig.CloudflareModel = v
 		}
+		if v, ok := tc["deepgramKey"].(string); ok {
+			options.TranscriptionConfig.DeepgramKey = v
+		}
+		if v, ok := tc["deepgramModel"].(string); ok {
+			options.TranscriptionConfig.DeepgramModel = v
+		}
+		if v, ok := tc["deepgramKeywords"].([]interface{}); ok {
+			keywords := make([]string, 0, len(v))
+			for _, k := range v {
+				if str, ok := k.(string); ok && str != "" {
+					keywords = append(keywords, str)
+				}
+			}
+			options.TranscriptionConfig.DeepgramKeywords = keywords
+		}
+		if v, ok := tc["whisperLocalEngine"].(string); ok {
+			options.TranscriptionConfig.WhisperLocalEngine = v
+		}
+		if v, ok := tc["whisperLocalBinary"].(string); ok {
+			options.TranscriptionConfig.WhisperLocalBinary = v
+		}
+		if v, ok := tc["whisperLocalModel"].(string); ok {
+			options.TranscriptionConfig.WhisperLocalModel = v
+		}
+		if v, ok := tc["whisperLocalGPU"].(bool); ok {
+			options.TranscriptionConfig.WhisperLocalGPU = v
+		}
+		if v, ok := tc["whisperLocalThreads"].(float64); ok && v >= 0 {
+			options.TranscriptionConfig.WhisperLocalThreads = int(v)
+		}
+		if v, ok := tc["whisperLocalConcurrency"].(float64); ok && v >= 0 {
+			options.TranscriptionConfig.WhisperLocalConcurrency = int(v)
+		}
 		if v, ok := tc["assemblyAIWordBoost"].([]interface{}); ok {
 			wordBoost := make([]string, 0, len(v))
 			for _, wb := range v {
@@ -961,6 +1177,15 @@ func (options *Options) FromMap(m map[string]any) *Options {
 			}
 			options.TranscriptionConfig.AssemblyAIWordBoost = wordBoost
 		}
+		if v, ok := tc["realtimeKeywords"].([]interface{}); ok {
+			keywords := make([]string, 0, len(v))
+			for _, k := range v {
+				if str, ok := k.(string); ok && str != "" {
+					keywords = append(keywords, str)
+				}
+			}
+			options.TranscriptionConfig.RealtimeKeywords = keywords
+		}
 		if v, ok := tc["hallucinationPatterns"].([]interface{}); ok {
 			patterns := make([]string, 0, len(v))
 			for _, p := range v {
@@ -979,6 +1204,14 @@ func (options *Options) FromMap(m map[string]any) *Options {
 		if v, ok := tc["timeoutSeconds"].(float64); ok && v > 0 {
 			options.TranscriptionConfig.TimeoutSeconds = int(v)
 		}
+		if v, ok := tc["chunking"].(map[string]any); ok {
+			if b, err := json.Marshal(v); err == nil {
+				var chunking map[string]TranscriptionChunkingSettings
+				if err := json.Unmarshal(b, &chunking); err == nil {
+					options.TranscriptionConfig.Chunking = chunking
+				}
+			}
+		}
 	}
 
 	if oai, ok := m["openAIIntegration"].(map[string]any); ok {
@@ -990,9 +1223,403 @@ func (options *Options) FromMap(m map[string]any) *Options {
 		migrateLegacyOpenAIIntegration(options, alc)
 	}
 
+	if tc, ok := m["translationConfig"].(map[string]any); ok {
+		applyTranslationConfigFromMap(&options.TranslationConfig, tc)
+	}
+
+	if oc, ok := m["onboardingConfig"].(map[string]any); ok {
+		applyOnboardingConfigFromMap(&options.OnboardingConfig, oc)
+	}
+
+	if hc, ok := m["heartbeatConfig"].(map[string]any); ok {
+		applyHeartbeatConfigFromMap(&options.HeartbeatConfig, hc)
+	}
+
+	if cvc, ok := m["clientVersionConfig"].(map[string]any); ok {
+		applyClientVersionConfigFromMap(&options.ClientVersionConfig, cvc)
+	}
+
+	if rlc, ok := m["rateLimitConfig"].(map[string]any); ok {
+		applyRateLimitConfigFromMap(&options.RateLimitConfig, rlc)
+	}
+
+	if fdc, ok := m["feedDedupConfig"].(map[string]any); ok {
+		if v, ok := fdc["keepFirst"].(bool); ok {
+			options.FeedDedupConfig.KeepFirst = v
+		}
+		if v, ok := fdc["minSimilarity"].(float64); ok && v >= 0 && v <= 1 {
+			options.FeedDedupConfig.MinSimilarity = v
+		}
+	}
+
+	if bp, ok := m["billingPlans"].([]any); ok {
+		if b, err := json.Marshal(bp); err == nil {
+			var plans []BillingPlan
+			if err := json.Unmarshal(b, &plans); err == nil && validateBillingPlans(plans) == nil {
+				options.BillingPlans = plans
+			}
+		}
+	}
+
+	if cac, ok := m["callArchiveConfig"].(map[string]any); ok {
+		applyCallArchiveConfigFromMap(&options.CallArchiveConfig, cac)
+	}
+
+	if asc, ok := m["audioStorageConfig"].(map[string]any); ok {
+		if v, ok := asc["backend"].(string); ok {
+			options.AudioStorageConfig.Backend = strings.TrimSpace(v)
+		}
+		if v, ok := asc["path"].(string); ok {
+			options.AudioStorageConfig.Path = strings.TrimSpace(v)
+		}
+		if v, ok := asc["dedup"].(bool); ok {
+			options.AudioStorageConfig.Dedup = v
+		}
+	}
+
+	if v, ok := m["loudnessAnalysisEnabled"].(bool); ok {
+		options.LoudnessAnalysisEnabled = v
+	}
+
+	if v, ok := m["feedQualityEnabled"].(bool); ok {
+		options.FeedQualityEnabled = v
+	}
+
+	if v, ok := m["loudnessTargets"].([]any); ok {
+		options.LoudnessTargets = loudnessTargetsFromList(v)
+	}
+
+	if v, ok := m["logicalChannels"].([]any); ok {
+		options.LogicalChannels = logicalChannelsFromList(v)
+	}
+
+	if v, ok := m["escalationPolicies"].([]any); ok {
+		options.EscalationPolicies = escalationPoliciesFromList(v)
+	}
+
+	if tc, ok := m["ttsConfig"].(map[string]any); ok {
+		if b, err := json.Marshal(tc); err == nil {
+			var cfg TTSConfig
+			if err := json.Unmarshal(b, &cfg); err == nil {
+				options.TTSConfig = cfg
+			}
+		}
+	}
+
+	if sc, ok := m["summarizationConfig"].(map[string]any); ok {
+		if b, err := json.Marshal(sc); err == nil {
+			var cfg SummarizationConfig
+			if err := json.Unmarshal(b, &cfg); err == nil {
+				options.SummarizationConfig = cfg
+			}
+		}
+	}
+
+	if ic, ok := m["incidentConfig"].(map[string]any); ok {
+		if b, err := json.Marshal(ic); err == nil {
+			var cfg IncidentConfig
+			if err := json.Unmarshal(b, &cfg); err == nil {
+				options.IncidentConfig = cfg
+			}
+		}
+	}
+
+	if gc, ok := m["geocodingConfig"].(map[string]any); ok {
+		if b, err := json.Marshal(gc); err == nil {
+			var cfg GeocodingConfig
+			if err := json.Unmarshal(b, &cfg); err == nil {
+				options.GeocodingConfig = cfg
+			}
+		}
+	}
+
+	if wc, ok := m["weatherAlertConfig"].(map[string]any); ok {
+		if b, err := json.Marshal(wc); err == nil {
+			var cfg WeatherAlertConfig
+			if err := json.Unmarshal(b, &cfg); err == nil {
+				options.WeatherAlertConfig = cfg
+			}
+		}
+	}
+
+	if cc, ok := m["cadConfig"].(map[string]any); ok {
+		if b, err := json.Marshal(cc); err == nil {
+			var cfg CADConfig
+			if err := json.Unmarshal(b, &cfg); err == nil {
+				options.CADConfig = cfg
+			}
+		}
+	}
+
+	if pc, ok := m["pushDeliveryConfig"].(map[string]any); ok {
+		if b, err := json.Marshal(pc); err == nil {
+			var cfg PushDeliveryConfig
+			if err := json.Unmarshal(b, &cfg); err == nil {
+				options.PushDeliveryConfig = cfg
+			}
+		}
+	}
+
+	if sc, ok := m["speechGateConfig"].(map[string]any); ok {
+		if b, err := json.Marshal(sc); err == nil {
+			var cfg SpeechGateConfig
+			if err := json.Unmarshal(b, &cfg); err == nil {
+				options.SpeechGateConfig = cfg
+			}
+		}
+	}
+
+	if vc, ok := m["vadTrimConfig"].(map[string]any); ok {
+		if b, err := json.Marshal(vc); err == nil {
+			var cfg VADTrimConfig
+			if err := json.Unmarshal(b, &cfg); err == nil {
+				options.VADTrimConfig = cfg
+			}
+		}
+	}
+
+	if ac, ok := m["audioProcessingConfig"].(map[string]any); ok {
+		if b, err := json.Marshal(ac); err == nil {
+			var cfg AudioProcessingConfig
+			if err := json.Unmarshal(b, &cfg); err == nil {
+				options.AudioProcessingConfig = cfg
+			}
+		}
+	}
+
+	if ic, ok := m["incidentRoutingConfig"].(map[string]any); ok {
+		if b, err := json.Marshal(ic); err == nil {
+			var cfg IncidentRoutingConfig
+			if err := json.Unmarshal(b, &cfg); err == nil {
+				options.IncidentRoutingConfig = cfg
+			}
+		}
+	}
+
+	if sc, ok := m["storageCapacityConfig"].(map[string]any); ok {
+		if b, err := json.Marshal(sc); err == nil {
+			var cfg StorageCapacityConfig
+			if err := json.Unmarshal(b, &cfg); err == nil {
+				options.StorageCapacityConfig = cfg
+			}
+		}
+	}
+
+	if hc, ok := m["callHlsConfig"].(map[string]any); ok {
+		if b, err := json.Marshal(hc); err == nil {
+			var cfg CallHLSConfig
+			if err := json.Unmarshal(b, &cfg); err == nil {
+				options.CallHLSConfig = cfg
+			}
+		}
+	}
+
+	if lc, ok := m["listenerAnalyticsConfig"].(map[string]any); ok {
+		if b, err := json.Marshal(lc); err == nil {
+			var cfg ListenerAnalyticsConfig
+			if err := json.Unmarshal(b, &cfg); err == nil {
+				options.ListenerAnalyticsConfig = cfg
+			}
+		}
+	}
+
+	if uc, ok := m["uploadLimitsConfig"].(map[string]any); ok {
+		if b, err := json.Marshal(uc); err == nil {
+			var cfg UploadLimitsConfig
+			if err := json.Unmarshal(b, &cfg); err == nil {
+				options.UploadLimitsConfig = cfg
+			}
+		}
+	}
+
+	if tc, ok := m["audioTranscodeConfig"].(map[string]any); ok {
+		if b, err := json.Marshal(tc); err == nil {
+			var cfg AudioTranscodeConfig
+			if err := json.Unmarshal(b, &cfg); err == nil {
+				options.AudioTranscodeConfig = cfg
+			}
+		}
+	}
+
+	if v, ok := m["talkgroupSchedules"].([]any); ok {
+		options.TalkgroupSchedules = talkgroupSchedulesFromList(v)
+	}
+
+	if sc, ok := m["toneStitchingConfig"].(map[string]any); ok {
+		if b, err := json.Marshal(sc); err == nil {
+			var cfg ToneStitchingConfig
+			if err := json.Unmarshal(b, &cfg); err == nil {
+				options.ToneStitchingConfig = cfg
+			}
+		}
+	}
+
+	if gc, ok := m["recordingGapConfig"].(map[string]any); ok {
+		if b, err := json.Marshal(gc); err == nil {
+			var cfg RecordingGapConfig
+			if err := json.Unmarshal(b, &cfg); err == nil {
+				options.RecordingGapConfig = cfg
+			}
+		}
+	}
+
+	if v, ok := m["webhooks"].([]any); ok {
+		options.Webhooks = webhooksFromList(v)
+	}
+
+	if v, ok := m["alertRules"].([]any); ok {
+		options.AlertRules = alertRulesFromList(v)
+	}
+
+	if v, ok := m["outboxEnabled"].(bool); ok {
+		options.OutboxEnabled = v
+	}
+	if v, ok := m["outboxRetentionDays"].(float64); ok && v > 0 {
+		options.OutboxRetentionDays = uint(v)
+	}
+
 	return options
 }
 
+func applyTranslationConfigFromMap(cfg *TranslationConfig, m map[string]any) {
+	if v, ok := m["enabled"].(bool); ok {
+		cfg.Enabled = v
+	}
+	if v, ok := m["provider"].(string); ok {
+		cfg.Provider = strings.TrimSpace(v)
+	}
+	if v, ok := m["apiKey"].(string); ok {
+		cfg.APIKey = strings.TrimSpace(v)
+	}
+	if v, ok := m["url"].(string); ok {
+		cfg.URL = strings.TrimSpace(v)
+	}
+}
+
+func applyOnboardingConfigFromMap(cfg *OnboardingConfig, m map[string]any) {
+	if v, ok := m["dismissed"].(bool); ok {
+		cfg.Dismissed = v
+	}
+	if v, ok := m["skipped"].([]any); ok {
+		cfg.Skipped = []string{}
+		for _, id := range v {
+			if id, ok := id.(string); ok && onboardingStepSkippable(id) {
+				cfg.Skipped = append(cfg.Skipped, id)
+			}
+		}
+	}
+}
+
+func applyHeartbeatConfigFromMap(cfg *HeartbeatConfig, m map[string]any) {
+	if v, ok := m["enabled"].(bool); ok {
+		cfg.Enabled = v
+	}
+	if v, ok := m["url"].(string); ok {
+		cfg.URL = strings.TrimSpace(v)
+	}
+	if v, ok := m["failUrl"].(string); ok {
+		cfg.FailURL = strings.TrimSpace(v)
+	}
+	if v, ok := m["method"].(string); ok {
+		cfg.Method = strings.ToUpper(strings.TrimSpace(v))
+	}
+	if v, ok := m["intervalSeconds"].(float64); ok && v >= 0 {
+		cfg.IntervalSeconds = uint(v)
+	}
+	if v, ok := m["authHeader"].(string); ok {
+		cfg.AuthHeader = strings.TrimSpace(v)
+	}
+}
+
+func applyClientVersionConfigFromMap(cfg *ClientVersionConfig, m map[string]any) {
+	if v, ok := m["minIos"].(string); ok {
+		cfg.MinIOS = strings.TrimSpace(v)
+	}
+	if v, ok := m["minAndroid"].(string); ok {
+		cfg.MinAndroid = strings.TrimSpace(v)
+	}
+	if v, ok := m["iosUpgradeUrl"].(string); ok {
+		cfg.IOSUpgradeURL = strings.TrimSpace(v)
+	}
+	if v, ok := m["androidUpgradeUrl"].(string); ok {
+		cfg.AndroidUpgradeURL = strings.TrimSpace(v)
+	}
+	if v, ok := m["message"].(string); ok {
+		cfg.Message = strings.TrimSpace(v)
+	}
+}
+
+func applyRateLimitConfigFromMap(cfg *RateLimitConfig, m map[string]any) {
+	rules := map[string]*RateLimitRule{
+		"login":         &cfg.Login,
+		"pin":           &cfg.Pin,
+		"passwordReset": &cfg.PasswordReset,
+		"ingest":        &cfg.Ingest,
+	}
+	for name, rule := range rules {
+		r, ok := m[name].(map[string]any)
+		if !ok {
+			continue
+		}
+		if v, ok := r["maxAttempts"].(float64); ok {
+			rule.MaxAttempts = int(v)
+		}
+		if v, ok := r["windowSeconds"].(float64); ok && v >= 0 {
+			rule.WindowSeconds = int(v)
+		}
+		if v, ok := r["lockoutSeconds"].(float64); ok && v >= 0 {
+			rule.LockoutSeconds = int(v)
+		}
+		if v, ok := r["maxLockoutSeconds"].(float64); ok && v >= 0 {
+			rule.MaxLockoutSeconds = int(v)
+		}
+	}
+	if v, ok := m["ingestRequestsPerMinute"].(float64); ok {
+		cfg.IngestRequestsPerMinute = int(v)
+	}
+	if v, ok := m["disableAlerts"].(bool); ok {
+		cfg.DisableAlerts = v
+	}
+	if v, ok := m["trustedProxies"].([]any); ok {
+		cfg.TrustedProxies = nil
+		for _, proxy := range v {
+			if proxy, ok := proxy.(string); ok && strings.TrimSpace(proxy) != "" {
+				cfg.TrustedProxies = append(cfg.TrustedProxies, strings.TrimSpace(proxy))
+			}
+		}
+	}
+}
+
+func applyCallArchiveConfigFromMap(cfg *CallArchiveConfig, m map[string]any) {
+	if v, ok := m["enabled"].(bool); ok {
+		cfg.Enabled = v
+	}
+	if v, ok := m["afterDays"].(float64); ok && v >= 0 {
+		cfg.AfterDays = uint(v)
+	}
+	if v, ok := m["endpoint"].(string); ok {
+		cfg.Endpoint = strings.TrimSpace(v)
+	}
+	if v, ok := m["region"].(string); ok {
+		cfg.Region = strings.TrimSpace(v)
+	}
+	if v, ok := m["bucket"].(string); ok {
+		cfg.Bucket = strings.TrimSpace(v)
+	}
+	if v, ok := m["prefix"].(string); ok {
+		cfg.Prefix = strings.TrimSpace(v)
+	}
+	if v, ok := m["accessKeyId"].(string); ok {
+		cfg.AccessKeyId = strings.TrimSpace(v)
+	}
+	if v, ok := m["secretAccessKey"].(string); ok {
+		cfg.SecretAccessKey = v
+	}
+	if v, ok := m["pathStyle"].(bool); ok {
+		cfg.PathStyle = v
+	}
+}
+
 func applyOpenAIIntegrationFromMap(cfg *OpenAIIntegration, m map[string]any) {
 	if v, ok := m["apiKey"].(string); ok {
 		cfg.APIKey = v
@@ -1095,6 +1722,17 @@ func (options *Options) Read(db *Database) error {
 	options.TranscriptionFailureRepeatMinutes = defaults.options.transcriptionFailureRepeatMinutes
 	options.ToneDetectionRepeatMinutes = defaults.options.toneDetectionRepeatMinutes
 	options.NoAudioRepeatMinutes = defaults.options.noAudioRepeatMinutes
+	options.ActivityAnomalyAlertsEnabled = defaults.options.activityAnomalyAlertsEnabled
+	options.ActivityAnomalyNotifyUsers = defaults.options.activityAnomalyNotifyUsers
+	options.ActivityAnomalySigma = defaults.options.activityAnomalySigma
+	options.ActivityAnomalyMinCalls = defaults.options.activityAnomalyMinCalls
+	options.ActivityAnomalyHistoryDays = defaults.options.activityAnomalyHistoryDays
+	options.ActivityAnomalyRepeatMinutes = defaults.options.activityAnomalyRepeatMinutes
+	options.LoudnessAnalysisEnabled = defaults.options.loudnessAnalysisEnabled
+	options.FeedQualityEnabled = defaults.options.feedQualityEnabled
+	options.EmailDigestHour = defaults.options.emailDigestHour
+	options.OutboxEnabled = defaults.options.outboxEnabled
+	options.OutboxRetentionDays = defaults.options.outboxRetentionDays
 	options.AdminLocalhostOnly = defaults.options.adminLocalhostOnly
 	options.ConfigSyncEnabled = defaults.options.configSyncEnabled
 	options.ConfigSyncPath = defaults.options.configSyncPath
@@ -1140,6 +1778,13 @@ func (options *Options) Read(db *Database) error {
 					options.adminPasswordNeedChange = v
 				}
 			}
+		case "setupSectionsApplied":
+			if err = json.Unmarshal([]byte(value.String), &f); err == nil {
+				switch v := f.(type) {
+				case string:
+					options.setupSectionsApplied = v
+				}
+			}
 		case "audioConversion":
 			if err = json.Unmarshal([]byte(value.String), &f); err == nil {
 				switch v := f.(type) {
@@ -1391,6 +2036,15 @@ func (options *Options) Read(db *Database) error {
 					options.EmailSmtpFromName = v
 				}
 			}
+		case "emailDigestHour":
+			if err = json.Unmarshal([]byte(value.String), &f); err == nil {
+				switch v := f.(type) {
+				case float64:
+					if v >= 0 && v <= 23 {
+						options.EmailDigestHour = uint(v)
+					}
+				}
+			}
 		case "emailSendGridApiKey":
 			if err = json.Unmarshal([]byte(value.String), &f); err == nil {
 				switch v := f.(type) {
@@ -1578,6 +2232,201 @@ func (options *Options) Read(db *Database) error {
 			if err := json.Unmarshal([]byte(value.String), &cfg); err == nil {
 				options.TranscriptParserConfig = cfg
 			}
+		case "translationConfig":
+			var cfg TranslationConfig
+			if err := json.Unmarshal([]byte(value.String), &cfg); err == nil {
+				options.TranslationConfig = cfg
+			}
+		case "onboardingConfig":
+			var cfg OnboardingConfig
+			if err := json.Unmarshal([]byte(value.String), &cfg); err == nil {
+				options.OnboardingConfig = cfg
+			}
+		case "heartbeatConfig":
+			var cfg HeartbeatConfig
+			if err := json.Unmarshal([]byte(value.String), &cfg); err == nil {
+				options.HeartbeatConfig = cfg
+			}
+		case "clientVersionConfig":
+			var cfg ClientVersionConfig
+			if err := json.Unmarshal([]byte(value.String), &cfg); err == nil {
+				options.ClientVersionConfig = cfg
+			}
+		case "rateLimitConfig":
+			var cfg RateLimitConfig
+			if err := json.Unmarshal([]byte(value.String), &cfg); err == nil {
+				options.RateLimitConfig = cfg
+			}
+		case "feedDedupConfig":
+			var cfg FeedDedupConfig
+			if err := json.Unmarshal([]byte(value.String), &cfg); err == nil {
+				options.FeedDedupConfig = cfg
+			}
+		case "billingPlans":
+			var plans []BillingPlan
+			if err := json.Unmarshal([]byte(value.String), &plans); err == nil {
+				options.BillingPlans = plans
+			}
+		case "callArchiveConfig":
+			var cfg CallArchiveConfig
+			if err := json.Unmarshal([]byte(value.String), &cfg); err == nil {
+				options.CallArchiveConfig = cfg
+			}
+		case "audioStorageConfig":
+			var cfg AudioStorageConfig
+			if err := json.Unmarshal([]byte(value.String), &cfg); err == nil {
+				options.AudioStorageConfig = cfg
+			}
+		case "loudnessAnalysisEnabled":
+			if err = json.Unmarshal([]byte(value.String), &f); err == nil {
+				switch v := f.(type) {
+				case bool:
+					options.LoudnessAnalysisEnabled = v
+				}
+			}
+		case "feedQualityEnabled":
+			if err = json.Unmarshal([]byte(value.String), &f); err == nil {
+				switch v := f.(type) {
+				case bool:
+					options.FeedQualityEnabled = v
+				}
+			}
+		case "loudnessTargets":
+			var targets LoudnessTargets
+			if err := json.Unmarshal([]byte(value.String), &targets); err == nil {
+				options.LoudnessTargets = targets
+			}
+		case "outboxEnabled":
+			if err = json.Unmarshal([]byte(value.String), &f); err == nil {
+				switch v := f.(type) {
+				case bool:
+					options.OutboxEnabled = v
+				}
+			}
+		case "outboxRetentionDays":
+			if err = json.Unmarshal([]byte(value.String), &f); err == nil {
+				switch v := f.(type) {
+				case float64:
+					options.OutboxRetentionDays = uint(v)
+				}
+			}
+		case "logicalChannels":
+			var channels LogicalChannels
+			if err := json.Unmarshal([]byte(value.String), &channels); err == nil {
+				options.LogicalChannels = channels
+			}
+		case "escalationPolicies":
+			var policies EscalationPolicies
+			if err := json.Unmarshal([]byte(value.String), &policies); err == nil {
+				options.EscalationPolicies = policies
+			}
+		case "ttsConfig":
+			var cfg TTSConfig
+			if err := json.Unmarshal([]byte(value.String), &cfg); err == nil {
+				options.TTSConfig = cfg
+			}
+		case "summarizationConfig":
+			var cfg SummarizationConfig
+			if err := json.Unmarshal([]byte(value.String), &cfg); err == nil {
+				options.SummarizationConfig = cfg
+			}
+		case "incidentConfig":
+			var cfg IncidentConfig
+			if err := json.Unmarshal([]byte(value.String), &cfg); err == nil {
+				options.IncidentConfig = cfg
+			}
+		case "geocodingConfig":
+			var cfg GeocodingConfig
+			if err := json.Unmarshal([]byte(value.String), &cfg); err == nil {
+				options.GeocodingConfig = cfg
+			}
+		case "weatherAlertConfig":
+			var cfg WeatherAlertConfig
+			if err := json.Unmarshal([]byte(value.String), &cfg); err == nil {
+				options.WeatherAlertConfig = cfg
+			}
+		case "cadConfig":
+			var cfg CADConfig
+			if err := json.Unmarshal([]byte(value.String), &cfg); err == nil {
+				options.CADConfig = cfg
+			}
+		case "pushDeliveryConfig":
+			var cfg PushDeliveryConfig
+			if err := json.Unmarshal([]byte(value.String), &cfg); err == nil {
+				options.PushDeliveryConfig = cfg
+			}
+		case "speechGateConfig":
+			var cfg SpeechGateConfig
+			if err := json.Unmarshal([]byte(value.String), &cfg); err == nil {
+				options.SpeechGateConfig = cfg
+			}
+		case "vadTrimConfig":
+			var cfg VADTrimConfig
+			if err := json.Unmarshal([]byte(value.String), &cfg); err == nil {
+				options.VADTrimConfig = cfg
+			}
+		case "audioProcessingConfig":
+			var cfg AudioProcessingConfig
+			if err := json.Unmarshal([]byte(value.String), &cfg); err == nil {
+				options.AudioProcessingConfig = cfg
+			}
+		case "incidentRoutingConfig":
+			var cfg IncidentRoutingConfig
+			if err := json.Unmarshal([]byte(value.String), &cfg); err == nil {
+				options.IncidentRoutingConfig = cfg
+			}
+		case "storageCapacityConfig":
+			var cfg StorageCapacityConfig
+			if err := json.Unmarshal([]byte(value.String), &cfg); err == nil {
+				options.StorageCapacityConfig = cfg
+			}
+		case "callHlsConfig":
+			var cfg CallHLSConfig
+			if err := json.Unmarshal([]byte(value.String), &cfg); err == nil {
+				options.CallHLSConfig = cfg
+			}
+		case "listenerAnalyticsConfig":
+			var cfg ListenerAnalyticsConfig
+			if err := json.Unmarshal([]byte(value.String), &cfg); err == nil {
+				options.ListenerAnalyticsConfig = cfg
+			}
+		case "uploadLimitsConfig":
+			var cfg UploadLimitsConfig
+			if err := json.Unmarshal([]byte(value.String), &cfg); err == nil {
+				options.UploadLimitsConfig = cfg
+			}
+		case "audioTranscodeConfig":
+			var cfg AudioTranscodeConfig
+			if err := json.Unmarshal([]byte(value.String), &cfg); err == nil {
+				options.AudioTranscodeConfig = cfg
+			}
+		case "talkgroupSchedules":
+			// Parsed like an admin save so the expressions are compiled
+			var schedules []any
+			if err := json.Unmarshal([]byte(value.String), &schedules); err == nil {
+				options.TalkgroupSchedules = talkgroupSchedulesFromList(schedules)
+			}
+		case "toneStitchingConfig":
+			var cfg ToneStitchingConfig
+			if err := json.Unmarshal([]byte(value.String), &cfg); err == nil {
+				options.ToneStitchingConfig = cfg
+			}
+		case "recordingGapConfig":
+			var cfg RecordingGapConfig
+			if err := json.Unmarshal([]byte(value.String), &cfg); err == nil {
+				options.RecordingGapConfig = cfg
+			}
+		case "webhooks":
+			// Parsed like an admin save so webhooks stored with a single "event" still load
+			var webhooks []any
+			if err := json.Unmarshal([]byte(value.String), &webhooks); err == nil {
+				options.Webhooks = webhooksFromList(webhooks)
+			}
+		case "alertRules":
+			var rules []any
+			if err := json.Unmarshal([]byte(value.String), &rules); err == nil {
+				options.AlertRules = alertRulesFromList(rules)
+			}
 		case "transcriptionEnhancement":
 			if err = json.Unmarshal([]byte(value.String), &f); err == nil {
 				switch v := f.(type) {
@@ -1697,6 +2546,48 @@ func (options *Options) Read(db *Database) error {
 					options.NoAudioRepeatMinutes = uint(v)
 				}
 			}
+		case "activityAnomalyAlertsEnabled":
+			if err = json.Unmarshal([]byte(value.String), &f); err == nil {
+				switch v := f.(type) {
+				case bool:
+					options.ActivityAnomalyAlertsEnabled = v
+				}
+			}
+		case "activityAnomalyNotifyUsers":
+			if err = json.Unmarshal([]byte(value.String), &f); err == nil {
+				switch v := f.(type) {
+				case bool:
+					options.ActivityAnomalyNotifyUsers = v
+				}
+			}
+		case "activityAnomalySigma":
+			if err = json.Unmarshal([]byte(value.String), &f); err == nil {
+				switch v := f.(type) {
+				case float64:
+					options.ActivityAnomalySigma = v
+				}
+			}
+		case "activityAnomalyMinCalls":
+			if err = json.Unmarshal([]byte(value.String), &f); err == nil {
+				switch v := f.(type) {
+				case float64:
+					options.ActivityAnomalyMinCalls = uint(v)
+				}
+			}
+		case "activityAnomalyHistoryDays":
+			if err = json.Unmarshal([]byte(value.String), &f); err == nil {
+				switch v := f.(type) {
+				case float64:
+					options.ActivityAnomalyHistoryDays = uint(v)
+				}
+			}
+		case "activityAnomalyRepeatMinutes":
+			if err = json.Unmarshal([]byte(value.String), &f); err == nil {
+				switch v := f.(type) {
+				case float64:
+					options.ActivityAnomalyRepeatMinutes = uint(v)
+				}
+			}
 		case "relayServerURL":
 			if err = json.Unmarshal([]byte(value.String), &f); err == nil {
 				switch v := f.(type) {
@@ -1901,6 +2792,7 @@ func (options *Options) Write(db *Database) error {
 
 	set("adminPassword", options.adminPassword)
 	set("adminPasswordNeedChange", options.adminPasswordNeedChange)
+	set("setupSectionsApplied", options.setupSectionsApplied)
 	set("audioConversion", options.AudioConversion)
 	set("autoPopulate", options.AutoPopulate)
 	set("branding", options.Branding)
@@ -1937,6 +2829,7 @@ func (options *Options) Write(db *Database) error {
 	set("emailProvider", options.EmailProvider)
 	set("emailSmtpFromEmail", options.EmailSmtpFromEmail)
 	set("emailSmtpFromName", options.EmailSmtpFromName)
+	set("emailDigestHour", options.EmailDigestHour)
 	set("emailSendGridApiKey", options.EmailSendGridAPIKey)
 	set("emailMailgunApiKey", options.EmailMailgunAPIKey)
 	set("emailMailgunDomain", options.EmailMailgunDomain)
@@ -1974,6 +2867,12 @@ func (options *Options) Write(db *Database) error {
 	set("transcriptionFailureRepeatMinutes", options.TranscriptionFailureRepeatMinutes)
 	set("toneDetectionRepeatMinutes", options.ToneDetectionRepeatMinutes)
 	set("noAudioRepeatMinutes", options.NoAudioRepeatMinutes)
+	set("activityAnomalyAlertsEnabled", options.ActivityAnomalyAlertsEnabled)
+	set("activityAnomalyNotifyUsers", options.ActivityAnomalyNotifyUsers)
+	set("activityAnomalySigma", options.ActivityAnomalySigma)
+	set("activityAnomalyMinCalls", options.ActivityAnomalyMinCalls)
+	set("activityAnomalyHistoryDays", options.ActivityAnomalyHistoryDays)
+	set("activityAnomalyRepeatMinutes", options.ActivityAnomalyRepeatMinutes)
 	set("relayServerURL", options.RelayServerURL)
 	set("relayServerAPIKey", options.RelayServerAPIKey)
 	set("relayListenerEmailsInitialSyncDone", options.RelayListenerEmailsInitialSyncDone)
@@ -2001,6 +2900,43 @@ func (options *Options) Write(db *Database) error {
 	set("autoLearnToneSetConfig", options.AutoLearnToneSetConfig)
 	set("transcriptionEnhancement", options.TranscriptionEnhancement)
 	set("transcriptParserConfig", options.TranscriptParserConfig)
+	set("translationConfig", options.TranslationConfig)
+	set("onboardingConfig", options.OnboardingConfig)
+	set("heartbeatConfig", options.HeartbeatConfig)
+	set("clientVersionConfig", options.ClientVersionConfig)
+	set("rateLimitConfig", options.RateLimitConfig)
+	set("feedDedupConfig", options.FeedDedupConfig)
+	set("billingPlans", options.BillingPlans)
+	set("callArchiveConfig", options.CallArchiveConfig)
+	set("audioStorageConfig", options.AudioStorageConfig)
+	set("loudnessAnalysisEnabled", options.LoudnessAnalysisEnabled)
+	set("feedQualityEnabled", options.FeedQualityEnabled)
+	set("loudnessTargets", options.LoudnessTargets)
+	set("logicalChannels", options.LogicalChannels)
+	set("escalationPolicies", options.EscalationPolicies)
+	set("ttsConfig", options.TTSConfig)
+	set("summarizationConfig", options.SummarizationConfig)
+	set("incidentConfig", options.IncidentConfig)
+	set("geocodingConfig", options.GeocodingConfig)
+	set("weatherAlertConfig", options.WeatherAlertConfig)
+	set("cadConfig", options.CADConfig)
+	set("pushDeliveryConfig", options.PushDeliveryConfig)
+	set("speechGateConfig", options.SpeechGateConfig)
+	set("vadTrimConfig", options.VADTrimConfig)
+	set("audioProcessingConfig", options.AudioProcessingConfig)
+	set("incidentRoutingConfig", options.IncidentRoutingConfig)
+	set("storageCapacityConfig", options.StorageCapacityConfig)
+	set("callHlsConfig", options.CallHLSConfig)
+	set("listenerAnalyticsConfig", options.ListenerAnalyticsConfig)
+	set("uploadLimitsConfig", options.UploadLimitsConfig)
+	set("audioTranscodeConfig", options.AudioTranscodeConfig)
+	set("talkgroupSchedules", options.TalkgroupSchedules)
+	set("toneStitchingConfig", options.ToneStitchingConfig)
+	set("recordingGapConfig", options.RecordingGapConfig)
+	set("webhooks", options.Webhooks)
+	set("alertRules", options.AlertRules)
+	set("outboxEnabled", options.OutboxEnabled)
+	set("outboxRetentionDays", options.OutboxRetentionDays)
 
 	if setErr != nil {
 		tx.Rollback()
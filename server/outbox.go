@@ -0,0 +1,260 @@
+// Copyright (C) 2026 Thinline Dynamic Solutions
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>
+
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// The outbox is an append-only table of call and configuration change events
+// written in the same transaction as the change itself. Analytics pipelines
+// read it with logical decoding (Debezium's outbox event router) or page
+// through /api/admin/outbox, instead of polling the calls and config tables.
+//
+// Event types:
+//   call.created         a call was stored
+//   call.transcribed     a call's transcript was stored
+//   <type>.created       configuration entity added (system, talkgroup, group, tag)
+//   <type>.updated       configuration entity changed; payload carries the changed fields
+//   <type>.deleted       configuration entity removed
+//
+// Calls removed by pruning or retention policies are not published, the
+// warehouse keeps its own history.
+
+const outboxSchemaVersion = 1
+
+type OutboxEvent struct {
+	Id            uint64          `json:"id"`
+	AggregateType string          `json:"aggregateType"`
+	AggregateId   string          `json:"aggregateId"`
+	EventType     string          `json:"eventType"`
+	Payload       json.RawMessage `json:"payload"`
+	CreatedAt     int64           `json:"createdAt"`
+}
+
+// outboxConfigSections are the configuration sections published to the
+// outbox. Users, API keys, dirwatches, downstreams and options are left out as
+// they hold personal data and credentials.
+var outboxConfigSections = []struct {
+	aggregateType string
+	section       string
+	keyField      string
+	labelField    string
+	ignore        []string
+}{
+	{"system", "systems", "systemRef", "label", []string{"talkgroups", "units", "sites"}},
+	{"talkgroup", "talkgroups", "key", "label", nil},
+	{"group", "groups", "id", "label", nil},
+	{"tag", "tags", "id", "label", nil},
+}
+
+func newOutboxEvent(aggregateType string, aggregateId string, eventType string, payload map[string]any) OutboxEvent {
+	payload["schemaVersion"] = outboxSchemaVersion
+	b, _ := json.Marshal(payload)
+	return OutboxEvent{
+		AggregateType: aggregateType,
+		AggregateId:   aggregateId,
+		EventType:     eventType,
+		Payload:       b,
+		CreatedAt:     time.Now().UnixMilli(),
+	}
+}
+
+// newCallOutboxEvent describes a call without its audio.
+func newCallOutboxEvent(eventType string, call *Call) OutboxEvent {
+	payload := map[string]any{
+		"callId":              call.Id,
+		"timestamp":           call.Timestamp.UnixMilli(),
+		"duration":            call.Duration,
+		"frequency":           call.Frequency,
+		"siteRef":             call.SiteRef,
+		"hasTones":            call.HasTones,
+		"transcript":          call.Transcript,
+		"transcriptionStatus": call.TranscriptionStatus,
+		"units":               len(call.Units),
+		"patches":             call.Patches,
+	}
+	if call.System != nil {
+		payload["systemId"] = call.System.Id
+		payload["systemRef"] = call.System.SystemRef
+		payload["systemLabel"] = call.System.Label
+	}
+	if call.Talkgroup != nil {
+		payload["talkgroupId"] = call.Talkgroup.Id
+		payload["talkgroupRef"] = call.Talkgroup.TalkgroupRef
+		payload["talkgroupLabel"] = call.Talkgroup.Label
+	}
+	return newOutboxEvent("call", strconv.FormatUint(call.Id, 10), eventType, payload)
+}
+
+// outboxEventsForConfig lists the entity changes between two configuration
+// states as recorded by ConfigHistory. from is nil for the first revision.
+func outboxEventsForConfig(from map[string]any, to map[string]any) []OutboxEvent {
+	events := []OutboxEvent{}
+
+	section := func(state map[string]any, name string) any {
+		if name == "talkgroups" {
+			return flattenConfigTalkgroups(state["systems"])
+		}
+		return state[name]
+	}
+
+	for _, s := range outboxConfigSections {
+		diff := diffConfigSection(section(from, s.section), section(to, s.section), s.keyField, s.labelField, s.ignore...)
+
+		entities := map[string]map[string]any{}
+		items, _ := section(to, s.section).([]any)
+		for _, item := range items {
+			if entity, ok := item.(map[string]any); ok {
+				entity = copyConfigEntity(entity, s.ignore)
+				entities[configValueString(entity[s.keyField])] = entity
+			}
+		}
+
+		for _, change := range diff.Added {
+			events = append(events, newOutboxEvent(s.aggregateType, change.Key, s.aggregateType+".created", map[string]any{"entity": entities[change.Key]}))
+		}
+		for _, change := range diff.Changed {
+			events = append(events, newOutboxEvent(s.aggregateType, change.Key, s.aggregateType+".updated", map[string]any{"entity": entities[change.Key], "changes": change.Changes}))
+		}
+		for _, change := range diff.Removed {
+			events = append(events, newOutboxEvent(s.aggregateType, change.Key, s.aggregateType+".deleted", map[string]any{"label": change.Label}))
+		}
+	}
+
+	return events
+}
+
+func (controller *Controller) outboxEnabled() bool {
+	return controller != nil && controller.Options != nil && controller.Options.OutboxEnabled
+}
+
+// writeOutboxEvents inserts events as part of tx, so they are only published
+// when the change they describe is committed.
+func writeOutboxEvents(tx *sql.Tx, events ...OutboxEvent) error {
+	query := `INSERT INTO "outboxEvents" ("aggregateType", "aggregateId", "eventType", "payload", "createdAt") VALUES ($1, $2, $3, $4, $5)`
+	for _, event := range events {
+		if _, err := tx.Exec(query, event.AggregateType, event.AggregateId, event.EventType, string(event.Payload), event.CreatedAt); err != nil {
+			return fmt.Errorf("outbox: %v", err)
+		}
+	}
+	return nil
+}
+
+// ListOutboxEvents returns up to limit events with an id greater than after,
+// oldest first.
+func (controller *Controller) ListOutboxEvents(after uint64, limit int) ([]OutboxEvent, error) {
+	events := []OutboxEvent{}
+
+	query := `SELECT "outboxEventId", "aggregateType", "aggregateId", "eventType", "payload", "createdAt" FROM "outboxEvents" WHERE "outboxEventId" > $1 ORDER BY "outboxEventId" ASC LIMIT $2`
+	rows, err := controller.Database.Sql.Query(query, after, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			event   OutboxEvent
+			payload string
+		)
+		if err := rows.Scan(&event.Id, &event.AggregateType, &event.AggregateId, &event.EventType, &payload, &event.CreatedAt); err != nil {
+			return nil, err
+		}
+		event.Payload = json.RawMessage(payload)
+		events = append(events, event)
+	}
+
+	return events, rows.Err()
+}
+
+// PruneOutboxEvents drops events older than the outbox retention. Events are
+// kept while the outbox is disabled so a consumer can still catch up.
+func (controller *Controller) PruneOutboxEvents() error {
+	days := controller.Options.OutboxRetentionDays
+	if days == 0 {
+		days = defaults.options.outboxRetentionDays
+	}
+	cutoff := time.Now().Add(-24 * time.Hour * time.Duration(days)).UnixMilli()
+
+	_, err := controller.Database.Sql.Exec(`DELETE FROM "outboxEvents" WHERE "createdAt" < $1`, cutoff)
+	return err
+}
+
+// OutboxHandler pages through the outbox for consumers that don't use logical
+// decoding. GET /api/admin/outbox?after=<id>&limit=<n>; limit defaults to 500
+// and is capped at 5000. Pass the returned lastId as after on the next request.
+func (admin *Admin) OutboxHandler(w http.ResponseWriter, r *http.Request) {
+	t := admin.GetAuthorization(r)
+	if !admin.ValidateToken(t) {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	writeError := func(status int, message string) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(map[string]string{"error": message})
+	}
+
+	var after uint64
+	if s := r.URL.Query().Get("after"); s != "" {
+		v, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			writeError(http.StatusBadRequest, "after must be an event id")
+			return
+		}
+		after = v
+	}
+
+	limit := 500
+	if s := r.URL.Query().Get("limit"); s != "" {
+		v, err := strconv.Atoi(s)
+		if err != nil || v <= 0 {
+			writeError(http.StatusBadRequest, "limit must be a positive number")
+			return
+		}
+		limit = min(v, 5000)
+	}
+
+	events, err := admin.Controller.ListOutboxEvents(after, limit)
+	if err != nil {
+		writeError(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	lastId := after
+	if len(events) > 0 {
+		lastId = events[len(events)-1].Id
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"enabled": admin.Controller.Options.OutboxEnabled,
+		"events":  events,
+		"lastId":  lastId,
+	})
+}
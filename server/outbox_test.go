@@ -0,0 +1,91 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestOutboxEventsForConfig(t *testing.T) {
+	from := testConfigState(t, `{
+		"systems": [{"id": 1, "systemRef": 42, "label": "County", "talkgroups": [
+			{"id": 10, "talkgroupRef": 1201, "label": "Fire Dispatch", "delay": 0},
+			{"id": 11, "talkgroupRef": 1202, "label": "Fire Tac"}
+		]}],
+		"users": [{"id": 1, "email": "a@example.com"}],
+		"options": {"branding": "x"}
+	}`)
+	to := testConfigState(t, `{
+		"systems": [{"id": 1, "systemRef": 42, "label": "County", "talkgroups": [
+			{"id": 10, "talkgroupRef": 1201, "label": "Fire Dispatch", "delay": 30},
+			{"id": 12, "talkgroupRef": 1300, "label": "EMS"}
+		]}],
+		"users": [{"id": 1, "email": "b@example.com"}],
+		"options": {"branding": "y"}
+	}`)
+
+	types := map[string]OutboxEvent{}
+	for _, event := range outboxEventsForConfig(from, to) {
+		types[event.EventType+" "+event.AggregateId] = event
+	}
+	if len(types) != 3 {
+		t.Fatalf("expected 3 events (users and options are not published), got %v", types)
+	}
+
+	updated, ok := types["talkgroup.updated 42/1201"]
+	if !ok {
+		t.Fatalf("missing talkgroup.updated: %v", types)
+	}
+	var payload struct {
+		SchemaVersion int                          `json:"schemaVersion"`
+		Changes       map[string]ConfigFieldChange `json:"changes"`
+	}
+	if err := json.Unmarshal(updated.Payload, &payload); err != nil {
+		t.Fatal(err)
+	}
+	if payload.SchemaVersion != outboxSchemaVersion || payload.Changes["delay"].To != float64(30) {
+		t.Fatalf("unexpected payload %s", updated.Payload)
+	}
+
+	if _, ok := types["talkgroup.created 42/1300"]; !ok {
+		t.Fatalf("missing talkgroup.created: %v", types)
+	}
+	if _, ok := types["talkgroup.deleted 42/1202"]; !ok {
+		t.Fatalf("missing talkgroup.deleted: %v", types)
+	}
+}
+
+func TestOutboxEventsForFirstRevision(t *testing.T) {
+	to := testConfigState(t, `{"tags": [{"id": 1, "label": "Fire"}], "systems": [{"systemRef": 5, "label": "City", "talkgroups": []}]}`)
+
+	events := outboxEventsForConfig(nil, to)
+	if len(events) != 2 || events[0].EventType != "system.created" || events[1].EventType != "tag.created" {
+		t.Fatalf("expected a created event per entity, got %+v", events)
+	}
+}
+
+func TestCallOutboxEvent(t *testing.T) {
+	call := &Call{
+		Id:        77,
+		Audio:     []byte{1, 2, 3},
+		Timestamp: time.UnixMilli(1700000000000),
+		System:    &System{Id: 1, SystemRef: 42, Label: "County"},
+		Talkgroup: &Talkgroup{Id: 10, TalkgroupRef: 1201, Label: "Fire Dispatch"},
+	}
+
+	event := newCallOutboxEvent("call.created", call)
+	if event.AggregateType != "call" || event.AggregateId != "77" {
+		t.Fatalf("unexpected envelope %+v", event)
+	}
+
+	var payload map[string]any
+	if err := json.Unmarshal(event.Payload, &payload); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := payload["audio"]; ok {
+		t.Fatal("audio must not be published")
+	}
+	if payload["talkgroupRef"] != float64(1201) || payload["timestamp"] != float64(1700000000000) {
+		t.Fatalf("unexpected payload %v", payload)
+	}
+}
@@ -0,0 +1,441 @@
+// Copyright (C) 2026 Thinline Dynamic Solutions
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>
+
+package main
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// pagingMaxAttempts deliveries are tried, waiting pagingRetryDelay
+	// before the second and doubling after each failure.
+	pagingMaxAttempts = 5
+	pagingRetryDelay  = 5 * time.Second
+
+	pagingStatusPending   = "pending"
+	pagingStatusDelivered = "delivered"
+	pagingStatusFailed    = "failed"
+)
+
+// PagingDispatch is a dispatch forwarded to a paging service when a tone set
+// matches.
+type PagingDispatch struct {
+	CallId         uint64 `json:"callId"`
+	System         uint   `json:"system"`
+	SystemLabel    string `json:"systemLabel"`
+	Talkgroup      uint   `json:"talkgroup"`
+	TalkgroupLabel string `json:"talkgroupLabel"`
+	Timestamp      int64  `json:"timestamp"` // Unix milliseconds
+	ToneSetId      string `json:"toneSetId"`
+	ToneSetLabel   string `json:"toneSetLabel"`
+	Transcript     string `json:"transcript"`
+	AudioUrl       string `json:"audioUrl,omitempty"`
+}
+
+// PagingDelivery is a dispatch sent to a paging service, with its status.
+type PagingDelivery struct {
+	Id        uint64 `json:"id"`
+	CallId    uint64 `json:"callId"`
+	ToneSetId string `json:"toneSetId"`
+	Provider  string `json:"provider"`
+	Status    string `json:"status"`
+	Attempts  int    `json:"attempts"`
+	LastError string `json:"lastError,omitempty"`
+	CreatedAt int64  `json:"createdAt"`
+	UpdatedAt int64  `json:"updatedAt"`
+}
+
+// callAudioURL returns the public URL of the audio of a call, or "" when no
+// base URL is configured.
+func (controller *Controller) callAudioURL(callId uint64) string {
+	baseUrl := strings.TrimRight(controller.Options.BaseUrl, "/")
+	if baseUrl == "" {
+		return ""
+	}
+	if !strings.HasPrefix(baseUrl, "http://") && !strings.HasPrefix(baseUrl, "https://") {
+		baseUrl = "https://" + baseUrl
+	}
+	return fmt.Sprintf("%s/api/calls/%d/audio", baseUrl, callId)
+}
+
+func newPagingDispatch(controller *Controller, call *Call, toneSet *ToneSet) *PagingDispatch {
+	dispatch := &PagingDispatch{
+		CallId:       call.Id,
+		Timestamp:    call.Timestamp.UnixMilli(),
+		ToneSetId:    toneSet.Id,
+		ToneSetLabel: toneSet.Label,
+		Transcript:   call.Transcript,
+		AudioUrl:     controller.callAudioURL(call.Id),
+	}
+	if call.System != nil {
+		dispatch.System = call.System.SystemRef
+		dispatch.SystemLabel = call.System.Label
+	}
+	if call.Talkgroup != nil {
+		dispatch.Talkgroup = call.Talkgroup.TalkgroupRef
+		dispatch.TalkgroupLabel = call.Talkgroup.Label
+	}
+	return dispatch
+}
+
+// pagingRequest builds the request for the paging provider of a tone set.
+func pagingRequest(toneSet *ToneSet, dispatch *PagingDispatch) (*http.Request, error) {
+	if toneSet.DispatchURL == "" {
+		return nil, fmt.Errorf("no dispatch URL for tone set %q", toneSet.Label)
+	}
+
+	title := dispatch.ToneSetLabel
+	details := dispatch.Transcript
+	if details == "" {
+		details = fmt.Sprintf("Tones on %s", dispatch.TalkgroupLabel)
+	}
+	if dispatch.AudioUrl != "" {
+		details += "\n\nAudio: " + dispatch.AudioUrl
+	}
+	sent := time.UnixMilli(dispatch.Timestamp).UTC().Format(time.RFC3339)
+
+	var body map[string]any
+	switch toneSet.DispatchProvider {
+	case "active911":
+		body = map[string]any{
+			"description": title,
+			"details":     details,
+			"source":      "ThinLineRadio",
+			"talkgroup":   dispatch.TalkgroupLabel,
+			"audio_url":   dispatch.AudioUrl,
+			"sent":        sent,
+		}
+	case "iamresponding":
+		body = map[string]any{
+			"agency":    toneSet.DispatchAgency,
+			"subject":   title,
+			"body":      details,
+			"talkgroup": dispatch.TalkgroupLabel,
+			"audioUrl":  dispatch.AudioUrl,
+			"sent":      sent,
+		}
+	default:
+		return nil, fmt.Errorf("unknown dispatch provider %q", toneSet.DispatchProvider)
+	}
+	body["dispatch"] = dispatch
+
+	b, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest(http.MethodPost, toneSet.DispatchURL, bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if toneSet.DispatchToken != "" {
+		req.Header.Set("Authorization", "Bearer "+toneSet.DispatchToken)
+	}
+	return req, nil
+}
+
+// PagingDispatcher forwards the dispatches of tone sets to Active911 or
+// IamResponding, so volunteer departments are paged through their existing
+// tools. Each delivery is recorded with its status.
+type PagingDispatcher struct {
+	controller *Controller
+	client     *http.Client
+	retryDelay time.Duration
+}
+
+func NewPagingDispatcher(controller *Controller) *PagingDispatcher {
+	return &PagingDispatcher{
+		controller: controller,
+		client:     &http.Client{Timeout: 30 * time.Second},
+		retryDelay: pagingRetryDelay,
+	}
+}
+
+// Start marks the deliveries left pending by a restart without a job to
+// resume them as failed, so they can be retried from the admin.
+func (paging *PagingDispatcher) Start() {
+	if _, err := paging.controller.Database.Sql.Exec(
+		`UPDATE "pagingDeliveries" SET "status" = $1, "lastError" = 'interrupted by a restart', "updatedAt" = $2 WHERE "status" = $3 AND NOT EXISTS (SELECT 1 FROM "jobs" WHERE "kind" = $4 AND "status" IN ($5, $6) AND "payload"::jsonb ->> 'deliveryId' = "pagingDeliveryId"::text)`,
+		pagingStatusFailed, time.Now().UnixMilli(), pagingStatusPending, jobKindPaging, JobStatusQueued, JobStatusRunning,
+	); err != nil {
+		paging.controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("paging: %v", err))
+	}
+}
+
+// Dispatch forwards a tone set match when the tone set has a paging provider.
+func (paging *PagingDispatcher) Dispatch(call *Call, toneSet *ToneSet) {
+	if paging == nil || call == nil || toneSet == nil || toneSet.DispatchProvider == "" {
+		return
+	}
+
+	var id uint64
+	now := time.Now().UnixMilli()
+	if err := paging.controller.Database.Sql.QueryRow(
+		`INSERT INTO "pagingDeliveries" ("callId", "toneSetId", "provider", "status", "attempts", "lastError", "createdAt", "updatedAt") VALUES ($1, $2, $3, $4, 0, '', $5, $5) RETURNING "pagingDeliveryId"`,
+		call.Id, toneSet.Id, toneSet.DispatchProvider, pagingStatusPending, now,
+	).Scan(&id); err != nil {
+		paging.controller.Logs.LogEvent(LogLevelError, fmt.Sprintf("paging: failed to record the dispatch of call %d: %v", call.Id, err))
+		return
+	}
+
+	toneSetCopy := *toneSet
+	paging.send(id, newPagingDispatch(paging.controller, call, &toneSetCopy), &toneSetCopy, 0)
+}
+
+// jobKindPaging is a persistent job forwarding one dispatch.
+const jobKindPaging = "paging"
+
+// pagingJobConcurrency dispatches are forwarded at the same time.
+const pagingJobConcurrency = 4
+
+// pagingJobPayload carries the tone set as it was when the tones matched.
+// Attempts counts the attempts made before the job, by earlier retries.
+type pagingJobPayload struct {
+	DeliveryId uint64          `json:"deliveryId"`
+	Dispatch   *PagingDispatch `json:"dispatch"`
+	ToneSet    *ToneSet        `json:"toneSet"`
+	Attempts   int             `json:"attempts"`
+}
+
+// send stores the delivery as a persistent job, so its retries survive a
+// restart, and falls back to delivering from memory.
+func (paging *PagingDispatcher) send(id uint64, dispatch *PagingDispatch, toneSet *ToneSet, attempts int) {
+	if jobs := paging.controller.Jobs; jobs != nil {
+		payload := pagingJobPayload{DeliveryId: id, Dispatch: dispatch, ToneSet: toneSet, Attempts: attempts}
+		_, err := jobs.Enqueue(jobKindPaging, 0, payload, nil, 0)
+		if err == nil {
+			return
+		}
+		paging.controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("paging: failed to store delivery %d, sending from memory: %v", id, err))
+	}
+
+	go paging.deliver(id, dispatch, toneSet, attempts)
+}
+
+// runPagingJob makes one delivery attempt. Failures other than connection
+// errors, 429 and 5xx responses are not retried.
+func (controller *Controller) runPagingJob(job *Job) error {
+	var payload pagingJobPayload
+	if err := json.Unmarshal(job.Payload, &payload); err != nil || payload.Dispatch == nil || payload.ToneSet == nil {
+		return jobPermanent(fmt.Errorf("invalid payload: %v", err))
+	}
+
+	last := job.Attempts >= job.MaxAttempts
+	retry, err := controller.Paging.attempt(payload.DeliveryId, payload.Dispatch, payload.ToneSet, payload.Attempts+int(job.Attempts), last)
+	if err != nil && !retry {
+		return jobPermanent(err)
+	}
+	return err
+}
+
+// deliver posts a dispatch from memory, retrying connection errors, 429 and
+// 5xx responses with exponential backoff.
+func (paging *PagingDispatcher) deliver(id uint64, dispatch *PagingDispatch, toneSet *ToneSet, attempts int) {
+	for attempt := 1; attempt <= pagingMaxAttempts; attempt++ {
+		if attempt > 1 {
+			time.Sleep(paging.retryDelay * time.Duration(1<<uint(attempt-2)))
+		}
+		if retry, err := paging.attempt(id, dispatch, toneSet, attempts+attempt, attempt == pagingMaxAttempts); err == nil || !retry {
+			return
+		}
+	}
+}
+
+// attempt posts a dispatch once and records the outcome. attempts counts
+// this attempt; after the last one a failure is final.
+func (paging *PagingDispatcher) attempt(id uint64, dispatch *PagingDispatch, toneSet *ToneSet, attempts int, last bool) (bool, error) {
+	retry, err := paging.post(dispatch, toneSet)
+
+	status, lastError := pagingStatusDelivered, ""
+	if err != nil {
+		status, lastError = pagingStatusPending, err.Error()
+		if !retry || last {
+			status = pagingStatusFailed
+		}
+	}
+	if _, dbErr := paging.controller.Database.Sql.Exec(
+		`UPDATE "pagingDeliveries" SET "status" = $1, "attempts" = $2, "lastError" = $3, "updatedAt" = $4 WHERE "pagingDeliveryId" = $5`,
+		status, attempts, lastError, time.Now().UnixMilli(), id,
+	); dbErr != nil {
+		paging.controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("paging: failed to update delivery %d: %v", id, dbErr))
+	}
+
+	logPrefix := fmt.Sprintf("paging[%s]: call=%d toneSet=%q", toneSet.DispatchProvider, dispatch.CallId, toneSet.Label)
+	switch status {
+	case pagingStatusDelivered:
+		paging.controller.Logs.LogEvent(LogLevelInfo, fmt.Sprintf("%s delivered", logPrefix))
+	case pagingStatusFailed:
+		paging.controller.Logs.LogEvent(LogLevelError, fmt.Sprintf("%s failed after %d attempt(s): %v", logPrefix, attempts, err))
+	}
+
+	return retry, err
+}
+
+// post makes one delivery attempt and reports whether a failure is worth
+// retrying.
+func (paging *PagingDispatcher) post(dispatch *PagingDispatch, toneSet *ToneSet) (bool, error) {
+	req, err := pagingRequest(toneSet, dispatch)
+	if err != nil {
+		return false, err
+	}
+	resp, err := paging.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+		return retry, fmt.Errorf("%s returned status %s", toneSet.DispatchURL, resp.Status)
+	}
+	return false, nil
+}
+
+// Retry sends a failed delivery again, with the current configuration of
+// its tone set.
+func (paging *PagingDispatcher) Retry(id uint64) error {
+	db := paging.controller.Database.Sql
+
+	var (
+		callId            uint64
+		toneSetId, status string
+		attempts          int
+	)
+	if err := db.QueryRow(
+		`SELECT "callId", "toneSetId", "status", "attempts" FROM "pagingDeliveries" WHERE "pagingDeliveryId" = $1`, id,
+	).Scan(&callId, &toneSetId, &status, &attempts); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("delivery %d not found", id)
+		}
+		return err
+	}
+	if status != pagingStatusFailed {
+		return fmt.Errorf("delivery %d is %s", id, status)
+	}
+
+	call, err := paging.controller.Calls.GetCall(callId)
+	if err != nil || call == nil || call.Talkgroup == nil {
+		return fmt.Errorf("call %d not found", callId)
+	}
+	var toneSet *ToneSet
+	for i := range call.Talkgroup.ToneSets {
+		if call.Talkgroup.ToneSets[i].Id == toneSetId {
+			toneSetCopy := call.Talkgroup.ToneSets[i]
+			toneSet = &toneSetCopy
+			break
+		}
+	}
+	if toneSet == nil || toneSet.DispatchProvider == "" {
+		return fmt.Errorf("tone set %q no longer forwards dispatches", toneSetId)
+	}
+
+	if _, err := db.Exec(
+		`UPDATE "pagingDeliveries" SET "status" = $1, "updatedAt" = $2 WHERE "pagingDeliveryId" = $3`,
+		pagingStatusPending, time.Now().UnixMilli(), id,
+	); err != nil {
+		return err
+	}
+	paging.send(id, newPagingDispatch(paging.controller, call, toneSet), toneSet, attempts)
+	return nil
+}
+
+// List returns the most recent deliveries, optionally with a status.
+func (paging *PagingDispatcher) List(status string, limit int) ([]*PagingDelivery, error) {
+	query := `SELECT "pagingDeliveryId", "callId", "toneSetId", "provider", "status", "attempts", "lastError", "createdAt", "updatedAt" FROM "pagingDeliveries" WHERE $1 = '' OR "status" = $1 ORDER BY "pagingDeliveryId" DESC LIMIT $2`
+	rows, err := paging.controller.Database.Sql.Query(query, status, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := []*PagingDelivery{}
+	for rows.Next() {
+		delivery := &PagingDelivery{}
+		if err := rows.Scan(&delivery.Id, &delivery.CallId, &delivery.ToneSetId, &delivery.Provider, &delivery.Status, &delivery.Attempts, &delivery.LastError, &delivery.CreatedAt, &delivery.UpdatedAt); err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	return deliveries, rows.Err()
+}
+
+// PagingDeliveriesHandler serves GET /api/admin/paging-deliveries?status=&limit=,
+// the recent dispatches forwarded to paging services, and
+// POST /api/admin/paging-deliveries/{id}/retry, which sends a failed one again.
+func (admin *Admin) PagingDeliveriesHandler(w http.ResponseWriter, r *http.Request) {
+	t := admin.GetAuthorization(r)
+	if !admin.ValidateToken(t) {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	writeError := func(status int, message string) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(map[string]string{"error": message})
+	}
+
+	paging := admin.Controller.Paging
+	path := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/admin/paging-deliveries"), "/")
+
+	switch {
+	case path == "" && r.Method == http.MethodGet:
+		limit := 100
+		if s := r.URL.Query().Get("limit"); s != "" {
+			v, err := strconv.Atoi(s)
+			if err != nil || v <= 0 {
+				writeError(http.StatusBadRequest, "limit must be a positive number")
+				return
+			}
+			if v < 1000 {
+				limit = v
+			} else {
+				limit = 1000
+			}
+		}
+		deliveries, err := paging.List(r.URL.Query().Get("status"), limit)
+		if err != nil {
+			writeError(http.StatusInternalServerError, err.Error())
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{"deliveries": deliveries})
+
+	case strings.HasSuffix(path, "/retry") && r.Method == http.MethodPost:
+		id, err := strconv.ParseUint(strings.TrimSuffix(path, "/retry"), 10, 64)
+		if err != nil {
+			writeError(http.StatusBadRequest, "invalid delivery id")
+			return
+		}
+		if err := paging.Retry(id); err != nil {
+			writeError(http.StatusConflict, err.Error())
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		json.NewEncoder(w).Encode(map[string]any{"id": id, "status": pagingStatusPending})
+
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
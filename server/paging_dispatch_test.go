@@ -0,0 +1,72 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestPagingRequest(t *testing.T) {
+	dispatch := &PagingDispatch{CallId: 7, TalkgroupLabel: "FD DISPATCH", Timestamp: 1700000000000, ToneSetId: "station-5", ToneSetLabel: "Station 5", Transcript: "structure fire 123 oak st", AudioUrl: "https://radio.example.com/api/calls/7/audio"}
+
+	toneSet := &ToneSet{Label: "Station 5", DispatchProvider: "active911", DispatchURL: "https://active911.example.com/alerts", DispatchToken: "secret"}
+	req, err := pagingRequest(toneSet, dispatch)
+	if err != nil {
+		t.Fatalf("pagingRequest: %v", err)
+	}
+	if req.Header.Get("Authorization") != "Bearer secret" || req.URL.String() != toneSet.DispatchURL {
+		t.Fatalf("request = %s %v", req.URL, req.Header)
+	}
+	var body map[string]any
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if body["description"] != "Station 5" || body["audio_url"] != dispatch.AudioUrl || body["sent"] != "2023-11-14T22:13:20Z" {
+		t.Fatalf("active911 body = %v", body)
+	}
+
+	toneSet = &ToneSet{Label: "Station 5", DispatchProvider: "iamresponding", DispatchURL: "https://iar.example.com/dispatch", DispatchAgency: "Oak Fire"}
+	req, err = pagingRequest(toneSet, dispatch)
+	if err != nil {
+		t.Fatalf("pagingRequest: %v", err)
+	}
+	body = nil
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if body["agency"] != "Oak Fire" || body["subject"] != "Station 5" || req.Header.Get("Authorization") != "" {
+		t.Fatalf("iamresponding body = %v", body)
+	}
+
+	if _, err := pagingRequest(&ToneSet{DispatchProvider: "pager", DispatchURL: "https://example.com"}, dispatch); err == nil {
+		t.Fatalf("expected an error for an unknown provider")
+	}
+	if _, err := pagingRequest(&ToneSet{DispatchProvider: "active911"}, dispatch); err == nil {
+		t.Fatalf("expected an error without a URL")
+	}
+}
+
+func TestPagingPostRetries(t *testing.T) {
+	status := http.StatusServiceUnavailable
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	paging := NewPagingDispatcher(nil)
+	toneSet := &ToneSet{DispatchProvider: "active911", DispatchURL: server.URL}
+	dispatch := &PagingDispatch{CallId: 1}
+
+	if retry, err := paging.post(dispatch, toneSet); err == nil || !retry {
+		t.Fatalf("503: retry = %v, err = %v", retry, err)
+	}
+	status = http.StatusBadRequest
+	if retry, err := paging.post(dispatch, toneSet); err == nil || retry {
+		t.Fatalf("400: retry = %v, err = %v", retry, err)
+	}
+	status = http.StatusOK
+	if _, err := paging.post(dispatch, toneSet); err != nil {
+		t.Fatalf("200: %v", err)
+	}
+}
@@ -0,0 +1,429 @@
+// Copyright (C) 2026 Thinline Dynamic Solutions
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>
+
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"time"
+)
+
+// A minimal Parquet writer for flat tables: PLAIN encoded data pages,
+// compressed with gzip, one page per column and row group. Values are
+// buffered one row group at a time, so memory stays bounded however many
+// rows are written.
+
+type ParquetType int
+
+const (
+	ParquetInt64 ParquetType = iota
+	ParquetDouble
+	ParquetBoolean
+	ParquetString
+	ParquetTimestamp // time.Time, stored as milliseconds
+)
+
+type ParquetColumn struct {
+	Name     string
+	Type     ParquetType
+	Optional bool
+}
+
+const parquetDefaultRowGroupSize = 50000
+
+// Parquet format constants
+const (
+	parquetPhysicalBoolean   = 0
+	parquetPhysicalInt64     = 2
+	parquetPhysicalDouble    = 5
+	parquetPhysicalByteArray = 6
+
+	parquetConvertedUTF8            = 0
+	parquetConvertedTimestampMillis = 9
+
+	parquetEncodingPlain = 0
+	parquetEncodingRLE   = 3
+	parquetCodecGzip     = 2
+)
+
+type parquetColumnBuffer struct {
+	values  bytes.Buffer
+	bools   []bool
+	defined []bool
+	count   int
+}
+
+type parquetChunk struct {
+	offset           int64
+	values           int64
+	uncompressedSize int64
+	compressedSize   int64
+}
+
+type ParquetWriter struct {
+	RowGroupSize int
+
+	w         io.Writer
+	offset    int64
+	columns   []ParquetColumn
+	buffers   []*parquetColumnBuffer
+	rows      int
+	totalRows int64
+	rowGroups [][]parquetChunk
+	groupRows []int64
+	err       error
+}
+
+func NewParquetWriter(w io.Writer, columns []ParquetColumn) *ParquetWriter {
+	pw := &ParquetWriter{RowGroupSize: parquetDefaultRowGroupSize, w: w, columns: columns}
+	pw.reset()
+	pw.write([]byte("PAR1"))
+	return pw
+}
+
+func (pw *ParquetWriter) reset() {
+	pw.buffers = make([]*parquetColumnBuffer, len(pw.columns))
+	for i := range pw.buffers {
+		pw.buffers[i] = &parquetColumnBuffer{}
+	}
+	pw.rows = 0
+}
+
+func (pw *ParquetWriter) write(b []byte) {
+	if pw.err != nil {
+		return
+	}
+	n, err := pw.w.Write(b)
+	pw.offset += int64(n)
+	pw.err = err
+}
+
+// Write adds a row. Values are int64, float64, bool, string or time.Time
+// following the column types, or nil for a null in an optional column.
+func (pw *ParquetWriter) Write(row []any) error {
+	if pw.err != nil {
+		return pw.err
+	}
+	if len(row) != len(pw.columns) {
+		return fmt.Errorf("parquet: %d values for %d columns", len(row), len(pw.columns))
+	}
+
+	for i, column := range pw.columns {
+		buffer := pw.buffers[i]
+		value := row[i]
+		if value == nil {
+			if !column.Optional {
+				return fmt.Errorf("parquet: %s can't be null", column.Name)
+			}
+			buffer.defined = append(buffer.defined, false)
+			buffer.count++
+			continue
+		}
+
+		ok := true
+		switch column.Type {
+		case ParquetInt64:
+			var v int64
+			if v, ok = value.(int64); ok {
+				binary.Write(&buffer.values, binary.LittleEndian, v)
+			}
+		case ParquetTimestamp:
+			var v time.Time
+			if v, ok = value.(time.Time); ok {
+				binary.Write(&buffer.values, binary.LittleEndian, v.UnixMilli())
+			}
+		case ParquetDouble:
+			var v float64
+			if v, ok = value.(float64); ok {
+				binary.Write(&buffer.values, binary.LittleEndian, math.Float64bits(v))
+			}
+		case ParquetBoolean:
+			var v bool
+			if v, ok = value.(bool); ok {
+				buffer.bools = append(buffer.bools, v)
+			}
+		case ParquetString:
+			var v string
+			if v, ok = value.(string); ok {
+				binary.Write(&buffer.values, binary.LittleEndian, uint32(len(v)))
+				buffer.values.WriteString(v)
+			}
+		}
+		if !ok {
+			return fmt.Errorf("parquet: invalid value %v for %s", value, column.Name)
+		}
+		buffer.defined = append(buffer.defined, true)
+		buffer.count++
+	}
+
+	pw.rows++
+	if pw.rows >= pw.RowGroupSize {
+		pw.flush()
+	}
+	return pw.err
+}
+
+// flush writes the buffered rows as a row group.
+func (pw *ParquetWriter) flush() {
+	if pw.rows == 0 || pw.err != nil {
+		return
+	}
+
+	chunks := make([]parquetChunk, len(pw.columns))
+	for i, column := range pw.columns {
+		buffer := pw.buffers[i]
+
+		var page bytes.Buffer
+		if column.Optional {
+			levels := parquetBitPacked(buffer.defined)
+			binary.Write(&page, binary.LittleEndian, uint32(len(levels)))
+			page.Write(levels)
+		}
+		if column.Type == ParquetBoolean {
+			page.Write(parquetPackBits(buffer.bools))
+		} else {
+			page.Write(buffer.values.Bytes())
+		}
+
+		var compressed bytes.Buffer
+		gz := gzip.NewWriter(&compressed)
+		gz.Write(page.Bytes())
+		gz.Close()
+
+		header := &thriftCompact{}
+		header.i32(1, 0) // DATA_PAGE
+		header.i32(2, int32(page.Len()))
+		header.i32(3, int32(compressed.Len()))
+		header.structBegin(5)
+		header.i32(1, int32(buffer.count))
+		header.i32(2, parquetEncodingPlain)
+		header.i32(3, parquetEncodingRLE)
+		header.i32(4, parquetEncodingRLE)
+		header.structEnd()
+		header.stop()
+
+		chunks[i] = parquetChunk{
+			offset:           pw.offset,
+			values:           int64(buffer.count),
+			uncompressedSize: int64(header.buf.Len() + page.Len()),
+			compressedSize:   int64(header.buf.Len() + compressed.Len()),
+		}
+		pw.write(header.buf.Bytes())
+		pw.write(compressed.Bytes())
+	}
+
+	pw.rowGroups = append(pw.rowGroups, chunks)
+	pw.groupRows = append(pw.groupRows, int64(pw.rows))
+	pw.totalRows += int64(pw.rows)
+	pw.reset()
+}
+
+// Close writes the last row group and the footer. It doesn't close the
+// underlying writer.
+func (pw *ParquetWriter) Close() error {
+	pw.flush()
+	if pw.err != nil {
+		return pw.err
+	}
+
+	meta := &thriftCompact{}
+	meta.i32(1, 1)
+
+	meta.listBegin(2, thriftStruct, len(pw.columns)+1)
+	meta.elementBegin()
+	meta.str(4, "schema")
+	meta.i32(5, int32(len(pw.columns)))
+	meta.elementEnd()
+	for _, column := range pw.columns {
+		meta.elementBegin()
+		meta.i32(1, column.physicalType())
+		repetition := int32(0)
+		if column.Optional {
+			repetition = 1
+		}
+		meta.i32(3, repetition)
+		meta.str(4, column.Name)
+		switch column.Type {
+		case ParquetString:
+			meta.i32(6, parquetConvertedUTF8)
+		case ParquetTimestamp:
+			meta.i32(6, parquetConvertedTimestampMillis)
+		}
+		meta.elementEnd()
+	}
+
+	meta.i64(3, pw.totalRows)
+
+	meta.listBegin(4, thriftStruct, len(pw.rowGroups))
+	for g, chunks := range pw.rowGroups {
+		meta.elementBegin()
+		meta.listBegin(1, thriftStruct, len(chunks))
+		var total int64
+		for i, chunk := range chunks {
+			column := pw.columns[i]
+			meta.elementBegin()
+			meta.i64(2, chunk.offset)
+			meta.structBegin(3)
+			meta.i32(1, column.physicalType())
+			meta.listBegin(2, thriftI32, 2)
+			meta.varint(parquetEncodingPlain)
+			meta.varint(parquetEncodingRLE)
+			meta.listBegin(3, thriftBinary, 1)
+			meta.binary(column.Name)
+			meta.i32(4, parquetCodecGzip)
+			meta.i64(5, chunk.values)
+			meta.i64(6, chunk.uncompressedSize)
+			meta.i64(7, chunk.compressedSize)
+			meta.i64(9, chunk.offset)
+			meta.structEnd()
+			meta.elementEnd()
+			total += chunk.uncompressedSize
+		}
+		meta.i64(2, total)
+		meta.i64(3, pw.groupRows[g])
+		meta.elementEnd()
+	}
+
+	meta.str(6, "ThinLine Radio")
+	meta.stop()
+
+	pw.write(meta.buf.Bytes())
+	footer := make([]byte, 4)
+	binary.LittleEndian.PutUint32(footer, uint32(meta.buf.Len()))
+	pw.write(footer)
+	pw.write([]byte("PAR1"))
+	return pw.err
+}
+
+func (column ParquetColumn) physicalType() int32 {
+	switch column.Type {
+	case ParquetDouble:
+		return parquetPhysicalDouble
+	case ParquetBoolean:
+		return parquetPhysicalBoolean
+	case ParquetString:
+		return parquetPhysicalByteArray
+	}
+	return parquetPhysicalInt64
+}
+
+// parquetPackBits packs booleans LSB first, the PLAIN encoding of booleans.
+func parquetPackBits(values []bool) []byte {
+	b := make([]byte, (len(values)+7)/8)
+	for i, v := range values {
+		if v {
+			b[i/8] |= 1 << (i % 8)
+		}
+	}
+	return b
+}
+
+// parquetBitPacked encodes definition levels of bit width 1 as a single
+// bit-packed run of the RLE/bit-packing hybrid encoding.
+func parquetBitPacked(levels []bool) []byte {
+	groups := (len(levels) + 7) / 8
+	header := binary.AppendUvarint(nil, uint64(groups)<<1|1)
+	packed := parquetPackBits(levels)
+	return append(header, append(packed, make([]byte, groups-len(packed))...)...)
+}
+
+// Thrift compact protocol, as used by the Parquet metadata
+
+const (
+	thriftI32    = 5
+	thriftI64    = 6
+	thriftBinary = 8
+	thriftStruct = 12
+)
+
+type thriftCompact struct {
+	buf   bytes.Buffer
+	last  int16
+	stack []int16
+}
+
+func (t *thriftCompact) varint(v int64) {
+	t.buf.Write(binary.AppendUvarint(nil, uint64((v<<1)^(v>>63))))
+}
+
+func (t *thriftCompact) field(id int16, kind byte) {
+	if delta := id - t.last; delta > 0 && delta <= 15 {
+		t.buf.WriteByte(byte(delta)<<4 | kind)
+	} else {
+		t.buf.WriteByte(kind)
+		t.varint(int64(id))
+	}
+	t.last = id
+}
+
+func (t *thriftCompact) i32(id int16, v int32) {
+	t.field(id, thriftI32)
+	t.varint(int64(v))
+}
+
+func (t *thriftCompact) i64(id int16, v int64) {
+	t.field(id, thriftI64)
+	t.varint(v)
+}
+
+func (t *thriftCompact) binary(s string) {
+	t.buf.Write(binary.AppendUvarint(nil, uint64(len(s))))
+	t.buf.WriteString(s)
+}
+
+func (t *thriftCompact) str(id int16, s string) {
+	t.field(id, thriftBinary)
+	t.binary(s)
+}
+
+func (t *thriftCompact) listBegin(id int16, kind byte, size int) {
+	t.field(id, 9)
+	if size < 15 {
+		t.buf.WriteByte(byte(size)<<4 | kind)
+	} else {
+		t.buf.WriteByte(0xF0 | kind)
+		t.buf.Write(binary.AppendUvarint(nil, uint64(size)))
+	}
+}
+
+func (t *thriftCompact) structBegin(id int16) {
+	t.field(id, thriftStruct)
+	t.elementBegin()
+}
+
+func (t *thriftCompact) structEnd() {
+	t.elementEnd()
+}
+
+// elementBegin starts a struct inside a list, with its own field ids.
+func (t *thriftCompact) elementBegin() {
+	t.stack = append(t.stack, t.last)
+	t.last = 0
+}
+
+func (t *thriftCompact) elementEnd() {
+	t.stop()
+	t.last = t.stack[len(t.stack)-1]
+	t.stack = t.stack[:len(t.stack)-1]
+}
+
+func (t *thriftCompact) stop() {
+	t.buf.WriteByte(0)
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"io"
+	"math"
+	"testing"
+	"time"
+)
+
+// thriftTestReader decodes Thrift compact structs into maps of field ids.
+type thriftTestReader struct {
+	b   []byte
+	pos int
+}
+
+func (r *thriftTestReader) uvarint() uint64 {
+	v, n := binary.Uvarint(r.b[r.pos:])
+	r.pos += n
+	return v
+}
+
+func (r *thriftTestReader) zigzag() int64 {
+	v := r.uvarint()
+	return int64(v>>1) ^ -int64(v&1)
+}
+
+func (r *thriftTestReader) value(kind byte) any {
+	switch kind {
+	case 1:
+		return true
+	case 2:
+		return false
+	case thriftI32, thriftI64:
+		return r.zigzag()
+	case thriftBinary:
+		n := int(r.uvarint())
+		s := string(r.b[r.pos : r.pos+n])
+		r.pos += n
+		return s
+	case 9:
+		header := r.b[r.pos]
+		r.pos++
+		size := int(header >> 4)
+		if size == 15 {
+			size = int(r.uvarint())
+		}
+		list := make([]any, size)
+		for i := range list {
+			list[i] = r.value(header & 0x0F)
+		}
+		return list
+	case thriftStruct:
+		return r.structure()
+	}
+	panic("unsupported thrift type")
+}
+
+func (r *thriftTestReader) structure() map[int16]any {
+	fields := map[int16]any{}
+	var last int16
+	for {
+		header := r.b[r.pos]
+		r.pos++
+		if header == 0 {
+			return fields
+		}
+		if delta := int16(header >> 4); delta > 0 {
+			last += delta
+		} else {
+			last = int16(r.zigzag())
+		}
+		fields[last] = r.value(header & 0x0F)
+	}
+}
+
+func TestParquetWriter(t *testing.T) {
+	var b bytes.Buffer
+	columns := []ParquetColumn{
+		{Name: "id", Type: ParquetInt64},
+		{Name: "label", Type: ParquetString, Optional: true},
+		{Name: "ok", Type: ParquetBoolean},
+		{Name: "at", Type: ParquetTimestamp},
+		{Name: "seconds", Type: ParquetDouble, Optional: true},
+	}
+	pw := NewParquetWriter(&b, columns)
+	pw.RowGroupSize = 2
+	at := time.UnixMilli(1700000000123)
+	rows := [][]any{
+		{int64(1), "a", true, at, 1.5},
+		{int64(2), nil, false, at, nil},
+		{int64(3), "c", true, at, 3.25},
+	}
+	for _, row := range rows {
+		if err := pw.Write(row); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+	if err := pw.Write([]any{nil, "x", true, at, 1.0}); err == nil {
+		t.Fatalf("expected an error for a null in a required column")
+	}
+	if err := pw.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	file := b.Bytes()
+	if string(file[:4]) != "PAR1" || string(file[len(file)-4:]) != "PAR1" {
+		t.Fatalf("missing magic bytes")
+	}
+	footerLength := int(binary.LittleEndian.Uint32(file[len(file)-8:]))
+	meta := (&thriftTestReader{b: file[len(file)-8-footerLength : len(file)-8]}).structure()
+
+	if meta[3].(int64) != 3 {
+		t.Fatalf("num_rows %v", meta[3])
+	}
+	schema := meta[2].([]any)
+	if len(schema) != 6 || schema[0].(map[int16]any)[5].(int64) != 5 || schema[2].(map[int16]any)[4] != "label" {
+		t.Fatalf("schema %v", schema)
+	}
+	groups := meta[4].([]any)
+	if len(groups) != 2 || groups[0].(map[int16]any)[3].(int64) != 2 || groups[1].(map[int16]any)[3].(int64) != 1 {
+		t.Fatalf("row groups %v", groups)
+	}
+
+	// Read back the label and seconds columns of the first row group
+	chunks := groups[0].(map[int16]any)[1].([]any)
+	page := func(column int) []byte {
+		offset := int(chunks[column].(map[int16]any)[3].(map[int16]any)[9].(int64))
+		r := &thriftTestReader{b: file, pos: offset}
+		header := r.structure()
+		gz, err := gzip.NewReader(bytes.NewReader(file[r.pos : r.pos+int(header[3].(int64))]))
+		if err != nil {
+			t.Fatalf("gzip: %v", err)
+		}
+		data, _ := io.ReadAll(gz)
+		if int64(len(data)) != header[2].(int64) || header[5].(map[int16]any)[1].(int64) != 2 {
+			t.Fatalf("page header %v", header)
+		}
+		return data
+	}
+
+	labels := page(1)
+	levelsLength := binary.LittleEndian.Uint32(labels)
+	// one bit-packed group: header 3, levels 0b01
+	if levelsLength != 2 || labels[4] != 3 || labels[5] != 1 {
+		t.Fatalf("definition levels % x", labels[:6])
+	}
+	if n := binary.LittleEndian.Uint32(labels[6:]); n != 1 || string(labels[10:11]) != "a" || len(labels) != 11 {
+		t.Fatalf("labels % x", labels)
+	}
+
+	ok := page(2)
+	if len(ok) != 1 || ok[0] != 1 {
+		t.Fatalf("booleans % x", ok)
+	}
+
+	seconds := page(4)
+	if v := math.Float64frombits(binary.LittleEndian.Uint64(seconds[6:])); v != 1.5 || len(seconds) != 14 {
+		t.Fatalf("seconds % x", seconds)
+	}
+}
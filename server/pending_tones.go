@@ -0,0 +1,331 @@
+// Copyright (C) 2026 Thinline Dynamic Solutions
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// pendingTonesSaveInterval bounds the pending tones a crash can lose
+	pendingTonesSaveInterval = 2 * time.Second
+
+	toneStitchDefaultGapSeconds = 10
+	toneStitchMaxCalls          = 10
+	toneStitchMaxGapSeconds     = 120
+	toneMaxWaitMinSeconds       = 10
+	toneMaxWaitMaxSeconds       = 600
+)
+
+// ToneStitchingConfig controls how matched tones wait for the voice of their
+// page. MaxWaitSeconds is how long tones wait for voice before alerting alone,
+// 60 by default. With MaxCalls above 1, the voice calls following the first
+// one on the talkgroup, each starting within GapSeconds of the end of the
+// last, join the page up to MaxCalls calls without alerting again.
+type ToneStitchingConfig struct {
+	MaxWaitSeconds uint `json:"maxWaitSeconds"`
+	MaxCalls       uint `json:"maxCalls"`
+	GapSeconds     uint `json:"gapSeconds"`
+}
+
+func (config ToneStitchingConfig) maxWait() time.Duration {
+	switch {
+	case config.MaxWaitSeconds == 0:
+		return orphanedToneAlertSeconds * time.Second
+	case config.MaxWaitSeconds < toneMaxWaitMinSeconds:
+		return toneMaxWaitMinSeconds * time.Second
+	case config.MaxWaitSeconds > toneMaxWaitMaxSeconds:
+		return toneMaxWaitMaxSeconds * time.Second
+	}
+	return time.Duration(config.MaxWaitSeconds) * time.Second
+}
+
+// pendingTimeout is how long, in milliseconds, pending tones can merge with
+// new tones or attach to voice. It is never shorter than the wait for voice.
+func (config ToneStitchingConfig) pendingTimeout() int64 {
+	timeout := int64(pendingToneTimeoutMinutes) * 60 * 1000
+	if wait := config.maxWait().Milliseconds(); wait > timeout {
+		return wait
+	}
+	return timeout
+}
+
+func (config ToneStitchingConfig) maxCalls() uint {
+	switch {
+	case config.MaxCalls == 0:
+		return 1
+	case config.MaxCalls > toneStitchMaxCalls:
+		return toneStitchMaxCalls
+	}
+	return config.MaxCalls
+}
+
+func (config ToneStitchingConfig) gap() int64 {
+	switch {
+	case config.GapSeconds == 0:
+		return toneStitchDefaultGapSeconds * 1000
+	case config.GapSeconds > toneStitchMaxGapSeconds:
+		return toneStitchMaxGapSeconds * 1000
+	}
+	return int64(config.GapSeconds) * 1000
+}
+
+// pendingToneExpiry is when a pending tones entry stops being useful, in
+// unix milliseconds.
+func (config ToneStitchingConfig) pendingToneExpiry(key string, pending *PendingToneSequence) int64 {
+	switch {
+	case strings.HasSuffix(key, ":stitch"):
+		return pending.Timestamp + config.gap()
+	case pending.WindowSeconds > 0:
+		return pending.Timestamp + int64(pending.WindowSeconds)*1000
+	}
+	return pending.Timestamp + config.pendingTimeout()
+}
+
+// startToneStitch opens the page of call, the first voice call of its tones,
+// to the voice calls that follow it on the talkgroup.
+func (controller *Controller) startToneStitch(key string, call *Call) {
+	config := controller.Options.ToneStitchingConfig
+	if config.maxCalls() < 2 || call == nil || call.Id == 0 || call.ToneSequence == nil {
+		return
+	}
+
+	controller.pendingTonesMutex.Lock()
+	controller.pendingTones[key+":stitch"] = &PendingToneSequence{
+		ToneSequence:  call.ToneSequence,
+		CallId:        call.Id,
+		Timestamp:     controller.toneCallEnd(call),
+		SystemId:      call.System.Id,
+		TalkgroupId:   call.Talkgroup.Id,
+		StitchedCalls: 1,
+	}
+	controller.pendingTonesMutex.Unlock()
+}
+
+// stitchToneCall adds a voice call without tones to the open page of its
+// talkgroup, when it starts soon enough after the last call of the page. The
+// call gets the page's tones, marked with the first voice call, and no alert.
+func (controller *Controller) stitchToneCall(call *Call) bool {
+	if call == nil || call.System == nil || call.Talkgroup == nil || call.HasTones {
+		return false
+	}
+
+	config := controller.Options.ToneStitchingConfig
+	key := fmt.Sprintf("%d:%d:stitch", call.System.Id, call.Talkgroup.Id)
+	start := call.Timestamp.UnixMilli()
+
+	controller.pendingTonesMutex.Lock()
+	stitch, exists := controller.pendingTones[key]
+	if !exists || stitch == nil || stitch.ToneSequence == nil || start < stitch.Timestamp-config.gap() {
+		controller.pendingTonesMutex.Unlock()
+		return false
+	}
+	if start > stitch.Timestamp+config.gap() || stitch.StitchedCalls >= config.maxCalls() {
+		delete(controller.pendingTones, key)
+		controller.pendingTonesMutex.Unlock()
+		return false
+	}
+	stitch.StitchedCalls++
+	if end := controller.toneCallEnd(call); end > stitch.Timestamp {
+		stitch.Timestamp = end
+	}
+	sequence := *stitch.ToneSequence
+	sequence.StitchedTo = stitch.CallId
+	part := stitch.StitchedCalls
+	controller.pendingTonesMutex.Unlock()
+
+	call.ToneSequence = &sequence
+	call.HasTones = len(sequence.Tones) > 0
+	controller.updateCallToneSequence(call.Id, &sequence)
+
+	controller.Logs.LogEvent(LogLevelInfo, fmt.Sprintf("stitched voice call %d to the page of call %d on talkgroup %d (part %d)", call.Id, sequence.StitchedTo, call.Talkgroup.TalkgroupRef, part))
+	return true
+}
+
+// toneCallEnd is when call ends, in unix milliseconds.
+func (controller *Controller) toneCallEnd(call *Call) int64 {
+	end := call.Timestamp.UnixMilli()
+	if duration, err := controller.getCallDuration(call); err == nil && duration > 0 {
+		end += int64(duration * 1000)
+	}
+	return end
+}
+
+// PendingTonesStore keeps the pending tones in the database so a restart
+// doesn't lose a page waiting for its voice.
+type PendingTonesStore struct {
+	controller *Controller
+	mutex      sync.Mutex
+	saved      string
+	stop       chan struct{}
+}
+
+func NewPendingTonesStore(controller *Controller) *PendingTonesStore {
+	return &PendingTonesStore{controller: controller}
+}
+
+// Save writes the pending tones when they changed since the last save.
+func (store *PendingTonesStore) Save() error {
+	controller := store.controller
+	config := controller.Options.ToneStitchingConfig
+
+	type row struct {
+		key       string
+		pending   string
+		expiresAt int64
+	}
+	var rows []row
+	now := time.Now().UnixMilli()
+
+	controller.pendingTonesMutex.Lock()
+	for key, pending := range controller.pendingTones {
+		if pending == nil {
+			continue
+		}
+		expiresAt := config.pendingToneExpiry(key, pending)
+		if expiresAt <= now {
+			continue
+		}
+		b, err := json.Marshal(pending)
+		if err != nil {
+			controller.pendingTonesMutex.Unlock()
+			return err
+		}
+		rows = append(rows, row{key: key, pending: string(b), expiresAt: expiresAt})
+	}
+	controller.pendingTonesMutex.Unlock()
+
+	sort.Slice(rows, func(i, j int) bool { return rows[i].key < rows[j].key })
+	var fingerprint strings.Builder
+	for _, r := range rows {
+		fingerprint.WriteString(r.key)
+		fingerprint.WriteString(r.pending)
+	}
+
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	if fingerprint.String() == store.saved {
+		return nil
+	}
+
+	tx, err := controller.Database.Sql.Begin()
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM "pendingTones"`); err != nil {
+		tx.Rollback()
+		return err
+	}
+	for _, r := range rows {
+		if _, err := tx.Exec(`INSERT INTO "pendingTones" ("pendingKey", "pending", "expiresAt") VALUES ($1, $2, $3)`, r.key, r.pending, r.expiresAt); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	store.saved = fingerprint.String()
+	return nil
+}
+
+// Restore loads the pending tones saved by the previous run that have not
+// expired, unlocked, and restarts their wait for voice.
+func (store *PendingTonesStore) Restore() (int, error) {
+	controller := store.controller
+	now := time.Now().UnixMilli()
+
+	rows, err := controller.Database.Sql.Query(`SELECT "pendingKey", "pending" FROM "pendingTones" WHERE "expiresAt" > $1`, now)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	restored := map[string]*PendingToneSequence{}
+	for rows.Next() {
+		var key, data string
+		if err := rows.Scan(&key, &data); err != nil {
+			return 0, err
+		}
+		pending := &PendingToneSequence{}
+		if err := json.Unmarshal([]byte(data), pending); err != nil {
+			continue
+		}
+		restored[key] = pending
+	}
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	controller.pendingTonesMutex.Lock()
+	for key, pending := range restored {
+		controller.pendingTones[key] = pending
+	}
+	controller.pendingTonesMutex.Unlock()
+
+	// Tones of a talkgroup's own stack alert alone once their wait for voice is over
+	maxWait := controller.Options.ToneStitchingConfig.maxWait()
+	for key, pending := range restored {
+		if strings.Count(key, ":") != 1 || pending.CrossTalkgroupSourceKey != "" {
+			continue
+		}
+		delay := maxWait - time.Duration(now-pending.Timestamp)*time.Millisecond
+		go controller.checkOrphanedTonesAfter(key, pending.CallId, pending.Timestamp, delay)
+	}
+
+	return len(restored), nil
+}
+
+// Start saves the pending tones every few seconds.
+func (store *PendingTonesStore) Start() {
+	store.stop = make(chan struct{})
+
+	go func() {
+		ticker := time.NewTicker(pendingTonesSaveInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				if err := store.Save(); err != nil {
+					store.controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("pending tones: %v", err))
+				}
+			case <-store.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop halts the periodic saves and saves the pending tones a last time.
+func (store *PendingTonesStore) Stop() {
+	if store.stop == nil {
+		return
+	}
+	close(store.stop)
+	store.stop = nil
+
+	if err := store.Save(); err != nil {
+		store.controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("pending tones: %v", err))
+	}
+}
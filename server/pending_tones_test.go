@@ -0,0 +1,71 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestToneStitchingConfigDefaults(t *testing.T) {
+	config := ToneStitchingConfig{}
+	if config.maxWait() != 60*time.Second || config.maxCalls() != 1 || config.gap() != 10000 {
+		t.Fatalf("defaults = %s %d %d", config.maxWait(), config.maxCalls(), config.gap())
+	}
+	if config.pendingTimeout() != 2*60*1000 {
+		t.Fatalf("pendingTimeout = %d", config.pendingTimeout())
+	}
+
+	config = ToneStitchingConfig{MaxWaitSeconds: 300, MaxCalls: 50, GapSeconds: 500}
+	if config.maxWait() != 300*time.Second || config.maxCalls() != toneStitchMaxCalls || config.gap() != toneStitchMaxGapSeconds*1000 {
+		t.Fatalf("limits = %s %d %d", config.maxWait(), config.maxCalls(), config.gap())
+	}
+	// Tones stay pending for as long as they wait for voice
+	if config.pendingTimeout() != 300*1000 {
+		t.Fatalf("pendingTimeout = %d", config.pendingTimeout())
+	}
+}
+
+func TestPendingToneExpiry(t *testing.T) {
+	config := ToneStitchingConfig{GapSeconds: 15}
+	pending := &PendingToneSequence{Timestamp: 1000}
+
+	if got := config.pendingToneExpiry("1:2", pending); got != 1000+2*60*1000 {
+		t.Fatalf("pending expiry = %d", got)
+	}
+	if got := config.pendingToneExpiry("1:2:stitch", pending); got != 1000+15000 {
+		t.Fatalf("stitch expiry = %d", got)
+	}
+	pending.WindowSeconds = 30
+	if got := config.pendingToneExpiry("1:3", pending); got != 1000+30000 {
+		t.Fatalf("cross-talkgroup expiry = %d", got)
+	}
+}
+
+func TestPendingToneSequenceJSON(t *testing.T) {
+	pending := &PendingToneSequence{
+		ToneSequence: &ToneSequence{
+			Tones:           []Tone{{Frequency: 853.2, Duration: 1}},
+			HasTones:        true,
+			MatchedToneSets: []*ToneSet{{Id: "a", Label: "Station 1"}},
+		},
+		CallId:                  42,
+		Timestamp:               1000,
+		Locked:                  true,
+		CrossTalkgroupSourceKey: "1:2",
+	}
+
+	b, err := json.Marshal(pending)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	restored := &PendingToneSequence{}
+	if err := json.Unmarshal(b, restored); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if restored.Locked {
+		t.Fatalf("lock of a transcription should not survive a restart")
+	}
+	if restored.CallId != 42 || restored.CrossTalkgroupSourceKey != "1:2" || len(restored.ToneSequence.MatchedToneSets) != 1 || restored.ToneSequence.MatchedToneSets[0].Label != "Station 1" {
+		t.Fatalf("restored = %+v", restored)
+	}
+}
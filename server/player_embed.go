@@ -0,0 +1,543 @@
+// Copyright (C) 2026 Thinline Dynamic Solutions
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>
+
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	playerEmbedTokenPrefix  = "embed_"
+	playerEmbedDefaultDelay = 30
+	playerEmbedMinDelay     = 5
+	playerEmbedCallsLimit   = 25
+	playerEmbedHistory      = 24 * time.Hour
+)
+
+// PlayerEmbed is a public player for a department website, showing the
+// recent calls of a few talkgroups. Its token is public, as it appears in
+// the page embedding the player, so every embed has a delay of its own on
+// top of the talkgroup and system delays.
+type PlayerEmbed struct {
+	Id        uint64           `json:"id"`
+	Label     string           `json:"label"`
+	Token     string           `json:"token"`
+	Scopes    []TalkgroupScope `json:"scopes"`
+	Delay     uint             `json:"delay"`
+	Origins   []string         `json:"origins"`
+	Enabled   bool             `json:"enabled"`
+	CreatedAt int64            `json:"createdAt"`
+}
+
+// validate normalizes the embed, defaulting its delay, and rejects embeds
+// without talkgroups or with a delay under the minimum.
+func (embed *PlayerEmbed) validate() error {
+	embed.Label = strings.TrimSpace(embed.Label)
+	if embed.Label == "" {
+		return fmt.Errorf("label is required")
+	}
+	if err := validateTalkgroupScopes(embed.Scopes); err != nil {
+		return err
+	}
+
+	if embed.Delay == 0 {
+		embed.Delay = playerEmbedDefaultDelay
+	}
+	if embed.Delay < playerEmbedMinDelay {
+		return fmt.Errorf("delay must be at least %d minutes", playerEmbedMinDelay)
+	}
+
+	origins := []string{}
+	for _, origin := range embed.Origins {
+		u, err := url.Parse(strings.TrimSpace(origin))
+		if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" || strings.Trim(u.Path, "/") != "" {
+			return fmt.Errorf("invalid origin %q, expected https://host", origin)
+		}
+		origins = append(origins, u.Scheme+"://"+strings.ToLower(u.Host))
+	}
+	embed.Origins = origins
+	return nil
+}
+
+// Allows reports whether the embed shows the talkgroup.
+func (embed *PlayerEmbed) Allows(system *System, talkgroup *Talkgroup) bool {
+	return embed != nil && talkgroupScopesAllow(embed.Scopes, system, talkgroup)
+}
+
+// released reports whether the call has passed the delay of the embed and
+// those of its talkgroup and system.
+func (embed *PlayerEmbed) released(controller *Controller, call *Call, now time.Time) bool {
+	delay := controller.Delayer.getSystemDelay(call)
+	if embed.Delay > delay {
+		delay = embed.Delay
+	}
+	return !call.Timestamp.Add(time.Duration(delay) * time.Minute).After(now)
+}
+
+// frameAncestors returns the Content-Security-Policy of the player page.
+func (embed *PlayerEmbed) frameAncestors() string {
+	if len(embed.Origins) == 0 {
+		return "frame-ancestors *"
+	}
+	return "frame-ancestors 'self' " + strings.Join(embed.Origins, " ")
+}
+
+// allowedOrigin returns the Access-Control-Allow-Origin of a request.
+func (embed *PlayerEmbed) allowedOrigin(origin string) string {
+	if len(embed.Origins) == 0 {
+		return "*"
+	}
+	for _, allowed := range embed.Origins {
+		if strings.EqualFold(allowed, origin) {
+			return allowed
+		}
+	}
+	return ""
+}
+
+type PlayerEmbeds struct {
+	mutex  sync.RWMutex
+	embeds map[uint64]*PlayerEmbed
+}
+
+func NewPlayerEmbeds() *PlayerEmbeds {
+	return &PlayerEmbeds{
+		embeds: map[uint64]*PlayerEmbed{},
+	}
+}
+
+func (embeds *PlayerEmbeds) Load(db *Database) error {
+	embeds.mutex.Lock()
+	defer embeds.mutex.Unlock()
+
+	rows, err := db.Sql.Query(`SELECT "playerEmbedId", "label", "token", "scopes", "delay", "origins", "enabled", "createdAt" FROM "playerEmbeds"`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	embeds.embeds = map[uint64]*PlayerEmbed{}
+	for rows.Next() {
+		embed := &PlayerEmbed{}
+		var scopes, origins string
+		if err := rows.Scan(&embed.Id, &embed.Label, &embed.Token, &scopes, &embed.Delay, &origins, &embed.Enabled, &embed.CreatedAt); err != nil {
+			continue
+		}
+		json.Unmarshal([]byte(scopes), &embed.Scopes)
+		json.Unmarshal([]byte(origins), &embed.Origins)
+		embeds.embeds[embed.Id] = embed
+	}
+	return rows.Err()
+}
+
+// List returns the embeds sorted by label.
+func (embeds *PlayerEmbeds) List() []PlayerEmbed {
+	embeds.mutex.RLock()
+	defer embeds.mutex.RUnlock()
+
+	list := make([]PlayerEmbed, 0, len(embeds.embeds))
+	for _, embed := range embeds.embeds {
+		list = append(list, *embed)
+	}
+	sort.Slice(list, func(i, j int) bool { return strings.ToLower(list[i].Label) < strings.ToLower(list[j].Label) })
+	return list
+}
+
+// Get returns a copy of the embed, or nil.
+func (embeds *PlayerEmbeds) Get(id uint64) *PlayerEmbed {
+	embeds.mutex.RLock()
+	defer embeds.mutex.RUnlock()
+
+	if embed, ok := embeds.embeds[id]; ok {
+		copy := *embed
+		return &copy
+	}
+	return nil
+}
+
+// Find returns a copy of the enabled embed of the token, or nil.
+func (embeds *PlayerEmbeds) Find(token string) *PlayerEmbed {
+	if embeds == nil || !strings.HasPrefix(token, playerEmbedTokenPrefix) {
+		return nil
+	}
+
+	embeds.mutex.RLock()
+	defer embeds.mutex.RUnlock()
+
+	for _, embed := range embeds.embeds {
+		if embed.Token == token && embed.Enabled {
+			copy := *embed
+			return &copy
+		}
+	}
+	return nil
+}
+
+// Save inserts the embed with a new token when Id is 0 and updates it
+// otherwise, keeping its token.
+func (embeds *PlayerEmbeds) Save(embed *PlayerEmbed, db *Database) error {
+	if err := embed.validate(); err != nil {
+		return err
+	}
+
+	scopes, _ := json.Marshal(embed.Scopes)
+	origins, _ := json.Marshal(embed.Origins)
+
+	if embed.Id == 0 {
+		buf := make([]byte, 16)
+		if _, err := rand.Read(buf); err != nil {
+			return fmt.Errorf("failed to generate token: %w", err)
+		}
+		embed.Token = playerEmbedTokenPrefix + hex.EncodeToString(buf)
+		embed.CreatedAt = time.Now().Unix()
+
+		if err := db.Sql.QueryRow(
+			`INSERT INTO "playerEmbeds" ("label", "token", "scopes", "delay", "origins", "enabled", "createdAt") VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING "playerEmbedId"`,
+			embed.Label, embed.Token, string(scopes), embed.Delay, string(origins), embed.Enabled, embed.CreatedAt,
+		).Scan(&embed.Id); err != nil {
+			return err
+		}
+	} else {
+		existing := embeds.Get(embed.Id)
+		if existing == nil {
+			return fmt.Errorf("embed %d not found", embed.Id)
+		}
+		embed.Token, embed.CreatedAt = existing.Token, existing.CreatedAt
+
+		if _, err := db.Sql.Exec(
+			`UPDATE "playerEmbeds" SET "label" = $1, "scopes" = $2, "delay" = $3, "origins" = $4, "enabled" = $5 WHERE "playerEmbedId" = $6`,
+			embed.Label, string(scopes), embed.Delay, string(origins), embed.Enabled, embed.Id,
+		); err != nil {
+			return err
+		}
+	}
+
+	embeds.mutex.Lock()
+	stored := *embed
+	embeds.embeds[embed.Id] = &stored
+	embeds.mutex.Unlock()
+	return nil
+}
+
+func (embeds *PlayerEmbeds) Delete(id uint64, db *Database) error {
+	if _, err := db.Sql.Exec(`DELETE FROM "playerEmbeds" WHERE "playerEmbedId" = $1`, id); err != nil {
+		return err
+	}
+
+	embeds.mutex.Lock()
+	delete(embeds.embeds, id)
+	embeds.mutex.Unlock()
+	return nil
+}
+
+// playerEmbedSnippet returns the HTML to paste in a website to show the
+// player.
+func (controller *Controller) playerEmbedSnippet(embed *PlayerEmbed) string {
+	base := normalizePublicBaseURL(controller.Options.BaseUrl)
+	return fmt.Sprintf(`<script src="%s/embed/player.js" data-token="%s" async></script>`, base, embed.Token)
+}
+
+// PlayerEmbedsHandler lists, creates, changes and deletes the public player
+// embeds. It needs the manage systems permission.
+//
+// GET    /api/admin/embeds
+// POST   /api/admin/embeds {"label": "...", "delay": 30, "origins": ["https://example.org"], "enabled": true, "scopes": [...]}
+// PUT    /api/admin/embeds?id=<id>
+// DELETE /api/admin/embeds?id=<id>
+func (admin *Admin) PlayerEmbedsHandler(w http.ResponseWriter, r *http.Request) {
+	t := admin.GetAuthorization(r)
+	if !admin.ValidatePermission(t, PermissionManageSystems) {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	writeError := func(status int, err error) {
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+	}
+
+	embeds := admin.Controller.PlayerEmbeds
+
+	existing := func() *PlayerEmbed {
+		id, err := strconv.ParseUint(r.URL.Query().Get("id"), 10, 64)
+		if err != nil || id == 0 {
+			writeError(http.StatusBadRequest, fmt.Errorf("invalid embed id"))
+			return nil
+		}
+		embed := embeds.Get(id)
+		if embed == nil || !admin.tenantAllowsTalkgroupScopes(t, embed.Scopes) {
+			writeError(http.StatusNotFound, fmt.Errorf("embed not found"))
+			return nil
+		}
+		return embed
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		list := []PlayerEmbed{}
+		for _, embed := range embeds.List() {
+			if admin.tenantAllowsTalkgroupScopes(t, embed.Scopes) {
+				list = append(list, embed)
+			}
+		}
+		json.NewEncoder(w).Encode(map[string]any{"embeds": list})
+
+	case http.MethodPost, http.MethodPut:
+		embed := &PlayerEmbed{}
+		if err := json.NewDecoder(r.Body).Decode(embed); err != nil {
+			writeError(http.StatusBadRequest, fmt.Errorf("invalid embed: %v", err))
+			return
+		}
+		embed.Id = 0
+		if r.Method == http.MethodPut {
+			current := existing()
+			if current == nil {
+				return
+			}
+			embed.Id = current.Id
+		}
+		if err := admin.checkTalkgroupScopes(t, embed.Scopes); err != nil {
+			writeError(http.StatusBadRequest, err)
+			return
+		}
+		if err := embeds.Save(embed, admin.Controller.Database); err != nil {
+			writeError(http.StatusBadRequest, err)
+			return
+		}
+		admin.Controller.Logs.LogEvent(LogLevelInfo, fmt.Sprintf("admin: player embed %q saved with a %d minute delay", embed.Label, embed.Delay))
+		json.NewEncoder(w).Encode(map[string]any{"embed": embed, "snippet": admin.Controller.playerEmbedSnippet(embed)})
+
+	case http.MethodDelete:
+		embed := existing()
+		if embed == nil {
+			return
+		}
+		if err := embeds.Delete(embed.Id, admin.Controller.Database); err != nil {
+			writeError(http.StatusInternalServerError, err)
+			return
+		}
+		admin.Controller.Logs.LogEvent(LogLevelInfo, fmt.Sprintf("admin: player embed %q deleted", embed.Label))
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// PlayerEmbedHandler serves the public player.
+//
+// GET /embed/player.js                       script adding the player iframe
+// GET /embed/{token}                         player page
+// GET /embed/{token}/calls                   recent calls as JSON
+// GET /embed/{token}/calls/{id}/audio        call audio
+func (api *Api) PlayerEmbedHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/embed/"), "/"), "/")
+	if len(parts) == 1 && parts[0] == "player.js" {
+		w.Header().Set("Content-Type", "application/javascript; charset=utf-8")
+		w.Header().Set("Cache-Control", "public, max-age=3600")
+		w.Write([]byte(playerEmbedScript))
+		return
+	}
+
+	embed := api.Controller.PlayerEmbeds.Find(parts[0])
+	if embed == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	switch {
+	case len(parts) == 1:
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Header().Set("Content-Security-Policy", embed.frameAncestors())
+		w.Header().Set("Cache-Control", "no-cache")
+		playerEmbedPage.Execute(w, map[string]any{"Label": embed.Label, "Delay": embed.Delay})
+
+	case len(parts) == 2 && parts[1] == "calls":
+		api.playerEmbedCalls(w, r, embed)
+
+	case len(parts) == 4 && parts[1] == "calls" && parts[3] == "audio":
+		callId, err := strconv.ParseUint(parts[2], 10, 64)
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		call, err := api.Controller.Calls.GetCall(callId)
+		if err != nil || call == nil || len(call.Audio) == 0 || !embed.Allows(call.System, call.Talkgroup) ||
+			call.Timestamp.Before(time.Now().Add(-playerEmbedHistory)) ||
+			api.Controller.callDelayed(callId) || !embed.released(api.Controller, call, time.Now()) {
+			http.NotFound(w, r)
+			return
+		}
+		writeCallAudio(w, r, call)
+
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// playerEmbedCalls answers with the recent calls of the embed, newest first.
+func (api *Api) playerEmbedCalls(w http.ResponseWriter, r *http.Request, embed *PlayerEmbed) {
+	if origin := r.Header.Get("Origin"); origin != "" {
+		if allowed := embed.allowedOrigin(origin); allowed != "" {
+			w.Header().Set("Access-Control-Allow-Origin", allowed)
+			w.Header().Set("Vary", "Origin")
+		}
+	}
+
+	now := time.Now()
+	list, err := api.Controller.readScopedCalls(embed.Scopes, now.Add(-playerEmbedHistory), 0, playerEmbedCallsLimit, func(call *Call) bool {
+		return embed.released(api.Controller, call, now)
+	})
+	if err != nil {
+		api.exitWithError(w, http.StatusInternalServerError, "Failed to read calls")
+		return
+	}
+
+	calls := []map[string]any{}
+	for _, call := range list {
+		calls = append(calls, scopedCallResponse(call, fmt.Sprintf("/embed/%s/calls/%d/audio", embed.Token, call.Id)))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=15")
+	json.NewEncoder(w).Encode(map[string]any{
+		"label": embed.Label,
+		"delay": embed.Delay,
+		"calls": calls,
+	})
+}
+
+// playerEmbedScript adds the player iframe after the script tag, sized by
+// its data-width and data-height attributes.
+const playerEmbedScript = `(function () {
+  var script = document.currentScript;
+  var token = script && script.getAttribute('data-token');
+  if (!token) return;
+  var frame = document.createElement('iframe');
+  frame.src = new URL('/embed/' + encodeURIComponent(token), script.src).href;
+  frame.title = 'Scanner player';
+  frame.loading = 'lazy';
+  frame.style.border = '0';
+  frame.style.width = script.getAttribute('data-width') || '100%';
+  frame.style.height = script.getAttribute('data-height') || '420px';
+  script.parentNode.insertBefore(frame, script.nextSibling);
+})();
+`
+
+var playerEmbedPage = template.Must(template.New("embed").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Label}}</title>
+<style>
+  body { margin: 0; font: 14px/1.4 system-ui, sans-serif; color: #222; background: #fff; }
+  header { padding: 10px 12px; border-bottom: 1px solid #ddd; }
+  header h1 { margin: 0; font-size: 16px; }
+  header p { margin: 2px 0 0; color: #666; font-size: 12px; }
+  ul { list-style: none; margin: 0; padding: 0; }
+  li { display: flex; align-items: center; gap: 10px; padding: 8px 12px; border-bottom: 1px solid #eee; }
+  li button { width: 32px; height: 32px; border: 0; border-radius: 50%; background: #1565c0; color: #fff; cursor: pointer; }
+  li .tg { font-weight: 600; }
+  li .time { color: #666; font-size: 12px; }
+  .empty { padding: 16px 12px; color: #666; }
+</style>
+</head>
+<body>
+<header>
+  <h1>{{.Label}}</h1>
+  <p>Calls are published {{.Delay}} minutes after they are received.</p>
+</header>
+<ul id="calls"></ul>
+<audio id="audio"></audio>
+<script>
+(function () {
+  var list = document.getElementById('calls');
+  var audio = document.getElementById('audio');
+  var playing = null;
+
+  function render(calls) {
+    list.textContent = '';
+    if (!calls.length) {
+      var empty = document.createElement('li');
+      empty.className = 'empty';
+      empty.textContent = 'No recent calls.';
+      list.appendChild(empty);
+      return;
+    }
+    calls.forEach(function (call) {
+      var item = document.createElement('li');
+      var button = document.createElement('button');
+      button.textContent = playing === call.audioUrl && !audio.paused ? '❚❚' : '▶';
+      button.setAttribute('aria-label', 'Play');
+      button.onclick = function () {
+        if (playing === call.audioUrl && !audio.paused) {
+          audio.pause();
+        } else {
+          playing = call.audioUrl;
+          audio.src = call.audioUrl;
+          audio.play();
+        }
+        render(calls);
+      };
+      var text = document.createElement('div');
+      var tg = document.createElement('div');
+      tg.className = 'tg';
+      tg.textContent = call.talkgroupName || call.talkgroup;
+      var time = document.createElement('div');
+      time.className = 'time';
+      time.textContent = new Date(call.timestamp).toLocaleString() + ' · ' + call.system;
+      text.appendChild(tg);
+      text.appendChild(time);
+      item.appendChild(button);
+      item.appendChild(text);
+      list.appendChild(item);
+    });
+  }
+
+  function refresh() {
+    fetch(location.pathname.replace(/\/$/, '') + '/calls')
+      .then(function (res) { return res.json(); })
+      .then(function (data) { render(data.calls || []); })
+      .catch(function () {});
+  }
+
+  audio.onended = audio.onpause = function () { refresh(); };
+  refresh();
+  setInterval(refresh, 60000);
+})();
+</script>
+</body>
+</html>
+`))
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPlayerEmbedValidate(t *testing.T) {
+	scopes := []TalkgroupScope{{SystemRef: 1, TalkgroupRefs: []uint{100}}}
+
+	embed := PlayerEmbed{Label: " County Fire ", Scopes: scopes, Origins: []string{"https://Example.org/"}}
+	if err := embed.validate(); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+	if embed.Label != "County Fire" || embed.Delay != playerEmbedDefaultDelay || embed.Origins[0] != "https://example.org" {
+		t.Fatalf("unexpected embed %+v", embed)
+	}
+
+	for _, embed := range []PlayerEmbed{
+		{Label: "Fire", Scopes: scopes, Delay: playerEmbedMinDelay - 1},
+		{Label: "Fire"},
+		{Label: "Fire", Scopes: scopes, Origins: []string{"example.org"}},
+		{Label: "Fire", Scopes: scopes, Origins: []string{"https://example.org/news"}},
+	} {
+		if err := embed.validate(); err == nil {
+			t.Fatalf("embed %+v should be invalid", embed)
+		}
+	}
+}
+
+func TestPlayerEmbedReleased(t *testing.T) {
+	controller := &Controller{Options: &Options{}}
+	controller.Delayer = NewDelayer(controller)
+	embed := &PlayerEmbed{Delay: 10}
+	now := time.Now()
+
+	call := &Call{Timestamp: now.Add(-15 * time.Minute), System: &System{}, Talkgroup: &Talkgroup{}}
+	if !embed.released(controller, call, now) {
+		t.Fatal("call past the embed delay should be released")
+	}
+	call.Talkgroup.Delay = 20
+	if embed.released(controller, call, now) {
+		t.Fatal("a longer talkgroup delay should hold the call")
+	}
+	call.Talkgroup.Delay = 0
+	call.Timestamp = now.Add(-5 * time.Minute)
+	if embed.released(controller, call, now) {
+		t.Fatal("the embed delay should hold the call")
+	}
+}
+
+func TestPlayerEmbedOrigins(t *testing.T) {
+	open := &PlayerEmbed{}
+	if open.frameAncestors() != "frame-ancestors *" || open.allowedOrigin("https://a.example") != "*" {
+		t.Fatal("embeds without origins should be open to every site")
+	}
+
+	embed := &PlayerEmbed{Origins: []string{"https://example.org"}}
+	if embed.frameAncestors() != "frame-ancestors 'self' https://example.org" {
+		t.Fatalf("frameAncestors = %q", embed.frameAncestors())
+	}
+	if embed.allowedOrigin("https://EXAMPLE.org") != "https://example.org" || embed.allowedOrigin("https://other.example") != "" {
+		t.Fatal("only the embed origins should be allowed")
+	}
+}
+
+func TestPlayerEmbedHandler(t *testing.T) {
+	controller := &Controller{Options: &Options{}, Logs: NewLogs(), PlayerEmbeds: NewPlayerEmbeds()}
+	controller.PlayerEmbeds.embeds[1] = &PlayerEmbed{Id: 1, Label: "County <Fire>", Token: playerEmbedTokenPrefix + "abc", Delay: 30, Enabled: true, Origins: []string{"https://example.org"}}
+	controller.PlayerEmbeds.embeds[2] = &PlayerEmbed{Id: 2, Label: "Off", Token: playerEmbedTokenPrefix + "off", Delay: 30}
+	api := &Api{Controller: controller}
+	handler := SecurityHeadersMiddleware(http.HandlerFunc(api.PlayerEmbedHandler))
+
+	serve := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w
+	}
+
+	w := serve("/embed/player.js")
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "data-token") {
+		t.Fatalf("player.js = %d", w.Code)
+	}
+
+	w = serve("/embed/" + playerEmbedTokenPrefix + "abc")
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "County &lt;Fire&gt;") {
+		t.Fatalf("player page = %d %s", w.Code, w.Body.String())
+	}
+	if w.Header().Get("Content-Security-Policy") != "frame-ancestors 'self' https://example.org" || w.Header().Get("X-Frame-Options") != "" {
+		t.Fatalf("player page should be framed by its origins only: %v", w.Header())
+	}
+
+	for _, path := range []string{"/embed/" + playerEmbedTokenPrefix + "off", "/embed/unknown", "/embed/" + playerEmbedTokenPrefix + "abc/other"} {
+		if w := serve(path); w.Code != http.StatusNotFound {
+			t.Fatalf("%s = %d, want 404", path, w.Code)
+		}
+	}
+}
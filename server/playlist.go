@@ -0,0 +1,351 @@
+// Copyright (C) 2026 Thinline Dynamic Solutions
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>
+
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var playlistLog = Logger(LogCategoryCalls)
+
+const (
+	playlistDefaultLimit = 20
+	playlistMaxLimit     = 100
+	playlistChunkSize    = 500
+	playlistMaxScan      = 5000
+	playlistLookback     = 10 * time.Minute
+)
+
+// playlistCursor is the position in a user's queue: calls are ordered by the
+// time they become playable for the user, then by id.
+type playlistCursor struct {
+	PlayableAt int64 // unix ms
+	CallId     uint64
+}
+
+func (cursor playlistCursor) String() string {
+	return fmt.Sprintf("%d.%d", cursor.PlayableAt, cursor.CallId)
+}
+
+func (cursor playlistCursor) before(other playlistCursor) bool {
+	if cursor.PlayableAt != other.PlayableAt {
+		return cursor.PlayableAt < other.PlayableAt
+	}
+	return cursor.CallId < other.CallId
+}
+
+func parsePlaylistCursor(s string) (playlistCursor, error) {
+	at, id, ok := strings.Cut(s, ".")
+	if !ok {
+		return playlistCursor{}, fmt.Errorf("invalid cursor %q", s)
+	}
+	playableAt, err := strconv.ParseInt(at, 10, 64)
+	if err != nil {
+		return playlistCursor{}, fmt.Errorf("invalid cursor %q", s)
+	}
+	callId, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		return playlistCursor{}, fmt.Errorf("invalid cursor %q", s)
+	}
+	return playlistCursor{PlayableAt: playableAt, CallId: callId}, nil
+}
+
+// playlistSelection is the talkgroups a playlist is built from. Empty means
+// every talkgroup the user can access.
+type playlistSelection struct {
+	systems    map[uint]bool
+	talkgroups map[string]bool // "systemRef:talkgroupRef"
+}
+
+// parsePlaylistSelection parses systems=1,2 and talkgroups=1:100,1:200.
+func parsePlaylistSelection(systems string, talkgroups string) (*playlistSelection, error) {
+	selection := &playlistSelection{talkgroups: map[string]bool{}}
+	var err error
+	if selection.systems, err = parseCallStreamRefs(systems); err != nil {
+		return nil, err
+	}
+	for _, f := range strings.Split(talkgroups, ",") {
+		f = strings.TrimSpace(f)
+		if f == "" {
+			continue
+		}
+		systemRef, talkgroupRef, ok := strings.Cut(f, ":")
+		s, err1 := strconv.ParseUint(systemRef, 10, 32)
+		t, err2 := strconv.ParseUint(talkgroupRef, 10, 32)
+		if !ok || err1 != nil || err2 != nil {
+			return nil, fmt.Errorf("invalid talkgroup %q, expected systemRef:talkgroupRef", f)
+		}
+		selection.talkgroups[fmt.Sprintf("%d:%d", s, t)] = true
+	}
+	return selection, nil
+}
+
+func (selection *playlistSelection) empty() bool {
+	return len(selection.systems) == 0 && len(selection.talkgroups) == 0
+}
+
+func (selection *playlistSelection) includes(system *System, talkgroup *Talkgroup) bool {
+	if selection.empty() {
+		return true
+	}
+	return selection.systems[system.SystemRef] || selection.talkgroups[fmt.Sprintf("%d:%d", system.SystemRef, talkgroup.TalkgroupRef)]
+}
+
+// playlistItem is a call in a user's queue.
+type playlistItem struct {
+	Id             uint64 `json:"id"`
+	System         uint   `json:"system"`
+	SystemLabel    string `json:"systemLabel"`
+	Talkgroup      uint   `json:"talkgroup"`
+	TalkgroupLabel string `json:"talkgroupLabel"`
+	TalkgroupName  string `json:"talkgroupName"`
+	Timestamp      int64  `json:"timestamp"`  // unix ms
+	PlayableAt     int64  `json:"playableAt"` // unix ms
+	Delay          uint   `json:"delay"`      // minutes
+	HasTones       bool   `json:"hasTones,omitempty"`
+	Transcript     string `json:"transcript,omitempty"`
+	AudioUrl       string `json:"audioUrl"`
+}
+
+func (item *playlistItem) cursor() playlistCursor {
+	return playlistCursor{PlayableAt: item.PlayableAt, CallId: item.Id}
+}
+
+// playlistPage orders the candidates by the time they become playable and
+// returns up to limit of those after the cursor that are playable at now.
+// Calls recorded from complete on were not scanned, so only candidates
+// playable before complete can be returned; more reports whether the queue
+// has further playable calls.
+func playlistPage(candidates []*playlistItem, after playlistCursor, now int64, complete int64, limit int) (page []*playlistItem, more bool) {
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].cursor().before(candidates[j].cursor())
+	})
+
+	page = []*playlistItem{}
+	for _, item := range candidates {
+		if !after.before(item.cursor()) {
+			continue
+		}
+		if item.PlayableAt > now || item.PlayableAt >= complete {
+			break
+		}
+		if len(page) == limit {
+			return page, true
+		}
+		page = append(page, item)
+	}
+	return page, complete <= now
+}
+
+// PlaylistHandler returns the next calls a user can play, in the order they
+// become playable after the user's effective delay, so thin clients do not
+// have to implement delays and access rules themselves.
+//
+//	GET /api/playlist?talkgroups=1:100,1:200&systems=2&limit=20&cursor=...
+//
+// Without a cursor the queue starts at since (unix ms), ten minutes ago by
+// default. Pass the returned cursor to get the calls that follow.
+func (api *Api) PlaylistHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		api.exitWithError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	client := api.getClient(r)
+	if client == nil || (!client.IsAdmin && client.User == nil) {
+		api.exitWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	if client.User != nil && client.User.PinExpired() {
+		api.exitWithError(w, http.StatusForbidden, "PIN expired")
+		return
+	}
+
+	query := r.URL.Query()
+	now := time.Now()
+
+	selection, err := parsePlaylistSelection(query.Get("systems"), query.Get("talkgroups"))
+	if err != nil {
+		api.exitWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	limit := playlistDefaultLimit
+	if s := query.Get("limit"); s != "" {
+		v, err := strconv.Atoi(s)
+		if err != nil || v < 1 {
+			api.exitWithError(w, http.StatusBadRequest, "invalid limit")
+			return
+		}
+		limit = v
+		if limit > playlistMaxLimit {
+			limit = playlistMaxLimit
+		}
+	}
+
+	after := playlistCursor{PlayableAt: now.Add(-playlistLookback).UnixMilli()}
+	if s := query.Get("cursor"); s != "" {
+		if after, err = parsePlaylistCursor(s); err != nil {
+			api.exitWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	} else if s := query.Get("since"); s != "" {
+		v, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			api.exitWithError(w, http.StatusBadRequest, "invalid since")
+			return
+		}
+		after.PlayableAt = v
+	}
+
+	// The talkgroups of the playlist and the longest delay among them, which
+	// bounds how far back a call playable after the cursor was recorded
+	talkgroupIds := []uint64{}
+	var maxDelay uint
+	api.Controller.Systems.mutex.RLock()
+	for _, system := range api.Controller.Systems.List {
+		for _, talkgroup := range system.Talkgroups.List {
+			if !selection.includes(system, talkgroup) {
+				continue
+			}
+			call := &Call{System: system, Talkgroup: talkgroup}
+			if !client.IsAdmin && !api.Controller.userHasAccess(client.User, call) {
+				continue
+			}
+			talkgroupIds = append(talkgroupIds, talkgroup.Id)
+			if client.IsAdmin {
+				continue
+			}
+			if delay := api.Controller.Delayer.getEffectiveDelayForClient(call, client); delay > maxDelay {
+				maxDelay = delay
+			}
+		}
+	}
+	api.Controller.Systems.mutex.RUnlock()
+
+	response := map[string]any{"calls": []*playlistItem{}, "cursor": after.String(), "more": false}
+	w.Header().Set("Content-Type", "application/json")
+	if len(talkgroupIds) == 0 {
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	from := after.PlayableAt - int64(maxDelay)*int64(time.Minute/time.Millisecond)
+	args := queryArgs{}
+	where := fmt.Sprintf(`"talkgroupId" IN %s AND "timestamp" >= %s AND "timestamp" <= %s`, args.in(talkgroupIds), args.add(from), args.add(now.UnixMilli()))
+
+	candidates := []*playlistItem{}
+	complete := now.UnixMilli() + 1
+	scanned := 0
+	for scanned < playlistMaxScan {
+		chunkArgs := append(queryArgs{}, args...)
+		rows, err := api.Controller.Database.Sql.Query(
+			fmt.Sprintf(`SELECT "callId", "systemId", "talkgroupId", "timestamp", "transcript", "hasTones" FROM "calls" WHERE %s ORDER BY "timestamp", "callId" LIMIT %s OFFSET %s`, where, chunkArgs.add(playlistChunkSize), chunkArgs.add(scanned)),
+			chunkArgs...,
+		)
+		if err != nil {
+			playlistLog.Error(fmt.Sprintf("PlaylistHandler: %v", err))
+			api.exitWithError(w, http.StatusInternalServerError, "failed to read the playlist")
+			return
+		}
+
+		rowCount := 0
+		var lastTimestamp int64
+		for rows.Next() {
+			rowCount++
+			var (
+				callId     uint64
+				systemId   uint64
+				tgId       uint64
+				timestamp  int64
+				transcript sql.NullString
+				hasTones   bool
+			)
+			if err := rows.Scan(&callId, &systemId, &tgId, &timestamp, &transcript, &hasTones); err != nil {
+				continue
+			}
+			lastTimestamp = timestamp
+
+			system, ok := api.Controller.Systems.GetSystemById(systemId)
+			if !ok {
+				continue
+			}
+			talkgroup, ok := system.Talkgroups.GetTalkgroupById(tgId)
+			if !ok {
+				continue
+			}
+			call := &Call{Id: callId, Timestamp: time.UnixMilli(timestamp), System: system, Talkgroup: talkgroup}
+
+			var delay uint
+			if !client.IsAdmin {
+				if !api.Controller.userHasAccess(client.User, call) {
+					continue
+				}
+				delay = api.Controller.Delayer.getEffectiveDelayForClient(call, client)
+			}
+
+			item := &playlistItem{
+				Id:             callId,
+				System:         system.SystemRef,
+				SystemLabel:    system.Label,
+				Talkgroup:      talkgroup.TalkgroupRef,
+				TalkgroupLabel: talkgroup.Label,
+				TalkgroupName:  talkgroup.Name,
+				Timestamp:      timestamp,
+				PlayableAt:     timestamp + int64(delay)*int64(time.Minute/time.Millisecond),
+				Delay:          delay,
+				HasTones:       hasTones,
+				AudioUrl:       fmt.Sprintf("/api/calls/%d/audio", callId),
+			}
+			if client.IsAdmin || api.Controller.Billing.TranscriptsAllowed(client.User) {
+				item.Transcript = transcript.String
+			}
+			candidates = append(candidates, item)
+		}
+		if err := rows.Err(); err != nil {
+			rows.Close()
+			playlistLog.Error(fmt.Sprintf("PlaylistHandler: %v", err))
+			api.exitWithError(w, http.StatusInternalServerError, "failed to read the playlist")
+			return
+		}
+		rows.Close()
+
+		scanned += rowCount
+		if rowCount < playlistChunkSize {
+			break
+		}
+		if scanned >= playlistMaxScan {
+			// Calls recorded from here on were not read and may become
+			// playable before the candidates that follow
+			complete = lastTimestamp
+		}
+	}
+
+	page, more := playlistPage(candidates, after, now.UnixMilli(), complete, limit)
+	response["calls"] = page
+	response["more"] = more
+	if len(page) > 0 {
+		response["cursor"] = page[len(page)-1].cursor().String()
+	}
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,60 @@
+package main
+
+import "testing"
+
+func TestPlaylistCursor(t *testing.T) {
+	cursor := playlistCursor{PlayableAt: 1767225600000, CallId: 42}
+	parsed, err := parsePlaylistCursor(cursor.String())
+	if err != nil || parsed != cursor {
+		t.Fatalf("parsed %v, %v", parsed, err)
+	}
+	for _, s := range []string{"", "42", "a.1", "1.b"} {
+		if _, err := parsePlaylistCursor(s); err == nil {
+			t.Fatalf("cursor %q accepted", s)
+		}
+	}
+}
+
+func TestParsePlaylistSelection(t *testing.T) {
+	selection, err := parsePlaylistSelection("2", "1:100, 1:200")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	system1 := &System{SystemRef: 1}
+	system2 := &System{SystemRef: 2}
+	if !selection.includes(system1, &Talkgroup{TalkgroupRef: 100}) || selection.includes(system1, &Talkgroup{TalkgroupRef: 300}) {
+		t.Fatalf("talkgroup selection not applied")
+	}
+	if !selection.includes(system2, &Talkgroup{TalkgroupRef: 300}) {
+		t.Fatalf("system selection not applied")
+	}
+	if _, err := parsePlaylistSelection("", "100"); err == nil {
+		t.Fatalf("talkgroup without system accepted")
+	}
+}
+
+func TestPlaylistPage(t *testing.T) {
+	// A long delay on call 1 makes it playable after call 2
+	candidates := []*playlistItem{
+		{Id: 1, Timestamp: 1000, PlayableAt: 61000},
+		{Id: 2, Timestamp: 2000, PlayableAt: 2000},
+		{Id: 3, Timestamp: 3000, PlayableAt: 3000},
+		{Id: 4, Timestamp: 4000, PlayableAt: 90000},
+	}
+
+	page, more := playlistPage(candidates, playlistCursor{}, 70000, 70001, 2)
+	if len(page) != 2 || page[0].Id != 2 || page[1].Id != 3 || !more {
+		t.Fatalf("first page %v, more %v", page, more)
+	}
+
+	page, more = playlistPage(candidates, page[1].cursor(), 70000, 70001, 2)
+	if len(page) != 1 || page[0].Id != 1 || more {
+		t.Fatalf("second page %v, more %v", page, more)
+	}
+
+	// Calls recorded after the scan stopped may still become playable first
+	page, more = playlistPage(candidates, playlistCursor{}, 70000, 2500, 10)
+	if len(page) != 1 || page[0].Id != 2 || !more {
+		t.Fatalf("truncated page %v, more %v", page, more)
+	}
+}
@@ -68,6 +68,7 @@ var PostgresqlSchema = []string{
     "siteRef" text NOT NULL DEFAULT '',
     "rfss" integer NOT NULL DEFAULT 0,
     "frequencies" text NOT NULL DEFAULT '[]',
+    "controlChannels" text NOT NULL DEFAULT '[]',
     "preferred" boolean NOT NULL DEFAULT false,
     "systemId" bigint NOT NULL DEFAULT 0,
     CONSTRAINT "sites_systemId" FOREIGN KEY ("systemId") REFERENCES "systems" ("systemId") ON DELETE CASCADE ON UPDATE CASCADE
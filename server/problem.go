@@ -0,0 +1,171 @@
+// Copyright (C) 2026 Thinline Dynamic Solutions
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// Error codes of the API error responses. They are part of the API: clients
+// branch on them and localize the messages, so existing codes must not change.
+const (
+	ErrCodeBadRequest        = "bad_request"
+	ErrCodeUnauthorized      = "unauthorized"
+	ErrCodePaymentRequired   = "payment_required"
+	ErrCodeForbidden         = "forbidden"
+	ErrCodeNotFound          = "not_found"
+	ErrCodeMethodNotAllowed  = "method_not_allowed"
+	ErrCodeConflict          = "conflict"
+	ErrCodeGone              = "gone"
+	ErrCodeTooLarge          = "payload_too_large"
+	ErrCodeInvalidCall       = "invalid_call"
+	ErrCodeRateLimited       = "rate_limited"
+	ErrCodeInternal          = "internal_error"
+	ErrCodeUpstream          = "upstream_error"
+	ErrCodeUnavailable       = "service_unavailable"
+	ErrCodeInvalidCredential = "invalid_credentials"
+	ErrCodeEmailNotVerified  = "email_not_verified"
+	ErrCodeCaptchaFailed     = "captcha_failed"
+	ErrCodeAdminRequired     = "admin_required"
+	ErrCodeUpgradeRequired   = "upgrade_required"
+)
+
+// errorCodeForStatus is the code of errors raised without a specific one.
+func errorCodeForStatus(status int) string {
+	switch status {
+	case http.StatusBadRequest:
+		return ErrCodeBadRequest
+	case http.StatusUnauthorized:
+		return ErrCodeUnauthorized
+	case http.StatusPaymentRequired:
+		return ErrCodePaymentRequired
+	case http.StatusForbidden:
+		return ErrCodeForbidden
+	case http.StatusNotFound:
+		return ErrCodeNotFound
+	case http.StatusMethodNotAllowed:
+		return ErrCodeMethodNotAllowed
+	case http.StatusConflict:
+		return ErrCodeConflict
+	case http.StatusGone:
+		return ErrCodeGone
+	case http.StatusRequestEntityTooLarge:
+		return ErrCodeTooLarge
+	case http.StatusExpectationFailed:
+		return ErrCodeInvalidCall
+	case http.StatusUpgradeRequired:
+		return ErrCodeUpgradeRequired
+	case http.StatusTooManyRequests:
+		return ErrCodeRateLimited
+	case http.StatusBadGateway:
+		return ErrCodeUpstream
+	case http.StatusServiceUnavailable:
+		return ErrCodeUnavailable
+	}
+	if status >= 500 {
+		return ErrCodeInternal
+	}
+	return ErrCodeBadRequest
+}
+
+// APIError is an error with the HTTP status and code it is reported with.
+type APIError struct {
+	Status  int
+	Code    string
+	Message string
+	Err     error
+}
+
+func NewAPIError(status int, code string, message string) *APIError {
+	if code == "" {
+		code = errorCodeForStatus(status)
+	}
+	return &APIError{Status: status, Code: code, Message: message}
+}
+
+func (e *APIError) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+func (e *APIError) Unwrap() error {
+	return e.Err
+}
+
+// Wrap returns a copy of the error with its cause, which is logged but not
+// sent to the client.
+func (e *APIError) Wrap(err error) *APIError {
+	wrapped := *e
+	wrapped.Err = err
+	return &wrapped
+}
+
+// Problem is an RFC 7807 problem details response. Error repeats Detail for
+// the clients reading the {"error": "..."} responses used before.
+type Problem struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+	Code     string `json:"code"`
+	Error    string `json:"error,omitempty"`
+}
+
+// newProblem describes err; errors other than APIError are internal errors
+// whose message is not disclosed.
+func newProblem(err error, instance string) Problem {
+	var apiError *APIError
+	if !errors.As(err, &apiError) {
+		apiError = NewAPIError(http.StatusInternalServerError, ErrCodeInternal, "internal server error")
+	}
+
+	detail := strings.TrimSpace(apiError.Message)
+	return Problem{
+		Type:     "about:blank",
+		Title:    http.StatusText(apiError.Status),
+		Status:   apiError.Status,
+		Detail:   detail,
+		Instance: instance,
+		Code:     apiError.Code,
+		Error:    detail,
+	}
+}
+
+// writeProblem writes err as an application/problem+json response.
+func writeProblem(w http.ResponseWriter, r *http.Request, err error) {
+	instance := ""
+	if r != nil {
+		instance = r.URL.Path
+	}
+	problem := newProblem(err, instance)
+
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.Header().Del("Content-Length")
+	w.WriteHeader(problem.Status)
+	json.NewEncoder(w).Encode(problem)
+}
+
+// exitWithAPIError logs and writes a typed API error.
+func (api *Api) exitWithAPIError(w http.ResponseWriter, r *http.Request, err *APIError) {
+	api.Controller.Logs.LogEvent(LogLevelError, "api: "+err.Error())
+	writeProblem(w, r, err)
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWriteProblem(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, "/api/user/login", nil)
+	w := httptest.NewRecorder()
+	writeProblem(w, r, NewAPIError(http.StatusForbidden, ErrCodeEmailNotVerified, "Email verification required"))
+
+	if w.Code != http.StatusForbidden || w.Header().Get("Content-Type") != "application/problem+json" {
+		t.Fatalf("status %d content type %q", w.Code, w.Header().Get("Content-Type"))
+	}
+
+	var problem Problem
+	if err := json.Unmarshal(w.Body.Bytes(), &problem); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if problem.Code != ErrCodeEmailNotVerified || problem.Status != http.StatusForbidden || problem.Title != "Forbidden" ||
+		problem.Detail != "Email verification required" || problem.Error != problem.Detail || problem.Instance != "/api/user/login" {
+		t.Fatalf("problem = %+v", problem)
+	}
+}
+
+func TestProblemDefaults(t *testing.T) {
+	if problem := newProblem(NewAPIError(http.StatusNotFound, "", "call not found\n"), ""); problem.Code != ErrCodeNotFound || problem.Detail != "call not found" {
+		t.Fatalf("problem = %+v", problem)
+	}
+
+	// Wrapped causes stay out of the response
+	wrapped := fmt.Errorf("saving: %w", NewAPIError(http.StatusConflict, "", "already exists").Wrap(errors.New("duplicate key")))
+	if problem := newProblem(wrapped, ""); problem.Status != http.StatusConflict || problem.Detail != "already exists" {
+		t.Fatalf("problem = %+v", problem)
+	}
+
+	if problem := newProblem(errors.New("pq: connection refused"), ""); problem.Code != ErrCodeInternal || problem.Detail != "internal server error" {
+		t.Fatalf("internal error disclosed: %+v", problem)
+	}
+}
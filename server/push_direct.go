@@ -0,0 +1,501 @@
+// Copyright (C) 2026 Thinline Dynamic Solutions
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>
+
+package main
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+const (
+	pushDirectWorkers   = 8
+	fcmScope            = "https://www.googleapis.com/auth/firebase.messaging"
+	apnsTokenLifetime   = 50 * time.Minute // Apple rejects tokens older than an hour
+	pushVoIPTokenPrefix = "voip:"
+)
+
+// Endpoints, variables for tests.
+var (
+	fcmSendURL        = "https://fcm.googleapis.com/v1/projects/%s/messages:send"
+	apnsProductionURL = "https://api.push.apple.com"
+	apnsSandboxURL    = "https://api.sandbox.push.apple.com"
+)
+
+// PushDeliveryConfig selects how push notifications are delivered: through
+// the relay server, or directly to FCM and APNs with the credentials of the
+// deployment.
+type PushDeliveryConfig struct {
+	Mode              string `json:"mode"`              // "relay" (default) or "direct"
+	FCMServiceAccount string `json:"fcmServiceAccount"` // service account JSON of the Firebase project
+	APNsKey           string `json:"apnsKey"`           // .p8 auth key, for VoIP pushes
+	APNsKeyId         string `json:"apnsKeyId"`
+	APNsTeamId        string `json:"apnsTeamId"`
+	APNsTopic         string `json:"apnsTopic"` // bundle id of the app, ".voip" is appended
+	APNsSandbox       bool   `json:"apnsSandbox"`
+}
+
+func (config PushDeliveryConfig) direct() bool {
+	return config.Mode == "direct"
+}
+
+// pushConfigured reports whether push notifications can be delivered.
+func (controller *Controller) pushConfigured() bool {
+	return controller.Options.PushDeliveryConfig.direct() || controller.Options.RelayServerAPIKey != ""
+}
+
+// removeInvalidPushTokens deletes device tokens the push service reported
+// as unregistered or invalid.
+func (controller *Controller) removeInvalidPushTokens(tokens []string) {
+	if len(tokens) == 0 {
+		return
+	}
+	controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("push notification: removing %d invalid token(s) from user accounts (reported UNREGISTERED / invalid)", len(tokens)))
+	for _, invalidToken := range tokens {
+		dt := controller.DeviceTokens.GetByToken(invalidToken)
+		if dt == nil {
+			controller.Logs.LogEvent(LogLevelInfo, "push notification: invalid token not found in index (already removed?)")
+			continue
+		}
+		controller.Logs.LogEvent(LogLevelInfo, fmt.Sprintf("push notification: removing invalid token for user %d", dt.UserId))
+		if err := controller.DeviceTokens.Delete(dt.Id, controller.Database, controller.Clients); err != nil {
+			controller.Logs.LogEvent(LogLevelError, fmt.Sprintf("push notification: failed to remove invalid token for user %d: %v", dt.UserId, err))
+		}
+	}
+}
+
+// pushMessage is one notification for a batch of devices.
+type pushMessage struct {
+	Title    string                 `json:"title"`
+	Subtitle string                 `json:"subtitle,omitempty"`
+	Message  string                 `json:"message"`
+	Sound    string                 `json:"sound,omitempty"`
+	Data     map[string]interface{} `json:"data,omitempty"`
+}
+
+// fcmServiceAccount is the part of a Google service account key FCM needs.
+type fcmServiceAccount struct {
+	ProjectId   string `json:"project_id"`
+	ClientEmail string `json:"client_email"`
+	PrivateKey  string `json:"private_key"`
+	TokenURI    string `json:"token_uri"`
+}
+
+// DirectPush delivers push notifications to FCM v1 and APNs without the
+// relay server. FCM tokens go to FCM, which also reaches iOS devices;
+// PushKit VoIP tokens go to APNs.
+type DirectPush struct {
+	client *http.Client
+
+	mutex          sync.Mutex
+	fcmAccount     string // service account the access token is for
+	fcmAccessToken string
+	fcmExpiry      time.Time
+	apnsKey        string // key the JWT is signed with
+	apnsToken      string
+	apnsIssued     time.Time
+}
+
+func NewDirectPush() *DirectPush {
+	return &DirectPush{client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// Send delivers a message to tokens and returns how many were sent and the
+// tokens the services reported invalid.
+func (push *DirectPush) Send(config PushDeliveryConfig, tokens []string, message *pushMessage) (int, []string, error) {
+	sent, invalid, _, err := push.deliver(config, tokens, message)
+	return sent, invalid, err
+}
+
+// deliver is Send that also returns the tokens that failed for another
+// reason, worth retrying.
+func (push *DirectPush) deliver(config PushDeliveryConfig, tokens []string, message *pushMessage) (int, []string, []string, error) {
+	var (
+		mutex   sync.Mutex
+		sent    int
+		invalid []string
+		failed  []string
+		errs    []string
+		wg      sync.WaitGroup
+	)
+	queue := make(chan string)
+	for i := 0; i < pushDirectWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for token := range queue {
+				var (
+					bad bool
+					err error
+				)
+				if strings.HasPrefix(token, pushVoIPTokenPrefix) {
+					bad, err = push.sendAPNs(config, strings.TrimPrefix(token, pushVoIPTokenPrefix), message)
+				} else {
+					bad, err = push.sendFCM(config, token, message)
+				}
+				mutex.Lock()
+				switch {
+				case bad:
+					invalid = append(invalid, token)
+				case err != nil:
+					failed = append(failed, token)
+					errs = append(errs, err.Error())
+				default:
+					sent++
+				}
+				mutex.Unlock()
+			}
+		}()
+	}
+	for _, token := range tokens {
+		queue <- token
+	}
+	close(queue)
+	wg.Wait()
+
+	if len(errs) > 0 {
+		return sent, invalid, failed, fmt.Errorf("%d failed: %s", len(errs), errs[0])
+	}
+	return sent, invalid, failed, nil
+}
+
+const (
+	// jobKindPush is a persistent job delivering one notification directly
+	// to a batch of devices.
+	jobKindPush = "push"
+
+	pushJobConcurrency = 4
+	pushJobMaxAttempts = 3
+	pushJobRetryDelay  = 10 * time.Second
+
+	// pushJobMaxAge drops notifications too old to be worth delivering, such
+	// as those left behind by a long outage.
+	pushJobMaxAge = 10 * time.Minute
+)
+
+// pushJobPayload is a direct delivery. Tokens shrinks to the failed ones
+// before a retry, so devices already notified are not notified twice.
+type pushJobPayload struct {
+	Platform  string       `json:"platform"`
+	Tokens    []string     `json:"tokens"`
+	Message   *pushMessage `json:"message"`
+	CreatedAt int64        `json:"createdAt"`
+}
+
+// sendDirectPush stores a direct delivery as a persistent job, and falls back
+// to delivering it from memory.
+func (controller *Controller) sendDirectPush(platform string, tokens []string, message *pushMessage) {
+	if jobs := controller.Jobs; jobs != nil {
+		payload := pushJobPayload{Platform: platform, Tokens: tokens, Message: message, CreatedAt: time.Now().UnixMilli()}
+		_, err := jobs.Enqueue(jobKindPush, 0, payload, nil, 0)
+		if err == nil {
+			return
+		}
+		controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("push notification: failed to store the delivery, sending from memory: %v", err))
+	}
+
+	sent, invalid, err := controller.DirectPush.Send(controller.Options.PushDeliveryConfig, tokens, message)
+	controller.removeInvalidPushTokens(invalid)
+	if err != nil {
+		controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("push notification partially failed: %d sent to %s devices, %v", sent, platform, err))
+	} else {
+		controller.Logs.LogEvent(LogLevelInfo, fmt.Sprintf("push notification sent directly to %d %s devices", sent, platform))
+	}
+}
+
+// runPushJob delivers a notification to the devices not reached yet.
+func (controller *Controller) runPushJob(job *Job) error {
+	var payload pushJobPayload
+	if err := json.Unmarshal(job.Payload, &payload); err != nil || payload.Message == nil {
+		return jobPermanent(fmt.Errorf("invalid payload: %v", err))
+	}
+	if time.Since(time.UnixMilli(payload.CreatedAt)) > pushJobMaxAge {
+		return jobPermanent(fmt.Errorf("notification to %d %s device(s) expired before it could be delivered", len(payload.Tokens), payload.Platform))
+	}
+
+	config := controller.Options.PushDeliveryConfig
+	if !config.direct() {
+		return jobPermanent(fmt.Errorf("direct push delivery is no longer enabled"))
+	}
+
+	sent, invalid, failed, err := controller.DirectPush.deliver(config, payload.Tokens, payload.Message)
+	controller.removeInvalidPushTokens(invalid)
+	if err != nil {
+		controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("push notification partially failed: %d sent to %s devices, %v", sent, payload.Platform, err))
+		if len(failed) > 0 && len(failed) < len(payload.Tokens) {
+			payload.Tokens = failed
+			if setErr := controller.Jobs.SetPayload(job, payload); setErr != nil {
+				return jobPermanent(fmt.Errorf("%v, and the failed devices could not be kept for a retry: %v", err, setErr))
+			}
+		}
+		return err
+	}
+
+	controller.Logs.LogEvent(LogLevelInfo, fmt.Sprintf("push notification sent directly to %d %s devices", sent, payload.Platform))
+	return nil
+}
+
+// stringData converts the data of a message to the string map FCM requires.
+func stringData(data map[string]interface{}) map[string]string {
+	out := make(map[string]string, len(data))
+	for k, v := range data {
+		switch v := v.(type) {
+		case string:
+			out[k] = v
+		case nil:
+		default:
+			if b, err := json.Marshal(v); err == nil {
+				out[k] = strings.Trim(string(b), `"`)
+			}
+		}
+	}
+	return out
+}
+
+// fcmToken returns an OAuth access token for the service account, cached
+// until shortly before it expires.
+func (push *DirectPush) fcmToken(config PushDeliveryConfig) (string, *fcmServiceAccount, error) {
+	account := &fcmServiceAccount{}
+	if err := json.Unmarshal([]byte(config.FCMServiceAccount), account); err != nil {
+		return "", nil, fmt.Errorf("invalid FCM service account: %v", err)
+	}
+	if account.ProjectId == "" || account.ClientEmail == "" || account.PrivateKey == "" {
+		return "", nil, fmt.Errorf("the FCM service account needs project_id, client_email and private_key")
+	}
+	if account.TokenURI == "" {
+		account.TokenURI = "https://oauth2.googleapis.com/token"
+	}
+
+	push.mutex.Lock()
+	defer push.mutex.Unlock()
+	if push.fcmAccount == config.FCMServiceAccount && push.fcmAccessToken != "" && time.Now().Before(push.fcmExpiry) {
+		return push.fcmAccessToken, account, nil
+	}
+
+	key, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(account.PrivateKey))
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid FCM private key: %v", err)
+	}
+	assertion, err := signFCMAssertion(account, key, time.Now())
+	if err != nil {
+		return "", nil, err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "urn:ietf:params:oauth:grant-type:jwt-bearer")
+	form.Set("assertion", assertion)
+	resp, err := push.client.PostForm(account.TokenURI, form)
+	if err != nil {
+		return "", nil, err
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return "", nil, fmt.Errorf("FCM token request returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	var token struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.Unmarshal(body, &token); err != nil || token.AccessToken == "" {
+		return "", nil, fmt.Errorf("invalid FCM token response")
+	}
+
+	push.fcmAccount = config.FCMServiceAccount
+	push.fcmAccessToken = token.AccessToken
+	push.fcmExpiry = time.Now().Add(time.Duration(token.ExpiresIn)*time.Second - 5*time.Minute)
+	return push.fcmAccessToken, account, nil
+}
+
+func signFCMAssertion(account *fcmServiceAccount, key *rsa.PrivateKey, now time.Time) (string, error) {
+	claims := jwt.MapClaims{
+		"iss":   account.ClientEmail,
+		"scope": fcmScope,
+		"aud":   account.TokenURI,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodRS256, claims).SignedString(key)
+}
+
+// sendFCM sends one FCM v1 message and reports whether the token is invalid.
+func (push *DirectPush) sendFCM(config PushDeliveryConfig, token string, message *pushMessage) (bool, error) {
+	accessToken, account, err := push.fcmToken(config)
+	if err != nil {
+		return false, err
+	}
+
+	alert := map[string]interface{}{"title": message.Title, "body": message.Message}
+	if message.Subtitle != "" {
+		alert["subtitle"] = message.Subtitle
+	}
+	body := map[string]interface{}{
+		"message": map[string]interface{}{
+			"token":        token,
+			"notification": map[string]interface{}{"title": message.Title, "body": message.Message},
+			"data":         stringData(message.Data),
+			"android": map[string]interface{}{
+				"priority":     "high",
+				"notification": map[string]interface{}{"sound": message.Sound},
+			},
+			"apns": map[string]interface{}{
+				"payload": map[string]interface{}{
+					"aps": map[string]interface{}{"alert": alert, "sound": message.Sound},
+				},
+			},
+		},
+	}
+	b, err := json.Marshal(body)
+	if err != nil {
+		return false, err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf(fcmSendURL, account.ProjectId), bytes.NewReader(b))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+
+	resp, err := push.client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusOK {
+		return false, nil
+	}
+
+	respBody, _ := io.ReadAll(resp.Body)
+	var response struct {
+		Error struct {
+			Status  string `json:"status"`
+			Message string `json:"message"`
+			Details []struct {
+				ErrorCode string `json:"errorCode"`
+			} `json:"details"`
+		} `json:"error"`
+	}
+	json.Unmarshal(respBody, &response)
+	for _, detail := range response.Error.Details {
+		if detail.ErrorCode == "UNREGISTERED" {
+			return true, nil
+		}
+	}
+	if resp.StatusCode == http.StatusNotFound || (resp.StatusCode == http.StatusBadRequest && strings.Contains(strings.ToLower(response.Error.Message), "registration token")) {
+		return true, nil
+	}
+	return false, fmt.Errorf("FCM returned status %d: %s", resp.StatusCode, response.Error.Message)
+}
+
+// apnsJWT returns the provider token for APNs, reused until it nears the
+// hour Apple accepts it for.
+func (push *DirectPush) apnsJWT(config PushDeliveryConfig) (string, error) {
+	if config.APNsKey == "" || config.APNsKeyId == "" || config.APNsTeamId == "" || config.APNsTopic == "" {
+		return "", fmt.Errorf("APNs needs apnsKey, apnsKeyId, apnsTeamId and apnsTopic")
+	}
+
+	push.mutex.Lock()
+	defer push.mutex.Unlock()
+	cacheKey := config.APNsKeyId + config.APNsTeamId + config.APNsKey
+	if push.apnsKey == cacheKey && push.apnsToken != "" && time.Since(push.apnsIssued) < apnsTokenLifetime {
+		return push.apnsToken, nil
+	}
+
+	key, err := jwt.ParseECPrivateKeyFromPEM([]byte(config.APNsKey))
+	if err != nil {
+		return "", fmt.Errorf("invalid APNs key: %v", err)
+	}
+	now := time.Now()
+	token, err := signAPNsToken(config, key, now)
+	if err != nil {
+		return "", err
+	}
+	push.apnsKey, push.apnsToken, push.apnsIssued = cacheKey, token, now
+	return token, nil
+}
+
+func signAPNsToken(config PushDeliveryConfig, key *ecdsa.PrivateKey, now time.Time) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{"iss": config.APNsTeamId, "iat": now.Unix()})
+	token.Header["kid"] = config.APNsKeyId
+	return token.SignedString(key)
+}
+
+// sendAPNs sends one VoIP push over HTTP/2 and reports whether the token is
+// invalid.
+func (push *DirectPush) sendAPNs(config PushDeliveryConfig, token string, message *pushMessage) (bool, error) {
+	jwtToken, err := push.apnsJWT(config)
+	if err != nil {
+		return false, err
+	}
+
+	payload := map[string]interface{}{"aps": map[string]interface{}{}}
+	for k, v := range message.Data {
+		payload[k] = v
+	}
+	payload["title"] = message.Title
+	payload["message"] = message.Message
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return false, err
+	}
+
+	base := apnsProductionURL
+	if config.APNsSandbox {
+		base = apnsSandboxURL
+	}
+	req, err := http.NewRequest(http.MethodPost, base+"/3/device/"+token, bytes.NewReader(b))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("authorization", "bearer "+jwtToken)
+	req.Header.Set("apns-topic", config.APNsTopic+".voip")
+	req.Header.Set("apns-push-type", "voip")
+	req.Header.Set("apns-priority", "10")
+	req.Header.Set("apns-expiration", "0")
+
+	resp, err := push.client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusOK {
+		return false, nil
+	}
+
+	var response struct {
+		Reason string `json:"reason"`
+	}
+	json.NewDecoder(resp.Body).Decode(&response)
+	switch {
+	case resp.StatusCode == http.StatusGone,
+		response.Reason == "BadDeviceToken",
+		response.Reason == "DeviceTokenNotForTopic",
+		response.Reason == "Unregistered":
+		return true, nil
+	}
+	return false, fmt.Errorf("APNs returned status %d: %s", resp.StatusCode, response.Reason)
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDirectPushFCM(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaKey)})
+
+	tokenRequests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/token":
+			tokenRequests++
+			if r.FormValue("grant_type") != "urn:ietf:params:oauth:grant-type:jwt-bearer" || r.FormValue("assertion") == "" {
+				t.Errorf("token request = %v", r.Form)
+			}
+			w.Write([]byte(`{"access_token":"access","expires_in":3600}`))
+		case r.URL.Path == "/v1/projects/radio/messages:send":
+			if r.Header.Get("Authorization") != "Bearer access" {
+				t.Errorf("Authorization = %q", r.Header.Get("Authorization"))
+			}
+			var body struct {
+				Message struct {
+					Token string            `json:"token"`
+					Data  map[string]string `json:"data"`
+				} `json:"message"`
+			}
+			json.NewDecoder(r.Body).Decode(&body)
+			if body.Message.Data["callId"] != "42" || body.Message.Data["count"] != "3" {
+				t.Errorf("data = %v", body.Message.Data)
+			}
+			if body.Message.Token == "busy" {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				w.Write([]byte(`{"error":{"status":"UNAVAILABLE","message":"try again"}}`))
+				return
+			}
+			if body.Message.Token == "gone" {
+				w.WriteHeader(http.StatusNotFound)
+				w.Write([]byte(`{"error":{"status":"NOT_FOUND","message":"Requested entity was not found.","details":[{"errorCode":"UNREGISTERED"}]}}`))
+				return
+			}
+			w.Write([]byte(`{"name":"projects/radio/messages/1"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	previous := fcmSendURL
+	fcmSendURL = server.URL + "/v1/projects/%s/messages:send"
+	defer func() { fcmSendURL = previous }()
+
+	account, _ := json.Marshal(map[string]string{"project_id": "radio", "client_email": "push@radio.iam.gserviceaccount.com", "private_key": string(keyPEM), "token_uri": server.URL + "/token"})
+	config := PushDeliveryConfig{Mode: "direct", FCMServiceAccount: string(account)}
+
+	push := NewDirectPush()
+	message := &pushMessage{Title: "Tone Alert", Message: "Station 5", Data: map[string]interface{}{"callId": "42", "count": 3}}
+	sent, invalid, err := push.Send(config, []string{"ok-1", "gone", "ok-2"}, message)
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if sent != 2 || len(invalid) != 1 || invalid[0] != "gone" {
+		t.Fatalf("sent = %d, invalid = %v", sent, invalid)
+	}
+	if tokenRequests != 1 {
+		t.Fatalf("access token requested %d times, want 1", tokenRequests)
+	}
+
+	sent, _, failed, err := push.deliver(config, []string{"ok-1", "busy"}, message)
+	if err == nil || sent != 1 || len(failed) != 1 || failed[0] != "busy" {
+		t.Fatalf("sent = %d, failed = %v, err = %v", sent, failed, err)
+	}
+}
+
+func TestRunPushJobExpired(t *testing.T) {
+	payload, _ := json.Marshal(pushJobPayload{Platform: "android", Tokens: []string{"ok-1"}, Message: &pushMessage{Title: "Tone Alert"}, CreatedAt: time.Now().Add(-time.Hour).UnixMilli()})
+
+	var permanent permanentJobError
+	if err := (&Controller{}).runPushJob(&Job{Kind: jobKindPush, Payload: payload}); !errors.As(err, &permanent) {
+		t.Fatalf("expected a stale notification to be dropped, got %v", err)
+	}
+}
+
+func TestDirectPushAPNs(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(ecKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("apns-topic") != "com.example.radio.voip" || r.Header.Get("apns-push-type") != "voip" || !strings.HasPrefix(r.Header.Get("authorization"), "bearer ") {
+			t.Errorf("headers = %v", r.Header)
+		}
+		switch r.URL.Path {
+		case "/3/device/abc123":
+			w.WriteHeader(http.StatusOK)
+		case "/3/device/expired":
+			w.WriteHeader(http.StatusGone)
+			w.Write([]byte(`{"reason":"Unregistered"}`))
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"reason":"BadDeviceToken"}`))
+		}
+	}))
+	defer server.Close()
+
+	previous := apnsSandboxURL
+	apnsSandboxURL = server.URL
+	defer func() { apnsSandboxURL = previous }()
+
+	config := PushDeliveryConfig{Mode: "direct", APNsKey: string(keyPEM), APNsKeyId: "KEY123", APNsTeamId: "TEAM123", APNsTopic: "com.example.radio", APNsSandbox: true}
+	sent, invalid, err := NewDirectPush().Send(config, []string{"voip:abc123", "voip:expired", "voip:bad"}, &pushMessage{Title: "Tone Alert"})
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if sent != 1 || len(invalid) != 2 {
+		t.Fatalf("sent = %d, invalid = %v", sent, invalid)
+	}
+
+	if _, _, err := NewDirectPush().Send(PushDeliveryConfig{Mode: "direct"}, []string{"voip:abc123"}, &pushMessage{}); err == nil {
+		t.Fatalf("expected an error without APNs credentials")
+	}
+}
@@ -68,8 +68,8 @@ func (controller *Controller) handleLegacyOneSignalToken(dt *DeviceToken, notifi
 
 // sendPushNotification sends a push notification to the relay server
 func (controller *Controller) sendPushNotification(userId uint64, alertType string, call *Call, systemLabel, talkgroupLabel string, toneSetName string, keywords []string) {
-	// Check if relay server API key is configured (URL is hardcoded)
-	if controller.Options.RelayServerAPIKey == "" {
+	// Check if the relay server API key (URL is hardcoded) or direct delivery is configured
+	if !controller.pushConfigured() {
 		return // Push notifications not configured
 	}
 
@@ -79,6 +79,11 @@ func (controller *Controller) sendPushNotification(userId uint64, alertType stri
 		return
 	}
 
+	// Alerts are held during the user's quiet hours; test pushes have no call
+	if call != nil && controller.inQuietHours(userId, call, time.Now()) {
+		return
+	}
+
 	// Note: Group suspension check removed as Suspended field was not added to UserGroup
 	// If needed, can be added later
 
@@ -152,54 +157,17 @@ func (controller *Controller) sendPushNotification(userId uint64, alertType stri
 		title = baseTitle
 	}
 
-	// Message: use summary if available and not generic "RADIO TRAFFIC", otherwise use transcript
-	message := ""
-	if call != nil && call.Transcript != "" {
-		message = strings.ToUpper(call.Transcript)
-	} else {
-		// Fallback to alert type info if no transcript
-		if alertType == "pre-alert" {
-			// Pre-alert: Tones detected, waiting for voice
-			currentTime := time.Now().Format("3:04 PM")
-			if toneSetName != "" {
-				message = fmt.Sprintf("%s Tones Detected @ %s", strings.ToUpper(toneSetName), currentTime)
-			} else {
-				message = fmt.Sprintf("Tones Detected @ %s", currentTime)
-			}
-		} else if alertType == "tone" {
-			if len(keywords) > 0 {
-				// Tone alert with keywords - include keyword info
-				keywordText := strings.ToUpper(keywords[0])
-				if toneSetName != "" {
-					message = fmt.Sprintf("%s + KEYWORD: %s", strings.ToUpper(toneSetName), keywordText)
-				} else {
-					message = fmt.Sprintf("TONE + KEYWORD: %s", keywordText)
-				}
-			} else {
-				// Tone alert without keywords
-				if toneSetName != "" {
-					message = fmt.Sprintf("%s DETECTED", strings.ToUpper(toneSetName))
-				} else {
-					message = "TONE ALERT"
-				}
-			}
-		} else if alertType == "keyword" {
-			if len(keywords) > 0 {
-				message = fmt.Sprintf("KEYWORD MATCH: %s", strings.ToUpper(keywords[0]))
-			} else {
-				message = "KEYWORD ALERT"
-			}
-		} else if alertType == "tone+keyword" {
-			keywordText := ""
-			if len(keywords) > 0 {
-				keywordText = strings.ToUpper(keywords[0])
-			}
-			if toneSetName != "" {
-				message = fmt.Sprintf("%s + KEYWORD: %s", strings.ToUpper(toneSetName), keywordText)
-			} else {
-				message = fmt.Sprintf("TONE + KEYWORD: %s", keywordText)
-			}
-		}
+	// Message: use summary if available and not generic "RADIO TRAFFIC", otherwise use transcript.
+	// Users who opted out of transcripts in their settings only get the alert type info.
+	fallback := alertFallbackMessage(alertType, toneSetName, keywords)
+	message := notificationCallText(call)
+	if message == "" {
+		message = fallback
+	}
+	var privacyExtra map[string]interface{}
+	if !userSettingBool(user, "pushTranscripts", true) {
+		message = privateAlertMessage(fallback)
+		privacyExtra = map[string]interface{}{"transcriptHidden": "true"}
 	}
 
 	// Resolve per-channel notification sound and pager-alert preference for this user+talkgroup.
@@ -281,6 +249,9 @@ func (controller *Controller) sendPushNotification(userId uint64, alertType stri
 	// Build per-call extra data. pager_alert is only set when the user has the
 	// feature enabled AND the device doesn't have live feed active.
 	pagerExtra := map[string]interface{}{"pager_alert": "true"}
+	for k, v := range privacyExtra {
+		pagerExtra[k] = v
+	}
 
 	// Send to Android devices — split into pager and non-pager based on live feed.
 	if len(androidDevices) > 0 {
@@ -301,17 +272,17 @@ func (controller *Controller) sendPushNotification(userId uint64, alertType stri
 			}
 			if len(normalAndroid) > 0 {
 				go func(ids []string, sound string) {
-					controller.sendNotificationBatch(ids, title, "", message, "android", sound, call, systemLabel, talkgroupLabel, nil)
+					controller.sendNotificationBatch(ids, title, "", message, "android", sound, call, systemLabel, talkgroupLabel, privacyExtra)
 				}(normalAndroid, androidSound)
 			}
 		} else if userPagerEnabled {
 			// Pager enabled but already sent for this call — regular push only.
 			go func(ids []string, sound string) {
-				controller.sendNotificationBatch(ids, title, "", message, "android", sound, call, systemLabel, talkgroupLabel, nil)
+				controller.sendNotificationBatch(ids, title, "", message, "android", sound, call, systemLabel, talkgroupLabel, privacyExtra)
 			}(androidDevices, androidSound)
 		} else {
 			go func(ids []string, sound string) {
-				controller.sendNotificationBatch(ids, title, "", message, "android", sound, call, systemLabel, talkgroupLabel, nil)
+				controller.sendNotificationBatch(ids, title, "", message, "android", sound, call, systemLabel, talkgroupLabel, privacyExtra)
 			}(androidDevices, androidSound)
 		}
 	}
@@ -330,7 +301,7 @@ func (controller *Controller) sendPushNotification(userId uint64, alertType stri
 			controller.Logs.LogEvent(LogLevelInfo, "push notification: iOS pager enabled — suppressing FCM notification sound (CallKit rings instead)")
 		}
 		controller.Logs.LogEvent(LogLevelInfo, fmt.Sprintf("push notification: iOS final sound: %s", iosSoundStripped))
-		var iosExtra map[string]interface{}
+		iosExtra := privacyExtra
 		if pagerClaimed {
 			iosExtra = pagerExtra
 		}
@@ -340,8 +311,104 @@ func (controller *Controller) sendPushNotification(userId uint64, alertType stri
 	}
 }
 
+// pushTranscriptExcerptLength caps the transcript excerpt sent in push payloads,
+// keeping the payload well under the FCM and APNs 4KB limit.
+const pushTranscriptExcerptLength = 200
+
+// alertFallbackMessage is the notification message used when a call has no
+// summary or transcript, or when the user does not want transcripts in pushes.
+func alertFallbackMessage(alertType, toneSetName string, keywords []string) string {
+	keywordText := ""
+	if len(keywords) > 0 {
+		keywordText = strings.ToUpper(keywords[0])
+	}
+
+	switch alertType {
+	case "pre-alert":
+		// Pre-alert: Tones detected, waiting for voice
+		currentTime := time.Now().Format("3:04 PM")
+		if toneSetName != "" {
+			return fmt.Sprintf("%s Tones Detected @ %s", strings.ToUpper(toneSetName), currentTime)
+		}
+		return fmt.Sprintf("Tones Detected @ %s", currentTime)
+	case "tone":
+		if keywordText != "" {
+			// Tone alert with keywords - include keyword info
+			if toneSetName != "" {
+				return fmt.Sprintf("%s + KEYWORD: %s", strings.ToUpper(toneSetName), keywordText)
+			}
+			return fmt.Sprintf("TONE + KEYWORD: %s", keywordText)
+		}
+		if toneSetName != "" {
+			return fmt.Sprintf("%s DETECTED", strings.ToUpper(toneSetName))
+		}
+		return "TONE ALERT"
+	case "keyword":
+		if keywordText != "" {
+			return fmt.Sprintf("KEYWORD MATCH: %s", keywordText)
+		}
+		return "KEYWORD ALERT"
+	case "activity":
+		return "UNUSUALLY HIGH ACTIVITY"
+	case "escalation":
+		if toneSetName != "" {
+			return fmt.Sprintf("UNACKNOWLEDGED: %s", strings.ToUpper(toneSetName))
+		} else if keywordText != "" {
+			return fmt.Sprintf("UNACKNOWLEDGED KEYWORD: %s", keywordText)
+		}
+		return "UNACKNOWLEDGED ALERT"
+	case "tone+keyword":
+		if toneSetName != "" {
+			return fmt.Sprintf("%s + KEYWORD: %s", strings.ToUpper(toneSetName), keywordText)
+		}
+		return fmt.Sprintf("TONE + KEYWORD: %s", keywordText)
+	}
+	return ""
+}
+
+// privateAlertMessage is the message sent to users who turned off transcripts,
+// which never falls back to the call text.
+func privateAlertMessage(fallback string) string {
+	if fallback == "" {
+		return "NEW CALL"
+	}
+	return fallback
+}
+
+// transcriptExcerpt shortens a transcript to at most limit characters, cutting
+// at a word boundary when possible.
+func transcriptExcerpt(transcript string, limit int) string {
+	transcript = strings.Join(strings.Fields(transcript), " ")
+	runes := []rune(transcript)
+	if len(runes) <= limit {
+		return transcript
+	}
+	excerpt := string(runes[:limit])
+	if i := strings.LastIndex(excerpt, " "); i > limit/2 {
+		excerpt = excerpt[:i]
+	}
+	return strings.TrimRight(excerpt, " ,.;:") + "…"
+}
+
+// userSettingBool reads a boolean from the user's settings JSON, as saved by
+// the apps through /api/settings.
+func userSettingBool(user *User, key string, fallback bool) bool {
+	if user == nil || user.Settings == "" {
+		return fallback
+	}
+	var userSettings map[string]interface{}
+	if err := json.Unmarshal([]byte(user.Settings), &userSettings); err != nil {
+		return fallback
+	}
+	if v, ok := userSettings[key].(bool); ok {
+		return v
+	}
+	return fallback
+}
+
 func (controller *Controller) sendNotificationBatch(playerIDs []string, title, subtitle, message, platform, sound string, call *Call, systemLabel, talkgroupLabel string, extraData map[string]interface{}) {
-	if controller.RelayPushSuspended() {
+	config := controller.Options.PushDeliveryConfig
+	if !config.direct() && controller.RelayPushSuspended() {
 		return
 	}
 	controller.Logs.LogEvent(LogLevelInfo, fmt.Sprintf("push notification: sendNotificationBatch called with %d player ID(s) for %s platform", len(playerIDs), platform))
@@ -381,6 +448,43 @@ func (controller *Controller) sendNotificationBatch(playerIDs []string, title, s
 		if controller.Options.BaseUrl != "" {
 			data["scanner_url"] = controller.Options.BaseUrl
 		}
+		// Everything the app needs to play the call and open it without another
+		// round trip: the audio URL, talkgroup metadata and deep-link route.
+		if audioUrl := controller.callAudioURL(call.Id); audioUrl != "" {
+			data["audioUrl"] = audioUrl
+		}
+		if call.System != nil {
+			data["systemRef"] = fmt.Sprintf("%d", call.System.SystemRef)
+		}
+		if call.Talkgroup != nil {
+			data["talkgroupRef"] = fmt.Sprintf("%d", call.Talkgroup.TalkgroupRef)
+			if call.Talkgroup.Name != "" {
+				data["talkgroupName"] = call.Talkgroup.Name
+			}
+			if tag, ok := controller.Tags.GetTagById(call.Talkgroup.TagId); ok {
+				data["talkgroupTag"] = tag.Label
+			}
+		}
+		data["route"] = "call"
+		data["routeId"] = fmt.Sprintf("%d", call.Id)
+		// The excerpt is left out for users who turned off transcripts
+		if data["transcriptHidden"] != "true" {
+			if excerpt := transcriptExcerpt(call.Transcript, pushTranscriptExcerptLength); excerpt != "" {
+				data["transcriptExcerpt"] = excerpt
+			}
+		}
+		// Fields extracted by the summarizer, as strings like the rest of the data
+		if fields := call.SummaryFields; fields != nil {
+			if fields.Address != "" {
+				data["address"] = fields.Address
+			}
+			if fields.IncidentType != "" {
+				data["incidentType"] = fields.IncidentType
+			}
+			if len(fields.Units) > 0 {
+				data["units"] = strings.Join(fields.Units, ",")
+			}
+		}
 	}
 
 	if systemLabel != "" {
@@ -390,6 +494,12 @@ func (controller *Controller) sendNotificationBatch(playerIDs []string, title, s
 		data["talkgroupLabel"] = talkgroupLabel
 	}
 
+	// Direct delivery skips the relay server
+	if config.direct() {
+		controller.sendDirectPush(platform, playerIDs, &pushMessage{Title: title, Subtitle: subtitle, Message: message, Sound: sound, Data: data})
+		return
+	}
+
 	// Build request payload
 	payload := map[string]interface{}{
 		"player_ids": playerIDs,
@@ -466,20 +576,7 @@ func (controller *Controller) sendNotificationBatch(playerIDs []string, title, s
 
 	// Handle invalid FCM tokens — relay server reports tokens it could not deliver to.
 	// O(1) per token via tokenIndex; no need to scan all users.
-	if len(response.InvalidPlayerIDs) > 0 {
-		controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("push notification: removing %d invalid FCM token(s) from user accounts (relay reported UNREGISTERED / invalid)", len(response.InvalidPlayerIDs)))
-		for _, invalidToken := range response.InvalidPlayerIDs {
-			dt := controller.DeviceTokens.GetByToken(invalidToken)
-			if dt == nil {
-				controller.Logs.LogEvent(LogLevelInfo, "push notification: invalid FCM token not found in index (already removed?)")
-				continue
-			}
-			controller.Logs.LogEvent(LogLevelInfo, fmt.Sprintf("push notification: removing invalid FCM token for user %d", dt.UserId))
-			if err := controller.DeviceTokens.Delete(dt.Id, controller.Database, controller.Clients); err != nil {
-				controller.Logs.LogEvent(LogLevelError, fmt.Sprintf("push notification: failed to remove invalid FCM token for user %d: %v", dt.UserId, err))
-			}
-		}
-	}
+	controller.removeInvalidPushTokens(response.InvalidPlayerIDs)
 
 	if resp.StatusCode != http.StatusOK {
 		controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("push notification failed (status %d): %s - this failure does not affect other batches", resp.StatusCode, response.Error))
@@ -497,7 +594,7 @@ func (controller *Controller) sendNotificationBatch(playerIDs []string, title, s
 // sendDisconnectPushNotification sends a push notification to a user's devices
 // when their WebSocket connection to the TLR server is dropped.
 func (controller *Controller) sendDisconnectPushNotification(user *User) {
-	if controller.Options.RelayServerAPIKey == "" {
+	if !controller.pushConfigured() {
 		return
 	}
 
@@ -585,7 +682,7 @@ func (controller *Controller) sendDisconnectPushNotification(user *User) {
 // sendDisconnectPushNotificationToDevice sends a disconnect notification to a
 // single device identified by its FCM token, rather than all devices on the account.
 func (controller *Controller) sendDisconnectPushNotificationToDevice(user *User, fcmToken string) {
-	if controller.Options.RelayServerAPIKey == "" || fcmToken == "" {
+	if !controller.pushConfigured() || fcmToken == "" {
 		return
 	}
 
@@ -705,9 +802,10 @@ func (controller *Controller) sendBatchedPushNotification(userIds []uint64, aler
 // sendBatchedPushNotificationWithToneSet is the full implementation that accepts a toneSetId
 // so per-tone-set notification sounds can be resolved from each user's alert preferences.
 func (controller *Controller) sendBatchedPushNotificationWithToneSet(userIds []uint64, alertType string, call *Call, systemLabel, talkgroupLabel string, toneSetName string, toneSetId string, keywords []string) {
-	// Check if relay server API key is configured (URL is hardcoded)
-	if controller.Options.RelayServerAPIKey == "" {
-		return // Push notifications not configured
+	// Users in their quiet hours get neither email nor push
+	userIds = controller.withoutQuietHours(userIds, call)
+	if len(userIds) == 0 {
+		return
 	}
 
 	// Build notification title and message (same for all users)
@@ -729,54 +827,22 @@ func (controller *Controller) sendBatchedPushNotificationWithToneSet(userIds []u
 		title = baseTitle
 	}
 
-	// Message: use summary if available and not generic "RADIO TRAFFIC", otherwise use transcript
-	message := ""
-	if call != nil && call.Transcript != "" {
-		message = strings.ToUpper(call.Transcript)
-	} else {
-		// Fallback to alert type info if no transcript
-		if alertType == "pre-alert" {
-			// Pre-alert: Tones detected, waiting for voice
-			currentTime := time.Now().Format("3:04 PM")
-			if toneSetName != "" {
-				message = fmt.Sprintf("%s Tones Detected @ %s", strings.ToUpper(toneSetName), currentTime)
-			} else {
-				message = fmt.Sprintf("Tones Detected @ %s", currentTime)
-			}
-		} else if alertType == "tone" {
-			if len(keywords) > 0 {
-				// Tone alert with keywords - include keyword info
-				keywordText := strings.ToUpper(keywords[0])
-				if toneSetName != "" {
-					message = fmt.Sprintf("%s + KEYWORD: %s", strings.ToUpper(toneSetName), keywordText)
-				} else {
-					message = fmt.Sprintf("TONE + KEYWORD: %s", keywordText)
-				}
-			} else {
-				// Tone alert without keywords
-				if toneSetName != "" {
-					message = fmt.Sprintf("%s DETECTED", strings.ToUpper(toneSetName))
-				} else {
-					message = "TONE ALERT"
-				}
-			}
-		} else if alertType == "keyword" {
-			if len(keywords) > 0 {
-				message = fmt.Sprintf("KEYWORD MATCH: %s", strings.ToUpper(keywords[0]))
-			} else {
-				message = "KEYWORD ALERT"
-			}
-		} else if alertType == "tone+keyword" {
-			keywordText := ""
-			if len(keywords) > 0 {
-				keywordText = strings.ToUpper(keywords[0])
-			}
-			if toneSetName != "" {
-				message = fmt.Sprintf("%s + KEYWORD: %s", strings.ToUpper(toneSetName), keywordText)
-			} else {
-				message = fmt.Sprintf("TONE + KEYWORD: %s", keywordText)
-			}
-		}
+	// Message: use summary if available and not generic "RADIO TRAFFIC", otherwise use transcript.
+	// Users who opted out of transcripts in their settings only get the alert type info.
+	fallback := alertFallbackMessage(alertType, toneSetName, keywords)
+	message := notificationCallText(call)
+	if message == "" {
+		message = fallback
+	}
+
+	// Email goes out even when push notifications are not configured
+	if controller.EmailAlerts != nil {
+		controller.EmailAlerts.Notify(userIds, alertType, call, title, message, toneSetId)
+	}
+
+	// Check if the relay server API key (URL is hardcoded) or direct delivery is configured
+	if !controller.pushConfigured() {
+		return // Push notifications not configured
 	}
 
 	// Collect all device tokens from all users, grouped by platform and sound.
@@ -863,6 +929,12 @@ func (controller *Controller) sendBatchedPushNotificationWithToneSet(userIds []u
 		// Pre-alerts are just a heads-up — don't trigger VoIP/CallKit for them.
 		userPagerEnabled := call != nil && alertType != "pre-alert" && controller.resolveUserPagerAlert(userId, systemId, talkgroupId, toneSetId)
 		pagerClaimed := userPagerEnabled && call != nil && controller.claimPagerAlert(userId, call.Id)
+		// Users who turned off transcripts in push notifications are batched
+		// separately so their payloads carry the alert type info only.
+		privacySuffix := ""
+		if !userSettingBool(user, "pushTranscripts", true) {
+			privacySuffix = "+private"
+		}
 
 		// Group devices by platform and sound; delete any legacy OneSignal tokens.
 		// VoIP tokens go into the same ios+pager:{sound} group as this user's iOS
@@ -897,7 +969,7 @@ func (controller *Controller) sendBatchedPushNotificationWithToneSet(userIds []u
 					if iosLiveFeedActive {
 						controller.Logs.LogEvent(LogLevelInfo, fmt.Sprintf("push notification (batched): skipping VoIP for user %d — iOS live feed active", userId))
 					} else {
-						key := fmt.Sprintf("ios+pager:%s", sound)
+						key := fmt.Sprintf("ios+pager%s:%s", privacySuffix, sound)
 						deviceGroups[key] = append(deviceGroups[key], device.FCMToken)
 					}
 				}
@@ -918,7 +990,7 @@ func (controller *Controller) sendBatchedPushNotificationWithToneSet(userIds []u
 					platformKey = device.Platform + "+pager"
 				}
 			}
-			key := fmt.Sprintf("%s:%s", platformKey, sound)
+			key := fmt.Sprintf("%s%s:%s", platformKey, privacySuffix, sound)
 			deviceGroups[key] = append(deviceGroups[key], device.FCMToken)
 		}
 	}
@@ -941,14 +1013,23 @@ func (controller *Controller) sendBatchedPushNotificationWithToneSet(userIds []u
 		sound := parts[1]
 
 		var batchExtra map[string]interface{}
+		batchMessage := message
+		if strings.HasSuffix(platform, "+private") {
+			platform = strings.TrimSuffix(platform, "+private")
+			batchMessage = privateAlertMessage(fallback)
+			batchExtra = map[string]interface{}{
+				"transcriptHidden": "true",
+			}
+		}
 		if strings.HasSuffix(platform, "+pager") {
 			platform = strings.TrimSuffix(platform, "+pager")
-			batchExtra = map[string]interface{}{
-				"pager_alert": "true",
+			if batchExtra == nil {
+				batchExtra = map[string]interface{}{}
 			}
+			batchExtra["pager_alert"] = "true"
 		}
 
-		controller.Logs.LogEvent(LogLevelInfo, fmt.Sprintf("push notification (batched): sending batch with %d player ID(s) for %s platform, sound: %s, pagerAlertInPayload: %v", len(playerIDs), platform, sound, batchExtra != nil))
+		controller.Logs.LogEvent(LogLevelInfo, fmt.Sprintf("push notification (batched): sending batch with %d player ID(s) for %s platform, sound: %s, pagerAlertInPayload: %v", len(playerIDs), platform, sound, batchExtra["pager_alert"] != nil))
 
 		// iOS requires sound name without extension (e.g., "startup" not "startup.wav")
 		finalSound := sound
@@ -961,12 +1042,41 @@ func (controller *Controller) sendBatchedPushNotificationWithToneSet(userIds []u
 		// Send each platform/sound batch independently so failures don't affect others.
 		// Stagger batches slightly to avoid relay-server rate limiting.
 		delay := time.Duration(batchIndex) * 200 * time.Millisecond
-		go func(ids []string, plat string, snd string, extra map[string]interface{}, d time.Duration) {
+		go func(ids []string, msg string, plat string, snd string, extra map[string]interface{}, d time.Duration) {
 			if d > 0 {
 				time.Sleep(d)
 			}
-			controller.sendNotificationBatch(ids, title, "", message, plat, snd, call, systemLabel, talkgroupLabel, extra)
-		}(playerIDs, platform, finalSound, batchExtra, delay)
+			controller.sendNotificationBatch(ids, title, "", msg, plat, snd, call, systemLabel, talkgroupLabel, extra)
+		}(playerIDs, batchMessage, platform, finalSound, batchExtra, delay)
 		batchIndex++
 	}
 }
+
+// sendUserPushNotification pushes a plain notification, not tied to a call,
+// to every device of the user. extra is added to the payload data so apps can
+// open the right screen.
+func (controller *Controller) sendUserPushNotification(userId uint64, title, message string, extra map[string]interface{}) {
+	if !controller.pushConfigured() {
+		return
+	}
+
+	byPlatform := make(map[string][]string)
+	for _, device := range controller.DeviceTokens.GetByUser(userId) {
+		if isLegacyOneSignalToken(device) || device.FCMToken == "" {
+			continue
+		}
+		platform := device.Platform
+		if platform == "" {
+			platform = "android"
+		}
+		byPlatform[platform] = append(byPlatform[platform], device.FCMToken)
+	}
+
+	for platform, ids := range byPlatform {
+		sound := "startup.wav"
+		if platform == "ios" {
+			sound = "startup"
+		}
+		controller.sendNotificationBatch(ids, title, "", message, platform, sound, nil, "", "", extra)
+	}
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAlertFallbackMessage(t *testing.T) {
+	tests := []struct {
+		alertType   string
+		toneSetName string
+		keywords    []string
+		want        string
+	}{
+		{"tone", "Station 1", nil, "STATION 1 DETECTED"},
+		{"tone", "", []string{"fire"}, "TONE + KEYWORD: FIRE"},
+		{"keyword", "", []string{"structure fire"}, "KEYWORD MATCH: STRUCTURE FIRE"},
+		{"keyword", "", nil, "KEYWORD ALERT"},
+		{"escalation", "", []string{"mayday"}, "UNACKNOWLEDGED KEYWORD: MAYDAY"},
+		{"activity", "", nil, "UNUSUALLY HIGH ACTIVITY"},
+		{"unknown", "", nil, ""},
+	}
+	for _, test := range tests {
+		if got := alertFallbackMessage(test.alertType, test.toneSetName, test.keywords); got != test.want {
+			t.Fatalf("alertFallbackMessage(%q, %q, %v) = %q, want %q", test.alertType, test.toneSetName, test.keywords, got, test.want)
+		}
+	}
+	if got := alertFallbackMessage("pre-alert", "Station 1", nil); !strings.HasPrefix(got, "STATION 1 Tones Detected @ ") {
+		t.Fatalf("unexpected pre-alert message %q", got)
+	}
+	if got := privateAlertMessage(""); got != "NEW CALL" {
+		t.Fatalf("expected NEW CALL, got %q", got)
+	}
+}
+
+func TestTranscriptExcerpt(t *testing.T) {
+	if got := transcriptExcerpt("  engine 5   respond  ", 200); got != "engine 5 respond" {
+		t.Fatalf("expected whitespace collapsed, got %q", got)
+	}
+	transcript := "engine five respond to a structure fire at one two three main street, cross of elm"
+	got := transcriptExcerpt(transcript, 40)
+	if got != "engine five respond to a structure fire…" {
+		t.Fatalf("expected cut at a word boundary, got %q", got)
+	}
+	if got := transcriptExcerpt(strings.Repeat("x", 50), 10); got != strings.Repeat("x", 10)+"…" {
+		t.Fatalf("expected hard cut without spaces, got %q", got)
+	}
+}
+
+func TestUserSettingBool(t *testing.T) {
+	if !userSettingBool(nil, "pushTranscripts", true) {
+		t.Fatalf("expected fallback for a nil user")
+	}
+	user := &User{Settings: `{"pushTranscripts":false,"disconnectAlertSound":"chime.wav"}`}
+	if userSettingBool(user, "pushTranscripts", true) {
+		t.Fatalf("expected the saved setting")
+	}
+	if !userSettingBool(user, "disconnectAlertSound", true) {
+		t.Fatalf("expected fallback for a non-boolean setting")
+	}
+	if !userSettingBool(&User{Settings: "not json"}, "pushTranscripts", true) {
+		t.Fatalf("expected fallback for invalid settings")
+	}
+}
@@ -0,0 +1,96 @@
+// Copyright (C) 2026 Thinline Dynamic Solutions
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>
+
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// queryArgs collects the arguments of a parameterized query. add returns the
+// placeholder for a value, so values are never spliced into the SQL:
+//
+//	args := queryArgs{}
+//	query := `SELECT COUNT(*) FROM "calls" WHERE "systemId" = ` + args.add(systemId)
+//	db.QueryRow(query, args...)
+//
+// The driver caches the prepared statement of each distinct query text, which
+// queries with spliced values defeat. Only identifiers, like table and column
+// names, are ever formatted into a query.
+type queryArgs []any
+
+// add appends the value and returns its placeholder.
+func (args *queryArgs) add(value any) string {
+	*args = append(*args, value)
+	return fmt.Sprintf("$%d", len(*args))
+}
+
+// in appends the ids and returns an IN list of their placeholders, as
+// "($1, $2)". An empty list matches nothing.
+func (args *queryArgs) in(ids []uint64) string {
+	if len(ids) == 0 {
+		return "(NULL)"
+	}
+	placeholders := make([]string, len(ids))
+	for i, id := range ids {
+		placeholders[i] = args.add(id)
+	}
+	return "(" + strings.Join(placeholders, ", ") + ")"
+}
+
+// queryIds converts refs and ids kept as uint for queryArgs.in.
+func queryIds[T uint | uint64](ids []T) []uint64 {
+	values := make([]uint64, len(ids))
+	for i, id := range ids {
+		values[i] = uint64(id)
+	}
+	return values
+}
+
+// queryColumns pairs column names with their values for inserts and updates
+// of many columns, so the placeholders always line up with the values.
+type queryColumns struct {
+	names  []string
+	values []any
+}
+
+func (columns *queryColumns) set(name string, value any) {
+	columns.names = append(columns.names, name)
+	columns.values = append(columns.values, value)
+}
+
+// insert returns the INSERT statement and its arguments.
+func (columns *queryColumns) insert(table string) (string, []any) {
+	args := queryArgs{}
+	names := make([]string, len(columns.names))
+	placeholders := make([]string, len(columns.names))
+	for i, name := range columns.names {
+		names[i] = fmt.Sprintf("%q", name)
+		placeholders[i] = args.add(columns.values[i])
+	}
+	return fmt.Sprintf(`INSERT INTO %q (%s) VALUES (%s)`, table, strings.Join(names, ", "), strings.Join(placeholders, ", ")), args
+}
+
+// update returns the UPDATE statement of the row whose key column equals
+// key, and its arguments.
+func (columns *queryColumns) update(table string, keyColumn string, key any) (string, []any) {
+	args := queryArgs{}
+	assignments := make([]string, len(columns.names))
+	for i, name := range columns.names {
+		assignments[i] = fmt.Sprintf("%q = %s", name, args.add(columns.values[i]))
+	}
+	return fmt.Sprintf(`UPDATE %q SET %s WHERE %q = %s`, table, strings.Join(assignments, ", "), keyColumn, args.add(key)), args
+}
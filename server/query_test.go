@@ -0,0 +1,97 @@
+package main
+
+import (
+	"database/sql/driver"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestQueryArgs(t *testing.T) {
+	args := queryArgs{}
+	query := `SELECT 1 WHERE "a" = ` + args.add("x'; DROP TABLE calls; --") + ` AND "b" IN ` + args.in([]uint64{3, 4})
+	if query != `SELECT 1 WHERE "a" = $1 AND "b" IN ($2, $3)` {
+		t.Fatalf("unexpected query %s", query)
+	}
+	if !reflect.DeepEqual([]any(args), []any{"x'; DROP TABLE calls; --", uint64(3), uint64(4)}) {
+		t.Fatalf("unexpected args %v", args)
+	}
+
+	if in := (&queryArgs{}).in(nil); in != "(NULL)" {
+		t.Fatalf("empty list should match nothing, got %s", in)
+	}
+}
+
+func TestQueryColumns(t *testing.T) {
+	columns := queryColumns{}
+	columns.set("label", "O'Brien")
+	columns.set("order", 2)
+
+	query, args := columns.insert("talkgroups")
+	if query != `INSERT INTO "talkgroups" ("label", "order") VALUES ($1, $2)` {
+		t.Fatalf("unexpected insert %s", query)
+	}
+	if !reflect.DeepEqual(args, []any{"O'Brien", 2}) {
+		t.Fatalf("unexpected insert args %v", args)
+	}
+
+	query, args = columns.update("talkgroups", "talkgroupId", uint64(7))
+	if query != `UPDATE "talkgroups" SET "label" = $1, "order" = $2 WHERE "talkgroupId" = $3` {
+		t.Fatalf("unexpected update %s", query)
+	}
+	if !reflect.DeepEqual(args, []any{"O'Brien", 2, uint64(7)}) {
+		t.Fatalf("unexpected update args %v", args)
+	}
+}
+
+func TestAlertDataMatch(t *testing.T) {
+	args := queryArgs{}
+	condition := alertDataMatch(&args, "systemId", 1)
+	if condition != `("data" LIKE $1 OR "data" LIKE $2)` {
+		t.Fatalf("unexpected condition %s", condition)
+	}
+	if !reflect.DeepEqual([]any(args), []any{`%"systemId":1,%`, `%"systemId":1}%`}) {
+		t.Fatalf("unexpected args %v", args)
+	}
+}
+
+func TestSitesWriteBindsValues(t *testing.T) {
+	var queries []string
+	var values []driver.Value
+	db := newFakeDatabase(t, func(query string, args []driver.Value) ([][]driver.Value, int64, error) {
+		queries = append(queries, query)
+		values = append(values, args...)
+		return nil, 1, nil
+	})
+
+	site := NewSite()
+	site.Label = "St. Mary's"
+	site.County = "O'Brien"
+	site.SiteRef = "001"
+	sites := NewSites()
+	sites.List = []*Site{site}
+
+	tx, err := db.Sql.Begin()
+	if err != nil {
+		t.Fatalf("begin: %v", err)
+	}
+	if err := sites.WriteTx(tx, 3); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	tx.Commit()
+
+	for _, query := range queries {
+		if strings.Contains(query, "O'Brien") || strings.Contains(query, "Mary") {
+			t.Fatalf("value spliced into %s", query)
+		}
+	}
+	found := 0
+	for _, v := range values {
+		if v == "O'Brien" || v == "St. Mary's" {
+			found++
+		}
+	}
+	if found != 2 {
+		t.Fatalf("values not bound: %v", values)
+	}
+}
@@ -0,0 +1,179 @@
+// Copyright (C) 2026 Thinline Dynamic Solutions
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>
+
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// QuietHours is a daily window during which alerts are not delivered to the
+// user, except for tone matches on critical tone sets. It is stored with the
+// alert preferences: the user's own schedule on the row with system and
+// talkgroup 0, and per-talkgroup overrides on the talkgroup rows.
+type QuietHours struct {
+	Enabled  bool   `json:"enabled"`
+	Start    string `json:"start"`              // "23:00"
+	End      string `json:"end"`                // "06:00", before Start for overnight windows
+	Days     []int  `json:"days,omitempty"`     // days the window starts on, 0 = Sunday; empty = every day
+	TimeZone string `json:"timeZone,omitempty"` // IANA time zone, empty = server time
+}
+
+// parseClock parses "HH:MM" into minutes after midnight.
+func parseClock(value string) (int, error) {
+	parts := strings.Split(strings.TrimSpace(value), ":")
+	if len(parts) != 2 {
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM", value)
+	}
+	hours, err := strconv.Atoi(parts[0])
+	if err != nil || hours < 0 || hours > 23 {
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM", value)
+	}
+	minutes, err := strconv.Atoi(parts[1])
+	if err != nil || minutes < 0 || minutes > 59 {
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM", value)
+	}
+	return hours*60 + minutes, nil
+}
+
+// Validate checks the times, days and time zone of an enabled schedule.
+func (quietHours *QuietHours) Validate() error {
+	if !quietHours.Enabled {
+		return nil
+	}
+	if _, err := parseClock(quietHours.Start); err != nil {
+		return err
+	}
+	if _, err := parseClock(quietHours.End); err != nil {
+		return err
+	}
+	for _, day := range quietHours.Days {
+		if day < 0 || day > 6 {
+			return fmt.Errorf("invalid day %d, expected 0 (Sunday) to 6", day)
+		}
+	}
+	if quietHours.TimeZone != "" {
+		if _, err := time.LoadLocation(quietHours.TimeZone); err != nil {
+			return fmt.Errorf("invalid time zone %q", quietHours.TimeZone)
+		}
+	}
+	return nil
+}
+
+// Active reports whether now falls in the window. A window whose end is before
+// its start runs overnight, and the same start and end cover the whole day.
+func (quietHours *QuietHours) Active(now time.Time) bool {
+	if quietHours == nil || !quietHours.Enabled {
+		return false
+	}
+	start, err := parseClock(quietHours.Start)
+	if err != nil {
+		return false
+	}
+	end, err := parseClock(quietHours.End)
+	if err != nil {
+		return false
+	}
+	if quietHours.TimeZone != "" {
+		if location, err := time.LoadLocation(quietHours.TimeZone); err == nil {
+			now = now.In(location)
+		}
+	}
+
+	minute := now.Hour()*60 + now.Minute()
+	startDay := now.Weekday()
+	switch {
+	case start == end:
+	case start < end:
+		if minute < start || minute >= end {
+			return false
+		}
+	case minute >= start:
+	case minute < end:
+		// After midnight in an overnight window started the day before
+		startDay = (startDay + 6) % 7
+	default:
+		return false
+	}
+
+	if len(quietHours.Days) == 0 {
+		return true
+	}
+	for _, day := range quietHours.Days {
+		if time.Weekday(day) == startDay {
+			return true
+		}
+	}
+	return false
+}
+
+// callIsCritical reports whether the call matched a tone set marked critical,
+// which is delivered during quiet hours.
+func callIsCritical(call *Call) bool {
+	if call == nil || call.ToneSequence == nil {
+		return false
+	}
+	if call.ToneSequence.MatchedToneSet != nil && call.ToneSequence.MatchedToneSet.Critical {
+		return true
+	}
+	for _, toneSet := range call.ToneSequence.MatchedToneSets {
+		if toneSet != nil && toneSet.Critical {
+			return true
+		}
+	}
+	return false
+}
+
+// userQuietHours returns the schedule that applies to the user for the call:
+// the talkgroup's override if set, otherwise the user's own schedule.
+func (controller *Controller) userQuietHours(userId uint64, call *Call) *QuietHours {
+	if call != nil && call.System != nil && call.Talkgroup != nil {
+		if pref := controller.PreferencesCache.GetPreference(userId, call.System.Id, call.Talkgroup.Id); pref != nil && pref.QuietHours != nil {
+			return pref.QuietHours
+		}
+	}
+	if pref := controller.PreferencesCache.GetPreference(userId, 0, 0); pref != nil {
+		return pref.QuietHours
+	}
+	return nil
+}
+
+// inQuietHours reports whether alerts for the call are held for the user.
+func (controller *Controller) inQuietHours(userId uint64, call *Call, now time.Time) bool {
+	if controller.PreferencesCache == nil || callIsCritical(call) {
+		return false
+	}
+	return controller.userQuietHours(userId, call).Active(now)
+}
+
+// withoutQuietHours drops the users in their quiet hours. Alerts go through it
+// before any channel, push, email or webhook, is invoked.
+func (controller *Controller) withoutQuietHours(userIds []uint64, call *Call) []uint64 {
+	now := time.Now()
+	kept := make([]uint64, 0, len(userIds))
+	for _, userId := range userIds {
+		if controller.inQuietHours(userId, call, now) {
+			continue
+		}
+		kept = append(kept, userId)
+	}
+	if held := len(userIds) - len(kept); held > 0 && controller.Logs != nil {
+		controller.Logs.LogEvent(LogLevelInfo, fmt.Sprintf("alert held for %d user(s) in quiet hours", held))
+	}
+	return kept
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestQuietHoursActive(t *testing.T) {
+	// Wednesday 2024-01-03
+	at := func(hour, minute int) time.Time {
+		return time.Date(2024, 1, 3, hour, minute, 0, 0, time.UTC)
+	}
+
+	overnight := &QuietHours{Enabled: true, Start: "23:00", End: "06:00"}
+	for _, test := range []struct {
+		now  time.Time
+		want bool
+	}{
+		{at(22, 59), false},
+		{at(23, 0), true},
+		{at(2, 30), true},
+		{at(6, 0), false},
+		{at(12, 0), false},
+	} {
+		if got := overnight.Active(test.now); got != test.want {
+			t.Fatalf("overnight at %s = %v, want %v", test.now.Format("15:04"), got, test.want)
+		}
+	}
+
+	daytime := &QuietHours{Enabled: true, Start: "09:00", End: "17:00"}
+	if !daytime.Active(at(12, 0)) || daytime.Active(at(17, 30)) {
+		t.Fatalf("unexpected daytime window")
+	}
+
+	allDay := &QuietHours{Enabled: true, Start: "00:00", End: "00:00"}
+	if !allDay.Active(at(15, 0)) {
+		t.Fatalf("expected the same start and end to cover the whole day")
+	}
+
+	// The window started Tuesday night still applies early Wednesday
+	tuesdays := &QuietHours{Enabled: true, Start: "23:00", End: "06:00", Days: []int{2}}
+	if !tuesdays.Active(at(2, 0)) {
+		t.Fatalf("expected Tuesday's window after midnight")
+	}
+	if tuesdays.Active(at(23, 30)) {
+		t.Fatalf("expected no window on Wednesday night")
+	}
+
+	zoned := &QuietHours{Enabled: true, Start: "23:00", End: "06:00", TimeZone: "America/New_York"}
+	if !zoned.Active(at(5, 0)) || zoned.Active(at(12, 0)) {
+		t.Fatalf("expected the window in the user's time zone")
+	}
+
+	if (&QuietHours{Start: "23:00", End: "06:00"}).Active(at(2, 0)) {
+		t.Fatalf("expected disabled quiet hours to never be active")
+	}
+	var unset *QuietHours
+	if unset.Active(at(2, 0)) {
+		t.Fatalf("expected nil quiet hours to never be active")
+	}
+}
+
+func TestQuietHoursValidate(t *testing.T) {
+	for _, quietHours := range []QuietHours{
+		{Enabled: true, Start: "24:00", End: "06:00"},
+		{Enabled: true, Start: "23:00", End: "6"},
+		{Enabled: true, Start: "23:00", End: "06:00", Days: []int{7}},
+		{Enabled: true, Start: "23:00", End: "06:00", TimeZone: "Mars/Olympus"},
+	} {
+		if err := quietHours.Validate(); err == nil {
+			t.Fatalf("expected %+v to be invalid", quietHours)
+		}
+	}
+	if err := (&QuietHours{Enabled: true, Start: "23:00", End: "06:00", Days: []int{0, 6}, TimeZone: "UTC"}).Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+}
+
+func TestInQuietHours(t *testing.T) {
+	controller := &Controller{PreferencesCache: NewPreferencesCache(nil)}
+	system := &System{Id: 1}
+	talkgroup := &Talkgroup{Id: 2}
+	always := &QuietHours{Enabled: true, Start: "00:00", End: "00:00"}
+	controller.PreferencesCache.byUser[7] = map[uint64]*UserAlertPreference{
+		makePreferenceKey(0, 0): {UserId: 7, QuietHours: always},
+	}
+	call := &Call{System: system, Talkgroup: talkgroup}
+	now := time.Now()
+
+	if !controller.inQuietHours(7, call, now) {
+		t.Fatalf("expected the user's own quiet hours to apply")
+	}
+	if controller.inQuietHours(8, call, now) {
+		t.Fatalf("expected no quiet hours for a user without a schedule")
+	}
+
+	critical := &Call{System: system, Talkgroup: talkgroup, ToneSequence: &ToneSequence{MatchedToneSets: []*ToneSet{{Id: "a"}, {Id: "b", Critical: true}}}}
+	if controller.inQuietHours(7, critical, now) {
+		t.Fatalf("expected critical tone matches to bypass quiet hours")
+	}
+
+	controller.PreferencesCache.byUser[7][makePreferenceKey(1, 2)] = &UserAlertPreference{UserId: 7, SystemId: 1, TalkgroupId: 2, QuietHours: &QuietHours{}}
+	if controller.inQuietHours(7, call, now) {
+		t.Fatalf("expected the talkgroup override to apply")
+	}
+
+	if kept := controller.withoutQuietHours([]uint64{7, 8}, &Call{}); len(kept) != 1 || kept[0] != 8 {
+		t.Fatalf("withoutQuietHours = %v", kept)
+	}
+}
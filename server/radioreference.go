@@ -28,7 +28,6 @@ import (
 	"encoding/xml"
 	"fmt"
 	"io"
-	"log"
 	"net/http"
 	"os"
 	"sort"
@@ -39,6 +38,8 @@ import (
 	"github.com/antchfx/xmlquery"
 )
 
+var radioReferenceLog = Logger(LogCategoryRadioReference)
+
 const (
 	RADIO_REFERENCE_BASE_URL = "http://api.radioreference.com/soap2/"
 )
@@ -92,6 +93,19 @@ type RadioReferenceSite struct {
 	CountyName  string    `xml:"countyName" json:"countyName"` // This will store countyName
 	RFSS        int       `xml:"rfss" json:"rfss"`             // This will store rfss
 	Frequencies []float64 `xml:"frequencies" json:"frequencies"` // Site frequencies
+	SiteNumber  int       `xml:"siteNumber" json:"siteNumber"`
+	// Control channels ("d" use) and alternate control channels ("a" use)
+	ControlChannels          []float64                   `xml:"controlChannels" json:"controlChannels"`
+	AlternateControlChannels []float64                   `xml:"alternateControlChannels" json:"alternateControlChannels"`
+	Channels                 []RadioReferenceSiteChannel `xml:"channels" json:"channels"`
+}
+
+// RadioReferenceSiteChannel is one frequency of a trunked site with its
+// logical channel number and use ("d" control, "a" alternate control, "" voice).
+type RadioReferenceSiteChannel struct {
+	Lcn       int     `xml:"lcn" json:"lcn"`
+	Frequency float64 `xml:"freq" json:"frequency"`
+	Use       string  `xml:"use" json:"use"`
 }
 
 type RadioReferenceFrequency struct {
@@ -300,7 +314,7 @@ func (rr *RadioReferenceService) AuthenticateAndValidate() error {
 
 	// Warn for non-premium accounts; some endpoints may still fail with AUTH
 	if strings.Contains(strings.ToLower(expiry), "feed provider") {
-		log.Printf("WARNING: RadioReference account appears to be Feed Provider (non-premium); some API methods may return AUTH faults")
+		radioReferenceLog.Warn("RadioReference account appears to be Feed Provider (non-premium); some API methods may return AUTH faults")
 	}
 	return nil
 }
@@ -1063,7 +1077,7 @@ func (rr *RadioReferenceService) GetSystemSites(systemID int) ([]RadioReferenceS
 	}
 
 	// Log the raw XML response for debugging
-	log.Printf("=== RAW RADIO REFERENCE SITES XML (first 2000 chars) ===\n%s\n=== END RAW XML ===", string(resp[:min(len(resp), 2000)]))
+	radioReferenceLog.Debug(fmt.Sprintf("=== RAW RADIO REFERENCE SITES XML (first 2000 chars) ===\n%s\n=== END RAW XML ===", string(resp[:min(len(resp), 2000)])))
 
 	var fault SOAPFault
 	if err := xml.Unmarshal(resp, &fault); err == nil && fault.FaultCode != "" {
@@ -1129,7 +1143,7 @@ func parseSiteList(bodyContent []byte) ([]RadioReferenceSite, error) {
 	var sites []RadioReferenceSite
 
 	// Log the body content we're parsing
-	log.Printf("=== PARSING SITE LIST - Body Content (first 3000 chars) ===\n%s\n=== END BODY CONTENT ===", string(bodyContent[:min(len(bodyContent), 3000)]))
+	radioReferenceLog.Debug(fmt.Sprintf("=== PARSING SITE LIST - Body Content (first 3000 chars) ===\n%s\n=== END BODY CONTENT ===", string(bodyContent[:min(len(bodyContent), 3000)])))
 
 	// Parse the XML response
 	doc, err := xmlquery.Parse(bytes.NewReader(bodyContent))
@@ -1139,7 +1153,7 @@ func parseSiteList(bodyContent []byte) ([]RadioReferenceSite, error) {
 
 	// Only TRS site rows have <siteNumber>; nested <item> under <siteFreqs> / <siteLicenses> must be ignored.
 	itemNodes := xmlquery.Find(doc, "//item[siteNumber]")
-	log.Printf("Found %d site item nodes", len(itemNodes))
+	radioReferenceLog.Debug(fmt.Sprintf("Found %d site item nodes", len(itemNodes)))
 
 	for _, itemNode := range itemNodes {
 		site := RadioReferenceSite{}
@@ -1155,6 +1169,7 @@ func parseSiteList(bodyContent []byte) ([]RadioReferenceSite, error) {
 		// Extract siteNumber (this is what rdio-scanner needs)
 		if numberNode := xmlquery.FindOne(itemNode, "siteNumber"); numberNode != nil {
 			if number, err := strconv.Atoi(numberNode.InnerText()); err == nil {
+				site.SiteNumber = number
 				// Format site ID to include RFSS prefix and 3-digit site number
 				if site.RFSS > 0 {
 					site.ID = fmt.Sprintf("%d-%03d", site.RFSS, number)
@@ -1200,7 +1215,7 @@ func parseSiteList(bodyContent []byte) ([]RadioReferenceSite, error) {
 		siteFreqsNode := xmlquery.FindOne(itemNode, "siteFreqs")
 		if siteFreqsNode != nil {
 			freqItems := xmlquery.Find(siteFreqsNode, "item")
-			log.Printf("Site %s: Found %d frequency items", site.Name, len(freqItems))
+			radioReferenceLog.Debug(fmt.Sprintf("Site %s: Found %d frequency items", site.Name, len(freqItems)))
 			
 			for _, freqItem := range freqItems {
 				// Each item contains lcn, freq, use, colorCode, ch_id
@@ -1208,22 +1223,37 @@ func parseSiteList(bodyContent []byte) ([]RadioReferenceSite, error) {
 					freqText := freqValueNode.InnerText()
 					if freq, err := strconv.ParseFloat(freqText, 64); err == nil && freq > 0 {
 						site.Frequencies = append(site.Frequencies, freq)
+
+						channel := RadioReferenceSiteChannel{Frequency: freq}
+						if lcnNode := xmlquery.FindOne(freqItem, "lcn"); lcnNode != nil {
+							channel.Lcn, _ = strconv.Atoi(strings.TrimSpace(lcnNode.InnerText()))
+						}
+						if useNode := xmlquery.FindOne(freqItem, "use"); useNode != nil {
+							channel.Use = strings.ToLower(strings.TrimSpace(useNode.InnerText()))
+						}
+						switch channel.Use {
+						case "d":
+							site.ControlChannels = append(site.ControlChannels, freq)
+						case "a":
+							site.AlternateControlChannels = append(site.AlternateControlChannels, freq)
+						}
+						site.Channels = append(site.Channels, channel)
 					}
 				}
 			}
-			log.Printf("Site %s: Successfully parsed %d frequencies", site.Name, len(site.Frequencies))
+			radioReferenceLog.Debug(fmt.Sprintf("Site %s: Successfully parsed %d frequencies", site.Name, len(site.Frequencies)))
 		} else if site.Name != "" {
-			log.Printf("Site %s: No siteFreqs node found", site.Name)
+			radioReferenceLog.Debug(fmt.Sprintf("Site %s: No siteFreqs node found", site.Name))
 		}
 
 		// Only add sites that have at least a number and name
 		if site.ID != "" && site.Name != "" {
-			log.Printf("Adding site: ID=%s, Name=%s, Frequencies=%d", site.ID, site.Name, len(site.Frequencies))
+			radioReferenceLog.Debug(fmt.Sprintf("Adding site: ID=%s, Name=%s, Frequencies=%d", site.ID, site.Name, len(site.Frequencies)))
 			sites = append(sites, site)
 		}
 	}
 
-	log.Printf("Parsed total of %d sites", len(sites))
+	radioReferenceLog.Debug(fmt.Sprintf("Parsed total of %d sites", len(sites)))
 	return sites, nil
 }
 
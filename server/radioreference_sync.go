@@ -0,0 +1,562 @@
+// Copyright (C) 2026 Thinline Dynamic Solutions
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// radioReferenceSyncInterval is how often a linked system is checked against
+// Radio Reference.
+const radioReferenceSyncInterval = 24 * time.Hour
+
+const (
+	RadioReferenceChangeAdded   = "added"
+	RadioReferenceChangeRenamed = "renamed"
+	RadioReferenceChangeUpdated = "updated"
+	RadioReferenceChangeRemoved = "removed"
+)
+
+// RadioReferenceChange is one difference between a local system and its Radio
+// Reference listing. Id ("tg:1001", "site:1-005") selects it when applying.
+type RadioReferenceChange struct {
+	Id        string                   `json:"id"`
+	Kind      string                   `json:"kind"` // "talkgroup" or "site"
+	Action    string                   `json:"action"`
+	Ref       string                   `json:"ref"`
+	Before    string                   `json:"before,omitempty"`
+	After     string                   `json:"after,omitempty"`
+	Talkgroup *RadioReferenceTalkgroup `json:"talkgroup,omitempty"`
+	Site      *RadioReferenceSite      `json:"site,omitempty"`
+}
+
+// RadioReferenceSyncStatus is a system linked to a Radio Reference system and
+// the changes found by its last check.
+type RadioReferenceSyncStatus struct {
+	SystemId    uint64                 `json:"systemId"`
+	SystemLabel string                 `json:"systemLabel"`
+	RRSystemId  int                    `json:"rrSystemId"`
+	CheckedAt   int64                  `json:"checkedAt"`
+	Error       string                 `json:"error,omitempty"`
+	Changes     []RadioReferenceChange `json:"changes"`
+}
+
+// RadioReferenceSync periodically re-fetches the talkgroups and sites of the
+// systems imported from Radio Reference and keeps the differences for an
+// admin to review and apply.
+type RadioReferenceSync struct {
+	controller *Controller
+	mutex      sync.Mutex
+}
+
+func NewRadioReferenceSync(controller *Controller) *RadioReferenceSync {
+	return &RadioReferenceSync{controller: controller}
+}
+
+// Link records which Radio Reference system a local system was imported from.
+func (rrs *RadioReferenceSync) Link(systemId uint64, rrSystemId int) error {
+	_, err := rrs.controller.Database.Sql.Exec(`INSERT INTO "radioReferenceSyncs" ("systemId", "rrSystemId") VALUES ($1, $2) ON CONFLICT ("systemId") DO UPDATE SET "rrSystemId" = EXCLUDED."rrSystemId", "checkedAt" = 0, "changes" = '[]', "error" = ''`, systemId, rrSystemId)
+	return err
+}
+
+func (rrs *RadioReferenceSync) Unlink(systemId uint64) error {
+	_, err := rrs.controller.Database.Sql.Exec(`DELETE FROM "radioReferenceSyncs" WHERE "systemId" = $1`, systemId)
+	return err
+}
+
+// Status returns the linked systems, or the one of systemId when not 0.
+func (rrs *RadioReferenceSync) Status(systemId uint64) ([]RadioReferenceSyncStatus, error) {
+	query := `SELECT "systemId", "rrSystemId", "checkedAt", "changes", "error" FROM "radioReferenceSyncs" ORDER BY "systemId"`
+	args := []any{}
+	if systemId > 0 {
+		query = `SELECT "systemId", "rrSystemId", "checkedAt", "changes", "error" FROM "radioReferenceSyncs" WHERE "systemId" = $1`
+		args = append(args, systemId)
+	}
+
+	rows, err := rrs.controller.Database.Sql.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	statuses := []RadioReferenceSyncStatus{}
+	for rows.Next() {
+		var (
+			status  RadioReferenceSyncStatus
+			changes string
+		)
+		if err := rows.Scan(&status.SystemId, &status.RRSystemId, &status.CheckedAt, &changes, &status.Error); err != nil {
+			return nil, err
+		}
+		status.Changes = []RadioReferenceChange{}
+		json.Unmarshal([]byte(changes), &status.Changes)
+		if system, ok := rrs.controller.Systems.GetSystemById(status.SystemId); ok {
+			status.SystemLabel = system.Label
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, rows.Err()
+}
+
+func (rrs *RadioReferenceSync) service() (*RadioReferenceService, error) {
+	options := rrs.controller.Options
+	if !options.RadioReferenceEnabled || options.RadioReferenceUsername == "" || options.RadioReferencePassword == "" {
+		return nil, fmt.Errorf("Radio Reference is not enabled")
+	}
+	return NewRadioReferenceService(options.RadioReferenceUsername, options.RadioReferencePassword, options.RadioReferenceAPIKey), nil
+}
+
+// fetchRadioReferenceTalkgroups returns every talkgroup of a Radio Reference system. Unlike
+// GetAllTalkgroupsForSystem it fails when a category fails, so a partial
+// listing is never taken for removed talkgroups.
+func fetchRadioReferenceTalkgroups(rr *RadioReferenceService, rrSystemId int) ([]RadioReferenceTalkgroup, error) {
+	categories, err := rr.GetTalkgroupCategories(rrSystemId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get talkgroup categories: %v", err)
+	}
+
+	talkgroups := []RadioReferenceTalkgroup{}
+	for _, category := range categories {
+		list, err := rr.GetTalkgroupsByCategory(rrSystemId, category.ID, category.Name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get talkgroups of category %s: %v", category.Name, err)
+		}
+		for i := range list {
+			if list[i].Group == "" {
+				list[i].Group = category.Name
+			}
+		}
+		talkgroups = append(talkgroups, list...)
+	}
+	return talkgroups, nil
+}
+
+// Check re-fetches a linked system from Radio Reference and stores the
+// differences. A system alert is raised when they changed since the last check.
+func (rrs *RadioReferenceSync) Check(systemId uint64) (*RadioReferenceSyncStatus, error) {
+	rrs.mutex.Lock()
+	defer rrs.mutex.Unlock()
+
+	statuses, err := rrs.Status(systemId)
+	if err != nil {
+		return nil, err
+	}
+	if len(statuses) == 0 {
+		return nil, fmt.Errorf("system not linked to Radio Reference")
+	}
+	status := statuses[0]
+
+	system, ok := rrs.controller.Systems.GetSystemById(systemId)
+	if !ok {
+		return nil, fmt.Errorf("system not found")
+	}
+
+	changes, checkErr := rrs.fetchChanges(system, status.RRSystemId)
+
+	now := time.Now().UnixMilli()
+	if checkErr != nil {
+		rrs.controller.Database.Sql.Exec(`UPDATE "radioReferenceSyncs" SET "checkedAt" = $2, "error" = $3 WHERE "systemId" = $1`, systemId, now, checkErr.Error())
+		return nil, checkErr
+	}
+
+	b, _ := json.Marshal(changes)
+	if _, err := rrs.controller.Database.Sql.Exec(`UPDATE "radioReferenceSyncs" SET "checkedAt" = $2, "changes" = $3, "error" = '' WHERE "systemId" = $1`, systemId, now, string(b)); err != nil {
+		return nil, err
+	}
+
+	if len(changes) > 0 && radioReferenceChangeIds(changes) != radioReferenceChangeIds(status.Changes) {
+		rrs.controller.CreateSystemAlert("radioreference_sync", "info",
+			fmt.Sprintf("Radio Reference changes for %s", system.Label),
+			fmt.Sprintf("%s differs from Radio Reference system %d: %s. Review and apply them in the Radio Reference sync.", system.Label, status.RRSystemId, summarizeRadioReferenceChanges(changes)),
+			&SystemAlertData{SystemId: system.Id, SystemLabel: system.Label, Count: len(changes)}, 0)
+	}
+
+	status.SystemLabel = system.Label
+	status.CheckedAt = now
+	status.Error = ""
+	status.Changes = changes
+	return &status, nil
+}
+
+func (rrs *RadioReferenceSync) fetchChanges(system *System, rrSystemId int) ([]RadioReferenceChange, error) {
+	rr, err := rrs.service()
+	if err != nil {
+		return nil, err
+	}
+	talkgroups, err := fetchRadioReferenceTalkgroups(rr, rrSystemId)
+	if err != nil {
+		return nil, err
+	}
+	sites, err := rr.GetSites(rrSystemId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sites: %v", err)
+	}
+	return diffRadioReference(system, talkgroups, sites), nil
+}
+
+// RunScheduled checks the linked systems not checked for a day. Called by
+// the hourly scheduler.
+func (rrs *RadioReferenceSync) RunScheduled() {
+	if _, err := rrs.service(); err != nil {
+		return
+	}
+
+	statuses, err := rrs.Status(0)
+	if err != nil {
+		rrs.controller.Logs.LogEvent(LogLevelError, fmt.Sprintf("radioreference sync: %v", err))
+		return
+	}
+
+	cutoff := time.Now().Add(-radioReferenceSyncInterval).UnixMilli()
+	for _, status := range statuses {
+		if status.CheckedAt > cutoff {
+			continue
+		}
+		if _, err := rrs.Check(status.SystemId); err != nil {
+			rrs.controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("radioreference sync: system %d: %v", status.SystemId, err))
+		}
+	}
+}
+
+// Apply applies the selected changes of the last check to the system and
+// keeps the others for later.
+func (rrs *RadioReferenceSync) Apply(systemId uint64, ids []string) (int, error) {
+	rrs.mutex.Lock()
+	defer rrs.mutex.Unlock()
+
+	statuses, err := rrs.Status(systemId)
+	if err != nil {
+		return 0, err
+	}
+	if len(statuses) == 0 {
+		return 0, fmt.Errorf("system not linked to Radio Reference")
+	}
+	system, ok := rrs.controller.Systems.GetSystemById(systemId)
+	if !ok {
+		return 0, fmt.Errorf("system not found")
+	}
+
+	selected := map[string]bool{}
+	for _, id := range ids {
+		selected[id] = true
+	}
+
+	body := radioReferenceImportBody{SystemId: float64(systemId)}
+	removedTalkgroups := map[string]bool{}
+	removedSites := map[string]bool{}
+	pending := []RadioReferenceChange{}
+	applied := 0
+
+	for _, change := range statuses[0].Changes {
+		if !selected[change.Id] {
+			pending = append(pending, change)
+			continue
+		}
+		applied++
+
+		switch {
+		case change.Action == RadioReferenceChangeRemoved && change.Kind == "talkgroup":
+			removedTalkgroups[change.Ref] = true
+		case change.Action == RadioReferenceChangeRemoved && change.Kind == "site":
+			removedSites[change.Ref] = true
+		case change.Talkgroup != nil:
+			tg := change.Talkgroup
+			body.Talkgroups = append(body.Talkgroups, radioReferenceImportTalkgroup{
+				Id:          float64(tg.ID),
+				AlphaTag:    tg.AlphaTag,
+				Description: tg.Description,
+				Group:       tg.Group,
+				Tag:         tg.Tag,
+				Enc:         float64(tg.Enc),
+			})
+		case change.Site != nil:
+			site := change.Site
+			body.Sites = append(body.Sites, radioReferenceImportSite{
+				Id:                       rrSiteImportID{value: site.ID},
+				Name:                     site.Name,
+				Rfss:                     float64(site.RFSS),
+				Frequencies:              site.Frequencies,
+				ControlChannels:          site.ControlChannels,
+				AlternateControlChannels: site.AlternateControlChannels,
+				Latitude:                 site.Latitude,
+				Longitude:                site.Longitude,
+				CountyName:               site.CountyName,
+			})
+		}
+	}
+
+	if applied == 0 {
+		return 0, nil
+	}
+
+	if len(removedTalkgroups) > 0 {
+		kept := []*Talkgroup{}
+		for _, talkgroup := range system.Talkgroups.List {
+			if !removedTalkgroups[strconv.FormatUint(uint64(talkgroup.TalkgroupRef), 10)] {
+				kept = append(kept, talkgroup)
+			}
+		}
+		system.Talkgroups.List = kept
+	}
+	if len(removedSites) > 0 {
+		kept := []*Site{}
+		for _, site := range system.Sites.List {
+			if !removedSites[site.SiteRef] {
+				kept = append(kept, site)
+			}
+		}
+		system.Sites.List = kept
+	}
+
+	if _, _, err := rrs.controller.Admin.radioReferenceImportToSystemCore(body); err != nil {
+		return 0, err
+	}
+
+	b, _ := json.Marshal(pending)
+	if _, err := rrs.controller.Database.Sql.Exec(`UPDATE "radioReferenceSyncs" SET "changes" = $2 WHERE "systemId" = $1`, systemId, string(b)); err != nil {
+		return applied, err
+	}
+
+	go rrs.controller.EmitConfig()
+
+	return applied, nil
+}
+
+// diffRadioReference lists what differs between a local system and the
+// talkgroups and sites Radio Reference has for it.
+func diffRadioReference(system *System, talkgroups []RadioReferenceTalkgroup, sites []RadioReferenceSite) []RadioReferenceChange {
+	changes := []RadioReferenceChange{}
+
+	remote := map[uint]bool{}
+	for i := range talkgroups {
+		tg := talkgroups[i]
+		ref := uint(tg.ID)
+		remote[ref] = true
+		id := fmt.Sprintf("tg:%d", ref)
+
+		local, ok := system.Talkgroups.GetTalkgroupByRef(ref)
+		switch {
+		case !ok:
+			changes = append(changes, RadioReferenceChange{Id: id, Kind: "talkgroup", Action: RadioReferenceChangeAdded, Ref: strconv.Itoa(tg.ID), After: tg.AlphaTag, Talkgroup: &tg})
+		case local.Label != tg.AlphaTag || local.Name != tg.Description:
+			changes = append(changes, RadioReferenceChange{Id: id, Kind: "talkgroup", Action: RadioReferenceChangeRenamed, Ref: strconv.Itoa(tg.ID), Before: local.Label, After: tg.AlphaTag, Talkgroup: &tg})
+		}
+	}
+	for _, local := range system.Talkgroups.List {
+		if !remote[local.TalkgroupRef] {
+			changes = append(changes, RadioReferenceChange{Id: fmt.Sprintf("tg:%d", local.TalkgroupRef), Kind: "talkgroup", Action: RadioReferenceChangeRemoved, Ref: strconv.FormatUint(uint64(local.TalkgroupRef), 10), Before: local.Label})
+		}
+	}
+
+	remoteSites := map[string]bool{}
+	for i := range sites {
+		site := sites[i]
+		remoteSites[site.ID] = true
+		id := "site:" + site.ID
+
+		local, ok := system.Sites.GetSiteByRef(site.ID)
+		controlChannels := append(append([]float64{}, site.ControlChannels...), site.AlternateControlChannels...)
+		switch {
+		case !ok:
+			changes = append(changes, RadioReferenceChange{Id: id, Kind: "site", Action: RadioReferenceChangeAdded, Ref: site.ID, After: site.Name, Site: &site})
+		case local.Label != site.Name:
+			changes = append(changes, RadioReferenceChange{Id: id, Kind: "site", Action: RadioReferenceChangeRenamed, Ref: site.ID, Before: local.Label, After: site.Name, Site: &site})
+		case local.RFSS != uint(site.RFSS) || !sameFrequencies(local.Frequencies, site.Frequencies) || !sameFrequencies(local.ControlChannels, controlChannels) || local.Latitude != site.Latitude || local.Longitude != site.Longitude:
+			changes = append(changes, RadioReferenceChange{Id: id, Kind: "site", Action: RadioReferenceChangeUpdated, Ref: site.ID, Before: local.Label, After: site.Name, Site: &site})
+		}
+	}
+	for _, local := range system.Sites.List {
+		if !remoteSites[local.SiteRef] {
+			changes = append(changes, RadioReferenceChange{Id: "site:" + local.SiteRef, Kind: "site", Action: RadioReferenceChangeRemoved, Ref: local.SiteRef, Before: local.Label})
+		}
+	}
+
+	return changes
+}
+
+// sameFrequencies compares two MHz lists regardless of order, to the Hz.
+func sameFrequencies(a []float64, b []float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	hz := func(list []float64) []int64 {
+		out := make([]int64, len(list))
+		for i, f := range list {
+			out[i] = int64(math.Round(f * 1e6))
+		}
+		sort.Slice(out, func(i, j int) bool { return out[i] < out[j] })
+		return out
+	}
+	ha, hb := hz(a), hz(b)
+	for i := range ha {
+		if ha[i] != hb[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func radioReferenceChangeIds(changes []RadioReferenceChange) string {
+	ids := make([]string, 0, len(changes))
+	for _, change := range changes {
+		ids = append(ids, change.Action+":"+change.Id)
+	}
+	sort.Strings(ids)
+	return strings.Join(ids, ",")
+}
+
+// summarizeRadioReferenceChanges counts changes by kind and action, e.g.
+// "3 talkgroups added, 1 talkgroup renamed".
+func summarizeRadioReferenceChanges(changes []RadioReferenceChange) string {
+	counts := map[string]int{}
+	order := []string{}
+	for _, change := range changes {
+		key := change.Kind + " " + change.Action
+		if counts[key] == 0 {
+			order = append(order, key)
+		}
+		counts[key]++
+	}
+
+	parts := []string{}
+	for _, key := range order {
+		kind, action, _ := strings.Cut(key, " ")
+		if counts[key] > 1 {
+			kind += "s"
+		}
+		parts = append(parts, fmt.Sprintf("%d %s %s", counts[key], kind, action))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// RadioReferenceSyncHandler manages the Radio Reference re-sync of imported
+// systems:
+//
+//	GET    /api/admin/radioreference/sync[?systemId=]        linked systems and their pending changes
+//	PUT    /api/admin/radioreference/sync                    {"systemId", "rrSystemId"} link a system
+//	DELETE /api/admin/radioreference/sync?systemId=          unlink
+//	POST   /api/admin/radioreference/sync/check              {"systemId"} check now
+//	POST   /api/admin/radioreference/sync/apply              {"systemId", "changes": [ids]}
+func (admin *Admin) RadioReferenceSyncHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	writeError := func(status int, message string) {
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(map[string]string{"error": message})
+	}
+
+	t := admin.GetAuthorization(r)
+	if !admin.ValidatePermission(t, PermissionManageSystems) {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	rrs := admin.Controller.RadioReferenceSync
+	action := strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, "/api/admin/radioreference/sync"), "/")
+
+	var body struct {
+		SystemId   uint64   `json:"systemId"`
+		RRSystemId int      `json:"rrSystemId"`
+		Changes    []string `json:"changes"`
+	}
+	if r.Method == http.MethodPut || r.Method == http.MethodPost {
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			writeError(http.StatusBadRequest, "invalid JSON")
+			return
+		}
+	} else {
+		body.SystemId, _ = strconv.ParseUint(r.URL.Query().Get("systemId"), 10, 64)
+	}
+
+	// Tenant administrators only reach their systems, one at a time
+	if admin.tokenTenant(t) != nil && !admin.tenantAllowsSystem(t, body.SystemId) {
+		writeError(http.StatusNotFound, "system not found")
+		return
+	}
+
+	statusFor := func(err error) int {
+		switch err.Error() {
+		case "system not found", "system not linked to Radio Reference":
+			return http.StatusNotFound
+		case "Radio Reference is not enabled":
+			return http.StatusExpectationFailed
+		}
+		return http.StatusInternalServerError
+	}
+
+	switch {
+	case action == "" && r.Method == http.MethodGet:
+		statuses, err := rrs.Status(body.SystemId)
+		if err != nil {
+			writeError(http.StatusInternalServerError, err.Error())
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{"systems": statuses})
+
+	case action == "" && r.Method == http.MethodPut:
+		if _, ok := admin.Controller.Systems.GetSystemById(body.SystemId); !ok {
+			writeError(http.StatusNotFound, "system not found")
+			return
+		}
+		if body.RRSystemId <= 0 {
+			writeError(http.StatusBadRequest, "rrSystemId is required")
+			return
+		}
+		if err := rrs.Link(body.SystemId, body.RRSystemId); err != nil {
+			writeError(http.StatusInternalServerError, err.Error())
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{"success": true})
+
+	case action == "" && r.Method == http.MethodDelete:
+		if err := rrs.Unlink(body.SystemId); err != nil {
+			writeError(http.StatusInternalServerError, err.Error())
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{"success": true})
+
+	case action == "check" && r.Method == http.MethodPost:
+		status, err := rrs.Check(body.SystemId)
+		if err != nil {
+			writeError(statusFor(err), err.Error())
+			return
+		}
+		json.NewEncoder(w).Encode(status)
+
+	case action == "apply" && r.Method == http.MethodPost:
+		if len(body.Changes) == 0 {
+			writeError(http.StatusBadRequest, "changes is required")
+			return
+		}
+		applied, err := rrs.Apply(body.SystemId, body.Changes)
+		if err != nil {
+			writeError(statusFor(err), err.Error())
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{"success": true, "applied": applied})
+
+	default:
+		writeError(http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
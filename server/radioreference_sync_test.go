@@ -0,0 +1,58 @@
+package main
+
+import "testing"
+
+func TestDiffRadioReference(t *testing.T) {
+	system := &System{Label: "County", Sites: NewSites(), Talkgroups: NewTalkgroups()}
+	system.Talkgroups.List = []*Talkgroup{
+		{TalkgroupRef: 100, Label: "FD Disp", Name: "Fire Dispatch"},
+		{TalkgroupRef: 200, Label: "PD Disp", Name: "Police Dispatch"},
+		{TalkgroupRef: 300, Label: "Old TAC", Name: "Old Tactical"},
+	}
+	system.Sites.List = []*Site{
+		{SiteRef: "1-001", Label: "North", RFSS: 1, Frequencies: []float64{851.0125}, ControlChannels: []float64{851.0125}},
+		{SiteRef: "1-002", Label: "South", RFSS: 1, Frequencies: []float64{852.0125}},
+	}
+
+	talkgroups := []RadioReferenceTalkgroup{
+		{ID: 100, AlphaTag: "FD Disp", Description: "Fire Dispatch"},
+		{ID: 200, AlphaTag: "PD Main", Description: "Police Dispatch"},
+		{ID: 400, AlphaTag: "EMS", Description: "EMS Dispatch"},
+	}
+	sites := []RadioReferenceSite{
+		{ID: "1-001", Name: "North", RFSS: 1, Frequencies: []float64{851.0125}, ControlChannels: []float64{851.0125}},
+		{ID: "1-002", Name: "South", RFSS: 1, Frequencies: []float64{852.0125, 852.5125}},
+		{ID: "1-003", Name: "East", RFSS: 1},
+	}
+
+	changes := diffRadioReference(system, talkgroups, sites)
+
+	want := map[string]string{
+		"tg:200":     RadioReferenceChangeRenamed,
+		"tg:400":     RadioReferenceChangeAdded,
+		"tg:300":     RadioReferenceChangeRemoved,
+		"site:1-002": RadioReferenceChangeUpdated,
+		"site:1-003": RadioReferenceChangeAdded,
+	}
+	if len(changes) != len(want) {
+		t.Fatalf("got %d changes, want %d: %+v", len(changes), len(want), changes)
+	}
+	for _, change := range changes {
+		if want[change.Id] != change.Action {
+			t.Fatalf("change %s action = %s, want %s", change.Id, change.Action, want[change.Id])
+		}
+	}
+
+	if s := summarizeRadioReferenceChanges(changes); s != "1 talkgroup renamed, 1 talkgroup added, 1 talkgroup removed, 1 site updated, 1 site added" {
+		t.Fatalf("summary = %q", s)
+	}
+}
+
+func TestSameFrequencies(t *testing.T) {
+	if !sameFrequencies([]float64{851.0125, 852.5}, []float64{852.5000001, 851.0125}) {
+		t.Fatalf("same frequencies in another order not matched")
+	}
+	if sameFrequencies([]float64{851.0125}, []float64{851.0250}) {
+		t.Fatalf("different frequencies matched")
+	}
+}
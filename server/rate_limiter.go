@@ -30,6 +30,8 @@ type RateLimiter struct {
 	mutex    sync.RWMutex
 	// Maximum requests per IP per window
 	maxRequests int
+	// Overrides maxRequests when set; 0 or less disables the limit
+	limit func() int
 	// Time window for rate limiting
 	windowDuration time.Duration
 	// Cleanup interval for old entries
@@ -42,22 +44,35 @@ type rateLimitEntry struct {
 	lastSeen  time.Time
 }
 
-// LoginAttemptTracker tracks failed login attempts and blocks IPs after threshold
+// LoginAttemptTracker tracks failed attempts per key (an IP address or an
+// account) and locks a key out after too many failures. Each repeat lockout
+// doubles in length up to the configured maximum.
 type LoginAttemptTracker struct {
 	attempts map[string]*loginAttemptEntry
 	mutex    sync.RWMutex
-	// Maximum failed attempts before blocking
-	maxAttempts int
-	// Block duration after max attempts reached
-	blockDuration time.Duration
+	// Current thresholds, read on every attempt so option changes apply live
+	limits func() AttemptLimits
+	// Called outside the lock when a key gets locked out
+	onLockout func(key string, attempts int, lockout time.Duration)
 	// Cleanup interval for old entries
 	cleanupInterval time.Duration
 }
 
+// AttemptLimits are the thresholds of a LoginAttemptTracker. MaxAttempts 0
+// disables lockouts.
+type AttemptLimits struct {
+	MaxAttempts int
+	Window      time.Duration // failures older than this are forgotten
+	Lockout     time.Duration // first lockout, doubled on each repeat
+	MaxLockout  time.Duration
+}
+
 type loginAttemptEntry struct {
 	failedAttempts int
-	blockedUntil    *time.Time
-	lastAttempt     time.Time
+	firstFailure   time.Time
+	lockouts       int
+	blockedUntil   *time.Time
+	lastAttempt    time.Time
 }
 
 // NewRateLimiter creates a new rate limiter
@@ -105,7 +120,14 @@ func (rl *RateLimiter) Allow(ip string) bool {
 	}
 
 	// Check if limit exceeded
-	if entry.count >= rl.maxRequests {
+	maxRequests := rl.maxRequests
+	if rl.limit != nil {
+		if maxRequests = rl.limit(); maxRequests <= 0 {
+			entry.lastSeen = now
+			return true
+		}
+	}
+	if entry.count >= maxRequests {
 		return false
 	}
 
@@ -115,6 +137,14 @@ func (rl *RateLimiter) Allow(ip string) bool {
 	return true
 }
 
+// SetLimit makes the limiter read its maximum requests per window from fn.
+func (rl *RateLimiter) SetLimit(fn func() int) {
+	rl.mutex.Lock()
+	defer rl.mutex.Unlock()
+
+	rl.limit = fn
+}
+
 // cleanup removes old entries to prevent memory leaks
 func (rl *RateLimiter) cleanup() {
 	ticker := time.NewTicker(rl.cleanupInterval)
@@ -136,10 +166,11 @@ func (rl *RateLimiter) cleanup() {
 // maxAttempts: maximum failed attempts before blocking (e.g., 6)
 // blockDuration: duration to block IP after max attempts (e.g., 15 minutes)
 func NewLoginAttemptTracker(maxAttempts int, blockDuration time.Duration) *LoginAttemptTracker {
+	limits := AttemptLimits{MaxAttempts: maxAttempts, Lockout: blockDuration, MaxLockout: blockDuration}
+
 	lat := &LoginAttemptTracker{
 		attempts:        make(map[string]*loginAttemptEntry),
-		maxAttempts:     maxAttempts,
-		blockDuration:   blockDuration,
+		limits:          func() AttemptLimits { return limits },
 		cleanupInterval: blockDuration * 2, // Clean up entries older than 2 block durations
 	}
 
@@ -149,81 +180,149 @@ func NewLoginAttemptTracker(maxAttempts int, blockDuration time.Duration) *Login
 	return lat
 }
 
-// RecordFailedAttempt records a failed login attempt for the given IP
-func (lat *LoginAttemptTracker) RecordFailedAttempt(ip string) {
+// SetLimits makes the tracker read its thresholds from fn.
+func (lat *LoginAttemptTracker) SetLimits(fn func() AttemptLimits) {
 	lat.mutex.Lock()
 	defer lat.mutex.Unlock()
 
+	lat.limits = fn
+}
+
+// OnLockout registers fn to be called when a key gets locked out.
+func (lat *LoginAttemptTracker) OnLockout(fn func(key string, attempts int, lockout time.Duration)) {
+	lat.mutex.Lock()
+	defer lat.mutex.Unlock()
+
+	lat.onLockout = fn
+}
+
+// lockoutFor returns the length of the nth lockout in a row.
+func (limits AttemptLimits) lockoutFor(n int) time.Duration {
+	lockout := limits.Lockout
+	for i := 1; i < n && (limits.MaxLockout <= 0 || lockout < limits.MaxLockout); i++ {
+		lockout *= 2
+	}
+	if limits.MaxLockout > 0 && lockout > limits.MaxLockout {
+		lockout = limits.MaxLockout
+	}
+	return lockout
+}
+
+// RecordFailedAttempt records a failed attempt for the given key
+func (lat *LoginAttemptTracker) RecordFailedAttempt(key string) {
+	lat.mutex.Lock()
+
+	limits := lat.limits()
+	if limits.MaxAttempts <= 0 {
+		lat.mutex.Unlock()
+		return
+	}
+
 	now := time.Now()
-	entry, exists := lat.attempts[ip]
+	entry, exists := lat.attempts[key]
 
 	if !exists {
-		entry = &loginAttemptEntry{
-			failedAttempts: 0,
-			lastAttempt:     now,
-		}
-		lat.attempts[ip] = entry
+		entry = &loginAttemptEntry{}
+		lat.attempts[key] = entry
 	}
 
-	entry.failedAttempts++
 	entry.lastAttempt = now
 
-	// If threshold reached, block the IP
-	if entry.failedAttempts >= lat.maxAttempts {
-		blockedUntil := now.Add(lat.blockDuration)
-		entry.blockedUntil = &blockedUntil
+	if entry.blockedUntil != nil && now.Before(*entry.blockedUntil) {
+		lat.mutex.Unlock()
+		return
+	}
+	entry.blockedUntil = nil
+
+	if entry.failedAttempts == 0 || (limits.Window > 0 && now.Sub(entry.firstFailure) > limits.Window) {
+		entry.failedAttempts = 0
+		entry.firstFailure = now
+	}
+	entry.failedAttempts++
+
+	// If threshold reached, lock the key out, longer on every repeat
+	if entry.failedAttempts < limits.MaxAttempts {
+		lat.mutex.Unlock()
+		return
+	}
+
+	attempts := entry.failedAttempts
+	entry.lockouts++
+	entry.failedAttempts = 0
+	lockout := limits.lockoutFor(entry.lockouts)
+	blockedUntil := now.Add(lockout)
+	entry.blockedUntil = &blockedUntil
+	onLockout := lat.onLockout
+
+	lat.mutex.Unlock()
+
+	if onLockout != nil {
+		onLockout(key, attempts, lockout)
 	}
 }
 
-// RecordSuccess resets failed attempts for a successful login
-func (lat *LoginAttemptTracker) RecordSuccess(ip string) {
+// RecordSuccess resets failed attempts and the lockout backoff for a key
+func (lat *LoginAttemptTracker) RecordSuccess(key string) {
 	lat.mutex.Lock()
 	defer lat.mutex.Unlock()
 
-	// Reset attempts on successful login
-	delete(lat.attempts, ip)
+	delete(lat.attempts, key)
 }
 
-// IsBlocked checks if the IP is currently blocked
-func (lat *LoginAttemptTracker) IsBlocked(ip string) bool {
-	lat.mutex.RLock()
-	defer lat.mutex.RUnlock()
+// IsBlocked checks if the key is currently locked out
+func (lat *LoginAttemptTracker) IsBlocked(key string) bool {
+	return lat.GetRemainingBlockTime(key) > 0
+}
 
-	entry, exists := lat.attempts[ip]
-	if !exists {
-		return false
-	}
+// GetRemainingBlockTime returns the remaining lockout of a key, or 0 if not blocked
+func (lat *LoginAttemptTracker) GetRemainingBlockTime(key string) time.Duration {
+	lat.mutex.Lock()
+	defer lat.mutex.Unlock()
 
-	if entry.blockedUntil == nil {
-		return false
+	entry, exists := lat.attempts[key]
+	if !exists || entry.blockedUntil == nil {
+		return 0
 	}
 
-	// Check if block has expired
-	if time.Now().After(*entry.blockedUntil) {
-		// Block expired, but keep entry for tracking
+	remaining := time.Until(*entry.blockedUntil)
+	if remaining <= 0 {
+		// Lockout expired; keep the entry so the next lockout backs off
 		entry.blockedUntil = nil
 		entry.failedAttempts = 0
-		return false
+		return 0
 	}
 
-	return true
+	return remaining
 }
 
-// GetRemainingBlockTime returns the remaining block time for an IP, or 0 if not blocked
-func (lat *LoginAttemptTracker) GetRemainingBlockTime(ip string) time.Duration {
-	lat.mutex.RLock()
-	defer lat.mutex.RUnlock()
+// accountAttemptKey keeps account counters apart from IP counters.
+func accountAttemptKey(account string) string {
+	return "account:" + strings.ToLower(strings.TrimSpace(account))
+}
 
-	entry, exists := lat.attempts[ip]
-	if !exists || entry.blockedUntil == nil {
-		return 0
+// RecordFailure counts a failed attempt against the IP and, when known, the
+// account it targeted.
+func (lat *LoginAttemptTracker) RecordFailure(ip string, account string) {
+	lat.RecordFailedAttempt(ip)
+	if account != "" {
+		lat.RecordFailedAttempt(accountAttemptKey(account))
 	}
+}
 
-	remaining := time.Until(*entry.blockedUntil)
-	if remaining < 0 {
-		return 0
+// RecordAccountSuccess resets the IP and account counters after a success.
+func (lat *LoginAttemptTracker) RecordAccountSuccess(ip string, account string) {
+	lat.RecordSuccess(ip)
+	if account != "" {
+		lat.RecordSuccess(accountAttemptKey(account))
 	}
+}
 
+// RemainingLockout returns how long the IP or the account stays locked out.
+func (lat *LoginAttemptTracker) RemainingLockout(ip string, account string) time.Duration {
+	remaining := lat.GetRemainingBlockTime(ip)
+	if account != "" {
+		remaining = max(remaining, lat.GetRemainingBlockTime(accountAttemptKey(account)))
+	}
 	return remaining
 }
 
@@ -235,47 +334,26 @@ func (lat *LoginAttemptTracker) cleanup() {
 	for range ticker.C {
 		lat.mutex.Lock()
 		now := time.Now()
-		for ip, entry := range lat.attempts {
-			// Remove if block expired and no recent activity
-			if entry.blockedUntil != nil && now.After(*entry.blockedUntil) {
-				if now.Sub(entry.lastAttempt) > lat.cleanupInterval {
-					delete(lat.attempts, ip)
-				}
+		// Idle keys are forgotten once a maximum lockout has passed, which
+		// also resets their backoff
+		idle := max(lat.cleanupInterval, lat.limits().MaxLockout)
+		for key, entry := range lat.attempts {
+			if entry.blockedUntil != nil && now.Before(*entry.blockedUntil) {
+				continue
+			}
+			if now.Sub(entry.lastAttempt) > idle {
+				delete(lat.attempts, key)
 			}
 		}
 		lat.mutex.Unlock()
 	}
 }
 
-// getRemoteAddr extracts the remote IP address from the request
-func getRemoteAddr(r *http.Request) string {
-	// Check X-Forwarded-For header first (for reverse proxies)
-	if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
-		// X-Forwarded-For can contain multiple IPs, take the first one
-		if idx := strings.Index(forwarded, ","); idx != -1 {
-			return strings.TrimSpace(forwarded[:idx])
-		}
-		return strings.TrimSpace(forwarded)
-	}
-	
-	// Check X-Real-IP header (another common reverse proxy header)
-	if realIP := r.Header.Get("X-Real-IP"); realIP != "" {
-		return strings.TrimSpace(realIP)
-	}
-	
-	// Fall back to RemoteAddr
-	ip := r.RemoteAddr
-	if idx := strings.LastIndex(ip, ":"); idx != -1 {
-		ip = ip[:idx]
-	}
-	return ip
-}
-
 // RateLimitMiddleware provides general rate limiting for all requests
 func RateLimitMiddleware(limiter *RateLimiter) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			ip := getRemoteAddr(r)
+			ip := GetRemoteAddr(r)
 
 			if !limiter.Allow(ip) {
 				w.Header().Set("Content-Type", "application/json")
@@ -297,7 +375,7 @@ func RateLimitMiddleware(limiter *RateLimiter) func(http.Handler) http.Handler {
 func LoginAttemptMiddleware(tracker *LoginAttemptTracker) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			ip := getRemoteAddr(r)
+			ip := GetRemoteAddr(r)
 
 			if tracker.IsBlocked(ip) {
 				remaining := tracker.GetRemainingBlockTime(ip)
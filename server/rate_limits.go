@@ -0,0 +1,237 @@
+// Copyright (C) 2026 Thinline Dynamic Solutions
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>
+
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	RateLimitLogin         = "login"
+	RateLimitPin           = "pin"
+	RateLimitPasswordReset = "passwordReset"
+	RateLimitIngest        = "ingest"
+
+	rateLimitDefaultIngestRequests = 1200
+	rateLimitAlertInterval         = 5 * time.Minute
+)
+
+var rateLimitDefaults = map[string]RateLimitRule{
+	RateLimitLogin:         {MaxAttempts: 6, WindowSeconds: 900, LockoutSeconds: 900, MaxLockoutSeconds: 86400},
+	RateLimitPin:           {MaxAttempts: 10, WindowSeconds: 600, LockoutSeconds: 600, MaxLockoutSeconds: 86400},
+	RateLimitPasswordReset: {MaxAttempts: 5, WindowSeconds: 3600, LockoutSeconds: 3600, MaxLockoutSeconds: 86400},
+	RateLimitIngest:        {MaxAttempts: 20, WindowSeconds: 600, LockoutSeconds: 600, MaxLockoutSeconds: 21600},
+}
+
+var rateLimitLabels = map[string]string{
+	RateLimitLogin:         "Login",
+	RateLimitPin:           "PIN",
+	RateLimitPasswordReset: "Password reset",
+	RateLimitIngest:        "Call upload API key",
+}
+
+// rule returns the configured rule for scope with defaults filled in.
+func (config RateLimitConfig) rule(scope string) RateLimitRule {
+	var rule RateLimitRule
+	switch scope {
+	case RateLimitLogin:
+		rule = config.Login
+	case RateLimitPin:
+		rule = config.Pin
+	case RateLimitPasswordReset:
+		rule = config.PasswordReset
+	case RateLimitIngest:
+		rule = config.Ingest
+	}
+
+	defaults := rateLimitDefaults[scope]
+	if rule.MaxAttempts == 0 {
+		rule.MaxAttempts = defaults.MaxAttempts
+	}
+	if rule.WindowSeconds == 0 {
+		rule.WindowSeconds = defaults.WindowSeconds
+	}
+	if rule.LockoutSeconds == 0 {
+		rule.LockoutSeconds = defaults.LockoutSeconds
+	}
+	if rule.MaxLockoutSeconds == 0 {
+		rule.MaxLockoutSeconds = defaults.MaxLockoutSeconds
+	}
+	rule.MaxLockoutSeconds = max(rule.MaxLockoutSeconds, rule.LockoutSeconds)
+
+	return rule
+}
+
+// limits converts the rule of scope for a LoginAttemptTracker.
+func (config RateLimitConfig) limits(scope string) AttemptLimits {
+	rule := config.rule(scope)
+	if rule.MaxAttempts < 0 {
+		return AttemptLimits{}
+	}
+	return AttemptLimits{
+		MaxAttempts: rule.MaxAttempts,
+		Window:      time.Duration(rule.WindowSeconds) * time.Second,
+		Lockout:     time.Duration(rule.LockoutSeconds) * time.Second,
+		MaxLockout:  time.Duration(rule.MaxLockoutSeconds) * time.Second,
+	}
+}
+
+// ingestRequests returns the call uploads allowed per minute per IP and per
+// API key, 0 for no limit.
+func (config RateLimitConfig) ingestRequests() int {
+	switch {
+	case config.IngestRequestsPerMinute < 0:
+		return 0
+	case config.IngestRequestsPerMinute == 0:
+		return rateLimitDefaultIngestRequests
+	}
+	return config.IngestRequestsPerMinute
+}
+
+// RateLimitAlerts raises a system alert when a key gets locked out, at most
+// once per scope every rateLimitAlertInterval.
+type RateLimitAlerts struct {
+	controller *Controller
+	last       map[string]time.Time
+	suppressed map[string]int
+	mutex      sync.Mutex
+}
+
+// initRateLimits creates the brute-force trackers, reading their thresholds
+// from the options on every attempt.
+func (controller *Controller) initRateLimits() {
+	alerts := &RateLimitAlerts{
+		controller: controller,
+		last:       map[string]time.Time{},
+		suppressed: map[string]int{},
+	}
+
+	tracker := func(scope string) *LoginAttemptTracker {
+		limits := controller.Options.RateLimitConfig.limits(scope)
+		lat := NewLoginAttemptTracker(limits.MaxAttempts, limits.Lockout)
+		lat.SetLimits(func() AttemptLimits { return controller.Options.RateLimitConfig.limits(scope) })
+		lat.OnLockout(func(key string, attempts int, lockout time.Duration) {
+			alerts.lockout(scope, key, attempts, lockout)
+		})
+		return lat
+	}
+
+	controller.LoginAttemptTracker = tracker(RateLimitLogin)
+	controller.PinAttemptTracker = tracker(RateLimitPin)
+	controller.PasswordResetTracker = tracker(RateLimitPasswordReset)
+	controller.IngestAttemptTracker = tracker(RateLimitIngest)
+
+	controller.IngestRateLimiter = NewRateLimiter(rateLimitDefaultIngestRequests, time.Minute)
+	controller.IngestRateLimiter.SetLimit(func() int { return controller.Options.RateLimitConfig.ingestRequests() })
+
+	trustedProxies = func() []string { return controller.Options.RateLimitConfig.TrustedProxies }
+}
+
+func (alerts *RateLimitAlerts) lockout(scope string, key string, attempts int, lockout time.Duration) {
+	label := rateLimitLabels[scope]
+	target := "IP " + key
+	if account, ok := strings.CutPrefix(key, "account:"); ok {
+		target = "account " + account
+	}
+	message := fmt.Sprintf("%s locked out for %s after %d failed %s attempts", target, lockout.Round(time.Second), attempts, strings.ToLower(label))
+
+	controller := alerts.controller
+	controller.Logs.LogEvent(LogLevelWarn, "rate limit: "+message)
+
+	if controller.Options.RateLimitConfig.DisableAlerts || controller.Database == nil {
+		return
+	}
+
+	alerts.mutex.Lock()
+	if time.Since(alerts.last[scope]) < rateLimitAlertInterval {
+		alerts.suppressed[scope]++
+		alerts.mutex.Unlock()
+		return
+	}
+	if n := alerts.suppressed[scope]; n > 0 {
+		message += fmt.Sprintf(" (%d more lockouts since the last alert)", n)
+	}
+	alerts.last[scope] = time.Now()
+	alerts.suppressed[scope] = 0
+	alerts.mutex.Unlock()
+
+	go controller.CreateSystemAlert("rate_limit", "warning", label+" lockout", message, nil, 0)
+}
+
+// writeLockout answers a locked out request with 429 and Retry-After.
+func writeLockout(w http.ResponseWriter, r *http.Request, remaining time.Duration) {
+	w.Header().Set("Retry-After", fmt.Sprintf("%.0f", remaining.Seconds()))
+	writeProblem(w, r, NewAPIError(http.StatusTooManyRequests, ErrCodeRateLimited, fmt.Sprintf("Too many failed attempts. Try again in %s.", remaining.Round(time.Second))))
+}
+
+// userByPin looks up the user of a PIN sent to the API, counting unknown
+// PINs against the client IP. Locked out IPs get no user.
+func (api *Api) userByPin(r *http.Request, pin string) *User {
+	ip := GetRemoteAddr(r)
+	if api.Controller.PinAttemptTracker.IsBlocked(ip) {
+		return nil
+	}
+
+	user := api.Controller.Users.GetUserByPin(pin)
+	if user == nil {
+		api.Controller.PinAttemptTracker.RecordFailedAttempt(ip)
+	}
+
+	return user
+}
+
+// ingestStatusRecorder keeps the status code of a call upload response.
+type ingestStatusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (recorder *ingestStatusRecorder) WriteHeader(status int) {
+	recorder.status = status
+	recorder.ResponseWriter.WriteHeader(status)
+}
+
+// ingestLimitMiddleware rate limits call uploads per IP and locks out IPs
+// that keep sending invalid API keys.
+func (controller *Controller) ingestLimitMiddleware(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ip := GetRemoteAddr(r)
+
+		if remaining := controller.IngestAttemptTracker.GetRemainingBlockTime(ip); remaining > 0 {
+			writeLockout(w, r, remaining)
+			return
+		}
+		if !controller.IngestRateLimiter.Allow(ip) {
+			w.Header().Set("Retry-After", "60")
+			writeProblem(w, r, NewAPIError(http.StatusTooManyRequests, ErrCodeRateLimited, "Too many uploads. Please slow down."))
+			return
+		}
+
+		recorder := &ingestStatusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next(recorder, r)
+
+		switch recorder.status {
+		case http.StatusOK:
+			controller.IngestAttemptTracker.RecordSuccess(ip)
+		case http.StatusUnauthorized:
+			controller.IngestAttemptTracker.RecordFailedAttempt(ip)
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestLoginAttemptTrackerBackoff(t *testing.T) {
+	tracker := NewLoginAttemptTracker(3, time.Minute)
+	tracker.SetLimits(func() AttemptLimits {
+		return AttemptLimits{MaxAttempts: 3, Window: time.Minute, Lockout: time.Minute, MaxLockout: 3 * time.Minute}
+	})
+
+	var lockouts []time.Duration
+	tracker.OnLockout(func(key string, attempts int, lockout time.Duration) {
+		if key != "10.0.0.1" || attempts != 3 {
+			t.Fatalf("unexpected lockout of %s after %d attempts", key, attempts)
+		}
+		lockouts = append(lockouts, lockout)
+	})
+
+	for round := 0; round < 3; round++ {
+		for i := 0; i < 3; i++ {
+			tracker.RecordFailedAttempt("10.0.0.1")
+		}
+		if !tracker.IsBlocked("10.0.0.1") {
+			t.Fatalf("round %d: expected a lockout", round)
+		}
+		// Let the lockout expire without forgetting the backoff
+		tracker.attempts["10.0.0.1"].blockedUntil = new(time.Time)
+	}
+
+	want := []time.Duration{time.Minute, 2 * time.Minute, 3 * time.Minute}
+	if len(lockouts) != len(want) {
+		t.Fatalf("got %d lockouts, want %d", len(lockouts), len(want))
+	}
+	for i := range want {
+		if lockouts[i] != want[i] {
+			t.Fatalf("lockout %d lasted %s, want %s", i, lockouts[i], want[i])
+		}
+	}
+
+	tracker.RecordSuccess("10.0.0.1")
+	if _, ok := tracker.attempts["10.0.0.1"]; ok {
+		t.Fatalf("expected success to reset the backoff")
+	}
+}
+
+func TestLoginAttemptTrackerAccounts(t *testing.T) {
+	tracker := NewLoginAttemptTracker(2, time.Minute)
+
+	tracker.RecordFailure("10.0.0.1", "User@Example.com")
+	tracker.RecordFailure("10.0.0.2", "user@example.com")
+
+	if tracker.IsBlocked("10.0.0.1") || tracker.IsBlocked("10.0.0.2") {
+		t.Fatalf("expected the IPs to stay below the limit")
+	}
+	if tracker.RemainingLockout("10.0.0.3", "user@example.com") == 0 {
+		t.Fatalf("expected the account to be locked out from any IP")
+	}
+
+	tracker.RecordAccountSuccess("10.0.0.3", "user@example.com")
+	if tracker.RemainingLockout("10.0.0.3", "user@example.com") != 0 {
+		t.Fatalf("expected success to clear the account lockout")
+	}
+}
+
+func TestLoginAttemptTrackerDisabled(t *testing.T) {
+	tracker := NewLoginAttemptTracker(1, time.Minute)
+	tracker.SetLimits(func() AttemptLimits { return RateLimitConfig{Pin: RateLimitRule{MaxAttempts: -1}}.limits(RateLimitPin) })
+
+	tracker.RecordFailedAttempt("10.0.0.1")
+	if tracker.IsBlocked("10.0.0.1") {
+		t.Fatalf("expected a disabled rule to never lock out")
+	}
+}
+
+func TestRateLimitConfigDefaults(t *testing.T) {
+	config := RateLimitConfig{Login: RateLimitRule{MaxAttempts: 3, LockoutSeconds: 120, MaxLockoutSeconds: 60}}
+
+	limits := config.limits(RateLimitLogin)
+	if limits.MaxAttempts != 3 || limits.Window != 15*time.Minute || limits.Lockout != 2*time.Minute || limits.MaxLockout != 2*time.Minute {
+		t.Fatalf("unexpected login limits %+v", limits)
+	}
+	if limits := config.limits(RateLimitPasswordReset); limits.MaxAttempts != 5 || limits.Lockout != time.Hour {
+		t.Fatalf("unexpected password reset limits %+v", limits)
+	}
+
+	if n := config.ingestRequests(); n != rateLimitDefaultIngestRequests {
+		t.Fatalf("got %d ingest requests, want the default", n)
+	}
+	if n := (RateLimitConfig{IngestRequestsPerMinute: -1}).ingestRequests(); n != 0 {
+		t.Fatalf("expected -1 to remove the ingest limit, got %d", n)
+	}
+
+	options := NewOptions()
+	options.FromMap(map[string]any{"rateLimitConfig": map[string]any{
+		"pin":                     map[string]any{"maxAttempts": float64(4), "lockoutSeconds": float64(30)},
+		"ingestRequestsPerMinute": float64(-1),
+		"disableAlerts":           true,
+	}})
+	if cfg := options.RateLimitConfig; cfg.Pin.MaxAttempts != 4 || cfg.Pin.LockoutSeconds != 30 || cfg.IngestRequestsPerMinute != -1 || !cfg.DisableAlerts {
+		t.Fatalf("unexpected parsed config %+v", cfg)
+	}
+}
+
+func TestIngestLimitMiddleware(t *testing.T) {
+	controller := &Controller{Options: &Options{}}
+	controller.IngestAttemptTracker = NewLoginAttemptTracker(2, time.Minute)
+	controller.IngestRateLimiter = NewRateLimiter(100, time.Minute)
+
+	status := http.StatusUnauthorized
+	handler := controller.ingestLimitMiddleware(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	})
+
+	upload := func(ip string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, "/api/call-upload", nil)
+		r.RemoteAddr = ip + ":5000"
+		w := httptest.NewRecorder()
+		handler(w, r)
+		return w
+	}
+
+	upload("192.0.2.7")
+	upload("192.0.2.7")
+	status = http.StatusOK
+	if w := upload("192.0.2.7"); w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") == "" {
+		t.Fatalf("expected the IP to be locked out after two bad keys, got %d", w.Code)
+	}
+
+	controller.IngestRateLimiter.SetLimit(func() int { return 1 })
+	if w := upload("192.0.2.8"); w.Code != http.StatusOK {
+		t.Fatalf("expected an upload within the limit, got %d", w.Code)
+	}
+	if w := upload("192.0.2.8"); w.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected the upload rate limit, got %d", w.Code)
+	}
+}
+
+func TestGetRemoteAddrTrustsConfiguredProxies(t *testing.T) {
+	defer func(saved func() []string) { trustedProxies = saved }(trustedProxies)
+
+	request := func(remoteAddr string, forwarded string) *http.Request {
+		r := httptest.NewRequest(http.MethodPost, "/api/call-upload", nil)
+		r.RemoteAddr = remoteAddr
+		if forwarded != "" {
+			r.Header.Set("X-Forwarded-For", forwarded)
+		}
+		return r
+	}
+
+	trustedProxies = func() []string { return nil }
+	if ip := GetRemoteAddr(request("203.0.113.5:4000", "198.51.100.1")); ip != "203.0.113.5" {
+		t.Fatalf("expected a direct client to be unable to spoof its address, got %s", ip)
+	}
+	if ip := GetRemoteAddr(request("127.0.0.1:4000", "198.51.100.1")); ip != "198.51.100.1" {
+		t.Fatalf("expected a local proxy to be trusted by default, got %s", ip)
+	}
+
+	trustedProxies = func() []string { return []string{"10.0.0.0/8", "192.0.2.10"} }
+	if ip := GetRemoteAddr(request("127.0.0.1:4000", "198.51.100.1")); ip != "127.0.0.1" {
+		t.Fatalf("expected loopback to be untrusted once proxies are configured, got %s", ip)
+	}
+	if ip := GetRemoteAddr(request("10.1.2.3:4000", "198.51.100.1, 203.0.113.9, 192.0.2.10")); ip != "203.0.113.9" {
+		t.Fatalf("expected the last address before the trusted proxies, got %s", ip)
+	}
+	if ip := GetRemoteAddr(request("[2001:db8::1]:4000", "198.51.100.1")); ip != "2001:db8::1" {
+		t.Fatalf("expected the IPv6 peer address, got %s", ip)
+	}
+}
@@ -0,0 +1,322 @@
+// Copyright (C) 2026 Thinline Dynamic Solutions
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>
+
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"math"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	ReceiverConfigTrunkRecorder           = "trunk-recorder"
+	ReceiverConfigTrunkRecorderTalkgroups = "trunk-recorder-talkgroups"
+	ReceiverConfigSDRTrunk                = "sdrtrunk"
+)
+
+var receiverShortNameRe = regexp.MustCompile(`[^a-z0-9]+`)
+
+// receiverShortName turns a label into a trunk-recorder shortName.
+func receiverShortName(label string, fallback string) string {
+	name := strings.Trim(receiverShortNameRe.ReplaceAllString(strings.ToLower(label), "-"), "-")
+	if name == "" {
+		return fallback
+	}
+	return name
+}
+
+func mhzToHz(mhz float64) int64 {
+	return int64(math.Round(mhz * 1e6))
+}
+
+// receiverSites returns the sites of system with control channels, or only
+// the one matching siteRef when given.
+func receiverSites(system *System, siteRef string) []*Site {
+	system.Sites.mutex.Lock()
+	defer system.Sites.mutex.Unlock()
+
+	sites := []*Site{}
+	for _, site := range system.Sites.List {
+		if siteRef != "" && site.SiteRef != siteRef {
+			continue
+		}
+		if len(site.ControlChannels) == 0 {
+			continue
+		}
+		sites = append(sites, site)
+	}
+	return sites
+}
+
+// buildTrunkRecorderConfig returns the systems and rdioscanner_uploader plugin
+// sections of a trunk-recorder config.json, one system per site.
+func buildTrunkRecorderConfig(system *System, sites []*Site, serverURL string) map[string]any {
+	base := receiverShortName(system.Label, fmt.Sprintf("system%d", system.SystemRef))
+
+	systems := []map[string]any{}
+	uploads := []map[string]any{}
+	for _, site := range sites {
+		shortName := base
+		if len(sites) > 1 {
+			shortName = fmt.Sprintf("%s-%s", base, receiverShortName(site.SiteRef, strconv.FormatUint(site.Id, 10)))
+		}
+
+		controlChannels := make([]int64, 0, len(site.ControlChannels))
+		for _, f := range site.ControlChannels {
+			controlChannels = append(controlChannels, mhzToHz(f))
+		}
+
+		systems = append(systems, map[string]any{
+			"shortName":        shortName,
+			"type":             "p25",
+			"control_channels": controlChannels,
+			"talkgroupsFile":   base + ".csv",
+		})
+		uploads = append(uploads, map[string]any{
+			"shortName": shortName,
+			"apiKey":    "YOUR_API_KEY",
+			"systemId":  system.SystemRef,
+		})
+	}
+
+	return map[string]any{
+		"systems": systems,
+		"plugins": []map[string]any{
+			{
+				"name":    "rdioscanner_uploader",
+				"library": "librdioscanner_uploader.so",
+				"server":  serverURL,
+				"systems": uploads,
+			},
+		},
+	}
+}
+
+// buildTrunkRecorderTalkgroups returns the talkgroups of system in the
+// trunk-recorder talkgroupsFile CSV format.
+func buildTrunkRecorderTalkgroups(system *System, groups *Groups, tags *Tags) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Write([]string{"Decimal", "Hex", "Alpha Tag", "Mode", "Description", "Tag", "Category"})
+
+	system.Talkgroups.mutex.Lock()
+	talkgroups := append([]*Talkgroup{}, system.Talkgroups.List...)
+	system.Talkgroups.mutex.Unlock()
+
+	for _, talkgroup := range talkgroups {
+		tagLabel := ""
+		if tag, ok := tags.GetTagById(talkgroup.TagId); ok {
+			tagLabel = tag.Label
+		}
+		groupLabel := ""
+		if len(talkgroup.GroupIds) > 0 {
+			if group, ok := groups.GetGroupById(talkgroup.GroupIds[0]); ok {
+				groupLabel = group.Label
+			}
+		}
+		w.Write([]string{
+			strconv.FormatUint(uint64(talkgroup.TalkgroupRef), 10),
+			strconv.FormatUint(uint64(talkgroup.TalkgroupRef), 16),
+			talkgroup.Label,
+			"D",
+			talkgroup.Name,
+			tagLabel,
+			groupLabel,
+		})
+	}
+
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+type sdrTrunkPlaylist struct {
+	XMLName  xml.Name          `xml:"playlist"`
+	Version  int               `xml:"version,attr"`
+	Aliases  []sdrTrunkAlias   `xml:"alias"`
+	Channels []sdrTrunkChannel `xml:"channel"`
+}
+
+type sdrTrunkAlias struct {
+	Group string       `xml:"group,attr,omitempty"`
+	List  string       `xml:"list,attr"`
+	Name  string       `xml:"name,attr"`
+	Ids   []sdrTrunkId `xml:"id"`
+}
+
+type sdrTrunkId struct {
+	Type     string `xml:"type,attr"`
+	Value    uint   `xml:"value,attr"`
+	Protocol string `xml:"protocol,attr"`
+}
+
+type sdrTrunkChannel struct {
+	System        string         `xml:"system,attr"`
+	Site          string         `xml:"site,attr"`
+	Name          string         `xml:"name,attr"`
+	Enabled       bool           `xml:"enabled,attr"`
+	Order         int            `xml:"order,attr"`
+	AliasListName string         `xml:"alias_list_name"`
+	Source        sdrTrunkSource `xml:"source_configuration"`
+	Decode        sdrTrunkDecode `xml:"decode_configuration"`
+}
+
+type sdrTrunkSource struct {
+	Type                   string  `xml:"type,attr"`
+	FrequencyRotationDelay int     `xml:"frequency_rotation_delay,attr"`
+	Frequencies            []int64 `xml:"frequency"`
+}
+
+type sdrTrunkDecode struct {
+	Type       string `xml:"type,attr"`
+	Modulation string `xml:"modulation,attr"`
+}
+
+// buildSDRTrunkPlaylist returns an SDRTrunk playlist with one P25 channel per
+// site, cycling through its control channels, and the talkgroups as aliases.
+func buildSDRTrunkPlaylist(system *System, sites []*Site, groups *Groups) ([]byte, error) {
+	playlist := sdrTrunkPlaylist{Version: 4}
+
+	system.Talkgroups.mutex.Lock()
+	for _, talkgroup := range system.Talkgroups.List {
+		alias := sdrTrunkAlias{
+			List: system.Label,
+			Name: talkgroup.Label,
+			Ids:  []sdrTrunkId{{Type: "talkgroup", Value: talkgroup.TalkgroupRef, Protocol: "APCO25"}},
+		}
+		if len(talkgroup.GroupIds) > 0 {
+			if group, ok := groups.GetGroupById(talkgroup.GroupIds[0]); ok {
+				alias.Group = group.Label
+			}
+		}
+		playlist.Aliases = append(playlist.Aliases, alias)
+	}
+	system.Talkgroups.mutex.Unlock()
+
+	for i, site := range sites {
+		frequencies := make([]int64, 0, len(site.ControlChannels))
+		for _, f := range site.ControlChannels {
+			frequencies = append(frequencies, mhzToHz(f))
+		}
+		playlist.Channels = append(playlist.Channels, sdrTrunkChannel{
+			System:        system.Label,
+			Site:          site.Label,
+			Name:          site.Label,
+			Enabled:       false,
+			Order:         i + 1,
+			AliasListName: system.Label,
+			Source: sdrTrunkSource{
+				Type:                   "sourceConfigTunerMultipleFrequency",
+				FrequencyRotationDelay: 400,
+				Frequencies:            frequencies,
+			},
+			Decode: sdrTrunkDecode{Type: "decodeConfigP25Phase1", Modulation: "C4FM"},
+		})
+	}
+
+	b, err := xml.MarshalIndent(playlist, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), b...), nil
+}
+
+// ReceiverConfigHandler exports the sites and talkgroups of a system, as
+// imported from Radio Reference, as a receiver config:
+// GET /api/admin/receiver-config?systemId=N&format=trunk-recorder|trunk-recorder-talkgroups|sdrtrunk[&siteRef=]
+func (admin *Admin) ReceiverConfigHandler(w http.ResponseWriter, r *http.Request) {
+	writeError := func(status int, message string) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(map[string]string{"error": message})
+	}
+
+	t := admin.GetAuthorization(r)
+	if !admin.ValidatePermission(t, PermissionManageSystems) {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	if r.Method != http.MethodGet {
+		writeError(http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	systemId, err := strconv.ParseUint(r.URL.Query().Get("systemId"), 10, 64)
+	if err != nil {
+		writeError(http.StatusBadRequest, "systemId is required")
+		return
+	}
+	system, ok := admin.Controller.Systems.GetSystemById(systemId)
+	if !ok || !admin.tenantAllowsSystem(t, system.Id) {
+		writeError(http.StatusNotFound, "system not found")
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	base := receiverShortName(system.Label, fmt.Sprintf("system%d", system.SystemRef))
+
+	if format == ReceiverConfigTrunkRecorderTalkgroups {
+		b, err := buildTrunkRecorderTalkgroups(system, admin.Controller.Groups, admin.Controller.Tags)
+		if err != nil {
+			writeError(http.StatusInternalServerError, err.Error())
+			return
+		}
+		w.Header().Set("Content-Type", "text/csv")
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.csv"`, base))
+		w.Write(b)
+		return
+	}
+
+	sites := receiverSites(system, r.URL.Query().Get("siteRef"))
+	if len(sites) == 0 {
+		writeError(http.StatusUnprocessableEntity, "no site with control channels; import the sites from Radio Reference first")
+		return
+	}
+
+	switch format {
+	case ReceiverConfigTrunkRecorder:
+		scheme := "http"
+		if r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https" {
+			scheme = "https"
+		}
+		config := buildTrunkRecorderConfig(system, sites, fmt.Sprintf("%s://%s", scheme, r.Host))
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s-trunk-recorder.json"`, base))
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		enc.Encode(config)
+
+	case ReceiverConfigSDRTrunk:
+		b, err := buildSDRTrunkPlaylist(system, sites, admin.Controller.Groups)
+		if err != nil {
+			writeError(http.StatusInternalServerError, err.Error())
+			return
+		}
+		w.Header().Set("Content-Type", "application/xml")
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s-sdrtrunk.xml"`, base))
+		w.Write(b)
+
+	default:
+		writeError(http.StatusBadRequest, "format must be trunk-recorder, trunk-recorder-talkgroups or sdrtrunk")
+	}
+}
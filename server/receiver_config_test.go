@@ -0,0 +1,90 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestParseSiteListControlChannels(t *testing.T) {
+	body := []byte(`<getTrsSitesResponse><return>
+		<item><siteNumber>5</siteNumber><rfss>1</rfss><siteDescr>North</siteDescr>
+			<siteFreqs>
+				<item><lcn>1</lcn><freq>851.0125</freq><use>d</use></item>
+				<item><lcn>2</lcn><freq>851.5125</freq><use>a</use></item>
+				<item><lcn>3</lcn><freq>852.0125</freq><use></use></item>
+			</siteFreqs>
+		</item>
+	</return></getTrsSitesResponse>`)
+
+	sites, err := parseSiteList(body)
+	if err != nil {
+		t.Fatalf("parseSiteList: %v", err)
+	}
+	if len(sites) != 1 {
+		t.Fatalf("got %d sites, want 1", len(sites))
+	}
+	site := sites[0]
+	if site.ID != "1-005" || site.SiteNumber != 5 || site.RFSS != 1 {
+		t.Fatalf("site = %+v", site)
+	}
+	if len(site.ControlChannels) != 1 || site.ControlChannels[0] != 851.0125 {
+		t.Fatalf("control channels = %v", site.ControlChannels)
+	}
+	if len(site.AlternateControlChannels) != 1 || site.AlternateControlChannels[0] != 851.5125 {
+		t.Fatalf("alternate control channels = %v", site.AlternateControlChannels)
+	}
+	if len(site.Channels) != 3 || site.Channels[2].Lcn != 3 || site.Channels[2].Use != "" {
+		t.Fatalf("channels = %+v", site.Channels)
+	}
+}
+
+func receiverTestSystem() *System {
+	system := &System{Label: "County P25", SystemRef: 7, Sites: NewSites(), Talkgroups: NewTalkgroups()}
+	system.Sites.List = []*Site{
+		{Id: 1, Label: "North", SiteRef: "1-005", ControlChannels: []float64{851.0125, 851.5125}},
+		{Id: 2, Label: "Voice only", SiteRef: "1-006"},
+	}
+	system.Talkgroups.List = []*Talkgroup{{TalkgroupRef: 1001, Label: "FD Dispatch", Name: "Fire Dispatch"}}
+	return system
+}
+
+func TestBuildTrunkRecorderConfig(t *testing.T) {
+	system := receiverTestSystem()
+	sites := receiverSites(system, "")
+	if len(sites) != 1 {
+		t.Fatalf("got %d sites with control channels, want 1", len(sites))
+	}
+
+	b, _ := json.Marshal(buildTrunkRecorderConfig(system, sites, "https://scanner.example"))
+	out := string(b)
+	for _, want := range []string{`"shortName":"county-p25"`, `"control_channels":[851012500,851512500]`, `"talkgroupsFile":"county-p25.csv"`, `"server":"https://scanner.example"`, `"systemId":7`} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("config missing %s: %s", want, out)
+		}
+	}
+}
+
+func TestBuildSDRTrunkPlaylist(t *testing.T) {
+	system := receiverTestSystem()
+	b, err := buildSDRTrunkPlaylist(system, receiverSites(system, ""), NewGroups())
+	if err != nil {
+		t.Fatalf("buildSDRTrunkPlaylist: %v", err)
+	}
+	out := string(b)
+	for _, want := range []string{`<frequency>851012500</frequency>`, `value="1001"`, `decodeConfigP25Phase1`, `site="North"`} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("playlist missing %s: %s", want, out)
+		}
+	}
+}
+
+func TestBuildTrunkRecorderTalkgroups(t *testing.T) {
+	b, err := buildTrunkRecorderTalkgroups(receiverTestSystem(), NewGroups(), NewTags())
+	if err != nil {
+		t.Fatalf("buildTrunkRecorderTalkgroups: %v", err)
+	}
+	if !strings.Contains(string(b), "1001,3e9,FD Dispatch,D,Fire Dispatch,,") {
+		t.Fatalf("csv = %s", b)
+	}
+}
@@ -106,7 +106,7 @@ func (rm *ReconnectionManager) BufferCallForDisconnected(call *Call) {
 		}
 
 		// Check if user's filters would allow this call
-		if !state.Livefeed.IsEnabled(call) {
+		if !rm.controller.livefeedAllows(state.Livefeed, call) {
 			continue
 		}
 
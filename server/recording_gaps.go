@@ -0,0 +1,448 @@
+// Copyright (C) 2026 Thinline Dynamic Solutions
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+)
+
+const (
+	recordingGapDefaultMinCalls        = 4
+	recordingGapDefaultMinSiblingCalls = 20
+	recordingGapDefaultHistoryDays     = 14
+	recordingGapRetentionDays          = 90
+
+	// recordingGapMinActiveShare is the share of previous days a talkgroup
+	// must have had calls in the window to count as normally busy.
+	recordingGapMinActiveShare = 0.8
+)
+
+// RecordingGapConfig detects talkgroups that go silent while the rest of
+// their system is busy, usually a recorder that lost their frequency. The
+// check reuses the hourly windows of the activity anomaly monitor.
+type RecordingGapConfig struct {
+	Enabled         bool `json:"enabled"`
+	MinCalls        uint `json:"minCalls,omitempty"`        // normal calls in the hour, default 4
+	MinSiblingCalls uint `json:"minSiblingCalls,omitempty"` // calls on the other talkgroups, default 20
+	HistoryDays     uint `json:"historyDays,omitempty"`     // default 14
+	Alerts          bool `json:"alerts,omitempty"`          // raise a recording_gap system alert
+}
+
+func (config RecordingGapConfig) minCalls() float64 {
+	if config.MinCalls == 0 {
+		return recordingGapDefaultMinCalls
+	}
+	return float64(config.MinCalls)
+}
+
+func (config RecordingGapConfig) minSiblingCalls() int {
+	if config.MinSiblingCalls == 0 {
+		return recordingGapDefaultMinSiblingCalls
+	}
+	return int(config.MinSiblingCalls)
+}
+
+func (config RecordingGapConfig) historyDays() int {
+	if config.HistoryDays == 0 {
+		return recordingGapDefaultHistoryDays
+	}
+	return int(config.HistoryDays)
+}
+
+// RecordingGap is a period without calls on a normally busy talkgroup. An
+// EndedAt of 0 is a gap still open.
+type RecordingGap struct {
+	Id             uint64  `json:"id"`
+	SystemId       uint64  `json:"systemId"`
+	SystemRef      uint    `json:"systemRef,omitempty"`
+	SystemLabel    string  `json:"systemLabel,omitempty"`
+	TalkgroupId    uint64  `json:"talkgroupId"`
+	TalkgroupRef   uint    `json:"talkgroupRef,omitempty"`
+	TalkgroupLabel string  `json:"talkgroupLabel,omitempty"`
+	StartedAt      int64   `json:"startedAt"`
+	EndedAt        int64   `json:"endedAt"`
+	ExpectedCalls  float64 `json:"expectedCalls"`
+	SiblingCalls   int     `json:"siblingCalls"`
+}
+
+// recordingGapCandidate is a talkgroup without calls in the current window.
+type recordingGapCandidate struct {
+	key          activityCountKey
+	expected     float64
+	siblingCalls int
+}
+
+// findRecordingGaps returns the talkgroups that had calls in the window on
+// most previous days but none now, on systems whose other talkgroups had at
+// least minSiblingCalls calls. counts is as returned by countActivityWindows.
+func findRecordingGaps(counts map[activityCountKey][]int, minCalls float64, minSiblingCalls int) []recordingGapCandidate {
+	systemCalls := map[uint64]int{}
+	for key, windows := range counts {
+		systemCalls[key.systemId] += windows[0]
+	}
+
+	var candidates []recordingGapCandidate
+	for key, windows := range counts {
+		if windows[0] > 0 || len(windows) < 2 || systemCalls[key.systemId] < minSiblingCalls {
+			continue
+		}
+
+		history := windows[1:]
+		active := 0
+		for _, count := range history {
+			if count > 0 {
+				active++
+			}
+		}
+		baseline := newActivityBaseline(history)
+		if baseline.Mean < minCalls || float64(active) < recordingGapMinActiveShare*float64(len(history)) {
+			continue
+		}
+
+		candidates = append(candidates, recordingGapCandidate{key: key, expected: baseline.Mean, siblingCalls: systemCalls[key.systemId]})
+	}
+
+	sort.Slice(candidates, func(i int, j int) bool {
+		if candidates[i].key.systemId != candidates[j].key.systemId {
+			return candidates[i].key.systemId < candidates[j].key.systemId
+		}
+		return candidates[i].key.talkgroupId < candidates[j].key.talkgroupId
+	})
+	return candidates
+}
+
+// MonitorRecordingGaps closes the gaps of talkgroups that have calls again
+// and opens a gap for every normally busy talkgroup gone silent while its
+// system is not.
+func (controller *Controller) MonitorRecordingGaps() {
+	config := controller.Options.RecordingGapConfig
+	if !config.Enabled {
+		return
+	}
+
+	now := time.Now()
+
+	open := map[activityCountKey]bool{}
+	rows, err := controller.Database.Sql.Query(`SELECT "recordingGapId", "systemId", "talkgroupId", "startedAt" FROM "recordingGaps" WHERE "endedAt" = 0`)
+	if err != nil {
+		controller.Logs.LogEvent(LogLevelError, fmt.Sprintf("failed to read recording gaps: %v", err))
+		return
+	}
+	var gaps []RecordingGap
+	for rows.Next() {
+		gap := RecordingGap{}
+		if err := rows.Scan(&gap.Id, &gap.SystemId, &gap.TalkgroupId, &gap.StartedAt); err == nil {
+			gaps = append(gaps, gap)
+		}
+	}
+	rows.Close()
+
+	for _, gap := range gaps {
+		var resumedAt int64
+		if err := controller.Database.Sql.QueryRow(`SELECT COALESCE(MIN("timestamp"), 0) FROM "calls" WHERE "systemId" = $1 AND "talkgroupId" = $2 AND "timestamp" > $3`, gap.SystemId, gap.TalkgroupId, gap.StartedAt).Scan(&resumedAt); err != nil {
+			continue
+		}
+		if resumedAt == 0 {
+			open[activityCountKey{gap.SystemId, gap.TalkgroupId}] = true
+			continue
+		}
+		if _, err := controller.Database.Sql.Exec(`UPDATE "recordingGaps" SET "endedAt" = $1 WHERE "recordingGapId" = $2`, resumedAt, gap.Id); err != nil {
+			controller.Logs.LogEvent(LogLevelError, fmt.Sprintf("failed to close recording gap %d: %v", gap.Id, err))
+		}
+	}
+
+	// A few days are needed before "normally busy" means anything
+	days := controller.activityHistoryDays(now, config.historyDays())
+	if days < 3 {
+		return
+	}
+
+	counts, err := controller.countActivityWindows(now, days)
+	if err != nil {
+		controller.Logs.LogEvent(LogLevelError, fmt.Sprintf("failed to count talkgroup activity: %v", err))
+		return
+	}
+
+	for _, candidate := range findRecordingGaps(counts, config.minCalls(), config.minSiblingCalls()) {
+		if open[candidate.key] {
+			continue
+		}
+
+		system, ok := controller.Systems.GetSystemById(candidate.key.systemId)
+		if !ok {
+			continue
+		}
+		talkgroup, ok := system.Talkgroups.GetTalkgroupById(candidate.key.talkgroupId)
+		if !ok {
+			continue
+		}
+
+		// The gap starts at the last call, at most a window before now
+		startedAt := now.Add(-activityAnomalyWindow).UnixMilli()
+		var lastCall int64
+		if err := controller.Database.Sql.QueryRow(`SELECT COALESCE(MAX("timestamp"), 0) FROM "calls" WHERE "systemId" = $1 AND "talkgroupId" = $2 AND "timestamp" <= $3`, system.Id, talkgroup.Id, now.UnixMilli()).Scan(&lastCall); err == nil && lastCall > startedAt {
+			startedAt = lastCall
+		}
+
+		if _, err := controller.Database.Sql.Exec(`INSERT INTO "recordingGaps" ("systemId", "talkgroupId", "startedAt", "endedAt", "expectedCalls", "siblingCalls") VALUES ($1, $2, $3, 0, $4, $5)`, system.Id, talkgroup.Id, startedAt, candidate.expected, candidate.siblingCalls); err != nil {
+			controller.Logs.LogEvent(LogLevelError, fmt.Sprintf("failed to store recording gap: %v", err))
+			continue
+		}
+		controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("recording gap on %s / %s: no calls in the last hour, normally %.1f, while the system had %d", system.Label, talkgroup.Label, candidate.expected, candidate.siblingCalls))
+
+		if config.Alerts && controller.Options.SystemHealthAlertsEnabled {
+			controller.CreateSystemAlert(
+				"recording_gap",
+				"warning",
+				fmt.Sprintf("Recording Gap: %s", talkgroup.Label),
+				fmt.Sprintf("%s / %s had no calls in the last hour, normally %.1f at this time of day, while the other talkgroups of the system had %d. A recorder may have missed its frequency.", system.Label, talkgroup.Label, candidate.expected, candidate.siblingCalls),
+				&SystemAlertData{
+					SystemId:       system.Id,
+					SystemLabel:    system.Label,
+					TalkgroupId:    talkgroup.Id,
+					TalkgroupLabel: talkgroup.Label,
+					Count:          candidate.siblingCalls,
+					Baseline:       candidate.expected,
+				},
+				0, // System-generated
+			)
+		}
+	}
+}
+
+// PruneRecordingGaps drops the gaps ended longer ago than the retention.
+func (controller *Controller) PruneRecordingGaps() error {
+	cutoff := time.Now().Add(-24 * time.Hour * recordingGapRetentionDays).UnixMilli()
+
+	_, err := controller.Database.Sql.Exec(`DELETE FROM "recordingGaps" WHERE "endedAt" > 0 AND "endedAt" < $1`, cutoff)
+	return err
+}
+
+// readRecordingGaps returns the gaps overlapping the period, of one system
+// or talkgroup when their ids are not 0, the most recent first.
+func (controller *Controller) readRecordingGaps(systemId uint64, talkgroupId uint64, from int64, to int64) ([]RecordingGap, error) {
+	rows, err := controller.Database.Sql.Query(`SELECT "recordingGapId", "systemId", "talkgroupId", "startedAt", "endedAt", "expectedCalls", "siblingCalls" FROM "recordingGaps" WHERE ($1 = 0 OR "systemId" = $1) AND ($2 = 0 OR "talkgroupId" = $2) AND "startedAt" < $4 AND ("endedAt" = 0 OR "endedAt" > $3) ORDER BY "startedAt" DESC`, systemId, talkgroupId, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	gaps := []RecordingGap{}
+	for rows.Next() {
+		gap := RecordingGap{}
+		if err := rows.Scan(&gap.Id, &gap.SystemId, &gap.TalkgroupId, &gap.StartedAt, &gap.EndedAt, &gap.ExpectedCalls, &gap.SiblingCalls); err != nil {
+			return nil, err
+		}
+		if system, ok := controller.Systems.GetSystemById(gap.SystemId); ok {
+			gap.SystemRef, gap.SystemLabel = system.SystemRef, system.Label
+			if talkgroup, ok := system.Talkgroups.GetTalkgroupById(gap.TalkgroupId); ok {
+				gap.TalkgroupRef, gap.TalkgroupLabel = talkgroup.TalkgroupRef, talkgroup.Label
+			}
+		}
+		gaps = append(gaps, gap)
+	}
+	return gaps, rows.Err()
+}
+
+// AvailabilityBucket is one hour of a talkgroup timeline.
+type AvailabilityBucket struct {
+	Start        int64 `json:"start"`
+	Calls        int   `json:"calls"`
+	SiblingCalls int   `json:"siblingCalls"`
+	Gap          bool  `json:"gap"`
+}
+
+// availabilityTimeline splits the period into buckets of bucketMs with the
+// calls of the talkgroup and of the rest of its system, keyed by bucket
+// index, and returns the share of the period outside gaps.
+func availabilityTimeline(from int64, to int64, bucketMs int64, calls map[int64]int, siblingCalls map[int64]int, gaps []RecordingGap) ([]AvailabilityBucket, float64) {
+	buckets := []AvailabilityBucket{}
+	for start, index := from, int64(0); start < to; start, index = start+bucketMs, index+1 {
+		buckets = append(buckets, AvailabilityBucket{Start: start, Calls: calls[index], SiblingCalls: siblingCalls[index]})
+	}
+
+	var gapped int64
+	for _, gap := range gaps {
+		start, end := max(gap.StartedAt, from), to
+		if gap.EndedAt > 0 && gap.EndedAt < to {
+			end = gap.EndedAt
+		}
+		if end <= start {
+			continue
+		}
+		gapped += end - start
+		for i := range buckets {
+			if buckets[i].Start < end && buckets[i].Start+bucketMs > start {
+				buckets[i].Gap = true
+			}
+		}
+	}
+
+	if to <= from {
+		return buckets, 1
+	}
+	return buckets, math.Round(math.Max(1-float64(gapped)/float64(to-from), 0)*1000) / 1000
+}
+
+// RecordingGapsHandler lists the recording gaps, optionally of one system.
+// GET /api/admin/recording-gaps?days=<n>&systemRef=<ref>; days defaults to 7.
+//
+// With talkgroupRef too, it returns the availability timeline of the
+// talkgroup: its calls per hour, the calls of the rest of the system and the
+// gaps. GET /api/admin/recording-gaps?systemRef=<ref>&talkgroupRef=<ref>&hours=<n>;
+// hours defaults to 24 and is capped at 7 days.
+func (admin *Admin) RecordingGapsHandler(w http.ResponseWriter, r *http.Request) {
+	t := admin.GetAuthorization(r)
+	if !admin.ValidateToken(t) {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	writeError := func(status int, message string) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(map[string]string{"error": message})
+	}
+
+	query := r.URL.Query()
+	positive := func(name string, fallback int) (int, bool) {
+		s := query.Get(name)
+		if s == "" {
+			return fallback, true
+		}
+		v, err := strconv.Atoi(s)
+		if err != nil || v < 1 {
+			writeError(http.StatusBadRequest, fmt.Sprintf("%s must be a positive number", name))
+			return 0, false
+		}
+		return v, true
+	}
+
+	var system *System
+	if query.Get("systemRef") != "" {
+		ref, ok := positive("systemRef", 0)
+		if !ok {
+			return
+		}
+		if system, ok = admin.Controller.Systems.GetSystemByRef(uint(ref)); !ok {
+			writeError(http.StatusNotFound, "system not found")
+			return
+		}
+	}
+
+	now := time.Now().UnixMilli()
+
+	if query.Get("talkgroupRef") == "" {
+		days, ok := positive("days", 7)
+		if !ok {
+			return
+		}
+		var systemId uint64
+		if system != nil {
+			systemId = system.Id
+		}
+		gaps, err := admin.Controller.readRecordingGaps(systemId, 0, now-int64(days)*24*60*60*1000, now)
+		if err != nil {
+			writeError(http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{
+			"enabled": admin.Controller.Options.RecordingGapConfig.Enabled,
+			"days":    days,
+			"gaps":    gaps,
+		})
+		return
+	}
+
+	if system == nil {
+		writeError(http.StatusBadRequest, "talkgroupRef needs a systemRef")
+		return
+	}
+	ref, ok := positive("talkgroupRef", 0)
+	if !ok {
+		return
+	}
+	talkgroup, ok := system.Talkgroups.GetTalkgroupByRef(uint(ref))
+	if !ok {
+		writeError(http.StatusNotFound, "talkgroup not found")
+		return
+	}
+	hours, ok := positive("hours", 24)
+	if !ok {
+		return
+	}
+	hours = min(hours, 7*24)
+
+	// Hourly buckets, the last one holding the current hour
+	const bucketMs = int64(time.Hour / time.Millisecond)
+	from := (now/bucketMs+1)*bucketMs - int64(hours)*bucketMs
+	to := now
+
+	rows, err := admin.Controller.Database.Sql.Query(`SELECT ("timestamp" - $3) / $4 AS "bucket", SUM(CASE WHEN "talkgroupId" = $2 THEN 1 ELSE 0 END), COUNT(*) FROM "calls" WHERE "systemId" = $1 AND "timestamp" >= $3 AND "timestamp" < $5 GROUP BY "bucket"`, system.Id, talkgroup.Id, from, bucketMs, to)
+	if err != nil {
+		writeError(http.StatusInternalServerError, err.Error())
+		return
+	}
+	defer rows.Close()
+
+	calls, siblingCalls := map[int64]int{}, map[int64]int{}
+	for rows.Next() {
+		var bucket int64
+		var own, total int
+		if err := rows.Scan(&bucket, &own, &total); err != nil {
+			writeError(http.StatusInternalServerError, err.Error())
+			return
+		}
+		calls[bucket], siblingCalls[bucket] = own, total-own
+	}
+	if err := rows.Err(); err != nil {
+		writeError(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	gaps, err := admin.Controller.readRecordingGaps(system.Id, talkgroup.Id, from, to)
+	if err != nil {
+		writeError(http.StatusInternalServerError, err.Error())
+		return
+	}
+	buckets, availability := availabilityTimeline(from, to, bucketMs, calls, siblingCalls, gaps)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"systemRef":      system.SystemRef,
+		"systemLabel":    system.Label,
+		"talkgroupRef":   talkgroup.TalkgroupRef,
+		"talkgroupLabel": talkgroup.Label,
+		"from":           from,
+		"to":             to,
+		"availability":   availability,
+		"buckets":        buckets,
+		"gaps":           gaps,
+	})
+}
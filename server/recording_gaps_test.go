@@ -0,0 +1,65 @@
+package main
+
+import "testing"
+
+func TestFindRecordingGaps(t *testing.T) {
+	busy := activityCountKey{systemId: 1, talkgroupId: 10}
+	dispatch := activityCountKey{systemId: 1, talkgroupId: 11}
+	occasional := activityCountKey{systemId: 1, talkgroupId: 12}
+	idleSystem := activityCountKey{systemId: 2, talkgroupId: 20}
+
+	counts := map[activityCountKey][]int{
+		busy:       {0, 6, 5, 7, 6, 5},
+		dispatch:   {25, 20, 22, 18, 24, 21},
+		occasional: {0, 0, 12, 0, 0, 15},
+		idleSystem: {0, 8, 9, 7, 8, 9},
+	}
+
+	gaps := findRecordingGaps(counts, 4, 20)
+	if len(gaps) != 1 || gaps[0].key != busy || gaps[0].siblingCalls != 25 || gaps[0].expected != 5.8 {
+		t.Fatalf("expected a gap on the busy talkgroup only, got %+v", gaps)
+	}
+
+	if gaps := findRecordingGaps(counts, 4, 30); len(gaps) != 0 {
+		t.Fatalf("expected no gap with a quieter system, got %+v", gaps)
+	}
+	if gaps := findRecordingGaps(counts, 10, 20); len(gaps) != 0 {
+		t.Fatalf("expected no gap under the normal calls, got %+v", gaps)
+	}
+
+	counts[busy][0] = 1
+	if gaps := findRecordingGaps(counts, 4, 20); len(gaps) != 0 {
+		t.Fatalf("expected no gap with a call in the window, got %+v", gaps)
+	}
+}
+
+func TestAvailabilityTimeline(t *testing.T) {
+	const hour = int64(60 * 60 * 1000)
+	const from = 1000 * hour
+
+	calls := map[int64]int{0: 5, 3: 2}
+	siblings := map[int64]int{0: 20, 1: 30, 2: 25, 3: 18}
+	gaps := []RecordingGap{
+		{StartedAt: from + hour + hour/2, EndedAt: from + 3*hour},
+		{StartedAt: from - hour, EndedAt: from - hour/2},
+	}
+
+	buckets, availability := availabilityTimeline(from, from+4*hour, hour, calls, siblings, gaps)
+	if len(buckets) != 4 {
+		t.Fatalf("expected 4 buckets, got %d", len(buckets))
+	}
+	if buckets[0].Calls != 5 || buckets[1].SiblingCalls != 30 || buckets[3].Calls != 2 {
+		t.Fatalf("counts: %+v", buckets)
+	}
+	if buckets[0].Gap || !buckets[1].Gap || !buckets[2].Gap || buckets[3].Gap {
+		t.Fatalf("gaps: %+v", buckets)
+	}
+	if availability != 0.625 {
+		t.Fatalf("expected 0.625 availability, got %v", availability)
+	}
+
+	// An open gap runs to the end of the period
+	if _, availability := availabilityTimeline(from, from+4*hour, hour, nil, nil, []RecordingGap{{StartedAt: from + 3*hour}}); availability != 0.75 {
+		t.Fatalf("expected 0.75 availability with an open gap, got %v", availability)
+	}
+}
@@ -0,0 +1,639 @@
+// Copyright (C) 2026 Thinline Dynamic Solutions
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>
+
+package main
+
+import (
+	"archive/zip"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/http"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	recordingCheckInterval = time.Minute
+	recordingMaxWindow     = 24 * time.Hour
+	recordingMaxPending    = 10
+	recordingMaxTalkgroups = 100
+	recordingRetentionDays = 30
+)
+
+const (
+	RecordingStatusScheduled = "scheduled"
+	RecordingStatusRecording = "recording"
+	RecordingStatusReady     = "ready"
+	RecordingStatusEmpty     = "empty"
+)
+
+// RecordingTalkgroup is one talkgroup of a recording, by database ids.
+type RecordingTalkgroup struct {
+	SystemId    uint64 `json:"systemId"`
+	TalkgroupId uint64 `json:"talkgroupId"`
+}
+
+// Recording is a time-shift recording: the user picks talkgroups and a
+// window ahead of time, and once the window is over the calls heard in it are
+// kept as a playlist that can be downloaded as a ZIP.
+type Recording struct {
+	Id          uint64
+	UserId      uint64
+	Label       string
+	Talkgroups  []RecordingTalkgroup
+	StartAt     int64 // unix ms
+	EndAt       int64 // unix ms
+	Status      string
+	CallIds     []uint64
+	CreatedAt   int64
+	CompletedAt int64
+}
+
+// Recordings completes time-shift recordings once their window is over.
+type Recordings struct {
+	controller *Controller
+	mutex      sync.Mutex
+	stopChan   chan struct{}
+}
+
+func NewRecordings(controller *Controller) *Recordings {
+	return &Recordings{
+		controller: controller,
+		stopChan:   make(chan struct{}),
+	}
+}
+
+// Start checks for finished recordings every minute.
+func (recordings *Recordings) Start() {
+	go func() {
+		ticker := time.NewTicker(recordingCheckInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := recordings.Process(); err != nil {
+					recordings.controller.Logs.LogEvent(LogLevelError, fmt.Sprintf("recordings: %v", err))
+				}
+			case <-recordings.stopChan:
+				return
+			}
+		}
+	}()
+}
+
+// Stop signals the background goroutine to exit.
+func (recordings *Recordings) Stop() {
+	select {
+	case <-recordings.stopChan:
+	default:
+		close(recordings.stopChan)
+	}
+}
+
+// validateRecordingWindow checks a window requested at now.
+func validateRecordingWindow(startAt int64, endAt int64, now time.Time) error {
+	if startAt <= 0 || endAt <= 0 {
+		return fmt.Errorf("start and end are required")
+	}
+	if endAt <= startAt {
+		return fmt.Errorf("end must be after start")
+	}
+	if time.Duration(endAt-startAt)*time.Millisecond > recordingMaxWindow {
+		return fmt.Errorf("a recording can last at most %d hours", int(recordingMaxWindow.Hours()))
+	}
+	if endAt <= now.UnixMilli() {
+		return fmt.Errorf("the window is already over")
+	}
+	return nil
+}
+
+// recordingStatus returns the stored status, or "recording" while the
+// window of a scheduled recording is open.
+func recordingStatus(recording *Recording, now time.Time) string {
+	if recording.Status == RecordingStatusScheduled && now.UnixMilli() >= recording.StartAt {
+		return RecordingStatusRecording
+	}
+	return recording.Status
+}
+
+const recordingColumns = `"recordingId", "userId", "label", "talkgroups", "startAt", "endAt", "status", "callIds", "createdAt", "completedAt"`
+
+func scanRecording(scan func(dest ...any) error) (*Recording, error) {
+	recording := &Recording{}
+	var talkgroupsJson, callIdsJson string
+	if err := scan(&recording.Id, &recording.UserId, &recording.Label, &talkgroupsJson, &recording.StartAt, &recording.EndAt, &recording.Status, &callIdsJson, &recording.CreatedAt, &recording.CompletedAt); err != nil {
+		return nil, err
+	}
+	json.Unmarshal([]byte(talkgroupsJson), &recording.Talkgroups)
+	json.Unmarshal([]byte(callIdsJson), &recording.CallIds)
+	return recording, nil
+}
+
+func (recordings *Recordings) query(where string, args ...any) ([]*Recording, error) {
+	rows, err := recordings.controller.Database.Sql.Query(`SELECT `+recordingColumns+` FROM "recordings" WHERE `+where+` ORDER BY "startAt" DESC`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []*Recording{}
+	for rows.Next() {
+		recording, err := scanRecording(rows.Scan)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, recording)
+	}
+	return list, rows.Err()
+}
+
+// ForUser returns the recordings of the user, latest first.
+func (recordings *Recordings) ForUser(userId uint64) ([]*Recording, error) {
+	return recordings.query(`"userId" = $1`, userId)
+}
+
+// Get returns the user's recording, or nil.
+func (recordings *Recordings) Get(userId uint64, id uint64) (*Recording, error) {
+	recording, err := scanRecording(recordings.controller.Database.Sql.QueryRow(`SELECT `+recordingColumns+` FROM "recordings" WHERE "recordingId" = $1 AND "userId" = $2`, id, userId).Scan)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return recording, err
+}
+
+func (recordings *Recordings) Add(recording *Recording) error {
+	talkgroupsJson, _ := json.Marshal(recording.Talkgroups)
+	recording.Status = RecordingStatusScheduled
+	recording.CreatedAt = time.Now().UnixMilli()
+
+	return recordings.controller.Database.Sql.QueryRow(
+		`INSERT INTO "recordings" ("userId", "label", "talkgroups", "startAt", "endAt", "status", "callIds", "createdAt", "completedAt") VALUES ($1, $2, $3, $4, $5, $6, '[]', $7, 0) RETURNING "recordingId"`,
+		recording.UserId, recording.Label, string(talkgroupsJson), recording.StartAt, recording.EndAt, recording.Status, recording.CreatedAt,
+	).Scan(&recording.Id)
+}
+
+func (recordings *Recordings) Delete(userId uint64, id uint64) (bool, error) {
+	res, err := recordings.controller.Database.Sql.Exec(`DELETE FROM "recordings" WHERE "recordingId" = $1 AND "userId" = $2`, id, userId)
+	if err != nil {
+		return false, err
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// resolve returns the call of the recording talkgroup, without audio, for
+// access and delay checks.
+func (recordings *Recordings) resolve(talkgroup RecordingTalkgroup) *Call {
+	system, ok := recordings.controller.Systems.GetSystemById(talkgroup.SystemId)
+	if !ok {
+		return nil
+	}
+	tg, ok := system.Talkgroups.GetTalkgroupById(talkgroup.TalkgroupId)
+	if !ok {
+		return nil
+	}
+	return &Call{System: system, Talkgroup: tg}
+}
+
+// readyAt is when the recording can be completed: the end of the window plus
+// the longest delay the user has on its talkgroups, so the playlist never
+// holds calls the user could not play live yet.
+func (recordings *Recordings) readyAt(recording *Recording, user *User) time.Time {
+	var delay uint
+	for _, talkgroup := range recording.Talkgroups {
+		if call := recordings.resolve(talkgroup); call != nil {
+			if d := recordings.controller.userEffectiveDelay(user, call, recordings.controller.Options.DefaultSystemDelay); d > delay {
+				delay = d
+			}
+		}
+	}
+	return time.UnixMilli(recording.EndAt).Add(time.Duration(delay) * time.Minute)
+}
+
+// Process completes the recordings whose window is over.
+func (recordings *Recordings) Process() error {
+	recordings.mutex.Lock()
+	defer recordings.mutex.Unlock()
+
+	now := time.Now()
+	pending, err := recordings.query(`"status" = $1 AND "endAt" <= $2`, RecordingStatusScheduled, now.UnixMilli())
+	if err != nil {
+		return err
+	}
+
+	for _, recording := range pending {
+		user := recordings.controller.Users.GetUserById(recording.UserId)
+		if user == nil {
+			continue
+		}
+		if now.Before(recordings.readyAt(recording, user)) {
+			continue
+		}
+		if err := recordings.complete(recording, user); err != nil {
+			recordings.controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("recording %d: %v", recording.Id, err))
+		}
+	}
+	return nil
+}
+
+// complete stores the calls of the window the user can play as the
+// recording's playlist and lets the user know.
+func (recordings *Recordings) complete(recording *Recording, user *User) error {
+	allowed := map[uint64]bool{}
+	var ids []string
+	for _, talkgroup := range recording.Talkgroups {
+		if call := recordings.resolve(talkgroup); call != nil && recordings.controller.userHasAccess(user, call) {
+			allowed[talkgroup.TalkgroupId] = true
+			ids = append(ids, strconv.FormatUint(talkgroup.TalkgroupId, 10))
+		}
+	}
+
+	callIds := []uint64{}
+	if len(ids) > 0 {
+		rows, err := recordings.controller.Database.Sql.Query(
+			fmt.Sprintf(`SELECT "callId", "talkgroupId" FROM "calls" WHERE "timestamp" >= $1 AND "timestamp" < $2 AND "talkgroupId" IN (%s) ORDER BY "timestamp"`, strings.Join(ids, ", ")),
+			recording.StartAt, recording.EndAt,
+		)
+		if err != nil {
+			return err
+		}
+		for rows.Next() {
+			var callId, talkgroupId uint64
+			if err := rows.Scan(&callId, &talkgroupId); err == nil && allowed[talkgroupId] {
+				callIds = append(callIds, callId)
+			}
+		}
+		rows.Close()
+	}
+
+	recording.CallIds = callIds
+	recording.CompletedAt = time.Now().UnixMilli()
+	recording.Status = RecordingStatusReady
+	if len(callIds) == 0 {
+		recording.Status = RecordingStatusEmpty
+	}
+
+	callIdsJson, _ := json.Marshal(callIds)
+	if _, err := recordings.controller.Database.Sql.Exec(
+		`UPDATE "recordings" SET "status" = $1, "callIds" = $2, "completedAt" = $3 WHERE "recordingId" = $4`,
+		recording.Status, string(callIdsJson), recording.CompletedAt, recording.Id,
+	); err != nil {
+		return err
+	}
+
+	recordings.notify(recording, user)
+	return nil
+}
+
+// notify tells the user the recording is done, on the open clients, by push
+// and by email.
+func (recordings *Recordings) notify(recording *Recording, user *User) {
+	controller := recordings.controller
+
+	label := recording.Label
+	if label == "" {
+		label = "Your recording"
+	}
+	title := "Recording ready"
+	message := fmt.Sprintf("%s: %d call(s)", label, len(recording.CallIds))
+	if recording.Status == RecordingStatusEmpty {
+		title = "Recording finished"
+		message = fmt.Sprintf("%s: no calls were heard", label)
+	}
+
+	msg := &Message{Command: MessageCommandAlert, Payload: map[string]any{
+		"type":        "recording",
+		"recordingId": recording.Id,
+		"status":      recording.Status,
+		"calls":       len(recording.CallIds),
+	}}
+	controller.Clients.mutex.Lock()
+	for client := range controller.Clients.Map {
+		if client.User != nil && client.User.Id == user.Id {
+			select {
+			case client.Send <- msg:
+			default:
+			}
+		}
+	}
+	controller.Clients.mutex.Unlock()
+
+	go controller.sendUserPushNotification(user.Id, title, message, map[string]interface{}{
+		"type":        "recording",
+		"recordingId": strconv.FormatUint(recording.Id, 10),
+	})
+
+	if user.Email == "" || !user.Verified || controller.EmailService == nil || controller.EmailService.configError() != nil {
+		return
+	}
+	go func() {
+		branding := controller.Options.Branding
+		if branding == "" {
+			branding = "ThinLine Radio"
+		}
+		fromName := controller.Options.EmailSmtpFromName
+		if fromName == "" {
+			fromName = branding
+		}
+		window := fmt.Sprintf("%s – %s", time.UnixMilli(recording.StartAt).Format("Jan 2 3:04 PM"), time.UnixMilli(recording.EndAt).Format("Jan 2 3:04 PM"))
+		body := fmt.Sprintf(`<!DOCTYPE html>
+<html><head><meta charset="UTF-8"></head>
+<body style="font-family:-apple-system,BlinkMacSystemFont,'Segoe UI',Roboto,sans-serif;line-height:1.6;color:#333;max-width:600px;margin:0 auto;padding:24px">
+<h1 style="font-size:20px">%s</h1>
+<p>%s</p>
+<p>Window: %s</p>
+<p>Open %s to play the calls or download them as a ZIP. Recordings are kept for %d days.</p>
+</body></html>`, html.EscapeString(title), html.EscapeString(message), html.EscapeString(window), html.EscapeString(branding), recordingRetentionDays)
+		if err := controller.EmailService.sendEmail(fromName, controller.Options.EmailSmtpFromEmail, user.Email, fmt.Sprintf("[%s] %s", branding, title), body); err != nil {
+			controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("recording %d: email to user %d failed: %v", recording.Id, user.Id, err))
+		}
+	}()
+}
+
+// PruneRecordings removes recordings completed more than recordingRetentionDays ago.
+func (controller *Controller) PruneRecordings() error {
+	cutoff := time.Now().Add(-24 * time.Hour * recordingRetentionDays).UnixMilli()
+
+	_, err := controller.Database.Sql.Exec(`DELETE FROM "recordings" WHERE "completedAt" > 0 AND "completedAt" < $1`, cutoff)
+	return err
+}
+
+// response returns the API representation of a recording.
+func (recordings *Recordings) response(recording *Recording) map[string]any {
+	talkgroups := []map[string]any{}
+	for _, talkgroup := range recording.Talkgroups {
+		entry := map[string]any{"systemId": talkgroup.SystemId, "talkgroupId": talkgroup.TalkgroupId}
+		if call := recordings.resolve(talkgroup); call != nil {
+			entry["systemRef"] = call.System.SystemRef
+			entry["systemLabel"] = call.System.Label
+			entry["talkgroupRef"] = call.Talkgroup.TalkgroupRef
+			entry["talkgroupLabel"] = call.Talkgroup.Label
+		}
+		talkgroups = append(talkgroups, entry)
+	}
+
+	return map[string]any{
+		"id":          recording.Id,
+		"label":       recording.Label,
+		"talkgroups":  talkgroups,
+		"startAt":     recording.StartAt,
+		"endAt":       recording.EndAt,
+		"status":      recordingStatus(recording, time.Now()),
+		"callIds":     recording.CallIds,
+		"createdAt":   recording.CreatedAt,
+		"completedAt": recording.CompletedAt,
+	}
+}
+
+// RecordingsHandler manages the user's time-shift recordings.
+//
+//	GET    /api/recordings
+//	POST   /api/recordings       {"label", "startAt", "endAt", "talkgroups": [{"systemRef", "talkgroupRef"}]}
+//	GET    /api/recordings/{id}
+//	DELETE /api/recordings/{id}
+//	GET    /api/recordings/{id}/zip
+func (api *Api) RecordingsHandler(w http.ResponseWriter, r *http.Request) {
+	client := api.getClient(r)
+	if client == nil || client.User == nil {
+		api.exitWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	user := client.User
+	recordings := api.Controller.Recordings
+
+	var id uint64
+	zipped := false
+	if path := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/recordings"), "/"); path != "" {
+		parts := strings.Split(path, "/")
+		v, err := strconv.ParseUint(parts[0], 10, 64)
+		if err != nil || v == 0 || len(parts) > 2 || (len(parts) == 2 && parts[1] != "zip") {
+			api.exitWithError(w, http.StatusBadRequest, "invalid recording id")
+			return
+		}
+		id = v
+		zipped = len(parts) == 2
+	}
+
+	writeJSON := func(v any) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(v)
+	}
+
+	switch {
+	case r.Method == http.MethodGet && id == 0:
+		list, err := recordings.ForUser(user.Id)
+		if err != nil {
+			api.exitWithError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		response := []map[string]any{}
+		for _, recording := range list {
+			response = append(response, recordings.response(recording))
+		}
+		writeJSON(response)
+
+	case r.Method == http.MethodPost && id == 0:
+		var request struct {
+			Label      string  `json:"label"`
+			StartAt    float64 `json:"startAt"`
+			EndAt      float64 `json:"endAt"`
+			Talkgroups []struct {
+				SystemRef    uint `json:"systemRef"`
+				TalkgroupRef uint `json:"talkgroupRef"`
+			} `json:"talkgroups"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			api.exitWithError(w, http.StatusBadRequest, fmt.Sprintf("invalid request body: %v", err))
+			return
+		}
+
+		recording := &Recording{
+			UserId:  user.Id,
+			Label:   strings.TrimSpace(request.Label),
+			StartAt: int64(request.StartAt),
+			EndAt:   int64(request.EndAt),
+		}
+		if err := validateRecordingWindow(recording.StartAt, recording.EndAt, time.Now()); err != nil {
+			api.exitWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if len(request.Talkgroups) == 0 || len(request.Talkgroups) > recordingMaxTalkgroups {
+			api.exitWithError(w, http.StatusBadRequest, fmt.Sprintf("select between 1 and %d talkgroups", recordingMaxTalkgroups))
+			return
+		}
+
+		seen := map[uint64]bool{}
+		for _, selected := range request.Talkgroups {
+			system, ok := api.Controller.Systems.GetSystemByRef(selected.SystemRef)
+			if !ok {
+				api.exitWithError(w, http.StatusBadRequest, fmt.Sprintf("unknown system %d", selected.SystemRef))
+				return
+			}
+			talkgroup, ok := system.Talkgroups.GetTalkgroupByRef(selected.TalkgroupRef)
+			if !ok {
+				api.exitWithError(w, http.StatusBadRequest, fmt.Sprintf("unknown talkgroup %d on system %d", selected.TalkgroupRef, selected.SystemRef))
+				return
+			}
+			if !api.Controller.userHasAccess(user, &Call{System: system, Talkgroup: talkgroup}) {
+				api.exitWithError(w, http.StatusForbidden, fmt.Sprintf("no access to talkgroup %d on system %d", selected.TalkgroupRef, selected.SystemRef))
+				return
+			}
+			if !seen[talkgroup.Id] {
+				seen[talkgroup.Id] = true
+				recording.Talkgroups = append(recording.Talkgroups, RecordingTalkgroup{SystemId: system.Id, TalkgroupId: talkgroup.Id})
+			}
+		}
+
+		list, err := recordings.ForUser(user.Id)
+		if err != nil {
+			api.exitWithError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		pending := 0
+		for _, existing := range list {
+			if existing.Status == RecordingStatusScheduled {
+				pending++
+			}
+		}
+		if pending >= recordingMaxPending {
+			api.exitWithError(w, http.StatusBadRequest, fmt.Sprintf("at most %d scheduled recordings per user", recordingMaxPending))
+			return
+		}
+
+		if err := recordings.Add(recording); err != nil {
+			api.exitWithError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		writeJSON(recordings.response(recording))
+
+	case (r.Method == http.MethodGet || r.Method == http.MethodDelete) && id > 0:
+		recording, err := recordings.Get(user.Id, id)
+		if err != nil {
+			api.exitWithError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		if recording == nil {
+			api.exitWithError(w, http.StatusNotFound, "recording not found")
+			return
+		}
+
+		if r.Method == http.MethodDelete {
+			if _, err := recordings.Delete(user.Id, id); err != nil {
+				api.exitWithError(w, http.StatusInternalServerError, err.Error())
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		if !zipped {
+			writeJSON(recordings.response(recording))
+			return
+		}
+		if recording.Status != RecordingStatusReady {
+			api.exitWithError(w, http.StatusConflict, "the recording has no calls to download yet")
+			return
+		}
+		recordings.writeZip(w, recording, user)
+
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+var recordingFileUnsafe = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// recordingFilename names a call in the ZIP so files sort by time.
+func recordingFilename(index int, call *Call) string {
+	label := "call"
+	if call.Talkgroup != nil && call.Talkgroup.Label != "" {
+		label = call.Talkgroup.Label
+	}
+	label = strings.Trim(recordingFileUnsafe.ReplaceAllString(label, "_"), "_")
+
+	ext := filepath.Ext(call.AudioFilename)
+	if ext == "" {
+		ext = ".m4a"
+	}
+	return fmt.Sprintf("%04d_%s_%s_%d%s", index+1, call.Timestamp.Format("20060102-150405"), label, call.Id, ext)
+}
+
+// writeZip streams the recording's calls with an M3U playlist and a CSV of
+// the transcripts. Calls pruned since the recording completed are skipped.
+func (recordings *Recordings) writeZip(w http.ResponseWriter, recording *Recording, user *User) {
+	name := recording.Label
+	if name == "" {
+		name = fmt.Sprintf("recording-%d", recording.Id)
+	}
+	name = strings.Trim(recordingFileUnsafe.ReplaceAllString(name, "_"), "_")
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.zip"`, name))
+	w.Header().Set("Cache-Control", "no-store")
+
+	archive := zip.NewWriter(w)
+	defer archive.Close()
+
+	var playlist strings.Builder
+	playlist.WriteString("#EXTM3U\n")
+
+	var index strings.Builder
+	table := csv.NewWriter(&index)
+	table.Write([]string{"file", "time", "system", "talkgroup", "transcript"})
+
+	written := 0
+	for _, callId := range recording.CallIds {
+		call, err := recordings.controller.Calls.GetCall(callId)
+		if err != nil || call == nil || len(call.Audio) == 0 || !recordings.controller.userHasAccess(user, call) {
+			continue
+		}
+
+		filename := recordingFilename(written, call)
+		f, err := archive.CreateHeader(&zip.FileHeader{Name: filename, Method: zip.Store, Modified: call.Timestamp})
+		if err != nil {
+			return
+		}
+		if _, err := f.Write(call.Audio); err != nil {
+			return
+		}
+		written++
+
+		var system, talkgroup string
+		if call.System != nil {
+			system = call.System.Label
+		}
+		if call.Talkgroup != nil {
+			talkgroup = call.Talkgroup.Label
+		}
+		fmt.Fprintf(&playlist, "#EXTINF:-1,%s - %s\n%s\n", system, talkgroup, filename)
+		table.Write([]string{filename, call.Timestamp.Format(time.RFC3339), system, talkgroup, call.Transcript})
+	}
+	table.Flush()
+
+	if f, err := archive.Create("playlist.m3u"); err == nil {
+		f.Write([]byte(playlist.String()))
+	}
+	if f, err := archive.Create("calls.csv"); err == nil {
+		f.Write([]byte(index.String()))
+	}
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestValidateRecordingWindow(t *testing.T) {
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	at := func(hour int) int64 {
+		return time.Date(2026, 10, 17, hour, 0, 0, 0, time.UTC).UnixMilli()
+	}
+
+	if err := validateRecordingWindow(at(18), at(22), now); err != nil {
+		t.Fatalf("tonight's window refused: %v", err)
+	}
+	if err := validateRecordingWindow(at(10), at(14), now); err != nil {
+		t.Fatalf("window already open refused: %v", err)
+	}
+	for name, window := range map[string][2]int64{
+		"missing":  {0, at(22)},
+		"reversed": {at(22), at(18)},
+		"over":     {at(8), at(11)},
+		"too long": {at(13), at(13) + (25 * time.Hour).Milliseconds()},
+	} {
+		if err := validateRecordingWindow(window[0], window[1], now); err == nil {
+			t.Fatalf("%s window accepted", name)
+		}
+	}
+}
+
+func TestRecordingStatus(t *testing.T) {
+	now := time.Now()
+	recording := &Recording{Status: RecordingStatusScheduled, StartAt: now.Add(time.Hour).UnixMilli()}
+	if status := recordingStatus(recording, now); status != RecordingStatusScheduled {
+		t.Fatalf("expected scheduled, got %s", status)
+	}
+	recording.StartAt = now.Add(-time.Minute).UnixMilli()
+	if status := recordingStatus(recording, now); status != RecordingStatusRecording {
+		t.Fatalf("expected recording, got %s", status)
+	}
+	recording.Status = RecordingStatusReady
+	if status := recordingStatus(recording, now); status != RecordingStatusReady {
+		t.Fatalf("expected ready, got %s", status)
+	}
+}
+
+func TestRecordingFilename(t *testing.T) {
+	call := &Call{
+		Id:            42,
+		Timestamp:     time.Date(2026, 10, 17, 18, 5, 12, 0, time.UTC),
+		Talkgroup:     &Talkgroup{Label: "Fire Disp / Main"},
+		AudioFilename: "call.mp3",
+	}
+	if name := recordingFilename(0, call); name != "0001_20261017-180512_Fire_Disp_Main_42.mp3" {
+		t.Fatalf("unexpected filename %s", name)
+	}
+
+	call.AudioFilename = ""
+	call.Talkgroup = nil
+	if name := recordingFilename(9, call); name != "0010_20261017-180512_call_42.m4a" {
+		t.Fatalf("unexpected filename %s", name)
+	}
+}
@@ -0,0 +1,327 @@
+// Copyright (C) 2026 Thinline Dynamic Solutions
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// retentionPurgeHour is the local hour at which the nightly purge runs.
+const retentionPurgeHour = 3
+
+// RetentionPolicy sets how long calls are kept for a system (TalkgroupId 0) or a
+// single talkgroup. A talkgroup policy overrides its system's policy, and any
+// policy takes the matching calls out of the global pruneDays sweep. Days 0 keeps
+// calls forever.
+type RetentionPolicy struct {
+	SystemId       uint64 `json:"systemId"`
+	TalkgroupId    uint64 `json:"talkgroupId"`
+	Days           uint   `json:"days"`
+	SystemLabel    string `json:"systemLabel,omitempty"`
+	TalkgroupLabel string `json:"talkgroupLabel,omitempty"`
+}
+
+type RetentionPurgeItem struct {
+	RetentionPolicy
+	Calls int64 `json:"calls"`
+	Bytes int64 `json:"bytes"`
+}
+
+type RetentionPurgeResult struct {
+	DryRun    bool                 `json:"dryRun"`
+	StartedAt int64                `json:"startedAt"`
+	Duration  int64                `json:"duration"`
+	Calls     int64                `json:"calls"`
+	Bytes     int64                `json:"bytes"`
+	Items     []RetentionPurgeItem `json:"items"`
+}
+
+type Retention struct {
+	controller *Controller
+	mutex      sync.Mutex
+	lastRunDay string
+}
+
+func NewRetention(controller *Controller) *Retention {
+	return &Retention{controller: controller}
+}
+
+func (retention *Retention) Policies() ([]RetentionPolicy, error) {
+	policies := []RetentionPolicy{}
+
+	query := `SELECT "systemId", "talkgroupId", "days" FROM "retentionPolicies" ORDER BY "systemId", "talkgroupId"`
+	rows, err := retention.controller.Database.Sql.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var policy RetentionPolicy
+		if err := rows.Scan(&policy.SystemId, &policy.TalkgroupId, &policy.Days); err != nil {
+			return nil, err
+		}
+		retention.label(&policy)
+		policies = append(policies, policy)
+	}
+
+	return policies, rows.Err()
+}
+
+func (retention *Retention) SetPolicy(policy RetentionPolicy) error {
+	system, ok := retention.controller.Systems.GetSystemById(policy.SystemId)
+	if !ok {
+		return fmt.Errorf("unknown system %d", policy.SystemId)
+	}
+	if policy.TalkgroupId > 0 {
+		if _, ok := system.Talkgroups.GetTalkgroupById(policy.TalkgroupId); !ok {
+			return fmt.Errorf("unknown talkgroup %d in system %d", policy.TalkgroupId, policy.SystemId)
+		}
+	}
+
+	query := `INSERT INTO "retentionPolicies" ("systemId", "talkgroupId", "days") VALUES ($1, $2, $3) ON CONFLICT ("systemId", "talkgroupId") DO UPDATE SET "days" = EXCLUDED."days"`
+	_, err := retention.controller.Database.Sql.Exec(query, policy.SystemId, policy.TalkgroupId, policy.Days)
+
+	return err
+}
+
+func (retention *Retention) DeletePolicy(systemId uint64, talkgroupId uint64) error {
+	query := `DELETE FROM "retentionPolicies" WHERE "systemId" = $1 AND "talkgroupId" = $2`
+	_, err := retention.controller.Database.Sql.Exec(query, systemId, talkgroupId)
+
+	return err
+}
+
+// Purge deletes calls that fall outside their retention policy. With dryRun set
+// it only counts what would be deleted.
+func (retention *Retention) Purge(dryRun bool) (*RetentionPurgeResult, error) {
+	retention.mutex.Lock()
+	defer retention.mutex.Unlock()
+
+	started := time.Now()
+	result := &RetentionPurgeResult{
+		DryRun:    dryRun,
+		StartedAt: started.UnixMilli(),
+		Items:     []RetentionPurgeItem{},
+	}
+
+	policies, err := retention.Policies()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, policy := range policies {
+		if policy.Days == 0 {
+			continue
+		}
+
+		cutoff := started.Add(-24 * time.Hour * time.Duration(policy.Days)).UnixMilli()
+		where, args := retentionPolicyWhere(policy, cutoff)
+
+		var query string
+		if dryRun {
+			query = fmt.Sprintf(`SELECT COUNT(*), COALESCE(SUM(octet_length("audio")), 0) FROM "calls" WHERE %s`, where)
+		} else {
+			query = fmt.Sprintf(`WITH "purged" AS (DELETE FROM "calls" WHERE %s RETURNING octet_length("audio") AS "size") SELECT COUNT(*), COALESCE(SUM("size"), 0) FROM "purged"`, where)
+		}
+
+		// Audio kept outside the calls table is counted by location: the
+		// bytes actually released, or for a dry run the stored sizes.
+		files := retention.controller.AudioStore.fileLocations(where, args...)
+
+		item := RetentionPurgeItem{RetentionPolicy: policy}
+		if err := retention.controller.Database.Sql.QueryRow(query, args...).Scan(&item.Calls, &item.Bytes); err != nil {
+			return nil, fmt.Errorf("retention purge system %d talkgroup %d: %v", policy.SystemId, policy.TalkgroupId, err)
+		}
+
+		if dryRun {
+			seen := map[string]bool{}
+			for _, location := range files {
+				if !seen[location] {
+					seen[location] = true
+					item.Bytes += retention.controller.AudioStore.locationSize(location)
+				}
+			}
+		} else if len(files) > 0 {
+			item.Bytes += retention.controller.AudioStore.Release(files)
+		}
+
+		if item.Calls > 0 {
+			result.Items = append(result.Items, item)
+			result.Calls += item.Calls
+			result.Bytes += item.Bytes
+		}
+	}
+
+	result.Duration = time.Since(started).Milliseconds()
+
+	return result, nil
+}
+
+// RunNightly is called by the hourly scheduler and purges once per day at
+// retentionPurgeHour, reporting the outcome as a system alert.
+func (retention *Retention) RunNightly() {
+	now := time.Now()
+	day := now.Format("2006-01-02")
+
+	if now.Hour() != retentionPurgeHour || retention.lastRunDay == day {
+		return
+	}
+	retention.lastRunDay = day
+
+	result, err := retention.Purge(false)
+	if err != nil {
+		retention.controller.Logs.LogEvent(LogLevelError, fmt.Sprintf("retention purge: %v", err))
+		retention.controller.CreateSystemAlert("retention_purge", "error", "Retention purge failed", err.Error(), nil, 0)
+		return
+	}
+
+	message := fmt.Sprintf("Purged %d calls (%s) across %d retention policies in %dms", result.Calls, formatBytes(int(result.Bytes)), len(result.Items), result.Duration)
+	retention.controller.Logs.LogEvent(LogLevelInfo, fmt.Sprintf("retention purge: %s", message))
+
+	if result.Calls > 0 {
+		retention.controller.CreateSystemAlert("retention_purge", "info", "Retention purge completed", message, &SystemAlertData{Count: int(result.Calls)}, 0)
+	}
+}
+
+func (retention *Retention) label(policy *RetentionPolicy) {
+	if system, ok := retention.controller.Systems.GetSystemById(policy.SystemId); ok {
+		policy.SystemLabel = system.Label
+		if policy.TalkgroupId > 0 {
+			if talkgroup, ok := system.Talkgroups.GetTalkgroupById(policy.TalkgroupId); ok {
+				policy.TalkgroupLabel = talkgroup.Label
+			}
+		}
+	}
+}
+
+// retentionPolicyWhere builds the call filter for a policy. A system policy
+// leaves out the talkgroups that carry their own policy.
+func retentionPolicyWhere(policy RetentionPolicy, cutoff int64) (string, []any) {
+	if policy.TalkgroupId > 0 {
+		return `"systemId" = $1 AND "talkgroupId" = $2 AND "timestamp" < $3`, []any{policy.SystemId, policy.TalkgroupId, cutoff}
+	}
+
+	return `"systemId" = $1 AND "timestamp" < $2 AND "talkgroupId" NOT IN (SELECT "talkgroupId" FROM "retentionPolicies" WHERE "systemId" = $1 AND "talkgroupId" <> 0)`, []any{policy.SystemId, cutoff}
+}
+
+// RetentionHandler manages retention policies.
+// GET lists them, POST/PUT upserts one, DELETE ?systemId=&talkgroupId= removes one.
+func (admin *Admin) RetentionHandler(w http.ResponseWriter, r *http.Request) {
+	t := admin.GetAuthorization(r)
+	if !admin.ValidateToken(t) {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	retention := admin.Controller.Retention
+
+	writeError := func(status int, err error) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		policies, err := retention.Policies()
+		if err != nil {
+			writeError(http.StatusInternalServerError, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{
+			"policies":  policies,
+			"pruneDays": admin.Controller.Options.PruneDays,
+		})
+
+	case http.MethodPost, http.MethodPut:
+		var policy RetentionPolicy
+		if err := json.NewDecoder(r.Body).Decode(&policy); err != nil {
+			writeError(http.StatusBadRequest, err)
+			return
+		}
+		if err := retention.SetPolicy(policy); err != nil {
+			writeError(http.StatusBadRequest, err)
+			return
+		}
+		retention.label(&policy)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(policy)
+
+	case http.MethodDelete:
+		systemId, err := strconv.ParseUint(r.URL.Query().Get("systemId"), 10, 64)
+		if err != nil {
+			writeError(http.StatusBadRequest, fmt.Errorf("invalid systemId"))
+			return
+		}
+		var talkgroupId uint64
+		if s := r.URL.Query().Get("talkgroupId"); s != "" {
+			if talkgroupId, err = strconv.ParseUint(s, 10, 64); err != nil {
+				writeError(http.StatusBadRequest, fmt.Errorf("invalid talkgroupId"))
+				return
+			}
+		}
+		if err := retention.DeletePolicy(systemId, talkgroupId); err != nil {
+			writeError(http.StatusInternalServerError, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// RetentionPurgeHandler previews (GET) or runs (POST) the retention purge.
+func (admin *Admin) RetentionPurgeHandler(w http.ResponseWriter, r *http.Request) {
+	t := admin.GetAuthorization(r)
+	if !admin.ValidateToken(t) {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	var dryRun bool
+	switch r.Method {
+	case http.MethodGet:
+		dryRun = true
+	case http.MethodPost:
+		dryRun = r.URL.Query().Get("dryRun") == "true"
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	result, err := admin.Controller.Retention.Purge(dryRun)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	if !dryRun {
+		admin.Controller.Logs.LogEvent(LogLevelInfo, fmt.Sprintf("retention purge (manual): purged %d calls (%s)", result.Calls, formatBytes(int(result.Bytes))))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
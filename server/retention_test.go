@@ -0,0 +1,78 @@
+package main
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRetentionPolicyWhere(t *testing.T) {
+	where, args := retentionPolicyWhere(RetentionPolicy{SystemId: 1, TalkgroupId: 9, Days: 30}, 1000)
+	if !strings.Contains(where, `"talkgroupId" = $2`) || len(args) != 3 || args[1] != uint64(9) || args[2] != int64(1000) {
+		t.Fatalf("talkgroup policy: %s %v", where, args)
+	}
+
+	where, args = retentionPolicyWhere(RetentionPolicy{SystemId: 1, Days: 30}, 1000)
+	if !strings.Contains(where, `NOT IN (SELECT "talkgroupId" FROM "retentionPolicies"`) || len(args) != 2 {
+		t.Fatalf("system policy must skip talkgroups with their own policy: %s %v", where, args)
+	}
+}
+
+func TestRetentionPurgeCountsReleasedAudio(t *testing.T) {
+	options := NewOptions()
+	options.AudioStorageConfig = AudioStorageConfig{Backend: AudioStorageFilesystem, Path: t.TempDir()}
+	controller := &Controller{Config: &Config{}, Options: options, Systems: NewSystems()}
+	controller.AudioStore = NewAudioStore(controller)
+	controller.Retention = NewRetention(controller)
+
+	store := controller.AudioStore
+	location, _, err := store.Put(store.Backend(), "", time.Now(), "old.m4a", "audio/mp4", []byte("old call audio"))
+	if err != nil {
+		t.Fatalf("put: %v", err)
+	}
+
+	purged := false
+	controller.Database = newFakeDatabase(t, func(query string, args []driver.Value) ([][]driver.Value, int64, error) {
+		switch {
+		case strings.HasPrefix(query, `SELECT "systemId", "talkgroupId", "days" FROM "retentionPolicies"`):
+			return [][]driver.Value{{int64(1), int64(0), int64(30)}}, 0, nil
+		case strings.HasPrefix(query, `SELECT "audioLocation" FROM "calls"`):
+			if purged {
+				return nil, 0, nil
+			}
+			return [][]driver.Value{{location}}, 0, nil
+		case strings.HasPrefix(query, `SELECT COUNT(*), COALESCE(SUM(octet_length("audio")), 0) FROM "calls"`):
+			return [][]driver.Value{{int64(1), int64(0)}}, 0, nil
+		case strings.HasPrefix(query, `WITH "purged" AS (DELETE FROM "calls"`):
+			purged = true
+			return [][]driver.Value{{int64(1), int64(0)}}, 0, nil
+		case strings.HasPrefix(query, `SELECT COUNT(*) FROM "calls" WHERE "audioLocation" = $1`):
+			if purged {
+				return [][]driver.Value{{int64(0)}}, 0, nil
+			}
+			return [][]driver.Value{{int64(1)}}, 0, nil
+		case strings.HasPrefix(query, `UPDATE "audioBlobs"`):
+			return nil, 0, nil
+		}
+		return nil, 0, fmt.Errorf("unexpected query %s", query)
+	})
+
+	size := int64(len("old call audio"))
+	for _, dryRun := range []bool{true, false} {
+		result, err := controller.Retention.Purge(dryRun)
+		if err != nil {
+			t.Fatalf("purge (dry run %v): %v", dryRun, err)
+		}
+		if result.Calls != 1 || result.Bytes != size {
+			t.Fatalf("purge (dry run %v): got %d calls and %d bytes, want 1 call and %d bytes", dryRun, result.Calls, result.Bytes, size)
+		}
+	}
+
+	path, _ := store.filePath(location)
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("audio of the purged call is still on disk: %v", err)
+	}
+}
@@ -0,0 +1,325 @@
+// Copyright (C) 2026 Thinline Dynamic Solutions
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Permissions granted by roles. System administrators and the admin password
+// login hold every permission.
+const (
+	PermissionManageUsers      = "manage_users"
+	PermissionManageSystems    = "manage_systems"
+	PermissionManageTalkgroups = "manage_talkgroups"
+	PermissionViewAlerts       = "view_alerts"
+	PermissionExportCalls      = "export_calls"
+	PermissionViewStats        = "view_stats"
+)
+
+var rolePermissions = []string{
+	PermissionManageUsers,
+	PermissionManageSystems,
+	PermissionManageTalkgroups,
+	PermissionViewAlerts,
+	PermissionExportCalls,
+	PermissionViewStats,
+}
+
+// Role grants its permissions to the users it is assigned to, directly or
+// through their user group. A tenant role only applies to users of that
+// tenant.
+type Role struct {
+	Id           uint64   `json:"id"`
+	Name         string   `json:"name"`
+	Description  string   `json:"description"`
+	Permissions  []string `json:"permissions"`
+	UserIds      []uint64 `json:"userIds"`
+	UserGroupIds []uint64 `json:"userGroupIds"`
+	TenantId     uint64   `json:"tenantId"`
+}
+
+// validate normalizes the role and rejects unknown permissions.
+func (role *Role) validate() error {
+	role.Name = strings.TrimSpace(role.Name)
+	if role.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+
+	known := map[string]bool{}
+	for _, permission := range rolePermissions {
+		known[permission] = true
+	}
+
+	seen := map[string]bool{}
+	permissions := []string{}
+	for _, permission := range role.Permissions {
+		permission = strings.ToLower(strings.TrimSpace(permission))
+		if !known[permission] {
+			return fmt.Errorf("unknown permission %q", permission)
+		}
+		if !seen[permission] {
+			seen[permission] = true
+			permissions = append(permissions, permission)
+		}
+	}
+	role.Permissions = permissions
+
+	if role.UserIds == nil {
+		role.UserIds = []uint64{}
+	}
+	if role.UserGroupIds == nil {
+		role.UserGroupIds = []uint64{}
+	}
+	return nil
+}
+
+// assignedTo reports whether the role applies to the user.
+func (role *Role) assignedTo(user *User) bool {
+	for _, id := range role.UserIds {
+		if id == user.Id {
+			return true
+		}
+	}
+	if user.UserGroupId > 0 {
+		for _, id := range role.UserGroupIds {
+			if id == user.UserGroupId {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+type Roles struct {
+	mutex   sync.RWMutex
+	roles   map[uint64]*Role
+	tenants *Tenants
+}
+
+func NewRoles() *Roles {
+	return &Roles{
+		roles: map[uint64]*Role{},
+	}
+}
+
+func (roles *Roles) Load(db *Database) error {
+	roles.mutex.Lock()
+	defer roles.mutex.Unlock()
+
+	rows, err := db.Sql.Query(`SELECT "roleId", "name", "description", "permissions", "userIds", "userGroupIds", "tenantId" FROM "roles"`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	roles.roles = map[uint64]*Role{}
+	for rows.Next() {
+		role := &Role{}
+		var permissions, userIds, userGroupIds string
+		if err := rows.Scan(&role.Id, &role.Name, &role.Description, &permissions, &userIds, &userGroupIds, &role.TenantId); err != nil {
+			continue
+		}
+		json.Unmarshal([]byte(permissions), &role.Permissions)
+		json.Unmarshal([]byte(userIds), &role.UserIds)
+		json.Unmarshal([]byte(userGroupIds), &role.UserGroupIds)
+		roles.roles[role.Id] = role
+	}
+
+	return rows.Err()
+}
+
+// List returns the roles sorted by name.
+func (roles *Roles) List() []Role {
+	roles.mutex.RLock()
+	defer roles.mutex.RUnlock()
+
+	list := make([]Role, 0, len(roles.roles))
+	for _, role := range roles.roles {
+		list = append(list, *role)
+	}
+	sort.Slice(list, func(i, j int) bool { return strings.ToLower(list[i].Name) < strings.ToLower(list[j].Name) })
+	return list
+}
+
+// Save inserts the role when Id is 0 and updates it otherwise.
+func (roles *Roles) Save(role *Role, db *Database) error {
+	if err := role.validate(); err != nil {
+		return err
+	}
+
+	roles.mutex.Lock()
+	defer roles.mutex.Unlock()
+
+	permissions, _ := json.Marshal(role.Permissions)
+	userIds, _ := json.Marshal(role.UserIds)
+	userGroupIds, _ := json.Marshal(role.UserGroupIds)
+
+	if role.Id == 0 {
+		if err := db.Sql.QueryRow(
+			`INSERT INTO "roles" ("name", "description", "permissions", "userIds", "userGroupIds", "tenantId") VALUES ($1, $2, $3, $4, $5, $6) RETURNING "roleId"`,
+			role.Name, role.Description, string(permissions), string(userIds), string(userGroupIds), role.TenantId,
+		).Scan(&role.Id); err != nil {
+			return err
+		}
+	} else {
+		if _, ok := roles.roles[role.Id]; !ok {
+			return fmt.Errorf("role %d not found", role.Id)
+		}
+		if _, err := db.Sql.Exec(
+			`UPDATE "roles" SET "name" = $1, "description" = $2, "permissions" = $3, "userIds" = $4, "userGroupIds" = $5, "tenantId" = $6 WHERE "roleId" = $7`,
+			role.Name, role.Description, string(permissions), string(userIds), string(userGroupIds), role.TenantId, role.Id,
+		); err != nil {
+			return err
+		}
+	}
+
+	saved := *role
+	roles.roles[role.Id] = &saved
+	return nil
+}
+
+func (roles *Roles) Delete(id uint64, db *Database) error {
+	roles.mutex.Lock()
+	defer roles.mutex.Unlock()
+
+	if _, err := db.Sql.Exec(`DELETE FROM "roles" WHERE "roleId" = $1`, id); err != nil {
+		return err
+	}
+	delete(roles.roles, id)
+	return nil
+}
+
+// Permissions returns the permissions the user holds, sorted.
+func (roles *Roles) Permissions(user *User) []string {
+	if user == nil {
+		return []string{}
+	}
+	if user.SystemAdmin {
+		return append([]string{}, rolePermissions...)
+	}
+
+	var tenantId uint64
+	if tenant := roles.tenants.Of(user); tenant != nil {
+		tenantId = tenant.Id
+	}
+
+	roles.mutex.RLock()
+	defer roles.mutex.RUnlock()
+
+	granted := map[string]bool{}
+	for _, role := range roles.roles {
+		if role.TenantId > 0 && role.TenantId != tenantId {
+			continue
+		}
+		if role.assignedTo(user) {
+			for _, permission := range role.Permissions {
+				granted[permission] = true
+			}
+		}
+	}
+
+	permissions := []string{}
+	for _, permission := range rolePermissions {
+		if granted[permission] {
+			permissions = append(permissions, permission)
+		}
+	}
+	return permissions
+}
+
+func (roles *Roles) HasPermission(user *User, permission string) bool {
+	for _, p := range roles.Permissions(user) {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// RolesHandler manages roles. Only full administrators may change them.
+//
+//	GET    /api/admin/roles          roles and the known permissions
+//	POST   /api/admin/roles          create a role
+//	PUT    /api/admin/roles?id=N     update a role
+//	DELETE /api/admin/roles?id=N     delete a role
+func (admin *Admin) RolesHandler(w http.ResponseWriter, r *http.Request) {
+	t := admin.GetAuthorization(r)
+	if !admin.ValidateToken(t) {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	writeError := func(status int, err error) {
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+	}
+
+	roles := admin.Controller.Roles
+
+	switch r.Method {
+	case http.MethodGet:
+		json.NewEncoder(w).Encode(map[string]any{
+			"roles":       roles.List(),
+			"permissions": rolePermissions,
+		})
+
+	case http.MethodPost, http.MethodPut:
+		role := &Role{}
+		if err := json.NewDecoder(r.Body).Decode(role); err != nil {
+			writeError(http.StatusBadRequest, fmt.Errorf("invalid role: %v", err))
+			return
+		}
+		role.Id = 0
+		if r.Method == http.MethodPut {
+			id, err := strconv.ParseUint(r.URL.Query().Get("id"), 10, 64)
+			if err != nil || id == 0 {
+				writeError(http.StatusBadRequest, fmt.Errorf("invalid role id"))
+				return
+			}
+			role.Id = id
+		}
+		if err := roles.Save(role, admin.Controller.Database); err != nil {
+			writeError(http.StatusBadRequest, err)
+			return
+		}
+		admin.Controller.Logs.LogEvent(LogLevelInfo, fmt.Sprintf("admin: role %q saved with permissions %s", role.Name, strings.Join(role.Permissions, ", ")))
+		json.NewEncoder(w).Encode(role)
+
+	case http.MethodDelete:
+		id, err := strconv.ParseUint(r.URL.Query().Get("id"), 10, 64)
+		if err != nil || id == 0 {
+			writeError(http.StatusBadRequest, fmt.Errorf("invalid role id"))
+			return
+		}
+		if err := roles.Delete(id, admin.Controller.Database); err != nil {
+			writeError(http.StatusInternalServerError, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
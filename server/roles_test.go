@@ -0,0 +1,87 @@
+package main
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+func TestRoleValidate(t *testing.T) {
+	role := &Role{Name: " Dispatch ", Permissions: []string{"VIEW_ALERTS", "view_alerts", "export_calls"}}
+	if err := role.validate(); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+	if role.Name != "Dispatch" || len(role.Permissions) != 2 || role.UserIds == nil || role.UserGroupIds == nil {
+		t.Fatalf("role = %+v", role)
+	}
+
+	if err := (&Role{Name: "x", Permissions: []string{"delete_everything"}}).validate(); err == nil {
+		t.Fatalf("unknown permission accepted")
+	}
+	if err := (&Role{Permissions: []string{PermissionViewAlerts}}).validate(); err == nil {
+		t.Fatalf("empty name accepted")
+	}
+}
+
+func TestRolesPermissions(t *testing.T) {
+	roles := NewRoles()
+	roles.roles[1] = &Role{Id: 1, Name: "Users", Permissions: []string{PermissionManageUsers}, UserIds: []uint64{7}}
+	roles.roles[2] = &Role{Id: 2, Name: "Alerts", Permissions: []string{PermissionViewAlerts}, UserGroupIds: []uint64{3}}
+
+	member := &User{Id: 7, UserGroupId: 3}
+	if got := roles.Permissions(member); len(got) != 2 || got[0] != PermissionManageUsers || got[1] != PermissionViewAlerts {
+		t.Fatalf("permissions = %v", got)
+	}
+
+	groupOnly := &User{Id: 8, UserGroupId: 3}
+	if roles.HasPermission(groupOnly, PermissionManageUsers) || !roles.HasPermission(groupOnly, PermissionViewAlerts) {
+		t.Fatalf("group role not applied alone")
+	}
+
+	if got := roles.Permissions(&User{Id: 9}); len(got) != 0 {
+		t.Fatalf("unassigned user has %v", got)
+	}
+	if !roles.HasPermission(&User{Id: 10, SystemAdmin: true}, PermissionExportCalls) {
+		t.Fatalf("system admin lacks a permission")
+	}
+}
+
+func TestAdminTokenPermissions(t *testing.T) {
+	controller := &Controller{Options: &Options{secret: "secret"}, Users: NewUsers(), Roles: NewRoles()}
+	controller.Users.users[7] = &User{Id: 7}
+	controller.Users.users[8] = &User{Id: 8, SystemAdmin: true}
+	controller.Roles.roles[1] = &Role{Id: 1, Name: "Users", Permissions: []string{PermissionManageUsers}, UserIds: []uint64{7}}
+	admin := &Admin{Controller: controller}
+
+	issue := func(subject string) string {
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{ID: subject + "-id", Subject: subject}).SignedString([]byte("secret"))
+		if err != nil {
+			t.Fatalf("sign: %v", err)
+		}
+		admin.Tokens = append(admin.Tokens, token)
+		return token
+	}
+
+	password := issue("")
+	if !admin.ValidateToken(password) || !admin.ValidatePermission(password, PermissionExportCalls) {
+		t.Fatalf("password login token rejected")
+	}
+
+	roleHolder := issue(strconv.Itoa(7))
+	if admin.ValidateToken(roleHolder) {
+		t.Fatalf("role holder accepted as full administrator")
+	}
+	if !admin.ValidatePermission(roleHolder, PermissionManageUsers) || admin.ValidatePermission(roleHolder, PermissionManageSystems) {
+		t.Fatalf("role permissions not enforced")
+	}
+
+	systemAdmin := issue(strconv.Itoa(8))
+	if !admin.ValidateToken(systemAdmin) || !admin.ValidatePermission(systemAdmin, PermissionManageSystems) {
+		t.Fatalf("system admin token rejected")
+	}
+
+	if admin.ValidatePermission("not-issued", PermissionManageUsers) {
+		t.Fatalf("unknown token accepted")
+	}
+}
@@ -0,0 +1,292 @@
+// Copyright (C) 2026 Thinline Dynamic Solutions
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>
+
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ScanControls keeps the avoid and hold state of listening sessions, like the
+// avoid and hold buttons of a scanner. The state belongs to the user, so all
+// of their connected devices share it, and it ends when the last of them
+// disconnects. Clients without a user have their own state.
+//
+// A talkgroup of 0 avoids or holds the whole system.
+type ScanControls struct {
+	controller *Controller
+	mutex      sync.Mutex
+	states     map[string]*scanControlState
+}
+
+type scanControlState struct {
+	avoids map[scanControlTarget]int64 // target -> until, unix ms, 0 = until cleared
+	hold   *scanControlTarget
+}
+
+type scanControlTarget struct {
+	System    uint `json:"system"`
+	Talkgroup uint `json:"talkgroup"`
+}
+
+func (target scanControlTarget) matches(call *Call) bool {
+	if call == nil || call.System == nil || call.System.SystemRef != target.System {
+		return false
+	}
+	return target.Talkgroup == 0 || (call.Talkgroup != nil && call.Talkgroup.TalkgroupRef == target.Talkgroup)
+}
+
+// ScanControlCommand is the payload of a SCN message from a client.
+type ScanControlCommand struct {
+	Action    string // avoid, unavoid, hold, release or clear
+	System    uint
+	Talkgroup uint
+	Minutes   uint // avoid only, 0 = until cleared
+}
+
+func NewScanControls(controller *Controller) *ScanControls {
+	return &ScanControls{
+		controller: controller,
+		states:     map[string]*scanControlState{},
+	}
+}
+
+func scanControlOwner(client *Client) string {
+	if client.User != nil {
+		return "user:" + strconv.FormatUint(client.User.Id, 10)
+	}
+	return fmt.Sprintf("client:%p", client)
+}
+
+// parseScanControlCommand reads a SCN payload such as
+// {"action":"avoid","system":1,"talkgroup":100,"minutes":30}.
+func parseScanControlCommand(payload any) (*ScanControlCommand, error) {
+	m, ok := payload.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("invalid scan control payload")
+	}
+
+	command := &ScanControlCommand{}
+	if v, ok := m["action"].(string); ok {
+		command.Action = strings.ToLower(strings.TrimSpace(v))
+	}
+	if v, ok := m["system"].(float64); ok && v > 0 {
+		command.System = uint(v)
+	}
+	if v, ok := m["talkgroup"].(float64); ok && v > 0 {
+		command.Talkgroup = uint(v)
+	}
+	if v, ok := m["minutes"].(float64); ok && v > 0 {
+		command.Minutes = uint(v)
+	}
+
+	switch command.Action {
+	case "avoid", "unavoid", "hold":
+		if command.System == 0 {
+			return nil, fmt.Errorf("%s requires a system", command.Action)
+		}
+	case "release", "clear":
+	default:
+		return nil, fmt.Errorf("unknown scan control action %q", command.Action)
+	}
+	return command, nil
+}
+
+// apply changes the state; it returns false if nothing changed.
+func (state *scanControlState) apply(command *ScanControlCommand, now time.Time) bool {
+	target := scanControlTarget{System: command.System, Talkgroup: command.Talkgroup}
+
+	switch command.Action {
+	case "avoid":
+		var until int64
+		if command.Minutes > 0 {
+			until = now.Add(time.Duration(command.Minutes) * time.Minute).UnixMilli()
+		}
+		state.avoids[target] = until
+		// Avoiding the held talkgroup releases the hold
+		if state.hold != nil && *state.hold == target {
+			state.hold = nil
+		}
+	case "unavoid":
+		if _, ok := state.avoids[target]; !ok {
+			return false
+		}
+		delete(state.avoids, target)
+	case "hold":
+		state.hold = &target
+	case "release":
+		if state.hold == nil {
+			return false
+		}
+		state.hold = nil
+	case "clear":
+		if len(state.avoids) == 0 && state.hold == nil {
+			return false
+		}
+		state.avoids = map[scanControlTarget]int64{}
+		state.hold = nil
+	}
+	return true
+}
+
+// allows reports whether a call plays under the state at now.
+func (state *scanControlState) allows(call *Call, now time.Time) bool {
+	if state.hold != nil {
+		return state.hold.matches(call)
+	}
+	for target, until := range state.avoids {
+		if (until == 0 || now.UnixMilli() < until) && target.matches(call) {
+			return false
+		}
+	}
+	return true
+}
+
+// payload is the state sent to clients in a SCN message.
+func (state *scanControlState) payload(now time.Time) map[string]any {
+	type avoid struct {
+		scanControlTarget
+		Until int64 `json:"until,omitempty"`
+	}
+	avoids := []avoid{}
+	for target, until := range state.avoids {
+		if until == 0 || now.UnixMilli() < until {
+			avoids = append(avoids, avoid{target, until})
+		}
+	}
+	sort.Slice(avoids, func(i, j int) bool {
+		if avoids[i].System != avoids[j].System {
+			return avoids[i].System < avoids[j].System
+		}
+		return avoids[i].Talkgroup < avoids[j].Talkgroup
+	})
+
+	payload := map[string]any{"avoid": avoids, "hold": nil}
+	if state.hold != nil {
+		payload["hold"] = *state.hold
+	}
+	return payload
+}
+
+// Allows reports whether the client's avoid and hold state lets the call play.
+func (controls *ScanControls) Allows(client *Client, call *Call) bool {
+	if controls == nil || client == nil {
+		return true
+	}
+
+	controls.mutex.Lock()
+	defer controls.mutex.Unlock()
+
+	state, ok := controls.states[scanControlOwner(client)]
+	if !ok {
+		return true
+	}
+	return state.allows(call, time.Now())
+}
+
+// Apply runs a SCN command from the client and sends the new state to all
+// the devices sharing it.
+func (controls *ScanControls) Apply(client *Client, payload any) error {
+	command, err := parseScanControlCommand(payload)
+	if err != nil {
+		return err
+	}
+
+	owner := scanControlOwner(client)
+
+	controls.mutex.Lock()
+	state, ok := controls.states[owner]
+	if !ok {
+		state = &scanControlState{avoids: map[scanControlTarget]int64{}}
+		controls.states[owner] = state
+	}
+	changed := state.apply(command, time.Now())
+	msg := &Message{Command: MessageCommandScanControl, Payload: state.payload(time.Now())}
+	controls.mutex.Unlock()
+
+	if !changed {
+		select {
+		case client.Send <- msg:
+		default:
+		}
+		return nil
+	}
+
+	clients := controls.controller.Clients
+	clients.mutex.Lock()
+	defer clients.mutex.Unlock()
+
+	for c := range clients.Map {
+		if c == client || scanControlOwner(c) == owner {
+			select {
+			case c.Send <- msg:
+			default:
+			}
+		}
+	}
+	return nil
+}
+
+// Send sends the current state to a client that just authenticated, so a new
+// device picks up the avoids set on the others.
+func (controls *ScanControls) Send(client *Client) {
+	if controls == nil {
+		return
+	}
+
+	controls.mutex.Lock()
+	state, ok := controls.states[scanControlOwner(client)]
+	var msg *Message
+	if ok {
+		msg = &Message{Command: MessageCommandScanControl, Payload: state.payload(time.Now())}
+	}
+	controls.mutex.Unlock()
+
+	if msg != nil {
+		select {
+		case client.Send <- msg:
+		default:
+		}
+	}
+}
+
+// Release ends the state of a disconnected client once none of the devices
+// sharing it are connected.
+func (controls *ScanControls) Release(client *Client) {
+	if controls == nil {
+		return
+	}
+
+	owner := scanControlOwner(client)
+
+	clients := controls.controller.Clients
+	clients.mutex.Lock()
+	for c := range clients.Map {
+		if c != client && scanControlOwner(c) == owner {
+			clients.mutex.Unlock()
+			return
+		}
+	}
+	clients.mutex.Unlock()
+
+	controls.mutex.Lock()
+	delete(controls.states, owner)
+	controls.mutex.Unlock()
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"encoding/json"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestParseScanControlCommand(t *testing.T) {
+	command, err := parseScanControlCommand(map[string]any{"action": "Avoid", "system": float64(1), "talkgroup": float64(100), "minutes": float64(30)})
+	if err != nil || command.Action != "avoid" || command.System != 1 || command.Talkgroup != 100 || command.Minutes != 30 {
+		t.Fatalf("command %+v, %v", command, err)
+	}
+	if _, err := parseScanControlCommand(map[string]any{"action": "hold"}); err == nil {
+		t.Fatalf("hold without a system accepted")
+	}
+	if _, err := parseScanControlCommand(map[string]any{"action": "skip", "system": float64(1)}); err == nil {
+		t.Fatalf("unknown action accepted")
+	}
+	if _, err := parseScanControlCommand("avoid"); err == nil {
+		t.Fatalf("non-object payload accepted")
+	}
+}
+
+func TestScanControlState(t *testing.T) {
+	now := time.Now()
+	system1 := &System{SystemRef: 1}
+	system2 := &System{SystemRef: 2}
+	call := func(system *System, talkgroup uint) *Call {
+		return &Call{System: system, Talkgroup: &Talkgroup{TalkgroupRef: talkgroup}}
+	}
+
+	state := &scanControlState{avoids: map[scanControlTarget]int64{}}
+	state.apply(&ScanControlCommand{Action: "avoid", System: 1, Talkgroup: 100}, now)
+	state.apply(&ScanControlCommand{Action: "avoid", System: 2, Minutes: 5}, now)
+
+	if state.allows(call(system1, 100), now) || !state.allows(call(system1, 200), now) {
+		t.Fatalf("talkgroup avoid not applied")
+	}
+	if state.allows(call(system2, 300), now) {
+		t.Fatalf("system avoid not applied")
+	}
+	if !state.allows(call(system2, 300), now.Add(6*time.Minute)) {
+		t.Fatalf("temporary avoid did not expire")
+	}
+
+	state.apply(&ScanControlCommand{Action: "hold", System: 1, Talkgroup: 200}, now)
+	if !state.allows(call(system1, 200), now) || state.allows(call(system1, 300), now) {
+		t.Fatalf("hold not applied")
+	}
+
+	if !state.apply(&ScanControlCommand{Action: "release"}, now) || state.apply(&ScanControlCommand{Action: "release"}, now) {
+		t.Fatalf("release should change the state once")
+	}
+	state.apply(&ScanControlCommand{Action: "unavoid", System: 1, Talkgroup: 100}, now)
+	if !state.allows(call(system1, 100), now) {
+		t.Fatalf("unavoid not applied")
+	}
+
+	b, _ := json.Marshal(state.payload(now))
+	if string(b) != `{"avoid":[{"system":2,"talkgroup":0,"until":`+strconv.FormatInt(now.Add(5*time.Minute).UnixMilli(), 10)+`}],"hold":null}` {
+		t.Fatalf("payload %s", b)
+	}
+
+	state.apply(&ScanControlCommand{Action: "clear"}, now)
+	if len(state.avoids) != 0 || state.hold != nil {
+		t.Fatalf("clear left %v, %v", state.avoids, state.hold)
+	}
+}
+
+func TestScanControlsSharedByUserDevices(t *testing.T) {
+	controller := &Controller{Clients: NewClients()}
+	controls := NewScanControls(controller)
+	controller.ScanControls = controls
+
+	user := &User{Id: 7}
+	phone := &Client{User: user, Send: make(chan *Message, 4)}
+	desktop := &Client{User: user, Send: make(chan *Message, 4)}
+	other := &Client{User: &User{Id: 8}, Send: make(chan *Message, 4)}
+	for _, c := range []*Client{phone, desktop, other} {
+		controller.Clients.Add(c)
+	}
+
+	if err := controls.Apply(phone, map[string]any{"action": "avoid", "system": float64(1), "talkgroup": float64(100)}); err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	if len(desktop.Send) != 1 || len(other.Send) != 0 {
+		t.Fatalf("state sent to %d desktop and %d other messages", len(desktop.Send), len(other.Send))
+	}
+
+	call := &Call{System: &System{SystemRef: 1}, Talkgroup: &Talkgroup{TalkgroupRef: 100}}
+	if controls.Allows(desktop, call) || !controls.Allows(other, call) {
+		t.Fatalf("avoid not shared by the user's devices only")
+	}
+
+	controller.Clients.Remove(phone)
+	controls.Release(phone)
+	if controls.Allows(desktop, call) {
+		t.Fatalf("state ended while a device is still connected")
+	}
+	controller.Clients.Remove(desktop)
+	controls.Release(desktop)
+	if !controls.Allows(desktop, call) {
+		t.Fatalf("state kept after the last device disconnected")
+	}
+}
@@ -79,6 +79,118 @@ func (scheduler *Scheduler) run() {
 		scheduler.Controller.CleanupOldSystemAlerts()
 	}()
 
+	// Nightly per-system/talkgroup retention purge (no-op outside the purge hour)
+	go scheduler.Controller.Retention.RunNightly()
+
+	// Sample storage use and warn when the disk is forecast to fill up
+	go scheduler.Controller.StorageCapacity.RunHourly()
+
+	// Move aged call audio to object storage (no-op unless archiving is enabled)
+	go scheduler.Controller.CallArchiver.RunScheduled()
+
+	// Drop config revisions past their retention window
+	go func() {
+		if err := scheduler.Controller.ConfigHistory.Prune(); err != nil {
+			scheduler.Controller.Logs.LogEvent(LogLevelError, fmt.Sprintf("scheduler.configHistory.Prune: %s", err.Error()))
+		}
+	}()
+
+	// Drop outbox events past their retention window
+	go func() {
+		if err := scheduler.Controller.PruneOutboxEvents(); err != nil {
+			scheduler.Controller.Logs.LogEvent(LogLevelError, fmt.Sprintf("scheduler.PruneOutboxEvents: %s", err.Error()))
+		}
+	}()
+
+	// Drop completed background jobs
+	go func() {
+		if err := scheduler.Controller.Jobs.Prune(); err != nil {
+			scheduler.Controller.Logs.LogEvent(LogLevelError, fmt.Sprintf("scheduler.Jobs.Prune: %s", err.Error()))
+		}
+	}()
+
+	// Drop loudness samples the report no longer looks at
+	go func() {
+		if err := scheduler.Controller.PruneLoudnessSamples(); err != nil {
+			scheduler.Controller.Logs.LogEvent(LogLevelError, fmt.Sprintf("scheduler.PruneLoudnessSamples: %s", err.Error()))
+		}
+	}()
+
+	// Drop feed quality days the report no longer looks at
+	go func() {
+		if err := scheduler.Controller.PruneFeedQuality(); err != nil {
+			scheduler.Controller.Logs.LogEvent(LogLevelError, fmt.Sprintf("scheduler.PruneFeedQuality: %s", err.Error()))
+		}
+	}()
+
+	// Drop recording gaps closed long ago
+	go func() {
+		if err := scheduler.Controller.PruneRecordingGaps(); err != nil {
+			scheduler.Controller.Logs.LogEvent(LogLevelError, fmt.Sprintf("scheduler.PruneRecordingGaps: %s", err.Error()))
+		}
+	}()
+
+	// Release shared audio left without calls
+	go func() {
+		if err := scheduler.Controller.PruneAudioBlobs(); err != nil {
+			scheduler.Controller.Logs.LogEvent(LogLevelError, fmt.Sprintf("scheduler.PruneAudioBlobs: %s", err.Error()))
+		}
+	}()
+
+	// Drop the HLS segments of calls no longer played
+	go func() {
+		if err := scheduler.Controller.CallHLS.Prune(); err != nil {
+			scheduler.Controller.Logs.LogEvent(LogLevelError, fmt.Sprintf("scheduler.CallHLS.Prune: %s", err.Error()))
+		}
+	}()
+
+	// Drop API key usage statistics past their retention
+	go func() {
+		if err := scheduler.Controller.ApikeyUsage.Prune(); err != nil {
+			scheduler.Controller.Logs.LogEvent(LogLevelError, fmt.Sprintf("scheduler.ApikeyUsage.Prune: %s", err.Error()))
+		}
+	}()
+
+	// Drop listener statistics past their retention
+	go func() {
+		if err := scheduler.Controller.ListenerAnalytics.Prune(); err != nil {
+			scheduler.Controller.Logs.LogEvent(LogLevelError, fmt.Sprintf("scheduler.ListenerAnalytics.Prune: %s", err.Error()))
+		}
+	}()
+
+	// Drop guest access grants a week after they expire
+	go func() {
+		if err := scheduler.Controller.GuestGrants.Prune(scheduler.Controller.Database); err != nil {
+			scheduler.Controller.Logs.LogEvent(LogLevelError, fmt.Sprintf("scheduler.GuestGrants.Prune: %s", err.Error()))
+		}
+	}()
+
+	// Drop time-shift recordings past their retention
+	go func() {
+		if err := scheduler.Controller.PruneRecordings(); err != nil {
+			scheduler.Controller.Logs.LogEvent(LogLevelError, fmt.Sprintf("scheduler.PruneRecordings: %s", err.Error()))
+		}
+	}()
+
+	// Forget sessions that ended long ago
+	go func() {
+		if err := scheduler.Controller.Sessions.Prune(); err != nil {
+			scheduler.Controller.Logs.LogEvent(LogLevelError, fmt.Sprintf("scheduler.sessions.Prune: %s", err.Error()))
+		}
+	}()
+
+	// Re-check the systems imported from Radio Reference once a day
+	go scheduler.Controller.RadioReferenceSync.RunScheduled()
+
+	// Report the listening minutes of metered plans to Stripe
+	go scheduler.Controller.Billing.ReportUsage()
+
+	// Downgrade trial and comp accounts that have lapsed
+	go scheduler.Controller.Billing.ExpireLapsed()
+
+	// Send the daily alert email digests at the configured hour
+	go scheduler.Controller.EmailAlerts.RunDaily()
+
 	// Prune authMutexes entries for users that no longer exist
 	go scheduler.Controller.pruneAuthMutexes()
 
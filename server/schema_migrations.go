@@ -0,0 +1,507 @@
+// Copyright (C) 2026 Thinline Dynamic Solutions
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>
+
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+)
+
+// schemaMigration is one versioned change of the database schema or data.
+// Migrations run in the order of their ids, each in its own transaction
+// together with its row in "schemaMigrations", so a failed migration leaves
+// no trace and runs again on the next start.
+type schemaMigration struct {
+	// Id sorts the migrations, as "YYYYMMDDhhmmss-short-name". Ids already
+	// recorded in "rdioScannerMeta" by the ad-hoc migrations count as applied.
+	Id   string
+	Up   func(tx *sql.Tx) error
+	Down func(tx *sql.Tx) error // nil when the migration cannot be reverted
+}
+
+// SchemaMigrationStatus is the state of a registered migration.
+type SchemaMigrationStatus struct {
+	Id         string `json:"id"`
+	Applied    bool   `json:"applied"`
+	AppliedAt  int64  `json:"appliedAt,omitempty"`
+	DurationMs int64  `json:"durationMs,omitempty"`
+	Reversible bool   `json:"reversible"`
+}
+
+// schemaMigrationLockKey is the PostgreSQL advisory lock held while
+// migrating, so that servers sharing a database never migrate concurrently.
+const schemaMigrationLockKey int64 = 0x74686c6e6d6967 // "thlnmig"
+
+// schemaMigrations lists the versioned migrations. Append new ones with an id
+// newer than the last; never reorder or rename applied ones.
+var schemaMigrations = []schemaMigration{
+	{
+		Id: "20251219000000-remove-alert-tones",
+		Up: migrationQueries(
+			`ALTER TABLE "systems" DROP COLUMN IF EXISTS "alert"`,
+			`ALTER TABLE "talkgroups" DROP COLUMN IF EXISTS "alert"`,
+			`ALTER TABLE "tags" DROP COLUMN IF EXISTS "alert"`,
+			`ALTER TABLE "groups" DROP COLUMN IF EXISTS "alert"`,
+		),
+		Down: migrationQueries(
+			`ALTER TABLE "systems" ADD COLUMN IF NOT EXISTS "alert" text NOT NULL DEFAULT ''`,
+			`ALTER TABLE "talkgroups" ADD COLUMN IF NOT EXISTS "alert" text NOT NULL DEFAULT ''`,
+			`ALTER TABLE "tags" ADD COLUMN IF NOT EXISTS "alert" text NOT NULL DEFAULT ''`,
+			`ALTER TABLE "groups" ADD COLUMN IF NOT EXISTS "alert" text NOT NULL DEFAULT ''`,
+		),
+	},
+	{
+		Id: "20251219000001-remove-led-colors",
+		Up: migrationQueries(
+			`ALTER TABLE "systems" DROP COLUMN IF EXISTS "led"`,
+			`ALTER TABLE "talkgroups" DROP COLUMN IF EXISTS "led"`,
+			`ALTER TABLE "tags" DROP COLUMN IF EXISTS "led"`,
+			`ALTER TABLE "groups" DROP COLUMN IF EXISTS "led"`,
+		),
+		Down: migrationQueries(
+			`ALTER TABLE "systems" ADD COLUMN IF NOT EXISTS "led" text NOT NULL DEFAULT ''`,
+			`ALTER TABLE "talkgroups" ADD COLUMN IF NOT EXISTS "led" text NOT NULL DEFAULT ''`,
+			`ALTER TABLE "tags" ADD COLUMN IF NOT EXISTS "led" text NOT NULL DEFAULT ''`,
+			`ALTER TABLE "groups" ADD COLUMN IF NOT EXISTS "led" text NOT NULL DEFAULT ''`,
+		),
+	},
+	{
+		Id: "20251228000000-fix-user-timestamps",
+		Up: func(tx *sql.Tx) error {
+			// Empty or zero createdAt becomes now; empty lastLogin means never logged in
+			if _, err := tx.Exec(`UPDATE "users" SET "createdAt" = $1 WHERE "createdAt" = '' OR "createdAt" = '0'`, fmt.Sprint(time.Now().Unix())); err != nil {
+				return err
+			}
+			_, err := tx.Exec(`UPDATE "users" SET "lastLogin" = '0' WHERE "lastLogin" = ''`)
+			return err
+		},
+	},
+	{
+		// Repairs orphaned keyword list ids in user alert preferences
+		Id: "20260101000000-fix-keyword-list-ids",
+		Up: fixKeywordListIds,
+	},
+	{
+		// Clears relayListenerEmailsInitialSyncDone so the scanner performs a
+		// full POST to /api/scanner-listener-emails on the next startup, for
+		// upgrades where the flag was saved before emails reached the relay.
+		Id: "20260504000000-relay-listener-emails-resync",
+		Up: migrationQueries(`UPDATE "options" SET "value" = 'false' WHERE "key" = 'relayListenerEmailsInitialSyncDone'`),
+	},
+	{
+		// Adds per-API-key upload limits and the usage statistics behind them
+		Id: "20261018000000-apikey-usage",
+		Up: migrationQueries(
+			`ALTER TABLE "apikeys" ADD COLUMN IF NOT EXISTS "rateLimit" integer NOT NULL DEFAULT 0`,
+			`ALTER TABLE "apikeys" ADD COLUMN IF NOT EXISTS "dailyQuota" integer NOT NULL DEFAULT 0`,
+			`ALTER TABLE "apikeys" ADD COLUMN IF NOT EXISTS "silentMinutes" integer NOT NULL DEFAULT 0`,
+			`CREATE TABLE IF NOT EXISTS "apikeyUsage" (
+				"apikeyId" bigint NOT NULL REFERENCES "apikeys" ("apikeyId") ON DELETE CASCADE,
+				"hour" bigint NOT NULL,
+				"calls" integer NOT NULL DEFAULT 0,
+				"bytes" bigint NOT NULL DEFAULT 0,
+				"rejected" integer NOT NULL DEFAULT 0,
+				PRIMARY KEY ("apikeyId", "hour")
+			)`,
+			`CREATE TABLE IF NOT EXISTS "apikeySources" (
+				"apikeyId" bigint NOT NULL REFERENCES "apikeys" ("apikeyId") ON DELETE CASCADE,
+				"ip" text NOT NULL,
+				"calls" bigint NOT NULL DEFAULT 0,
+				"firstSeen" bigint NOT NULL DEFAULT 0,
+				"lastSeen" bigint NOT NULL DEFAULT 0,
+				PRIMARY KEY ("apikeyId", "ip")
+			)`,
+		),
+		Down: migrationQueries(
+			`DROP TABLE IF EXISTS "apikeySources"`,
+			`DROP TABLE IF EXISTS "apikeyUsage"`,
+			`ALTER TABLE "apikeys" DROP COLUMN IF EXISTS "silentMinutes"`,
+			`ALTER TABLE "apikeys" DROP COLUMN IF EXISTS "dailyQuota"`,
+			`ALTER TABLE "apikeys" DROP COLUMN IF EXISTS "rateLimit"`,
+		),
+	},
+	{
+		Id: "20261019000000-guest-grants",
+		Up: migrationQueries(
+			`CREATE TABLE IF NOT EXISTS "guestGrants" (
+				"guestGrantId" bigserial NOT NULL PRIMARY KEY,
+				"tokenHash" text NOT NULL UNIQUE,
+				"label" text NOT NULL DEFAULT '',
+				"scopes" text NOT NULL DEFAULT '[]',
+				"createdAt" bigint NOT NULL DEFAULT 0,
+				"expiresAt" bigint NOT NULL DEFAULT 0
+			)`,
+		),
+		Down: migrationQueries(
+			`DROP TABLE IF EXISTS "guestGrants"`,
+		),
+	},
+	{
+		Id: "20261020000000-player-embeds",
+		Up: migrationQueries(
+			`CREATE TABLE IF NOT EXISTS "playerEmbeds" (
+				"playerEmbedId" bigserial NOT NULL PRIMARY KEY,
+				"label" text NOT NULL DEFAULT '',
+				"token" text NOT NULL UNIQUE,
+				"scopes" text NOT NULL DEFAULT '[]',
+				"delay" integer NOT NULL DEFAULT 30,
+				"origins" text NOT NULL DEFAULT '[]',
+				"enabled" boolean NOT NULL DEFAULT true,
+				"createdAt" bigint NOT NULL DEFAULT 0
+			)`,
+		),
+		Down: migrationQueries(
+			`DROP TABLE IF EXISTS "playerEmbeds"`,
+		),
+	},
+	{
+		Id: "20261021000000-listener-analytics",
+		Up: migrationQueries(
+			`CREATE TABLE IF NOT EXISTS "listenerStats" (
+				"hour" bigint NOT NULL,
+				"systemRef" bigint NOT NULL,
+				"talkgroupRef" bigint NOT NULL,
+				"peak" integer NOT NULL DEFAULT 0,
+				"listenerMinutes" bigint NOT NULL DEFAULT 0,
+				PRIMARY KEY ("hour", "systemRef", "talkgroupRef")
+			)`,
+			`CREATE TABLE IF NOT EXISTS "listenerSessions" (
+				"listenerSessionId" bigserial NOT NULL PRIMARY KEY,
+				"userId" bigint NOT NULL DEFAULT 0,
+				"ip" text NOT NULL DEFAULT '',
+				"startedAt" bigint NOT NULL DEFAULT 0,
+				"endedAt" bigint NOT NULL DEFAULT 0,
+				"talkgroups" text NOT NULL DEFAULT '[]'
+			)`,
+			`CREATE INDEX IF NOT EXISTS "listenerSessions_startedAt_idx" ON "listenerSessions" ("startedAt")`,
+		),
+		Down: migrationQueries(
+			`DROP TABLE IF EXISTS "listenerSessions"`,
+			`DROP TABLE IF EXISTS "listenerStats"`,
+		),
+	},
+	{
+		Id: "20261022000000-call-original-mime",
+		Up: migrationQueries(
+			`ALTER TABLE "calls" ADD COLUMN IF NOT EXISTS "audioOriginalMime" text NOT NULL DEFAULT ''`,
+		),
+		Down: migrationQueries(
+			`ALTER TABLE "calls" DROP COLUMN IF EXISTS "audioOriginalMime"`,
+		),
+	},
+	{
+		Id: "20261023000000-system-time-zone",
+		Up: migrationQueries(
+			`ALTER TABLE "systems" ADD COLUMN IF NOT EXISTS "timeZone" text NOT NULL DEFAULT ''`,
+		),
+		Down: migrationQueries(
+			`ALTER TABLE "systems" DROP COLUMN IF EXISTS "timeZone"`,
+		),
+	},
+	{
+		Id: "20261024000000-pending-tones",
+		Up: migrationQueries(
+			`CREATE TABLE IF NOT EXISTS "pendingTones" ("pendingKey" text PRIMARY KEY, "pending" text NOT NULL, "expiresAt" bigint NOT NULL)`,
+		),
+		Down: migrationQueries(
+			`DROP TABLE IF EXISTS "pendingTones"`,
+		),
+	},
+	{
+		Id: "20261025000000-feed-quality",
+		Up: migrationQueries(
+			`CREATE TABLE IF NOT EXISTS "feedQualityDays" (
+				"apiKeyId" bigint NOT NULL DEFAULT 0,
+				"systemId" bigint NOT NULL DEFAULT 0,
+				"day" bigint NOT NULL,
+				"calls" bigint NOT NULL DEFAULT 0,
+				"activeCalls" bigint NOT NULL DEFAULT 0,
+				"levelSum" double precision NOT NULL DEFAULT 0,
+				"samples" bigint NOT NULL DEFAULT 0,
+				"clipped" bigint NOT NULL DEFAULT 0,
+				"frames" bigint NOT NULL DEFAULT 0,
+				"silentFrames" bigint NOT NULL DEFAULT 0,
+				"band100Sum" double precision NOT NULL DEFAULT 0,
+				"band300Sum" double precision NOT NULL DEFAULT 0,
+				"band600Sum" double precision NOT NULL DEFAULT 0,
+				"band1200Sum" double precision NOT NULL DEFAULT 0,
+				"band2400Sum" double precision NOT NULL DEFAULT 0,
+				PRIMARY KEY ("apiKeyId", "systemId", "day")
+			)`,
+		),
+		Down: migrationQueries(
+			`DROP TABLE IF EXISTS "feedQualityDays"`,
+		),
+	},
+	{
+		Id: "20261026000000-recording-gaps",
+		Up: migrationQueries(
+			`CREATE TABLE IF NOT EXISTS "recordingGaps" (
+				"recordingGapId" bigserial NOT NULL PRIMARY KEY,
+				"systemId" bigint NOT NULL,
+				"talkgroupId" bigint NOT NULL,
+				"startedAt" bigint NOT NULL,
+				"endedAt" bigint NOT NULL DEFAULT 0,
+				"expectedCalls" double precision NOT NULL DEFAULT 0,
+				"siblingCalls" integer NOT NULL DEFAULT 0
+			)`,
+			`CREATE INDEX IF NOT EXISTS "recordingGaps_talkgroup_idx" ON "recordingGaps" ("systemId", "talkgroupId", "startedAt")`,
+			`CREATE INDEX IF NOT EXISTS "recordingGaps_endedAt_idx" ON "recordingGaps" ("endedAt")`,
+		),
+		Down: migrationQueries(
+			`DROP TABLE IF EXISTS "recordingGaps"`,
+		),
+	},
+}
+
+// migrationQueries returns a migration step running the queries in order.
+func migrationQueries(queries ...string) func(tx *sql.Tx) error {
+	return func(tx *sql.Tx) error {
+		for _, query := range queries {
+			if _, err := tx.Exec(query); err != nil {
+				return fmt.Errorf("%v while doing %s", err, query)
+			}
+		}
+		return nil
+	}
+}
+
+// validateSchemaMigrations reports registry mistakes: ids out of order,
+// duplicated or without an up step.
+func validateSchemaMigrations(migrations []schemaMigration) error {
+	for i, migration := range migrations {
+		if migration.Id == "" || migration.Up == nil {
+			return fmt.Errorf("schema migration %d has no id or up step", i)
+		}
+		if i > 0 && migration.Id <= migrations[i-1].Id {
+			return fmt.Errorf("schema migration %s is not newer than %s", migration.Id, migrations[i-1].Id)
+		}
+	}
+	return nil
+}
+
+// lockSchemaMigrations takes the migration advisory lock on a dedicated
+// connection and returns its release.
+func (db *Database) lockSchemaMigrations() (func(), error) {
+	ctx := context.Background()
+
+	conn, err := db.Sql.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var locked bool
+	if err = conn.QueryRowContext(ctx, `SELECT pg_try_advisory_lock($1)`, schemaMigrationLockKey).Scan(&locked); err == nil && !locked {
+		log.Println("waiting for another server to finish migrating the database")
+		_, err = conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, schemaMigrationLockKey)
+	}
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return func() {
+		if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_unlock($1)`, schemaMigrationLockKey); err != nil {
+			log.Printf("schema migrations unlock: %v", err)
+		}
+		conn.Close()
+	}, nil
+}
+
+func (db *Database) prepareSchemaMigrations() error {
+	_, err := db.Sql.Exec(`CREATE TABLE IF NOT EXISTS "schemaMigrations" ("id" text NOT NULL PRIMARY KEY, "appliedAt" bigint NOT NULL DEFAULT 0, "durationMs" bigint NOT NULL DEFAULT 0)`)
+	return err
+}
+
+// appliedSchemaMigrations returns the recorded migrations by id.
+func (db *Database) appliedSchemaMigrations() (map[string]SchemaMigrationStatus, error) {
+	rows, err := db.Sql.Query(`SELECT "id", "appliedAt", "durationMs" FROM "schemaMigrations"`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := map[string]SchemaMigrationStatus{}
+	for rows.Next() {
+		status := SchemaMigrationStatus{Applied: true}
+		if err := rows.Scan(&status.Id, &status.AppliedAt, &status.DurationMs); err != nil {
+			return nil, err
+		}
+		applied[status.Id] = status
+	}
+	return applied, rows.Err()
+}
+
+// runSchemaMigrations applies the pending migrations in order. Migrations
+// recorded in "rdioScannerMeta" before versioning existed are adopted
+// without running again.
+func (db *Database) runSchemaMigrations(migrations []schemaMigration) error {
+	if err := validateSchemaMigrations(migrations); err != nil {
+		return err
+	}
+
+	if err := db.prepareSchemaMigrations(); err != nil {
+		return err
+	}
+
+	applied, err := db.appliedSchemaMigrations()
+	if err != nil {
+		return err
+	}
+
+	for _, migration := range migrations {
+		if applied[migration.Id].Applied {
+			continue
+		}
+
+		var legacy int
+		if err := db.Sql.QueryRow(`SELECT COUNT(*) FROM "rdioScannerMeta" WHERE "name" = $1`, migration.Id).Scan(&legacy); err == nil && legacy > 0 {
+			if _, err := db.Sql.Exec(`INSERT INTO "schemaMigrations" ("id") VALUES ($1) ON CONFLICT ("id") DO NOTHING`, migration.Id); err != nil {
+				return fmt.Errorf("schema migration %s: %v", migration.Id, err)
+			}
+			continue
+		}
+
+		log.Printf("running database migration %s", migration.Id)
+
+		started := time.Now()
+
+		tx, err := db.Sql.Begin()
+		if err != nil {
+			return err
+		}
+
+		if err = migration.Up(tx); err == nil {
+			_, err = tx.Exec(`INSERT INTO "schemaMigrations" ("id", "appliedAt", "durationMs") VALUES ($1, $2, $3)`, migration.Id, started.UnixMilli(), time.Since(started).Milliseconds())
+		}
+		if err == nil {
+			err = tx.Commit()
+		} else {
+			tx.Rollback()
+		}
+		if err != nil {
+			return fmt.Errorf("schema migration %s: %v", migration.Id, err)
+		}
+	}
+
+	return nil
+}
+
+// revertSchemaMigration runs the down step of a migration. Only the newest
+// applied migration can be reverted, so later migrations never run on top
+// of a schema they did not expect.
+func (db *Database) revertSchemaMigration(migrations []schemaMigration, id string) error {
+	if err := db.prepareSchemaMigrations(); err != nil {
+		return err
+	}
+
+	applied, err := db.appliedSchemaMigrations()
+	if err != nil {
+		return err
+	}
+
+	var migration *schemaMigration
+	for i := len(migrations) - 1; i >= 0; i-- {
+		if !applied[migrations[i].Id].Applied {
+			continue
+		}
+		if migrations[i].Id != id {
+			return fmt.Errorf("%s is not the newest applied migration, revert %s first", id, migrations[i].Id)
+		}
+		migration = &migrations[i]
+		break
+	}
+
+	if migration == nil {
+		return fmt.Errorf("migration %s is not applied", id)
+	}
+	if migration.Down == nil {
+		return fmt.Errorf("migration %s cannot be reverted", id)
+	}
+
+	log.Printf("reverting database migration %s", id)
+
+	tx, err := db.Sql.Begin()
+	if err != nil {
+		return err
+	}
+
+	if err = migration.Down(tx); err == nil {
+		if _, err = tx.Exec(`DELETE FROM "schemaMigrations" WHERE "id" = $1`, id); err == nil {
+			_, err = tx.Exec(`DELETE FROM "rdioScannerMeta" WHERE "name" = $1`, id)
+		}
+	}
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("revert migration %s: %v", id, err)
+	}
+
+	return tx.Commit()
+}
+
+// SchemaMigrations returns the status of the registered migrations, oldest
+// first.
+func (db *Database) SchemaMigrations() ([]SchemaMigrationStatus, error) {
+	if err := db.prepareSchemaMigrations(); err != nil {
+		return nil, err
+	}
+
+	applied, err := db.appliedSchemaMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]SchemaMigrationStatus, 0, len(schemaMigrations))
+	for _, migration := range schemaMigrations {
+		status := applied[migration.Id]
+		status.Id = migration.Id
+		status.Reversible = migration.Down != nil
+		statuses = append(statuses, status)
+	}
+
+	return statuses, nil
+}
+
+// runSchemaMigrationsCommand reverts the given migration, if any, then
+// prints the status of all versioned migrations.
+func runSchemaMigrationsCommand(db *Database, down string) error {
+	if down != "" {
+		if err := db.revertSchemaMigration(schemaMigrations, down); err != nil {
+			return err
+		}
+		fmt.Printf("reverted %s\n", down)
+	}
+
+	statuses, err := db.SchemaMigrations()
+	if err != nil {
+		return err
+	}
+
+	for _, status := range statuses {
+		state := "pending"
+		if status.Applied && status.AppliedAt > 0 {
+			state = "applied " + time.UnixMilli(status.AppliedAt).Format(time.RFC3339)
+		} else if status.Applied {
+			state = "applied before versioning"
+		}
+		fmt.Printf("%-50s %s\n", status.Id, state)
+	}
+
+	return nil
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"database/sql"
+	"strings"
+	"testing"
+)
+
+func TestSchemaMigrationsRegistry(t *testing.T) {
+	if err := validateSchemaMigrations(schemaMigrations); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestValidateSchemaMigrations(t *testing.T) {
+	up := func(tx *sql.Tx) error { return nil }
+
+	if err := validateSchemaMigrations([]schemaMigration{{Id: "20260102000000-b", Up: up}, {Id: "20260101000000-a", Up: up}}); err == nil || !strings.Contains(err.Error(), "not newer") {
+		t.Fatalf("out of order migrations should be refused, got %v", err)
+	}
+
+	if err := validateSchemaMigrations([]schemaMigration{{Id: "20260101000000-a", Up: up}, {Id: "20260101000000-a", Up: up}}); err == nil {
+		t.Fatal("duplicate migrations should be refused")
+	}
+
+	if err := validateSchemaMigrations([]schemaMigration{{Id: "20260101000000-a"}}); err == nil {
+		t.Fatal("a migration without up step should be refused")
+	}
+
+	if err := validateSchemaMigrations([]schemaMigration{{Id: "20260101000000-a", Up: up}, {Id: "20260102000000-b", Up: up}}); err != nil {
+		t.Fatal(err)
+	}
+}
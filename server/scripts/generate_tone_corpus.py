@@ -0,0 +1,97 @@
+#!/usr/bin/env python3
+"""
+Generate the synthetic labeled fixtures of the tone detection corpus.
+
+Usage:
+  python3 scripts/generate_tone_corpus.py [testdata/tones]
+
+The WAV files are 16 kHz mono 16-bit, the rate production detection decodes
+to. Their expected tones are listed in testdata/tones/corpus.json; keep both in
+sync when adding a fixture. Recorded fixtures can sit next to these ones.
+"""
+from __future__ import annotations
+
+import math
+import os
+import random
+import struct
+import sys
+import wave
+
+RATE = 16000
+
+
+def silence(seconds: float) -> list[float]:
+    return [0.0] * int(seconds * RATE)
+
+
+def sine(freq: float, seconds: float, amplitude: float = 0.5) -> list[float]:
+    n = int(seconds * RATE)
+    # Short fades avoid clicks that smear the spectrum at tone edges
+    fade = int(0.005 * RATE)
+    out = []
+    for i in range(n):
+        gain = min(1.0, i / fade, (n - 1 - i) / fade) if fade else 1.0
+        out.append(amplitude * gain * math.sin(2 * math.pi * freq * i / RATE))
+    return out
+
+
+def warble(high: float, low: float, half: float, halves: int) -> list[float]:
+    out: list[float] = []
+    phase = 0.0
+    for h in range(halves):
+        freq = high if h % 2 == 0 else low
+        for _ in range(int(half * RATE)):
+            phase += 2 * math.pi * freq / RATE
+            out.append(0.5 * math.sin(phase))
+    return out
+
+
+def voice_like(seconds: float, rng: random.Random) -> list[float]:
+    """Harmonic buzz whose pitch jumps every syllable, like speech."""
+    out: list[float] = []
+    phase = 0.0
+    syllable = int(0.12 * RATE)
+    pitch = 140.0
+    for i in range(int(seconds * RATE)):
+        if i % syllable == 0:
+            pitch = rng.uniform(100, 220)
+        phase += 2 * math.pi * pitch / RATE
+        v = sum(math.sin(k * phase) / k for k in range(1, 16))
+        out.append(0.15 * v)
+    return out
+
+
+def add_noise(samples: list[float], level: float, rng: random.Random) -> list[float]:
+    return [s + rng.gauss(0, level) for s in samples]
+
+
+def write(path: str, samples: list[float]) -> None:
+    frames = b"".join(struct.pack("<h", max(-32768, min(32767, int(s * 32767)))) for s in samples)
+    with wave.open(path, "wb") as w:
+        w.setnchannels(1)
+        w.setsampwidth(2)
+        w.setframerate(RATE)
+        w.writeframes(frames)
+
+
+def main() -> None:
+    out = sys.argv[1] if len(sys.argv) > 1 else os.path.join("testdata", "tones")
+    os.makedirs(out, exist_ok=True)
+    rng = random.Random(2822)
+
+    fixtures = {
+        "two_tone_853_960.wav": silence(0.5) + sine(853.2, 1.0) + sine(960.0, 2.0) + silence(0.5),
+        "two_tone_349_433_noisy.wav": add_noise(silence(0.5) + sine(349.0, 1.0) + sine(433.7, 2.0) + silence(0.5), 0.05, rng),
+        "long_tone_1500.wav": silence(0.5) + sine(1500.0, 3.0) + silence(0.5),
+        "warble_1200_800.wav": silence(0.5) + warble(1200.0, 800.0, 0.3, 8) + silence(0.5),
+        "voice_no_tones.wav": silence(0.3) + voice_like(3.0, rng) + silence(0.3),
+        "static_no_tones.wav": add_noise(silence(3.0), 0.2, rng),
+    }
+    for name, samples in fixtures.items():
+        write(os.path.join(out, name), samples)
+        print(f"wrote {name} ({len(samples) / RATE:.1f}s)")
+
+
+if __name__ == "__main__":
+    main()
@@ -66,7 +66,10 @@ func (rw *securityResponseWriter) addSecurityHeaders() {
 	if isHTML {
 		// Use SAMEORIGIN instead of DENY to allow same-origin iframe embedding
 		// This preserves functionality while preventing cross-origin clickjacking
-		rw.Header().Set("X-Frame-Options", "SAMEORIGIN")
+		// Pages setting their own frame-ancestors, like the embeddable player, keep them
+		if !strings.Contains(rw.Header().Get("Content-Security-Policy"), "frame-ancestors") {
+			rw.Header().Set("X-Frame-Options", "SAMEORIGIN")
+		}
 		rw.Header().Set("X-XSS-Protection", "1; mode=block")
 	}
 }
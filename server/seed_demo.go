@@ -0,0 +1,409 @@
+// Copyright (C) 2026 Thinline Dynamic Solutions
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>
+
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"strings"
+	"time"
+)
+
+const (
+	demoAudioRate = 8000
+	demoCallCount = 200
+	demoCallDays  = 3
+	demoPassword  = "DemoPass123"
+)
+
+type demoTalkgroup struct {
+	ref      uint
+	label    string
+	name     string
+	group    string
+	tag      string
+	toneSets []ToneSet
+}
+
+type demoSystem struct {
+	ref        uint
+	label      string
+	units      []uint
+	talkgroups []demoTalkgroup
+}
+
+var demoSystems = []demoSystem{
+	{
+		ref:   1001,
+		label: "Demo County P25",
+		units: []uint{1201, 1202, 1305, 1311, 2104, 2107, 3301},
+		talkgroups: []demoTalkgroup{
+			{ref: 101, label: "FD Dispatch", name: "Fire Dispatch", group: "Fire", tag: "Emergency", toneSets: []ToneSet{
+				{Id: "demo-station-1", Label: "Station 1", ATone: &ToneSpec{Frequency: 349.0, MinDuration: 0.8}, BTone: &ToneSpec{Frequency: 433.7, MinDuration: 2.5}, Tolerance: 10},
+				{Id: "demo-station-2", Label: "Station 2", ATone: &ToneSpec{Frequency: 384.6, MinDuration: 0.8}, BTone: &ToneSpec{Frequency: 457.9, MinDuration: 2.5}, Tolerance: 10},
+			}},
+			{ref: 102, label: "FD Tac 1", name: "Fire Tactical 1", group: "Fire", tag: "Emergency"},
+			{ref: 201, label: "EMS Dispatch", name: "EMS Dispatch", group: "EMS", tag: "Emergency", toneSets: []ToneSet{
+				{Id: "demo-medic", Label: "Medic Units", ATone: &ToneSpec{Frequency: 553.9, MinDuration: 0.8}, BTone: &ToneSpec{Frequency: 339.6, MinDuration: 2.5}, Tolerance: 10},
+			}},
+			{ref: 301, label: "PD Dispatch", name: "Police Dispatch", group: "Police", tag: "Emergency"},
+			{ref: 302, label: "PD Tac", name: "Police Tactical", group: "Police", tag: "Non-Emergency"},
+		},
+	},
+	{
+		ref:   2002,
+		label: "Demo City Services",
+		units: []uint{501, 502, 610},
+		talkgroups: []demoTalkgroup{
+			{ref: 401, label: "DPW Ops", name: "Public Works Operations", group: "Public Works", tag: "Non-Emergency"},
+			{ref: 402, label: "Schools", name: "School Transportation", group: "Schools", tag: "Administrative"},
+		},
+	},
+}
+
+// demoTranscripts holds canned traffic for each talkgroup group so the
+// search, alert and transcript views have something realistic to show.
+var demoTranscripts = map[string][]string{
+	"Fire": {
+		"Engine 1, Ladder 1, respond to a reported structure fire, 412 Maple Street, cross street Elm.",
+		"Engine 2 on scene, two story residential, smoke showing from the second floor, we'll be assuming command.",
+		"Command to dispatch, we have a working fire, request a second alarm.",
+		"Engine 1 to dispatch, fire is knocked down, checking for extension.",
+		"Station 2, respond to a fire alarm activation at the Riverside Mall, zone 4.",
+	},
+	"EMS": {
+		"Medic 5, respond to 88 Oak Avenue for a cardiac arrest, CPR in progress.",
+		"Medic 3 en route to County General with one patient, ETA eight minutes.",
+		"Medic 5 on scene, requesting an engine for lift assist.",
+		"Medic 3, respond to Route 9 at mile marker 14 for a motor vehicle accident with injuries.",
+	},
+	"Police": {
+		"Unit 21, check on a suspicious vehicle in the lot at 200 Main Street.",
+		"Dispatch, 21, show me out on a traffic stop, Route 9 northbound near exit 4.",
+		"Any unit near the high school, report of a fight in progress in the parking lot.",
+		"Unit 14, 10-4, clear, returning to service.",
+	},
+	"Public Works": {
+		"Truck 12, there's a water main break reported at Pine and 3rd.",
+		"DPW to all trucks, begin salting the primary routes.",
+		"Truck 7 back at the garage, out of service for the night.",
+	},
+	"Schools": {
+		"Bus 14 running about ten minutes behind on the afternoon route.",
+		"Bus 3, the driveway at Lincoln Elementary is blocked, use the side entrance.",
+	},
+}
+
+// demoKeywords are matched against the canned transcripts to create
+// keyword alerts alongside the tone alerts.
+var demoKeywords = []string{"structure fire", "working fire", "cardiac arrest", "cpr", "injuries", "fight in progress"}
+
+// runSeedDemoCommand populates a fresh install with synthetic systems,
+// talkgroups, users, calls and alerts so the product can be tried without a
+// live feed.
+func runSeedDemoCommand(controller *Controller) error {
+	if err := controller.readAllData(false); err != nil {
+		return err
+	}
+
+	if len(controller.Systems.List) > 0 {
+		return fmt.Errorf("the database already has %d systems; -seed-demo only fills a fresh install", len(controller.Systems.List))
+	}
+
+	rng := rand.New(rand.NewPCG(2783, uint64(time.Now().UnixNano())))
+
+	if err := seedDemoSystems(controller); err != nil {
+		return err
+	}
+	fmt.Printf("created %d demo systems\n", len(demoSystems))
+
+	users, err := seedDemoUsers(controller)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("created %d demo users with password %s: %s\n", len(users), demoPassword, strings.Join(users, ", "))
+
+	calls, alerts, err := seedDemoCalls(controller, rng)
+	fmt.Printf("created %d demo calls and %d alerts\n", calls, alerts)
+
+	return err
+}
+
+func seedDemoSystems(controller *Controller) error {
+	for i, d := range demoSystems {
+		system := NewSystem()
+		system.Label = d.label
+		system.Order = uint(i + 1)
+		system.SystemRef = d.ref
+		system.AlertsEnabled = true
+		system.AutoPopulate = true
+		system.AutoPopulateAlertsEnabled = true
+		system.NoAudioThresholdMinutes = 30
+
+		for j, t := range d.talkgroups {
+			talkgroup := NewTalkgroup()
+			talkgroup.Label = t.label
+			talkgroup.Name = t.name
+			talkgroup.Order = uint(j + 1)
+			talkgroup.TalkgroupRef = t.ref
+			talkgroup.AlertsEnabled = true
+			if group, ok := controller.Groups.GetGroupByLabel(t.group); ok {
+				talkgroup.GroupIds = []uint64{group.Id}
+			}
+			if tag, ok := controller.Tags.GetTagByLabel(t.tag); ok {
+				talkgroup.TagId = tag.Id
+			}
+			if len(t.toneSets) > 0 {
+				talkgroup.ToneDetectionEnabled = true
+				talkgroup.ToneSets = t.toneSets
+			}
+			system.Talkgroups.List = append(system.Talkgroups.List, talkgroup)
+		}
+
+		for _, ref := range d.units {
+			system.Units.Add(ref, fmt.Sprintf("Unit %d", ref))
+		}
+
+		controller.Systems.List = append(controller.Systems.List, system)
+	}
+
+	if err := controller.Systems.Write(controller.Database); err != nil {
+		return err
+	}
+
+	return controller.Systems.Read(controller.Database)
+}
+
+func seedDemoUsers(controller *Controller) ([]string, error) {
+	emails := []string{}
+
+	for _, name := range [][2]string{{"Dana", "Dispatcher"}, {"Lee", "Listener"}, {"Sam", "Scanner"}} {
+		email := fmt.Sprintf("%s@demo.local", strings.ToLower(name[0]))
+		if controller.Users.GetUserByEmail(email) != nil {
+			continue
+		}
+
+		pin, err := controller.Users.GenerateUniquePin(0)
+		if err != nil {
+			return emails, err
+		}
+
+		user := NewUser(email, demoPassword)
+		if err := user.HashPassword(demoPassword); err != nil {
+			return emails, err
+		}
+		user.FirstName = name[0]
+		user.LastName = name[1]
+		user.Pin = pin
+		user.Verified = true
+		user.VerificationToken = ""
+		user.Systems = "*"
+
+		if err := controller.Users.SaveNewUser(user, controller.Database); err != nil {
+			return emails, err
+		}
+		emails = append(emails, email)
+	}
+
+	return emails, nil
+}
+
+func seedDemoCalls(controller *Controller, rng *rand.Rand) (int, int, error) {
+	type target struct {
+		system    *System
+		talkgroup *Talkgroup
+		group     string
+		units     []uint
+	}
+
+	targets := []target{}
+	for _, d := range demoSystems {
+		system, ok := controller.Systems.GetSystemByRef(d.ref)
+		if !ok {
+			return 0, 0, fmt.Errorf("demo system %d was not saved", d.ref)
+		}
+		for _, t := range d.talkgroups {
+			if talkgroup, ok := system.Talkgroups.GetTalkgroupByRef(t.ref); ok {
+				targets = append(targets, target{system, talkgroup, t.group, d.units})
+			}
+		}
+	}
+	if len(targets) == 0 {
+		return 0, 0, fmt.Errorf("no demo talkgroups to seed calls on")
+	}
+
+	var (
+		calls  int
+		alerts int
+		now    = time.Now()
+		span   = int64(demoCallDays * 24 * time.Hour)
+	)
+
+	for i := 0; i < demoCallCount; i++ {
+		t := targets[rng.IntN(len(targets))]
+		transcripts := demoTranscripts[t.group]
+		transcript := transcripts[rng.IntN(len(transcripts))]
+
+		var toneSet *ToneSet
+		if len(t.talkgroup.ToneSets) > 0 && rng.IntN(3) == 0 {
+			toneSet = &t.talkgroup.ToneSets[rng.IntN(len(t.talkgroup.ToneSets))]
+		}
+
+		timestamp := now.Add(-time.Duration(rng.Int64N(span))).Truncate(time.Second)
+		samples, sequence := demoCallAudio(rng, toneSet, transcript)
+
+		call := &Call{
+			Audio:                encodePCM16Wav(samples, demoAudioRate),
+			AudioFilename:        fmt.Sprintf("%d-%d_%s.wav", t.system.SystemRef, t.talkgroup.TalkgroupRef, timestamp.Format("20060102150405")),
+			AudioMime:            "audio/wav",
+			Duration:             float64(len(samples)) / demoAudioRate,
+			System:               t.system,
+			Talkgroup:            t.talkgroup,
+			Timestamp:            timestamp,
+			Transcript:           transcript,
+			TranscriptConfidence: 0.8 + rng.Float64()*0.19,
+			TranscriptionStatus:  "completed",
+			Units:                []CallUnit{{UnitRef: t.units[rng.IntN(len(t.units))]}},
+		}
+		if sequence != nil {
+			call.ToneSequence = sequence
+			call.HasTones = true
+		}
+
+		id, err := controller.Calls.WriteCall(call, controller.Database)
+		if err != nil {
+			return calls, alerts, err
+		}
+		calls++
+
+		created, err := seedDemoAlerts(controller, id, call, toneSet)
+		if err != nil {
+			return calls, alerts, err
+		}
+		alerts += created
+	}
+
+	return calls, alerts, nil
+}
+
+// seedDemoAlerts records the tone and keyword alerts the alert engine would
+// have raised for a demo call, without notifying anyone.
+func seedDemoAlerts(controller *Controller, callId uint64, call *Call, toneSet *ToneSet) (int, error) {
+	matched := []string{}
+	lower := strings.ToLower(call.Transcript)
+	for _, keyword := range demoKeywords {
+		if strings.Contains(lower, keyword) {
+			matched = append(matched, keyword)
+		}
+	}
+
+	alertType := ""
+	switch {
+	case toneSet != nil && len(matched) > 0:
+		alertType = "tone+keyword"
+	case toneSet != nil:
+		alertType = "tone"
+	case len(matched) > 0:
+		alertType = "keyword"
+	default:
+		return 0, nil
+	}
+
+	toneSetId := ""
+	if toneSet != nil {
+		toneSetId = toneSet.Id
+	}
+	keywords, _ := json.Marshal(matched)
+
+	snippet := call.Transcript
+	if len(snippet) > 200 {
+		snippet = snippet[:200]
+	}
+
+	query := `INSERT INTO "alerts" ("callId", "systemId", "talkgroupId", "alertType", "toneDetected", "toneSetId", "keywordsMatched", "transcriptSnippet", "createdAt") VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`
+	if _, err := controller.Database.Sql.Exec(query, callId, call.System.Id, call.Talkgroup.Id, alertType, toneSet != nil, toneSetId, string(keywords), snippet, call.Timestamp.UnixMilli()); err != nil {
+		return 0, fmt.Errorf("seed demo alert: %v", err)
+	}
+
+	return 1, nil
+}
+
+// demoCallAudio renders a call: optional two-tone page followed by noise
+// shaped like speech, roughly one syllable per character group of the
+// transcript.
+func demoCallAudio(rng *rand.Rand, toneSet *ToneSet, transcript string) ([]int16, *ToneSequence) {
+	samples := []int16{}
+
+	var sequence *ToneSequence
+	if toneSet != nil && toneSet.ATone != nil && toneSet.BTone != nil {
+		a := Tone{Frequency: toneSet.ATone.Frequency, StartTime: 0, EndTime: 1, Duration: 1, ToneType: "A"}
+		b := Tone{Frequency: toneSet.BTone.Frequency, StartTime: 1, EndTime: 4, Duration: 3, ToneType: "B"}
+		samples = append(samples, demoSine(a.Frequency, a.Duration)...)
+		samples = append(samples, demoSine(b.Frequency, b.Duration)...)
+		samples = append(samples, make([]int16, demoAudioRate/2)...)
+		sequence = &ToneSequence{
+			Tones:           []Tone{a, b},
+			Duration:        4,
+			ATone:           &a,
+			BTone:           &b,
+			HasTones:        true,
+			MatchedToneSet:  toneSet,
+			MatchedToneSets: []*ToneSet{toneSet},
+		}
+	}
+
+	syllables := max(len(transcript)/4, 4)
+	for i := 0; i < syllables; i++ {
+		n := demoAudioRate/10 + rng.IntN(demoAudioRate/8)
+		for j := 0; j < n; j++ {
+			envelope := math.Sin(math.Pi * float64(j) / float64(n))
+			samples = append(samples, int16(6000*envelope*(rng.Float64()*2-1)))
+		}
+		if rng.IntN(6) == 0 {
+			samples = append(samples, make([]int16, demoAudioRate/5)...)
+		}
+	}
+
+	return samples, sequence
+}
+
+func demoSine(frequency float64, seconds float64) []int16 {
+	samples := make([]int16, int(seconds*demoAudioRate))
+	for i := range samples {
+		samples[i] = int16(8000 * math.Sin(2*math.Pi*frequency*float64(i)/demoAudioRate))
+	}
+	return samples
+}
+
+// encodePCM16Wav wraps mono 16-bit samples in a WAV container.
+func encodePCM16Wav(samples []int16, rate uint32) []byte {
+	var b bytes.Buffer
+	b.WriteString("RIFF")
+	binary.Write(&b, binary.LittleEndian, uint32(36+2*len(samples)))
+	b.WriteString("WAVEfmt ")
+	for _, v := range []any{uint32(16), uint16(1), uint16(1), rate, 2 * rate, uint16(2), uint16(16)} {
+		binary.Write(&b, binary.LittleEndian, v)
+	}
+	b.WriteString("data")
+	binary.Write(&b, binary.LittleEndian, uint32(2*len(samples)))
+	binary.Write(&b, binary.LittleEndian, samples)
+
+	return b.Bytes()
+}
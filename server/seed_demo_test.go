@@ -0,0 +1,47 @@
+package main
+
+import (
+	"math"
+	"math/rand/v2"
+	"testing"
+)
+
+func TestDemoCallAudioWav(t *testing.T) {
+	rng := rand.New(rand.NewPCG(1, 2))
+	toneSet := &demoSystems[0].talkgroups[0].toneSets[0]
+
+	samples, sequence := demoCallAudio(rng, toneSet, "Engine 1, respond to a reported structure fire.")
+	if sequence == nil || !sequence.HasTones || sequence.MatchedToneSet != toneSet {
+		t.Fatalf("expected a tone sequence matching %s, got %+v", toneSet.Label, sequence)
+	}
+	if len(samples) <= 4*demoAudioRate {
+		t.Fatalf("expected voice after the tones, got %d samples", len(samples))
+	}
+
+	pcm, rate, err := NewToneDetector().parseWAV(encodePCM16Wav(samples, demoAudioRate))
+	if err != nil {
+		t.Fatalf("parseWAV: %v", err)
+	}
+	if rate != demoAudioRate || len(pcm) != len(samples) {
+		t.Fatalf("got %d samples at %d Hz, want %d at %d Hz", len(pcm), rate, len(samples), demoAudioRate)
+	}
+
+	_, plain := demoCallAudio(rng, nil, "Unit 14, clear.")
+	if plain != nil {
+		t.Fatalf("expected no tone sequence without a tone set")
+	}
+}
+
+func TestDemoTranscriptsCoverTalkgroups(t *testing.T) {
+	for _, system := range demoSystems {
+		for _, talkgroup := range system.talkgroups {
+			if len(demoTranscripts[talkgroup.group]) == 0 {
+				t.Fatalf("talkgroup %s has no transcripts for group %s", talkgroup.label, talkgroup.group)
+			}
+		}
+	}
+
+	if s := demoSine(1000, 0.5); len(s) != demoAudioRate/2 || math.Abs(float64(s[2])) == 0 {
+		t.Fatalf("unexpected sine rendering")
+	}
+}
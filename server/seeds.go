@@ -16,8 +16,6 @@
 
 package main
 
-import "fmt"
-
 func seedGroups(db *Database) error {
 	var (
 		count uint
@@ -34,8 +32,8 @@ func seedGroups(db *Database) error {
 	if count == 0 {
 		if tx, err := db.Sql.Begin(); err == nil {
 			for _, group := range defaults.groups {
-				query := fmt.Sprintf(`INSERT INTO "groups" ("label") VALUES ('%s')`, group)
-				if _, err := tx.Exec(query); err != nil {
+				query := `INSERT INTO "groups" ("label") VALUES ($1)`
+				if _, err := tx.Exec(query, group); err != nil {
 					tx.Rollback()
 					return formatError(err, query)
 				}
@@ -70,8 +68,8 @@ func seedTags(db *Database) error {
 	if count == 0 {
 		if tx, err := db.Sql.Begin(); err == nil {
 			for _, tag := range defaults.tags {
-				query := fmt.Sprintf(`INSERT INTO "tags" ("label") VALUES ('%s')`, tag)
-				if _, err := tx.Exec(query); err != nil {
+				query := `INSERT INTO "tags" ("label") VALUES ($1)`
+				if _, err := tx.Exec(query, tag); err != nil {
 					tx.Rollback()
 					return formatError(err, query)
 				}
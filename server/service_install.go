@@ -0,0 +1,212 @@
+// Copyright (C) 2026 Thinline Dynamic Solutions
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	osuser "os/user"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/kardianos/service"
+)
+
+const serviceName = "thinline-radio"
+
+// systemdUnitTemplate is rendered by kardianos/service with its usual fields.
+// %s is replaced with the environment file before rendering.
+const systemdUnitTemplate = `[Unit]
+Description={{.Description}}
+ConditionFileIsExecutable={{.Path|cmdEscape}}
+After=network-online.target postgresql.service
+Wants=network-online.target
+
+[Service]
+ExecStart={{.Path|cmdEscape}}{{range .Arguments}} {{.|cmd}}{{end}}
+{{if .WorkingDirectory}}WorkingDirectory={{.WorkingDirectory|cmdEscape}}{{end}}
+{{if .UserName}}User={{.UserName}}{{end}}
+EnvironmentFile=-%s
+Restart=on-failure
+RestartSec=10
+StartLimitInterval=300
+StartLimitBurst=10
+LimitNOFILE=65536
+
+[Install]
+WantedBy=multi-user.target
+`
+
+// ServiceInstallOptions are the -install-service settings.
+type ServiceInstallOptions struct {
+	User    string
+	EnvFile string
+}
+
+// defaultServiceEnvFile is where the environment file lives when none is given.
+func defaultServiceEnvFile(config *Config) string {
+	if runtime.GOOS == "linux" {
+		return "/etc/default/" + serviceName
+	}
+	return config.GetPath(serviceName + ".env")
+}
+
+// serviceConfig builds the service definition for the current platform.
+func serviceConfig(config *Config, opts ServiceInstallOptions, platform string) (*service.Config, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return nil, err
+	}
+	if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+		exe = resolved
+	}
+
+	baseDir, err := filepath.Abs(config.BaseDir)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &service.Config{
+		Name:             serviceName,
+		DisplayName:      "ThinLine Radio",
+		Description:      "The perfect software-defined radio companion",
+		Executable:       exe,
+		Arguments:        []string{"-service", "run", "-base_dir", baseDir, "-config", config.GetConfigFilePath()},
+		WorkingDirectory: baseDir,
+		UserName:         opts.User,
+		Option:           service.KeyValue{},
+	}
+
+	switch {
+	case strings.HasSuffix(platform, "-systemd"):
+		cfg.Option["SystemdScript"] = fmt.Sprintf(systemdUnitTemplate, opts.EnvFile)
+
+	case runtime.GOOS == "windows":
+		cfg.Option["StartType"] = "automatic"
+		cfg.Option["DelayedAutoStart"] = true
+		cfg.Option["OnFailure"] = "restart"
+		cfg.Option["OnFailureDelayDuration"] = "10s"
+		fallthrough
+
+	default:
+		// Platforms without an environment file directive get the variables baked in
+		if vars, err := readEnvFile(opts.EnvFile); err == nil {
+			cfg.EnvVars = vars
+		} else if !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+
+	return cfg, nil
+}
+
+// prepareServiceUser checks the account exists and gives it the base directory.
+func prepareServiceUser(config *Config, name string) error {
+	if name == "" {
+		return nil
+	}
+	if runtime.GOOS == "windows" {
+		return errors.New("-service_user is not supported on Windows, set the log on account in the Services console")
+	}
+
+	u, err := osuser.Lookup(name)
+	if err != nil {
+		return fmt.Errorf("service user %s: %v", name, err)
+	}
+	uid, _ := strconv.Atoi(u.Uid)
+	gid, _ := strconv.Atoi(u.Gid)
+
+	return filepath.Walk(config.BaseDir, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		return os.Lchown(p, uid, gid)
+	})
+}
+
+// writeServiceEnvFile creates a commented environment file the first time.
+func writeServiceEnvFile(p string) error {
+	if _, err := os.Stat(p); err == nil {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		return err
+	}
+
+	content := "# Environment for the " + serviceName + " service, one KEY=value per line.\n" +
+		"# Changes apply on the next service restart.\n" +
+		"# TZ=America/New_York\n"
+
+	return os.WriteFile(p, []byte(content), 0640)
+}
+
+// runInstallServiceCommand registers the server with the platform service
+// manager: a systemd unit on Linux, a Windows service on Windows, and the
+// native init system elsewhere.
+func runInstallServiceCommand(config *Config, opts ServiceInstallOptions) error {
+	if opts.EnvFile == "" {
+		opts.EnvFile = defaultServiceEnvFile(config)
+	}
+	if !filepath.IsAbs(opts.EnvFile) {
+		opts.EnvFile = config.GetPath(opts.EnvFile)
+	}
+
+	if _, err := os.Stat(config.GetConfigFilePath()); err != nil {
+		return fmt.Errorf("%s not found, run the setup wizard first", config.GetConfigFilePath())
+	}
+
+	platform := service.Platform()
+
+	if strings.HasSuffix(platform, "-systemd") {
+		if err := writeServiceEnvFile(opts.EnvFile); err != nil {
+			return fmt.Errorf("environment file: %v", err)
+		}
+	}
+
+	cfg, err := serviceConfig(config, opts, platform)
+	if err != nil {
+		return err
+	}
+
+	if err := prepareServiceUser(config, opts.User); err != nil {
+		return err
+	}
+
+	s, err := service.New(&DaemonInterface{}, cfg)
+	if err != nil {
+		return err
+	}
+
+	if err := s.Install(); err != nil {
+		if strings.Contains(err.Error(), "already exists") {
+			return errors.New("the service is already installed, run -service uninstall first")
+		}
+		return err
+	}
+
+	fmt.Printf("Installed %s service (%s)\n", serviceName, platform)
+	fmt.Printf("  command:     %s %s\n", cfg.Executable, strings.Join(cfg.Arguments, " "))
+	if cfg.UserName != "" {
+		fmt.Printf("  user:        %s\n", cfg.UserName)
+	}
+	fmt.Printf("  environment: %s\n", opts.EnvFile)
+	fmt.Println("Start it with: thinline-radio -service start")
+
+	return nil
+}
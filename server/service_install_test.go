@@ -0,0 +1,47 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestServiceConfigSystemd(t *testing.T) {
+	dir := t.TempDir()
+	config := &Config{BaseDir: dir, ConfigFile: "thinline-radio.ini"}
+
+	cfg, err := serviceConfig(config, ServiceInstallOptions{User: "radio", EnvFile: "/etc/default/thinline-radio"}, "linux-systemd")
+	if err != nil {
+		t.Fatalf("serviceConfig: %v", err)
+	}
+
+	args := strings.Join(cfg.Arguments, " ")
+	if !strings.Contains(args, "-service run") || !strings.Contains(args, "-config "+filepath.Join(dir, "thinline-radio.ini")) {
+		t.Fatalf("arguments = %s", args)
+	}
+	if cfg.UserName != "radio" || cfg.WorkingDirectory != dir {
+		t.Fatalf("user = %s, working directory = %s", cfg.UserName, cfg.WorkingDirectory)
+	}
+
+	unit, _ := cfg.Option["SystemdScript"].(string)
+	for _, want := range []string{"EnvironmentFile=-/etc/default/thinline-radio", "Restart=on-failure", "{{if .UserName}}User="} {
+		if !strings.Contains(unit, want) {
+			t.Fatalf("unit is missing %q:\n%s", want, unit)
+		}
+	}
+}
+
+func TestServiceConfigEnvVarsWithoutSystemd(t *testing.T) {
+	dir := t.TempDir()
+	envFile := filepath.Join(dir, "thinline-radio.env")
+	os.WriteFile(envFile, []byte("# comment\nexport TZ=\"UTC\"\nFOO=bar\n"), 0600)
+
+	cfg, err := serviceConfig(&Config{BaseDir: dir, ConfigFile: "thinline-radio.ini"}, ServiceInstallOptions{EnvFile: envFile}, "unix-systemv")
+	if err != nil {
+		t.Fatalf("serviceConfig: %v", err)
+	}
+	if cfg.EnvVars["TZ"] != "UTC" || cfg.EnvVars["FOO"] != "bar" || len(cfg.EnvVars) != 2 {
+		t.Fatalf("env = %v", cfg.EnvVars)
+	}
+}
@@ -0,0 +1,616 @@
+// Copyright (C) 2026 Thinline Dynamic Solutions
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	sessionRevocationTTL = 24 * time.Hour
+	sessionTouchInterval = time.Minute
+	sessionHistoryDays   = 30
+	sessionListLimit     = 50
+)
+
+const (
+	SessionEndDisconnected = "disconnected"
+	SessionEndRevoked      = "revoked"
+	SessionEndRestart      = "restart"
+	SessionEndCleared      = "revoked_cleared"
+)
+
+var (
+	errSessionRevoked = errors.New("device was signed out")
+	errSessionLimit   = errors.New("too many concurrent connections")
+)
+
+// Session is one device logged in to a user account: a websocket client
+// authenticated with the user's PIN.
+type Session struct {
+	Id        string
+	UserId    uint64
+	Platform  string
+	Device    string
+	UserAgent string
+	Ip        string
+	CreatedAt int64 // unix ms
+	LastSeen  int64
+	EndedAt   int64
+	EndReason string
+
+	client    *Client
+	savedSeen int64
+}
+
+// Sessions tracks the active sessions in memory and records every session in
+// the sessions table. Revoked devices can't start a new session until the
+// user logs in with their password again or the revocation expires.
+type Sessions struct {
+	controller *Controller
+	active     map[string]*Session
+	revoked    map[string]int64 // revocationKey -> unix ms
+	mutex      sync.Mutex
+}
+
+func NewSessions(controller *Controller) *Sessions {
+	return &Sessions{
+		controller: controller,
+		active:     map[string]*Session{},
+		revoked:    map[string]int64{},
+	}
+}
+
+func sessionRevocationKey(userId uint64, userAgent string) string {
+	return fmt.Sprintf("%d|%s", userId, userAgent)
+}
+
+// Load closes the sessions left open by the previous run and restores the
+// revocations that are still in effect.
+func (sessions *Sessions) Load(db *Database) error {
+	formatError := errorFormatter("sessions", "load")
+
+	// Sessions still open in the database but not in memory were cut by a
+	// restart. Load also runs on a config restore, with live sessions.
+	query := `SELECT "sessionId" FROM "sessions" WHERE "endedAt" = 0`
+	rows, err := db.Sql.Query(query)
+	if err != nil {
+		return formatError(err, query)
+	}
+	stale := []string{}
+	sessions.mutex.Lock()
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			sessions.mutex.Unlock()
+			rows.Close()
+			return formatError(err, query)
+		}
+		if _, ok := sessions.active[id]; !ok {
+			stale = append(stale, id)
+		}
+	}
+	sessions.mutex.Unlock()
+	rows.Close()
+
+	query = `UPDATE "sessions" SET "endedAt" = "lastSeen", "endReason" = $1 WHERE "sessionId" = $2`
+	for _, id := range stale {
+		if _, err := db.Sql.Exec(query, SessionEndRestart, id); err != nil {
+			return formatError(err, query)
+		}
+	}
+
+	cutoff := time.Now().Add(-sessionRevocationTTL).UnixMilli()
+	query = `SELECT "userId", "userAgent", "endedAt" FROM "sessions" WHERE "endReason" = $1 AND "endedAt" > $2`
+	rows, err = db.Sql.Query(query, SessionEndRevoked, cutoff)
+	if err != nil {
+		return formatError(err, query)
+	}
+	defer rows.Close()
+
+	revoked := map[string]int64{}
+	for rows.Next() {
+		var (
+			userId    uint64
+			userAgent string
+			endedAt   int64
+		)
+		if err := rows.Scan(&userId, &userAgent, &endedAt); err != nil {
+			return formatError(err, query)
+		}
+		revoked[sessionRevocationKey(userId, userAgent)] = endedAt + sessionRevocationTTL.Milliseconds()
+	}
+
+	sessions.mutex.Lock()
+	sessions.revoked = revoked
+	sessions.mutex.Unlock()
+
+	return rows.Err()
+}
+
+// Start opens a session for a client that authenticated as user. It fails
+// when the device was revoked or the user already has limit sessions.
+func (sessions *Sessions) Start(client *Client, user *User, limit uint) (*Session, error) {
+	now := time.Now().UnixMilli()
+
+	var info clientInfo
+	userAgent := ""
+	ip := ""
+	if client.request != nil {
+		info = clientInfoFromRequest(client.request)
+		userAgent = client.request.UserAgent()
+		ip = client.GetRemoteAddr()
+	}
+
+	sessions.mutex.Lock()
+
+	if until, ok := sessions.revoked[sessionRevocationKey(user.Id, userAgent)]; ok {
+		if until > now {
+			sessions.mutex.Unlock()
+			return nil, errSessionRevoked
+		}
+		delete(sessions.revoked, sessionRevocationKey(user.Id, userAgent))
+	}
+
+	if limit > 0 && sessions.activeCount(user.Id) >= limit {
+		sessions.mutex.Unlock()
+		return nil, errSessionLimit
+	}
+
+	session := &Session{
+		Id:        uuid.NewString(),
+		UserId:    user.Id,
+		Platform:  info.Platform,
+		Device:    describeDevice(info, userAgent),
+		UserAgent: userAgent,
+		Ip:        ip,
+		CreatedAt: now,
+		LastSeen:  now,
+		client:    client,
+		savedSeen: now,
+	}
+	sessions.active[session.Id] = session
+	client.Session = session
+
+	sessions.mutex.Unlock()
+
+	if db := sessions.controller.Database; db != nil {
+		query := `INSERT INTO "sessions" ("sessionId", "userId", "platform", "device", "userAgent", "ip", "createdAt", "lastSeen") VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+		if _, err := db.Sql.Exec(query, session.Id, session.UserId, session.Platform, session.Device, session.UserAgent, session.Ip, session.CreatedAt, session.LastSeen); err != nil {
+			sessions.controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("sessions: %v", err))
+		}
+	}
+
+	return session, nil
+}
+
+// activeCount counts the live sessions of a user. The caller holds the mutex.
+func (sessions *Sessions) activeCount(userId uint64) uint {
+	var count uint
+	for _, session := range sessions.active {
+		if session.UserId != userId {
+			continue
+		}
+		// Connections that died without unregistering don't count
+		if session.client != nil && session.client.Send == nil {
+			continue
+		}
+		count++
+	}
+	return count
+}
+
+// ActiveCount returns the number of live sessions of a user.
+func (sessions *Sessions) ActiveCount(userId uint64) uint {
+	sessions.mutex.Lock()
+	defer sessions.mutex.Unlock()
+
+	return sessions.activeCount(userId)
+}
+
+// Touch records activity on the session of a client.
+func (sessions *Sessions) Touch(client *Client) {
+	session := client.Session
+	if session == nil {
+		return
+	}
+
+	now := time.Now().UnixMilli()
+
+	sessions.mutex.Lock()
+	session.LastSeen = now
+	persist := now-session.savedSeen >= sessionTouchInterval.Milliseconds()
+	if persist {
+		session.savedSeen = now
+	}
+	sessions.mutex.Unlock()
+
+	if persist && sessions.controller.Database != nil {
+		go sessions.controller.Database.Sql.Exec(`UPDATE "sessions" SET "lastSeen" = $1 WHERE "sessionId" = $2`, now, session.Id)
+	}
+}
+
+// End closes the session of a client. Revoked devices are refused new
+// sessions for sessionRevocationTTL.
+func (sessions *Sessions) End(client *Client, reason string) {
+	session := client.Session
+	if session == nil {
+		return
+	}
+
+	now := time.Now().UnixMilli()
+
+	sessions.mutex.Lock()
+	if _, ok := sessions.active[session.Id]; !ok {
+		sessions.mutex.Unlock()
+		return
+	}
+	delete(sessions.active, session.Id)
+	session.EndedAt = now
+	session.EndReason = reason
+	if reason == SessionEndRevoked {
+		sessions.revoked[sessionRevocationKey(session.UserId, session.UserAgent)] = now + sessionRevocationTTL.Milliseconds()
+	}
+	sessions.mutex.Unlock()
+
+	if db := sessions.controller.Database; db != nil {
+		query := `UPDATE "sessions" SET "lastSeen" = $1, "endedAt" = $2, "endReason" = $3 WHERE "sessionId" = $4`
+		if _, err := db.Sql.Exec(query, session.LastSeen, now, reason, session.Id); err != nil {
+			sessions.controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("sessions: %v", err))
+		}
+	}
+}
+
+// Revoke signs out one session of a user. It returns false when the session
+// is not active.
+func (sessions *Sessions) Revoke(userId uint64, sessionId string) bool {
+	sessions.mutex.Lock()
+	session, ok := sessions.active[sessionId]
+	sessions.mutex.Unlock()
+
+	if !ok || session.UserId != userId {
+		return false
+	}
+
+	sessions.signOut(session)
+	return true
+}
+
+// RevokeAll signs out every session of a user and returns how many there were.
+func (sessions *Sessions) RevokeAll(userId uint64) int {
+	sessions.mutex.Lock()
+	list := []*Session{}
+	for _, session := range sessions.active {
+		if session.UserId == userId {
+			list = append(list, session)
+		}
+	}
+	sessions.mutex.Unlock()
+
+	for _, session := range list {
+		sessions.signOut(session)
+	}
+	return len(list)
+}
+
+// signOut ends a session as revoked, asks the client for a PIN again and
+// closes its connection.
+func (sessions *Sessions) signOut(session *Session) {
+	client := session.client
+	if client == nil {
+		return
+	}
+
+	sessions.End(client, SessionEndRevoked)
+
+	if send := client.Send; send != nil {
+		select {
+		case send <- &Message{Command: MessageCommandPin}:
+		default:
+		}
+	}
+	if conn := client.Conn; conn != nil {
+		time.AfterFunc(time.Second, func() { conn.Close() })
+	}
+}
+
+// ClearRevocations lets the revoked devices of a user start sessions again,
+// after the user proved their password.
+func (sessions *Sessions) ClearRevocations(userId uint64) {
+	prefix := sessionRevocationKey(userId, "")
+
+	sessions.mutex.Lock()
+	cleared := false
+	for key := range sessions.revoked {
+		if strings.HasPrefix(key, prefix) {
+			delete(sessions.revoked, key)
+			cleared = true
+		}
+	}
+	sessions.mutex.Unlock()
+
+	if cleared && sessions.controller.Database != nil {
+		query := `UPDATE "sessions" SET "endReason" = $1 WHERE "userId" = $2 AND "endReason" = $3`
+		if _, err := sessions.controller.Database.Sql.Exec(query, SessionEndCleared, userId, SessionEndRevoked); err != nil {
+			sessions.controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("sessions: %v", err))
+		}
+	}
+}
+
+// List returns the recent sessions of a user, active ones first.
+func (sessions *Sessions) List(userId uint64) ([]*Session, error) {
+	formatError := errorFormatter("sessions", "list")
+
+	query := `SELECT "sessionId", "platform", "device", "userAgent", "ip", "createdAt", "lastSeen", "endedAt", "endReason" FROM "sessions" WHERE "userId" = $1 ORDER BY "lastSeen" DESC LIMIT $2`
+	rows, err := sessions.controller.Database.Sql.Query(query, userId, sessionListLimit)
+	if err != nil {
+		return nil, formatError(err, query)
+	}
+	defer rows.Close()
+
+	list := []*Session{}
+	for rows.Next() {
+		session := &Session{UserId: userId}
+		if err := rows.Scan(&session.Id, &session.Platform, &session.Device, &session.UserAgent, &session.Ip, &session.CreatedAt, &session.LastSeen, &session.EndedAt, &session.EndReason); err != nil {
+			return nil, formatError(err, query)
+		}
+		list = append(list, session)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, formatError(err, query)
+	}
+
+	// The database lags behind the live last seen times
+	sessions.mutex.Lock()
+	for _, session := range list {
+		if active, ok := sessions.active[session.Id]; ok {
+			session.LastSeen = active.LastSeen
+		}
+	}
+	sessions.mutex.Unlock()
+
+	sort.SliceStable(list, func(i, j int) bool {
+		return list[i].EndedAt == 0 && list[j].EndedAt != 0
+	})
+
+	return list, nil
+}
+
+// Prune deletes the sessions that ended more than sessionHistoryDays ago.
+func (sessions *Sessions) Prune() error {
+	formatError := errorFormatter("sessions", "prune")
+
+	cutoff := time.Now().AddDate(0, 0, -sessionHistoryDays).UnixMilli()
+	query := `DELETE FROM "sessions" WHERE "endedAt" > 0 AND "endedAt" < $1`
+	if _, err := sessions.controller.Database.Sql.Exec(query, cutoff); err != nil {
+		return formatError(err, query)
+	}
+	return nil
+}
+
+func (session *Session) response() map[string]any {
+	return map[string]any{
+		"id":        session.Id,
+		"platform":  session.Platform,
+		"device":    session.Device,
+		"ip":        session.Ip,
+		"createdAt": session.CreatedAt,
+		"lastSeen":  session.LastSeen,
+		"active":    session.EndedAt == 0,
+		"endedAt":   session.EndedAt,
+		"endReason": session.EndReason,
+	}
+}
+
+// describeDevice names a device for the session list, e.g. "iOS app 2.4.1"
+// or "Chrome on Windows".
+func describeDevice(info clientInfo, userAgent string) string {
+	switch info.Platform {
+	case "ios", "android":
+		name := "iOS app"
+		if info.Platform == "android" {
+			name = "Android app"
+		}
+		if info.Version != "" {
+			name += " " + info.Version
+		}
+		return name
+	}
+
+	browser := ""
+	for _, b := range []struct{ token, name string }{
+		{"Edg/", "Edge"}, {"OPR/", "Opera"}, {"Firefox/", "Firefox"}, {"Chrome/", "Chrome"}, {"Safari/", "Safari"},
+	} {
+		if strings.Contains(userAgent, b.token) {
+			browser = b.name
+			break
+		}
+	}
+
+	os := ""
+	for _, o := range []struct{ token, name string }{
+		{"iPhone", "iOS"}, {"iPad", "iOS"}, {"Android", "Android"}, {"Windows", "Windows"}, {"Mac OS X", "macOS"}, {"CrOS", "ChromeOS"}, {"Linux", "Linux"},
+	} {
+		if strings.Contains(userAgent, o.token) {
+			os = o.name
+			break
+		}
+	}
+
+	switch {
+	case browser != "" && os != "":
+		return browser + " on " + os
+	case browser != "":
+		return browser
+	case os != "":
+		return os
+	case userAgent != "":
+		if len(userAgent) > 60 {
+			return userAgent[:60]
+		}
+		return userAgent
+	}
+	return "Unknown device"
+}
+
+// SessionsHandler lets users see the devices logged in to their account and
+// sign them out.
+//
+//	GET    /api/user/sessions
+//	DELETE /api/user/sessions        signs out every device
+//	DELETE /api/user/sessions/{id}
+func (api *Api) SessionsHandler(w http.ResponseWriter, r *http.Request) {
+	client := api.getClient(r)
+	if client == nil || client.User == nil {
+		api.exitWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	user := client.User
+
+	id := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/user/sessions"), "/")
+
+	writeJSON := func(v any) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(v)
+	}
+
+	switch {
+	case r.Method == http.MethodGet && id == "":
+		list, err := api.Controller.Sessions.List(user.Id)
+		if err != nil {
+			api.exitWithError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		response := []map[string]any{}
+		for _, session := range list {
+			response = append(response, session.response())
+		}
+		writeJSON(response)
+
+	case r.Method == http.MethodDelete && id == "":
+		writeJSON(map[string]any{"revoked": api.Controller.Sessions.RevokeAll(user.Id)})
+
+	case r.Method == http.MethodDelete:
+		if !api.Controller.Sessions.Revoke(user.Id, id) {
+			api.exitWithError(w, http.StatusNotFound, "session not found or already ended")
+			return
+		}
+		writeJSON(map[string]any{"revoked": 1})
+
+	default:
+		api.exitWithError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+// UserSessionsHandler lists the sessions of a user and signs the user out of
+// every device, optionally with a new PIN so saved PINs stop working.
+//
+//	GET  /api/admin/users/{id}/sessions
+//	POST /api/admin/users/{id}/logout  {"resetPin": true}
+func (admin *Admin) UserSessionsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	writeError := func(status int, message string) {
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(map[string]string{"error": message})
+	}
+
+	t := admin.GetAuthorization(r)
+	if !admin.ValidatePermission(t, PermissionManageUsers) {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	pathParts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(pathParts) != 5 {
+		writeError(http.StatusBadRequest, "invalid path")
+		return
+	}
+	userId, err := strconv.ParseUint(pathParts[3], 10, 64)
+	if err != nil {
+		writeError(http.StatusBadRequest, "invalid user id")
+		return
+	}
+	user := admin.Controller.Users.GetUserById(userId)
+	if user == nil || !admin.tenantAllowsUser(t, user) {
+		writeError(http.StatusNotFound, "user not found")
+		return
+	}
+
+	switch {
+	case pathParts[4] == "sessions" && r.Method == http.MethodGet:
+		list, err := admin.Controller.Sessions.List(user.Id)
+		if err != nil {
+			writeError(http.StatusInternalServerError, err.Error())
+			return
+		}
+		response := []map[string]any{}
+		for _, session := range list {
+			response = append(response, session.response())
+		}
+		json.NewEncoder(w).Encode(response)
+
+	case pathParts[4] == "logout" && r.Method == http.MethodPost:
+		// Role holders may not act on system administrators
+		if user.SystemAdmin && !admin.ValidateToken(t) {
+			writeError(http.StatusForbidden, "only administrators can change a system administrator")
+			return
+		}
+
+		var request struct {
+			ResetPin bool `json:"resetPin"`
+		}
+		if r.ContentLength != 0 {
+			if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+				writeError(http.StatusBadRequest, "invalid request body")
+				return
+			}
+		}
+
+		if request.ResetPin {
+			pin, err := admin.Controller.Users.GenerateUniquePin(user.Id)
+			if err != nil {
+				writeError(http.StatusInternalServerError, "failed to generate a new pin")
+				return
+			}
+			user.Pin = pin
+			admin.Controller.Users.Update(user)
+			if err := admin.Controller.Users.Write(admin.Controller.Database); err != nil {
+				writeError(http.StatusInternalServerError, err.Error())
+				return
+			}
+			admin.Controller.SyncConfigToFile()
+		}
+
+		revoked := admin.Controller.Sessions.RevokeAll(user.Id)
+		admin.Controller.Logs.LogEvent(LogLevelInfo, fmt.Sprintf("admin: signed out user %s from %d sessions (new pin: %t)", user.Email, revoked, request.ResetPin))
+
+		json.NewEncoder(w).Encode(map[string]any{"revoked": revoked, "pinReset": request.ResetPin})
+
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
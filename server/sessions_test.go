@@ -0,0 +1,95 @@
+package main
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func newSessionTestClient(userAgent string) *Client {
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("User-Agent", userAgent)
+	return &Client{request: r, Send: make(chan *Message, 4)}
+}
+
+func TestSessionsEnforceConnectionLimit(t *testing.T) {
+	controller := &Controller{Options: &Options{}}
+	sessions := NewSessions(controller)
+	user := &User{Id: 7}
+
+	first := newSessionTestClient("Mozilla/5.0 (Windows NT 10.0) Chrome/120.0")
+	if _, err := sessions.Start(first, user, 1); err != nil {
+		t.Fatalf("first session: %v", err)
+	}
+	if _, err := sessions.Start(newSessionTestClient("x"), user, 1); err != errSessionLimit {
+		t.Fatalf("expected errSessionLimit, got %v", err)
+	}
+
+	// A dead connection no longer counts against the limit
+	first.Send = nil
+	if _, err := sessions.Start(newSessionTestClient("x"), user, 1); err != nil {
+		t.Fatalf("session after dead connection: %v", err)
+	}
+
+	if _, err := sessions.Start(newSessionTestClient("x"), &User{Id: 8}, 1); err != nil {
+		t.Fatalf("other user: %v", err)
+	}
+}
+
+func TestSessionsRevokeBlocksDevice(t *testing.T) {
+	controller := &Controller{Options: &Options{}}
+	sessions := NewSessions(controller)
+	user := &User{Id: 7}
+
+	client := newSessionTestClient("Mozilla/5.0 (iPhone) Safari/604.1")
+	session, err := sessions.Start(client, user, 0)
+	if err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	if sessions.Revoke(8, session.Id) {
+		t.Fatalf("revoked a session of another user")
+	}
+	if !sessions.Revoke(user.Id, session.Id) {
+		t.Fatalf("revoke failed")
+	}
+	if msg := <-client.Send; msg.Command != MessageCommandPin {
+		t.Fatalf("expected pin prompt, got %q", msg.Command)
+	}
+	if sessions.ActiveCount(user.Id) != 0 {
+		t.Fatalf("revoked session still active")
+	}
+
+	if _, err := sessions.Start(newSessionTestClient("Mozilla/5.0 (iPhone) Safari/604.1"), user, 0); err != errSessionRevoked {
+		t.Fatalf("expected errSessionRevoked, got %v", err)
+	}
+	if _, err := sessions.Start(newSessionTestClient("Mozilla/5.0 (Windows NT 10.0) Chrome/120.0"), user, 0); err != nil {
+		t.Fatalf("other device: %v", err)
+	}
+
+	sessions.ClearRevocations(user.Id)
+	if _, err := sessions.Start(newSessionTestClient("Mozilla/5.0 (iPhone) Safari/604.1"), user, 0); err != nil {
+		t.Fatalf("after password login: %v", err)
+	}
+	if n := sessions.RevokeAll(user.Id); n != 2 {
+		t.Fatalf("expected 2 revoked sessions, got %d", n)
+	}
+}
+
+func TestDescribeDevice(t *testing.T) {
+	cases := []struct {
+		info      clientInfo
+		userAgent string
+		want      string
+	}{
+		{clientInfo{Platform: "ios", Version: "2.4.1"}, "", "iOS app 2.4.1"},
+		{clientInfo{Platform: "android"}, "", "Android app"},
+		{clientInfo{}, "Mozilla/5.0 (Windows NT 10.0; Win64) AppleWebKit/537.36 Chrome/120.0 Safari/537.36", "Chrome on Windows"},
+		{clientInfo{}, "Mozilla/5.0 (Macintosh; Intel Mac OS X 14_0) Version/17.0 Safari/605.1.15", "Safari on macOS"},
+		{clientInfo{}, "Mozilla/5.0 (Windows NT 10.0) Chrome/120.0 Safari/537.36 Edg/120.0", "Edge on Windows"},
+		{clientInfo{}, "", "Unknown device"},
+	}
+	for _, c := range cases {
+		if got := describeDevice(c.info, c.userAgent); got != c.want {
+			t.Fatalf("describeDevice(%q) = %q, want %q", c.userAgent, got, c.want)
+		}
+	}
+}
@@ -23,6 +23,7 @@ import (
 	"os"
 	"os/exec"
 	"runtime"
+	"strconv"
 	"strings"
 
 	_ "github.com/lib/pq"
@@ -134,11 +135,13 @@ func runInteractiveSetup(configFile string) error {
 		fmt.Println("\nThis wizard will help you set up ThinLine Radio by:")
 		fmt.Println("  1. Creating a database user with appropriate permissions")
 		fmt.Println("  2. Creating a PostgreSQL database")
-		fmt.Println("  3. Generating a configuration file")
+		fmt.Println("  3. Optionally setting up HTTPS, email (SMTP) and transcription")
+		fmt.Println("  4. Generating a configuration file")
 	} else {
 		fmt.Println("\nThis wizard will help you set up ThinLine Radio by:")
 		fmt.Println("  1. Configuring connection to your existing remote database")
-		fmt.Println("  2. Generating a configuration file")
+		fmt.Println("  2. Optionally setting up HTTPS, email (SMTP) and transcription")
+		fmt.Println("  3. Generating a configuration file")
 	}
 	fmt.Println("")
 
@@ -306,34 +309,15 @@ func runInteractiveSetup(configFile string) error {
 		fmt.Println("\n✓ Using existing remote database configuration")
 	}
 
-	// Create config file
-	fmt.Print("🔄 Creating configuration file... ")
-	configContent := fmt.Sprintf(`# ThinLine Radio Configuration
-# Generated by interactive setup wizard
-
-# Database Configuration
-db_type = postgresql
-db_host = %s
-db_port = %s
-db_name = %s
-db_user = %s
-db_pass = %s
-
-# Server Configuration
-listen = %s
+	// Optional steps, written to the config file
+	tlsSettings := setupTLSStep()
+	smtpSettings := setupSMTPStep()
+	transcriptionSettings := setupTranscriptionStep()
 
-# Optional SSL Configuration (uncomment to enable)
-# ssl_listen = 0.0.0.0:3443
-# ssl_cert_file = /path/to/cert.pem
-# ssl_key_file = /path/to/key.pem
-# ssl_auto_cert = yourdomain.com
-
-# Base directory for data storage (optional)
-# base_dir = /var/lib/thinline-radio
-
-# Debug logging (optional)
-# enable_debug_log = true
-`, pgHost, pgPort, dbName, dbUser, dbPassword, serverListen)
+	// Create config file
+	fmt.Print("\n🔄 Creating configuration file... ")
+	configContent := setupConfigContent("interactive setup wizard", pgHost, pgPort, dbName, dbUser, dbPassword, serverListen)
+	configContent += setupOptionalContent(tlsSettings, smtpSettings, transcriptionSettings)
 
 	if err := os.WriteFile(configFile, []byte(configContent), 0600); err != nil {
 		fmt.Println("❌")
@@ -341,6 +325,20 @@ listen = %s
 	}
 	fmt.Println("✓")
 
+	port, _ := strconv.ParseUint(pgPort, 10, 16)
+	adminCreated, adminEmail, err := setupAdminStep(&Config{
+		DbType:     DbTypePostgresql,
+		DbHost:     pgHost,
+		DbPort:     uint(port),
+		DbName:     dbName,
+		DbUsername: dbUser,
+		DbPassword: dbPassword,
+	})
+	if err != nil {
+		fmt.Printf("⚠️  Administrator account not created: %v\n", err)
+		fmt.Println("  A one-time setup token will be printed to the console on first start instead.")
+	}
+
 	// Success message
 	fmt.Println()
 	fmt.Println("╔════════════════════════════════════════════════════════════════════╗")
@@ -352,16 +350,63 @@ listen = %s
 	fmt.Printf("Database: %s\n", dbName)
 	fmt.Printf("User: %s\n", dbUser)
 	fmt.Printf("Server: %s\n", serverListen)
+	if tlsSettings != nil {
+		fmt.Printf("HTTPS: %s\n", tlsSettings.Listen)
+	}
+	if smtpSettings != nil {
+		fmt.Printf("Email: SMTP via %s\n", smtpSettings.Host)
+	}
+	if transcriptionSettings != nil {
+		fmt.Printf("Transcription: %s\n", transcriptionSettings.Provider)
+	}
 	fmt.Println("\nNext steps:")
 	fmt.Println("  1. Review and edit the configuration file if needed")
 	fmt.Printf("  2. Start the server: ./thinline-radio -config %s\n", configFile)
 	fmt.Println("  3. Access admin dashboard: http://localhost:3000/admin")
-	fmt.Println("  4. Default admin password: admin (change immediately!)")
+	if adminCreated {
+		if adminEmail != "" {
+			fmt.Printf("  4. Sign in with the administrator password, or as system admin %s\n", adminEmail)
+		} else {
+			fmt.Println("  4. Sign in with the administrator password you chose")
+		}
+	} else {
+		fmt.Println("  4. Enter the one-time setup token printed in the server console to create the administrator account")
+	}
 	fmt.Println("")
 
 	return nil
 }
 
+// setupConfigContent renders the ini file written by the setup wizards
+func setupConfigContent(generator, pgHost, pgPort, dbName, dbUser, dbPassword, serverListen string) string {
+	return fmt.Sprintf(`# ThinLine Radio Configuration
+# Generated by %s
+
+# Database Configuration
+db_type = postgresql
+db_host = %s
+db_port = %s
+db_name = %s
+db_user = %s
+db_pass = %s
+
+# Server Configuration
+listen = %s
+
+# Optional SSL Configuration (uncomment to enable)
+# ssl_listen = 0.0.0.0:3443
+# ssl_cert_file = /path/to/cert.pem
+# ssl_key_file = /path/to/key.pem
+# ssl_auto_cert = yourdomain.com
+
+# Base directory for data storage (optional)
+# base_dir = /var/lib/thinline-radio
+
+# Debug logging (optional)
+# enable_debug_log = true
+`, generator, pgHost, pgPort, dbName, dbUser, dbPassword, serverListen)
+}
+
 // shouldRunInteractiveSetup checks if interactive setup should run
 func shouldRunInteractiveSetup(config *Config) bool {
 	// Check if we're in an interactive terminal first
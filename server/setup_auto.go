@@ -0,0 +1,240 @@
+// Copyright (C) 2026 Thinline Dynamic Solutions
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>
+
+package main
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/base64"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var setupIdentifierRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]{0,62}$`)
+
+// SetupResult is printed as JSON by -setup_auto so provisioning tools can
+// capture generated credentials.
+type SetupResult struct {
+	ConfigFile             string `json:"configFile"`
+	DbHost                 string `json:"dbHost"`
+	DbPort                 uint   `json:"dbPort"`
+	DbName                 string `json:"dbName"`
+	DbUser                 string `json:"dbUser"`
+	DbPassword             string `json:"dbPassword"`
+	DbPasswordGenerated    bool   `json:"dbPasswordGenerated"`
+	DatabaseProvisioned    bool   `json:"databaseProvisioned"`
+	Listen                 string `json:"listen"`
+	AdminPassword          string `json:"adminPassword,omitempty"`
+	AdminEmail             string `json:"adminEmail,omitempty"`
+	AdminPasswordGenerated bool   `json:"adminPasswordGenerated"`
+	AdminPasswordUnchanged bool   `json:"adminPasswordUnchanged"`
+}
+
+// runAutomatedSetup is the non-interactive counterpart of runInteractiveSetup.
+// Values come from flags first, then environment variables:
+//
+//	DB_HOST, DB_PORT, DB_NAME, DB_USER, DB_PASS, LISTEN   app database and listener
+//	PG_SUPERUSER, PG_SUPERUSER_PASS                       when set, create the role and database
+//	ADMIN_PASSWORD                                        admin password (generated when unset)
+//	ADMIN_EMAIL                                           also create a system administrator user
+//
+// It is safe to run on every container start: existing roles and databases are
+// reused, and an admin password that was already changed is left alone unless
+// ADMIN_PASSWORD is given.
+func runAutomatedSetup(config *Config, getenv func(string) string) (*SetupResult, error) {
+	env := func(key string, fallback string) string {
+		if v := strings.TrimSpace(getenv(key)); v != "" {
+			return v
+		}
+		return fallback
+	}
+
+	result := &SetupResult{
+		ConfigFile: config.GetConfigFilePath(),
+		DbHost:     config.DbHost,
+		DbPort:     config.DbPort,
+		DbName:     config.DbName,
+		DbUser:     config.DbUsername,
+		DbPassword: config.DbPassword,
+		Listen:     config.Listen,
+	}
+
+	if v := getenv("DB_HOST"); v != "" && (result.DbHost == "" || result.DbHost == "localhost") {
+		result.DbHost = v
+	}
+	if v := getenv("DB_PORT"); v != "" {
+		port, err := strconv.ParseUint(v, 10, 16)
+		if err != nil {
+			return nil, fmt.Errorf("invalid DB_PORT %q", v)
+		}
+		result.DbPort = uint(port)
+	}
+	if result.DbPort == 0 {
+		result.DbPort = 5432
+	}
+	if result.DbName == "" {
+		result.DbName = env("DB_NAME", "thinline_radio")
+	}
+	if result.DbUser == "" {
+		result.DbUser = env("DB_USER", "thinline_user")
+	}
+	if result.DbPassword == "" {
+		result.DbPassword = getenv("DB_PASS")
+	}
+	if v := getenv("LISTEN"); v != "" && result.Listen == ":3000" {
+		result.Listen = v
+	}
+
+	if !setupIdentifierRegexp.MatchString(result.DbName) {
+		return nil, fmt.Errorf("invalid database name %q", result.DbName)
+	}
+	if !setupIdentifierRegexp.MatchString(result.DbUser) {
+		return nil, fmt.Errorf("invalid database user %q", result.DbUser)
+	}
+
+	superuser := env("PG_SUPERUSER", "")
+	superuserPass := getenv("PG_SUPERUSER_PASS")
+
+	if superuser != "" {
+		if result.DbPassword == "" {
+			result.DbPassword = generateSetupSecret()
+			result.DbPasswordGenerated = true
+		}
+		if err := provisionSetupDatabase(result.DbHost, result.DbPort, superuser, superuserPass, result.DbName, result.DbUser, result.DbPassword); err != nil {
+			return nil, err
+		}
+		result.DatabaseProvisioned = true
+	} else if result.DbPassword == "" {
+		return nil, fmt.Errorf("DB_PASS is required when PG_SUPERUSER is not set")
+	}
+
+	content := setupConfigContent("non-interactive setup (-setup_auto)", result.DbHost, strconv.FormatUint(uint64(result.DbPort), 10), result.DbName, result.DbUser, result.DbPassword, result.Listen)
+	if err := os.WriteFile(result.ConfigFile, []byte(content), 0600); err != nil {
+		return nil, fmt.Errorf("failed to write config file: %v", err)
+	}
+
+	config.DbHost = result.DbHost
+	config.DbPort = result.DbPort
+	config.DbName = result.DbName
+	config.DbUsername = result.DbUser
+	config.DbPassword = result.DbPassword
+	config.Listen = result.Listen
+
+	// Opening the database runs the migrations, which creates the schema
+	log.Printf("setup: creating schema in %s", result.DbName)
+	database := NewDatabase(config)
+	defer database.Sql.Close()
+
+	options := NewOptions()
+	if err := options.Read(database); err != nil {
+		return nil, fmt.Errorf("failed to read options: %v", err)
+	}
+
+	adminPassword := getenv("ADMIN_PASSWORD")
+	if adminPassword == "" && !options.adminPasswordNeedChange && !isDefaultAdminPassword(options.adminPassword) {
+		result.AdminPasswordUnchanged = true
+		return result, nil
+	}
+	if adminPassword == "" {
+		adminPassword = generateAdminPassword()
+		result.AdminPasswordGenerated = true
+	}
+
+	users := NewUsers()
+	if err := users.Read(database); err != nil {
+		return nil, fmt.Errorf("failed to read users: %v", err)
+	}
+
+	if err := setAdminCredentials(database, options, users, getenv("ADMIN_EMAIL"), adminPassword); err != nil {
+		return nil, fmt.Errorf("failed to set admin credentials: %v", err)
+	}
+
+	result.AdminPassword = adminPassword
+	result.AdminEmail = NormalizeEmail(getenv("ADMIN_EMAIL"))
+
+	return result, nil
+}
+
+// provisionSetupDatabase creates (or updates) the application role and database
+// using superuser credentials. Identifiers must already be validated.
+func provisionSetupDatabase(host string, port uint, superuser string, superuserPass string, dbName string, dbUser string, dbPassword string) error {
+	connStr := fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=postgres sslmode=disable", host, port, superuser, superuserPass)
+
+	db, err := sql.Open("postgres", connStr)
+	if err != nil {
+		return fmt.Errorf("failed to connect to PostgreSQL: %v", err)
+	}
+	defer db.Close()
+
+	if err := db.Ping(); err != nil {
+		return fmt.Errorf("failed to ping PostgreSQL: %v", err)
+	}
+
+	safePassword := strings.ReplaceAll(dbPassword, "'", "''")
+
+	var exists bool
+	if err := db.QueryRow(`SELECT EXISTS (SELECT 1 FROM pg_roles WHERE rolname = $1)`, dbUser).Scan(&exists); err != nil {
+		return fmt.Errorf("failed to look up user: %v", err)
+	}
+	if exists {
+		log.Printf("setup: user %s exists, updating password", dbUser)
+		if _, err := db.Exec(fmt.Sprintf(`ALTER USER "%s" WITH PASSWORD '%s'`, dbUser, safePassword)); err != nil {
+			return fmt.Errorf("failed to update user password: %v", err)
+		}
+	} else {
+		log.Printf("setup: creating user %s", dbUser)
+		if _, err := db.Exec(fmt.Sprintf(`CREATE USER "%s" WITH PASSWORD '%s'`, dbUser, safePassword)); err != nil {
+			return fmt.Errorf("failed to create user: %v", err)
+		}
+	}
+
+	if err := db.QueryRow(`SELECT EXISTS (SELECT 1 FROM pg_database WHERE datname = $1)`, dbName).Scan(&exists); err != nil {
+		return fmt.Errorf("failed to look up database: %v", err)
+	}
+	if !exists {
+		log.Printf("setup: creating database %s", dbName)
+		if _, err := db.Exec(fmt.Sprintf(`CREATE DATABASE "%s" OWNER "%s"`, dbName, dbUser)); err != nil {
+			return fmt.Errorf("failed to create database: %v", err)
+		}
+	}
+
+	if _, err := db.Exec(fmt.Sprintf(`GRANT ALL PRIVILEGES ON DATABASE "%s" TO "%s"`, dbName, dbUser)); err != nil {
+		return fmt.Errorf("failed to grant privileges: %v", err)
+	}
+
+	return nil
+}
+
+// generateAdminPassword returns a random password that passes ValidatePassword.
+func generateAdminPassword() string {
+	for {
+		if password := generateSetupSecret(); ValidatePassword(password) == nil {
+			return password
+		}
+	}
+}
+
+func generateSetupSecret() string {
+	b := make([]byte, 18)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
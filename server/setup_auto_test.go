@@ -0,0 +1,50 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"gopkg.in/ini.v1"
+)
+
+func TestAutomatedSetupValidatesInputs(t *testing.T) {
+	env := func(values map[string]string) func(string) string {
+		return func(key string) string { return values[key] }
+	}
+
+	config := &Config{BaseDir: t.TempDir(), ConfigFile: "thinline-radio.ini", DbHost: "localhost", Listen: ":3000"}
+	if _, err := runAutomatedSetup(config, env(map[string]string{"DB_NAME": "radio; DROP DATABASE x"})); err == nil || !strings.Contains(err.Error(), "invalid database name") {
+		t.Fatalf("expected invalid database name, got %v", err)
+	}
+
+	config = &Config{BaseDir: t.TempDir(), ConfigFile: "thinline-radio.ini", DbHost: "localhost", Listen: ":3000"}
+	if _, err := runAutomatedSetup(config, env(map[string]string{"DB_NAME": "radio", "DB_USER": "radio"})); err == nil || !strings.Contains(err.Error(), "DB_PASS is required") {
+		t.Fatalf("expected missing password error, got %v", err)
+	}
+}
+
+func TestSetupOptionalContentRoundTrip(t *testing.T) {
+	smtp := &SetupSMTPSettings{Host: "smtp.example.com", Port: 465, Username: "radio", Password: "p#ss;word", UseTLS: true, FromEmail: "radio@example.com", FromName: "Radio"}
+	transcription := &TranscriptionConfig{Enabled: true, Provider: "whisper-api", Language: "en", WhisperAPIURL: "http://whisper:8000"}
+	tlsSettings := &SetupTLSSettings{Listen: "0.0.0.0:443", AutoCert: "radio.example.com"}
+
+	content := setupConfigContent("test", "localhost", "5432", "radio", "radio", "secret", ":3000") + setupOptionalContent(tlsSettings, smtp, transcription)
+
+	cfg, err := ini.Load([]byte(content))
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if got := cfg.Section("").Key("ssl_auto_cert").String(); got != "radio.example.com" {
+		t.Fatalf("ssl_auto_cert = %q", got)
+	}
+
+	config := &Config{}
+	config.readSetupSections(cfg)
+
+	if config.SetupSMTP == nil || *config.SetupSMTP != *smtp {
+		t.Fatalf("smtp = %+v", config.SetupSMTP)
+	}
+	if config.SetupTranscription == nil || config.SetupTranscription.Provider != "whisper-api" || config.SetupTranscription.WhisperAPIURL != "http://whisper:8000" {
+		t.Fatalf("transcription = %+v", config.SetupTranscription)
+	}
+}
@@ -0,0 +1,536 @@
+// Copyright (C) 2026 Thinline Dynamic Solutions
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>
+
+package main
+
+import (
+	"crypto/tls"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	"gopkg.in/ini.v1"
+)
+
+// SetupTLSSettings is written to the ssl_* keys of the config file.
+type SetupTLSSettings struct {
+	Listen   string
+	AutoCert string
+	CertFile string
+	KeyFile  string
+}
+
+// SetupSMTPSettings is the [smtp] section of the config file. The admin options
+// are seeded from it on first start; later changes are made in the admin UI.
+type SetupSMTPSettings struct {
+	Host       string
+	Port       int
+	Username   string
+	Password   string
+	UseTLS     bool
+	SkipVerify bool
+	FromEmail  string
+	FromName   string
+}
+
+// setupTLSStep asks for automatic (Let's Encrypt) or manual certificates.
+func setupTLSStep() *SetupTLSSettings {
+	fmt.Println("\n━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+	fmt.Println("HTTPS / TLS (optional)")
+	fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+	fmt.Println("  1. Skip — serve plain HTTP (e.g. behind a reverse proxy)")
+	fmt.Println("  2. Let's Encrypt — automatic certificate for a public domain name")
+	fmt.Println("  3. Manual — use an existing certificate and key (PEM)")
+
+	switch readInput("Enter choice", "1") {
+	case "2":
+		domain := readInput("Domain name (must resolve to this server)", "")
+		if domain == "" {
+			fmt.Println("⚠️  No domain given, skipping TLS")
+			return nil
+		}
+		fmt.Println("  Let's Encrypt validates over port 443, which must be reachable from the internet.")
+		return &SetupTLSSettings{
+			Listen:   readInput("HTTPS listen address", "0.0.0.0:443"),
+			AutoCert: domain,
+		}
+
+	case "3":
+		for {
+			settings := &SetupTLSSettings{
+				CertFile: readInput("Certificate file", ""),
+				KeyFile:  readInput("Private key file", ""),
+			}
+			if settings.CertFile == "" || settings.KeyFile == "" {
+				fmt.Println("⚠️  Certificate or key missing, skipping TLS")
+				return nil
+			}
+
+			fmt.Print("🔄 Checking certificate... ")
+			if _, err := tls.LoadX509KeyPair(settings.CertFile, settings.KeyFile); err != nil {
+				fmt.Println("❌")
+				fmt.Printf("  %v\n", err)
+				if readInput("Try again? (y/n)", "y") != "y" {
+					return nil
+				}
+				continue
+			}
+			fmt.Println("✓")
+
+			settings.Listen = readInput("HTTPS listen address", "0.0.0.0:3443")
+			return settings
+		}
+	}
+
+	return nil
+}
+
+// setupSMTPStep asks for SMTP settings and offers to send a test email.
+func setupSMTPStep() *SetupSMTPSettings {
+	fmt.Println("\n━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+	fmt.Println("Email / SMTP (optional)")
+	fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+	fmt.Println("Used for account verification and password reset emails.")
+
+	if readInput("Configure SMTP now? (y/n)", "n") != "y" {
+		return nil
+	}
+
+	for {
+		settings := &SetupSMTPSettings{Host: readInput("SMTP host", "")}
+		if settings.Host == "" {
+			return nil
+		}
+
+		port, err := strconv.Atoi(readInput("SMTP port", "587"))
+		if err != nil || port <= 0 {
+			port = 587
+		}
+		settings.Port = port
+		settings.UseTLS = readInput("Use TLS (STARTTLS, or implicit TLS on port 465)? (y/n)", "y") == "y"
+		settings.Username = readInput("SMTP username", "")
+		if settings.Username != "" {
+			if settings.Password, err = readPassword("SMTP password: "); err != nil {
+				fmt.Printf("⚠️  %v\n", err)
+			}
+		}
+		settings.FromEmail = readInput("From email address", settings.Username)
+		settings.FromName = readInput("From name", "ThinLine Radio")
+
+		to := readInput("Send a test email to (leave empty to skip)", "")
+		if to == "" {
+			return settings
+		}
+
+		fmt.Print("🔄 Sending test email... ")
+		if err := sendSetupTestEmail(settings, to); err != nil {
+			fmt.Println("❌")
+			fmt.Printf("  %v\n", err)
+			switch readInput("Re-enter SMTP settings (r), keep them anyway (k) or skip SMTP (s)?", "r") {
+			case "k":
+				return settings
+			case "s":
+				return nil
+			}
+			continue
+		}
+		fmt.Println("✓")
+
+		return settings
+	}
+}
+
+// sendSetupTestEmail sends a test message through the same SMTP code the server uses.
+func sendSetupTestEmail(settings *SetupSMTPSettings, to string) error {
+	options := NewOptions()
+	settings.applyTo(options)
+
+	es := &EmailService{Controller: &Controller{Options: options}}
+
+	return es.sendSMTPEmail(settings.FromName, settings.FromEmail, to, "ThinLine Radio test email", "<p>Your SMTP settings work. This message was sent by the ThinLine Radio setup wizard.</p>")
+}
+
+func (settings *SetupSMTPSettings) applyTo(options *Options) {
+	options.EmailServiceEnabled = true
+	options.EmailProvider = "smtp"
+	options.EmailSmtpHost = settings.Host
+	options.EmailSmtpPort = settings.Port
+	options.EmailSmtpUsername = settings.Username
+	options.EmailSmtpPassword = settings.Password
+	options.EmailSmtpUseTLS = settings.UseTLS
+	options.EmailSmtpSkipVerify = settings.SkipVerify
+	options.EmailSmtpFromEmail = settings.FromEmail
+	options.EmailSmtpFromName = settings.FromName
+}
+
+// setupTranscriptionStep asks for a transcription provider and offers a test
+// transcription.
+func setupTranscriptionStep() *TranscriptionConfig {
+	fmt.Println("\n━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+	fmt.Println("Transcription (optional)")
+	fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+	fmt.Println("  1. Skip")
+	fmt.Println("  2. Whisper API (self-hosted Whisper server or OpenAI)")
+	fmt.Println("  3. Azure Speech Services")
+	fmt.Println("  4. Google Cloud Speech-to-Text")
+	fmt.Println("  5. AssemblyAI")
+	fmt.Println("  6. Cloudflare Workers AI")
+	fmt.Println("  7. Local whisper.cpp / faster-whisper (offline)")
+	fmt.Println("  8. Deepgram")
+
+	for {
+		config := &TranscriptionConfig{Enabled: true}
+
+		var err error
+		switch readInput("Enter choice", "1") {
+		case "2":
+			config.Provider = "whisper-api"
+			config.WhisperAPIURL = readInput("Whisper API URL", "http://localhost:8000")
+			if config.WhisperAPIKey, err = readPassword("API key (leave empty for none): "); err != nil {
+				return nil
+			}
+			config.WhisperAPIModel = readInput("Model", "whisper-1")
+		case "3":
+			config.Provider = "azure"
+			if config.AzureKey, err = readPassword("Azure subscription key: "); err != nil {
+				return nil
+			}
+			config.AzureRegion = readInput("Azure region", "eastus")
+		case "4":
+			config.Provider = "google"
+			if config.GoogleAPIKey, err = readPassword("Google API key: "); err != nil {
+				return nil
+			}
+		case "5":
+			config.Provider = "assemblyai"
+			if config.AssemblyAIKey, err = readPassword("AssemblyAI API key: "); err != nil {
+				return nil
+			}
+		case "6":
+			config.Provider = "cloudflare"
+			config.CloudflareAccountID = readInput("Cloudflare account ID", "")
+			if config.CloudflareAPIToken, err = readPassword("Cloudflare API token: "); err != nil {
+				return nil
+			}
+		case "7":
+			config.Provider = "whisper-local"
+			config.WhisperLocalEngine = readInput("Engine (whisper.cpp or faster-whisper)", WhisperLocalEngineCpp)
+			if config.WhisperLocalEngine == WhisperLocalEngineFaster {
+				config.WhisperLocalBinary = readInput("Binary", "whisper-ctranslate2")
+				config.WhisperLocalModel = readInput("Model (tiny, base, small, medium, large-v3)", "small")
+			} else {
+				config.WhisperLocalBinary = readInput("Binary", "whisper-cli")
+				config.WhisperLocalModel = readInput("Model file (ggml .bin)", "")
+			}
+		case "8":
+			config.Provider = "deepgram"
+			if config.DeepgramKey, err = readPassword("Deepgram API key: "); err != nil {
+				return nil
+			}
+			config.DeepgramModel = readInput("Model", "nova-3")
+		default:
+			return nil
+		}
+		config.Language = readInput("Language (e.g. en, auto)", "en")
+
+		if readInput("Run a test transcription? (y/n)", "y") != "y" {
+			return config
+		}
+
+		audioFile := readInput("Audio file to transcribe (leave empty for a generated test tone)", "")
+		fmt.Print("🔄 Transcribing... ")
+		transcript, err := runSetupTestTranscription(config, audioFile)
+		if err != nil {
+			fmt.Println("❌")
+			fmt.Printf("  %v\n", err)
+			switch readInput("Re-enter provider settings (r), keep them anyway (k) or skip transcription (s)?", "r") {
+			case "k":
+				return config
+			case "s":
+				return nil
+			}
+			continue
+		}
+		fmt.Println("✓")
+		if transcript == "" {
+			fmt.Println("  Provider responded (no speech in the sample)")
+		} else {
+			fmt.Printf("  Transcript: %s\n", transcript)
+		}
+
+		return config
+	}
+}
+
+// runSetupTestTranscription sends audioFile, or a short generated tone, to the
+// configured provider.
+func runSetupTestTranscription(config *TranscriptionConfig, audioFile string) (string, error) {
+	provider := newTranscriptionProvider(*config)
+	if !provider.IsAvailable() {
+		return "", fmt.Errorf("provider %s is not available, check the settings", provider.GetName())
+	}
+
+	audio := setupTestToneWav()
+	mime := "audio/wav"
+	if audioFile != "" {
+		b, err := os.ReadFile(audioFile)
+		if err != nil {
+			return "", err
+		}
+		audio = b
+		mime = ""
+	}
+
+	result, err := provider.Transcribe(audio, TranscriptionOptions{Language: config.Language, AudioMime: mime})
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(result.Transcript), nil
+}
+
+// setupTestToneWav renders one second of a 1 kHz tone as 16 kHz mono PCM WAV.
+func setupTestToneWav() []byte {
+	const rate = 16000
+
+	samples := make([]int16, rate)
+	for i := range samples {
+		samples[i] = int16(8000 * math.Sin(2*math.Pi*1000*float64(i)/rate))
+	}
+
+	return encodePCM16Wav(samples, rate)
+}
+
+// setupAdminStep creates the administrator account in the new database. When
+// skipped, the server prints a one-time setup token on first start instead.
+// It returns the email of the system administrator user, if one was created.
+func setupAdminStep(config *Config) (bool, string, error) {
+	fmt.Println("\n━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+	fmt.Println("Administrator Account")
+	fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+	fmt.Println("  1. Create it now (password, and optionally an email for a system admin user)")
+	fmt.Println("  2. Later — print a one-time setup token to the console on first start")
+
+	if readInput("Enter choice", "1") != "1" {
+		return false, "", nil
+	}
+
+	email := readInput("Administrator email (leave empty for password-only admin)", "")
+
+	var password string
+	for {
+		var err error
+		if password, err = readPassword("Administrator password (8+ characters, upper, lower and a number): "); err != nil {
+			return false, "", fmt.Errorf("failed to read password: %v", err)
+		}
+		if password == defaults.adminPassword {
+			fmt.Println("⚠️  The default password cannot be used")
+			continue
+		}
+		if err := ValidatePassword(password); err != nil {
+			fmt.Printf("⚠️  %v\n", err)
+			continue
+		}
+		confirm, err := readPassword("Confirm administrator password: ")
+		if err != nil {
+			return false, "", fmt.Errorf("failed to read password: %v", err)
+		}
+		if confirm != password {
+			fmt.Println("⚠️  Passwords do not match")
+			continue
+		}
+		break
+	}
+
+	// Opening the database runs the migrations, which creates the schema
+	fmt.Print("🔄 Creating schema and administrator account... ")
+	database := NewDatabase(config)
+	defer database.Sql.Close()
+
+	options := NewOptions()
+	if err := options.Read(database); err != nil {
+		fmt.Println("❌")
+		return false, "", fmt.Errorf("failed to read options: %v", err)
+	}
+
+	users := NewUsers()
+	if err := users.Read(database); err != nil {
+		fmt.Println("❌")
+		return false, "", fmt.Errorf("failed to read users: %v", err)
+	}
+
+	if err := setAdminCredentials(database, options, users, email, password); err != nil {
+		fmt.Println("❌")
+		return false, "", err
+	}
+	fmt.Println("✓")
+
+	return true, NormalizeEmail(email), nil
+}
+
+// setupOptionalContent renders the TLS keys and the [smtp] and [transcription]
+// sections appended to the config file by the setup wizard. Keys in the default
+// section must come before the first section header.
+func setupOptionalContent(tlsSettings *SetupTLSSettings, smtp *SetupSMTPSettings, transcription *TranscriptionConfig) string {
+	var b strings.Builder
+
+	if tlsSettings != nil {
+		b.WriteString("\n# HTTPS Configuration\n")
+		fmt.Fprintf(&b, "ssl_listen = %s\n", tlsSettings.Listen)
+		if tlsSettings.AutoCert != "" {
+			fmt.Fprintf(&b, "ssl_auto_cert = %s\n", tlsSettings.AutoCert)
+		} else {
+			fmt.Fprintf(&b, "ssl_cert_file = %s\n", setupIniValue(tlsSettings.CertFile))
+			fmt.Fprintf(&b, "ssl_key_file = %s\n", setupIniValue(tlsSettings.KeyFile))
+		}
+	}
+
+	if smtp != nil {
+		b.WriteString("\n# Email settings, applied to the admin options on first start\n[smtp]\n")
+		fmt.Fprintf(&b, "host = %s\n", setupIniValue(smtp.Host))
+		fmt.Fprintf(&b, "port = %d\n", smtp.Port)
+		fmt.Fprintf(&b, "username = %s\n", setupIniValue(smtp.Username))
+		fmt.Fprintf(&b, "password = %s\n", setupIniValue(smtp.Password))
+		fmt.Fprintf(&b, "use_tls = %t\n", smtp.UseTLS)
+		fmt.Fprintf(&b, "skip_verify = %t\n", smtp.SkipVerify)
+		fmt.Fprintf(&b, "from_email = %s\n", setupIniValue(smtp.FromEmail))
+		fmt.Fprintf(&b, "from_name = %s\n", setupIniValue(smtp.FromName))
+	}
+
+	if transcription != nil {
+		b.WriteString("\n# Transcription settings, applied to the admin options on first start\n[transcription]\n")
+		for _, kv := range setupTranscriptionKeys(transcription) {
+			if *kv.value != "" {
+				fmt.Fprintf(&b, "%s = %s\n", kv.key, setupIniValue(*kv.value))
+			}
+		}
+	}
+
+	return b.String()
+}
+
+type setupIniKey struct {
+	key   string
+	value *string
+}
+
+func setupTranscriptionKeys(config *TranscriptionConfig) []setupIniKey {
+	return []setupIniKey{
+		{"provider", &config.Provider},
+		{"language", &config.Language},
+		{"whisper_api_url", &config.WhisperAPIURL},
+		{"whisper_api_key", &config.WhisperAPIKey},
+		{"whisper_api_model", &config.WhisperAPIModel},
+		{"azure_key", &config.AzureKey},
+		{"azure_region", &config.AzureRegion},
+		{"google_api_key", &config.GoogleAPIKey},
+		{"assemblyai_key", &config.AssemblyAIKey},
+		{"cloudflare_account_id", &config.CloudflareAccountID},
+		{"cloudflare_api_token", &config.CloudflareAPIToken},
+		{"cloudflare_model", &config.CloudflareModel},
+		{"whisper_local_engine", &config.WhisperLocalEngine},
+		{"whisper_local_binary", &config.WhisperLocalBinary},
+		{"whisper_local_model", &config.WhisperLocalModel},
+		{"deepgram_key", &config.DeepgramKey},
+		{"deepgram_model", &config.DeepgramModel},
+	}
+}
+
+// setupIniValue quotes values that ini would otherwise cut at a comment
+// character or trim.
+func setupIniValue(v string) string {
+	if v == "" || !strings.ContainsAny(v, "#;\"`'") && strings.TrimSpace(v) == v {
+		return v
+	}
+	if !strings.Contains(v, "`") {
+		return "`" + v + "`"
+	}
+	return `"""` + v + `"""`
+}
+
+// readSetupSections loads the [smtp] and [transcription] sections written by
+// the setup wizard.
+func (config *Config) readSetupSections(cfg *ini.File) {
+	if section, err := cfg.GetSection("smtp"); err == nil && section.Key("host").String() != "" {
+		smtp := &SetupSMTPSettings{
+			Host:      section.Key("host").String(),
+			Port:      section.Key("port").MustInt(587),
+			Username:  section.Key("username").String(),
+			Password:  section.Key("password").String(),
+			UseTLS:    section.Key("use_tls").MustBool(true),
+			FromEmail: section.Key("from_email").String(),
+			FromName:  section.Key("from_name").String(),
+		}
+		smtp.SkipVerify = section.Key("skip_verify").MustBool(false)
+		config.SetupSMTP = smtp
+	}
+
+	if section, err := cfg.GetSection("transcription"); err == nil && section.Key("provider").String() != "" {
+		transcription := &TranscriptionConfig{Enabled: true}
+		for _, kv := range setupTranscriptionKeys(transcription) {
+			*kv.value = section.Key(kv.key).String()
+		}
+		config.SetupTranscription = transcription
+	}
+}
+
+// applySetupSections copies the [smtp] and [transcription] sections into the
+// options the first time the server sees them. Later changes are made in the
+// admin UI and are not overwritten on restart.
+func (controller *Controller) applySetupSections() {
+	config := controller.Config
+	options := controller.Options
+
+	applied := strings.Split(options.setupSectionsApplied, ",")
+	isApplied := func(section string) bool {
+		for _, s := range applied {
+			if s == section {
+				return true
+			}
+		}
+		return false
+	}
+
+	changed := false
+
+	if config.SetupSMTP != nil && !isApplied("smtp") {
+		config.SetupSMTP.applyTo(options)
+		applied = append(applied, "smtp")
+		changed = true
+		controller.Logs.LogEvent(LogLevelInfo, fmt.Sprintf("setup: email configured for SMTP server %s from the config file", config.SetupSMTP.Host))
+	}
+
+	if seed := config.SetupTranscription; seed != nil && !isApplied("transcription") {
+		current := &options.TranscriptionConfig
+		current.Enabled = true
+		for i, kv := range setupTranscriptionKeys(current) {
+			if v := *setupTranscriptionKeys(seed)[i].value; v != "" {
+				*kv.value = v
+			}
+		}
+		applied = append(applied, "transcription")
+		changed = true
+		controller.Logs.LogEvent(LogLevelInfo, fmt.Sprintf("setup: transcription configured for provider %s from the config file", seed.Provider))
+	}
+
+	if changed {
+		options.setupSectionsApplied = strings.Trim(strings.Join(applied, ","), ",")
+		if err := options.Write(controller.Database); err != nil {
+			controller.Logs.LogEvent(LogLevelError, fmt.Sprintf("setup: failed to save options: %v", err))
+		}
+	}
+}
@@ -33,6 +33,11 @@ type Site struct {
 	RFSS        uint      // Radio Frequency Sub-System ID
 	SystemId    uint64
 	Frequencies []float64 // MHz frequencies for this site
+	// MHz control channels, primary first then alternates (from Radio Reference)
+	ControlChannels []float64
+	Latitude        float64 // From Radio Reference, 0 when unknown
+	Longitude       float64
+	County          string // County name from Radio Reference, e.g. "Sangamon"
 }
 
 func NewSite() *Site {
@@ -87,6 +92,30 @@ func (site *Site) FromMap(m map[string]any) *Site {
 		}
 	}
 
+	switch v := m["controlChannels"].(type) {
+	case []any:
+		site.ControlChannels = []float64{}
+		for _, f := range v {
+			switch freq := f.(type) {
+			case float64:
+				site.ControlChannels = append(site.ControlChannels, freq)
+			}
+		}
+	}
+
+	switch v := m["latitude"].(type) {
+	case float64:
+		site.Latitude = v
+	}
+	switch v := m["longitude"].(type) {
+	case float64:
+		site.Longitude = v
+	}
+	switch v := m["county"].(type) {
+	case string:
+		site.County = strings.TrimSpace(v)
+	}
+
 	return site
 }
 
@@ -108,6 +137,17 @@ func (site *Site) MarshalJSON() ([]byte, error) {
 	if site.Frequencies == nil {
 		m["frequencies"] = []float64{}
 	}
+	m["controlChannels"] = site.ControlChannels
+	if site.ControlChannels == nil {
+		m["controlChannels"] = []float64{}
+	}
+	if site.HasLocation() {
+		m["latitude"] = site.Latitude
+		m["longitude"] = site.Longitude
+	}
+	if site.County != "" {
+		m["county"] = site.County
+	}
 
 	return json.Marshal(m)
 }
@@ -212,6 +252,23 @@ func (sites *Sites) GetSiteByFrequency(frequency uint) (site *Site, ok bool) {
 	return nil, false
 }
 
+// HasLocation reports whether the coordinates of the site are known.
+func (site *Site) HasLocation() bool {
+	return site.Latitude != 0 || site.Longitude != 0
+}
+
+// parseSiteControlChannels decodes the "controlChannels" column.
+func parseSiteControlChannels(s string) []float64 {
+	channels := []float64{}
+	if len(s) > 0 {
+		json.Unmarshal([]byte(s), &channels)
+	}
+	if channels == nil {
+		channels = []float64{}
+	}
+	return channels
+}
+
 func (sites *Sites) ReadTx(tx *sql.Tx, systemId uint64) error {
 	var (
 		err   error
@@ -226,17 +283,17 @@ func (sites *Sites) ReadTx(tx *sql.Tx, systemId uint64) error {
 
 	formatError := errorFormatter("sites", "read")
 
-	query = fmt.Sprintf(`SELECT "siteId", "label", "order", "siteRef", "rfss", "frequencies", "preferred" FROM "sites" WHERE "systemId" = %d`, systemId)
-	if rows, err = tx.Query(query); err != nil {
+	query = `SELECT "siteId", "label", "order", "siteRef", "rfss", "frequencies", "controlChannels", "latitude", "longitude", "county", "preferred" FROM "sites" WHERE "systemId" = $1`
+	if rows, err = tx.Query(query, systemId); err != nil {
 		return formatError(err, query)
 	}
 
 	for rows.Next() {
 		site := NewSite()
-		var frequenciesJSON string
+		var frequenciesJSON, controlChannelsJSON string
 
 		var preferredUnused bool
-		if err = rows.Scan(&site.Id, &site.Label, &site.Order, &site.SiteRef, &site.RFSS, &frequenciesJSON, &preferredUnused); err != nil {
+		if err = rows.Scan(&site.Id, &site.Label, &site.Order, &site.SiteRef, &site.RFSS, &frequenciesJSON, &controlChannelsJSON, &site.Latitude, &site.Longitude, &site.County, &preferredUnused); err != nil {
 			break
 		}
 
@@ -247,6 +304,7 @@ func (sites *Sites) ReadTx(tx *sql.Tx, systemId uint64) error {
 		if site.Frequencies == nil {
 			site.Frequencies = []float64{}
 		}
+		site.ControlChannels = parseSiteControlChannels(controlChannelsJSON)
 
 		sites.List = append(sites.List, site)
 	}
@@ -277,8 +335,8 @@ func (sites *Sites) WriteTx(tx *sql.Tx, systemId uint64) error {
 
 	formatError := errorFormatter("sites", "writetx")
 
-	query = fmt.Sprintf(`SELECT "siteId" FROM "sites" WHERE "systemId" = %d`, systemId)
-	if rows, err = tx.Query(query); err != nil {
+	query = `SELECT "siteId" FROM "sites" WHERE "systemId" = $1`
+	if rows, err = tx.Query(query, systemId); err != nil {
 		return formatError(err, query)
 	}
 
@@ -306,12 +364,10 @@ func (sites *Sites) WriteTx(tx *sql.Tx, systemId uint64) error {
 	}
 
 	if len(siteIds) > 0 {
-		if b, err := json.Marshal(siteIds); err == nil {
-			in := strings.ReplaceAll(strings.ReplaceAll(string(b), "[", "("), "]", ")")
-			query = fmt.Sprintf(`DELETE FROM "sites" WHERE "siteId" IN %s`, in)
-			if _, err = tx.Exec(query); err != nil {
-				return formatError(err, query)
-			}
+		args := queryArgs{}
+		query = `DELETE FROM "sites" WHERE "siteId" IN ` + args.in(siteIds)
+		if _, err = tx.Exec(query, args...); err != nil {
+			return formatError(err, query)
 		}
 	}
 
@@ -325,31 +381,48 @@ func (sites *Sites) WriteTx(tx *sql.Tx, systemId uint64) error {
 				frequenciesJSON = string(b)
 			}
 		}
+		controlChannelsJSON := "[]"
+		if len(site.ControlChannels) > 0 {
+			if b, err := json.Marshal(site.ControlChannels); err == nil {
+				controlChannelsJSON = string(b)
+			}
+		}
 
 		if site.Id > 0 {
-			query = fmt.Sprintf(`SELECT COUNT(*) FROM "sites" WHERE "siteId" = %d`, site.Id)
-			if err = tx.QueryRow(query).Scan(&count); err != nil {
+			query = `SELECT COUNT(*) FROM "sites" WHERE "siteId" = $1`
+			if err = tx.QueryRow(query, site.Id).Scan(&count); err != nil {
 				break
 			}
 		}
 
+		columns := queryColumns{}
+		columns.set("label", site.Label)
+		columns.set("order", site.Order)
+		columns.set("siteRef", site.SiteRef)
+		columns.set("rfss", site.RFSS)
+		columns.set("frequencies", frequenciesJSON)
+		columns.set("controlChannels", controlChannelsJSON)
+		columns.set("latitude", site.Latitude)
+		columns.set("longitude", site.Longitude)
+		columns.set("county", site.County)
+		columns.set("preferred", false)
+
+		var args []any
+
 		if count == 0 {
+			columns.set("systemId", systemId)
 			if site.Id > 0 {
 				// Preserve the explicit ID when inserting
-				query = fmt.Sprintf(`INSERT INTO "sites" ("siteId", "label", "order", "siteRef", "rfss", "systemId", "frequencies", "preferred") VALUES (%d, '%s', %d, '%s', %d, %d, '%s', %t)`, site.Id, escapeQuotes(site.Label), site.Order, escapeQuotes(site.SiteRef), site.RFSS, systemId, frequenciesJSON, false)
-			} else {
-				// Let database assign auto-increment ID
-				query = fmt.Sprintf(`INSERT INTO "sites" ("label", "order", "siteRef", "rfss", "systemId", "frequencies", "preferred") VALUES ('%s', %d, '%s', %d, %d, '%s', %t)`, escapeQuotes(site.Label), site.Order, escapeQuotes(site.SiteRef), site.RFSS, systemId, frequenciesJSON, false)
-			}
-			if _, err = tx.Exec(query); err != nil {
-				break
+				columns.set("siteId", site.Id)
 			}
-
+			// Otherwise let database assign auto-increment ID
+			query, args = columns.insert("sites")
 		} else {
-			query = fmt.Sprintf(`UPDATE "sites" SET "label" = '%s', "order" = %d, "siteRef" = '%s', "rfss" = %d, "frequencies" = '%s', "preferred" = %t where "siteId" = %d`, escapeQuotes(site.Label), site.Order, escapeQuotes(site.SiteRef), site.RFSS, frequenciesJSON, false, site.Id)
-			if _, err = tx.Exec(query); err != nil {
-				break
-			}
+			query, args = columns.update("sites", "siteId", site.Id)
+		}
+
+		if _, err = tx.Exec(query, args...); err != nil {
+			break
 		}
 	}
 
@@ -0,0 +1,184 @@
+// Copyright (C) 2026 Thinline Dynamic Solutions
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>
+
+package main
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+
+	"gonum.org/v1/gonum/dsp/fourier"
+)
+
+const (
+	// speechFrameSamples is the analysis frame, 32ms at energySampleHz.
+	speechFrameSamples = 256
+
+	speechGateDefaultMinLevel         = -45.0 // dBFS
+	speechGateDefaultMaxFlatness      = 0.4
+	speechGateDefaultMinSpeechSeconds = 0.5
+
+	// Voice band used for the spectral flatness
+	speechBandLowHz  = 300
+	speechBandHighHz = 3400
+)
+
+// SpeechGateConfig skips transcription of calls without voice, such as
+// static-only calls Whisper makes up transcripts for. A frame counts as speech
+// when it is loud enough and its spectrum is peaked rather than flat like
+// noise; calls with less speech than MinSpeechSeconds are marked "no speech".
+type SpeechGateConfig struct {
+	Enabled          bool                 `json:"enabled"`
+	MinLevel         float64              `json:"minLevel,omitempty"`         // dBFS, default -45
+	MaxFlatness      float64              `json:"maxFlatness,omitempty"`      // 0 to 1, default 0.4
+	MinSpeechSeconds float64              `json:"minSpeechSeconds,omitempty"` // default 0.5
+	Systems          []SpeechGateSettings `json:"systems,omitempty"`          // per-system thresholds
+}
+
+// SpeechGateSettings overrides the thresholds for one system; zero values
+// keep the global ones.
+type SpeechGateSettings struct {
+	SystemRef        uint    `json:"systemRef"`
+	MinLevel         float64 `json:"minLevel,omitempty"`
+	MaxFlatness      float64 `json:"maxFlatness,omitempty"`
+	MinSpeechSeconds float64 `json:"minSpeechSeconds,omitempty"`
+}
+
+// ForSystem returns the thresholds that apply to the system.
+func (config SpeechGateConfig) ForSystem(systemRef uint) SpeechGateSettings {
+	settings := SpeechGateSettings{
+		SystemRef:        systemRef,
+		MinLevel:         config.MinLevel,
+		MaxFlatness:      config.MaxFlatness,
+		MinSpeechSeconds: config.MinSpeechSeconds,
+	}
+	for _, override := range config.Systems {
+		if override.SystemRef != systemRef {
+			continue
+		}
+		if override.MinLevel != 0 {
+			settings.MinLevel = override.MinLevel
+		}
+		if override.MaxFlatness != 0 {
+			settings.MaxFlatness = override.MaxFlatness
+		}
+		if override.MinSpeechSeconds != 0 {
+			settings.MinSpeechSeconds = override.MinSpeechSeconds
+		}
+	}
+	if settings.MinLevel == 0 {
+		settings.MinLevel = speechGateDefaultMinLevel
+	}
+	if settings.MaxFlatness == 0 {
+		settings.MaxFlatness = speechGateDefaultMaxFlatness
+	}
+	if settings.MinSpeechSeconds == 0 {
+		settings.MinSpeechSeconds = speechGateDefaultMinSpeechSeconds
+	}
+	return settings
+}
+
+// speechSeconds measures the speech in 8 kHz mono s16le audio: the frames at
+// least minLevel dBFS loud with a voice band spectral flatness of at most
+// maxFlatness.
+func speechSeconds(pcm []byte, minLevel float64, maxFlatness float64) float64 {
+	speech := 0
+	for _, voiced := range speechFrames(pcm, minLevel, maxFlatness) {
+		if voiced {
+			speech++
+		}
+	}
+	return float64(speech*speechFrameSamples) / energySampleHz
+}
+
+// speechFrames classifies each speechFrameSamples frame of 8 kHz mono s16le
+// audio as speech or not. A maxFlatness of 1 or more checks the level only.
+func speechFrames(pcm []byte, minLevel float64, maxFlatness float64) []bool {
+	frames := len(pcm) / 2 / speechFrameSamples
+	if frames == 0 {
+		return nil
+	}
+
+	fft := fourier.NewFFT(speechFrameSamples)
+	window := make([]float64, speechFrameSamples)
+	for i := range window {
+		window[i] = 0.5 - 0.5*math.Cos(2*math.Pi*float64(i)/float64(speechFrameSamples-1))
+	}
+	lowBin := speechBandLowHz * speechFrameSamples / energySampleHz
+	highBin := speechBandHighHz * speechFrameSamples / energySampleHz
+
+	samples := make([]float64, speechFrameSamples)
+	var coefficients []complex128
+	voiced := make([]bool, frames)
+	for f := 0; f < frames; f++ {
+		offset := f * speechFrameSamples * 2
+		var sumSq float64
+		for i := range samples {
+			sample := float64(int16(binary.LittleEndian.Uint16(pcm[offset+i*2:]))) / 32768
+			sumSq += sample * sample
+			samples[i] = sample * window[i]
+		}
+		rms := math.Sqrt(sumSq / speechFrameSamples)
+		if rms == 0 || 20*math.Log10(rms) < minLevel {
+			continue
+		}
+		if maxFlatness >= 1 {
+			voiced[f] = true
+			continue
+		}
+
+		coefficients = fft.Coefficients(coefficients, samples)
+		var logSum, sum float64
+		for k := lowBin; k <= highBin; k++ {
+			power := real(coefficients[k])*real(coefficients[k]) + imag(coefficients[k])*imag(coefficients[k]) + 1e-12
+			logSum += math.Log(power)
+			sum += power
+		}
+		bins := float64(highBin - lowBin + 1)
+		voiced[f] = math.Exp(logSum/bins)/(sum/bins) <= maxFlatness
+	}
+
+	return voiced
+}
+
+// passesSpeechGate reports whether the call has enough speech to transcribe.
+// Calls that do not are marked "no speech". The gate lets calls through when
+// it is disabled or the audio cannot be decoded.
+func (controller *Controller) passesSpeechGate(call *Call, audio []byte, mime string) bool {
+	config := controller.Options.SpeechGateConfig
+	if !config.Enabled || call.System == nil || len(audio) == 0 {
+		return true
+	}
+
+	pcm, err := decodeMonoPCM(audio, mime)
+	if err != nil {
+		controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("speech gate: call %d: %v", call.Id, err))
+		return true
+	}
+
+	settings := config.ForSystem(call.System.SystemRef)
+	speech := speechSeconds(pcm, settings.MinLevel, settings.MaxFlatness)
+	if speech >= settings.MinSpeechSeconds {
+		return true
+	}
+
+	controller.Logs.LogEvent(LogLevelInfo, fmt.Sprintf("skipping transcription for call %d: %.1fs of speech is less than %.1fs - no speech", call.Id, speech, settings.MinSpeechSeconds))
+	call.TranscriptionStatus = "no_speech"
+	if _, err := controller.Database.Sql.Exec(`UPDATE "calls" SET "transcriptionStatus" = 'no_speech' WHERE "callId" = $1`, call.Id); err != nil {
+		controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("speech gate: call %d: %v", call.Id, err))
+	}
+	return false
+}
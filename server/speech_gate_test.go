@@ -0,0 +1,58 @@
+package main
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestSpeechSeconds(t *testing.T) {
+	const seconds = 2
+	random := rand.New(rand.NewSource(1))
+
+	static := make([]int16, energySampleHz*seconds)
+	for i := range static {
+		static[i] = int16(random.NormFloat64() * 4000)
+	}
+	if speech := speechSeconds(pcmFromSamples(static), speechGateDefaultMinLevel, speechGateDefaultMaxFlatness); speech > 0.1 {
+		t.Fatalf("expected no speech in static, got %.2fs", speech)
+	}
+
+	// Voiced sound: harmonics of a 150 Hz pitch over a little noise
+	voiced := make([]int16, energySampleHz*seconds)
+	for i := range voiced {
+		var v float64
+		for harmonic := 1; harmonic <= 20; harmonic++ {
+			v += math.Sin(2*math.Pi*150*float64(harmonic)*float64(i)/energySampleHz) / float64(harmonic)
+		}
+		voiced[i] = int16(4000*v + random.NormFloat64()*100)
+	}
+	if speech := speechSeconds(pcmFromSamples(voiced), speechGateDefaultMinLevel, speechGateDefaultMaxFlatness); speech < 1.5 {
+		t.Fatalf("expected speech in voiced audio, got %.2fs", speech)
+	}
+
+	quiet := make([]int16, len(voiced))
+	for i, v := range voiced {
+		quiet[i] = v / 1000
+	}
+	if speech := speechSeconds(pcmFromSamples(quiet), speechGateDefaultMinLevel, speechGateDefaultMaxFlatness); speech != 0 {
+		t.Fatalf("expected audio under the level to have no speech, got %.2fs", speech)
+	}
+}
+
+func TestSpeechGateForSystem(t *testing.T) {
+	config := SpeechGateConfig{
+		Enabled:     true,
+		MaxFlatness: 0.3,
+		Systems:     []SpeechGateSettings{{SystemRef: 5, MinLevel: -35, MinSpeechSeconds: 1}},
+	}
+
+	settings := config.ForSystem(1)
+	if settings.MinLevel != speechGateDefaultMinLevel || settings.MaxFlatness != 0.3 || settings.MinSpeechSeconds != speechGateDefaultMinSpeechSeconds {
+		t.Fatalf("global settings = %+v", settings)
+	}
+	settings = config.ForSystem(5)
+	if settings.MinLevel != -35 || settings.MaxFlatness != 0.3 || settings.MinSpeechSeconds != 1 {
+		t.Fatalf("system settings = %+v", settings)
+	}
+}
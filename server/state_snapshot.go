@@ -0,0 +1,458 @@
+// Copyright (C) 2026 Thinline Dynamic Solutions
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>
+
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+var stateSnapshotLog = Logger(LogCategorySystem)
+
+// State snapshots let large installs skip the slow systems/talkgroups/users
+// load at boot. The snapshot is only a cache: it is accepted when its
+// signature, format version and DB fingerprint all check out, and the real
+// DB read still runs in the background right after startup to reconcile any
+// drift. The database always remains the source of truth.
+//
+// File layout: magic (8 bytes) | format version (1 byte) | HMAC-SHA256 (32 bytes) | gzip(gob(payload))
+
+const (
+	stateSnapshotFile          = "state-snapshot.bin"
+	stateSnapshotKeyFile       = "state-snapshot.key"
+	stateSnapshotMagic         = "TLRSNAP\x00"
+	stateSnapshotFormatVersion = byte(1)
+	// stateSnapshotMaxAge bounds how stale a snapshot may be and still be used for a cold start.
+	stateSnapshotMaxAge = 7 * 24 * time.Hour
+	// defaultStateSnapshotInterval is used when state_snapshot_interval is not set in the ini.
+	defaultStateSnapshotInterval = 15 * time.Minute
+)
+
+var (
+	errStateSnapshotMissing     = errors.New("no snapshot file")
+	errStateSnapshotBadMagic    = errors.New("not a state snapshot file")
+	errStateSnapshotBadVersion  = errors.New("unsupported snapshot format version")
+	errStateSnapshotBadSig      = errors.New("snapshot signature mismatch")
+	errStateSnapshotStale       = errors.New("snapshot is too old")
+	errStateSnapshotFingerprint = errors.New("snapshot does not match database fingerprint")
+)
+
+// StateFingerprint summarizes the DB tables covered by the snapshot. It is
+// compared on boot to detect snapshots written against a different DB or
+// before rows were added, removed or edited.
+type StateFingerprint struct {
+	Systems      int64
+	MaxSystemId  int64
+	Talkgroups   int64
+	MaxTalkgroup int64
+	Groups       int64
+	Tags         int64
+	Users        int64
+	MaxUserId    int64
+	// Contents hashes the rows of every table in stateSnapshotTables, so
+	// edits that leave the counts and ids alone still reject the snapshot.
+	Contents string
+}
+
+// stateSnapshotTables are the tables whose rows make up the snapshot.
+var stateSnapshotTables = []string{"systems", "sites", "talkgroups", "talkgroupGroups", "units", "groups", "tags", "users"}
+
+// StateSnapshot is the gob payload persisted to disk.
+type StateSnapshot struct {
+	CreatedAt     int64
+	ServerVersion string
+	Fingerprint   StateFingerprint
+	Systems       []*System
+	Groups        []*Group
+	Tags          []*Tag
+	Users         []*User
+}
+
+// StateSnapshotter periodically writes the in-memory state to disk.
+type StateSnapshotter struct {
+	controller *Controller
+	interval   time.Duration
+	mutex      sync.Mutex
+	stop       chan struct{}
+	// loadedFromSnapshot is true when the current process booted from the snapshot
+	// and has not yet reconciled with the database.
+	loadedFromSnapshot bool
+}
+
+func NewStateSnapshotter(controller *Controller) *StateSnapshotter {
+	interval := defaultStateSnapshotInterval
+	if controller.Config.StateSnapshotMinutes > 0 {
+		interval = time.Duration(controller.Config.StateSnapshotMinutes) * time.Minute
+	}
+	return &StateSnapshotter{
+		controller: controller,
+		interval:   interval,
+	}
+}
+
+func (snapshotter *StateSnapshotter) path() string {
+	return snapshotter.controller.Config.GetPath(stateSnapshotFile)
+}
+
+// signingKey returns the per-install HMAC key, generating it on first use.
+// The key never leaves the base directory and is not stored in the database,
+// so a snapshot copied to another install is rejected.
+func (snapshotter *StateSnapshotter) signingKey() ([]byte, error) {
+	keyPath := snapshotter.controller.Config.GetPath(stateSnapshotKeyFile)
+
+	if b, err := os.ReadFile(keyPath); err == nil && len(b) == 32 {
+		return b, nil
+	}
+
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(keyPath, key, 0600); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// Fingerprint reads the row counts, max ids and content hash from the
+// database.
+func (snapshotter *StateSnapshotter) Fingerprint() (StateFingerprint, error) {
+	var fp StateFingerprint
+	db := snapshotter.controller.Database
+
+	queries := []struct {
+		query string
+		dest  []any
+	}{
+		{`SELECT COUNT(*), COALESCE(MAX("systemId"), 0) FROM "systems"`, []any{&fp.Systems, &fp.MaxSystemId}},
+		{`SELECT COUNT(*), COALESCE(MAX("talkgroupId"), 0) FROM "talkgroups"`, []any{&fp.Talkgroups, &fp.MaxTalkgroup}},
+		{`SELECT COUNT(*) FROM "groups"`, []any{&fp.Groups}},
+		{`SELECT COUNT(*) FROM "tags"`, []any{&fp.Tags}},
+		{`SELECT COUNT(*), COALESCE(MAX("userId"), 0) FROM "users"`, []any{&fp.Users, &fp.MaxUserId}},
+	}
+
+	for _, q := range queries {
+		if err := db.Sql.QueryRow(q.query).Scan(q.dest...); err != nil {
+			return fp, fmt.Errorf("%v in %s", err, q.query)
+		}
+	}
+
+	contents := sha256.New()
+	for _, table := range stateSnapshotTables {
+		query := fmt.Sprintf(`SELECT COALESCE(md5(string_agg(t::text, E'\n' ORDER BY t::text)), '') FROM %q t`, table)
+		var sum string
+		if err := db.Sql.QueryRow(query).Scan(&sum); err != nil {
+			return fp, fmt.Errorf("%v in %s", err, query)
+		}
+		fmt.Fprintf(contents, "%s:%s\n", table, sum)
+	}
+	fp.Contents = hex.EncodeToString(contents.Sum(nil))
+
+	return fp, nil
+}
+
+// encodeStateSnapshot serializes and signs a snapshot.
+func encodeStateSnapshot(snapshot *StateSnapshot, key []byte) ([]byte, error) {
+	var payload bytes.Buffer
+
+	zw := gzip.NewWriter(&payload)
+	if err := gob.NewEncoder(zw).Encode(snapshot); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte{stateSnapshotFormatVersion})
+	mac.Write(payload.Bytes())
+
+	var out bytes.Buffer
+	out.WriteString(stateSnapshotMagic)
+	out.WriteByte(stateSnapshotFormatVersion)
+	out.Write(mac.Sum(nil))
+	out.Write(payload.Bytes())
+
+	return out.Bytes(), nil
+}
+
+// decodeStateSnapshot verifies the signature and version, then decodes the payload.
+func decodeStateSnapshot(b []byte, key []byte) (*StateSnapshot, error) {
+	headerLen := len(stateSnapshotMagic) + 1 + sha256.Size
+	if len(b) < headerLen || string(b[:len(stateSnapshotMagic)]) != stateSnapshotMagic {
+		return nil, errStateSnapshotBadMagic
+	}
+
+	version := b[len(stateSnapshotMagic)]
+	if version != stateSnapshotFormatVersion {
+		return nil, errStateSnapshotBadVersion
+	}
+
+	sig := b[len(stateSnapshotMagic)+1 : headerLen]
+	payload := b[headerLen:]
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte{version})
+	mac.Write(payload)
+	if !hmac.Equal(sig, mac.Sum(nil)) {
+		return nil, errStateSnapshotBadSig
+	}
+
+	zr, err := gzip.NewReader(bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+
+	snapshot := &StateSnapshot{}
+	if err := gob.NewDecoder(zr).Decode(snapshot); err != nil {
+		return nil, err
+	}
+
+	return snapshot, nil
+}
+
+// Write captures the current in-memory state and atomically replaces the snapshot file.
+func (snapshotter *StateSnapshotter) Write() error {
+	snapshotter.mutex.Lock()
+	defer snapshotter.mutex.Unlock()
+
+	controller := snapshotter.controller
+
+	key, err := snapshotter.signingKey()
+	if err != nil {
+		return fmt.Errorf("state snapshot key: %v", err)
+	}
+
+	fp, err := snapshotter.Fingerprint()
+	if err != nil {
+		return fmt.Errorf("state snapshot fingerprint: %v", err)
+	}
+
+	snapshot := &StateSnapshot{
+		CreatedAt:     time.Now().UnixMilli(),
+		ServerVersion: Version,
+		Fingerprint:   fp,
+	}
+
+	controller.Groups.mutex.RLock()
+	snapshot.Groups = append(snapshot.Groups, controller.Groups.List...)
+	controller.Groups.mutex.RUnlock()
+
+	controller.Tags.mutex.RLock()
+	snapshot.Tags = append(snapshot.Tags, controller.Tags.List...)
+	controller.Tags.mutex.RUnlock()
+
+	snapshot.Users = controller.Users.GetAllUsers()
+
+	// Hold the systems lock for the whole encode so talkgroup lists are not
+	// replaced mid-encode by a concurrent config save.
+	controller.Systems.mutex.RLock()
+	snapshot.Systems = append(snapshot.Systems, controller.Systems.List...)
+	b, err := encodeStateSnapshot(snapshot, key)
+	controller.Systems.mutex.RUnlock()
+	if err != nil {
+		return fmt.Errorf("state snapshot encode: %v", err)
+	}
+
+	tmp := snapshotter.path() + ".tmp"
+	if err := os.WriteFile(tmp, b, 0600); err != nil {
+		return fmt.Errorf("state snapshot write: %v", err)
+	}
+	if err := os.Rename(tmp, snapshotter.path()); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("state snapshot rename: %v", err)
+	}
+
+	return nil
+}
+
+// Load validates the snapshot file against the database fingerprint and, when
+// accepted, installs systems, groups, tags and users into memory.
+func (snapshotter *StateSnapshotter) Load() error {
+	controller := snapshotter.controller
+
+	b, err := os.ReadFile(snapshotter.path())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return errStateSnapshotMissing
+		}
+		return err
+	}
+
+	key, err := snapshotter.signingKey()
+	if err != nil {
+		return err
+	}
+
+	snapshot, err := decodeStateSnapshot(b, key)
+	if err != nil {
+		return err
+	}
+
+	if time.Since(time.UnixMilli(snapshot.CreatedAt)) > stateSnapshotMaxAge {
+		return errStateSnapshotStale
+	}
+
+	fp, err := snapshotter.Fingerprint()
+	if err != nil {
+		return err
+	}
+	if fp != snapshot.Fingerprint {
+		return errStateSnapshotFingerprint
+	}
+
+	controller.Systems.mutex.Lock()
+	controller.Systems.List = snapshot.Systems
+	for _, system := range controller.Systems.List {
+		if system.Sites == nil {
+			system.Sites = NewSites()
+		}
+		if system.Talkgroups == nil {
+			system.Talkgroups = NewTalkgroups()
+		}
+		if system.Units == nil {
+			system.Units = NewUnits()
+		}
+	}
+	controller.Systems.mutex.Unlock()
+
+	controller.Groups.mutex.Lock()
+	controller.Groups.List = snapshot.Groups
+	controller.Groups.mutex.Unlock()
+
+	controller.Tags.mutex.Lock()
+	controller.Tags.List = snapshot.Tags
+	controller.Tags.mutex.Unlock()
+
+	controller.Users.replaceAll(snapshot.Users)
+
+	snapshotter.loadedFromSnapshot = true
+
+	stateSnapshotLog.Info(fmt.Sprintf("startup: state snapshot accepted (written %s by v%s)", time.UnixMilli(snapshot.CreatedAt).Format(time.RFC3339), snapshot.ServerVersion))
+
+	return nil
+}
+
+// Reconcile re-reads the snapshot-covered collections from the database so any
+// drift since the snapshot was written is corrected, then refreshes the file.
+func (snapshotter *StateSnapshotter) Reconcile() {
+	controller := snapshotter.controller
+	start := time.Now()
+
+	reads := []struct {
+		name string
+		fn   func() error
+	}{
+		{"systems", func() error { return controller.Systems.Read(controller.Database) }},
+		{"groups", func() error { return controller.Groups.Read(controller.Database) }},
+		{"tags", func() error { return controller.Tags.Read(controller.Database) }},
+		{"users", func() error { return controller.Users.Read(controller.Database) }},
+	}
+
+	for _, r := range reads {
+		if err := r.fn(); err != nil {
+			controller.Logs.LogEvent(LogLevelError, fmt.Sprintf("state snapshot: reconcile %s failed: %v", r.name, err))
+			return
+		}
+	}
+
+	snapshotter.loadedFromSnapshot = false
+	controller.EmitConfig()
+
+	controller.Logs.LogEvent(LogLevelInfo, fmt.Sprintf("state snapshot: reconciled with database in %s", time.Since(start).Round(time.Millisecond)))
+
+	if err := snapshotter.Write(); err != nil {
+		controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("state snapshot: %v", err))
+	}
+}
+
+// Start begins periodic snapshot writes.
+func (snapshotter *StateSnapshotter) Start() {
+	snapshotter.stop = make(chan struct{})
+
+	go func() {
+		ticker := time.NewTicker(snapshotter.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				if err := snapshotter.Write(); err != nil {
+					snapshotter.controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("state snapshot: %v", err))
+				}
+			case <-snapshotter.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop halts periodic writes and flushes a final snapshot so the next boot is warm.
+func (snapshotter *StateSnapshotter) Stop() {
+	if snapshotter.stop == nil {
+		return
+	}
+	close(snapshotter.stop)
+	snapshotter.stop = nil
+
+	if snapshotter.loadedFromSnapshot {
+		return
+	}
+	if err := snapshotter.Write(); err != nil {
+		stateSnapshotLog.Error(fmt.Sprintf("state snapshot: %v", err))
+	}
+}
+
+// replaceAll installs a full user list (e.g. from a state snapshot) and
+// rebuilds the derived pin and group-admin indexes the same way Read does.
+func (users *Users) replaceAll(list []*User) {
+	users.mutex.Lock()
+	defer users.mutex.Unlock()
+
+	users.users = make(map[uint64]*User, len(list))
+	users.pins = make(map[string]*User)
+	users.groupAdmins = make(map[uint64]*User)
+
+	for _, user := range list {
+		if user == nil {
+			continue
+		}
+		user.ensurePinsLoaded()
+		user.loadSystemScopes()
+		user.loadDelayMaps()
+
+		users.users[user.Id] = user
+		if user.Pin != "" {
+			user.Pin = strings.TrimSpace(user.Pin)
+			users.pins[user.Pin] = user
+		}
+		if user.IsGroupAdmin && user.UserGroupId > 0 {
+			users.groupAdmins[user.UserGroupId] = user
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"strings"
+	"testing"
+)
+
+func testStateSnapshot() *StateSnapshot {
+	system := NewSystem()
+	system.Id = 7
+	system.Label = "County P25"
+	system.SystemRef = 42
+
+	talkgroup := NewTalkgroup()
+	talkgroup.Id = 100
+	talkgroup.Label = "FD DISP"
+	talkgroup.TalkgroupRef = 1201
+	talkgroup.ToneSets = []ToneSet{{Id: "a", Label: "Station 1", ATone: &ToneSpec{Frequency: 853.2}}}
+	system.Talkgroups.List = append(system.Talkgroups.List, talkgroup)
+
+	return &StateSnapshot{
+		CreatedAt:     1700000000000,
+		ServerVersion: "test",
+		Fingerprint:   StateFingerprint{Systems: 1, MaxSystemId: 7, Talkgroups: 1, MaxTalkgroup: 100},
+		Systems:       []*System{system},
+		Groups:        []*Group{{Id: 1, Label: "Fire"}},
+		Tags:          []*Tag{{Id: 2, Label: "Dispatch", Color: "red"}},
+		Users:         []*User{{Id: 3, Email: "a@example.com", Pin: "1234"}},
+	}
+}
+
+func TestStateSnapshotRoundTrip(t *testing.T) {
+	key := bytes.Repeat([]byte{1}, 32)
+
+	b, err := encodeStateSnapshot(testStateSnapshot(), key)
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+
+	got, err := decodeStateSnapshot(b, key)
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+
+	if len(got.Systems) != 1 || got.Systems[0].Label != "County P25" {
+		t.Fatalf("systems not restored: %+v", got.Systems)
+	}
+	tgs := got.Systems[0].Talkgroups.List
+	if len(tgs) != 1 || tgs[0].TalkgroupRef != 1201 || len(tgs[0].ToneSets) != 1 || tgs[0].ToneSets[0].ATone.Frequency != 853.2 {
+		t.Fatalf("talkgroups not restored: %+v", tgs)
+	}
+	if got.Fingerprint.MaxTalkgroup != 100 {
+		t.Fatalf("fingerprint not restored: %+v", got.Fingerprint)
+	}
+	if len(got.Users) != 1 || got.Users[0].Pin != "1234" {
+		t.Fatalf("users not restored: %+v", got.Users)
+	}
+}
+
+func TestStateSnapshotRejectsTamperingAndForeignKey(t *testing.T) {
+	key := bytes.Repeat([]byte{1}, 32)
+
+	b, err := encodeStateSnapshot(testStateSnapshot(), key)
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+
+	if _, err := decodeStateSnapshot(b, bytes.Repeat([]byte{2}, 32)); err != errStateSnapshotBadSig {
+		t.Fatalf("foreign key: expected signature error, got %v", err)
+	}
+
+	tampered := append([]byte(nil), b...)
+	tampered[len(tampered)-1] ^= 0xff
+	if _, err := decodeStateSnapshot(tampered, key); err != errStateSnapshotBadSig {
+		t.Fatalf("tampered payload: expected signature error, got %v", err)
+	}
+
+	versioned := append([]byte(nil), b...)
+	versioned[len(stateSnapshotMagic)] = stateSnapshotFormatVersion + 1
+	if _, err := decodeStateSnapshot(versioned, key); err != errStateSnapshotBadVersion {
+		t.Fatalf("future version: expected version error, got %v", err)
+	}
+
+	if _, err := decodeStateSnapshot([]byte("garbage"), key); err != errStateSnapshotBadMagic {
+		t.Fatalf("garbage: expected magic error, got %v", err)
+	}
+}
+
+func TestStateFingerprintHashesContents(t *testing.T) {
+	label := "FD DISP"
+	controller := &Controller{Config: &Config{}}
+	controller.Database = newFakeDatabase(t, func(query string, args []driver.Value) ([][]driver.Value, int64, error) {
+		switch {
+		case strings.Contains(query, "md5"):
+			if strings.Contains(query, `"talkgroups"`) {
+				return [][]driver.Value{{label}}, 0, nil
+			}
+			return [][]driver.Value{{""}}, 0, nil
+		case strings.Contains(query, "MAX"):
+			return [][]driver.Value{{int64(1), int64(7)}}, 0, nil
+		default:
+			return [][]driver.Value{{int64(1)}}, 0, nil
+		}
+	})
+	snapshotter := NewStateSnapshotter(controller)
+
+	before, err := snapshotter.Fingerprint()
+	if err != nil {
+		t.Fatalf("fingerprint: %v", err)
+	}
+	label = "FD TAC"
+	after, err := snapshotter.Fingerprint()
+	if err != nil {
+		t.Fatalf("fingerprint: %v", err)
+	}
+
+	if before.Contents == "" || before == after {
+		t.Fatalf("an edited talkgroup should change the fingerprint: %+v", after)
+	}
+}
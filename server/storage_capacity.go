@@ -0,0 +1,367 @@
+// Copyright (C) 2026 Thinline Dynamic Solutions
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/shirou/gopsutil/v4/disk"
+)
+
+// StorageCapacityConfig tunes the storage forecast. Zero values keep the
+// defaults.
+type StorageCapacityConfig struct {
+	Disabled     bool `json:"disabled,omitempty"`
+	WarningDays  uint `json:"warningDays,omitempty"`  // default 30
+	CriticalDays uint `json:"criticalDays,omitempty"` // default 7
+	RepeatHours  uint `json:"repeatHours,omitempty"`  // default 24
+}
+
+const (
+	// storageSampleRetentionDays of hourly samples are kept for the API.
+	storageSampleRetentionDays = 30
+	// storageForecastDays of samples feed the growth rate.
+	storageForecastDays = 7
+	// storageForecastMinSpan is the shortest sampling span worth a forecast.
+	storageForecastMinSpan = 6 * time.Hour
+	// storageReserve is the share of the disk the recommendation keeps free.
+	storageReserve = 0.1
+)
+
+// StorageSample is one hourly measurement of the storage in use.
+type StorageSample struct {
+	Timestamp      int64 `json:"timestamp"`
+	DatabaseBytes  int64 `json:"databaseBytes"`
+	AudioBytes     int64 `json:"audioBytes"` // calls and shared audio tables
+	DiskFreeBytes  int64 `json:"diskFreeBytes"`
+	DiskTotalBytes int64 `json:"diskTotalBytes"`
+	OldestCall     int64 `json:"oldestCall"`
+}
+
+// StorageForecast is the capacity API response.
+type StorageForecast struct {
+	Current                 StorageSample   `json:"current"`
+	DatabaseGrowthPerDay    float64         `json:"databaseGrowthPerDay"` // bytes
+	AudioGrowthPerDay       float64         `json:"audioGrowthPerDay"`    // bytes
+	DiskGrowthPerDay        float64         `json:"diskGrowthPerDay"`     // bytes used per day
+	DaysUntilFull           float64         `json:"daysUntilFull"`        // -1 when not filling up
+	Severity                string          `json:"severity"`             // ok, warning or critical
+	PruneDays               uint            `json:"pruneDays"`
+	RecommendedPruneDays    uint            `json:"recommendedPruneDays,omitempty"`
+	Recommendation          string          `json:"recommendation,omitempty"`
+	Samples                 []StorageSample `json:"samples"`
+	ForecastSpanHours       float64         `json:"forecastSpanHours"`
+	InsufficientSampleRange bool            `json:"insufficientSampleRange,omitempty"`
+}
+
+type StorageCapacity struct {
+	controller *Controller
+	mutex      sync.Mutex
+	alertedAt  time.Time
+	alerted    string
+}
+
+func NewStorageCapacity(controller *Controller) *StorageCapacity {
+	return &StorageCapacity{controller: controller}
+}
+
+func (capacity *StorageCapacity) config() StorageCapacityConfig {
+	config := capacity.controller.Options.StorageCapacityConfig
+	if config.WarningDays == 0 {
+		config.WarningDays = 30
+	}
+	if config.CriticalDays == 0 {
+		config.CriticalDays = 7
+	}
+	if config.RepeatHours == 0 {
+		config.RepeatHours = 24
+	}
+	return config
+}
+
+// Measure takes a sample of the current storage without storing it.
+func (capacity *StorageCapacity) Measure() (StorageSample, error) {
+	controller := capacity.controller
+	sample := StorageSample{Timestamp: time.Now().UnixMilli()}
+
+	db := controller.Database.Sql
+	if controller.Database.Config.DbType == DbTypePostgresql {
+		if err := db.QueryRow(`SELECT pg_database_size(current_database())`).Scan(&sample.DatabaseBytes); err != nil {
+			return sample, fmt.Errorf("database size: %v", err)
+		}
+		if err := db.QueryRow(`SELECT pg_total_relation_size('"calls"') + COALESCE(pg_total_relation_size(to_regclass('"audioBlobs"')), 0)`).Scan(&sample.AudioBytes); err != nil {
+			return sample, fmt.Errorf("audio table size: %v", err)
+		}
+	}
+	if err := db.QueryRow(`SELECT COALESCE(MIN("timestamp"), 0) FROM "calls"`).Scan(&sample.OldestCall); err != nil {
+		return sample, fmt.Errorf("oldest call: %v", err)
+	}
+
+	if controller.Config != nil && controller.Config.BaseDir != "" {
+		if usage, err := disk.Usage(controller.Config.BaseDir); err == nil && usage != nil {
+			sample.DiskFreeBytes = int64(usage.Free)
+			sample.DiskTotalBytes = int64(usage.Total)
+		}
+	}
+
+	return sample, nil
+}
+
+// Samples returns the stored samples since the time, oldest first.
+func (capacity *StorageCapacity) Samples(since time.Time) ([]StorageSample, error) {
+	rows, err := capacity.controller.Database.Sql.Query(`SELECT "timestamp", "databaseBytes", "audioBytes", "diskFreeBytes", "diskTotalBytes", "oldestCall" FROM "storageSamples" WHERE "timestamp" >= $1 ORDER BY "timestamp"`, since.UnixMilli())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	samples := []StorageSample{}
+	for rows.Next() {
+		var sample StorageSample
+		if err := rows.Scan(&sample.Timestamp, &sample.DatabaseBytes, &sample.AudioBytes, &sample.DiskFreeBytes, &sample.DiskTotalBytes, &sample.OldestCall); err != nil {
+			return nil, err
+		}
+		samples = append(samples, sample)
+	}
+	return samples, rows.Err()
+}
+
+// Forecast projects the samples of the last storageForecastDays onto the
+// current measurement.
+func (capacity *StorageCapacity) Forecast() (*StorageForecast, error) {
+	current, err := capacity.Measure()
+	if err != nil {
+		return nil, err
+	}
+	samples, err := capacity.Samples(time.Now().Add(-24 * time.Hour * storageForecastDays))
+	if err != nil {
+		return nil, err
+	}
+	return forecastStorage(current, samples, capacity.controller.Options.PruneDays, capacity.config()), nil
+}
+
+// forecastStorage fits the growth rates to the samples and current
+// measurement and grades the days left until the disk is full.
+func forecastStorage(current StorageSample, samples []StorageSample, pruneDays uint, config StorageCapacityConfig) *StorageForecast {
+	forecast := &StorageForecast{
+		Current:       current,
+		Samples:       samples,
+		PruneDays:     pruneDays,
+		DaysUntilFull: -1,
+		Severity:      "ok",
+	}
+
+	points := append(append([]StorageSample{}, samples...), current)
+	span := time.Duration(current.Timestamp-points[0].Timestamp) * time.Millisecond
+	forecast.ForecastSpanHours = math.Round(span.Hours()*10) / 10
+	if span < storageForecastMinSpan {
+		forecast.InsufficientSampleRange = true
+		return forecast
+	}
+
+	forecast.DatabaseGrowthPerDay = storageSlopePerDay(points, func(s StorageSample) int64 { return s.DatabaseBytes })
+	forecast.AudioGrowthPerDay = storageSlopePerDay(points, func(s StorageSample) int64 { return s.AudioBytes })
+	if current.DiskTotalBytes > 0 {
+		forecast.DiskGrowthPerDay = -storageSlopePerDay(points, func(s StorageSample) int64 { return s.DiskFreeBytes })
+	}
+
+	if forecast.DiskGrowthPerDay <= 0 || current.DiskTotalBytes == 0 {
+		return forecast
+	}
+
+	days := float64(current.DiskFreeBytes) / forecast.DiskGrowthPerDay
+	forecast.DaysUntilFull = math.Round(days*10) / 10
+
+	switch {
+	case days < float64(config.CriticalDays):
+		forecast.Severity = "critical"
+	case days < float64(config.WarningDays):
+		forecast.Severity = "warning"
+	default:
+		return forecast
+	}
+
+	// At the current growth, the calls held so far plus the usable free
+	// space last this many days
+	usable := float64(current.DiskFreeBytes) - storageReserve*float64(current.DiskTotalBytes)
+	if usable < 0 {
+		usable = 0
+	}
+	var heldDays float64
+	if current.OldestCall > 0 {
+		heldDays = float64(current.Timestamp-current.OldestCall) / float64(24*time.Hour/time.Millisecond)
+	}
+	fit := uint(heldDays + usable/forecast.DiskGrowthPerDay)
+	if fit < 1 {
+		fit = 1
+	}
+
+	if pruneDays == 0 || fit < pruneDays {
+		forecast.RecommendedPruneDays = fit
+		if pruneDays == 0 {
+			forecast.Recommendation = fmt.Sprintf("Calls are kept forever. Set pruneDays to %d or less, or add retention policies for the busiest systems.", fit)
+		} else {
+			forecast.Recommendation = fmt.Sprintf("Lower pruneDays from %d to %d or less, or add shorter retention policies for the busiest systems.", pruneDays, fit)
+		}
+	} else {
+		forecast.Recommendation = "Retention already fits the disk once old calls are purged. Add disk space or shorter retention policies if the disk fills before then."
+	}
+
+	return forecast
+}
+
+// storageSlopePerDay is the least-squares growth of the value in bytes per
+// day.
+func storageSlopePerDay(samples []StorageSample, value func(StorageSample) int64) float64 {
+	if len(samples) < 2 {
+		return 0
+	}
+	origin := samples[0].Timestamp
+	var sumX, sumY, sumXY, sumXX float64
+	for _, sample := range samples {
+		x := float64(sample.Timestamp-origin) / float64(24*time.Hour/time.Millisecond)
+		y := float64(value(sample))
+		sumX += x
+		sumY += y
+		sumXY += x * y
+		sumXX += x * x
+	}
+	n := float64(len(samples))
+	denominator := n*sumXX - sumX*sumX
+	if denominator == 0 {
+		return 0
+	}
+	return (n*sumXY - sumX*sumY) / denominator
+}
+
+// RunHourly is called by the scheduler. It stores a sample, drops old ones
+// and raises a storage_capacity alert when the disk is forecast to fill up.
+func (capacity *StorageCapacity) RunHourly() {
+	controller := capacity.controller
+	config := capacity.config()
+	if config.Disabled {
+		return
+	}
+
+	sample, err := capacity.Measure()
+	if err != nil {
+		controller.Logs.LogEvent(LogLevelError, fmt.Sprintf("storage capacity: %v", err))
+		return
+	}
+	if _, err := controller.Database.Sql.Exec(`INSERT INTO "storageSamples" ("timestamp", "databaseBytes", "audioBytes", "diskFreeBytes", "diskTotalBytes", "oldestCall") VALUES ($1, $2, $3, $4, $5, $6)`, sample.Timestamp, sample.DatabaseBytes, sample.AudioBytes, sample.DiskFreeBytes, sample.DiskTotalBytes, sample.OldestCall); err != nil {
+		controller.Logs.LogEvent(LogLevelError, fmt.Sprintf("storage capacity: store sample: %v", err))
+	}
+	cutoff := time.Now().Add(-24 * time.Hour * storageSampleRetentionDays).UnixMilli()
+	if _, err := controller.Database.Sql.Exec(`DELETE FROM "storageSamples" WHERE "timestamp" < $1`, cutoff); err != nil {
+		controller.Logs.LogEvent(LogLevelError, fmt.Sprintf("storage capacity: prune samples: %v", err))
+	}
+
+	samples, err := capacity.Samples(time.Now().Add(-24 * time.Hour * storageForecastDays))
+	if err != nil {
+		controller.Logs.LogEvent(LogLevelError, fmt.Sprintf("storage capacity: %v", err))
+		return
+	}
+	forecast := forecastStorage(sample, samples, controller.Options.PruneDays, config)
+
+	capacity.mutex.Lock()
+	defer capacity.mutex.Unlock()
+
+	if forecast.Severity == "ok" {
+		if capacity.alerted != "" {
+			controller.IncidentRouting.Resolve("storage_capacity", 0)
+		}
+		capacity.alerted = ""
+		return
+	}
+
+	// Alert again after the repeat interval, or straight away when it gets worse
+	repeat := time.Duration(config.RepeatHours) * time.Hour
+	if forecast.Severity == capacity.alerted && time.Since(capacity.alertedAt) < repeat {
+		return
+	}
+	capacity.alerted = forecast.Severity
+	capacity.alertedAt = time.Now()
+
+	message := fmt.Sprintf("The data disk is forecast to be full in %.1f days (%s free, growing %s per day).", forecast.DaysUntilFull, formatBytes(int(sample.DiskFreeBytes)), formatBytes(int(forecast.DiskGrowthPerDay)))
+	if forecast.Recommendation != "" {
+		message += " " + forecast.Recommendation
+	}
+
+	controller.CreateSystemAlert(
+		"storage_capacity",
+		forecast.Severity,
+		"Storage Running Out",
+		message,
+		&SystemAlertData{Value: forecast.DaysUntilFull},
+		0, // System-generated
+	)
+}
+
+// StorageCapacityHandler returns the current storage use, growth rates and
+// forecast with the hourly samples behind it. ?days= (up to
+// storageSampleRetentionDays) returns a longer history of samples.
+func (admin *Admin) StorageCapacityHandler(w http.ResponseWriter, r *http.Request) {
+	t := admin.GetAuthorization(r)
+	if !admin.ValidateToken(t) {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	writeError := func(status int, message string) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(map[string]string{"error": message})
+	}
+
+	days := storageForecastDays
+	if s := r.URL.Query().Get("days"); s != "" {
+		v, err := strconv.Atoi(s)
+		if err != nil || v < 1 {
+			writeError(http.StatusBadRequest, "days must be a positive number")
+			return
+		}
+		if v > storageSampleRetentionDays {
+			v = storageSampleRetentionDays
+		}
+		days = v
+	}
+
+	forecast, err := admin.Controller.StorageCapacity.Forecast()
+	if err != nil {
+		writeError(http.StatusInternalServerError, err.Error())
+		return
+	}
+	if days != storageForecastDays {
+		if forecast.Samples, err = admin.Controller.StorageCapacity.Samples(time.Now().Add(-24 * time.Hour * time.Duration(days))); err != nil {
+			writeError(http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(forecast)
+}
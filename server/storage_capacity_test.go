@@ -0,0 +1,83 @@
+package main
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testGB = int64(1 << 30)
+
+// storageTestSamples returns hourly samples over the days, with the disk
+// filling up by perDay.
+func storageTestSamples(start time.Time, days int, free int64, perDay int64) []StorageSample {
+	var samples []StorageSample
+	for h := 0; h <= days*24; h++ {
+		samples = append(samples, StorageSample{
+			Timestamp:      start.Add(time.Duration(h) * time.Hour).UnixMilli(),
+			DatabaseBytes:  10*testGB + perDay*int64(h)/24,
+			AudioBytes:     8*testGB + perDay*int64(h)/24,
+			DiskFreeBytes:  free - perDay*int64(h)/24,
+			DiskTotalBytes: 100 * testGB,
+			OldestCall:     start.Add(-30 * 24 * time.Hour).UnixMilli(),
+		})
+	}
+	return samples
+}
+
+func TestStorageSlopePerDay(t *testing.T) {
+	samples := storageTestSamples(time.Unix(1700000000, 0), 3, 50*testGB, 2*testGB)
+	slope := storageSlopePerDay(samples, func(s StorageSample) int64 { return s.DiskFreeBytes })
+	if math.Abs(slope+float64(2*testGB)) > float64(testGB)/100 {
+		t.Fatalf("got slope %.0f, want %d", slope, -2*testGB)
+	}
+	if slope := storageSlopePerDay(samples[:1], func(s StorageSample) int64 { return s.DiskFreeBytes }); slope != 0 {
+		t.Fatalf("single sample gave slope %f", slope)
+	}
+}
+
+func TestForecastStorage(t *testing.T) {
+	config := StorageCapacityConfig{WarningDays: 30, CriticalDays: 7}
+	start := time.Unix(1700000000, 0)
+
+	// 20 GB free three days ago, filling 4 GB a day: 2 days left
+	samples := storageTestSamples(start, 3, 20*testGB, 4*testGB)
+	current, history := samples[len(samples)-1], samples[:len(samples)-1]
+	forecast := forecastStorage(current, history, 0, config)
+	if forecast.Severity != "critical" {
+		t.Fatalf("got severity %q with %.1f days left, want critical", forecast.Severity, forecast.DaysUntilFull)
+	}
+	if math.Abs(forecast.DaysUntilFull-2) > 0.2 {
+		t.Fatalf("got %.1f days until full, want 2", forecast.DaysUntilFull)
+	}
+	// 8 GB free is inside the 10 GB reserve, so only the 33 days held fit
+	if forecast.RecommendedPruneDays != 33 || !strings.Contains(forecast.Recommendation, "kept forever") {
+		t.Fatalf("got recommendation %d %q", forecast.RecommendedPruneDays, forecast.Recommendation)
+	}
+
+	// 90 GB free, filling 4 GB a day: 22 days left, and 33 days held plus
+	// 80 GB usable fit 53 days
+	samples = storageTestSamples(start, 3, 90*testGB+12*testGB, 4*testGB)
+	current, history = samples[len(samples)-1], samples[:len(samples)-1]
+	forecast = forecastStorage(current, history, 90, config)
+	if forecast.Severity != "warning" {
+		t.Fatalf("got severity %q with %.1f days left, want warning", forecast.Severity, forecast.DaysUntilFull)
+	}
+	if forecast.RecommendedPruneDays < 52 || forecast.RecommendedPruneDays > 53 || !strings.Contains(forecast.Recommendation, "from 90 to") {
+		t.Fatalf("got recommendation %d %q", forecast.RecommendedPruneDays, forecast.Recommendation)
+	}
+
+	// Shrinking use never fills the disk
+	samples = storageTestSamples(start, 3, 20*testGB, -testGB)
+	forecast = forecastStorage(samples[len(samples)-1], samples[:len(samples)-1], 0, config)
+	if forecast.Severity != "ok" || forecast.DaysUntilFull != -1 {
+		t.Fatalf("got %q and %.1f days for shrinking use", forecast.Severity, forecast.DaysUntilFull)
+	}
+
+	// Too short a history for a forecast
+	forecast = forecastStorage(samples[2], samples[:2], 0, config)
+	if !forecast.InsufficientSampleRange || forecast.Severity != "ok" {
+		t.Fatalf("forecast from 2 hours of samples: %+v", forecast)
+	}
+}
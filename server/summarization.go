@@ -0,0 +1,256 @@
+// Copyright (C) 2026 Thinline Dynamic Solutions
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const summarizationLocalDefaultURL = "http://localhost:11434"
+
+// SummarizationConfig adds a one-line incident summary and the address,
+// incident type and units extracted from each voiced transcript. The "openai"
+// provider uses the OpenAI integration credentials; "local" talks to any
+// OpenAI-compatible chat endpoint such as Ollama, LM Studio or vLLM.
+type SummarizationConfig struct {
+	Enabled  bool   `json:"enabled"`
+	Provider string `json:"provider"` // "openai" (default) or "local"
+	URL      string `json:"url"`      // local endpoint (default http://localhost:11434)
+	Model    string `json:"model"`    // local model, e.g. "llama3.1:8b"
+	APIKey   string `json:"apiKey"`   // optional bearer token for the local endpoint
+}
+
+// CallSummaryFields are the details the summarizer extracts from a transcript.
+type CallSummaryFields struct {
+	Address      string   `json:"address,omitempty"`
+	IncidentType string   `json:"incidentType,omitempty"`
+	Units        []string `json:"units,omitempty"`
+}
+
+// IsEmpty reports whether nothing was extracted.
+func (fields *CallSummaryFields) IsEmpty() bool {
+	return fields == nil || (fields.Address == "" && fields.IncidentType == "" && len(fields.Units) == 0)
+}
+
+// CallSummary is the parsed response of a summarization provider.
+type CallSummary struct {
+	Summary string
+	Fields  CallSummaryFields
+}
+
+// SummarizationProvider summarizes a call transcript.
+type SummarizationProvider interface {
+	Summarize(prompt string, transcript string) (string, error)
+	GetName() string
+}
+
+const summarizationPrompt = `You summarize public safety radio transcripts for alert notifications.
+Write one short line describing the incident, using only what the transcript says.
+Extract the street address or location, the incident type and the unit identifiers dispatched or responding.
+Leave a field empty when the transcript does not say it. Never guess.
+Respond with JSON: {"summary": "...", "address": "...", "incidentType": "...", "units": ["..."]}`
+
+var summarizationFencePattern = regexp.MustCompile("(?s)^```[a-zA-Z]*\\s*(.*?)\\s*```$")
+
+// parseCallSummary reads the JSON a provider returned. Local models often wrap
+// the JSON in a code fence, which is removed first.
+func parseCallSummary(content string) (*CallSummary, error) {
+	content = strings.TrimSpace(content)
+	if m := summarizationFencePattern.FindStringSubmatch(content); m != nil {
+		content = m[1]
+	}
+
+	var response struct {
+		Summary      string `json:"summary"`
+		Address      string `json:"address"`
+		IncidentType string `json:"incidentType"`
+		Units        []any  `json:"units"`
+	}
+	if err := json.Unmarshal([]byte(content), &response); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %v", err)
+	}
+
+	// Notifications show a single line
+	summary := strings.Join(strings.Fields(response.Summary), " ")
+	if summary == "" {
+		return nil, fmt.Errorf("empty summary")
+	}
+
+	units := []string{}
+	for _, unit := range response.Units {
+		if unit == nil {
+			continue
+		}
+		// Some models return unit numbers as numbers
+		if s := strings.TrimSpace(fmt.Sprint(unit)); s != "" {
+			units = append(units, strings.ToUpper(s))
+		}
+	}
+
+	return &CallSummary{
+		Summary: summary,
+		Fields: CallSummaryFields{
+			Address:      strings.ToUpper(strings.TrimSpace(response.Address)),
+			IncidentType: strings.ToUpper(strings.TrimSpace(response.IncidentType)),
+			Units:        units,
+		},
+	}, nil
+}
+
+// newSummarizationProvider returns the provider selected in config.
+func (controller *Controller) newSummarizationProvider(config SummarizationConfig) (SummarizationProvider, error) {
+	switch strings.ToLower(config.Provider) {
+	case "", "openai":
+		if strings.TrimSpace(controller.Options.OpenAIIntegration.APIKey) == "" {
+			return nil, fmt.Errorf("openai api key not configured")
+		}
+		return &openAISummarization{controller: controller}, nil
+	case "local":
+		if strings.TrimSpace(config.Model) == "" {
+			return nil, fmt.Errorf("local summarization model not configured")
+		}
+		url := strings.TrimRight(config.URL, "/")
+		if url == "" {
+			url = summarizationLocalDefaultURL
+		}
+		return &localSummarization{url: url, model: config.Model, apiKey: config.APIKey}, nil
+	default:
+		return nil, fmt.Errorf("unknown summarization provider %q", config.Provider)
+	}
+}
+
+// summarizeTranscript summarizes a transcript and stores the extracted fields.
+// The summary itself is stored as the call "alertSummary" with the transcript.
+// Returns nil when summarization is not possible.
+func (controller *Controller) summarizeTranscript(callId uint64, transcript string) *CallSummary {
+	if strings.TrimSpace(transcript) == "" {
+		return nil
+	}
+
+	provider, err := controller.newSummarizationProvider(controller.Options.SummarizationConfig)
+	if err != nil {
+		controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("summarization skipped for call %d: %v", callId, err))
+		return nil
+	}
+
+	content, err := provider.Summarize(summarizationPrompt, fmt.Sprintf("Transcript: %s", transcript))
+	if err != nil {
+		controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("summarization of call %d with %s failed: %v", callId, provider.GetName(), err))
+		return nil
+	}
+
+	summary, err := parseCallSummary(content)
+	if err != nil {
+		controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("summarization of call %d with %s failed: %v", callId, provider.GetName(), err))
+		return nil
+	}
+
+	if !summary.Fields.IsEmpty() {
+		fields, _ := json.Marshal(summary.Fields)
+		if _, err := controller.Database.Sql.Exec(`UPDATE "calls" SET "summaryFields" = $1 WHERE "callId" = $2`, string(fields), callId); err != nil {
+			controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("failed to store summary fields for call %d: %v", callId, err))
+		}
+	}
+
+	return summary
+}
+
+// parseSummaryFields reads the "summaryFields" column of a call.
+func parseSummaryFields(value string) *CallSummaryFields {
+	if value == "" {
+		return nil
+	}
+	var fields CallSummaryFields
+	if err := json.Unmarshal([]byte(value), &fields); err != nil || fields.IsEmpty() {
+		return nil
+	}
+	return &fields
+}
+
+// notificationCallText is the notification body for a call: its summary when
+// one was made, otherwise the transcript.
+func notificationCallText(call *Call) string {
+	if call == nil {
+		return ""
+	}
+	if summary := strings.TrimSpace(call.AlertSummary); summary != "" && !strings.EqualFold(summary, "RADIO TRAFFIC") {
+		return strings.ToUpper(summary)
+	}
+	return strings.ToUpper(call.Transcript)
+}
+
+// openAISummarization summarizes with the OpenAI integration chat model.
+type openAISummarization struct {
+	controller *Controller
+}
+
+func (summarization *openAISummarization) Summarize(prompt string, transcript string) (string, error) {
+	return summarization.controller.openAIChatJSON(prompt, transcript)
+}
+
+func (summarization *openAISummarization) GetName() string {
+	return "OpenAI"
+}
+
+// localSummarization summarizes with an OpenAI-compatible chat endpoint.
+type localSummarization struct {
+	url    string
+	model  string
+	apiKey string
+}
+
+func (summarization *localSummarization) Summarize(prompt string, transcript string) (string, error) {
+	body, _ := json.Marshal(map[string]any{
+		"model":           summarization.model,
+		"response_format": map[string]string{"type": "json_object"},
+		"messages": []map[string]string{
+			{"role": "system", "content": prompt},
+			{"role": "user", "content": transcript},
+		},
+		"temperature": 0.2,
+	})
+
+	headers := map[string]string{}
+	if summarization.apiKey != "" {
+		headers["Authorization"] = "Bearer " + summarization.apiKey
+	}
+	respBody, err := postTranslation(summarization.url+"/v1/chat/completions", body, headers)
+	if err != nil {
+		return "", err
+	}
+
+	var response struct {
+		Choices []struct {
+			Message struct {
+				Content string `json:"content"`
+			} `json:"message"`
+		} `json:"choices"`
+	}
+	if err := json.Unmarshal(respBody, &response); err != nil {
+		return "", fmt.Errorf("failed to parse response: %v", err)
+	}
+	if len(response.Choices) == 0 {
+		return "", fmt.Errorf("no choices returned")
+	}
+	return response.Choices[0].Message.Content, nil
+}
+
+func (summarization *localSummarization) GetName() string {
+	return "local"
+}
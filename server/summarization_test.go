@@ -0,0 +1,57 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseCallSummary(t *testing.T) {
+	content := "```json\n{\"summary\": \"Structure fire\\nat 12 Oak St\", \"address\": \"12 oak st\", \"incidentType\": \"structure fire\", \"units\": [\"Engine 5\", 12, null, \"\"]}\n```"
+
+	summary, err := parseCallSummary(content)
+	if err != nil {
+		t.Fatalf("parseCallSummary: %v", err)
+	}
+	if summary.Summary != "Structure fire at 12 Oak St" {
+		t.Fatalf("summary = %q", summary.Summary)
+	}
+	if summary.Fields.Address != "12 OAK ST" || summary.Fields.IncidentType != "STRUCTURE FIRE" {
+		t.Fatalf("fields = %+v", summary.Fields)
+	}
+	if strings.Join(summary.Fields.Units, ",") != "ENGINE 5,12" {
+		t.Fatalf("units = %v", summary.Fields.Units)
+	}
+}
+
+func TestParseCallSummaryRejectsEmpty(t *testing.T) {
+	if _, err := parseCallSummary(`{"summary": "  "}`); err == nil {
+		t.Fatalf("expected an error for an empty summary")
+	}
+	if _, err := parseCallSummary(`not json`); err == nil {
+		t.Fatalf("expected an error for invalid json")
+	}
+}
+
+func TestParseSummaryFields(t *testing.T) {
+	if parseSummaryFields("") != nil || parseSummaryFields(`{}`) != nil {
+		t.Fatalf("expected nil for empty fields")
+	}
+	fields := parseSummaryFields(`{"address":"1 MAIN ST"}`)
+	if fields == nil || fields.Address != "1 MAIN ST" {
+		t.Fatalf("fields = %+v", fields)
+	}
+}
+
+func TestNotificationCallText(t *testing.T) {
+	call := &Call{Transcript: "engine 5 respond", AlertSummary: "Radio traffic"}
+	if got := notificationCallText(call); got != "ENGINE 5 RESPOND" {
+		t.Fatalf("generic summary: got %q", got)
+	}
+	call.AlertSummary = "Engine 5 to a fire alarm"
+	if got := notificationCallText(call); got != "ENGINE 5 TO A FIRE ALARM" {
+		t.Fatalf("summary: got %q", got)
+	}
+	if notificationCallText(nil) != "" {
+		t.Fatalf("expected empty text for nil call")
+	}
+}
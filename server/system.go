@@ -46,6 +46,9 @@ type System struct {
 	// When true, heard unit refs + labels from calls are merged into this system's unit list (independent of AutoPopulate).
 	AutoPopulateUnits bool `json:"autoPopulateUnits"`
 	TranscriptionPrompt string // Custom Whisper/AssemblyAI prompt; overrides the global prompt when non-empty
+	LifeSafetyPhrases   bool   // Check transcripts against the built-in life-safety phrase pack (mayday, officer down...)
+	GeocodeHint         string // City, county or state appended to dispatch addresses when geocoding, e.g. "Springfield, IL"
+	TimeZone            string // IANA time zone calls are shown in, e.g. "America/Chicago"; empty = server time
 	// When true, talkgroups with autoLearnToneSets may observe paging patterns for admin review emails.
 	AutoLearnToneSets              bool     `json:"autoLearnToneSets"`
 	AutoLearnToneSetsTagIds        []uint64 `json:"autoLearnToneSetsTagIds"`
@@ -176,6 +179,21 @@ func (system *System) FromMap(m map[string]any) *System {
 		system.TranscriptionPrompt = v
 	}
 
+	switch v := m["lifeSafetyPhrases"].(type) {
+	case bool:
+		system.LifeSafetyPhrases = v
+	}
+
+	switch v := m["geocodeHint"].(type) {
+	case string:
+		system.GeocodeHint = strings.TrimSpace(v)
+	}
+
+	switch v := m["timeZone"].(type) {
+	case string:
+		system.TimeZone = validTimeZone(v)
+	}
+
 	switch v := m["autoLearnToneSets"].(type) {
 	case bool:
 		system.AutoLearnToneSets = v
@@ -293,6 +311,10 @@ func (system *System) MarshalJSON() ([]byte, error) {
 	// Always include transcriptionPrompt (empty string is valid — means "use global")
 	m["transcriptionPrompt"] = system.TranscriptionPrompt
 
+	m["lifeSafetyPhrases"] = system.LifeSafetyPhrases
+	m["geocodeHint"] = system.GeocodeHint
+	m["timeZone"] = system.TimeZone
+
 	m["autoLearnToneSets"] = system.AutoLearnToneSets
 	m["autoLearnToneSetsTagIds"] = system.AutoLearnToneSetsTagIds
 	m["autoLearnToneSetsAutoOffDays"] = system.AutoLearnToneSetsAutoOffDays
@@ -319,8 +341,9 @@ func (system *System) MarshalJSON() ([]byte, error) {
 type SystemMap map[string]any
 
 type Systems struct {
-	List  []*System
-	mutex sync.RWMutex
+	List       []*System
+	audioStore *AudioStore
+	mutex      sync.RWMutex
 }
 
 func NewSystems() *Systems {
@@ -420,135 +443,23 @@ func (systems *Systems) GetScopedSystems(client *Client, groups *Groups, tags *T
 		systemsMap = SystemsMap{}
 	)
 
-	user := client.User
-
-	// Get user's group if they belong to one
-	var userGroup *UserGroup
-	if user != nil && user.UserGroupId > 0 && client.Controller != nil {
-		userGroup = client.Controller.UserGroups.Get(user.UserGroupId)
-	}
-
-	// Helper function to check if a system is allowed
-	isSystemAllowed := func(systemRef uint) bool {
-		// If user belongs to a group, check group access first
-		if userGroup != nil {
-			return userGroup.HasSystemAccess(uint64(systemRef))
-		}
-		// No group restrictions
-		return true
-	}
-
-	// Helper function to filter talkgroups based on group restrictions
-	filterTalkgroupsByGroup := func(system *System) *System {
-		// If no group restrictions, return system as-is
-		if userGroup == nil {
-			return system
-		}
-
-		// Filter talkgroups based on group access
-		filteredSystem := *system
-		filteredSystem.Talkgroups = NewTalkgroups()
-
-		for _, tg := range system.Talkgroups.List {
-			if userGroup.HasTalkgroupAccess(uint64(system.SystemRef), tg.TalkgroupRef) {
-				filteredSystem.Talkgroups.List = append(filteredSystem.Talkgroups.List, tg)
-			}
-		}
-
-		return &filteredSystem
+	scope := userScope{user: client.User}
+	if client.Controller != nil {
+		scope = client.Controller.userScope(client.User)
 	}
 
-	if user == nil || user.systemsData == nil {
-		// No user-level restrictions, but still need to check group restrictions
-		for _, system := range systems.List {
-			if isSystemAllowed(system.SystemRef) {
-				filteredSystem := filterTalkgroupsByGroup(system)
-				rawSystems = append(rawSystems, *filteredSystem)
-			}
-		}
-
-	} else {
-		switch v := user.systemsData.(type) {
-		case nil:
-			// No user-level restrictions, but still need to check group restrictions
-			for _, system := range systems.List {
-				if isSystemAllowed(system.SystemRef) {
-					filteredSystem := filterTalkgroupsByGroup(system)
-					rawSystems = append(rawSystems, *filteredSystem)
-				}
-			}
-
-		case string:
-			if strings.TrimSpace(v) == "" || v == "*" {
-				// User allows all systems, but still need to check group restrictions
-				for _, system := range systems.List {
-					if isSystemAllowed(system.SystemRef) {
-						filteredSystem := filterTalkgroupsByGroup(system)
-						rawSystems = append(rawSystems, *filteredSystem)
-					}
-				}
-			}
-
-		case []any:
-			for _, fSystem := range v {
-				switch v := fSystem.(type) {
-				case map[string]any:
-					var (
-						mSystemId   = v["id"]
-						mTalkgroups = v["talkgroups"]
-						systemId    uint
-					)
-
-					switch v := mSystemId.(type) {
-					case float64:
-						systemId = uint(v)
-					default:
-						continue
-					}
-
-				system, ok := systems.GetSystemByRef(systemId)
-				if !ok {
-					continue
-				}
-
-				// Check group access first - if group doesn't allow this system, skip it
-				if !isSystemAllowed(system.SystemRef) {
-					continue
-				}
-
-				switch v := mTalkgroups.(type) {
-				case string:
-					if mTalkgroups == "*" {
-						// User allows all talkgroups, but filter by group restrictions
-						filteredSystem := filterTalkgroupsByGroup(system)
-						rawSystems = append(rawSystems, *filteredSystem)
-						continue
-					}
-
-				case []any:
-					rawSystem := *system
-					rawSystem.Talkgroups = NewTalkgroups()
-					for _, fTalkgroupId := range v {
-						switch v := fTalkgroupId.(type) {
-						case float64:
-							rawTalkgroup, ok := system.Talkgroups.GetTalkgroupByRef(uint(v))
-							if !ok {
-								continue
-							}
-							// Check group access for this talkgroup
-							if userGroup != nil && !userGroup.HasTalkgroupAccess(uint64(system.SystemRef), rawTalkgroup.TalkgroupRef) {
-								continue
-							}
-							rawSystem.Talkgroups.List = append(rawSystem.Talkgroups.List, rawTalkgroup)
-						default:
-							continue
-						}
-					}
-					rawSystems = append(rawSystems, rawSystem)
-				}
-				}
+	for _, system := range systems.List {
+		if !scope.allowsSystem(system) {
+			continue
+		}
+		rawSystem := *system
+		rawSystem.Talkgroups = NewTalkgroups()
+		for _, talkgroup := range system.Talkgroups.List {
+			if scope.allowsTalkgroup(system, talkgroup) {
+				rawSystem.Talkgroups.List = append(rawSystem.Talkgroups.List, talkgroup)
 			}
 		}
+		rawSystems = append(rawSystems, rawSystem)
 	}
 
 	for _, rawSystem := range rawSystems {
@@ -654,6 +565,7 @@ func (systems *Systems) GetScopedSystems(client *Client, groups *Groups, tags *T
 			"units":         rawSystem.Units.List,
 			"type":          rawSystem.Kind,
 			"alertsEnabled": rawSystem.AlertsEnabled,
+			"timeZone":      rawSystem.TimeZone,
 		}
 
 		systemsMap = append(systemsMap, systemMap)
@@ -683,7 +595,7 @@ func (systems *Systems) Read(db *Database) error {
 	formatError := errorFormatter("systems", "read")
 
 	// --- Query 1: systems ---
-	query := `SELECT "systemId", "autoPopulate", "blacklists", "delay", "label", "order", "systemRef", "type", "preferredApiKeyId", "noAudioAlertsEnabled", "noAudioThresholdMinutes", "alertsEnabled", "autoPopulateAlertsEnabled", "autoPopulateUnits", "transcriptionPrompt", "autoLearnToneSets", "autoLearnToneSetsTagIds", "autoLearnToneSetsAutoOffDays", "autoLearnToneSetsExpiresAt", "bulkToneDetectionEnabled", "bulkToneDetectionTagIds", "bulkToneDetectionAutoOffDays", "bulkToneDetectionExpiresAt", "autoLearnUnitAliases", "autoLearnUnitAliasesTagIds", "autoLearnUnitAliasesAutoOffDays", "autoLearnUnitAliasesExpiresAt" FROM "systems"`
+	query := `SELECT "systemId", "autoPopulate", "blacklists", "delay", "label", "order", "systemRef", "type", "preferredApiKeyId", "noAudioAlertsEnabled", "noAudioThresholdMinutes", "alertsEnabled", "autoPopulateAlertsEnabled", "autoPopulateUnits", "transcriptionPrompt", "autoLearnToneSets", "autoLearnToneSetsTagIds", "autoLearnToneSetsAutoOffDays", "autoLearnToneSetsExpiresAt", "bulkToneDetectionEnabled", "bulkToneDetectionTagIds", "bulkToneDetectionAutoOffDays", "bulkToneDetectionExpiresAt", "autoLearnUnitAliases", "autoLearnUnitAliasesTagIds", "autoLearnUnitAliasesAutoOffDays", "autoLearnUnitAliasesExpiresAt", "lifeSafetyPhrases", "geocodeHint", "timeZone" FROM "systems"`
 	rows, err := db.Sql.Query(query)
 	if err != nil {
 		return formatError(err, query)
@@ -697,7 +609,7 @@ func (systems *Systems) Read(db *Database) error {
 		var bulkTagIdsJson string
 		var toneLearnTagIdsJson string
 		var unitLearnTagIdsJson string
-		if err = rows.Scan(&system.Id, &system.AutoPopulate, &system.Blacklists, &system.Delay, &system.Label, &system.Order, &system.SystemRef, &system.Kind, &preferredApiKeyUnused, &system.NoAudioAlertsEnabled, &system.NoAudioThresholdMinutes, &system.AlertsEnabled, &system.AutoPopulateAlertsEnabled, &system.AutoPopulateUnits, &system.TranscriptionPrompt, &system.AutoLearnToneSets, &toneLearnTagIdsJson, &system.AutoLearnToneSetsAutoOffDays, &system.AutoLearnToneSetsExpiresAt, &system.BulkToneDetectionEnabled, &bulkTagIdsJson, &system.BulkToneDetectionAutoOffDays, &system.BulkToneDetectionExpiresAt, &system.AutoLearnUnitAliases, &unitLearnTagIdsJson, &system.AutoLearnUnitAliasesAutoOffDays, &system.AutoLearnUnitAliasesExpiresAt); err != nil {
+		if err = rows.Scan(&system.Id, &system.AutoPopulate, &system.Blacklists, &system.Delay, &system.Label, &system.Order, &system.SystemRef, &system.Kind, &preferredApiKeyUnused, &system.NoAudioAlertsEnabled, &system.NoAudioThresholdMinutes, &system.AlertsEnabled, &system.AutoPopulateAlertsEnabled, &system.AutoPopulateUnits, &system.TranscriptionPrompt, &system.AutoLearnToneSets, &toneLearnTagIdsJson, &system.AutoLearnToneSetsAutoOffDays, &system.AutoLearnToneSetsExpiresAt, &system.BulkToneDetectionEnabled, &bulkTagIdsJson, &system.BulkToneDetectionAutoOffDays, &system.BulkToneDetectionExpiresAt, &system.AutoLearnUnitAliases, &unitLearnTagIdsJson, &system.AutoLearnUnitAliasesAutoOffDays, &system.AutoLearnUnitAliasesExpiresAt, &system.LifeSafetyPhrases, &system.GeocodeHint, &system.TimeZone); err != nil {
 			return formatError(err, query)
 		}
 		system.AutoLearnToneSetsTagIds = parseBulkToneTagIds(toneLearnTagIdsJson)
@@ -713,7 +625,7 @@ func (systems *Systems) Read(db *Database) error {
 	}
 
 	// --- Query 2: all sites (bulk, no per-system loop) ---
-	siteQuery := `SELECT "siteId", "systemId", "label", "order", "siteRef", "rfss", "frequencies", "preferred" FROM "sites" ORDER BY "systemId", "order"`
+	siteQuery := `SELECT "siteId", "systemId", "label", "order", "siteRef", "rfss", "frequencies", "controlChannels", "latitude", "longitude", "county", "preferred" FROM "sites" ORDER BY "systemId", "order"`
 	siteRows, err := db.Sql.Query(siteQuery)
 	if err != nil {
 		return formatError(err, siteQuery)
@@ -723,9 +635,9 @@ func (systems *Systems) Read(db *Database) error {
 	for siteRows.Next() {
 		site := NewSite()
 		var systemId uint64
-		var frequenciesJSON string
+		var frequenciesJSON, controlChannelsJSON string
 		var sitePreferredUnused bool
-		if err = siteRows.Scan(&site.Id, &systemId, &site.Label, &site.Order, &site.SiteRef, &site.RFSS, &frequenciesJSON, &sitePreferredUnused); err != nil {
+		if err = siteRows.Scan(&site.Id, &systemId, &site.Label, &site.Order, &site.SiteRef, &site.RFSS, &frequenciesJSON, &controlChannelsJSON, &site.Latitude, &site.Longitude, &site.County, &sitePreferredUnused); err != nil {
 			return formatError(err, siteQuery)
 		}
 		if len(frequenciesJSON) > 0 {
@@ -734,6 +646,7 @@ func (systems *Systems) Read(db *Database) error {
 		if site.Frequencies == nil {
 			site.Frequencies = []float64{}
 		}
+		site.ControlChannels = parseSiteControlChannels(controlChannelsJSON)
 		if sys, ok := systemById[systemId]; ok {
 			sys.Sites.mutex.Lock()
 			sys.Sites.List = append(sys.Sites.List, site)
@@ -750,12 +663,7 @@ func (systems *Systems) Read(db *Database) error {
 	}
 
 	// --- Query 3: all talkgroups (bulk, no per-system loop) ---
-	var tgQuery string
-	if db.Config.DbType == DbTypePostgresql {
-		tgQuery = `SELECT t."talkgroupId", t."systemId", t."delay", t."frequency", t."label", t."name", t."order", t."tagId", t."talkgroupRef", t."type", t."toneDetectionEnabled", t."toneSets", t."preferredApiKeyId", t."excludeFromPreferredSite", t."toneDownstreamEnabled", t."toneDownstreamURL", t."toneDownstreamAPIKey", t."alertCooldownSeconds", t."linkedVoiceTalkgroupRef", t."linkedVoiceWindowSeconds", t."linkedVoiceMinDurationSeconds", t."alertsEnabled", t."transcriptionPrompt", t."autoLearnToneSets", t."alertingTalkgroup", t."autoLearnUnitAliases", STRING_AGG(CAST(COALESCE(tg."groupId", 0) AS text), ',') FROM "talkgroups" AS t LEFT JOIN "talkgroupGroups" AS tg ON tg."talkgroupId" = t."talkgroupId" GROUP BY t."talkgroupId", t."systemId", t."preferredApiKeyId", t."excludeFromPreferredSite", t."toneDownstreamEnabled", t."toneDownstreamURL", t."toneDownstreamAPIKey", t."alertCooldownSeconds", t."linkedVoiceTalkgroupRef", t."linkedVoiceWindowSeconds", t."linkedVoiceMinDurationSeconds", t."alertsEnabled", t."transcriptionPrompt", t."autoLearnToneSets", t."alertingTalkgroup", t."autoLearnUnitAliases" ORDER BY t."systemId", t."order", t."talkgroupId"`
-	} else {
-		tgQuery = `SELECT t."talkgroupId", t."systemId", t."delay", t."frequency", t."label", t."name", t."order", t."tagId", t."talkgroupRef", t."type", t."toneDetectionEnabled", t."toneSets", t."preferredApiKeyId", t."excludeFromPreferredSite", t."toneDownstreamEnabled", t."toneDownstreamURL", t."toneDownstreamAPIKey", t."alertCooldownSeconds", t."linkedVoiceTalkgroupRef", t."linkedVoiceWindowSeconds", t."linkedVoiceMinDurationSeconds", t."alertsEnabled", t."transcriptionPrompt", t."autoLearnToneSets", t."alertingTalkgroup", t."autoLearnUnitAliases", GROUP_CONCAT(COALESCE(tg."groupId", 0)) FROM "talkgroups" AS t LEFT JOIN "talkgroupGroups" AS tg ON tg."talkgroupId" = t."talkgroupId" GROUP BY t."talkgroupId" ORDER BY t."systemId", t."order", t."talkgroupId"`
-	}
+	tgQuery := `SELECT t."talkgroupId", t."systemId", t."delay", t."frequency", t."label", t."name", t."order", t."tagId", t."talkgroupRef", t."type", t."toneDetectionEnabled", t."toneSets", t."preferredApiKeyId", t."excludeFromPreferredSite", t."toneDownstreamEnabled", t."toneDownstreamURL", t."toneDownstreamAPIKey", t."alertCooldownSeconds", t."linkedVoiceTalkgroupRef", t."linkedVoiceWindowSeconds", t."linkedVoiceMinDurationSeconds", t."alertsEnabled", t."transcriptionPrompt", t."autoLearnToneSets", t."alertingTalkgroup", t."autoLearnUnitAliases", t."transcriptLanguage", t."encrypted", t."encryptedPolicy", t."transcriptionTier", STRING_AGG(CAST(COALESCE(tg."groupId", 0) AS text), ',') FROM "talkgroups" AS t LEFT JOIN "talkgroupGroups" AS tg ON tg."talkgroupId" = t."talkgroupId" GROUP BY t."talkgroupId", t."systemId", t."preferredApiKeyId", t."excludeFromPreferredSite", t."toneDownstreamEnabled", t."toneDownstreamURL", t."toneDownstreamAPIKey", t."alertCooldownSeconds", t."linkedVoiceTalkgroupRef", t."linkedVoiceWindowSeconds", t."linkedVoiceMinDurationSeconds", t."alertsEnabled", t."transcriptionPrompt", t."autoLearnToneSets", t."alertingTalkgroup", t."autoLearnUnitAliases", t."transcriptLanguage", t."encrypted", t."encryptedPolicy", t."transcriptionTier" ORDER BY t."systemId", t."order", t."talkgroupId"`
 
 	tgRows, err := db.Sql.Query(tgQuery)
 	if err != nil {
@@ -771,7 +679,7 @@ func (systems *Systems) Read(db *Database) error {
 		var preferredApiKeyUnused sql.NullInt64
 		var excludePreferredUnused bool
 
-		if err = tgRows.Scan(&talkgroup.Id, &systemId, &talkgroup.Delay, &talkgroup.Frequency, &talkgroup.Label, &talkgroup.Name, &talkgroup.Order, &talkgroup.TagId, &talkgroup.TalkgroupRef, &talkgroup.Kind, &talkgroup.ToneDetectionEnabled, &toneSetsJson, &preferredApiKeyUnused, &excludePreferredUnused, &talkgroup.ToneDownstreamEnabled, &talkgroup.ToneDownstreamURL, &talkgroup.ToneDownstreamAPIKey, &talkgroup.AlertCooldownSeconds, &talkgroup.LinkedVoiceTalkgroupRef, &talkgroup.LinkedVoiceWindowSeconds, &talkgroup.LinkedVoiceMinDurationSeconds, &talkgroup.AlertsEnabled, &talkgroup.TranscriptionPrompt, &talkgroup.AutoLearnToneSets, &talkgroup.AlertingTalkgroup, &talkgroup.AutoLearnUnitAliases, &groupIds); err != nil {
+		if err = tgRows.Scan(&talkgroup.Id, &systemId, &talkgroup.Delay, &talkgroup.Frequency, &talkgroup.Label, &talkgroup.Name, &talkgroup.Order, &talkgroup.TagId, &talkgroup.TalkgroupRef, &talkgroup.Kind, &talkgroup.ToneDetectionEnabled, &toneSetsJson, &preferredApiKeyUnused, &excludePreferredUnused, &talkgroup.ToneDownstreamEnabled, &talkgroup.ToneDownstreamURL, &talkgroup.ToneDownstreamAPIKey, &talkgroup.AlertCooldownSeconds, &talkgroup.LinkedVoiceTalkgroupRef, &talkgroup.LinkedVoiceWindowSeconds, &talkgroup.LinkedVoiceMinDurationSeconds, &talkgroup.AlertsEnabled, &talkgroup.TranscriptionPrompt, &talkgroup.AutoLearnToneSets, &talkgroup.AlertingTalkgroup, &talkgroup.AutoLearnUnitAliases, &talkgroup.TranscriptLanguage, &talkgroup.Encrypted, &talkgroup.EncryptedPolicy, &talkgroup.TranscriptionTier, &groupIds); err != nil {
 			return formatError(err, tgQuery)
 		}
 		if toneSetsJson != "" && toneSetsJson != "[]" {
@@ -834,6 +742,7 @@ func (systems *Systems) Read(db *Database) error {
 func (systems *Systems) Write(db *Database) error {
 	var (
 		err       error
+		files     []string
 		query     string
 		res       sql.Result
 		rows      *sql.Rows
@@ -890,30 +799,22 @@ func (systems *Systems) Write(db *Database) error {
 	}
 
 	if len(systemIds) > 0 {
-		if b, err := json.Marshal(systemIds); err == nil {
-			in := strings.ReplaceAll(strings.ReplaceAll(string(b), "[", "("), "]", ")")
-
-			query = fmt.Sprintf(`DELETE FROM "systems" WHERE "systemId" IN %s`, in)
-			if res, err = tx.Exec(query); err != nil {
-				tx.Rollback()
-				return formatError(err, query)
-			}
+		args := queryArgs{}
+		in := args.in(systemIds)
 
-			if count, err := res.RowsAffected(); err == nil && count > 0 {
-				query = fmt.Sprintf(`DELETE FROM "sites" WHERE "systemId" IN %s`, in)
-				if _, err = tx.Exec(query); err != nil {
-					tx.Rollback()
-					return formatError(err, query)
-				}
+		// Calls go with their system through ON DELETE CASCADE
+		files = audioFileLocations(tx, `"systemId" IN `+in, args...)
 
-				query = fmt.Sprintf(`DELETE FROM "talkgroups" WHERE "systemId" IN %s`, in)
-				if _, err = tx.Exec(query); err != nil {
-					tx.Rollback()
-					return formatError(err, query)
-				}
+		query = `DELETE FROM "systems" WHERE "systemId" IN ` + in
+		if res, err = tx.Exec(query, args...); err != nil {
+			tx.Rollback()
+			return formatError(err, query)
+		}
 
-				query = fmt.Sprintf(`DELETE FROM "units" WHERE "systemId" IN %s`, in)
-				if _, err = tx.Exec(query); err != nil {
+		if count, err := res.RowsAffected(); err == nil && count > 0 {
+			for _, table := range []string{"sites", "talkgroups", "units"} {
+				query = fmt.Sprintf(`DELETE FROM %q WHERE "systemId" IN %s`, table, in)
+				if _, err = tx.Exec(query, args...); err != nil {
 					tx.Rollback()
 					return formatError(err, query)
 				}
@@ -927,8 +828,8 @@ func (systems *Systems) Write(db *Database) error {
 
 		// First check if a system with this ID already exists
 		if system.Id > 0 {
-			query = fmt.Sprintf(`SELECT COUNT(*) FROM "systems" WHERE "systemId" = %d`, system.Id)
-			if err = tx.QueryRow(query).Scan(&count); err != nil {
+			query = `SELECT COUNT(*) FROM "systems" WHERE "systemId" = $1`
+			if err = tx.QueryRow(query, system.Id).Scan(&count); err != nil {
 				break
 			}
 		}
@@ -936,8 +837,8 @@ func (systems *Systems) Write(db *Database) error {
 		// If not found by ID, check if a system with the same SystemRef exists
 		// This prevents duplicates when auto-creating systems
 		if count == 0 && system.SystemRef > 0 {
-			query = fmt.Sprintf(`SELECT "systemId" FROM "systems" WHERE "systemRef" = %d LIMIT 1`, system.SystemRef)
-			if err = tx.QueryRow(query).Scan(&existingId); err == nil && existingId > 0 {
+			query = `SELECT "systemId" FROM "systems" WHERE "systemRef" = $1 LIMIT 1`
+			if err = tx.QueryRow(query, system.SystemRef).Scan(&existingId); err == nil && existingId > 0 {
 				// Found existing system with same SystemRef, use its ID
 				system.Id = existingId
 				count = 1
@@ -947,33 +848,63 @@ func (systems *Systems) Write(db *Database) error {
 			}
 		}
 
-		preferredApiKeyIdSQL := "NULL"
+		columns := queryColumns{}
+		columns.set("autoPopulate", system.AutoPopulate)
+		columns.set("blacklists", system.Blacklists)
+		columns.set("delay", system.Delay)
+		columns.set("label", system.Label)
+		columns.set("order", system.Order)
+		columns.set("systemRef", system.SystemRef)
+		columns.set("type", system.Kind)
+		columns.set("preferredApiKeyId", nil)
+		columns.set("noAudioAlertsEnabled", system.NoAudioAlertsEnabled)
+		columns.set("noAudioThresholdMinutes", system.NoAudioThresholdMinutes)
+		columns.set("alertsEnabled", system.AlertsEnabled)
+		columns.set("autoPopulateAlertsEnabled", system.AutoPopulateAlertsEnabled)
+		columns.set("autoPopulateUnits", system.AutoPopulateUnits)
+		columns.set("transcriptionPrompt", system.TranscriptionPrompt)
+		columns.set("autoLearnToneSets", system.AutoLearnToneSets)
+		columns.set("autoLearnToneSetsTagIds", serializeBulkToneTagIds(system.AutoLearnToneSetsTagIds))
+		columns.set("autoLearnToneSetsAutoOffDays", system.AutoLearnToneSetsAutoOffDays)
+		columns.set("autoLearnToneSetsExpiresAt", system.AutoLearnToneSetsExpiresAt)
+		columns.set("bulkToneDetectionEnabled", system.BulkToneDetectionEnabled)
+		columns.set("bulkToneDetectionTagIds", serializeBulkToneTagIds(system.BulkToneDetectionTagIds))
+		columns.set("bulkToneDetectionAutoOffDays", system.BulkToneDetectionAutoOffDays)
+		columns.set("bulkToneDetectionExpiresAt", system.BulkToneDetectionExpiresAt)
+		columns.set("autoLearnUnitAliases", system.AutoLearnUnitAliases)
+		columns.set("autoLearnUnitAliasesTagIds", serializeBulkToneTagIds(system.AutoLearnUnitAliasesTagIds))
+		columns.set("autoLearnUnitAliasesAutoOffDays", system.AutoLearnUnitAliasesAutoOffDays)
+		columns.set("autoLearnUnitAliasesExpiresAt", system.AutoLearnUnitAliasesExpiresAt)
+		columns.set("lifeSafetyPhrases", system.LifeSafetyPhrases)
+		columns.set("geocodeHint", system.GeocodeHint)
+		columns.set("timeZone", system.TimeZone)
+
+		var args []any
 
 		if count == 0 {
 			if system.Id > 0 {
 				// Preserve the explicit ID when inserting
-				query = fmt.Sprintf(`INSERT INTO "systems" ("systemId", "autoPopulate", "blacklists", "delay", "label", "order", "systemRef", "type", "preferredApiKeyId", "noAudioAlertsEnabled", "noAudioThresholdMinutes", "alertsEnabled", "autoPopulateAlertsEnabled", "autoPopulateUnits", "transcriptionPrompt", "autoLearnToneSets", "autoLearnToneSetsTagIds", "autoLearnToneSetsAutoOffDays", "autoLearnToneSetsExpiresAt", "bulkToneDetectionEnabled", "bulkToneDetectionTagIds", "bulkToneDetectionAutoOffDays", "bulkToneDetectionExpiresAt", "autoLearnUnitAliases", "autoLearnUnitAliasesTagIds", "autoLearnUnitAliasesAutoOffDays", "autoLearnUnitAliasesExpiresAt") VALUES (%d, %t, '%s', %d, '%s', %d, %d, '%s', %s, %t, %d, %t, %t, %t, '%s', %t, '%s', %d, %d, %t, '%s', %d, %d, %t, '%s', %d, %d)`, system.Id, system.AutoPopulate, system.Blacklists, system.Delay, escapeQuotes(system.Label), system.Order, system.SystemRef, system.Kind, preferredApiKeyIdSQL, system.NoAudioAlertsEnabled, system.NoAudioThresholdMinutes, system.AlertsEnabled, system.AutoPopulateAlertsEnabled, system.AutoPopulateUnits, escapeQuotes(system.TranscriptionPrompt), system.AutoLearnToneSets, escapeQuotes(serializeBulkToneTagIds(system.AutoLearnToneSetsTagIds)), system.AutoLearnToneSetsAutoOffDays, system.AutoLearnToneSetsExpiresAt, system.BulkToneDetectionEnabled, escapeQuotes(serializeBulkToneTagIds(system.BulkToneDetectionTagIds)), system.BulkToneDetectionAutoOffDays, system.BulkToneDetectionExpiresAt, system.AutoLearnUnitAliases, escapeQuotes(serializeBulkToneTagIds(system.AutoLearnUnitAliasesTagIds)), system.AutoLearnUnitAliasesAutoOffDays, system.AutoLearnUnitAliasesExpiresAt)
-			} else {
-				// Let database assign auto-increment ID
-				query = fmt.Sprintf(`INSERT INTO "systems" ("autoPopulate", "blacklists", "delay", "label", "order", "systemRef", "type", "preferredApiKeyId", "noAudioAlertsEnabled", "noAudioThresholdMinutes", "alertsEnabled", "autoPopulateAlertsEnabled", "autoPopulateUnits", "transcriptionPrompt", "autoLearnToneSets", "autoLearnToneSetsTagIds", "autoLearnToneSetsAutoOffDays", "autoLearnToneSetsExpiresAt", "bulkToneDetectionEnabled", "bulkToneDetectionTagIds", "bulkToneDetectionAutoOffDays", "bulkToneDetectionExpiresAt", "autoLearnUnitAliases", "autoLearnUnitAliasesTagIds", "autoLearnUnitAliasesAutoOffDays", "autoLearnUnitAliasesExpiresAt") VALUES (%t, '%s', %d, '%s', %d, %d, '%s', %s, %t, %d, %t, %t, %t, '%s', %t, '%s', %d, %d, %t, '%s', %d, %d, %t, '%s', %d, %d)`, system.AutoPopulate, system.Blacklists, system.Delay, escapeQuotes(system.Label), system.Order, system.SystemRef, system.Kind, preferredApiKeyIdSQL, system.NoAudioAlertsEnabled, system.NoAudioThresholdMinutes, system.AlertsEnabled, system.AutoPopulateAlertsEnabled, system.AutoPopulateUnits, escapeQuotes(system.TranscriptionPrompt), system.AutoLearnToneSets, escapeQuotes(serializeBulkToneTagIds(system.AutoLearnToneSetsTagIds)), system.AutoLearnToneSetsAutoOffDays, system.AutoLearnToneSetsExpiresAt, system.BulkToneDetectionEnabled, escapeQuotes(serializeBulkToneTagIds(system.BulkToneDetectionTagIds)), system.BulkToneDetectionAutoOffDays, system.BulkToneDetectionExpiresAt, system.AutoLearnUnitAliases, escapeQuotes(serializeBulkToneTagIds(system.AutoLearnUnitAliasesTagIds)), system.AutoLearnUnitAliasesAutoOffDays, system.AutoLearnUnitAliasesExpiresAt)
+				columns.set("systemId", system.Id)
 			}
+			// Otherwise let database assign auto-increment ID
+			query, args = columns.insert("systems")
 
 			if db.Config.DbType == DbTypePostgresql {
 				if system.Id > 0 {
 					// When inserting with explicit ID, don't use RETURNING as it's already set
-					if _, err = tx.Exec(query); err != nil {
+					if _, err = tx.Exec(query, args...); err != nil {
 						break
 					}
 				} else {
 					// Only use RETURNING when database assigns the ID
 					query = query + ` RETURNING "systemId"`
-					if err = tx.QueryRow(query).Scan(&system.Id); err != nil {
+					if err = tx.QueryRow(query, args...).Scan(&system.Id); err != nil {
 						break
 					}
 				}
 
 			} else {
-				if res, err = tx.Exec(query); err == nil {
+				if res, err = tx.Exec(query, args...); err == nil {
 					// Only get LastInsertId when we didn't specify an explicit ID
 					if system.Id == 0 {
 						if id, err := res.LastInsertId(); err == nil {
@@ -987,8 +918,8 @@ func (systems *Systems) Write(db *Database) error {
 			}
 
 		} else {
-			query = fmt.Sprintf(`UPDATE "systems" SET "autoPopulate" = %t, "blacklists" = '%s', "delay" = %d, "label" = '%s', "order" = %d, "systemRef" = %d, "type" = '%s', "preferredApiKeyId" = %s, "noAudioAlertsEnabled" = %t, "noAudioThresholdMinutes" = %d, "alertsEnabled" = %t, "autoPopulateAlertsEnabled" = %t, "autoPopulateUnits" = %t, "transcriptionPrompt" = '%s', "autoLearnToneSets" = %t, "autoLearnToneSetsTagIds" = '%s', "autoLearnToneSetsAutoOffDays" = %d, "autoLearnToneSetsExpiresAt" = %d, "bulkToneDetectionEnabled" = %t, "bulkToneDetectionTagIds" = '%s', "bulkToneDetectionAutoOffDays" = %d, "bulkToneDetectionExpiresAt" = %d, "autoLearnUnitAliases" = %t, "autoLearnUnitAliasesTagIds" = '%s', "autoLearnUnitAliasesAutoOffDays" = %d, "autoLearnUnitAliasesExpiresAt" = %d WHERE "systemId" = %d`, system.AutoPopulate, system.Blacklists, system.Delay, escapeQuotes(system.Label), system.Order, system.SystemRef, system.Kind, preferredApiKeyIdSQL, system.NoAudioAlertsEnabled, system.NoAudioThresholdMinutes, system.AlertsEnabled, system.AutoPopulateAlertsEnabled, system.AutoPopulateUnits, escapeQuotes(system.TranscriptionPrompt), system.AutoLearnToneSets, escapeQuotes(serializeBulkToneTagIds(system.AutoLearnToneSetsTagIds)), system.AutoLearnToneSetsAutoOffDays, system.AutoLearnToneSetsExpiresAt, system.BulkToneDetectionEnabled, escapeQuotes(serializeBulkToneTagIds(system.BulkToneDetectionTagIds)), system.BulkToneDetectionAutoOffDays, system.BulkToneDetectionExpiresAt, system.AutoLearnUnitAliases, escapeQuotes(serializeBulkToneTagIds(system.AutoLearnUnitAliasesTagIds)), system.AutoLearnUnitAliasesAutoOffDays, system.AutoLearnUnitAliasesExpiresAt, system.Id)
-			if _, err = tx.Exec(query); err != nil {
+			query, args = columns.update("systems", "systemId", system.Id)
+			if _, err = tx.Exec(query, args...); err != nil {
 				break
 			}
 		}
@@ -1003,7 +934,10 @@ func (systems *Systems) Write(db *Database) error {
 			break
 		}
 
-		if err = system.Talkgroups.WriteTx(tx, system.Id, db.Config.DbType); err != nil {
+		var removed []string
+		removed, err = system.Talkgroups.WriteTx(tx, system.Id, db.Config.DbType)
+		files = append(files, removed...)
+		if err != nil {
 			break
 		}
 
@@ -1023,6 +957,10 @@ func (systems *Systems) Write(db *Database) error {
 		return formatError(err, "")
 	}
 
+	if systems.audioStore != nil {
+		systems.audioStore.Release(files)
+	}
+
 	// Idempotent cleanup: delete user alert preferences for any talkgroup or system
 	// that now has alertsEnabled = false. Runs on every config save but only touches
 	// rows that are actually disabled, so it's safe and self-healing.
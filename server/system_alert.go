@@ -26,7 +26,7 @@ import (
 // SystemAlert represents a system-level alert for administrators
 type SystemAlert struct {
 	Id        uint64 `json:"id"`
-	AlertType string `json:"alertType"` // "transcription_failure", "tone_detection_issue", "service_health", "manual"
+	AlertType string `json:"alertType"` // "transcription_failure", "tone_detection_issue", "service_health", "activity_anomaly", "recording_gap", "alert_rule", "storage_capacity", "weather", "manual"
 	Severity  string `json:"severity"`  // "info", "warning", "error", "critical"
 	Title     string `json:"title"`
 	Message   string `json:"message"`
@@ -38,16 +38,23 @@ type SystemAlert struct {
 
 // SystemAlertData represents the parsed Data field
 type SystemAlertData struct {
-	CallId           uint64 `json:"callId,omitempty"`
-	SystemId         uint64 `json:"systemId,omitempty"`
-	SystemLabel      string `json:"systemLabel,omitempty"`
-	TalkgroupId      uint64 `json:"talkgroupId,omitempty"`
-	Error            string `json:"error,omitempty"`
-	Count            int    `json:"count,omitempty"`
-	Service          string `json:"service,omitempty"`
-	Threshold        int    `json:"threshold,omitempty"`
-	LastCallTime     int64  `json:"lastCallTime,omitempty"`
-	MinutesSinceLast int    `json:"minutesSinceLast,omitempty"`
+	CallId           uint64  `json:"callId,omitempty"`
+	SystemId         uint64  `json:"systemId,omitempty"`
+	SystemLabel      string  `json:"systemLabel,omitempty"`
+	TalkgroupId      uint64  `json:"talkgroupId,omitempty"`
+	TalkgroupLabel   string  `json:"talkgroupLabel,omitempty"`
+	Error            string  `json:"error,omitempty"`
+	Count            int     `json:"count,omitempty"`
+	Service          string  `json:"service,omitempty"`
+	Threshold        int     `json:"threshold,omitempty"`
+	LastCallTime     int64   `json:"lastCallTime,omitempty"`
+	MinutesSinceLast int     `json:"minutesSinceLast,omitempty"`
+	Baseline         float64 `json:"baseline,omitempty"`
+	WeatherAlertId   string  `json:"weatherAlertId,omitempty"`
+	Areas            string  `json:"areas,omitempty"`
+	RuleId           uint    `json:"ruleId,omitempty"`
+	Value            float64 `json:"value,omitempty"`
+	ApikeyId         uint64  `json:"apikeyId,omitempty"`
 }
 
 // CreateSystemAlert creates a new system alert
@@ -66,24 +73,35 @@ func (controller *Controller) CreateSystemAlert(alertType, severity, title, mess
 
 	createdAt := time.Now().UnixMilli()
 
-	var query string
+	columns := queryColumns{}
+	columns.set("alertType", alertType)
+	columns.set("severity", severity)
+	columns.set("title", title)
+	columns.set("message", message)
+	columns.set("data", dataJSON)
+	columns.set("createdAt", createdAt)
 	if createdBy > 0 {
-		query = fmt.Sprintf(`INSERT INTO "systemAlerts" ("alertType", "severity", "title", "message", "data", "createdAt", "createdBy") VALUES ('%s', '%s', '%s', '%s', '%s', %d, %d)`,
-			escapeQuotes(alertType), escapeQuotes(severity), escapeQuotes(title), escapeQuotes(message), escapeQuotes(dataJSON), createdAt, createdBy)
-	} else {
-		query = fmt.Sprintf(`INSERT INTO "systemAlerts" ("alertType", "severity", "title", "message", "data", "createdAt") VALUES ('%s', '%s', '%s', '%s', '%s', %d)`,
-			escapeQuotes(alertType), escapeQuotes(severity), escapeQuotes(title), escapeQuotes(message), escapeQuotes(dataJSON), createdAt)
+		columns.set("createdBy", createdBy)
 	}
 
-	if _, err := controller.Database.Sql.Exec(query); err != nil {
+	query, args := columns.insert("systemAlerts")
+	if _, err := controller.Database.Sql.Exec(query, args...); err != nil {
 		return fmt.Errorf("failed to create system alert: %v", err)
 	}
 
 	controller.Logs.LogEvent(LogLevelInfo, fmt.Sprintf("System alert created: [%s] %s - %s", severity, title, message))
 
+	go controller.dispatchWebhooks(&webhookEvent{
+		Name:        webhookEventSystemAlert,
+		SystemAlert: &SystemAlert{AlertType: alertType, Severity: severity, Title: title, Message: message, Data: dataJSON, CreatedAt: createdAt, CreatedBy: createdBy},
+	})
+
 	// Send push notification to all system admins
 	go controller.SendSystemAlertNotification(title, message, alertType, severity, dataJSON)
 
+	// Page the on-call rotation for critical conditions
+	go controller.IncidentRouting.Trigger(alertType, severity, title, message, data)
+
 	return nil
 }
 
@@ -125,21 +143,28 @@ func (controller *Controller) SendSystemAlertNotification(title, message, alertT
 		return
 	}
 
-	// Determine icon and sound based on severity
-	icon := "🔔"
-	defaultSound := "startup.wav"
+	notificationTitle := fmt.Sprintf("%s System Alert", systemAlertIcon(severity))
+	controller.pushSystemAlert(targetUserIds, notificationTitle, title, message, alertType, targetDescription)
+}
+
+// systemAlertIcon returns the notification icon for a severity.
+func systemAlertIcon(severity string) string {
 	switch severity {
 	case "critical":
-		icon = "🚨"
+		return "🚨"
 	case "error":
-		icon = "❌"
+		return "❌"
 	case "warning":
-		icon = "⚠️"
+		return "⚠️"
 	case "info":
-		icon = "ℹ️"
+		return "ℹ️"
 	}
+	return "🔔"
+}
 
-	notificationTitle := fmt.Sprintf("%s System Alert", icon)
+// pushSystemAlert sends an alert notification to the devices of the given users.
+func (controller *Controller) pushSystemAlert(targetUserIds []uint64, notificationTitle, title, message, alertType, targetDescription string) {
+	defaultSound := "startup.wav"
 
 	// Collect device tokens grouped by platform, preferring FCMToken over Token
 	// This mirrors the logic in sendBatchedPushNotification so iOS and Android
@@ -227,12 +252,12 @@ func (controller *Controller) GetSystemAlerts(limit int, includeDismissed bool)
 
 	var query string
 	if includeDismissed {
-		query = fmt.Sprintf(`SELECT "alertId", "alertType", "severity", "title", "message", "data", "createdAt", COALESCE("createdBy", 0), "dismissed" FROM "systemAlerts" ORDER BY "createdAt" DESC LIMIT %d`, limit)
+		query = `SELECT "alertId", "alertType", "severity", "title", "message", "data", "createdAt", COALESCE("createdBy", 0), "dismissed" FROM "systemAlerts" ORDER BY "createdAt" DESC LIMIT $1`
 	} else {
-		query = fmt.Sprintf(`SELECT "alertId", "alertType", "severity", "title", "message", "data", "createdAt", COALESCE("createdBy", 0), "dismissed" FROM "systemAlerts" WHERE "dismissed" = false ORDER BY "createdAt" DESC LIMIT %d`, limit)
+		query = `SELECT "alertId", "alertType", "severity", "title", "message", "data", "createdAt", COALESCE("createdBy", 0), "dismissed" FROM "systemAlerts" WHERE "dismissed" = false ORDER BY "createdAt" DESC LIMIT $1`
 	}
 
-	rows, err := controller.Database.Sql.Query(query)
+	rows, err := controller.Database.Sql.Query(query, limit)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query system alerts: %v", err)
 	}
@@ -252,8 +277,8 @@ func (controller *Controller) GetSystemAlerts(limit int, includeDismissed bool)
 
 // DismissSystemAlert marks a system alert as dismissed
 func (controller *Controller) DismissSystemAlert(alertId uint64) error {
-	query := fmt.Sprintf(`UPDATE "systemAlerts" SET "dismissed" = true WHERE "alertId" = %d`, alertId)
-	if _, err := controller.Database.Sql.Exec(query); err != nil {
+	query := `UPDATE "systemAlerts" SET "dismissed" = true WHERE "alertId" = $1`
+	if _, err := controller.Database.Sql.Exec(query, alertId); err != nil {
 		return fmt.Errorf("failed to dismiss system alert: %v", err)
 	}
 	return nil
@@ -262,10 +287,19 @@ func (controller *Controller) DismissSystemAlert(alertId uint64) error {
 // DismissAlertsByType bulk-dismisses all undismissed alerts of a given type.
 // Called when an alert-type toggle is turned off so existing alerts clear immediately.
 func (controller *Controller) DismissAlertsByType(alertType string) {
-	query := fmt.Sprintf(`UPDATE "systemAlerts" SET "dismissed" = true WHERE "alertType" = '%s' AND "dismissed" = false`, alertType)
-	if _, err := controller.Database.Sql.Exec(query); err != nil {
+	query := `UPDATE "systemAlerts" SET "dismissed" = true WHERE "alertType" = $1 AND "dismissed" = false`
+	if _, err := controller.Database.Sql.Exec(query, alertType); err != nil {
 		controller.Logs.LogEvent(LogLevelError, fmt.Sprintf("failed to bulk-dismiss %s alerts: %v", alertType, err))
 	}
+	go controller.IncidentRouting.ResolveType(alertType)
+}
+
+// alertDataMatch returns the condition matching alerts whose JSON data holds
+// the id in the given field. The id must be followed by the end of the
+// number, so that system 1 does not match the alerts of system 10.
+func alertDataMatch(args *queryArgs, field string, id uint64) string {
+	prefix := fmt.Sprintf(`%%"%s":%d`, field, id)
+	return fmt.Sprintf(`("data" LIKE %s OR "data" LIKE %s)`, args.add(prefix+",%"), args.add(prefix+"}%"))
 }
 
 // CleanupOldSystemAlerts removes system alerts older than retention days
@@ -300,6 +334,10 @@ func getProviderDisplayName(provider string) string {
 	switch provider {
 	case "whisper-api":
 		return "Whisper API Server"
+	case "whisper-local":
+		return "Local Whisper"
+	case "deepgram":
+		return "Deepgram"
 	case "azure":
 		return "Azure Speech Services"
 	case "google":
@@ -329,10 +367,10 @@ func (controller *Controller) MonitorTranscriptionFailures() {
 	}
 	timeWindowAgo := time.Now().Add(-time.Duration(timeWindowHours) * time.Hour).UnixMilli()
 
-	query := fmt.Sprintf(`SELECT COUNT(*) FROM "calls" WHERE "transcriptionStatus" = 'failed' AND "timestamp" >= %d`, timeWindowAgo)
+	query := `SELECT COUNT(*) FROM "calls" WHERE "transcriptionStatus" = 'failed' AND "timestamp" >= $1`
 
 	var failureCount int
-	if err := controller.Database.Sql.QueryRow(query).Scan(&failureCount); err != nil {
+	if err := controller.Database.Sql.QueryRow(query, timeWindowAgo).Scan(&failureCount); err != nil {
 		controller.Logs.LogEvent(LogLevelError, fmt.Sprintf("failed to check transcription failures: %v", err))
 		return
 	}
@@ -389,6 +427,8 @@ func (controller *Controller) MonitorTranscriptionFailures() {
 				0, // System-generated
 			)
 		}
+	} else {
+		controller.IncidentRouting.Resolve("transcription_failure", 0)
 	}
 }
 
@@ -441,18 +481,18 @@ func (controller *Controller) MonitorToneDetectionIssues() {
 		}
 
 		// Check if this talkgroup has had any calls with tones in the time window
-		checkQuery := fmt.Sprintf(`SELECT COUNT(*) FROM "calls" WHERE "talkgroupId" = %d AND "hasTones" = true AND "timestamp" >= %d`, talkgroupId, timeWindowAgo)
+		checkQuery := `SELECT COUNT(*) FROM "calls" WHERE "talkgroupId" = $1 AND "hasTones" = true AND "timestamp" >= $2`
 
 		var toneCount int
-		if err := controller.Database.Sql.QueryRow(checkQuery).Scan(&toneCount); err != nil {
+		if err := controller.Database.Sql.QueryRow(checkQuery, talkgroupId, timeWindowAgo).Scan(&toneCount); err != nil {
 			continue
 		}
 
 		// Also check if there have been ANY calls on this talkgroup
-		callCountQuery := fmt.Sprintf(`SELECT COUNT(*) FROM "calls" WHERE "talkgroupId" = %d AND "timestamp" >= %d`, talkgroupId, timeWindowAgo)
+		callCountQuery := `SELECT COUNT(*) FROM "calls" WHERE "talkgroupId" = $1 AND "timestamp" >= $2`
 
 		var callCount int
-		if err := controller.Database.Sql.QueryRow(callCountQuery).Scan(&callCount); err != nil {
+		if err := controller.Database.Sql.QueryRow(callCountQuery, talkgroupId, timeWindowAgo).Scan(&callCount); err != nil {
 			continue
 		}
 
@@ -469,16 +509,16 @@ func (controller *Controller) MonitorToneDetectionIssues() {
 				repeatMinutes = 60 // Default: 60 minutes
 			}
 
-		checkAlertQuery := fmt.Sprintf(`
+			args := queryArgs{}
+			checkAlertQuery := `
 			SELECT MAX("createdAt") FROM "systemAlerts" 
 			WHERE "alertType" = 'tone_detection_issue' 
-				AND "data" LIKE '%%"talkgroupId":%d%%'
-				AND "dismissed" = false
-		`, talkgroupId)
+				AND ` + alertDataMatch(&args, "talkgroupId", uint64(talkgroupId)) + `
+				AND "dismissed" = false`
 
 			var lastAlertTime sql.NullInt64
 			shouldCreateAlert := true
-			if err := controller.Database.Sql.QueryRow(checkAlertQuery).Scan(&lastAlertTime); err == nil && lastAlertTime.Valid {
+			if err := controller.Database.Sql.QueryRow(checkAlertQuery, args...).Scan(&lastAlertTime); err == nil && lastAlertTime.Valid {
 				lastAlertTimeObj := time.UnixMilli(lastAlertTime.Int64)
 				minutesSinceLastAlert := int(time.Since(lastAlertTimeObj).Minutes())
 				// Only create new alert if last one is older than repeat interval
@@ -561,17 +601,17 @@ func (controller *Controller) MonitorNoAudioForSystem(systemId uint64, systemLab
 		}
 
 		// Check if we already have a recent alert for this system
-		checkAlertQuery := fmt.Sprintf(`
+		args := queryArgs{}
+		checkAlertQuery := `
 			SELECT MAX("createdAt") FROM "systemAlerts" 
 			WHERE "alertType" = 'no_audio' 
-				AND "data" LIKE '%%"systemId":%d%%'
-				AND "dismissed" = false
-		`, systemId)
+				AND ` + alertDataMatch(&args, "systemId", uint64(systemId)) + `
+				AND "dismissed" = false`
 
 		var lastAlertTime sql.NullInt64
 		shouldCreateAlert := true
 		repeatThreshold := currentTime.Add(-time.Duration(repeatMinutes) * time.Minute).UnixMilli()
-		if err := controller.Database.Sql.QueryRow(checkAlertQuery).Scan(&lastAlertTime); err == nil && lastAlertTime.Valid {
+		if err := controller.Database.Sql.QueryRow(checkAlertQuery, args...).Scan(&lastAlertTime); err == nil && lastAlertTime.Valid {
 			// Only create new alert if last one is older than repeat interval
 			if lastAlertTime.Int64 > repeatThreshold {
 				shouldCreateAlert = false
@@ -584,14 +624,14 @@ func (controller *Controller) MonitorNoAudioForSystem(systemId uint64, systemLab
 		if shouldCreateAlert {
 		// Dismiss any existing no-audio alerts for this system before creating new one
 		// This keeps only the latest alert instead of accumulating them
-		dismissQuery := fmt.Sprintf(`
+		dismissArgs := queryArgs{}
+		dismissQuery := `
 			UPDATE "systemAlerts" 
 			SET "dismissed" = true 
 			WHERE "alertType" = 'no_audio' 
-				AND "data" LIKE '%%"systemId":%d%%'
-				AND "dismissed" = false
-		`, systemId)
-			if _, err := controller.Database.Sql.Exec(dismissQuery); err != nil {
+				AND ` + alertDataMatch(&dismissArgs, "systemId", uint64(systemId)) + `
+				AND "dismissed" = false`
+			if _, err := controller.Database.Sql.Exec(dismissQuery, dismissArgs...); err != nil {
 				controller.Logs.LogEvent(LogLevelError, fmt.Sprintf("failed to dismiss old no-audio alerts for system %d: %v", systemId, err))
 			}
 
@@ -632,6 +672,7 @@ func (controller *Controller) MonitorNoAudioForSystem(systemId uint64, systemLab
 	} else {
 		controller.Logs.LogEvent(LogLevelInfo, fmt.Sprintf("no-audio check OK: system '%s' (ID: %d) within threshold - %d minutes since last call (threshold: %d minutes)", 
 			systemLabel, systemId, int(timeSinceLastCall.Minutes()), thresholdMinutes))
+		controller.IncidentRouting.Resolve("no_audio", uint64(systemId))
 	}
 }
 
@@ -795,6 +836,36 @@ func (controller *Controller) StartSystemHealthMonitoring() {
 		}
 	}()
 
+	// Talkgroup activity is checked more often than hourly so a surge or a
+	// recording gap is reported while it is still happening
+	go func() {
+		ticker := time.NewTicker(activityAnomalyCheckInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				controller.MonitorActivityAnomalies()
+				controller.MonitorRecordingGaps()
+			case <-controller.healthMonitorStop:
+				return
+			}
+		}
+	}()
+
+	// Admin-defined alert rules
+	go func() {
+		ticker := time.NewTicker(alertRuleCheckInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				controller.MonitorAlertRules()
+			case <-controller.healthMonitorStop:
+				return
+			}
+		}
+	}()
+
 	// Start per-system no-audio monitoring with individual timers
 	go controller.StartNoAudioMonitoringForAllSystems()
 
@@ -0,0 +1,303 @@
+// Copyright (C) 2026 Thinline Dynamic Solutions
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// IncidentRoutingConfig pages an on-call rotation through PagerDuty and/or
+// Opsgenie when a critical system alert is raised. Each alert type and system
+// pair is one incident: repeated alerts for it update the open incident
+// instead of paging again, and it is resolved when the condition clears.
+type IncidentRoutingConfig struct {
+	Enabled             bool     `json:"enabled"`
+	PagerDutyRoutingKey string   `json:"pagerDutyRoutingKey,omitempty"` // Events API v2 integration key
+	OpsgenieAPIKey      string   `json:"opsgenieApiKey,omitempty"`
+	OpsgenieURL         string   `json:"opsgenieUrl,omitempty"` // default https://api.opsgenie.com, https://api.eu.opsgenie.com for EU accounts
+	AlertTypes          []string `json:"alertTypes,omitempty"`  // default no_audio and transcription_failure
+	Source              string   `json:"source,omitempty"`      // default the branding, or ThinLine Radio
+}
+
+const (
+	incidentDefaultOpsgenieURL = "https://api.opsgenie.com"
+	incidentTimeout            = 10 * time.Second
+)
+
+// pagerDutyEventsURL is a variable so tests can point it at a local server.
+var pagerDutyEventsURL = "https://events.pagerduty.com/v2/enqueue"
+
+// incidentDefaultAlertTypes are the alerts that mean scanners are not being
+// heard or transcribed at all.
+var incidentDefaultAlertTypes = []string{"no_audio", "transcription_failure"}
+
+// IncidentRouter sends system alerts to the configured incident services and
+// remembers which incidents it opened so recoveries resolve only those.
+type IncidentRouter struct {
+	controller *Controller
+	mutex      sync.Mutex
+	open       map[string]bool
+}
+
+func NewIncidentRouter(controller *Controller) *IncidentRouter {
+	return &IncidentRouter{controller: controller, open: map[string]bool{}}
+}
+
+// routes reports whether an alert pages. Listed alert types page, and so does
+// anything raised as critical.
+func (config IncidentRoutingConfig) routes(alertType string, severity string) bool {
+	if !config.Enabled || (config.PagerDutyRoutingKey == "" && config.OpsgenieAPIKey == "") {
+		return false
+	}
+	if severity == "critical" {
+		return true
+	}
+	types := config.AlertTypes
+	if len(types) == 0 {
+		types = incidentDefaultAlertTypes
+	}
+	for _, t := range types {
+		if t == alertType {
+			return true
+		}
+	}
+	return false
+}
+
+// incidentDedupKey is shared by every alert for the same condition on the
+// same system, so both services fold repeats into one incident.
+func incidentDedupKey(alertType string, systemId uint64) string {
+	if systemId == 0 {
+		return fmt.Sprintf("thinline:%s:global", alertType)
+	}
+	return fmt.Sprintf("thinline:%s:%d", alertType, systemId)
+}
+
+// opsgeniePriority maps an alert severity to an Opsgenie priority.
+func opsgeniePriority(severity string) string {
+	switch severity {
+	case "critical":
+		return "P1"
+	case "error":
+		return "P2"
+	case "warning":
+		return "P3"
+	default:
+		return "P5"
+	}
+}
+
+// pagerDutySeverity keeps the severities PagerDuty accepts.
+func pagerDutySeverity(severity string) string {
+	switch severity {
+	case "critical", "error", "warning":
+		return severity
+	default:
+		return "info"
+	}
+}
+
+func (router *IncidentRouter) source() string {
+	if s := router.controller.Options.IncidentRoutingConfig.Source; s != "" {
+		return s
+	}
+	if s := router.controller.Options.Branding; s != "" {
+		return s
+	}
+	return "ThinLine Radio"
+}
+
+// Trigger opens or updates the incident for the alert.
+func (router *IncidentRouter) Trigger(alertType, severity, title, message string, data *SystemAlertData) {
+	config := router.controller.Options.IncidentRoutingConfig
+	if !config.routes(alertType, severity) {
+		return
+	}
+
+	var systemId uint64
+	details := map[string]any{"alertType": alertType, "message": message}
+	if data != nil {
+		systemId = data.SystemId
+		if data.SystemLabel != "" {
+			details["system"] = data.SystemLabel
+		}
+		if data.Service != "" {
+			details["service"] = data.Service
+		}
+		if data.Count > 0 {
+			details["count"] = data.Count
+		}
+		if data.MinutesSinceLast > 0 {
+			details["minutesSinceLast"] = data.MinutesSinceLast
+		}
+	}
+	// Each alert rule is its own incident
+	keyType := alertType
+	if data != nil && data.RuleId > 0 {
+		keyType = fmt.Sprintf("%s:%d", alertType, data.RuleId)
+	}
+	key := incidentDedupKey(keyType, systemId)
+	summary := title
+	if message != "" {
+		summary = fmt.Sprintf("%s: %s", title, message)
+	}
+	// PagerDuty rejects summaries over 1024 characters
+	if len(summary) > 1024 {
+		summary = summary[:1024]
+	}
+
+	router.mutex.Lock()
+	router.open[key] = true
+	router.mutex.Unlock()
+
+	if config.PagerDutyRoutingKey != "" {
+		err := router.post(pagerDutyEventsURL, "", map[string]any{
+			"routing_key":  config.PagerDutyRoutingKey,
+			"event_action": "trigger",
+			"dedup_key":    key,
+			"payload": map[string]any{
+				"summary":        summary,
+				"source":         router.source(),
+				"severity":       pagerDutySeverity(severity),
+				"component":      alertType,
+				"custom_details": details,
+			},
+		})
+		if err != nil {
+			router.controller.Logs.LogEvent(LogLevelError, fmt.Sprintf("incident routing: PagerDuty trigger %s: %v", key, err))
+		}
+	}
+
+	if config.OpsgenieAPIKey != "" {
+		detailStrings := map[string]string{}
+		for k, v := range details {
+			detailStrings[k] = fmt.Sprint(v)
+		}
+		// Opsgenie rejects messages over 130 characters
+		ogMessage := []rune(title)
+		if len(ogMessage) > 130 {
+			ogMessage = ogMessage[:130]
+		}
+		err := router.post(router.opsgenieURL()+"/v2/alerts", config.OpsgenieAPIKey, map[string]any{
+			"message":     string(ogMessage),
+			"alias":       key,
+			"description": message,
+			"priority":    opsgeniePriority(severity),
+			"source":      router.source(),
+			"tags":        []string{"thinline", alertType},
+			"details":     detailStrings,
+		})
+		if err != nil {
+			router.controller.Logs.LogEvent(LogLevelError, fmt.Sprintf("incident routing: Opsgenie alert %s: %v", key, err))
+		}
+	}
+}
+
+// Resolve closes the incident for the alert type and system if this server
+// opened it. It is called on every healthy check, so it does nothing when no
+// incident is open.
+func (router *IncidentRouter) Resolve(alertType string, systemId uint64) {
+	router.resolveKeys([]string{incidentDedupKey(alertType, systemId)})
+}
+
+// ResolveType closes every open incident of the alert type, for when its
+// alerts are dismissed in bulk.
+func (router *IncidentRouter) ResolveType(alertType string) {
+	prefix := fmt.Sprintf("thinline:%s:", alertType)
+	var keys []string
+	router.mutex.Lock()
+	for key := range router.open {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	router.mutex.Unlock()
+	router.resolveKeys(keys)
+}
+
+func (router *IncidentRouter) resolveKeys(keys []string) {
+	config := router.controller.Options.IncidentRoutingConfig
+
+	for _, key := range keys {
+		router.mutex.Lock()
+		wasOpen := router.open[key]
+		delete(router.open, key)
+		router.mutex.Unlock()
+		if !wasOpen {
+			continue
+		}
+
+		if config.PagerDutyRoutingKey != "" {
+			err := router.post(pagerDutyEventsURL, "", map[string]any{
+				"routing_key":  config.PagerDutyRoutingKey,
+				"event_action": "resolve",
+				"dedup_key":    key,
+			})
+			if err != nil {
+				router.controller.Logs.LogEvent(LogLevelError, fmt.Sprintf("incident routing: PagerDuty resolve %s: %v", key, err))
+			}
+		}
+
+		if config.OpsgenieAPIKey != "" {
+			u := fmt.Sprintf("%s/v2/alerts/%s/close?identifierType=alias", router.opsgenieURL(), url.PathEscape(key))
+			if err := router.post(u, config.OpsgenieAPIKey, map[string]any{"source": router.source(), "note": "Condition cleared"}); err != nil {
+				router.controller.Logs.LogEvent(LogLevelError, fmt.Sprintf("incident routing: Opsgenie close %s: %v", key, err))
+			}
+		}
+	}
+}
+
+func (router *IncidentRouter) opsgenieURL() string {
+	if u := router.controller.Options.IncidentRoutingConfig.OpsgenieURL; u != "" {
+		return strings.TrimRight(u, "/")
+	}
+	return incidentDefaultOpsgenieURL
+}
+
+// post sends a JSON body; genieKey sets the Opsgenie authorization header.
+func (router *IncidentRouter) post(endpoint string, genieKey string, body any) error {
+	b, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, endpoint, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if genieKey != "" {
+		req.Header.Set("Authorization", "GenieKey "+genieKey)
+	}
+
+	client := &http.Client{Timeout: incidentTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("status %s", resp.Status)
+	}
+	return nil
+}
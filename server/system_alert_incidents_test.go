@@ -0,0 +1,112 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+func TestIncidentRoutingConfigRoutes(t *testing.T) {
+	config := IncidentRoutingConfig{Enabled: true, PagerDutyRoutingKey: "key"}
+	if !config.routes("no_audio", "warning") || !config.routes("transcription_failure", "warning") {
+		t.Fatalf("default alert types not routed")
+	}
+	if config.routes("activity_anomaly", "info") {
+		t.Fatalf("activity_anomaly routed by default")
+	}
+	if !config.routes("manual", "critical") {
+		t.Fatalf("critical alert not routed")
+	}
+	config.AlertTypes = []string{"activity_anomaly"}
+	if config.routes("no_audio", "warning") || !config.routes("activity_anomaly", "info") {
+		t.Fatalf("alertTypes not honoured")
+	}
+	if (IncidentRoutingConfig{Enabled: true}).routes("manual", "critical") {
+		t.Fatalf("routed without any integration key")
+	}
+}
+
+func TestIncidentRouterTriggerAndResolve(t *testing.T) {
+	type request struct {
+		path string
+		auth string
+		body map[string]any
+	}
+	var (
+		mutex    sync.Mutex
+		requests []request
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		json.NewDecoder(r.Body).Decode(&body)
+		mutex.Lock()
+		requests = append(requests, request{path: r.URL.RequestURI(), auth: r.Header.Get("Authorization"), body: body})
+		mutex.Unlock()
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	saved := pagerDutyEventsURL
+	pagerDutyEventsURL = server.URL + "/v2/enqueue"
+	defer func() { pagerDutyEventsURL = saved }()
+
+	controller := &Controller{Options: &Options{IncidentRoutingConfig: IncidentRoutingConfig{
+		Enabled:             true,
+		PagerDutyRoutingKey: "pd-key",
+		OpsgenieAPIKey:      "og-key",
+		OpsgenieURL:         server.URL + "/",
+		Source:              "county",
+	}}}
+	router := NewIncidentRouter(controller)
+
+	data := &SystemAlertData{SystemId: 7, SystemLabel: "County", MinutesSinceLast: 45}
+	router.Trigger("no_audio", "warning", "No Audio Received", "System 'County' has not received audio", data)
+	router.Trigger("no_audio", "warning", "No Audio Received", "System 'County' has not received audio", data)
+	if len(requests) != 4 {
+		t.Fatalf("got %d requests after two triggers, want 4", len(requests))
+	}
+	pd, og := requests[0], requests[1]
+	if pd.path != "/v2/enqueue" || pd.body["routing_key"] != "pd-key" || pd.body["event_action"] != "trigger" || pd.body["dedup_key"] != "thinline:no_audio:7" {
+		t.Fatalf("unexpected PagerDuty trigger: %s %v", pd.path, pd.body)
+	}
+	payload, _ := pd.body["payload"].(map[string]any)
+	if payload["severity"] != "warning" || payload["source"] != "county" {
+		t.Fatalf("unexpected PagerDuty payload: %v", payload)
+	}
+	if og.path != "/v2/alerts" || og.auth != "GenieKey og-key" || og.body["alias"] != "thinline:no_audio:7" || og.body["priority"] != "P3" {
+		t.Fatalf("unexpected Opsgenie alert: %s %s %v", og.path, og.auth, og.body)
+	}
+	if requests[2].body["dedup_key"] != pd.body["dedup_key"] || requests[3].body["alias"] != og.body["alias"] {
+		t.Fatalf("repeat alert used a different dedup key")
+	}
+
+	router.Resolve("no_audio", 8)
+	if len(requests) != 4 {
+		t.Fatalf("resolved an incident that was never opened")
+	}
+	router.Resolve("no_audio", 7)
+	if len(requests) != 6 {
+		t.Fatalf("got %d requests after resolve, want 6", len(requests))
+	}
+	if requests[4].body["event_action"] != "resolve" || requests[4].body["dedup_key"] != "thinline:no_audio:7" {
+		t.Fatalf("unexpected PagerDuty resolve: %v", requests[4].body)
+	}
+	if requests[5].path != "/v2/alerts/thinline:no_audio:7/close?identifierType=alias" {
+		t.Fatalf("unexpected Opsgenie close: %s", requests[5].path)
+	}
+	router.Resolve("no_audio", 7)
+	if len(requests) != 6 {
+		t.Fatalf("resolved the same incident twice")
+	}
+}
+
+func TestIncidentDedupKey(t *testing.T) {
+	if key := incidentDedupKey("transcription_failure", 0); key != "thinline:transcription_failure:global" {
+		t.Fatalf("got %q", key)
+	}
+	if key := incidentDedupKey("no_audio", 3); key != "thinline:no_audio:3" {
+		t.Fatalf("got %q", key)
+	}
+}
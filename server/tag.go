@@ -18,9 +18,7 @@ package main
 import (
 	"database/sql"
 	"encoding/json"
-	"fmt"
 	"sort"
-	"strings"
 	"sync"
 )
 
@@ -322,13 +320,11 @@ func (tags *Tags) Write(db *Database) error {
 	}
 
 	if len(tagIds) > 0 {
-		if b, err := json.Marshal(tagIds); err == nil {
-			in := strings.ReplaceAll(strings.ReplaceAll(string(b), "[", "("), "]", ")")
-			query = fmt.Sprintf(`DELETE FROM "tags" WHERE "tagId" IN %s`, in)
-			if _, err = tx.Exec(query); err != nil {
-				tx.Rollback()
-				return formatError(err, query)
-			}
+		args := queryArgs{}
+		query = `DELETE FROM "tags" WHERE "tagId" IN ` + args.in(tagIds)
+		if _, err = tx.Exec(query, args...); err != nil {
+			tx.Rollback()
+			return formatError(err, query)
 		}
 	}
 
@@ -337,14 +333,14 @@ func (tags *Tags) Write(db *Database) error {
 		var existingId uint64
 
 		if tag.Id > 0 {
-			query = fmt.Sprintf(`SELECT COUNT(*) FROM "tags" WHERE "tagId" = %d`, tag.Id)
-			if err = tx.QueryRow(query).Scan(&count); err != nil {
+			query = `SELECT COUNT(*) FROM "tags" WHERE "tagId" = $1`
+			if err = tx.QueryRow(query, tag.Id).Scan(&count); err != nil {
 				break
 			}
 		} else {
 			// Check if a tag with this label already exists (to prevent duplicates)
-			query = fmt.Sprintf(`SELECT "tagId" FROM "tags" WHERE "label" = '%s' LIMIT 1`, escapeQuotes(tag.Label))
-			err = tx.QueryRow(query).Scan(&existingId)
+			query = `SELECT "tagId" FROM "tags" WHERE "label" = $1 LIMIT 1`
+			err = tx.QueryRow(query, tag.Label).Scan(&existingId)
 			if err != nil && err != sql.ErrNoRows {
 				// Real error (not just "no rows")
 				break
@@ -359,20 +355,20 @@ func (tags *Tags) Write(db *Database) error {
 		if count == 0 {
 			if tag.Id > 0 {
 				// Preserve the explicit ID when inserting.
-				query = fmt.Sprintf(`INSERT INTO "tags" ("tagId", "label", "order", "color") VALUES (%d, '%s', %d, '%s')`, tag.Id, escapeQuotes(tag.Label), tag.Order, escapeQuotes(tag.Color))
-				if _, err = tx.Exec(query); err != nil {
+				query = `INSERT INTO "tags" ("tagId", "label", "order", "color") VALUES ($1, $2, $3, $4)`
+				if _, err = tx.Exec(query, tag.Id, tag.Label, tag.Order, tag.Color); err != nil {
 					break
 				}
 			} else {
 				// Let the database assign an auto-increment ID and immediately capture it
 				// so the in-memory tag pointer gets the real Id before Write() returns.
-				query = fmt.Sprintf(`INSERT INTO "tags" ("label", "order", "color") VALUES ('%s', %d, '%s')`, escapeQuotes(tag.Label), tag.Order, escapeQuotes(tag.Color))
+				query = `INSERT INTO "tags" ("label", "order", "color") VALUES ($1, $2, $3)`
 				if db.Config.DbType == DbTypePostgresql {
-					if err = tx.QueryRow(query + ` RETURNING "tagId"`).Scan(&tag.Id); err != nil {
+					if err = tx.QueryRow(query+` RETURNING "tagId"`, tag.Label, tag.Order, tag.Color).Scan(&tag.Id); err != nil {
 						break
 					}
 				} else {
-					if res, err = tx.Exec(query); err != nil {
+					if res, err = tx.Exec(query, tag.Label, tag.Order, tag.Color); err != nil {
 						break
 					}
 					if id, err2 := res.LastInsertId(); err2 == nil {
@@ -381,8 +377,8 @@ func (tags *Tags) Write(db *Database) error {
 				}
 			}
 		} else {
-			query = fmt.Sprintf(`UPDATE "tags" SET "label" = '%s', "order" = %d, "color" = '%s' WHERE "tagId" = %d`, escapeQuotes(tag.Label), tag.Order, escapeQuotes(tag.Color), tag.Id)
-			if _, err = tx.Exec(query); err != nil {
+			query = `UPDATE "tags" SET "label" = $1, "order" = $2, "color" = $3 WHERE "tagId" = $4`
+			if _, err = tx.Exec(query, tag.Label, tag.Order, tag.Color, tag.Id); err != nil {
 				break
 			}
 		}
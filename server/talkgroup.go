@@ -18,7 +18,6 @@ package main
 import (
 	"database/sql"
 	"encoding/json"
-	"fmt"
 	"sort"
 	"strconv"
 	"strings"
@@ -70,6 +69,19 @@ type Talkgroup struct {
 
 	// When true, learn radio unitRef → label mappings on this talkgroup.
 	AutoLearnUnitAliases bool `json:"autoLearnUnitAliases"`
+
+	// Spoken language of the talkgroup ("es", "fr"...). Empty means English.
+	// Transcripts of non-English talkgroups are translated when translation is enabled.
+	TranscriptLanguage string `json:"transcriptLanguage"`
+
+	// Encrypted marks a fully encrypted talkgroup, whose calls are all treated as
+	// encrypted. EncryptedPolicy is applied to encrypted calls, see encrypted_calls.go.
+	Encrypted       bool   `json:"encrypted"`
+	EncryptedPolicy string `json:"encryptedPolicy"`
+
+	// TranscriptionTier is "off", "standard" (empty) or "priority", see
+	// transcription_tiers.go.
+	TranscriptionTier string `json:"transcriptionTier"`
 }
 
 func NewTalkgroup() *Talkgroup {
@@ -220,6 +232,30 @@ func (talkgroup *Talkgroup) FromMap(m map[string]any) *Talkgroup {
 		talkgroup.AutoLearnUnitAliases = v
 	}
 
+	switch v := m["transcriptLanguage"].(type) {
+	case string:
+		talkgroup.TranscriptLanguage = strings.TrimSpace(v)
+	}
+
+	switch v := m["encrypted"].(type) {
+	case bool:
+		talkgroup.Encrypted = v
+	}
+
+	switch v := m["encryptedPolicy"].(type) {
+	case string:
+		if validEncryptedPolicy(v) {
+			talkgroup.EncryptedPolicy = v
+		}
+	}
+
+	switch v := m["transcriptionTier"].(type) {
+	case string:
+		if validTranscriptionTier(v) {
+			talkgroup.TranscriptionTier = v
+		}
+	}
+
 	return talkgroup
 }
 
@@ -277,7 +313,11 @@ func (talkgroup *Talkgroup) MarshalJSON() ([]byte, error) {
 	m["transcriptionPrompt"] = talkgroup.TranscriptionPrompt
 	m["autoLearnToneSets"] = talkgroup.AutoLearnToneSets
 	m["autoLearnUnitAliases"] = talkgroup.AutoLearnUnitAliases
+	m["transcriptLanguage"] = talkgroup.TranscriptLanguage
 	m["alertingTalkgroup"] = talkgroup.AlertingTalkgroup
+	m["encrypted"] = talkgroup.Encrypted
+	m["encryptedPolicy"] = talkgroup.EncryptedPolicy
+	m["transcriptionTier"] = talkgroup.TranscriptionTier
 
 	return json.Marshal(m)
 }
@@ -368,14 +408,9 @@ func (talkgroups *Talkgroups) ReadTx(tx *sql.Tx, systemId uint64, dbType string)
 
 	formatError := errorFormatter("talkgroups", "read")
 
-	if dbType == DbTypePostgresql {
-		query = fmt.Sprintf(`SELECT t."talkgroupId", t."delay", t."frequency", t."label", t."name", t."order", t."tagId", t."talkgroupRef", t."type", t."toneDetectionEnabled", t."toneSets", t."preferredApiKeyId", t."excludeFromPreferredSite", t."toneDownstreamEnabled", t."toneDownstreamURL", t."toneDownstreamAPIKey", t."alertCooldownSeconds", t."linkedVoiceTalkgroupRef", t."linkedVoiceWindowSeconds", t."linkedVoiceMinDurationSeconds", t."alertsEnabled", t."transcriptionPrompt", t."autoLearnToneSets", t."alertingTalkgroup", t."autoLearnUnitAliases", STRING_AGG(CAST(COALESCE(tg."groupId", 0) AS text), ',') FROM "talkgroups" AS t LEFT JOIN "talkgroupGroups" AS tg ON tg."talkgroupId" = t."talkgroupId" WHERE t."systemId" = %d GROUP BY t."talkgroupId", t."preferredApiKeyId", t."excludeFromPreferredSite", t."toneDownstreamEnabled", t."toneDownstreamURL", t."toneDownstreamAPIKey", t."alertCooldownSeconds", t."linkedVoiceTalkgroupRef", t."linkedVoiceWindowSeconds", t."linkedVoiceMinDurationSeconds", t."alertsEnabled", t."transcriptionPrompt", t."autoLearnToneSets", t."alertingTalkgroup", t."autoLearnUnitAliases"`, systemId)
-
-	} else {
-		query = fmt.Sprintf(`SELECT t."talkgroupId", t."delay", t."frequency", t."label", t."name", t."order", t."tagId", t."talkgroupRef", t."type", t."toneDetectionEnabled", t."toneSets", t."preferredApiKeyId", t."excludeFromPreferredSite", t."toneDownstreamEnabled", t."toneDownstreamURL", t."toneDownstreamAPIKey", t."alertCooldownSeconds", t."linkedVoiceTalkgroupRef", t."linkedVoiceWindowSeconds", t."linkedVoiceMinDurationSeconds", t."alertsEnabled", t."transcriptionPrompt", t."autoLearnToneSets", t."alertingTalkgroup", t."autoLearnUnitAliases", GROUP_CONCAT(COALESCE(tg."groupId", 0)) FROM "talkgroups" AS t LEFT JOIN "talkgroupGroups" AS tg ON tg."talkgroupId" = t."talkgroupId" WHERE t."systemId" = %d GROUP BY t."talkgroupId"`, systemId)
-	}
+	query = `SELECT t."talkgroupId", t."delay", t."frequency", t."label", t."name", t."order", t."tagId", t."talkgroupRef", t."type", t."toneDetectionEnabled", t."toneSets", t."preferredApiKeyId", t."excludeFromPreferredSite", t."toneDownstreamEnabled", t."toneDownstreamURL", t."toneDownstreamAPIKey", t."alertCooldownSeconds", t."linkedVoiceTalkgroupRef", t."linkedVoiceWindowSeconds", t."linkedVoiceMinDurationSeconds", t."alertsEnabled", t."transcriptionPrompt", t."autoLearnToneSets", t."alertingTalkgroup", t."autoLearnUnitAliases", t."transcriptLanguage", t."encrypted", t."encryptedPolicy", t."transcriptionTier", STRING_AGG(CAST(COALESCE(tg."groupId", 0) AS text), ',') FROM "talkgroups" AS t LEFT JOIN "talkgroupGroups" AS tg ON tg."talkgroupId" = t."talkgroupId" WHERE t."systemId" = $1 GROUP BY t."talkgroupId", t."preferredApiKeyId", t."excludeFromPreferredSite", t."toneDownstreamEnabled", t."toneDownstreamURL", t."toneDownstreamAPIKey", t."alertCooldownSeconds", t."linkedVoiceTalkgroupRef", t."linkedVoiceWindowSeconds", t."linkedVoiceMinDurationSeconds", t."alertsEnabled", t."transcriptionPrompt", t."autoLearnToneSets", t."alertingTalkgroup", t."autoLearnUnitAliases", t."transcriptLanguage", t."encrypted", t."encryptedPolicy", t."transcriptionTier"`
 
-	if rows, err = tx.Query(query); err != nil {
+	if rows, err = tx.Query(query, systemId); err != nil {
 		return formatError(err, query)
 	}
 
@@ -385,7 +420,7 @@ func (talkgroups *Talkgroups) ReadTx(tx *sql.Tx, systemId uint64, dbType string)
 		var preferredApiKeyUnused sql.NullInt64
 		var excludePreferredUnused bool
 
-		if err = rows.Scan(&talkgroup.Id, &talkgroup.Delay, &talkgroup.Frequency, &talkgroup.Label, &talkgroup.Name, &talkgroup.Order, &talkgroup.TagId, &talkgroup.TalkgroupRef, &talkgroup.Kind, &talkgroup.ToneDetectionEnabled, &toneSetsJson, &preferredApiKeyUnused, &excludePreferredUnused, &talkgroup.ToneDownstreamEnabled, &talkgroup.ToneDownstreamURL, &talkgroup.ToneDownstreamAPIKey, &talkgroup.AlertCooldownSeconds, &talkgroup.LinkedVoiceTalkgroupRef, &talkgroup.LinkedVoiceWindowSeconds, &talkgroup.LinkedVoiceMinDurationSeconds, &talkgroup.AlertsEnabled, &talkgroup.TranscriptionPrompt, &talkgroup.AutoLearnToneSets, &talkgroup.AlertingTalkgroup, &talkgroup.AutoLearnUnitAliases, &groupIds); err != nil {
+		if err = rows.Scan(&talkgroup.Id, &talkgroup.Delay, &talkgroup.Frequency, &talkgroup.Label, &talkgroup.Name, &talkgroup.Order, &talkgroup.TagId, &talkgroup.TalkgroupRef, &talkgroup.Kind, &talkgroup.ToneDetectionEnabled, &toneSetsJson, &preferredApiKeyUnused, &excludePreferredUnused, &talkgroup.ToneDownstreamEnabled, &talkgroup.ToneDownstreamURL, &talkgroup.ToneDownstreamAPIKey, &talkgroup.AlertCooldownSeconds, &talkgroup.LinkedVoiceTalkgroupRef, &talkgroup.LinkedVoiceWindowSeconds, &talkgroup.LinkedVoiceMinDurationSeconds, &talkgroup.AlertsEnabled, &talkgroup.TranscriptionPrompt, &talkgroup.AutoLearnToneSets, &talkgroup.AlertingTalkgroup, &talkgroup.AutoLearnUnitAliases, &talkgroup.TranscriptLanguage, &talkgroup.Encrypted, &talkgroup.EncryptedPolicy, &talkgroup.TranscriptionTier, &groupIds); err != nil {
 			break
 		}
 
@@ -423,13 +458,16 @@ func (talkgroups *Talkgroups) ReadTx(tx *sql.Tx, systemId uint64, dbType string)
 	return nil
 }
 
-func (talkgroups *Talkgroups) WriteTx(tx *sql.Tx, systemId uint64, dbType string) error {
+// WriteTx saves the talkgroups of a system and returns the audio locations of
+// the calls removed with the talkgroups, to release once tx is committed.
+func (talkgroups *Talkgroups) WriteTx(tx *sql.Tx, systemId uint64, dbType string) ([]string, error) {
 	var (
 		err   error
 		query string
 		res   sql.Result
 		rows  *sql.Rows
 
+		files             []string
 		talkgroupGroupIds = []uint64{}
 		talkgroupIds      = []uint64{}
 	)
@@ -439,9 +477,9 @@ func (talkgroups *Talkgroups) WriteTx(tx *sql.Tx, systemId uint64, dbType string
 
 	formatError := errorFormatter("talkgroups", "writetx")
 
-	query = fmt.Sprintf(`SELECT "talkgroupId" FROM "talkgroups" WHERE "systemId" = %d`, systemId)
-	if rows, err = tx.Query(query); err != nil {
-		return formatError(err, query)
+	query = `SELECT "talkgroupId" FROM "talkgroups" WHERE "systemId" = $1`
+	if rows, err = tx.Query(query, systemId); err != nil {
+		return files, formatError(err, query)
 	}
 
 	for rows.Next() {
@@ -464,22 +502,24 @@ func (talkgroups *Talkgroups) WriteTx(tx *sql.Tx, systemId uint64, dbType string
 	rows.Close()
 
 	if err != nil {
-		return formatError(err, "")
+		return files, formatError(err, "")
 	}
 
 	if len(talkgroupIds) > 0 {
-		if b, err := json.Marshal(talkgroupIds); err == nil {
-			in := strings.ReplaceAll(strings.ReplaceAll(string(b), "[", "("), "]", ")")
+		args := queryArgs{}
+		in := args.in(talkgroupIds)
 
-			query = fmt.Sprintf(`DELETE FROM "talkgroups" WHERE "talkgroupId" IN %s`, in)
-			if _, err = tx.Exec(query); err != nil {
-				return formatError(err, query)
-			}
+		// Calls go with their talkgroup through ON DELETE CASCADE
+		files = audioFileLocations(tx, `"talkgroupId" IN `+in, args...)
 
-			query = fmt.Sprintf(`DELETE FROM "talkgroupGroups" WHERE "talkgroupId" IN %s`, in)
-			if _, err = tx.Exec(query); err != nil {
-				return formatError(err, query)
-			}
+		query = `DELETE FROM "talkgroups" WHERE "talkgroupId" IN ` + in
+		if _, err = tx.Exec(query, args...); err != nil {
+			return files, formatError(err, query)
+		}
+
+		query = `DELETE FROM "talkgroupGroups" WHERE "talkgroupId" IN ` + in
+		if _, err = tx.Exec(query, args...); err != nil {
+			return files, formatError(err, query)
 		}
 	}
 
@@ -487,8 +527,8 @@ func (talkgroups *Talkgroups) WriteTx(tx *sql.Tx, systemId uint64, dbType string
 		var count uint
 
 		if talkgroup.Id > 0 {
-			query = fmt.Sprintf(`SELECT COUNT(*) FROM "talkgroups" WHERE "talkgroupId" = %d`, talkgroup.Id)
-			if err = tx.QueryRow(query).Scan(&count); err != nil {
+			query = `SELECT COUNT(*) FROM "talkgroups" WHERE "talkgroupId" = $1`
+			if err = tx.QueryRow(query, talkgroup.Id).Scan(&count); err != nil {
 				break
 			}
 		}
@@ -497,8 +537,8 @@ func (talkgroups *Talkgroups) WriteTx(tx *sql.Tx, systemId uint64, dbType string
 		var tagExists uint
 		var validTagId uint64 = talkgroup.TagId
 		if talkgroup.TagId > 0 {
-			query = fmt.Sprintf(`SELECT COUNT(*) FROM "tags" WHERE "tagId" = %d`, talkgroup.TagId)
-			if err = tx.QueryRow(query).Scan(&tagExists); err != nil {
+			query = `SELECT COUNT(*) FROM "tags" WHERE "tagId" = $1`
+			if err = tx.QueryRow(query, talkgroup.TagId).Scan(&tagExists); err != nil {
 				break
 			}
 			if tagExists == 0 {
@@ -547,26 +587,56 @@ func (talkgroups *Talkgroups) WriteTx(tx *sql.Tx, systemId uint64, dbType string
 			}
 		}
 
-		preferredApiKeyIdSQL := "NULL"
+		columns := queryColumns{}
+		columns.set("delay", talkgroup.Delay)
+		columns.set("frequency", talkgroup.Frequency)
+		columns.set("label", talkgroup.Label)
+		columns.set("name", talkgroup.Name)
+		columns.set("order", talkgroup.Order)
+		columns.set("systemId", systemId)
+		columns.set("tagId", validTagId)
+		columns.set("talkgroupRef", talkgroup.TalkgroupRef)
+		columns.set("type", talkgroup.Kind)
+		columns.set("toneDetectionEnabled", talkgroup.ToneDetectionEnabled)
+		columns.set("toneSets", toneSetsJson)
+		columns.set("preferredApiKeyId", nil)
+		columns.set("excludeFromPreferredSite", false)
+		columns.set("toneDownstreamEnabled", talkgroup.ToneDownstreamEnabled)
+		columns.set("toneDownstreamURL", talkgroup.ToneDownstreamURL)
+		columns.set("toneDownstreamAPIKey", talkgroup.ToneDownstreamAPIKey)
+		columns.set("alertCooldownSeconds", talkgroup.AlertCooldownSeconds)
+		columns.set("linkedVoiceTalkgroupRef", talkgroup.LinkedVoiceTalkgroupRef)
+		columns.set("linkedVoiceWindowSeconds", talkgroup.LinkedVoiceWindowSeconds)
+		columns.set("linkedVoiceMinDurationSeconds", talkgroup.LinkedVoiceMinDurationSeconds)
+		columns.set("alertsEnabled", talkgroup.AlertsEnabled)
+		columns.set("transcriptionPrompt", talkgroup.TranscriptionPrompt)
+		columns.set("autoLearnToneSets", talkgroup.AutoLearnToneSets)
+		columns.set("alertingTalkgroup", talkgroup.AlertingTalkgroup)
+		columns.set("autoLearnUnitAliases", talkgroup.AutoLearnUnitAliases)
+		columns.set("transcriptLanguage", talkgroup.TranscriptLanguage)
+		columns.set("encrypted", talkgroup.Encrypted)
+		columns.set("encryptedPolicy", talkgroup.EncryptedPolicy)
+		columns.set("transcriptionTier", talkgroup.TranscriptionTier)
+
+		var args []any
 
 		if count == 0 {
 			if talkgroup.Id > 0 {
 				// Preserve the explicit ID when inserting
-				query = fmt.Sprintf(`INSERT INTO "talkgroups" ("talkgroupId", "delay", "frequency", "label", "name", "order", "systemId", "tagId", "talkgroupRef", "type", "toneDetectionEnabled", "toneSets", "preferredApiKeyId", "excludeFromPreferredSite", "toneDownstreamEnabled", "toneDownstreamURL", "toneDownstreamAPIKey", "alertCooldownSeconds", "linkedVoiceTalkgroupRef", "linkedVoiceWindowSeconds", "linkedVoiceMinDurationSeconds", "alertsEnabled", "transcriptionPrompt", "autoLearnToneSets", "alertingTalkgroup", "autoLearnUnitAliases") VALUES (%d, %d, %d, '%s', '%s', %d, %d, %d, %d, '%s', %t, '%s', %s, %t, %t, '%s', '%s', %d, %d, %d, %d, %t, '%s', %t, %t, %t)`, talkgroup.Id, talkgroup.Delay, talkgroup.Frequency, escapeQuotes(talkgroup.Label), escapeQuotes(talkgroup.Name), talkgroup.Order, systemId, validTagId, talkgroup.TalkgroupRef, talkgroup.Kind, talkgroup.ToneDetectionEnabled, escapeQuotes(toneSetsJson), preferredApiKeyIdSQL, false, talkgroup.ToneDownstreamEnabled, escapeQuotes(talkgroup.ToneDownstreamURL), escapeQuotes(talkgroup.ToneDownstreamAPIKey), talkgroup.AlertCooldownSeconds, talkgroup.LinkedVoiceTalkgroupRef, talkgroup.LinkedVoiceWindowSeconds, talkgroup.LinkedVoiceMinDurationSeconds, talkgroup.AlertsEnabled, escapeQuotes(talkgroup.TranscriptionPrompt), talkgroup.AutoLearnToneSets, talkgroup.AlertingTalkgroup, talkgroup.AutoLearnUnitAliases)
-			} else {
-				// Let database assign auto-increment ID
-				query = fmt.Sprintf(`INSERT INTO "talkgroups" ("delay", "frequency", "label", "name", "order", "systemId", "tagId", "talkgroupRef", "type", "toneDetectionEnabled", "toneSets", "preferredApiKeyId", "excludeFromPreferredSite", "toneDownstreamEnabled", "toneDownstreamURL", "toneDownstreamAPIKey", "alertCooldownSeconds", "linkedVoiceTalkgroupRef", "linkedVoiceWindowSeconds", "linkedVoiceMinDurationSeconds", "alertsEnabled", "transcriptionPrompt", "autoLearnToneSets", "alertingTalkgroup", "autoLearnUnitAliases") VALUES (%d, %d, '%s', '%s', %d, %d, %d, %d, '%s', %t, '%s', %s, %t, %t, '%s', '%s', %d, %d, %d, %d, %t, '%s', %t, %t, %t)`, talkgroup.Delay, talkgroup.Frequency, escapeQuotes(talkgroup.Label), escapeQuotes(talkgroup.Name), talkgroup.Order, systemId, validTagId, talkgroup.TalkgroupRef, talkgroup.Kind, talkgroup.ToneDetectionEnabled, escapeQuotes(toneSetsJson), preferredApiKeyIdSQL, false, talkgroup.ToneDownstreamEnabled, escapeQuotes(talkgroup.ToneDownstreamURL), escapeQuotes(talkgroup.ToneDownstreamAPIKey), talkgroup.AlertCooldownSeconds, talkgroup.LinkedVoiceTalkgroupRef, talkgroup.LinkedVoiceWindowSeconds, talkgroup.LinkedVoiceMinDurationSeconds, talkgroup.AlertsEnabled, escapeQuotes(talkgroup.TranscriptionPrompt), talkgroup.AutoLearnToneSets, talkgroup.AlertingTalkgroup, talkgroup.AutoLearnUnitAliases)
+				columns.set("talkgroupId", talkgroup.Id)
 			}
+			// Otherwise let database assign auto-increment ID
+			query, args = columns.insert("talkgroups")
 
 			if dbType == DbTypePostgresql {
 				query = query + ` RETURNING "talkgroupId"`
 
-				if err = tx.QueryRow(query).Scan(&talkgroup.Id); err != nil {
+				if err = tx.QueryRow(query, args...).Scan(&talkgroup.Id); err != nil {
 					break
 				}
 
 			} else {
-				if res, err = tx.Exec(query); err == nil {
+				if res, err = tx.Exec(query, args...); err == nil {
 					if id, err := res.LastInsertId(); err == nil {
 						talkgroup.Id = uint64(id)
 					}
@@ -576,22 +646,14 @@ func (talkgroups *Talkgroups) WriteTx(tx *sql.Tx, systemId uint64, dbType string
 			}
 
 		} else {
-			// Serialize tone sets (already done above, but we're in else block so need to recalculate)
-			toneSetsJson := "[]"
-			if len(talkgroup.ToneSets) > 0 {
-				if json, err := SerializeToneSets(talkgroup.ToneSets); err == nil {
-					toneSetsJson = json
-				}
-			}
-			// preferredApiKeyIdSQL is already calculated above
-			query = fmt.Sprintf(`UPDATE "talkgroups" SET "delay" = %d, "frequency" = %d, "label" = '%s', "name" = '%s', "order" = %d, "tagId" = %d, "talkgroupRef" = %d, "type" = '%s', "toneDetectionEnabled" = %t, "toneSets" = '%s', "preferredApiKeyId" = %s, "excludeFromPreferredSite" = %t, "toneDownstreamEnabled" = %t, "toneDownstreamURL" = '%s', "toneDownstreamAPIKey" = '%s', "alertCooldownSeconds" = %d, "linkedVoiceTalkgroupRef" = %d, "linkedVoiceWindowSeconds" = %d, "linkedVoiceMinDurationSeconds" = %d, "alertsEnabled" = %t, "transcriptionPrompt" = '%s', "autoLearnToneSets" = %t, "alertingTalkgroup" = %t, "autoLearnUnitAliases" = %t WHERE "talkgroupId" = %d`, talkgroup.Delay, talkgroup.Frequency, escapeQuotes(talkgroup.Label), escapeQuotes(talkgroup.Name), talkgroup.Order, validTagId, talkgroup.TalkgroupRef, talkgroup.Kind, talkgroup.ToneDetectionEnabled, escapeQuotes(toneSetsJson), preferredApiKeyIdSQL, false, talkgroup.ToneDownstreamEnabled, escapeQuotes(talkgroup.ToneDownstreamURL), escapeQuotes(talkgroup.ToneDownstreamAPIKey), talkgroup.AlertCooldownSeconds, talkgroup.LinkedVoiceTalkgroupRef, talkgroup.LinkedVoiceWindowSeconds, talkgroup.LinkedVoiceMinDurationSeconds, talkgroup.AlertsEnabled, escapeQuotes(talkgroup.TranscriptionPrompt), talkgroup.AutoLearnToneSets, talkgroup.AlertingTalkgroup, talkgroup.AutoLearnUnitAliases, talkgroup.Id)
-			if _, err = tx.Exec(query); err != nil {
+			query, args = columns.update("talkgroups", "talkgroupId", talkgroup.Id)
+			if _, err = tx.Exec(query, args...); err != nil {
 				break
 			}
 		}
 
-		query = fmt.Sprintf(`SELECT "groupId", "talkgroupGroupId" FROM "talkgroupGroups" WHERE "talkgroupId" = %d`, talkgroup.Id)
-		if rows, err = tx.Query(query); err != nil {
+		query = `SELECT "groupId", "talkgroupGroupId" FROM "talkgroupGroups" WHERE "talkgroupId" = $1`
+		if rows, err = tx.Query(query, talkgroup.Id); err != nil {
 			break
 		}
 
@@ -618,16 +680,14 @@ func (talkgroups *Talkgroups) WriteTx(tx *sql.Tx, systemId uint64, dbType string
 		rows.Close()
 
 		if err != nil {
-			return formatError(err, "")
+			return files, formatError(err, "")
 		}
 
 		if len(talkgroupGroupIds) > 0 {
-			if b, err := json.Marshal(talkgroupGroupIds); err == nil {
-				in := strings.ReplaceAll(strings.ReplaceAll(string(b), "[", "("), "]", ")")
-				query = fmt.Sprintf(`DELETE FROM "talkgroupGroups" WHERE "talkgroupGroupId" IN %s`, in)
-				if _, err = tx.Exec(query); err != nil {
-					return formatError(err, query)
-				}
+			args := queryArgs{}
+			query = `DELETE FROM "talkgroupGroups" WHERE "talkgroupGroupId" IN ` + args.in(talkgroupGroupIds)
+			if _, err = tx.Exec(query, args...); err != nil {
+				return files, formatError(err, query)
 			}
 		}
 
@@ -638,14 +698,14 @@ func (talkgroups *Talkgroups) WriteTx(tx *sql.Tx, systemId uint64, dbType string
 				continue
 			}
 
-			query = fmt.Sprintf(`SELECT COUNT(*) FROM "talkgroupGroups" WHERE "talkgroupId" = %d AND "groupId" = %d`, talkgroup.Id, groupId)
-			if err = tx.QueryRow(query).Scan(&count); err != nil {
+			query = `SELECT COUNT(*) FROM "talkgroupGroups" WHERE "talkgroupId" = $1 AND "groupId" = $2`
+			if err = tx.QueryRow(query, talkgroup.Id, groupId).Scan(&count); err != nil {
 				break
 			}
 
 			if count == 0 {
-				query = fmt.Sprintf(`INSERT INTO "talkgroupGroups" ("groupId", "talkgroupId") VALUES (%d, %d)`, groupId, talkgroup.Id)
-				if _, err = tx.Exec(query); err != nil {
+				query = `INSERT INTO "talkgroupGroups" ("groupId", "talkgroupId") VALUES ($1, $2)`
+				if _, err = tx.Exec(query, groupId, talkgroup.Id); err != nil {
 					break
 				}
 			}
@@ -661,10 +721,10 @@ func (talkgroups *Talkgroups) WriteTx(tx *sql.Tx, systemId uint64, dbType string
 	}
 
 	if err != nil {
-		return formatError(err, query)
+		return files, formatError(err, query)
 	}
 
-	return nil
+	return files, nil
 }
 
 type TalkgroupsMap []TalkgroupMap
@@ -0,0 +1,363 @@
+// Copyright (C) 2026 Thinline Dynamic Solutions
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>
+
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// TalkgroupImportRequest imports a trunk-recorder or Radio Reference talkgroup
+// CSV into a system. With DryRun nothing is written.
+type TalkgroupImportRequest struct {
+	SystemId uint64 `json:"systemId"`
+	Content  string `json:"content"`
+	DryRun   bool   `json:"dryRun"`
+}
+
+// TalkgroupImportRow is a talkgroup of the file.
+type TalkgroupImportRow struct {
+	TalkgroupRef uint   `json:"talkgroupRef"`
+	Label        string `json:"label"`
+	Name         string `json:"name"`
+	Mode         string `json:"mode,omitempty"`
+	Tag          string `json:"tag"`
+	Group        string `json:"group"`
+}
+
+// TalkgroupImportChange is a talkgroup the import adds or updates; Before is
+// the current talkgroup when updated.
+type TalkgroupImportChange struct {
+	TalkgroupImportRow
+	Before *TalkgroupImportRow `json:"before,omitempty"`
+}
+
+// TalkgroupImportResult is the diff of an import, and what was written when
+// it was not a dry run.
+type TalkgroupImportResult struct {
+	DryRun    bool                    `json:"dryRun"`
+	Added     []TalkgroupImportChange `json:"added"`
+	Updated   []TalkgroupImportChange `json:"updated"`
+	Unchanged int                     `json:"unchanged"`
+	NewTags   []string                `json:"newTags"`
+	NewGroups []string                `json:"newGroups"`
+	Warnings  []string                `json:"warnings,omitempty"`
+}
+
+// trunkRecorderColumns is the column order of a headerless trunk-recorder
+// talkgroupsFile.
+var trunkRecorderColumns = []string{"decimal", "hex", "mode", "alphatag", "description", "tag", "category"}
+
+// ParseTalkgroupCSV reads a talkgroup CSV. Files with a header row are read
+// by column name, in any order (Radio Reference exports: Decimal, Hex, Alpha
+// Tag, Mode, Description, Tag, Category); headerless files use the
+// trunk-recorder order (Decimal, Hex, Mode, Alpha Tag, Description, Tag,
+// Group[, Priority]).
+func ParseTalkgroupCSV(content string) ([]TalkgroupImportRow, []string, error) {
+	content = strings.TrimLeft(strings.TrimSpace(content), "\ufeff")
+	if content == "" {
+		return nil, nil, fmt.Errorf("no content provided")
+	}
+
+	reader := csv.NewReader(strings.NewReader(content))
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+
+	first, err := reader.Read()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read csv: %w", err)
+	}
+
+	columns := map[string]int{}
+	records := [][]string{}
+	headerless := len(first) > 0 && isTalkgroupNumber(first[0])
+	if headerless {
+		for idx, name := range trunkRecorderColumns {
+			columns[name] = idx
+		}
+		records = append(records, first)
+	} else {
+		for idx, header := range first {
+			switch h := normalizeHeader(header); h {
+			case "decimal", "dec", "tgid", "talkgroup", "talkgroupid":
+				columns["decimal"] = idx
+			case "alphatag", "alpha", "label":
+				columns["alphatag"] = idx
+			case "category", "group":
+				columns["category"] = idx
+			case "hex", "mode", "description", "tag":
+				columns[h] = idx
+			}
+		}
+		if _, ok := columns["decimal"]; !ok {
+			if _, ok := columns["hex"]; !ok {
+				return nil, nil, fmt.Errorf("csv header has no Decimal or Hex column")
+			}
+		}
+	}
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read csv: %w", err)
+		}
+		records = append(records, record)
+	}
+
+	get := func(record []string, name string) string {
+		if idx, ok := columns[name]; ok && idx < len(record) {
+			return strings.TrimSpace(record[idx])
+		}
+		return ""
+	}
+
+	rows := []TalkgroupImportRow{}
+	warnings := []string{}
+	seen := map[uint]int{}
+	for i, record := range records {
+		line := i + 2
+		if headerless {
+			line = i + 1
+		}
+
+		var ref uint64
+		if v := get(record, "decimal"); v != "" {
+			ref, err = strconv.ParseUint(v, 10, 32)
+		} else if v := get(record, "hex"); v != "" {
+			ref, err = strconv.ParseUint(strings.TrimPrefix(strings.ToLower(v), "0x"), 16, 32)
+		} else {
+			err = fmt.Errorf("no talkgroup id")
+		}
+		if err != nil || ref == 0 {
+			warnings = append(warnings, fmt.Sprintf("line %d: invalid talkgroup id, skipped", line))
+			continue
+		}
+
+		row := TalkgroupImportRow{
+			TalkgroupRef: uint(ref),
+			Label:        get(record, "alphatag"),
+			Name:         get(record, "description"),
+			Mode:         get(record, "mode"),
+			Tag:          get(record, "tag"),
+			Group:        get(record, "category"),
+		}
+		if row.Label == "" {
+			row.Label = strconv.FormatUint(ref, 10)
+		}
+		if row.Name == "" {
+			row.Name = row.Label
+		}
+
+		if idx, ok := seen[row.TalkgroupRef]; ok {
+			warnings = append(warnings, fmt.Sprintf("line %d: talkgroup %d listed twice, the last one is kept", line, ref))
+			rows[idx] = row
+			continue
+		}
+		seen[row.TalkgroupRef] = len(rows)
+		rows = append(rows, row)
+	}
+
+	return rows, warnings, nil
+}
+
+func isTalkgroupNumber(s string) bool {
+	_, err := strconv.ParseUint(strings.TrimSpace(s), 10, 32)
+	return err == nil
+}
+
+// existingLabel returns the label of labels matching label regardless of
+// case, so "fire dispatch" maps to an existing "Fire Dispatch" tag.
+func existingLabel(labels []string, label string) (string, bool) {
+	for _, l := range labels {
+		if strings.EqualFold(l, label) {
+			return l, true
+		}
+	}
+	return "", false
+}
+
+// diffTalkgroupImport compares the rows of a file with the talkgroups of a
+// system. Tags and groups are matched to the existing ones by label; rows
+// without one get "Untagged" and "Unknown", like Radio Reference imports.
+func diffTalkgroupImport(system *System, rows []TalkgroupImportRow, tagLabels []string, groupLabels []string, tagLabel func(uint64) string, groupLabel func(uint64) string) *TalkgroupImportResult {
+	result := &TalkgroupImportResult{
+		Added:     []TalkgroupImportChange{},
+		Updated:   []TalkgroupImportChange{},
+		NewTags:   []string{},
+		NewGroups: []string{},
+	}
+
+	newLabel := func(labels []string, fresh *[]string, label string, fallback string) string {
+		if label == "" {
+			label = fallback
+		}
+		if existing, ok := existingLabel(labels, label); ok {
+			return existing
+		}
+		if existing, ok := existingLabel(*fresh, label); ok {
+			return existing
+		}
+		*fresh = append(*fresh, label)
+		return label
+	}
+
+	for _, row := range rows {
+		row.Tag = newLabel(tagLabels, &result.NewTags, row.Tag, "Untagged")
+		row.Group = newLabel(groupLabels, &result.NewGroups, row.Group, "Unknown")
+
+		existing, ok := system.Talkgroups.GetTalkgroupByRef(row.TalkgroupRef)
+		if !ok {
+			result.Added = append(result.Added, TalkgroupImportChange{TalkgroupImportRow: row})
+			continue
+		}
+
+		before := TalkgroupImportRow{
+			TalkgroupRef: existing.TalkgroupRef,
+			Label:        existing.Label,
+			Name:         existing.Name,
+			Tag:          tagLabel(existing.TagId),
+		}
+		if len(existing.GroupIds) > 0 {
+			before.Group = groupLabel(existing.GroupIds[0])
+		}
+
+		if before.Label == row.Label && before.Name == row.Name && before.Tag == row.Tag && before.Group == row.Group {
+			result.Unchanged++
+			continue
+		}
+		result.Updated = append(result.Updated, TalkgroupImportChange{TalkgroupImportRow: row, Before: &before})
+	}
+
+	return result
+}
+
+// ImportTalkgroupCSV diffs a talkgroup CSV against a system and, unless
+// dryRun, writes the added and updated talkgroups.
+func (controller *Controller) ImportTalkgroupCSV(system *System, content string, dryRun bool) (*TalkgroupImportResult, error) {
+	rows, warnings, err := ParseTalkgroupCSV(content)
+	if err != nil {
+		return nil, err
+	}
+
+	tagLabels := []string{}
+	controller.Tags.mutex.RLock()
+	for _, tag := range controller.Tags.List {
+		tagLabels = append(tagLabels, tag.Label)
+	}
+	controller.Tags.mutex.RUnlock()
+
+	groupLabels := []string{}
+	controller.Groups.mutex.RLock()
+	for _, group := range controller.Groups.List {
+		groupLabels = append(groupLabels, group.Label)
+	}
+	controller.Groups.mutex.RUnlock()
+	tagLabel := func(id uint64) string {
+		if tag, ok := controller.Tags.GetTagById(id); ok {
+			return tag.Label
+		}
+		return ""
+	}
+	groupLabel := func(id uint64) string {
+		if group, ok := controller.Groups.GetGroupById(id); ok {
+			return group.Label
+		}
+		return ""
+	}
+
+	result := diffTalkgroupImport(system, rows, tagLabels, groupLabels, tagLabel, groupLabel)
+	result.DryRun = dryRun
+	result.Warnings = warnings
+
+	if dryRun || len(result.Added)+len(result.Updated) == 0 {
+		return result, nil
+	}
+
+	body := radioReferenceImportBody{SystemId: float64(system.Id)}
+	for _, changes := range [][]TalkgroupImportChange{result.Added, result.Updated} {
+		for _, change := range changes {
+			body.Talkgroups = append(body.Talkgroups, radioReferenceImportTalkgroup{
+				Id:          float64(change.TalkgroupRef),
+				AlphaTag:    change.Label,
+				Description: change.Name,
+				Group:       change.Group,
+				Tag:         change.Tag,
+			})
+		}
+	}
+	if _, _, err := controller.Admin.radioReferenceImportToSystemCore(body); err != nil {
+		return nil, err
+	}
+
+	go controller.EmitConfig()
+
+	return result, nil
+}
+
+// TalkgroupImportHandler imports a talkgroup CSV into a system:
+// POST /api/admin/talkgroup-import {"systemId", "content", "dryRun"}
+func (admin *Admin) TalkgroupImportHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	writeError := func(status int, message string) {
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(map[string]string{"error": message})
+	}
+
+	t := admin.GetAuthorization(r)
+	if !admin.ValidatePermission(t, PermissionManageTalkgroups) {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		writeError(http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	var req TalkgroupImportRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(http.StatusBadRequest, "invalid JSON")
+		return
+	}
+
+	system, ok := admin.Controller.Systems.GetSystemById(req.SystemId)
+	if !ok || !admin.tenantAllowsSystem(t, system.Id) {
+		writeError(http.StatusNotFound, "system not found")
+		return
+	}
+
+	result, err := admin.Controller.ImportTalkgroupCSV(system, req.Content, req.DryRun)
+	if err != nil {
+		admin.Controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("talkgroup import failed: %s", err.Error()))
+		writeError(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if !req.DryRun {
+		admin.Controller.Logs.LogEvent(LogLevelInfo, fmt.Sprintf("talkgroup import into %s: %d added, %d updated", system.Label, len(result.Added), len(result.Updated)))
+	}
+
+	json.NewEncoder(w).Encode(result)
+}
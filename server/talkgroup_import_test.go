@@ -0,0 +1,82 @@
+package main
+
+import "testing"
+
+func TestParseTalkgroupCSVRadioReference(t *testing.T) {
+	content := "\ufeffDecimal,Hex,Alpha Tag,Mode,Description,Tag,Category\n" +
+		"1001,3e9,FD Disp,D,Fire Dispatch,Fire Dispatch,County Fire\n" +
+		"1002,3ea,PD Main,DE,Police Main,Law Dispatch,County Police\n" +
+		"abc,,Bad,D,Bad,,\n" +
+		"1001,3e9,FD Dispatch,D,Fire Dispatch,Fire Dispatch,County Fire\n"
+
+	rows, warnings, err := ParseTalkgroupCSV(content)
+	if err != nil {
+		t.Fatalf("ParseTalkgroupCSV: %v", err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("got %d rows, want 2: %+v", len(rows), rows)
+	}
+	if rows[0].TalkgroupRef != 1001 || rows[0].Label != "FD Dispatch" || rows[0].Group != "County Fire" || rows[0].Tag != "Fire Dispatch" {
+		t.Fatalf("row 0 = %+v", rows[0])
+	}
+	if rows[1].Mode != "DE" || rows[1].Name != "Police Main" {
+		t.Fatalf("row 1 = %+v", rows[1])
+	}
+	if len(warnings) != 2 {
+		t.Fatalf("warnings = %v", warnings)
+	}
+}
+
+func TestParseTalkgroupCSVTrunkRecorder(t *testing.T) {
+	rows, _, err := ParseTalkgroupCSV("2001,7d1,D,EMS Disp,EMS Dispatch,EMS Dispatch,County EMS,1\n")
+	if err != nil {
+		t.Fatalf("ParseTalkgroupCSV: %v", err)
+	}
+	if len(rows) != 1 || rows[0].TalkgroupRef != 2001 || rows[0].Label != "EMS Disp" || rows[0].Mode != "D" || rows[0].Group != "County EMS" {
+		t.Fatalf("rows = %+v", rows)
+	}
+}
+
+func TestParseTalkgroupCSVHexOnly(t *testing.T) {
+	rows, _, err := ParseTalkgroupCSV("Hex,Alpha Tag\n3e9,FD Disp\n")
+	if err != nil {
+		t.Fatalf("ParseTalkgroupCSV: %v", err)
+	}
+	if len(rows) != 1 || rows[0].TalkgroupRef != 1001 {
+		t.Fatalf("rows = %+v", rows)
+	}
+
+	if _, _, err := ParseTalkgroupCSV("Alpha Tag,Description\nFD,Fire\n"); err == nil {
+		t.Fatalf("csv without an id column accepted")
+	}
+}
+
+func TestDiffTalkgroupImport(t *testing.T) {
+	system := &System{Talkgroups: NewTalkgroups()}
+	system.Talkgroups.List = []*Talkgroup{
+		{TalkgroupRef: 1001, Label: "FD Disp", Name: "Fire Dispatch", TagId: 1, GroupIds: []uint64{1}},
+		{TalkgroupRef: 1002, Label: "PD Main", Name: "Police Main", TagId: 1, GroupIds: []uint64{1}},
+	}
+	tagLabel := func(id uint64) string { return map[uint64]string{1: "Fire Dispatch"}[id] }
+	groupLabel := func(id uint64) string { return map[uint64]string{1: "County"}[id] }
+
+	rows := []TalkgroupImportRow{
+		{TalkgroupRef: 1001, Label: "FD Disp", Name: "Fire Dispatch", Tag: "fire dispatch", Group: "County"},
+		{TalkgroupRef: 1002, Label: "PD Dispatch", Name: "Police Main", Tag: "Fire Dispatch", Group: "County"},
+		{TalkgroupRef: 1003, Label: "EMS", Name: "EMS", Tag: "EMS Dispatch", Group: ""},
+	}
+
+	result := diffTalkgroupImport(system, rows, []string{"Fire Dispatch"}, []string{"County"}, tagLabel, groupLabel)
+	if result.Unchanged != 1 {
+		t.Fatalf("unchanged = %d, want 1 (tag matched regardless of case)", result.Unchanged)
+	}
+	if len(result.Updated) != 1 || result.Updated[0].Before.Label != "PD Main" || result.Updated[0].Label != "PD Dispatch" {
+		t.Fatalf("updated = %+v", result.Updated)
+	}
+	if len(result.Added) != 1 || result.Added[0].Group != "Unknown" {
+		t.Fatalf("added = %+v", result.Added)
+	}
+	if len(result.NewTags) != 1 || result.NewTags[0] != "EMS Dispatch" || len(result.NewGroups) != 1 || result.NewGroups[0] != "Unknown" {
+		t.Fatalf("new tags %v, new groups %v", result.NewTags, result.NewGroups)
+	}
+}
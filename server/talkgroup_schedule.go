@@ -0,0 +1,290 @@
+// Copyright (C) 2026 Thinline Dynamic Solutions
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>
+
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	talkgroupScheduleDrop = "drop"
+	talkgroupScheduleMute = "mute"
+)
+
+// TalkgroupSchedule limits when the calls of some talkgroups are kept. Record
+// and Ignore are cron-like expressions of the minutes they cover; a call is
+// outside the schedule when Record is set and does not match its start, or
+// when Ignore matches it. Calls outside the schedule are dropped, or stored
+// but kept off live streams when Action is "mute".
+type TalkgroupSchedule struct {
+	Label         string `json:"label,omitempty"`
+	SystemRef     uint   `json:"systemRef"`
+	TalkgroupRefs []uint `json:"talkgroupRefs,omitempty"` // empty for every talkgroup of the system
+	Record        string `json:"record,omitempty"`
+	Ignore        string `json:"ignore,omitempty"`
+	Action        string `json:"action,omitempty"`
+	TimeZone      string `json:"timeZone,omitempty"` // empty for the system time zone
+
+	record *cronExpr
+	ignore *cronExpr
+}
+
+type TalkgroupSchedules []TalkgroupSchedule
+
+// talkgroupSchedulesFromList drops schedules without a system, without an
+// expression, or with an expression or time zone that does not parse.
+func talkgroupSchedulesFromList(list []any) TalkgroupSchedules {
+	schedules := TalkgroupSchedules{}
+
+	for _, item := range list {
+		m, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+
+		schedule := TalkgroupSchedule{}
+		if v, ok := m["label"].(string); ok {
+			schedule.Label = strings.TrimSpace(v)
+		}
+		if v, ok := m["systemRef"].(float64); ok && v > 0 {
+			schedule.SystemRef = uint(v)
+		}
+		if v, ok := m["talkgroupRefs"].([]any); ok {
+			for _, ref := range v {
+				if ref, ok := ref.(float64); ok && ref > 0 {
+					schedule.TalkgroupRefs = append(schedule.TalkgroupRefs, uint(ref))
+				}
+			}
+		}
+		if v, ok := m["record"].(string); ok {
+			schedule.Record = strings.TrimSpace(v)
+		}
+		if v, ok := m["ignore"].(string); ok {
+			schedule.Ignore = strings.TrimSpace(v)
+		}
+		if v, ok := m["action"].(string); ok && v == talkgroupScheduleMute {
+			schedule.Action = talkgroupScheduleMute
+		} else {
+			schedule.Action = talkgroupScheduleDrop
+		}
+		if v, ok := m["timeZone"].(string); ok {
+			schedule.TimeZone = strings.TrimSpace(v)
+		}
+
+		if err := schedule.compile(); err != nil {
+			continue
+		}
+		schedules = append(schedules, schedule)
+	}
+
+	return schedules
+}
+
+func (schedule *TalkgroupSchedule) compile() error {
+	if schedule.SystemRef == 0 {
+		return fmt.Errorf("schedule has no system")
+	}
+	if schedule.Record == "" && schedule.Ignore == "" {
+		return fmt.Errorf("schedule has no record or ignore expression")
+	}
+	if schedule.TimeZone != "" && validTimeZone(schedule.TimeZone) == "" {
+		return fmt.Errorf("unknown time zone %q", schedule.TimeZone)
+	}
+
+	var err error
+	if schedule.Record != "" {
+		if schedule.record, err = parseCronExpr(schedule.Record); err != nil {
+			return err
+		}
+	}
+	if schedule.Ignore != "" {
+		if schedule.ignore, err = parseCronExpr(schedule.Ignore); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (schedule *TalkgroupSchedule) covers(systemRef uint, talkgroupRef uint) bool {
+	if schedule.SystemRef != systemRef {
+		return false
+	}
+	if len(schedule.TalkgroupRefs) == 0 {
+		return true
+	}
+	for _, ref := range schedule.TalkgroupRefs {
+		if ref == talkgroupRef {
+			return true
+		}
+	}
+	return false
+}
+
+// allows reports whether t, in the schedule time zone, is inside the schedule.
+func (schedule *TalkgroupSchedule) allows(t time.Time) bool {
+	if schedule.record != nil && !schedule.record.matches(t) {
+		return false
+	}
+	if schedule.ignore != nil && schedule.ignore.matches(t) {
+		return false
+	}
+	return true
+}
+
+// ActionFor returns talkgroupScheduleDrop or talkgroupScheduleMute when call
+// starts outside a schedule of its talkgroup, or "" to keep it. A schedule
+// that drops the call wins over one that mutes it.
+func (schedules TalkgroupSchedules) ActionFor(call *Call) string {
+	if call == nil || call.System == nil || call.Talkgroup == nil {
+		return ""
+	}
+
+	action := ""
+	for i := range schedules {
+		schedule := &schedules[i]
+		if !schedule.covers(call.System.SystemRef, call.Talkgroup.TalkgroupRef) {
+			continue
+		}
+
+		location := call.System.Location()
+		if schedule.TimeZone != "" {
+			location = timeZoneLocation(schedule.TimeZone)
+		}
+		if schedule.allows(call.Timestamp.In(location)) {
+			continue
+		}
+
+		if schedule.Action != talkgroupScheduleMute {
+			return talkgroupScheduleDrop
+		}
+		action = talkgroupScheduleMute
+	}
+
+	return action
+}
+
+// cronExpr is a cron expression of minute, hour, day of month, month and day
+// of week, e.g. "* 6-21 * * 1-5". As in cron, when both day fields are
+// restricted a time matches when either of them does.
+type cronExpr struct {
+	minute     uint64
+	hour       uint64
+	day        uint64
+	month      uint64
+	weekday    uint64
+	anyDay     bool
+	anyWeekday bool
+}
+
+var cronFieldBounds = [5][2]int{{0, 59}, {0, 23}, {1, 31}, {1, 12}, {0, 7}}
+
+func parseCronExpr(expr string) (*cronExpr, error) {
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("invalid schedule %q, expected minute hour day month weekday", expr)
+	}
+
+	var sets [5]uint64
+	for i, field := range fields {
+		set, err := parseCronField(field, cronFieldBounds[i][0], cronFieldBounds[i][1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid schedule %q: %v", expr, err)
+		}
+		sets[i] = set
+	}
+
+	// Sunday is either 0 or 7
+	if sets[4]&(1<<7) != 0 {
+		sets[4] |= 1
+	}
+
+	return &cronExpr{
+		minute:     sets[0],
+		hour:       sets[1],
+		day:        sets[2],
+		month:      sets[3],
+		weekday:    sets[4],
+		anyDay:     strings.HasPrefix(fields[2], "*"),
+		anyWeekday: strings.HasPrefix(fields[4], "*"),
+	}, nil
+}
+
+// parseCronField parses a comma list of "*", "n", "a-b", each with an optional
+// "/step", into a bitset of the values between low and high.
+func parseCronField(field string, low int, high int) (uint64, error) {
+	var set uint64
+
+	for _, part := range strings.Split(field, ",") {
+		span, stepText, hasStep := strings.Cut(part, "/")
+
+		step := 1
+		if hasStep {
+			v, err := strconv.Atoi(stepText)
+			if err != nil || v < 1 {
+				return 0, fmt.Errorf("invalid step in %q", part)
+			}
+			step = v
+		}
+
+		start, end := low, high
+		if span != "*" {
+			from, to, isRange := strings.Cut(span, "-")
+			v, err := strconv.Atoi(from)
+			if err != nil {
+				return 0, fmt.Errorf("invalid value %q", part)
+			}
+			start, end = v, v
+			if isRange {
+				if end, err = strconv.Atoi(to); err != nil {
+					return 0, fmt.Errorf("invalid value %q", part)
+				}
+			} else if hasStep {
+				end = high
+			}
+		}
+
+		if start < low || end > high || start > end {
+			return 0, fmt.Errorf("%q is outside %d-%d", part, low, high)
+		}
+		for v := start; v <= end; v += step {
+			set |= 1 << uint(v)
+		}
+	}
+
+	return set, nil
+}
+
+func (expr *cronExpr) matches(t time.Time) bool {
+	if expr.minute&(1<<uint(t.Minute())) == 0 || expr.hour&(1<<uint(t.Hour())) == 0 || expr.month&(1<<uint(t.Month())) == 0 {
+		return false
+	}
+
+	day := expr.day&(1<<uint(t.Day())) != 0
+	weekday := expr.weekday&(1<<uint(t.Weekday())) != 0
+
+	switch {
+	case expr.anyDay && expr.anyWeekday:
+		return true
+	case expr.anyDay:
+		return weekday
+	case expr.anyWeekday:
+		return day
+	}
+	return day || weekday
+}
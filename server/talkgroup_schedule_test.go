@@ -0,0 +1,83 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCronExpr(t *testing.T) {
+	expr, err := parseCronExpr("0-14 12 * * 3")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	cases := []struct {
+		at   time.Time
+		want bool
+	}{
+		{time.Date(2026, 10, 21, 12, 0, 0, 0, time.UTC), true}, // Wednesday
+		{time.Date(2026, 10, 21, 12, 14, 59, 0, time.UTC), true},
+		{time.Date(2026, 10, 21, 12, 15, 0, 0, time.UTC), false},
+		{time.Date(2026, 10, 22, 12, 5, 0, 0, time.UTC), false}, // Thursday
+	}
+	for _, c := range cases {
+		if got := expr.matches(c.at); got != c.want {
+			t.Fatalf("matches(%s) = %v, want %v", c.at, got, c.want)
+		}
+	}
+
+	for _, bad := range []string{"", "* * * *", "60 * * * *", "* 5-2 * * *", "*/0 * * * *", "a * * * *"} {
+		if _, err := parseCronExpr(bad); err == nil {
+			t.Fatalf("parse %q: expected error", bad)
+		}
+	}
+}
+
+func TestCronExprDays(t *testing.T) {
+	// Sunday as 7, with steps
+	expr, err := parseCronExpr("*/30 * * * 7")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if !expr.matches(time.Date(2026, 10, 18, 9, 30, 0, 0, time.UTC)) || expr.matches(time.Date(2026, 10, 18, 9, 31, 0, 0, time.UTC)) {
+		t.Fatalf("sunday step mismatch")
+	}
+
+	// Both day fields restricted match either of them
+	expr, _ = parseCronExpr("* * 1 * 1")
+	if !expr.matches(time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)) || !expr.matches(time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("day of month or weekday should match")
+	}
+	if expr.matches(time.Date(2026, 10, 20, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("tuesday 20th should not match")
+	}
+}
+
+func TestTalkgroupSchedulesActionFor(t *testing.T) {
+	schedules := talkgroupSchedulesFromList([]any{
+		map[string]any{"systemRef": float64(1), "talkgroupRefs": []any{float64(101)}, "record": "* 6-21 * * *", "timeZone": "America/Chicago"},
+		map[string]any{"systemRef": float64(1), "talkgroupRefs": []any{float64(900)}, "ignore": "0-14 12 * * 3", "action": "mute", "timeZone": "UTC"},
+		map[string]any{"systemRef": float64(1), "record": "not cron"},
+		map[string]any{"record": "* * * * *"},
+	})
+	if len(schedules) != 2 {
+		t.Fatalf("schedules = %d, want 2", len(schedules))
+	}
+
+	call := func(talkgroupRef uint, at time.Time) *Call {
+		return &Call{System: &System{SystemRef: 1}, Talkgroup: &Talkgroup{TalkgroupRef: talkgroupRef}, Timestamp: at}
+	}
+
+	// 04:00 UTC is 23:00 the day before in Chicago
+	if got := schedules.ActionFor(call(101, time.Date(2026, 10, 21, 4, 0, 0, 0, time.UTC))); got != talkgroupScheduleDrop {
+		t.Fatalf("night call action = %q", got)
+	}
+	if got := schedules.ActionFor(call(101, time.Date(2026, 10, 21, 15, 0, 0, 0, time.UTC))); got != "" {
+		t.Fatalf("day call action = %q", got)
+	}
+	if got := schedules.ActionFor(call(900, time.Date(2026, 10, 21, 12, 5, 0, 0, time.UTC))); got != talkgroupScheduleMute {
+		t.Fatalf("test tone action = %q", got)
+	}
+	if got := schedules.ActionFor(call(102, time.Date(2026, 10, 21, 4, 0, 0, 0, time.UTC))); got != "" {
+		t.Fatalf("unscheduled talkgroup action = %q", got)
+	}
+}
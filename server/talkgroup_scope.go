@@ -0,0 +1,182 @@
+// Copyright (C) 2026 Thinline Dynamic Solutions
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>
+
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// TalkgroupScope lists the talkgroups of a system opened to people without
+// an account, through guest grants and player embeds.
+type TalkgroupScope struct {
+	SystemRef     uint   `json:"systemRef"`
+	TalkgroupRefs []uint `json:"talkgroupRefs"`
+}
+
+// validateTalkgroupScopes rejects empty scopes.
+func validateTalkgroupScopes(scopes []TalkgroupScope) error {
+	for _, scope := range scopes {
+		if scope.SystemRef == 0 || len(scope.TalkgroupRefs) == 0 {
+			return fmt.Errorf("each scope needs a systemRef and talkgroupRefs")
+		}
+	}
+	if len(scopes) == 0 {
+		return fmt.Errorf("at least one talkgroup is required")
+	}
+	return nil
+}
+
+// talkgroupScopesAllow reports whether the scopes list the talkgroup.
+func talkgroupScopesAllow(scopes []TalkgroupScope, system *System, talkgroup *Talkgroup) bool {
+	if system == nil || talkgroup == nil {
+		return false
+	}
+	for _, scope := range scopes {
+		if scope.SystemRef != system.SystemRef {
+			continue
+		}
+		for _, ref := range scope.TalkgroupRefs {
+			if ref == talkgroup.TalkgroupRef {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// tenantAllowsTalkgroupScopes reports whether every system of the scopes
+// belongs to the administrator's tenant.
+func (admin *Admin) tenantAllowsTalkgroupScopes(sToken string, scopes []TalkgroupScope) bool {
+	for _, scope := range scopes {
+		system, ok := admin.Controller.Systems.GetSystemByRef(scope.SystemRef)
+		if !ok || !admin.tenantAllowsSystem(sToken, system.Id) {
+			return false
+		}
+	}
+	return true
+}
+
+// checkTalkgroupScopes rejects scopes naming unknown talkgroups or systems
+// outside the administrator's tenant.
+func (admin *Admin) checkTalkgroupScopes(sToken string, scopes []TalkgroupScope) error {
+	for _, scope := range scopes {
+		system, ok := admin.Controller.Systems.GetSystemByRef(scope.SystemRef)
+		if !ok || !admin.tenantAllowsSystem(sToken, system.Id) {
+			return fmt.Errorf("unknown system %d", scope.SystemRef)
+		}
+		for _, ref := range scope.TalkgroupRefs {
+			if _, ok := system.Talkgroups.GetTalkgroupByRef(ref); !ok {
+				return fmt.Errorf("unknown talkgroup %d on system %d", ref, scope.SystemRef)
+			}
+		}
+	}
+	return nil
+}
+
+// talkgroupScopeIds returns the database ids of the talkgroups of the scopes.
+func (controller *Controller) talkgroupScopeIds(scopes []TalkgroupScope) []uint64 {
+	ids := []uint64{}
+	for _, scope := range scopes {
+		system, ok := controller.Systems.GetSystemByRef(scope.SystemRef)
+		if !ok {
+			continue
+		}
+		for _, ref := range scope.TalkgroupRefs {
+			if talkgroup, ok := system.Talkgroups.GetTalkgroupByRef(ref); ok {
+				ids = append(ids, talkgroup.Id)
+			}
+		}
+	}
+	return ids
+}
+
+// readScopedCalls returns up to limit calls of the scopes since a time and
+// before a call id when not 0, newest first. Duplicates, calls still held by
+// the delayer and calls not yet released are left out. The calls only carry
+// their id, time, system and talkgroup.
+func (controller *Controller) readScopedCalls(scopes []TalkgroupScope, since time.Time, before uint64, limit int, released func(call *Call) bool) ([]*Call, error) {
+	talkgroupIds := controller.talkgroupScopeIds(scopes)
+	if len(talkgroupIds) == 0 {
+		return []*Call{}, nil
+	}
+	args := queryArgs{}
+	where := []string{
+		`c."talkgroupId" IN ` + args.in(talkgroupIds),
+		`NOT c."isDuplicate"`,
+		`d."callId" IS NULL`,
+		`c."timestamp" >= ` + args.add(since.UnixMilli()),
+	}
+	if before > 0 {
+		where = append(where, `c."callId" < `+args.add(before))
+	}
+
+	rows, err := controller.Database.Sql.Query(fmt.Sprintf(
+		`SELECT c."callId", c."systemId", c."talkgroupId", c."timestamp" FROM "calls" c LEFT JOIN "delayed" AS d ON d."callId" = c."callId" WHERE %s ORDER BY c."callId" DESC LIMIT %s`,
+		strings.Join(where, " AND "), args.add(limit),
+	), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	calls := []*Call{}
+	for rows.Next() {
+		var callId, systemId, talkgroupId uint64
+		var timestamp int64
+		if err := rows.Scan(&callId, &systemId, &talkgroupId, &timestamp); err != nil {
+			continue
+		}
+		system, ok := controller.Systems.GetSystemById(systemId)
+		if !ok {
+			continue
+		}
+		talkgroup, ok := system.Talkgroups.GetTalkgroupById(talkgroupId)
+		if !ok || !talkgroupScopesAllow(scopes, system, talkgroup) {
+			continue
+		}
+		call := &Call{Id: callId, Timestamp: time.UnixMilli(timestamp), System: system, Talkgroup: talkgroup}
+		if released(call) {
+			calls = append(calls, call)
+		}
+	}
+	return calls, rows.Err()
+}
+
+// callDelayed reports whether the delayer still holds the call.
+func (controller *Controller) callDelayed(callId uint64) bool {
+	var delayed bool
+	controller.Database.Sql.QueryRow(`SELECT EXISTS (SELECT 1 FROM "delayed" WHERE "callId" = $1)`, callId).Scan(&delayed)
+	return delayed
+}
+
+// scopedCallResponse returns the API representation of a call read by
+// readScopedCalls.
+func scopedCallResponse(call *Call, audioUrl string) map[string]any {
+	response := map[string]any{
+		"id":            call.Id,
+		"timestamp":     call.Timestamp.UnixMilli(),
+		"systemRef":     call.System.SystemRef,
+		"system":        call.System.Label,
+		"talkgroupRef":  call.Talkgroup.TalkgroupRef,
+		"talkgroup":     call.Talkgroup.Label,
+		"talkgroupName": call.Talkgroup.Name,
+		"audioUrl":      audioUrl,
+	}
+	addCallTimeHints(response, call)
+	return response
+}
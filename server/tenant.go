@@ -0,0 +1,422 @@
+// Copyright (C) 2026 Thinline Dynamic Solutions
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var tenantColorPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// Tenant is a department hosted on a shared server. Its systems, users and
+// user groups are hidden from other tenants: listeners only receive the
+// tenant's systems, and tenant administrators, users of the tenant holding
+// role permissions, only see and manage the tenant's data.
+type Tenant struct {
+	Id           uint64   `json:"id"`
+	Name         string   `json:"name"`
+	LogoUrl      string   `json:"logoUrl"`
+	PrimaryColor string   `json:"primaryColor"`
+	AccentColor  string   `json:"accentColor"`
+	SystemIds    []uint64 `json:"systemIds"`
+	UserGroupIds []uint64 `json:"userGroupIds"`
+	UserIds      []uint64 `json:"userIds"`
+}
+
+// validate normalizes the tenant and rejects invalid branding.
+func (tenant *Tenant) validate() error {
+	tenant.Name = strings.TrimSpace(tenant.Name)
+	if tenant.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+
+	tenant.LogoUrl = strings.TrimSpace(tenant.LogoUrl)
+	if tenant.LogoUrl != "" && !strings.HasPrefix(tenant.LogoUrl, "https://") && !strings.HasPrefix(tenant.LogoUrl, "http://") && !strings.HasPrefix(tenant.LogoUrl, "/") {
+		return fmt.Errorf("logoUrl must be an http(s) URL or a path")
+	}
+
+	for _, color := range []*string{&tenant.PrimaryColor, &tenant.AccentColor} {
+		*color = strings.TrimSpace(*color)
+		if *color != "" && !tenantColorPattern.MatchString(*color) {
+			return fmt.Errorf("invalid color %q, expected #rrggbb", *color)
+		}
+	}
+
+	if tenant.SystemIds == nil {
+		tenant.SystemIds = []uint64{}
+	}
+	if tenant.UserGroupIds == nil {
+		tenant.UserGroupIds = []uint64{}
+	}
+	if tenant.UserIds == nil {
+		tenant.UserIds = []uint64{}
+	}
+	return nil
+}
+
+func containsId(ids []uint64, id uint64) bool {
+	for _, v := range ids {
+		if v == id {
+			return true
+		}
+	}
+	return false
+}
+
+// HasSystem reports whether the system (database id) belongs to the tenant.
+func (tenant *Tenant) HasSystem(systemId uint64) bool {
+	return containsId(tenant.SystemIds, systemId)
+}
+
+// HasUser reports whether the user belongs to the tenant, directly or
+// through their user group.
+func (tenant *Tenant) HasUser(user *User) bool {
+	if user == nil {
+		return false
+	}
+	return containsId(tenant.UserIds, user.Id) || (user.UserGroupId > 0 && containsId(tenant.UserGroupIds, user.UserGroupId))
+}
+
+// Branding is the tenant branding sent to its listeners.
+func (tenant *Tenant) Branding() map[string]any {
+	return map[string]any{
+		"name":         tenant.Name,
+		"logoUrl":      tenant.LogoUrl,
+		"primaryColor": tenant.PrimaryColor,
+		"accentColor":  tenant.AccentColor,
+	}
+}
+
+type Tenants struct {
+	mutex   sync.RWMutex
+	tenants map[uint64]*Tenant
+}
+
+func NewTenants() *Tenants {
+	return &Tenants{
+		tenants: map[uint64]*Tenant{},
+	}
+}
+
+func (tenants *Tenants) Load(db *Database) error {
+	tenants.mutex.Lock()
+	defer tenants.mutex.Unlock()
+
+	rows, err := db.Sql.Query(`SELECT "tenantId", "name", "logoUrl", "primaryColor", "accentColor", "systemIds", "userGroupIds", "userIds" FROM "tenants"`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	tenants.tenants = map[uint64]*Tenant{}
+	for rows.Next() {
+		tenant := &Tenant{}
+		var systemIds, userGroupIds, userIds string
+		if err := rows.Scan(&tenant.Id, &tenant.Name, &tenant.LogoUrl, &tenant.PrimaryColor, &tenant.AccentColor, &systemIds, &userGroupIds, &userIds); err != nil {
+			continue
+		}
+		json.Unmarshal([]byte(systemIds), &tenant.SystemIds)
+		json.Unmarshal([]byte(userGroupIds), &tenant.UserGroupIds)
+		json.Unmarshal([]byte(userIds), &tenant.UserIds)
+		tenants.tenants[tenant.Id] = tenant
+	}
+	return rows.Err()
+}
+
+// List returns the tenants sorted by name.
+func (tenants *Tenants) List() []Tenant {
+	tenants.mutex.RLock()
+	defer tenants.mutex.RUnlock()
+
+	list := make([]Tenant, 0, len(tenants.tenants))
+	for _, tenant := range tenants.tenants {
+		list = append(list, *tenant)
+	}
+	sort.Slice(list, func(i, j int) bool { return strings.ToLower(list[i].Name) < strings.ToLower(list[j].Name) })
+	return list
+}
+
+// Of returns a copy of the tenant the user belongs to, or nil.
+func (tenants *Tenants) Of(user *User) *Tenant {
+	if tenants == nil || user == nil {
+		return nil
+	}
+
+	tenants.mutex.RLock()
+	defer tenants.mutex.RUnlock()
+
+	var found *Tenant
+	for _, tenant := range tenants.tenants {
+		if tenant.HasUser(user) && (found == nil || tenant.Id < found.Id) {
+			found = tenant
+		}
+	}
+	if found == nil {
+		return nil
+	}
+	tenant := *found
+	return &tenant
+}
+
+// Owner returns the id of the tenant owning the system, or 0.
+func (tenants *Tenants) Owner(systemId uint64) uint64 {
+	if tenants == nil {
+		return 0
+	}
+
+	tenants.mutex.RLock()
+	defer tenants.mutex.RUnlock()
+
+	for _, tenant := range tenants.tenants {
+		if tenant.HasSystem(systemId) {
+			return tenant.Id
+		}
+	}
+	return 0
+}
+
+// Save inserts the tenant when Id is 0 and updates it otherwise. A system,
+// user or user group belongs to at most one tenant.
+func (tenants *Tenants) Save(tenant *Tenant, db *Database) error {
+	if err := tenant.validate(); err != nil {
+		return err
+	}
+
+	tenants.mutex.Lock()
+	defer tenants.mutex.Unlock()
+
+	for _, other := range tenants.tenants {
+		if other.Id == tenant.Id {
+			continue
+		}
+		for _, id := range tenant.SystemIds {
+			if other.HasSystem(id) {
+				return fmt.Errorf("system %d already belongs to tenant %q", id, other.Name)
+			}
+		}
+		for _, id := range tenant.UserGroupIds {
+			if containsId(other.UserGroupIds, id) {
+				return fmt.Errorf("user group %d already belongs to tenant %q", id, other.Name)
+			}
+		}
+		for _, id := range tenant.UserIds {
+			if containsId(other.UserIds, id) {
+				return fmt.Errorf("user %d already belongs to tenant %q", id, other.Name)
+			}
+		}
+	}
+
+	systemIds, _ := json.Marshal(tenant.SystemIds)
+	userGroupIds, _ := json.Marshal(tenant.UserGroupIds)
+	userIds, _ := json.Marshal(tenant.UserIds)
+
+	if tenant.Id == 0 {
+		if err := db.Sql.QueryRow(
+			`INSERT INTO "tenants" ("name", "logoUrl", "primaryColor", "accentColor", "systemIds", "userGroupIds", "userIds") VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING "tenantId"`,
+			tenant.Name, tenant.LogoUrl, tenant.PrimaryColor, tenant.AccentColor, string(systemIds), string(userGroupIds), string(userIds),
+		).Scan(&tenant.Id); err != nil {
+			return err
+		}
+	} else {
+		if _, ok := tenants.tenants[tenant.Id]; !ok {
+			return fmt.Errorf("tenant %d not found", tenant.Id)
+		}
+		if _, err := db.Sql.Exec(
+			`UPDATE "tenants" SET "name" = $1, "logoUrl" = $2, "primaryColor" = $3, "accentColor" = $4, "systemIds" = $5, "userGroupIds" = $6, "userIds" = $7 WHERE "tenantId" = $8`,
+			tenant.Name, tenant.LogoUrl, tenant.PrimaryColor, tenant.AccentColor, string(systemIds), string(userGroupIds), string(userIds), tenant.Id,
+		); err != nil {
+			return err
+		}
+	}
+
+	saved := *tenant
+	tenants.tenants[tenant.Id] = &saved
+	return nil
+}
+
+func (tenants *Tenants) Delete(id uint64, db *Database) error {
+	tenants.mutex.Lock()
+	defer tenants.mutex.Unlock()
+
+	if _, err := db.Sql.Exec(`DELETE FROM "tenants" WHERE "tenantId" = $1`, id); err != nil {
+		return err
+	}
+	delete(tenants.tenants, id)
+	return nil
+}
+
+// adopt adds a system or user created by a tenant administrator to their
+// tenant.
+func (tenants *Tenants) adopt(tenantId uint64, systemId uint64, userId uint64, db *Database) error {
+	tenants.mutex.RLock()
+	current, ok := tenants.tenants[tenantId]
+	var tenant Tenant
+	if ok {
+		tenant = *current
+		tenant.SystemIds = append([]uint64{}, current.SystemIds...)
+		tenant.UserIds = append([]uint64{}, current.UserIds...)
+	}
+	tenants.mutex.RUnlock()
+	if !ok {
+		return fmt.Errorf("tenant %d not found", tenantId)
+	}
+
+	if systemId > 0 && !tenant.HasSystem(systemId) {
+		tenant.SystemIds = append(tenant.SystemIds, systemId)
+	}
+	if userId > 0 && !containsId(tenant.UserIds, userId) {
+		tenant.UserIds = append(tenant.UserIds, userId)
+	}
+	return tenants.Save(&tenant, db)
+}
+
+// tenantAllowsCall reports whether a listener may receive the call: users of
+// a tenant only receive the calls of its systems.
+func (controller *Controller) tenantAllowsCall(user *User, call *Call) bool {
+	if user == nil || call == nil || call.System == nil {
+		return true
+	}
+	tenant := controller.Tenants.Of(user)
+	return tenant == nil || tenant.HasSystem(call.System.Id)
+}
+
+// tokenTenant returns the tenant an admin token is limited to, or nil for
+// tokens that see every tenant: the admin password and system administrators.
+func (admin *Admin) tokenTenant(sToken string) *Tenant {
+	claims, ok := admin.tokenClaims(sToken)
+	if !ok || claims.Subject == "" {
+		return nil
+	}
+	user := admin.tokenUser(claims)
+	if user == nil || user.SystemAdmin {
+		return nil
+	}
+	return admin.Controller.Tenants.Of(user)
+}
+
+// tenantAllowsSystem reports whether the admin token may see the system.
+func (admin *Admin) tenantAllowsSystem(sToken string, systemId uint64) bool {
+	tenant := admin.tokenTenant(sToken)
+	return tenant == nil || tenant.HasSystem(systemId)
+}
+
+// tenantAllowsUser reports whether the admin token may see the user.
+func (admin *Admin) tenantAllowsUser(sToken string, user *User) bool {
+	tenant := admin.tokenTenant(sToken)
+	return tenant == nil || tenant.HasUser(user)
+}
+
+// tenantSystems returns the systems the admin token may see.
+func (admin *Admin) tenantSystems(sToken string) []*System {
+	tenant := admin.tokenTenant(sToken)
+
+	admin.Controller.Systems.mutex.RLock()
+	defer admin.Controller.Systems.mutex.RUnlock()
+
+	systems := []*System{}
+	for _, system := range admin.Controller.Systems.List {
+		if tenant == nil || tenant.HasSystem(system.Id) {
+			systems = append(systems, system)
+		}
+	}
+	return systems
+}
+
+// tenantAllowsAlert reports whether the admin token may see the system alert.
+// Alerts not tied to a system are only shown to full administrators.
+func (admin *Admin) tenantAllowsAlert(sToken string, alert *SystemAlert) bool {
+	tenant := admin.tokenTenant(sToken)
+	if tenant == nil {
+		return true
+	}
+	data := SystemAlertData{}
+	json.Unmarshal([]byte(alert.Data), &data)
+	return data.SystemId > 0 && tenant.HasSystem(data.SystemId)
+}
+
+// TenantsHandler manages tenants. Only full administrators may change them.
+//
+//	GET    /api/admin/tenants          list the tenants
+//	POST   /api/admin/tenants          create a tenant
+//	PUT    /api/admin/tenants?id=N     update a tenant
+//	DELETE /api/admin/tenants?id=N     delete a tenant
+func (admin *Admin) TenantsHandler(w http.ResponseWriter, r *http.Request) {
+	t := admin.GetAuthorization(r)
+	if !admin.ValidateToken(t) {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	writeError := func(status int, err error) {
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+	}
+
+	tenants := admin.Controller.Tenants
+
+	switch r.Method {
+	case http.MethodGet:
+		json.NewEncoder(w).Encode(map[string]any{"tenants": tenants.List()})
+
+	case http.MethodPost, http.MethodPut:
+		tenant := &Tenant{}
+		if err := json.NewDecoder(r.Body).Decode(tenant); err != nil {
+			writeError(http.StatusBadRequest, fmt.Errorf("invalid tenant: %v", err))
+			return
+		}
+		tenant.Id = 0
+		if r.Method == http.MethodPut {
+			id, err := strconv.ParseUint(r.URL.Query().Get("id"), 10, 64)
+			if err != nil || id == 0 {
+				writeError(http.StatusBadRequest, fmt.Errorf("invalid tenant id"))
+				return
+			}
+			tenant.Id = id
+		}
+		if err := tenants.Save(tenant, admin.Controller.Database); err != nil {
+			writeError(http.StatusBadRequest, err)
+			return
+		}
+		admin.Controller.Logs.LogEvent(LogLevelInfo, fmt.Sprintf("admin: tenant %q saved with %d systems", tenant.Name, len(tenant.SystemIds)))
+		go admin.Controller.EmitConfig()
+		json.NewEncoder(w).Encode(tenant)
+
+	case http.MethodDelete:
+		id, err := strconv.ParseUint(r.URL.Query().Get("id"), 10, 64)
+		if err != nil || id == 0 {
+			writeError(http.StatusBadRequest, fmt.Errorf("invalid tenant id"))
+			return
+		}
+		if err := tenants.Delete(id, admin.Controller.Database); err != nil {
+			writeError(http.StatusInternalServerError, err)
+			return
+		}
+		go admin.Controller.EmitConfig()
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
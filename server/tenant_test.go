@@ -0,0 +1,106 @@
+package main
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+func TestTenantValidate(t *testing.T) {
+	tenant := &Tenant{Name: " Fire ", LogoUrl: "https://example.com/fire.png", PrimaryColor: "#b71c1c"}
+	if err := tenant.validate(); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+	if tenant.Name != "Fire" || tenant.SystemIds == nil || tenant.UserGroupIds == nil || tenant.UserIds == nil {
+		t.Fatalf("tenant = %+v", tenant)
+	}
+
+	if err := (&Tenant{Name: "x", PrimaryColor: "red"}).validate(); err == nil {
+		t.Fatalf("invalid color accepted")
+	}
+	if err := (&Tenant{Name: "x", LogoUrl: "javascript:alert(1)"}).validate(); err == nil {
+		t.Fatalf("invalid logo url accepted")
+	}
+	if err := (&Tenant{}).validate(); err == nil {
+		t.Fatalf("empty name accepted")
+	}
+}
+
+func TestTenantsMembership(t *testing.T) {
+	tenants := NewTenants()
+	tenants.tenants[1] = &Tenant{Id: 1, Name: "Fire", SystemIds: []uint64{10}, UserGroupIds: []uint64{3}, UserIds: []uint64{7}}
+	tenants.tenants[2] = &Tenant{Id: 2, Name: "Police", SystemIds: []uint64{20}}
+
+	if tenant := tenants.Of(&User{Id: 7}); tenant == nil || tenant.Id != 1 {
+		t.Fatalf("direct member tenant = %v", tenant)
+	}
+	if tenant := tenants.Of(&User{Id: 8, UserGroupId: 3}); tenant == nil || tenant.Id != 1 {
+		t.Fatalf("group member tenant = %v", tenant)
+	}
+	if tenant := tenants.Of(&User{Id: 9}); tenant != nil {
+		t.Fatalf("non-member tenant = %v", tenant)
+	}
+	if tenants.Owner(20) != 2 || tenants.Owner(30) != 0 {
+		t.Fatalf("system owners wrong")
+	}
+
+	controller := &Controller{Tenants: tenants}
+	call := &Call{System: &System{Id: 20}}
+	if controller.tenantAllowsCall(&User{Id: 7}, call) {
+		t.Fatalf("tenant user received another tenant's call")
+	}
+	if !controller.tenantAllowsCall(&User{Id: 9}, call) {
+		t.Fatalf("user without tenant blocked")
+	}
+}
+
+func TestTenantRolesAndTokens(t *testing.T) {
+	tenants := NewTenants()
+	tenants.tenants[1] = &Tenant{Id: 1, Name: "Fire", SystemIds: []uint64{10}, UserIds: []uint64{7}}
+	roles := NewRoles()
+	roles.tenants = tenants
+	roles.roles[1] = &Role{Id: 1, Name: "Fire admins", Permissions: []string{PermissionManageUsers}, UserIds: []uint64{7, 8}, TenantId: 1}
+
+	controller := &Controller{Options: &Options{secret: "secret"}, Users: NewUsers(), Roles: roles, Tenants: tenants}
+	controller.Users.users[7] = &User{Id: 7}
+	controller.Users.users[8] = &User{Id: 8}
+	controller.Users.users[9] = &User{Id: 9, SystemAdmin: true}
+	admin := &Admin{Controller: controller}
+
+	issue := func(subject string) string {
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{ID: subject + "-id", Subject: subject}).SignedString([]byte("secret"))
+		if err != nil {
+			t.Fatalf("sign: %v", err)
+		}
+		admin.Tokens = append(admin.Tokens, token)
+		return token
+	}
+
+	tenantAdmin := issue(strconv.Itoa(7))
+	if !admin.ValidatePermission(tenantAdmin, PermissionManageUsers) {
+		t.Fatalf("tenant role not applied to tenant member")
+	}
+	if admin.ValidatePermission(issue(strconv.Itoa(8)), PermissionManageUsers) {
+		t.Fatalf("tenant role applied outside its tenant")
+	}
+
+	if tenant := admin.tokenTenant(tenantAdmin); tenant == nil || tenant.Id != 1 {
+		t.Fatalf("tenant admin token not scoped: %v", tenant)
+	}
+	if !admin.tenantAllowsSystem(tenantAdmin, 10) || admin.tenantAllowsSystem(tenantAdmin, 20) {
+		t.Fatalf("tenant admin system scope wrong")
+	}
+	if admin.tenantAllowsUser(tenantAdmin, controller.Users.users[8]) {
+		t.Fatalf("tenant admin sees another tenant's user")
+	}
+	if admin.tenantAllowsAlert(tenantAdmin, &SystemAlert{Data: `{"systemId":20}`}) || !admin.tenantAllowsAlert(tenantAdmin, &SystemAlert{Data: `{"systemId":10}`}) {
+		t.Fatalf("tenant admin alert scope wrong")
+	}
+
+	for _, token := range []string{issue(""), issue(strconv.Itoa(9))} {
+		if admin.tokenTenant(token) != nil || !admin.tenantAllowsSystem(token, 20) {
+			t.Fatalf("full administrator scoped to a tenant")
+		}
+	}
+}
@@ -0,0 +1,87 @@
+// Copyright (C) 2026 Thinline Dynamic Solutions
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>
+
+package main
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// timeZoneLocations caches the loaded IANA time zones by name.
+var timeZoneLocations sync.Map
+
+// loadTimeZone returns the IANA time zone of the name.
+func loadTimeZone(name string) (*time.Location, bool) {
+	if name == "" || name == "Local" {
+		return nil, false
+	}
+	if location, ok := timeZoneLocations.Load(name); ok {
+		return location.(*time.Location), true
+	}
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, false
+	}
+	timeZoneLocations.Store(name, location)
+	return location, true
+}
+
+// validTimeZone returns the trimmed name when it is an IANA time zone the
+// server knows, or "" for server time.
+func validTimeZone(name string) string {
+	name = strings.TrimSpace(name)
+	if _, ok := loadTimeZone(name); !ok {
+		return ""
+	}
+	return name
+}
+
+// timeZoneLocation is the time zone of the name, or the server's.
+func timeZoneLocation(name string) *time.Location {
+	if location, ok := loadTimeZone(name); ok {
+		return location
+	}
+	return time.Local
+}
+
+// Location returns the time zone the calls of the system are shown in.
+func (system *System) Location() *time.Location {
+	if system == nil {
+		return time.Local
+	}
+	return timeZoneLocation(system.TimeZone)
+}
+
+// formatLocalTime renders a time for people in the time zone of the name,
+// e.g. "Jan 2 3:04 PM CST".
+func formatLocalTime(t time.Time, timeZone string) string {
+	return t.In(timeZoneLocation(timeZone)).Format("Jan 2 3:04 PM MST")
+}
+
+// addCallTimeHints adds the UTC time of a call to its payload and, when its
+// system has a time zone, the zone and the local time to show.
+func addCallTimeHints(m map[string]any, call *Call) {
+	m["dateTimeUtc"] = call.Timestamp.UTC().Format(time.RFC3339)
+
+	if call.System == nil || call.System.TimeZone == "" {
+		return
+	}
+	local := call.Timestamp.In(call.System.Location())
+	m["timeZone"] = call.System.TimeZone
+	m["timeZoneAbbr"] = local.Format("MST")
+	m["localDateTime"] = local.Format(time.RFC3339)
+}
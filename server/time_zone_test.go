@@ -0,0 +1,58 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSystemTimeZoneFromMap(t *testing.T) {
+	system := NewSystem().FromMap(map[string]any{"timeZone": " America/Chicago "})
+	if system.TimeZone != "America/Chicago" {
+		t.Fatalf("timeZone = %q", system.TimeZone)
+	}
+	if system.Location().String() != "America/Chicago" {
+		t.Fatalf("location = %s", system.Location())
+	}
+
+	system = NewSystem().FromMap(map[string]any{"timeZone": "Mars/Olympus"})
+	if system.TimeZone != "" || system.Location() != time.Local {
+		t.Fatalf("invalid zone kept: %q", system.TimeZone)
+	}
+}
+
+func TestAddCallTimeHints(t *testing.T) {
+	call := &Call{
+		System:    &System{TimeZone: "America/Denver"},
+		Timestamp: time.Date(2026, 1, 15, 18, 30, 0, 0, time.UTC),
+	}
+
+	m := map[string]any{}
+	addCallTimeHints(m, call)
+
+	if m["dateTimeUtc"] != "2026-01-15T18:30:00Z" {
+		t.Fatalf("dateTimeUtc = %v", m["dateTimeUtc"])
+	}
+	if m["localDateTime"] != "2026-01-15T11:30:00-07:00" || m["timeZone"] != "America/Denver" || m["timeZoneAbbr"] != "MST" {
+		t.Fatalf("local hints = %v", m)
+	}
+
+	m = map[string]any{}
+	addCallTimeHints(m, &Call{System: &System{}, Timestamp: call.Timestamp})
+	if _, ok := m["timeZone"]; ok {
+		t.Fatalf("time zone hint without a system time zone")
+	}
+}
+
+func TestEmailDigestLocalTime(t *testing.T) {
+	controller := &Controller{Options: &Options{}}
+	controller.Options.secret = "secret"
+	alerts := NewEmailAlerts(controller)
+
+	at := time.Date(2026, 7, 4, 2, 5, 0, 0, time.UTC)
+	items := []emailDigestItem{{CallId: 1, Title: "FIRE", CreatedAt: at.UnixMilli(), TimeZone: "America/New_York"}}
+
+	if body := alerts.digestHTML(&User{Id: 3}, items, "Scanner"); !strings.Contains(body, "Jul 3 10:05 PM EDT") {
+		t.Fatalf("digest time not in the system time zone: %s", body)
+	}
+}
@@ -0,0 +1,143 @@
+// Copyright (C) 2026 Thinline Dynamic Solutions
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>
+
+package main
+
+import "math"
+
+// Weights of the parts of a tone match confidence. Parts that can't be
+// measured, such as the SNR of an imported tone or the gap of a single tone,
+// are left out and the others share their weight.
+const (
+	toneConfidenceFrequencyWeight = 0.35
+	toneConfidenceDurationWeight  = 0.2
+	toneConfidenceSNRWeight       = 0.3
+	toneConfidenceGapWeight       = 0.15
+
+	toneConfidenceFullSNR = 20.0 // dB above the noise floor scored as a clean tone
+	toneConfidenceGoodGap = 0.1  // seconds between A and B tones scored as a clean sequence
+	toneConfidenceMaxGap  = 0.5  // seconds, the longest gap matchesToneSet accepts
+
+	// toneSNRMax caps the SNR of tones over digital silence, where the noise floor is meaningless
+	toneSNRMax = 100.0
+)
+
+// toneMatchConfidence scores, from 0 to 1, the tones that matched the specs
+// of a tone set. gap is the A to B gap in seconds when hasGap is set.
+func toneMatchConfidence(tones []Tone, specs []*ToneSpec, tolerance float64, gap float64, hasGap bool) float64 {
+	if len(tones) == 0 || len(tones) != len(specs) {
+		return 0
+	}
+
+	var frequency, duration, snr float64
+	snrCount := 0
+	for i, tone := range tones {
+		frequency += toneFrequencyScore(tone.Frequency, specs[i].Frequency, tolerance)
+		duration += toneDurationScore(tone.Duration, specs[i].MinDuration)
+		if tone.SNR > 0 {
+			snr += toneSNRScore(tone.SNR)
+			snrCount++
+		}
+	}
+
+	score := toneConfidenceFrequencyWeight*frequency/float64(len(tones)) + toneConfidenceDurationWeight*duration/float64(len(tones))
+	weight := toneConfidenceFrequencyWeight + toneConfidenceDurationWeight
+	if snrCount > 0 {
+		score += toneConfidenceSNRWeight * snr / float64(snrCount)
+		weight += toneConfidenceSNRWeight
+	}
+	if hasGap {
+		score += toneConfidenceGapWeight * toneGapScore(gap)
+		weight += toneConfidenceGapWeight
+	}
+
+	return roundConfidence(score / weight)
+}
+
+// warbleMatchConfidence scores the best warble of detected matching the tone
+// set, on the error of both frequencies and the cycles heard.
+func warbleMatchConfidence(detected *ToneSequence, toneSet ToneSet) float64 {
+	spec := toneSet.Warble
+	tolerance := toneSetTolerance(toneSet)
+
+	best := 0.0
+	for _, tone := range detected.Tones {
+		if tone.ToneType != "Warble" || tone.Cycles < spec.minCycles() {
+			continue
+		}
+		if math.Abs(tone.Frequency-spec.HighFrequency) > tolerance || math.Abs(tone.LowFrequency-spec.LowFrequency) > tolerance {
+			continue
+		}
+
+		frequency := (toneFrequencyScore(tone.Frequency, spec.HighFrequency, tolerance) + toneFrequencyScore(tone.LowFrequency, spec.LowFrequency, tolerance)) / 2
+		cycles := toneDurationScore(float64(tone.Cycles), float64(spec.minCycles()))
+		score := (toneConfidenceFrequencyWeight*frequency + toneConfidenceDurationWeight*cycles) / (toneConfidenceFrequencyWeight + toneConfidenceDurationWeight)
+		if score > best {
+			best = score
+		}
+	}
+
+	return roundConfidence(best)
+}
+
+// toneFrequencyScore falls from 1 on the expected frequency to 0 at the edge
+// of the tolerance.
+func toneFrequencyScore(detected float64, expected float64, tolerance float64) float64 {
+	if tolerance <= 0 {
+		return 1
+	}
+	return clampConfidence(1 - math.Abs(detected-expected)/tolerance)
+}
+
+// toneDurationScore is 0.5 for a tone just long enough and 1 from half as
+// long again as the minimum.
+func toneDurationScore(duration float64, minDuration float64) float64 {
+	if minDuration <= 0 {
+		return 1
+	}
+	return clampConfidence(0.5 + (duration/minDuration - 1))
+}
+
+// toneSNRScore rises from 0 at the detection gate to 1 at toneConfidenceFullSNR.
+func toneSNRScore(snr float64) float64 {
+	return clampConfidence((snr - toneDetectSNRAboveNoise) / (toneConfidenceFullSNR - toneDetectSNRAboveNoise))
+}
+
+// toneGapScore is 1 for overlapping or back to back A and B tones and falls to
+// 0.5 at the longest gap accepted.
+func toneGapScore(gap float64) float64 {
+	if gap <= toneConfidenceGoodGap {
+		return 1
+	}
+	return clampConfidence(1 - 0.5*(gap-toneConfidenceGoodGap)/(toneConfidenceMaxGap-toneConfidenceGoodGap))
+}
+
+// toneSNR is the level of a tone above the noise floor, in dB, or 0 when the
+// gates were not measured.
+func toneSNR(magnitude float64, gates toneAnalysisGates) float64 {
+	if magnitude <= 0 || gates.globalPeak < 1e-20 {
+		return 0
+	}
+	snr := 20.0*math.Log10(magnitude/gates.globalPeak) - gates.noiseFloorDB
+	return math.Round(math.Max(0, math.Min(snr, toneSNRMax))*10) / 10
+}
+
+func clampConfidence(v float64) float64 {
+	return math.Max(0, math.Min(1, v))
+}
+
+func roundConfidence(v float64) float64 {
+	return math.Round(clampConfidence(v)*100) / 100
+}
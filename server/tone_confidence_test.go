@@ -0,0 +1,55 @@
+package main
+
+import "testing"
+
+func TestToneMatchConfidence(t *testing.T) {
+	detector := NewToneDetector()
+	toneSet := ToneSet{
+		Label:     "Station 1",
+		Tolerance: 10,
+		ATone:     &ToneSpec{Frequency: 853.2, MinDuration: 0.8},
+		BTone:     &ToneSpec{Frequency: 960, MinDuration: 1.5},
+	}
+
+	clean := &ToneSequence{HasTones: true, Tones: []Tone{
+		{Frequency: 853.2, StartTime: 0, EndTime: 1.2, Duration: 1.2, SNR: 30},
+		{Frequency: 960, StartTime: 1.2, EndTime: 3.5, Duration: 2.3, SNR: 30},
+	}}
+	noisy := &ToneSequence{HasTones: true, Tones: []Tone{
+		{Frequency: 861, StartTime: 0, EndTime: 0.85, Duration: 0.85, SNR: 5},
+		{Frequency: 952, StartTime: 1.3, EndTime: 2.9, Duration: 1.6, SNR: 5},
+	}}
+
+	ok, cleanConfidence := detector.matchesToneSet(clean, toneSet)
+	if !ok || cleanConfidence != 1 {
+		t.Fatalf("clean match = %v, %.2f", ok, cleanConfidence)
+	}
+	ok, noisyConfidence := detector.matchesToneSet(noisy, toneSet)
+	if !ok || noisyConfidence >= 0.5 {
+		t.Fatalf("noisy match = %v, %.2f", ok, noisyConfidence)
+	}
+
+	matched := detector.MatchToneSets(noisy, []ToneSet{toneSet})
+	if len(matched) != 1 || matched[0].Confidence != noisyConfidence {
+		t.Fatalf("matched = %+v", matched)
+	}
+
+	toneSet.MinConfidence = 0.6
+	if matched := detector.MatchToneSets(noisy, []ToneSet{toneSet}); len(matched) != 0 {
+		t.Fatalf("match under the minimum kept: %+v", matched)
+	}
+	if matched := detector.MatchToneSets(clean, []ToneSet{toneSet}); len(matched) != 1 {
+		t.Fatalf("clean match dropped")
+	}
+}
+
+func TestToneConfidenceWithoutSNR(t *testing.T) {
+	// Imported tones have no SNR; frequency and duration share its weight
+	spec := &ToneSpec{Frequency: 1500, MinDuration: 2}
+	if got := toneMatchConfidence([]Tone{{Frequency: 1500, Duration: 3}}, []*ToneSpec{spec}, 10, 0, false); got != 1 {
+		t.Fatalf("confidence = %.2f", got)
+	}
+	if got := toneMatchConfidence([]Tone{{Frequency: 1505, Duration: 2}}, []*ToneSpec{spec}, 10, 0, false); got != 0.5 {
+		t.Fatalf("confidence = %.2f", got)
+	}
+}
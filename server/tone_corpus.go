@@ -0,0 +1,309 @@
+// Copyright (C) 2026 Thinline Dynamic Solutions
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// toneCorpusManifest is the corpus.json of a directory of labeled audio
+// fixtures: the tone sets to match against and the tones each file holds.
+type toneCorpusManifest struct {
+	FrequencyTolerance float64             `json:"frequencyTolerance"` // Hz, default 10
+	MinPrecision       float64             `json:"minPrecision"`
+	MinRecall          float64             `json:"minRecall"`
+	ToneSets           []ToneSet           `json:"toneSets"`
+	Fixtures           []toneCorpusFixture `json:"fixtures"`
+}
+
+type toneCorpusFixture struct {
+	File        string   `json:"file"`
+	Description string   `json:"description"`
+	Tones       []Tone   `json:"tones"`   // an empty toneType matches any type
+	Matches     []string `json:"matches"` // labels of the tone sets expected to match
+}
+
+// toneCorpusCounts are true positives, false positives and false negatives.
+type toneCorpusCounts struct {
+	TruePositives  int `json:"truePositives"`
+	FalsePositives int `json:"falsePositives"`
+	FalseNegatives int `json:"falseNegatives"`
+}
+
+func (counts *toneCorpusCounts) add(other toneCorpusCounts) {
+	counts.TruePositives += other.TruePositives
+	counts.FalsePositives += other.FalsePositives
+	counts.FalseNegatives += other.FalseNegatives
+}
+
+// Precision is 1 when nothing was detected.
+func (counts toneCorpusCounts) Precision() float64 {
+	if counts.TruePositives+counts.FalsePositives == 0 {
+		return 1
+	}
+	return float64(counts.TruePositives) / float64(counts.TruePositives+counts.FalsePositives)
+}
+
+// Recall is 1 when nothing was expected.
+func (counts toneCorpusCounts) Recall() float64 {
+	if counts.TruePositives+counts.FalseNegatives == 0 {
+		return 1
+	}
+	return float64(counts.TruePositives) / float64(counts.TruePositives+counts.FalseNegatives)
+}
+
+type toneCorpusResult struct {
+	File     string           `json:"file"`
+	Tones    toneCorpusCounts `json:"tones"`
+	Matches  toneCorpusCounts `json:"matches"`
+	Detected []Tone           `json:"detected"`
+	Matched  []string         `json:"matched"`
+	Err      error            `json:"-"`
+}
+
+// toneCorpusScore scores tone detection and tone set matching over a corpus.
+type toneCorpusScore struct {
+	Results []toneCorpusResult `json:"results"`
+	Tones   toneCorpusCounts   `json:"tones"`
+	Matches toneCorpusCounts   `json:"matches"`
+}
+
+func loadToneCorpus(dir string) (*toneCorpusManifest, error) {
+	b, err := os.ReadFile(filepath.Join(dir, "corpus.json"))
+	if err != nil {
+		return nil, err
+	}
+	manifest := &toneCorpusManifest{}
+	if err := json.Unmarshal(b, manifest); err != nil {
+		return nil, fmt.Errorf("corpus.json: %v", err)
+	}
+	if manifest.FrequencyTolerance == 0 {
+		manifest.FrequencyTolerance = 10
+	}
+	return manifest, nil
+}
+
+// decodeToneAudio decodes audio like production detection, falling back to
+// reading WAV files directly when ffmpeg is not available.
+func (detector *ToneDetector) decodeToneAudio(audio []byte) ([]float64, int, error) {
+	samples, sampleRate, err := detector.decodeAudioForDetect(audio, "")
+	if err != nil && len(audio) > 12 && string(audio[0:4]) == "RIFF" {
+		return detector.parseWAV(audio)
+	}
+	return samples, sampleRate, err
+}
+
+// scoreToneCorpus runs every fixture of the corpus in dir through detection
+// and matching and counts the hits and misses against the labels.
+func (detector *ToneDetector) scoreToneCorpus(dir string, manifest *toneCorpusManifest) *toneCorpusScore {
+	score := &toneCorpusScore{}
+	for _, fixture := range manifest.Fixtures {
+		result := toneCorpusResult{File: fixture.File}
+
+		audio, err := os.ReadFile(filepath.Join(dir, fixture.File))
+		if err == nil {
+			var samples []float64
+			var sampleRate int
+			if samples, sampleRate, err = detector.decodeToneAudio(audio); err == nil {
+				result.Detected = detector.analyzeFrequencies(samples, sampleRate, manifest.ToneSets, false)
+			}
+		}
+		if err != nil {
+			result.Err = err
+			result.Tones.FalseNegatives = len(fixture.Tones)
+			result.Matches.FalseNegatives = len(fixture.Matches)
+			score.add(result)
+			continue
+		}
+
+		result.Tones = scoreCorpusTones(fixture.Tones, result.Detected, manifest.FrequencyTolerance)
+
+		sequence := &ToneSequence{Tones: result.Detected, HasTones: len(result.Detected) > 0}
+		for _, toneSet := range detector.MatchToneSets(sequence, manifest.ToneSets) {
+			result.Matched = append(result.Matched, toneSet.Label)
+		}
+		result.Matches = scoreCorpusMatches(fixture.Matches, result.Matched)
+
+		score.add(result)
+	}
+	return score
+}
+
+func (score *toneCorpusScore) add(result toneCorpusResult) {
+	score.Results = append(score.Results, result)
+	score.Tones.add(result.Tones)
+	score.Matches.add(result.Matches)
+}
+
+// scoreCorpusTones pairs each expected tone with at most one detected tone of
+// the same type within tolerance.
+func scoreCorpusTones(expected []Tone, detected []Tone, tolerance float64) toneCorpusCounts {
+	counts := toneCorpusCounts{}
+	used := make([]bool, len(detected))
+	for _, want := range expected {
+		found := false
+		for i, got := range detected {
+			if used[i] || (want.ToneType != "" && want.ToneType != got.ToneType) {
+				continue
+			}
+			if math.Abs(got.Frequency-want.Frequency) > tolerance {
+				continue
+			}
+			if want.LowFrequency > 0 && math.Abs(got.LowFrequency-want.LowFrequency) > tolerance {
+				continue
+			}
+			used[i] = true
+			found = true
+			break
+		}
+		if found {
+			counts.TruePositives++
+		} else {
+			counts.FalseNegatives++
+		}
+	}
+	for _, u := range used {
+		if !u {
+			counts.FalsePositives++
+		}
+	}
+	return counts
+}
+
+func scoreCorpusMatches(expected []string, matched []string) toneCorpusCounts {
+	counts := toneCorpusCounts{}
+	remaining := map[string]int{}
+	for _, label := range matched {
+		remaining[label]++
+	}
+	for _, label := range expected {
+		if remaining[label] > 0 {
+			remaining[label]--
+			counts.TruePositives++
+		} else {
+			counts.FalseNegatives++
+		}
+	}
+	for _, n := range remaining {
+		counts.FalsePositives += n
+	}
+	return counts
+}
+
+// writeReport prints the per-fixture results and the totals.
+func (score *toneCorpusScore) writeReport(w io.Writer) {
+	for _, result := range score.Results {
+		if result.Err != nil {
+			fmt.Fprintf(w, "%-32s error: %v\n", result.File, result.Err)
+			continue
+		}
+		detected := make([]string, len(result.Detected))
+		for i, tone := range result.Detected {
+			detected[i] = describeTone(tone)
+		}
+		fmt.Fprintf(w, "%-32s tones tp=%d fp=%d fn=%d  matches tp=%d fp=%d fn=%d  [%s] -> [%s]\n", result.File,
+			result.Tones.TruePositives, result.Tones.FalsePositives, result.Tones.FalseNegatives,
+			result.Matches.TruePositives, result.Matches.FalsePositives, result.Matches.FalseNegatives,
+			strings.Join(detected, ", "), strings.Join(result.Matched, ", "))
+	}
+	fmt.Fprintf(w, "tones:   precision %.3f recall %.3f\n", score.Tones.Precision(), score.Tones.Recall())
+	fmt.Fprintf(w, "matches: precision %.3f recall %.3f\n", score.Matches.Precision(), score.Matches.Recall())
+}
+
+func describeTone(tone Tone) string {
+	toneType := tone.ToneType
+	if toneType == "" {
+		toneType = "?"
+	}
+	if tone.ToneType == "Warble" {
+		return fmt.Sprintf("%s %.1f/%.1f Hz x%d %.2f-%.2fs", toneType, tone.Frequency, tone.LowFrequency, tone.Cycles, tone.StartTime, tone.EndTime)
+	}
+	return fmt.Sprintf("%s %.1f Hz %.2f-%.2fs", toneType, tone.Frequency, tone.StartTime, tone.EndTime)
+}
+
+// runToneCheckCommand runs one audio file through tone detection and prints
+// every stage: decoding, the analysis gates, the stable segments, the
+// detected tones and the tone sets matched. toneSetsPath is an optional JSON
+// array of tone sets, or a corpus.json.
+func runToneCheckCommand(audioPath string, toneSetsPath string, w io.Writer) error {
+	audio, err := os.ReadFile(audioPath)
+	if err != nil {
+		return err
+	}
+
+	var toneSets []ToneSet
+	if toneSetsPath != "" {
+		b, err := os.ReadFile(toneSetsPath)
+		if err != nil {
+			return err
+		}
+		if err := json.Unmarshal(b, &toneSets); err != nil {
+			manifest := toneCorpusManifest{}
+			if json.Unmarshal(b, &manifest) != nil {
+				return fmt.Errorf("%s: %v", toneSetsPath, err)
+			}
+			toneSets = manifest.ToneSets
+		}
+	}
+
+	detector := NewToneDetector()
+	samples, sampleRate, err := detector.decodeToneAudio(audio)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "decoded: %d samples at %d Hz (%.2fs)\n", len(samples), sampleRate, float64(len(samples))/float64(sampleRate))
+
+	analyzed := samples
+	if maxSamples := int(toneAnalysisMaxSeconds * float64(sampleRate)); len(analyzed) > maxSamples {
+		analyzed = analyzed[:maxSamples]
+		fmt.Fprintf(w, "analysis capped at %.0fs\n", toneAnalysisMaxSeconds)
+	}
+	gates := detector.computeToneAnalysisGates(analyzed, sampleRate)
+	fmt.Fprintf(w, "gates: global peak %.4f, noise floor %.1f dB, q20 %.1f dB\n", gates.globalPeak, gates.noiseFloorDB, gates.q20)
+
+	work := cropSamplesToPagingRegion(analyzed, sampleRate)
+	fmt.Fprintf(w, "paging region: %.2fs of %.2fs\n", float64(len(work))/float64(sampleRate), float64(len(analyzed))/float64(sampleRate))
+
+	segments := pruneHarmonicMergedDetections(detector.analyzeSTFTTones(work, sampleRate, gates, warbleMinSegmentDuration(toneSets)))
+	sort.Slice(segments, func(i, j int) bool { return segments[i].startTime < segments[j].startTime })
+	fmt.Fprintf(w, "segments: %d\n", len(segments))
+	for _, md := range segments {
+		fmt.Fprintf(w, "  %.1f Hz %.2f-%.2fs (%.2fs) magnitude %.4f\n", md.frequency, md.startTime, md.endTime, md.endTime-md.startTime, md.magnitude)
+	}
+
+	tones := detector.analyzeFrequencies(samples, sampleRate, toneSets, len(toneSets) == 0)
+	fmt.Fprintf(w, "tones: %d\n", len(tones))
+	for _, tone := range tones {
+		fmt.Fprintf(w, "  %s\n", describeTone(tone))
+	}
+
+	if len(toneSets) > 0 {
+		sequence := &ToneSequence{Tones: tones, HasTones: len(tones) > 0}
+		matched := detector.MatchToneSets(sequence, toneSets)
+		fmt.Fprintf(w, "matched tone sets: %d of %d\n", len(matched), len(toneSets))
+		for _, toneSet := range matched {
+			fmt.Fprintf(w, "  %s\n", toneSet.Label)
+		}
+	}
+	return nil
+}
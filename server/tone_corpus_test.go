@@ -0,0 +1,72 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var toneCorpusDir = filepath.Join("testdata", "tones")
+
+// TestToneCorpus scores detection over the labeled fixtures with both the
+// Goertzel fast path and the full FFT sweep and fails when precision or recall
+// drops under the floors of corpus.json. Run with -v to see the per-fixture
+// results.
+func TestToneCorpus(t *testing.T) {
+	manifest, err := loadToneCorpus(toneCorpusDir)
+	if err != nil {
+		t.Fatalf("load corpus: %v", err)
+	}
+
+	for _, fftOnly := range []bool{false, true} {
+		detector := NewToneDetector()
+		detector.FFTOnly = fftOnly
+		engine := "goertzel"
+		if fftOnly {
+			engine = "fft"
+		}
+
+		score := detector.scoreToneCorpus(toneCorpusDir, manifest)
+		report := &strings.Builder{}
+		score.writeReport(report)
+		t.Log(engine + "\n" + report.String())
+
+		for _, result := range score.Results {
+			if result.Err != nil {
+				t.Errorf("%s %s: %v", engine, result.File, result.Err)
+			}
+		}
+		for name, counts := range map[string]toneCorpusCounts{"tones": score.Tones, "matches": score.Matches} {
+			if counts.Precision() < manifest.MinPrecision {
+				t.Errorf("%s %s precision %.3f is under %.3f", engine, name, counts.Precision(), manifest.MinPrecision)
+			}
+			if counts.Recall() < manifest.MinRecall {
+				t.Errorf("%s %s recall %.3f is under %.3f", engine, name, counts.Recall(), manifest.MinRecall)
+			}
+		}
+	}
+}
+
+func TestScoreCorpusTones(t *testing.T) {
+	expected := []Tone{{ToneType: "A", Frequency: 853.2}, {ToneType: "B", Frequency: 960}}
+	detected := []Tone{{ToneType: "A", Frequency: 855}, {ToneType: "A", Frequency: 960}, {ToneType: "", Frequency: 1500}}
+
+	counts := scoreCorpusTones(expected, detected, 10)
+	if counts.TruePositives != 1 || counts.FalseNegatives != 1 || counts.FalsePositives != 2 {
+		t.Fatalf("unexpected counts %+v", counts)
+	}
+	if precision := counts.Precision(); precision < 0.33 || precision > 0.34 {
+		t.Fatalf("unexpected precision %.3f", precision)
+	}
+}
+
+func BenchmarkToneCorpus(b *testing.B) {
+	manifest, err := loadToneCorpus(toneCorpusDir)
+	if err != nil {
+		b.Fatalf("load corpus: %v", err)
+	}
+	detector := NewToneDetector()
+	for i := 0; i < b.N; i++ {
+		detector.scoreToneCorpus(toneCorpusDir, manifest)
+	}
+}
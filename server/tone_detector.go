@@ -36,14 +36,21 @@ import (
 	"gonum.org/v1/gonum/dsp/fourier"
 )
 
+// toneLog reports detection details at the debug level; enable with log_subsystems=tones=debug.
+var toneLog = Logger(LogCategoryTones)
+
 // Tone represents a detected tone with frequency and timing information
 type Tone struct {
 	Frequency float64 `json:"frequency"` // Hz
 	StartTime float64 `json:"startTime"` // seconds from start of audio
 	EndTime   float64 `json:"endTime"`   // seconds from start of audio
 	Duration  float64 `json:"duration"`  // seconds
-	ToneType  string  `json:"toneType"`  // Type of tone: "A", "B", "Long", or "" if matched multiple/none
+	ToneType  string  `json:"toneType"`  // Type of tone: "A", "B", "Long", "Warble", or "" if matched multiple/none
 	Magnitude float64 `json:"magnitude,omitempty"` // FFT peak magnitude (internal scoring; not persisted)
+	SNR       float64 `json:"snr,omitempty"`       // dB above the noise floor, 0 when not measured
+	// Warble tones alternate between Frequency (high) and LowFrequency
+	LowFrequency float64 `json:"lowFrequency,omitempty"`
+	Cycles       int     `json:"cycles,omitempty"` // high-low cycles heard
 }
 
 // ToneSet represents a configured set of tones for a talkgroup
@@ -53,12 +60,25 @@ type ToneSet struct {
 	ATone       *ToneSpec `json:"aTone"`       // First tone specification (optional)
 	BTone       *ToneSpec `json:"bTone"`       // Second tone specification (optional)
 	LongTone    *ToneSpec `json:"longTone"`    // Long tone specification (optional)
+	Warble      *WarbleSpec `json:"warble,omitempty"` // Alternating hi-low pattern (optional)
 	Tolerance   float64   `json:"tolerance"`   // Frequency tolerance in Hz (default: ±10Hz)
 	MinDuration float64   `json:"minDuration"` // Minimum duration in seconds to be considered valid
 	// TonesToActive downstream forwarding (per tone set)
 	DownstreamEnabled bool   `json:"downstreamEnabled"` // Forward alerts for this tone set to an external endpoint
 	DownstreamURL     string `json:"downstreamURL"`     // Destination URL (TonesToActive server)
 	DownstreamAPIKey  string `json:"downstreamAPIKey"`  // API key sent in X-API-Key header
+	// Dispatch forwarding to a paging service (per tone set)
+	DispatchProvider string `json:"dispatchProvider,omitempty"` // "active911" or "iamresponding", empty to not forward
+	DispatchURL      string `json:"dispatchURL,omitempty"`      // Alert endpoint of the provider's API for the agency
+	DispatchToken    string `json:"dispatchToken,omitempty"`    // API token sent as a bearer token
+	DispatchAgency   string `json:"dispatchAgency,omitempty"`   // IamResponding agency name
+	// Critical tone matches are delivered during the listeners' quiet hours
+	Critical bool `json:"critical,omitempty"`
+	// Matches during a test window are recorded and tagged as a test without alerting
+	TestWindows []string `json:"testWindows,omitempty"` // cron expressions, in the system time zone
+	// Matches scoring under MinConfidence (0-1) are dropped to cut false positives on noisy channels
+	MinConfidence float64 `json:"minConfidence,omitempty"`
+	Confidence    float64 `json:"confidence,omitempty"` // Score of the match, set on matched tone sets only
 }
 
 // ToneSpec defines the expected frequency and duration ranges for a tone
@@ -78,27 +98,33 @@ type ToneSequence struct {
 	HasTones        bool       `json:"hasTones"`        // Quick flag for filtering
 	MatchedToneSet  *ToneSet   `json:"matchedToneSet"`  // Which configured tone set matched the full pattern (if any)
 	MatchedToneSets []*ToneSet `json:"matchedToneSets"` // All configured tone sets that matched any detected tone
+	Test            bool       `json:"test,omitempty"`  // Every matched tone set was in its test window
+	StitchedTo      uint64     `json:"stitchedTo,omitempty"` // First voice call of the page, on later parts of its dispatch
 }
 
 // PendingToneSequence represents tones detected on a call that are waiting to be attached to a subsequent voice call
 type PendingToneSequence struct {
-	ToneSequence *ToneSequence
-	CallId       uint64
-	Timestamp    int64 // Unix millisecond timestamp when tones were detected
-	SystemId     uint64
-	TalkgroupId  uint64
-	Locked       bool // When true, prevents new tones from merging (claimed by transcribing call)
+	ToneSequence *ToneSequence `json:"toneSequence"`
+	CallId       uint64        `json:"callId"`
+	Timestamp    int64         `json:"timestamp"` // Unix millisecond timestamp when tones were detected
+	SystemId     uint64        `json:"systemId"`
+	TalkgroupId  uint64        `json:"talkgroupId"`
+	Locked       bool          `json:"-"` // When true, prevents new tones from merging (claimed by transcribing call)
 
 	// Cross-talkgroup fields (Scenario 2: tones on TGID A, voice on TGID B)
 	// When non-zero, WindowSeconds overrides the global pendingToneTimeoutMinutes for this entry.
-	WindowSeconds uint
+	WindowSeconds uint `json:"windowSeconds,omitempty"`
 	// MinVoiceDurationSeconds filters out mic-click false positives on the linked voice channel.
 	// A voice call shorter than this many seconds will not claim these pending tones.
-	MinVoiceDurationSeconds uint
+	MinVoiceDurationSeconds uint `json:"minVoiceDurationSeconds,omitempty"`
 	// CrossTalkgroupSourceKey is set on cross-talkgroup watch entries. When this entry is consumed
 	// it is used to also clean up the source talkgroup's own pending-tones entry so a second alert
 	// is not fired if a voice call later arrives on the original (tone) talkgroup.
-	CrossTalkgroupSourceKey string
+	CrossTalkgroupSourceKey string `json:"crossTalkgroupSourceKey,omitempty"`
+
+	// StitchedCalls counts the voice calls of a page on stitch entries, where CallId is the
+	// first voice call and Timestamp the end of the last one.
+	StitchedCalls uint `json:"stitchedCalls,omitempty"`
 }
 
 // ToneDetector handles tone detection in audio calls
@@ -111,6 +137,7 @@ type ToneDetector struct {
 		Min float64 // Minimum frequency to detect (Hz)
 		Max float64 // Maximum frequency to detect (Hz)
 	}
+	FFTOnly bool // Skip the Goertzel fast path and always run the full FFT sweep
 }
 
 // NewToneDetector creates a new tone detector with default settings
@@ -135,7 +162,7 @@ func (detector *ToneDetector) Detect(audio []byte, audioMime string, toneSets []
 		return &ToneSequence{Tones: []Tone{}, HasTones: false}, nil
 	}
 
-	samples, sampleRate, err := detector.decodeAudioForDetect(audio)
+	samples, sampleRate, err := detector.decodeAudioForDetect(audio, audioMime)
 	if err != nil {
 		return nil, err
 	}
@@ -147,7 +174,7 @@ func (detector *ToneDetector) Detect(audio []byte, audioMime string, toneSets []
 	detectedTones := detector.analyzeFrequencies(samples, sampleRate, toneSets, false)
 
 	// Log tone detection analysis
-	fmt.Printf("tone detection: analyzed %d samples at %d Hz, found %d potential tone detections\n", len(samples), sampleRate, len(detectedTones))
+	toneLog.Debug(fmt.Sprintf("tone detection: analyzed %d samples at %d Hz, found %d potential tone detections", len(samples), sampleRate, len(detectedTones)))
 
 	if len(detectedTones) == 0 {
 		return &ToneSequence{Tones: []Tone{}, HasTones: false}, nil
@@ -212,10 +239,16 @@ const (
 
 // cropSamplesToPagingRegion trims leading/trailing silence using a short-hop energy envelope.
 func cropSamplesToPagingRegion(samples []float64, sampleRate int) []float64 {
+	start, end := pagingRegionBounds(samples, sampleRate)
+	return samples[start:end]
+}
+
+// pagingRegionBounds returns the sample range cropSamplesToPagingRegion keeps.
+func pagingRegionBounds(samples []float64, sampleRate int) (int, int) {
 	const envWindow = 512
 	const envHop = 128
 	if len(samples) < envWindow*2 {
-		return samples
+		return 0, len(samples)
 	}
 
 	type envFrame struct {
@@ -237,7 +270,7 @@ func cropSamplesToPagingRegion(samples []float64, sampleRate int) []float64 {
 		frames = append(frames, envFrame{start: start, rms: rms})
 	}
 	if global < 1e-20 || len(frames) == 0 {
-		return samples
+		return 0, len(samples)
 	}
 
 	threshold := global * math.Pow(10, toneDetectPagingGateDB/20.0)
@@ -251,7 +284,7 @@ func cropSamplesToPagingRegion(samples []float64, sampleRate int) []float64 {
 		}
 	}
 	if first < 0 {
-		return samples
+		return 0, len(samples)
 	}
 
 	startSample := frames[first].start
@@ -266,9 +299,9 @@ func cropSamplesToPagingRegion(samples []float64, sampleRate int) []float64 {
 		endSample = len(samples)
 	}
 	if endSample <= startSample {
-		return samples
+		return 0, len(samples)
 	}
-	return samples[startSample:endSample]
+	return startSample, endSample
 }
 
 type toneFreqDetection struct {
@@ -335,46 +368,14 @@ func pruneHarmonicMergedDetections(merged []mergedDetection) []mergedDetection {
 }
 
 // decodeAudioForDetect decodes call audio to mono PCM for production Detect and auto-learn Discover.
-func (detector *ToneDetector) decodeAudioForDetect(audio []byte) ([]float64, int, error) {
-	ffArgs := []string{
-		"-i", "pipe:0",
-		"-ar", "16000",
-		"-ac", "1",
-		"-af", "highpass=f=200,lowpass=f=3000,dynaudnorm",
-		"-f", "wav",
-		"-loglevel", "error",
-		"pipe:1",
-	}
-
-	ffCmd := exec.Command("ffmpeg", ffArgs...)
-	stdin, err := ffCmd.StdinPipe()
+// The decode is shared with the other ingest stages (see decodeAudioShared) and filtered with
+// highpass=f=200,lowpass=f=3000,dynaudnorm; the samples must not be modified.
+func (detector *ToneDetector) decodeAudioForDetect(audio []byte, audioMime string) ([]float64, int, error) {
+	decoded, err := decodeAudioShared(audio, audioMime)
 	if err != nil {
-		return nil, 0, fmt.Errorf("failed to create stdin pipe: %v", err)
-	}
-
-	var wavData bytes.Buffer
-	var ffErr bytes.Buffer
-	ffCmd.Stdout = &wavData
-	ffCmd.Stderr = &ffErr
-
-	if err := ffCmd.Start(); err != nil {
-		return nil, 0, fmt.Errorf("failed to start ffmpeg: %v", err)
+		return nil, 0, err
 	}
-
-	go func() {
-		defer stdin.Close()
-		stdin.Write(audio)
-	}()
-
-	if err := ffCmd.Wait(); err != nil {
-		return nil, 0, fmt.Errorf("ffmpeg conversion failed: %v, stderr: %s", err, ffErr.String())
-	}
-
-	if wavData.Len() == 0 {
-		return nil, 0, fmt.Errorf("ffmpeg produced no output")
-	}
-
-	return detector.parseWAV(wavData.Bytes())
+	return decoded.Tones, toneDecodeHz, nil
 }
 
 // Discover analyzes audio and returns all sustained tones (matched or not) for auto-learn.
@@ -383,7 +384,7 @@ func (detector *ToneDetector) Discover(audio []byte, audioMime string) ([]Tone,
 		return []Tone{}, nil
 	}
 
-	samples, sampleRate, err := detector.decodeAudioForDetect(audio)
+	samples, sampleRate, err := detector.decodeAudioForDetect(audio, audioMime)
 	if err != nil {
 		return nil, err
 	}
@@ -472,15 +473,36 @@ func (detector *ToneDetector) analyzeFrequencies(samples []float64, sampleRate i
 	}
 
 	minToneDuration := toneDetectMinDurationSec
-	gates := detector.computeToneAnalysisGates(samples, sampleRate)
-	if gates.globalPeak < 1e-20 {
-		return []Tone{}
+	var segments []mergedDetection
+	var gates toneAnalysisGates
+	if bank := detector.goertzelBankFor(toneSets, sampleRate, includeUnmatched); bank != nil {
+		// Configured tone sets only need their own frequencies: evaluate those with
+		// the Goertzel bank instead of sweeping the whole spectrum
+		segments, gates = detector.analyzeGoertzelTones(samples, sampleRate, bank, warbleMinSegmentDuration(toneSets))
+		if gates.globalPeak < 1e-20 {
+			return []Tone{}
+		}
+	} else {
+		gates = detector.computeToneAnalysisGates(samples, sampleRate)
+		if gates.globalPeak < 1e-20 {
+			return []Tone{}
+		}
+		toneLog.Debug(fmt.Sprintf("tone detection: global peak=%.4f, noise floor=%.1f dB, q20=%.1f dB", gates.globalPeak, gates.noiseFloorDB, gates.q20))
+
+		work := cropSamplesToPagingRegion(samples, sampleRate)
+		segments = detector.analyzeSTFTTones(work, sampleRate, gates, warbleMinSegmentDuration(toneSets))
 	}
-	fmt.Printf("tone detection: global peak=%.4f, noise floor=%.1f dB, q20=%.1f dB\n", gates.globalPeak, gates.noiseFloorDB, gates.q20)
+	segments = pruneHarmonicMergedDetections(segments)
 
-	work := cropSamplesToPagingRegion(samples, sampleRate)
-	mergedDetections := detector.analyzeSTFTTones(work, sampleRate, gates)
-	mergedDetections = pruneHarmonicMergedDetections(mergedDetections)
+	// Warble segments can be shorter than a tone; only sustained segments are
+	// matched as A, B or long tones
+	warbles := findWarbleTones(segments, toneSets)
+	var mergedDetections []mergedDetection
+	for _, md := range segments {
+		if md.endTime-md.startTime >= minToneDuration {
+			mergedDetections = append(mergedDetections, md)
+		}
+	}
 
 	var tones []Tone
 	var allDetections []toneFreqDetection
@@ -586,9 +608,9 @@ func (detector *ToneDetector) analyzeFrequencies(samples []float64, sampleRate i
 		// Log merged detection (showing merge info if multiple detections were merged)
 		if matched {
 			if md.count > 1 {
-				fmt.Printf("tone matched - %.1f Hz (merged from %d detections) for %.2fs (matched: %s)\n", md.frequency, md.count, duration, strings.Join(matchedToneSets, ", "))
+				toneLog.Debug(fmt.Sprintf("tone matched - %.1f Hz (merged from %d detections) for %.2fs (matched: %s)", md.frequency, md.count, duration, strings.Join(matchedToneSets, ", ")))
 			} else {
-				fmt.Printf("tone matched - %.1f Hz for %.2fs (matched: %s)\n", md.frequency, duration, strings.Join(matchedToneSets, ", "))
+				toneLog.Debug(fmt.Sprintf("tone matched - %.1f Hz for %.2fs (matched: %s)", md.frequency, duration, strings.Join(matchedToneSets, ", ")))
 			}
 			tones = append(tones, Tone{
 				Frequency: md.frequency,
@@ -597,6 +619,7 @@ func (detector *ToneDetector) analyzeFrequencies(samples []float64, sampleRate i
 				Duration:  duration,
 				ToneType:  toneType,
 				Magnitude: md.magnitude,
+				SNR:       toneSNR(md.magnitude, gates),
 			})
 		} else if includeUnmatched {
 			seqType := ""
@@ -613,13 +636,14 @@ func (detector *ToneDetector) analyzeFrequencies(samples []float64, sampleRate i
 				Duration:  duration,
 				ToneType:  seqType,
 				Magnitude: md.magnitude,
+				SNR:       toneSNR(md.magnitude, gates),
 			})
 		} else {
 			// Log what we were looking for vs what was detected
 			if md.count > 1 {
-				fmt.Printf("tone detected but NO MATCH - %.1f Hz (merged from %d detections) for %.2fs (mag: %.4f)\n", md.frequency, md.count, duration, md.magnitude)
+				toneLog.Debug(fmt.Sprintf("tone detected but NO MATCH - %.1f Hz (merged from %d detections) for %.2fs (mag: %.4f)", md.frequency, md.count, duration, md.magnitude))
 			} else {
-				fmt.Printf("tone detected but NO MATCH - %.1f Hz for %.2fs (mag: %.4f)\n", md.frequency, duration, md.magnitude)
+				toneLog.Debug(fmt.Sprintf("tone detected but NO MATCH - %.1f Hz for %.2fs (mag: %.4f)", md.frequency, duration, md.magnitude))
 			}
 			// Show closest configured tones for debugging
 			if len(toneSets) > 0 {
@@ -662,20 +686,25 @@ func (detector *ToneDetector) analyzeFrequencies(samples []float64, sampleRate i
 					}
 				}
 				if closestTone != "" {
-					fmt.Printf("closest configured tone: %s\n", closestTone)
+					toneLog.Debug(fmt.Sprintf("closest configured tone: %s", closestTone))
 				}
 			}
 		}
 	}
 
+	for _, warble := range warbles {
+		toneLog.Debug(fmt.Sprintf("warble matched - %.1f/%.1f Hz, %d cycles for %.2fs", warble.Frequency, warble.LowFrequency, warble.Cycles, warble.Duration))
+	}
+	tones = append(tones, warbles...)
+
 	// Log summary
 	if len(allDetections) > 0 {
-		fmt.Printf("total detections meeting duration: %d, merged to: %d, matched: %d\n", len(allDetections), len(mergedDetections), len(tones))
+		toneLog.Debug(fmt.Sprintf("total detections meeting duration: %d, merged to: %d, matched: %d", len(allDetections), len(mergedDetections), len(tones)))
 		if len(allDetections) != len(mergedDetections) {
-			fmt.Printf("DEBUG: merged %d detections into %d (removed %d duplicates)\n", len(allDetections), len(mergedDetections), len(allDetections)-len(mergedDetections))
+			toneLog.Debug(fmt.Sprintf("merged %d detections into %d (removed %d duplicates)", len(allDetections), len(mergedDetections), len(allDetections)-len(mergedDetections)))
 		}
 	} else {
-		fmt.Printf("no tones detected meeting minimum duration (%.1fs)\n", minToneDuration)
+		toneLog.Debug(fmt.Sprintf("no tones detected meeting minimum duration (%.1fs)", minToneDuration))
 	}
 
 	return tones
@@ -721,6 +750,7 @@ func (detector *ToneDetector) MatchToneSet(detected *ToneSequence, configured []
 
 // MatchToneSets matches detected tones against configured tone sets and returns ALL matches
 // This is used for stacked tones where multiple tone sequences may be detected across calls
+// Each match carries its Confidence; matches under the tone set's MinConfidence are dropped
 func (detector *ToneDetector) MatchToneSets(detected *ToneSequence, configured []ToneSet) []*ToneSet {
 	if detected == nil || !detected.HasTones || len(configured) == 0 {
 		return nil
@@ -729,18 +759,45 @@ func (detector *ToneDetector) MatchToneSets(detected *ToneSequence, configured [
 	var matched []*ToneSet
 	for i := range configured {
 		toneSet := configured[i]
-		if detector.matchesToneSet(detected, toneSet) {
-			matched = append(matched, &toneSet)
+		ok, confidence := detector.matchesToneSet(detected, toneSet)
+		if !ok {
+			continue
 		}
+		if confidence < toneSet.MinConfidence {
+			toneLog.Debug(fmt.Sprintf("Tone set '%s' matched with confidence %.2f, under its minimum %.2f", toneSet.Label, confidence, toneSet.MinConfidence))
+			continue
+		}
+		toneSet.Confidence = confidence
+		matched = append(matched, &toneSet)
 	}
 
 	return matched
 }
 
-// matchesToneSet checks if detected tones match a configured tone set
+// matchesToneSet checks if detected tones match a configured tone set, and scores the best match
 // Requires that A-tone and B-tone come from the same sequence (A-tone before B-tone)
-func (detector *ToneDetector) matchesToneSet(detected *ToneSequence, toneSet ToneSet) bool {
+func (detector *ToneDetector) matchesToneSet(detected *ToneSequence, toneSet ToneSet) (bool, float64) {
+	// A warble pattern is required on top of any A/B/long tones
+	warbleConfidence := 1.0
+	if toneSet.Warble != nil {
+		if !warbleMatches(detected, toneSet) {
+			return false, 0
+		}
+		warbleConfidence = warbleMatchConfidence(detected, toneSet)
+		if toneSet.ATone == nil && toneSet.BTone == nil && toneSet.LongTone == nil {
+			return true, warbleConfidence
+		}
+	}
+
+	// The match is as confident as its weakest part
+	matched, confidence := detector.matchesToneSetTones(detected, toneSet)
+	return matched, math.Min(confidence, warbleConfidence)
+}
+
+// matchesToneSetTones checks the A, B and long tones of a tone set
+func (detector *ToneDetector) matchesToneSetTones(detected *ToneSequence, toneSet ToneSet) (bool, float64) {
 	baseTolerance := toneSet.Tolerance
+	tolerance := toneSetTolerance(toneSet)
 
 	// If tone set only has a long tone (no A/B tones), only check for long tone
 	if toneSet.LongTone != nil && toneSet.ATone == nil && toneSet.BTone == nil {
@@ -749,18 +806,19 @@ func (detector *ToneDetector) matchesToneSet(detected *ToneSequence, toneSet Ton
 			actualTolerance = baseTolerance * 500.0
 		}
 
+		matched, best := false, 0.0
 		for _, tone := range detected.Tones {
 			if detector.frequencyMatches(tone.Frequency, toneSet.LongTone.Frequency, actualTolerance) {
 				if tone.Duration >= toneSet.LongTone.MinDuration {
 					if toneSet.LongTone.MaxDuration == 0 || tone.Duration <= toneSet.LongTone.MaxDuration {
-						// Found matching long tone
-						return true
+						// Found matching long tone, keep the best scoring one
+						matched = true
+						best = math.Max(best, toneMatchConfidence([]Tone{tone}, []*ToneSpec{toneSet.LongTone}, tolerance, 0, false))
 					}
 				}
 			}
 		}
-		// No matching long tone found
-		return false
+		return matched, best
 	}
 
 	// Find matching A-tone(s) and B-tone(s) with timing
@@ -810,14 +868,14 @@ func (detector *ToneDetector) matchesToneSet(detected *ToneSequence, toneSet Ton
 
 	// Require A-tone if configured
 	if toneSet.ATone != nil && len(aTones) == 0 {
-		fmt.Printf("DEBUG: Tone set '%s' requires A-tone but none found\n", toneSet.Label)
-		return false
+		toneLog.Debug(fmt.Sprintf("Tone set '%s' requires A-tone but none found", toneSet.Label))
+		return false, 0
 	}
 
 	// Require B-tone if configured
 	if toneSet.BTone != nil && len(bTones) == 0 {
-		fmt.Printf("DEBUG: Tone set '%s' requires B-tone but none found\n", toneSet.Label)
-		return false
+		toneLog.Debug(fmt.Sprintf("Tone set '%s' requires B-tone but none found", toneSet.Label))
+		return false, 0
 	}
 
 	// Note: If tone set has A/B tones, we do NOT check for long tones
@@ -827,7 +885,7 @@ func (detector *ToneDetector) matchesToneSet(detected *ToneSequence, toneSet Ton
 	// Each A-tone must be paired with its closest following B-tone (within 0.5s)
 	// This prevents false matches where an A-tone pairs with a B-tone from a different tone sequence
 	if toneSet.ATone != nil && toneSet.BTone != nil {
-		fmt.Printf("DEBUG: Checking sequence for tone set '%s' - found %d A-tones and %d B-tones\n", toneSet.Label, len(aTones), len(bTones))
+		toneLog.Debug(fmt.Sprintf("Checking sequence for tone set '%s' - found %d A-tones and %d B-tones", toneSet.Label, len(aTones), len(bTones)))
 
 		// Sort A-tones by start time to process them in sequence
 		aTonesSorted := make([]matchingTone, len(aTones))
@@ -838,9 +896,10 @@ func (detector *ToneDetector) matchesToneSet(detected *ToneSequence, toneSet Ton
 
 		// Check each A-tone against the tone set's B-tone
 		// Each A-tone must find its closest following B-tone that matches this tone set
+		matched, best := false, 0.0
 		for _, aMatch := range aTonesSorted {
-			fmt.Printf("DEBUG: A-tone %.1f Hz: start=%.2fs, end=%.2fs, duration=%.2fs\n",
-				aMatch.tone.Frequency, aMatch.tone.StartTime, aMatch.tone.EndTime, aMatch.tone.Duration)
+			toneLog.Debug(fmt.Sprintf("A-tone %.1f Hz: start=%.2fs, end=%.2fs, duration=%.2fs",
+				aMatch.tone.Frequency, aMatch.tone.StartTime, aMatch.tone.EndTime, aMatch.tone.Duration))
 
 			// Find the closest following B-tone within 0.5s gap
 			// "Closest" means the smallest gap (either negative for overlap, or positive for sequential)
@@ -851,14 +910,14 @@ func (detector *ToneDetector) matchesToneSet(detected *ToneSequence, toneSet Ton
 			for i := range bTones {
 				bMatch := &bTones[i]
 
-				fmt.Printf("DEBUG:   Checking B-tone %.1f Hz: start=%.2fs, end=%.2fs, duration=%.2fs\n",
-					bMatch.tone.Frequency, bMatch.tone.StartTime, bMatch.tone.EndTime, bMatch.tone.Duration)
+				toneLog.Debug(fmt.Sprintf("Checking B-tone %.1f Hz: start=%.2fs, end=%.2fs, duration=%.2fs",
+					bMatch.tone.Frequency, bMatch.tone.StartTime, bMatch.tone.EndTime, bMatch.tone.Duration))
 
 				// B-tone must START after A-tone starts (allows overlapping tones)
 				// This supports both sequential (A then B) and overlapping (A+B simultaneously) two-tone paging
 				if bMatch.tone.StartTime < aMatch.tone.StartTime {
-					fmt.Printf("DEBUG:     REJECTED: B-tone starts (%.2fs) before A-tone starts (%.2fs)\n",
-						bMatch.tone.StartTime, aMatch.tone.StartTime)
+					toneLog.Debug(fmt.Sprintf("REJECTED: B-tone starts (%.2fs) before A-tone starts (%.2fs)",
+						bMatch.tone.StartTime, aMatch.tone.StartTime))
 					continue // B starts before A starts, not a valid sequence
 				}
 
@@ -866,8 +925,8 @@ func (detector *ToneDetector) matchesToneSet(detected *ToneSequence, toneSet Ton
 				// Negative = overlapping (B starts before A ends) - this is OK now!
 				// Positive = sequential (B starts after A ends)
 				gap := bMatch.tone.StartTime - aMatch.tone.EndTime
-				fmt.Printf("DEBUG:     Gap: %.2fs (B start %.2fs - A end %.2fs)\n",
-					gap, bMatch.tone.StartTime, aMatch.tone.EndTime)
+				toneLog.Debug(fmt.Sprintf("Gap: %.2fs (B start %.2fs - A end %.2fs)",
+					gap, bMatch.tone.StartTime, aMatch.tone.EndTime))
 
 				// Allow overlap up to full duration of A-tone, or sequential up to 0.5s gap
 				// This handles overlapping two-tone paging (gap will be negative)
@@ -878,25 +937,25 @@ func (detector *ToneDetector) matchesToneSet(detected *ToneSequence, toneSet Ton
 						closestB = bMatch
 						closestGap = gap
 						hasClosest = true
-						fmt.Printf("DEBUG:     ACCEPTED as closest (gap=%.2fs)\n", gap)
+						toneLog.Debug(fmt.Sprintf("ACCEPTED as closest (gap=%.2fs)", gap))
 					} else {
 						// Compare absolute gaps - want the one closest to 0
 						if math.Abs(gap) < math.Abs(closestGap) {
 							closestB = bMatch
 							closestGap = gap
-							fmt.Printf("DEBUG:     ACCEPTED as new closest (gap=%.2fs, prev=%.2fs)\n", gap, closestGap)
+							toneLog.Debug(fmt.Sprintf("ACCEPTED as new closest (gap=%.2fs, prev=%.2fs)", gap, closestGap))
 						} else {
-							fmt.Printf("DEBUG:     Not closer than current closest (gap=%.2fs vs %.2fs)\n", gap, closestGap)
+							toneLog.Debug(fmt.Sprintf("Not closer than current closest (gap=%.2fs vs %.2fs)", gap, closestGap))
 						}
 					}
 				} else {
-					fmt.Printf("DEBUG:     REJECTED: Gap %.2fs outside of -0.5s to +0.5s range\n", gap)
+					toneLog.Debug(fmt.Sprintf("REJECTED: Gap %.2fs outside of -0.5s to +0.5s range", gap))
 				}
 			}
 
 			// If we found a closest B-tone, check if it matches this tone set's B-tone frequency
 			if closestB != nil {
-				fmt.Printf("DEBUG:   Found closest B-tone: %.1f Hz with gap=%.2fs\n", closestB.tone.Frequency, closestGap)
+				toneLog.Debug(fmt.Sprintf("Found closest B-tone: %.1f Hz with gap=%.2fs", closestB.tone.Frequency, closestGap))
 				// Check if the closest B-tone matches the tone set's B-tone frequency
 				actualTolerance := baseTolerance
 				if baseTolerance < 1.0 {
@@ -906,23 +965,34 @@ func (detector *ToneDetector) matchesToneSet(detected *ToneSequence, toneSet Ton
 				if detector.frequencyMatches(closestB.tone.Frequency, toneSet.BTone.Frequency, actualTolerance) {
 					// Found a valid A-B pair where A-tone pairs with its closest B-tone
 					// and that closest B-tone matches this tone set's B-tone
-					fmt.Printf("DEBUG: MATCH! Tone set '%s' matched with A-B sequence\n", toneSet.Label)
-					return true
+					toneLog.Debug(fmt.Sprintf("MATCH! Tone set '%s' matched with A-B sequence", toneSet.Label))
+					matched = true
+					best = math.Max(best, toneMatchConfidence([]Tone{aMatch.tone, closestB.tone}, []*ToneSpec{toneSet.ATone, toneSet.BTone}, tolerance, closestGap, true))
 				} else {
-					fmt.Printf("DEBUG:   B-tone frequency %.1f Hz does NOT match expected %.1f Hz (tol: ±%.1f Hz)\n",
-						closestB.tone.Frequency, toneSet.BTone.Frequency, actualTolerance)
+					toneLog.Debug(fmt.Sprintf("B-tone frequency %.1f Hz does NOT match expected %.1f Hz (tol: ±%.1f Hz)",
+						closestB.tone.Frequency, toneSet.BTone.Frequency, actualTolerance))
 				}
 			} else {
-				fmt.Printf("DEBUG:   No valid B-tone found within 0.5s of this A-tone\n")
+				toneLog.Debug("No valid B-tone found within 0.5s of this A-tone")
 			}
 		}
 
-		fmt.Printf("DEBUG: No valid A-B sequence found for tone set '%s'\n", toneSet.Label)
 		// No valid A-B pair found where A pairs with closest B-tone that matches this tone set
-		return false
+		if !matched {
+			toneLog.Debug(fmt.Sprintf("No valid A-B sequence found for tone set '%s'", toneSet.Label))
+		}
+		return matched, best
 	}
 
-	return true
+	// A single configured A or B tone, scored on its best match
+	best := 0.0
+	for _, aMatch := range aTones {
+		best = math.Max(best, toneMatchConfidence([]Tone{aMatch.tone}, []*ToneSpec{toneSet.ATone}, tolerance, 0, false))
+	}
+	for _, bMatch := range bTones {
+		best = math.Max(best, toneMatchConfidence([]Tone{bMatch.tone}, []*ToneSpec{toneSet.BTone}, tolerance, 0, false))
+	}
+	return true, best
 }
 
 // frequencyMatches checks if a detected frequency matches an expected frequency within tolerance
@@ -942,6 +1012,12 @@ func ParseToneSets(jsonData string) ([]ToneSet, error) {
 		return nil, fmt.Errorf("failed to parse tone sets: %v", err)
 	}
 
+	for i := range toneSets {
+		toneSets[i].TestWindows = validToneTestWindows(toneSets[i].TestWindows)
+		toneSets[i].MinConfidence = clampConfidence(toneSets[i].MinConfidence)
+		toneSets[i].Confidence = 0
+	}
+
 	return toneSets, nil
 }
 
@@ -1097,14 +1173,14 @@ func (detector *ToneDetector) RemoveTonesFromAudio(audio []byte, audioMime strin
 			// Keep segments that are at least 0.3s (300ms) - shorter segments are likely artifacts
 			if segmentDuration >= 0.3 {
 				keepSegments = append(keepSegments, segment{currentPos, toneStart})
-				fmt.Printf("audio filtering: keeping voice segment %.3fs-%.3fs (%.2fs)\n", currentPos, toneStart, segmentDuration)
+				toneLog.Debug(fmt.Sprintf("audio filtering: keeping voice segment %.3fs-%.3fs (%.2fs)", currentPos, toneStart, segmentDuration))
 			} else {
-				fmt.Printf("audio filtering: skipping short segment %.3fs-%.3fs (%.2fs, likely artifact)\n", currentPos, toneStart, segmentDuration)
+				toneLog.Debug(fmt.Sprintf("audio filtering: skipping short segment %.3fs-%.3fs (%.2fs, likely artifact)", currentPos, toneStart, segmentDuration))
 			}
 		}
 
 		// Skip the tone itself
-		fmt.Printf("audio filtering: removing tone %.3fs-%.3fs (%.2fs at %.1fHz)\n", toneStart, toneEnd, tone.Duration, tone.Frequency)
+		toneLog.Debug(fmt.Sprintf("audio filtering: removing tone %.3fs-%.3fs (%.2fs at %.1fHz)", toneStart, toneEnd, tone.Duration, tone.Frequency))
 		currentPos = toneEnd
 	}
 
@@ -1114,13 +1190,13 @@ func (detector *ToneDetector) RemoveTonesFromAudio(audio []byte, audioMime strin
 		// Always keep the final segment if it exists, as it's likely voice after tones
 		if segmentDuration >= 0.1 {
 			keepSegments = append(keepSegments, segment{currentPos, totalDuration})
-			fmt.Printf("audio filtering: keeping final voice segment %.3fs-%.3fs (%.2fs)\n", currentPos, totalDuration, segmentDuration)
+			toneLog.Debug(fmt.Sprintf("audio filtering: keeping final voice segment %.3fs-%.3fs (%.2fs)", currentPos, totalDuration, segmentDuration))
 		}
 	}
 
 	// If no segments to keep, return empty (all tones)
 	if len(keepSegments) == 0 {
-		fmt.Printf("audio filtering: all audio is tones, returning original\n")
+		toneLog.Debug("audio filtering: all audio is tones, returning original")
 		return audio, nil
 	}
 
@@ -1154,8 +1230,8 @@ func (detector *ToneDetector) RemoveTonesFromAudio(audio []byte, audioMime strin
 		"pipe:1", // Write to stdout
 	}
 
-	fmt.Printf("audio filtering: removing %d tone segments (%.2fs of tones from %.2fs total)\n",
-		len(sortedTones), calculateTotalToneDuration(sortedTones), totalDuration)
+	toneLog.Debug(fmt.Sprintf("audio filtering: removing %d tone segments (%.2fs of tones from %.2fs total)",
+		len(sortedTones), calculateTotalToneDuration(sortedTones), totalDuration))
 
 	ffCmd := exec.Command("ffmpeg", ffArgs...)
 
@@ -1190,12 +1266,12 @@ func (detector *ToneDetector) RemoveTonesFromAudio(audio []byte, audioMime strin
 
 	// Verify we got something back
 	if len(filteredAudioBytes) < 1000 {
-		fmt.Printf("audio filtering: filtered audio too small (%d bytes), returning original\n", len(filteredAudioBytes))
+		toneLog.Debug(fmt.Sprintf("audio filtering: filtered audio too small (%d bytes), returning original", len(filteredAudioBytes)))
 		return audio, nil
 	}
 
-	fmt.Printf("audio filtering: success - original: %d bytes, filtered: %d bytes (removed %.1f%%)\n",
-		len(audio), len(filteredAudioBytes), (1.0-float64(len(filteredAudioBytes))/float64(len(audio)))*100)
+	toneLog.Debug(fmt.Sprintf("audio filtering: success - original: %d bytes, filtered: %d bytes (removed %.1f%%)",
+		len(audio), len(filteredAudioBytes), (1.0-float64(len(filteredAudioBytes))/float64(len(audio)))*100))
 
 	return filteredAudioBytes, nil
 }
@@ -1209,7 +1285,7 @@ func calculateTotalToneDuration(tones []Tone) float64 {
 	return total
 }
 
-// DetectAllTonesForTranscription detects ALL sustained tones in audio (200-5000Hz range)
+// DetectAllTonesForTranscription detects ALL sustained tones in audio (200-3000Hz range)
 // regardless of whether they match configured tone sets. This is used to remove dispatch tones
 // before transcription to prevent Whisper hallucinations.
 // Returns all detected tones that meet minimum duration requirements.
@@ -1218,53 +1294,10 @@ func (detector *ToneDetector) DetectAllTonesForTranscription(audio []byte, audio
 		return []Tone{}, nil
 	}
 
-	// Convert audio to WAV PCM format using ffmpeg via stdin/stdout pipes
-	ffArgs := []string{
-		"-y", "-loglevel", "error",
-		"-i", "pipe:0", // Read from stdin
-		"-ar", "16000", // 16kHz sample rate
-		"-ac", "1", // Mono
-		"-af", "highpass=f=200,lowpass=f=5000,dynaudnorm", // Detect tones in dispatch range
-		"-f", "wav",
-		"pipe:1", // Write to stdout
-	}
-	ffCmd := exec.Command("ffmpeg", ffArgs...)
-
-	// Setup stdin for ffmpeg
-	stdin, err := ffCmd.StdinPipe()
-	if err != nil {
-		return nil, fmt.Errorf("failed to create stdin pipe for ffmpeg: %v", err)
-	}
-	go func() {
-		defer stdin.Close()
-		_, _ = stdin.Write(audio)
-	}()
-
-	// Setup stdout for ffmpeg
-	var wavData bytes.Buffer
-	ffCmd.Stdout = &wavData
-	var ffErr bytes.Buffer
-	ffCmd.Stderr = &ffErr
-
-	// Run ffmpeg
-	if err := ffCmd.Start(); err != nil {
-		return nil, fmt.Errorf("failed to start ffmpeg: %v", err)
-	}
-
-	// Wait for ffmpeg to complete
-	if err := ffCmd.Wait(); err != nil {
-		return nil, fmt.Errorf("ffmpeg conversion failed: %v, stderr: %s", err, ffErr.String())
-	}
-
-	// Get the WAV data from stdout
-	if wavData.Len() == 0 {
-		return nil, fmt.Errorf("ffmpeg produced no output")
-	}
-
-	// Parse WAV and extract PCM samples
-	samples, sampleRate, err := detector.parseWAV(wavData.Bytes())
+	// Reuse the decode of tone detection when the call was already checked for tones
+	samples, sampleRate, err := detector.decodeAudioForDetect(audio, audioMime)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse WAV: %v", err)
+		return nil, err
 	}
 
 	if len(samples) < 100 {
@@ -1275,7 +1308,7 @@ func (detector *ToneDetector) DetectAllTonesForTranscription(audio []byte, audio
 	// Use aggressive detection parameters to catch all dispatch tones
 	detectedTones := detector.detectAllSustainedTones(samples, sampleRate)
 
-	fmt.Printf("transcription tone detection: found %d sustained tones to remove before transcription\n", len(detectedTones))
+	toneLog.Debug(fmt.Sprintf("transcription tone detection: found %d sustained tones to remove before transcription", len(detectedTones)))
 
 	return detectedTones, nil
 }
@@ -1517,8 +1550,8 @@ func (detector *ToneDetector) detectAllSustainedTones(samples []float64, sampleR
 				Duration:  duration,
 				ToneType:  "", // Not matched to any tone set
 			})
-			fmt.Printf("detected tone for removal: %.1f Hz for %.2fs (%.2f-%.2fs)\n",
-				md.frequency, duration, md.startTime, md.endTime)
+			toneLog.Debug(fmt.Sprintf("detected tone for removal: %.1f Hz for %.2fs (%.2f-%.2fs)",
+				md.frequency, duration, md.startTime, md.endTime))
 		}
 	}
 
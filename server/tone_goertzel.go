@@ -0,0 +1,265 @@
+// Copyright (C) 2026 Thinline Dynamic Solutions
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>
+
+package main
+
+import (
+	"fmt"
+	"math"
+)
+
+// goertzelMinTonality is the share of a frame's energy that has to sit at the probed
+// frequency before the frame counts as a tone. A clean tone scores ~1, a tone over a
+// silent half window ~0.5, voice and static well under 0.1.
+const goertzelMinTonality = 0.3
+
+// goertzelTarget is one configured frequency and its slice of probes in the bank.
+type goertzelTarget struct {
+	frequency float64
+	first     int
+	count     int
+}
+
+// goertzelBank is the set of frequencies evaluated by the fast path: every configured
+// tone frequency, probes one bin apart across its tolerance band and one guard probe
+// on each side of that band.
+type goertzelBank struct {
+	step    float64
+	targets []goertzelTarget
+	probes  []float64
+}
+
+// goertzelBankFor returns the probe bank for the tone sets, or nil when the full FFT
+// sweep has to run instead (learning/discovery, no configured frequencies, or the fast
+// path disabled).
+func (detector *ToneDetector) goertzelBankFor(toneSets []ToneSet, sampleRate int, includeUnmatched bool) *goertzelBank {
+	if detector.FFTOnly || includeUnmatched || len(toneSets) == 0 || sampleRate <= 0 {
+		return nil
+	}
+	toneRange := detector.FrequencyRange
+	if toneRange.Max == 0 {
+		toneRange.Max = 5000
+	}
+
+	step := float64(sampleRate) / float64(stftWindowSize)
+	type wanted struct {
+		frequency float64
+		tolerance float64
+	}
+	var frequencies []wanted
+	add := func(frequency float64, tolerance float64) {
+		if frequency <= 0 || frequency < toneRange.Min || frequency > toneRange.Max {
+			return
+		}
+		for i := range frequencies {
+			if math.Abs(frequencies[i].frequency-frequency) < step/2 {
+				if tolerance > frequencies[i].tolerance {
+					frequencies[i].tolerance = tolerance
+				}
+				return
+			}
+		}
+		frequencies = append(frequencies, wanted{frequency, tolerance})
+	}
+	for _, toneSet := range toneSets {
+		tolerance := toneSetTolerance(toneSet)
+		if toneSet.ATone != nil {
+			add(toneSet.ATone.Frequency, tolerance)
+		}
+		if toneSet.BTone != nil {
+			add(toneSet.BTone.Frequency, tolerance)
+		}
+		if toneSet.LongTone != nil {
+			add(toneSet.LongTone.Frequency, tolerance)
+		}
+		if toneSet.Warble != nil {
+			add(toneSet.Warble.HighFrequency, tolerance)
+			add(toneSet.Warble.LowFrequency, tolerance)
+		}
+	}
+	if len(frequencies) == 0 {
+		return nil
+	}
+
+	bank := &goertzelBank{step: step}
+	for _, f := range frequencies {
+		// Probes cover the tolerance band plus one guard probe either side, so a tone
+		// drifting out of tolerance peaks on the guard and is not matched
+		reach := int(math.Ceil(f.tolerance/step)) + 1
+		target := goertzelTarget{frequency: f.frequency, first: len(bank.probes)}
+		for k := -reach; k <= reach; k++ {
+			probe := f.frequency + float64(k)*step
+			if probe <= 0 || probe >= float64(sampleRate)/2 {
+				continue
+			}
+			bank.probes = append(bank.probes, probe)
+			target.count++
+		}
+		if target.count > 0 {
+			bank.targets = append(bank.targets, target)
+		}
+	}
+	if len(bank.targets) == 0 {
+		return nil
+	}
+	return bank
+}
+
+// interpolate refines the strongest probe's frequency from its neighbours within the
+// same target, like the parabolic sub-bin step of the FFT path.
+func (bank *goertzelBank) interpolate(probe int, mags []float64) float64 {
+	frequency := bank.probes[probe]
+	for _, target := range bank.targets {
+		if probe < target.first || probe >= target.first+target.count {
+			continue
+		}
+		if probe > target.first && probe < target.first+target.count-1 {
+			delta := parabolicInterpolate(mags[probe-1], mags[probe], mags[probe+1])
+			delta = math.Max(-0.5, math.Min(0.5, delta))
+			frequency += delta * bank.step
+		}
+		break
+	}
+	return frequency
+}
+
+// goertzelBlockSums runs one Goertzel filter per probe over each hop-sized block and
+// returns the block DFT terms phase-aligned to the start of the audio, so the DFT of any
+// window made of whole blocks is the sum of its blocks. This keeps the cost per sample
+// independent of the window overlap.
+func goertzelBlockSums(samples []float64, sampleRate int, probes []float64, hop int) ([]float64, []float64, int) {
+	blocks := len(samples) / hop
+	re := make([]float64, len(probes)*blocks)
+	im := make([]float64, len(probes)*blocks)
+	for p, frequency := range probes {
+		w := 2.0 * math.Pi * frequency / float64(sampleRate)
+		coeff := 2.0 * math.Cos(w)
+		sinW, cosW := math.Sincos(w)
+		for b := 0; b < blocks; b++ {
+			start := b * hop
+			var s1, s2 float64
+			for _, x := range samples[start : start+hop] {
+				s0 := x + coeff*s1 - s2
+				s2 = s1
+				s1 = s0
+			}
+			// y = s1 - e^{-jw}*s2 is the block DFT referenced to its last sample;
+			// rotate by e^{-jw(start+hop-1)} to reference it to sample zero
+			yr := s1 - cosW*s2
+			yi := sinW * s2
+			sinP, cosP := math.Sincos(w * float64(start+hop-1))
+			re[p*blocks+b] = yr*cosP + yi*sinP
+			im[p*blocks+b] = yi*cosP - yr*sinP
+		}
+	}
+	return re, im, blocks
+}
+
+// analyzeGoertzelTones is the fast path of analyzeSTFTTones for configured tone sets: it
+// evaluates only the bank's probes on the same window/hop grid, derives the gates from
+// the probe peaks, and groups frames with the same grouping stage as the FFT engine.
+// Frames are kept from the paging region only, timed from its start like the FFT path.
+func (detector *ToneDetector) analyzeGoertzelTones(samples []float64, sampleRate int, bank *goertzelBank, minDuration float64) ([]mergedDetection, toneAnalysisGates) {
+	windowSize := stftWindowSize
+	hop := stftHop
+	gates := toneAnalysisGates{noiseFloorDB: -60}
+	if len(samples) < windowSize {
+		return nil, gates
+	}
+
+	re, im, blocks := goertzelBlockSums(samples, sampleRate, bank.probes, hop)
+	energy := make([]float64, blocks)
+	for b := 0; b < blocks; b++ {
+		var sum float64
+		for _, x := range samples[b*hop : (b+1)*hop] {
+			sum += x * x
+		}
+		energy[b] = sum
+	}
+
+	blocksPerWindow := windowSize / hop
+	frameCount := blocks - blocksPerWindow + 1
+	if frameCount <= 0 {
+		return nil, gates
+	}
+
+	type candidate struct {
+		probe    int
+		freq     float64
+		mag      float64
+		tonality float64
+	}
+	candidates := make([]candidate, frameCount)
+	framePeaks := make([]float64, frameCount)
+	probeMags := make([]float64, len(bank.probes))
+	for f := 0; f < frameCount; f++ {
+		var meanSquare float64
+		for b := f; b < f+blocksPerWindow; b++ {
+			meanSquare += energy[b]
+		}
+		meanSquare /= float64(windowSize)
+
+		best := candidate{probe: -1}
+		for p := range bank.probes {
+			var sr, si float64
+			for b := f; b < f+blocksPerWindow; b++ {
+				sr += re[p*blocks+b]
+				si += im[p*blocks+b]
+			}
+			// Halved to the Hann-windowed |X|/N scale the FFT gates are tuned for
+			mag := math.Hypot(sr, si) / float64(windowSize) / 2
+			probeMags[p] = mag
+			if mag > best.mag {
+				best = candidate{probe: p, mag: mag}
+			}
+		}
+		if best.probe >= 0 && meanSquare > 0 {
+			best.tonality = 8 * best.mag * best.mag / meanSquare
+		}
+		framePeaks[f] = best.mag
+		if best.probe >= 0 {
+			best.freq = bank.interpolate(best.probe, probeMags)
+		}
+		candidates[f] = best
+	}
+
+	gates = gatesFromFramePeaks(framePeaks, sampleRate, hop)
+	if gates.globalPeak < 1e-20 {
+		return nil, gates
+	}
+	toneLog.Debug(fmt.Sprintf("tone detection (goertzel, %d probes): global peak=%.4f, noise floor=%.1f dB, q20=%.1f dB", len(bank.probes), gates.globalPeak, gates.noiseFloorDB, gates.q20))
+
+	regionStart, regionEnd := pagingRegionBounds(samples, sampleRate)
+	var frames []stftFrame
+	for f, c := range candidates {
+		start := f * hop
+		if start < regionStart || start+windowSize > regionEnd {
+			continue
+		}
+		frame := stftFrame{startSample: start - regionStart}
+		if c.probe >= 0 && framePeaks[f] > toneDetectMagnitudeThreshold && c.tonality >= goertzelMinTonality {
+			relDB := 20.0 * math.Log10(math.Max(framePeaks[f], 1e-20)/gates.globalPeak)
+			if relDB >= toneDetectSilenceBelowGlobal && relDB >= gates.noiseFloorDB+toneDetectSNRAboveNoise {
+				frame.freq = c.freq
+				frame.mag = c.mag
+			}
+		}
+		frames = append(frames, frame)
+	}
+	if len(frames) == 0 {
+		return nil, gates
+	}
+	return groupToneFrames(frames, sampleRate, windowSize, minDuration), gates
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"math"
+	"math/cmplx"
+	"testing"
+)
+
+func TestGoertzelBlockSums(t *testing.T) {
+	const sampleRate = 16000
+	samples := make([]float64, stftWindowSize*2)
+	for i := range samples {
+		samples[i] = 0.5*math.Sin(2*math.Pi*853.2*float64(i)/sampleRate) + 0.1*math.Sin(2*math.Pi*2000*float64(i)/sampleRate)
+	}
+	probes := []float64{853.2, 900}
+
+	re, im, blocks := goertzelBlockSums(samples, sampleRate, probes, stftHop)
+	for p, frequency := range probes {
+		// A window of whole blocks has to match the direct DFT over the same samples
+		first, count := 3, stftWindowSize/stftHop
+		var sum complex128
+		for b := first; b < first+count; b++ {
+			sum += complex(re[p*blocks+b], im[p*blocks+b])
+		}
+		var direct complex128
+		for n := first * stftHop; n < (first+count)*stftHop; n++ {
+			direct += complex(samples[n], 0) * cmplx.Exp(complex(0, -2*math.Pi*frequency*float64(n)/sampleRate))
+		}
+		if cmplx.Abs(sum-direct) > 1e-6*cmplx.Abs(direct)+1e-9 {
+			t.Fatalf("%.1f Hz: block sum %v, direct DFT %v", frequency, sum, direct)
+		}
+	}
+}
+
+func TestGoertzelBankFor(t *testing.T) {
+	detector := NewToneDetector()
+	toneSets := []ToneSet{
+		{Label: "A", Tolerance: 10, ATone: &ToneSpec{Frequency: 853.2}, BTone: &ToneSpec{Frequency: 960}},
+		{Label: "B", Tolerance: 0.02, ATone: &ToneSpec{Frequency: 855}, Warble: &WarbleSpec{HighFrequency: 1200, LowFrequency: 800}},
+	}
+
+	bank := detector.goertzelBankFor(toneSets, 16000, false)
+	if bank == nil {
+		t.Fatalf("expected a bank for configured tone sets")
+	}
+	// 853.2 and 855 are within half a bin and share one target
+	if len(bank.targets) != 4 {
+		t.Fatalf("expected 4 targets, got %d", len(bank.targets))
+	}
+	for _, target := range bank.targets {
+		low := bank.probes[target.first]
+		high := bank.probes[target.first+target.count-1]
+		if target.frequency-low <= 10 || high-target.frequency <= 10 {
+			t.Fatalf("%.1f Hz: probes %.1f-%.1f do not cover the tolerance and guard", target.frequency, low, high)
+		}
+	}
+
+	if detector.goertzelBankFor(toneSets, 16000, true) != nil {
+		t.Fatalf("discovery must use the FFT sweep")
+	}
+	if detector.goertzelBankFor(nil, 16000, false) != nil {
+		t.Fatalf("no tone sets must use the FFT sweep")
+	}
+	detector.FFTOnly = true
+	if detector.goertzelBankFor(toneSets, 16000, false) != nil {
+		t.Fatalf("FFTOnly must disable the fast path")
+	}
+}
+
+func BenchmarkToneDetectionGoertzel(b *testing.B) {
+	benchmarkToneDetection(b, false)
+}
+
+func BenchmarkToneDetectionFFT(b *testing.B) {
+	benchmarkToneDetection(b, true)
+}
+
+func benchmarkToneDetection(b *testing.B, fftOnly bool) {
+	const sampleRate = 16000
+	samples := warbleSamples(sampleRate, 853.2, 960, 1, 2)
+	toneSets := []ToneSet{{Label: "Station 1", Tolerance: 10, ATone: &ToneSpec{Frequency: 853.2, MinDuration: 0.5}, BTone: &ToneSpec{Frequency: 960, MinDuration: 0.5}}}
+	detector := NewToneDetector()
+	detector.FFTOnly = fftOnly
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		detector.analyzeFrequencies(samples, sampleRate, toneSets, false)
+	}
+}
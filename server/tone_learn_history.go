@@ -122,7 +122,7 @@ func (controller *Controller) toneHistoryResolveVoice(call toneHistoryCallInput,
 	if controller.isVoiceForToneAlerts(own) {
 		return own
 	}
-	windowMs := int64(pendingToneTimeoutMinutes) * 60 * 1000
+	windowMs := controller.Options.ToneStitchingConfig.pendingTimeout()
 	for _, other := range chronological {
 		if other.callId == call.callId || other.timestamp <= call.timestamp {
 			continue
@@ -398,9 +398,9 @@ func (controller *Controller) fetchToneHistoryCallBatch(systemId, talkgroupId ui
 
 	var query string
 	if controller.Database.Config.DbType == DbTypePostgresql {
-		query = `SELECT "callId", "audio", "audioMime", "audioFilename", "transcript", "reviewedTranscript", "timestamp" FROM "calls" WHERE "systemId" = $1 AND "talkgroupId" = $2 AND "timestamp" >= $3 AND length("audio") > 0 ORDER BY "timestamp" DESC LIMIT $4`
+		query = `SELECT "callId", "audio", "audioMime", "audioFilename", "audioLocation", "audioChecksum", "transcript", "reviewedTranscript", "timestamp" FROM "calls" WHERE "systemId" = $1 AND "talkgroupId" = $2 AND "timestamp" >= $3 AND (length("audio") > 0 OR "audioLocation" <> '') ORDER BY "timestamp" DESC LIMIT $4`
 	} else {
-		query = `SELECT "callId", "audio", "audioMime", "audioFilename", "transcript", "reviewedTranscript", "timestamp" FROM "calls" WHERE "systemId" = ? AND "talkgroupId" = ? AND "timestamp" >= ? AND length("audio") > 0 ORDER BY "timestamp" DESC LIMIT ?`
+		query = `SELECT "callId", "audio", "audioMime", "audioFilename", "audioLocation", "audioChecksum", "transcript", "reviewedTranscript", "timestamp" FROM "calls" WHERE "systemId" = ? AND "talkgroupId" = ? AND "timestamp" >= ? AND (length("audio") > 0 OR "audioLocation" <> '') ORDER BY "timestamp" DESC LIMIT ?`
 	}
 
 	rows, err := controller.Database.Sql.Query(query, systemId, talkgroupId, since, fetchLimit)
@@ -416,16 +416,23 @@ func (controller *Controller) fetchToneHistoryCallBatch(systemId, talkgroupId ui
 			audio              []byte
 			audioMime          string
 			audioFilename      string
+			audioLocation      string
+			audioChecksum      string
 			transcript         sql.NullString
 			reviewedTranscript sql.NullString
 			timestamp          int64
 		)
-		if err := rows.Scan(&callId, &audio, &audioMime, &audioFilename, &transcript, &reviewedTranscript, &timestamp); err != nil {
+		if err := rows.Scan(&callId, &audio, &audioMime, &audioFilename, &audioLocation, &audioChecksum, &transcript, &reviewedTranscript, &timestamp); err != nil {
 			return nil, fmt.Errorf("scan call: %w", err)
 		}
 		if exclude[callId] {
 			continue
 		}
+		if len(audio) == 0 {
+			if audio, err = controller.AudioStore.Fetch(audioLocation, audioChecksum); err != nil {
+				continue
+			}
+		}
 		row := toneHistoryCallInput{
 			callId:        callId,
 			audio:         audio,
@@ -538,7 +545,7 @@ func (admin *Admin) ToneHistoryAnalyzeHandler(w http.ResponseWriter, r *http.Req
 		admin.Controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("tone history analyze failed: %s", err.Error()))
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusBadRequest)
-		w.Write([]byte(fmt.Sprintf(`{"error":"%s"}`, escapeQuotes(err.Error()))))
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
 		return
 	}
 
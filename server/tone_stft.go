@@ -72,6 +72,11 @@ func (detector *ToneDetector) computeToneAnalysisGates(samples []float64, sample
 		framePeaks = append(framePeaks, framePeak)
 	}
 
+	return gatesFromFramePeaks(framePeaks, sampleRate, hopSize)
+}
+
+// gatesFromFramePeaks derives the analysis gates from the per-frame peak magnitudes.
+func gatesFromFramePeaks(framePeaks []float64, sampleRate int, hopSize int) toneAnalysisGates {
 	gates := toneAnalysisGates{noiseFloorDB: -60}
 	if len(framePeaks) == 0 {
 		return gates
@@ -180,7 +185,7 @@ func (detector *ToneDetector) frameDominantFrequency(window []float64, sampleRat
 // analyzeSTFTTones is the single tone-extraction engine: per-frame dominant frequency, then
 // one grouping pass (dynamic tolerance + force-split + OFF breaks), then a minimum-duration
 // gate. It returns stable segments; classification into A/B/Long happens in the caller.
-func (detector *ToneDetector) analyzeSTFTTones(samples []float64, sampleRate int, gates toneAnalysisGates) []mergedDetection {
+func (detector *ToneDetector) analyzeSTFTTones(samples []float64, sampleRate int, gates toneAnalysisGates, minDuration float64) []mergedDetection {
 	windowSize := stftWindowSize
 	if len(samples) < windowSize {
 		return nil
@@ -196,6 +201,12 @@ func (detector *ToneDetector) analyzeSTFTTones(samples []float64, sampleRate int
 		return nil
 	}
 
+	return groupToneFrames(frames, sampleRate, windowSize, minDuration)
+}
+
+// groupToneFrames groups per-frame dominant frequencies into stable segments of at least
+// minDuration seconds.
+func groupToneFrames(frames []stftFrame, sampleRate int, windowSize int, minDuration float64) []mergedDetection {
 	windowSec := float64(windowSize) / float64(sampleRate)
 	var dets []mergedDetection
 
@@ -215,7 +226,7 @@ func (detector *ToneDetector) analyzeSTFTTones(samples []float64, sampleRate int
 		}
 		startTime := float64(groupStart) / float64(sampleRate)
 		endTime := float64(groupEnd)/float64(sampleRate) + windowSec
-		if endTime-startTime >= toneDetectMinDurationSec {
+		if endTime-startTime >= minDuration {
 			hist := make([]float64, len(groupFreqs))
 			copy(hist, groupFreqs)
 			dets = append(dets, mergedDetection{
@@ -274,7 +285,7 @@ func (detector *ToneDetector) analyzeSTFTTones(samples []float64, sampleRate int
 	}
 	flush()
 
-	fmt.Printf("stft tone detection: %d stable segments\n", len(dets))
+	toneLog.Debug(fmt.Sprintf("stft tone detection: %d stable segments", len(dets)))
 	return dets
 }
 
@@ -0,0 +1,73 @@
+// Copyright (C) 2026 Thinline Dynamic Solutions
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>
+
+package main
+
+import (
+	"strings"
+	"time"
+)
+
+// validToneTestWindows trims the test windows of a tone set and drops the
+// ones that don't parse.
+func validToneTestWindows(windows []string) []string {
+	var valid []string
+	for _, window := range windows {
+		window = strings.TrimSpace(window)
+		if _, err := parseCronExpr(window); err == nil {
+			valid = append(valid, window)
+		}
+	}
+	return valid
+}
+
+// inTestWindow reports whether t is inside one of the tone set's test windows.
+func (toneSet *ToneSet) inTestWindow(t time.Time) bool {
+	for _, window := range toneSet.TestWindows {
+		if expr, err := parseCronExpr(window); err == nil && expr.matches(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// markToneTest tags the tone sequence of call as a test when every matched
+// tone set is in a test window at the start of the call, in the system time
+// zone. A match outside its window keeps the whole sequence alerting.
+func markToneTest(call *Call, toneSequence *ToneSequence) bool {
+	if call == nil || toneSequence == nil || len(toneSequence.MatchedToneSets) == 0 {
+		return false
+	}
+
+	location := time.Local
+	if call.System != nil {
+		location = call.System.Location()
+	}
+	at := call.Timestamp.In(location)
+
+	for _, toneSet := range toneSequence.MatchedToneSets {
+		if toneSet == nil || !toneSet.inTestWindow(at) {
+			return false
+		}
+	}
+
+	toneSequence.Test = true
+	return true
+}
+
+// isToneTest reports whether the tones of call were tagged as a test.
+func isToneTest(call *Call) bool {
+	return call != nil && call.ToneSequence != nil && call.ToneSequence.Test
+}
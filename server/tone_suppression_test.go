@@ -0,0 +1,46 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseToneSetsDropsInvalidTestWindows(t *testing.T) {
+	toneSets, err := ParseToneSets(`[{"id":"a","label":"Station 4","testWindows":[" 0-14 12 * * 3 ","noon"]}]`)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if len(toneSets[0].TestWindows) != 1 || toneSets[0].TestWindows[0] != "0-14 12 * * 3" {
+		t.Fatalf("testWindows = %q", toneSets[0].TestWindows)
+	}
+}
+
+func TestMarkToneTest(t *testing.T) {
+	weekly := &ToneSet{Id: "a", TestWindows: []string{"0-14 12 * * 3"}}
+	always := &ToneSet{Id: "b"}
+	call := &Call{
+		System:    &System{TimeZone: "America/Chicago"},
+		Timestamp: time.Date(2026, 10, 21, 17, 5, 0, 0, time.UTC), // Wednesday 12:05 in Chicago
+	}
+
+	sequence := &ToneSequence{MatchedToneSets: []*ToneSet{weekly}}
+	if !markToneTest(call, sequence) || !sequence.Test {
+		t.Fatalf("match in its test window should be a test")
+	}
+
+	call.ToneSequence = sequence
+	if !isToneTest(call) {
+		t.Fatalf("isToneTest = false")
+	}
+
+	sequence = &ToneSequence{MatchedToneSets: []*ToneSet{weekly, always}}
+	if markToneTest(call, sequence) || sequence.Test {
+		t.Fatalf("a match outside a test window should alert")
+	}
+
+	call.Timestamp = time.Date(2026, 10, 21, 12, 5, 0, 0, time.UTC) // 07:05 in Chicago
+	sequence = &ToneSequence{MatchedToneSets: []*ToneSet{weekly}}
+	if markToneTest(call, sequence) {
+		t.Fatalf("match outside the window should not be a test")
+	}
+}
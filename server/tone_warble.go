@@ -0,0 +1,182 @@
+// Copyright (C) 2026 Thinline Dynamic Solutions
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>
+
+package main
+
+import (
+	"math"
+	"sort"
+)
+
+const (
+	warbleDefaultMinCycles  = 2
+	warbleDefaultSegmentMin = 0.15 // seconds
+	warbleMaxGapSec         = 0.25 // silence allowed between two halves of a cycle
+)
+
+// WarbleSpec is an alternating hi-low pattern, as sent by some fire
+// departments instead of two-tone paging. Each high or low half counts as a
+// segment and a high-low pair as a cycle.
+type WarbleSpec struct {
+	HighFrequency      float64 `json:"highFrequency"`                // Hz
+	LowFrequency       float64 `json:"lowFrequency"`                 // Hz
+	MinCycles          int     `json:"minCycles,omitempty"`          // default 2
+	SegmentMinDuration float64 `json:"segmentMinDuration,omitempty"` // seconds per half, default 0.15
+	SegmentMaxDuration float64 `json:"segmentMaxDuration,omitempty"` // seconds per half, 0 = unlimited
+}
+
+func (spec *WarbleSpec) minCycles() int {
+	if spec.MinCycles > 0 {
+		return spec.MinCycles
+	}
+	return warbleDefaultMinCycles
+}
+
+func (spec *WarbleSpec) segmentMin() float64 {
+	if spec.SegmentMinDuration > 0 {
+		return spec.SegmentMinDuration
+	}
+	return warbleDefaultSegmentMin
+}
+
+// toneSetTolerance returns the tone set tolerance in Hz: values under 1 are a
+// multiple of 500 Hz (0.01 = 5 Hz).
+func toneSetTolerance(toneSet ToneSet) float64 {
+	if toneSet.Tolerance < 1.0 {
+		return toneSet.Tolerance * 500.0
+	}
+	return toneSet.Tolerance
+}
+
+// warbleMinSegmentDuration returns the shortest segment the tone sets need
+// from the STFT engine.
+func warbleMinSegmentDuration(toneSets []ToneSet) float64 {
+	minDuration := toneDetectMinDurationSec
+	for _, toneSet := range toneSets {
+		if toneSet.Warble != nil && toneSet.Warble.segmentMin() < minDuration {
+			minDuration = toneSet.Warble.segmentMin()
+		}
+	}
+	return minDuration
+}
+
+// findWarbleTones returns a "Warble" tone for each run of segments
+// alternating between the high and low frequencies of a tone set's warble.
+func findWarbleTones(segments []mergedDetection, toneSets []ToneSet) []Tone {
+	sorted := make([]mergedDetection, len(segments))
+	copy(sorted, segments)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].startTime < sorted[j].startTime
+	})
+
+	var warbles []Tone
+	for _, toneSet := range toneSets {
+		spec := toneSet.Warble
+		if spec == nil {
+			continue
+		}
+		tolerance := toneSetTolerance(toneSet)
+
+		// side is 1 for a high segment, -1 for a low one, 0 for neither
+		side := func(md mergedDetection) int {
+			duration := md.endTime - md.startTime
+			if duration < spec.segmentMin() || (spec.SegmentMaxDuration > 0 && duration > spec.SegmentMaxDuration) {
+				return 0
+			}
+			if math.Abs(md.frequency-spec.HighFrequency) <= tolerance {
+				return 1
+			}
+			if math.Abs(md.frequency-spec.LowFrequency) <= tolerance {
+				return -1
+			}
+			return 0
+		}
+
+		var run []mergedDetection
+		lastSide := 0
+		flush := func() {
+			if cycles := len(run) / 2; cycles >= spec.minCycles() {
+				warbles = appendWarble(warbles, run, spec, cycles)
+			}
+			run = nil
+			lastSide = 0
+		}
+		for _, md := range sorted {
+			current := side(md)
+			if current == 0 {
+				flush()
+				continue
+			}
+			if len(run) > 0 && (current == lastSide || md.startTime-run[len(run)-1].endTime > warbleMaxGapSec) {
+				flush()
+			}
+			run = append(run, md)
+			lastSide = current
+		}
+		flush()
+	}
+	return warbles
+}
+
+// appendWarble adds the warble heard over run, once when several tone sets
+// share the same pattern.
+func appendWarble(warbles []Tone, run []mergedDetection, spec *WarbleSpec, cycles int) []Tone {
+	start, end := run[0].startTime, run[len(run)-1].endTime
+	for _, warble := range warbles {
+		if warble.StartTime == start && warble.EndTime == end {
+			return warbles
+		}
+	}
+
+	var high, low []float64
+	var magnitude float64
+	for _, md := range run {
+		if math.Abs(md.frequency-spec.HighFrequency) < math.Abs(md.frequency-spec.LowFrequency) {
+			high = append(high, md.frequency)
+		} else {
+			low = append(low, md.frequency)
+		}
+		if md.magnitude > magnitude {
+			magnitude = md.magnitude
+		}
+	}
+
+	return append(warbles, Tone{
+		Frequency:    medianFloat(high),
+		LowFrequency: medianFloat(low),
+		StartTime:    start,
+		EndTime:      end,
+		Duration:     end - start,
+		ToneType:     "Warble",
+		Magnitude:    magnitude,
+		Cycles:       cycles,
+	})
+}
+
+// warbleMatches reports whether the detected tones hold the warble of the
+// tone set.
+func warbleMatches(detected *ToneSequence, toneSet ToneSet) bool {
+	spec := toneSet.Warble
+	tolerance := toneSetTolerance(toneSet)
+	for _, tone := range detected.Tones {
+		if tone.ToneType != "Warble" || tone.Cycles < spec.minCycles() {
+			continue
+		}
+		if math.Abs(tone.Frequency-spec.HighFrequency) <= tolerance && math.Abs(tone.LowFrequency-spec.LowFrequency) <= tolerance {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+// warbleSamples alternates high and low sine halves of halfSeconds each.
+func warbleSamples(sampleRate int, high float64, low float64, halfSeconds float64, halves int) []float64 {
+	var samples []float64
+	// Lead-in silence so the paging crop keeps the whole pattern
+	samples = append(samples, make([]float64, sampleRate/2)...)
+	phase := 0.0
+	for h := 0; h < halves; h++ {
+		freq := high
+		if h%2 == 1 {
+			freq = low
+		}
+		for i := 0; i < int(halfSeconds*float64(sampleRate)); i++ {
+			phase += 2 * math.Pi * freq / float64(sampleRate)
+			samples = append(samples, 0.5*math.Sin(phase))
+		}
+	}
+	return append(samples, make([]float64, sampleRate/2)...)
+}
+
+func TestWarbleDetection(t *testing.T) {
+	detector := NewToneDetector()
+	const sampleRate = 16000
+
+	toneSets := []ToneSet{
+		{Label: "Station 4 warble", Tolerance: 15, Warble: &WarbleSpec{HighFrequency: 1200, LowFrequency: 800, MinCycles: 3}},
+		{Label: "Station 9 warble", Tolerance: 15, Warble: &WarbleSpec{HighFrequency: 1500, LowFrequency: 900}},
+	}
+
+	tones := detector.analyzeFrequencies(warbleSamples(sampleRate, 1200, 800, 0.3, 8), sampleRate, toneSets, false)
+	var warble *Tone
+	for i := range tones {
+		if tones[i].ToneType == "Warble" {
+			warble = &tones[i]
+		}
+	}
+	if warble == nil {
+		t.Fatalf("expected a warble tone, got %+v", tones)
+	}
+	if math.Abs(warble.Frequency-1200) > 15 || math.Abs(warble.LowFrequency-800) > 15 || warble.Cycles != 4 {
+		t.Fatalf("unexpected warble %+v", *warble)
+	}
+
+	sequence := &ToneSequence{Tones: tones, HasTones: true}
+	matched := detector.MatchToneSets(sequence, toneSets)
+	if len(matched) != 1 || matched[0].Label != "Station 4 warble" {
+		t.Fatalf("expected only station 4 to match, got %v", matched)
+	}
+
+	// Two cycles are not enough for station 4
+	tones = detector.analyzeFrequencies(warbleSamples(sampleRate, 1200, 800, 0.3, 4), sampleRate, toneSets, false)
+	if matched := detector.MatchToneSets(&ToneSequence{Tones: tones, HasTones: true}, toneSets); len(matched) != 0 {
+		t.Fatalf("expected no match on two cycles, got %v", matched)
+	}
+
+	// A steady tone is not a warble
+	steady := warbleSamples(sampleRate, 1200, 1200, 1.2, 2)
+	for _, tone := range detector.analyzeFrequencies(steady, sampleRate, toneSets, false) {
+		if tone.ToneType == "Warble" {
+			t.Fatalf("expected no warble in a steady tone, got %+v", tone)
+		}
+	}
+}
@@ -116,25 +116,27 @@ func (admin *Admin) listTranscriptReviewQueue(w http.ResponseWriter, r *http.Req
 
 	db := admin.Controller.Database
 	// Same base filters as GET /api/transcripts (Alerts tab).
+	args := queryArgs{}
 	where := []string{
 		`(c."transcript" IS NOT NULL AND c."transcript" <> '')`,
 		`d."callId" IS NULL`,
 	}
 	if search := strings.TrimSpace(r.URL.Query().Get("search")); search != "" {
+		pattern := args.add("%" + search + "%")
 		if admin.Controller.Database.Config.DbType == DbTypePostgresql {
-			where = append(where, fmt.Sprintf(`c."transcript" ILIKE '%%%s%%'`, escapeQuotes(search)))
+			where = append(where, fmt.Sprintf(`(c."transcript" ILIKE %[1]s OR c."transcriptTranslation" ILIKE %[1]s)`, pattern))
 		} else {
-			where = append(where, fmt.Sprintf(`c."transcript" LIKE '%%%s%%'`, escapeQuotes(search)))
+			where = append(where, fmt.Sprintf(`(c."transcript" LIKE %[1]s OR c."transcriptTranslation" LIKE %[1]s)`, pattern))
 		}
 	}
 	whereClause := strings.Join(where, " AND ")
 
 	collectorConfigured := admin.collectorConfigured()
 	// Prefer query without review columns — works before migration runs.
-	out, qerr := admin.queryTranscriptReviewQueue(ctx, db, whereClause, limit, offset, false)
+	out, qerr := admin.queryTranscriptReviewQueue(ctx, db, whereClause, args, limit, offset, false)
 	if qerr != nil {
 		log.Printf("transcript review list: base query failed, trying extended: %v", qerr)
-		out, qerr = admin.queryTranscriptReviewQueue(ctx, db, whereClause, limit, offset, true)
+		out, qerr = admin.queryTranscriptReviewQueue(ctx, db, whereClause, args, limit, offset, true)
 	}
 	if qerr != nil {
 		log.Printf("transcript review list: query failed: %v", qerr)
@@ -163,7 +165,7 @@ func (admin *Admin) listTranscriptReviewQueue(w http.ResponseWriter, r *http.Req
 	})
 }
 
-func (admin *Admin) queryTranscriptReviewQueue(ctx context.Context, db *Database, whereClause string, limit, offset int, withReviewCols bool) ([]map[string]any, error) {
+func (admin *Admin) queryTranscriptReviewQueue(ctx context.Context, db *Database, whereClause string, args queryArgs, limit, offset int, withReviewCols bool) ([]map[string]any, error) {
 	selectCols := `c."callId", c."timestamp", c."transcript", COALESCE(c."transcriptionStatus", ''),
 			COALESCE(s."label", ''), COALESCE(t."label", ''), COALESCE(t."name", '')`
 	if withReviewCols {
@@ -180,7 +182,7 @@ func (admin *Admin) queryTranscriptReviewQueue(ctx context.Context, db *Database
 		ORDER BY c."callId" DESC
 		LIMIT %d OFFSET %d`, selectCols, whereClause, limit, offset)
 
-	rows, err := db.Sql.QueryContext(ctx, query)
+	rows, err := db.Sql.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -270,9 +272,8 @@ func (admin *Admin) saveTranscriptReview(w http.ResponseWriter, r *http.Request,
 		return
 	}
 
-	esc := escapeQuotes(body.ReviewedTranscript)
-	query := fmt.Sprintf(`UPDATE "calls" SET "reviewedTranscript" = '%s', "trainingReviewStatus" = 'pending' WHERE "callId" = %d`, esc, callId)
-	if _, err := admin.Controller.Database.Sql.Exec(query); err != nil {
+	query := `UPDATE "calls" SET "reviewedTranscript" = $1, "trainingReviewStatus" = 'pending' WHERE "callId" = $2`
+	if _, err := admin.Controller.Database.Sql.Exec(query, body.ReviewedTranscript, callId); err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		json.NewEncoder(w).Encode(map[string]string{"error": "save failed"})
 		return
@@ -358,10 +359,8 @@ func (admin *Admin) approveTranscriptReview(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	escTranscript := escapeQuotes(reviewedUpper)
-	escReviewed := escapeQuotes(reviewedUpper)
-	query := fmt.Sprintf(`UPDATE "calls" SET "transcript" = '%s', "reviewedTranscript" = '%s', "trainingReviewStatus" = 'submitted' WHERE "callId" = %d`, escTranscript, escReviewed, callId)
-	if _, err := admin.Controller.Database.Sql.Exec(query); err != nil {
+	query := `UPDATE "calls" SET "transcript" = $1, "reviewedTranscript" = $1, "trainingReviewStatus" = 'submitted' WHERE "callId" = $2`
+	if _, err := admin.Controller.Database.Sql.Exec(query, reviewedUpper, callId); err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		json.NewEncoder(w).Encode(map[string]string{"error": "submitted but failed to update local status"})
 		return
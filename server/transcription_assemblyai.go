@@ -27,6 +27,8 @@ import (
 	"time"
 )
 
+var assemblyAILog = Logger(LogCategoryTranscription)
+
 // AssemblyAITranscription implements TranscriptionProvider for AssemblyAI
 type AssemblyAITranscription struct {
 	available  bool
@@ -77,14 +79,14 @@ func (assemblyai *AssemblyAITranscription) Transcribe(audio []byte, options Tran
 
 	// Step 1: Convert audio to WAV format using ffmpeg
 	// This ensures AssemblyAI can recognize and process the audio correctly
-	fmt.Printf("DEBUG: Converting audio to WAV - original size: %d bytes, mime: %s\n", len(audio), options.AudioMime)
+	assemblyAILog.Debug(fmt.Sprintf("Converting audio to WAV - original size: %d bytes, mime: %s", len(audio), options.AudioMime))
 
 	wavAudio, err := convertToWAV(audio)
 	if err != nil {
 		return nil, fmt.Errorf("failed to convert audio to WAV: %v", err)
 	}
 
-	fmt.Printf("DEBUG: Converted to WAV - new size: %d bytes\n", len(wavAudio))
+	assemblyAILog.Debug(fmt.Sprintf("Converted to WAV - new size: %d bytes", len(wavAudio)))
 
 	// Validate WAV audio data
 	if len(wavAudio) == 0 {
@@ -94,7 +96,7 @@ func (assemblyai *AssemblyAITranscription) Transcribe(audio []byte, options Tran
 	// Check WAV header
 	if len(wavAudio) >= 4 {
 		header := wavAudio[:4]
-		fmt.Printf("DEBUG: WAV header bytes: %x (should be 52494646 for 'RIFF')\n", header)
+		assemblyAILog.Debug(fmt.Sprintf("WAV header bytes: %x (should be 52494646 for 'RIFF')", header))
 	}
 
 	// Step 2: Upload WAV audio as raw bytes
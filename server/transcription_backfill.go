@@ -0,0 +1,375 @@
+// Copyright (C) 2026 Thinline Dynamic Solutions
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	transcriptionBackfillDefaultBatchSize = 50
+	transcriptionBackfillDefaultPerMinute = 30
+
+	// The backfill leaves most of the queue free for live calls, which are
+	// dropped when it is full.
+	transcriptionBackfillMaxQueued = 10
+)
+
+// RetranscriptionFilter selects the historical calls to transcribe again.
+// Zero values match everything.
+type RetranscriptionFilter struct {
+	From         int64  `json:"from"` // unix ms, inclusive
+	To           int64  `json:"to"`   // unix ms, exclusive
+	SystemRef    uint   `json:"systemRef"`
+	TalkgroupRef uint   `json:"talkgroupRef"`
+	Status       string `json:"status"`   // previous transcription status, e.g. "failed"
+	Provider     string `json:"provider"` // provider of the previous transcript, e.g. "azure"
+	Limit        int64  `json:"limit"`    // 0 = every matching call
+	BatchSize    int    `json:"batchSize"`
+	PerMinute    int    `json:"perMinute"` // calls queued per minute
+	DryRun       bool   `json:"dryRun"`
+}
+
+// RetranscriptionStatus is the progress of the last backfill.
+type RetranscriptionStatus struct {
+	Running    bool                  `json:"running"`
+	Filter     RetranscriptionFilter `json:"filter"`
+	Matched    int64                 `json:"matched"`
+	Queued     int64                 `json:"queued"`
+	Skipped    int64                 `json:"skipped"`
+	StartedAt  int64                 `json:"startedAt,omitempty"`
+	FinishedAt int64                 `json:"finishedAt,omitempty"`
+	Cancelled  bool                  `json:"cancelled,omitempty"`
+	Error      string                `json:"error,omitempty"`
+}
+
+// TranscriptionBackfill re-runs transcription over historical calls through
+// the transcription queue, one backfill at a time. Backfilled calls only get
+// their transcript replaced; no alert is sent for them.
+type TranscriptionBackfill struct {
+	controller *Controller
+	mutex      sync.Mutex
+	status     *RetranscriptionStatus
+	cancel     chan struct{}
+}
+
+func NewTranscriptionBackfill(controller *Controller) *TranscriptionBackfill {
+	return &TranscriptionBackfill{controller: controller}
+}
+
+func (filter *RetranscriptionFilter) normalize() {
+	if filter.BatchSize <= 0 {
+		filter.BatchSize = transcriptionBackfillDefaultBatchSize
+	}
+	if filter.PerMinute <= 0 {
+		filter.PerMinute = transcriptionBackfillDefaultPerMinute
+	}
+	filter.Status = strings.TrimSpace(filter.Status)
+	filter.Provider = strings.TrimSpace(filter.Provider)
+}
+
+// where returns the conditions of the filter on "calls", with its arguments
+// numbered from $first.
+func (filter RetranscriptionFilter) where(first int) (string, []any) {
+	var (
+		conditions []string
+		args       []any
+	)
+	add := func(condition string, arg any) {
+		conditions = append(conditions, fmt.Sprintf(condition, first+len(args)))
+		args = append(args, arg)
+	}
+	if filter.From > 0 {
+		add(`"timestamp" >= $%d`, filter.From)
+	}
+	if filter.To > 0 {
+		add(`"timestamp" < $%d`, filter.To)
+	}
+	if filter.SystemRef > 0 {
+		add(`"systemRef" = $%d`, filter.SystemRef)
+	}
+	if filter.TalkgroupRef > 0 {
+		add(`"talkgroupRef" = $%d`, filter.TalkgroupRef)
+	}
+	if filter.Status != "" {
+		add(`"transcriptionStatus" = $%d`, filter.Status)
+	}
+	if filter.Provider != "" {
+		add(`"transcriptionProvider" = $%d`, filter.Provider)
+	}
+	if len(conditions) == 0 {
+		return "TRUE", nil
+	}
+	return strings.Join(conditions, " AND "), args
+}
+
+// Count returns the number of calls the filter selects, capped at its limit.
+func (backfill *TranscriptionBackfill) Count(filter RetranscriptionFilter) (int64, error) {
+	where, args := filter.where(1)
+	var count int64
+	if err := backfill.controller.Database.Sql.QueryRow(`SELECT COUNT(*) FROM "calls" WHERE `+where, args...).Scan(&count); err != nil {
+		return 0, err
+	}
+	if filter.Limit > 0 && count > filter.Limit {
+		count = filter.Limit
+	}
+	return count, nil
+}
+
+// Start counts the matching calls and, unless it is a dry run, queues them
+// in the background.
+func (backfill *TranscriptionBackfill) Start(filter RetranscriptionFilter) (*RetranscriptionStatus, error) {
+	filter.normalize()
+
+	matched, err := backfill.Count(filter)
+	if err != nil {
+		return nil, err
+	}
+	if filter.DryRun {
+		return &RetranscriptionStatus{Filter: filter, Matched: matched}, nil
+	}
+	if !backfill.controller.Options.TranscriptionConfig.Enabled {
+		return nil, fmt.Errorf("transcription is not enabled")
+	}
+	if backfill.controller.TranscriptionQueue == nil {
+		backfill.controller.TranscriptionQueue = NewTranscriptionQueue(backfill.controller, backfill.controller.Options.TranscriptionConfig)
+	}
+
+	backfill.mutex.Lock()
+	defer backfill.mutex.Unlock()
+
+	if backfill.status != nil && backfill.status.Running {
+		return nil, fmt.Errorf("a backfill is already running")
+	}
+
+	status := &RetranscriptionStatus{Running: true, Filter: filter, Matched: matched, StartedAt: time.Now().UnixMilli()}
+	backfill.status = status
+	backfill.cancel = make(chan struct{})
+
+	go backfill.run(status, backfill.cancel)
+
+	snapshot := *status
+	return &snapshot, nil
+}
+
+func (backfill *TranscriptionBackfill) run(status *RetranscriptionStatus, cancel chan struct{}) {
+	err := backfill.queueCalls(status, cancel)
+
+	backfill.mutex.Lock()
+	status.Running = false
+	status.FinishedAt = time.Now().UnixMilli()
+	if err != nil {
+		status.Error = err.Error()
+	}
+	queued, skipped := status.Queued, status.Skipped
+	backfill.mutex.Unlock()
+
+	if err != nil {
+		backfill.controller.Logs.LogEvent(LogLevelError, fmt.Sprintf("transcription backfill: %v", err))
+	} else {
+		backfill.controller.Logs.LogEvent(LogLevelInfo, fmt.Sprintf("transcription backfill: queued %d calls, skipped %d", queued, skipped))
+	}
+}
+
+func (backfill *TranscriptionBackfill) queueCalls(status *RetranscriptionStatus, cancel chan struct{}) error {
+	filter := status.Filter
+	where, args := filter.where(2)
+	query := fmt.Sprintf(`SELECT "callId" FROM "calls" WHERE "callId" > $1 AND %s ORDER BY "callId" LIMIT $%d`, where, 2+len(args))
+	args = append(args, filter.BatchSize)
+
+	interval := time.Minute / time.Duration(filter.PerMinute)
+	var (
+		afterId uint64
+		handled int64
+	)
+	for {
+		ids, err := backfill.nextBatch(query, afterId, args)
+		if err != nil {
+			return err
+		}
+
+		for _, id := range ids {
+			if filter.Limit > 0 && handled >= filter.Limit {
+				return nil
+			}
+			if err := backfill.waitForRoom(cancel); err != nil {
+				select {
+				case <-cancel:
+					return nil
+				default:
+					return err
+				}
+			}
+
+			queued := backfill.queueCall(id)
+			handled++
+			backfill.mutex.Lock()
+			if queued {
+				status.Queued++
+			} else {
+				status.Skipped++
+			}
+			backfill.mutex.Unlock()
+
+			select {
+			case <-cancel:
+				return nil
+			case <-time.After(interval):
+			}
+		}
+
+		if len(ids) < filter.BatchSize {
+			return nil
+		}
+		afterId = ids[len(ids)-1]
+	}
+}
+
+func (backfill *TranscriptionBackfill) nextBatch(query string, afterId uint64, args []any) ([]uint64, error) {
+	rows, err := backfill.controller.Database.Sql.Query(query, append([]any{afterId}, args...)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []uint64
+	for rows.Next() {
+		var id uint64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// waitForRoom waits until the queue has room for a backfilled call. It fails
+// when the backfill is cancelled or the queue stops.
+func (backfill *TranscriptionBackfill) waitForRoom(cancel chan struct{}) error {
+	for {
+		queue := backfill.controller.TranscriptionQueue
+		if queue == nil || !queue.running {
+			return fmt.Errorf("transcription queue stopped")
+		}
+		if queue.QueueDepth() < transcriptionBackfillMaxQueued {
+			return nil
+		}
+		select {
+		case <-cancel:
+			return fmt.Errorf("cancelled")
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+// queueCall queues one call for transcription, reporting false when its
+// audio cannot be loaded.
+func (backfill *TranscriptionBackfill) queueCall(id uint64) bool {
+	call, err := backfill.controller.Calls.GetCall(id)
+	if err != nil || call == nil || len(call.Audio) == 0 || call.System == nil || call.Talkgroup == nil {
+		return false
+	}
+	backfill.controller.TranscriptionQueue.QueueJob(TranscriptionJob{
+		CallId:        call.Id,
+		Audio:         call.Audio,
+		AudioMime:     call.AudioMime,
+		OriginalAudio: call.Audio,
+		OriginalMime:  call.AudioMime,
+		SystemId:      call.System.Id,
+		TalkgroupId:   call.Talkgroup.Id,
+		Reasons:       []string{"backfill"},
+		Backfill:      true,
+	})
+	return true
+}
+
+// Cancel stops a running backfill; calls already queued are still transcribed.
+func (backfill *TranscriptionBackfill) Cancel() bool {
+	backfill.mutex.Lock()
+	defer backfill.mutex.Unlock()
+
+	if backfill.status == nil || !backfill.status.Running {
+		return false
+	}
+	backfill.status.Cancelled = true
+	close(backfill.cancel)
+	backfill.status.Running = false
+	return true
+}
+
+func (backfill *TranscriptionBackfill) Status() *RetranscriptionStatus {
+	backfill.mutex.Lock()
+	defer backfill.mutex.Unlock()
+
+	if backfill.status == nil {
+		return &RetranscriptionStatus{}
+	}
+	snapshot := *backfill.status
+	return &snapshot
+}
+
+// TranscriptionBackfillHandler re-transcribes historical calls. GET returns
+// progress, POST starts a backfill (or counts the matches with dryRun) and
+// DELETE cancels it.
+func (admin *Admin) TranscriptionBackfillHandler(w http.ResponseWriter, r *http.Request) {
+	t := admin.GetAuthorization(r)
+	if !admin.ValidateToken(t) {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	backfill := admin.Controller.TranscriptionBackfill
+
+	switch r.Method {
+	case http.MethodGet:
+		json.NewEncoder(w).Encode(backfill.Status())
+
+	case http.MethodPost:
+		var filter RetranscriptionFilter
+		if err := json.NewDecoder(r.Body).Decode(&filter); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid request body"})
+			return
+		}
+		status, err := backfill.Start(filter)
+		if err != nil {
+			w.WriteHeader(http.StatusConflict)
+			json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+			return
+		}
+		if !filter.DryRun {
+			w.WriteHeader(http.StatusAccepted)
+		}
+		json.NewEncoder(w).Encode(status)
+
+	case http.MethodDelete:
+		if !backfill.Cancel() {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(map[string]string{"error": "no backfill is running"})
+			return
+		}
+		json.NewEncoder(w).Encode(backfill.Status())
+
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
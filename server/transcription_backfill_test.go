@@ -0,0 +1,28 @@
+package main
+
+import "testing"
+
+func TestRetranscriptionFilterWhere(t *testing.T) {
+	where, args := RetranscriptionFilter{}.where(1)
+	if where != "TRUE" || len(args) != 0 {
+		t.Fatalf("expected an empty filter to match everything, got %q %v", where, args)
+	}
+
+	filter := RetranscriptionFilter{From: 1000, To: 2000, TalkgroupRef: 42, Status: "failed", Provider: "azure"}
+	where, args = filter.where(2)
+	expected := `"timestamp" >= $2 AND "timestamp" < $3 AND "talkgroupRef" = $4 AND "transcriptionStatus" = $5 AND "transcriptionProvider" = $6`
+	if where != expected {
+		t.Fatalf("unexpected conditions %q", where)
+	}
+	if len(args) != 5 || args[0] != int64(1000) || args[2] != uint(42) || args[4] != "azure" {
+		t.Fatalf("unexpected arguments %v", args)
+	}
+}
+
+func TestRetranscriptionFilterNormalize(t *testing.T) {
+	filter := RetranscriptionFilter{Status: " failed "}
+	filter.normalize()
+	if filter.BatchSize != transcriptionBackfillDefaultBatchSize || filter.PerMinute != transcriptionBackfillDefaultPerMinute || filter.Status != "failed" {
+		t.Fatalf("unexpected defaults %+v", filter)
+	}
+}
@@ -0,0 +1,231 @@
+// Copyright (C) 2026 Thinline Dynamic Solutions
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>
+
+package main
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+const (
+	transcriptionChunkDefaultMinCallSeconds = 60
+	transcriptionChunkDefaultMaxSeconds     = 30
+	transcriptionChunkDefaultConcurrency    = 3
+)
+
+// TranscriptionChunkingSettings splits long calls at silences and transcribes
+// the pieces in parallel. TranscriptionConfig.Chunking holds one per provider.
+type TranscriptionChunkingSettings struct {
+	Enabled         bool    `json:"enabled"`
+	MinCallDuration float64 `json:"minCallDuration,omitempty"` // calls at least this long are chunked, default 60s
+	MaxChunkSeconds float64 `json:"maxChunkSeconds,omitempty"` // default 30s
+	Concurrency     int     `json:"concurrency,omitempty"`     // chunks transcribed at once, default 3
+}
+
+// transcriptionChunk is one piece of a call, in seconds from its start.
+type transcriptionChunk struct {
+	start float64
+	end   float64
+}
+
+// providerKey returns the configured provider, which defaults to whisper-api.
+func (config TranscriptionConfig) providerKey() string {
+	if config.Provider == "" {
+		return "whisper-api"
+	}
+	return config.Provider
+}
+
+// chunkingFor returns the chunking settings of the active provider, with the
+// defaults filled in.
+func (config TranscriptionConfig) chunkingFor() (TranscriptionChunkingSettings, bool) {
+	settings, ok := config.Chunking[config.providerKey()]
+	if !ok || !settings.Enabled {
+		return settings, false
+	}
+	if settings.MinCallDuration <= 0 {
+		settings.MinCallDuration = transcriptionChunkDefaultMinCallSeconds
+	}
+	if settings.MaxChunkSeconds <= 0 {
+		settings.MaxChunkSeconds = transcriptionChunkDefaultMaxSeconds
+	}
+	if settings.Concurrency <= 0 {
+		settings.Concurrency = transcriptionChunkDefaultConcurrency
+	}
+	return settings, true
+}
+
+// transcriptionChunkBounds splits 8 kHz mono s16le audio into chunks of at most
+// maxSeconds. Each cut is placed in the middle of the longest silence found in
+// the second half of the chunk, or at maxSeconds when there is none.
+func transcriptionChunkBounds(pcm []byte, maxSeconds float64) []transcriptionChunk {
+	frameSeconds := float64(speechFrameSamples) / energySampleHz
+	voiced := speechFrames(pcm, speechGateDefaultMinLevel, speechGateDefaultMaxFlatness)
+	total := float64(len(pcm)/2) / energySampleHz
+	maxFrames := int(maxSeconds / frameSeconds)
+	if maxFrames < 2 || len(voiced) <= maxFrames {
+		return []transcriptionChunk{{start: 0, end: total}}
+	}
+
+	var chunks []transcriptionChunk
+	start := 0
+	for len(voiced)-start > maxFrames {
+		cut := start + maxFrames
+		bestRun := 0
+		run := 0
+		for f := start + maxFrames/2; f < start+maxFrames; f++ {
+			if voiced[f] {
+				run = 0
+				continue
+			}
+			run++
+			if run > bestRun {
+				bestRun = run
+				cut = f - run/2
+			}
+		}
+		chunks = append(chunks, transcriptionChunk{start: float64(start) * frameSeconds, end: float64(cut) * frameSeconds})
+		start = cut
+	}
+	return append(chunks, transcriptionChunk{start: float64(start) * frameSeconds, end: total})
+}
+
+// transcribeChunked transcribes a long call in chunks and reassembles the
+// result with timestamps relative to the whole call. It returns nil without an
+// error when the call fits in one chunk, so the caller transcribes it whole.
+// partial, when set, receives the text of the chunks finished so far, in order.
+func (queue *TranscriptionQueue) transcribeChunked(audio []byte, options TranscriptionOptions, settings TranscriptionChunkingSettings, partial func(text string)) (*TranscriptionResult, error) {
+	pcm, err := decodeMonoPCM(audio, options.AudioMime)
+	if err != nil {
+		return nil, err
+	}
+	if float64(len(pcm)/2)/energySampleHz < settings.MinCallDuration {
+		return nil, nil
+	}
+	chunks := transcriptionChunkBounds(pcm, settings.MaxChunkSeconds)
+	if len(chunks) < 2 {
+		return nil, nil
+	}
+
+	// Radio audio is narrowband, so chunks are sent as the 8 kHz decode
+	chunkOptions := options
+	chunkOptions.AudioMime = "audio/wav"
+
+	results := make([]*TranscriptionResult, len(chunks))
+	errs := make([]error, len(chunks))
+	var (
+		mutex    sync.Mutex
+		reported int
+		wg       sync.WaitGroup
+	)
+	slots := make(chan struct{}, settings.Concurrency)
+	for i, chunk := range chunks {
+		wg.Add(1)
+		go func(i int, chunk transcriptionChunk) {
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+
+			first := int(chunk.start*energySampleHz) * 2
+			last := int(chunk.end*energySampleHz) * 2
+			if last > len(pcm) {
+				last = len(pcm)
+			}
+			samples := make([]int16, (last-first)/2)
+			for s := range samples {
+				samples[s] = int16(uint16(pcm[first+s*2]) | uint16(pcm[first+s*2+1])<<8)
+			}
+			result, err := queue.provider.Transcribe(encodePCM16Wav(samples, energySampleHz), chunkOptions)
+
+			mutex.Lock()
+			defer mutex.Unlock()
+			results[i], errs[i] = result, err
+			if partial == nil || err != nil {
+				return
+			}
+			advanced := false
+			for reported < len(results) && results[reported] != nil {
+				reported++
+				advanced = true
+			}
+			if advanced {
+				partial(joinChunkTranscripts(results[:reported]))
+			}
+		}(i, chunk)
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			return nil, fmt.Errorf("chunk %d of %d (%.1fs-%.1fs): %w", i+1, len(chunks), chunks[i].start, chunks[i].end, err)
+		}
+	}
+	return assembleChunkedTranscription(chunks, results), nil
+}
+
+// assembleChunkedTranscription joins the chunk results into one, shifting the
+// segment timestamps by the chunk start. Confidence is weighted by duration.
+func assembleChunkedTranscription(chunks []transcriptionChunk, results []*TranscriptionResult) *TranscriptionResult {
+	assembled := &TranscriptionResult{Transcript: joinChunkTranscripts(results)}
+	var weighted, weights float64
+	for i, result := range results {
+		if result == nil {
+			continue
+		}
+		chunk := chunks[i]
+		if assembled.Language == "" {
+			assembled.Language = result.Language
+		}
+		if strings.TrimSpace(result.Transcript) != "" {
+			weighted += result.Confidence * (chunk.end - chunk.start)
+			weights += chunk.end - chunk.start
+		}
+		if len(result.Segments) == 0 && strings.TrimSpace(result.Transcript) != "" {
+			assembled.Segments = append(assembled.Segments, TranscriptSegment{
+				Text:       strings.TrimSpace(result.Transcript),
+				StartTime:  chunk.start,
+				EndTime:    chunk.end,
+				Confidence: result.Confidence,
+			})
+		}
+		for _, segment := range result.Segments {
+			segment.StartTime += chunk.start
+			segment.EndTime += chunk.start
+			assembled.Segments = append(assembled.Segments, segment)
+		}
+		if result.AlertSummary != "" {
+			assembled.AlertSummary = strings.TrimSpace(assembled.AlertSummary + " " + result.AlertSummary)
+		}
+	}
+	if weights > 0 {
+		assembled.Confidence = weighted / weights
+	}
+	return assembled
+}
+
+func joinChunkTranscripts(results []*TranscriptionResult) string {
+	var parts []string
+	for _, result := range results {
+		if result == nil {
+			continue
+		}
+		if text := strings.TrimSpace(result.Transcript); text != "" {
+			parts = append(parts, text)
+		}
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestTranscriptionChunkBounds(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	voice := func(seconds float64) []int16 {
+		samples := make([]int16, int(seconds*energySampleHz))
+		for i := range samples {
+			var v float64
+			for harmonic := 1; harmonic <= 20; harmonic++ {
+				v += math.Sin(2*math.Pi*150*float64(harmonic)*float64(i)/energySampleHz) / float64(harmonic)
+			}
+			samples[i] = int16(4000*v + random.NormFloat64()*100)
+		}
+		return samples
+	}
+	silence := func(seconds float64) []int16 {
+		samples := make([]int16, int(seconds*energySampleHz))
+		for i := range samples {
+			samples[i] = int16(random.NormFloat64() * 2)
+		}
+		return samples
+	}
+
+	// 20s of voice, a 1s pause, 15s of voice, a 1s pause, 20s of voice
+	var samples []int16
+	for _, part := range [][]int16{voice(20), silence(1), voice(15), silence(1), voice(20)} {
+		samples = append(samples, part...)
+	}
+
+	chunks := transcriptionChunkBounds(pcmFromSamples(samples), 30)
+	if len(chunks) != 3 {
+		t.Fatalf("expected 3 chunks, got %+v", chunks)
+	}
+	for i, want := range []float64{20.5, 36.5} {
+		if math.Abs(chunks[i].end-want) > 0.1 || chunks[i+1].start != chunks[i].end {
+			t.Fatalf("expected a cut at %.1fs, got %+v", want, chunks)
+		}
+	}
+	if chunks[2].end != 57 {
+		t.Fatalf("expected the last chunk to end at 57s, got %+v", chunks)
+	}
+
+	// Without a pause the cut falls at the maximum chunk length
+	chunks = transcriptionChunkBounds(pcmFromSamples(voice(40)), 30)
+	if len(chunks) != 2 || math.Abs(chunks[0].end-30) > 0.05 {
+		t.Fatalf("expected a cut at 30s, got %+v", chunks)
+	}
+
+	if chunks := transcriptionChunkBounds(pcmFromSamples(voice(10)), 30); len(chunks) != 1 {
+		t.Fatalf("expected a single chunk, got %+v", chunks)
+	}
+}
+
+func TestAssembleChunkedTranscription(t *testing.T) {
+	chunks := []transcriptionChunk{{0, 20}, {20, 30}, {30, 60}}
+	results := []*TranscriptionResult{
+		{Transcript: "ENGINE 4 RESPOND", Confidence: 0.9, Language: "en", Segments: []TranscriptSegment{{Text: "ENGINE 4 RESPOND", StartTime: 1, EndTime: 3}}},
+		{Transcript: " "},
+		{Transcript: "MAIN AND FIRST", Confidence: 0.6},
+	}
+
+	assembled := assembleChunkedTranscription(chunks, results)
+	if assembled.Transcript != "ENGINE 4 RESPOND MAIN AND FIRST" {
+		t.Fatalf("unexpected transcript %q", assembled.Transcript)
+	}
+	if assembled.Language != "en" {
+		t.Fatalf("unexpected language %q", assembled.Language)
+	}
+	if math.Abs(assembled.Confidence-0.72) > 1e-9 {
+		t.Fatalf("expected duration weighted confidence 0.72, got %f", assembled.Confidence)
+	}
+	if len(assembled.Segments) != 2 || assembled.Segments[1].StartTime != 30 || assembled.Segments[1].EndTime != 60 {
+		t.Fatalf("unexpected segments %+v", assembled.Segments)
+	}
+}
+
+func TestTranscriptionChunkingFor(t *testing.T) {
+	config := TranscriptionConfig{Provider: "deepgram", Chunking: map[string]TranscriptionChunkingSettings{
+		"deepgram":    {Enabled: true, MaxChunkSeconds: 45},
+		"whisper-api": {Enabled: true},
+	}}
+
+	settings, ok := config.chunkingFor()
+	if !ok || settings.MaxChunkSeconds != 45 || settings.MinCallDuration != 60 || settings.Concurrency != 3 {
+		t.Fatalf("unexpected settings %+v", settings)
+	}
+
+	config.Provider = "azure"
+	if _, ok := config.chunkingFor(); ok {
+		t.Fatalf("expected no chunking for a provider without settings")
+	}
+	config.Provider = ""
+	if _, ok := config.chunkingFor(); !ok {
+		t.Fatalf("expected the default provider to use the whisper-api settings")
+	}
+}
@@ -0,0 +1,255 @@
+// Copyright (C) 2026 Thinline Dynamic Solutions
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT EVEN THE IMPLIED WARRANTY OF MERCHANTABILITY or FITNESS
+// FOR A PARTICULAR PURPOSE.  See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const deepgramDefaultURL = "https://api.deepgram.com/v1/listen"
+
+// DeepgramTranscription implements TranscriptionProvider for Deepgram pre-recorded audio
+type DeepgramTranscription struct {
+	apiKey     string
+	model      string
+	baseURL    string
+	httpClient *http.Client
+}
+
+// DeepgramConfig contains configuration for Deepgram transcription
+type DeepgramConfig struct {
+	APIKey         string
+	Model          string // e.g. "nova-3" (default), "nova-2", "nova-2-phonecall"
+	TimeoutSeconds int
+}
+
+// NewDeepgramTranscription creates a new Deepgram transcription provider
+func NewDeepgramTranscription(config *DeepgramConfig) *DeepgramTranscription {
+	const defaultTimeoutSeconds = 300
+	timeoutSecs := config.TimeoutSeconds
+	if timeoutSecs <= 0 {
+		timeoutSecs = defaultTimeoutSeconds
+	}
+	timeout := time.Duration(timeoutSecs) * time.Second
+
+	transport := &http.Transport{
+		MaxIdleConns:        100,
+		MaxIdleConnsPerHost: 10,
+		MaxConnsPerHost:     20,
+		IdleConnTimeout:     90 * time.Second,
+		DialContext: (&net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ResponseHeaderTimeout: timeout,
+		ExpectContinueTimeout: 1 * time.Second,
+	}
+
+	model := config.Model
+	if model == "" {
+		model = "nova-3"
+	}
+
+	return &DeepgramTranscription{
+		apiKey:  config.APIKey,
+		model:   model,
+		baseURL: deepgramDefaultURL,
+		httpClient: &http.Client{
+			Timeout:   timeout,
+			Transport: transport,
+		},
+	}
+}
+
+// Transcribe transcribes audio using Deepgram
+func (deepgram *DeepgramTranscription) Transcribe(audio []byte, options TranscriptionOptions) (*TranscriptionResult, error) {
+	maxRetries := 3
+	baseDelay := 1 * time.Second
+
+	var lastErr error
+	for attempt := 0; attempt <= maxRetries; attempt++ {
+		if attempt > 0 {
+			delay := baseDelay * time.Duration(1<<uint(attempt-1))
+			time.Sleep(delay)
+		}
+
+		result, err := deepgram.attemptTranscribe(audio, options)
+		if err == nil {
+			return result, nil
+		}
+
+		lastErr = err
+
+		if isRetryableError(err) && attempt < maxRetries {
+			continue
+		}
+
+		break
+	}
+
+	return nil, lastErr
+}
+
+// query maps TranscriptionOptions onto Deepgram's query parameters.
+func (deepgram *DeepgramTranscription) query(options TranscriptionOptions) url.Values {
+	query := url.Values{}
+	query.Set("model", deepgram.model)
+	query.Set("punctuate", "true")
+	query.Set("smart_format", "true")
+	query.Set("numerals", "true")
+
+	language := options.Language
+	if language == "" || language == "auto" {
+		query.Set("detect_language", "true")
+	} else {
+		query.Set("language", language)
+	}
+
+	// Nova-3 takes plain key terms; older models take keywords with an intensifier
+	seen := map[string]bool{}
+	for _, term := range options.WordBoost {
+		term = strings.TrimSpace(term)
+		if term == "" || seen[strings.ToLower(term)] {
+			continue
+		}
+		seen[strings.ToLower(term)] = true
+		if strings.HasPrefix(deepgram.model, "nova-3") {
+			query.Add("keyterm", term)
+		} else if !strings.Contains(term, " ") {
+			query.Add("keywords", term+":2")
+		}
+	}
+
+	return query
+}
+
+func (deepgram *DeepgramTranscription) attemptTranscribe(audio []byte, options TranscriptionOptions) (*TranscriptionResult, error) {
+	req, err := http.NewRequest("POST", deepgram.baseURL+"?"+deepgram.query(options).Encode(), bytes.NewReader(audio))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %v", err)
+	}
+
+	mime := options.AudioMime
+	if mime == "" {
+		mime = "application/octet-stream"
+	}
+	req.Header.Set("Content-Type", mime)
+	req.Header.Set("Authorization", "Token "+deepgram.apiKey)
+
+	resp, err := deepgram.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	respBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %v", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(respBytes))
+	}
+
+	return parseDeepgramResponse(respBytes, options.Language)
+}
+
+// parseDeepgramResponse reads the first alternative of the first channel.
+func parseDeepgramResponse(b []byte, language string) (*TranscriptionResult, error) {
+	var dgResponse struct {
+		Results struct {
+			Channels []struct {
+				DetectedLanguage string `json:"detected_language"`
+				Alternatives     []struct {
+					Transcript string  `json:"transcript"`
+					Confidence float64 `json:"confidence"`
+					Words      []struct {
+						Word           string  `json:"word"`
+						PunctuatedWord string  `json:"punctuated_word"`
+						Start          float64 `json:"start"`
+						End            float64 `json:"end"`
+						Confidence     float64 `json:"confidence"`
+					} `json:"words"`
+				} `json:"alternatives"`
+			} `json:"channels"`
+		} `json:"results"`
+	}
+
+	if err := json.Unmarshal(b, &dgResponse); err != nil {
+		return nil, fmt.Errorf("failed to parse API response: %v", err)
+	}
+
+	result := &TranscriptionResult{Language: language}
+
+	if len(dgResponse.Results.Channels) == 0 || len(dgResponse.Results.Channels[0].Alternatives) == 0 {
+		return result, nil
+	}
+
+	channel := dgResponse.Results.Channels[0]
+	alternative := channel.Alternatives[0]
+
+	if channel.DetectedLanguage != "" {
+		result.Language = channel.DetectedLanguage
+	}
+	result.Transcript = strings.ToUpper(strings.TrimSpace(alternative.Transcript))
+	result.Confidence = alternative.Confidence
+
+	for _, w := range alternative.Words {
+		word := w.PunctuatedWord
+		if word == "" {
+			word = w.Word
+		}
+		if word = strings.TrimSpace(word); word == "" {
+			continue
+		}
+		result.Segments = append(result.Segments, TranscriptSegment{
+			Text:       strings.ToUpper(word),
+			StartTime:  w.Start,
+			EndTime:    w.End,
+			Confidence: w.Confidence,
+		})
+	}
+
+	return result, nil
+}
+
+// IsAvailable reports whether an API key is configured; connectivity errors surface at transcription time
+func (deepgram *DeepgramTranscription) IsAvailable() bool {
+	return deepgram.apiKey != ""
+}
+
+// GetName returns the name of this transcription provider
+func (deepgram *DeepgramTranscription) GetName() string {
+	return fmt.Sprintf("Deepgram (%s)", deepgram.model)
+}
+
+// GetSupportedLanguages returns supported languages
+func (deepgram *DeepgramTranscription) GetSupportedLanguages() []string {
+	return []string{
+		"auto", "en", "en-US", "en-GB", "en-AU", "es", "es-419", "fr", "fr-CA",
+		"de", "it", "pt", "pt-BR", "nl", "ru", "ja", "ko", "zh", "hi", "uk",
+		"pl", "sv", "da", "no", "fi", "tr", "id", "vi",
+	}
+}
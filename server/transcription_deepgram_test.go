@@ -0,0 +1,57 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestDeepgramTranscribe(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if r.Header.Get("Authorization") != "Token secret" || r.Header.Get("Content-Type") != "audio/mpeg" {
+			t.Errorf("headers = %v", r.Header)
+		}
+		if query.Get("model") != "nova-3" || query.Get("punctuate") != "true" || query.Get("language") != "en" {
+			t.Errorf("query = %v", query)
+		}
+		if terms := query["keyterm"]; len(terms) != 2 || terms[0] != "Engine 5" || terms[1] != "Medic" {
+			t.Errorf("keyterm = %q", terms)
+		}
+		if b, _ := io.ReadAll(r.Body); string(b) != "audio" {
+			t.Errorf("body = %q", b)
+		}
+
+		w.Write([]byte(`{"results":{"channels":[{"alternatives":[{"transcript":"Engine 5, respond.","confidence":0.91,
+			"words":[{"word":"engine","punctuated_word":"Engine","start":0.1,"end":0.4,"confidence":0.9}]}]}]}}`))
+	}))
+	defer server.Close()
+
+	deepgram := NewDeepgramTranscription(&DeepgramConfig{APIKey: "secret"})
+	deepgram.baseURL = server.URL
+
+	result, err := deepgram.Transcribe([]byte("audio"), TranscriptionOptions{
+		Language:  "en",
+		AudioMime: "audio/mpeg",
+		WordBoost: []string{"Engine 5", "Medic", "medic", ""},
+	})
+	if err != nil {
+		t.Fatalf("transcribe: %v", err)
+	}
+	if result.Transcript != "ENGINE 5, RESPOND." || result.Confidence != 0.91 || len(result.Segments) != 1 || result.Segments[0].Text != "ENGINE" {
+		t.Fatalf("result = %+v", result)
+	}
+}
+
+func TestDeepgramQueryOlderModels(t *testing.T) {
+	deepgram := NewDeepgramTranscription(&DeepgramConfig{APIKey: "secret", Model: "nova-2"})
+
+	query := deepgram.query(TranscriptionOptions{Language: "auto", WordBoost: []string{"Medic", "Engine 5"}})
+	if query.Get("detect_language") != "true" || query.Get("language") != "" {
+		t.Fatalf("query = %v", query)
+	}
+	if keywords := query["keywords"]; len(keywords) != 1 || keywords[0] != "Medic:2" || query.Get("keyterm") != "" {
+		t.Fatalf("keywords = %q", keywords)
+	}
+}
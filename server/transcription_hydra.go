@@ -19,13 +19,14 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"log"
 	"net/http"
 	"strings"
 	"sync"
 	"time"
 )
 
+var hydraLog = Logger(LogCategoryTranscription)
+
 const (
 	hydraBaseURL = "https://hydra.alertpage.us"
 )
@@ -65,9 +66,9 @@ func NewHydraTranscriptionRetrievalQueue(controller *Controller) *HydraTranscrip
 
 	// Authenticate immediately to get JWT
 	if err := queue.authenticate(); err != nil {
-		log.Printf("Hydra retrieval: WARNING - initial authentication failed: %v", err)
+		hydraLog.Warn(fmt.Sprintf("Hydra retrieval: initial authentication failed: %v", err))
 	} else {
-		log.Printf("Hydra retrieval: queue initialized and authenticated, starting poll worker")
+		hydraLog.Info("Hydra retrieval: queue initialized and authenticated, starting poll worker")
 	}
 
 	// Start the polling worker
@@ -90,18 +91,18 @@ func (queue *HydraTranscriptionRetrievalQueue) QueueJob(job HydraTranscriptionRe
 	select {
 	case queue.jobs <- job:
 		if job.RetryCount == 0 {
-			log.Printf("Hydra retrieval: queued call %d with transmission_id=%s", job.CallId, job.TransmissionId)
+			hydraLog.Debug(fmt.Sprintf("Hydra retrieval: queued call %d with transmission_id=%s", job.CallId, job.TransmissionId))
 		} else {
-			log.Printf("Hydra retrieval: requeued call %d with transmission_id=%s (retry %d)", job.CallId, job.TransmissionId, job.RetryCount)
+			hydraLog.Debug(fmt.Sprintf("Hydra retrieval: requeued call %d with transmission_id=%s (retry %d)", job.CallId, job.TransmissionId, job.RetryCount))
 		}
 	default:
-		log.Printf("Hydra retrieval: queue full, dropping call %d", job.CallId)
+		hydraLog.Warn(fmt.Sprintf("Hydra retrieval: queue full, dropping call %d", job.CallId))
 	}
 }
 
 // pollWorker polls Hydra API every 6 seconds to retrieve transcriptions for queued jobs
 func (queue *HydraTranscriptionRetrievalQueue) pollWorker() {
-	log.Printf("Hydra retrieval: poll worker started")
+	hydraLog.Info("Hydra retrieval: poll worker started")
 	ticker := time.NewTicker(6 * time.Second)
 	defer ticker.Stop()
 
@@ -113,7 +114,7 @@ func (queue *HydraTranscriptionRetrievalQueue) pollWorker() {
 		case <-ticker.C:
 			queue.processBatch()
 		case <-queue.stopChan:
-			log.Printf("Hydra retrieval: poll worker stopped")
+			hydraLog.Info("Hydra retrieval: poll worker stopped")
 			return
 		}
 	}
@@ -171,7 +172,7 @@ func (queue *HydraTranscriptionRetrievalQueue) authenticate() error {
 	// JWT expires in 90 days according to docs, but set expiry to 85 days to be safe
 	queue.tokenExpiry = time.Now().Add(85 * 24 * time.Hour)
 
-	log.Printf("Hydra retrieval: authenticated successfully, JWT expires at %v", queue.tokenExpiry)
+	hydraLog.Info(fmt.Sprintf("Hydra retrieval: authenticated successfully, JWT expires at %v", queue.tokenExpiry))
 	return nil
 }
 
@@ -205,7 +206,7 @@ func (queue *HydraTranscriptionRetrievalQueue) processBatch() {
 		firstCheck := queue.lastNoJobsLogTime.IsZero()
 		queue.mutex.Unlock()
 		if firstCheck {
-			log.Printf("Hydra retrieval: processBatch called but Hydra disabled or no API secret (enabled=%v, hasSecret=%v)", enabled, queue.apiSecret != "")
+			hydraLog.Debug(fmt.Sprintf("Hydra retrieval: processBatch called but Hydra disabled or no API secret (enabled=%v, hasSecret=%v)", enabled, queue.apiSecret != ""))
 		}
 		// Hydra not enabled or no API secret - skip processing but keep queue running
 		// Log once every 60 seconds if disabled to help debug
@@ -217,9 +218,9 @@ func (queue *HydraTranscriptionRetrievalQueue) processBatch() {
 		queue.mutex.Unlock()
 		if shouldLog {
 			if !enabled {
-				log.Printf("Hydra retrieval: queue running but Hydra transcription is disabled (enabled=%v, apiSecret empty=%v)", enabled, queue.apiSecret == "")
+				hydraLog.Debug(fmt.Sprintf("Hydra retrieval: queue running but Hydra transcription is disabled (enabled=%v, apiSecret empty=%v)", enabled, queue.apiSecret == ""))
 			} else {
-				log.Printf("Hydra retrieval: queue running but API secret is empty (enabled=%v)", enabled)
+				hydraLog.Debug(fmt.Sprintf("Hydra retrieval: queue running but API secret is empty (enabled=%v)", enabled))
 			}
 		}
 		return
@@ -227,7 +228,7 @@ func (queue *HydraTranscriptionRetrievalQueue) processBatch() {
 
 	// Ensure we have a valid JWT token
 	if err := queue.ensureAuthenticated(); err != nil {
-		log.Printf("Hydra retrieval: authentication failed: %v", err)
+		hydraLog.Warn(fmt.Sprintf("Hydra retrieval: authentication failed: %v", err))
 		return
 	}
 
@@ -240,7 +241,7 @@ func (queue *HydraTranscriptionRetrievalQueue) processBatch() {
 	queue.pollCount++
 	queue.mutex.Unlock()
 	if pollCount < 5 {
-		log.Printf("Hydra retrieval: processBatch checking for ready jobs (poll #%d)", pollCount+1)
+		hydraLog.Debug(fmt.Sprintf("Hydra retrieval: processBatch checking for ready jobs (poll #%d)", pollCount+1))
 	}
 	readyJobs := make([]HydraTranscriptionRetrievalJob, 0, 15)
 	pendingJobs := make([]HydraTranscriptionRetrievalJob, 0)
@@ -268,18 +269,18 @@ func (queue *HydraTranscriptionRetrievalQueue) processBatch() {
 		case queue.jobs <- job:
 			// Successfully requeued
 		default:
-			log.Printf("Hydra retrieval: queue full, dropping pending call %d", job.CallId)
+			hydraLog.Warn(fmt.Sprintf("Hydra retrieval: queue full, dropping pending call %d", job.CallId))
 		}
 	}
 
 	if len(readyJobs) == 0 {
 		if len(pendingJobs) > 0 {
-			log.Printf("Hydra retrieval: %d job(s) still waiting for 15-second delay", len(pendingJobs))
+			hydraLog.Debug(fmt.Sprintf("Hydra retrieval: %d job(s) still waiting for 15-second delay", len(pendingJobs)))
 		}
 		return
 	}
 
-	log.Printf("Hydra retrieval: processing %d transmission(s) (%d still waiting)", len(readyJobs), len(pendingJobs))
+	hydraLog.Debug(fmt.Sprintf("Hydra retrieval: processing %d transmission(s) (%d still waiting)", len(readyJobs), len(pendingJobs)))
 
 	// Query Hydra API for each transmission ID
 	for _, job := range readyJobs {
@@ -290,7 +291,7 @@ func (queue *HydraTranscriptionRetrievalQueue) processBatch() {
 // retrieveTranscription queries Hydra API for a single transmission ID
 func (queue *HydraTranscriptionRetrievalQueue) retrieveTranscription(job HydraTranscriptionRetrievalJob) {
 	if job.TransmissionId == "" {
-		log.Printf("Hydra retrieval: skipping call %d - empty transmission_id", job.CallId)
+		hydraLog.Debug(fmt.Sprintf("Hydra retrieval: skipping call %d - empty transmission_id", job.CallId))
 		return
 	}
 
@@ -298,7 +299,7 @@ func (queue *HydraTranscriptionRetrievalQueue) retrieveTranscription(job HydraTr
 	url := fmt.Sprintf("%s/api/transmission/%s", hydraBaseURL, job.TransmissionId)
 	req, err := http.NewRequest("GET", url, nil)
 	if err != nil {
-		log.Printf("Hydra retrieval: failed to create request for call %d: %v", job.CallId, err)
+		hydraLog.Warn(fmt.Sprintf("Hydra retrieval: failed to create request for call %d: %v", job.CallId, err))
 		return
 	}
 
@@ -308,7 +309,7 @@ func (queue *HydraTranscriptionRetrievalQueue) retrieveTranscription(job HydraTr
 	queue.mutex.Unlock()
 
 	if jwtToken == "" {
-		log.Printf("Hydra retrieval: no JWT token available for call %d", job.CallId)
+		hydraLog.Warn(fmt.Sprintf("Hydra retrieval: no JWT token available for call %d", job.CallId))
 		return
 	}
 
@@ -318,20 +319,20 @@ func (queue *HydraTranscriptionRetrievalQueue) retrieveTranscription(job HydraTr
 	client := &http.Client{Timeout: 10 * time.Second}
 	resp, err := client.Do(req)
 	if err != nil {
-		log.Printf("Hydra retrieval: failed to query Hydra for call %d: %v", job.CallId, err)
+		hydraLog.Warn(fmt.Sprintf("Hydra retrieval: failed to query Hydra for call %d: %v", job.CallId, err))
 		return
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode == http.StatusUnauthorized {
 		// JWT expired or invalid - re-authenticate and retry once
-		log.Printf("Hydra retrieval: JWT expired/invalid for call %d, re-authenticating", job.CallId)
+		hydraLog.Warn(fmt.Sprintf("Hydra retrieval: JWT expired/invalid for call %d, re-authenticating", job.CallId))
 		queue.mutex.Lock()
 		queue.jwtToken = "" // Clear invalid token
 		queue.mutex.Unlock()
 		
 		if err := queue.authenticate(); err != nil {
-			log.Printf("Hydra retrieval: re-authentication failed: %v", err)
+			hydraLog.Warn(fmt.Sprintf("Hydra retrieval: re-authentication failed: %v", err))
 			return
 		}
 		
@@ -346,20 +347,20 @@ func (queue *HydraTranscriptionRetrievalQueue) retrieveTranscription(job HydraTr
 		
 		resp2, err := client.Do(req2)
 		if err != nil {
-			log.Printf("Hydra retrieval: retry failed for call %d: %v", job.CallId, err)
+			hydraLog.Warn(fmt.Sprintf("Hydra retrieval: retry failed for call %d: %v", job.CallId, err))
 			return
 		}
 		defer resp2.Body.Close()
 		
 		if resp2.StatusCode != http.StatusOK {
-			log.Printf("Hydra retrieval: Hydra returned %d for call %d (transmission_id=%s) after re-auth", resp2.StatusCode, job.CallId, job.TransmissionId)
+			hydraLog.Warn(fmt.Sprintf("Hydra retrieval: Hydra returned %d for call %d (transmission_id=%s) after re-auth", resp2.StatusCode, job.CallId, job.TransmissionId))
 			return
 		}
 		
 		// Use resp2 for decoding
 		resp = resp2
 	} else if resp.StatusCode != http.StatusOK {
-		log.Printf("Hydra retrieval: Hydra returned %d for call %d (transmission_id=%s)", resp.StatusCode, job.CallId, job.TransmissionId)
+		hydraLog.Warn(fmt.Sprintf("Hydra retrieval: Hydra returned %d for call %d (transmission_id=%s)", resp.StatusCode, job.CallId, job.TransmissionId))
 		return
 	}
 
@@ -375,7 +376,7 @@ func (queue *HydraTranscriptionRetrievalQueue) retrieveTranscription(job HydraTr
 	// Read response body
 	bodyBytes, err := io.ReadAll(resp.Body)
 	if err != nil {
-		log.Printf("Hydra retrieval: failed to read Hydra response for call %d: %v", job.CallId, err)
+		hydraLog.Warn(fmt.Sprintf("Hydra retrieval: failed to read Hydra response for call %d: %v", job.CallId, err))
 		return
 	}
 	
@@ -384,21 +385,21 @@ func (queue *HydraTranscriptionRetrievalQueue) retrieveTranscription(job HydraTr
 	if len(bodyStr) > 500 {
 		bodyStr = bodyStr[:500] + "..."
 	}
-	log.Printf("Hydra retrieval: response for call %d (transmission_id=%s): %s", job.CallId, job.TransmissionId, bodyStr)
+	hydraLog.Debug(fmt.Sprintf("Hydra retrieval: response for call %d (transmission_id=%s): %s", job.CallId, job.TransmissionId, bodyStr))
 
 	if err := json.Unmarshal(bodyBytes, &hydraResponse); err != nil {
-		log.Printf("Hydra retrieval: failed to decode Hydra response for call %d: %v", job.CallId, err)
+		hydraLog.Warn(fmt.Sprintf("Hydra retrieval: failed to decode Hydra response for call %d: %v", job.CallId, err))
 		return
 	}
 
 	if !hydraResponse.Success {
-		log.Printf("Hydra retrieval: no transcription found for call %d (transmission_id=%s)", job.CallId, job.TransmissionId)
+		hydraLog.Debug(fmt.Sprintf("Hydra retrieval: no transcription found for call %d (transmission_id=%s)", job.CallId, job.TransmissionId))
 		return
 	}
 
 	// Verify the returned transmission matches what we requested
 	if hydraResponse.Result.IdTransmission != "" && hydraResponse.Result.IdTransmission != job.TransmissionId {
-		log.Printf("Hydra retrieval: WARNING - Hydra returned wrong transmission for call %d: requested=%s, got=%s", job.CallId, job.TransmissionId, hydraResponse.Result.IdTransmission)
+		hydraLog.Warn(fmt.Sprintf("Hydra retrieval: Hydra returned wrong transmission for call %d: requested=%s, got=%s", job.CallId, job.TransmissionId, hydraResponse.Result.IdTransmission))
 		return
 	}
 
@@ -406,7 +407,7 @@ func (queue *HydraTranscriptionRetrievalQueue) retrieveTranscription(job HydraTr
 	if transcriptionText == "" || strings.TrimSpace(transcriptionText) == "" {
 		// Empty transcription - requeue once if this is the first attempt
 		if job.RetryCount == 0 {
-			log.Printf("Hydra retrieval: empty transcription for call %d (transmission_id=%s), requeuing for retry", job.CallId, job.TransmissionId)
+			hydraLog.Debug(fmt.Sprintf("Hydra retrieval: empty transcription for call %d (transmission_id=%s), requeuing for retry", job.CallId, job.TransmissionId))
 			// Requeue with retry count incremented
 			// For retry, wait only 5 seconds (will be checked in next polling cycle)
 			retryJob := job
@@ -415,7 +416,7 @@ func (queue *HydraTranscriptionRetrievalQueue) retrieveTranscription(job HydraTr
 			queue.QueueJob(retryJob)
 		} else {
 			// Already retried once, drop it
-			log.Printf("Hydra retrieval: empty transcription for call %d (transmission_id=%s) after retry, dropping", job.CallId, job.TransmissionId)
+			hydraLog.Debug(fmt.Sprintf("Hydra retrieval: empty transcription for call %d (transmission_id=%s) after retry, dropping", job.CallId, job.TransmissionId))
 		}
 		return
 	}
@@ -429,13 +430,13 @@ func (queue *HydraTranscriptionRetrievalQueue) retrieveTranscription(job HydraTr
 	}
 	err = queue.controller.Database.Sql.QueryRow(verifyQuery, job.CallId).Scan(&dbTransmissionId)
 	if err != nil {
-		log.Printf("Hydra retrieval: failed to verify call %d exists: %v", job.CallId, err)
+		hydraLog.Warn(fmt.Sprintf("Hydra retrieval: failed to verify call %d exists: %v", job.CallId, err))
 		return
 	}
 	
 	// Verify transmission_id matches
 	if dbTransmissionId != job.TransmissionId {
-		log.Printf("Hydra retrieval: WARNING - transmission_id mismatch for call %d: stored=%q, expected=%q, skipping transcript storage", job.CallId, dbTransmissionId, job.TransmissionId)
+		hydraLog.Warn(fmt.Sprintf("Hydra retrieval: transmission_id mismatch for call %d: stored=%q, expected=%q, skipping transcript storage", job.CallId, dbTransmissionId, job.TransmissionId))
 		return
 	}
 
@@ -449,18 +450,18 @@ func (queue *HydraTranscriptionRetrievalQueue) retrieveTranscription(job HydraTr
 	}
 	result, err := queue.controller.Database.Sql.Exec(query, transcript, 1.0, "completed", job.CallId, job.TransmissionId)
 	if err != nil {
-		log.Printf("Hydra retrieval: failed to update call transcript for call %d: %v", job.CallId, err)
+		hydraLog.Warn(fmt.Sprintf("Hydra retrieval: failed to update call transcript for call %d: %v", job.CallId, err))
 		return
 	}
 	
 	// Verify the update actually affected a row
 	rowsAffected, err := result.RowsAffected()
 	if err != nil {
-		log.Printf("Hydra retrieval: failed to get rows affected for call %d: %v", job.CallId, err)
+		hydraLog.Warn(fmt.Sprintf("Hydra retrieval: failed to get rows affected for call %d: %v", job.CallId, err))
 		return
 	}
 	if rowsAffected == 0 {
-		log.Printf("Hydra retrieval: WARNING - no rows updated for call %d with transmission_id=%s (call may have been deleted or transmission_id changed)", job.CallId, job.TransmissionId)
+		hydraLog.Warn(fmt.Sprintf("Hydra retrieval: no rows updated for call %d with transmission_id=%s (call may have been deleted or transmission_id changed)", job.CallId, job.TransmissionId))
 		return
 	}
 
@@ -469,7 +470,7 @@ func (queue *HydraTranscriptionRetrievalQueue) retrieveTranscription(job HydraTr
 	if len(preview) > 80 {
 		preview = preview[:80] + "..."
 	}
-	log.Printf("Hydra retrieval: stored transcript for call %d (transmission_id=%s, len=%d): %q", job.CallId, job.TransmissionId, len(transcript), preview)
+	hydraLog.Debug(fmt.Sprintf("Hydra retrieval: stored transcript for call %d (transmission_id=%s, len=%d): %q", job.CallId, job.TransmissionId, len(transcript), preview))
 }
 
 // Stop stops the retrieval queue
@@ -493,6 +494,6 @@ func (queue *HydraTranscriptionRetrievalQueue) UpdateAPIKey(apiSecret string) {
 	if oldSecret != apiSecret {
 		queue.jwtToken = ""
 		queue.tokenExpiry = time.Time{}
-		log.Printf("Hydra retrieval: API secret updated, will re-authenticate on next request")
+		hydraLog.Info("Hydra retrieval: API secret updated, will re-authenticate on next request")
 	}
 }
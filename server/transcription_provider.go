@@ -23,6 +23,13 @@ type TranscriptionProvider interface {
 	GetSupportedLanguages() []string
 }
 
+// StreamingTranscriptionProvider is implemented by providers that can report
+// the transcript while it is being produced. partial receives the whole text
+// decoded so far each time it grows; the final result is returned as usual.
+type StreamingTranscriptionProvider interface {
+	TranscribeStream(audio []byte, options TranscriptionOptions, partial func(text string)) (*TranscriptionResult, error)
+}
+
 // TranscriptionOptions contains options for transcription
 type TranscriptionOptions struct {
 	Language       string   // "en", "auto", etc.
@@ -31,7 +38,7 @@ type TranscriptionOptions struct {
 	Temperature    float64  // Temperature for sampling (0.0-1.0)
 	InitialPrompt  string   // Initial prompt/context
 	AudioMime      string   // MIME type of audio (e.g., "audio/mp4", "audio/mpeg")
-	WordBoost      []string // Word boost/keyterms for AssemblyAI (max 100 terms, 50 chars each)
+	WordBoost      []string // Word boost/keyterms for AssemblyAI (max 100 terms, 50 chars each) and Deepgram
 	SpeechModel    string   // Speech model for AssemblyAI (e.g., "universal-2", "universal-3-pro")
 	SystemLabel    string   // Human-readable system name (passed to Whisper server for logging)
 	TalkgroupLabel string   // Human-readable talkgroup name (passed to Whisper server for logging)
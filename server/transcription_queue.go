@@ -16,7 +16,9 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
+	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -34,11 +36,21 @@ type TranscriptionJob struct {
 	TalkgroupId   uint64
 	Priority      int // Higher priority processed first
 	Reasons       []string
+	Backfill      bool // Re-transcription of a historical call: store the transcript only, no alerts
+	Done          chan<- error // receives the outcome when set, needs room for one value
+}
+
+// finish reports the outcome to whoever waits on the job.
+func (job TranscriptionJob) finish(err error) {
+	if job.Done != nil {
+		job.Done <- err
+	}
 }
 
 // TranscriptionQueue manages transcription jobs with a worker pool
 type TranscriptionQueue struct {
 	jobs            chan TranscriptionJob
+	priorityJobs    chan TranscriptionJob // priority tier jobs, taken before jobs
 	workers         int
 	provider        TranscriptionProvider
 	controller      *Controller
@@ -47,55 +59,57 @@ type TranscriptionQueue struct {
 	processedCount  atomic.Uint64 // total transcriptions completed since startup
 }
 
-// NewTranscriptionQueue creates a new transcription queue with worker pool
-func NewTranscriptionQueue(controller *Controller, config TranscriptionConfig) *TranscriptionQueue {
-	// Use configured worker pool size for all providers
-	// WARNING: For Whisper API (local Whisper), using more than 1 worker may cause
-	// transcription failures if insufficient VRAM is available. Users should start
-	// with 1 worker and increase only if they have adequate resources (8GB+ VRAM).
-	workerCount := config.WorkerPoolSize
-	if workerCount == 0 {
-		// Default to 1 for safety (can be increased by users with adequate resources)
-		workerCount = 1
-	}
+// newTranscriptionProvider builds the provider selected in config.
+func newTranscriptionProvider(config TranscriptionConfig) TranscriptionProvider {
+	var provider TranscriptionProvider
 
-	queue := &TranscriptionQueue{
-		jobs:       make(chan TranscriptionJob, 100), // Buffer 100 jobs
-		workers:    workerCount,
-		controller: controller,
-		running:    true,
-	}
-
-	// Initialize provider based on config
 	switch config.Provider {
 	case "whisper-api":
 		// External OpenAI-compatible Whisper API server
-		queue.provider = NewWhisperAPITranscription(&WhisperAPIConfig{
+		provider = NewWhisperAPITranscription(&WhisperAPIConfig{
 			BaseURL:        config.WhisperAPIURL,
 			APIKey:         config.WhisperAPIKey,
 			Model:          config.WhisperAPIModel,
 			TimeoutSeconds: config.TimeoutSeconds,
 		})
+	case "whisper-local":
+		// Local whisper.cpp / faster-whisper binary, no network needed
+		provider = NewWhisperLocalTranscription(&WhisperLocalConfig{
+			Engine:         config.WhisperLocalEngine,
+			Binary:         config.WhisperLocalBinary,
+			Model:          config.WhisperLocalModel,
+			GPU:            config.WhisperLocalGPU,
+			Threads:        config.WhisperLocalThreads,
+			Concurrency:    config.WhisperLocalConcurrency,
+			TimeoutSeconds: config.TimeoutSeconds,
+		})
 	case "azure":
 		// Azure Speech Services
-		queue.provider = NewAzureTranscription(&AzureConfig{
+		provider = NewAzureTranscription(&AzureConfig{
 			APIKey: config.AzureKey,
 			Region: config.AzureRegion,
 		})
 	case "google":
 		// Google Cloud Speech-to-Text
-		queue.provider = NewGoogleTranscription(&GoogleConfig{
+		provider = NewGoogleTranscription(&GoogleConfig{
 			APIKey:      config.GoogleAPIKey,
 			Credentials: config.GoogleCredentials,
 		})
 	case "assemblyai":
 		// AssemblyAI
-		queue.provider = NewAssemblyAITranscription(&AssemblyAIConfig{
+		provider = NewAssemblyAITranscription(&AssemblyAIConfig{
 			APIKey: config.AssemblyAIKey,
 		})
+	case "deepgram":
+		// Deepgram pre-recorded audio API
+		provider = NewDeepgramTranscription(&DeepgramConfig{
+			APIKey:         config.DeepgramKey,
+			Model:          config.DeepgramModel,
+			TimeoutSeconds: config.TimeoutSeconds,
+		})
 	case "cloudflare":
 		// Cloudflare Workers AI Whisper
-		queue.provider = NewCloudflareTranscription(&CloudflareConfig{
+		provider = NewCloudflareTranscription(&CloudflareConfig{
 			AccountID:      config.CloudflareAccountID,
 			APIToken:       config.CloudflareAPIToken,
 			Model:          config.CloudflareModel,
@@ -106,7 +120,7 @@ func NewTranscriptionQueue(controller *Controller, config TranscriptionConfig) *
 		// This provider case should not be used, but we handle it gracefully
 		// Hydra transcriptions are retrieved via HydraTranscriptionRetrievalQueue
 		// For now, use a no-op provider that will mark itself as unavailable
-		queue.provider = NewWhisperAPITranscription(&WhisperAPIConfig{
+		provider = NewWhisperAPITranscription(&WhisperAPIConfig{
 			BaseURL: "",
 			APIKey:  "",
 			Model:   "",
@@ -116,7 +130,7 @@ func NewTranscriptionQueue(controller *Controller, config TranscriptionConfig) *
 		if config.WhisperAPIURL == "" {
 			config.WhisperAPIURL = "http://localhost:8000"
 		}
-		queue.provider = NewWhisperAPITranscription(&WhisperAPIConfig{
+		provider = NewWhisperAPITranscription(&WhisperAPIConfig{
 			BaseURL:        config.WhisperAPIURL,
 			APIKey:         config.WhisperAPIKey,
 			Model:          config.WhisperAPIModel,
@@ -124,6 +138,31 @@ func NewTranscriptionQueue(controller *Controller, config TranscriptionConfig) *
 		})
 	}
 
+	return provider
+}
+
+// NewTranscriptionQueue creates a new transcription queue with worker pool
+func NewTranscriptionQueue(controller *Controller, config TranscriptionConfig) *TranscriptionQueue {
+	// Use configured worker pool size for all providers
+	// WARNING: For Whisper API (local Whisper), using more than 1 worker may cause
+	// transcription failures if insufficient VRAM is available. Users should start
+	// with 1 worker and increase only if they have adequate resources (8GB+ VRAM).
+	workerCount := config.WorkerPoolSize
+	if workerCount == 0 {
+		// Default to 1 for safety (can be increased by users with adequate resources)
+		workerCount = 1
+	}
+
+	queue := &TranscriptionQueue{
+		jobs:         make(chan TranscriptionJob, 100), // Buffer 100 jobs
+		priorityJobs: make(chan TranscriptionJob, 100),
+		workers:    workerCount,
+		controller: controller,
+		running:    true,
+	}
+
+	queue.provider = newTranscriptionProvider(config)
+
 	// Start worker pool
 	if queue.provider.IsAvailable() {
 		for i := 0; i < queue.workers; i++ {
@@ -139,26 +178,124 @@ func NewTranscriptionQueue(controller *Controller, config TranscriptionConfig) *
 	return queue
 }
 
-// QueueJob adds a job to the transcription queue
-func (queue *TranscriptionQueue) QueueJob(job TranscriptionJob) {
+// QueueJob adds a job to the transcription queue, reporting false when it
+// was dropped.
+func (queue *TranscriptionQueue) QueueJob(job TranscriptionJob) bool {
 	if !queue.running {
-		return
+		return false
+	}
+
+	jobs := queue.jobs
+	if job.Priority >= transcriptionPriorityTier {
+		jobs = queue.priorityJobs
 	}
 
 	select {
-	case queue.jobs <- job:
+	case jobs <- job:
 		// Job queued successfully
 		queue.controller.Logs.LogEvent(LogLevelInfo, fmt.Sprintf("transcription job queued for call %d (priority: %d)", job.CallId, job.Priority))
+		return true
 	default:
 		// Queue is full, log warning
 		queue.controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("transcription queue full, dropping call %d", job.CallId))
+		return false
+	}
+}
+
+// jobKindTranscription is a persistent job transcribing one call, so calls
+// queued for transcription are not lost on a crash or restart.
+const jobKindTranscription = "transcription"
+
+const (
+	// transcriptionJobConcurrency jobs are handed to the in-memory queue at
+	// once; its workers set how many are transcribed at the same time.
+	transcriptionJobConcurrency = 32
+	// transcriptionJobTimeout gives up waiting for a job the in-memory queue
+	// lost, so it is retried.
+	transcriptionJobTimeout = 30 * time.Minute
+)
+
+type transcriptionJobPayload struct {
+	CallId  uint64   `json:"callId"`
+	Reasons []string `json:"reasons,omitempty"`
+}
+
+// runTranscriptionJob hands a persistent transcription job to the in-memory
+// queue and waits for the outcome. The audio handed over at ingest is used
+// when this process queued the job; after a restart the stored audio is.
+func (controller *Controller) runTranscriptionJob(job *Job) error {
+	var payload transcriptionJobPayload
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		return jobPermanent(fmt.Errorf("invalid payload: %v", err))
+	}
+
+	if !controller.Options.TranscriptionConfig.Enabled {
+		return jobPermanent(fmt.Errorf("transcription is disabled"))
+	}
+	if controller.TranscriptionQueue == nil {
+		controller.TranscriptionQueue = NewTranscriptionQueue(controller, controller.Options.TranscriptionConfig)
+	}
+	queue := controller.TranscriptionQueue
+	if !queue.provider.IsAvailable() {
+		return fmt.Errorf("transcription provider %s is not available", queue.provider.GetName())
+	}
+
+	transcription, ok := job.local.(TranscriptionJob)
+	if !ok {
+		call, err := controller.Calls.GetCall(payload.CallId)
+		if err != nil || call == nil || call.System == nil || call.Talkgroup == nil {
+			return jobPermanent(fmt.Errorf("call %d not found", payload.CallId))
+		}
+		if len(call.Audio) == 0 {
+			return jobPermanent(fmt.Errorf("call %d has no audio", payload.CallId))
+		}
+		transcription = TranscriptionJob{
+			CallId:        call.Id,
+			Audio:         call.Audio,
+			AudioMime:     call.AudioMime,
+			OriginalAudio: call.Audio,
+			OriginalMime:  call.AudioMime,
+			SystemId:      call.System.Id,
+			TalkgroupId:   call.Talkgroup.Id,
+			Reasons:       payload.Reasons,
+		}
+	}
+	transcription.Priority = job.Priority
+
+	done := make(chan error, 1)
+	transcription.Done = done
+	if !queue.QueueJob(transcription) {
+		return fmt.Errorf("transcription queue is full")
+	}
+
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(transcriptionJobTimeout):
+		return fmt.Errorf("no outcome after %s", transcriptionJobTimeout)
+	}
+}
+
+// nextJob waits for the next job, taking priority tier jobs first.
+func (queue *TranscriptionQueue) nextJob() (TranscriptionJob, bool) {
+	select {
+	case job, ok := <-queue.priorityJobs:
+		return job, ok
+	default:
+	}
+	select {
+	case job, ok := <-queue.priorityJobs:
+		return job, ok
+	case job, ok := <-queue.jobs:
+		return job, ok
 	}
 }
 
 // worker processes transcription jobs
 func (queue *TranscriptionQueue) worker(workerId int) {
-	for job := range queue.jobs {
-		if !queue.running {
+	for {
+		job, ok := queue.nextJob()
+		if !ok || !queue.running {
 			return
 		}
 
@@ -198,7 +335,7 @@ func (queue *TranscriptionQueue) worker(workerId int) {
 
 		// LOCK PENDING TONES: Prevent new tones from merging while this call transcribes
 		// This prevents unrelated tones (from a different incident) from being attached to this voice call
-		if call != nil && call.System != nil && call.Talkgroup != nil {
+		if !job.Backfill && call != nil && call.System != nil && call.Talkgroup != nil {
 			key := fmt.Sprintf("%d:%d", call.System.Id, call.Talkgroup.Id)
 			queue.controller.pendingTonesMutex.Lock()
 			if pending, exists := queue.controller.pendingTones[key]; exists && pending != nil && !pending.Locked {
@@ -218,6 +355,8 @@ func (queue *TranscriptionQueue) worker(workerId int) {
 		// Resolve transcription prompt: talkgroup overrides system which overrides global.
 		// An empty string at any level means "fall through to the next level".
 		resolvedPrompt := queue.controller.Options.TranscriptionConfig.Prompt
+		// Talkgroups flagged with a spoken language are transcribed in that language
+		resolvedLanguage := queue.controller.Options.TranscriptionConfig.Language
 		if system, ok := queue.controller.Systems.GetSystemById(job.SystemId); ok {
 			if system.TranscriptionPrompt != "" {
 				resolvedPrompt = system.TranscriptionPrompt
@@ -226,12 +365,15 @@ func (queue *TranscriptionQueue) worker(workerId int) {
 				if talkgroup.TranscriptionPrompt != "" {
 					resolvedPrompt = talkgroup.TranscriptionPrompt
 				}
+				if talkgroup.TranscriptLanguage != "" {
+					resolvedLanguage = talkgroup.TranscriptLanguage
+				}
 			}
 		}
 
 		// Transcribe audio (filtered if tones were present, original otherwise)
 		transcriptionOpts := TranscriptionOptions{
-			Language:       queue.controller.Options.TranscriptionConfig.Language,
+			Language:       resolvedLanguage,
 			InitialPrompt:  resolvedPrompt,
 			AudioMime:      audioMimeType,
 			SystemLabel:    systemLabel,
@@ -254,7 +396,42 @@ func (queue *TranscriptionQueue) worker(workerId int) {
 			transcriptionOpts.WordBoost = wordBoost
 		}
 
-		result, err := queue.provider.Transcribe(audioToTranscribe, transcriptionOpts)
+		// Deepgram boosts the configured keywords plus per-channel prompt terms
+		if queue.controller.Options.TranscriptionConfig.Provider == "deepgram" {
+			wordBoost := append([]string{}, queue.controller.Options.TranscriptionConfig.DeepgramKeywords...)
+			if resolvedPrompt != queue.controller.Options.TranscriptionConfig.Prompt && resolvedPrompt != "" {
+				wordBoost = append(wordBoost, strings.Fields(resolvedPrompt)...)
+			}
+			transcriptionOpts.WordBoost = wordBoost
+		}
+
+		// Streaming providers report partial transcripts, which lets critical
+		// keywords alert before the whole call has been transcribed
+		var spotter *realtimeKeywordSpotter
+		streamer, streaming := queue.provider.(StreamingTranscriptionProvider)
+		if streaming {
+			spotter = queue.newRealtimeKeywordSpotter(call)
+		}
+
+		var result *TranscriptionResult
+		// Long calls are split at silences and transcribed in parallel chunks
+		if chunking, ok := queue.controller.Options.TranscriptionConfig.chunkingFor(); ok && (call == nil || call.Duration >= chunking.MinCallDuration) {
+			var partial func(text string)
+			if spotter != nil {
+				partial = spotter.partial
+			}
+			if result, err = queue.transcribeChunked(audioToTranscribe, transcriptionOpts, chunking, partial); err != nil {
+				queue.controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("transcription worker %d: chunked transcription of call %d failed, transcribing it whole: %v", workerId, job.CallId, err))
+				result = nil
+			}
+		}
+		if result == nil {
+			if spotter != nil {
+				result, err = streamer.TranscribeStream(audioToTranscribe, transcriptionOpts, spotter.partial)
+			} else {
+				result, err = queue.provider.Transcribe(audioToTranscribe, transcriptionOpts)
+			}
+		}
 
 		if err != nil {
 			errorMsg := err.Error()
@@ -291,21 +468,74 @@ func (queue *TranscriptionQueue) worker(workerId int) {
 				queue.controller.pendingTonesMutex.Unlock()
 			}
 
+			job.finish(err)
 			continue
 		}
 
 		// Clean the transcript of hallucinations before storing and processing
 		cleanedTranscript, hadHallucinations := queue.controller.cleanTranscript(result.Transcript, job.CallId)
 
+		// Backfilled calls are historical: replace the transcript and stop there
+		if job.Backfill {
+			backfilled := &TranscriptionResult{
+				Transcript: cleanedTranscript,
+				Confidence: result.Confidence,
+				Language:   result.Language,
+			}
+			if call != nil {
+				backfilled.AlertSummary = call.AlertSummary
+			}
+			queue.storeTranscription(job.CallId, backfilled)
+			queue.controller.Logs.LogEvent(LogLevelInfo, fmt.Sprintf(
+				"[transcription] worker %d | call %d | %s / %s | backfilled in %.2fs",
+				workerId, job.CallId, systemLabel, talkgroupLabel, time.Since(startTime).Seconds(),
+			))
+			job.finish(nil)
+			continue
+		}
+
+		// Life-safety phrases come first, before the transcript is stored or any
+		// other alert is considered
+		if call != nil && queue.controller.AlertEngine != nil {
+			language := result.Language
+			if language == "" {
+				language = transcriptionOpts.Language
+			}
+			queue.controller.AlertEngine.TriggerLifeSafetyAlerts(call, cleanedTranscript, language)
+		}
+
+		// Summarize voiced calls before they are stored, so alerts that follow
+		// carry the summary. A summary from the Whisper server is kept as is.
+		alertSummary := strings.TrimSpace(result.AlertSummary)
+		if alertSummary == "" && queue.controller.Options.SummarizationConfig.Enabled && queue.controller.isActualVoice(cleanedTranscript) {
+			if summary := queue.controller.summarizeTranscript(job.CallId, cleanedTranscript); summary != nil {
+				alertSummary = summary.Summary
+				if call != nil && !summary.Fields.IsEmpty() {
+					fields := summary.Fields
+					call.SummaryFields = &fields
+				}
+			}
+		}
+		if call != nil {
+			call.AlertSummary = alertSummary
+		}
+
 		// Store cleaned transcription result (include optional summary from Whisper server when present)
 		cleanedResult := &TranscriptionResult{
 			Transcript:   cleanedTranscript,
 			Confidence:   result.Confidence,
 			Language:     result.Language,
-			AlertSummary: strings.TrimSpace(result.AlertSummary),
+			AlertSummary: alertSummary,
 		}
 		go queue.storeTranscription(job.CallId, cleanedResult)
 
+		// Store an English translation alongside transcripts of non-English talkgroups
+		if call != nil && queue.controller.Options.TranslationConfig.Enabled {
+			if source := translationSource(call.Talkgroup, result.Language); source != "" {
+				go queue.controller.translateTranscript(job.CallId, cleanedTranscript, source)
+			}
+		}
+
 		// Capture the pre-transcription call for the post-transcription goroutine.
 		// Tone detection has almost certainly completed by the time transcription finishes,
 		// so we re-fetch HasTones from DB only once (at the HasTones check below) rather
@@ -377,8 +607,16 @@ func (queue *TranscriptionQueue) worker(workerId int) {
 
 						if attachedPending {
 							go queue.controller.AlertEngine.TriggerToneAlerts(call)
+						} else if call.ToneSequence != nil && call.ToneSequence.StitchedTo > 0 {
+							// Already part of a page that alerted on its first voice call
 						} else if call.HasTones {
 							go queue.controller.AlertEngine.TriggerToneAlerts(call)
+							if call.ToneSequence != nil && len(call.ToneSequence.MatchedToneSets) > 0 && call.System != nil {
+								queue.controller.startToneStitch(fmt.Sprintf("%d:%d", call.System.Id, call.Talkgroup.Id), call)
+							}
+						} else {
+							// Later recordings of a dispatch join its page without alerting again
+							queue.controller.stitchToneCall(call)
 						}
 					}
 				} else {
@@ -449,7 +687,7 @@ func (queue *TranscriptionQueue) worker(workerId int) {
 		}()
 
 		// Process keywords if needed - use cleaned transcript
-		go queue.processKeywords(job.CallId, job.SystemId, job.TalkgroupId, cleanedResult)
+		go queue.processKeywords(job.CallId, job.SystemId, job.TalkgroupId, cleanedResult, spotter.alertedUsers())
 
 		// Auto-learn tone sets (observe patterns, auto-add or log after N voiced calls)
 		go func() {
@@ -465,6 +703,28 @@ func (queue *TranscriptionQueue) worker(workerId int) {
 			}
 		}()
 
+		// Forward the transcript to webhooks that wait for it
+		if postCall != nil {
+			transcribed := *postCall
+			transcribed.Transcript = cleanedTranscript
+			go queue.controller.dispatchWebhooks(&webhookEvent{Name: webhookEventTranscript, Call: &transcribed})
+
+			// Locate the dispatched address for map views
+			if queue.controller.Options.GeocodingConfig.Enabled {
+				address := ""
+				if transcribed.SummaryFields != nil {
+					address = transcribed.SummaryFields.Address
+				}
+				located := transcribed
+				go queue.controller.Geocoding.LocateCall(&located, address)
+			}
+
+			// Group the call with related calls into an incident
+			if queue.controller.Options.IncidentConfig.Enabled && queue.controller.isActualVoice(cleanedTranscript) {
+				go queue.controller.Incidents.Observe(&transcribed)
+			}
+		}
+
 		// Auto-learn unit aliases (radio unitRef → human label)
 		go func() {
 			if postCall != nil {
@@ -486,25 +746,31 @@ func (queue *TranscriptionQueue) worker(workerId int) {
 			workerId, job.CallId, systemLabel, talkgroupLabel,
 			duration.Seconds(), result.Confidence, count,
 		))
+		job.finish(nil)
 	}
 }
 
 // updateCallTranscriptionStatus updates the transcription status for a call
 func (queue *TranscriptionQueue) updateCallTranscriptionStatus(callId uint64, status string, failureReason ...string) {
-	var query string
+	var (
+		query string
+		args  []any
+	)
 	if status == "failed" && len(failureReason) > 0 && failureReason[0] != "" {
 		// Store failure reason when status is failed
-		reason := escapeQuotes(failureReason[0])
+		reason := failureReason[0]
 		// Truncate to reasonable length (500 chars)
 		if len(reason) > 500 {
-			reason = reason[:500]
+			reason = strings.ToValidUTF8(reason[:500], "")
 		}
-		query = fmt.Sprintf(`UPDATE "calls" SET "transcriptionStatus" = '%s', "transcriptionFailureReason" = '%s' WHERE "callId" = %d`, escapeQuotes(status), reason, callId)
+		query = `UPDATE "calls" SET "transcriptionStatus" = $1, "transcriptionFailureReason" = $2, "transcriptionProvider" = $3 WHERE "callId" = $4`
+		args = []any{status, reason, queue.controller.Options.TranscriptionConfig.providerKey(), callId}
 	} else {
 		// Clear failure reason when status is not failed
-		query = fmt.Sprintf(`UPDATE "calls" SET "transcriptionStatus" = '%s', "transcriptionFailureReason" = '' WHERE "callId" = %d`, escapeQuotes(status), callId)
+		query = `UPDATE "calls" SET "transcriptionStatus" = $1, "transcriptionFailureReason" = '' WHERE "callId" = $2`
+		args = []any{status, callId}
 	}
-	if _, err := queue.controller.Database.Sql.Exec(query); err != nil {
+	if _, err := queue.controller.Database.Sql.Exec(query, args...); err != nil {
 		queue.controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("failed to update transcription status for call %d: %v", callId, err))
 	}
 }
@@ -518,8 +784,12 @@ func (queue *TranscriptionQueue) storeTranscription(callId uint64, result *Trans
 	// Update call table (and optional alert summary when provided by Whisper server)
 	transcript := strings.ToUpper(result.Transcript) // Ensure ALL CAPS
 	if queue.controller.Database.Config.DbType == DbTypePostgresql {
-		query := `UPDATE "calls" SET "transcript" = $1, "transcriptConfidence" = $2, "transcriptionStatus" = 'completed', "alertSummary" = $4 WHERE "callId" = $3`
-		if _, err := queue.controller.Database.Sql.Exec(query, transcript, result.Confidence, callId, result.AlertSummary); err != nil {
+		query := `UPDATE "calls" SET "transcript" = $1, "transcriptConfidence" = $2, "transcriptionStatus" = 'completed', "alertSummary" = $4, "transcriptionProvider" = $5 WHERE "callId" = $3`
+		if queue.controller.outboxEnabled() {
+			if err := queue.storeTranscriptWithOutbox(query, callId, transcript, result); err != nil {
+				queue.controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("failed to update call transcript: %v", err))
+			}
+		} else if _, err := queue.controller.Database.Sql.Exec(query, transcript, result.Confidence, callId, result.AlertSummary, queue.controller.Options.TranscriptionConfig.providerKey()); err != nil {
 			queue.controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("failed to update call transcript: %v", err))
 		}
 	}
@@ -531,9 +801,37 @@ func (queue *TranscriptionQueue) storeTranscription(callId uint64, result *Trans
 	}
 }
 
+// storeTranscriptWithOutbox updates the call and publishes call.transcribed
+// in one transaction.
+func (queue *TranscriptionQueue) storeTranscriptWithOutbox(query string, callId uint64, transcript string, result *TranscriptionResult) error {
+	tx, err := queue.controller.Database.Sql.Begin()
+	if err != nil {
+		return err
+	}
+
+	if _, err = tx.Exec(query, transcript, result.Confidence, callId, result.AlertSummary, queue.controller.Options.TranscriptionConfig.providerKey()); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	event := newOutboxEvent("call", strconv.FormatUint(callId, 10), "call.transcribed", map[string]any{
+		"callId":     callId,
+		"transcript": transcript,
+		"confidence": result.Confidence,
+		"language":   result.Language,
+	})
+	if err = writeOutboxEvents(tx, event); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
 // processKeywords processes keywords after transcription completes
 // OPTIMIZED: Loads users once, caches keyword lists, runs matching once per unique keyword set
-func (queue *TranscriptionQueue) processKeywords(callId uint64, systemId uint64, talkgroupId uint64, result *TranscriptionResult) {
+// Users in realtimeAlerted were already pushed from a partial transcript.
+func (queue *TranscriptionQueue) processKeywords(callId uint64, systemId uint64, talkgroupId uint64, result *TranscriptionResult, realtimeAlerted map[uint64]bool) {
 	if result == nil || result.Transcript == "" {
 		queue.controller.Logs.LogEvent(LogLevelInfo, fmt.Sprintf("keyword processing skipped for call %d: no transcript", callId))
 		return
@@ -675,11 +973,12 @@ func (queue *TranscriptionQueue) processKeywords(callId uint64, systemId uint64,
 				// Check if pending tones are still valid (within time window)
 				now := time.Now().UnixMilli()
 				ageMinutes := float64(now-pending.Timestamp) / (1000.0 * 60.0)
-				if ageMinutes <= float64(pendingToneTimeoutMinutes) {
+				timeout := queue.controller.Options.ToneStitchingConfig.pendingTimeout()
+				if now-pending.Timestamp <= timeout {
 					hasPendingTones = true
 					queue.controller.Logs.LogEvent(LogLevelInfo, fmt.Sprintf("found pending tones for key %s (age: %.2f minutes) when processing keywords for call %d", key, ageMinutes, callId))
 				} else {
-					queue.controller.Logs.LogEvent(LogLevelInfo, fmt.Sprintf("pending tones for key %s expired (age: %.2f minutes > %d minutes)", key, ageMinutes, pendingToneTimeoutMinutes))
+					queue.controller.Logs.LogEvent(LogLevelInfo, fmt.Sprintf("pending tones for key %s expired (age: %.2f minutes > %.1f minutes)", key, ageMinutes, float64(timeout)/60000))
 				}
 			} else {
 				queue.controller.Logs.LogEvent(LogLevelInfo, fmt.Sprintf("no pending tones found for key %s when processing keywords for call %d", key, callId))
@@ -721,6 +1020,11 @@ func (queue *TranscriptionQueue) processKeywords(callId uint64, systemId uint64,
 					}
 				}
 
+				if shouldSendKeywordAlert && realtimeAlerted[userId] {
+					shouldSendKeywordAlert = false
+					queue.controller.Logs.LogEvent(LogLevelInfo, fmt.Sprintf("skipping keyword push notification for user %d on call %d (already alerted from the partial transcript)", userId, callId))
+				}
+
 				// Collect user for batched push notification (only if not skipping)
 				if shouldSendKeywordAlert {
 					eligibleUserIds = append(eligibleUserIds, userId)
@@ -777,18 +1081,12 @@ func (queue *TranscriptionQueue) getKeywordsFromList(listId uint64) []string {
 
 // storeKeywordMatch stores a keyword match in the database
 func (queue *TranscriptionQueue) storeKeywordMatch(match *KeywordMatch) {
-	query := fmt.Sprintf(`INSERT INTO "keywordMatches" ("callId", "userId", "keyword", "context", "position", "alerted") VALUES (%d, %d, $1, $2, %d, false)`, match.CallId, match.UserId, match.Position)
-	if queue.controller.Database.Config.DbType == DbTypePostgresql {
-		_, err := queue.controller.Database.Sql.Exec(query, match.Keyword, match.Context)
-		if err != nil {
-			queue.controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("failed to store keyword match: %v", err))
-		}
-	} else {
-		query = fmt.Sprintf(`INSERT INTO "keywordMatches" ("callId", "userId", "keyword", "context", "position", "alerted") VALUES (%d, %d, ?, ?, %d, false)`, match.CallId, match.UserId, match.Position)
-		_, err := queue.controller.Database.Sql.Exec(query, match.Keyword, match.Context)
-		if err != nil {
-			queue.controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("failed to store keyword match: %v", err))
-		}
+	query := `INSERT INTO "keywordMatches" ("callId", "userId", "keyword", "context", "position", "alerted") VALUES ($1, $2, $3, $4, $5, false)`
+	if queue.controller.Database.Config.DbType != DbTypePostgresql {
+		query = `INSERT INTO "keywordMatches" ("callId", "userId", "keyword", "context", "position", "alerted") VALUES (?, ?, ?, ?, ?, false)`
+	}
+	if _, err := queue.controller.Database.Sql.Exec(query, match.CallId, match.UserId, match.Keyword, match.Context, match.Position); err != nil {
+		queue.controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("failed to store keyword match: %v", err))
 	}
 }
 
@@ -833,7 +1131,7 @@ func (queue *TranscriptionQueue) storeKeywordMatchesBatch(callId uint64, userIds
 
 // QueueDepth returns the number of jobs currently waiting in the queue channel
 func (queue *TranscriptionQueue) QueueDepth() int {
-	return len(queue.jobs)
+	return len(queue.jobs) + len(queue.priorityJobs)
 }
 
 // Stop stops the transcription queue
@@ -843,4 +1141,5 @@ func (queue *TranscriptionQueue) Stop() {
 
 	queue.running = false
 	close(queue.jobs)
+	close(queue.priorityJobs)
 }
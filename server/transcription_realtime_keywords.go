@@ -0,0 +1,176 @@
+// Copyright (C) 2026 Thinline Dynamic Solutions
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>
+
+package main
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// realtimeKeywordSpotter matches the configured critical keywords against
+// partial transcripts, so users following "MAYDAY" or "SHOTS FIRED" are
+// paged while the provider is still working on the rest of the call.
+//
+// Systems with the life-safety phrase pack enabled are checked the same way.
+//
+// Only the push notification is sent early. The alert record and keyword
+// matches are stored by processKeywords once the full transcript is in, and
+// users alerted here are not pushed a second time.
+type realtimeKeywordSpotter struct {
+	queue     *TranscriptionQueue
+	call      *Call
+	startedAt time.Time
+	users     map[uint64][]string // userId -> critical keywords the user follows
+	language  string
+	mutex     sync.Mutex
+	alerted   map[uint64]bool
+}
+
+// realtimeKeywordsFor returns the keywords that are also in critical.
+func realtimeKeywordsFor(keywords []string, critical map[string]bool) []string {
+	var found []string
+	for _, keyword := range keywords {
+		if critical[strings.ToUpper(strings.TrimSpace(keyword))] {
+			found = append(found, keyword)
+		}
+	}
+	return found
+}
+
+// newRealtimeKeywordSpotter returns nil when nobody listening to the call's
+// talkgroup follows a critical keyword and the life-safety pack is off.
+func (queue *TranscriptionQueue) newRealtimeKeywordSpotter(call *Call) *realtimeKeywordSpotter {
+	if call == nil || call.System == nil || call.Talkgroup == nil {
+		return nil
+	}
+
+	spotter := &realtimeKeywordSpotter{
+		queue:     queue,
+		call:      call,
+		startedAt: time.Now(),
+		users:     map[uint64][]string{},
+		language:  queue.controller.Options.TranscriptionConfig.Language,
+		alerted:   map[uint64]bool{},
+	}
+
+	lifeSafety := call.System.LifeSafetyPhrases
+	if call.Talkgroup.AlertingTalkgroup {
+		if lifeSafety {
+			return spotter
+		}
+		return nil
+	}
+
+	critical := map[string]bool{}
+	for _, keyword := range queue.controller.Options.TranscriptionConfig.RealtimeKeywords {
+		if keyword = strings.ToUpper(strings.TrimSpace(keyword)); keyword != "" {
+			critical[keyword] = true
+		}
+	}
+	if len(critical) == 0 {
+		if lifeSafety {
+			return spotter
+		}
+		return nil
+	}
+
+	users := spotter.users
+	for _, userId := range queue.controller.PreferencesCache.GetUsersForTalkgroup(call.System.Id, call.Talkgroup.Id) {
+		pref := queue.controller.PreferencesCache.GetPreference(userId, call.System.Id, call.Talkgroup.Id)
+		if pref == nil || !pref.AlertEnabled || !pref.KeywordAlerts {
+			continue
+		}
+
+		keywords := append([]string{}, pref.Keywords...)
+		for _, listId := range pref.KeywordListIds {
+			keywords = append(keywords, queue.getKeywordsFromList(listId)...)
+		}
+
+		if found := realtimeKeywordsFor(keywords, critical); len(found) > 0 {
+			users[userId] = found
+		}
+	}
+	if len(users) == 0 && !lifeSafety {
+		return nil
+	}
+
+	return spotter
+}
+
+// partial is called by the provider each time the transcript grows.
+func (spotter *realtimeKeywordSpotter) partial(text string) {
+	if spotter.call.System.LifeSafetyPhrases && spotter.queue.controller.AlertEngine != nil {
+		spotter.queue.controller.AlertEngine.TriggerLifeSafetyAlerts(spotter.call, text, spotter.language)
+	}
+
+	spotter.mutex.Lock()
+	var userIds []uint64
+	var keywordsMatched []string
+	seen := map[string]bool{}
+	for userId, keywords := range spotter.users {
+		if spotter.alerted[userId] {
+			continue
+		}
+		matches := spotter.queue.controller.KeywordMatcher.MatchKeywords(text, keywords)
+		if len(matches) == 0 {
+			continue
+		}
+		spotter.alerted[userId] = true
+		userIds = append(userIds, userId)
+		for _, match := range matches {
+			if !seen[match.Keyword] {
+				seen[match.Keyword] = true
+				keywordsMatched = append(keywordsMatched, match.Keyword)
+			}
+		}
+	}
+	spotter.mutex.Unlock()
+
+	if len(userIds) == 0 {
+		return
+	}
+
+	// The notification shows what has been heard so far
+	call := *spotter.call
+	call.Transcript = text
+
+	// Critical keywords are not held back by the talkgroup alert cooldown, and
+	// do not start it either, so the regular alert for other users still goes out.
+	go spotter.queue.controller.sendBatchedPushNotification(userIds, "keyword", &call, call.System.Label, call.Talkgroup.Label, "", keywordsMatched)
+
+	spotter.queue.controller.Logs.LogEvent(LogLevelInfo, fmt.Sprintf(
+		"realtime keyword alert for call %d on talkgroup %d: %s sent to %d user(s) %.1fs into transcription",
+		call.Id, call.Talkgroup.TalkgroupRef, strings.Join(keywordsMatched, ", "), len(userIds), time.Since(spotter.startedAt).Seconds(),
+	))
+}
+
+// alertedUsers returns the users already pushed from a partial transcript.
+func (spotter *realtimeKeywordSpotter) alertedUsers() map[uint64]bool {
+	if spotter == nil {
+		return nil
+	}
+
+	spotter.mutex.Lock()
+	defer spotter.mutex.Unlock()
+
+	alerted := make(map[uint64]bool, len(spotter.alerted))
+	for userId := range spotter.alerted {
+		alerted[userId] = true
+	}
+	return alerted
+}
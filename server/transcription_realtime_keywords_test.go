@@ -0,0 +1,16 @@
+package main
+
+import "testing"
+
+func TestRealtimeKeywordsFor(t *testing.T) {
+	critical := map[string]bool{"MAYDAY": true, "SHOTS FIRED": true}
+
+	found := realtimeKeywordsFor([]string{"structure fire", " Shots Fired ", "mayday", "MVA"}, critical)
+	if len(found) != 2 || found[0] != " Shots Fired " || found[1] != "mayday" {
+		t.Fatalf("found = %q", found)
+	}
+
+	if found := realtimeKeywordsFor([]string{"structure fire"}, critical); len(found) != 0 {
+		t.Fatalf("found = %q", found)
+	}
+}
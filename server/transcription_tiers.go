@@ -0,0 +1,52 @@
+// Copyright (C) 2026 Thinline Dynamic Solutions
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>
+
+package main
+
+// Talkgroup transcription tiers. Calls of "off" talkgroups are never
+// transcribed, so they use no provider quota; calls of "priority" talkgroups
+// skip ahead of every standard call waiting in the transcription queue.
+const (
+	TranscriptionTierOff      = "off"
+	TranscriptionTierStandard = "standard"
+	TranscriptionTierPriority = "priority"
+
+	transcriptionPriorityStandard = 50
+	transcriptionPriorityTier     = 100
+)
+
+func validTranscriptionTier(tier string) bool {
+	switch tier {
+	case "", TranscriptionTierOff, TranscriptionTierStandard, TranscriptionTierPriority:
+		return true
+	}
+	return false
+}
+
+// transcriptionTierOf returns the tier of a talkgroup; empty means standard.
+func transcriptionTierOf(talkgroup *Talkgroup) string {
+	if talkgroup == nil || talkgroup.TranscriptionTier == "" {
+		return TranscriptionTierStandard
+	}
+	return talkgroup.TranscriptionTier
+}
+
+// transcriptionPriority returns the queue priority of a talkgroup's calls.
+func transcriptionPriority(talkgroup *Talkgroup) int {
+	if transcriptionTierOf(talkgroup) == TranscriptionTierPriority {
+		return transcriptionPriorityTier
+	}
+	return transcriptionPriorityStandard
+}
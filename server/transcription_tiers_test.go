@@ -0,0 +1,47 @@
+package main
+
+import "testing"
+
+func TestTranscriptionTiers(t *testing.T) {
+	for tier, want := range map[string]int{"": 50, "standard": 50, "priority": 100, "off": 50} {
+		if got := transcriptionPriority(&Talkgroup{TranscriptionTier: tier}); got != want {
+			t.Fatalf("tier %q: expected priority %d, got %d", tier, want, got)
+		}
+	}
+	if transcriptionTierOf(nil) != TranscriptionTierStandard {
+		t.Fatalf("expected a missing talkgroup to be standard")
+	}
+	if validTranscriptionTier("urgent") || !validTranscriptionTier("off") {
+		t.Fatalf("unexpected tier validation")
+	}
+
+	talkgroup := NewTalkgroup()
+	talkgroup.FromMap(map[string]any{"transcriptionTier": "urgent"})
+	if talkgroup.TranscriptionTier != "" {
+		t.Fatalf("expected an invalid tier to be ignored, got %q", talkgroup.TranscriptionTier)
+	}
+	talkgroup.FromMap(map[string]any{"transcriptionTier": "priority"})
+	if talkgroup.TranscriptionTier != TranscriptionTierPriority {
+		t.Fatalf("expected the priority tier, got %q", talkgroup.TranscriptionTier)
+	}
+}
+
+func TestTranscriptionQueueTakesPriorityJobsFirst(t *testing.T) {
+	queue := &TranscriptionQueue{
+		jobs:         make(chan TranscriptionJob, 10),
+		priorityJobs: make(chan TranscriptionJob, 10),
+	}
+	queue.jobs <- TranscriptionJob{CallId: 1}
+	queue.jobs <- TranscriptionJob{CallId: 2}
+	queue.priorityJobs <- TranscriptionJob{CallId: 3, Priority: transcriptionPriorityTier}
+
+	for _, want := range []uint64{3, 1, 2} {
+		job, ok := queue.nextJob()
+		if !ok || job.CallId != want {
+			t.Fatalf("expected call %d, got %d", want, job.CallId)
+		}
+	}
+	if queue.QueueDepth() != 0 {
+		t.Fatalf("expected an empty queue")
+	}
+}
@@ -0,0 +1,424 @@
+// Copyright (C) 2026 Thinline Dynamic Solutions
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT EVEN THE IMPLIED WARRANTY OF MERCHANTABILITY or FITNESS
+// FOR A PARTICULAR PURPOSE.  See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	WhisperLocalEngineCpp    = "whisper.cpp"
+	WhisperLocalEngineFaster = "faster-whisper"
+
+	whisperLocalHealthInterval = time.Minute
+)
+
+// WhisperLocalTranscription implements TranscriptionProvider by running a local
+// whisper.cpp (whisper-cli) or faster-whisper (whisper-ctranslate2) binary, so
+// transcription works without any network access.
+type WhisperLocalTranscription struct {
+	engine  string
+	binary  string
+	model   string
+	gpu     bool
+	threads int
+	timeout time.Duration
+	slots   chan struct{}
+
+	mutex     sync.Mutex
+	checkedAt time.Time
+	checkErr  error
+}
+
+// WhisperLocalConfig contains configuration for local Whisper transcription
+type WhisperLocalConfig struct {
+	Engine         string // "whisper.cpp" (default) or "faster-whisper"
+	Binary         string // executable name or path; defaults to whisper-cli / whisper-ctranslate2
+	Model          string // ggml model file for whisper.cpp, model name or directory for faster-whisper
+	GPU            bool
+	Threads        int // CPU threads per process; 0 = half the cores
+	Concurrency    int // processes allowed at once; 0 = 1
+	TimeoutSeconds int
+}
+
+// NewWhisperLocalTranscription creates a new local Whisper transcription provider
+func NewWhisperLocalTranscription(config *WhisperLocalConfig) *WhisperLocalTranscription {
+	const defaultTimeoutSeconds = 300
+
+	local := &WhisperLocalTranscription{
+		engine:  config.Engine,
+		binary:  config.Binary,
+		model:   config.Model,
+		gpu:     config.GPU,
+		threads: config.Threads,
+		timeout: time.Duration(defaultTimeoutSeconds) * time.Second,
+	}
+
+	if local.engine != WhisperLocalEngineFaster {
+		local.engine = WhisperLocalEngineCpp
+	}
+	if local.binary == "" {
+		if local.engine == WhisperLocalEngineFaster {
+			local.binary = "whisper-ctranslate2"
+		} else {
+			local.binary = "whisper-cli"
+		}
+	}
+	if local.model == "" && local.engine == WhisperLocalEngineFaster {
+		local.model = "small"
+	}
+	if local.threads <= 0 {
+		local.threads = max(1, runtime.NumCPU()/2)
+	}
+	if config.TimeoutSeconds > 0 {
+		local.timeout = time.Duration(config.TimeoutSeconds) * time.Second
+	}
+
+	concurrency := config.Concurrency
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+	local.slots = make(chan struct{}, concurrency)
+
+	return local
+}
+
+// Check verifies the binary and model are usable. The result is cached for a
+// minute so IsAvailable stays cheap.
+func (local *WhisperLocalTranscription) Check() error {
+	local.mutex.Lock()
+	defer local.mutex.Unlock()
+
+	if !local.checkedAt.IsZero() && time.Since(local.checkedAt) < whisperLocalHealthInterval {
+		return local.checkErr
+	}
+
+	local.checkErr = local.check()
+	local.checkedAt = time.Now()
+
+	return local.checkErr
+}
+
+func (local *WhisperLocalTranscription) check() error {
+	if _, err := exec.LookPath(local.binary); err != nil {
+		return fmt.Errorf("%s binary not found: %v", local.engine, err)
+	}
+
+	if local.engine == WhisperLocalEngineCpp {
+		if local.model == "" {
+			return errors.New("no whisper.cpp model file configured")
+		}
+		if _, err := os.Stat(local.model); err != nil {
+			return fmt.Errorf("whisper.cpp model: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// Transcribe transcribes audio with the local binary
+func (local *WhisperLocalTranscription) Transcribe(audio []byte, options TranscriptionOptions) (*TranscriptionResult, error) {
+	return local.TranscribeStream(audio, options, nil)
+}
+
+// TranscribeStream transcribes audio and calls partial with the text decoded
+// so far each time the binary prints a new segment.
+func (local *WhisperLocalTranscription) TranscribeStream(audio []byte, options TranscriptionOptions, partial func(text string)) (*TranscriptionResult, error) {
+	if err := local.Check(); err != nil {
+		return nil, err
+	}
+
+	local.slots <- struct{}{}
+	defer func() { <-local.slots }()
+
+	wav, err := convertToWAV(audio)
+	if err != nil {
+		return nil, err
+	}
+
+	dir, err := os.MkdirTemp("", "tlr-whisper-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	input := filepath.Join(dir, "call.wav")
+	if err := os.WriteFile(input, wav, 0600); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), local.timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, local.binary, local.args(input, dir, options)...)
+	cmd.Dir = dir
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("%s failed to start: %v", local.engine, err)
+	}
+
+	// Both engines print each segment as soon as it is decoded
+	var texts []string
+	scanner := bufio.NewScanner(stdout)
+	for scanner.Scan() {
+		text, ok := parseWhisperProgressLine(scanner.Text())
+		if !ok || text == "" {
+			continue
+		}
+		texts = append(texts, text)
+		if partial != nil {
+			partial(strings.ToUpper(strings.Join(texts, " ")))
+		}
+	}
+
+	if err := cmd.Wait(); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, fmt.Errorf("%s timed out after %s", local.engine, local.timeout)
+		}
+		return nil, fmt.Errorf("%s failed: %v, stderr: %s", local.engine, err, lastLines(stderr.String(), 5))
+	}
+
+	output, err := os.ReadFile(filepath.Join(dir, "call.json"))
+	if err != nil {
+		return nil, fmt.Errorf("%s produced no output: %v", local.engine, err)
+	}
+
+	if local.engine == WhisperLocalEngineFaster {
+		return parseFasterWhisperOutput(output, options.Language)
+	}
+	return parseWhisperCppOutput(output, options.Language)
+}
+
+// args builds the command line; both engines write call.json into dir.
+func (local *WhisperLocalTranscription) args(input string, dir string, options TranscriptionOptions) []string {
+	language := options.Language
+	if language == "" {
+		language = "auto"
+	}
+
+	if local.engine == WhisperLocalEngineFaster {
+		device := "cpu"
+		if local.gpu {
+			device = "cuda"
+		}
+		args := []string{
+			input,
+			"--model", local.model,
+			"--device", device,
+			"--threads", strconv.Itoa(local.threads),
+			"--output_format", "json",
+			"--output_dir", dir,
+			"--verbose", "True",
+		}
+		if language != "auto" {
+			args = append(args, "--language", language)
+		}
+		if options.InitialPrompt != "" {
+			args = append(args, "--initial_prompt", options.InitialPrompt)
+		}
+		return args
+	}
+
+	args := []string{
+		"-m", local.model,
+		"-f", input,
+		"-l", language,
+		"-t", strconv.Itoa(local.threads),
+		"-of", filepath.Join(dir, "call"),
+		"-oj",
+		"-np",
+	}
+	if !local.gpu {
+		args = append(args, "-ng")
+	}
+	if options.InitialPrompt != "" {
+		args = append(args, "--prompt", options.InitialPrompt)
+	}
+	return args
+}
+
+// whisperProgressLine matches the segment lines printed on stdout, e.g.
+// "[00:00:00.000 --> 00:00:02.140]   Engine 5 respond" (whisper.cpp) or
+// "[00:00.000 --> 00:02.140] Engine 5 respond" (faster-whisper).
+var whisperProgressLine = regexp.MustCompile(`^\[[0-9:.]+ --> [0-9:.]+\]\s*(.*)$`)
+
+func parseWhisperProgressLine(line string) (string, bool) {
+	m := whisperProgressLine.FindStringSubmatch(strings.TrimSpace(line))
+	if m == nil {
+		return "", false
+	}
+	return strings.TrimSpace(m[1]), true
+}
+
+// parseWhisperCppOutput reads the -oj output of whisper-cli.
+func parseWhisperCppOutput(b []byte, language string) (*TranscriptionResult, error) {
+	var output struct {
+		Result struct {
+			Language string `json:"language"`
+		} `json:"result"`
+		Transcription []struct {
+			Offsets struct {
+				From float64 `json:"from"`
+				To   float64 `json:"to"`
+			} `json:"offsets"`
+			Text   string `json:"text"`
+			Tokens []struct {
+				P float64 `json:"p"`
+			} `json:"tokens"`
+		} `json:"transcription"`
+	}
+	if err := json.Unmarshal(b, &output); err != nil {
+		return nil, fmt.Errorf("failed to parse whisper.cpp output: %v", err)
+	}
+
+	result := &TranscriptionResult{Language: language, Confidence: 0.95}
+	if output.Result.Language != "" {
+		result.Language = output.Result.Language
+	}
+
+	var texts []string
+	var pSum float64
+	var pCount int
+	for _, s := range output.Transcription {
+		text := strings.ToUpper(strings.TrimSpace(s.Text))
+		if text == "" {
+			continue
+		}
+		texts = append(texts, text)
+
+		confidence := 0.95
+		if len(s.Tokens) > 0 {
+			var sum float64
+			for _, t := range s.Tokens {
+				sum += t.P
+			}
+			confidence = sum / float64(len(s.Tokens))
+			pSum += sum
+			pCount += len(s.Tokens)
+		}
+
+		result.Segments = append(result.Segments, TranscriptSegment{
+			Text:       text,
+			StartTime:  s.Offsets.From / 1000,
+			EndTime:    s.Offsets.To / 1000,
+			Confidence: confidence,
+		})
+	}
+	if pCount > 0 {
+		result.Confidence = pSum / float64(pCount)
+	}
+
+	result.Transcript = strings.Join(texts, " ")
+
+	return result, nil
+}
+
+// parseFasterWhisperOutput reads the JSON written by whisper-ctranslate2.
+func parseFasterWhisperOutput(b []byte, language string) (*TranscriptionResult, error) {
+	var output struct {
+		Text     string `json:"text"`
+		Language string `json:"language"`
+		Segments []struct {
+			Start      float64 `json:"start"`
+			End        float64 `json:"end"`
+			Text       string  `json:"text"`
+			AvgLogprob float64 `json:"avg_logprob"`
+		} `json:"segments"`
+	}
+	if err := json.Unmarshal(b, &output); err != nil {
+		return nil, fmt.Errorf("failed to parse faster-whisper output: %v", err)
+	}
+
+	result := &TranscriptionResult{
+		Transcript: strings.ToUpper(strings.TrimSpace(output.Text)),
+		Language:   language,
+		Confidence: 0.95,
+	}
+	if output.Language != "" {
+		result.Language = output.Language
+	}
+
+	var sum float64
+	for _, s := range output.Segments {
+		confidence := math.Exp(s.AvgLogprob)
+		sum += confidence
+		result.Segments = append(result.Segments, TranscriptSegment{
+			Text:       strings.ToUpper(strings.TrimSpace(s.Text)),
+			StartTime:  s.Start,
+			EndTime:    s.End,
+			Confidence: confidence,
+		})
+	}
+	if len(output.Segments) > 0 {
+		result.Confidence = sum / float64(len(output.Segments))
+	}
+
+	return result, nil
+}
+
+// lastLines keeps error output short enough for the logs.
+func lastLines(s string, n int) string {
+	lines := strings.Split(strings.TrimSpace(s), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// IsAvailable reports whether the binary and model were found
+func (local *WhisperLocalTranscription) IsAvailable() bool {
+	return local.Check() == nil
+}
+
+// GetName returns the name of this transcription provider
+func (local *WhisperLocalTranscription) GetName() string {
+	device := "CPU"
+	if local.gpu {
+		device = "GPU"
+	}
+	return fmt.Sprintf("Local %s (%s, %s)", local.engine, filepath.Base(local.model), device)
+}
+
+// GetSupportedLanguages returns supported languages
+func (local *WhisperLocalTranscription) GetSupportedLanguages() []string {
+	return []string{
+		"auto", "en", "es", "fr", "de", "it", "pt", "ru", "ja", "ko", "zh",
+		"nl", "tr", "pl", "ca", "fa", "ar", "cs", "el", "fi", "he", "hi",
+		"hu", "id", "ms", "no", "ro", "sk", "sv", "uk", "vi",
+	}
+}
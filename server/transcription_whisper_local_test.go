@@ -0,0 +1,71 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseWhisperCppOutput(t *testing.T) {
+	output := `{"result":{"language":"en"},"transcription":[
+		{"offsets":{"from":0,"to":1500},"text":" Engine 5 respond","tokens":[{"p":0.9},{"p":0.7}]},
+		{"offsets":{"from":1500,"to":3000},"text":" to Main Street.","tokens":[{"p":0.8},{"p":0.8}]}]}`
+
+	result, err := parseWhisperCppOutput([]byte(output), "auto")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if result.Transcript != "ENGINE 5 RESPOND TO MAIN STREET." || result.Language != "en" {
+		t.Fatalf("result = %+v", result)
+	}
+	if len(result.Segments) != 2 || result.Segments[1].StartTime != 1.5 || result.Confidence < 0.79 || result.Confidence > 0.81 {
+		t.Fatalf("segments = %+v, confidence = %v", result.Segments, result.Confidence)
+	}
+}
+
+func TestParseFasterWhisperOutput(t *testing.T) {
+	output := `{"text":" Medic 2 en route","language":"en","segments":[{"start":0,"end":2.1,"text":" Medic 2 en route","avg_logprob":0}]}`
+
+	result, err := parseFasterWhisperOutput([]byte(output), "en")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if result.Transcript != "MEDIC 2 EN ROUTE" || result.Confidence != 1 || result.Segments[0].EndTime != 2.1 {
+		t.Fatalf("result = %+v", result)
+	}
+}
+
+func TestWhisperLocalArgsAndCheck(t *testing.T) {
+	local := NewWhisperLocalTranscription(&WhisperLocalConfig{Binary: "tlr-no-such-binary", Model: "/models/ggml-base.en.bin", Threads: 4})
+
+	args := strings.Join(local.args("/tmp/x/call.wav", "/tmp/x", TranscriptionOptions{Language: "en", InitialPrompt: "Engine"}), " ")
+	for _, want := range []string{"-m /models/ggml-base.en.bin", "-l en", "-t 4", "-of /tmp/x/call", "-oj", "-ng", "--prompt Engine"} {
+		if !strings.Contains(args, want) {
+			t.Fatalf("args %q missing %q", args, want)
+		}
+	}
+
+	if local.IsAvailable() {
+		t.Fatalf("missing binary reported as available")
+	}
+
+	faster := NewWhisperLocalTranscription(&WhisperLocalConfig{Engine: WhisperLocalEngineFaster, GPU: true})
+	args = strings.Join(faster.args("/tmp/x/call.wav", "/tmp/x", TranscriptionOptions{Language: "auto"}), " ")
+	if !strings.Contains(args, "--model small") || !strings.Contains(args, "--device cuda") || strings.Contains(args, "--language") {
+		t.Fatalf("faster-whisper args = %q", args)
+	}
+}
+
+func TestParseWhisperProgressLine(t *testing.T) {
+	for line, want := range map[string]string{
+		"[00:00:00.000 --> 00:00:02.140]   Mayday mayday": "Mayday mayday",
+		"[00:00.000 --> 00:02.140] Shots fired":           "Shots fired",
+	} {
+		if got, ok := parseWhisperProgressLine(line); !ok || got != want {
+			t.Fatalf("parseWhisperProgressLine(%q) = %q, %v", line, got, ok)
+		}
+	}
+
+	if _, ok := parseWhisperProgressLine("whisper_init_from_file: loading model"); ok {
+		t.Fatalf("log line parsed as a segment")
+	}
+}
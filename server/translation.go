@@ -0,0 +1,259 @@
+// Copyright (C) 2026 Thinline Dynamic Solutions
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	deepLFreeURL             = "https://api-free.deepl.com/v2/translate"
+	deepLProURL              = "https://api.deepl.com/v2/translate"
+	libreTranslateDefaultURL = "http://localhost:5000"
+)
+
+// TranslationProvider translates a transcript from source ("es", "fr"...) to English.
+type TranslationProvider interface {
+	Translate(text string, source string) (string, error)
+	GetName() string
+}
+
+var translationHTTPClient = &http.Client{Timeout: 60 * time.Second}
+
+// translationBaseLanguage returns the lower case language of a code such as "es-MX".
+func translationBaseLanguage(language string) string {
+	language = strings.ToLower(strings.TrimSpace(language))
+	if i := strings.IndexAny(language, "-_"); i > 0 {
+		language = language[:i]
+	}
+	return language
+}
+
+// translationSource returns the language a transcript of talkgroup is
+// translated from, or "" when it is not translated. Transcripts the provider
+// detected as English are left alone, since non-English talkgroups often carry
+// some English traffic.
+func translationSource(talkgroup *Talkgroup, detected string) string {
+	if talkgroup == nil {
+		return ""
+	}
+	source := translationBaseLanguage(talkgroup.TranscriptLanguage)
+	if source == "" || source == "en" || source == "auto" {
+		return ""
+	}
+	if translationBaseLanguage(detected) == "en" {
+		return ""
+	}
+	return source
+}
+
+// newTranslationProvider returns the provider selected in config.
+func (controller *Controller) newTranslationProvider(config TranslationConfig) (TranslationProvider, error) {
+	switch strings.ToLower(config.Provider) {
+	case "", "openai":
+		if strings.TrimSpace(controller.Options.OpenAIIntegration.APIKey) == "" {
+			return nil, fmt.Errorf("openai api key not configured")
+		}
+		return &openAITranslation{controller: controller}, nil
+	case "deepl":
+		if config.APIKey == "" {
+			return nil, fmt.Errorf("deepl api key not configured")
+		}
+		url := config.URL
+		if url == "" {
+			// Free plan keys end with ":fx"
+			if strings.HasSuffix(config.APIKey, ":fx") {
+				url = deepLFreeURL
+			} else {
+				url = deepLProURL
+			}
+		}
+		return &deepLTranslation{apiKey: config.APIKey, url: url}, nil
+	case "libretranslate":
+		url := strings.TrimRight(config.URL, "/")
+		if url == "" {
+			url = libreTranslateDefaultURL
+		}
+		return &libreTranslation{apiKey: config.APIKey, url: url}, nil
+	default:
+		return nil, fmt.Errorf("unknown translation provider %q", config.Provider)
+	}
+}
+
+// translateTranscript stores the English translation of a call transcript.
+func (controller *Controller) translateTranscript(callId uint64, transcript string, source string) {
+	if strings.TrimSpace(transcript) == "" {
+		return
+	}
+
+	provider, err := controller.newTranslationProvider(controller.Options.TranslationConfig)
+	if err != nil {
+		controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("translation skipped for call %d: %v", callId, err))
+		return
+	}
+
+	translation, err := provider.Translate(transcript, source)
+	if err != nil {
+		controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("translation of call %d from %s with %s failed: %v", callId, source, provider.GetName(), err))
+		return
+	}
+
+	// Stored in capitals like the transcript, so both read and search the same way
+	translation = strings.ToUpper(strings.TrimSpace(translation))
+	if _, err := controller.Database.Sql.Exec(`UPDATE "calls" SET "transcriptTranslation" = $1 WHERE "callId" = $2`, translation, callId); err != nil {
+		controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("failed to store translation for call %d: %v", callId, err))
+	}
+}
+
+// openAITranslation translates with the OpenAI integration chat model.
+type openAITranslation struct {
+	controller *Controller
+}
+
+const openAITranslationPrompt = `You translate public safety radio transcripts to English.
+Keep unit numbers, street names, addresses, codes and proper names as they are.
+Do not add, explain or summarize anything.
+Respond with JSON: {"translation": "..."}`
+
+func (translation *openAITranslation) Translate(text string, source string) (string, error) {
+	content, err := translation.controller.openAIChatJSON(openAITranslationPrompt, fmt.Sprintf("Language: %s\nTranscript: %s", source, text))
+	if err != nil {
+		return "", err
+	}
+
+	var response struct {
+		Translation string `json:"translation"`
+	}
+	if err := json.Unmarshal([]byte(content), &response); err != nil {
+		return "", fmt.Errorf("failed to parse response: %v", err)
+	}
+	if strings.TrimSpace(response.Translation) == "" {
+		return "", fmt.Errorf("empty translation")
+	}
+	return response.Translation, nil
+}
+
+func (translation *openAITranslation) GetName() string {
+	return "OpenAI"
+}
+
+// deepLTranslation translates with the DeepL API.
+type deepLTranslation struct {
+	apiKey string
+	url    string
+}
+
+func (translation *deepLTranslation) Translate(text string, source string) (string, error) {
+	body, _ := json.Marshal(map[string]any{
+		"text":        []string{text},
+		"source_lang": strings.ToUpper(source),
+		"target_lang": "EN-US",
+	})
+
+	respBody, err := postTranslation(translation.url, body, map[string]string{"Authorization": "DeepL-Auth-Key " + translation.apiKey})
+	if err != nil {
+		return "", err
+	}
+
+	var response struct {
+		Translations []struct {
+			Text string `json:"text"`
+		} `json:"translations"`
+	}
+	if err := json.Unmarshal(respBody, &response); err != nil {
+		return "", fmt.Errorf("failed to parse response: %v", err)
+	}
+	if len(response.Translations) == 0 {
+		return "", fmt.Errorf("empty translation")
+	}
+	return response.Translations[0].Text, nil
+}
+
+func (translation *deepLTranslation) GetName() string {
+	return "DeepL"
+}
+
+// libreTranslation translates with a LibreTranslate server.
+type libreTranslation struct {
+	apiKey string
+	url    string
+}
+
+func (translation *libreTranslation) Translate(text string, source string) (string, error) {
+	request := map[string]any{
+		"q":      text,
+		"source": source,
+		"target": "en",
+		"format": "text",
+	}
+	if translation.apiKey != "" {
+		request["api_key"] = translation.apiKey
+	}
+	body, _ := json.Marshal(request)
+
+	respBody, err := postTranslation(translation.url+"/translate", body, nil)
+	if err != nil {
+		return "", err
+	}
+
+	var response struct {
+		TranslatedText string `json:"translatedText"`
+	}
+	if err := json.Unmarshal(respBody, &response); err != nil {
+		return "", fmt.Errorf("failed to parse response: %v", err)
+	}
+	if strings.TrimSpace(response.TranslatedText) == "" {
+		return "", fmt.Errorf("empty translation")
+	}
+	return response.TranslatedText, nil
+}
+
+func (translation *libreTranslation) GetName() string {
+	return "LibreTranslate"
+}
+
+// postTranslation posts a JSON request and returns the body of a 200 response.
+func postTranslation(url string, body []byte, headers map[string]string) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := translationHTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(respBody))
+	}
+	return respBody, nil
+}
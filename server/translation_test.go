@@ -0,0 +1,118 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTranslationSource(t *testing.T) {
+	cases := []struct {
+		language string
+		detected string
+		want     string
+	}{
+		{"", "es", ""},
+		{"en", "", ""},
+		{"en-US", "es", ""},
+		{"es", "", "es"},
+		{"es-MX", "es", "es"},
+		{"fr", "fr-CA", "fr"},
+		{"es", "en", ""},
+		{"es", "en-US", ""},
+	}
+	for _, c := range cases {
+		if got := translationSource(&Talkgroup{TranscriptLanguage: c.language}, c.detected); got != c.want {
+			t.Fatalf("translationSource(%q, %q) = %q, want %q", c.language, c.detected, got, c.want)
+		}
+	}
+	if got := translationSource(nil, "es"); got != "" {
+		t.Fatalf("nil talkgroup: got %q", got)
+	}
+}
+
+func TestDeepLTranslation(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "DeepL-Auth-Key secret" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		var body struct {
+			Text       []string `json:"text"`
+			SourceLang string   `json:"source_lang"`
+			TargetLang string   `json:"target_lang"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		if len(body.Text) != 1 || body.SourceLang != "ES" || body.TargetLang != "EN-US" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte(`{"translations":[{"detected_source_language":"ES","text":"Engine 5 en route"}]}`))
+	}))
+	defer server.Close()
+
+	controller := &Controller{Options: NewOptions()}
+	provider, err := controller.newTranslationProvider(TranslationConfig{Provider: "deepl", APIKey: "secret", URL: server.URL})
+	if err != nil {
+		t.Fatalf("newTranslationProvider: %v", err)
+	}
+	got, err := provider.Translate("MAQUINA 5 EN CAMINO", "es")
+	if err != nil {
+		t.Fatalf("Translate: %v", err)
+	}
+	if got != "Engine 5 en route" {
+		t.Fatalf("got %q", got)
+	}
+}
+
+func TestLibreTranslation(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/translate" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		var body map[string]any
+		json.NewDecoder(r.Body).Decode(&body)
+		if body["source"] != "fr" || body["target"] != "en" || body["api_key"] != "key" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte(`{"translatedText":"Shots fired"}`))
+	}))
+	defer server.Close()
+
+	controller := &Controller{Options: NewOptions()}
+	provider, err := controller.newTranslationProvider(TranslationConfig{Provider: "libretranslate", APIKey: "key", URL: server.URL + "/"})
+	if err != nil {
+		t.Fatalf("newTranslationProvider: %v", err)
+	}
+	got, err := provider.Translate("COUPS DE FEU", "fr")
+	if err != nil {
+		t.Fatalf("Translate: %v", err)
+	}
+	if got != "Shots fired" {
+		t.Fatalf("got %q", got)
+	}
+}
+
+func TestNewTranslationProviderErrors(t *testing.T) {
+	controller := &Controller{Options: NewOptions()}
+	if _, err := controller.newTranslationProvider(TranslationConfig{Provider: "openai"}); err == nil {
+		t.Fatalf("expected an error without an OpenAI key")
+	}
+	if _, err := controller.newTranslationProvider(TranslationConfig{Provider: "deepl"}); err == nil {
+		t.Fatalf("expected an error without a DeepL key")
+	}
+	if _, err := controller.newTranslationProvider(TranslationConfig{Provider: "babelfish"}); err == nil {
+		t.Fatalf("expected an error for an unknown provider")
+	}
+
+	provider, err := controller.newTranslationProvider(TranslationConfig{Provider: "deepl", APIKey: "abc:fx"})
+	if err != nil {
+		t.Fatalf("newTranslationProvider: %v", err)
+	}
+	if url := provider.(*deepLTranslation).url; url != deepLFreeURL {
+		t.Fatalf("free key url = %q", url)
+	}
+}
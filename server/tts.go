@@ -0,0 +1,378 @@
+// Copyright (C) 2026 Thinline Dynamic Solutions
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>
+
+package main
+
+import (
+	"bytes"
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	ttsDefaultTemplate  = "{talkgroup}"
+	ttsDefaultCacheSize = 500
+	ttsAnnouncedCalls   = 50
+	ttsUserSetting      = "ttsAnnouncements"
+)
+
+// TTSConfig announces the talkgroup before the audio of calls for users who
+// turned announcements on. The "openai" provider uses the OpenAI integration
+// credentials; "command" runs a local synthesizer such as espeak-ng or piper
+// that reads the text on stdin and writes audio to stdout.
+type TTSConfig struct {
+	Enabled   bool   `json:"enabled"`
+	Provider  string `json:"provider"`  // "openai" (default) or "command"
+	Voice     string `json:"voice"`     // openai voice, or {voice} in the command
+	Model     string `json:"model"`     // openai model (default tts-1)
+	Command   string `json:"command"`   // e.g. "espeak-ng -v {voice} --stdin --stdout"
+	Template  string `json:"template"`  // placeholders {talkgroup}, {talkgroupLabel}, {system}, {unit}
+	CacheSize uint   `json:"cacheSize"` // announcements kept in memory (default 500)
+}
+
+// TTSProvider synthesizes speech. The audio can be in any format ffmpeg reads.
+type TTSProvider interface {
+	Synthesize(text string, voice string) ([]byte, error)
+	GetName() string
+}
+
+// OpenAITTSProvider uses the OpenAI speech API, or a compatible server at the
+// integration's base URL.
+type OpenAITTSProvider struct {
+	APIKey  string
+	BaseURL string
+	Model   string
+}
+
+func (provider *OpenAITTSProvider) GetName() string { return "openai" }
+
+func (provider *OpenAITTSProvider) Synthesize(text string, voice string) ([]byte, error) {
+	if strings.TrimSpace(provider.APIKey) == "" {
+		return nil, errors.New("openai api key not configured")
+	}
+	model := provider.Model
+	if model == "" {
+		model = "tts-1"
+	}
+	if voice == "" {
+		voice = "alloy"
+	}
+
+	body, _ := json.Marshal(map[string]any{
+		"model":           model,
+		"input":           text,
+		"voice":           voice,
+		"response_format": "wav",
+	})
+	req, err := http.NewRequest(http.MethodPost, resolveOpenAIBaseURL(provider.BaseURL)+"/v1/audio/speech", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+provider.APIKey)
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	audio, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("openai status %d: %s", resp.StatusCode, string(audio))
+	}
+	return audio, nil
+}
+
+// CommandTTSProvider runs a local synthesizer. {voice} in the command is
+// replaced by the voice and the text is written to its stdin.
+type CommandTTSProvider struct {
+	Command string
+}
+
+func (provider *CommandTTSProvider) GetName() string { return "command" }
+
+func (provider *CommandTTSProvider) Synthesize(text string, voice string) ([]byte, error) {
+	fields := strings.Fields(provider.Command)
+	if len(fields) == 0 {
+		return nil, errors.New("tts command not configured")
+	}
+	for i, f := range fields {
+		fields[i] = strings.ReplaceAll(f, "{voice}", voice)
+	}
+
+	cmd := exec.Command(fields[0], fields[1:]...)
+	cmd.Stdin = strings.NewReader(text)
+	stdout := bytes.NewBuffer([]byte(nil))
+	cmd.Stdout = stdout
+	stderr := bytes.NewBuffer([]byte(nil))
+	cmd.Stderr = stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("%v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	if stdout.Len() == 0 {
+		return nil, errors.New("tts command wrote no audio")
+	}
+	return stdout.Bytes(), nil
+}
+
+// ttsAnnouncementText fills the template for the call.
+func ttsAnnouncementText(template string, call *Call) string {
+	if strings.TrimSpace(template) == "" {
+		template = ttsDefaultTemplate
+	}
+
+	var system, talkgroup, talkgroupLabel, unit string
+	if call.System != nil {
+		system = call.System.Label
+	}
+	if call.Talkgroup != nil {
+		talkgroupLabel = call.Talkgroup.Label
+		talkgroup = call.Talkgroup.Name
+		if talkgroup == "" {
+			talkgroup = talkgroupLabel
+		}
+	}
+	if len(call.Units) > 0 && call.System != nil {
+		unitRef := call.Units[0].UnitRef
+		if unit = call.System.Units.Alias(unitRef); unit == "" {
+			unit = fmt.Sprintf("%d", unitRef)
+		}
+	}
+
+	text := strings.NewReplacer(
+		"{talkgroup}", talkgroup,
+		"{talkgroupLabel}", talkgroupLabel,
+		"{system}", system,
+		"{unit}", unit,
+	).Replace(template)
+
+	// Placeholders without a value leave stray separators behind
+	text = strings.Join(strings.Fields(text), " ")
+	return strings.Trim(text, " ,.-")
+}
+
+type ttsCacheEntry struct {
+	key   string
+	audio []byte
+}
+
+// ttsCache keeps the most recently used announcements.
+type ttsCache struct {
+	size    int
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+func newTTSCache(size int) *ttsCache {
+	return &ttsCache{size: size, order: list.New(), entries: map[string]*list.Element{}}
+}
+
+func (cache *ttsCache) get(key string) ([]byte, bool) {
+	element, ok := cache.entries[key]
+	if !ok {
+		return nil, false
+	}
+	cache.order.MoveToFront(element)
+	return element.Value.(*ttsCacheEntry).audio, true
+}
+
+func (cache *ttsCache) put(key string, audio []byte) {
+	if element, ok := cache.entries[key]; ok {
+		element.Value.(*ttsCacheEntry).audio = audio
+		cache.order.MoveToFront(element)
+		return
+	}
+	cache.entries[key] = cache.order.PushFront(&ttsCacheEntry{key: key, audio: audio})
+	for cache.order.Len() > cache.size {
+		oldest := cache.order.Back()
+		cache.order.Remove(oldest)
+		delete(cache.entries, oldest.Value.(*ttsCacheEntry).key)
+	}
+}
+
+// TTS prepends spoken announcements to call audio.
+type TTS struct {
+	controller *Controller
+	mutex      sync.Mutex
+	cache      *ttsCache
+	announced  map[uint64]*Call // recent calls with their announcement, shared by users
+	calls      []uint64
+	settings   map[uint64]ttsUserSettings
+}
+
+type ttsUserSettings struct {
+	raw     string
+	enabled bool
+}
+
+func NewTTS(controller *Controller) *TTS {
+	return &TTS{
+		controller: controller,
+		cache:      newTTSCache(ttsDefaultCacheSize),
+		announced:  map[uint64]*Call{},
+		settings:   map[uint64]ttsUserSettings{},
+	}
+}
+
+func (tts *TTS) provider() TTSProvider {
+	config := tts.controller.Options.TTSConfig
+	switch config.Provider {
+	case "command":
+		return &CommandTTSProvider{Command: config.Command}
+	default:
+		oai := tts.controller.Options.OpenAIIntegration
+		return &OpenAITTSProvider{APIKey: oai.APIKey, BaseURL: oai.BaseURL, Model: config.Model}
+	}
+}
+
+// EnabledFor reports whether the user gets announcements: the server has
+// them on and the user turned on the ttsAnnouncements setting.
+func (tts *TTS) EnabledFor(user *User) bool {
+	if tts == nil || user == nil || !tts.controller.Options.TTSConfig.Enabled {
+		return false
+	}
+
+	tts.mutex.Lock()
+	defer tts.mutex.Unlock()
+
+	if cached, ok := tts.settings[user.Id]; ok && cached.raw == user.Settings {
+		return cached.enabled
+	}
+	enabled := false
+	if user.Settings != "" {
+		var settings map[string]any
+		if err := json.Unmarshal([]byte(user.Settings), &settings); err == nil {
+			enabled, _ = settings[ttsUserSetting].(bool)
+		}
+	}
+	tts.settings[user.Id] = ttsUserSettings{raw: user.Settings, enabled: enabled}
+	return enabled
+}
+
+// Announcement returns the synthesized announcement for the call, from the
+// cache when the same text was spoken before.
+func (tts *TTS) Announcement(call *Call) ([]byte, error) {
+	config := tts.controller.Options.TTSConfig
+	text := ttsAnnouncementText(config.Template, call)
+	if text == "" {
+		return nil, errors.New("nothing to announce")
+	}
+
+	provider := tts.provider()
+	sum := sha256.Sum256([]byte(provider.GetName() + "\x00" + config.Voice + "\x00" + text))
+	key := hex.EncodeToString(sum[:])
+
+	tts.mutex.Lock()
+	size := int(config.CacheSize)
+	if size == 0 {
+		size = ttsDefaultCacheSize
+	}
+	tts.cache.size = size
+	audio, ok := tts.cache.get(key)
+	tts.mutex.Unlock()
+	if ok {
+		return audio, nil
+	}
+
+	audio, err := provider.Synthesize(text, config.Voice)
+	if err != nil {
+		return nil, err
+	}
+
+	tts.mutex.Lock()
+	tts.cache.put(key, audio)
+	tts.mutex.Unlock()
+	return audio, nil
+}
+
+// Announced returns a copy of the call with its announcement before the
+// audio. If the announcement fails, the call is returned unchanged.
+func (tts *TTS) Announced(call *Call) *Call {
+	if tts == nil || call == nil || len(call.Audio) == 0 {
+		return call
+	}
+
+	tts.mutex.Lock()
+	if announced, ok := tts.announced[call.Id]; ok && call.Id > 0 {
+		tts.mutex.Unlock()
+		return announced
+	}
+	tts.mutex.Unlock()
+
+	intro, err := tts.Announcement(call)
+	if err != nil {
+		tts.controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("tts: announcement for call %d: %v", call.Id, err))
+		return call
+	}
+	audio, err := tts.controller.FFMpeg.Prepend(intro, call.Audio)
+	if err != nil {
+		tts.controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("tts: announcing call %d: %v", call.Id, err))
+		return call
+	}
+
+	announced := *call
+	announced.Audio = audio
+	announced.AudioMime = "audio/mp4"
+	announced.AudioFilename = fmt.Sprintf("call_%d.m4a", call.Id)
+
+	if call.Id > 0 {
+		tts.mutex.Lock()
+		if _, ok := tts.announced[call.Id]; !ok {
+			tts.announced[call.Id] = &announced
+			tts.calls = append(tts.calls, call.Id)
+			if len(tts.calls) > ttsAnnouncedCalls {
+				delete(tts.announced, tts.calls[0])
+				tts.calls = tts.calls[1:]
+			}
+		}
+		tts.mutex.Unlock()
+	}
+	return &announced
+}
+
+// EmitAnnounced sends the call with its announcement to the clients of users
+// who turned announcements on, after their delay like Clients.EmitCall.
+// Synthesis runs here so it never holds up the other listeners.
+func (tts *TTS) EmitAnnounced(call *Call, clients []*Client) {
+	announced := tts.Announced(call)
+	msg := &Message{Command: MessageCommandCall, Payload: announced}
+
+	for _, c := range clients {
+		if tts.controller.Delayer.CanDelayForClient(call, c) {
+			tts.controller.Delayer.DelayForClient(announced, c)
+			continue
+		}
+		select {
+		case c.Send <- msg:
+		default:
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package main
+
+import "testing"
+
+func TestTTSAnnouncementText(t *testing.T) {
+	units := NewUnits()
+	units.Add(4424, "Engine 14")
+	call := &Call{
+		System:    &System{Label: "County", Units: units},
+		Talkgroup: &Talkgroup{Label: "FD A1", Name: "Station 14 Fire Dispatch"},
+		Units:     []CallUnit{{UnitRef: 4424}},
+	}
+
+	if got := ttsAnnouncementText("", call); got != "Station 14 Fire Dispatch" {
+		t.Fatalf("default template: %q", got)
+	}
+	if got := ttsAnnouncementText("{system} {talkgroupLabel}, {unit}", call); got != "County FD A1, Engine 14" {
+		t.Fatalf("template: %q", got)
+	}
+
+	call.Units = nil
+	if got := ttsAnnouncementText("{talkgroup}, {unit}", call); got != "Station 14 Fire Dispatch" {
+		t.Fatalf("missing unit: %q", got)
+	}
+}
+
+func TestTTSCache(t *testing.T) {
+	cache := newTTSCache(2)
+	cache.put("a", []byte("a"))
+	cache.put("b", []byte("b"))
+	cache.get("a")
+	cache.put("c", []byte("c"))
+
+	if _, ok := cache.get("b"); ok {
+		t.Fatalf("least recently used entry kept")
+	}
+	if _, ok := cache.get("a"); !ok {
+		t.Fatalf("recently used entry evicted")
+	}
+}
+
+func TestTTSEnabledFor(t *testing.T) {
+	controller := &Controller{Options: &Options{TTSConfig: TTSConfig{Enabled: true}}}
+	tts := NewTTS(controller)
+
+	user := &User{Id: 1, Settings: `{"ttsAnnouncements":true}`}
+	if !tts.EnabledFor(user) {
+		t.Fatalf("setting not applied")
+	}
+	user.Settings = `{"ttsAnnouncements":false}`
+	if tts.EnabledFor(user) {
+		t.Fatalf("changed setting not picked up")
+	}
+	if tts.EnabledFor(nil) {
+		t.Fatalf("announcements for a client without a user")
+	}
+
+	user.Settings = `{"ttsAnnouncements":true}`
+	controller.Options.TTSConfig.Enabled = false
+	if tts.EnabledFor(user) {
+		t.Fatalf("announcements while disabled on the server")
+	}
+}
+
+func TestTTSAnnouncementCached(t *testing.T) {
+	controller := &Controller{Options: &Options{TTSConfig: TTSConfig{Enabled: true, Provider: "command", Command: "cat"}}}
+	tts := NewTTS(controller)
+	call := &Call{System: &System{}, Talkgroup: &Talkgroup{Name: "Fire Dispatch"}}
+
+	audio, err := tts.Announcement(call)
+	if err != nil || string(audio) != "Fire Dispatch" {
+		t.Fatalf("announcement %q, %v", audio, err)
+	}
+
+	controller.Options.TTSConfig.Command = "false"
+	if audio, err := tts.Announcement(call); err != nil || string(audio) != "Fire Dispatch" {
+		t.Fatalf("cached announcement %q, %v", audio, err)
+	}
+}
@@ -18,9 +18,7 @@ package main
 import (
 	"database/sql"
 	"encoding/json"
-	"fmt"
 	"sort"
-	"strings"
 	"sync"
 )
 
@@ -195,6 +193,30 @@ func (u *Units) Merge(units *Units) bool {
 	return merged
 }
 
+// Alias returns the label of a unit ref, from its own entry or else from
+// the first unit range holding it.
+func (units *Units) Alias(unitRef uint) string {
+	if units == nil || unitRef == 0 {
+		return ""
+	}
+
+	units.mutex.Lock()
+	defer units.mutex.Unlock()
+
+	for _, unit := range units.List {
+		if unit.UnitRef == unitRef && unit.Label != "" {
+			return unit.Label
+		}
+	}
+	for _, unit := range units.List {
+		if unit.UnitFrom > 0 && unit.UnitTo >= unit.UnitFrom && unitRef >= unit.UnitFrom && unitRef <= unit.UnitTo && unit.Label != "" {
+			return unit.Label
+		}
+	}
+
+	return ""
+}
+
 func (units *Units) ReadTx(tx *sql.Tx, systemId uint64) error {
 	var (
 		err   error
@@ -209,8 +231,8 @@ func (units *Units) ReadTx(tx *sql.Tx, systemId uint64) error {
 
 	formatError := errorFormatter("units", "read")
 
-	query = fmt.Sprintf(`SELECT "unitId", "label", "order", "unitRef", "unitFrom", "unitTo" FROM "units" WHERE "systemId" = %d`, systemId)
-	if rows, err = tx.Query(query); err != nil {
+	query = `SELECT "unitId", "label", "order", "unitRef", "unitFrom", "unitTo" FROM "units" WHERE "systemId" = $1`
+	if rows, err = tx.Query(query, systemId); err != nil {
 		return formatError(err, query)
 	}
 
@@ -261,8 +283,8 @@ func (units *Units) WriteTx(tx *sql.Tx, systemId uint64) error {
 		}
 	}
 
-	query = fmt.Sprintf(`SELECT "unitId", "unitRef" FROM "units" WHERE "systemId" = %d`, systemId)
-	if rows, err = tx.Query(query); err != nil {
+	query = `SELECT "unitId", "unitRef" FROM "units" WHERE "systemId" = $1`
+	if rows, err = tx.Query(query, systemId); err != nil {
 		return formatError(err, query)
 	}
 
@@ -286,25 +308,23 @@ func (units *Units) WriteTx(tx *sql.Tx, systemId uint64) error {
 	}
 
 	if len(unitIds) > 0 {
-		if b, err := json.Marshal(unitIds); err == nil {
-			in := strings.ReplaceAll(strings.ReplaceAll(string(b), "[", "("), "]", ")")
-			query = fmt.Sprintf(`DELETE FROM "units" WHERE "unitId" IN %s`, in)
-			if _, err = tx.Exec(query); err != nil {
-				return formatError(err, query)
-			}
+		args := queryArgs{}
+		query = `DELETE FROM "units" WHERE "unitId" IN ` + args.in(unitIds)
+		if _, err = tx.Exec(query, args...); err != nil {
+			return formatError(err, query)
 		}
 	}
 
 	for _, unit := range units.List {
 		if unit.Id > 0 {
 			var count uint
-			query = fmt.Sprintf(`SELECT COUNT(*) FROM "units" WHERE "unitId" = %d AND "systemId" = %d`, unit.Id, systemId)
-			if err = tx.QueryRow(query).Scan(&count); err != nil {
+			query = `SELECT COUNT(*) FROM "units" WHERE "unitId" = $1 AND "systemId" = $2`
+			if err = tx.QueryRow(query, unit.Id, systemId).Scan(&count); err != nil {
 				break
 			}
 			if count > 0 {
-				query = fmt.Sprintf(`UPDATE "units" SET "label" = '%s', "order" = %d, "unitRef" = %d, "unitFrom" = %d, "unitTo" = %d WHERE "unitId" = %d AND "systemId" = %d`, escapeQuotes(unit.Label), unit.Order, unit.UnitRef, unit.UnitFrom, unit.UnitTo, unit.Id, systemId)
-				if _, err = tx.Exec(query); err != nil {
+				query = `UPDATE "units" SET "label" = $1, "order" = $2, "unitRef" = $3, "unitFrom" = $4, "unitTo" = $5 WHERE "unitId" = $6 AND "systemId" = $7`
+				if _, err = tx.Exec(query, unit.Label, unit.Order, unit.UnitRef, unit.UnitFrom, unit.UnitTo, unit.Id, systemId); err != nil {
 					break
 				}
 				continue
@@ -313,24 +333,24 @@ func (units *Units) WriteTx(tx *sql.Tx, systemId uint64) error {
 
 		if unit.UnitRef > 0 {
 			var existingId uint64
-			q2 := fmt.Sprintf(`SELECT "unitId" FROM "units" WHERE "systemId" = %d AND "unitRef" = %d LIMIT 1`, systemId, unit.UnitRef)
-			scanErr := tx.QueryRow(q2).Scan(&existingId)
+			q2 := `SELECT "unitId" FROM "units" WHERE "systemId" = $1 AND "unitRef" = $2 LIMIT 1`
+			scanErr := tx.QueryRow(q2, systemId, unit.UnitRef).Scan(&existingId)
 			if scanErr == sql.ErrNoRows {
 				// fall through to INSERT
 			} else if scanErr != nil {
 				err = scanErr
 				break
 			} else if existingId > 0 {
-				query = fmt.Sprintf(`UPDATE "units" SET "label" = '%s', "order" = %d, "unitRef" = %d, "unitFrom" = %d, "unitTo" = %d WHERE "unitId" = %d AND "systemId" = %d`, escapeQuotes(unit.Label), unit.Order, unit.UnitRef, unit.UnitFrom, unit.UnitTo, existingId, systemId)
-				if _, err = tx.Exec(query); err != nil {
+				query = `UPDATE "units" SET "label" = $1, "order" = $2, "unitRef" = $3, "unitFrom" = $4, "unitTo" = $5 WHERE "unitId" = $6 AND "systemId" = $7`
+				if _, err = tx.Exec(query, unit.Label, unit.Order, unit.UnitRef, unit.UnitFrom, unit.UnitTo, existingId, systemId); err != nil {
 					break
 				}
 				continue
 			}
 		}
 
-		query = fmt.Sprintf(`INSERT INTO "units" ("label", "order", "systemId", "unitRef", "unitFrom", "unitTo") VALUES ('%s', %d, %d, %d, %d, %d)`, escapeQuotes(unit.Label), unit.Order, systemId, unit.UnitRef, unit.UnitFrom, unit.UnitTo)
-		if _, err = tx.Exec(query); err != nil {
+		query = `INSERT INTO "units" ("label", "order", "systemId", "unitRef", "unitFrom", "unitTo") VALUES ($1, $2, $3, $4, $5, $6)`
+		if _, err = tx.Exec(query, unit.Label, unit.Order, systemId, unit.UnitRef, unit.UnitFrom, unit.UnitTo); err != nil {
 			break
 		}
 	}
@@ -0,0 +1,449 @@
+// Copyright (C) 2026 Thinline Dynamic Solutions
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>
+
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	unitActivityFlushInterval = 30 * time.Second
+	unitActivityListLimit     = 500
+	unitActivityTopTalkgroups = 3
+)
+
+type unitActivityKey struct {
+	SystemId uint64
+	UnitRef  uint
+}
+
+// unitActivityDelta is the activity of a unit since the last flush.
+type unitActivityDelta struct {
+	FirstSeen  int64
+	LastSeen   int64
+	Calls      int64
+	RadioLabel string
+	Talkgroups map[uint64]int64
+}
+
+// UnitActivity tracks the radio units heard on each system: first and last
+// seen, call count and the talkgroups they talk on. Calls are counted in
+// memory and written in batches.
+type UnitActivity struct {
+	controller *Controller
+	pending    map[unitActivityKey]*unitActivityDelta
+	mutex      sync.Mutex
+	stopChan   chan struct{}
+}
+
+func NewUnitActivity(controller *Controller) *UnitActivity {
+	return &UnitActivity{
+		controller: controller,
+		pending:    map[unitActivityKey]*unitActivityDelta{},
+		stopChan:   make(chan struct{}),
+	}
+}
+
+// Record counts a call for each unit heard on it.
+func (activity *UnitActivity) Record(call *Call) {
+	if call == nil || call.System == nil || call.System.Id == 0 || call.Talkgroup == nil || call.Talkgroup.Id == 0 {
+		return
+	}
+
+	timestamp := call.Timestamp.UnixMilli()
+
+	activity.mutex.Lock()
+	defer activity.mutex.Unlock()
+
+	for _, obs := range extractUnitObservations(call) {
+		key := unitActivityKey{SystemId: call.System.Id, UnitRef: obs.UnitRef}
+		delta, ok := activity.pending[key]
+		if !ok {
+			delta = &unitActivityDelta{FirstSeen: timestamp, LastSeen: timestamp, Talkgroups: map[uint64]int64{}}
+			activity.pending[key] = delta
+		}
+		delta.Calls++
+		if timestamp < delta.FirstSeen {
+			delta.FirstSeen = timestamp
+		}
+		if timestamp > delta.LastSeen {
+			delta.LastSeen = timestamp
+		}
+		delta.Talkgroups[call.Talkgroup.Id]++
+		if obs.RadioLabel != "" {
+			delta.RadioLabel = obs.RadioLabel
+		}
+	}
+}
+
+// Start writes the recorded activity every 30 seconds.
+func (activity *UnitActivity) Start() {
+	go func() {
+		ticker := time.NewTicker(unitActivityFlushInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := activity.Flush(); err != nil {
+					activity.controller.Logs.LogEvent(LogLevelError, fmt.Sprintf("unit activity: %v", err))
+				}
+			case <-activity.stopChan:
+				return
+			}
+		}
+	}()
+}
+
+// Stop signals the background goroutine to exit and writes what's pending.
+func (activity *UnitActivity) Stop() {
+	select {
+	case <-activity.stopChan:
+	default:
+		close(activity.stopChan)
+		activity.Flush()
+	}
+}
+
+// Flush writes the activity recorded since the last flush.
+func (activity *UnitActivity) Flush() error {
+	activity.mutex.Lock()
+	pending := activity.pending
+	activity.pending = map[unitActivityKey]*unitActivityDelta{}
+	activity.mutex.Unlock()
+
+	if len(pending) == 0 {
+		return nil
+	}
+
+	formatError := errorFormatter("unitActivity", "flush")
+
+	tx, err := activity.controller.Database.Sql.Begin()
+	if err != nil {
+		return formatError(err, "")
+	}
+
+	unitQuery := `INSERT INTO "unitActivity" ("systemId", "unitRef", "firstSeen", "lastSeen", "callCount", "radioLabel") VALUES ($1, $2, $3, $4, $5, $6) ` +
+		`ON CONFLICT ("systemId", "unitRef") DO UPDATE SET "firstSeen" = LEAST("unitActivity"."firstSeen", EXCLUDED."firstSeen"), "lastSeen" = GREATEST("unitActivity"."lastSeen", EXCLUDED."lastSeen"), ` +
+		`"callCount" = "unitActivity"."callCount" + EXCLUDED."callCount", "radioLabel" = CASE WHEN EXCLUDED."radioLabel" <> '' THEN EXCLUDED."radioLabel" ELSE "unitActivity"."radioLabel" END`
+	talkgroupQuery := `INSERT INTO "unitTalkgroupActivity" ("systemId", "unitRef", "talkgroupId", "callCount", "lastSeen") VALUES ($1, $2, $3, $4, $5) ` +
+		`ON CONFLICT ("systemId", "unitRef", "talkgroupId") DO UPDATE SET "callCount" = "unitTalkgroupActivity"."callCount" + EXCLUDED."callCount", "lastSeen" = GREATEST("unitTalkgroupActivity"."lastSeen", EXCLUDED."lastSeen")`
+
+	for key, delta := range pending {
+		if _, err := tx.Exec(unitQuery, key.SystemId, key.UnitRef, delta.FirstSeen, delta.LastSeen, delta.Calls, delta.RadioLabel); err != nil {
+			tx.Rollback()
+			return formatError(err, unitQuery)
+		}
+		for talkgroupId, calls := range delta.Talkgroups {
+			if _, err := tx.Exec(talkgroupQuery, key.SystemId, key.UnitRef, talkgroupId, calls, delta.LastSeen); err != nil {
+				tx.Rollback()
+				return formatError(err, talkgroupQuery)
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return formatError(err, "")
+	}
+
+	return nil
+}
+
+// UnitAffinity is a talkgroup a unit talks on.
+type UnitAffinity struct {
+	TalkgroupRef uint   `json:"talkgroupRef"`
+	Label        string `json:"label"`
+	Calls        int64  `json:"calls"`
+	LastSeen     int64  `json:"lastSeen"`
+}
+
+// UnitActivityEntry is a unit heard on a system.
+type UnitActivityEntry struct {
+	UnitRef    uint           `json:"unitRef"`
+	Alias      string         `json:"alias"`
+	RadioLabel string         `json:"radioLabel"`
+	FirstSeen  int64          `json:"firstSeen"`
+	LastSeen   int64          `json:"lastSeen"`
+	Calls      int64          `json:"calls"`
+	Talkgroups []UnitAffinity `json:"talkgroups"`
+}
+
+// List returns the units heard on a system, most recently heard first. search
+// filters on the unit ref, the alias and the radio label.
+func (activity *UnitActivity) List(system *System, search string, limit int) ([]*UnitActivityEntry, error) {
+	// Include what hasn't been written yet
+	if err := activity.Flush(); err != nil {
+		return nil, err
+	}
+
+	formatError := errorFormatter("unitActivity", "list")
+
+	query := `SELECT "unitRef", "firstSeen", "lastSeen", "callCount", "radioLabel" FROM "unitActivity" WHERE "systemId" = $1 ORDER BY "lastSeen" DESC`
+	rows, err := activity.controller.Database.Sql.Query(query, system.Id)
+	if err != nil {
+		return nil, formatError(err, query)
+	}
+	defer rows.Close()
+
+	search = strings.ToLower(strings.TrimSpace(search))
+
+	entries := []*UnitActivityEntry{}
+	byRef := map[uint]*UnitActivityEntry{}
+	for rows.Next() {
+		entry := &UnitActivityEntry{Talkgroups: []UnitAffinity{}}
+		if err := rows.Scan(&entry.UnitRef, &entry.FirstSeen, &entry.LastSeen, &entry.Calls, &entry.RadioLabel); err != nil {
+			return nil, formatError(err, query)
+		}
+		entry.Alias = system.Units.Alias(entry.UnitRef)
+		if search != "" && !unitActivityMatches(entry, search) {
+			continue
+		}
+		entries = append(entries, entry)
+		byRef[entry.UnitRef] = entry
+		if len(entries) == limit {
+			break
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, formatError(err, query)
+	}
+	rows.Close()
+
+	if len(entries) == 0 {
+		return entries, nil
+	}
+
+	query = `SELECT "unitRef", "talkgroupId", "callCount", "lastSeen" FROM "unitTalkgroupActivity" WHERE "systemId" = $1 ORDER BY "callCount" DESC`
+	affinityRows, err := activity.controller.Database.Sql.Query(query, system.Id)
+	if err != nil {
+		return nil, formatError(err, query)
+	}
+	defer affinityRows.Close()
+
+	for affinityRows.Next() {
+		var (
+			unitRef     uint
+			talkgroupId uint64
+			affinity    UnitAffinity
+		)
+		if err := affinityRows.Scan(&unitRef, &talkgroupId, &affinity.Calls, &affinity.LastSeen); err != nil {
+			return nil, formatError(err, query)
+		}
+		entry, ok := byRef[unitRef]
+		if !ok || len(entry.Talkgroups) == unitActivityTopTalkgroups {
+			continue
+		}
+		talkgroup, ok := system.Talkgroups.GetTalkgroupById(talkgroupId)
+		if !ok {
+			continue
+		}
+		affinity.TalkgroupRef = talkgroup.TalkgroupRef
+		affinity.Label = talkgroup.Label
+		entry.Talkgroups = append(entry.Talkgroups, affinity)
+	}
+
+	return entries, affinityRows.Err()
+}
+
+func unitActivityMatches(entry *UnitActivityEntry, search string) bool {
+	return strings.Contains(strconv.FormatUint(uint64(entry.UnitRef), 10), search) ||
+		strings.Contains(strings.ToLower(entry.Alias), search) ||
+		strings.Contains(strings.ToLower(entry.RadioLabel), search)
+}
+
+// SetUnitAlias sets the alias of a unit ref on a system, or removes it when
+// label is empty. Unit ranges are left alone.
+func (controller *Controller) SetUnitAlias(system *System, unitRef uint, label string) error {
+	formatError := errorFormatter("units", "alias")
+
+	label = strings.TrimSpace(label)
+
+	var query string
+	var err error
+	if label == "" {
+		query = `DELETE FROM "units" WHERE "systemId" = $1 AND "unitRef" = $2 AND "unitFrom" = 0`
+		_, err = controller.Database.Sql.Exec(query, system.Id, unitRef)
+	} else {
+		query = `UPDATE "units" SET "label" = $1 WHERE "systemId" = $2 AND "unitRef" = $3 AND "unitFrom" = 0`
+		var result sql.Result
+		if result, err = controller.Database.Sql.Exec(query, label, system.Id, unitRef); err == nil {
+			if n, _ := result.RowsAffected(); n == 0 {
+				err = controller.persistLearnedUnit(system.Id, unitRef, label)
+			}
+		}
+	}
+	if err != nil {
+		return formatError(err, query)
+	}
+
+	system.Units.mutex.Lock()
+	list := []*Unit{}
+	found := false
+	for _, unit := range system.Units.List {
+		if unit.UnitRef == unitRef && unit.UnitFrom == 0 {
+			if label == "" {
+				continue
+			}
+			unit.Label = label
+			found = true
+		}
+		list = append(list, unit)
+	}
+	if !found && label != "" {
+		list = append(list, &Unit{Label: label, SystemId: system.Id, UnitRef: unitRef})
+	}
+	system.Units.List = list
+	system.Units.mutex.Unlock()
+
+	controller.SyncConfigToFile()
+	go controller.EmitConfig()
+
+	return nil
+}
+
+// UnitsHandler lists the units heard on a system and sets their aliases.
+//
+//	GET /api/admin/units?systemRef=1&search=engine
+//	PUT /api/admin/units  {"systemRef": 1, "unitRef": 1402, "alias": "Engine 2"}
+func (admin *Admin) UnitsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	writeError := func(status int, message string) {
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(map[string]string{"error": message})
+	}
+
+	t := admin.GetAuthorization(r)
+	if !admin.ValidatePermission(t, PermissionManageTalkgroups) {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		systemRef, err := strconv.ParseUint(r.URL.Query().Get("systemRef"), 10, 32)
+		if err != nil {
+			writeError(http.StatusBadRequest, "systemRef is required")
+			return
+		}
+		system, ok := admin.Controller.Systems.GetSystemByRef(uint(systemRef))
+		if !ok || !admin.tenantAllowsSystem(t, system.Id) {
+			writeError(http.StatusNotFound, "system not found")
+			return
+		}
+
+		limit := unitActivityListLimit
+		if v, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && v > 0 && v < limit {
+			limit = v
+		}
+
+		entries, err := admin.Controller.UnitActivity.List(system, r.URL.Query().Get("search"), limit)
+		if err != nil {
+			writeError(http.StatusInternalServerError, err.Error())
+			return
+		}
+		json.NewEncoder(w).Encode(entries)
+
+	case http.MethodPut:
+		var request struct {
+			SystemRef uint   `json:"systemRef"`
+			UnitRef   uint   `json:"unitRef"`
+			Alias     string `json:"alias"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil || request.UnitRef == 0 {
+			writeError(http.StatusBadRequest, "systemRef and unitRef are required")
+			return
+		}
+		system, ok := admin.Controller.Systems.GetSystemByRef(request.SystemRef)
+		if !ok || !admin.tenantAllowsSystem(t, system.Id) {
+			writeError(http.StatusNotFound, "system not found")
+			return
+		}
+
+		if err := admin.Controller.SetUnitAlias(system, request.UnitRef, request.Alias); err != nil {
+			writeError(http.StatusInternalServerError, err.Error())
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{
+			"systemRef": system.SystemRef,
+			"unitRef":   request.UnitRef,
+			"alias":     system.Units.Alias(request.UnitRef),
+		})
+
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// attachCallUnits adds the units heard on each call, with their aliases, to
+// transcript entries holding "callId" and "systemId".
+func (controller *Controller) attachCallUnits(entries []map[string]any) {
+	if len(entries) == 0 {
+		return
+	}
+
+	byId := map[uint64]map[string]any{}
+	ids := []uint64{}
+	for _, entry := range entries {
+		if id, ok := entry["callId"].(uint64); ok {
+			byId[id] = entry
+			ids = append(ids, id)
+		}
+	}
+
+	args := queryArgs{}
+	query := `SELECT "callId", "unitRef", COALESCE("label", '') FROM "callUnits" WHERE "callId" IN ` + args.in(ids) + ` ORDER BY "callId", "offset"`
+	rows, err := controller.Database.Sql.Query(query, args...)
+	if err != nil {
+		controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("transcripts: call units: %v", err))
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			callId  uint64
+			unitRef uint
+			tag     string
+		)
+		if err := rows.Scan(&callId, &unitRef, &tag); err != nil || unitRef == 0 {
+			continue
+		}
+		entry, ok := byId[callId]
+		if !ok {
+			continue
+		}
+		unit := map[string]any{"unitRef": unitRef}
+		if tag != "" {
+			unit["tag"] = tag
+		}
+		if systemId, ok := entry["systemId"].(uint64); ok {
+			if system, ok := controller.Systems.GetSystemById(systemId); ok {
+				if alias := system.Units.Alias(unitRef); alias != "" {
+					unit["alias"] = alias
+				}
+			}
+		}
+		units, _ := entry["units"].([]map[string]any)
+		entry["units"] = append(units, unit)
+	}
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestUnitsAlias(t *testing.T) {
+	units := NewUnits()
+	units.List = []*Unit{
+		{UnitRef: 1402, Label: "Engine 2"},
+		{UnitFrom: 1400, UnitTo: 1499, Label: "Fire"},
+		{UnitRef: 1500},
+	}
+
+	for ref, want := range map[uint]string{1402: "Engine 2", 1403: "Fire", 1500: "", 2000: ""} {
+		if got := units.Alias(ref); got != want {
+			t.Fatalf("Alias(%d) = %q, want %q", ref, got, want)
+		}
+	}
+}
+
+func TestUnitActivityRecord(t *testing.T) {
+	activity := NewUnitActivity(&Controller{Options: &Options{}})
+
+	system := NewSystem()
+	system.Id = 1
+	tg1 := &Talkgroup{Id: 10}
+	tg2 := &Talkgroup{Id: 11}
+	base := time.UnixMilli(1760000000000)
+
+	call := func(talkgroup *Talkgroup, at time.Time, refs ...uint) *Call {
+		c := NewCall()
+		c.System = system
+		c.Talkgroup = talkgroup
+		c.Timestamp = at
+		c.Meta.UnitRefs = refs
+		return c
+	}
+
+	activity.Record(call(tg1, base, 1402, 1403))
+	activity.Record(call(tg2, base.Add(-time.Minute), 1402))
+	activity.Record(call(tg1, base.Add(time.Minute), 1402))
+
+	delta := activity.pending[unitActivityKey{SystemId: 1, UnitRef: 1402}]
+	if delta == nil || delta.Calls != 3 {
+		t.Fatalf("unexpected delta %+v", delta)
+	}
+	if delta.FirstSeen != base.Add(-time.Minute).UnixMilli() || delta.LastSeen != base.Add(time.Minute).UnixMilli() {
+		t.Fatalf("unexpected first/last seen %d %d", delta.FirstSeen, delta.LastSeen)
+	}
+	if delta.Talkgroups[10] != 2 || delta.Talkgroups[11] != 1 {
+		t.Fatalf("unexpected affinity %v", delta.Talkgroups)
+	}
+	if len(activity.pending) != 2 {
+		t.Fatalf("expected 2 units, got %d", len(activity.pending))
+	}
+}
+
+func TestCallJsonIncludesUnitAlias(t *testing.T) {
+	system := NewSystem()
+	system.Units.List = []*Unit{{UnitRef: 1402, Label: "Engine 2"}}
+
+	call := NewCall()
+	call.System = system
+	call.Talkgroup = &Talkgroup{TalkgroupRef: 1}
+	call.Units = []CallUnit{{UnitRef: 1402}, {UnitRef: 77}}
+
+	b, err := json.Marshal(call)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	if !strings.Contains(string(b), `"alias":"Engine 2"`) || strings.Count(string(b), `"alias"`) != 1 {
+		t.Fatalf("unexpected call json %s", b)
+	}
+}
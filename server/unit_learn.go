@@ -343,13 +343,7 @@ func (controller *Controller) autoAddLearnedUnitAlias(system *System, talkgroup
 }
 
 func (controller *Controller) persistLearnedUnit(systemId uint64, unitRef uint, label string) error {
-	var query string
-	if controller.Database.Config.DbType == DbTypePostgresql {
-		query = `INSERT INTO "units" ("label", "order", "systemId", "unitRef", "unitFrom", "unitTo") VALUES ($1, 0, $2, $3, 0, 0)`
-		_, err := controller.Database.Sql.Exec(query, label, systemId, unitRef)
-		return err
-	}
-	query = fmt.Sprintf(`INSERT INTO "units" ("label", "order", "systemId", "unitRef", "unitFrom", "unitTo") VALUES ('%s', 0, %d, %d, 0, 0)`, escapeQuotes(label), systemId, unitRef)
-	_, err := controller.Database.Sql.Exec(query)
+	query := `INSERT INTO "units" ("label", "order", "systemId", "unitRef", "unitFrom", "unitTo") VALUES ($1, 0, $2, $3, 0, 0)`
+	_, err := controller.Database.Sql.Exec(query, label, systemId, unitRef)
 	return err
 }
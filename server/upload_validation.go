@@ -0,0 +1,373 @@
+// Copyright (C) 2026 Thinline Dynamic Solutions
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// UploadLimitsConfig bounds the calls accepted by the upload endpoints and
+// enables the probe that rejects audio the pipeline can't use.
+type UploadLimitsConfig struct {
+	MaxBytes     uint64               `json:"maxBytes,omitempty"`   // default 100 MB
+	MaxSeconds   uint                 `json:"maxSeconds,omitempty"` // 0 = no limit
+	DisableProbe bool                 `json:"disableProbe,omitempty"`
+	Systems      []UploadSystemLimits `json:"systems,omitempty"`
+}
+
+// UploadSystemLimits overrides the limits for the calls of one system. A
+// zero limit keeps the global one.
+type UploadSystemLimits struct {
+	SystemRef  uint   `json:"systemRef"`
+	MaxBytes   uint64 `json:"maxBytes,omitempty"`
+	MaxSeconds uint   `json:"maxSeconds,omitempty"`
+}
+
+const (
+	uploadDefaultMaxBytes = 100 << 20
+	// uploadBodyOverhead leaves room for the multipart framing and metadata
+	// fields around the audio file.
+	uploadBodyOverhead = 1 << 20
+	uploadProbeTimeout = 5 * time.Second
+)
+
+// limits returns the size and duration limits of a system's calls.
+func (config UploadLimitsConfig) limits(systemRef uint) (maxBytes uint64, maxSeconds uint) {
+	maxBytes, maxSeconds = config.MaxBytes, config.MaxSeconds
+	if maxBytes == 0 {
+		maxBytes = uploadDefaultMaxBytes
+	}
+	for _, system := range config.Systems {
+		if system.SystemRef != systemRef {
+			continue
+		}
+		if system.MaxBytes > 0 {
+			maxBytes = system.MaxBytes
+		}
+		if system.MaxSeconds > 0 {
+			maxSeconds = system.MaxSeconds
+		}
+	}
+	return maxBytes, maxSeconds
+}
+
+// bodyLimit caps an upload request before its system is known, at the
+// largest size any system accepts.
+func (config UploadLimitsConfig) bodyLimit() int64 {
+	maxBytes, _ := config.limits(0)
+	for _, system := range config.Systems {
+		maxBytes = max(maxBytes, system.MaxBytes)
+	}
+	return int64(maxBytes) + uploadBodyOverhead
+}
+
+// uploadReadStatus is the status answering a failed read of an upload body.
+func uploadReadStatus(err error) int {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return http.StatusRequestEntityTooLarge
+	}
+	return http.StatusExpectationFailed
+}
+
+// uploadRejection is an uploaded call refused before ingestion.
+type uploadRejection struct {
+	status  int
+	message string
+}
+
+func (rejection *uploadRejection) Error() string {
+	return rejection.message
+}
+
+// uploadProbe is what could be learned about an uploaded file. A negative
+// duration is unknown.
+type uploadProbe struct {
+	format   string
+	audio    bool
+	video    bool
+	duration float64
+}
+
+// validateUpload checks a call's audio against the limits of its system and
+// probes it for anything that would break conversion, tone detection or
+// transcription later on.
+func (controller *Controller) validateUpload(call *Call) *uploadRejection {
+	config := controller.Options.UploadLimitsConfig
+
+	var systemRef uint
+	if call.System != nil {
+		systemRef = call.System.SystemRef
+	}
+	maxBytes, maxSeconds := config.limits(systemRef)
+
+	if len(call.Audio) == 0 {
+		return &uploadRejection{http.StatusUnprocessableEntity, "Rejected audio: file is empty"}
+	}
+	if uint64(len(call.Audio)) > maxBytes {
+		return &uploadRejection{http.StatusRequestEntityTooLarge, fmt.Sprintf("Rejected audio: %d bytes exceeds the %d byte limit of system %d", len(call.Audio), maxBytes, systemRef)}
+	}
+
+	if config.DisableProbe {
+		return nil
+	}
+
+	probe, err := probeUploadAudio(call.Audio, call.AudioFilename, call.AudioMime)
+	switch {
+	case err != nil:
+		return &uploadRejection{http.StatusUnprocessableEntity, fmt.Sprintf("Rejected audio: %s", err.Error())}
+	case probe.video:
+		return &uploadRejection{http.StatusUnprocessableEntity, fmt.Sprintf("Rejected audio: %s file contains a video stream", probe.format)}
+	case !probe.audio:
+		return &uploadRejection{http.StatusUnprocessableEntity, fmt.Sprintf("Rejected audio: %s file has no audio stream", probe.format)}
+	case probe.duration == 0:
+		return &uploadRejection{http.StatusUnprocessableEntity, "Rejected audio: duration is zero"}
+	case maxSeconds > 0 && probe.duration > float64(maxSeconds):
+		return &uploadRejection{http.StatusUnprocessableEntity, fmt.Sprintf("Rejected audio: %.1f seconds exceeds the %d second limit of system %d", probe.duration, maxSeconds, systemRef)}
+	}
+
+	return nil
+}
+
+// probeUploadAudio probes an uploaded file with ffprobe, or by its header
+// when ffprobe isn't installed or doesn't answer.
+func probeUploadAudio(audio []byte, filename string, audioMime string) (uploadProbe, error) {
+	if _, err := exec.LookPath("ffprobe"); err == nil {
+		probe, err := probeUploadAudioFFprobe(audio, filename, audioMime)
+		var exitErr *exec.ExitError
+		if err == nil || errors.As(err, &exitErr) {
+			return probe, err
+		}
+	}
+	return probeUploadAudioNative(audio)
+}
+
+func probeUploadAudioFFprobe(audio []byte, filename string, audioMime string) (uploadProbe, error) {
+	probe := uploadProbe{duration: -1}
+
+	ext := strings.ToLower(filepath.Ext(filename))
+	if ext == "" {
+		switch {
+		case strings.Contains(audioMime, "mp3"), strings.Contains(audioMime, "mpeg"):
+			ext = ".mp3"
+		case strings.Contains(audioMime, "wav"):
+			ext = ".wav"
+		case strings.Contains(audioMime, "ogg"):
+			ext = ".ogg"
+		default:
+			ext = ".m4a"
+		}
+	}
+
+	tempFile := filepath.Join(os.TempDir(), fmt.Sprintf("upload_probe_%d%s", time.Now().UnixNano(), ext))
+	if err := os.WriteFile(tempFile, audio, 0644); err != nil {
+		return probe, fmt.Errorf("write temp file: %w", err)
+	}
+	defer os.Remove(tempFile)
+
+	ctx, cancel := context.WithTimeout(context.Background(), uploadProbeTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "ffprobe",
+		"-v", "error",
+		"-show_entries", "stream=codec_type,duration:stream_disposition=attached_pic",
+		"-show_entries", "format=format_name,duration",
+		"-of", "json",
+		tempFile,
+	)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return probe, ctx.Err()
+		}
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			reason, _, _ := strings.Cut(strings.TrimSpace(stderr.String()), "\n")
+			reason = strings.TrimSpace(strings.TrimPrefix(reason, tempFile+":"))
+			if reason == "" {
+				reason = "unreadable file"
+			}
+			return probe, &uploadProbeError{reason: reason, err: exitErr}
+		}
+		return probe, err
+	}
+
+	var result struct {
+		Streams []struct {
+			CodecType   string `json:"codec_type"`
+			Duration    string `json:"duration"`
+			Disposition struct {
+				AttachedPic int `json:"attached_pic"`
+			} `json:"disposition"`
+		} `json:"streams"`
+		Format struct {
+			FormatName string `json:"format_name"`
+			Duration   string `json:"duration"`
+		} `json:"format"`
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &result); err != nil {
+		return probe, fmt.Errorf("parse ffprobe output: %w", err)
+	}
+
+	probe.format = result.Format.FormatName
+	for _, stream := range result.Streams {
+		switch stream.CodecType {
+		case "audio":
+			// Stream duration comes from the frames; some recorders leave a
+			// placeholder in the container header.
+			if !probe.audio {
+				if d, err := strconv.ParseFloat(stream.Duration, 64); err == nil {
+					probe.duration = d
+				}
+			}
+			probe.audio = true
+		case "video":
+			// Cover art embedded in MP3 and M4A tags is harmless.
+			if stream.Disposition.AttachedPic == 0 {
+				probe.video = true
+			}
+		}
+	}
+	if probe.duration < 0 {
+		if d, err := strconv.ParseFloat(result.Format.Duration, 64); err == nil {
+			probe.duration = d
+		}
+	}
+
+	return probe, nil
+}
+
+// uploadProbeError is a file ffprobe could not read.
+type uploadProbeError struct {
+	reason string
+	err    error
+}
+
+func (e *uploadProbeError) Error() string {
+	return fmt.Sprintf("corrupt or unsupported file (%s)", e.reason)
+}
+
+func (e *uploadProbeError) Unwrap() error {
+	return e.err
+}
+
+// probeUploadAudioNative recognizes the containers recorders upload by their
+// signature. Only WAV reports a duration.
+func probeUploadAudioNative(audio []byte) (uploadProbe, error) {
+	probe := uploadProbe{duration: -1}
+
+	switch {
+	case len(audio) >= 12 && string(audio[0:4]) == "RIFF" && string(audio[8:12]) == "WAVE":
+		probe.format, probe.audio = "wav", true
+		duration, err := wavDuration(audio)
+		if err != nil {
+			return probe, fmt.Errorf("corrupt or unsupported file (%s)", err.Error())
+		}
+		probe.duration = duration
+
+	case len(audio) >= 12 && string(audio[0:4]) == "RIFF" && string(audio[8:12]) == "AVI ":
+		probe.format, probe.video = "avi", true
+
+	case len(audio) >= 3 && string(audio[0:3]) == "FLV":
+		probe.format, probe.video = "flv", true
+
+	case len(audio) >= 8 && string(audio[4:8]) == "ftyp":
+		probe.format = "mp4"
+		probe.audio, probe.video = mp4Handlers(audio)
+
+	case len(audio) >= 4 && string(audio[0:4]) == "OggS":
+		probe.format, probe.audio = "ogg", true
+		probe.video = bytes.Contains(audio, []byte("\x80theora"))
+
+	case len(audio) >= 4 && string(audio[0:4]) == "fLaC":
+		probe.format, probe.audio = "flac", true
+
+	case len(audio) >= 4 && bytes.Equal(audio[0:4], []byte{0x1a, 0x45, 0xdf, 0xa3}):
+		probe.format, probe.audio = "matroska", true
+
+	case len(audio) >= 3 && string(audio[0:3]) == "ID3",
+		len(audio) >= 2 && audio[0] == 0xff && audio[1]&0xe0 == 0xe0:
+		// MPEG audio and AAC ADTS frames share the sync word.
+		probe.format, probe.audio = "mpeg", true
+
+	default:
+		return probe, errors.New("corrupt or unsupported file (unrecognized format)")
+	}
+
+	return probe, nil
+}
+
+// wavDuration walks the chunks of a WAV file for its byte rate and the
+// length of its samples.
+func wavDuration(audio []byte) (float64, error) {
+	var byteRate uint32
+	for offset := 12; offset+8 <= len(audio); {
+		id := string(audio[offset : offset+4])
+		size := int(binary.LittleEndian.Uint32(audio[offset+4 : offset+8]))
+		body := offset + 8
+
+		switch id {
+		case "fmt ":
+			if size < 16 || body+16 > len(audio) {
+				return 0, errors.New("truncated fmt chunk")
+			}
+			byteRate = binary.LittleEndian.Uint32(audio[body+8 : body+12])
+		case "data":
+			if byteRate == 0 {
+				return 0, errors.New("no fmt chunk before data")
+			}
+			// Streaming writers leave the size unset; count what arrived.
+			size = min(size, len(audio)-body)
+			return float64(size) / float64(byteRate), nil
+		}
+
+		offset = body + size + size%2
+	}
+	return 0, errors.New("no data chunk")
+}
+
+// mp4Handlers reports the track types declared by the hdlr boxes of an MP4
+// file.
+func mp4Handlers(audio []byte) (sound bool, video bool) {
+	for rest := audio; ; {
+		i := bytes.Index(rest, []byte("hdlr"))
+		if i < 0 || i+16 > len(rest) {
+			return sound, video
+		}
+		switch string(rest[i+12 : i+16]) {
+		case "soun":
+			sound = true
+		case "vide":
+			video = true
+		}
+		rest = rest[i+4:]
+	}
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestUploadLimitsPerSystem(t *testing.T) {
+	config := UploadLimitsConfig{
+		MaxSeconds: 600,
+		Systems: []UploadSystemLimits{
+			{SystemRef: 7, MaxBytes: 200 << 20},
+			{SystemRef: 8, MaxSeconds: 60},
+		},
+	}
+
+	if maxBytes, maxSeconds := config.limits(1); maxBytes != uploadDefaultMaxBytes || maxSeconds != 600 {
+		t.Fatalf("system 1 limits = %d, %d", maxBytes, maxSeconds)
+	}
+	if maxBytes, maxSeconds := config.limits(7); maxBytes != 200<<20 || maxSeconds != 600 {
+		t.Fatalf("system 7 limits = %d, %d", maxBytes, maxSeconds)
+	}
+	if maxBytes, maxSeconds := config.limits(8); maxBytes != uploadDefaultMaxBytes || maxSeconds != 60 {
+		t.Fatalf("system 8 limits = %d, %d", maxBytes, maxSeconds)
+	}
+	if got := config.bodyLimit(); got != 200<<20+uploadBodyOverhead {
+		t.Fatalf("bodyLimit = %d", got)
+	}
+}
+
+func TestUploadReadStatusTooLarge(t *testing.T) {
+	w := httptest.NewRecorder()
+	body := http.MaxBytesReader(w, io.NopCloser(strings.NewReader(strings.Repeat("x", 100))), 10)
+
+	_, err := io.ReadAll(body)
+	if status := uploadReadStatus(err); status != http.StatusRequestEntityTooLarge {
+		t.Fatalf("status = %d, want 413", status)
+	}
+	if status := uploadReadStatus(errors.New("unexpected EOF")); status != http.StatusExpectationFailed {
+		t.Fatalf("status = %d, want 417", status)
+	}
+}
+
+func TestProbeUploadAudioNative(t *testing.T) {
+	wav := encodePCM16Wav(make([]int16, 16000), 8000)
+
+	probe, err := probeUploadAudioNative(wav)
+	if err != nil || !probe.audio || probe.video || probe.duration != 2 {
+		t.Fatalf("wav probe = %+v, %v", probe, err)
+	}
+
+	probe, err = probeUploadAudioNative(encodePCM16Wav(nil, 8000))
+	if err != nil || probe.duration != 0 {
+		t.Fatalf("empty wav probe = %+v, %v", probe, err)
+	}
+
+	if _, err := probeUploadAudioNative(wav[:20]); err == nil {
+		t.Fatal("truncated wav was accepted")
+	}
+
+	avi := append([]byte("RIFF\x00\x00\x00\x00AVI LIST"), make([]byte, 64)...)
+	if probe, err := probeUploadAudioNative(avi); err != nil || !probe.video {
+		t.Fatalf("avi probe = %+v, %v", probe, err)
+	}
+
+	var mp4 bytes.Buffer
+	mp4.WriteString("\x00\x00\x00\x18ftypisom")
+	for _, handler := range []string{"vide", "soun"} {
+		mp4.WriteString("\x00\x00\x00\x21hdlr\x00\x00\x00\x00\x00\x00\x00\x00" + handler)
+	}
+	if probe, err := probeUploadAudioNative(mp4.Bytes()); err != nil || !probe.audio || !probe.video {
+		t.Fatalf("mp4 probe = %+v, %v", probe, err)
+	}
+
+	if probe, err := probeUploadAudioNative([]byte{0xff, 0xfb, 0x90, 0x00}); err != nil || !probe.audio || probe.duration >= 0 {
+		t.Fatalf("mp3 probe = %+v, %v", probe, err)
+	}
+
+	if _, err := probeUploadAudioNative([]byte("<html>not audio</html>")); err == nil {
+		t.Fatal("html was accepted")
+	}
+}
+
+func TestValidateUploadSizeLimit(t *testing.T) {
+	controller := &Controller{Options: &Options{UploadLimitsConfig: UploadLimitsConfig{
+		MaxBytes:     1000,
+		DisableProbe: true,
+	}}}
+
+	call := &Call{Audio: make([]byte, 1001)}
+	if rejection := controller.validateUpload(call); rejection == nil || rejection.status != http.StatusRequestEntityTooLarge {
+		t.Fatalf("rejection = %+v", rejection)
+	}
+
+	call.Audio = call.Audio[:1000]
+	if rejection := controller.validateUpload(call); rejection != nil {
+		t.Fatalf("rejection = %+v", rejection)
+	}
+}
@@ -325,83 +325,68 @@ func (users *Users) GenerateUniquePin(excludeID uint64) (string, error) {
 	return "", fmt.Errorf("unable to generate unique pin after %d attempts", maxAttempts)
 }
 
-func (u *User) HasAccess(call *Call) bool {
-	if u == nil || call == nil || call.System == nil || call.Talkgroup == nil {
+// HasTalkgroupAccess reports whether the user's own system scopes allow the
+// talkgroup. Group and tenant restrictions are added by userScope.
+func (u *User) HasTalkgroupAccess(systemRef uint, talkgroupRef uint) bool {
+	all, entries := u.systemEntries(systemRef)
+	if all {
 		return true
 	}
 
-	// If user has a group, use group's system access
-	// Note: This requires access to Controller, which we'll handle in the caller
-	// For now, fall back to user-level access if group is not available
-	if u.UserGroupId > 0 {
-		// Group access will be checked in controller where we have access to UserGroups
-		// This method signature will need to be updated or we check group in the caller
-		// For backward compatibility, we'll check both
+	for _, entry := range entries {
+		tg, ok := entry["talkgroups"]
+		if !ok {
+			// No talkgroups restriction means whole system allowed
+			return true
+		}
+		switch talkgroups := tg.(type) {
+		case string:
+			if talkgroups == "*" {
+				return true
+			}
+		case []any:
+			for _, value := range talkgroups {
+				if ref, ok := parseUintFromAny(value); ok && ref == talkgroupRef {
+					return true
+				}
+			}
+		}
 	}
 
-	// Fall back to user-level access check
-	if u.systemsData == nil {
-		return true
+	return false
+}
+
+// HasSystemAccess reports whether the user's own system scopes list the
+// system, with some or all of its talkgroups.
+func (u *User) HasSystemAccess(systemRef uint) bool {
+	all, entries := u.systemEntries(systemRef)
+	return all || len(entries) > 0
+}
+
+// systemEntries returns the scope entries of the user for a system, or all
+// when the user isn't limited to some systems.
+func (u *User) systemEntries(systemRef uint) (all bool, entries []map[string]any) {
+	if u == nil || u.systemsData == nil {
+		return true, nil
 	}
 
 	switch v := u.systemsData.(type) {
 	case string:
-		return strings.TrimSpace(v) == "" || v == "*"
+		return strings.TrimSpace(v) == "" || v == "*", nil
 	case []any:
 		for _, scope := range v {
 			scopeMap, ok := scope.(map[string]any)
 			if !ok {
 				continue
 			}
-
-			idVal, ok := scopeMap["id"]
-			if !ok {
-				continue
-			}
-
-			var systemRef uint
-			switch id := idVal.(type) {
-			case float64:
-				systemRef = uint(id)
-			case string:
-				if parsed, err := strconv.ParseUint(id, 10, 32); err == nil {
-					systemRef = uint(parsed)
-				}
-			}
-			if systemRef != uint(call.System.SystemRef) {
-				continue
-			}
-
-			if tg, ok := scopeMap["talkgroups"]; ok {
-				switch talkgroups := tg.(type) {
-				case string:
-					if talkgroups == "*" {
-						return true
-					}
-				case []any:
-					for _, entry := range talkgroups {
-						switch talkgroupRef := entry.(type) {
-						case float64:
-							if uint(talkgroupRef) == uint(call.Talkgroup.TalkgroupRef) {
-								return true
-							}
-						case string:
-							if parsed, err := strconv.ParseUint(talkgroupRef, 10, 32); err == nil && uint(parsed) == uint(call.Talkgroup.TalkgroupRef) {
-								return true
-							}
-						}
-					}
-				}
-			} else {
-				// No talkgroups restriction means whole system allowed
-				return true
+			if id, ok := parseUintFromAny(scopeMap["id"]); ok && id == systemRef {
+				entries = append(entries, scopeMap)
 			}
 		}
-	default:
-		return true
+		return false, entries
 	}
 
-	return false
+	return true, nil
 }
 
 func (u *User) PinExpired() bool {
@@ -411,39 +396,22 @@ func (u *User) PinExpired() bool {
 	return uint64(time.Now().Unix()) > u.PinExpiresAt
 }
 
-func (u *User) EffectiveDelay(call *Call, defaultDelay uint) uint {
-	if u == nil || call == nil || call.System == nil || call.Talkgroup == nil {
-		return defaultDelay
+// delays returns the talkgroup, system and account delays the user's own
+// settings give the call, 0 where unset.
+func (u *User) delays(call *Call) [3]uint {
+	var delays [3]uint
+	if u == nil || call == nil || call.System == nil {
+		return delays
 	}
 
-	// If user has a group, use group's delay settings
-	// Note: This requires access to Controller, which we'll handle in the caller
-	// For now, fall back to user-level delays if group is not available
-	if u.UserGroupId > 0 {
-		// Group delays will be checked in controller where we have access to UserGroups
-		// This method signature will need to be updated or we check group in the caller
-		// For backward compatibility, we'll check both
+	if call.Talkgroup != nil {
+		delays[0] = u.talkgroupDelaysMap[fmt.Sprintf("%d:%d", call.System.SystemRef, call.Talkgroup.TalkgroupRef)]
 	}
-
-	// Fall back to user-level delays
-	if len(u.talkgroupDelaysMap) > 0 {
-		key := fmt.Sprintf("%d:%d", call.System.SystemRef, call.Talkgroup.TalkgroupRef)
-		if delay, ok := u.talkgroupDelaysMap[key]; ok && delay > 0 {
-			return delay
-		}
-	}
-
-	if len(u.systemDelaysMap) > 0 {
-		if delay, ok := u.systemDelaysMap[uint64(call.System.SystemRef)]; ok && delay > 0 {
-			return delay
-		}
-	}
-
+	delays[1] = u.systemDelaysMap[uint64(call.System.SystemRef)]
 	if u.Delay > 0 {
-		return uint(u.Delay)
+		delays[2] = uint(u.Delay)
 	}
-
-	return defaultDelay
+	return delays
 }
 
 func (u *User) HashPassword(password string) error {
@@ -252,29 +252,22 @@ func (ug *UserGroup) HasTalkgroupAccess(systemId uint64, talkgroupId uint) bool
 	return true
 }
 
-func (ug *UserGroup) EffectiveDelay(call *Call, defaultDelay uint) uint {
-	if ug == nil || call == nil || call.System == nil || call.Talkgroup == nil {
-		return defaultDelay
+// delays returns the talkgroup, system and account delays the group gives
+// the call, 0 where unset.
+func (ug *UserGroup) delays(call *Call) [3]uint {
+	var delays [3]uint
+	if ug == nil || call == nil || call.System == nil {
+		return delays
 	}
 
-	if len(ug.talkgroupDelaysMap) > 0 {
-		key := fmt.Sprintf("%d:%d", call.System.SystemRef, call.Talkgroup.TalkgroupRef)
-		if delay, ok := ug.talkgroupDelaysMap[key]; ok && delay > 0 {
-			return delay
-		}
-	}
-
-	if len(ug.systemDelaysMap) > 0 {
-		if delay, ok := ug.systemDelaysMap[uint64(call.System.SystemRef)]; ok && delay > 0 {
-			return delay
-		}
+	if call.Talkgroup != nil {
+		delays[0] = ug.talkgroupDelaysMap[fmt.Sprintf("%d:%d", call.System.SystemRef, call.Talkgroup.TalkgroupRef)]
 	}
-
+	delays[1] = ug.systemDelaysMap[uint64(call.System.SystemRef)]
 	if ug.Delay > 0 {
-		return uint(ug.Delay)
+		delays[2] = uint(ug.Delay)
 	}
-
-	return defaultDelay
+	return delays
 }
 
 func (ugs *UserGroups) Load(db *Database) error {
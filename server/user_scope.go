@@ -0,0 +1,106 @@
+// Copyright (C) 2026 Thinline Dynamic Solutions
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>
+
+package main
+
+// userScope resolves what a user may hear and how long calls are delayed for
+// them, from the user's own settings, then their user group's, then the
+// defaults:
+//
+//   - A talkgroup must be allowed by the user's tenant, their group and their
+//     own system scopes. A user can be narrowed within their group but never
+//     widened beyond it.
+//   - A delay set for the talkgroup wins over one set for its system, which
+//     wins over the account delay. At each of these levels the user's own
+//     setting wins over the group's. Without any, the default applies.
+type userScope struct {
+	user   *User
+	group  *UserGroup
+	tenant *Tenant
+}
+
+func (controller *Controller) userScope(user *User) userScope {
+	scope := userScope{user: user}
+	if user == nil {
+		return scope
+	}
+	if user.UserGroupId > 0 && controller.UserGroups != nil {
+		scope.group = controller.UserGroups.Get(user.UserGroupId)
+	}
+	if controller.Tenants != nil {
+		scope.tenant = controller.Tenants.Of(user)
+	}
+	return scope
+}
+
+// allowsSystem reports whether the user may hear some talkgroups of the
+// system.
+func (scope userScope) allowsSystem(system *System) bool {
+	if scope.user == nil || system == nil {
+		return true
+	}
+	if scope.tenant != nil && !scope.tenant.HasSystem(system.Id) {
+		return false
+	}
+	if scope.group != nil && !scope.group.HasSystemAccess(uint64(system.SystemRef)) {
+		return false
+	}
+	return scope.user.HasSystemAccess(system.SystemRef)
+}
+
+// allowsTalkgroup reports whether the user may hear the talkgroup.
+func (scope userScope) allowsTalkgroup(system *System, talkgroup *Talkgroup) bool {
+	if !scope.allowsSystem(system) {
+		return false
+	}
+	if scope.user == nil || talkgroup == nil {
+		return true
+	}
+	if scope.group != nil && !scope.group.HasTalkgroupAccess(uint64(system.SystemRef), talkgroup.TalkgroupRef) {
+		return false
+	}
+	return scope.user.HasTalkgroupAccess(system.SystemRef, talkgroup.TalkgroupRef)
+}
+
+// delay returns the delay in minutes of the call for the user.
+func (scope userScope) delay(call *Call, defaultDelay uint) uint {
+	if scope.user == nil || call == nil || call.System == nil {
+		return defaultDelay
+	}
+
+	user := scope.user.delays(call)
+	group := scope.group.delays(call)
+	for level := range user {
+		if user[level] > 0 {
+			return user[level]
+		}
+		if group[level] > 0 {
+			return group[level]
+		}
+	}
+	return defaultDelay
+}
+
+// accountDelay returns the delay of the user's account, ignoring talkgroup
+// and system delays.
+func (scope userScope) accountDelay(defaultDelay uint) uint {
+	if scope.user != nil && scope.user.Delay > 0 {
+		return uint(scope.user.Delay)
+	}
+	if scope.group != nil && scope.group.Delay > 0 {
+		return uint(scope.group.Delay)
+	}
+	return defaultDelay
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"testing"
+)
+
+func newUserScopeTestController(groups ...*UserGroup) *Controller {
+	controller := &Controller{Tenants: NewTenants(), UserGroups: NewUserGroups()}
+	for _, group := range groups {
+		group.loadSystemAccess()
+		group.loadSystemDelays()
+		group.loadTalkgroupDelays()
+		controller.UserGroups.groups[group.Id] = group
+	}
+	return controller
+}
+
+func newUserScopeTestUser(groupId uint64, systems string, systemDelays string, talkgroupDelays string, delay int) *User {
+	user := &User{Id: 1, UserGroupId: groupId, Systems: systems, SystemDelays: systemDelays, TalkgroupDelays: talkgroupDelays, Delay: delay}
+	user.loadSystemScopes()
+	user.loadDelayMaps()
+	return user
+}
+
+func TestUserScopeDelayPrecedence(t *testing.T) {
+	system := &System{Id: 1, SystemRef: 10}
+	call := &Call{System: system, Talkgroup: &Talkgroup{Id: 2, TalkgroupRef: 100}}
+	group := &UserGroup{Id: 5, Delay: 4, SystemDelays: `{"10": 6}`, TalkgroupDelays: `{"10:100": 8}`}
+	controller := newUserScopeTestController(group)
+
+	cases := []struct {
+		name string
+		user *User
+		want uint
+	}{
+		{"no settings", newUserScopeTestUser(0, "", "", "", 0), 1},
+		{"user account", newUserScopeTestUser(0, "", "", "", 2), 2},
+		{"user system over account", newUserScopeTestUser(0, "", `{"10": 3}`, "", 2), 3},
+		{"user talkgroup over system", newUserScopeTestUser(0, "", `{"10": 3}`, `{"10:100": 9}`, 2), 9},
+		{"group talkgroup", newUserScopeTestUser(5, "", "", "", 0), 8},
+		{"user talkgroup over group talkgroup", newUserScopeTestUser(5, "", "", `{"10:100": 1}`, 0), 1},
+		{"group talkgroup over user system", newUserScopeTestUser(5, "", `{"10": 3}`, "", 2), 8},
+		{"missing group", newUserScopeTestUser(99, "", "", "", 0), 1},
+	}
+	for _, c := range cases {
+		if got := controller.userEffectiveDelay(c.user, call, 1); got != c.want {
+			t.Errorf("%s: delay %d, want %d", c.name, got, c.want)
+		}
+	}
+
+	// Without a talkgroup setting the group system delay comes before any
+	// account delay
+	other := &Call{System: system, Talkgroup: &Talkgroup{Id: 3, TalkgroupRef: 101}}
+	if got := controller.userEffectiveDelay(newUserScopeTestUser(5, "", "", "", 2), other, 1); got != 6 {
+		t.Errorf("group system delay %d, want 6", got)
+	}
+	group.SystemDelays = ""
+	group.loadSystemDelays()
+	if got := controller.userEffectiveDelay(newUserScopeTestUser(5, "", "", "", 2), other, 1); got != 2 {
+		t.Errorf("user account delay %d, want 2", got)
+	}
+	if got := controller.userScope(newUserScopeTestUser(5, "", "", "", 0)).accountDelay(1); got != 4 {
+		t.Errorf("group account delay %d, want 4", got)
+	}
+}
+
+func TestUserScopeAccess(t *testing.T) {
+	system := &System{Id: 1, SystemRef: 10}
+	other := &System{Id: 2, SystemRef: 20}
+	tg100 := &Talkgroup{Id: 1, TalkgroupRef: 100}
+	tg101 := &Talkgroup{Id: 2, TalkgroupRef: 101}
+	group := &UserGroup{Id: 5, SystemAccess: `[{"id": 10, "talkgroups": [100, 101]}]`}
+	controller := newUserScopeTestController(group)
+
+	allowed := func(user *User, system *System, talkgroup *Talkgroup) bool {
+		return controller.userHasAccess(user, &Call{System: system, Talkgroup: talkgroup})
+	}
+
+	everything := newUserScopeTestUser(0, "", "", "", 0)
+	if !allowed(everything, system, tg100) || !allowed(everything, other, tg101) {
+		t.Fatalf("user without scopes was restricted")
+	}
+
+	limited := newUserScopeTestUser(0, `[{"id": 10, "talkgroups": [101]}]`, "", "", 0)
+	if allowed(limited, system, tg100) || !allowed(limited, system, tg101) || allowed(limited, other, tg101) {
+		t.Fatalf("user scopes not applied")
+	}
+	if !allowed(limited, system, nil) || allowed(limited, other, nil) {
+		t.Fatalf("system access without a talkgroup not applied")
+	}
+
+	// The group bounds the user, who can narrow it further
+	member := newUserScopeTestUser(5, "*", "", "", 0)
+	if !allowed(member, system, tg100) || allowed(member, other, tg100) {
+		t.Fatalf("group scopes not applied")
+	}
+	narrowed := newUserScopeTestUser(5, `[{"id": 10, "talkgroups": [100]}, {"id": 20}]`, "", "", 0)
+	if !allowed(narrowed, system, tg100) || allowed(narrowed, system, tg101) || allowed(narrowed, other, tg100) {
+		t.Fatalf("user narrowing within the group not applied")
+	}
+
+	// Users of a tenant never hear other tenants' systems
+	controller.Tenants.tenants[1] = &Tenant{Id: 1, SystemIds: []uint64{2}, UserIds: []uint64{1}}
+	if allowed(everything, system, tg100) || !allowed(everything, other, tg100) {
+		t.Fatalf("tenant scope not applied")
+	}
+}
+
+func TestGetScopedSystemsUsesUserScope(t *testing.T) {
+	systems := &Systems{List: []*System{
+		{Id: 1, SystemRef: 10, Label: "A", Units: NewUnits(), Talkgroups: &Talkgroups{List: []*Talkgroup{{Id: 1, TalkgroupRef: 100, TagId: 1}, {Id: 2, TalkgroupRef: 101, TagId: 1}}}},
+		{Id: 2, SystemRef: 20, Label: "B", Units: NewUnits(), Talkgroups: &Talkgroups{List: []*Talkgroup{{Id: 3, TalkgroupRef: 200, TagId: 1}}}},
+	}}
+	group := &UserGroup{Id: 5, SystemAccess: `[{"id": 10, "talkgroups": "*"}]`}
+	controller := newUserScopeTestController(group)
+	client := &Client{Controller: controller, User: newUserScopeTestUser(5, `[{"id": 10, "talkgroups": [101]}, {"id": 20, "talkgroups": "*"}]`, "", "", 0)}
+
+	scoped := systems.GetScopedSystems(client, &Groups{}, &Tags{List: []*Tag{{Id: 1, Label: "Fire"}}}, false)
+	if len(scoped) != 1 || scoped[0]["systemRef"] != uint(10) {
+		t.Fatalf("scoped systems %v", scoped)
+	}
+	talkgroups := scoped[0]["talkgroups"].(TalkgroupsMap)
+	if len(talkgroups) != 1 || talkgroups[0]["talkgroupRef"] != uint(101) {
+		t.Fatalf("scoped talkgroups %v", talkgroups)
+	}
+}
@@ -0,0 +1,301 @@
+// Copyright (C) 2026 Thinline Dynamic Solutions
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// userWebhooksMax is the number of webhooks a user may register.
+const userWebhooksMax = 10
+
+// UserWebhook is a webhook registered by a user from the API. It is
+// delivered like an admin webhook, but only for calls the user has access
+// to, after the user's delay, and only for system alerts the user would be
+// pushed.
+type UserWebhook struct {
+	Id        uint64
+	UserId    uint64
+	Webhook   Webhook
+	CreatedAt int64
+}
+
+type UserWebhooks struct {
+	mutex    sync.RWMutex
+	webhooks map[uint64]*UserWebhook
+}
+
+func NewUserWebhooks() *UserWebhooks {
+	return &UserWebhooks{
+		webhooks: map[uint64]*UserWebhook{},
+	}
+}
+
+func (userWebhooks *UserWebhooks) Load(db *Database) error {
+	userWebhooks.mutex.Lock()
+	defer userWebhooks.mutex.Unlock()
+
+	rows, err := db.Sql.Query(`SELECT "userWebhookId", "userId", "config", "createdAt" FROM "userWebhooks"`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	userWebhooks.webhooks = map[uint64]*UserWebhook{}
+	for rows.Next() {
+		userWebhook := &UserWebhook{}
+		var config string
+		if err := rows.Scan(&userWebhook.Id, &userWebhook.UserId, &config, &userWebhook.CreatedAt); err != nil {
+			continue
+		}
+		if err := json.Unmarshal([]byte(config), &userWebhook.Webhook); err != nil {
+			continue
+		}
+		userWebhook.Webhook.Id = uint(userWebhook.Id)
+		userWebhooks.webhooks[userWebhook.Id] = userWebhook
+	}
+
+	return rows.Err()
+}
+
+// ForUser returns the user's webhooks, oldest first.
+func (userWebhooks *UserWebhooks) ForUser(userId uint64) []UserWebhook {
+	userWebhooks.mutex.RLock()
+	defer userWebhooks.mutex.RUnlock()
+
+	list := []UserWebhook{}
+	for _, userWebhook := range userWebhooks.webhooks {
+		if userWebhook.UserId == userId {
+			list = append(list, *userWebhook)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Id < list[j].Id })
+	return list
+}
+
+func (userWebhooks *UserWebhooks) all() []UserWebhook {
+	userWebhooks.mutex.RLock()
+	defer userWebhooks.mutex.RUnlock()
+
+	list := make([]UserWebhook, 0, len(userWebhooks.webhooks))
+	for _, userWebhook := range userWebhooks.webhooks {
+		list = append(list, *userWebhook)
+	}
+	return list
+}
+
+// Save inserts the webhook when Id is 0 and updates it otherwise.
+func (userWebhooks *UserWebhooks) Save(userWebhook *UserWebhook, db *Database) error {
+	userWebhooks.mutex.Lock()
+	defer userWebhooks.mutex.Unlock()
+
+	config, err := json.Marshal(userWebhook.Webhook)
+	if err != nil {
+		return err
+	}
+
+	if userWebhook.Id == 0 {
+		userWebhook.CreatedAt = time.Now().UnixMilli()
+		if err := db.Sql.QueryRow(
+			`INSERT INTO "userWebhooks" ("userId", "config", "createdAt") VALUES ($1, $2, $3) RETURNING "userWebhookId"`,
+			userWebhook.UserId, string(config), userWebhook.CreatedAt,
+		).Scan(&userWebhook.Id); err != nil {
+			return err
+		}
+	} else if _, err := db.Sql.Exec(
+		`UPDATE "userWebhooks" SET "config" = $1 WHERE "userWebhookId" = $2 AND "userId" = $3`,
+		string(config), userWebhook.Id, userWebhook.UserId,
+	); err != nil {
+		return err
+	}
+
+	userWebhook.Webhook.Id = uint(userWebhook.Id)
+	saved := *userWebhook
+	userWebhooks.webhooks[userWebhook.Id] = &saved
+	return nil
+}
+
+func (userWebhooks *UserWebhooks) Delete(id uint64, db *Database) error {
+	userWebhooks.mutex.Lock()
+	defer userWebhooks.mutex.Unlock()
+
+	if _, err := db.Sql.Exec(`DELETE FROM "userWebhooks" WHERE "userWebhookId" = $1`, id); err != nil {
+		return err
+	}
+	delete(userWebhooks.webhooks, id)
+	return nil
+}
+
+// dispatchUserWebhooks sends the event to the user webhooks that cover it.
+func (controller *Controller) dispatchUserWebhooks(event *webhookEvent) {
+	for _, userWebhook := range controller.UserWebhooks.all() {
+		webhook := userWebhook.Webhook
+		if !webhook.Covers(event.Name, event.Call) {
+			continue
+		}
+
+		user := controller.Users.GetUserById(userWebhook.UserId)
+		if user == nil {
+			continue
+		}
+
+		// System alerts follow SendSystemAlertNotification: manual alerts go
+		// to everyone, health alerts to system admins only
+		if event.Call == nil {
+			if event.SystemAlert == nil || (event.SystemAlert.AlertType != "manual" && !user.SystemAdmin) {
+				continue
+			}
+			controller.sendWebhook(event, &webhook, 0)
+			continue
+		}
+
+		if !controller.userHasAccess(user, event.Call) || controller.inQuietHours(user.Id, event.Call, time.Now()) {
+			continue
+		}
+
+		var delay time.Duration
+		if effectiveDelay := controller.userEffectiveDelay(user, event.Call, controller.Options.DefaultSystemDelay); effectiveDelay > 0 {
+			delay = time.Until(event.Call.Timestamp.Add(time.Duration(effectiveDelay) * time.Minute))
+		}
+
+		controller.sendWebhook(event, &webhook, delay)
+	}
+}
+
+// userWebhookResponse is the API representation; the secret is write-only.
+func userWebhookResponse(userWebhook UserWebhook) map[string]any {
+	webhook := userWebhook.Webhook
+	return map[string]any{
+		"id":          userWebhook.Id,
+		"label":       webhook.Label,
+		"enabled":     webhook.Enabled,
+		"url":         webhook.URL,
+		"hasSecret":   webhook.Secret != "",
+		"events":      webhook.Events,
+		"contentType": webhook.ContentType,
+		"headers":     webhook.Headers,
+		"template":    webhook.Template,
+		"talkgroups":  webhook.Talkgroups,
+		"createdAt":   userWebhook.CreatedAt,
+	}
+}
+
+// UserWebhooksHandler handles GET/POST /api/webhooks and PUT/DELETE
+// /api/webhooks/{id} for the authenticated user's webhooks.
+func (api *Api) UserWebhooksHandler(w http.ResponseWriter, r *http.Request) {
+	client := api.getClient(r)
+	if client == nil || client.User == nil {
+		api.exitWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	userId := client.User.Id
+
+	var id uint64
+	if path := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/webhooks"), "/"); path != "" {
+		v, err := strconv.ParseUint(path, 10, 64)
+		if err != nil || v == 0 {
+			api.exitWithError(w, http.StatusBadRequest, "invalid webhook id")
+			return
+		}
+		id = v
+	}
+
+	// owned returns the user's webhook with the path id
+	owned := func() (UserWebhook, bool) {
+		for _, userWebhook := range api.Controller.UserWebhooks.ForUser(userId) {
+			if userWebhook.Id == id {
+				return userWebhook, true
+			}
+		}
+		return UserWebhook{}, false
+	}
+
+	writeJSON := func(v any) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(v)
+	}
+
+	switch {
+	case r.Method == http.MethodGet && id == 0:
+		list := []map[string]any{}
+		for _, userWebhook := range api.Controller.UserWebhooks.ForUser(userId) {
+			list = append(list, userWebhookResponse(userWebhook))
+		}
+		writeJSON(list)
+
+	case (r.Method == http.MethodPost && id == 0) || (r.Method == http.MethodPut && id > 0):
+		var m map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&m); err != nil {
+			api.exitWithError(w, http.StatusBadRequest, fmt.Sprintf("invalid request body: %v", err))
+			return
+		}
+
+		userWebhook := UserWebhook{UserId: userId}
+		if id > 0 {
+			existing, ok := owned()
+			if !ok {
+				api.exitWithError(w, http.StatusNotFound, "webhook not found")
+				return
+			}
+			userWebhook = existing
+		} else if len(api.Controller.UserWebhooks.ForUser(userId)) >= userWebhooksMax {
+			api.exitWithError(w, http.StatusBadRequest, fmt.Sprintf("at most %d webhooks per user", userWebhooksMax))
+			return
+		}
+
+		// Keep the stored secret unless a new one is sent
+		secret := userWebhook.Webhook.Secret
+		webhook := webhookFromMap(m)
+		if _, ok := m["secret"]; !ok {
+			webhook.Secret = secret
+		}
+		if _, ok := m["enabled"]; !ok {
+			webhook.Enabled = true
+		}
+		if err := validateWebhook(&webhook); err != nil {
+			api.exitWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		userWebhook.Webhook = webhook
+
+		if err := api.Controller.UserWebhooks.Save(&userWebhook, api.Controller.Database); err != nil {
+			api.exitWithError(w, http.StatusInternalServerError, fmt.Sprintf("failed to save webhook: %v", err))
+			return
+		}
+		writeJSON(userWebhookResponse(userWebhook))
+
+	case r.Method == http.MethodDelete && id > 0:
+		if _, ok := owned(); !ok {
+			api.exitWithError(w, http.StatusNotFound, "webhook not found")
+			return
+		}
+		if err := api.Controller.UserWebhooks.Delete(id, api.Controller.Database); err != nil {
+			api.exitWithError(w, http.StatusInternalServerError, fmt.Sprintf("failed to delete webhook: %v", err))
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
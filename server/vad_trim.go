@@ -0,0 +1,137 @@
+// Copyright (C) 2026 Thinline Dynamic Solutions
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>
+
+package main
+
+import (
+	"fmt"
+)
+
+const (
+	vadTrimDefaultAggressiveness = 2
+	vadTrimDefaultPaddingMs      = 200
+
+	// Calls are left alone when trimming would save less than this.
+	vadTrimMinSeconds = 0.25
+)
+
+// vadTrimLevels are the frame thresholds per aggressiveness: 1 trims silence
+// only, 2 also trims squelch tails and 3 trims anything that is not speech
+// like the speech gate.
+var vadTrimLevels = [...]struct {
+	minLevel    float64 // dBFS
+	maxFlatness float64
+}{
+	{-55, 1},
+	{-50, 0.45},
+	{speechGateDefaultMinLevel, 0.35},
+}
+
+// VADTrimConfig trims leading and trailing silence and squelch tails from
+// uploaded audio before it is stored. The untrimmed length is kept as the
+// call's original duration.
+type VADTrimConfig struct {
+	Enabled        bool `json:"enabled"`
+	Aggressiveness uint `json:"aggressiveness,omitempty"` // 1 to 3, default 2
+	PaddingMs      uint `json:"paddingMs,omitempty"`      // kept around the speech, default 200
+}
+
+// thresholds returns the frame thresholds and the padding in seconds.
+func (config VADTrimConfig) thresholds() (float64, float64, float64) {
+	aggressiveness := config.Aggressiveness
+	if aggressiveness == 0 {
+		aggressiveness = vadTrimDefaultAggressiveness
+	}
+	if aggressiveness > uint(len(vadTrimLevels)) {
+		aggressiveness = uint(len(vadTrimLevels))
+	}
+	paddingMs := config.PaddingMs
+	if paddingMs == 0 {
+		paddingMs = vadTrimDefaultPaddingMs
+	}
+	level := vadTrimLevels[aggressiveness-1]
+	return level.minLevel, level.maxFlatness, float64(paddingMs) / 1000
+}
+
+// vadTrimBounds returns the start and end in seconds of the audio to keep
+// from 8 kHz mono s16le samples, padding included. Audio without any voiced
+// frame is kept whole.
+func vadTrimBounds(pcm []byte, minLevel float64, maxFlatness float64, padding float64) (float64, float64) {
+	total := float64(len(pcm)/2) / energySampleHz
+	frames := speechFrames(pcm, minLevel, maxFlatness)
+
+	first, last := -1, -1
+	for i, voiced := range frames {
+		if voiced {
+			if first < 0 {
+				first = i
+			}
+			last = i
+		}
+	}
+	if first < 0 {
+		return 0, total
+	}
+
+	frameSeconds := float64(speechFrameSamples) / energySampleHz
+	start := float64(first)*frameSeconds - padding
+	if start < 0 {
+		start = 0
+	}
+	end := float64(last+1)*frameSeconds + padding
+	if end > total {
+		end = total
+	}
+	return start, end
+}
+
+// trimSilence trims the call audio to its voiced part. Unit offsets are moved
+// with the audio and call.OriginalDuration is set when the audio is trimmed.
+func (controller *Controller) trimSilence(call *Call) {
+	config := controller.Options.VADTrimConfig
+	if !config.Enabled || len(call.Audio) == 0 {
+		return
+	}
+
+	pcm, err := decodeMonoPCM(call.Audio, call.AudioMime)
+	if err != nil {
+		controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("silence trim: %v", err))
+		return
+	}
+
+	minLevel, maxFlatness, padding := config.thresholds()
+	total := float64(len(pcm)/2) / energySampleHz
+	start, end := vadTrimBounds(pcm, minLevel, maxFlatness, padding)
+	if start+total-end < vadTrimMinSeconds {
+		return
+	}
+
+	trimmed, err := controller.FFMpeg.Trim(call.Audio, call.AudioMime, start, end-start)
+	if err != nil {
+		controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("silence trim: %v", err))
+		return
+	}
+
+	call.Audio = trimmed
+	call.OriginalDuration = total
+	call.Duration = end - start
+	for i := range call.Units {
+		offset := call.Units[i].Offset - float32(start)
+		if offset < 0 {
+			offset = 0
+		}
+		call.Units[i].Offset = offset
+	}
+}
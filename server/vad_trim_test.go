@@ -0,0 +1,52 @@
+package main
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestVADTrimBounds(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+
+	// 1s of near silence, 1s of squelch tail static, 2s of voice, 1s of near silence
+	samples := make([]int16, 0, energySampleHz*5)
+	for i := 0; i < energySampleHz; i++ {
+		samples = append(samples, int16(random.NormFloat64()*2))
+	}
+	for i := 0; i < energySampleHz; i++ {
+		samples = append(samples, int16(random.NormFloat64()*4000))
+	}
+	for i := 0; i < energySampleHz*2; i++ {
+		var v float64
+		for harmonic := 1; harmonic <= 20; harmonic++ {
+			v += math.Sin(2*math.Pi*150*float64(harmonic)*float64(i)/energySampleHz) / float64(harmonic)
+		}
+		samples = append(samples, int16(4000*v+random.NormFloat64()*100))
+	}
+	for i := 0; i < energySampleHz; i++ {
+		samples = append(samples, int16(random.NormFloat64()*2))
+	}
+	pcm := pcmFromSamples(samples)
+
+	near := func(got float64, want float64) bool {
+		return math.Abs(got-want) <= 0.1
+	}
+
+	minLevel, maxFlatness, padding := VADTrimConfig{Aggressiveness: 1}.thresholds()
+	start, end := vadTrimBounds(pcm, minLevel, maxFlatness, padding)
+	if !near(start, 0.8) || !near(end, 4.2) {
+		t.Fatalf("aggressiveness 1: expected 0.8s to 4.2s, got %.2fs to %.2fs", start, end)
+	}
+
+	minLevel, maxFlatness, padding = VADTrimConfig{}.thresholds()
+	start, end = vadTrimBounds(pcm, minLevel, maxFlatness, padding)
+	if !near(start, 1.8) || !near(end, 4.2) {
+		t.Fatalf("aggressiveness 2: expected 1.8s to 4.2s, got %.2fs to %.2fs", start, end)
+	}
+
+	silence := pcmFromSamples(make([]int16, energySampleHz*2))
+	if start, end := vadTrimBounds(silence, minLevel, maxFlatness, padding); start != 0 || end != 2 {
+		t.Fatalf("expected silence to be kept whole, got %.2fs to %.2fs", start, end)
+	}
+}
@@ -18,3 +18,12 @@ package main
 
 // CalVer-style release id (YY.MM.build).
 const Version = "26.06.15"
+
+// APIVersion is the schema version of the API and websocket messages, raised
+// on every breaking change. Clients announce the version they were built
+// against in the X-API-Version header; MinAPIVersion is the oldest one the
+// server still answers.
+const (
+	APIVersion    = 2
+	MinAPIVersion = 1
+)
@@ -0,0 +1,433 @@
+// Copyright (C) 2026 Thinline Dynamic Solutions
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	weatherDefaultPoll   = 5 * time.Minute
+	weatherMinPoll       = time.Minute
+	weatherRequestTimout = 20 * time.Second
+	weatherRetention     = 7 * 24 * time.Hour
+)
+
+// weatherAPIURL is the National Weather Service API, a variable for tests.
+var weatherAPIURL = "https://api.weather.gov"
+
+// weatherDefaultSeverities are the CAP severities recorded by default.
+var weatherDefaultSeverities = []string{"Extreme", "Severe"}
+
+// WeatherAlertConfig records National Weather Service alerts for the watched
+// areas as system alerts, and optionally pushes them to users whose zip code
+// is in an affected zone.
+type WeatherAlertConfig struct {
+	Enabled           bool     `json:"enabled"`
+	Areas             []string `json:"areas"`             // NWS UGC codes ("ILC167", "ILZ051") or counties ("Sangamon, IL")
+	SiteCountiesState string   `json:"siteCountiesState"` // when set, the Radio Reference site counties are watched in this state
+	Severities        []string `json:"severities"`        // CAP severities recorded (default Extreme and Severe)
+	PollMinutes       uint     `json:"pollMinutes"`       // default 5
+	Contact           string   `json:"contact"`           // email or URL sent in the User-Agent, as the NWS asks
+	NotifyUsers       bool     `json:"notifyUsers"`       // push to users whose zip code is in an affected zone
+}
+
+func (config WeatherAlertConfig) pollInterval() time.Duration {
+	if config.PollMinutes == 0 {
+		return weatherDefaultPoll
+	}
+	if interval := time.Duration(config.PollMinutes) * time.Minute; interval > weatherMinPoll {
+		return interval
+	}
+	return weatherMinPoll
+}
+
+func (config WeatherAlertConfig) severityAllowed(severity string) bool {
+	severities := config.Severities
+	if len(severities) == 0 {
+		severities = weatherDefaultSeverities
+	}
+	for _, s := range severities {
+		if strings.EqualFold(s, severity) {
+			return true
+		}
+	}
+	return false
+}
+
+// weatherAlert is a CAP alert from the NWS API.
+type weatherAlert struct {
+	Id          string `json:"id"`
+	AreaDesc    string `json:"areaDesc"`
+	MessageType string `json:"messageType"`
+	Severity    string `json:"severity"`
+	Urgency     string `json:"urgency"`
+	Event       string `json:"event"`
+	Headline    string `json:"headline"`
+	Description string `json:"description"`
+	Instruction string `json:"instruction"`
+	Expires     string `json:"expires"`
+	Ends        string `json:"ends"`
+	Geocode     struct {
+		UGC []string `json:"UGC"`
+	} `json:"geocode"`
+}
+
+// weatherWatch is the set of areas watched.
+type weatherWatch struct {
+	zones    map[string]bool // UGC codes
+	counties map[string]bool // "SANGAMON, IL"
+	states   map[string]bool
+}
+
+var weatherUGCPattern = regexp.MustCompile(`^[A-Z]{2}[CZ]\d{3}$`)
+
+// weatherCounty normalizes "Sangamon County, il" to "SANGAMON, IL".
+func weatherCounty(name string, state string) string {
+	name = strings.ToUpper(strings.TrimSpace(name))
+	for _, suffix := range []string{" COUNTY", " PARISH", " BOROUGH"} {
+		name = strings.TrimSuffix(name, suffix)
+	}
+	return name + ", " + strings.ToUpper(strings.TrimSpace(state))
+}
+
+// newWeatherWatch reads the configured areas and the counties of the sites.
+func newWeatherWatch(config WeatherAlertConfig, siteCounties []string) *weatherWatch {
+	watch := &weatherWatch{zones: map[string]bool{}, counties: map[string]bool{}, states: map[string]bool{}}
+	for _, area := range config.Areas {
+		area = strings.ToUpper(strings.TrimSpace(area))
+		if weatherUGCPattern.MatchString(area) {
+			watch.zones[area] = true
+			watch.states[area[:2]] = true
+		} else if name, state, ok := strings.Cut(area, ","); ok && len(strings.TrimSpace(state)) == 2 {
+			watch.counties[weatherCounty(name, state)] = true
+			watch.states[strings.TrimSpace(state)] = true
+		}
+	}
+	if state := strings.ToUpper(strings.TrimSpace(config.SiteCountiesState)); len(state) == 2 {
+		for _, county := range siteCounties {
+			// Counties Radio Reference did not name are listed as "County 123"
+			if county == "" || strings.HasPrefix(county, "County ") {
+				continue
+			}
+			watch.counties[weatherCounty(county, state)] = true
+			watch.states[state] = true
+		}
+	}
+	return watch
+}
+
+// matches reports whether an alert covers a watched area.
+func (watch *weatherWatch) matches(alert *weatherAlert) bool {
+	for _, ugc := range alert.Geocode.UGC {
+		if watch.zones[strings.ToUpper(ugc)] {
+			return true
+		}
+	}
+	for _, area := range strings.Split(alert.AreaDesc, ";") {
+		if name, state, ok := strings.Cut(area, ","); ok && watch.counties[weatherCounty(name, state)] {
+			return true
+		}
+	}
+	return false
+}
+
+// weatherSystemAlertSeverity maps a CAP severity to a system alert severity.
+func weatherSystemAlertSeverity(severity string) string {
+	switch strings.ToLower(severity) {
+	case "extreme":
+		return "critical"
+	case "severe":
+		return "error"
+	case "moderate":
+		return "warning"
+	default:
+		return "info"
+	}
+}
+
+// WeatherAlerts polls the NWS API for active alerts.
+type WeatherAlerts struct {
+	controller *Controller
+	client     *http.Client
+	stopChan   chan struct{}
+
+	mutex     sync.Mutex
+	zipZones  map[string][]string // zip code -> UGC codes, empty when it could not be located
+	lastError string
+}
+
+func NewWeatherAlerts(controller *Controller) *WeatherAlerts {
+	return &WeatherAlerts{
+		controller: controller,
+		client:     &http.Client{Timeout: weatherRequestTimout},
+		stopChan:   make(chan struct{}),
+		zipZones:   map[string][]string{},
+	}
+}
+
+// Start runs the polling loop. The configuration is read on every poll so
+// changes in the admin UI apply without a restart.
+func (weather *WeatherAlerts) Start() {
+	go func() {
+		timer := time.NewTimer(weatherMinPoll)
+		defer timer.Stop()
+		for {
+			select {
+			case <-timer.C:
+				config := weather.controller.Options.WeatherAlertConfig
+				if config.Enabled {
+					weather.poll(config)
+				}
+				timer.Reset(config.pollInterval())
+			case <-weather.stopChan:
+				return
+			}
+		}
+	}()
+}
+
+// Stop signals the background goroutine to exit.
+func (weather *WeatherAlerts) Stop() {
+	select {
+	case <-weather.stopChan:
+	default:
+		close(weather.stopChan)
+	}
+}
+
+// siteCounties returns the counties of the sites of every system.
+func (weather *WeatherAlerts) siteCounties() []string {
+	counties := []string{}
+	weather.controller.Systems.mutex.RLock()
+	defer weather.controller.Systems.mutex.RUnlock()
+	for _, system := range weather.controller.Systems.List {
+		system.Sites.mutex.Lock()
+		for _, site := range system.Sites.List {
+			if site.County != "" {
+				counties = append(counties, site.County)
+			}
+		}
+		system.Sites.mutex.Unlock()
+	}
+	return counties
+}
+
+// get fetches a NWS API path and decodes the JSON response.
+func (weather *WeatherAlerts) get(path string, contact string, response any) error {
+	req, err := http.NewRequest(http.MethodGet, weatherAPIURL+path, nil)
+	if err != nil {
+		return err
+	}
+	userAgent := geocodeUserAgent
+	if contact != "" {
+		userAgent = fmt.Sprintf("ThinLineRadio (%s)", contact)
+	}
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set("Accept", "application/geo+json")
+
+	resp, err := weather.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return json.Unmarshal(body, response)
+}
+
+// poll records the new alerts for the watched areas.
+func (weather *WeatherAlerts) poll(config WeatherAlertConfig) {
+	watch := newWeatherWatch(config, weather.siteCounties())
+	if len(watch.states) == 0 {
+		return
+	}
+	states := make([]string, 0, len(watch.states))
+	for state := range watch.states {
+		states = append(states, state)
+	}
+	sort.Strings(states)
+
+	var response struct {
+		Features []struct {
+			Properties weatherAlert `json:"properties"`
+		} `json:"features"`
+	}
+	err := weather.get("/alerts/active?status=actual&area="+url.QueryEscape(strings.Join(states, ",")), config.Contact, &response)
+
+	// Log when polling starts or stops failing, not on every poll
+	weather.mutex.Lock()
+	lastError := weather.lastError
+	weather.lastError = ""
+	if err != nil {
+		weather.lastError = err.Error()
+	}
+	weather.mutex.Unlock()
+	if err != nil {
+		if lastError == "" {
+			weather.controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("weather alerts: polling failed: %v", err))
+		}
+		return
+	}
+	if lastError != "" {
+		weather.controller.Logs.LogEvent(LogLevelInfo, "weather alerts: polling recovered")
+	}
+
+	for i := range response.Features {
+		alert := &response.Features[i].Properties
+		if alert.Id == "" || alert.MessageType == "Cancel" || !config.severityAllowed(alert.Severity) || !watch.matches(alert) {
+			continue
+		}
+		if !weather.record(alert) {
+			continue
+		}
+
+		title := alert.Event
+		message := alert.Headline
+		if message == "" {
+			message = alert.AreaDesc
+		}
+		data := &SystemAlertData{WeatherAlertId: alert.Id, Areas: alert.AreaDesc}
+		if err := weather.controller.CreateSystemAlert("weather", weatherSystemAlertSeverity(alert.Severity), title, message, data, 0); err != nil {
+			weather.controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("weather alerts: %v", err))
+			continue
+		}
+		if config.NotifyUsers {
+			go weather.notifyUsers(alert, config)
+		}
+	}
+
+	if _, err := weather.controller.Database.Sql.Exec(`DELETE FROM "weatherAlerts" WHERE "expiresAt" < $1`, time.Now().Add(-weatherRetention).UnixMilli()); err != nil {
+		weather.controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("weather alerts: cleanup failed: %v", err))
+	}
+}
+
+// record stores an alert and reports whether it is new.
+func (weather *WeatherAlerts) record(alert *weatherAlert) bool {
+	expires := alert.Ends
+	if expires == "" {
+		expires = alert.Expires
+	}
+	expiresAt := time.Now().Add(24 * time.Hour)
+	if t, err := time.Parse(time.RFC3339, expires); err == nil {
+		expiresAt = t
+	}
+
+	result, err := weather.controller.Database.Sql.Exec(
+		`INSERT INTO "weatherAlerts" ("alertId", "event", "expiresAt", "createdAt") VALUES ($1, $2, $3, $4) ON CONFLICT DO NOTHING`,
+		alert.Id, alert.Event, expiresAt.UnixMilli(), time.Now().UnixMilli(),
+	)
+	if err != nil {
+		weather.controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("weather alerts: failed to record %s: %v", alert.Id, err))
+		return false
+	}
+	affected, err := result.RowsAffected()
+	return err == nil && affected > 0
+}
+
+// zonesForZip returns the NWS county and forecast zones of a zip code. The
+// zip code is located with the geocoder, then looked up on the NWS API.
+// Results are cached, failures included.
+func (weather *WeatherAlerts) zonesForZip(zip string, config WeatherAlertConfig) []string {
+	weather.mutex.Lock()
+	zones, ok := weather.zipZones[zip]
+	weather.mutex.Unlock()
+	if ok {
+		return zones
+	}
+
+	zones = []string{}
+	if geocoder, err := newGeocoder(weather.controller.Options.GeocodingConfig); err == nil {
+		if location, err := geocoder.Geocode(zip + ", USA"); err == nil && location != nil {
+			var point struct {
+				Properties struct {
+					County          string `json:"county"`
+					ForecastZone    string `json:"forecastZone"`
+					FireWeatherZone string `json:"fireWeatherZone"`
+				} `json:"properties"`
+			}
+			if err := weather.get(fmt.Sprintf("/points/%.4f,%.4f", location.Latitude, location.Longitude), config.Contact, &point); err == nil {
+				for _, zone := range []string{point.Properties.County, point.Properties.ForecastZone, point.Properties.FireWeatherZone} {
+					if i := strings.LastIndex(zone, "/"); i >= 0 && i < len(zone)-1 {
+						zones = append(zones, strings.ToUpper(zone[i+1:]))
+					}
+				}
+			}
+		}
+	}
+
+	weather.mutex.Lock()
+	weather.zipZones[zip] = zones
+	weather.mutex.Unlock()
+	return zones
+}
+
+// notifyUsers pushes an alert to the verified users whose zip code is in one
+// of its zones.
+func (weather *WeatherAlerts) notifyUsers(alert *weatherAlert, config WeatherAlertConfig) {
+	affected := map[string]bool{}
+	for _, ugc := range alert.Geocode.UGC {
+		affected[strings.ToUpper(ugc)] = true
+	}
+
+	userIds := []uint64{}
+	matches := map[string]bool{}
+	for _, user := range weather.controller.Users.GetAllUsers() {
+		zip := strings.TrimSpace(user.ZipCode)
+		if !user.Verified || len(zip) < 5 {
+			continue
+		}
+		zip = zip[:5]
+		match, ok := matches[zip]
+		if !ok {
+			for _, zone := range weather.zonesForZip(zip, config) {
+				if affected[zone] {
+					match = true
+					break
+				}
+			}
+			matches[zip] = match
+		}
+		if match {
+			userIds = append(userIds, user.Id)
+		}
+	}
+	if len(userIds) == 0 {
+		return
+	}
+
+	message := alert.Headline
+	if message == "" {
+		message = alert.AreaDesc
+	}
+	title := fmt.Sprintf("%s %s", systemAlertIcon(weatherSystemAlertSeverity(alert.Severity)), alert.Event)
+	weather.controller.pushSystemAlert(userIds, title, alert.Event, message, "weather", "users in the affected zones")
+}
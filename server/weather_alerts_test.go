@@ -0,0 +1,99 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestWeatherWatchMatches(t *testing.T) {
+	config := WeatherAlertConfig{
+		Areas:             []string{"ilz051", "Cook, IL", "not an area"},
+		SiteCountiesState: "il",
+	}
+	watch := newWeatherWatch(config, []string{"Sangamon", "County 12", ""})
+
+	if !watch.states["IL"] || len(watch.states) != 1 {
+		t.Fatalf("states = %v, want IL", watch.states)
+	}
+
+	tests := []struct {
+		name  string
+		alert weatherAlert
+		want  bool
+	}{
+		{"zone", weatherAlert{AreaDesc: "Logan", Geocode: struct {
+			UGC []string `json:"UGC"`
+		}{UGC: []string{"ILZ050", "ILZ051"}}}, true},
+		{"configured county", weatherAlert{AreaDesc: "Lake, IL; Cook County, IL"}, true},
+		{"site county", weatherAlert{AreaDesc: "Sangamon, IL"}, true},
+		{"other state", weatherAlert{AreaDesc: "Sangamon, MO"}, false},
+		{"unnamed site county", weatherAlert{AreaDesc: "County 12, IL"}, false},
+		{"no match", weatherAlert{AreaDesc: "Macon, IL"}, false},
+	}
+	for _, test := range tests {
+		if got := watch.matches(&test.alert); got != test.want {
+			t.Fatalf("%s: matches = %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestWeatherAlertConfig(t *testing.T) {
+	config := WeatherAlertConfig{}
+	if !config.severityAllowed("severe") || !config.severityAllowed("Extreme") || config.severityAllowed("Moderate") {
+		t.Fatalf("default severities are Extreme and Severe")
+	}
+	if config.pollInterval() != weatherDefaultPoll {
+		t.Fatalf("pollInterval = %v, want %v", config.pollInterval(), weatherDefaultPoll)
+	}
+
+	config = WeatherAlertConfig{Severities: []string{"Moderate"}, PollMinutes: 15}
+	if !config.severityAllowed("moderate") || config.severityAllowed("Extreme") {
+		t.Fatalf("configured severities not applied")
+	}
+	if config.pollInterval() != 15*time.Minute {
+		t.Fatalf("pollInterval = %v, want 15m", config.pollInterval())
+	}
+
+	if got := weatherSystemAlertSeverity("Extreme"); got != "critical" {
+		t.Fatalf("Extreme maps to %q, want critical", got)
+	}
+	if got := weatherSystemAlertSeverity("Unknown"); got != "info" {
+		t.Fatalf("Unknown maps to %q, want info", got)
+	}
+}
+
+func TestWeatherAlertsGet(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("User-Agent") != "ThinLineRadio (ops@example.com)" {
+			t.Errorf("User-Agent = %q", r.Header.Get("User-Agent"))
+		}
+		if r.URL.Query().Get("area") != "IL" {
+			t.Errorf("area = %q, want IL", r.URL.Query().Get("area"))
+		}
+		w.Write([]byte(`{"features":[{"properties":{"id":"urn:oid:1","areaDesc":"Sangamon, IL","severity":"Severe","event":"Tornado Warning","geocode":{"UGC":["ILC167"]}}}]}`))
+	}))
+	defer server.Close()
+
+	previous := weatherAPIURL
+	weatherAPIURL = server.URL
+	defer func() { weatherAPIURL = previous }()
+
+	var response struct {
+		Features []struct {
+			Properties weatherAlert `json:"properties"`
+		} `json:"features"`
+	}
+	weather := NewWeatherAlerts(nil)
+	if err := weather.get("/alerts/active?area=IL", "ops@example.com", &response); err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if len(response.Features) != 1 {
+		t.Fatalf("features = %d, want 1", len(response.Features))
+	}
+	alert := response.Features[0].Properties
+	if alert.Event != "Tornado Warning" || len(alert.Geocode.UGC) != 1 || alert.Geocode.UGC[0] != "ILC167" {
+		t.Fatalf("alert = %+v", alert)
+	}
+}
@@ -0,0 +1,623 @@
+// Copyright (C) 2026 Thinline Dynamic Solutions
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>
+
+package main
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Webhook posts calls, alerts and system events to an external system such
+// as a CAD, in the shape that system expects. The body is rendered from
+// Template, where {{name}} is replaced by a field escaped for the content type
+// and {{{name}}} by the raw value. An empty template sends every field as a
+// flat JSON object.
+//
+// With a Secret, deliveries are signed with HMAC-SHA256 over the timestamp
+// and body, so the receiver can tell them apart from forged requests.
+type Webhook struct {
+	Id          uint               `json:"id"`
+	Label       string             `json:"label"`
+	Enabled     bool               `json:"enabled"`
+	URL         string             `json:"url"`
+	Secret      string             `json:"secret,omitempty"`
+	Events      []string           `json:"events"`      // see webhookEvents
+	ContentType string             `json:"contentType"` // default application/json
+	Headers     map[string]string  `json:"headers,omitempty"`
+	Template    string             `json:"template"`
+	Talkgroups  []WebhookTalkgroup `json:"talkgroups"` // empty = every talkgroup
+}
+
+// WebhookTalkgroup scopes a webhook; TalkgroupRef 0 covers the whole system.
+type WebhookTalkgroup struct {
+	SystemRef    uint `json:"systemRef"`
+	TalkgroupRef uint `json:"talkgroupRef"`
+}
+
+type Webhooks []Webhook
+
+const (
+	// webhookEventCall fires when a call is received.
+	webhookEventCall = "call"
+	// webhookEventTranscript fires when a call's transcript is stored.
+	webhookEventTranscript = "transcript"
+	// webhookEventTone fires when a tone alert is created.
+	webhookEventTone = "tone"
+	// webhookEventKeyword fires when a keyword alert is created.
+	webhookEventKeyword = "keyword"
+	// webhookEventSystemAlert fires when a system alert is raised.
+	webhookEventSystemAlert = "system_alert"
+
+	webhookDefaultContentType = "application/json"
+	webhookTemplateMaxLength  = 16384
+	webhookTimeout            = 10 * time.Second
+
+	// webhookMaxAttempts deliveries are tried, waiting webhookRetryDelay
+	// before the second and doubling after each failure.
+	webhookMaxAttempts = 5
+	webhookRetryDelay  = 2 * time.Second
+)
+
+var webhookEvents = map[string]bool{
+	webhookEventCall:        true,
+	webhookEventTranscript:  true,
+	webhookEventTone:        true,
+	webhookEventKeyword:     true,
+	webhookEventSystemAlert: true,
+}
+
+// webhookVariables are the placeholders a template may use.
+var webhookVariables = []string{
+	"event",
+	"webhook.label",
+	"call.id",
+	"call.timestamp",
+	"call.time",
+	"call.frequency",
+	"call.site",
+	"call.unit",
+	"call.url",
+	"system.id",
+	"system.label",
+	"talkgroup.id",
+	"talkgroup.label",
+	"talkgroup.name",
+	"transcript",
+	"tones",
+	"keywords",
+	"alert.id",
+	"alert.type",
+	"systemAlert.type",
+	"systemAlert.severity",
+	"systemAlert.title",
+	"systemAlert.message",
+}
+
+// webhookEvent is something webhooks are notified of. Call is nil for
+// system alerts.
+type webhookEvent struct {
+	Name        string
+	Call        *Call
+	Alert       *AlertRecord
+	Keywords    []string
+	SystemAlert *SystemAlert
+}
+
+// webhookFromMap parses one webhook of the admin representation.
+func webhookFromMap(m map[string]any) Webhook {
+	webhook := Webhook{}
+	if v, ok := m["id"].(float64); ok && v > 0 {
+		webhook.Id = uint(v)
+	}
+	if v, ok := m["label"].(string); ok {
+		webhook.Label = strings.TrimSpace(v)
+	}
+	if v, ok := m["enabled"].(bool); ok {
+		webhook.Enabled = v
+	}
+	if v, ok := m["url"].(string); ok {
+		webhook.URL = strings.TrimSpace(v)
+	}
+	if v, ok := m["secret"].(string); ok {
+		webhook.Secret = strings.TrimSpace(v)
+	}
+	if events, ok := m["events"].([]any); ok {
+		for _, event := range events {
+			if v, ok := event.(string); ok {
+				webhook.Events = append(webhook.Events, strings.ToLower(strings.TrimSpace(v)))
+			}
+		}
+	} else if v, ok := m["event"].(string); ok {
+		webhook.Events = []string{strings.ToLower(strings.TrimSpace(v))}
+	}
+	if v, ok := m["contentType"].(string); ok {
+		webhook.ContentType = strings.TrimSpace(v)
+	}
+	if v, ok := m["template"].(string); ok {
+		webhook.Template = v
+	}
+	if headers, ok := m["headers"].(map[string]any); ok {
+		for name, value := range headers {
+			if s, ok := value.(string); ok && strings.TrimSpace(name) != "" {
+				if webhook.Headers == nil {
+					webhook.Headers = map[string]string{}
+				}
+				webhook.Headers[strings.TrimSpace(name)] = s
+			}
+		}
+	}
+	if talkgroups, ok := m["talkgroups"].([]any); ok {
+		for _, item := range talkgroups {
+			m, ok := item.(map[string]any)
+			if !ok {
+				continue
+			}
+			systemRef, _ := m["systemRef"].(float64)
+			talkgroupRef, _ := m["talkgroupRef"].(float64)
+			if systemRef <= 0 || talkgroupRef < 0 {
+				continue
+			}
+			webhook.Talkgroups = append(webhook.Talkgroups, WebhookTalkgroup{SystemRef: uint(systemRef), TalkgroupRef: uint(talkgroupRef)})
+		}
+	}
+
+	if len(webhook.Events) == 0 {
+		webhook.Events = []string{webhookEventCall}
+	}
+	if webhook.ContentType == "" {
+		webhook.ContentType = webhookDefaultContentType
+	}
+
+	return webhook
+}
+
+// webhooksFromList parses the admin representation. Webhooks without a
+// valid http(s) URL or with a template that doesn't render are dropped; the
+// config handler refuses those with validateWebhookList before saving.
+func webhooksFromList(list []any) Webhooks {
+	webhooks := Webhooks{}
+
+	for _, item := range list {
+		m, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+
+		webhook := webhookFromMap(m)
+		if validateWebhook(&webhook) != nil {
+			continue
+		}
+
+		webhooks = append(webhooks, webhook)
+	}
+
+	// Number webhooks saved without an id after the highest one in use
+	var maxId uint
+	for _, webhook := range webhooks {
+		if webhook.Id > maxId {
+			maxId = webhook.Id
+		}
+	}
+	for i := range webhooks {
+		if webhooks[i].Id == 0 {
+			maxId++
+			webhooks[i].Id = maxId
+		}
+	}
+
+	return webhooks
+}
+
+// validateWebhookList returns the first error of the admin representation.
+func validateWebhookList(list []any) error {
+	for _, item := range list {
+		if m, ok := item.(map[string]any); ok {
+			webhook := webhookFromMap(m)
+			if err := validateWebhook(&webhook); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// validateWebhook checks the destination and renders the template against
+// sample values, so a broken template is refused when saved rather than
+// failing on every call.
+func validateWebhook(webhook *Webhook) error {
+	u, err := url.Parse(webhook.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("webhook %q: url must be an http or https URL", webhook.Label)
+	}
+	for _, event := range webhook.Events {
+		if !webhookEvents[event] {
+			return fmt.Errorf("webhook %q: unknown event %q", webhook.Label, event)
+		}
+	}
+	if len(webhook.Template) > webhookTemplateMaxLength {
+		return fmt.Errorf("webhook %q: template is longer than %d characters", webhook.Label, webhookTemplateMaxLength)
+	}
+
+	sample := map[string]string{}
+	for _, name := range webhookVariables {
+		sample[name] = "0"
+	}
+	sample["transcript"] = `SAMPLE "TRANSCRIPT" \ WITH QUOTES`
+
+	body, err := renderWebhookTemplate(webhook.Template, webhook.ContentType, sample)
+	if err != nil {
+		return fmt.Errorf("webhook %q: %v", webhook.Label, err)
+	}
+	if isJSONContentType(webhook.ContentType) && !json.Valid(body) {
+		return fmt.Errorf("webhook %q: template does not render valid JSON", webhook.Label)
+	}
+
+	return nil
+}
+
+// Covers reports whether the webhook fires for event on the call's
+// talkgroup. System alerts have no call and ignore the talkgroup scope.
+func (webhook *Webhook) Covers(event string, call *Call) bool {
+	if !webhook.Enabled {
+		return false
+	}
+	subscribed := false
+	for _, name := range webhook.Events {
+		if webhookEventMatches(name, event) {
+			subscribed = true
+		}
+	}
+	if !subscribed {
+		return false
+	}
+	if event == webhookEventSystemAlert {
+		return true
+	}
+	if call == nil || call.System == nil || call.Talkgroup == nil {
+		return false
+	}
+	if len(webhook.Talkgroups) == 0 {
+		return true
+	}
+	for _, scope := range webhook.Talkgroups {
+		if scope.SystemRef == call.System.SystemRef && (scope.TalkgroupRef == 0 || scope.TalkgroupRef == call.Talkgroup.TalkgroupRef) {
+			return true
+		}
+	}
+	return false
+}
+
+// webhookEventMatches reports whether a subscription to subscribed covers
+// event. Alert events are named after the alert type, so a combined
+// tone+keyword alert reaches both tone and keyword subscribers once.
+func webhookEventMatches(subscribed string, event string) bool {
+	switch subscribed {
+	case webhookEventTone:
+		return event == "tone" || event == "tone+keyword"
+	case webhookEventKeyword:
+		return event == "keyword" || event == "tone+keyword" || event == "life-safety"
+	default:
+		return subscribed == event
+	}
+}
+
+func isJSONContentType(contentType string) bool {
+	mediaType := strings.ToLower(strings.TrimSpace(strings.Split(contentType, ";")[0]))
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+func isFormContentType(contentType string) bool {
+	mediaType := strings.ToLower(strings.TrimSpace(strings.Split(contentType, ";")[0]))
+	return mediaType == "application/x-www-form-urlencoded"
+}
+
+// renderWebhookTemplate replaces the placeholders of template with values.
+// An empty template renders values as a flat object (or form) keyed by
+// variable name. Unknown placeholders are an error.
+func renderWebhookTemplate(template string, contentType string, values map[string]string) ([]byte, error) {
+	if strings.TrimSpace(template) == "" {
+		return defaultWebhookBody(contentType, values), nil
+	}
+
+	escape := func(s string) string { return s }
+	if isJSONContentType(contentType) {
+		escape = jsonStringContent
+	} else if isFormContentType(contentType) {
+		escape = url.QueryEscape
+	}
+
+	known := map[string]bool{}
+	for _, name := range webhookVariables {
+		known[name] = true
+	}
+
+	var out strings.Builder
+	rest := template
+	for {
+		start := strings.Index(rest, "{{")
+		if start < 0 {
+			out.WriteString(rest)
+			break
+		}
+		out.WriteString(rest[:start])
+		rest = rest[start:]
+
+		raw := strings.HasPrefix(rest, "{{{")
+		open, close := "{{", "}}"
+		if raw {
+			open, close = "{{{", "}}}"
+		}
+		end := strings.Index(rest[len(open):], close)
+		if end < 0 {
+			return nil, fmt.Errorf("unclosed %s in template", open)
+		}
+		name := strings.TrimSpace(rest[len(open) : len(open)+end])
+		if !known[name] {
+			return nil, fmt.Errorf("unknown template variable %q", name)
+		}
+		if raw {
+			out.WriteString(values[name])
+		} else {
+			out.WriteString(escape(values[name]))
+		}
+		rest = rest[len(open)+end+len(close):]
+	}
+
+	return []byte(out.String()), nil
+}
+
+// jsonStringContent escapes s for use inside a JSON string, without the
+// surrounding quotes.
+func jsonStringContent(s string) string {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.Encode(s)
+	encoded := strings.TrimSuffix(buf.String(), "\n")
+	return encoded[1 : len(encoded)-1]
+}
+
+func defaultWebhookBody(contentType string, values map[string]string) []byte {
+	if isFormContentType(contentType) {
+		form := url.Values{}
+		for name, value := range values {
+			form.Set(name, value)
+		}
+		return []byte(form.Encode())
+	}
+
+	b, _ := json.Marshal(values)
+	return b
+}
+
+// webhookValues returns the template values for an event. Fields that don't
+// apply to the event are empty.
+func (controller *Controller) webhookValues(event *webhookEvent, webhook *Webhook) map[string]string {
+	values := map[string]string{}
+	for _, name := range webhookVariables {
+		values[name] = ""
+	}
+	values["event"] = event.Name
+	values["webhook.label"] = webhook.Label
+	values["keywords"] = strings.Join(event.Keywords, ", ")
+
+	if alert := event.Alert; alert != nil {
+		values["alert.id"] = strconv.FormatUint(alert.AlertId, 10)
+		values["alert.type"] = alert.AlertType
+	}
+
+	if systemAlert := event.SystemAlert; systemAlert != nil {
+		values["systemAlert.type"] = systemAlert.AlertType
+		values["systemAlert.severity"] = systemAlert.Severity
+		values["systemAlert.title"] = systemAlert.Title
+		values["systemAlert.message"] = systemAlert.Message
+	}
+
+	call := event.Call
+	if call == nil || call.System == nil || call.Talkgroup == nil {
+		return values
+	}
+
+	values["call.id"] = strconv.FormatUint(call.Id, 10)
+	values["call.timestamp"] = strconv.FormatInt(call.Timestamp.UnixMilli(), 10)
+	values["call.time"] = call.Timestamp.UTC().Format(time.RFC3339)
+	values["call.localTime"] = call.Timestamp.In(call.System.Location()).Format(time.RFC3339)
+	values["call.frequency"] = strconv.FormatUint(uint64(call.Frequency), 10)
+	values["call.site"] = call.SiteRef
+	values["system.id"] = strconv.FormatUint(uint64(call.System.SystemRef), 10)
+	values["system.label"] = call.System.Label
+	values["talkgroup.id"] = strconv.FormatUint(uint64(call.Talkgroup.TalkgroupRef), 10)
+	values["talkgroup.label"] = call.Talkgroup.Label
+	values["talkgroup.name"] = call.Talkgroup.Name
+	values["transcript"] = call.Transcript
+
+	if len(call.Units) > 0 {
+		values["call.unit"] = strconv.FormatUint(uint64(call.Units[0].UnitRef), 10)
+	}
+
+	values["call.url"] = controller.callAudioURL(call.Id)
+
+	if call.ToneSequence != nil {
+		var labels []string
+		for _, toneSet := range call.ToneSequence.MatchedToneSets {
+			if toneSet != nil && toneSet.Label != "" {
+				labels = append(labels, toneSet.Label)
+			}
+		}
+		if len(labels) == 0 && call.ToneSequence.MatchedToneSet != nil {
+			labels = append(labels, call.ToneSequence.MatchedToneSet.Label)
+		}
+		values["tones"] = strings.Join(labels, ", ")
+	}
+
+	return values
+}
+
+// dispatchWebhooks notifies the admin webhooks and the user webhooks that
+// cover the event.
+func (controller *Controller) dispatchWebhooks(event *webhookEvent) {
+	for i := range controller.Options.Webhooks {
+		webhook := controller.Options.Webhooks[i]
+		if webhook.Covers(event.Name, event.Call) {
+			controller.sendWebhook(event, &webhook, 0)
+		}
+	}
+
+	if controller.UserWebhooks != nil {
+		controller.dispatchUserWebhooks(event)
+	}
+}
+
+// sendWebhook renders the body and delivers it after delay, retrying in the
+// background.
+func (controller *Controller) sendWebhook(event *webhookEvent, webhook *Webhook, delay time.Duration) {
+	body, err := renderWebhookTemplate(webhook.Template, webhook.ContentType, controller.webhookValues(event, webhook))
+	if err != nil {
+		controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("webhook %q: %v", webhook.Label, err))
+		return
+	}
+
+	// Stored as a persistent job so deliveries survive a restart, and those
+	// that keep failing can be inspected and retried
+	payload := webhookJobPayload{Webhook: *webhook, Event: event.Name, DeliveryId: newWebhookDeliveryId(), Body: body}
+	if controller.Jobs != nil {
+		_, err := controller.Jobs.Enqueue(jobKindWebhook, 0, payload, nil, delay)
+		if err == nil {
+			return
+		}
+		controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("webhook %q: failed to store delivery, sending from memory: %v", webhook.Label, err))
+	}
+
+	go func() {
+		if delay > 0 {
+			time.Sleep(delay)
+		}
+		if err := deliverWebhook(webhook, event.Name, body, webhookRetryDelay); err != nil {
+			controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("webhook %q for %s event: %v", webhook.Label, event.Name, err))
+		}
+	}()
+}
+
+// jobKindWebhook is a persistent job making one webhook delivery.
+const jobKindWebhook = "webhook"
+
+// webhookJobConcurrency deliveries are made at the same time.
+const webhookJobConcurrency = 8
+
+// webhookJobPayload carries the webhook itself, so user webhooks and
+// webhooks edited after the event deliver as they were configured.
+type webhookJobPayload struct {
+	Webhook    Webhook `json:"webhook"`
+	Event      string  `json:"event"`
+	DeliveryId string  `json:"deliveryId"`
+	Body       []byte  `json:"body"`
+}
+
+// runWebhookJob makes one delivery attempt. Failures other than connection
+// errors, 429 and 5xx responses are not retried.
+func (controller *Controller) runWebhookJob(job *Job) error {
+	var payload webhookJobPayload
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		return jobPermanent(fmt.Errorf("invalid payload: %v", err))
+	}
+
+	retry, err := postWebhook(&payload.Webhook, payload.Event, payload.DeliveryId, payload.Body)
+	if err != nil && !retry {
+		return jobPermanent(err)
+	}
+	return err
+}
+
+// newWebhookDeliveryId is the id shared by every attempt of a delivery.
+func newWebhookDeliveryId() string {
+	idBytes := make([]byte, 8)
+	rand.Read(idBytes)
+	return hex.EncodeToString(idBytes)
+}
+
+// webhookSignature is the hex HMAC-SHA256 of "<timestamp>.<body>".
+func webhookSignature(secret string, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// deliverWebhook posts body, retrying connection errors, 429 and 5xx
+// responses with exponential backoff from retryDelay. Every attempt carries
+// the same delivery id so receivers can drop repeats.
+func deliverWebhook(webhook *Webhook, event string, body []byte, retryDelay time.Duration) error {
+	deliveryId := newWebhookDeliveryId()
+
+	var err error
+	for attempt := 1; attempt <= webhookMaxAttempts; attempt++ {
+		if attempt > 1 {
+			time.Sleep(retryDelay * time.Duration(1<<uint(attempt-2)))
+		}
+
+		var retry bool
+		retry, err = postWebhook(webhook, event, deliveryId, body)
+		if err == nil || !retry {
+			break
+		}
+	}
+	return err
+}
+
+// postWebhook makes one delivery attempt and reports whether a failure is
+// worth retrying.
+func postWebhook(webhook *Webhook, event string, deliveryId string, body []byte) (bool, error) {
+	req, err := http.NewRequest(http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("build request: %w", err)
+	}
+	req.Header.Set("Content-Type", webhook.ContentType)
+	for name, value := range webhook.Headers {
+		req.Header.Set(name, value)
+	}
+	req.Header.Set("X-Webhook-Event", event)
+	req.Header.Set("X-Webhook-Delivery", deliveryId)
+	if webhook.Secret != "" {
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		req.Header.Set("X-Webhook-Timestamp", timestamp)
+		req.Header.Set("X-Webhook-Signature", "sha256="+webhookSignature(webhook.Secret, timestamp, body))
+	}
+
+	client := &http.Client{Timeout: webhookTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return true, fmt.Errorf("POST to %s: %w", webhook.URL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+		return retry, fmt.Errorf("%s returned status %s", webhook.URL, resp.Status)
+	}
+
+	return false, nil
+}
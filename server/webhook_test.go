@@ -0,0 +1,197 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRenderWebhookTemplateJSON(t *testing.T) {
+	template := `{"incident": {"talkgroup": {{talkgroup.id}}, "narrative": "{{transcript}}", "raw": {{{call.id}}}}}`
+	values := map[string]string{
+		"talkgroup.id": "100",
+		"transcript":   `ENGINE 5 "RESPOND" <NOW>`,
+		"call.id":      "42",
+	}
+
+	body, err := renderWebhookTemplate(template, "application/json", values)
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+
+	var decoded struct {
+		Incident struct {
+			Talkgroup int    `json:"talkgroup"`
+			Narrative string `json:"narrative"`
+			Raw       int    `json:"raw"`
+		} `json:"incident"`
+	}
+	if err := json.Unmarshal(body, &decoded); err != nil {
+		t.Fatalf("rendered body is not JSON: %v\n%s", err, body)
+	}
+	if decoded.Incident.Talkgroup != 100 || decoded.Incident.Raw != 42 || decoded.Incident.Narrative != values["transcript"] {
+		t.Fatalf("unexpected body %s", body)
+	}
+}
+
+func TestRenderWebhookTemplateForm(t *testing.T) {
+	body, err := renderWebhookTemplate("text={{ transcript }}&tg={{talkgroup.label}}", "application/x-www-form-urlencoded", map[string]string{
+		"transcript":      "A & B",
+		"talkgroup.label": "FIRE DISPATCH",
+	})
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	if string(body) != "text=A+%26+B&tg=FIRE+DISPATCH" {
+		t.Fatalf("unexpected body %q", body)
+	}
+}
+
+func TestRenderWebhookTemplateErrors(t *testing.T) {
+	if _, err := renderWebhookTemplate(`{"a": "{{nope}}"}`, "application/json", nil); err == nil {
+		t.Fatalf("expected unknown variable to fail")
+	}
+	if _, err := renderWebhookTemplate(`{"a": "{{transcript"}`, "application/json", nil); err == nil {
+		t.Fatalf("expected unclosed placeholder to fail")
+	}
+}
+
+func TestRenderWebhookTemplateDefault(t *testing.T) {
+	body, err := renderWebhookTemplate("", "application/json", map[string]string{"call.id": "7"})
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	if string(body) != `{"call.id":"7"}` {
+		t.Fatalf("unexpected default body %s", body)
+	}
+}
+
+func TestValidateWebhookList(t *testing.T) {
+	valid := map[string]any{"label": "CAD", "url": "https://cad.example.com/in", "template": `{"text": "{{transcript}}"}`}
+	if err := validateWebhookList([]any{valid}); err != nil {
+		t.Fatalf("expected valid webhook, got %v", err)
+	}
+
+	tests := []map[string]any{
+		{"label": "no url", "url": "ftp://example.com"},
+		{"label": "bad event", "url": "https://example.com", "events": []any{"call", "unit"}},
+		{"label": "not json", "url": "https://example.com", "template": `{"text": {{transcript}}}`},
+	}
+	for _, test := range tests {
+		if err := validateWebhookList([]any{test}); err == nil || !strings.Contains(err.Error(), test["label"].(string)) {
+			t.Fatalf("expected %q to be refused, got %v", test["label"], err)
+		}
+	}
+
+	webhooks := webhooksFromList([]any{valid, tests[0]})
+	if len(webhooks) != 1 || webhooks[0].Id != 1 || len(webhooks[0].Events) != 1 || webhooks[0].Events[0] != webhookEventCall || webhooks[0].ContentType != webhookDefaultContentType {
+		t.Fatalf("unexpected webhooks %+v", webhooks)
+	}
+}
+
+func TestWebhookCovers(t *testing.T) {
+	call := &Call{System: &System{SystemRef: 1}, Talkgroup: &Talkgroup{TalkgroupRef: 100}}
+
+	webhook := Webhook{Enabled: true, Events: []string{webhookEventCall}}
+	if !webhook.Covers(webhookEventCall, call) {
+		t.Fatalf("expected unscoped webhook to cover the call")
+	}
+	if webhook.Covers(webhookEventTranscript, call) {
+		t.Fatalf("expected webhook to cover its event only")
+	}
+
+	webhook.Talkgroups = []WebhookTalkgroup{{SystemRef: 1, TalkgroupRef: 200}}
+	if webhook.Covers(webhookEventCall, call) {
+		t.Fatalf("expected talkgroup scope to exclude the call")
+	}
+	webhook.Talkgroups = append(webhook.Talkgroups, WebhookTalkgroup{SystemRef: 1})
+	if !webhook.Covers(webhookEventCall, call) {
+		t.Fatalf("expected system scope to cover the call")
+	}
+
+	webhook.Enabled = false
+	if webhook.Covers(webhookEventCall, call) {
+		t.Fatalf("expected disabled webhook to cover nothing")
+	}
+}
+
+func TestWebhookEventMatches(t *testing.T) {
+	webhook := Webhook{Enabled: true, Events: []string{webhookEventKeyword, webhookEventSystemAlert}}
+	call := &Call{System: &System{SystemRef: 1}, Talkgroup: &Talkgroup{TalkgroupRef: 100}}
+
+	for event, want := range map[string]bool{
+		"keyword":      true,
+		"tone+keyword": true,
+		"life-safety":  true,
+		"tone":         false,
+		"pre-alert":    false,
+		"call":         false,
+	} {
+		if got := webhook.Covers(event, call); got != want {
+			t.Fatalf("Covers(%q) = %t, want %t", event, got, want)
+		}
+	}
+
+	webhook.Talkgroups = []WebhookTalkgroup{{SystemRef: 2}}
+	if !webhook.Covers(webhookEventSystemAlert, nil) {
+		t.Fatalf("expected system alerts to ignore the talkgroup scope")
+	}
+}
+
+func TestDeliverWebhookSignsAndRetries(t *testing.T) {
+	var attempts atomic.Int32
+	deliveries := map[string]bool{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		timestamp := r.Header.Get("X-Webhook-Timestamp")
+		if r.Header.Get("X-Webhook-Signature") != "sha256="+webhookSignature("s3cret", timestamp, body) {
+			t.Errorf("bad signature %q", r.Header.Get("X-Webhook-Signature"))
+		}
+		if r.Header.Get("X-Webhook-Event") != "tone" {
+			t.Errorf("unexpected event header %q", r.Header.Get("X-Webhook-Event"))
+		}
+		deliveries[r.Header.Get("X-Webhook-Delivery")] = true
+		if attempts.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	webhook := &Webhook{URL: server.URL, Secret: "s3cret", ContentType: webhookDefaultContentType}
+	if err := deliverWebhook(webhook, "tone", []byte(`{"a":1}`), time.Millisecond); err != nil {
+		t.Fatalf("deliver: %v", err)
+	}
+	if attempts.Load() != 3 {
+		t.Fatalf("expected 3 attempts, got %d", attempts.Load())
+	}
+	if len(deliveries) != 1 {
+		t.Fatalf("expected retries to keep the delivery id, got %v", deliveries)
+	}
+}
+
+func TestDeliverWebhookDoesNotRetryClientErrors(t *testing.T) {
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Webhook-Signature") != "" {
+			t.Errorf("unexpected signature without a secret")
+		}
+		attempts.Add(1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	webhook := &Webhook{URL: server.URL, ContentType: webhookDefaultContentType}
+	if err := deliverWebhook(webhook, "call", []byte(`{}`), time.Millisecond); err == nil {
+		t.Fatalf("expected an error")
+	}
+	if attempts.Load() != 1 {
+		t.Fatalf("expected a single attempt, got %d", attempts.Load())
+	}
+}